@@ -77,6 +77,7 @@ type Client interface {
 	PostStartup(ctx context.Context, req agentsdk.PostStartupRequest) error
 	PostMetadata(ctx context.Context, key string, req agentsdk.PostMetadataRequest) error
 	PatchStartupLogs(ctx context.Context, req agentsdk.PatchStartupLogs) error
+	DERPMapUpdates(ctx context.Context) (<-chan *tailcfg.DERPMap, error)
 }
 
 type Agent interface {
@@ -809,19 +810,64 @@ func (a *agent) runCoordinator(ctx context.Context, network *tailnet.Conn) error
 		return err
 	}
 	defer coordinator.Close()
+
+	derpMapDone := make(chan struct{})
+	defer func() {
+		cancel()
+		<-derpMapDone
+	}()
+	go func() {
+		defer close(derpMapDone)
+		a.runDERPMapUpdates(ctx, network)
+	}()
 	a.logger.Info(ctx, "connected to coordination endpoint")
-	sendNodes, errChan := tailnet.ServeCoordinator(coordinator, func(nodes []*tailnet.Node) error {
-		return network.UpdateNodes(nodes, false)
-	})
-	network.SetNodeCallback(sendNodes)
+	coordination := tailnet.NewRemoteCoordination(a.logger, coordinator, network, uuid.Nil)
+	defer coordination.Close()
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case err := <-errChan:
+	case err := <-coordination.Error():
 		return err
 	}
 }
 
+// runDERPMapUpdates applies DERP map changes pushed by coderd to the network
+// until ctx is canceled.
+func (a *agent) runDERPMapUpdates(ctx context.Context, network *tailnet.Conn) {
+	for retrier := retry.New(100*time.Millisecond, 10*time.Second); retrier.Wait(ctx); {
+		derpMapUpdates, err := a.client.DERPMapUpdates(ctx)
+		if err != nil {
+			var sdkErr *codersdk.Error
+			if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+				// Older versions of coderd don't stream DERP map updates, in
+				// which case the agent keeps using the DERP map of the
+				// manifest.
+				a.logger.Debug(ctx, "derp map updates are unsupported")
+				return
+			}
+			a.logger.Debug(ctx, "failed to stream derp map updates", slog.Error(err))
+			continue
+		}
+		for {
+			var (
+				derpMap *tailcfg.DERPMap
+				ok      bool
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case derpMap, ok = <-derpMapUpdates:
+			}
+			if !ok {
+				break
+			}
+			a.logger.Debug(ctx, "updating derp map")
+			network.SetDERPMap(derpMap)
+			retrier.Reset()
+		}
+	}
+}
+
 func (a *agent) runStartupScript(ctx context.Context, script string) error {
 	return a.runScript(ctx, "startup", script)
 }
@@ -1263,6 +1309,7 @@ func (a *agent) startReportingConnectionStats(ctx context.Context) {
 		var mu sync.Mutex
 		status := a.network.Status()
 		durations := []float64{}
+		stats.PeerConnections = []agentsdk.PeerConnection{}
 		pingCtx, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
 		defer cancelFunc()
 		for nodeID, peer := range status.Peer {
@@ -1279,12 +1326,20 @@ func (a *agent) startReportingConnectionStats(ctx context.Context) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				duration, _, _, err := a.network.Ping(pingCtx, addresses[0].Addr())
+				duration, p2p, pong, err := a.network.Ping(pingCtx, addresses[0].Addr())
 				if err != nil {
 					return
 				}
+				peerConnection := agentsdk.PeerConnection{
+					Direct:              p2p,
+					LatencyMilliseconds: float64(duration.Microseconds()) / 1000,
+				}
+				if !p2p {
+					peerConnection.DERPRegionID = pong.DERPRegionID
+				}
 				mu.Lock()
 				durations = append(durations, float64(duration.Microseconds()))
+				stats.PeerConnections = append(stats.PeerConnections, peerConnection)
 				mu.Unlock()
 			}()
 		}
@@ -91,6 +91,33 @@ func TestAgent_Stats_SSH(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAgent_Stats_PeerConnections(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	//nolint:dogsled
+	conn, _, stats, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+
+	// Stats are only reported while there's traffic.
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+
+	var s *agentsdk.Stats
+	require.Eventuallyf(t, func() bool {
+		var ok bool
+		s, ok = <-stats
+		return ok && len(s.PeerConnections) == 1
+	}, testutil.WaitLong, testutil.IntervalFast,
+		"never saw stats: %+v", s,
+	)
+	peerConnection := s.PeerConnections[0]
+	if !peerConnection.Direct {
+		require.NotZero(t, peerConnection.DERPRegionID)
+	}
+}
+
 func TestAgent_Stats_ReconnectingPTY(t *testing.T) {
 	t.Parallel()
 
@@ -1622,6 +1649,42 @@ func TestAgent_Reconnect(t *testing.T) {
 	}, testutil.WaitShort, testutil.IntervalFast)
 }
 
+func TestAgent_UpdatedDERP(t *testing.T) {
+	t.Parallel()
+
+	conn, client, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	// Replace the DERP region of the manifest while the client is connected.
+	newDerpMap := tailnettest.RunDERPAndSTUN(t)
+	region := newDerpMap.Regions[1]
+	delete(newDerpMap.Regions, 1)
+	region.RegionID = 2
+	for _, node := range region.Nodes {
+		node.RegionID = 2
+	}
+	newDerpMap.Regions[2] = region
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out sending the DERP map")
+	case client.derpMapUpdates <- newDerpMap:
+	}
+	require.Eventually(t, func() bool {
+		node := client.coordinator.Node(client.agentID)
+		return node != nil && node.PreferredDERP == 2
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	// The client follows and can still reach the agent.
+	conn.SetDERPMap(newDerpMap)
+	require.True(t, conn.AwaitReachable(ctx))
+	netConn, err := conn.DialContextTCP(ctx, netip.AddrPortFrom(codersdk.WorkspaceAgentIP, codersdk.WorkspaceAgentSpeedtestPort))
+	require.NoError(t, err)
+	_ = netConn.Close()
+}
+
 func TestAgent_WriteVSCodeConfigs(t *testing.T) {
 	t.Parallel()
 	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
@@ -1747,11 +1810,12 @@ func setupAgent(t *testing.T, metadata agentsdk.Manifest, ptyTimeout time.Durati
 	statsCh := make(chan *agentsdk.Stats, 50)
 	fs := afero.NewMemMapFs()
 	c := &client{
-		t:           t,
-		agentID:     agentID,
-		manifest:    metadata,
-		statsChan:   statsCh,
-		coordinator: coordinator,
+		t:              t,
+		agentID:        agentID,
+		manifest:       metadata,
+		statsChan:      statsCh,
+		coordinator:    coordinator,
+		derpMapUpdates: make(chan *tailcfg.DERPMap),
 	}
 
 	options := agent.Options{
@@ -1847,6 +1911,7 @@ type client struct {
 	coordinator        tailnet.Coordinator
 	lastWorkspaceAgent func()
 	patchWorkspaceLogs func() error
+	derpMapUpdates     chan *tailcfg.DERPMap
 
 	mu              sync.Mutex // Protects following.
 	lifecycleStates []codersdk.WorkspaceAgentLifecycle
@@ -1868,7 +1933,8 @@ func (c *client) Listen(_ context.Context) (net.Conn, error) {
 	}
 	c.t.Cleanup(c.lastWorkspaceAgent)
 	go func() {
-		_ = c.coordinator.ServeAgent(serverConn, c.agentID, "")
+		_ = tailnet.ServeCoordinatePeer(context.Background(), slogtest.Make(c.t, nil), c.coordinator, serverConn,
+			c.agentID, "", tailnet.AgentTunnelAuth{})
 		close(closed)
 	}()
 	return clientConn, nil
@@ -1958,6 +2024,10 @@ func (c *client) getStartupLogs() []agentsdk.StartupLog {
 	return c.logs
 }
 
+func (c *client) DERPMapUpdates(_ context.Context) (<-chan *tailcfg.DERPMap, error) {
+	return c.derpMapUpdates, nil
+}
+
 func (c *client) PatchStartupLogs(_ context.Context, logs agentsdk.PatchStartupLogs) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
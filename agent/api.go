@@ -9,6 +9,7 @@ import (
 
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
 )
 
 func (a *agent) apiHandler() http.Handler {
@@ -28,6 +29,7 @@ func (a *agent) apiHandler() http.Handler {
 
 	lp := &listeningPortsHandler{ignorePorts: cpy}
 	r.Get("/api/v0/listening-ports", lp.handler)
+	r.Get("/api/v0/netcheck", a.netcheckHandler)
 
 	return r
 }
@@ -55,3 +57,20 @@ func (lp *listeningPortsHandler) handler(rw http.ResponseWriter, r *http.Request
 		Ports: ports,
 	})
 }
+
+// netcheckHandler runs a netcheck from the workspace, so clients can tell why
+// their connection to the agent isn't direct.
+func (a *agent) netcheckHandler(rw http.ResponseWriter, r *http.Request) {
+	a.closeMutex.Lock()
+	network := a.network
+	a.closeMutex.Unlock()
+	if network == nil {
+		httpapi.Write(r.Context(), rw, http.StatusServiceUnavailable, codersdk.Response{
+			Message: "The agent network isn't ready yet.",
+		})
+		return
+	}
+
+	report := tailnet.Netcheck(r.Context(), a.logger, network.DERPMap())
+	httpapi.Write(r.Context(), rw, http.StatusOK, report)
+}
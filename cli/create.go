@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ import (
 func (r *RootCmd) create() *clibase.Cmd {
 	var (
 		richParameterFile string
+		presetName        string
 		templateName      string
 		startAt           string
 		stopAfter         time.Duration
@@ -119,9 +121,18 @@ func (r *RootCmd) create() *clibase.Cmd {
 				schedSpec = ptr.Ref(sched.String())
 			}
 
+			var preset codersdk.TemplateVersionPreset
+			if presetName != "" {
+				preset, err = templateVersionPresetByName(inv.Context(), client, template.ActiveVersionID, presetName)
+				if err != nil {
+					return err
+				}
+			}
+
 			buildParams, err := prepWorkspaceBuild(inv, client, prepWorkspaceBuildArgs{
 				Template:          template,
 				RichParameterFile: richParameterFile,
+				PresetParameters:  preset.Parameters,
 				NewWorkspaceName:  workspaceName,
 			})
 			if err != nil {
@@ -144,11 +155,12 @@ func (r *RootCmd) create() *clibase.Cmd {
 			}
 
 			workspace, err := client.CreateWorkspace(inv.Context(), organization.ID, codersdk.Me, codersdk.CreateWorkspaceRequest{
-				TemplateID:          template.ID,
-				Name:                workspaceName,
-				AutostartSchedule:   schedSpec,
-				TTLMillis:           ttlMillis,
-				RichParameterValues: buildParams.richParameters,
+				TemplateID:              template.ID,
+				Name:                    workspaceName,
+				AutostartSchedule:       schedSpec,
+				TTLMillis:               ttlMillis,
+				RichParameterValues:     buildParams.richParameters,
+				TemplateVersionPresetID: preset.ID,
 			})
 			if err != nil {
 				return xerrors.Errorf("create workspace: %w", err)
@@ -177,6 +189,12 @@ func (r *RootCmd) create() *clibase.Cmd {
 			Description: "Specify a file path with values for rich parameters defined in the template.",
 			Value:       clibase.StringOf(&richParameterFile),
 		},
+		clibase.Option{
+			Flag:        "preset",
+			Env:         "CODER_WORKSPACE_PRESET",
+			Description: "Specify the name of a preset of the template to use its parameter values. Values from the rich parameter file take precedence.",
+			Value:       clibase.StringOf(&presetName),
+		},
 		clibase.Option{
 			Flag:        "start-at",
 			Env:         "CODER_WORKSPACE_START_AT",
@@ -199,6 +217,7 @@ type prepWorkspaceBuildArgs struct {
 	Template           codersdk.Template
 	ExistingRichParams []codersdk.WorkspaceBuildParameter
 	RichParameterFile  string
+	PresetParameters   []codersdk.WorkspaceBuildParameter
 	NewWorkspaceName   string
 
 	UpdateWorkspace bool
@@ -270,6 +289,15 @@ PromptRichParamLoop:
 			}
 		}
 
+		if _, ok := parameterMapFromFile[templateVersionParameter.Name]; !ok {
+			for _, p := range args.PresetParameters {
+				if p.Name == templateVersionParameter.Name {
+					richParameters = append(richParameters, p)
+					continue PromptRichParamLoop
+				}
+			}
+		}
+
 		parameterValue, err := getWorkspaceBuildParameterValueFromMapOrInput(inv, parameterMapFromFile, templateVersionParameter)
 		if err != nil {
 			return nil, err
@@ -339,6 +367,26 @@ PromptRichParamLoop:
 	}, nil
 }
 
+// templateVersionPresetByName returns the preset of the template version with
+// the given name.
+func templateVersionPresetByName(ctx context.Context, client *codersdk.Client, templateVersionID uuid.UUID, name string) (codersdk.TemplateVersionPreset, error) {
+	presets, err := client.TemplateVersionPresets(ctx, templateVersionID)
+	if err != nil {
+		return codersdk.TemplateVersionPreset{}, xerrors.Errorf("get template version presets: %w", err)
+	}
+	names := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+		names = append(names, preset.Name)
+	}
+	if len(names) == 0 {
+		return codersdk.TemplateVersionPreset{}, xerrors.Errorf("preset %q not found, the template has no presets", name)
+	}
+	return codersdk.TemplateVersionPreset{}, xerrors.Errorf("preset %q not found, available presets: %s", name, strings.Join(names, ", "))
+}
+
 func workspaceBuildParameterExists(ctx context.Context, client *codersdk.Client, workspaceID uuid.UUID, templateVersionParameter codersdk.TemplateVersionParameter) (bool, error) {
 	lastBuildParameters, err := client.WorkspaceBuildParameters(ctx, workspaceID)
 	if err != nil {
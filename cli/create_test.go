@@ -300,6 +300,67 @@ func TestCreateWithRichParameters(t *testing.T) {
 		}
 		<-doneChan
 	})
+
+	t.Run("Preset", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, echoResponses)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+			Presets: []codersdk.TemplateVersionPresetRequest{{
+				Name: "default",
+				Parameters: []codersdk.WorkspaceBuildParameter{
+					{Name: firstParameterName, Value: firstParameterValue},
+					{Name: secondParameterName, Value: secondParameterValue},
+				},
+			}},
+		})
+		require.NoError(t, err)
+
+		inv, root := clitest.New(t, "create", "my-workspace", "--template", template.Name, "--preset", "default")
+		clitest.SetupConfig(t, client, root)
+		doneChan := make(chan struct{})
+		pty := ptytest.New(t).Attach(inv)
+		go func() {
+			defer close(doneChan)
+			err := inv.Run()
+			assert.NoError(t, err)
+		}()
+
+		// Only the parameter that the preset leaves out is prompted.
+		matches := []string{
+			immutableParameterDescription, immutableParameterValue,
+			"Confirm create?", "yes",
+		}
+		for i := 0; i < len(matches); i += 2 {
+			match := matches[i]
+			value := matches[i+1]
+			pty.ExpectMatch(match)
+			pty.WriteLine(value)
+		}
+		<-doneChan
+
+		workspace, err := client.WorkspaceByOwnerAndName(ctx, codersdk.Me, "my-workspace", codersdk.WorkspaceOptions{})
+		require.NoError(t, err)
+		parameters, err := client.WorkspaceBuildParameters(ctx, workspace.LatestBuild.ID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []codersdk.WorkspaceBuildParameter{
+			{Name: firstParameterName, Value: firstParameterValue},
+			{Name: secondParameterName, Value: secondParameterValue},
+			{Name: immutableParameterName, Value: immutableParameterValue},
+		}, parameters)
+
+		inv, root = clitest.New(t, "create", "other-workspace", "--template", template.Name, "--preset", "unknown", "-y")
+		clitest.SetupConfig(t, client, root)
+		err = inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "available presets: default")
+	})
 }
 
 func TestCreateValidateRichParameters(t *testing.T) {
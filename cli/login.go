@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -11,6 +12,7 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/pkg/browser"
@@ -45,6 +47,11 @@ func (r *RootCmd) login() *clibase.Cmd {
 		username string
 		password string
 		trial    bool
+
+		loginEmail    string
+		loginPassword string
+		totpCode      string
+		useDevice     bool
 	)
 	cmd := &clibase.Cmd{
 		Use:        "login <url>",
@@ -191,15 +198,14 @@ func (r *RootCmd) login() *clibase.Cmd {
 				if err != nil {
 					return xerrors.Errorf("create initial user: %w", err)
 				}
-				resp, err := client.LoginWithPassword(inv.Context(), codersdk.LoginWithPasswordRequest{
+				sessionToken, err := loginWithPassword(inv, client, codersdk.LoginWithPasswordRequest{
 					Email:    email,
 					Password: password,
 				})
 				if err != nil {
-					return xerrors.Errorf("login with password: %w", err)
+					return err
 				}
 
-				sessionToken := resp.SessionToken
 				config := r.createConfig()
 				err = config.Session().Write(sessionToken)
 				if err != nil {
@@ -219,17 +225,55 @@ func (r *RootCmd) login() *clibase.Cmd {
 			}
 
 			sessionToken, _ := inv.ParsedFlags().GetString(varToken)
+			if sessionToken == "" && loginEmail != "" {
+				if loginPassword == "" {
+					loginPassword, err = cliui.Prompt(inv, cliui.PromptOptions{
+						Text:     "Enter your " + cliui.DefaultStyles.Field.Render("password") + ":",
+						Secret:   true,
+						Validate: cliui.ValidateNotEmpty,
+					})
+					if err != nil {
+						return xerrors.Errorf("password prompt: %w", err)
+					}
+				}
+				sessionToken, err = loginWithPassword(inv, client, codersdk.LoginWithPasswordRequest{
+					Email:    loginEmail,
+					Password: loginPassword,
+					TOTPCode: totpCode,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if sessionToken == "" && useDevice {
+				sessionToken, err = loginWithDevice(inv, client)
+				if err != nil {
+					return err
+				}
+			}
 			if sessionToken == "" {
 				authURL := *serverURL
 				// Don't use filepath.Join, we don't want to use the os separator
 				// for a url.
 				authURL.Path = path.Join(serverURL.Path, "/cli-auth")
 				if err := openURL(inv, authURL.String()); err != nil {
-					_, _ = fmt.Fprintf(inv.Stdout, "Open the following in your browser:\n\n\t%s\n\n", authURL.String())
+					noOpen, _ := inv.ParsedFlags().GetBool(varNoOpen)
+					if !noOpen {
+						// There is no browser on this machine, so the user
+						// has to approve the login from another device.
+						sessionToken, err = loginWithDevice(inv, client)
+						if err != nil && !errors.Is(err, errDeviceLoginUnsupported) {
+							return err
+						}
+					}
+					if sessionToken == "" {
+						_, _ = fmt.Fprintf(inv.Stdout, "Open the following in your browser:\n\n\t%s\n\n", authURL.String())
+					}
 				} else {
 					_, _ = fmt.Fprintf(inv.Stdout, "Your browser has been opened to visit:\n\n\t%s\n\n", authURL.String())
 				}
-
+			}
+			if sessionToken == "" {
 				sessionToken, err = cliui.Prompt(inv, cliui.PromptOptions{
 					Text:   "Paste your token here:",
 					Secret: true,
@@ -293,10 +337,132 @@ func (r *RootCmd) login() *clibase.Cmd {
 			Description: "Specifies whether a trial license should be provisioned for the Coder deployment or not.",
 			Value:       clibase.BoolOf(&trial),
 		},
+		{
+			Flag:        "email",
+			Env:         "CODER_LOGIN_EMAIL",
+			Description: "Log in with the password of the user with this email address instead of a session token.",
+			Value:       clibase.StringOf(&loginEmail),
+		},
+		{
+			Flag:        "password",
+			Env:         "CODER_LOGIN_PASSWORD",
+			Description: "Specifies the password to log in with. Prompted for if --email is set and this is not.",
+			Value:       clibase.StringOf(&loginPassword),
+		},
+		{
+			Flag:        "totp-code",
+			Env:         "CODER_LOGIN_TOTP_CODE",
+			Description: "Specifies the code from your authenticator app if multi-factor authentication is enabled. Prompted for if required and not set.",
+			Value:       clibase.StringOf(&totpCode),
+		},
+		{
+			Flag:        "device",
+			Env:         "CODER_LOGIN_DEVICE",
+			Description: "Log in by approving a code from a browser on another device. Used automatically if a browser cannot be opened.",
+			Value:       clibase.BoolOf(&useDevice),
+		},
 	}
 	return cmd
 }
 
+// loginWithPassword returns a session token for the email and password. If the
+// user has multi-factor authentication enabled, or the deployment requires
+// them to enroll, the user is prompted for a code.
+func loginWithPassword(inv *clibase.Invocation, client *codersdk.Client, req codersdk.LoginWithPasswordRequest) (string, error) {
+	resp, err := client.LoginWithPassword(inv.Context(), req)
+	if err != nil {
+		return "", xerrors.Errorf("login with password: %w", err)
+	}
+	if resp.SessionToken != "" {
+		return resp.SessionToken, nil
+	}
+
+	codeText := "Enter the " + cliui.DefaultStyles.Field.Render("code") + " from your authenticator app or a recovery code:"
+	if resp.MFAEnrollmentRequired {
+		enrollment, err := client.EnrollTOTPWithPassword(inv.Context(), req)
+		if err != nil {
+			return "", xerrors.Errorf("enroll in multi-factor authentication: %w", err)
+		}
+		_, _ = fmt.Fprintf(inv.Stdout, Caret+"Your deployment requires you to enroll in multi-factor authentication. Add this secret to your authenticator app:\n\n\t%s\n\nOr import it from this URL:\n\n\t%s\n\n", enrollment.Secret, enrollment.URL)
+		codeText = "Enter the " + cliui.DefaultStyles.Field.Render("code") + " from your authenticator app:"
+	}
+
+	code, err := cliui.Prompt(inv, cliui.PromptOptions{
+		Text:     codeText,
+		Validate: cliui.ValidateNotEmpty,
+	})
+	if err != nil {
+		return "", xerrors.Errorf("authentication code prompt: %w", err)
+	}
+	code = strings.TrimSpace(code)
+	// Codes from authenticator apps are all digits, recovery codes are not.
+	if strings.Trim(code, "0123456789") == "" {
+		req.TOTPCode = code
+	} else {
+		req.RecoveryCode = code
+	}
+
+	resp, err = client.LoginWithPassword(inv.Context(), req)
+	if err != nil {
+		return "", xerrors.Errorf("login with password: %w", err)
+	}
+	if resp.SessionToken == "" {
+		return "", xerrors.New("login did not return a session token")
+	}
+	if len(resp.RecoveryCodes) > 0 {
+		_, _ = fmt.Fprintf(inv.Stdout, Caret+"Store these recovery codes somewhere safe. Each can be used once in place of a code if you lose your authenticator app:\n\n\t%s\n\n", strings.Join(resp.RecoveryCodes, "\n\t"))
+	}
+	return resp.SessionToken, nil
+}
+
+var errDeviceLoginUnsupported = xerrors.New("the deployment does not support device login")
+
+// loginWithDevice returns a session token using the device authorization flow.
+// The user approves the login from a browser on any device, and the token is
+// polled for until they respond or the code expires.
+func loginWithDevice(inv *clibase.Invocation, client *codersdk.Client) (string, error) {
+	auth, err := client.OAuth2DeviceAuthorization(inv.Context(), "")
+	if err != nil {
+		var sdkErr *codersdk.Error
+		if errors.As(err, &sdkErr) && (sdkErr.StatusCode() == http.StatusNotFound || sdkErr.StatusCode() == http.StatusMethodNotAllowed) {
+			return "", errDeviceLoginUnsupported
+		}
+		return "", xerrors.Errorf("start device login: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(inv.Stdout, "Open the following in a browser on any device:\n\n\t%s\n\nAnd enter the code:\n\n\t%s\n\n",
+		auth.VerificationURI, cliui.DefaultStyles.Keyword.Render(auth.UserCode))
+
+	interval := time.Duration(auth.Interval) * time.Second
+	for {
+		select {
+		case <-inv.Context().Done():
+			return "", inv.Context().Err()
+		case <-time.After(interval):
+		}
+
+		token, err := client.OAuth2DeviceToken(inv.Context(), auth.DeviceCode)
+		if err == nil {
+			return token.AccessToken, nil
+		}
+		var oauthErr *codersdk.OAuth2Error
+		if !errors.As(err, &oauthErr) {
+			return "", xerrors.Errorf("device login: %w", err)
+		}
+		switch oauthErr.Code {
+		case codersdk.OAuth2ErrorAuthorizationPending:
+		case codersdk.OAuth2ErrorSlowDown:
+			interval += 5 * time.Second
+		case codersdk.OAuth2ErrorAccessDenied:
+			return "", xerrors.New("the login was denied")
+		case codersdk.OAuth2ErrorExpiredToken:
+			return "", xerrors.New("the code expired, run the command again to get a new one")
+		default:
+			return "", xerrors.Errorf("device login: %w", err)
+		}
+	}
+}
+
 // isWSL determines if coder-cli is running within Windows Subsystem for Linux
 func isWSL() (bool, error) {
 	if runtime.GOOS == goosDarwin || runtime.GOOS == goosWindows {
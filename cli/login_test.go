@@ -3,7 +3,12 @@ package cli_test
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,7 +16,10 @@ import (
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/totp"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
 )
 
 func TestLogin(t *testing.T) {
@@ -208,4 +216,78 @@ func TestLogin(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, client.SessionToken(), sessionFile)
 	})
+	t.Run("PasswordTOTP", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		coderdtest.CreateFirstUser(t, client)
+		ctx := testutil.Context(t, testutil.WaitLong)
+		enrollment, err := client.EnrollTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		code, err := totp.Code(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+		_, err = client.VerifyTOTP(ctx, codersdk.Me, codersdk.VerifyTOTPRequest{Code: code})
+		require.NoError(t, err)
+
+		doneChan := make(chan struct{})
+		root, cfg := clitest.New(t, "login", client.URL.String(),
+			"--email", coderdtest.FirstUserParams.Email,
+			"--password", coderdtest.FirstUserParams.Password,
+		)
+		pty := ptytest.New(t).Attach(root)
+		go func() {
+			defer close(doneChan)
+			err := root.WithContext(ctx).Run()
+			assert.NoError(t, err)
+		}()
+
+		pty.ExpectMatch("authenticator app")
+		code, err = totp.Code(enrollment.Secret, time.Now().Add(totp.Period))
+		require.NoError(t, err)
+		pty.WriteLine(code)
+		pty.ExpectMatch("Welcome to Coder")
+		<-doneChan
+
+		sessionFile, err := cfg.Session().Read()
+		require.NoError(t, err)
+		require.NotEmpty(t, sessionFile)
+	})
+
+	t.Run("Device", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		coderdtest.CreateFirstUser(t, client)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		doneChan := make(chan struct{})
+		root, cfg := clitest.New(t, "login", client.URL.String(), "--device")
+		pty := ptytest.New(t).Attach(root)
+		go func() {
+			defer close(doneChan)
+			err := root.WithContext(ctx).Run()
+			assert.NoError(t, err)
+		}()
+
+		pty.ExpectMatch("enter the code:")
+		var userCode string
+		for userCode == "" {
+			userCode = regexp.MustCompile(`[A-Z]{4}-[A-Z]{4}`).FindString(pty.ReadLine(ctx))
+		}
+		form := url.Values{
+			"user_code": {userCode},
+			"action":    {"approve"},
+		}
+		res, err := client.Request(ctx, http.MethodPost, "/oauth2/device", strings.NewReader(form.Encode()), func(r *http.Request) {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		})
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		pty.ExpectMatch("Welcome to Coder")
+		<-doneChan
+
+		sessionFile, err := cfg.Session().Read()
+		require.NoError(t, err)
+		require.NotEmpty(t, sessionFile)
+	})
 }
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
+)
+
+type netcheckResult struct {
+	Client     tailnet.NetcheckReport     `json:"client"`
+	Workspace  *tailnet.NetcheckReport    `json:"workspace,omitempty"`
+	Connection *netcheckConnection        `json:"connection,omitempty"`
+	Diagnosis  *tailnet.NetcheckDiagnosis `json:"diagnosis,omitempty"`
+	DERPMap    *tailcfg.DERPMap           `json:"-"`
+}
+
+type netcheckConnection struct {
+	Direct        bool   `json:"direct"`
+	Endpoint      string `json:"endpoint,omitempty"`
+	DERPRegionID  int    `json:"derp_region_id,omitempty"`
+	LatencyMillis int64  `json:"latency_ms"`
+	Error         string `json:"error,omitempty"`
+}
+
+func (r *RootCmd) netcheck() *clibase.Cmd {
+	var pingTimeout time.Duration
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			result, ok := data.(netcheckResult)
+			if !ok {
+				return nil, xerrors.Errorf("expected type %T, got %T", result, data)
+			}
+			return renderNetcheck(result), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "netcheck [workspace]",
+		Short:       "Diagnose the network between your machine and a workspace",
+		Long: "Probes UDP reachability, NAT behavior, DERP latency and interface MTUs. " +
+			"When a workspace is given, the workspace runs the same checks, and the reasons a " +
+			"connection is relayed through DERP instead of direct are explained.",
+		Middleware: clibase.Chain(
+			clibase.RequireRangeArgs(0, 1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			logger, ok := LoggerFromContext(ctx)
+			if !ok {
+				logger = slog.Make(sloghuman.Sink(inv.Stderr))
+			}
+			if r.verbose {
+				logger = logger.Leveled(slog.LevelDebug)
+			}
+
+			var result netcheckResult
+			if len(inv.Args) == 0 {
+				derpMap, err := userDERPMap(ctx, client)
+				if err != nil {
+					return err
+				}
+				result.DERPMap = derpMap
+				result.Client = tailnet.Netcheck(ctx, logger, derpMap)
+			} else {
+				workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, codersdk.Me, inv.Args[0])
+				if err != nil {
+					return err
+				}
+				err = cliui.Agent(ctx, inv.Stderr, cliui.AgentOptions{
+					WorkspaceName: workspace.Name,
+					Fetch: func(ctx context.Context) (codersdk.WorkspaceAgent, error) {
+						return client.WorkspaceAgent(ctx, workspaceAgent.ID)
+					},
+					Wait: false,
+				})
+				if err != nil && !xerrors.Is(err, cliui.AgentStartError) {
+					return xerrors.Errorf("await agent: %w", err)
+				}
+				conn, err := client.DialWorkspaceAgent(ctx, workspaceAgent.ID, &codersdk.DialWorkspaceAgentOptions{
+					Logger: logger,
+				})
+				if err != nil {
+					return err
+				}
+				defer conn.Close()
+				result.DERPMap = conn.DERPMap()
+
+				// Both netchecks run at the same time, since each takes a
+				// few seconds.
+				agentReport := make(chan tailnet.NetcheckReport, 1)
+				go func() {
+					report, err := conn.Netcheck(ctx)
+					if err != nil {
+						report = tailnet.NetcheckReport{Error: err.Error()}
+					}
+					agentReport <- report
+				}()
+				result.Client = tailnet.Netcheck(ctx, logger, result.DERPMap)
+				workspaceReport := <-agentReport
+				result.Workspace = &workspaceReport
+				result.Connection = netcheckPing(ctx, conn, pingTimeout)
+				diagnosis := tailnet.DiagnoseNetcheck(result.Client, workspaceReport)
+				result.Diagnosis = &diagnosis
+			}
+
+			out, err := formatter.Format(ctx, result)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "timeout",
+			FlagShorthand: "t",
+			Default:       "10s",
+			Description:   "Specifies how long to wait for a direct connection to the workspace.",
+			Value:         clibase.DurationOf(&pingTimeout),
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+// userDERPMap returns the DERP map used to connect to workspaces.
+func userDERPMap(ctx context.Context, client *codersdk.Client) (*tailcfg.DERPMap, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := client.DERPMapUpdates(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get derp map: %w", err)
+	}
+	derpMap, ok := <-updates
+	if !ok {
+		return nil, xerrors.New("derp map stream closed")
+	}
+	return derpMap, nil
+}
+
+// netcheckPing pings the workspace until the connection is direct or the
+// timeout is reached, and returns the path of the last pong.
+func netcheckPing(ctx context.Context, conn *codersdk.WorkspaceAgentConn, timeout time.Duration) *netcheckConnection {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	connection := &netcheckConnection{}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		dur, p2p, pong, err := conn.Ping(ctx)
+		if err == nil {
+			connection.Error = ""
+			connection.Direct = p2p
+			connection.LatencyMillis = dur.Milliseconds()
+			if p2p {
+				connection.Endpoint = pong.Endpoint
+				return connection
+			}
+			connection.DERPRegionID = pong.DERPRegionID
+		} else if connection.LatencyMillis == 0 {
+			connection.Error = err.Error()
+		}
+
+		select {
+		case <-ctx.Done():
+			return connection
+		case <-ticker.C:
+		}
+	}
+}
+
+func renderNetcheck(result netcheckResult) string {
+	var b strings.Builder
+	renderNetcheckReport(&b, "Client", result.Client, result.DERPMap)
+	if result.Workspace != nil {
+		_, _ = fmt.Fprintln(&b)
+		renderNetcheckReport(&b, "Workspace", *result.Workspace, result.DERPMap)
+	}
+	if result.Connection != nil {
+		_, _ = fmt.Fprintln(&b)
+		_, _ = fmt.Fprintln(&b, cliui.DefaultStyles.Bold.Render("Connection"))
+		switch {
+		case result.Connection.Direct:
+			netcheckField(&b, "Path", fmt.Sprintf("%s via %s in %dms",
+				cliui.DefaultStyles.Fuchsia.Render("p2p"),
+				cliui.DefaultStyles.Code.Render(result.Connection.Endpoint),
+				result.Connection.LatencyMillis,
+			))
+		case result.Connection.Error != "":
+			netcheckField(&b, "Path", fmt.Sprintf("unreachable: %s", result.Connection.Error))
+		default:
+			netcheckField(&b, "Path", fmt.Sprintf("%s via %s in %dms",
+				cliui.DefaultStyles.Fuchsia.Render("proxied"),
+				cliui.DefaultStyles.Code.Render(fmt.Sprintf("DERP(%s)", derpRegionName(result.DERPMap, result.Connection.DERPRegionID))),
+				result.Connection.LatencyMillis,
+			))
+		}
+	}
+	if result.Diagnosis != nil {
+		_, _ = fmt.Fprintln(&b)
+		_, _ = fmt.Fprintln(&b, cliui.DefaultStyles.Bold.Render("Diagnosis"))
+		for _, blocker := range result.Diagnosis.Blockers {
+			_, _ = fmt.Fprintf(&b, "  %s %s\n", cliui.DefaultStyles.Crossmark.String(), blocker)
+		}
+		for _, warning := range result.Diagnosis.Warnings {
+			_, _ = fmt.Fprintf(&b, "  %s %s\n", cliui.DefaultStyles.Warn.Render("!"), warning)
+		}
+		if len(result.Diagnosis.Blockers) == 0 {
+			if result.Connection != nil && !result.Connection.Direct {
+				_, _ = fmt.Fprintln(&b, "  No reason for relaying was found. A firewall may be dropping inbound UDP on one side, or direct connections are disabled.")
+			} else {
+				_, _ = fmt.Fprintf(&b, "  %s Nothing prevents a direct connection.\n", cliui.DefaultStyles.Checkmark.String())
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func renderNetcheckReport(b *strings.Builder, title string, report tailnet.NetcheckReport, derpMap *tailcfg.DERPMap) {
+	_, _ = fmt.Fprintln(b, cliui.DefaultStyles.Bold.Render(title))
+	if report.Error != "" {
+		netcheckField(b, "Error", report.Error)
+		return
+	}
+	netcheckField(b, "UDP", yesNo(report.UDP))
+	netcheckField(b, "IPv4", netcheckAddress(report.IPv4, report.GlobalV4))
+	netcheckField(b, "IPv6", netcheckAddress(report.IPv6, report.GlobalV6))
+
+	natMapping := "unknown"
+	if report.MappingVariesByDestIP != nil {
+		natMapping = "same port for every destination (easy NAT)"
+		if *report.MappingVariesByDestIP {
+			natMapping = "different port for every destination (hard NAT)"
+		}
+	}
+	netcheckField(b, "NAT mapping", natMapping)
+
+	var portMapping []string
+	for name, supported := range map[string]*bool{"UPnP": report.UPnP, "NAT-PMP": report.PMP, "PCP": report.PCP} {
+		if supported != nil && *supported {
+			portMapping = append(portMapping, name)
+		}
+	}
+	sort.Strings(portMapping)
+	if len(portMapping) == 0 {
+		portMapping = []string{"none"}
+	}
+	netcheckField(b, "Port mapping", strings.Join(portMapping, ", "))
+
+	captivePortal := "unknown"
+	if report.CaptivePortal != nil {
+		captivePortal = yesNo(*report.CaptivePortal)
+	}
+	netcheckField(b, "Captive portal", captivePortal)
+
+	preferredDERP := "none"
+	if report.PreferredDERP != 0 {
+		preferredDERP = fmt.Sprintf("%s (%dms)", derpRegionName(derpMap, report.PreferredDERP), report.RegionLatencyMillis[report.PreferredDERP])
+	}
+	netcheckField(b, "Preferred DERP", preferredDERP)
+
+	for _, iface := range report.Interfaces {
+		netcheckField(b, "Interface", fmt.Sprintf("%s (MTU %d)", iface.Name, iface.MTU))
+	}
+}
+
+func netcheckField(b *strings.Builder, name, value string) {
+	_, _ = fmt.Fprintf(b, "  %-16s %s\n", name+":", value)
+}
+
+func netcheckAddress(ok bool, address string) string {
+	if !ok {
+		return "no"
+	}
+	if address == "" {
+		return "yes"
+	}
+	return fmt.Sprintf("yes, %s", address)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func derpRegionName(derpMap *tailcfg.DERPMap, regionID int) string {
+	if derpMap != nil {
+		if region, ok := derpMap.Regions[regionID]; ok {
+			return region.RegionName
+		}
+	}
+	return fmt.Sprintf("unknown region %d", regionID)
+}
@@ -0,0 +1,86 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestNetcheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Local", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		inv, root := clitest.New(t, "netcheck", "--output", "json")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		var result map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+		require.Contains(t, result, "client")
+		require.NotContains(t, result, "workspace")
+	})
+
+	t.Run("Workspace", func(t *testing.T) {
+		t.Parallel()
+
+		client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(agentToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent"),
+		})
+		defer func() {
+			_ = agentCloser.Close()
+		}()
+		coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+		inv, root := clitest.New(t, "netcheck", workspace.Name, "--timeout", "1s", "--output", "json")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		var result struct {
+			Workspace *struct {
+				Error string `json:"error"`
+			} `json:"workspace"`
+			Connection *struct {
+				Error string `json:"error"`
+			} `json:"connection"`
+			Diagnosis *struct {
+				Blockers []string `json:"blockers"`
+			} `json:"diagnosis"`
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+		require.NotNil(t, result.Workspace)
+		require.NotContains(t, result.Workspace.Error, "do request")
+		require.NotNil(t, result.Connection)
+		require.Empty(t, result.Connection.Error)
+		require.NotNil(t, result.Diagnosis)
+	})
+}
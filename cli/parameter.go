@@ -28,27 +28,33 @@ func createParameterMapFromFile(parameterFile string) (map[string]string, error)
 			return nil, err
 		}
 
-		parameterMap := map[string]string{}
-		for k, v := range mapStringInterface {
-			switch val := v.(type) {
-			case string, bool, int:
-				parameterMap[k] = fmt.Sprintf("%v", val)
-			case []interface{}:
-				b, err := json.Marshal(&val)
-				if err != nil {
-					return nil, err
-				}
-				parameterMap[k] = string(b)
-			default:
-				return nil, xerrors.Errorf("invalid parameter type: %T", v)
-			}
-		}
-		return parameterMap, nil
+		return parameterMapFromInterfaces(mapStringInterface)
 	}
 
 	return nil, xerrors.Errorf("Parameter file name is not specified")
 }
 
+// parameterMapFromInterfaces converts values decoded from YAML to the string
+// representation of rich parameter values.
+func parameterMapFromInterfaces(mapStringInterface map[string]interface{}) (map[string]string, error) {
+	parameterMap := map[string]string{}
+	for k, v := range mapStringInterface {
+		switch val := v.(type) {
+		case string, bool, int:
+			parameterMap[k] = fmt.Sprintf("%v", val)
+		case []interface{}:
+			b, err := json.Marshal(&val)
+			if err != nil {
+				return nil, err
+			}
+			parameterMap[k] = string(b)
+		default:
+			return nil, xerrors.Errorf("invalid parameter type: %T", v)
+		}
+	}
+	return parameterMap, nil
+}
+
 func getWorkspaceBuildParameterValueFromMapOrInput(inv *clibase.Invocation, parameterMap map[string]string, templateVersionParameter codersdk.TemplateVersionParameter) (*codersdk.WorkspaceBuildParameter, error) {
 	var parameterValue string
 	var err error
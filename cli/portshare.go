@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) portShare() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "port",
+		Short: "Share workspace ports with other users",
+		Long: "Shared ports are reachable through their subdomain application URL by the users they are shared with. " +
+			"Templates limit how widely ports may be shared.\n" + formatExamples(
+			example{
+				Description: "Share port 3000 with every signed in user for two hours",
+				Command:     "coder port share my-workspace 3000 --level authenticated --expires 2h",
+			},
+			example{
+				Description: "Share port 8080 of the agent \"main\" with the members of a group",
+				Command:     "coder port share my-workspace 8080 --agent main --level group:product",
+			},
+			example{
+				Description: "Stop sharing port 3000",
+				Command:     "coder port unshare my-workspace 3000",
+			},
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.portShareList(),
+			r.portShareShare(),
+			r.portShareUnshare(),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) portShareShare() *clibase.Cmd {
+	var (
+		agentName string
+		level     string
+		expires   time.Duration
+	)
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "share <workspace> <port>",
+		Short: "Share a port of a workspace",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+			workspace, err := namedWorkspace(ctx, client, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+			port, err := parsePortShareNumber(inv.Args[1])
+			if err != nil {
+				return err
+			}
+
+			req := codersdk.UpsertWorkspacePortShareRequest{
+				AgentName:  agentName,
+				Port:       port,
+				ShareLevel: codersdk.WorkspacePortShareLevel(level),
+			}
+			if groupName, ok := strings.CutPrefix(level, "group:"); ok {
+				group, err := client.GroupByOrgAndName(ctx, workspace.OrganizationID, groupName)
+				if err != nil {
+					return xerrors.Errorf("get group %q: %w", groupName, err)
+				}
+				req.ShareLevel = codersdk.WorkspacePortShareLevelGroup
+				req.GroupID = &group.ID
+			}
+			if expires > 0 {
+				expiresAt := time.Now().Add(expires)
+				req.ExpiresAt = &expiresAt
+			}
+
+			share, err := client.UpsertWorkspacePortShare(ctx, workspace.ID, req)
+			if err != nil {
+				return xerrors.Errorf("share port: %w", err)
+			}
+
+			sharedWith := fmt.Sprintf("%s users", share.ShareLevel)
+			if share.ShareLevel == codersdk.WorkspacePortShareLevelGroup {
+				sharedWith = fmt.Sprintf("the members of the group %q", strings.TrimPrefix(level, "group:"))
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Port %s of agent %s is shared with %s", cliui.DefaultStyles.Keyword.Render(strconv.Itoa(int(share.Port))), cliui.DefaultStyles.Keyword.Render(share.AgentName), sharedWith)
+			if share.ExpiresAt != nil {
+				_, _ = fmt.Fprintf(inv.Stdout, " until %s", cliui.DefaultStyles.DateTimeStamp.Render(share.ExpiresAt.Local().Format(time.Stamp)))
+			}
+			_, _ = fmt.Fprintln(inv.Stdout, ".")
+
+			appHost, err := client.AppHost(ctx)
+			if err == nil && appHost.Host != "" {
+				appURL := httpapi.ApplicationURL{
+					AppSlugOrPort: strconv.Itoa(int(share.Port)),
+					AgentName:     share.AgentName,
+					WorkspaceName: workspace.Name,
+					Username:      workspace.OwnerName,
+				}
+				// The host is a wildcard, e.g. "*.apps.coder.com".
+				_, _ = fmt.Fprintf(inv.Stdout, "%s://%s.%s\n", client.URL.Scheme, appURL.String(), strings.TrimPrefix(appHost.Host, "*."))
+			}
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "agent",
+			Description: "The agent the port belongs to. Required if the workspace has more than one agent.",
+			Value:       clibase.StringOf(&agentName),
+		},
+		{
+			Flag:        "level",
+			Description: `Who to share the port with: "authenticated", "public" or "group:<name>".`,
+			Default:     string(codersdk.WorkspacePortShareLevelAuthenticated),
+			Value:       clibase.StringOf(&level),
+		},
+		{
+			Flag:        "expires",
+			Description: "Stop sharing the port after this duration. The port is shared until it is unshared if unset.",
+			Value:       clibase.DurationOf(&expires),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) portShareUnshare() *clibase.Cmd {
+	var agentName string
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "unshare <workspace> <port>",
+		Short: "Stop sharing a port of a workspace",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+			workspace, err := namedWorkspace(ctx, client, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+			port, err := parsePortShareNumber(inv.Args[1])
+			if err != nil {
+				return err
+			}
+
+			err = client.DeleteWorkspacePortShare(ctx, workspace.ID, codersdk.DeleteWorkspacePortShareRequest{
+				AgentName: agentName,
+				Port:      port,
+			})
+			if err != nil {
+				return xerrors.Errorf("unshare port: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Port %s is no longer shared.\n", cliui.DefaultStyles.Keyword.Render(strconv.Itoa(int(port))))
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "agent",
+			Description: "The agent the port belongs to. Required if the workspace has more than one agent.",
+			Value:       clibase.StringOf(&agentName),
+		},
+	}
+	return cmd
+}
+
+type portShareRow struct {
+	codersdk.WorkspacePortShare `table:"-"`
+
+	AgentName  string `json:"-" table:"agent,default_sort"`
+	Port       int32  `json:"-" table:"port"`
+	ShareLevel string `json:"-" table:"level"`
+	ExpiresAt  string `json:"-" table:"expires at"`
+}
+
+func (r *RootCmd) portShareList() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]portShareRow{}, []string{"agent", "port", "level", "expires at"}),
+		cliui.JSONFormat(),
+	)
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "list <workspace>",
+		Aliases: []string{"ls"},
+		Short:   "List the shared ports of a workspace",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+			workspace, err := namedWorkspace(ctx, client, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+			shares, err := client.WorkspacePortShares(ctx, workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("list port shares: %w", err)
+			}
+			if len(shares) == 0 {
+				cliui.Infof(inv.Stderr, "No ports of %s are shared.\n", workspace.Name)
+				return nil
+			}
+
+			rows := make([]portShareRow, 0, len(shares))
+			for _, share := range shares {
+				row := portShareRow{
+					WorkspacePortShare: share,
+					AgentName:          share.AgentName,
+					Port:               share.Port,
+					ShareLevel:         string(share.ShareLevel),
+					ExpiresAt:          "never",
+				}
+				if share.ExpiresAt != nil {
+					row.ExpiresAt = share.ExpiresAt.Local().Format(time.Stamp)
+					if !share.ExpiresAt.After(time.Now()) {
+						row.ExpiresAt += " (expired)"
+					}
+				}
+				rows = append(rows, row)
+			}
+
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func parsePortShareNumber(s string) (int32, error) {
+	port, err := strconv.ParseUint(s, 10, 16)
+	if err != nil || port == 0 {
+		return 0, xerrors.Errorf("invalid port %q: must be between 1 and 65535", s)
+	}
+	return int32(port), nil
+}
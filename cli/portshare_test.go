@@ -0,0 +1,77 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestPortShare(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+		AppHostname:              "*.apps.coder.com",
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  echo.ProvisionComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(uuid.NewString()),
+	})
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	inv, root := clitest.New(t, "port", "share", workspace.Name, "3000", "--expires", "2h")
+	clitest.SetupConfig(t, client, root)
+	var out bytes.Buffer
+	inv.Stdout = &out
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "is shared with authenticated users until")
+	require.Contains(t, out.String(), "://3000--example--"+workspace.Name+"--"+workspace.OwnerName+".apps.coder.com")
+
+	shares, err := client.WorkspacePortShares(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	require.Equal(t, codersdk.WorkspacePortShareLevelAuthenticated, shares[0].ShareLevel)
+	require.NotNil(t, shares[0].ExpiresAt)
+
+	// The template only allows sharing with authenticated users.
+	inv, root = clitest.New(t, "port", "share", workspace.Name, "3000", "--level", "public")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.ErrorContains(t, err, "only allows sharing ports up to the level")
+
+	inv, root = clitest.New(t, "port", "list", workspace.Name)
+	clitest.SetupConfig(t, client, root)
+	out.Reset()
+	inv.Stdout = &out
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "3000")
+	require.Contains(t, out.String(), "authenticated")
+
+	inv, root = clitest.New(t, "port", "unshare", workspace.Name, "3000")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	shares, err = client.WorkspacePortShares(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Empty(t, shares)
+}
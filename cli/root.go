@@ -17,6 +17,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"text/tabwriter"
 	"time"
@@ -81,6 +82,7 @@ func (r *RootCmd) Core() []*clibase.Cmd {
 		r.portForward(),
 		r.publickey(),
 		r.resetPassword(),
+		r.sessions(),
 		r.state(),
 		r.templates(),
 		r.users(),
@@ -92,7 +94,9 @@ func (r *RootCmd) Core() []*clibase.Cmd {
 		r.create(),
 		r.deleteWorkspace(),
 		r.list(),
+		r.netcheck(),
 		r.ping(),
+		r.portShare(),
 		r.rename(),
 		r.scaletest(),
 		r.schedules(),
@@ -468,6 +472,21 @@ func addTelemetryHeader(client *codersdk.Client, inv *clibase.Invocation) {
 	transport.header.Add(codersdk.CLITelemetryHeader, s)
 }
 
+// addImpersonationWarning makes the client warn on stderr when the session
+// token impersonates a user, so the owner is reminded on every command.
+func addImpersonationWarning(client *codersdk.Client, inv *clibase.Invocation) {
+	// The warning goes underneath the headerTransport, which also provides
+	// the headers of connections that don't use the HTTP client.
+	transport, ok := client.HTTPClient.Transport.(*headerTransport)
+	if !ok {
+		return
+	}
+	transport.transport = &impersonationTransport{
+		transport: transport.transport,
+		stderr:    inv.Stderr,
+	}
+}
+
 // InitClient sets client to a new client.
 // It reads from global configuration files if flags are not set.
 func (r *RootCmd) InitClient(client *codersdk.Client) clibase.MiddlewareFunc {
@@ -515,6 +534,7 @@ func (r *RootCmd) InitClient(client *codersdk.Client) clibase.MiddlewareFunc {
 			}
 
 			addTelemetryHeader(client, inv)
+			addImpersonationWarning(client, inv)
 
 			client.SetSessionToken(r.token)
 
@@ -773,6 +793,29 @@ func (h *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return h.transport.RoundTrip(req)
 }
 
+type impersonationTransport struct {
+	transport http.RoundTripper
+	stderr    io.Writer
+	once      sync.Once
+}
+
+func (t *impersonationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	impersonator := res.Header.Get(codersdk.ImpersonatorHeader)
+	if impersonator != "" {
+		t.once.Do(func() {
+			_, _ = fmt.Fprintln(t.stderr, cliui.DefaultStyles.Warn.Render(fmt.Sprintf(
+				"You are impersonating this user as %q. Your actions are recorded in the audit log.",
+				impersonator,
+			)))
+		})
+	}
+	return res, nil
+}
+
 // DumpHandler provides a custom SIGQUIT and SIGTRAP handler that dumps the
 // stacktrace of all goroutines to stderr and a well-known file in the home
 // directory. This is useful for debugging deadlock issues that may occur in
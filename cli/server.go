@@ -10,7 +10,6 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
-	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
@@ -542,49 +541,49 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 					return xerrors.Errorf("OIDC issuer URL must be set!")
 				}
 
-				if cfg.OIDC.IgnoreEmailVerified {
-					logger.Warn(ctx, "coder will not check email_verified for OIDC logins")
-				}
-
-				oidcProvider, err := oidc.NewProvider(
-					ctx, cfg.OIDC.IssuerURL.String(),
-				)
-				if err != nil {
-					return xerrors.Errorf("configure oidc provider: %w", err)
-				}
-				redirectURL, err := cfg.AccessURL.Value().Parse("/api/v2/users/oidc/callback")
-				if err != nil {
-					return xerrors.Errorf("parse oidc oauth callback url: %w", err)
-				}
-				// If the scopes contain 'groups', we enable group support.
-				// Do not override any custom value set by the user.
-				if slice.Contains(cfg.OIDC.Scopes, "groups") && cfg.OIDC.GroupField == "" {
-					cfg.OIDC.GroupField = "groups"
-				}
-				options.OIDCConfig = &coderd.OIDCConfig{
-					OAuth2Config: &oauth2.Config{
-						ClientID:     cfg.OIDC.ClientID.String(),
-						ClientSecret: cfg.OIDC.ClientSecret.String(),
-						RedirectURL:  redirectURL.String(),
-						Endpoint:     oidcProvider.Endpoint(),
-						Scopes:       cfg.OIDC.Scopes,
-					},
-					Provider: oidcProvider,
-					Verifier: oidcProvider.Verifier(&oidc.Config{
-						ClientID: cfg.OIDC.ClientID.String(),
-					}),
-					EmailDomain:         cfg.OIDC.EmailDomain,
+				options.OIDCConfig, err = configureOIDC(ctx, logger, cfg.AccessURL.Value(), codersdk.OIDCProviderConfig{
+					IssuerURL:           cfg.OIDC.IssuerURL.String(),
+					ClientID:            cfg.OIDC.ClientID.String(),
+					ClientSecret:        cfg.OIDC.ClientSecret.String(),
+					Scopes:              cfg.OIDC.Scopes,
 					AllowSignups:        cfg.OIDC.AllowSignups.Value(),
+					EmailDomain:         cfg.OIDC.EmailDomain,
+					IgnoreEmailVerified: cfg.OIDC.IgnoreEmailVerified.Value(),
 					UsernameField:       cfg.OIDC.UsernameField.String(),
 					EmailField:          cfg.OIDC.EmailField.String(),
 					AuthURLParams:       cfg.OIDC.AuthURLParams.Value,
 					IgnoreUserInfo:      cfg.OIDC.IgnoreUserInfo.Value(),
 					GroupField:          cfg.OIDC.GroupField.String(),
 					GroupMapping:        cfg.OIDC.GroupMapping.Value,
+					UserRoleField:       cfg.OIDC.UserRoleField.String(),
+					UserRoleMapping:     cfg.OIDC.UserRoleMapping.Value,
+					UserRolesDefault:    cfg.OIDC.UserRolesDefault.Value(),
 					SignInText:          cfg.OIDC.SignInText.String(),
 					IconURL:             cfg.OIDC.IconURL.String(),
-					IgnoreEmailVerified: cfg.OIDC.IgnoreEmailVerified.Value(),
+				})
+				if err != nil {
+					return xerrors.Errorf("configure oidc provider: %w", err)
+				}
+			}
+
+			oidcProviderIDs := map[string]struct{}{}
+			for _, provider := range cfg.OIDC.Providers.Value {
+				if valid := httpapi.NameValid(provider.ID); valid != nil {
+					return xerrors.Errorf("oidc provider %q doesn't have a valid id: %w", provider.ID, valid)
+				}
+				if _, exists := oidcProviderIDs[provider.ID]; exists {
+					return xerrors.Errorf("multiple oidc providers exist with the id %q. specify a unique id for each", provider.ID)
+				}
+				oidcProviderIDs[provider.ID] = struct{}{}
+				if provider.ClientID == "" || provider.IssuerURL == "" {
+					return xerrors.Errorf("oidc provider %q must set a client ID and issuer URL", provider.ID)
+				}
+
+				oidcConfig, err := configureOIDC(ctx, logger, cfg.AccessURL.Value(), provider)
+				if err != nil {
+					return xerrors.Errorf("configure oidc provider %q: %w", provider.ID, err)
 				}
+				options.OIDCProviders = append(options.OIDCProviders, oidcConfig)
 			}
 
 			if cfg.InMemoryDatabase {
@@ -633,42 +632,19 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 					}
 				}
 
-				// Read the app signing key from the DB. We store it hex encoded
-				// since the config table uses strings for the value and we
-				// don't want to deal with automatic encoding issues.
-				appSecurityKeyStr, err := tx.GetAppSecurityKey(ctx)
-				if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
-					return xerrors.Errorf("get app signing key: %w", err)
-				}
-				// If the string in the DB is an invalid hex string or the
-				// length is not equal to the current key length, generate a new
-				// one.
+				// Read the app security keys from the DB, generating a key if
+				// there is none. Keys are rotated in the background by coderd.
 				//
-				// If the key is regenerated, old signed tokens and encrypted
-				// strings will become invalid. New signed app tokens will be
-				// generated automatically on failure. Any workspace app token
-				// smuggling operations in progress may fail, although with a
-				// helpful error.
-				if decoded, err := hex.DecodeString(appSecurityKeyStr); err != nil || len(decoded) != len(workspaceapps.SecurityKey{}) {
-					b := make([]byte, len(workspaceapps.SecurityKey{}))
-					_, err := rand.Read(b)
-					if err != nil {
-						return xerrors.Errorf("generate fresh app signing key: %w", err)
-					}
-
-					appSecurityKeyStr = hex.EncodeToString(b)
-					err = tx.UpsertAppSecurityKey(ctx, appSecurityKeyStr)
-					if err != nil {
-						return xerrors.Errorf("insert freshly generated app signing key to database: %w", err)
-					}
-				}
-
-				appSecurityKey, err := workspaceapps.KeyFromString(appSecurityKeyStr)
+				// If the keys are lost, old signed tokens and encrypted strings
+				// will become invalid. New signed app tokens will be generated
+				// automatically on failure. Any workspace app token smuggling
+				// operations in progress may fail, although with a helpful
+				// error.
+				options.AppSecurityKeys = &workspaceapps.SecurityKeySet{}
+				err = workspaceapps.RefreshSecurityKeys(ctx, tx, options.AppSecurityKeys, workspaceapps.SecurityKeyRotationOptions{})
 				if err != nil {
-					return xerrors.Errorf("decode app signing key from database: %w", err)
+					return xerrors.Errorf("refresh app security keys: %w", err)
 				}
-
-				options.AppSecurityKey = appSecurityKey
 				return nil
 			}, nil)
 			if err != nil {
@@ -771,7 +747,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 
 			if cfg.Prometheus.Enable {
 				// Agent metrics require reference to the tailnet coordinator, so must be initiated after Coder API.
-				closeAgentsFunc, err := prometheusmetrics.Agents(ctx, logger, options.PrometheusRegistry, coderAPI.Database, &coderAPI.TailnetCoordinator, options.DERPMap, coderAPI.Options.AgentInactiveDisconnectTimeout, 0)
+				closeAgentsFunc, err := prometheusmetrics.Agents(ctx, logger, options.PrometheusRegistry, coderAPI.Database, &coderAPI.TailnetCoordinator, &coderAPI.DERPMapProvider, coderAPI.Options.AgentInactiveDisconnectTimeout, 0)
 				if err != nil {
 					return xerrors.Errorf("register agents prometheus metric: %w", err)
 				}
@@ -1544,6 +1520,61 @@ func configureGithubOAuth2(accessURL *url.URL, clientID, clientSecret string, al
 }
 
 // embeddedPostgresURL returns the URL for the embedded PostgreSQL deployment.
+// configureOIDC discovers the OpenID Connect provider and returns the config
+// coderd uses to log users in with it. An empty provider ID configures the
+// primary provider.
+func configureOIDC(ctx context.Context, logger slog.Logger, accessURL *url.URL, provider codersdk.OIDCProviderConfig) (*coderd.OIDCConfig, error) {
+	if provider.IgnoreEmailVerified {
+		logger.Warn(ctx, "coder will not check email_verified for OIDC logins", slog.F("provider", provider.ID))
+	}
+
+	oidcProvider, err := oidc.NewProvider(ctx, provider.IssuerURL)
+	if err != nil {
+		return nil, xerrors.Errorf("discover provider: %w", err)
+	}
+	callbackPath := "/api/v2/users/oidc/callback"
+	if provider.ID != "" {
+		callbackPath = fmt.Sprintf("/api/v2/users/oidc/%s/callback", provider.ID)
+	}
+	redirectURL, err := accessURL.Parse(callbackPath)
+	if err != nil {
+		return nil, xerrors.Errorf("parse oidc oauth callback url: %w", err)
+	}
+	// If the scopes contain 'groups', we enable group support.
+	// Do not override any custom value set by the user.
+	if slice.Contains(provider.Scopes, "groups") && provider.GroupField == "" {
+		provider.GroupField = "groups"
+	}
+	return &coderd.OIDCConfig{
+		OAuth2Config: &oauth2.Config{
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  redirectURL.String(),
+			Endpoint:     oidcProvider.Endpoint(),
+			Scopes:       provider.Scopes,
+		},
+		ID:       provider.ID,
+		Provider: oidcProvider,
+		Verifier: oidcProvider.Verifier(&oidc.Config{
+			ClientID: provider.ClientID,
+		}),
+		EmailDomain:         provider.EmailDomain,
+		AllowSignups:        provider.AllowSignups,
+		UsernameField:       provider.UsernameField,
+		EmailField:          provider.EmailField,
+		AuthURLParams:       provider.AuthURLParams,
+		IgnoreUserInfo:      provider.IgnoreUserInfo,
+		GroupField:          provider.GroupField,
+		GroupMapping:        provider.GroupMapping,
+		UserRoleField:       provider.UserRoleField,
+		UserRoleMapping:     provider.UserRoleMapping,
+		UserRolesDefault:    provider.UserRolesDefault,
+		SignInText:          provider.SignInText,
+		IconURL:             provider.IconURL,
+		IgnoreEmailVerified: provider.IgnoreEmailVerified,
+	}, nil
+}
+
 func embeddedPostgresURL(cfg config.Root) (string, error) {
 	pgPassword, err := cfg.PostgresPassword().Read()
 	if errors.Is(err, os.ErrNotExist) {
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) sessions() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "sessions",
+		Short: "Manage the sessions you are logged in with",
+		Long: "Sessions are created by logging in to the dashboard or the CLI. Revoke sessions on devices you no longer use.\n" + formatExamples(
+			example{
+				Description: "List your sessions",
+				Command:     "coder sessions ls",
+			},
+			example{
+				Description: "Revoke a session by ID",
+				Command:     "coder sessions revoke WuoWs4ZsMX",
+			},
+			example{
+				Description: "Revoke all sessions except the current one",
+				Command:     "coder sessions revoke --others",
+			},
+			example{
+				Description: "Revoke all sessions of another user",
+				Command:     "coder sessions revoke --others --user example_user",
+			},
+		),
+		Aliases: []string{"session"},
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.listSessions(),
+			r.revokeSessions(),
+		},
+	}
+	return cmd
+}
+
+// sessionListRow is the type provided to the OutputFormatter.
+type sessionListRow struct {
+	// For JSON format:
+	codersdk.Session `table:"-"`
+
+	// For table format:
+	ID        string    `json:"-" table:"id"`
+	LoginType string    `json:"-" table:"login type"`
+	IPAddress string    `json:"-" table:"ip address"`
+	UserAgent string    `json:"-" table:"user agent"`
+	LastUsed  time.Time `json:"-" table:"last used,default_sort"`
+	ExpiresAt time.Time `json:"-" table:"expires at"`
+	CreatedAt time.Time `json:"-" table:"created at"`
+	Current   bool      `json:"-" table:"current"`
+}
+
+func sessionListRowFromSession(session codersdk.Session) sessionListRow {
+	loginType := string(session.LoginType)
+	if session.Scope == codersdk.APIKeyScopeApplicationConnect {
+		loginType += " (apps)"
+	}
+	return sessionListRow{
+		Session:   session,
+		ID:        session.ID,
+		LoginType: loginType,
+		IPAddress: session.IPAddress,
+		UserAgent: session.UserAgent,
+		LastUsed:  session.LastUsed,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+		Current:   session.Current,
+	}
+}
+
+func (r *RootCmd) listSessions() *clibase.Cmd {
+	var (
+		user      string
+		formatter = cliui.NewOutputFormatter(
+			cliui.TableFormat([]sessionListRow{}, []string{"id", "login type", "ip address", "user agent", "last used", "current"}),
+			cliui.JSONFormat(),
+		)
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List sessions",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			sessions, err := client.Sessions(inv.Context(), user)
+			if err != nil {
+				return xerrors.Errorf("list sessions: %w", err)
+			}
+
+			if len(sessions) == 0 {
+				cliui.Infof(
+					inv.Stdout,
+					"No sessions found.\n",
+				)
+			}
+
+			rows := make([]sessionListRow, len(sessions))
+			for i, session := range sessions {
+				rows[i] = sessionListRowFromSession(session)
+			}
+
+			out, err := formatter.Format(inv.Context(), rows)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		sessionsUserOption(&user),
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) revokeSessions() *clibase.Cmd {
+	var (
+		user   string
+		others bool
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "revoke [id]",
+		Aliases: []string{"rm"},
+		Short:   "Revoke a session, or all sessions except the current one",
+		Middleware: clibase.Chain(
+			clibase.RequireRangeArgs(0, 1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			switch {
+			case others && len(inv.Args) > 0:
+				return xerrors.New("specify either a session ID or --others, not both")
+			case others:
+				err := client.RevokeOtherSessions(inv.Context(), user)
+				if err != nil {
+					return xerrors.Errorf("revoke sessions: %w", err)
+				}
+				cliui.Infof(inv.Stdout, "All other sessions have been revoked.")
+				return nil
+			case len(inv.Args) == 0:
+				return xerrors.New("specify a session ID or --others")
+			}
+
+			err := client.RevokeSession(inv.Context(), user, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("revoke session %s: %w", inv.Args[0], err)
+			}
+			cliui.Infof(inv.Stdout, "Session has been revoked.")
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		sessionsUserOption(&user),
+		{
+			Flag:        "others",
+			Description: "Revoke all sessions except the one used by this command. When revoking the sessions of another user, all of them are revoked.",
+			Value:       clibase.BoolOf(&others),
+		},
+	}
+	return cmd
+}
+
+func sessionsUserOption(user *string) clibase.Option {
+	return clibase.Option{
+		Flag:          "user",
+		FlagShorthand: "u",
+		Description:   "The user whose sessions to manage. Managing the sessions of other users requires the Owner role.",
+		Default:       codersdk.Me,
+		Value:         clibase.StringOf(user),
+	}
+}
@@ -0,0 +1,64 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	other := codersdk.New(client.URL)
+	login, err := other.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+		Email:    coderdtest.FirstUserParams.Email,
+		Password: coderdtest.FirstUserParams.Password,
+	})
+	require.NoError(t, err)
+	other.SetSessionToken(login.SessionToken)
+
+	inv, root := clitest.New(t, "sessions", "ls")
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	res := buf.String()
+	require.Contains(t, res, "IP ADDRESS")
+	require.Contains(t, res, "USER AGENT")
+	require.Contains(t, res, "LAST USED")
+
+	inv, root = clitest.New(t, "sessions", "ls", "--output=json")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	var sessions []codersdk.Session
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &sessions))
+	require.Len(t, sessions, 2)
+
+	inv, root = clitest.New(t, "sessions", "revoke", "--others")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "revoked")
+
+	_, err = other.User(ctx, codersdk.Me)
+	require.Error(t, err)
+	_, err = client.User(ctx, codersdk.Me)
+	require.NoError(t, err)
+}
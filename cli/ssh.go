@@ -126,6 +126,7 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 			if err != nil {
 				return err
 			}
+			warnIfDeprecatedVersion(inv, client, workspace, workspace.LatestBuild.TemplateVersionID)
 
 			// Select the startup script behavior based on template configuration or flags.
 			var wait bool
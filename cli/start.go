@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/codersdk"
@@ -40,8 +42,23 @@ func (r *RootCmd) start() *clibase.Cmd {
 			}
 
 			_, _ = fmt.Fprintf(inv.Stdout, "\nThe %s workspace has been started at %s!\n", cliui.DefaultStyles.Keyword.Render(workspace.Name), cliui.DefaultStyles.DateTimeStamp.Render(time.Now().Format(time.Stamp)))
+			warnIfDeprecatedVersion(inv, client, workspace, build.TemplateVersionID)
 			return nil
 		},
 	}
 	return cmd
 }
+
+// warnIfDeprecatedVersion warns the user if the workspace was built from a
+// deprecated template version. Errors are ignored since the warning is only
+// informational.
+func warnIfDeprecatedVersion(inv *clibase.Invocation, client *codersdk.Client, workspace codersdk.Workspace, versionID uuid.UUID) {
+	version, err := client.TemplateVersion(inv.Context(), versionID)
+	if err != nil || version.State != codersdk.TemplateVersionStateDeprecated {
+		return
+	}
+	cliui.Warn(inv.Stderr,
+		fmt.Sprintf("The %s workspace is running version %s of the %s template, which is deprecated.", workspace.Name, version.Name, workspace.TemplateName),
+		fmt.Sprintf("Run %q to update to the active version.", "coder update "+workspace.Name),
+	)
+}
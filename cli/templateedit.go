@@ -25,6 +25,8 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 		allowUserCancelWorkspaceJobs bool
 		allowUserAutostart           bool
 		allowUserAutostop            bool
+		requireActiveVersion         bool
+		maxPortShareLevel            string
 	)
 	client := new(codersdk.Client)
 
@@ -84,6 +86,11 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 				AllowUserCancelWorkspaceJobs: allowUserCancelWorkspaceJobs,
 				AllowUserAutostart:           allowUserAutostart,
 				AllowUserAutostop:            allowUserAutostop,
+				RequireActiveVersion:         requireActiveVersion,
+			}
+			if maxPortShareLevel != "" {
+				level := codersdk.WorkspacePortShareLevel(maxPortShareLevel)
+				req.MaxPortShareLevel = &level
 			}
 
 			_, err = client.UpdateTemplateMeta(inv.Context(), template.ID, req)
@@ -156,6 +163,17 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 			Default:     "true",
 			Value:       clibase.BoolOf(&allowUserAutostop),
 		},
+		{
+			Flag:        "require-active-version",
+			Description: "Require workspaces to be started on the active template version. Template managers may still start other versions.",
+			Default:     "false",
+			Value:       clibase.BoolOf(&requireActiveVersion),
+		},
+		{
+			Flag:        "max-port-share-level",
+			Description: "The least restrictive level workspace owners may share ports with. Left unchanged if unset.",
+			Value:       clibase.EnumOf(&maxPortShareLevel, "owner", "group", "authenticated", "public"),
+		},
 		cliui.SkipPromptOption(),
 	}
 
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"sort"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// templatePresetFile is a preset as written in the file passed to
+// "--presets-file".
+type templatePresetFile struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Parameters  map[string]interface{} `yaml:"parameters"`
+}
+
+// loadTemplateVersionPresetsFromFile reads the presets of a template version
+// from a YAML file. The file is a list of presets, each with a name, an
+// optional description and a map of parameter names to values.
+func loadTemplateVersionPresetsFromFile(presetsFile string) ([]codersdk.TemplateVersionPresetRequest, error) {
+	presetsFileContents, err := os.ReadFile(presetsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var presetsFromFile []templatePresetFile
+	err = yaml.Unmarshal(presetsFileContents, &presetsFromFile)
+	if err != nil {
+		return nil, xerrors.Errorf("parse presets file: %w", err)
+	}
+
+	presets := make([]codersdk.TemplateVersionPresetRequest, 0, len(presetsFromFile))
+	for _, preset := range presetsFromFile {
+		if preset.Name == "" {
+			return nil, xerrors.New("every preset must have a name")
+		}
+		parameterMap, err := parameterMapFromInterfaces(preset.Parameters)
+		if err != nil {
+			return nil, xerrors.Errorf("preset %q: %w", preset.Name, err)
+		}
+		parameters := make([]codersdk.WorkspaceBuildParameter, 0, len(parameterMap))
+		for name, value := range parameterMap {
+			parameters = append(parameters, codersdk.WorkspaceBuildParameter{
+				Name:  name,
+				Value: value,
+			})
+		}
+		sort.Slice(parameters, func(i, j int) bool {
+			return parameters[i].Name < parameters[j].Name
+		})
+		presets = append(presets, codersdk.TemplateVersionPresetRequest{
+			Name:        preset.Name,
+			Description: preset.Description,
+			Parameters:  parameters,
+		})
+	}
+	return presets, nil
+}
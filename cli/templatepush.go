@@ -112,6 +112,7 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 		workdir         string
 		variablesFile   string
 		variables       []string
+		presetsFile     string
 		alwaysPrompt    bool
 		provisionerTags []string
 		uploadFlags     templateUploadFlags
@@ -143,6 +144,14 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 				return err
 			}
 
+			var presets []codersdk.TemplateVersionPresetRequest
+			if presetsFile != "" {
+				presets, err = loadTemplateVersionPresetsFromFile(presetsFile)
+				if err != nil {
+					return xerrors.Errorf("load presets: %w", err)
+				}
+			}
+
 			resp, err := uploadFlags.upload(inv, client)
 			if err != nil {
 				return err
@@ -173,6 +182,15 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 				return xerrors.Errorf("job failed: %s", job.Job.Status)
 			}
 
+			if presetsFile != "" {
+				_, err = client.UpdateTemplateVersionPresets(inv.Context(), job.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+					Presets: presets,
+				})
+				if err != nil {
+					return xerrors.Errorf("update template version presets: %w", err)
+				}
+			}
+
 			if activate {
 				err = client.UpdateActiveTemplateVersion(inv.Context(), template.ID, codersdk.UpdateActiveTemplateVersion{
 					ID: job.ID,
@@ -214,6 +232,11 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 			Description: "Specify a set of values for Terraform-managed variables.",
 			Value:       clibase.StringArrayOf(&variables),
 		},
+		{
+			Flag:        "presets-file",
+			Description: "Specify a YAML file with named sets of parameter values that users can pick when creating a workspace.",
+			Value:       clibase.StringOf(&presetsFile),
+		},
 		{
 			Flag:        "provisioner-tag",
 			Description: "Specify a set of tags to target provisioner daemons.",
@@ -69,6 +69,60 @@ func TestTemplatePush(t *testing.T) {
 		require.Equal(t, "example", templateVersions[1].Name)
 	})
 
+	t.Run("Presets", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Parameters: []*proto.RichParameter{
+							{Name: "cpu", Type: "number", DefaultValue: "2"},
+							{Name: "gpu", Type: "bool", DefaultValue: "false"},
+						},
+					},
+				},
+			}},
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		presetsFile := filepath.Join(t.TempDir(), "presets.yaml")
+		err := os.WriteFile(presetsFile, []byte(`
+- name: gpu-dev
+  description: Eight cores and a GPU
+  parameters:
+    cpu: 8
+    gpu: true
+- name: small
+  parameters:
+    cpu: 1
+`), 0o600)
+		require.NoError(t, err)
+
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--presets-file", presetsFile, "--yes")
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, inv.Run())
+
+		template, err = client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		presets, err := client.TemplateVersionPresets(context.Background(), template.ActiveVersionID)
+		require.NoError(t, err)
+		require.Len(t, presets, 2)
+		require.Equal(t, "gpu-dev", presets[0].Name)
+		require.Equal(t, "Eight cores and a GPU", presets[0].Description)
+		require.Equal(t, []codersdk.WorkspaceBuildParameter{
+			{Name: "cpu", Value: "8"},
+			{Name: "gpu", Value: "true"},
+		}, presets[0].Parameters)
+		require.Equal(t, []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "1"}}, presets[1].Parameters)
+	})
+
 	t.Run("PushInactiveTemplateVersion", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) templateRollout() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "rollout",
+		Short: "Move the workspaces of a template to a new version in batches",
+		Long: formatExamples(
+			example{
+				Description: "Update a quarter of the workspaces of the frontend group, five at a time",
+				Command:     "coder templates rollout start my-template v2 --percentage 25 --group frontend --batch-size 5",
+			},
+			example{
+				Description: "Check the progress of the rollout",
+				Command:     "coder templates rollout status my-template",
+			},
+			example{
+				Description: "Retry failed builds and continue a paused rollout",
+				Command:     "coder templates rollout resume my-template",
+			},
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.templateRolloutStart(),
+			r.templateRolloutStatus(),
+			r.templateRolloutUpdate("pause", "Pause the rollout of a template", codersdk.TemplateVersionRolloutStatusPaused),
+			r.templateRolloutUpdate("resume", "Resume a paused rollout, retrying the workspaces that failed to build", codersdk.TemplateVersionRolloutStatusRunning),
+			r.templateRolloutUpdate("abort", "Stop the rollout of a template. Builds in progress are not canceled", codersdk.TemplateVersionRolloutStatusAborted),
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) templateRolloutStart() *clibase.Cmd {
+	var (
+		percentage       int64
+		groups           []string
+		batchSize        int64
+		failureThreshold int64
+	)
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "start <template> <version>",
+		Short: "Start moving the workspaces of a template to a version",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			version, err := client.TemplateVersionByName(inv.Context(), template.ID, inv.Args[1])
+			if err != nil {
+				return xerrors.Errorf("get template version by name: %w", err)
+			}
+			groupIDs := make([]uuid.UUID, 0, len(groups))
+			for _, name := range groups {
+				if id, err := uuid.Parse(name); err == nil {
+					groupIDs = append(groupIDs, id)
+					continue
+				}
+				group, err := client.GroupByOrgAndName(inv.Context(), organization.ID, name)
+				if err != nil {
+					return xerrors.Errorf("get group %q: %w", name, err)
+				}
+				groupIDs = append(groupIDs, group.ID)
+			}
+
+			rollout, err := client.CreateTemplateVersionRollout(inv.Context(), template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+				TemplateVersionID:       version.ID,
+				Percentage:              int(percentage),
+				GroupIDs:                groupIDs,
+				BatchSize:               int(batchSize),
+				FailureThresholdPercent: int(failureThreshold),
+			})
+			if err != nil {
+				return xerrors.Errorf("create template version rollout: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Started moving %d workspaces of %s to %s.\n", rollout.Counts.Total,
+				cliui.DefaultStyles.Keyword.Render(template.Name), cliui.DefaultStyles.Keyword.Render(version.Name))
+			_, _ = fmt.Fprintf(inv.Stdout, "Run %s to follow its progress.\n",
+				cliui.DefaultStyles.Code.Render(fmt.Sprintf("coder templates rollout status %s", template.Name)))
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "percentage",
+			Description: "The percentage of the cohort to move to the version.",
+			Default:     "100",
+			Value:       clibase.Int64Of(&percentage),
+		},
+		{
+			Flag:        "group",
+			Description: "Only move the workspaces owned by members of the group. Accepts a group name or ID and can be repeated.",
+			Value:       clibase.StringArrayOf(&groups),
+		},
+		{
+			Flag:        "batch-size",
+			Description: "The maximum number of builds in progress at once.",
+			Default:     "10",
+			Value:       clibase.Int64Of(&batchSize),
+		},
+		{
+			Flag:        "failure-threshold",
+			Description: "Pause the rollout when the percentage of failed builds exceeds this value.",
+			Default:     "20",
+			Value:       clibase.Int64Of(&failureThreshold),
+		},
+	}
+	return cmd
+}
+
+type templateRolloutWorkspaceRow struct {
+	Workspace string `table:"workspace,default_sort"`
+	Status    string `table:"status"`
+	Error     string `table:"error"`
+}
+
+func (r *RootCmd) templateRolloutStatus() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "status <template> [rollout-id]",
+		Short: "Show the progress of the latest or the given rollout of a template",
+		Middleware: clibase.Chain(
+			clibase.RequireRangeArgs(1, 2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			var rolloutID uuid.UUID
+			if len(inv.Args) > 1 {
+				rolloutID, err = uuid.Parse(inv.Args[1])
+				if err != nil {
+					return xerrors.Errorf("parse rollout id: %w", err)
+				}
+			} else {
+				rollouts, err := client.TemplateVersionRollouts(inv.Context(), template.ID)
+				if err != nil {
+					return xerrors.Errorf("get template version rollouts: %w", err)
+				}
+				if len(rollouts) == 0 {
+					return xerrors.Errorf("template %q has no rollouts", template.Name)
+				}
+				rolloutID = rollouts[0].ID
+			}
+			rollout, err := client.TemplateVersionRollout(inv.Context(), template.ID, rolloutID)
+			if err != nil {
+				return xerrors.Errorf("get template version rollout: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Rollout %s of %s to %s is %s.\n", rollout.ID,
+				cliui.DefaultStyles.Keyword.Render(template.Name), cliui.DefaultStyles.Keyword.Render(rollout.TemplateVersionName),
+				cliui.DefaultStyles.Keyword.Render(string(rollout.Status)))
+			if rollout.PausedReason != "" {
+				_, _ = fmt.Fprintf(inv.Stdout, "Paused because %s.\n", rollout.PausedReason)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "%d succeeded, %d failed, %d building, %d pending, %d skipped of %d workspaces.\n\n",
+				rollout.Counts.Succeeded, rollout.Counts.Failed, rollout.Counts.Building, rollout.Counts.Pending, rollout.Counts.Skipped, rollout.Counts.Total)
+
+			rows := make([]templateRolloutWorkspaceRow, 0, len(rollout.Workspaces))
+			for _, workspace := range rollout.Workspaces {
+				rows = append(rows, templateRolloutWorkspaceRow{
+					Workspace: workspace.WorkspaceOwnerName + "/" + workspace.WorkspaceName,
+					Status:    string(workspace.Status),
+					Error:     workspace.Error,
+				})
+			}
+			out, err := cliui.DisplayTable(rows, "workspace", nil)
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	return cmd
+}
+
+// templateRolloutUpdate changes the status of the rollout that is in progress.
+func (r *RootCmd) templateRolloutUpdate(use, short string, status codersdk.TemplateVersionRolloutStatus) *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   use + " <template>",
+		Short: short,
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			rollouts, err := client.TemplateVersionRollouts(inv.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("get template version rollouts: %w", err)
+			}
+			var rollout *codersdk.TemplateVersionRollout
+			for i := range rollouts {
+				if rollouts[i].Status == codersdk.TemplateVersionRolloutStatusRunning ||
+					rollouts[i].Status == codersdk.TemplateVersionRolloutStatusPaused {
+					rollout = &rollouts[i]
+					break
+				}
+			}
+			if rollout == nil {
+				return xerrors.Errorf("template %q has no rollout in progress", template.Name)
+			}
+
+			updated, err := client.UpdateTemplateVersionRollout(inv.Context(), template.ID, rollout.ID, codersdk.UpdateTemplateVersionRolloutRequest{
+				Status: status,
+			})
+			if err != nil {
+				return xerrors.Errorf("update template version rollout: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "The rollout of %s to %s is %s.\n",
+				cliui.DefaultStyles.Keyword.Render(template.Name), cliui.DefaultStyles.Keyword.Render(updated.TemplateVersionName),
+				cliui.DefaultStyles.Keyword.Render(string(updated.Status)))
+			return nil
+		},
+	}
+	return cmd
+}
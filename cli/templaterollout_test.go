@@ -0,0 +1,66 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateRollout(t *testing.T) {
+	t.Parallel()
+
+	client, closer := coderdtest.NewWithProvisionerCloser(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	next := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, next.ID)
+	// Keep the rollout in progress.
+	require.NoError(t, closer.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	inv, root := clitest.New(t, "templates", "rollout", "start", template.Name, next.Name, "--batch-size", "1")
+	clitest.SetupConfig(t, client, root)
+	var out bytes.Buffer
+	inv.Stdout = &out
+	require.NoError(t, inv.WithContext(ctx).Run())
+	require.Contains(t, out.String(), "Started moving 1 workspaces")
+
+	inv, root = clitest.New(t, "templates", "rollout", "pause", template.Name)
+	clitest.SetupConfig(t, client, root)
+	require.NoError(t, inv.WithContext(ctx).Run())
+
+	inv, root = clitest.New(t, "templates", "rollout", "status", template.Name)
+	clitest.SetupConfig(t, client, root)
+	out.Reset()
+	inv.Stdout = &out
+	require.NoError(t, inv.WithContext(ctx).Run())
+	require.Contains(t, out.String(), "paused")
+	require.Contains(t, out.String(), workspace.Name)
+
+	inv, root = clitest.New(t, "templates", "rollout", "abort", template.Name)
+	clitest.SetupConfig(t, client, root)
+	require.NoError(t, inv.WithContext(ctx).Run())
+
+	rollouts, err := client.TemplateVersionRollouts(ctx, template.ID)
+	require.NoError(t, err)
+	require.Len(t, rollouts, 1)
+	require.Equal(t, codersdk.TemplateVersionRolloutStatusAborted, rollouts[0].Status)
+
+	// There is nothing left to abort.
+	inv, root = clitest.New(t, "templates", "rollout", "abort", template.Name)
+	clitest.SetupConfig(t, client, root)
+	require.ErrorContains(t, inv.WithContext(ctx).Run(), "no rollout in progress")
+}
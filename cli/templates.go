@@ -39,6 +39,8 @@ func (r *RootCmd) templates() *clibase.Cmd {
 			r.templateList(),
 			r.templatePlan(),
 			r.templatePush(),
+			r.templateRollout(),
+			r.templateSource(),
 			r.templateVersions(),
 			r.templateDelete(),
 			r.templatePull(),
@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) templateSource() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "source",
+		Short: "Manage the git repository that new versions of a template are created from",
+		Long: formatExamples(
+			example{
+				Description: "Create new versions of a template whenever the main branch changes",
+				Command:     "coder templates source set my-template --repository https://github.com/acme/templates.git --subdirectory docker --poll-interval 5m",
+			},
+			example{
+				Description: "Create a new version from the latest commit now",
+				Command:     "coder templates source sync my-template",
+			},
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.templateSourceSet(),
+			r.templateSourceShow(),
+			r.templateSourceSync(),
+			r.templateSourceUnset(),
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) templateSourceSet() *clibase.Cmd {
+	var (
+		repository      string
+		ref             string
+		subdirectory    string
+		gitAuthProvider string
+		pollInterval    time.Duration
+		webhookSecret   string
+	)
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "set <template>",
+		Short: "Set the git source of a template",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			if repository == "" {
+				return xerrors.New("--repository is required")
+			}
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			req := codersdk.UpdateTemplateGitSourceRequest{
+				RepositoryURL:      repository,
+				Ref:                ref,
+				Subdirectory:       subdirectory,
+				GitAuthProviderID:  gitAuthProvider,
+				PollIntervalMillis: pollInterval.Milliseconds(),
+			}
+			if inv.ParsedFlags().Changed("webhook-secret") {
+				req.WebhookSecret = &webhookSecret
+			}
+			source, err := client.UpdateTemplateGitSource(inv.Context(), template.ID, req)
+			if err != nil {
+				return xerrors.Errorf("update template git source: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Updated the git source of %s!\n", cliui.DefaultStyles.Keyword.Render(template.Name))
+			if source.HasWebhookSecret {
+				_, _ = fmt.Fprintf(inv.Stdout, "Push webhooks are accepted at %s\n",
+					client.URL.JoinPath(fmt.Sprintf("/api/v2/templates/%s/git-source/webhook", template.ID)))
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Run %s to create a version now.\n",
+				cliui.DefaultStyles.Code.Render(fmt.Sprintf("coder templates source sync %s", template.Name)))
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "repository",
+			Description: "The HTTP(S) clone URL of the git repository.",
+			Value:       clibase.StringOf(&repository),
+		},
+		{
+			Flag:        "ref",
+			Description: "The branch, tag or commit to create versions from.",
+			Default:     "main",
+			Value:       clibase.StringOf(&ref),
+		},
+		{
+			Flag:        "subdirectory",
+			Description: "The path of the template within the repository.",
+			Value:       clibase.StringOf(&subdirectory),
+		},
+		{
+			Flag:        "git-auth-provider",
+			Description: "The ID of the git auth provider used to clone private repositories. You must have authenticated with the provider.",
+			Value:       clibase.StringOf(&gitAuthProvider),
+		},
+		{
+			Flag:        "poll-interval",
+			Description: "How often to check the ref for new commits. Polling is disabled if zero.",
+			Default:     "0s",
+			Value:       clibase.DurationOf(&pollInterval),
+		},
+		{
+			Flag:        "webhook-secret",
+			Description: "The secret used to verify push webhooks. Webhooks are disabled if empty. The existing secret is kept if omitted.",
+			Value:       clibase.StringOf(&webhookSecret),
+		},
+	}
+	return cmd
+}
+
+type templateGitSourceRow struct {
+	// Used by json format:
+	Source codersdk.TemplateGitSource `table:"-"`
+
+	// Used by table format:
+	Repository   string        `json:"-" table:"repository,default_sort"`
+	Ref          string        `json:"-" table:"ref"`
+	Subdirectory string        `json:"-" table:"subdirectory"`
+	PollInterval time.Duration `json:"-" table:"poll interval"`
+	Webhook      bool          `json:"-" table:"webhook"`
+	LastCommit   string        `json:"-" table:"last commit"`
+	LastSynced   string        `json:"-" table:"last synced"`
+	LastError    string        `json:"-" table:"last error"`
+}
+
+func (r *RootCmd) templateSourceShow() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]templateGitSourceRow{}, nil),
+		cliui.JSONFormat(),
+	)
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "show <template>",
+		Short: "Show the git source of a template and the status of the last sync",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			source, err := client.TemplateGitSource(inv.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("get template git source: %w", err)
+			}
+
+			row := templateGitSourceRow{
+				Source:       source,
+				Repository:   source.RepositoryURL,
+				Ref:          source.Ref,
+				Subdirectory: source.Subdirectory,
+				PollInterval: time.Duration(source.PollIntervalMillis) * time.Millisecond,
+				Webhook:      source.HasWebhookSecret,
+				LastCommit:   source.LastCommitSHA,
+				LastError:    source.LastError,
+			}
+			if source.LastSyncedAt != nil {
+				row.LastSynced = source.LastSyncedAt.Format(time.Stamp)
+			}
+			out, err := formatter.Format(inv.Context(), []templateGitSourceRow{row})
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) templateSourceSync() *clibase.Cmd {
+	var force bool
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "sync <template>",
+		Short: "Create a new template version if the git source has new commits",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			before, err := client.SyncTemplateGitSource(inv.Context(), template.ID, codersdk.SyncTemplateGitSourceRequest{
+				Force: force,
+			})
+			if err != nil {
+				return xerrors.Errorf("sync template git source: %w", err)
+			}
+
+			// The sync runs in the background and is done once the source
+			// records a newer sync.
+			_, _ = fmt.Fprintf(inv.Stdout, "Syncing %s from %s...\n", cliui.DefaultStyles.Keyword.Render(template.Name), before.Ref)
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			var source codersdk.TemplateGitSource
+			for {
+				select {
+				case <-inv.Context().Done():
+					return inv.Context().Err()
+				case <-ticker.C:
+				}
+				source, err = client.TemplateGitSource(inv.Context(), template.ID)
+				if err != nil {
+					return xerrors.Errorf("get template git source: %w", err)
+				}
+				if source.LastSyncedAt != nil && (before.LastSyncedAt == nil || source.LastSyncedAt.After(*before.LastSyncedAt)) {
+					break
+				}
+			}
+			if source.LastError != "" {
+				return xerrors.Errorf("sync template git source: %s", source.LastError)
+			}
+
+			if source.LastCommitSHA == before.LastCommitSHA && !force {
+				_, _ = fmt.Fprintf(inv.Stdout, "%s is up to date with %s.\n",
+					cliui.DefaultStyles.Keyword.Render(template.Name), source.LastCommitSHA)
+				return nil
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Created a new version of %s from %s. It becomes active once the import succeeds.\n",
+				cliui.DefaultStyles.Keyword.Render(template.Name), source.LastCommitSHA)
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "force",
+			Description: "Create a new version even if the ref has not changed.",
+			Value:       clibase.BoolOf(&force),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) templateSourceUnset() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "unset <template>",
+		Short: "Stop creating versions of a template from git",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			err = client.DeleteTemplateGitSource(inv.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("delete template git source: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Removed the git source of %s.\n", cliui.DefaultStyles.Keyword.Render(template.Name))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,63 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateSource(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	inv, root := clitest.New(t, "templates", "source", "set", template.Name,
+		"--repository", "https://github.com/coder/coder.git",
+		"--subdirectory", "examples/templates/docker",
+		"--poll-interval", "5m",
+		"--webhook-secret", "secret",
+	)
+	clitest.SetupConfig(t, client, root)
+	var out bytes.Buffer
+	inv.Stdout = &out
+	require.NoError(t, inv.WithContext(ctx).Run())
+	require.Contains(t, out.String(), "/git-source/webhook")
+
+	source, err := client.TemplateGitSource(ctx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, "main", source.Ref)
+	require.Equal(t, "examples/templates/docker", source.Subdirectory)
+	require.EqualValues(t, 300000, source.PollIntervalMillis)
+	require.True(t, source.HasWebhookSecret)
+
+	inv, root = clitest.New(t, "templates", "source", "show", template.Name)
+	clitest.SetupConfig(t, client, root)
+	out.Reset()
+	inv.Stdout = &out
+	require.NoError(t, inv.WithContext(ctx).Run())
+	require.Contains(t, out.String(), "https://github.com/coder/coder.git")
+	require.Contains(t, out.String(), "examples/templates/docker")
+
+	inv, root = clitest.New(t, "templates", "source", "unset", template.Name)
+	clitest.SetupConfig(t, client, root)
+	require.NoError(t, inv.WithContext(ctx).Run())
+
+	_, err = client.TemplateGitSource(ctx, template.ID)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
@@ -23,12 +23,28 @@ func (r *RootCmd) templateVersions() *clibase.Cmd {
 				Description: "List versions of a specific template",
 				Command:     "coder templates versions list my-template",
 			},
+			example{
+				Description: "Warn users that are still building an old version",
+				Command:     "coder templates versions deprecate my-template v1",
+			},
+			example{
+				Description: "Archive every version that is neither active nor used by a workspace",
+				Command:     "coder templates versions archive my-template",
+			},
+			example{
+				Description: "Review the changes between two versions",
+				Command:     "coder templates versions diff my-template v1 v2",
+			},
 		),
 		Handler: func(inv *clibase.Invocation) error {
 			return inv.Command.HelpHandler(inv)
 		},
 		Children: []*clibase.Cmd{
+			r.templateVersionsArchive(),
+			r.templateVersionsDeprecate(),
+			r.templateVersionsDiff(),
 			r.templateVersionsList(),
+			r.templateVersionsPromote(),
 		},
 	}
 
@@ -82,6 +98,267 @@ func (r *RootCmd) templateVersionsList() *clibase.Cmd {
 	return cmd
 }
 
+func (r *RootCmd) templateVersionsPromote() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use: "promote <template> <version>",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Short: "Make a version the active version of a template",
+		Handler: func(inv *clibase.Invocation) error {
+			template, version, err := templateVersionByName(inv, client, inv.Args[0], inv.Args[1])
+			if err != nil {
+				return err
+			}
+			err = client.UpdateActiveTemplateVersion(inv.Context(), template.ID, codersdk.UpdateActiveTemplateVersion{
+				ID: version.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("update active template version: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Promoted %s to the active version of %s!\n",
+				cliui.DefaultStyles.Keyword.Render(version.Name), cliui.DefaultStyles.Keyword.Render(template.Name))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) templateVersionsDeprecate() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use: "deprecate <template> <version>",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Short: "Deprecate a version so users are warned to update",
+		Handler: func(inv *clibase.Invocation) error {
+			template, version, err := templateVersionByName(inv, client, inv.Args[0], inv.Args[1])
+			if err != nil {
+				return err
+			}
+			_, err = client.UpdateTemplateVersionState(inv.Context(), version.ID, codersdk.UpdateTemplateVersionStateRequest{
+				State: codersdk.TemplateVersionStateDeprecated,
+			})
+			if err != nil {
+				return xerrors.Errorf("deprecate template version: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Deprecated version %s of %s!\n",
+				cliui.DefaultStyles.Keyword.Render(version.Name), cliui.DefaultStyles.Keyword.Render(template.Name))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) templateVersionsArchive() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use: "archive <template> [versions...]",
+		Middleware: clibase.Chain(
+			clibase.RequireRangeArgs(1, -1),
+			r.InitClient(client),
+		),
+		Short: "Archive versions of a template and delete their source files",
+		Long: "Versions that are active or used by the latest build of a workspace are skipped. " +
+			"If no versions are given, every unused version is archived. Archived versions can't be used to build workspaces.",
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			var req codersdk.ArchiveTemplateVersionsRequest
+			for _, name := range inv.Args[1:] {
+				version, err := client.TemplateVersionByName(inv.Context(), template.ID, name)
+				if err != nil {
+					return xerrors.Errorf("get template version %q: %w", name, err)
+				}
+				req.TemplateVersionIDs = append(req.TemplateVersionIDs, version.ID)
+			}
+			if len(req.TemplateVersionIDs) == 0 {
+				_, err = cliui.Prompt(inv, cliui.PromptOptions{
+					Text:      fmt.Sprintf("Archive every unused version of %s?", cliui.DefaultStyles.Code.Render(template.Name)),
+					IsConfirm: true,
+					Default:   cliui.ConfirmNo,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			resp, err := client.ArchiveTemplateVersions(inv.Context(), template.ID, req)
+			if err != nil {
+				return xerrors.Errorf("archive template versions: %w", err)
+			}
+			if len(req.TemplateVersionIDs) > len(resp.ArchivedIDs) {
+				cliui.Warn(inv.Stderr, "Some versions were not archived because they are active, used by a workspace or already archived.")
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Archived %d versions of %s!\n",
+				len(resp.ArchivedIDs), cliui.DefaultStyles.Keyword.Render(template.Name))
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		cliui.SkipPromptOption(),
+	}
+	return cmd
+}
+
+func (r *RootCmd) templateVersionsDiff() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			diff, ok := data.(codersdk.TemplateVersionDiff)
+			if !ok {
+				return nil, xerrors.Errorf("expected type %T, got %T", diff, data)
+			}
+			return renderTemplateVersionDiff(diff), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use: "diff <template> <base-version> <version>",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(3),
+			r.InitClient(client),
+		),
+		Short: "Show the changes between two versions of a template",
+		Long: "Compares the source files, rich parameters, template variables, and the resources, " +
+			"agents and apps reported by the import jobs of both versions.",
+		Handler: func(inv *clibase.Invocation) error {
+			template, base, err := templateVersionByName(inv, client, inv.Args[0], inv.Args[1])
+			if err != nil {
+				return err
+			}
+			version, err := client.TemplateVersionByName(inv.Context(), template.ID, inv.Args[2])
+			if err != nil {
+				return xerrors.Errorf("get template version by name: %w", err)
+			}
+
+			diff, err := client.TemplateVersionDiff(inv.Context(), base.ID, version.ID)
+			if err != nil {
+				return xerrors.Errorf("compare template versions: %w", err)
+			}
+			out, err := formatter.Format(inv.Context(), diff)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				_, _ = fmt.Fprintf(inv.Stderr, "No changes between %s and %s.\n",
+					cliui.DefaultStyles.Keyword.Render(base.Name), cliui.DefaultStyles.Keyword.Render(version.Name))
+				return nil
+			}
+
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+// renderTemplateVersionDiff formats a template version diff for humans.
+// File changes are printed as a unified diff, followed by a summary of the
+// parameters, variables and resources that changed. It returns an empty
+// string if there are no changes.
+func renderTemplateVersionDiff(diff codersdk.TemplateVersionDiff) string {
+	var sections []string
+	if len(diff.Files) > 0 {
+		var sb strings.Builder
+		for _, file := range diff.Files {
+			if file.Binary {
+				_, _ = fmt.Fprintf(&sb, "Binary file %s %s\n", file.Path, file.Change)
+				continue
+			}
+			_, _ = sb.WriteString(file.Diff)
+		}
+		sections = append(sections, strings.TrimSuffix(sb.String(), "\n"))
+	}
+
+	type entry struct {
+		name   string
+		change codersdk.TemplateVersionDiffChange
+		fields []codersdk.TemplateVersionFieldDiff
+	}
+	summarize := func(title string, entries []entry) {
+		if len(entries) == 0 {
+			return
+		}
+		var sb strings.Builder
+		_, _ = sb.WriteString(cliui.DefaultStyles.Bold.Render(title) + ":")
+		for _, e := range entries {
+			marker := "~"
+			switch e.change {
+			case codersdk.TemplateVersionDiffChangeAdded:
+				marker = "+"
+			case codersdk.TemplateVersionDiffChangeRemoved:
+				marker = "-"
+			}
+			_, _ = fmt.Fprintf(&sb, "\n  %s %s", marker, e.name)
+			for _, field := range e.fields {
+				_, _ = fmt.Fprintf(&sb, "\n      %s: %q -> %q", field.Field, field.Old, field.New)
+			}
+		}
+		sections = append(sections, sb.String())
+	}
+
+	parameters := make([]entry, 0, len(diff.Parameters))
+	for _, p := range diff.Parameters {
+		parameters = append(parameters, entry{name: p.Name, change: p.Change, fields: p.Fields})
+	}
+	summarize("Parameters", parameters)
+
+	variables := make([]entry, 0, len(diff.Variables))
+	for _, v := range diff.Variables {
+		variables = append(variables, entry{name: v.Name, change: v.Change, fields: v.Fields})
+	}
+	summarize("Variables", variables)
+
+	resources := make([]entry, 0, len(diff.Resources))
+	for _, r := range diff.Resources {
+		resources = append(resources, entry{name: fmt.Sprintf("%s %s", r.Kind, r.Path), change: r.Change, fields: r.Fields})
+	}
+	summarize("Resources", resources)
+
+	return strings.Join(sections, "\n\n")
+}
+
+// templateVersionByName fetches a template and one of its versions by name.
+func templateVersionByName(inv *clibase.Invocation, client *codersdk.Client, templateName, versionName string) (codersdk.Template, codersdk.TemplateVersion, error) {
+	organization, err := CurrentOrganization(inv, client)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, xerrors.Errorf("get current organization: %w", err)
+	}
+	template, err := client.TemplateByName(inv.Context(), organization.ID, templateName)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, xerrors.Errorf("get template by name: %w", err)
+	}
+	version, err := client.TemplateVersionByName(inv.Context(), template.ID, versionName)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, xerrors.Errorf("get template version by name: %w", err)
+	}
+	return template, version, nil
+}
+
 type templateVersionRow struct {
 	// For json format:
 	TemplateVersion codersdk.TemplateVersion `table:"-"`
@@ -91,6 +368,7 @@ type templateVersionRow struct {
 	CreatedAt time.Time `json:"-" table:"created at"`
 	CreatedBy string    `json:"-" table:"created by"`
 	Status    string    `json:"-" table:"status"`
+	State     string    `json:"-" table:"state"`
 	Active    string    `json:"-" table:"active"`
 }
 
@@ -110,6 +388,7 @@ func templateVersionsToRows(activeVersionID uuid.UUID, templateVersions ...coder
 			CreatedAt:       templateVersion.CreatedAt,
 			CreatedBy:       templateVersion.CreatedBy.Username,
 			Status:          strings.Title(string(templateVersion.Job.Status)),
+			State:           strings.Title(string(templateVersion.State)),
 			Active:          activeStatus,
 		}
 	}
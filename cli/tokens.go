@@ -48,6 +48,7 @@ func (r *RootCmd) createToken() *clibase.Cmd {
 	var (
 		tokenLifetime time.Duration
 		name          string
+		user          string
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -58,7 +59,7 @@ func (r *RootCmd) createToken() *clibase.Cmd {
 			r.InitClient(client),
 		),
 		Handler: func(inv *clibase.Invocation) error {
-			res, err := client.CreateToken(inv.Context(), codersdk.Me, codersdk.CreateTokenRequest{
+			res, err := client.CreateToken(inv.Context(), user, codersdk.CreateTokenRequest{
 				Lifetime:  tokenLifetime,
 				TokenName: name,
 			})
@@ -87,6 +88,13 @@ func (r *RootCmd) createToken() *clibase.Cmd {
 			Description:   "Specify a human-readable name.",
 			Value:         clibase.StringOf(&name),
 		},
+		{
+			Flag:        "user",
+			Env:         "CODER_TOKEN_USER",
+			Description: "Specify the user to create the token for. Only admins can create tokens for other users, such as service accounts.",
+			Default:     codersdk.Me,
+			Value:       clibase.StringOf(&user),
+		},
 	}
 
 	return cmd
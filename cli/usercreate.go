@@ -14,10 +14,11 @@ import (
 
 func (r *RootCmd) userCreate() *clibase.Cmd {
 	var (
-		email        string
-		username     string
-		password     string
-		disableLogin bool
+		email          string
+		username       string
+		password       string
+		disableLogin   bool
+		serviceAccount bool
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -54,7 +55,7 @@ func (r *RootCmd) userCreate() *clibase.Cmd {
 					return err
 				}
 			}
-			if password == "" && !disableLogin {
+			if password == "" && !disableLogin && !serviceAccount {
 				password, err = cryptorand.StringCharset(cryptorand.Human, 20)
 				if err != nil {
 					return err
@@ -67,10 +68,18 @@ func (r *RootCmd) userCreate() *clibase.Cmd {
 				Password:       password,
 				OrganizationID: organization.ID,
 				DisableLogin:   disableLogin,
+				ServiceAccount: serviceAccount,
 			})
 			if err != nil {
 				return err
 			}
+			if serviceAccount {
+				_, _ = fmt.Fprintln(inv.Stderr, `A new service account has been created!
+It cannot log in. Create a token for it with:
+
+`+cliui.DefaultStyles.Code.Render("coder tokens create --user "+username))
+				return nil
+			}
 			authenticationMethod := `Your password is: ` + cliui.DefaultStyles.Field.Render(password)
 			if disableLogin {
 				authenticationMethod = "Login has been disabled for this user. Contact your administrator to authenticate."
@@ -116,6 +125,11 @@ Create a workspace  `+cliui.DefaultStyles.Code.Render("coder create")+`!`)
 				"Be careful when using this flag as it can lock the user out of their account.",
 			Value: clibase.BoolOf(&disableLogin),
 		},
+		{
+			Flag:        "service-account",
+			Description: "Create a non-human account for automation. Service accounts cannot log in, only authenticate with tokens, and do not count towards the licensed users.",
+			Value:       clibase.BoolOf(&serviceAccount),
+		},
 	}
 	return cmd
 }
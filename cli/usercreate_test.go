@@ -1,13 +1,18 @@
 package cli_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
 )
 
 func TestUserCreate(t *testing.T) {
@@ -37,4 +42,28 @@ func TestUserCreate(t *testing.T) {
 		}
 		<-doneChan
 	})
+	t.Run("ServiceAccount", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		coderdtest.CreateFirstUser(t, client)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		inv, root := clitest.New(t, "users", "create", "--service-account", "-u", "ci", "-e", "ci@coder.com")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		inv, root = clitest.New(t, "tokens", "create", "--user", "ci")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		inv.Stdout = buf
+		err = inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		accountClient := codersdk.New(client.URL)
+		accountClient.SetSessionToken(strings.TrimSpace(buf.String()))
+		me, err := accountClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, "ci", me.Username)
+	})
 }
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) userImpersonate() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "impersonate <username|user_id>",
+		Short: "Create a short-lived session token that acts as another user",
+		Long: "Only owners can impersonate users. The actions taken with the token are recorded in the audit log along with the owner that is impersonating the user.\n" + formatExamples(
+			example{
+				Description: "Run a command as another user",
+				Command:     "CODER_SESSION_TOKEN=$(coder users impersonate example_user) coder list",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			identifier := inv.Args[0]
+			if identifier == "" {
+				return xerrors.Errorf("user identifier cannot be an empty string")
+			}
+
+			res, err := client.ImpersonateUser(inv.Context(), identifier)
+			if err != nil {
+				return xerrors.Errorf("impersonate user: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stderr, "The token impersonates %s until %s.\n",
+				cliui.DefaultStyles.Keyword.Render(identifier),
+				res.ExpiresAt.Local().Format("15:04:05 MST"),
+			)
+			_, _ = fmt.Fprintln(inv.Stdout, res.Key)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,45 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestUserImpersonate(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	_, member := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	inv, root := clitest.New(t, "users", "impersonate", member.Username)
+	clitest.SetupConfig(t, client, root)
+	var stdout bytes.Buffer
+	inv.Stdout = &stdout
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	impersonated := codersdk.New(client.URL)
+	impersonated.SetSessionToken(strings.TrimSpace(stdout.String()))
+	me, err := impersonated.User(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.Equal(t, member.ID, me.ID)
+
+	// Commands run with the token warn that the user is impersonated.
+	inv, root = clitest.New(t, "users", "show", codersdk.Me)
+	clitest.SetupConfig(t, impersonated, root)
+	var stderr bytes.Buffer
+	inv.Stderr = &stderr
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, stderr.String(), "You are impersonating this user")
+}
@@ -19,6 +19,8 @@ func (r *RootCmd) users() *clibase.Cmd {
 			r.userSingle(),
 			r.createUserStatusCommand(codersdk.UserStatusActive),
 			r.createUserStatusCommand(codersdk.UserStatusSuspended),
+			r.userUnlock(),
+			r.userImpersonate(),
 		},
 	}
 	return cmd
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) userUnlock() *clibase.Cmd {
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "unlock <username|user_id>",
+		Short: "Unlock a user that was locked out after too many failed login attempts",
+		Long: formatExamples(
+			example{
+				Command: "coder users unlock example_user",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			identifier := inv.Args[0]
+			if identifier == "" {
+				return xerrors.Errorf("user identifier cannot be an empty string")
+			}
+
+			user, err := client.User(inv.Context(), identifier)
+			if err != nil {
+				return xerrors.Errorf("fetch user: %w", err)
+			}
+
+			err = client.UnlockUser(inv.Context(), user.ID.String())
+			if err != nil {
+				return xerrors.Errorf("unlock user: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "User %s has been unlocked!\n", cliui.DefaultStyles.Keyword.Render(user.Username))
+			return nil
+		},
+	}
+	return cmd
+}
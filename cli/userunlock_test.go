@@ -0,0 +1,47 @@
+package cli_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestUserUnlock(t *testing.T) {
+	t.Parallel()
+	dv := coderdtest.DeploymentValues(t)
+	dv.LoginLockout.Threshold = 1
+	client := coderdtest.New(t, &coderdtest.Options{DeploymentValues: dv})
+	admin := coderdtest.CreateFirstUser(t, client)
+	_, member := coderdtest.CreateAnotherUser(t, client, admin.OrganizationID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	req := codersdk.LoginWithPasswordRequest{
+		Email:    member.Email,
+		Password: "badpass",
+	}
+	userClient := codersdk.New(client.URL)
+	_, err := userClient.LoginWithPassword(ctx, req)
+	require.Error(t, err)
+	req.Password = "SomeSecurePassword!"
+	_, err = userClient.LoginWithPassword(ctx, req)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode())
+
+	inv, root := clitest.New(t, "users", "unlock", member.Username)
+	clitest.SetupConfig(t, client, root)
+	pty := ptytest.New(t).Attach(inv)
+	clitest.Start(t, inv)
+	pty.ExpectMatch("has been unlocked")
+
+	_, err = userClient.LoginWithPassword(ctx, req)
+	require.NoError(t, err)
+}
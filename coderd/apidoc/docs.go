@@ -530,7 +530,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/entitlements": {
+        "/derp-map": {
             "get": {
                 "security": [
                     {
@@ -538,24 +538,24 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Agents"
                 ],
-                "summary": "Get entitlements",
-                "operationId": "get-entitlements",
+                "summary": "Watch DERP map of the authenticated user",
+                "operationId": "watch-derp-map-of-the-authenticated-user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Entitlements"
+                            "$ref": "#/definitions/tailcfg.DERPMap"
                         }
                     }
                 }
             }
         },
-        "/experiments": {
+        "/derp-region-policies": {
             "get": {
                 "security": [
                     {
@@ -566,132 +566,136 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "General"
+                    "Enterprise"
                 ],
-                "summary": "Get experiments",
-                "operationId": "get-experiments",
+                "summary": "Get DERP region policies",
+                "operationId": "get-derp-region-policies",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.Experiment"
+                                "$ref": "#/definitions/codersdk.DERPRegionPolicy"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/files": {
+            },
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "description": "Swagger notice: Swagger 2.0 doesn't support file upload with a ` + "`" + `content-type` + "`" + ` different than ` + "`" + `application/x-www-form-urlencoded` + "`" + `.",
                 "consumes": [
-                    "application/x-tar"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Files"
+                    "Enterprise"
                 ],
-                "summary": "Upload file",
-                "operationId": "upload-file",
+                "summary": "Create DERP region policy",
+                "operationId": "create-derp-region-policy",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "application/x-tar",
-                        "description": "Content-Type must be ` + "`" + `application/x-tar` + "`" + `",
-                        "name": "Content-Type",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "File to be uploaded",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Create DERP region policy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateDERPRegionPolicyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UploadResponse"
+                            "$ref": "#/definitions/codersdk.DERPRegionPolicy"
                         }
                     }
                 }
             }
         },
-        "/files/{fileID}": {
-            "get": {
+        "/derp-region-policies/{policy}": {
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "tags": [
-                    "Files"
+                    "Enterprise"
                 ],
-                "summary": "Get file by ID",
-                "operationId": "get-file-by-id",
+                "summary": "Delete DERP region policy",
+                "operationId": "delete-derp-region-policy",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "File ID",
-                        "name": "fileID",
+                        "description": "Policy ID",
+                        "name": "policy",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            }
-        },
-        "/groups/{group}": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Get group by name",
-                "operationId": "get-group-by-name",
+                "summary": "Update DERP region policy",
+                "operationId": "update-derp-region-policy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group name",
-                        "name": "group",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "policy",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update DERP region policy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateDERPRegionPolicyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Group"
+                            "$ref": "#/definitions/codersdk.DERPRegionPolicy"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/derp-regions": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -703,60 +707,59 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Delete group by name",
-                "operationId": "delete-group-by-name",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Group name",
-                        "name": "group",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get managed DERP regions",
+                "operationId": "get-managed-derp-regions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Group"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ManagedDERPRegion"
+                            }
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Update group by name",
-                "operationId": "update-group-by-name",
+                "summary": "Create managed DERP region",
+                "operationId": "create-managed-derp-region",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Group name",
-                        "name": "group",
-                        "in": "path",
-                        "required": true
+                        "description": "Create DERP region request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateManagedDERPRegionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Group"
+                            "$ref": "#/definitions/codersdk.ManagedDERPRegion"
                         }
                     }
                 }
             }
         },
-        "/insights/daus": {
+        "/derp-regions/{region}": {
             "get": {
                 "security": [
                     {
@@ -767,48 +770,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Insights"
+                    "Enterprise"
+                ],
+                "summary": "Get managed DERP region",
+                "operationId": "get-managed-derp-region",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get deployment DAUs",
-                "operationId": "get-deployment-daus",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.DAUsResponse"
+                            "$ref": "#/definitions/codersdk.ManagedDERPRegion"
                         }
                     }
                 }
-            }
-        },
-        "/licenses": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Get licenses",
-                "operationId": "get-licenses",
+                "summary": "Delete managed DERP region",
+                "operationId": "delete-managed-derp-region",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.License"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             },
-            "post": {
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -821,33 +831,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Enterprise"
                 ],
-                "summary": "Add new license",
-                "operationId": "add-new-license",
+                "summary": "Update managed DERP region",
+                "operationId": "update-managed-derp-region",
                 "parameters": [
                     {
-                        "description": "Add license request",
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update DERP region request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.AddLicenseRequest"
+                            "$ref": "#/definitions/codersdk.UpdateManagedDERPRegionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.License"
+                            "$ref": "#/definitions/codersdk.ManagedDERPRegion"
                         }
                     }
                 }
             }
         },
-        "/licenses/{id}": {
-            "delete": {
+        "/entitlements": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -859,183 +876,155 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Delete license",
-                "operationId": "delete-license",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "number",
-                        "description": "License ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get entitlements",
+                "operationId": "get-entitlements",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Entitlements"
+                        }
                     }
                 }
             }
         },
-        "/organizations": {
-            "post": {
+        "/experiments": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
-                ],
-                "summary": "Create organization",
-                "operationId": "create-organization",
-                "parameters": [
-                    {
-                        "description": "Create organization request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateOrganizationRequest"
-                        }
-                    }
+                    "General"
                 ],
+                "summary": "Get experiments",
+                "operationId": "get-experiments",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Organization"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Experiment"
+                            }
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}": {
-            "get": {
+        "/files": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Swagger notice: Swagger 2.0 doesn't support file upload with a ` + "`" + `content-type` + "`" + ` different than ` + "`" + `application/x-www-form-urlencoded` + "`" + `.",
+                "consumes": [
+                    "application/x-tar"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Files"
                 ],
-                "summary": "Get organization by ID",
-                "operationId": "get-organization-by-id",
+                "summary": "Upload file",
+                "operationId": "upload-file",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
-                    }
+                        "default": "application/x-tar",
+                        "description": "Content-Type must be ` + "`" + `application/x-tar` + "`" + `",
+                        "name": "Content-Type",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to be uploaded",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Organization"
+                            "$ref": "#/definitions/codersdk.UploadResponse"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/groups": {
+        "/files/{fileID}": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Enterprise"
+                    "Files"
                 ],
-                "summary": "Get groups by organization",
-                "operationId": "get-groups-by-organization",
+                "summary": "Get file by ID",
+                "operationId": "get-file-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "description": "File ID",
+                        "name": "fileID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.Group"
-                            }
-                        }
+                        "description": "OK"
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/groups/{group}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Create group for organization",
-                "operationId": "create-group-for-organization",
+                "summary": "Get group by name",
+                "operationId": "get-group-by-name",
                 "parameters": [
-                    {
-                        "description": "Create group request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateGroupRequest"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "description": "Group name",
+                        "name": "group",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/codersdk.Group"
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/groups/{groupName}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -1047,21 +1036,13 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Get group by organization and group name",
-                "operationId": "get-group-by-organization-and-group-name",
+                "summary": "Delete group by name",
+                "operationId": "delete-group-by-name",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Group name",
-                        "name": "groupName",
+                        "name": "group",
                         "in": "path",
                         "required": true
                     }
@@ -1074,10 +1055,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/members/roles": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -1087,16 +1066,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Members"
+                    "Enterprise"
                 ],
-                "summary": "Get member roles by organization",
-                "operationId": "get-member-roles-by-organization",
+                "summary": "Update group by name",
+                "operationId": "update-group-by-name",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "description": "Group name",
+                        "name": "group",
                         "in": "path",
                         "required": true
                     }
@@ -1105,123 +1083,89 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.AssignableRoles"
-                            }
+                            "$ref": "#/definitions/codersdk.Group"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/members/{user}/roles": {
-            "put": {
+        "/insights/apps": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Members"
+                    "Insights"
                 ],
-                "summary": "Assign role to organization member",
-                "operationId": "assign-role-to-organization-member",
+                "summary": "Get app insights",
+                "operationId": "get-app-insights",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
+                        "format": "date-time",
+                        "description": "Start time, defaults to 30 days before the end time",
+                        "name": "start_time",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
+                        "format": "date-time",
+                        "description": "End time, defaults to now",
+                        "name": "end_time",
+                        "in": "query"
                     },
                     {
-                        "description": "Update roles request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateRoles"
-                        }
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Template IDs, defaults to all templates",
+                        "name": "template_ids",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.OrganizationMember"
+                            "$ref": "#/definitions/codersdk.AppInsightsResponse"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/members/{user}/workspaces": {
-            "post": {
+        "/insights/daus": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workspaces"
-                ],
-                "summary": "Create user workspace by organization",
-                "operationId": "create-user-workspace-by-organization",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Username, UUID, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Create workspace request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateWorkspaceRequest"
-                        }
-                    }
+                    "Insights"
                 ],
+                "summary": "Get deployment DAUs",
+                "operationId": "get-deployment-daus",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Workspace"
+                            "$ref": "#/definitions/codersdk.DAUsResponse"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/provisionerdaemons": {
+        "/licenses": {
             "get": {
                 "security": [
                     {
@@ -1234,62 +1178,60 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Get provisioner daemons",
-                "operationId": "get-provisioner-daemons",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get licenses",
+                "operationId": "get-licenses",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.ProvisionerDaemon"
+                                "$ref": "#/definitions/codersdk.License"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/provisionerdaemons/serve": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Enterprise"
+                    "Organizations"
                 ],
-                "summary": "Serve provisioner daemon",
-                "operationId": "serve-provisioner-daemon",
+                "summary": "Add new license",
+                "operationId": "add-new-license",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
+                        "description": "Add license request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.AddLicenseRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.License"
+                        }
                     }
                 }
             }
         },
-        "/organizations/{organization}/templates": {
-            "get": {
+        "/licenses/{id}": {
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -1299,27 +1241,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get templates by organization",
-                "operationId": "get-templates-by-organization",
+                "summary": "Delete license",
+                "operationId": "delete-license",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "format": "number",
+                        "description": "License ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/oauth2-provider/apps": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth2"
+                ],
+                "summary": "Get OAuth2 applications",
+                "operationId": "get-oauth2-applications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by applications authorized for a user",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.Template"
+                                "$ref": "#/definitions/codersdk.OAuth2ProviderApp"
                             }
                         }
                     }
@@ -1338,39 +1310,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "OAuth2"
                 ],
-                "summary": "Create template by organization",
-                "operationId": "create-template-by-organization",
+                "summary": "Create OAuth2 application",
+                "operationId": "create-oauth2-application",
                 "parameters": [
                     {
-                        "description": "Request body",
+                        "description": "The OAuth2 application to create.",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateRequest"
+                            "$ref": "#/definitions/codersdk.PostOAuth2ProviderAppRequest"
                         }
-                    },
-                    {
-                        "type": "string",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
+                            "$ref": "#/definitions/codersdk.OAuth2ProviderApp"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/templates/examples": {
+        "/oauth2-provider/apps/{app}": {
             "get": {
                 "security": [
                     {
@@ -1381,16 +1346,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "OAuth2"
                 ],
-                "summary": "Get template examples by organization",
-                "operationId": "get-template-examples-by-organization",
+                "summary": "Get OAuth2 application",
+                "operationId": "get-oauth2-application",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "description": "App ID",
+                        "name": "app",
                         "in": "path",
                         "required": true
                     }
@@ -1399,58 +1363,83 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateExample"
-                            }
+                            "$ref": "#/definitions/codersdk.OAuth2ProviderApp"
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/templates/{templatename}": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "OAuth2"
                 ],
-                "summary": "Get templates by organization and template name",
-                "operationId": "get-templates-by-organization-and-template-name",
+                "summary": "Update OAuth2 application",
+                "operationId": "update-oauth2-application",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "description": "App ID",
+                        "name": "app",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Template name",
-                        "name": "templatename",
-                        "in": "path",
-                        "required": true
+                        "description": "Update an OAuth2 application.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PutOAuth2ProviderAppRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
+                            "$ref": "#/definitions/codersdk.OAuth2ProviderApp"
                         }
                     }
                 }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "OAuth2"
+                ],
+                "summary": "Delete OAuth2 application",
+                "operationId": "delete-oauth2-application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "App ID",
+                        "name": "app",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
             }
         },
-        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}": {
+        "/oauth2-provider/apps/{app}/secrets": {
             "get": {
                 "security": [
                     {
@@ -1461,30 +1450,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "OAuth2"
                 ],
-                "summary": "Get template version by organization, template, and name",
-                "operationId": "get-template-version-by-organization-template-and-name",
+                "summary": "Get OAuth2 application secrets",
+                "operationId": "get-oauth2-application-secrets",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Template name",
-                        "name": "templatename",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Template version name",
-                        "name": "templateversionname",
+                        "description": "App ID",
+                        "name": "app",
                         "in": "path",
                         "required": true
                     }
@@ -1493,14 +1467,15 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.OAuth2ProviderAppSecret"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}/previous": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -1510,45 +1485,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "OAuth2"
                 ],
-                "summary": "Get previous template version by organization, template, and name",
-                "operationId": "get-previous-template-version-by-organization-template-and-name",
+                "summary": "Create OAuth2 application secret",
+                "operationId": "create-oauth2-application-secret",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
+                        "description": "App ID",
+                        "name": "app",
                         "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OAuth2ProviderAppSecretFull"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth2-provider/apps/{app}/secrets/{secretID}": {
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "OAuth2"
+                ],
+                "summary": "Delete OAuth2 application secret",
+                "operationId": "delete-oauth2-application-secret",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Template name",
-                        "name": "templatename",
+                        "description": "App ID",
+                        "name": "app",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Template version name",
-                        "name": "templateversionname",
+                        "description": "Secret ID",
+                        "name": "secretID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/organizations/{organization}/templateversions": {
+        "/organizations": {
             "post": {
                 "security": [
                     {
@@ -1562,26 +1557,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Organizations"
                 ],
-                "summary": "Create template version by organization",
-                "operationId": "create-template-version-by-organization",
+                "summary": "Create organization",
+                "operationId": "create-organization",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Create template version request",
+                        "description": "Create organization request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateVersionRequest"
+                            "$ref": "#/definitions/codersdk.CreateOrganizationRequest"
                         }
                     }
                 ],
@@ -1589,13 +1576,13 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "$ref": "#/definitions/codersdk.Organization"
                         }
                     }
                 }
             }
         },
-        "/regions": {
+        "/organizations/{organization}": {
             "get": {
                 "security": [
                     {
@@ -1606,21 +1593,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "WorkspaceProxies"
+                    "Organizations"
+                ],
+                "summary": "Get organization by ID",
+                "operationId": "get-organization-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get site-wide regions for workspace connections",
-                "operationId": "get-site-wide-regions-for-workspace-connections",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.RegionsResponse"
+                            "$ref": "#/definitions/codersdk.Organization"
                         }
                     }
                 }
             }
         },
-        "/replicas": {
+        "/organizations/{organization}/groups": {
             "get": {
                 "security": [
                     {
@@ -1633,44 +1630,78 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Get active replicas",
-                "operationId": "get-active-replicas",
+                "summary": "Get groups by organization",
+                "operationId": "get-groups-by-organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.Replica"
+                                "$ref": "#/definitions/codersdk.Group"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/scim/v2/Users": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "SCIM 2.0: Get users",
-                "operationId": "scim-get-users",
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    }
-                }
-            },
-            "post": {
-                "security": [
+                "summary": "Create group for organization",
+                "operationId": "create-group-for-organization",
+                "parameters": [
+                    {
+                        "description": "Create group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateGroupRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Group"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/groups/{groupName}": {
+            "get": {
+                "security": [
                     {
                         "CoderSessionToken": []
                     }
@@ -1681,30 +1712,36 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "SCIM 2.0: Create new user",
-                "operationId": "scim-create-new-user",
+                "summary": "Get group by organization and group name",
+                "operationId": "get-group-by-organization-and-group-name",
                 "parameters": [
                     {
-                        "description": "New user",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
+                            "$ref": "#/definitions/codersdk.Group"
                         }
                     }
                 }
             }
         },
-        "/scim/v2/Users/{id}": {
+        "/organizations/{organization}/members/roles": {
             "get": {
                 "security": [
                     {
@@ -1712,59 +1749,76 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Members"
                 ],
-                "summary": "SCIM 2.0: Get user by ID",
-                "operationId": "scim-get-user-by-id",
+                "summary": "Get member roles by organization",
+                "operationId": "get-member-roles-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "404": {
-                        "description": "Not Found"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.AssignableRoles"
+                            }
+                        }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/organizations/{organization}/members/{user}/roles": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Members"
                 ],
-                "summary": "SCIM 2.0: Update user account",
-                "operationId": "scim-update-user-status",
+                "summary": "Assign role to organization member",
+                "operationId": "assign-role-to-organization-member",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update user request",
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update roles request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
+                            "$ref": "#/definitions/codersdk.UpdateRoles"
                         }
                     }
                 ],
@@ -1772,47 +1826,68 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.OrganizationMember"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}": {
-            "get": {
+        "/organizations/{organization}/members/{user}/workspaces": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Workspaces"
                 ],
-                "summary": "Get template metadata by ID",
-                "operationId": "get-template-metadata-by-id",
+                "summary": "Create user workspace by organization",
+                "operationId": "create-user-workspace-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username, UUID, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Create workspace request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateWorkspaceRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
+                            "$ref": "#/definitions/codersdk.Workspace"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/organizations/{organization}/provisionerdaemons": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -1822,16 +1897,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Delete template by ID",
-                "operationId": "delete-template-by-id",
+                "summary": "Get provisioner daemons",
+                "operationId": "get-provisioner-daemons",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -1840,46 +1915,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerDaemon"
+                            }
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/organizations/{organization}/provisionerdaemons/serve": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Update template metadata by ID",
-                "operationId": "update-template-metadata-by-id",
+                "summary": "Serve provisioner daemon",
+                "operationId": "serve-provisioner-daemon",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
-                        }
+                    "101": {
+                        "description": "Switching Protocols"
                     }
                 }
             }
         },
-        "/templates/{template}/acl": {
+        "/organizations/{organization}/templates": {
             "get": {
                 "security": [
                     {
@@ -1890,16 +1964,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get template ACLs",
-                "operationId": "get-template-acls",
+                "summary": "Get templates by organization",
+                "operationId": "get-templates-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -1910,13 +1984,13 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.TemplateUser"
+                                "$ref": "#/definitions/codersdk.Template"
                             }
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -1929,40 +2003,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Update template ACL",
-                "operationId": "update-template-acl",
+                "summary": "Create template by organization",
+                "operationId": "create-template-by-organization",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update template request",
+                        "description": "Request body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateTemplateACL"
+                            "$ref": "#/definitions/codersdk.CreateTemplateRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.Template"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/daus": {
+        "/organizations/{organization}/templates/examples": {
             "get": {
                 "security": [
                     {
@@ -1975,14 +2048,14 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get template DAUs by ID",
-                "operationId": "get-template-daus-by-id",
+                "summary": "Get template examples by organization",
+                "operationId": "get-template-examples-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -1991,13 +2064,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.DAUsResponse"
-                        }
-                    }
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateExample"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "/templates/{template}/versions": {
+        "/organizations/{organization}/templates/{templatename}": {
             "get": {
                 "security": [
                     {
@@ -2010,81 +2086,70 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "List template versions by template ID",
-                "operationId": "list-template-versions-by-template-id",
+                "summary": "Get templates by organization and template name",
+                "operationId": "get-templates-by-organization-and-template-name",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "After ID",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Template name",
+                        "name": "templatename",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersion"
-                            }
+                            "$ref": "#/definitions/codersdk.Template"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Update active template version by template ID",
-                "operationId": "update-active-template-version-by-template-id",
+                "summary": "Get template version by organization, template, and name",
+                "operationId": "get-template-version-by-organization-template-and-name",
                 "parameters": [
                     {
-                        "description": "Modified template version",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateActiveTemplateVersion"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Template name",
+                        "name": "templatename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template version name",
+                        "name": "templateversionname",
                         "in": "path",
                         "required": true
                     }
@@ -2093,13 +2158,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/versions/{templateversionname}": {
+        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}/previous": {
             "get": {
                 "security": [
                     {
@@ -2112,14 +2177,21 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get template version by template ID and name",
-                "operationId": "get-template-version-by-template-id-and-name",
+                "summary": "Get previous template version by organization, template, and name",
+                "operationId": "get-previous-template-version-by-organization-template-and-name",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "templatename",
                         "in": "path",
                         "required": true
                     },
@@ -2135,164 +2207,175 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersion"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}": {
-            "get": {
+        "/organizations/{organization}/templateversions": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get template version by ID",
-                "operationId": "get-template-version-by-id",
+                "summary": "Create template version by organization",
+                "operationId": "create-template-version-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Create template version request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/regions": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "WorkspaceProxies"
                 ],
-                "summary": "Patch template version by ID",
-                "operationId": "patch-template-version-by-id",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Patch template version request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get site-wide regions for workspace connections",
+                "operationId": "get-site-wide-regions-for-workspace-connections",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.PatchTemplateVersionRequest"
+                            "$ref": "#/definitions/codersdk.RegionsResponse"
                         }
                     }
+                }
+            }
+        },
+        "/replicas": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
                 ],
+                "summary": "Get active replicas",
+                "operationId": "get-active-replicas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Replica"
+                            }
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/cancel": {
-            "patch": {
+        "/scim/v2/Groups": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Cancel template version by ID",
-                "operationId": "cancel-template-version-by-id",
+                "summary": "SCIM 2.0: Get groups",
+                "operationId": "scim-get-groups",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter of eq comparisons joined by and",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1-based index of the first result",
+                        "name": "startIndex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results",
+                        "name": "count",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
-                        }
+                        "description": "OK"
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/dry-run": {
+            },
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Create template version dry-run",
-                "operationId": "create-template-version-dry-run",
+                "summary": "SCIM 2.0: Create new group",
+                "operationId": "scim-create-new-group",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Dry-run request",
+                        "description": "New group",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateVersionDryRunRequest"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 ],
@@ -2300,13 +2383,13 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/dry-run/{jobID}": {
+        "/scim/v2/Groups/{id}": {
             "get": {
                 "security": [
                     {
@@ -2314,85 +2397,148 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version dry-run by job ID",
-                "operationId": "get-template-version-dry-run-by-job-id",
+                "summary": "SCIM 2.0: Get group by ID",
+                "operationId": "scim-get-group-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
                     },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Replace group",
+                "operationId": "scim-replace-group",
+                "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Replace group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/dry-run/{jobID}/cancel": {
-            "patch": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Cancel template version dry-run by job ID",
-                "operationId": "cancel-template-version-dry-run-by-job-id",
+                "summary": "SCIM 2.0: Delete group",
+                "operationId": "scim-delete-group",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Update group",
+                "operationId": "scim-update-group",
+                "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMPatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/dry-run/{jobID}/logs": {
+        "/scim/v2/Users": {
             "get": {
                 "security": [
                     {
@@ -2400,64 +2546,40 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version dry-run logs by job ID",
-                "operationId": "get-template-version-dry-run-logs-by-job-id",
+                "summary": "SCIM 2.0: Get users",
+                "operationId": "scim-get-users",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Before Unix timestamp",
-                        "name": "before",
+                        "description": "Filter of eq comparisons joined by and",
+                        "name": "filter",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "After Unix timestamp",
-                        "name": "after",
+                        "description": "1-based index of the first result",
+                        "name": "startIndex",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
+                        "type": "integer",
+                        "description": "Maximum number of results",
+                        "name": "count",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
-                            }
-                        }
+                        "description": "OK"
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/dry-run/{jobID}/resources": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2467,42 +2589,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version dry-run resources by job ID",
-                "operationId": "get-template-version-dry-run-resources-by-job-id",
+                "summary": "SCIM 2.0: Create new user",
+                "operationId": "scim-create-new-user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
-                        "in": "path",
-                        "required": true
+                        "description": "New user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceResource"
-                            }
+                            "$ref": "#/definitions/coderd.SCIMUser"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/gitauth": {
+        "/scim/v2/Users/{id}": {
             "get": {
                 "security": [
                     {
@@ -2510,19 +2622,19 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get git auth by template version",
-                "operationId": "get-git-auth-by-template-version",
+                "summary": "SCIM 2.0: Get user by ID",
+                "operationId": "scim-get-user-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -2531,139 +2643,127 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersionGitAuth"
-                            }
+                            "$ref": "#/definitions/coderd.SCIMUser"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found"
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/logs": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get logs by template version",
-                "operationId": "get-logs-by-template-version",
+                "summary": "SCIM 2.0: Replace user account",
+                "operationId": "scim-replace-user",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Before log id",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "After log id",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
+                        "description": "Replace user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
-                            }
+                            "$ref": "#/definitions/coderd.SCIMUser"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/parameters": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Removed: Get parameters by template version",
-                "operationId": "removed-get-parameters-by-template-version",
+                "summary": "SCIM 2.0: Delete user",
+                "operationId": "scim-delete-user",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/resources": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get resources by template version",
-                "operationId": "get-resources-by-template-version",
+                "summary": "SCIM 2.0: Update user account",
+                "operationId": "scim-update-user-status",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMPatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceResource"
-                            }
+                            "$ref": "#/definitions/coderd.SCIMUser"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/rich-parameters": {
+        "/templates/{template}": {
             "get": {
                 "security": [
                     {
@@ -2676,14 +2776,14 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get rich parameters by template version",
-                "operationId": "get-rich-parameters-by-template-version",
+                "summary": "Get template metadata by ID",
+                "operationId": "get-template-metadata-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -2692,45 +2792,79 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersionParameter"
-                            }
+                            "$ref": "#/definitions/codersdk.Template"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/schema": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Removed: Get schema by template version",
-                "operationId": "removed-get-schema-by-template-version",
+                "summary": "Delete template by ID",
+                "operationId": "delete-template-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update template metadata by ID",
+                "operationId": "update-template-metadata-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Template"
+                        }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/variables": {
+        "/templates/{template}/acl": {
             "get": {
                 "security": [
                     {
@@ -2741,16 +2875,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template variables by template version",
-                "operationId": "get-template-variables-by-template-version",
+                "summary": "Get template ACLs",
+                "operationId": "get-template-acls",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -2761,34 +2895,94 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersionVariable"
+                                "$ref": "#/definitions/codersdk.TemplateUser"
                             }
                         }
                     }
                 }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Update template ACL",
+                "operationId": "update-template-acl",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateACL"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
             }
         },
-        "/updatecheck": {
+        "/templates/{template}/daus": {
             "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "General"
+                    "Templates"
+                ],
+                "summary": "Get template DAUs by ID",
+                "operationId": "get-template-daus-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Update check",
-                "operationId": "update-check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateCheckResponse"
+                            "$ref": "#/definitions/codersdk.DAUsResponse"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/templates/{template}/git-source": {
             "get": {
                 "security": [
                     {
@@ -2799,47 +2993,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get users",
-                "operationId": "get-users",
+                "summary": "Get template git source",
+                "operationId": "get-template-git-source",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "After ID",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GetUsersResponse"
+                            "$ref": "#/definitions/codersdk.TemplateGitSource"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2852,24 +3029,1810 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Create new user",
-                "operationId": "create-new-user",
+                "summary": "Update template git source",
+                "operationId": "update-template-git-source",
                 "parameters": [
                     {
-                        "description": "Create user request",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Git source",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateUserRequest"
+                            "$ref": "#/definitions/codersdk.UpdateTemplateGitSourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateGitSource"
                         }
                     }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Delete template git source",
+                "operationId": "delete-template-git-source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/templates/{template}/git-source/sync": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Sync template git source",
+                "operationId": "sync-template-git-source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sync request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.SyncTemplateGitSourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateGitSource"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/git-source/webhook": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Receive template git source webhook",
+                "operationId": "receive-template-git-source-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/insights/apps": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template app insights",
+                "operationId": "get-template-app-insights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Start time, defaults to 30 days before the end time",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "End time, defaults to now",
+                        "name": "end_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.AppInsightsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/rollouts": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version rollouts by template",
+                "operationId": "get-template-version-rollouts-by-template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionRollout"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Moves a percentage of the template's workspaces, optionally\nrestricted to the members of groups, to a template version in\nbatches. The rollout is paused when the share of failed builds\nexceeds the failure threshold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Create template version rollout",
+                "operationId": "create-template-version-rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rollout request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionRolloutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersionRollout"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/rollouts/{rollout}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version rollout",
+                "operationId": "get-template-version-rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Rollout ID",
+                        "name": "rollout",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersionRollout"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Pauses, resumes or aborts a rollout. Resuming a rollout\nretries the workspaces that failed to build.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update template version rollout",
+                "operationId": "update-template-version-rollout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Rollout ID",
+                        "name": "rollout",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateVersionRolloutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersionRollout"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "List template versions by template ID",
+                "operationId": "list-template-versions-by-template-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "After ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update active template version by template ID",
+                "operationId": "update-active-template-version-by-template-id",
+                "parameters": [
+                    {
+                        "description": "Modified template version",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateActiveTemplateVersion"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/versions/archive": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Archive unused template versions",
+                "operationId": "archive-unused-template-versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Archive template versions request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ArchiveTemplateVersionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ArchiveTemplateVersionsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/versions/{templateversionname}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version by template ID and name",
+                "operationId": "get-template-version-by-template-id-and-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template version name",
+                        "name": "templateversionname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version by ID",
+                "operationId": "get-template-version-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Patch template version by ID",
+                "operationId": "patch-template-version-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch template version request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PatchTemplateVersionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/cancel": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Cancel template version by ID",
+                "operationId": "cancel-template-version-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/diff": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Compare template versions",
+                "operationId": "compare-template-versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Base template version ID",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersionDiff"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/dry-run": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Create template version dry-run",
+                "operationId": "create-template-version-dry-run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dry-run request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionDryRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/dry-run/{jobID}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version dry-run by job ID",
+                "operationId": "get-template-version-dry-run-by-job-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/dry-run/{jobID}/cancel": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Cancel template version dry-run by job ID",
+                "operationId": "cancel-template-version-dry-run-by-job-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/dry-run/{jobID}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version dry-run logs by job ID",
+                "operationId": "get-template-version-dry-run-logs-by-job-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before Unix timestamp",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After Unix timestamp",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/dry-run/{jobID}/resources": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version dry-run resources by job ID",
+                "operationId": "get-template-version-dry-run-resources-by-job-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceResource"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/gitauth": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get git auth by template version",
+                "operationId": "get-git-auth-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionGitAuth"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get logs by template version",
+                "operationId": "get-logs-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before log id",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After log id",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/parameters": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Removed: Get parameters by template version",
+                "operationId": "removed-get-parameters-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/presets": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get presets by template version",
+                "operationId": "get-presets-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionPreset"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Replaces every preset of the template version.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update presets by template version",
+                "operationId": "update-presets-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template version presets request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateVersionPresetsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionPreset"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/resources": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get resources by template version",
+                "operationId": "get-resources-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceResource"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/rich-parameters": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get rich parameters by template version",
+                "operationId": "get-rich-parameters-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionParameter"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/schema": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Removed: Get schema by template version",
+                "operationId": "removed-get-schema-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/state": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update template version state",
+                "operationId": "update-template-version-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template version state request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateVersionStateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/variables": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template variables by template version",
+                "operationId": "get-template-variables-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionVariable"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/updatecheck": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "General"
+                ],
+                "summary": "Update check",
+                "operationId": "update-check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateCheckResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get users",
+                "operationId": "get-users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "After ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.GetUsersResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create new user",
+                "operationId": "create-new-user",
+                "parameters": [
+                    {
+                        "description": "Create user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/authmethods": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get authentication methods",
+                "operationId": "get-authentication-methods",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.AuthMethods"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/first": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Check initial user created",
+                "operationId": "check-initial-user-created",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create initial user",
+                "operationId": "create-initial-user",
+                "parameters": [
+                    {
+                        "description": "First user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateFirstUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateFirstUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Log in user",
+                "operationId": "log-in-user",
+                "parameters": [
+                    {
+                        "description": "Login request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/totp": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Enroll in TOTP at login",
+                "operationId": "enroll-in-totp-at-login",
+                "parameters": [
+                    {
+                        "description": "Login request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TOTPEnrollment"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/logout": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Log out user",
+                "operationId": "log-out-user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oauth2/github/callback": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "OAuth 2.0 GitHub Callback",
+                "operationId": "oauth-20-github-callback",
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
+                    }
+                }
+            }
+        },
+        "/users/oidc/callback": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "OpenID Connect Callback",
+                "operationId": "openid-connect-callback",
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
+                    }
+                }
+            }
+        },
+        "/users/oidc/{provider}/callback": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "OpenID Connect Callback for an additional provider",
+                "operationId": "openid-connect-callback-for-an-additional-provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OIDC provider ID",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
+                    }
+                }
+            }
+        },
+        "/users/roles": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Members"
+                ],
+                "summary": "Get site member roles",
+                "operationId": "get-site-member-roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.AssignableRoles"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user by name",
+                "operationId": "get-user-by-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete user",
+                "operationId": "delete-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/codersdk.User"
                         }
@@ -2877,7 +4840,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/authmethods": {
+        "/users/{user}/gitsshkey": {
             "get": {
                 "security": [
                     {
@@ -2890,20 +4853,27 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get authentication methods",
-                "operationId": "get-authentication-methods",
+                "summary": "Get user Git SSH key",
+                "operationId": "get-user-git-ssh-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.AuthMethods"
+                            "$ref": "#/definitions/codersdk.GitSSHKey"
                         }
                     }
                 }
-            }
-        },
-        "/users/first": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2915,91 +4885,97 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Check initial user created",
-                "operationId": "check-initial-user-created",
+                "summary": "Regenerate user SSH key",
+                "operationId": "regenerate-user-ssh-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.GitSSHKey"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/users/{user}/impersonate": {
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Create initial user",
-                "operationId": "create-initial-user",
+                "summary": "Impersonate user",
+                "operationId": "impersonate-user",
                 "parameters": [
                     {
-                        "description": "First user request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateFirstUserRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateFirstUserResponse"
+                            "$ref": "#/definitions/codersdk.ImpersonateUserResponse"
                         }
                     }
                 }
             }
         },
-        "/users/login": {
+        "/users/{user}/keys": {
             "post": {
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authorization"
+                    "Users"
                 ],
-                "summary": "Log in user",
-                "operationId": "log-in-user",
+                "summary": "Create new session key",
+                "operationId": "create-new-session-key",
                 "parameters": [
                     {
-                        "description": "Login request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.LoginWithPasswordRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
+                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
                         }
                     }
                 }
             }
         },
-        "/users/logout": {
-            "post": {
+        "/users/{user}/keys/tokens": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3011,57 +4987,109 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Log out user",
-                "operationId": "log-out-user",
+                "summary": "Get user tokens",
+                "operationId": "get-user-tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.APIKey"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/users/oauth2/github/callback": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "OAuth 2.0 GitHub Callback",
-                "operationId": "oauth-20-github-callback",
+                "summary": "Create token API key",
+                "operationId": "create-token-api-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create token request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTokenRequest"
+                        }
+                    }
+                ],
                 "responses": {
-                    "307": {
-                        "description": "Temporary Redirect"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                        }
                     }
                 }
             }
         },
-        "/users/oidc/callback": {
+        "/users/{user}/keys/tokens/tokenconfig": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Users"
+                    "General"
+                ],
+                "summary": "Get token config",
+                "operationId": "get-token-config",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "OpenID Connect Callback",
-                "operationId": "openid-connect-callback",
                 "responses": {
-                    "307": {
-                        "description": "Temporary Redirect"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TokenConfig"
+                        }
                     }
                 }
             }
         },
-        "/users/roles": {
+        "/users/{user}/keys/tokens/{keyname}": {
             "get": {
                 "security": [
                     {
@@ -3072,24 +5100,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Members"
+                    "Users"
+                ],
+                "summary": "Get API key by token name",
+                "operationId": "get-api-key-by-token-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "string",
+                        "description": "Key Name",
+                        "name": "keyname",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get site member roles",
-                "operationId": "get-site-member-roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.AssignableRoles"
-                            }
+                            "$ref": "#/definitions/codersdk.APIKey"
                         }
                     }
                 }
             }
         },
-        "/users/{user}": {
+        "/users/{user}/keys/{keyid}": {
             "get": {
                 "security": [
                     {
@@ -3102,8 +5144,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get user by name",
-                "operationId": "get-user-by-name",
+                "summary": "Get API key by ID",
+                "operationId": "get-api-key-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -3111,18 +5153,62 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Key ID",
+                        "name": "keyid",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.APIKey"
                         }
                     }
                 }
-            },
-            "delete": {
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete API key",
+                "operationId": "delete-api-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Key ID",
+                        "name": "keyid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/users/{user}/organizations": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3134,8 +5220,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Delete user",
-                "operationId": "delete-user",
+                "summary": "Get organizations by user",
+                "operationId": "get-organizations-by-user",
                 "parameters": [
                     {
                         "type": "string",
@@ -3149,13 +5235,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Organization"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users/{user}/gitsshkey": {
+        "/users/{user}/organizations/{organizationname}": {
             "get": {
                 "security": [
                     {
@@ -3168,8 +5257,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get user Git SSH key",
-                "operationId": "get-user-git-ssh-key",
+                "summary": "Get organization by user and organization name",
+                "operationId": "get-organization-by-user-and-organization-name",
                 "parameters": [
                     {
                         "type": "string",
@@ -3177,31 +5266,40 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organizationname",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GitSSHKey"
+                            "$ref": "#/definitions/codersdk.Organization"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/users/{user}/password": {
             "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Regenerate user SSH key",
-                "operationId": "regenerate-user-ssh-key",
+                "summary": "Update user password",
+                "operationId": "update-user-password",
                 "parameters": [
                     {
                         "type": "string",
@@ -3209,33 +5307,42 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update password request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.GitSSHKey"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/users/{user}/keys": {
-            "post": {
+        "/users/{user}/profile": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Create new session key",
-                "operationId": "create-new-session-key",
+                "summary": "Update user profile",
+                "operationId": "update-user-profile",
                 "parameters": [
                     {
                         "type": "string",
@@ -3243,19 +5350,28 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Updated profile",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserProfileRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/keys/tokens": {
+        "/users/{user}/roles": {
             "get": {
                 "security": [
                     {
@@ -3268,8 +5384,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get user tokens",
-                "operationId": "get-user-tokens",
+                "summary": "Get user roles",
+                "operationId": "get-user-roles",
                 "parameters": [
                     {
                         "type": "string",
@@ -3283,15 +5399,12 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.APIKey"
-                            }
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3306,8 +5419,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Create token API key",
-                "operationId": "create-token-api-key",
+                "summary": "Assign role to user",
+                "operationId": "assign-role-to-user",
                 "parameters": [
                     {
                         "type": "string",
@@ -3317,60 +5430,26 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Create token request",
+                        "description": "Update roles request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTokenRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                            "$ref": "#/definitions/codersdk.UpdateRoles"
                         }
                     }
-                }
-            }
-        },
-        "/users/{user}/keys/tokens/tokenconfig": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "General"
-                ],
-                "summary": "Get token config",
-                "operationId": "get-token-config",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
-                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TokenConfig"
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/keys/tokens/{keyname}": {
+        "/users/{user}/sessions": {
             "get": {
                 "security": [
                     {
@@ -3383,8 +5462,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get API key by token name",
-                "operationId": "get-api-key-by-token-name",
+                "summary": "Get user sessions",
+                "operationId": "get-user-sessions",
                 "parameters": [
                     {
                         "type": "string",
@@ -3392,41 +5471,31 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "string",
-                        "description": "Key Name",
-                        "name": "keyname",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.APIKey"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Session"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/keys/{keyid}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get API key by ID",
-                "operationId": "get-api-key-by-id",
+                "summary": "Revoke other user sessions",
+                "operationId": "revoke-other-user-sessions",
                 "parameters": [
                     {
                         "type": "string",
@@ -3434,25 +5503,16 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Key ID",
-                        "name": "keyid",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.APIKey"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            },
+            }
+        },
+        "/users/{user}/sessions/{session}": {
             "delete": {
                 "security": [
                     {
@@ -3462,8 +5522,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Delete API key",
-                "operationId": "delete-api-key",
+                "summary": "Revoke user session",
+                "operationId": "revoke-user-session",
                 "parameters": [
                     {
                         "type": "string",
@@ -3474,9 +5534,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Key ID",
-                        "name": "keyid",
+                        "description": "Session ID",
+                        "name": "session",
                         "in": "path",
                         "required": true
                     }
@@ -3488,8 +5547,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{user}/organizations": {
-            "get": {
+        "/users/{user}/status/activate": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3501,8 +5560,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get organizations by user",
-                "operationId": "get-organizations-by-user",
+                "summary": "Activate user account",
+                "operationId": "activate-user-account",
                 "parameters": [
                     {
                         "type": "string",
@@ -3516,17 +5575,14 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.Organization"
-                            }
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/organizations/{organizationname}": {
-            "get": {
+        "/users/{user}/status/suspend": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3538,8 +5594,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get organization by user and organization name",
-                "operationId": "get-organization-by-user-and-organization-name",
+                "summary": "Suspend user account",
+                "operationId": "suspend-user-account",
                 "parameters": [
                     {
                         "type": "string",
@@ -3547,40 +5603,33 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Organization name",
-                        "name": "organizationname",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Organization"
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/password": {
-            "put": {
+        "/users/{user}/totp": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Update user password",
-                "operationId": "update-user-password",
+                "summary": "Get user TOTP enrollment",
+                "operationId": "get-user-totp-enrollment",
                 "parameters": [
                     {
                         "type": "string",
@@ -3588,33 +5637,22 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Update password request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateUserPasswordRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserTOTP"
+                        }
                     }
                 }
-            }
-        },
-        "/users/{user}/profile": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
-                    }
-                ],
-                "consumes": [
-                    "application/json"
+                    }
                 ],
                 "produces": [
                     "application/json"
@@ -3622,8 +5660,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Update user profile",
-                "operationId": "update-user-profile",
+                "summary": "Enroll user in TOTP",
+                "operationId": "enroll-user-in-totp",
                 "parameters": [
                     {
                         "type": "string",
@@ -3631,42 +5669,31 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated profile",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateUserProfileRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.TOTPEnrollment"
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/roles": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get user roles",
-                "operationId": "get-user-roles",
+                "summary": "Reset user TOTP enrollment",
+                "operationId": "reset-user-totp-enrollment",
                 "parameters": [
                     {
                         "type": "string",
@@ -3674,18 +5701,26 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Reset request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ResetTOTPRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.User"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/users/{user}/totp/recovery-codes": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3700,8 +5735,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Assign role to user",
-                "operationId": "assign-role-to-user",
+                "summary": "Regenerate user TOTP recovery codes",
+                "operationId": "regenerate-user-totp-recovery-codes",
                 "parameters": [
                     {
                         "type": "string",
@@ -3711,12 +5746,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Update roles request",
+                        "description": "Verify request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateRoles"
+                            "$ref": "#/definitions/codersdk.VerifyTOTPRequest"
                         }
                     }
                 ],
@@ -3724,27 +5759,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.TOTPRecoveryCodes"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/status/activate": {
-            "put": {
+        "/users/{user}/totp/verify": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Activate user account",
-                "operationId": "activate-user-account",
+                "summary": "Verify user TOTP enrollment",
+                "operationId": "verify-user-totp-enrollment",
                 "parameters": [
                     {
                         "type": "string",
@@ -3752,33 +5790,39 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Verify request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.VerifyTOTPRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.TOTPRecoveryCodes"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/status/suspend": {
+        "/users/{user}/unlock": {
             "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Suspend user account",
-                "operationId": "suspend-user-account",
+                "summary": "Unlock user account",
+                "operationId": "unlock-user-account",
                 "parameters": [
                     {
                         "type": "string",
@@ -3789,11 +5833,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.User"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
@@ -4094,6 +6135,14 @@ const docTemplate = `{
                 ],
                 "summary": "Coordinate workspace agent via Tailnet",
                 "operationId": "coordinate-workspace-agent-via-tailnet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Coordination protocol version",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "101": {
                         "description": "Switching Protocols"
@@ -4101,6 +6150,31 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaceagents/me/derp-map": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Watch DERP map of the authorized workspace agent",
+                "operationId": "watch-derp-map-of-the-authorized-workspace-agent",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tailcfg.DERPMap"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaceagents/me/gitauth": {
             "get": {
                 "security": [
@@ -4485,6 +6559,12 @@ const docTemplate = `{
                         "name": "workspaceagent",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Coordination protocol version",
+                        "name": "version",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -4942,6 +7022,42 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaceproxies/me/app-usage": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Report workspace app usage",
+                "operationId": "report-workspace-app-usage",
+                "parameters": [
+                    {
+                        "description": "Report app usage request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.ReportAppUsageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
         "/workspaceproxies/me/goingaway": {
             "post": {
                 "security": [
@@ -5487,14 +7603,139 @@ const docTemplate = `{
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Extend workspace deadline by ID",
+                "operationId": "extend-workspace-deadline-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Extend deadline update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PutExtendWorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{workspace}/port-shares": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get workspace port shares",
+                "operationId": "get-workspace-port-shares",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspacePortShare"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Upsert workspace port share",
+                "operationId": "upsert-workspace-port-share",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upsert port share request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpsertWorkspacePortShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspacePortShare"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Extend workspace deadline by ID",
-                "operationId": "extend-workspace-deadline-by-id",
+                "summary": "Delete workspace port share",
+                "operationId": "delete-workspace-port-share",
                 "parameters": [
                     {
                         "type": "string",
@@ -5505,21 +7746,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Extend deadline update request",
+                        "description": "Delete port share request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.PutExtendWorkspaceRequest"
+                            "$ref": "#/definitions/codersdk.DeleteWorkspacePortShareRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
@@ -5797,6 +8035,21 @@ const docTemplate = `{
                 }
             }
         },
+        "agentsdk.PeerConnection": {
+            "type": "object",
+            "properties": {
+                "derp_region_id": {
+                    "description": "DERPRegionID is the region relaying traffic when the connection isn't\ndirect.",
+                    "type": "integer"
+                },
+                "direct": {
+                    "type": "boolean"
+                },
+                "latency_ms": {
+                    "type": "number"
+                }
+            }
+        },
         "agentsdk.PostAppHealthsRequest": {
             "type": "object",
             "properties": {
@@ -5892,6 +8145,13 @@ const docTemplate = `{
                         "$ref": "#/definitions/agentsdk.AgentMetric"
                     }
                 },
+                "peer_connections": {
+                    "description": "PeerConnections is a snapshot of the active tailnet connections to the\nagent. Nil means the agent doesn't report connections.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/agentsdk.PeerConnection"
+                    }
+                },
                 "rx_bytes": {
                     "description": "RxBytes is the number of received bytes.",
                     "type": "integer"
@@ -6054,6 +8314,17 @@ const docTemplate = `{
                 }
             }
         },
+        "clibase.Struct-array_codersdk_OIDCProviderConfig": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.OIDCProviderConfig"
+                    }
+                }
+            }
+        },
         "clibase.URL": {
             "type": "object",
             "properties": {
@@ -6123,6 +8394,80 @@ const docTemplate = `{
                 "ValueSourceDefault"
             ]
         },
+        "coderd.SCIMGroup": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMMember"
+                    }
+                },
+                "meta": {
+                    "type": "object",
+                    "properties": {
+                        "resourceType": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "coderd.SCIMMember": {
+            "type": "object",
+            "properties": {
+                "display": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "coderd.SCIMPatchOperation": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "description": "Op is add, remove or replace, case-insensitive.",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "object"
+                }
+            }
+        },
+        "coderd.SCIMPatchRequest": {
+            "type": "object",
+            "properties": {
+                "Operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMPatchOperation"
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "coderd.SCIMUser": {
             "type": "object",
             "properties": {
@@ -6151,8 +8496,11 @@ const docTemplate = `{
                     }
                 },
                 "groups": {
+                    "description": "Groups is read-only, membership is managed with the Groups resource.",
                     "type": "array",
-                    "items": {}
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMMember"
+                    }
                 },
                 "id": {
                     "type": "string"
@@ -6306,6 +8654,62 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.AppInsight": {
+            "type": "object",
+            "properties": {
+                "average_latency_ms": {
+                    "description": "AverageLatencyMS is the average time until the app sent the response\nheaders.",
+                    "type": "number"
+                },
+                "bytes_sent": {
+                    "type": "integer"
+                },
+                "display_name": {
+                    "description": "DisplayName and Icon are empty if the active version of the template\ndoes not declare the app.",
+                    "type": "string"
+                },
+                "error_requests": {
+                    "description": "ErrorRequests is the number of requests that failed with a 5xx status.",
+                    "type": "integer"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "unique_users": {
+                    "description": "UniqueUsers includes unauthenticated users of public apps as a single\nuser.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.AppInsightsResponse": {
+            "type": "object",
+            "properties": {
+                "apps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.AppInsight"
+                    }
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
         "codersdk.AppearanceConfig": {
             "type": "object",
             "properties": {
@@ -6323,6 +8727,34 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ArchiveTemplateVersionsRequest": {
+            "type": "object",
+            "properties": {
+                "template_version_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                }
+            }
+        },
+        "codersdk.ArchiveTemplateVersionsResponse": {
+            "type": "object",
+            "properties": {
+                "archived_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.AssignableRoles": {
             "type": "object",
             "properties": {
@@ -6347,7 +8779,9 @@ const docTemplate = `{
                 "stop",
                 "login",
                 "logout",
-                "register"
+                "register",
+                "connect",
+                "lockout"
             ],
             "x-enum-varnames": [
                 "AuditActionCreate",
@@ -6357,7 +8791,9 @@ const docTemplate = `{
                 "AuditActionStop",
                 "AuditActionLogin",
                 "AuditActionLogout",
-                "AuditActionRegister"
+                "AuditActionRegister",
+                "AuditActionConnect",
+                "AuditActionLockout"
             ]
         },
         "codersdk.AuditDiff": {
@@ -6475,6 +8911,13 @@ const docTemplate = `{
                 "oidc": {
                     "$ref": "#/definitions/codersdk.OIDCAuthMethod"
                 },
+                "oidc_providers": {
+                    "description": "OIDCProviders are the additional OpenID Connect providers users can\nsign in with.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.OIDCProviderAuthMethod"
+                    }
+                },
                 "password": {
                     "$ref": "#/definitions/codersdk.AuthMethod"
                 }
@@ -6580,6 +9023,25 @@ const docTemplate = `{
                 "BuildReasonAutostop"
             ]
         },
+        "codersdk.CreateDERPRegionPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "region_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.CreateFirstUserRequest": {
             "type": "object",
             "required": [
@@ -6629,6 +9091,37 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateManagedDERPRegionRequest": {
+            "type": "object",
+            "required": [
+                "nodes",
+                "region_code",
+                "region_id",
+                "region_name"
+            ],
+            "properties": {
+                "avoid": {
+                    "type": "boolean"
+                },
+                "nodes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/codersdk.ManagedDERPNode"
+                    }
+                },
+                "region_code": {
+                    "type": "string"
+                },
+                "region_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "region_name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateOrganizationRequest": {
             "type": "object",
             "required": [
@@ -6775,6 +9268,45 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateTemplateVersionRolloutRequest": {
+            "type": "object",
+            "required": [
+                "batch_size",
+                "percentage",
+                "template_version_id"
+            ],
+            "properties": {
+                "batch_size": {
+                    "description": "BatchSize is the maximum number of builds in progress at once.",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "failure_threshold_percent": {
+                    "description": "FailureThresholdPercent pauses the rollout when the percentage of\nfailed builds exceeds it.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "group_ids": {
+                    "description": "GroupIDs restrict the cohort to workspaces owned by members of the\ngroups.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "percentage": {
+                    "description": "Percentage of the cohort to move to the template version.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.CreateTestAuditLogRequest": {
             "type": "object",
             "properties": {
@@ -6880,6 +9412,10 @@ const docTemplate = `{
                 "password": {
                     "type": "string"
                 },
+                "service_account": {
+                    "description": "ServiceAccount creates a non-human account owned by the organization.\nService accounts cannot log in, only authenticate with tokens, and are\nnot counted as licensed users.",
+                    "type": "boolean"
+                },
                 "username": {
                     "type": "string"
                 }
@@ -6982,6 +9518,11 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "template_version_preset_id": {
+                    "description": "TemplateVersionPresetID selects a preset of the active template version.\nIts values are used for parameters that are missing from\nRichParameterValues.",
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "ttl_ms": {
                     "type": "integer"
                 }
@@ -7035,14 +9576,46 @@ const docTemplate = `{
                 }
             }
         },
-        "codersdk.DERPRegion": {
+        "codersdk.DERPRegion": {
+            "type": "object",
+            "properties": {
+                "latency_ms": {
+                    "type": "number"
+                },
+                "preferred": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "codersdk.DERPRegionPolicy": {
             "type": "object",
             "properties": {
-                "latency_ms": {
-                    "type": "number"
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
                 },
-                "preferred": {
-                    "type": "boolean"
+                "group_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "region_ids": {
+                    "description": "RegionIDs are the DERP regions that may be used, including regions\nof the static DERP map.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
                 }
             }
         },
@@ -7086,6 +9659,21 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.DeleteWorkspacePortShareRequest": {
+            "type": "object",
+            "required": [
+                "port"
+            ],
+            "properties": {
+                "agent_name": {
+                    "description": "AgentName may be left empty if the workspace has a single agent.",
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.DeploymentConfig": {
             "type": "object",
             "properties": {
@@ -7134,11 +9722,7 @@ const docTemplate = `{
                 },
                 "address": {
                     "description": "DEPRECATED: Use HTTPAddress or TLS.Address instead.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/clibase.HostPort"
-                        }
-                    ]
+                    "$ref": "#/definitions/clibase.HostPort"
                 },
                 "agent_fallback_troubleshooting_url": {
                     "$ref": "#/definitions/clibase.URL"
@@ -7153,6 +9737,7 @@ const docTemplate = `{
                     "type": "boolean"
                 },
                 "cache_directory": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 },
                 "config": {
@@ -7167,6 +9752,9 @@ const docTemplate = `{
                 "derp": {
                     "$ref": "#/definitions/codersdk.DERP"
                 },
+                "disable_impersonation": {
+                    "type": "boolean"
+                },
                 "disable_owner_workspace_exec": {
                     "type": "boolean"
                 },
@@ -7198,6 +9786,9 @@ const docTemplate = `{
                 "logging": {
                     "$ref": "#/definitions/codersdk.LoggingConfig"
                 },
+                "login_lockout": {
+                    "$ref": "#/definitions/codersdk.LoginLockoutConfig"
+                },
                 "max_session_expiry": {
                     "type": "integer"
                 },
@@ -7214,6 +9805,7 @@ const docTemplate = `{
                     "$ref": "#/definitions/codersdk.OIDCConfig"
                 },
                 "pg_connection_url": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 },
                 "pprof": {
@@ -7246,13 +9838,18 @@ const docTemplate = `{
                 "redirect_to_access_url": {
                     "type": "boolean"
                 },
+                "require_owner_mfa": {
+                    "type": "boolean"
+                },
                 "scim_api_key": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 },
                 "secure_auth_cookie": {
                     "type": "boolean"
                 },
                 "ssh_keygen_algorithm": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 },
                 "strict_transport_security": {
@@ -7286,6 +9883,7 @@ const docTemplate = `{
                     "type": "boolean"
                 },
                 "wgtunnel_host": {
+                    "description": "DeploymentName is the config-ssh Hostname prefix",
                     "type": "string"
                 },
                 "wildcard_access_url": {
@@ -7346,12 +9944,14 @@ const docTemplate = `{
             "enum": [
                 "moons",
                 "workspace_actions",
-                "workspace_filter"
+                "workspace_filter",
+                "tailnet_pg_coordinator"
             ],
             "x-enum-varnames": [
                 "ExperimentMoons",
                 "ExperimentWorkspaceActions",
-                "ExperimentWorkspaceFilter"
+                "ExperimentWorkspaceFilter",
+                "ExperimentTailnetPGCoordinator"
             ]
         },
         "codersdk.Feature": {
@@ -7508,6 +10108,18 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ImpersonateUserResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.IssueReconnectingPTYSignedTokenRequest": {
             "type": "object",
             "required": [
@@ -7610,6 +10222,9 @@ const docTemplate = `{
         "codersdk.LoggingConfig": {
             "type": "object",
             "properties": {
+                "app_access_sample_percent": {
+                    "type": "integer"
+                },
                 "human": {
                     "type": "string"
                 },
@@ -7621,6 +10236,20 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.LoginLockoutConfig": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "integer"
+                },
+                "ip_threshold": {
+                    "type": "integer"
+                },
+                "threshold": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.LoginType": {
             "type": "string",
             "enum": [
@@ -7628,14 +10257,18 @@ const docTemplate = `{
                 "github",
                 "oidc",
                 "token",
-                "none"
+                "none",
+                "oauth2_provider_app",
+                "impersonation"
             ],
             "x-enum-varnames": [
                 "LoginTypePassword",
                 "LoginTypeGithub",
                 "LoginTypeOIDC",
                 "LoginTypeToken",
-                "LoginTypeNone"
+                "LoginTypeNone",
+                "LoginTypeOAuth2ProviderApp",
+                "LoginTypeImpersonation"
             ]
         },
         "codersdk.LoginWithPasswordRequest": {
@@ -7651,16 +10284,127 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string"
+                },
+                "recovery_code": {
+                    "description": "RecoveryCode can be used once instead of TOTPCode.",
+                    "type": "string"
+                },
+                "totp_code": {
+                    "description": "TOTPCode is the code of the user's authenticator app. It is required\nwhen the response of a login without it sets MFARequired or\nMFAEnrollmentRequired.",
+                    "type": "string"
                 }
             }
         },
         "codersdk.LoginWithPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "mfa_enrollment_required": {
+                    "description": "MFAEnrollmentRequired is set if the user must enroll an authenticator\napp before logging in. See Client.EnrollTOTPWithPassword.",
+                    "type": "boolean"
+                },
+                "mfa_required": {
+                    "description": "MFARequired is set if the login must be repeated with a TOTP or\nrecovery code.",
+                    "type": "boolean"
+                },
+                "recovery_codes": {
+                    "description": "RecoveryCodes are set when the login verified an enrollment.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "session_token": {
+                    "description": "SessionToken is empty if another factor is required.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.ManagedDERPNode": {
             "type": "object",
             "required": [
-                "session_token"
+                "name"
             ],
             "properties": {
-                "session_token": {
+                "derp_port": {
+                    "description": "DERPPort defaults to 443.",
+                    "type": "integer"
+                },
+                "force_http": {
+                    "type": "boolean"
+                },
+                "host_name": {
+                    "type": "string"
+                },
+                "ipv4": {
+                    "type": "string"
+                },
+                "ipv6": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stun_only": {
+                    "type": "boolean"
+                },
+                "stun_port": {
+                    "description": "STUNPort defaults to 3478, -1 disables STUN.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.ManagedDERPRegion": {
+            "type": "object",
+            "properties": {
+                "avoid": {
+                    "description": "Avoid prevents clients from picking the region as their home region.\nThe region is still used to reach peers that picked it.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "health_error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "last_health_check_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.ManagedDERPNode"
+                    }
+                },
+                "region_code": {
+                    "type": "string"
+                },
+                "region_id": {
+                    "type": "integer"
+                },
+                "region_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.OAuth2AppEndpoints": {
+            "type": "object",
+            "properties": {
+                "authorization": {
+                    "type": "string"
+                },
+                "revocation": {
+                    "type": "string"
+                },
+                "token": {
                     "type": "string"
                 }
             }
@@ -7682,58 +10426,202 @@ const docTemplate = `{
                 "allow_signups": {
                     "type": "boolean"
                 },
-                "allowed_orgs": {
+                "allowed_orgs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "allowed_teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "client_secret": {
+                    "type": "string"
+                },
+                "enterprise_base_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.OAuth2ProviderApp": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "endpoints": {
+                    "description": "Endpoints are included in the app response for easier discovery. The\nOAuth2 spec does not have a defined place to find these (for comparison,\nOIDC has a '/.well-known/openid-configuration' endpoint).",
+                    "$ref": "#/definitions/codersdk.OAuth2AppEndpoints"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.OAuth2ProviderAppSecret": {
+            "type": "object",
+            "properties": {
+                "client_secret_truncated": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.OAuth2ProviderAppSecretFull": {
+            "type": "object",
+            "properties": {
+                "client_secret_full": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.OIDCAuthMethod": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "iconUrl": {
+                    "type": "string"
+                },
+                "signInText": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.OIDCConfig": {
+            "type": "object",
+            "properties": {
+                "allow_signups": {
+                    "type": "boolean"
+                },
+                "auth_url_params": {
+                    "type": "object"
+                },
+                "client_id": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
+                    "type": "string"
+                },
+                "client_secret": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
+                    "type": "string"
+                },
+                "email_domain": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "allowed_teams": {
+                "email_field": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
+                    "type": "string"
+                },
+                "group_mapping": {
+                    "type": "object"
+                },
+                "groups_field": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
+                    "type": "string"
+                },
+                "icon_url": {
+                    "$ref": "#/definitions/clibase.URL"
+                },
+                "ignore_email_verified": {
+                    "type": "boolean"
+                },
+                "ignore_user_info": {
+                    "type": "boolean"
+                },
+                "issuer_url": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
+                    "type": "string"
+                },
+                "providers": {
+                    "description": "Providers are OpenID Connect identity providers trusted in addition\nto the one configured above.",
+                    "$ref": "#/definitions/clibase.Struct-array_codersdk_OIDCProviderConfig"
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "client_id": {
+                "sign_in_text": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 },
-                "client_secret": {
+                "user_role_field": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 },
-                "enterprise_base_url": {
+                "user_role_mapping": {
+                    "type": "object"
+                },
+                "user_roles_default": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "username_field": {
+                    "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
                 }
             }
         },
-        "codersdk.OIDCAuthMethod": {
+        "codersdk.OIDCProviderAuthMethod": {
             "type": "object",
             "properties": {
-                "enabled": {
-                    "type": "boolean"
-                },
                 "iconUrl": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
                 "signInText": {
                     "type": "string"
                 }
             }
         },
-        "codersdk.OIDCConfig": {
+        "codersdk.OIDCProviderConfig": {
             "type": "object",
             "properties": {
                 "allow_signups": {
                     "type": "boolean"
                 },
                 "auth_url_params": {
-                    "type": "object"
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
                 "client_id": {
                     "type": "string"
                 },
-                "client_secret": {
-                    "type": "string"
-                },
                 "email_domain": {
                     "type": "array",
                     "items": {
@@ -7744,13 +10632,19 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "group_mapping": {
-                    "type": "object"
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
                 "groups_field": {
                     "type": "string"
                 },
                 "icon_url": {
-                    "$ref": "#/definitions/clibase.URL"
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
                 },
                 "ignore_email_verified": {
                     "type": "boolean"
@@ -7770,6 +10664,24 @@ const docTemplate = `{
                 "sign_in_text": {
                     "type": "string"
                 },
+                "user_role_field": {
+                    "type": "string"
+                },
+                "user_role_mapping": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "user_roles_default": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "username_field": {
                     "type": "string"
                 }
@@ -7863,6 +10775,26 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.PostOAuth2ProviderAppRequest": {
+            "type": "object",
+            "required": [
+                "callback_url",
+                "name"
+            ],
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
         "codersdk.PprofConfig": {
             "type": "object",
             "properties": {
@@ -8130,6 +11062,26 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.PutOAuth2ProviderAppRequest": {
+            "type": "object",
+            "required": [
+                "callback_url",
+                "name"
+            ],
+            "properties": {
+                "callback_url": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
         "codersdk.RBACResource": {
             "type": "string",
             "enum": [
@@ -8150,6 +11102,7 @@ const docTemplate = `{
                 "user_data",
                 "organization_member",
                 "license",
+                "oauth2_app",
                 "deployment_config",
                 "deployment_stats",
                 "replicas",
@@ -8174,6 +11127,7 @@ const docTemplate = `{
                 "ResourceUserData",
                 "ResourceOrganizationMember",
                 "ResourceLicense",
+                "ResourceOAuth2ProviderApp",
                 "ResourceDeploymentValues",
                 "ResourceDeploymentStats",
                 "ResourceReplicas",
@@ -8267,6 +11221,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ResetTOTPRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.ResourceType": {
             "type": "string",
             "enum": [
@@ -8278,7 +11243,13 @@ const docTemplate = `{
                 "git_ssh_key",
                 "api_key",
                 "group",
-                "license"
+                "license",
+                "oauth2_provider_app",
+                "oauth2_provider_app_secret",
+                "template_version_rollout",
+                "workspace_port_share",
+                "derp_region",
+                "derp_region_policy"
             ],
             "x-enum-varnames": [
                 "ResourceTypeTemplate",
@@ -8289,7 +11260,13 @@ const docTemplate = `{
                 "ResourceTypeGitSSHKey",
                 "ResourceTypeAPIKey",
                 "ResourceTypeGroup",
-                "ResourceTypeLicense"
+                "ResourceTypeLicense",
+                "ResourceTypeOAuth2ProviderApp",
+                "ResourceTypeOAuth2ProviderAppSecret",
+                "ResourceTypeTemplateVersionRollout",
+                "ResourceTypeWorkspacePortShare",
+                "ResourceTypeDERPRegion",
+                "ResourceTypeDERPRegionPolicy"
             ]
         },
         "codersdk.Response": {
@@ -8367,6 +11344,64 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.Session": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "expires_at",
+                "id",
+                "last_used",
+                "login_type",
+                "scope",
+                "user_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "current": {
+                    "description": "Current is true for the session that made the request.",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "login_type": {
+                    "type": "string",
+                    "enum": [
+                        "password",
+                        "github",
+                        "oidc"
+                    ]
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "application_connect"
+                    ]
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.SessionCountDeploymentStats": {
             "type": "object",
             "properties": {
@@ -8400,6 +11435,15 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.SyncTemplateGitSourceRequest": {
+            "type": "object",
+            "properties": {
+                "force": {
+                    "description": "Force creates a new version even if the ref has not changed.",
+                    "type": "boolean"
+                }
+            }
+        },
         "codersdk.TLSConfig": {
             "type": "object",
             "properties": {
@@ -8441,6 +11485,29 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TOTPEnrollment": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the otpauth:// URL authenticator apps import the secret from.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TOTPRecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.TelemetryConfig": {
             "type": "object",
             "properties": {
@@ -8513,6 +11580,16 @@ const docTemplate = `{
                 "inactivity_ttl_ms": {
                     "type": "integer"
                 },
+                "max_port_share_level": {
+                    "description": "MaxPortShareLevel is the least restrictive level workspace owners may\nshare ports with.",
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "group",
+                        "authenticated",
+                        "public"
+                    ]
+                },
                 "max_ttl_ms": {
                     "description": "MaxTTLMillis is an enterprise feature. It's value is only used if your\nlicense is entitled to use the advanced template scheduling feature.",
                     "type": "integer"
@@ -8530,6 +11607,10 @@ const docTemplate = `{
                         "terraform"
                     ]
                 },
+                "require_active_version": {
+                    "description": "RequireActiveVersion forces workspaces to be started on the active\nversion. Only template managers may start other versions.",
+                    "type": "boolean"
+                },
                 "updated_at": {
                     "type": "string",
                     "format": "date-time"
@@ -8548,27 +11629,79 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
-                "icon": {
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "markdown": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateGitSource": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "git_auth_provider_id": {
+                    "description": "GitAuthProviderID is the git auth provider used to authenticate as\nCreatedBy when cloning the repository.",
+                    "type": "string"
+                },
+                "has_webhook_secret": {
+                    "description": "HasWebhookSecret is true if push webhooks are accepted for the source.",
+                    "type": "boolean"
+                },
+                "last_commit_sha": {
+                    "type": "string"
+                },
+                "last_error": {
                     "type": "string"
                 },
-                "id": {
+                "last_synced_at": {
                     "type": "string",
-                    "format": "uuid"
+                    "format": "date-time"
                 },
-                "markdown": {
-                    "type": "string"
+                "poll_interval_ms": {
+                    "description": "PollIntervalMillis is how often the ref is checked for new commits. Zero\ndisables polling.",
+                    "type": "integer"
                 },
-                "name": {
+                "ref": {
                     "type": "string"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "repository_url": {
+                    "type": "string"
                 },
-                "url": {
+                "subdirectory": {
                     "type": "string"
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
                 }
             }
         },
@@ -8681,6 +11814,15 @@ const docTemplate = `{
                 "readme": {
                     "type": "string"
                 },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "active",
+                        "deprecated",
+                        "archived"
+                    ]
+                },
                 "template_id": {
                     "type": "string",
                     "format": "uuid"
@@ -8700,6 +11842,81 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionDiff": {
+            "type": "object",
+            "properties": {
+                "base_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionFileDiff"
+                    }
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionParameterDiff"
+                    }
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionResourceDiff"
+                    }
+                },
+                "variables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionVariableDiff"
+                    }
+                },
+                "version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.TemplateVersionFieldDiff": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionFileDiff": {
+            "type": "object",
+            "properties": {
+                "binary": {
+                    "description": "Binary is true if either side of the file isn't valid UTF-8 text or\nhas lines too long to compare. No diff is produced for binary files.",
+                    "type": "boolean"
+                },
+                "change": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "removed",
+                        "modified"
+                    ]
+                },
+                "diff": {
+                    "description": "Diff is the unified diff of the file contents.",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateVersionGitAuth": {
             "type": "object",
             "properties": {
@@ -8787,6 +12004,28 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionParameterDiff": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "removed",
+                        "modified"
+                    ]
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionFieldDiff"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateVersionParameterOption": {
             "type": "object",
             "properties": {
@@ -8796,10 +12035,212 @@ const docTemplate = `{
                 "icon": {
                     "type": "string"
                 },
-                "name": {
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionPreset": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateVersionPresetRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "parameters"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "description": "Parameters must reference rich parameters of the template version.\nParameters that are left out are prompted for as usual.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateVersionResourceDiff": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "removed",
+                        "modified"
+                    ]
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionFieldDiff"
+                    }
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "resource",
+                        "agent",
+                        "app"
+                    ]
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionRollout": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "type": "integer"
+                },
+                "counts": {
+                    "$ref": "#/definitions/codersdk.TemplateVersionRolloutCounts"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "failure_threshold_percent": {
+                    "type": "integer"
+                },
+                "group_ids": {
+                    "description": "GroupIDs restrict the cohort to workspaces owned by members of the\ngroups. Empty selects every workspace of the template.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "paused_reason": {
+                    "description": "PausedReason is set when the rollout was paused automatically.",
+                    "type": "string"
+                },
+                "percentage": {
+                    "description": "Percentage of the cohort that was selected for the rollout.",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "paused",
+                        "completed",
+                        "aborted"
+                    ]
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_version_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workspaces": {
+                    "description": "Workspaces are only included when fetching a single rollout.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionRolloutWorkspace"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateVersionRolloutCounts": {
+            "type": "object",
+            "properties": {
+                "building": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.TemplateVersionRolloutWorkspace": {
+            "type": "object",
+            "properties": {
+                "build_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "error": {
+                    "description": "Error is set when a build could not be created for the workspace.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "building",
+                        "succeeded",
+                        "failed",
+                        "skipped"
+                    ]
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_name": {
                     "type": "string"
                 },
-                "value": {
+                "workspace_owner_name": {
                     "type": "string"
                 }
             }
@@ -8835,6 +12276,28 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionVariableDiff": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "removed",
+                        "modified"
+                    ]
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionFieldDiff"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateVersionWarning": {
             "type": "string",
             "enum": [
@@ -8919,6 +12382,43 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateDERPRegionPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "region_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "codersdk.UpdateManagedDERPRegionRequest": {
+            "type": "object",
+            "required": [
+                "nodes",
+                "region_code",
+                "region_name"
+            ],
+            "properties": {
+                "avoid": {
+                    "type": "boolean"
+                },
+                "nodes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/codersdk.ManagedDERPNode"
+                    }
+                },
+                "region_code": {
+                    "type": "string"
+                },
+                "region_name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.UpdateRoles": {
             "type": "object",
             "properties": {
@@ -8947,6 +12447,76 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateTemplateGitSourceRequest": {
+            "type": "object",
+            "required": [
+                "ref",
+                "repository_url"
+            ],
+            "properties": {
+                "git_auth_provider_id": {
+                    "type": "string"
+                },
+                "poll_interval_ms": {
+                    "type": "integer"
+                },
+                "ref": {
+                    "type": "string"
+                },
+                "repository_url": {
+                    "type": "string"
+                },
+                "subdirectory": {
+                    "type": "string"
+                },
+                "webhook_secret": {
+                    "description": "WebhookSecret is used to verify push webhooks. The existing secret is\nkept if omitted, and webhooks are disabled if empty.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateTemplateVersionPresetsRequest": {
+            "type": "object",
+            "properties": {
+                "presets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionPresetRequest"
+                    }
+                }
+            }
+        },
+        "codersdk.UpdateTemplateVersionRolloutRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "paused",
+                        "aborted"
+                    ]
+                }
+            }
+        },
+        "codersdk.UpdateTemplateVersionStateRequest": {
+            "type": "object",
+            "required": [
+                "state"
+            ],
+            "properties": {
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "deprecated",
+                        "archived"
+                    ]
+                }
+            }
+        },
         "codersdk.UpdateUserPasswordRequest": {
             "type": "object",
             "required": [
@@ -9005,6 +12575,40 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpsertWorkspacePortShareRequest": {
+            "type": "object",
+            "required": [
+                "port",
+                "share_level"
+            ],
+            "properties": {
+                "agent_name": {
+                    "description": "AgentName may be left empty if the workspace has a single agent.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "group_id": {
+                    "description": "GroupID is required when ShareLevel is \"group\".",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "share_level": {
+                    "description": "ShareLevel may not exceed the maximum level of the template.",
+                    "type": "string",
+                    "enum": [
+                        "group",
+                        "authenticated",
+                        "public"
+                    ]
+                }
+            }
+        },
         "codersdk.User": {
             "type": "object",
             "required": [
@@ -9074,6 +12678,22 @@ const docTemplate = `{
                 "UserStatusSuspended"
             ]
         },
+        "codersdk.UserTOTP": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Enabled is true once an enrollment was verified. Password logins of the\nuser then require a code from their authenticator app.",
+                    "type": "boolean"
+                },
+                "recovery_codes_remaining": {
+                    "type": "integer"
+                },
+                "required": {
+                    "description": "Required is true if the deployment requires the user to enroll.",
+                    "type": "boolean"
+                }
+            }
+        },
         "codersdk.ValidationError": {
             "type": "object",
             "required": [
@@ -9111,6 +12731,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.VerifyTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.Workspace": {
             "type": "object",
             "properties": {
@@ -9237,7 +12868,7 @@ const docTemplate = `{
                     }
                 },
                 "lifecycle_state": {
-                    "$ref": "#/definitions/codersdk.WorkspaceAgentLifecycle"
+                    "type": "string"
                 },
                 "login_before_ready": {
                     "description": "Deprecated: Use StartupScriptBehavior instead.",
@@ -9249,6 +12880,13 @@ const docTemplate = `{
                 "operating_system": {
                     "type": "string"
                 },
+                "peer_connections": {
+                    "description": "PeerConnections are the tailnet connections to the agent the last time\nit reported stats. They're empty when the agent isn't connected.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentPeerConnection"
+                    }
+                },
                 "resource_id": {
                     "type": "string",
                     "format": "uuid"
@@ -9269,17 +12907,17 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "startup_script_behavior": {
-                    "$ref": "#/definitions/codersdk.WorkspaceAgentStartupScriptBehavior"
+                    "type": "string"
                 },
                 "startup_script_timeout_seconds": {
                     "description": "StartupScriptTimeoutSeconds is the number of seconds to wait for the startup script to complete. If the script does not complete within this time, the agent lifecycle will be marked as start_timeout.",
                     "type": "integer"
                 },
                 "status": {
-                    "$ref": "#/definitions/codersdk.WorkspaceAgentStatus"
+                    "type": "string"
                 },
                 "subsystem": {
-                    "$ref": "#/definitions/codersdk.AgentSubsystem"
+                    "type": "string"
                 },
                 "troubleshooting_url": {
                     "type": "string"
@@ -9374,6 +13012,23 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAgentPeerConnection": {
+            "type": "object",
+            "properties": {
+                "derp_region_id": {
+                    "type": "integer"
+                },
+                "derp_region_name": {
+                    "type": "string"
+                },
+                "direct": {
+                    "type": "boolean"
+                },
+                "latency_ms": {
+                    "type": "number"
+                }
+            }
+        },
         "codersdk.WorkspaceAgentStartupLog": {
             "type": "object",
             "properties": {
@@ -9455,11 +13110,19 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "sharing_groups": {
+                    "description": "SharingGroups are the names of the groups whose members may access the\napp if the sharing level is \"group\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "sharing_level": {
                     "enum": [
                         "owner",
                         "authenticated",
-                        "public"
+                        "public",
+                        "group"
                     ],
                     "allOf": [
                         {
@@ -9501,12 +13164,14 @@ const docTemplate = `{
             "enum": [
                 "owner",
                 "authenticated",
-                "public"
+                "public",
+                "group"
             ],
             "x-enum-varnames": [
                 "WorkspaceAppSharingLevelOwner",
                 "WorkspaceAppSharingLevelAuthenticated",
-                "WorkspaceAppSharingLevelPublic"
+                "WorkspaceAppSharingLevelPublic",
+                "WorkspaceAppSharingLevelGroup"
             ]
         },
         "codersdk.WorkspaceBuild": {
@@ -9671,6 +13336,49 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspacePortShare": {
+            "type": "object",
+            "properties": {
+                "agent_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is unset when the share does not expire.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "group_id": {
+                    "description": "GroupID is set when the port is shared with a group.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "share_level": {
+                    "description": "ShareLevel is the level the port was shared with. Shares that exceed\nthe maximum level of the template are capped when enforced.",
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "group",
+                        "authenticated",
+                        "public"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.WorkspaceProxy": {
             "type": "object",
             "properties": {
@@ -10270,6 +13978,43 @@ const docTemplate = `{
                 "AccessMethodTerminal"
             ]
         },
+        "workspaceapps.AppUsage": {
+            "type": "object",
+            "properties": {
+                "app_slug": {
+                    "type": "string"
+                },
+                "bucket": {
+                    "description": "Bucket is the start of the hour the requests were made in.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "bytes_sent": {
+                    "description": "BytesSent is the sum of the response body bytes sent to the user. Data\nsent over upgraded connections, such as WebSockets, is not included.",
+                    "type": "integer"
+                },
+                "error_requests": {
+                    "description": "ErrorRequests is the number of requests that failed with a 5xx status.",
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "total_latency_ms": {
+                    "description": "TotalLatencyMS is the sum of the time to the response headers of all\nrequests.",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "description": "UserID is the nil UUID for unauthenticated requests to public apps.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "workspaceapps.IssueTokenRequest": {
             "type": "object",
             "properties": {
@@ -10324,6 +14069,18 @@ const docTemplate = `{
                 }
             }
         },
+        "wsproxysdk.AppSecurityKey": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Key is the hex encoded key.",
+                    "type": "string"
+                }
+            }
+        },
         "wsproxysdk.IssueSignedAppTokenResponse": {
             "type": "object",
             "properties": {
@@ -10349,8 +14106,27 @@ const docTemplate = `{
         "wsproxysdk.RegisterWorkspaceProxyResponse": {
             "type": "object",
             "properties": {
-                "app_security_key": {
+                "active_app_security_key_id": {
+                    "description": "ActiveAppSecurityKeyID is the ID of the key new tokens are signed with.",
                     "type": "string"
+                },
+                "app_security_keys": {
+                    "description": "AppSecurityKeys are the keys the workspace proxy must accept for signed\napp tokens and encrypted API keys. Keys are rotated by the primary, so\nworkspace proxies register periodically to stay up to date.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wsproxysdk.AppSecurityKey"
+                    }
+                }
+            }
+        },
+        "wsproxysdk.ReportAppUsageRequest": {
+            "type": "object",
+            "properties": {
+                "usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workspaceapps.AppUsage"
+                    }
                 }
             }
         }
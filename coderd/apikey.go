@@ -49,6 +49,10 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 	aReq.Old = database.APIKey{}
 	defer commitAudit()
 
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
 	var createToken codersdk.CreateTokenRequest
 	if !httpapi.Read(ctx, rw, r, &createToken) {
 		return
@@ -124,12 +128,17 @@ func (api *API) postAPIKey(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	user := httpmw.UserParam(r)
 
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
 	lifeTime := time.Hour * 24 * 7
 	cookie, _, err := api.createAPIKey(ctx, apikey.CreateParams{
 		UserID:           user.ID,
 		DeploymentValues: api.DeploymentValues,
 		LoginType:        database.LoginTypePassword,
 		RemoteAddr:       r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
 		// All api generated keys will last 1 week. Browser login tokens have
 		// a shorter life.
 		ExpiresAt:       database.Now().Add(lifeTime),
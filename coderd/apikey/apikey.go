@@ -26,6 +26,7 @@ type CreateParams struct {
 	Scope           database.APIKeyScope
 	TokenName       string
 	RemoteAddr      string
+	UserAgent       string
 }
 
 // Generate generates an API key, returning the key as a string as well as the
@@ -91,6 +92,7 @@ func Generate(params CreateParams) (database.InsertAPIKeyParams, string, error)
 		LoginType:    params.LoginType,
 		Scope:        scope,
 		TokenName:    params.TokenName,
+		UserAgent:    params.UserAgent,
 	}, token, nil
 }
 
@@ -259,6 +259,12 @@ func auditLogDescription(alog database.GetAuditLogsOffsetRow) string {
 		return str
 	}
 
+	// Lockouts are logged for the user that was locked out:
+	// "User was locked out"
+	if alog.Action == database.AuditActionLockout {
+		return str
+	}
+
 	// We don't display the name (target) for git ssh keys. It's fairly long and doesn't
 	// make too much sense to display.
 	if alog.ResourceType == database.ResourceTypeGitSshKey {
@@ -17,6 +17,8 @@ type AdditionalFields struct {
 	BuildNumber    string               `json:"build_number"`
 	BuildReason    database.BuildReason `json:"build_reason"`
 	WorkspaceOwner string               `json:"workspace_owner"`
+	// App is the slug or port of the workspace app that was connected to.
+	App string `json:"app,omitempty"`
 }
 
 func NewNop() Auditor {
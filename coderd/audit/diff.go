@@ -17,7 +17,13 @@ type Auditable interface {
 		database.WorkspaceBuild |
 		database.AuditableGroup |
 		database.License |
-		database.WorkspaceProxy
+		database.WorkspaceProxy |
+		database.OAuth2ProviderApp |
+		database.OAuth2ProviderAppSecret |
+		database.TemplateVersionRollout |
+		database.WorkspacePortShare |
+		database.DERPRegion |
+		database.DERPRegionPolicy
 }
 
 // Map is a map of changed fields in an audited resource. It maps field names to
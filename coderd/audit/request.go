@@ -25,6 +25,9 @@ type RequestParams struct {
 	Request          *http.Request
 	Action           database.AuditAction
 	AdditionalFields json.RawMessage
+	// System is set for requests that are not made by a user, such as SCIM
+	// requests of the identity provider. They are audited without a user.
+	System bool
 }
 
 type Request[T Auditable] struct {
@@ -40,6 +43,28 @@ type Request[T Auditable] struct {
 	// This optional field can be passed in if the AuditAction must be overridden
 	// such as in the case of new user authentication when the Audit Action is 'register', not 'login'.
 	Action database.AuditAction
+
+	// This optional field can be passed in when the additional fields are only
+	// known once the request has been handled.
+	AdditionalFields json.RawMessage
+}
+
+type BackgroundAuditParams[T Auditable] struct {
+	Audit Auditor
+	Log   slog.Logger
+
+	UserID           uuid.UUID
+	RequestID        uuid.UUID
+	IP               string
+	UserAgent        string
+	Status           int
+	Action           database.AuditAction
+	AdditionalFields json.RawMessage
+	// ServiceAccountCreatedBy is the admin that created the user if they are
+	// a service account.
+	ServiceAccountCreatedBy uuid.NullUUID
+
+	Resource T
 }
 
 type BuildAuditParams[T Auditable] struct {
@@ -51,6 +76,9 @@ type BuildAuditParams[T Auditable] struct {
 	Status           int
 	Action           database.AuditAction
 	AdditionalFields json.RawMessage
+	// ServiceAccountCreatedBy is the admin that created the user if they are
+	// a service account.
+	ServiceAccountCreatedBy uuid.NullUUID
 
 	New T
 	Old T
@@ -84,6 +112,18 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return strconv.Itoa(int(typed.ID))
 	case database.WorkspaceProxy:
 		return typed.Name
+	case database.OAuth2ProviderApp:
+		return typed.Name
+	case database.OAuth2ProviderAppSecret:
+		return typed.DisplaySecret
+	case database.TemplateVersionRollout:
+		return typed.ID.String()
+	case database.WorkspacePortShare:
+		return fmt.Sprintf("%s:%d", typed.AgentName, typed.Port)
+	case database.DERPRegion:
+		return typed.RegionCode
+	case database.DERPRegionPolicy:
+		return typed.ID.String()
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -111,6 +151,19 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 		return typed.UUID
 	case database.WorkspaceProxy:
 		return typed.ID
+	case database.OAuth2ProviderApp:
+		return typed.ID
+	case database.OAuth2ProviderAppSecret:
+		return typed.ID
+	case database.TemplateVersionRollout:
+		return typed.ID
+	case database.WorkspacePortShare:
+		// Port shares have no ID of their own.
+		return typed.WorkspaceID
+	case database.DERPRegion:
+		return typed.ID
+	case database.DERPRegionPolicy:
+		return typed.ID
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -138,6 +191,18 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeLicense
 	case database.WorkspaceProxy:
 		return database.ResourceTypeWorkspaceProxy
+	case database.OAuth2ProviderApp:
+		return database.ResourceTypeOauth2ProviderApp
+	case database.OAuth2ProviderAppSecret:
+		return database.ResourceTypeOauth2ProviderAppSecret
+	case database.TemplateVersionRollout:
+		return database.ResourceTypeTemplateVersionRollout
+	case database.WorkspacePortShare:
+		return database.ResourceTypeWorkspacePortShare
+	case database.DERPRegion:
+		return database.ResourceTypeDerpRegion
+	case database.DERPRegionPolicy:
+		return database.ResourceTypeDerpRegionPolicy
 	default:
 		panic(fmt.Sprintf("unknown resource %T", typed))
 	}
@@ -185,6 +250,9 @@ func InitRequest[T Auditable](w http.ResponseWriter, p *RequestParams) (*Request
 			}
 		}
 
+		if req.AdditionalFields != nil {
+			p.AdditionalFields = req.AdditionalFields
+		}
 		if p.AdditionalFields == nil {
 			p.AdditionalFields = json.RawMessage("{}")
 		}
@@ -193,7 +261,16 @@ func InitRequest[T Auditable](w http.ResponseWriter, p *RequestParams) (*Request
 		key, ok := httpmw.APIKeyOptional(p.Request)
 		if ok {
 			userID = key.UserID
-		} else if req.UserID != uuid.Nil {
+			// Record who is responsible for the actions of service accounts.
+			if authz, ok := httpmw.UserAuthorizationOptional(p.Request); ok && authz.ServiceAccountCreatedBy.Valid {
+				p.AdditionalFields = withAdditionalField(logCtx, p.Log, p.AdditionalFields, "service_account_created_by", authz.ServiceAccountCreatedBy.UUID)
+			}
+			// The user is the impersonated user, record the owner that is
+			// acting as them as well.
+			if authz, ok := httpmw.UserAuthorizationOptional(p.Request); ok && authz.ImpersonatorID.Valid {
+				p.AdditionalFields = withAdditionalField(logCtx, p.Log, p.AdditionalFields, "impersonator_id", authz.ImpersonatorID.UUID)
+			}
+		} else if req.UserID != uuid.Nil || p.System {
 			userID = req.UserID
 		} else {
 			// if we do not have a user associated with the audit action
@@ -234,6 +311,24 @@ func InitRequest[T Auditable](w http.ResponseWriter, p *RequestParams) (*Request
 	}
 }
 
+// withAdditionalField adds a field to the additional fields of an audit log,
+// which are a JSON object.
+func withAdditionalField(ctx context.Context, log slog.Logger, fields json.RawMessage, key string, value any) json.RawMessage {
+	m := map[string]any{}
+	err := json.Unmarshal(fields, &m)
+	if err != nil {
+		log.Warn(ctx, "unmarshal additional fields", slog.Error(err))
+		return fields
+	}
+	m[key] = value
+	raw, err := json.Marshal(m)
+	if err != nil {
+		log.Warn(ctx, "marshal additional fields", slog.Error(err))
+		return fields
+	}
+	return raw
+}
+
 // BuildAudit creates an audit log for a workspace build.
 // The audit log is committed upon invocation.
 func BuildAudit[T Auditable](ctx context.Context, p *BuildAuditParams[T]) {
@@ -252,6 +347,9 @@ func BuildAudit[T Auditable](ctx context.Context, p *BuildAuditParams[T]) {
 	if p.AdditionalFields == nil {
 		p.AdditionalFields = json.RawMessage("{}")
 	}
+	if p.ServiceAccountCreatedBy.Valid {
+		p.AdditionalFields = withAdditionalField(ctx, p.Log, p.AdditionalFields, "service_account_created_by", p.ServiceAccountCreatedBy.UUID)
+	}
 
 	auditLog := database.AuditLog{
 		ID:               uuid.New(),
@@ -278,6 +376,41 @@ func BuildAudit[T Auditable](ctx context.Context, p *BuildAuditParams[T]) {
 	}
 }
 
+// BackgroundAudit creates an audit log for an action on a resource that is
+// not a change to the resource, such as connecting to a workspace app. The
+// audit log is committed upon invocation.
+func BackgroundAudit[T Auditable](ctx context.Context, p *BackgroundAuditParams[T]) {
+	if p.AdditionalFields == nil {
+		p.AdditionalFields = json.RawMessage("{}")
+	}
+	if p.ServiceAccountCreatedBy.Valid {
+		p.AdditionalFields = withAdditionalField(ctx, p.Log, p.AdditionalFields, "service_account_created_by", p.ServiceAccountCreatedBy.UUID)
+	}
+
+	auditLog := database.AuditLog{
+		ID:               uuid.New(),
+		Time:             database.Now(),
+		UserID:           p.UserID,
+		Ip:               parseIP(p.IP),
+		UserAgent:        sql.NullString{String: p.UserAgent, Valid: p.UserAgent != ""},
+		ResourceType:     ResourceType(p.Resource),
+		ResourceID:       ResourceID(p.Resource),
+		ResourceTarget:   ResourceTarget(p.Resource),
+		Action:           p.Action,
+		Diff:             []byte("{}"),
+		StatusCode:       int32(p.Status),
+		RequestID:        p.RequestID,
+		AdditionalFields: p.AdditionalFields,
+	}
+	err := p.Audit.Export(ctx, auditLog)
+	if err != nil {
+		p.Log.Error(ctx, "export audit log",
+			slog.F("audit_log", auditLog),
+			slog.Error(err),
+		)
+	}
+}
+
 func either[T Auditable, R any](old, new T, fn func(T) R, auditAction database.AuditAction) R {
 	if ResourceID(new) != uuid.Nil {
 		return fn(new)
@@ -46,16 +46,20 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/database/pubsub"
+	"github.com/coder/coder/coderd/derpmap"
 	"github.com/coder/coder/coderd/gitauth"
 	"github.com/coder/coder/coderd/gitsshkey"
 	"github.com/coder/coder/coderd/healthcheck"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/loginlimit"
 	"github.com/coder/coder/coderd/metricscache"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/coderd/templategit"
+	"github.com/coder/coder/coderd/templaterollout"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/updatecheck"
 	"github.com/coder/coder/coderd/util/slice"
@@ -108,15 +112,18 @@ type Options struct {
 	GoogleTokenValidator           *idtoken.Validator
 	GithubOAuth2Config             *GithubOAuth2Config
 	OIDCConfig                     *OIDCConfig
-	PrometheusRegistry             *prometheus.Registry
-	SecureAuthCookie               bool
-	StrictTransportSecurityCfg     httpmw.HSTSConfig
-	SSHKeygenAlgorithm             gitsshkey.Algorithm
-	Telemetry                      telemetry.Reporter
-	TracerProvider                 trace.TracerProvider
-	GitAuthConfigs                 []*gitauth.Config
-	RealIPConfig                   *httpmw.RealIPConfig
-	TrialGenerator                 func(ctx context.Context, email string) error
+	// OIDCProviders are the OpenID Connect providers trusted in addition to
+	// OIDCConfig.
+	OIDCProviders              []*OIDCConfig
+	PrometheusRegistry         *prometheus.Registry
+	SecureAuthCookie           bool
+	StrictTransportSecurityCfg httpmw.HSTSConfig
+	SSHKeygenAlgorithm         gitsshkey.Algorithm
+	Telemetry                  telemetry.Reporter
+	TracerProvider             trace.TracerProvider
+	GitAuthConfigs             []*gitauth.Config
+	RealIPConfig               *httpmw.RealIPConfig
+	TrialGenerator             func(ctx context.Context, email string) error
 	// TLSCertificates is used to mesh DERP servers securely.
 	TLSCertificates       []tls.Certificate
 	TailnetCoordinator    tailnet.Coordinator
@@ -124,13 +131,17 @@ type Options struct {
 	DERPMap               *tailcfg.DERPMap
 	SwaggerEndpoint       bool
 	SetUserGroups         func(ctx context.Context, tx database.Store, userID uuid.UUID, groupNames []string) error
+	SetUserSiteRoles      func(ctx context.Context, tx database.Store, userID uuid.UUID, roles []string) error
 	TemplateScheduleStore *atomic.Pointer[schedule.TemplateScheduleStore]
-	// AppSecurityKey is the crypto key used to sign and encrypt tokens related to
-	// workspace applications. It consists of both a signing and encryption key.
-	AppSecurityKey     workspaceapps.SecurityKey
-	HealthcheckFunc    func(ctx context.Context, apiKey string) *healthcheck.Report
-	HealthcheckTimeout time.Duration
-	HealthcheckRefresh time.Duration
+	// AppSecurityKeys are the crypto keys used to sign and encrypt tokens
+	// related to workspace applications. Each key consists of both a signing
+	// and encryption key. The keys are refreshed from the database and rotated
+	// in the background.
+	AppSecurityKeys        *workspaceapps.SecurityKeySet
+	AppSecurityKeyRotation workspaceapps.SecurityKeyRotationOptions
+	HealthcheckFunc        func(ctx context.Context, apiKey string) *healthcheck.Report
+	HealthcheckTimeout     time.Duration
+	HealthcheckRefresh     time.Duration
 
 	// APIRateLimit is the minutely throughput rate limit per user or ip.
 	// Setting a rate limit <0 will disable the rate limiter across the entire
@@ -141,6 +152,8 @@ type Options struct {
 
 	MetricsCacheRefreshInterval time.Duration
 	AgentStatsRefreshInterval   time.Duration
+	TemplateRolloutInterval     time.Duration
+	AppUsageFlushInterval       time.Duration
 	DeploymentValues            *codersdk.DeploymentValues
 	UpdateCheckOptions          *updatecheck.Options // Set non-nil to enable update checking.
 
@@ -252,6 +265,17 @@ func New(options *Options) *API {
 			return nil
 		}
 	}
+	if options.SetUserSiteRoles == nil {
+		options.SetUserSiteRoles = func(ctx context.Context, _ database.Store, id uuid.UUID, roles []string) error {
+			options.Logger.Warn(ctx, "attempted to assign OIDC user roles without enterprise license",
+				slog.F("id", id), slog.F("roles", roles),
+			)
+			return nil
+		}
+	}
+	if options.AppSecurityKeys == nil {
+		options.AppSecurityKeys = &workspaceapps.SecurityKeySet{}
+	}
 	if options.TemplateScheduleStore == nil {
 		options.TemplateScheduleStore = &atomic.Pointer[schedule.TemplateScheduleStore]{}
 	}
@@ -300,8 +324,9 @@ func New(options *Options) *API {
 	staticHandler = httpmw.HSTS(staticHandler, options.StrictTransportSecurityCfg)
 
 	oauthConfigs := &httpmw.OAuth2Configs{
-		Github: options.GithubOAuth2Config,
-		OIDC:   options.OIDCConfig,
+		Github:        options.GithubOAuth2Config,
+		OIDC:          options.OIDCConfig,
+		OIDCProviders: OIDCProviderOAuth2Configs(options.OIDCProviders),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -318,22 +343,41 @@ func New(options *Options) *API {
 			Authorizer: options.Authorizer,
 			Logger:     options.Logger,
 		},
-		WorkspaceAppsProvider: workspaceapps.NewDBTokenProvider(
-			options.Logger.Named("workspaceapps"),
-			options.AccessURL,
-			options.Authorizer,
-			options.Database,
-			options.DeploymentValues,
-			oauthConfigs,
-			options.AgentInactiveDisconnectTimeout,
-			options.AppSecurityKey,
-		),
 		metricsCache:          metricsCache,
 		Auditor:               atomic.Pointer[audit.Auditor]{},
 		TemplateScheduleStore: options.TemplateScheduleStore,
 		Experiments:           experiments,
 		healthCheckGroup:      &singleflight.Group[string, *healthcheck.Report]{},
+		loginIPTracker: loginlimit.NewIPTracker(
+			options.DeploymentValues.LoginLockout.IPThreshold.Value(),
+			options.DeploymentValues.LoginLockout.Duration.Value(),
+		),
 	}
+	api.templateGitSyncer = templategit.New(
+		options.Database,
+		options.Logger.Named("template_git_syncer"),
+		options.GitAuthConfigs,
+		templategit.Options{},
+	)
+	api.templateRolloutRunner = templaterollout.New(
+		options.Database,
+		options.Logger.Named("template_rollout_runner"),
+		templaterollout.Options{Interval: options.TemplateRolloutInterval},
+	)
+	api.appSecurityKeyRefresher = workspaceapps.NewSecurityKeyRefresher(
+		options.Database,
+		options.Logger.Named("app_security_key_refresher"),
+		options.AppSecurityKeys,
+		options.AppSecurityKeyRotation,
+	)
+	api.appUsageCollector = workspaceapps.NewAppUsageCollector(
+		options.Logger.Named("app_usage_collector"),
+		func(ctx context.Context, usage []workspaceapps.AppUsage) error {
+			//nolint:gocritic // App usage is collected by the system.
+			return workspaceapps.UpsertAppUsage(dbauthz.AsSystemRestricted(ctx), options.Database, usage)
+		},
+		options.AppUsageFlushInterval,
+	)
 	if options.UpdateCheckOptions != nil {
 		api.updateChecker = updatecheck.New(
 			options.Database,
@@ -348,8 +392,22 @@ func New(options *Options) *API {
 	}
 
 	api.Auditor.Store(&options.Auditor)
+	api.WorkspaceAppsProvider = workspaceapps.NewDBTokenProvider(
+		options.Logger.Named("workspaceapps"),
+		options.AccessURL,
+		options.Authorizer,
+		&api.Auditor,
+		options.Database,
+		options.DeploymentValues,
+		oauthConfigs,
+		options.AgentInactiveDisconnectTimeout,
+		options.AppSecurityKeys,
+	)
 	api.workspaceAgentCache = wsconncache.New(api.dialWorkspaceAgentTailnet, 0)
 	api.TailnetCoordinator.Store(&options.TailnetCoordinator)
+	derpMapProvider := derpmap.NewAGPLProvider(options.DERPMap)
+	api.DERPMapProvider.Store(&derpMapProvider)
+	api.DERPMapWatcher = derpmap.NewWatcher(options.Logger.Named("derp_map_watcher"), options.Pubsub)
 
 	api.workspaceAppServer = &workspaceapps.Server{
 		Logger: options.Logger.Named("workspaceapps"),
@@ -362,10 +420,13 @@ func New(options *Options) *API {
 
 		SignedTokenProvider: api.WorkspaceAppsProvider,
 		WorkspaceConnCache:  api.workspaceAgentCache,
-		AppSecurityKey:      options.AppSecurityKey,
+		AppSecurityKeys:     options.AppSecurityKeys,
 
 		DisablePathApps:  options.DeploymentValues.DisablePathApps.Value(),
 		SecureAuthCookie: options.DeploymentValues.SecureAuthCookie.Value(),
+
+		AppUsage:            api.appUsageCollector,
+		AccessLogSampleRate: float64(options.DeploymentValues.Logging.AppAccessSamplePercent.Value()) / 100,
 	}
 
 	apiKeyMiddleware := httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
@@ -373,6 +434,7 @@ func New(options *Options) *API {
 		OAuth2Configs:               oauthConfigs,
 		RedirectToLogin:             false,
 		DisableSessionExpiryRefresh: options.DeploymentValues.DisableSessionExpiryRefresh.Value(),
+		DisableImpersonation:        options.DeploymentValues.DisableImpersonation.Value(),
 		Optional:                    false,
 	})
 	// Same as above but it redirects to the login page.
@@ -381,6 +443,7 @@ func New(options *Options) *API {
 		OAuth2Configs:               oauthConfigs,
 		RedirectToLogin:             true,
 		DisableSessionExpiryRefresh: options.DeploymentValues.DisableSessionExpiryRefresh.Value(),
+		DisableImpersonation:        options.DeploymentValues.DisableImpersonation.Value(),
 		Optional:                    false,
 	})
 	// Same as the first but it's optional.
@@ -389,6 +452,7 @@ func New(options *Options) *API {
 		OAuth2Configs:               oauthConfigs,
 		RedirectToLogin:             false,
 		DisableSessionExpiryRefresh: options.DeploymentValues.DisableSessionExpiryRefresh.Value(),
+		DisableImpersonation:        options.DeploymentValues.DisableImpersonation.Value(),
 		Optional:                    true,
 	})
 
@@ -463,6 +527,37 @@ func New(options *Options) *API {
 			})
 		}
 	})
+	// Coder acts as an OAuth2 authorization server for registered
+	// applications.
+	r.Route("/oauth2", func(r chi.Router) {
+		r.Use(apiRateLimiter)
+		r.Route("/authorize", func(r chi.Router) {
+			r.Use(apiKeyMiddlewareRedirect)
+			r.Get("/", api.getOAuth2ProviderAppAuthorize)
+			r.Post("/", api.postOAuth2ProviderAppAuthorize)
+		})
+		r.Route("/tokens", func(r chi.Router) {
+			// Clients authenticate with their secret.
+			r.Post("/", api.postOAuth2ProviderAppToken)
+			r.Group(func(r chi.Router) {
+				r.Use(apiKeyMiddleware)
+				r.Delete("/", api.deleteOAuth2ProviderAppTokens)
+			})
+		})
+		r.Post("/revoke", api.postOAuth2ProviderAppRevoke)
+		// The device authorization grant lets the CLI log in on machines
+		// without a browser.
+		r.Route("/device", func(r chi.Router) {
+			// Devices are not authenticated, they poll /tokens with the
+			// device code.
+			r.Post("/code", api.postOAuth2DeviceAuthorization)
+			r.Group(func(r chi.Router) {
+				r.Use(apiKeyMiddlewareRedirect)
+				r.Get("/", api.getOAuth2DeviceVerification)
+				r.Post("/", api.postOAuth2DeviceVerification)
+			})
+		})
+	})
 	r.Route("/api/v2", func(r chi.Router) {
 		api.APIHandler = r
 
@@ -483,6 +578,10 @@ func New(options *Options) *API {
 			r.Use(apiKeyMiddleware)
 			r.Get("/regions", api.regions)
 		})
+		r.Group(func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/derp-map", api.derpMapUpdates)
+		})
 		r.Route("/deployment", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Get("/config", api.deploymentValues)
@@ -510,6 +609,27 @@ func New(options *Options) *API {
 			r.Get("/{fileID}", api.fileByID)
 			r.Post("/", api.postFile)
 		})
+		r.Route("/oauth2-provider", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Route("/apps", func(r chi.Router) {
+				r.Get("/", api.oAuth2ProviderApps)
+				r.Post("/", api.postOAuth2ProviderApp)
+				r.Route("/{app}", func(r chi.Router) {
+					r.Use(httpmw.ExtractOAuth2ProviderAppParam(options.Database))
+					r.Get("/", api.oAuth2ProviderApp)
+					r.Put("/", api.putOAuth2ProviderApp)
+					r.Delete("/", api.deleteOAuth2ProviderApp)
+					r.Route("/secrets", func(r chi.Router) {
+						r.Get("/", api.oAuth2ProviderAppSecrets)
+						r.Post("/", api.postOAuth2ProviderAppSecret)
+						r.Route("/{secretID}", func(r chi.Router) {
+							r.Use(httpmw.ExtractOAuth2ProviderAppSecretParam(options.Database))
+							r.Delete("/", api.deleteOAuth2ProviderAppSecret)
+						})
+					})
+				})
+			})
+		})
 		r.Route("/organizations", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -552,15 +672,35 @@ func New(options *Options) *API {
 				httpmw.ExtractTemplateParam(options.Database),
 			)
 			r.Get("/daus", api.templateDAUs)
+			r.Get("/insights/apps", api.templateAppInsights)
 			r.Get("/", api.template)
 			r.Delete("/", api.deleteTemplate)
 			r.Patch("/", api.patchTemplateMeta)
 			r.Route("/versions", func(r chi.Router) {
 				r.Get("/", api.templateVersionsByTemplate)
 				r.Patch("/", api.patchActiveTemplateVersion)
+				r.Post("/archive", api.postArchiveTemplateVersions)
 				r.Get("/{templateversionname}", api.templateVersionByName)
 			})
+			r.Route("/git-source", func(r chi.Router) {
+				r.Get("/", api.templateGitSource)
+				r.Put("/", api.putTemplateGitSource)
+				r.Delete("/", api.deleteTemplateGitSource)
+				r.Post("/sync", api.postTemplateGitSourceSync)
+			})
+			r.Route("/rollouts", func(r chi.Router) {
+				r.Get("/", api.templateVersionRollouts)
+				r.Post("/", api.postTemplateVersionRollout)
+				r.Route("/{rollout}", func(r chi.Router) {
+					r.Use(httpmw.ExtractTemplateVersionRolloutParam(options.Database))
+					r.Get("/", api.templateVersionRollout)
+					r.Patch("/", api.patchTemplateVersionRollout)
+				})
+			})
 		})
+		// Webhooks are authenticated with the git source's secret rather than
+		// an API key.
+		r.Post("/templates/{template}/git-source/webhook", api.postTemplateGitSourceWebhook)
 		r.Route("/templateversions/{templateversion}", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -569,13 +709,17 @@ func New(options *Options) *API {
 			r.Get("/", api.templateVersion)
 			r.Patch("/", api.patchTemplateVersion)
 			r.Patch("/cancel", api.patchCancelTemplateVersion)
+			r.Patch("/state", api.patchTemplateVersionState)
 			// Old agents may expect a non-error response from /schema and /parameters endpoints.
 			// The idea is to return an empty [], so that the coder CLI won't get blocked accidentally.
 			r.Get("/schema", templateVersionSchemaDeprecated)
 			r.Get("/parameters", templateVersionParametersDeprecated)
 			r.Get("/rich-parameters", api.templateVersionRichParameters)
+			r.Get("/presets", api.templateVersionPresets)
+			r.Put("/presets", api.putTemplateVersionPresets)
 			r.Get("/gitauth", api.templateVersionGitAuth)
 			r.Get("/variables", api.templateVersionVariables)
+			r.Get("/diff", api.templateVersionDiff)
 			r.Get("/resources", api.templateVersionResources)
 			r.Get("/logs", api.templateVersionLogs)
 			r.Route("/dry-run", func(r chi.Router) {
@@ -598,6 +742,7 @@ func New(options *Options) *API {
 				// This value is intentionally increased during tests.
 				r.Use(httpmw.RateLimit(options.LoginRateLimit, time.Minute))
 				r.Post("/login", api.postLogin)
+				r.Post("/login/totp", api.postLoginTOTPEnrollment)
 				r.Route("/oauth2", func(r chi.Router) {
 					r.Route("/github", func(r chi.Router) {
 						r.Use(httpmw.ExtractOAuth2(options.GithubOAuth2Config, options.HTTPClient, nil))
@@ -608,6 +753,7 @@ func New(options *Options) *API {
 					r.Use(httpmw.ExtractOAuth2(options.OIDCConfig, options.HTTPClient, oidcAuthURLParams))
 					r.Get("/", api.userOIDC)
 				})
+				r.Get("/oidc/{provider}/callback", api.userOIDCProvider)
 			})
 			r.Group(func(r chi.Router) {
 				r.Use(
@@ -629,6 +775,8 @@ func New(options *Options) *API {
 						r.Put("/suspend", api.putSuspendUserAccount())
 						r.Put("/activate", api.putActivateUserAccount())
 					})
+					r.Put("/unlock", api.putUnlockUserAccount)
+					r.Post("/impersonate", api.postUserImpersonation)
 					r.Route("/password", func(r chi.Router) {
 						r.Put("/", api.putUserPassword)
 					})
@@ -651,6 +799,11 @@ func New(options *Options) *API {
 							r.Delete("/", api.deleteAPIKey)
 						})
 					})
+					r.Route("/sessions", func(r chi.Router) {
+						r.Get("/", api.sessions)
+						r.Delete("/", api.deleteSessions)
+						r.Delete("/{session}", api.deleteSession)
+					})
 
 					r.Route("/organizations", func(r chi.Router) {
 						r.Get("/", api.organizationsByUser)
@@ -662,6 +815,13 @@ func New(options *Options) *API {
 					})
 					r.Get("/gitsshkey", api.gitSSHKey)
 					r.Put("/gitsshkey", api.regenerateGitSSHKey)
+					r.Route("/totp", func(r chi.Router) {
+						r.Get("/", api.userTOTP)
+						r.Post("/", api.postUserTOTP)
+						r.Delete("/", api.deleteUserTOTP)
+						r.Post("/verify", api.postUserTOTPVerify)
+						r.Post("/recovery-codes", api.postUserTOTPRecoveryCodes)
+					})
 				})
 			})
 		})
@@ -681,6 +841,7 @@ func New(options *Options) *API {
 				r.Get("/gitauth", api.workspaceAgentsGitAuth)
 				r.Get("/gitsshkey", api.agentGitSSHKey)
 				r.Get("/coordinate", api.workspaceAgentCoordinate)
+				r.Get("/derp-map", api.workspaceAgentDERPMapUpdates)
 				r.Post("/report-stats", api.workspaceAgentReportStats)
 				r.Post("/report-lifecycle", api.workspaceAgentReportLifecycle)
 				r.Post("/metadata/{key}", api.workspaceAgentPostMetadata)
@@ -731,6 +892,11 @@ func New(options *Options) *API {
 				})
 				r.Get("/watch", api.watchWorkspace)
 				r.Put("/extend", api.putExtendWorkspace)
+				r.Route("/port-shares", func(r chi.Router) {
+					r.Get("/", api.workspacePortShares)
+					r.Put("/", api.putWorkspacePortShare)
+					r.Delete("/", api.deleteWorkspacePortShare)
+				})
 			})
 		})
 		r.Route("/workspacebuilds/{workspacebuild}", func(r chi.Router) {
@@ -768,6 +934,7 @@ func New(options *Options) *API {
 		r.Route("/insights", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Get("/daus", api.deploymentDAUs)
+			r.Get("/apps", api.deploymentAppInsights)
 		})
 		r.Route("/debug", func(r chi.Router) {
 			r.Use(
@@ -844,6 +1011,12 @@ type API struct {
 	WorkspaceClientCoordinateOverride atomic.Pointer[func(rw http.ResponseWriter) bool]
 	TailnetCoordinator                atomic.Pointer[tailnet.Coordinator]
 	QuotaCommitter                    atomic.Pointer[proto.QuotaCommitter]
+	// DERPMapProvider returns the DERP maps handed out to clients and
+	// agents. Enterprise restricts them using DERP region policies.
+	DERPMapProvider atomic.Pointer[derpmap.Provider]
+	// DERPMapWatcher notifies everyone that hands out DERP maps when they
+	// may have changed.
+	DERPMapWatcher *derpmap.Watcher
 	// WorkspaceProxyHostsFn returns the hosts of healthy workspace proxies
 	// for header reasons.
 	WorkspaceProxyHostsFn atomic.Pointer[func() []string]
@@ -864,11 +1037,16 @@ type API struct {
 	WebsocketWaitGroup sync.WaitGroup
 	derpCloseFunc      func()
 
-	metricsCache          *metricscache.Cache
-	workspaceAgentCache   *wsconncache.Cache
-	updateChecker         *updatecheck.Checker
-	WorkspaceAppsProvider workspaceapps.SignedTokenProvider
-	workspaceAppServer    *workspaceapps.Server
+	metricsCache            *metricscache.Cache
+	workspaceAgentCache     *wsconncache.Cache
+	updateChecker           *updatecheck.Checker
+	templateGitSyncer       *templategit.Syncer
+	templateRolloutRunner   *templaterollout.Runner
+	appSecurityKeyRefresher *workspaceapps.SecurityKeyRefresher
+	appUsageCollector       *workspaceapps.AppUsageCollector
+	loginIPTracker          *loginlimit.IPTracker
+	WorkspaceAppsProvider   workspaceapps.SignedTokenProvider
+	workspaceAppServer      *workspaceapps.Server
 
 	// Experiments contains the list of experiments currently enabled.
 	// This is used to gate features that are not yet ready for production.
@@ -888,6 +1066,11 @@ func (api *API) Close() error {
 	api.WebsocketWaitMutex.Unlock()
 
 	api.metricsCache.Close()
+	api.templateGitSyncer.Close()
+	api.templateRolloutRunner.Close()
+	api.appSecurityKeyRefresher.Close()
+	api.DERPMapWatcher.Close()
+	_ = api.appUsageCollector.Close()
 	if api.updateChecker != nil {
 		api.updateChecker.Close()
 	}
@@ -961,6 +1144,7 @@ func (api *API) CreateInMemoryProvisionerDaemon(ctx context.Context, debounce ti
 		AccessURL:             api.AccessURL,
 		ID:                    daemon.ID,
 		OIDCConfig:            api.OIDCConfig,
+		OIDCProviders:         OIDCProviderOAuth2Configs(api.OIDCProviders),
 		Database:              api.Database,
 		Pubsub:                api.Pubsub,
 		Provisioners:          daemon.Provisioners,
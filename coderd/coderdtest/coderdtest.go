@@ -83,6 +83,9 @@ import (
 	"github.com/coder/coder/testutil"
 )
 
+// AppSecurityKeyID is the ID of AppSecurityKey in the database.
+const AppSecurityKeyID = "2d5a7b4c-8e3f-4a61-9b0d-6c1e2f3a4b5c"
+
 // AppSecurityKey is a 96-byte key used to sign JWTs and encrypt JWEs for
 // workspace app tokens in tests.
 var AppSecurityKey = must(workspaceapps.KeyFromString("6465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e2077617320686572"))
@@ -99,6 +102,7 @@ type Options struct {
 	GithubOAuth2Config    *coderd.GithubOAuth2Config
 	RealIPConfig          *httpmw.RealIPConfig
 	OIDCConfig            *coderd.OIDCConfig
+	OIDCProviders         []*coderd.OIDCConfig
 	GoogleTokenValidator  *idtoken.Validator
 	SSHKeygenAlgorithm    gitsshkey.Algorithm
 	AutobuildTicker       <-chan time.Time
@@ -122,6 +126,8 @@ type Options struct {
 	IncludeProvisionerDaemon    bool
 	MetricsCacheRefreshInterval time.Duration
 	AgentStatsRefreshInterval   time.Duration
+	TemplateRolloutInterval     time.Duration
+	AppUsageFlushInterval       time.Duration
 	DeploymentValues            *codersdk.DeploymentValues
 
 	// Set update check options to enable update check.
@@ -219,6 +225,19 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 		err := options.Database.InsertDeploymentID(dbauthz.AsSystemRestricted(context.Background()), uuid.NewString())
 		require.NoError(t, err, "insert a deployment id")
 	}
+	// nolint:gocritic // Setting up unit test data inside test helper
+	appSecurityKeys, err := options.Database.GetWorkspaceAppSecurityKeys(dbauthz.AsSystemRestricted(context.Background()), database.Now())
+	require.NoError(t, err, "get app security keys")
+	if len(appSecurityKeys) == 0 {
+		// nolint:gocritic // Setting up unit test data inside test helper
+		_, err := options.Database.InsertWorkspaceAppSecurityKey(dbauthz.AsSystemRestricted(context.Background()), database.InsertWorkspaceAppSecurityKeyParams{
+			ID:        uuid.MustParse(AppSecurityKeyID),
+			Secret:    AppSecurityKey.String(),
+			CreatedAt: database.Now(),
+			StartsAt:  database.Now(),
+		})
+		require.NoError(t, err, "insert an app security key")
+	}
 
 	if options.DeploymentValues == nil {
 		options.DeploymentValues = DeploymentValues(t)
@@ -334,6 +353,7 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 			GithubOAuth2Config:    options.GithubOAuth2Config,
 			RealIPConfig:          options.RealIPConfig,
 			OIDCConfig:            options.OIDCConfig,
+			OIDCProviders:         options.OIDCProviders,
 			GoogleTokenValidator:  options.GoogleTokenValidator,
 			SSHKeygenAlgorithm:    options.SSHKeygenAlgorithm,
 			DERPServer:            derpServer,
@@ -366,10 +386,12 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 			},
 			MetricsCacheRefreshInterval: options.MetricsCacheRefreshInterval,
 			AgentStatsRefreshInterval:   options.AgentStatsRefreshInterval,
+			TemplateRolloutInterval:     options.TemplateRolloutInterval,
+			AppUsageFlushInterval:       options.AppUsageFlushInterval,
 			DeploymentValues:            options.DeploymentValues,
 			UpdateCheckOptions:          options.UpdateCheckOptions,
 			SwaggerEndpoint:             options.SwaggerEndpoint,
-			AppSecurityKey:              AppSecurityKey,
+			AppSecurityKeys:             workspaceapps.NewSecurityKeySet(AppSecurityKeyID, AppSecurityKey),
 			SSHConfig:                   options.ConfigSSH,
 			HealthcheckFunc:             options.HealthcheckFunc,
 			HealthcheckTimeout:          options.HealthcheckTimeout,
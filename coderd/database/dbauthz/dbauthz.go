@@ -176,6 +176,28 @@ var (
 		Scope: rbac.ScopeAll,
 	}.WithCachedASTValue()
 
+	subjectSCIM = rbac.Subject{
+		ID: uuid.Nil.String(),
+		Roles: rbac.Roles([]rbac.Role{
+			{
+				Name:        "scim",
+				DisplayName: "SCIM Provisioning",
+				Site: rbac.Permissions(map[string][]rbac.Action{
+					rbac.ResourceWildcard.Type:           {rbac.ActionRead},
+					rbac.ResourceGroup.Type:              {rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
+					rbac.ResourceRoleAssignment.Type:     {rbac.ActionCreate},
+					rbac.ResourceOrganizationMember.Type: {rbac.ActionCreate},
+					rbac.ResourceOrgRoleAssignment.Type:  {rbac.ActionCreate},
+					rbac.ResourceUser.Type:               {rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
+					rbac.ResourceUserData.Type:           {rbac.ActionCreate, rbac.ActionUpdate},
+				}),
+				Org:  map[string][]rbac.Permission{},
+				User: []rbac.Permission{},
+			},
+		}),
+		Scope: rbac.ScopeAll,
+	}.WithCachedASTValue()
+
 	subjectSystemRestricted = rbac.Subject{
 		ID: uuid.Nil.String(),
 		Roles: rbac.Roles([]rbac.Role{
@@ -186,7 +208,8 @@ var (
 					rbac.ResourceWildcard.Type:           {rbac.ActionRead},
 					rbac.ResourceAPIKey.Type:             {rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
 					rbac.ResourceGroup.Type:              {rbac.ActionCreate, rbac.ActionUpdate},
-					rbac.ResourceRoleAssignment.Type:     {rbac.ActionCreate},
+					rbac.ResourceOAuth2ProviderApp.Type:  {rbac.ActionUpdate},
+					rbac.ResourceRoleAssignment.Type:     {rbac.ActionCreate, rbac.ActionDelete},
 					rbac.ResourceSystem.Type:             {rbac.WildcardSymbol},
 					rbac.ResourceOrganization.Type:       {rbac.ActionCreate},
 					rbac.ResourceOrganizationMember.Type: {rbac.ActionCreate},
@@ -217,6 +240,12 @@ func AsAutostart(ctx context.Context) context.Context {
 	return context.WithValue(ctx, authContextKey{}, subjectAutostart)
 }
 
+// AsSCIM returns a context with an actor that has permissions required for
+// SCIM requests to provision users and groups.
+func AsSCIM(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authContextKey{}, subjectSCIM)
+}
+
 // AsSystemRestricted returns a context with an actor that has permissions
 // required for various system operations (login, logout, metrics cache).
 func AsSystemRestricted(ctx context.Context) context.Context {
@@ -496,6 +525,44 @@ func (q *querier) authorizeUpdateFileTemplate(ctx context.Context, file database
 	}
 }
 
+// authorizeTemplateVersionRollout authorizes access to the rollouts of a
+// template. Rollouts list the workspaces of other users, so only actors that
+// can update the template may read them. The rollout runner acts as the
+// system instead.
+func (q *querier) authorizeTemplateVersionRollout(ctx context.Context, systemAction rbac.Action, templateID uuid.UUID) error {
+	template, err := q.db.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, template)
+	if err != nil && q.authorizeContext(ctx, systemAction, rbac.ResourceSystem) != nil {
+		return err
+	}
+	return nil
+}
+
+// authorizeTemplateVersionPresets authorizes the action against the template
+// of the version. Versions that aren't attached to a template yet are
+// authorized against the organization.
+func (q *querier) authorizeTemplateVersionPresets(ctx context.Context, action rbac.Action, templateVersionID uuid.UUID) error {
+	tv, err := q.db.GetTemplateVersionByID(ctx, templateVersionID)
+	if err != nil {
+		return err
+	}
+
+	var object rbac.Objecter
+	template, err := q.db.GetTemplateByID(ctx, tv.TemplateID.UUID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		object = rbac.ResourceTemplate.InOrg(tv.OrganizationID)
+	} else {
+		object = tv.RBACObject(template)
+	}
+	return q.authorizeContext(ctx, action, object)
+}
+
 func (q *querier) canAssignRoles(ctx context.Context, orgID *uuid.UUID, added, removed []string) error {
 	actor, ok := ActorFromContext(ctx)
 	if !ok {
@@ -683,6 +750,31 @@ func (q *querier) AcquireProvisionerJob(ctx context.Context, arg database.Acquir
 	return q.db.AcquireProvisionerJob(ctx, arg)
 }
 
+func (q *querier) ArchiveUnusedTemplateVersions(ctx context.Context, arg database.ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	tpl, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, tpl); err != nil {
+		return nil, err
+	}
+	return q.db.ArchiveUnusedTemplateVersions(ctx, arg)
+}
+
+func (q *querier) CleanTailnetLostPeers(ctx context.Context, updatedAt time.Time) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.CleanTailnetLostPeers(ctx, updatedAt)
+}
+
+func (q *querier) CleanTailnetLostTunnels(ctx context.Context, updatedAt time.Time) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.CleanTailnetLostTunnels(ctx, updatedAt)
+}
+
 func (q *querier) DeleteAPIKeyByID(ctx context.Context, id string) error {
 	return deleteQ(q.log, q.auth, q.db.GetAPIKeyByID, q.db.DeleteAPIKeyByID)(ctx, id)
 }
@@ -697,6 +789,13 @@ func (q *querier) DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUID) e
 	return q.db.DeleteAPIKeysByUserID(ctx, userID)
 }
 
+func (q *querier) DeleteAllTailnetTunnels(ctx context.Context, arg database.DeleteAllTailnetTunnelsParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteAllTailnetTunnels(ctx, arg)
+}
+
 func (q *querier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error {
 	// TODO: This is not 100% correct because it omits apikey IDs.
 	err := q.authorizeContext(ctx, rbac.ActionDelete,
@@ -707,6 +806,48 @@ func (q *querier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, u
 	return q.db.DeleteApplicationConnectAPIKeysByUserID(ctx, userID)
 }
 
+func (q *querier) DeleteDERPRegionByID(ctx context.Context, regionID int32) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceDeploymentValues); err != nil {
+		return err
+	}
+	return q.db.DeleteDERPRegionByID(ctx, regionID)
+}
+
+func (q *querier) DeleteDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceDeploymentValues); err != nil {
+		return err
+	}
+	return q.db.DeleteDERPRegionPolicyByID(ctx, id)
+}
+
+func (q *querier) DeleteExpiredOAuth2DeviceCodes(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteExpiredOAuth2DeviceCodes(ctx)
+}
+
+func (q *querier) DeleteExpiredOAuth2ProviderAppCodes(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteExpiredOAuth2ProviderAppCodes(ctx)
+}
+
+func (q *querier) DeleteExpiredTailnetCoordinators(ctx context.Context, heartbeatAt time.Time) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteExpiredTailnetCoordinators(ctx, heartbeatAt)
+}
+
+func (q *querier) DeleteExpiredWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteExpiredWorkspaceAppSecurityKeys(ctx, now)
+}
+
 func (q *querier) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
 	return deleteQ(q.log, q.auth, q.db.GetGitSSHKey, q.db.DeleteGitSSHKey)(ctx, userID)
 }
@@ -744,6 +885,55 @@ func (q *querier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
 	return id, nil
 }
 
+func (q *querier) DeleteOAuth2DeviceCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return uuid.Nil, err
+	}
+	return q.db.DeleteOAuth2DeviceCodeByID(ctx, id)
+}
+
+func (q *querier) DeleteOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) error {
+	return deleteQ(q.log, q.auth, q.db.GetOAuth2ProviderAppByID, q.db.DeleteOAuth2ProviderAppByID)(ctx, id)
+}
+
+func (q *querier) DeleteOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	err := deleteQ(q.log, q.auth, q.db.GetOAuth2ProviderAppCodeByID, func(ctx context.Context, id uuid.UUID) error {
+		_, err := q.db.DeleteOAuth2ProviderAppCodeByID(ctx, id)
+		return err
+	})(ctx, id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func (q *querier) DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Context, arg database.DeleteOAuth2ProviderAppCodesByAppAndUserIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceAPIKey.WithOwner(arg.UserID.String())); err != nil {
+		return err
+	}
+	return q.db.DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx, arg)
+}
+
+func (q *querier) DeleteOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) error {
+	return deleteQ(q.log, q.auth, q.db.GetOAuth2ProviderAppSecretByID, q.db.DeleteOAuth2ProviderAppSecretByID)(ctx, id)
+}
+
+func (q *querier) DeleteOAuth2ProviderAppTokenByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	// Tokens are only deleted when they are exchanged, which is done by
+	// applications rather than users.
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return uuid.Nil, err
+	}
+	return q.db.DeleteOAuth2ProviderAppTokenByID(ctx, id)
+}
+
+func (q *querier) DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Context, arg database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceAPIKey.WithOwner(arg.UserID.String())); err != nil {
+		return err
+	}
+	return q.db.DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx, arg)
+}
+
 func (q *querier) DeleteOldWorkspaceAgentStartupLogs(ctx context.Context) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
 		return err
@@ -758,6 +948,13 @@ func (q *querier) DeleteOldWorkspaceAgentStats(ctx context.Context) error {
 	return q.db.DeleteOldWorkspaceAgentStats(ctx)
 }
 
+func (q *querier) DeleteOldWorkspaceAppUsageRollups(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteOldWorkspaceAppUsageRollups(ctx)
+}
+
 func (q *querier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
 		return err
@@ -765,6 +962,116 @@ func (q *querier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt tim
 	return q.db.DeleteReplicasUpdatedBefore(ctx, updatedAt)
 }
 
+func (q *querier) DeleteSessionAPIKeysByUserID(ctx context.Context, arg database.DeleteSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	// Every deleted key is owned by the user, so this is the same as
+	// deleting each of them.
+	err := q.authorizeContext(ctx, rbac.ActionDelete,
+		rbac.ResourceAPIKey.WithOwner(arg.UserID.String()))
+	if err != nil {
+		return nil, err
+	}
+	return q.db.DeleteSessionAPIKeysByUserID(ctx, arg)
+}
+
+func (q *querier) DeleteTailnetCoordinator(ctx context.Context, id uuid.UUID) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteTailnetCoordinator(ctx, id)
+}
+
+func (q *querier) DeleteTailnetPeer(ctx context.Context, arg database.DeleteTailnetPeerParams) (database.DeleteTailnetPeerRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return database.DeleteTailnetPeerRow{}, err
+	}
+	return q.db.DeleteTailnetPeer(ctx, arg)
+}
+
+func (q *querier) DeleteTailnetTunnel(ctx context.Context, arg database.DeleteTailnetTunnelParams) (database.DeleteTailnetTunnelRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return database.DeleteTailnetTunnelRow{}, err
+	}
+	return q.db.DeleteTailnetTunnel(ctx, arg)
+}
+
+func (q *querier) DeleteTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	// Removing the git source of a template counts as updating the template.
+	fetch := func(ctx context.Context, templateID uuid.UUID) (database.Template, error) {
+		return q.db.GetTemplateByID(ctx, templateID)
+	}
+	return update(q.log, q.auth, fetch, q.db.DeleteTemplateGitSourceByTemplateID)(ctx, templateID)
+}
+
+func (q *querier) DeleteTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) error {
+	if err := q.authorizeTemplateVersionPresets(ctx, rbac.ActionUpdate, templateVersionID); err != nil {
+		return err
+	}
+	return q.db.DeleteTemplateVersionPresetsByTemplateVersionID(ctx, templateVersionID)
+}
+
+func (q *querier) DeleteUserLoginFailures(ctx context.Context, userID uuid.UUID) error {
+	user, err := q.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// Unlocking a user is a change to the user, like activating them.
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, user.RBACObject()); err != nil {
+		return err
+	}
+	return q.db.DeleteUserLoginFailures(ctx, userID)
+}
+
+func (q *querier) DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error {
+	user, err := q.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return NoActorError
+	}
+	if actor.ID == userID.String() {
+		err = q.authorizeContext(ctx, rbac.ActionDelete, user.UserDataRBACObject())
+		if err != nil {
+			return err
+		}
+		return q.db.DeleteUserTOTP(ctx, userID)
+	}
+
+	// Resetting the enrollment of another user lets anyone with their
+	// password log in as them, so admins can only reset the enrollment of
+	// users whose roles they could assign.
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, user.RBACObject())
+	if err != nil {
+		return err
+	}
+	err = q.canAssignRoles(ctx, nil, user.RBACRoles, nil)
+	if err != nil {
+		return NotAuthorizedError{Err: err}
+	}
+
+	return q.db.DeleteUserTOTP(ctx, userID)
+}
+
+func (q *querier) DeleteWorkspacePortShare(ctx context.Context, arg database.DeleteWorkspacePortShareParams) error {
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, workspace.RBACObject()); err != nil {
+		return err
+	}
+	return q.db.DeleteWorkspacePortShare(ctx, arg)
+}
+
+func (q *querier) ExpireWorkspaceAppSecurityKeys(ctx context.Context, arg database.ExpireWorkspaceAppSecurityKeysParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.ExpireWorkspaceAppSecurityKeys(ctx, arg)
+}
+
 func (q *querier) GetAPIKeyByID(ctx context.Context, id string) (database.APIKey, error) {
 	return fetch(q.log, q.auth, q.db.GetAPIKeyByID)(ctx, id)
 }
@@ -773,6 +1080,13 @@ func (q *querier) GetAPIKeyByName(ctx context.Context, arg database.GetAPIKeyByN
 	return fetch(q.log, q.auth, q.db.GetAPIKeyByName)(ctx, arg)
 }
 
+func (q *querier) GetAPIKeyImpersonationByAPIKeyID(ctx context.Context, apiKeyID string) (database.GetAPIKeyImpersonationByAPIKeyIDRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.GetAPIKeyImpersonationByAPIKeyIDRow{}, err
+	}
+	return q.db.GetAPIKeyImpersonationByAPIKeyID(ctx, apiKeyID)
+}
+
 func (q *querier) GetAPIKeysByLoginType(ctx context.Context, loginType database.LoginType) ([]database.APIKey, error) {
 	return fetchWithPostFilter(q.auth, q.db.GetAPIKeysByLoginType)(ctx, loginType)
 }
@@ -792,11 +1106,6 @@ func (q *querier) GetActiveUserCount(ctx context.Context) (int64, error) {
 	return q.db.GetActiveUserCount(ctx)
 }
 
-func (q *querier) GetAppSecurityKey(ctx context.Context) (string, error) {
-	// No authz checks
-	return q.db.GetAppSecurityKey(ctx)
-}
-
 func (q *querier) GetAuditLogsOffset(ctx context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	// To optimize audit logs, we only check the global audit log permission once.
 	// This is because we expect a large unbounded set of audit logs, and applying a SQL
@@ -821,6 +1130,48 @@ func (q *querier) GetDERPMeshKey(ctx context.Context) (string, error) {
 	return q.db.GetDERPMeshKey(ctx)
 }
 
+func (q *querier) GetDERPRegionByID(ctx context.Context, regionID int32) (database.DERPRegion, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceDeploymentValues); err != nil {
+		return database.DERPRegion{}, err
+	}
+	return q.db.GetDERPRegionByID(ctx, regionID)
+}
+
+func (q *querier) GetDERPRegionPolicies(ctx context.Context) ([]database.DERPRegionPolicy, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceDeploymentValues); err != nil {
+		return nil, err
+	}
+	return q.db.GetDERPRegionPolicies(ctx)
+}
+
+func (q *querier) GetDERPRegionPoliciesByUserID(ctx context.Context, userID uuid.UUID) ([]database.DERPRegionPolicy, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetDERPRegionPoliciesByUserID(ctx, userID)
+}
+
+func (q *querier) GetDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) (database.DERPRegionPolicy, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceDeploymentValues); err != nil {
+		return database.DERPRegionPolicy{}, err
+	}
+	return q.db.GetDERPRegionPolicyByID(ctx, id)
+}
+
+func (q *querier) GetDERPRegionPolicyByTemplateID(ctx context.Context, templateID uuid.NullUUID) (database.DERPRegionPolicy, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.DERPRegionPolicy{}, err
+	}
+	return q.db.GetDERPRegionPolicyByTemplateID(ctx, templateID)
+}
+
+func (q *querier) GetDERPRegions(ctx context.Context) ([]database.DERPRegion, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceDeploymentValues); err != nil {
+		return nil, err
+	}
+	return q.db.GetDERPRegions(ctx)
+}
+
 func (q *querier) GetDefaultProxyConfig(ctx context.Context) (database.GetDefaultProxyConfigRow, error) {
 	// No authz checks
 	return q.db.GetDefaultProxyConfig(ctx)
@@ -977,6 +1328,67 @@ func (q *querier) GetLogoURL(ctx context.Context) (string, error) {
 	return q.db.GetLogoURL(ctx)
 }
 
+func (q *querier) GetOAuth2DeviceCodeByPrefix(ctx context.Context, secretPrefix []byte) (database.OAuth2DeviceCode, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+	return q.db.GetOAuth2DeviceCodeByPrefix(ctx, secretPrefix)
+}
+
+func (q *querier) GetOAuth2DeviceCodeByUserCode(ctx context.Context, userCode string) (database.OAuth2DeviceCode, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+	return q.db.GetOAuth2DeviceCodeByUserCode(ctx, userCode)
+}
+
+func (q *querier) GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderApp, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppByID)(ctx, id)
+}
+
+func (q *querier) GetOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppCode, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppCodeByID)(ctx, id)
+}
+
+func (q *querier) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secretPrefix []byte) (database.OAuth2ProviderAppCode, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppCodeByPrefix)(ctx, secretPrefix)
+}
+
+func (q *querier) GetOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppSecretByID)(ctx, id)
+}
+
+func (q *querier) GetOAuth2ProviderAppSecretByPrefix(ctx context.Context, secretPrefix []byte) (database.OAuth2ProviderAppSecret, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppSecretByPrefix)(ctx, secretPrefix)
+}
+
+func (q *querier) GetOAuth2ProviderAppSecretsByAppID(ctx context.Context, appID uuid.UUID) ([]database.OAuth2ProviderAppSecret, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetOAuth2ProviderAppSecretsByAppID)(ctx, appID)
+}
+
+func (q *querier) GetOAuth2ProviderAppTokenByPrefix(ctx context.Context, hashPrefix []byte) (database.OAuth2ProviderAppToken, error) {
+	// Tokens are only looked up when they are exchanged, which is done by
+	// applications rather than users.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.OAuth2ProviderAppToken{}, err
+	}
+	return q.db.GetOAuth2ProviderAppTokenByPrefix(ctx, hashPrefix)
+}
+
+func (q *querier) GetOAuth2ProviderApps(ctx context.Context) ([]database.OAuth2ProviderApp, error) {
+	return fetchWithPostFilter(q.auth, func(ctx context.Context, _ interface{}) ([]database.OAuth2ProviderApp, error) {
+		return q.db.GetOAuth2ProviderApps(ctx)
+	})(ctx, nil)
+}
+
+func (q *querier) GetOAuth2ProviderAppsByUserID(ctx context.Context, userID uuid.UUID) ([]database.OAuth2ProviderApp, error) {
+	// Applications are authorized by handing them API keys of the user.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceAPIKey.WithOwner(userID.String())); err != nil {
+		return nil, err
+	}
+	return q.db.GetOAuth2ProviderAppsByUserID(ctx, userID)
+}
+
 func (q *querier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (database.Organization, error) {
 	return fetch(q.log, q.auth, q.db.GetOrganizationByID)(ctx, id)
 }
@@ -1124,12 +1536,58 @@ func (q *querier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Ti
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 		return nil, err
 	}
-	return q.db.GetReplicasUpdatedAfter(ctx, updatedAt)
-}
-
-func (q *querier) GetServiceBanner(ctx context.Context) (string, error) {
-	// No authz checks
-	return q.db.GetServiceBanner(ctx)
+	return q.db.GetReplicasUpdatedAfter(ctx, updatedAt)
+}
+
+func (q *querier) GetRunningTemplateVersionRollouts(ctx context.Context) ([]database.TemplateVersionRollout, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetRunningTemplateVersionRollouts(ctx)
+}
+
+func (q *querier) GetServiceBanner(ctx context.Context) (string, error) {
+	// No authz checks
+	return q.db.GetServiceBanner(ctx)
+}
+
+func (q *querier) GetSessionAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]database.APIKey, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetSessionAPIKeysByUserID)(ctx, userID)
+}
+
+func (q *querier) GetTailnetCoordinators(ctx context.Context) ([]database.TailnetCoordinator, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTailnetCoordinators(ctx)
+}
+
+func (q *querier) GetTailnetPeers(ctx context.Context, id uuid.UUID) ([]database.TailnetPeer, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTailnetPeers(ctx, id)
+}
+
+func (q *querier) GetTailnetTunnelPeerBindings(ctx context.Context, srcID uuid.UUID) ([]database.GetTailnetTunnelPeerBindingsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTailnetTunnelPeerBindings(ctx, srcID)
+}
+
+func (q *querier) GetTailnetTunnelPeerIDs(ctx context.Context, srcID uuid.UUID) ([]database.GetTailnetTunnelPeerIDsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTailnetTunnelPeerIDs(ctx, srcID)
+}
+
+func (q *querier) GetTemplateActiveVersionApps(ctx context.Context, templateIDs []uuid.UUID) ([]database.GetTemplateActiveVersionAppsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateActiveVersionApps(ctx, templateIDs)
 }
 
 // Only used by metrics cache.
@@ -1156,6 +1614,30 @@ func (q *querier) GetTemplateDAUs(ctx context.Context, arg database.GetTemplateD
 	return q.db.GetTemplateDAUs(ctx, arg)
 }
 
+func (q *querier) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	// The git source contains the webhook secret, so only actors that can
+	// update the template may read it.
+	template, err := q.db.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, template)
+	if err != nil {
+		// The syncer and webhooks read sources as the system.
+		if q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem) != nil {
+			return database.TemplateGitSource{}, err
+		}
+	}
+	return q.db.GetTemplateGitSourceByTemplateID(ctx, templateID)
+}
+
+func (q *querier) GetTemplateGitSources(ctx context.Context) ([]database.TemplateGitSource, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateGitSources(ctx)
+}
+
 func (q *querier) GetTemplateVersionByID(ctx context.Context, tvid uuid.UUID) (database.TemplateVersion, error) {
 	tv, err := q.db.GetTemplateVersionByID(ctx, tvid)
 	if err != nil {
@@ -1231,6 +1713,67 @@ func (q *querier) GetTemplateVersionParameters(ctx context.Context, templateVers
 	return q.db.GetTemplateVersionParameters(ctx, templateVersionID)
 }
 
+func (q *querier) GetTemplateVersionPresetByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionPreset, error) {
+	preset, err := q.db.GetTemplateVersionPresetByID(ctx, id)
+	if err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+	if err := q.authorizeTemplateVersionPresets(ctx, rbac.ActionRead, preset.TemplateVersionID); err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+	return preset, nil
+}
+
+func (q *querier) GetTemplateVersionPresetParametersByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPresetParameter, error) {
+	if err := q.authorizeTemplateVersionPresets(ctx, rbac.ActionRead, templateVersionID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionPresetParametersByTemplateVersionID(ctx, templateVersionID)
+}
+
+func (q *querier) GetTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	if err := q.authorizeTemplateVersionPresets(ctx, rbac.ActionRead, templateVersionID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionPresetsByTemplateVersionID(ctx, templateVersionID)
+}
+
+func (q *querier) GetTemplateVersionRolloutByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionRollout, error) {
+	rollout, err := q.db.GetTemplateVersionRolloutByID(ctx, id)
+	if err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionRead, rollout.TemplateID); err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+	return rollout, nil
+}
+
+func (q *querier) GetTemplateVersionRolloutCandidates(ctx context.Context, arg database.GetTemplateVersionRolloutCandidatesParams) ([]uuid.UUID, error) {
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionRead, arg.TemplateID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionRolloutCandidates(ctx, arg)
+}
+
+func (q *querier) GetTemplateVersionRolloutWorkspacesByRolloutID(ctx context.Context, rolloutID uuid.UUID) ([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error) {
+	rollout, err := q.db.GetTemplateVersionRolloutByID(ctx, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionRead, rollout.TemplateID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rolloutID)
+}
+
+func (q *querier) GetTemplateVersionRolloutsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.TemplateVersionRollout, error) {
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionRead, templateID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionRolloutsByTemplateID(ctx, templateID)
+}
+
 func (q *querier) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	tv, err := q.db.GetTemplateVersionByID(ctx, templateVersionID)
 	if err != nil {
@@ -1336,6 +1879,17 @@ func (q *querier) GetUserLinkByUserIDLoginType(ctx context.Context, arg database
 	return q.db.GetUserLinkByUserIDLoginType(ctx, arg)
 }
 
+func (q *querier) GetUserLoginFailuresByUserID(ctx context.Context, userID uuid.UUID) (database.UserLoginFailure, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.UserLoginFailure{}, err
+	}
+	return q.db.GetUserLoginFailuresByUserID(ctx, userID)
+}
+
+func (q *querier) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	return fetch(q.log, q.auth, q.db.GetUserTOTPByUserID)(ctx, userID)
+}
+
 func (q *querier) GetUsers(ctx context.Context, arg database.GetUsersParams) ([]database.GetUsersRow, error) {
 	// TODO: We should use GetUsersWithCount with a better method signature.
 	return fetchWithPostFilter(q.auth, q.db.GetUsers)(ctx, arg)
@@ -1457,6 +2011,20 @@ func (q *querier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg datab
 	return q.db.GetWorkspaceAppByAgentIDAndSlug(ctx, arg)
 }
 
+func (q *querier) GetWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) ([]database.WorkspaceAppSecurityKey, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAppSecurityKeys(ctx, now)
+}
+
+func (q *querier) GetWorkspaceAppUsageInsights(ctx context.Context, arg database.GetWorkspaceAppUsageInsightsParams) ([]database.GetWorkspaceAppUsageInsightsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAppUsageInsights(ctx, arg)
+}
+
 func (q *querier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]database.WorkspaceApp, error) {
 	if _, err := q.GetWorkspaceByAgentID(ctx, agentID); err != nil {
 		return nil, err
@@ -1555,6 +2123,21 @@ func (q *querier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceApp
 	return fetch(q.log, q.auth, q.db.GetWorkspaceByWorkspaceAppID)(ctx, workspaceAppID)
 }
 
+func (q *querier) GetWorkspacePortShare(ctx context.Context, arg database.GetWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	// If we can fetch the workspace, we can fetch its port shares.
+	if _, err := q.GetWorkspaceByID(ctx, arg.WorkspaceID); err != nil {
+		return database.WorkspacePortShare{}, err
+	}
+	return q.db.GetWorkspacePortShare(ctx, arg)
+}
+
+func (q *querier) GetWorkspacePortSharesByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]database.WorkspacePortShare, error) {
+	if _, err := q.GetWorkspaceByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspacePortSharesByWorkspaceID(ctx, workspaceID)
+}
+
 func (q *querier) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
 	return fetchWithPostFilter(q.auth, func(ctx context.Context, _ interface{}) ([]database.WorkspaceProxy, error) {
 		return q.db.GetWorkspaceProxies(ctx)
@@ -1687,6 +2270,18 @@ func (q *querier) InsertAPIKey(ctx context.Context, arg database.InsertAPIKeyPar
 		q.db.InsertAPIKey)(ctx, arg)
 }
 
+func (q *querier) InsertAPIKeyImpersonation(ctx context.Context, arg database.InsertAPIKeyImpersonationParams) (database.APIKeyImpersonation, error) {
+	// Marking a key as an impersonation key is part of creating the key.
+	key, err := q.db.GetAPIKeyByID(ctx, arg.APIKeyID)
+	if err != nil {
+		return database.APIKeyImpersonation{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, key); err != nil {
+		return database.APIKeyImpersonation{}, err
+	}
+	return q.db.InsertAPIKeyImpersonation(ctx, arg)
+}
+
 func (q *querier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (database.Group, error) {
 	// This method creates a new group.
 	return insert(q.log, q.auth, rbac.ResourceGroup.InOrg(organizationID), q.db.InsertAllUsersGroup)(ctx, organizationID)
@@ -1703,6 +2298,20 @@ func (q *querier) InsertDERPMeshKey(ctx context.Context, value string) error {
 	return q.db.InsertDERPMeshKey(ctx, value)
 }
 
+func (q *querier) InsertDERPRegion(ctx context.Context, arg database.InsertDERPRegionParams) (database.DERPRegion, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceDeploymentValues); err != nil {
+		return database.DERPRegion{}, err
+	}
+	return q.db.InsertDERPRegion(ctx, arg)
+}
+
+func (q *querier) InsertDERPRegionPolicy(ctx context.Context, arg database.InsertDERPRegionPolicyParams) (database.DERPRegionPolicy, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceDeploymentValues); err != nil {
+		return database.DERPRegionPolicy{}, err
+	}
+	return q.db.InsertDERPRegionPolicy(ctx, arg)
+}
+
 func (q *querier) InsertDeploymentID(ctx context.Context, value string) error {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
 		return err
@@ -1740,6 +2349,36 @@ func (q *querier) InsertLicense(ctx context.Context, arg database.InsertLicenseP
 	return q.db.InsertLicense(ctx, arg)
 }
 
+func (q *querier) InsertOAuth2DeviceCode(ctx context.Context, arg database.InsertOAuth2DeviceCodeParams) (database.OAuth2DeviceCode, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+	return q.db.InsertOAuth2DeviceCode(ctx, arg)
+}
+
+func (q *querier) InsertOAuth2ProviderApp(ctx context.Context, arg database.InsertOAuth2ProviderAppParams) (database.OAuth2ProviderApp, error) {
+	return insert(q.log, q.auth, rbac.ResourceOAuth2ProviderApp, q.db.InsertOAuth2ProviderApp)(ctx, arg)
+}
+
+func (q *querier) InsertOAuth2ProviderAppCode(ctx context.Context, arg database.InsertOAuth2ProviderAppCodeParams) (database.OAuth2ProviderAppCode, error) {
+	return insert(q.log, q.auth, rbac.ResourceAPIKey.WithOwner(arg.UserID.String()), q.db.InsertOAuth2ProviderAppCode)(ctx, arg)
+}
+
+func (q *querier) InsertOAuth2ProviderAppSecret(ctx context.Context, arg database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
+	return insert(q.log, q.auth, rbac.ResourceOAuth2ProviderApp.WithID(arg.AppID), q.db.InsertOAuth2ProviderAppSecret)(ctx, arg)
+}
+
+func (q *querier) InsertOAuth2ProviderAppToken(ctx context.Context, arg database.InsertOAuth2ProviderAppTokenParams) (database.OAuth2ProviderAppToken, error) {
+	key, err := q.db.GetAPIKeyByID(ctx, arg.APIKeyID)
+	if err != nil {
+		return database.OAuth2ProviderAppToken{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, key.RBACObject()); err != nil {
+		return database.OAuth2ProviderAppToken{}, err
+	}
+	return q.db.InsertOAuth2ProviderAppToken(ctx, arg)
+}
+
 func (q *querier) InsertOrganization(ctx context.Context, arg database.InsertOrganizationParams) (database.Organization, error) {
 	return insert(q.log, q.auth, rbac.ResourceOrganization, q.db.InsertOrganization)(ctx, arg)
 }
@@ -1787,6 +2426,11 @@ func (q *querier) InsertReplica(ctx context.Context, arg database.InsertReplicaP
 	return q.db.InsertReplica(ctx, arg)
 }
 
+func (q *querier) InsertServiceAccount(ctx context.Context, arg database.InsertServiceAccountParams) (database.ServiceAccount, error) {
+	// Creating a service account is part of creating the user.
+	return insert(q.log, q.auth, rbac.ResourceUser, q.db.InsertServiceAccount)(ctx, arg)
+}
+
 func (q *querier) InsertTemplate(ctx context.Context, arg database.InsertTemplateParams) (database.Template, error) {
 	obj := rbac.ResourceTemplate.InOrg(arg.OrganizationID)
 	return insert(q.log, q.auth, obj, q.db.InsertTemplate)(ctx, arg)
@@ -1822,6 +2466,42 @@ func (q *querier) InsertTemplateVersionParameter(ctx context.Context, arg databa
 	return q.db.InsertTemplateVersionParameter(ctx, arg)
 }
 
+func (q *querier) InsertTemplateVersionPreset(ctx context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	if err := q.authorizeTemplateVersionPresets(ctx, rbac.ActionUpdate, arg.TemplateVersionID); err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+	return q.db.InsertTemplateVersionPreset(ctx, arg)
+}
+
+func (q *querier) InsertTemplateVersionPresetParameters(ctx context.Context, arg database.InsertTemplateVersionPresetParametersParams) error {
+	preset, err := q.db.GetTemplateVersionPresetByID(ctx, arg.PresetID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeTemplateVersionPresets(ctx, rbac.ActionUpdate, preset.TemplateVersionID); err != nil {
+		return err
+	}
+	return q.db.InsertTemplateVersionPresetParameters(ctx, arg)
+}
+
+func (q *querier) InsertTemplateVersionRollout(ctx context.Context, arg database.InsertTemplateVersionRolloutParams) (database.TemplateVersionRollout, error) {
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionCreate, arg.TemplateID); err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+	return q.db.InsertTemplateVersionRollout(ctx, arg)
+}
+
+func (q *querier) InsertTemplateVersionRolloutWorkspace(ctx context.Context, arg database.InsertTemplateVersionRolloutWorkspaceParams) error {
+	rollout, err := q.db.GetTemplateVersionRolloutByID(ctx, arg.RolloutID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionCreate, rollout.TemplateID); err != nil {
+		return err
+	}
+	return q.db.InsertTemplateVersionRolloutWorkspace(ctx, arg)
+}
+
 func (q *querier) InsertTemplateVersionVariable(ctx context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
 		return database.TemplateVersionVariable{}, err
@@ -1858,6 +2538,13 @@ func (q *querier) InsertUserLink(ctx context.Context, arg database.InsertUserLin
 	return q.db.InsertUserLink(ctx, arg)
 }
 
+func (q *querier) InsertUserLoginFailure(ctx context.Context, arg database.InsertUserLoginFailureParams) (database.UserLoginFailure, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.UserLoginFailure{}, err
+	}
+	return q.db.InsertUserLoginFailure(ctx, arg)
+}
+
 func (q *querier) InsertWorkspace(ctx context.Context, arg database.InsertWorkspaceParams) (database.Workspace, error) {
 	obj := rbac.ResourceWorkspace.WithOwner(arg.OwnerID.String()).InOrg(arg.OrganizationID)
 	return insert(q.log, q.auth, obj, q.db.InsertWorkspace)(ctx, arg)
@@ -1907,6 +2594,13 @@ func (q *querier) InsertWorkspaceApp(ctx context.Context, arg database.InsertWor
 	return q.db.InsertWorkspaceApp(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAppSecurityKey(ctx context.Context, arg database.InsertWorkspaceAppSecurityKeyParams) (database.WorkspaceAppSecurityKey, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.WorkspaceAppSecurityKey{}, err
+	}
+	return q.db.InsertWorkspaceAppSecurityKey(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceBuild(ctx context.Context, arg database.InsertWorkspaceBuildParams) (database.WorkspaceBuild, error) {
 	w, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
@@ -1981,6 +2675,27 @@ func (q *querier) UpdateAPIKeyByID(ctx context.Context, arg database.UpdateAPIKe
 	return update(q.log, q.auth, fetch, q.db.UpdateAPIKeyByID)(ctx, arg)
 }
 
+func (q *querier) UpdateDERPRegionByID(ctx context.Context, arg database.UpdateDERPRegionByIDParams) (database.DERPRegion, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceDeploymentValues); err != nil {
+		return database.DERPRegion{}, err
+	}
+	return q.db.UpdateDERPRegionByID(ctx, arg)
+}
+
+func (q *querier) UpdateDERPRegionHealthByID(ctx context.Context, arg database.UpdateDERPRegionHealthByIDParams) (database.DERPRegion, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.DERPRegion{}, err
+	}
+	return q.db.UpdateDERPRegionHealthByID(ctx, arg)
+}
+
+func (q *querier) UpdateDERPRegionPolicyByID(ctx context.Context, arg database.UpdateDERPRegionPolicyByIDParams) (database.DERPRegionPolicy, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceDeploymentValues); err != nil {
+		return database.DERPRegionPolicy{}, err
+	}
+	return q.db.UpdateDERPRegionPolicyByID(ctx, arg)
+}
+
 func (q *querier) UpdateGitAuthLink(ctx context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	fetch := func(ctx context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 		return q.db.GetGitAuthLink(ctx, database.GetGitAuthLinkParams{UserID: arg.UserID, ProviderID: arg.ProviderID})
@@ -2023,6 +2738,34 @@ func (q *querier) UpdateMemberRoles(ctx context.Context, arg database.UpdateMemb
 	return q.db.UpdateMemberRoles(ctx, arg)
 }
 
+func (q *querier) UpdateOAuth2DeviceCodePolledByID(ctx context.Context, arg database.UpdateOAuth2DeviceCodePolledByIDParams) (database.OAuth2DeviceCode, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+	return q.db.UpdateOAuth2DeviceCodePolledByID(ctx, arg)
+}
+
+func (q *querier) UpdateOAuth2DeviceCodeStatusByID(ctx context.Context, arg database.UpdateOAuth2DeviceCodeStatusByIDParams) (database.OAuth2DeviceCode, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+	return q.db.UpdateOAuth2DeviceCodeStatusByID(ctx, arg)
+}
+
+func (q *querier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg database.UpdateOAuth2ProviderAppByIDParams) (database.OAuth2ProviderApp, error) {
+	fetch := func(ctx context.Context, arg database.UpdateOAuth2ProviderAppByIDParams) (database.OAuth2ProviderApp, error) {
+		return q.db.GetOAuth2ProviderAppByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateOAuth2ProviderAppByID)(ctx, arg)
+}
+
+func (q *querier) UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
+	fetch := func(ctx context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
+		return q.db.GetOAuth2ProviderAppSecretByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateOAuth2ProviderAppSecretByID)(ctx, arg)
+}
+
 // TODO: We need to create a ProvisionerJob resource type
 func (q *querier) UpdateProvisionerJobByID(ctx context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	// if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
@@ -2113,6 +2856,13 @@ func (q *querier) UpdateReplica(ctx context.Context, arg database.UpdateReplicaP
 	return q.db.UpdateReplica(ctx, arg)
 }
 
+func (q *querier) UpdateTailnetPeerStatusByCoordinator(ctx context.Context, arg database.UpdateTailnetPeerStatusByCoordinatorParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateTailnetPeerStatusByCoordinator(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateACLByID(ctx context.Context, arg database.UpdateTemplateACLByIDParams) (database.Template, error) {
 	// UpdateTemplateACL uses the ActionCreate action. Only users that can create the template
 	// may update the ACL.
@@ -2134,6 +2884,13 @@ func (q *querier) UpdateTemplateDeletedByID(ctx context.Context, arg database.Up
 	return q.SoftDeleteTemplateByID(ctx, arg.ID)
 }
 
+func (q *querier) UpdateTemplateGitSourceSyncStatus(ctx context.Context, arg database.UpdateTemplateGitSourceSyncStatusParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateGitSourceSyncStatus(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateMetaByID(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 	fetch := func(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 		return q.db.GetTemplateByID(ctx, arg.ID)
@@ -2214,6 +2971,50 @@ func (q *querier) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Conte
 	return q.db.UpdateTemplateVersionGitAuthProvidersByJobID(ctx, arg)
 }
 
+func (q *querier) UpdateTemplateVersionRolloutStatusByID(ctx context.Context, arg database.UpdateTemplateVersionRolloutStatusByIDParams) (database.TemplateVersionRollout, error) {
+	rollout, err := q.db.GetTemplateVersionRolloutByID(ctx, arg.ID)
+	if err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionUpdate, rollout.TemplateID); err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+	return q.db.UpdateTemplateVersionRolloutStatusByID(ctx, arg)
+}
+
+func (q *querier) UpdateTemplateVersionRolloutWorkspace(ctx context.Context, arg database.UpdateTemplateVersionRolloutWorkspaceParams) error {
+	rollout, err := q.db.GetTemplateVersionRolloutByID(ctx, arg.RolloutID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeTemplateVersionRollout(ctx, rbac.ActionUpdate, rollout.TemplateID); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateVersionRolloutWorkspace(ctx, arg)
+}
+
+func (q *querier) UpdateTemplateVersionStateByID(ctx context.Context, arg database.UpdateTemplateVersionStateByIDParams) error {
+	// An actor is allowed to update the template version state if they are authorized to update the template.
+	tv, err := q.db.GetTemplateVersionByID(ctx, arg.ID)
+	if err != nil {
+		return err
+	}
+	var obj rbac.Objecter
+	if !tv.TemplateID.Valid {
+		obj = rbac.ResourceTemplate.InOrg(tv.OrganizationID)
+	} else {
+		tpl, err := q.db.GetTemplateByID(ctx, tv.TemplateID.UUID)
+		if err != nil {
+			return err
+		}
+		obj = tpl
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, obj); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateVersionStateByID(ctx, arg)
+}
+
 // UpdateUserDeletedByID
 // Deprecated: Delete this function in favor of 'SoftDeleteUserByID'. Deletes are
 // irreversible.
@@ -2268,6 +3069,13 @@ func (q *querier) UpdateUserLinkedID(ctx context.Context, arg database.UpdateUse
 	return q.db.UpdateUserLinkedID(ctx, arg)
 }
 
+func (q *querier) UpdateUserLoginLockout(ctx context.Context, arg database.UpdateUserLoginLockoutParams) (database.UserLoginFailure, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.UserLoginFailure{}, err
+	}
+	return q.db.UpdateUserLoginLockout(ctx, arg)
+}
+
 func (q *querier) UpdateUserProfile(ctx context.Context, arg database.UpdateUserProfileParams) (database.User, error) {
 	u, err := q.db.GetUserByID(ctx, arg.ID)
 	if err != nil {
@@ -2309,6 +3117,13 @@ func (q *querier) UpdateUserStatus(ctx context.Context, arg database.UpdateUserS
 	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateUserStatus)(ctx, arg)
 }
 
+func (q *querier) UpdateUserTOTP(ctx context.Context, arg database.UpdateUserTOTPParams) (database.UserTOTP, error) {
+	fetch := func(ctx context.Context, arg database.UpdateUserTOTPParams) (database.UserTOTP, error) {
+		return q.db.GetUserTOTPByUserID(ctx, arg.UserID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateUserTOTP)(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspace(ctx context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -2355,6 +3170,24 @@ func (q *querier) UpdateWorkspaceAgentMetadata(ctx context.Context, arg database
 	return q.db.UpdateWorkspaceAgentMetadata(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceAgentPeerConnectionsByID(ctx context.Context, arg database.UpdateWorkspaceAgentPeerConnectionsByIDParams) error {
+	agent, err := q.db.GetWorkspaceAgentByID(ctx, arg.ID)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, agent.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, workspace); err != nil {
+		return err
+	}
+
+	return q.db.UpdateWorkspaceAgentPeerConnectionsByID(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	agent, err := q.db.GetWorkspaceAgentByID(ctx, arg.ID)
 	if err != nil {
@@ -2483,11 +3316,6 @@ func (q *querier) UpdateWorkspaceTTLToBeWithinTemplateMax(ctx context.Context, a
 	return fetchAndExec(q.log, q.auth, rbac.ActionUpdate, fetch, q.db.UpdateWorkspaceTTLToBeWithinTemplateMax)(ctx, arg)
 }
 
-func (q *querier) UpsertAppSecurityKey(ctx context.Context, data string) error {
-	// No authz checks as this is done during startup
-	return q.db.UpsertAppSecurityKey(ctx, data)
-}
-
 func (q *querier) UpsertDefaultProxy(ctx context.Context, arg database.UpsertDefaultProxyParams) error {
 	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
 		return err
@@ -2515,3 +3343,60 @@ func (q *querier) UpsertServiceBanner(ctx context.Context, value string) error {
 	}
 	return q.db.UpsertServiceBanner(ctx, value)
 }
+
+func (q *querier) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (database.TailnetCoordinator, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.TailnetCoordinator{}, err
+	}
+	return q.db.UpsertTailnetCoordinator(ctx, id)
+}
+
+func (q *querier) UpsertTailnetPeer(ctx context.Context, arg database.UpsertTailnetPeerParams) (database.TailnetPeer, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.TailnetPeer{}, err
+	}
+	return q.db.UpsertTailnetPeer(ctx, arg)
+}
+
+func (q *querier) UpsertTailnetTunnel(ctx context.Context, arg database.UpsertTailnetTunnelParams) (database.TailnetTunnel, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.TailnetTunnel{}, err
+	}
+	return q.db.UpsertTailnetTunnel(ctx, arg)
+}
+
+func (q *querier) UpsertTemplateGitSource(ctx context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+	return q.db.UpsertTemplateGitSource(ctx, arg)
+}
+
+func (q *querier) UpsertUserTOTP(ctx context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceUserData.WithOwner(arg.UserID.String()).WithID(arg.UserID)); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return q.db.UpsertUserTOTP(ctx, arg)
+}
+
+func (q *querier) UpsertWorkspaceAppUsageRollup(ctx context.Context, arg database.UpsertWorkspaceAppUsageRollupParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpsertWorkspaceAppUsageRollup(ctx, arg)
+}
+
+func (q *querier) UpsertWorkspacePortShare(ctx context.Context, arg database.UpsertWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return database.WorkspacePortShare{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, workspace.RBACObject()); err != nil {
+		return database.WorkspacePortShare{}, err
+	}
+	return q.db.UpsertWorkspacePortShare(ctx, arg)
+}
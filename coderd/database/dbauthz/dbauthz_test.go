@@ -179,6 +179,20 @@ func (s *MethodTestSuite) TestAPIKey() {
 			UserID:    key.UserID,
 		}).Asserts(key, rbac.ActionRead).Returns(key)
 	}))
+	s.Run("GetAPIKeyImpersonationByAPIKeyID", s.Subtest(func(db database.Store, check *expects) {
+		owner := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{LoginType: database.LoginTypeImpersonation})
+		impersonation := dbgen.APIKeyImpersonation(s.T(), db, database.APIKeyImpersonation{
+			APIKeyID:       key.ID,
+			ImpersonatorID: owner.ID,
+		})
+		check.Args(key.ID).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(database.GetAPIKeyImpersonationByAPIKeyIDRow{
+			APIKeyID:             impersonation.APIKeyID,
+			ImpersonatorID:       impersonation.ImpersonatorID,
+			CreatedAt:            impersonation.CreatedAt,
+			ImpersonatorUsername: owner.Username,
+		})
+	}))
 	s.Run("GetAPIKeysByLoginType", s.Subtest(func(db database.Store, check *expects) {
 		a, _ := dbgen.APIKey(s.T(), db, database.APIKey{LoginType: database.LoginTypePassword})
 		b, _ := dbgen.APIKey(s.T(), db, database.APIKey{LoginType: database.LoginTypePassword})
@@ -199,6 +213,12 @@ func (s *MethodTestSuite) TestAPIKey() {
 			Asserts(keyA, rbac.ActionRead, keyB, rbac.ActionRead).
 			Returns(slice.New(keyA, keyB))
 	}))
+	s.Run("GetSessionAPIKeysByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{UserID: u.ID})
+		_, _ = dbgen.APIKey(s.T(), db, database.APIKey{UserID: u.ID, LoginType: database.LoginTypeToken})
+		check.Args(u.ID).Asserts(key, rbac.ActionRead).Returns(slice.New(key))
+	}))
 	s.Run("GetAPIKeysLastUsedAfter", s.Subtest(func(db database.Store, check *expects) {
 		a, _ := dbgen.APIKey(s.T(), db, database.APIKey{LastUsed: time.Now().Add(time.Hour)})
 		b, _ := dbgen.APIKey(s.T(), db, database.APIKey{LastUsed: time.Now().Add(time.Hour)})
@@ -215,6 +235,14 @@ func (s *MethodTestSuite) TestAPIKey() {
 			Scope:     database.APIKeyScopeAll,
 		}).Asserts(rbac.ResourceAPIKey.WithOwner(u.ID.String()), rbac.ActionCreate)
 	}))
+	s.Run("InsertAPIKeyImpersonation", s.Subtest(func(db database.Store, check *expects) {
+		owner := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{LoginType: database.LoginTypeImpersonation})
+		check.Args(database.InsertAPIKeyImpersonationParams{
+			APIKeyID:       key.ID,
+			ImpersonatorID: owner.ID,
+		}).Asserts(key, rbac.ActionCreate)
+	}))
 	s.Run("UpdateAPIKeyByID", s.Subtest(func(db database.Store, check *expects) {
 		a, _ := dbgen.APIKey(s.T(), db, database.APIKey{})
 		check.Args(database.UpdateAPIKeyByIDParams{
@@ -239,6 +267,108 @@ func (s *MethodTestSuite) TestAuditLogs() {
 	}))
 }
 
+func (s *MethodTestSuite) TestDERPRegion() {
+	insertRegion := func(db database.Store) database.DERPRegion {
+		region, err := db.InsertDERPRegion(context.Background(), database.InsertDERPRegionParams{
+			ID:         uuid.New(),
+			RegionID:   10000,
+			RegionCode: "eu-west",
+			RegionName: "EU West",
+			Nodes:      []byte("[]"),
+			CreatedAt:  database.Now(),
+			UpdatedAt:  database.Now(),
+		})
+		s.NoError(err)
+		return region
+	}
+	insertPolicy := func(db database.Store) database.DERPRegionPolicy {
+		g := dbgen.Group(s.T(), db, database.Group{})
+		policy, err := db.InsertDERPRegionPolicy(context.Background(), database.InsertDERPRegionPolicyParams{
+			ID:        uuid.New(),
+			GroupID:   uuid.NullUUID{UUID: g.ID, Valid: true},
+			RegionIDs: []int32{10000},
+			CreatedAt: database.Now(),
+			UpdatedAt: database.Now(),
+		})
+		s.NoError(err)
+		return policy
+	}
+	s.Run("GetDERPRegions", s.Subtest(func(db database.Store, check *expects) {
+		region := insertRegion(db)
+		check.Args().Asserts(rbac.ResourceDeploymentValues, rbac.ActionRead).Returns([]database.DERPRegion{region})
+	}))
+	s.Run("GetDERPRegionByID", s.Subtest(func(db database.Store, check *expects) {
+		region := insertRegion(db)
+		check.Args(region.RegionID).Asserts(rbac.ResourceDeploymentValues, rbac.ActionRead).Returns(region)
+	}))
+	s.Run("InsertDERPRegion", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertDERPRegionParams{
+			RegionID:   10000,
+			RegionCode: "eu-west",
+			Nodes:      []byte("[]"),
+		}).Asserts(rbac.ResourceDeploymentValues, rbac.ActionCreate)
+	}))
+	s.Run("UpdateDERPRegionByID", s.Subtest(func(db database.Store, check *expects) {
+		region := insertRegion(db)
+		check.Args(database.UpdateDERPRegionByIDParams{
+			RegionID:   region.RegionID,
+			RegionCode: region.RegionCode,
+			Nodes:      region.Nodes,
+		}).Asserts(rbac.ResourceDeploymentValues, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateDERPRegionHealthByID", s.Subtest(func(db database.Store, check *expects) {
+		region := insertRegion(db)
+		check.Args(database.UpdateDERPRegionHealthByIDParams{
+			RegionID: region.RegionID,
+			Healthy:  false,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteDERPRegionByID", s.Subtest(func(db database.Store, check *expects) {
+		region := insertRegion(db)
+		check.Args(region.RegionID).Asserts(rbac.ResourceDeploymentValues, rbac.ActionDelete)
+	}))
+	s.Run("GetDERPRegionPolicies", s.Subtest(func(db database.Store, check *expects) {
+		policy := insertPolicy(db)
+		check.Args().Asserts(rbac.ResourceDeploymentValues, rbac.ActionRead).Returns([]database.DERPRegionPolicy{policy})
+	}))
+	s.Run("GetDERPRegionPolicyByID", s.Subtest(func(db database.Store, check *expects) {
+		policy := insertPolicy(db)
+		check.Args(policy.ID).Asserts(rbac.ResourceDeploymentValues, rbac.ActionRead).Returns(policy)
+	}))
+	s.Run("GetDERPRegionPolicyByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		policy, err := db.InsertDERPRegionPolicy(context.Background(), database.InsertDERPRegionPolicyParams{
+			ID:         uuid.New(),
+			TemplateID: uuid.NullUUID{UUID: tpl.ID, Valid: true},
+			RegionIDs:  []int32{10000},
+		})
+		s.NoError(err)
+		check.Args(policy.TemplateID).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(policy)
+	}))
+	s.Run("GetDERPRegionPoliciesByUserID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.DERPRegionPolicy{})
+	}))
+	s.Run("InsertDERPRegionPolicy", s.Subtest(func(db database.Store, check *expects) {
+		g := dbgen.Group(s.T(), db, database.Group{})
+		check.Args(database.InsertDERPRegionPolicyParams{
+			ID:        uuid.New(),
+			GroupID:   uuid.NullUUID{UUID: g.ID, Valid: true},
+			RegionIDs: []int32{},
+		}).Asserts(rbac.ResourceDeploymentValues, rbac.ActionCreate)
+	}))
+	s.Run("UpdateDERPRegionPolicyByID", s.Subtest(func(db database.Store, check *expects) {
+		policy := insertPolicy(db)
+		check.Args(database.UpdateDERPRegionPolicyByIDParams{
+			ID:        policy.ID,
+			RegionIDs: []int32{},
+		}).Asserts(rbac.ResourceDeploymentValues, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteDERPRegionPolicyByID", s.Subtest(func(db database.Store, check *expects) {
+		policy := insertPolicy(db)
+		check.Args(policy.ID).Asserts(rbac.ResourceDeploymentValues, rbac.ActionDelete)
+	}))
+}
+
 func (s *MethodTestSuite) TestFile() {
 	s.Run("GetFileByHashAndCreator", s.Subtest(func(db database.Store, check *expects) {
 		f := dbgen.File(s.T(), db, database.File{})
@@ -499,6 +629,244 @@ func (s *MethodTestSuite) TestLicense() {
 	}))
 }
 
+func (s *MethodTestSuite) TestOAuth2ProviderApps() {
+	s.Run("GetOAuth2ProviderApps", s.Subtest(func(db database.Store, check *expects) {
+		apps := []database.OAuth2ProviderApp{
+			dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{Name: "first"}),
+			dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{Name: "last"}),
+		}
+		check.Args().Asserts(apps[0], rbac.ActionRead, apps[1], rbac.ActionRead).Returns(apps)
+	}))
+	s.Run("GetOAuth2ProviderAppsByUserID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{
+			UserID:    user.ID,
+			LoginType: database.LoginTypeOAuth2ProviderApp,
+		})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		_ = dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		_ = dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
+			AppSecretID: secret.ID,
+			APIKeyID:    key.ID,
+		})
+		check.Args(user.ID).Asserts(rbac.ResourceAPIKey.WithOwner(user.ID.String()), rbac.ActionRead).Returns([]database.OAuth2ProviderApp{app})
+	}))
+	s.Run("GetOAuth2ProviderAppByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		check.Args(app.ID).Asserts(app, rbac.ActionRead).Returns(app)
+	}))
+	s.Run("InsertOAuth2ProviderApp", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertOAuth2ProviderAppParams{}).Asserts(rbac.ResourceOAuth2ProviderApp, rbac.ActionCreate)
+	}))
+	s.Run("UpdateOAuth2ProviderAppByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		app.Name = "my-new-name"
+		app.UpdatedAt = database.Now()
+		check.Args(database.UpdateOAuth2ProviderAppByIDParams{
+			ID:          app.ID,
+			Name:        app.Name,
+			CallbackURL: app.CallbackURL,
+			UpdatedAt:   app.UpdatedAt,
+		}).Asserts(rbac.ResourceOAuth2ProviderApp.WithID(app.ID), rbac.ActionUpdate).Returns(app)
+	}))
+	s.Run("DeleteOAuth2ProviderAppByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		check.Args(app.ID).Asserts(app, rbac.ActionDelete)
+	}))
+}
+
+func (s *MethodTestSuite) TestOAuth2ProviderAppSecrets() {
+	s.Run("GetOAuth2ProviderAppSecretsByAppID", s.Subtest(func(db database.Store, check *expects) {
+		app1 := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		app2 := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secrets := []database.OAuth2ProviderAppSecret{
+			dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{
+				AppID:     app1.ID,
+				CreatedAt: time.Now().Add(-time.Hour),
+			}),
+			dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{
+				AppID:     app1.ID,
+				CreatedAt: time.Now(),
+			}),
+		}
+		_ = dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app2.ID})
+		check.Args(app1.ID).Asserts(secrets[0], rbac.ActionRead, secrets[1], rbac.ActionRead).Returns(secrets)
+	}))
+	s.Run("GetOAuth2ProviderAppSecretByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		check.Args(secret.ID).Asserts(secret, rbac.ActionRead).Returns(secret)
+	}))
+	s.Run("GetOAuth2ProviderAppSecretByPrefix", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		check.Args(secret.SecretPrefix).Asserts(secret, rbac.ActionRead).Returns(secret)
+	}))
+	s.Run("InsertOAuth2ProviderAppSecret", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		check.Args(database.InsertOAuth2ProviderAppSecretParams{
+			AppID: app.ID,
+		}).Asserts(rbac.ResourceOAuth2ProviderApp.WithID(app.ID), rbac.ActionCreate)
+	}))
+	s.Run("UpdateOAuth2ProviderAppSecretByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		secret.LastUsedAt = sql.NullTime{Time: database.Now(), Valid: true}
+		check.Args(database.UpdateOAuth2ProviderAppSecretByIDParams{
+			ID:         secret.ID,
+			LastUsedAt: secret.LastUsedAt,
+		}).Asserts(secret, rbac.ActionUpdate).Returns(secret)
+	}))
+	s.Run("DeleteOAuth2ProviderAppSecretByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		check.Args(secret.ID).Asserts(secret, rbac.ActionDelete)
+	}))
+}
+
+func (s *MethodTestSuite) TestOAuth2ProviderAppCodes() {
+	s.Run("GetOAuth2ProviderAppCodeByID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppCode(s.T(), db, database.OAuth2ProviderAppCode{AppID: app.ID, UserID: user.ID})
+		check.Args(code.ID).Asserts(code, rbac.ActionRead).Returns(code)
+	}))
+	s.Run("GetOAuth2ProviderAppCodeByPrefix", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppCode(s.T(), db, database.OAuth2ProviderAppCode{AppID: app.ID, UserID: user.ID})
+		check.Args(code.SecretPrefix).Asserts(code, rbac.ActionRead).Returns(code)
+	}))
+	s.Run("InsertOAuth2ProviderAppCode", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		check.Args(database.InsertOAuth2ProviderAppCodeParams{
+			AppID:  app.ID,
+			UserID: user.ID,
+			Scope:  database.APIKeyScopeAll,
+		}).Asserts(rbac.ResourceAPIKey.WithOwner(user.ID.String()), rbac.ActionCreate)
+	}))
+	s.Run("DeleteOAuth2ProviderAppCodeByID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppCode(s.T(), db, database.OAuth2ProviderAppCode{AppID: app.ID, UserID: user.ID})
+		check.Args(code.ID).Asserts(code, rbac.ActionDelete).Returns(code.ID)
+	}))
+	s.Run("DeleteOAuth2ProviderAppCodesByAppAndUserID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		_ = dbgen.OAuth2ProviderAppCode(s.T(), db, database.OAuth2ProviderAppCode{AppID: app.ID, UserID: user.ID})
+		check.Args(database.DeleteOAuth2ProviderAppCodesByAppAndUserIDParams{
+			AppID:  app.ID,
+			UserID: user.ID,
+		}).Asserts(rbac.ResourceAPIKey.WithOwner(user.ID.String()), rbac.ActionDelete)
+	}))
+	s.Run("DeleteExpiredOAuth2ProviderAppCodes", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+}
+
+func (s *MethodTestSuite) TestOAuth2ProviderAppTokens() {
+	s.Run("InsertOAuth2ProviderAppToken", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{
+			UserID:    user.ID,
+			LoginType: database.LoginTypeOAuth2ProviderApp,
+		})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		check.Args(database.InsertOAuth2ProviderAppTokenParams{
+			AppSecretID: secret.ID,
+			APIKeyID:    key.ID,
+		}).Asserts(key, rbac.ActionCreate)
+	}))
+	s.Run("GetOAuth2ProviderAppTokenByPrefix", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{
+			UserID:    user.ID,
+			LoginType: database.LoginTypeOAuth2ProviderApp,
+		})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		token := dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
+			AppSecretID: secret.ID,
+			APIKeyID:    key.ID,
+		})
+		check.Args(token.HashPrefix).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(token)
+	}))
+	s.Run("DeleteOAuth2ProviderAppTokenByID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{
+			UserID:    user.ID,
+			LoginType: database.LoginTypeOAuth2ProviderApp,
+		})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		token := dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
+			AppSecretID: secret.ID,
+			APIKeyID:    key.ID,
+		})
+		check.Args(token.ID).Asserts(rbac.ResourceSystem, rbac.ActionDelete).Returns(token.ID)
+	}))
+	s.Run("DeleteOAuth2ProviderAppTokensByAppAndUserID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{
+			UserID:    user.ID,
+			LoginType: database.LoginTypeOAuth2ProviderApp,
+		})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		secret := dbgen.OAuth2ProviderAppSecret(s.T(), db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+		_ = dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
+			AppSecretID: secret.ID,
+			APIKeyID:    key.ID,
+		})
+		check.Args(database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams{
+			AppID:  app.ID,
+			UserID: user.ID,
+		}).Asserts(rbac.ResourceAPIKey.WithOwner(user.ID.String()), rbac.ActionDelete)
+	}))
+}
+
+func (s *MethodTestSuite) TestOAuth2DeviceCodes() {
+	s.Run("GetOAuth2DeviceCodeByPrefix", s.Subtest(func(db database.Store, check *expects) {
+		code := dbgen.OAuth2DeviceCode(s.T(), db, database.OAuth2DeviceCode{})
+		check.Args(code.SecretPrefix).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(code)
+	}))
+	s.Run("GetOAuth2DeviceCodeByUserCode", s.Subtest(func(db database.Store, check *expects) {
+		code := dbgen.OAuth2DeviceCode(s.T(), db, database.OAuth2DeviceCode{})
+		check.Args(code.UserCode).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(code)
+	}))
+	s.Run("InsertOAuth2DeviceCode", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertOAuth2DeviceCodeParams{
+			Scope: database.APIKeyScopeAll,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("UpdateOAuth2DeviceCodePolledByID", s.Subtest(func(db database.Store, check *expects) {
+		code := dbgen.OAuth2DeviceCode(s.T(), db, database.OAuth2DeviceCode{})
+		check.Args(database.UpdateOAuth2DeviceCodePolledByIDParams{
+			ID:              code.ID,
+			PollingInterval: 10,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateOAuth2DeviceCodeStatusByID", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		code := dbgen.OAuth2DeviceCode(s.T(), db, database.OAuth2DeviceCode{})
+		check.Args(database.UpdateOAuth2DeviceCodeStatusByIDParams{
+			ID:     code.ID,
+			Status: database.OAuth2DeviceCodeStatusApproved,
+			UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteOAuth2DeviceCodeByID", s.Subtest(func(db database.Store, check *expects) {
+		code := dbgen.OAuth2DeviceCode(s.T(), db, database.OAuth2DeviceCode{})
+		check.Args(code.ID).Asserts(rbac.ResourceSystem, rbac.ActionDelete).Returns(code.ID)
+	}))
+	s.Run("DeleteExpiredOAuth2DeviceCodes", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+}
+
 func (s *MethodTestSuite) TestOrganization() {
 	s.Run("GetGroupsByOrganizationID", s.Subtest(func(db database.Store, check *expects) {
 		o := dbgen.Organization(s.T(), db, database.Organization{})
@@ -621,6 +989,12 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 }
 
 func (s *MethodTestSuite) TestTemplate() {
+	s.Run("ArchiveUnusedTemplateVersions", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.ArchiveUnusedTemplateVersionsParams{
+			TemplateID: t1.ID,
+		}).Asserts(t1, rbac.ActionUpdate).Returns([]uuid.UUID{})
+	}))
 	s.Run("GetPreviousTemplateVersion", s.Subtest(func(db database.Store, check *expects) {
 		tvid := uuid.New()
 		now := time.Now()
@@ -797,9 +1171,137 @@ func (s *MethodTestSuite) TestTemplate() {
 	s.Run("UpdateTemplateMetaByID", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		check.Args(database.UpdateTemplateMetaByIDParams{
-			ID: t1.ID,
+			ID:                t1.ID,
+			MaxPortShareLevel: database.PortShareLevelAuthenticated,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("GetTemplateGitSourceByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		src := dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate).Returns(src)
+	}))
+	s.Run("UpsertTemplateGitSource", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpsertTemplateGitSourceParams{
+			TemplateID:    t1.ID,
+			RepositoryURL: "https://github.com/coder/coder.git",
+			Ref:           "main",
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteTemplateGitSourceByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_ = dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("DeleteTemplateVersionPresetsByTemplateVersionID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(tv.ID).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("GetTemplateVersionPresetByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		p := dbgen.TemplateVersionPreset(s.T(), db, database.TemplateVersionPreset{TemplateVersionID: tv.ID})
+		check.Args(p.ID).Asserts(t1, rbac.ActionRead).Returns(p)
+	}))
+	s.Run("GetTemplateVersionPresetParametersByTemplateVersionID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(tv.ID).Asserts(t1, rbac.ActionRead).Returns([]database.TemplateVersionPresetParameter{})
+	}))
+	s.Run("GetTemplateVersionPresetsByTemplateVersionID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		p := dbgen.TemplateVersionPreset(s.T(), db, database.TemplateVersionPreset{TemplateVersionID: tv.ID})
+		check.Args(tv.ID).Asserts(t1, rbac.ActionRead).Returns([]database.TemplateVersionPreset{p})
+	}))
+	s.Run("InsertTemplateVersionPreset", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.InsertTemplateVersionPresetParams{
+			ID:                uuid.New(),
+			TemplateVersionID: tv.ID,
+			Name:              "small",
 		}).Asserts(t1, rbac.ActionUpdate)
 	}))
+	s.Run("InsertTemplateVersionPresetParameters", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		p := dbgen.TemplateVersionPreset(s.T(), db, database.TemplateVersionPreset{TemplateVersionID: tv.ID})
+		check.Args(database.InsertTemplateVersionPresetParametersParams{
+			PresetID: p.ID,
+			Name:     []string{"cpu"},
+			Value:    []string{"2"},
+		}).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("GetTemplateVersionRolloutByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args(r.ID).Asserts(t1, rbac.ActionUpdate).Returns(r)
+	}))
+	s.Run("GetTemplateVersionRolloutsByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate).Returns([]database.TemplateVersionRollout{r})
+	}))
+	s.Run("GetTemplateVersionRolloutCandidates", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.GetTemplateVersionRolloutCandidatesParams{
+			TemplateID:        t1.ID,
+			TemplateVersionID: uuid.New(),
+		}).Asserts(t1, rbac.ActionUpdate).Returns([]uuid.UUID{})
+	}))
+	s.Run("GetTemplateVersionRolloutWorkspacesByRolloutID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args(r.ID).Asserts(t1, rbac.ActionUpdate).Returns([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow{})
+	}))
+	s.Run("InsertTemplateVersionRollout", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.InsertTemplateVersionRolloutParams{
+			ID:         uuid.New(),
+			TemplateID: t1.ID,
+			Percentage: 100,
+			BatchSize:  1,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("InsertTemplateVersionRolloutWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args(database.InsertTemplateVersionRolloutWorkspaceParams{
+			RolloutID:   r.ID,
+			WorkspaceID: uuid.New(),
+		}).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("UpdateTemplateVersionRolloutStatusByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args(database.UpdateTemplateVersionRolloutStatusByIDParams{
+			ID:     r.ID,
+			Status: database.TemplateVersionRolloutStatusPaused,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateTemplateVersionRolloutWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args(database.UpdateTemplateVersionRolloutWorkspaceParams{
+			RolloutID:   r.ID,
+			WorkspaceID: uuid.New(),
+			Status:      database.TemplateVersionRolloutWorkspaceStatusSucceeded,
+		}).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateTemplateVersionByID", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
@@ -836,6 +1338,16 @@ func (s *MethodTestSuite) TestTemplate() {
 			GitAuthProviders: []string{},
 		}).Asserts(t1, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("UpdateTemplateVersionStateByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.UpdateTemplateVersionStateByIDParams{
+			ID:    tv.ID,
+			State: database.TemplateVersionStateDeprecated,
+		}).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
 }
 
 func (s *MethodTestSuite) TestUser() {
@@ -843,6 +1355,11 @@ func (s *MethodTestSuite) TestUser() {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(rbac.ResourceAPIKey.WithOwner(u.ID.String()), rbac.ActionDelete).Returns()
 	}))
+	s.Run("DeleteSessionAPIKeysByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		key, _ := dbgen.APIKey(s.T(), db, database.APIKey{UserID: u.ID, LoginType: database.LoginTypePassword})
+		check.Args(database.DeleteSessionAPIKeysByUserIDParams{UserID: u.ID}).Asserts(rbac.ResourceAPIKey.WithOwner(u.ID.String()), rbac.ActionDelete).Returns([]database.APIKey{key})
+	}))
 	s.Run("GetQuotaAllowanceForUser", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns(int64(0))
@@ -894,6 +1411,12 @@ func (s *MethodTestSuite) TestUser() {
 			LoginType: database.LoginTypePassword,
 		}).Asserts(rbac.ResourceRoleAssignment, rbac.ActionCreate, rbac.ResourceUser, rbac.ActionCreate)
 	}))
+	s.Run("InsertServiceAccount", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{LoginType: database.LoginTypeNone})
+		check.Args(database.InsertServiceAccountParams{
+			UserID: u.ID,
+		}).Asserts(rbac.ResourceUser, rbac.ActionCreate)
+	}))
 	s.Run("InsertUserLink", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(database.InsertUserLinkParams{
@@ -943,6 +1466,59 @@ func (s *MethodTestSuite) TestUser() {
 			UpdatedAt: u.UpdatedAt,
 		}).Asserts(u, rbac.ActionUpdate).Returns(u)
 	}))
+	s.Run("DeleteUserTOTP", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{RBACRoles: []string{rbac.RoleUserAdmin()}})
+		_ = dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(u.ID).Asserts(u, rbac.ActionUpdate, rbac.ResourceRoleAssignment, rbac.ActionCreate).Returns()
+	}))
+	s.Run("GetUserTOTPByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		totp := dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(u.ID).Asserts(totp, rbac.ActionRead).Returns(totp)
+	}))
+	s.Run("UpdateUserTOTP", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		totp := dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(database.UpdateUserTOTPParams{
+			UserID:              u.ID,
+			Enabled:             true,
+			HashedRecoveryCodes: []string{},
+			UpdatedAt:           totp.UpdatedAt,
+		}).Asserts(totp, rbac.ActionUpdate)
+	}))
+	s.Run("UpsertUserTOTP", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.UpsertUserTOTPParams{
+			UserID:    u.ID,
+			Secret:    "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+			CreatedAt: database.Now(),
+		}).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionUpdate)
+	}))
+	s.Run("DeleteUserLoginFailures", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_ = dbgen.UserLoginFailure(s.T(), db, database.UserLoginFailure{UserID: u.ID})
+		check.Args(u.ID).Asserts(u, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("GetUserLoginFailuresByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		failure := dbgen.UserLoginFailure(s.T(), db, database.UserLoginFailure{UserID: u.ID})
+		check.Args(u.ID).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(failure)
+	}))
+	s.Run("InsertUserLoginFailure", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertUserLoginFailureParams{
+			UserID:       u.ID,
+			LastFailedAt: database.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("UpdateUserLoginLockout", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_ = dbgen.UserLoginFailure(s.T(), db, database.UserLoginFailure{UserID: u.ID})
+		check.Args(database.UpdateUserLoginLockoutParams{
+			UserID:      u.ID,
+			LockedUntil: sql.NullTime{Time: database.Now(), Valid: true},
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
 	s.Run("DeleteGitSSHKey", s.Subtest(func(db database.Store, check *expects) {
 		key := dbgen.GitSSHKey(s.T(), db, database.GitSSHKey{})
 		check.Args(key.UserID).Asserts(key, rbac.ActionDelete).Returns()
@@ -1085,6 +1661,16 @@ func (s *MethodTestSuite) TestWorkspace() {
 			Subsystem: database.WorkspaceAgentSubsystemNone,
 		}).Asserts(ws, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("UpdateWorkspaceAgentPeerConnectionsByID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.UpdateWorkspaceAgentPeerConnectionsByIDParams{
+			ID:              agt.ID,
+			PeerConnections: json.RawMessage("[]"),
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("GetWorkspaceAgentStartupLogsAfter", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
@@ -1288,6 +1874,38 @@ func (s *MethodTestSuite) TestWorkspace() {
 		app := dbgen.WorkspaceApp(s.T(), db, database.WorkspaceApp{AgentID: agt.ID})
 		check.Args(app.ID).Asserts(ws, rbac.ActionRead).Returns(ws)
 	}))
+	s.Run("GetWorkspacePortShare", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		share := dbgen.WorkspacePortShare(s.T(), db, database.WorkspacePortShare{WorkspaceID: ws.ID})
+		check.Args(database.GetWorkspacePortShareParams{
+			WorkspaceID: ws.ID,
+			AgentName:   share.AgentName,
+			Port:        share.Port,
+		}).Asserts(ws, rbac.ActionRead).Returns(share)
+	}))
+	s.Run("GetWorkspacePortSharesByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		share := dbgen.WorkspacePortShare(s.T(), db, database.WorkspacePortShare{WorkspaceID: ws.ID})
+		check.Args(ws.ID).Asserts(ws, rbac.ActionRead).Returns([]database.WorkspacePortShare{share})
+	}))
+	s.Run("UpsertWorkspacePortShare", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpsertWorkspacePortShareParams{
+			WorkspaceID: ws.ID,
+			AgentName:   "main",
+			Port:        8080,
+			ShareLevel:  database.PortShareLevelPublic,
+		}).Asserts(ws, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteWorkspacePortShare", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		share := dbgen.WorkspacePortShare(s.T(), db, database.WorkspacePortShare{WorkspaceID: ws.ID})
+		check.Args(database.DeleteWorkspacePortShareParams{
+			WorkspaceID: ws.ID,
+			AgentName:   share.AgentName,
+			Port:        share.Port,
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
 }
 
 func (s *MethodTestSuite) TestExtraMethods() {
@@ -1365,6 +1983,24 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			DatabaseLatency: 100,
 		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
 	}))
+	s.Run("GetTemplateGitSources", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		src := dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.TemplateGitSource{src})
+	}))
+	s.Run("UpdateTemplateGitSourceSyncStatus", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_ = dbgen.TemplateGitSource(s.T(), db, database.TemplateGitSource{TemplateID: t1.ID})
+		check.Args(database.UpdateTemplateGitSourceSyncStatusParams{
+			TemplateID:    t1.ID,
+			LastCommitSHA: "0123456789abcdef",
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("GetRunningTemplateVersionRollouts", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r := dbgen.TemplateVersionRollout(s.T(), db, database.TemplateVersionRollout{TemplateID: t1.ID})
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.TemplateVersionRollout{r})
+	}))
 	s.Run("DeleteReplicasUpdatedBefore", s.Subtest(func(db database.Store, check *expects) {
 		_, err := db.InsertReplica(context.Background(), database.InsertReplicaParams{ID: uuid.New(), UpdatedAt: time.Now()})
 		require.NoError(s.T(), err)
@@ -1375,6 +2011,142 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		require.NoError(s.T(), err)
 		check.Args(time.Now().Add(time.Hour*-1)).Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
+	s.Run("InsertWorkspaceAppSecurityKey", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWorkspaceAppSecurityKeyParams{
+			ID: uuid.New(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("GetWorkspaceAppSecurityKeys", s.Subtest(func(db database.Store, check *expects) {
+		key, err := db.InsertWorkspaceAppSecurityKey(context.Background(), database.InsertWorkspaceAppSecurityKeyParams{ID: uuid.New(), StartsAt: time.Now()})
+		require.NoError(s.T(), err)
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.WorkspaceAppSecurityKey{key})
+	}))
+	s.Run("ExpireWorkspaceAppSecurityKeys", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.ExpireWorkspaceAppSecurityKeysParams{
+			ExpiresAt: time.Now().Add(time.Hour),
+			StartsAt:  time.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteExpiredWorkspaceAppSecurityKeys", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("UpsertWorkspaceAppUsageRollup", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpsertWorkspaceAppUsageRollupParams{
+			Bucket:      time.Now().Truncate(time.Hour),
+			WorkspaceID: ws.ID,
+			UserID:      ws.OwnerID,
+			AppSlug:     "code-server",
+			Requests:    1,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("GetWorkspaceAppUsageInsights", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetWorkspaceAppUsageInsightsParams{
+			StartTime: time.Now().Add(-time.Hour),
+			EndTime:   time.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.GetWorkspaceAppUsageInsightsRow{})
+	}))
+	s.Run("GetTemplateActiveVersionApps", s.Subtest(func(db database.Store, check *expects) {
+		check.Args([]uuid.UUID{}).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.GetTemplateActiveVersionAppsRow{})
+	}))
+	s.Run("DeleteOldWorkspaceAppUsageRollups", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("UpsertTailnetCoordinator", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("GetTailnetCoordinators", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.TailnetCoordinator{})
+	}))
+	s.Run("DeleteTailnetCoordinator", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("DeleteExpiredTailnetCoordinators", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("UpsertTailnetPeer", s.Subtest(func(db database.Store, check *expects) {
+		coordinator, err := db.UpsertTailnetCoordinator(context.Background(), uuid.New())
+		s.NoError(err)
+		check.Args(database.UpsertTailnetPeerParams{
+			ID:            uuid.New(),
+			CoordinatorID: coordinator.ID,
+			Node:          []byte{},
+			Status:        database.TailnetStatusOk,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("UpdateTailnetPeerStatusByCoordinator", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateTailnetPeerStatusByCoordinatorParams{
+			CoordinatorID: uuid.New(),
+			Status:        database.TailnetStatusLost,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteTailnetPeer", s.Subtest(func(db database.Store, check *expects) {
+		coordinator, err := db.UpsertTailnetCoordinator(context.Background(), uuid.New())
+		s.NoError(err)
+		peer, err := db.UpsertTailnetPeer(context.Background(), database.UpsertTailnetPeerParams{
+			ID:            uuid.New(),
+			CoordinatorID: coordinator.ID,
+			Node:          []byte{},
+			Status:        database.TailnetStatusOk,
+		})
+		s.NoError(err)
+		check.Args(database.DeleteTailnetPeerParams{
+			ID:            peer.ID,
+			CoordinatorID: coordinator.ID,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionDelete).Returns(database.DeleteTailnetPeerRow{
+			ID:            peer.ID,
+			CoordinatorID: coordinator.ID,
+		})
+	}))
+	s.Run("CleanTailnetLostPeers", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("CleanTailnetLostTunnels", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("GetTailnetPeers", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.TailnetPeer{})
+	}))
+	s.Run("UpsertTailnetTunnel", s.Subtest(func(db database.Store, check *expects) {
+		coordinator, err := db.UpsertTailnetCoordinator(context.Background(), uuid.New())
+		s.NoError(err)
+		check.Args(database.UpsertTailnetTunnelParams{
+			CoordinatorID: coordinator.ID,
+			SrcID:         uuid.New(),
+			DstID:         uuid.New(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
+	s.Run("DeleteTailnetTunnel", s.Subtest(func(db database.Store, check *expects) {
+		coordinator, err := db.UpsertTailnetCoordinator(context.Background(), uuid.New())
+		s.NoError(err)
+		tunnel, err := db.UpsertTailnetTunnel(context.Background(), database.UpsertTailnetTunnelParams{
+			CoordinatorID: coordinator.ID,
+			SrcID:         uuid.New(),
+			DstID:         uuid.New(),
+		})
+		s.NoError(err)
+		check.Args(database.DeleteTailnetTunnelParams{
+			CoordinatorID: coordinator.ID,
+			SrcID:         tunnel.SrcID,
+			DstID:         tunnel.DstID,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionDelete).Returns(database.DeleteTailnetTunnelRow{
+			CoordinatorID: coordinator.ID,
+			SrcID:         tunnel.SrcID,
+			DstID:         tunnel.DstID,
+		})
+	}))
+	s.Run("DeleteAllTailnetTunnels", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.DeleteAllTailnetTunnelsParams{
+			CoordinatorID: uuid.New(),
+			SrcID:         uuid.New(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("GetTailnetTunnelPeerIDs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.GetTailnetTunnelPeerIDsRow{})
+	}))
+	s.Run("GetTailnetTunnelPeerBindings", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.GetTailnetTunnelPeerBindingsRow{})
+	}))
 	s.Run("GetUserCount", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(int64(0))
 	}))
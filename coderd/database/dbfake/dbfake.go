@@ -1,6 +1,7 @@
 package dbfake
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -32,6 +33,11 @@ var errDuplicateKey = &pq.Error{
 	Message: "duplicate key value violates unique constraint",
 }
 
+var errForeignKeyConstraint = &pq.Error{
+	Code:    "23503",
+	Message: "update or delete on table violates foreign key constraint",
+}
+
 // New returns an in-memory fake of the database.
 func New() database.Store {
 	q := &fakeQuerier{
@@ -110,33 +116,55 @@ type data struct {
 	userLinks           []database.UserLink
 
 	// New tables
-	workspaceAgentStats       []database.WorkspaceAgentStat
-	auditLogs                 []database.AuditLog
-	files                     []database.File
-	gitAuthLinks              []database.GitAuthLink
-	gitSSHKey                 []database.GitSSHKey
-	groupMembers              []database.GroupMember
-	groups                    []database.Group
-	licenses                  []database.License
-	parameterSchemas          []database.ParameterSchema
-	provisionerDaemons        []database.ProvisionerDaemon
-	provisionerJobLogs        []database.ProvisionerJobLog
-	provisionerJobs           []database.ProvisionerJob
-	replicas                  []database.Replica
-	templateVersions          []database.TemplateVersion
-	templateVersionParameters []database.TemplateVersionParameter
-	templateVersionVariables  []database.TemplateVersionVariable
-	templates                 []database.Template
-	workspaceAgents           []database.WorkspaceAgent
-	workspaceAgentMetadata    []database.WorkspaceAgentMetadatum
-	workspaceAgentLogs        []database.WorkspaceAgentStartupLog
-	workspaceApps             []database.WorkspaceApp
-	workspaceBuilds           []database.WorkspaceBuild
-	workspaceBuildParameters  []database.WorkspaceBuildParameter
-	workspaceResourceMetadata []database.WorkspaceResourceMetadatum
-	workspaceResources        []database.WorkspaceResource
-	workspaces                []database.Workspace
-	workspaceProxies          []database.WorkspaceProxy
+	workspaceAgentStats              []database.WorkspaceAgentStat
+	apiKeyImpersonations             []database.APIKeyImpersonation
+	auditLogs                        []database.AuditLog
+	derpRegions                      []database.DERPRegion
+	derpRegionPolicies               []database.DERPRegionPolicy
+	files                            []database.File
+	gitAuthLinks                     []database.GitAuthLink
+	gitSSHKey                        []database.GitSSHKey
+	groupMembers                     []database.GroupMember
+	groups                           []database.Group
+	licenses                         []database.License
+	oauth2ProviderApps               []database.OAuth2ProviderApp
+	oauth2ProviderAppSecrets         []database.OAuth2ProviderAppSecret
+	oauth2ProviderAppCodes           []database.OAuth2ProviderAppCode
+	oauth2ProviderAppTokens          []database.OAuth2ProviderAppToken
+	oauth2DeviceCodes                []database.OAuth2DeviceCode
+	parameterSchemas                 []database.ParameterSchema
+	provisionerDaemons               []database.ProvisionerDaemon
+	provisionerJobLogs               []database.ProvisionerJobLog
+	provisionerJobs                  []database.ProvisionerJob
+	replicas                         []database.Replica
+	serviceAccounts                  []database.ServiceAccount
+	tailnetCoordinators              []database.TailnetCoordinator
+	tailnetPeers                     []database.TailnetPeer
+	tailnetTunnels                   []database.TailnetTunnel
+	templateGitSources               []database.TemplateGitSource
+	templateVersions                 []database.TemplateVersion
+	templateVersionParameters        []database.TemplateVersionParameter
+	templateVersionPresets           []database.TemplateVersionPreset
+	templateVersionPresetParameters  []database.TemplateVersionPresetParameter
+	templateVersionRollouts          []database.TemplateVersionRollout
+	templateVersionRolloutWorkspaces []database.TemplateVersionRolloutWorkspace
+	templateVersionVariables         []database.TemplateVersionVariable
+	templates                        []database.Template
+	userLoginFailures                []database.UserLoginFailure
+	userTOTPs                        []database.UserTOTP
+	workspaceAgents                  []database.WorkspaceAgent
+	workspaceAgentMetadata           []database.WorkspaceAgentMetadatum
+	workspaceAgentLogs               []database.WorkspaceAgentStartupLog
+	workspaceApps                    []database.WorkspaceApp
+	workspaceAppSecurityKeys         []database.WorkspaceAppSecurityKey
+	workspaceAppUsageRollups         []database.WorkspaceAppUsageRollup
+	workspaceBuilds                  []database.WorkspaceBuild
+	workspaceBuildParameters         []database.WorkspaceBuildParameter
+	workspacePortShares              []database.WorkspacePortShare
+	workspaceResourceMetadata        []database.WorkspaceResourceMetadatum
+	workspaceResources               []database.WorkspaceResource
+	workspaces                       []database.Workspace
+	workspaceProxies                 []database.WorkspaceProxy
 
 	// Locks is a map of lock names. Any keys within the map are currently
 	// locked.
@@ -146,7 +174,6 @@ type data struct {
 	lastUpdateCheck         []byte
 	serviceBanner           []byte
 	logoURL                 string
-	appSecurityKey          string
 	lastLicenseID           int32
 	defaultProxyDisplayName string
 	defaultProxyIconURL     string
@@ -263,6 +290,45 @@ func (q *fakeQuerier) getUserByIDNoLock(id uuid.UUID) (database.User, error) {
 	return database.User{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) isServiceAccountNoLock(userID uuid.UUID) bool {
+	for _, account := range q.serviceAccounts {
+		if account.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// tailnetCoordinatorExistsNoLock is used to enforce the foreign keys of the
+// tailnet peers and tunnels.
+func (q *fakeQuerier) tailnetCoordinatorExistsNoLock(id uuid.UUID) bool {
+	for _, coordinator := range q.tailnetCoordinators {
+		if coordinator.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteTailnetCoordinatorNoLock deletes the peers and tunnels of a deleted
+// coordinator, like the cascading foreign keys do.
+func (q *fakeQuerier) deleteTailnetCoordinatorNoLock(id uuid.UUID) {
+	peers := make([]database.TailnetPeer, 0, len(q.tailnetPeers))
+	for _, peer := range q.tailnetPeers {
+		if peer.CoordinatorID != id {
+			peers = append(peers, peer)
+		}
+	}
+	q.tailnetPeers = peers
+	tunnels := make([]database.TailnetTunnel, 0, len(q.tailnetTunnels))
+	for _, tunnel := range q.tailnetTunnels {
+		if tunnel.CoordinatorID != id {
+			tunnels = append(tunnels, tunnel)
+		}
+	}
+	q.tailnetTunnels = tunnels
+}
+
 func (q *fakeQuerier) GetAuthorizedUserCount(ctx context.Context, params database.GetFilteredUserCountParams, prepared rbac.PreparedAuthorized) (int64, error) {
 	if err := validateDatabaseType(params); err != nil {
 		return 0, err
@@ -956,6 +1022,37 @@ func (q *fakeQuerier) getGroupByIDNoLock(_ context.Context, id uuid.UUID) (datab
 	return database.Group{}, sql.ErrNoRows
 }
 
+// isSessionAPIKey matches the keys returned by GetSessionAPIKeysByUserID.
+func isSessionAPIKey(key database.APIKey) bool {
+	return key.LoginType != database.LoginTypeToken &&
+		key.LoginType != database.LoginTypeOAuth2ProviderApp &&
+		key.TokenName == ""
+}
+
+// deleteOAuth2ProviderAppTokensNoLock deletes the matching tokens along with
+// their API keys, like the trigger on oauth2_provider_app_tokens does.
+func (q *fakeQuerier) deleteOAuth2ProviderAppTokensNoLock(match func(token database.OAuth2ProviderAppToken) bool) {
+	apiKeyIDs := map[string]struct{}{}
+	tokens := make([]database.OAuth2ProviderAppToken, 0, len(q.oauth2ProviderAppTokens))
+	for _, token := range q.oauth2ProviderAppTokens {
+		if match(token) {
+			apiKeyIDs[token.APIKeyID] = struct{}{}
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	q.oauth2ProviderAppTokens = tokens
+
+	apiKeys := make([]database.APIKey, 0, len(q.apiKeys))
+	for _, key := range q.apiKeys {
+		if _, ok := apiKeyIDs[key.ID]; ok {
+			continue
+		}
+		apiKeys = append(apiKeys, key)
+	}
+	q.apiKeys = apiKeys
+}
+
 // isNull is only used in dbfake, so reflect is ok. Use this to make the logic
 // look more similar to the postgres.
 func isNull(v interface{}) bool {
@@ -1025,6 +1122,111 @@ func (q *fakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	return database.ProvisionerJob{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) ArchiveUnusedTemplateVersions(ctx context.Context, arg database.ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	template, err := q.getTemplateByIDNoLock(ctx, arg.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[uuid.UUID]bool{
+		template.ActiveVersionID: true,
+	}
+	for _, workspace := range q.workspaces {
+		if workspace.TemplateID != arg.TemplateID || workspace.Deleted {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if err != nil {
+			continue
+		}
+		used[build.TemplateVersionID] = true
+	}
+
+	archived := make([]uuid.UUID, 0)
+	archivedJobs := map[uuid.UUID]bool{}
+	for i, version := range q.templateVersions {
+		if version.TemplateID.UUID != arg.TemplateID || version.State == database.TemplateVersionStateArchived || used[version.ID] {
+			continue
+		}
+		if len(arg.TemplateVersionIds) > 0 && !slices.Contains(arg.TemplateVersionIds, version.ID) {
+			continue
+		}
+		version.State = database.TemplateVersionStateArchived
+		version.UpdatedAt = arg.UpdatedAt
+		q.templateVersions[i] = version
+		archived = append(archived, version.ID)
+		archivedJobs[version.JobID] = true
+	}
+
+	// Delete the files of archived versions that no other provisioner job
+	// uses, such as workspace builds or the imports of other versions.
+	archivedVersionJobs := map[uuid.UUID]bool{}
+	for _, version := range q.templateVersions {
+		if version.State == database.TemplateVersionStateArchived {
+			archivedVersionJobs[version.JobID] = true
+		}
+	}
+	unusedFiles := map[uuid.UUID]bool{}
+	for _, job := range q.provisionerJobs {
+		if archivedJobs[job.ID] {
+			unusedFiles[job.FileID] = true
+		}
+	}
+	for _, job := range q.provisionerJobs {
+		if !archivedVersionJobs[job.ID] {
+			delete(unusedFiles, job.FileID)
+		}
+	}
+	files := make([]database.File, 0, len(q.files))
+	for _, file := range q.files {
+		if !unusedFiles[file.ID] {
+			files = append(files, file)
+		}
+	}
+	q.files = files
+
+	return archived, nil
+}
+
+func (q *fakeQuerier) CleanTailnetLostPeers(_ context.Context, updatedAt time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	peers := make([]database.TailnetPeer, 0, len(q.tailnetPeers))
+	for _, peer := range q.tailnetPeers {
+		if peer.Status == database.TailnetStatusLost && peer.UpdatedAt.Before(updatedAt) {
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	q.tailnetPeers = peers
+	return nil
+}
+
+func (q *fakeQuerier) CleanTailnetLostTunnels(_ context.Context, updatedAt time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	tunnels := make([]database.TailnetTunnel, 0, len(q.tailnetTunnels))
+	for _, tunnel := range q.tailnetTunnels {
+		if tunnel.UpdatedAt.Before(updatedAt) && !slices.ContainsFunc(q.tailnetPeers, func(peer database.TailnetPeer) bool {
+			return peer.ID == tunnel.SrcID && peer.CoordinatorID == tunnel.CoordinatorID
+		}) {
+			continue
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+	q.tailnetTunnels = tunnels
+	return nil
+}
+
 func (q *fakeQuerier) DeleteAPIKeyByID(_ context.Context, id string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1053,6 +1255,25 @@ func (q *fakeQuerier) DeleteAPIKeysByUserID(_ context.Context, userID uuid.UUID)
 	return nil
 }
 
+func (q *fakeQuerier) DeleteAllTailnetTunnels(_ context.Context, arg database.DeleteAllTailnetTunnelsParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	tunnels := make([]database.TailnetTunnel, 0, len(q.tailnetTunnels))
+	for _, tunnel := range q.tailnetTunnels {
+		if tunnel.CoordinatorID == arg.CoordinatorID && tunnel.SrcID == arg.SrcID {
+			continue
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+	q.tailnetTunnels = tunnels
+	return nil
+}
+
 func (q *fakeQuerier) DeleteApplicationConnectAPIKeysByUserID(_ context.Context, userID uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1066,6 +1287,95 @@ func (q *fakeQuerier) DeleteApplicationConnectAPIKeysByUserID(_ context.Context,
 	return nil
 }
 
+func (q *fakeQuerier) DeleteDERPRegionByID(_ context.Context, regionID int32) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, region := range q.derpRegions {
+		if region.RegionID == regionID {
+			q.derpRegions = append(q.derpRegions[:i], q.derpRegions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteDERPRegionPolicyByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, policy := range q.derpRegionPolicies {
+		if policy.ID == id {
+			q.derpRegionPolicies = append(q.derpRegionPolicies[:i], q.derpRegionPolicies[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteExpiredOAuth2DeviceCodes(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := database.Now()
+	codes := make([]database.OAuth2DeviceCode, 0, len(q.oauth2DeviceCodes))
+	for _, code := range q.oauth2DeviceCodes {
+		if code.ExpiresAt.Before(now) {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	q.oauth2DeviceCodes = codes
+	return nil
+}
+
+func (q *fakeQuerier) DeleteExpiredOAuth2ProviderAppCodes(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := database.Now()
+	codes := make([]database.OAuth2ProviderAppCode, 0, len(q.oauth2ProviderAppCodes))
+	for _, code := range q.oauth2ProviderAppCodes {
+		if code.ExpiresAt.Before(now) {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	q.oauth2ProviderAppCodes = codes
+	return nil
+}
+
+func (q *fakeQuerier) DeleteExpiredTailnetCoordinators(_ context.Context, heartbeatAt time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	coordinators := make([]database.TailnetCoordinator, 0, len(q.tailnetCoordinators))
+	for _, coordinator := range q.tailnetCoordinators {
+		if coordinator.HeartbeatAt.Before(heartbeatAt) {
+			q.deleteTailnetCoordinatorNoLock(coordinator.ID)
+			continue
+		}
+		coordinators = append(coordinators, coordinator)
+	}
+	q.tailnetCoordinators = coordinators
+	return nil
+}
+
+func (q *fakeQuerier) DeleteExpiredWorkspaceAppSecurityKeys(_ context.Context, now time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	keys := make([]database.WorkspaceAppSecurityKey, 0, len(q.workspaceAppSecurityKeys))
+	for _, key := range q.workspaceAppSecurityKeys {
+		if key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	q.workspaceAppSecurityKeys = keys
+	return nil
+}
+
 func (q *fakeQuerier) DeleteGitSSHKey(_ context.Context, userID uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1088,6 +1398,14 @@ func (q *fakeQuerier) DeleteGroupByID(_ context.Context, id uuid.UUID) error {
 	for i, group := range q.groups {
 		if group.ID == id {
 			q.groups = append(q.groups[:i], q.groups[i+1:]...)
+			policies := make([]database.DERPRegionPolicy, 0, len(q.derpRegionPolicies))
+			for _, policy := range q.derpRegionPolicies {
+				if policy.GroupID.Valid && policy.GroupID.UUID == id {
+					continue
+				}
+				policies = append(policies, policy)
+			}
+			q.derpRegionPolicies = policies
 			return nil
 		}
 	}
@@ -1151,92 +1469,454 @@ func (q *fakeQuerier) DeleteLicense(_ context.Context, id int32) (int32, error)
 	return 0, sql.ErrNoRows
 }
 
-func (*fakeQuerier) DeleteOldWorkspaceAgentStartupLogs(_ context.Context) error {
-	// noop
-	return nil
-}
+func (q *fakeQuerier) DeleteOAuth2DeviceCodeByID(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-func (*fakeQuerier) DeleteOldWorkspaceAgentStats(_ context.Context) error {
-	// no-op
-	return nil
+	for i, code := range q.oauth2DeviceCodes {
+		if code.ID == id {
+			q.oauth2DeviceCodes = append(q.oauth2DeviceCodes[:i], q.oauth2DeviceCodes[i+1:]...)
+			return id, nil
+		}
+	}
+	return uuid.Nil, sql.ErrNoRows
 }
 
-func (q *fakeQuerier) DeleteReplicasUpdatedBefore(_ context.Context, before time.Time) error {
+func (q *fakeQuerier) DeleteOAuth2ProviderAppByID(_ context.Context, id uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	for i, replica := range q.replicas {
-		if replica.UpdatedAt.Before(before) {
-			q.replicas = append(q.replicas[:i], q.replicas[i+1:]...)
+	index := slices.IndexFunc(q.oauth2ProviderApps, func(app database.OAuth2ProviderApp) bool {
+		return app.ID == id
+	})
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+	q.oauth2ProviderApps = slices.Delete(q.oauth2ProviderApps, index, index+1)
+
+	secretIDs := map[uuid.UUID]struct{}{}
+	secrets := make([]database.OAuth2ProviderAppSecret, 0, len(q.oauth2ProviderAppSecrets))
+	for _, secret := range q.oauth2ProviderAppSecrets {
+		if secret.AppID == id {
+			secretIDs[secret.ID] = struct{}{}
+			continue
 		}
+		secrets = append(secrets, secret)
 	}
+	q.oauth2ProviderAppSecrets = secrets
+	q.deleteOAuth2ProviderAppTokensNoLock(func(token database.OAuth2ProviderAppToken) bool {
+		_, ok := secretIDs[token.AppSecretID]
+		return ok
+	})
 
+	codes := make([]database.OAuth2ProviderAppCode, 0, len(q.oauth2ProviderAppCodes))
+	for _, code := range q.oauth2ProviderAppCodes {
+		if code.AppID == id {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	q.oauth2ProviderAppCodes = codes
 	return nil
 }
 
-func (q *fakeQuerier) GetAPIKeyByID(_ context.Context, id string) (database.APIKey, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+func (q *fakeQuerier) DeleteOAuth2ProviderAppCodeByID(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-	for _, apiKey := range q.apiKeys {
-		if apiKey.ID == id {
-			return apiKey, nil
+	for i, code := range q.oauth2ProviderAppCodes {
+		if code.ID == id {
+			q.oauth2ProviderAppCodes = append(q.oauth2ProviderAppCodes[:i], q.oauth2ProviderAppCodes[i+1:]...)
+			return id, nil
 		}
 	}
-	return database.APIKey{}, sql.ErrNoRows
+	return uuid.Nil, sql.ErrNoRows
 }
 
-func (q *fakeQuerier) GetAPIKeyByName(_ context.Context, params database.GetAPIKeyByNameParams) (database.APIKey, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
-
-	if params.TokenName == "" {
-		return database.APIKey{}, sql.ErrNoRows
+func (q *fakeQuerier) DeleteOAuth2ProviderAppCodesByAppAndUserID(_ context.Context, arg database.DeleteOAuth2ProviderAppCodesByAppAndUserIDParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
 	}
-	for _, apiKey := range q.apiKeys {
-		if params.UserID == apiKey.UserID && params.TokenName == apiKey.TokenName {
-			return apiKey, nil
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	codes := make([]database.OAuth2ProviderAppCode, 0, len(q.oauth2ProviderAppCodes))
+	for _, code := range q.oauth2ProviderAppCodes {
+		if code.AppID == arg.AppID && code.UserID == arg.UserID {
+			continue
 		}
+		codes = append(codes, code)
 	}
-	return database.APIKey{}, sql.ErrNoRows
+	q.oauth2ProviderAppCodes = codes
+	return nil
 }
 
-func (q *fakeQuerier) GetAPIKeysByLoginType(_ context.Context, t database.LoginType) ([]database.APIKey, error) {
-	if err := validateDatabaseType(t); err != nil {
-		return nil, err
+func (q *fakeQuerier) DeleteOAuth2ProviderAppSecretByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	index := slices.IndexFunc(q.oauth2ProviderAppSecrets, func(secret database.OAuth2ProviderAppSecret) bool {
+		return secret.ID == id
+	})
+	if index < 0 {
+		return sql.ErrNoRows
 	}
+	q.oauth2ProviderAppSecrets = slices.Delete(q.oauth2ProviderAppSecrets, index, index+1)
+	q.deleteOAuth2ProviderAppTokensNoLock(func(token database.OAuth2ProviderAppToken) bool {
+		return token.AppSecretID == id
+	})
+	return nil
+}
 
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+func (q *fakeQuerier) DeleteOAuth2ProviderAppTokenByID(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-	apiKeys := make([]database.APIKey, 0)
-	for _, key := range q.apiKeys {
-		if key.LoginType == t {
-			apiKeys = append(apiKeys, key)
+	for i, token := range q.oauth2ProviderAppTokens {
+		if token.ID == id {
+			q.oauth2ProviderAppTokens = append(q.oauth2ProviderAppTokens[:i], q.oauth2ProviderAppTokens[i+1:]...)
+			return id, nil
 		}
 	}
-	return apiKeys, nil
+	return uuid.Nil, sql.ErrNoRows
 }
 
-func (q *fakeQuerier) GetAPIKeysByUserID(_ context.Context, params database.GetAPIKeysByUserIDParams) ([]database.APIKey, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+func (q *fakeQuerier) DeleteOAuth2ProviderAppTokensByAppAndUserID(_ context.Context, arg database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
 
-	apiKeys := make([]database.APIKey, 0)
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	secretIDs := map[uuid.UUID]struct{}{}
+	for _, secret := range q.oauth2ProviderAppSecrets {
+		if secret.AppID == arg.AppID {
+			secretIDs[secret.ID] = struct{}{}
+		}
+	}
+	userKeyIDs := map[string]struct{}{}
 	for _, key := range q.apiKeys {
-		if key.UserID == params.UserID && key.LoginType == params.LoginType {
-			apiKeys = append(apiKeys, key)
+		if key.UserID == arg.UserID {
+			userKeyIDs[key.ID] = struct{}{}
 		}
 	}
-	return apiKeys, nil
+	q.deleteOAuth2ProviderAppTokensNoLock(func(token database.OAuth2ProviderAppToken) bool {
+		_, appOK := secretIDs[token.AppSecretID]
+		_, userOK := userKeyIDs[token.APIKeyID]
+		return appOK && userOK
+	})
+	return nil
 }
 
-func (q *fakeQuerier) GetAPIKeysLastUsedAfter(_ context.Context, after time.Time) ([]database.APIKey, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+func (q *fakeQuerier) DeleteOldWorkspaceAppUsageRollups(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-	apiKeys := make([]database.APIKey, 0)
-	for _, key := range q.apiKeys {
+	before := database.Now().Add(-90 * 24 * time.Hour)
+	rollups := make([]database.WorkspaceAppUsageRollup, 0, len(q.workspaceAppUsageRollups))
+	for _, rollup := range q.workspaceAppUsageRollups {
+		if rollup.Bucket.Before(before) {
+			continue
+		}
+		rollups = append(rollups, rollup)
+	}
+	q.workspaceAppUsageRollups = rollups
+	return nil
+}
+
+func (*fakeQuerier) DeleteOldWorkspaceAgentStartupLogs(_ context.Context) error {
+	// noop
+	return nil
+}
+
+func (*fakeQuerier) DeleteOldWorkspaceAgentStats(_ context.Context) error {
+	// no-op
+	return nil
+}
+
+func (q *fakeQuerier) DeleteReplicasUpdatedBefore(_ context.Context, before time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, replica := range q.replicas {
+		if replica.UpdatedAt.Before(before) {
+			q.replicas = append(q.replicas[:i], q.replicas[i+1:]...)
+		}
+	}
+
+	return nil
+}
+
+func (q *fakeQuerier) DeleteSessionAPIKeysByUserID(_ context.Context, arg database.DeleteSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var deleted []database.APIKey
+	for i := len(q.apiKeys) - 1; i >= 0; i-- {
+		key := q.apiKeys[i]
+		if key.UserID == arg.UserID && isSessionAPIKey(key) && key.ID != arg.ExceptID {
+			deleted = append(deleted, key)
+			q.apiKeys = append(q.apiKeys[:i], q.apiKeys[i+1:]...)
+		}
+	}
+
+	return deleted, nil
+}
+
+func (q *fakeQuerier) DeleteTailnetCoordinator(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, coordinator := range q.tailnetCoordinators {
+		if coordinator.ID != id {
+			continue
+		}
+		q.tailnetCoordinators = append(q.tailnetCoordinators[:i], q.tailnetCoordinators[i+1:]...)
+		q.deleteTailnetCoordinatorNoLock(id)
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteTailnetPeer(_ context.Context, arg database.DeleteTailnetPeerParams) (database.DeleteTailnetPeerRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DeleteTailnetPeerRow{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, peer := range q.tailnetPeers {
+		if peer.ID != arg.ID || peer.CoordinatorID != arg.CoordinatorID {
+			continue
+		}
+		q.tailnetPeers = append(q.tailnetPeers[:i], q.tailnetPeers[i+1:]...)
+		return database.DeleteTailnetPeerRow{
+			ID:            peer.ID,
+			CoordinatorID: peer.CoordinatorID,
+		}, nil
+	}
+	return database.DeleteTailnetPeerRow{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteTailnetTunnel(_ context.Context, arg database.DeleteTailnetTunnelParams) (database.DeleteTailnetTunnelRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DeleteTailnetTunnelRow{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, tunnel := range q.tailnetTunnels {
+		if tunnel.CoordinatorID != arg.CoordinatorID || tunnel.SrcID != arg.SrcID || tunnel.DstID != arg.DstID {
+			continue
+		}
+		q.tailnetTunnels = append(q.tailnetTunnels[:i], q.tailnetTunnels[i+1:]...)
+		return database.DeleteTailnetTunnelRow{
+			CoordinatorID: tunnel.CoordinatorID,
+			SrcID:         tunnel.SrcID,
+			DstID:         tunnel.DstID,
+		}, nil
+	}
+	return database.DeleteTailnetTunnelRow{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteTemplateGitSourceByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID == templateID {
+			q.templateGitSources = append(q.templateGitSources[:i], q.templateGitSources[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteTemplateVersionPresetsByTemplateVersionID(_ context.Context, templateVersionID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deleted := map[uuid.UUID]struct{}{}
+	presets := make([]database.TemplateVersionPreset, 0, len(q.templateVersionPresets))
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == templateVersionID {
+			deleted[preset.ID] = struct{}{}
+			continue
+		}
+		presets = append(presets, preset)
+	}
+	q.templateVersionPresets = presets
+
+	parameters := make([]database.TemplateVersionPresetParameter, 0, len(q.templateVersionPresetParameters))
+	for _, parameter := range q.templateVersionPresetParameters {
+		if _, ok := deleted[parameter.PresetID]; ok {
+			continue
+		}
+		parameters = append(parameters, parameter)
+	}
+	q.templateVersionPresetParameters = parameters
+	return nil
+}
+
+func (q *fakeQuerier) DeleteUserLoginFailures(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, failure := range q.userLoginFailures {
+		if failure.UserID != userID {
+			continue
+		}
+		q.userLoginFailures = append(q.userLoginFailures[:i], q.userLoginFailures[i+1:]...)
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteUserTOTP(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, totp := range q.userTOTPs {
+		if totp.UserID != userID {
+			continue
+		}
+		q.userTOTPs = append(q.userTOTPs[:i], q.userTOTPs[i+1:]...)
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) DeleteWorkspacePortShare(_ context.Context, arg database.DeleteWorkspacePortShareParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, share := range q.workspacePortShares {
+		if share.WorkspaceID == arg.WorkspaceID && share.AgentName == arg.AgentName && share.Port == arg.Port {
+			q.workspacePortShares = append(q.workspacePortShares[:i], q.workspacePortShares[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) ExpireWorkspaceAppSecurityKeys(_ context.Context, arg database.ExpireWorkspaceAppSecurityKeysParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, key := range q.workspaceAppSecurityKeys {
+		if key.ExpiresAt.Valid || !key.StartsAt.Before(arg.StartsAt) {
+			continue
+		}
+		key.ExpiresAt = sql.NullTime{Time: arg.ExpiresAt, Valid: true}
+		q.workspaceAppSecurityKeys[i] = key
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetAPIKeyByID(_ context.Context, id string) (database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, apiKey := range q.apiKeys {
+		if apiKey.ID == id {
+			return apiKey, nil
+		}
+	}
+	return database.APIKey{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetAPIKeyByName(_ context.Context, params database.GetAPIKeyByNameParams) (database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if params.TokenName == "" {
+		return database.APIKey{}, sql.ErrNoRows
+	}
+	for _, apiKey := range q.apiKeys {
+		if params.UserID == apiKey.UserID && params.TokenName == apiKey.TokenName {
+			return apiKey, nil
+		}
+	}
+	return database.APIKey{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetAPIKeyImpersonationByAPIKeyID(_ context.Context, apiKeyID string) (database.GetAPIKeyImpersonationByAPIKeyIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, impersonation := range q.apiKeyImpersonations {
+		if impersonation.APIKeyID != apiKeyID {
+			continue
+		}
+		impersonator, err := q.getUserByIDNoLock(impersonation.ImpersonatorID)
+		if err != nil {
+			return database.GetAPIKeyImpersonationByAPIKeyIDRow{}, err
+		}
+		return database.GetAPIKeyImpersonationByAPIKeyIDRow{
+			APIKeyID:             impersonation.APIKeyID,
+			ImpersonatorID:       impersonation.ImpersonatorID,
+			CreatedAt:            impersonation.CreatedAt,
+			ImpersonatorUsername: impersonator.Username,
+		}, nil
+	}
+	return database.GetAPIKeyImpersonationByAPIKeyIDRow{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetAPIKeysByLoginType(_ context.Context, t database.LoginType) ([]database.APIKey, error) {
+	if err := validateDatabaseType(t); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.LoginType == t {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (q *fakeQuerier) GetAPIKeysByUserID(_ context.Context, params database.GetAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.UserID == params.UserID && key.LoginType == params.LoginType {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (q *fakeQuerier) GetAPIKeysLastUsedAfter(_ context.Context, after time.Time) ([]database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
 		if key.LastUsed.After(after) {
 			apiKeys = append(apiKeys, key)
 		}
@@ -1250,20 +1930,13 @@ func (q *fakeQuerier) GetActiveUserCount(_ context.Context) (int64, error) {
 
 	active := int64(0)
 	for _, u := range q.users {
-		if u.Status == database.UserStatusActive && !u.Deleted {
+		if u.Status == database.UserStatusActive && !u.Deleted && !q.isServiceAccountNoLock(u.ID) {
 			active++
 		}
 	}
 	return active, nil
 }
 
-func (q *fakeQuerier) GetAppSecurityKey(_ context.Context) (string, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
-
-	return q.appSecurityKey, nil
-}
-
 func (q *fakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -1391,12 +2064,20 @@ func (q *fakeQuerier) GetAuthorizationUserRoles(_ context.Context, userID uuid.U
 		return database.GetAuthorizationUserRolesRow{}, sql.ErrNoRows
 	}
 
+	var createdBy uuid.NullUUID
+	for _, account := range q.serviceAccounts {
+		if account.UserID == userID {
+			createdBy = uuid.NullUUID{UUID: account.CreatedBy, Valid: true}
+		}
+	}
+
 	return database.GetAuthorizationUserRolesRow{
-		ID:       userID,
-		Username: user.Username,
-		Status:   user.Status,
-		Roles:    roles,
-		Groups:   groups,
+		ID:                      userID,
+		Username:                user.Username,
+		Status:                  user.Status,
+		ServiceAccountCreatedBy: createdBy,
+		Roles:                   roles,
+		Groups:                  groups,
 	}, nil
 }
 
@@ -1407,6 +2088,93 @@ func (q *fakeQuerier) GetDERPMeshKey(_ context.Context) (string, error) {
 	return q.derpMeshKey, nil
 }
 
+func (q *fakeQuerier) GetDERPRegionByID(_ context.Context, regionID int32) (database.DERPRegion, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, region := range q.derpRegions {
+		if region.RegionID == regionID {
+			return region, nil
+		}
+	}
+	return database.DERPRegion{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetDERPRegionPolicies(_ context.Context) ([]database.DERPRegionPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	policies := slices.Clone(q.derpRegionPolicies)
+	slices.SortFunc(policies, func(a, b database.DERPRegionPolicy) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return policies, nil
+}
+
+func (q *fakeQuerier) GetDERPRegionPoliciesByUserID(_ context.Context, userID uuid.UUID) ([]database.DERPRegionPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	groupIDs := map[uuid.UUID]struct{}{}
+	for _, member := range q.groupMembers {
+		if member.UserID == userID {
+			groupIDs[member.GroupID] = struct{}{}
+		}
+	}
+	// The "Everyone" group shares its ID with the organization.
+	for _, member := range q.organizationMembers {
+		if member.UserID == userID {
+			groupIDs[member.OrganizationID] = struct{}{}
+		}
+	}
+
+	policies := make([]database.DERPRegionPolicy, 0)
+	for _, policy := range q.derpRegionPolicies {
+		if !policy.GroupID.Valid {
+			continue
+		}
+		if _, ok := groupIDs[policy.GroupID.UUID]; ok {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func (q *fakeQuerier) GetDERPRegionPolicyByID(_ context.Context, id uuid.UUID) (database.DERPRegionPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.derpRegionPolicies {
+		if policy.ID == id {
+			return policy, nil
+		}
+	}
+	return database.DERPRegionPolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetDERPRegionPolicyByTemplateID(_ context.Context, templateID uuid.NullUUID) (database.DERPRegionPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.derpRegionPolicies {
+		if templateID.Valid && policy.TemplateID == templateID {
+			return policy, nil
+		}
+	}
+	return database.DERPRegionPolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetDERPRegions(_ context.Context) ([]database.DERPRegion, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	regions := slices.Clone(q.derpRegions)
+	slices.SortFunc(regions, func(a, b database.DERPRegion) bool {
+		return a.RegionID < b.RegionID
+	})
+	return regions, nil
+}
+
 func (q *fakeQuerier) GetDefaultProxyConfig(_ context.Context) (database.GetDefaultProxyConfigRow, error) {
 	return database.GetDefaultProxyConfigRow{
 		DisplayName: q.defaultProxyDisplayName,
@@ -1829,6 +2597,173 @@ func (q *fakeQuerier) GetLogoURL(_ context.Context) (string, error) {
 	return q.logoURL, nil
 }
 
+func (q *fakeQuerier) GetOAuth2DeviceCodeByPrefix(_ context.Context, secretPrefix []byte) (database.OAuth2DeviceCode, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, code := range q.oauth2DeviceCodes {
+		if bytes.Equal(code.SecretPrefix, secretPrefix) {
+			return code, nil
+		}
+	}
+	return database.OAuth2DeviceCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2DeviceCodeByUserCode(_ context.Context, userCode string) (database.OAuth2DeviceCode, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, code := range q.oauth2DeviceCodes {
+		if code.UserCode == userCode {
+			return code, nil
+		}
+	}
+	return database.OAuth2DeviceCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppByID(_ context.Context, id uuid.UUID) (database.OAuth2ProviderApp, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.ID == id {
+			return app, nil
+		}
+	}
+	return database.OAuth2ProviderApp{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppCodeByID(_ context.Context, id uuid.UUID) (database.OAuth2ProviderAppCode, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, code := range q.oauth2ProviderAppCodes {
+		if code.ID == id {
+			return code, nil
+		}
+	}
+	return database.OAuth2ProviderAppCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppCodeByPrefix(_ context.Context, secretPrefix []byte) (database.OAuth2ProviderAppCode, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, code := range q.oauth2ProviderAppCodes {
+		if bytes.Equal(code.SecretPrefix, secretPrefix) {
+			return code, nil
+		}
+	}
+	return database.OAuth2ProviderAppCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppSecretByID(_ context.Context, id uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, secret := range q.oauth2ProviderAppSecrets {
+		if secret.ID == id {
+			return secret, nil
+		}
+	}
+	return database.OAuth2ProviderAppSecret{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppSecretByPrefix(_ context.Context, secretPrefix []byte) (database.OAuth2ProviderAppSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, secret := range q.oauth2ProviderAppSecrets {
+		if bytes.Equal(secret.SecretPrefix, secretPrefix) {
+			return secret, nil
+		}
+	}
+	return database.OAuth2ProviderAppSecret{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppSecretsByAppID(_ context.Context, appID uuid.UUID) ([]database.OAuth2ProviderAppSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	secrets := make([]database.OAuth2ProviderAppSecret, 0)
+	for _, secret := range q.oauth2ProviderAppSecrets {
+		if secret.AppID == appID {
+			secrets = append(secrets, secret)
+		}
+	}
+	slices.SortFunc(secrets, func(a, b database.OAuth2ProviderAppSecret) bool {
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID.String() < b.ID.String()
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return secrets, nil
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppTokenByPrefix(_ context.Context, hashPrefix []byte) (database.OAuth2ProviderAppToken, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, token := range q.oauth2ProviderAppTokens {
+		if !bytes.Equal(token.HashPrefix, hashPrefix) {
+			continue
+		}
+		// The token is deleted along with its API key by a foreign key.
+		if !slices.ContainsFunc(q.apiKeys, func(key database.APIKey) bool {
+			return key.ID == token.APIKeyID
+		}) {
+			break
+		}
+		return token, nil
+	}
+	return database.OAuth2ProviderAppToken{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderApps(_ context.Context) ([]database.OAuth2ProviderApp, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apps := slices.Clone(q.oauth2ProviderApps)
+	slices.SortFunc(apps, func(a, b database.OAuth2ProviderApp) bool {
+		return a.Name < b.Name
+	})
+	return apps, nil
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppsByUserID(_ context.Context, userID uuid.UUID) ([]database.OAuth2ProviderApp, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	userKeyIDs := map[string]struct{}{}
+	for _, key := range q.apiKeys {
+		if key.UserID == userID {
+			userKeyIDs[key.ID] = struct{}{}
+		}
+	}
+	appIDs := map[uuid.UUID]struct{}{}
+	for _, token := range q.oauth2ProviderAppTokens {
+		if _, ok := userKeyIDs[token.APIKeyID]; !ok {
+			continue
+		}
+		for _, secret := range q.oauth2ProviderAppSecrets {
+			if secret.ID == token.AppSecretID {
+				appIDs[secret.AppID] = struct{}{}
+			}
+		}
+	}
+
+	apps := make([]database.OAuth2ProviderApp, 0)
+	for _, app := range q.oauth2ProviderApps {
+		if _, ok := appIDs[app.ID]; ok {
+			apps = append(apps, app)
+		}
+	}
+	slices.SortFunc(apps, func(a, b database.OAuth2ProviderApp) bool {
+		return a.Name < b.Name
+	})
+	return apps, nil
+}
+
 func (q *fakeQuerier) GetOrganizationByID(_ context.Context, id uuid.UUID) (database.Organization, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2064,93 +2999,271 @@ func (q *fakeQuerier) GetProvisionerJobsCreatedAfter(_ context.Context, after ti
 	return jobs, nil
 }
 
-func (q *fakeQuerier) GetProvisionerLogsAfterID(_ context.Context, arg database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
-	if err := validateDatabaseType(arg); err != nil {
-		return nil, err
+func (q *fakeQuerier) GetProvisionerLogsAfterID(_ context.Context, arg database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	logs := make([]database.ProvisionerJobLog, 0)
+	for _, jobLog := range q.provisionerJobLogs {
+		if jobLog.JobID != arg.JobID {
+			continue
+		}
+		if arg.CreatedAfter != 0 && jobLog.ID < arg.CreatedAfter {
+			continue
+		}
+		logs = append(logs, jobLog)
+	}
+	return logs, nil
+}
+
+func (q *fakeQuerier) GetQuotaAllowanceForUser(_ context.Context, userID uuid.UUID) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var sum int64
+	for _, member := range q.groupMembers {
+		if member.UserID != userID {
+			continue
+		}
+		for _, group := range q.groups {
+			if group.ID == member.GroupID {
+				sum += int64(group.QuotaAllowance)
+			}
+		}
+	}
+	return sum, nil
+}
+
+func (q *fakeQuerier) GetQuotaConsumedForUser(_ context.Context, userID uuid.UUID) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var sum int64
+	for _, workspace := range q.workspaces {
+		if workspace.OwnerID != userID {
+			continue
+		}
+		if workspace.Deleted {
+			continue
+		}
+
+		var lastBuild database.WorkspaceBuild
+		for _, build := range q.workspaceBuilds {
+			if build.WorkspaceID != workspace.ID {
+				continue
+			}
+			if build.CreatedAt.After(lastBuild.CreatedAt) {
+				lastBuild = build
+			}
+		}
+		sum += int64(lastBuild.DailyCost)
+	}
+	return sum, nil
+}
+
+func (q *fakeQuerier) GetReplicasUpdatedAfter(_ context.Context, updatedAt time.Time) ([]database.Replica, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	replicas := make([]database.Replica, 0)
+	for _, replica := range q.replicas {
+		if replica.UpdatedAt.After(updatedAt) && !replica.StoppedAt.Valid {
+			replicas = append(replicas, replica)
+		}
+	}
+	return replicas, nil
+}
+
+func (q *fakeQuerier) GetRunningTemplateVersionRollouts(_ context.Context) ([]database.TemplateVersionRollout, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rollouts := make([]database.TemplateVersionRollout, 0)
+	for _, rollout := range q.templateVersionRollouts {
+		if rollout.Status != database.TemplateVersionRolloutStatusRunning {
+			continue
+		}
+		for _, template := range q.templates {
+			if template.ID == rollout.TemplateID && !template.Deleted {
+				rollouts = append(rollouts, rollout)
+				break
+			}
+		}
+	}
+	sort.Slice(rollouts, func(i, j int) bool {
+		return rollouts[i].CreatedAt.Before(rollouts[j].CreatedAt)
+	})
+	return rollouts, nil
+}
+
+func (q *fakeQuerier) GetServiceBanner(_ context.Context) (string, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if q.serviceBanner == nil {
+		return "", sql.ErrNoRows
+	}
+
+	return string(q.serviceBanner), nil
+}
+
+func (q *fakeQuerier) GetSessionAPIKeysByUserID(_ context.Context, userID uuid.UUID) ([]database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	now := database.Now()
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.UserID == userID && isSessionAPIKey(key) && key.ExpiresAt.After(now) {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].LastUsed.After(apiKeys[j].LastUsed)
+	})
+	return apiKeys, nil
+}
+
+func (q *fakeQuerier) GetTailnetCoordinators(_ context.Context) ([]database.TailnetCoordinator, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	coordinators := make([]database.TailnetCoordinator, len(q.tailnetCoordinators))
+	copy(coordinators, q.tailnetCoordinators)
+	return coordinators, nil
+}
+
+func (q *fakeQuerier) GetTailnetPeers(_ context.Context, id uuid.UUID) ([]database.TailnetPeer, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	peers := make([]database.TailnetPeer, 0)
+	for _, peer := range q.tailnetPeers {
+		if peer.ID == id {
+			peers = append(peers, peer)
+		}
 	}
+	return peers, nil
+}
 
+func (q *fakeQuerier) GetTailnetTunnelPeerBindings(_ context.Context, srcID uuid.UUID) ([]database.GetTailnetTunnelPeerBindingsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	logs := make([]database.ProvisionerJobLog, 0)
-	for _, jobLog := range q.provisionerJobLogs {
-		if jobLog.JobID != arg.JobID {
-			continue
+	peerIDs := make(map[uuid.UUID]struct{})
+	for _, tunnel := range q.tailnetTunnels {
+		if tunnel.SrcID == srcID {
+			peerIDs[tunnel.DstID] = struct{}{}
 		}
-		if arg.CreatedAfter != 0 && jobLog.ID < arg.CreatedAfter {
+		if tunnel.DstID == srcID {
+			peerIDs[tunnel.SrcID] = struct{}{}
+		}
+	}
+	rows := make([]database.GetTailnetTunnelPeerBindingsRow, 0)
+	for _, peer := range q.tailnetPeers {
+		if _, ok := peerIDs[peer.ID]; !ok {
 			continue
 		}
-		logs = append(logs, jobLog)
+		rows = append(rows, database.GetTailnetTunnelPeerBindingsRow{
+			PeerID:        peer.ID,
+			CoordinatorID: peer.CoordinatorID,
+			UpdatedAt:     peer.UpdatedAt,
+			Node:          peer.Node,
+			Status:        peer.Status,
+		})
 	}
-	return logs, nil
+	return rows, nil
 }
 
-func (q *fakeQuerier) GetQuotaAllowanceForUser(_ context.Context, userID uuid.UUID) (int64, error) {
+func (q *fakeQuerier) GetTailnetTunnelPeerIDs(_ context.Context, srcID uuid.UUID) ([]database.GetTailnetTunnelPeerIDsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	var sum int64
-	for _, member := range q.groupMembers {
-		if member.UserID != userID {
+	rows := make([]database.GetTailnetTunnelPeerIDsRow, 0)
+	for _, tunnel := range q.tailnetTunnels {
+		row := database.GetTailnetTunnelPeerIDsRow{
+			CoordinatorID: tunnel.CoordinatorID,
+			UpdatedAt:     tunnel.UpdatedAt,
+		}
+		switch srcID {
+		case tunnel.SrcID:
+			row.PeerID = tunnel.DstID
+		case tunnel.DstID:
+			row.PeerID = tunnel.SrcID
+		default:
 			continue
 		}
-		for _, group := range q.groups {
-			if group.ID == member.GroupID {
-				sum += int64(group.QuotaAllowance)
-			}
+		// The query is a UNION, so duplicate rows are removed.
+		if !slices.Contains(rows, row) {
+			rows = append(rows, row)
 		}
 	}
-	return sum, nil
+	return rows, nil
 }
 
-func (q *fakeQuerier) GetQuotaConsumedForUser(_ context.Context, userID uuid.UUID) (int64, error) {
+func (q *fakeQuerier) GetTemplateActiveVersionApps(ctx context.Context, templateIDs []uuid.UUID) ([]database.GetTemplateActiveVersionAppsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	var sum int64
-	for _, workspace := range q.workspaces {
-		if workspace.OwnerID != userID {
+	templates := make([]database.Template, 0, len(q.templates))
+	for _, template := range q.templates {
+		if template.Deleted {
 			continue
 		}
-		if workspace.Deleted {
+		if len(templateIDs) > 0 && !slices.Contains(templateIDs, template.ID) {
 			continue
 		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].ID.String() < templates[j].ID.String()
+	})
 
-		var lastBuild database.WorkspaceBuild
-		for _, build := range q.workspaceBuilds {
-			if build.WorkspaceID != workspace.ID {
-				continue
-			}
-			if build.CreatedAt.After(lastBuild.CreatedAt) {
-				lastBuild = build
+	rows := make([]database.GetTemplateActiveVersionAppsRow, 0)
+	for _, template := range templates {
+		version, err := q.getTemplateVersionByIDNoLock(ctx, template.ActiveVersionID)
+		if err != nil {
+			continue
+		}
+		resources, err := q.getWorkspaceResourcesByJobIDNoLock(ctx, version.JobID)
+		if err != nil {
+			return nil, err
+		}
+		resourceIDs := make([]uuid.UUID, 0, len(resources))
+		for _, resource := range resources {
+			resourceIDs = append(resourceIDs, resource.ID)
+		}
+		agents, err := q.getWorkspaceAgentsByResourceIDsNoLock(ctx, resourceIDs)
+		if err != nil {
+			return nil, err
+		}
+		apps := make(map[string]database.GetTemplateActiveVersionAppsRow)
+		for _, agent := range agents {
+			for _, app := range q.workspaceApps {
+				if app.AgentID != agent.ID {
+					continue
+				}
+				if _, ok := apps[app.Slug]; ok {
+					continue
+				}
+				apps[app.Slug] = database.GetTemplateActiveVersionAppsRow{
+					TemplateID:  template.ID,
+					Slug:        app.Slug,
+					DisplayName: app.DisplayName,
+					Icon:        app.Icon,
+				}
 			}
 		}
-		sum += int64(lastBuild.DailyCost)
-	}
-	return sum, nil
-}
-
-func (q *fakeQuerier) GetReplicasUpdatedAfter(_ context.Context, updatedAt time.Time) ([]database.Replica, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
-	replicas := make([]database.Replica, 0)
-	for _, replica := range q.replicas {
-		if replica.UpdatedAt.After(updatedAt) && !replica.StoppedAt.Valid {
-			replicas = append(replicas, replica)
+		slugs := maps.Keys(apps)
+		sort.Strings(slugs)
+		for _, slug := range slugs {
+			rows = append(rows, apps[slug])
 		}
 	}
-	return replicas, nil
-}
-
-func (q *fakeQuerier) GetServiceBanner(_ context.Context) (string, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
-
-	if q.serviceBanner == nil {
-		return "", sql.ErrNoRows
-	}
-
-	return string(q.serviceBanner), nil
+	return rows, nil
 }
 
 func (q *fakeQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
@@ -2280,6 +3393,34 @@ func (q *fakeQuerier) GetTemplateDAUs(_ context.Context, arg database.GetTemplat
 	return rs, nil
 }
 
+func (q *fakeQuerier) GetTemplateGitSourceByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, source := range q.templateGitSources {
+		if source.TemplateID == templateID {
+			return source, nil
+		}
+	}
+	return database.TemplateGitSource{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateGitSources(_ context.Context) ([]database.TemplateGitSource, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	sources := make([]database.TemplateGitSource, 0)
+	for _, source := range q.templateGitSources {
+		for _, template := range q.templates {
+			if template.ID == source.TemplateID && !template.Deleted {
+				sources = append(sources, source)
+				break
+			}
+		}
+	}
+	return sources, nil
+}
+
 func (q *fakeQuerier) GetTemplateVersionByID(ctx context.Context, templateVersionID uuid.UUID) (database.TemplateVersion, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2334,6 +3475,158 @@ func (q *fakeQuerier) GetTemplateVersionParameters(_ context.Context, templateVe
 	return parameters, nil
 }
 
+func (q *fakeQuerier) GetTemplateVersionPresetByID(_ context.Context, id uuid.UUID) (database.TemplateVersionPreset, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, preset := range q.templateVersionPresets {
+		if preset.ID == id {
+			return preset, nil
+		}
+	}
+	return database.TemplateVersionPreset{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateVersionPresetParametersByTemplateVersionID(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPresetParameter, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	presetIDs := map[uuid.UUID]struct{}{}
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == templateVersionID {
+			presetIDs[preset.ID] = struct{}{}
+		}
+	}
+	parameters := make([]database.TemplateVersionPresetParameter, 0)
+	for _, parameter := range q.templateVersionPresetParameters {
+		if _, ok := presetIDs[parameter.PresetID]; ok {
+			parameters = append(parameters, parameter)
+		}
+	}
+	sort.Slice(parameters, func(i, j int) bool {
+		return parameters[i].Name < parameters[j].Name
+	})
+	return parameters, nil
+}
+
+func (q *fakeQuerier) GetTemplateVersionPresetsByTemplateVersionID(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	presets := make([]database.TemplateVersionPreset, 0)
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == templateVersionID {
+			presets = append(presets, preset)
+		}
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets, nil
+}
+
+func (q *fakeQuerier) GetTemplateVersionRolloutByID(_ context.Context, id uuid.UUID) (database.TemplateVersionRollout, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, rollout := range q.templateVersionRollouts {
+		if rollout.ID == id {
+			return rollout, nil
+		}
+	}
+	return database.TemplateVersionRollout{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateVersionRolloutCandidates(ctx context.Context, arg database.GetTemplateVersionRolloutCandidatesParams) ([]uuid.UUID, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	inCohort := func(workspace database.Workspace) bool {
+		if len(arg.GroupIDs) == 0 || slice.Contains(arg.GroupIDs, workspace.OrganizationID) {
+			return true
+		}
+		for _, member := range q.groupMembers {
+			if member.UserID == workspace.OwnerID && slice.Contains(arg.GroupIDs, member.GroupID) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ids := make([]uuid.UUID, 0)
+	for _, workspace := range q.workspaces {
+		if workspace.TemplateID != arg.TemplateID || workspace.Deleted || !inCohort(workspace) {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if err != nil {
+			continue
+		}
+		if build.TemplateVersionID == arg.TemplateVersionID {
+			continue
+		}
+		ids = append(ids, workspace.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids, nil
+}
+
+func (q *fakeQuerier) GetTemplateVersionRolloutWorkspacesByRolloutID(ctx context.Context, rolloutID uuid.UUID) ([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, 0)
+	for _, entry := range q.templateVersionRolloutWorkspaces {
+		if entry.RolloutID != rolloutID {
+			continue
+		}
+		workspace, err := q.getWorkspaceByIDNoLock(ctx, entry.WorkspaceID)
+		if err != nil {
+			continue
+		}
+		owner, err := q.getUserByIDNoLock(workspace.OwnerID)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow{
+			RolloutID:          entry.RolloutID,
+			WorkspaceID:        entry.WorkspaceID,
+			BuildID:            entry.BuildID,
+			Status:             entry.Status,
+			Error:              entry.Error,
+			UpdatedAt:          entry.UpdatedAt,
+			WorkspaceName:      workspace.Name,
+			WorkspaceOwnerName: owner.Username,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].WorkspaceID.String() < rows[j].WorkspaceID.String()
+	})
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetTemplateVersionRolloutsByTemplateID(_ context.Context, templateID uuid.UUID) ([]database.TemplateVersionRollout, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rollouts := make([]database.TemplateVersionRollout, 0)
+	for _, rollout := range q.templateVersionRollouts {
+		if rollout.TemplateID == templateID {
+			rollouts = append(rollouts, rollout)
+		}
+	}
+	sort.Slice(rollouts, func(i, j int) bool {
+		return rollouts[i].CreatedAt.After(rollouts[j].CreatedAt)
+	})
+	return rollouts, nil
+}
+
 func (q *fakeQuerier) GetTemplateVersionVariables(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2549,6 +3842,30 @@ func (q *fakeQuerier) GetUserLinkByUserIDLoginType(_ context.Context, params dat
 	return database.UserLink{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) GetUserLoginFailuresByUserID(_ context.Context, userID uuid.UUID) (database.UserLoginFailure, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, failure := range q.userLoginFailures {
+		if failure.UserID == userID {
+			return failure, nil
+		}
+	}
+	return database.UserLoginFailure{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetUserTOTPByUserID(_ context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, totp := range q.userTOTPs {
+		if totp.UserID == userID {
+			return totp, nil
+		}
+	}
+	return database.UserTOTP{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) GetUsers(_ context.Context, params database.GetUsersParams) ([]database.GetUsersRow, error) {
 	if err := validateDatabaseType(params); err != nil {
 		return nil, err
@@ -2962,6 +4279,80 @@ func (q *fakeQuerier) GetWorkspaceAppByAgentIDAndSlug(_ context.Context, arg dat
 	return database.WorkspaceApp{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) GetWorkspaceAppSecurityKeys(_ context.Context, now time.Time) ([]database.WorkspaceAppSecurityKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	keys := make([]database.WorkspaceAppSecurityKey, 0)
+	for _, key := range q.workspaceAppSecurityKeys {
+		if key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].StartsAt.After(keys[j].StartsAt)
+	})
+	return keys, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceAppUsageInsights(ctx context.Context, arg database.GetWorkspaceAppUsageInsightsParams) ([]database.GetWorkspaceAppUsageInsightsRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type key struct {
+		templateID uuid.UUID
+		appSlug    string
+	}
+	insights := make(map[key]*database.GetWorkspaceAppUsageInsightsRow)
+	users := make(map[key]map[uuid.UUID]struct{})
+	for _, rollup := range q.workspaceAppUsageRollups {
+		if rollup.Bucket.Before(arg.StartTime) || !rollup.Bucket.Before(arg.EndTime) {
+			continue
+		}
+		workspace, err := q.getWorkspaceByIDNoLock(ctx, rollup.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(arg.TemplateIDs) > 0 && !slices.Contains(arg.TemplateIDs, workspace.TemplateID) {
+			continue
+		}
+
+		k := key{templateID: workspace.TemplateID, appSlug: rollup.AppSlug}
+		insight, ok := insights[k]
+		if !ok {
+			insight = &database.GetWorkspaceAppUsageInsightsRow{
+				TemplateID: workspace.TemplateID,
+				AppSlug:    rollup.AppSlug,
+			}
+			insights[k] = insight
+			users[k] = make(map[uuid.UUID]struct{})
+		}
+		users[k][rollup.UserID] = struct{}{}
+		insight.Requests += rollup.Requests
+		insight.ErrorRequests += rollup.ErrorRequests
+		insight.TotalLatencyMs += rollup.TotalLatencyMs
+		insight.BytesSent += rollup.BytesSent
+	}
+
+	rows := make([]database.GetWorkspaceAppUsageInsightsRow, 0, len(insights))
+	for k, insight := range insights {
+		insight.UniqueUsers = int64(len(users[k]))
+		rows = append(rows, *insight)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].TemplateID != rows[j].TemplateID {
+			return rows[i].TemplateID.String() < rows[j].TemplateID.String()
+		}
+		return rows[i].AppSlug < rows[j].AppSlug
+	})
+	return rows, nil
+}
+
 func (q *fakeQuerier) GetWorkspaceAppsByAgentID(_ context.Context, id uuid.UUID) ([]database.WorkspaceApp, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3190,13 +4581,48 @@ func (q *fakeQuerier) GetWorkspaceByWorkspaceAppID(_ context.Context, workspaceA
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	for _, workspaceApp := range q.workspaceApps {
-		workspaceApp := workspaceApp
-		if workspaceApp.ID == workspaceAppID {
-			return q.getWorkspaceByAgentIDNoLock(context.Background(), workspaceApp.AgentID)
+	for _, workspaceApp := range q.workspaceApps {
+		workspaceApp := workspaceApp
+		if workspaceApp.ID == workspaceAppID {
+			return q.getWorkspaceByAgentIDNoLock(context.Background(), workspaceApp.AgentID)
+		}
+	}
+	return database.Workspace{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspacePortShare(_ context.Context, arg database.GetWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspacePortShare{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, share := range q.workspacePortShares {
+		if share.WorkspaceID == arg.WorkspaceID && share.AgentName == arg.AgentName && share.Port == arg.Port {
+			return share, nil
+		}
+	}
+	return database.WorkspacePortShare{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspacePortSharesByWorkspaceID(_ context.Context, workspaceID uuid.UUID) ([]database.WorkspacePortShare, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	shares := make([]database.WorkspacePortShare, 0)
+	for _, share := range q.workspacePortShares {
+		if share.WorkspaceID == workspaceID {
+			shares = append(shares, share)
 		}
 	}
-	return database.Workspace{}, sql.ErrNoRows
+	slices.SortFunc(shares, func(a, b database.WorkspacePortShare) bool {
+		if a.AgentName != b.AgentName {
+			return a.AgentName < b.AgentName
+		}
+		return a.Port < b.Port
+	})
+	return shares, nil
 }
 
 func (q *fakeQuerier) GetWorkspaceProxies(_ context.Context) ([]database.WorkspaceProxy, error) {
@@ -3430,11 +4856,36 @@ func (q *fakeQuerier) InsertAPIKey(_ context.Context, arg database.InsertAPIKeyP
 		LoginType:       arg.LoginType,
 		Scope:           arg.Scope,
 		TokenName:       arg.TokenName,
+		UserAgent:       arg.UserAgent,
 	}
 	q.apiKeys = append(q.apiKeys, key)
 	return key, nil
 }
 
+func (q *fakeQuerier) InsertAPIKeyImpersonation(_ context.Context, arg database.InsertAPIKeyImpersonationParams) (database.APIKeyImpersonation, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.APIKeyImpersonation{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, impersonation := range q.apiKeyImpersonations {
+		if impersonation.APIKeyID == arg.APIKeyID {
+			return database.APIKeyImpersonation{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	impersonation := database.APIKeyImpersonation{
+		APIKeyID:       arg.APIKeyID,
+		ImpersonatorID: arg.ImpersonatorID,
+		CreatedAt:      arg.CreatedAt,
+	}
+	q.apiKeyImpersonations = append(q.apiKeyImpersonations, impersonation)
+	return impersonation, nil
+}
+
 func (q *fakeQuerier) InsertAllUsersGroup(ctx context.Context, orgID uuid.UUID) (database.Group, error) {
 	return q.InsertGroup(ctx, database.InsertGroupParams{
 		ID:             orgID,
@@ -3469,6 +4920,70 @@ func (q *fakeQuerier) InsertDERPMeshKey(_ context.Context, id string) error {
 	return nil
 }
 
+func (q *fakeQuerier) InsertDERPRegion(_ context.Context, arg database.InsertDERPRegionParams) (database.DERPRegion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DERPRegion{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, region := range q.derpRegions {
+		if region.RegionID == arg.RegionID || strings.EqualFold(region.RegionCode, arg.RegionCode) {
+			return database.DERPRegion{}, errDuplicateKey
+		}
+	}
+	region := database.DERPRegion{
+		ID:         arg.ID,
+		RegionID:   arg.RegionID,
+		RegionCode: arg.RegionCode,
+		RegionName: arg.RegionName,
+		Nodes:      arg.Nodes,
+		Avoid:      arg.Avoid,
+		Healthy:    true,
+		CreatedAt:  arg.CreatedAt,
+		UpdatedAt:  arg.UpdatedAt,
+	}
+	q.derpRegions = append(q.derpRegions, region)
+	return region, nil
+}
+
+func (q *fakeQuerier) InsertDERPRegionPolicy(_ context.Context, arg database.InsertDERPRegionPolicyParams) (database.DERPRegionPolicy, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DERPRegionPolicy{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if arg.GroupID.Valid && !slices.ContainsFunc(q.groups, func(group database.Group) bool {
+		return group.ID == arg.GroupID.UUID
+	}) {
+		return database.DERPRegionPolicy{}, errForeignKeyConstraint
+	}
+	if arg.TemplateID.Valid && !slices.ContainsFunc(q.templates, func(template database.Template) bool {
+		return template.ID == arg.TemplateID.UUID
+	}) {
+		return database.DERPRegionPolicy{}, errForeignKeyConstraint
+	}
+	for _, policy := range q.derpRegionPolicies {
+		if (arg.GroupID.Valid && policy.GroupID == arg.GroupID) ||
+			(arg.TemplateID.Valid && policy.TemplateID == arg.TemplateID) {
+			return database.DERPRegionPolicy{}, errDuplicateKey
+		}
+	}
+	policy := database.DERPRegionPolicy{
+		ID:         arg.ID,
+		GroupID:    arg.GroupID,
+		TemplateID: arg.TemplateID,
+		RegionIDs:  arg.RegionIDs,
+		CreatedAt:  arg.CreatedAt,
+		UpdatedAt:  arg.UpdatedAt,
+	}
+	q.derpRegionPolicies = append(q.derpRegionPolicies, policy)
+	return policy, nil
+}
+
 func (q *fakeQuerier) InsertDeploymentID(_ context.Context, id string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -3613,6 +5128,171 @@ func (q *fakeQuerier) InsertLicense(
 	return l, nil
 }
 
+func (q *fakeQuerier) InsertOAuth2DeviceCode(_ context.Context, arg database.InsertOAuth2DeviceCodeParams) (database.OAuth2DeviceCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, code := range q.oauth2DeviceCodes {
+		if bytes.Equal(code.SecretPrefix, arg.SecretPrefix) || code.UserCode == arg.UserCode {
+			return database.OAuth2DeviceCode{}, errDuplicateKey
+		}
+	}
+
+	code := database.OAuth2DeviceCode{
+		ID:              arg.ID,
+		CreatedAt:       arg.CreatedAt,
+		ExpiresAt:       arg.ExpiresAt,
+		SecretPrefix:    arg.SecretPrefix,
+		HashedSecret:    arg.HashedSecret,
+		UserCode:        arg.UserCode,
+		Scope:           arg.Scope,
+		Status:          database.OAuth2DeviceCodeStatusPending,
+		PollingInterval: arg.PollingInterval,
+	}
+	q.oauth2DeviceCodes = append(q.oauth2DeviceCodes, code)
+	return code, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderApp(_ context.Context, arg database.InsertOAuth2ProviderAppParams) (database.OAuth2ProviderApp, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderApp{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.Name == arg.Name {
+			return database.OAuth2ProviderApp{}, &pq.Error{
+				Code:       errDuplicateKey.Code,
+				Message:    errDuplicateKey.Message,
+				Constraint: string(database.UniqueOauth2ProviderAppsNameKey),
+				Table:      "oauth2_provider_apps",
+			}
+		}
+	}
+
+	//nolint:gosimple
+	app := database.OAuth2ProviderApp{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Name:        arg.Name,
+		Icon:        arg.Icon,
+		CallbackURL: arg.CallbackURL,
+	}
+	q.oauth2ProviderApps = append(q.oauth2ProviderApps, app)
+	return app, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderAppCode(_ context.Context, arg database.InsertOAuth2ProviderAppCodeParams) (database.OAuth2ProviderAppCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !slices.ContainsFunc(q.oauth2ProviderApps, func(app database.OAuth2ProviderApp) bool {
+		return app.ID == arg.AppID
+	}) {
+		return database.OAuth2ProviderAppCode{}, errForeignKeyConstraint
+	}
+	for _, code := range q.oauth2ProviderAppCodes {
+		if bytes.Equal(code.SecretPrefix, arg.SecretPrefix) {
+			return database.OAuth2ProviderAppCode{}, errDuplicateKey
+		}
+	}
+
+	code := database.OAuth2ProviderAppCode{
+		ID:            arg.ID,
+		CreatedAt:     arg.CreatedAt,
+		ExpiresAt:     arg.ExpiresAt,
+		SecretPrefix:  arg.SecretPrefix,
+		HashedSecret:  arg.HashedSecret,
+		UserID:        arg.UserID,
+		AppID:         arg.AppID,
+		Scope:         arg.Scope,
+		RedirectURI:   arg.RedirectURI,
+		CodeChallenge: arg.CodeChallenge,
+	}
+	q.oauth2ProviderAppCodes = append(q.oauth2ProviderAppCodes, code)
+	return code, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderAppSecret(_ context.Context, arg database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppSecret{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !slices.ContainsFunc(q.oauth2ProviderApps, func(app database.OAuth2ProviderApp) bool {
+		return app.ID == arg.AppID
+	}) {
+		return database.OAuth2ProviderAppSecret{}, errForeignKeyConstraint
+	}
+	for _, secret := range q.oauth2ProviderAppSecrets {
+		if bytes.Equal(secret.SecretPrefix, arg.SecretPrefix) {
+			return database.OAuth2ProviderAppSecret{}, errDuplicateKey
+		}
+	}
+
+	secret := database.OAuth2ProviderAppSecret{
+		ID:            arg.ID,
+		CreatedAt:     arg.CreatedAt,
+		SecretPrefix:  arg.SecretPrefix,
+		HashedSecret:  arg.HashedSecret,
+		DisplaySecret: arg.DisplaySecret,
+		AppID:         arg.AppID,
+	}
+	q.oauth2ProviderAppSecrets = append(q.oauth2ProviderAppSecrets, secret)
+	return secret, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderAppToken(_ context.Context, arg database.InsertOAuth2ProviderAppTokenParams) (database.OAuth2ProviderAppToken, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppToken{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !slices.ContainsFunc(q.oauth2ProviderAppSecrets, func(secret database.OAuth2ProviderAppSecret) bool {
+		return secret.ID == arg.AppSecretID
+	}) {
+		return database.OAuth2ProviderAppToken{}, errForeignKeyConstraint
+	}
+	for _, token := range q.oauth2ProviderAppTokens {
+		if bytes.Equal(token.HashPrefix, arg.HashPrefix) {
+			return database.OAuth2ProviderAppToken{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	token := database.OAuth2ProviderAppToken{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		ExpiresAt:   arg.ExpiresAt,
+		HashPrefix:  arg.HashPrefix,
+		RefreshHash: arg.RefreshHash,
+		AppSecretID: arg.AppSecretID,
+		APIKeyID:    arg.APIKeyID,
+	}
+	q.oauth2ProviderAppTokens = append(q.oauth2ProviderAppTokens, token)
+	return token, nil
+}
+
 func (q *fakeQuerier) InsertOrganization(_ context.Context, arg database.InsertOrganizationParams) (database.Organization, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Organization{}, err
@@ -3747,6 +5427,31 @@ func (q *fakeQuerier) InsertReplica(_ context.Context, arg database.InsertReplic
 	return replica, nil
 }
 
+func (q *fakeQuerier) InsertServiceAccount(_ context.Context, arg database.InsertServiceAccountParams) (database.ServiceAccount, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ServiceAccount{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, account := range q.serviceAccounts {
+		if account.UserID == arg.UserID {
+			return database.ServiceAccount{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	account := database.ServiceAccount{
+		UserID:         arg.UserID,
+		OrganizationID: arg.OrganizationID,
+		CreatedBy:      arg.CreatedBy,
+		CreatedAt:      arg.CreatedAt,
+	}
+	q.serviceAccounts = append(q.serviceAccounts, account)
+	return account, nil
+}
+
 func (q *fakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTemplateParams) (database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Template{}, err
@@ -3773,6 +5478,7 @@ func (q *fakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTempl
 		AllowUserCancelWorkspaceJobs: arg.AllowUserCancelWorkspaceJobs,
 		AllowUserAutostart:           true,
 		AllowUserAutostop:            true,
+		MaxPortShareLevel:            database.PortShareLevelAuthenticated,
 	}
 	q.templates = append(q.templates, template)
 	return template.DeepCopy(), nil
@@ -3797,6 +5503,7 @@ func (q *fakeQuerier) InsertTemplateVersion(_ context.Context, arg database.Inse
 		Readme:         arg.Readme,
 		JobID:          arg.JobID,
 		CreatedBy:      arg.CreatedBy,
+		State:          database.TemplateVersionStateDraft,
 	}
 	q.templateVersions = append(q.templateVersions, version)
 	return version, nil
@@ -3833,6 +5540,105 @@ func (q *fakeQuerier) InsertTemplateVersionParameter(_ context.Context, arg data
 	return param, nil
 }
 
+func (q *fakeQuerier) InsertTemplateVersionPreset(_ context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == arg.TemplateVersionID && preset.Name == arg.Name {
+			return database.TemplateVersionPreset{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	preset := database.TemplateVersionPreset{
+		ID:                arg.ID,
+		TemplateVersionID: arg.TemplateVersionID,
+		Name:              arg.Name,
+		Description:       arg.Description,
+		CreatedAt:         arg.CreatedAt,
+	}
+	q.templateVersionPresets = append(q.templateVersionPresets, preset)
+	return preset, nil
+}
+
+func (q *fakeQuerier) InsertTemplateVersionPresetParameters(_ context.Context, arg database.InsertTemplateVersionPresetParametersParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, name := range arg.Name {
+		q.templateVersionPresetParameters = append(q.templateVersionPresetParameters, database.TemplateVersionPresetParameter{
+			PresetID: arg.PresetID,
+			Name:     name,
+			Value:    arg.Value[index],
+		})
+	}
+	return nil
+}
+
+func (q *fakeQuerier) InsertTemplateVersionRollout(_ context.Context, arg database.InsertTemplateVersionRolloutParams) (database.TemplateVersionRollout, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, rollout := range q.templateVersionRollouts {
+		if rollout.TemplateID == arg.TemplateID &&
+			(rollout.Status == database.TemplateVersionRolloutStatusRunning || rollout.Status == database.TemplateVersionRolloutStatusPaused) {
+			return database.TemplateVersionRollout{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	rollout := database.TemplateVersionRollout{
+		ID:                      arg.ID,
+		TemplateID:              arg.TemplateID,
+		TemplateVersionID:       arg.TemplateVersionID,
+		CreatedBy:               arg.CreatedBy,
+		CreatedAt:               arg.CreatedAt,
+		UpdatedAt:               arg.UpdatedAt,
+		Status:                  database.TemplateVersionRolloutStatusRunning,
+		Percentage:              arg.Percentage,
+		GroupIDs:                arg.GroupIDs,
+		BatchSize:               arg.BatchSize,
+		FailureThresholdPercent: arg.FailureThresholdPercent,
+	}
+	q.templateVersionRollouts = append(q.templateVersionRollouts, rollout)
+	return rollout, nil
+}
+
+func (q *fakeQuerier) InsertTemplateVersionRolloutWorkspace(_ context.Context, arg database.InsertTemplateVersionRolloutWorkspaceParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, workspace := range q.templateVersionRolloutWorkspaces {
+		if workspace.RolloutID == arg.RolloutID && workspace.WorkspaceID == arg.WorkspaceID {
+			return errDuplicateKey
+		}
+	}
+	q.templateVersionRolloutWorkspaces = append(q.templateVersionRolloutWorkspaces, database.TemplateVersionRolloutWorkspace{
+		RolloutID:   arg.RolloutID,
+		WorkspaceID: arg.WorkspaceID,
+		Status:      database.TemplateVersionRolloutWorkspaceStatusPending,
+		UpdatedAt:   arg.UpdatedAt,
+	})
+	return nil
+}
+
 func (q *fakeQuerier) InsertTemplateVersionVariable(_ context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.TemplateVersionVariable{}, err
@@ -3935,6 +5741,7 @@ func (q *fakeQuerier) InsertUserLink(_ context.Context, args database.InsertUser
 		OAuthAccessToken:  args.OAuthAccessToken,
 		OAuthRefreshToken: args.OAuthRefreshToken,
 		OAuthExpiry:       args.OAuthExpiry,
+		OIDCProviderID:    args.OIDCProviderID,
 	}
 
 	q.userLinks = append(q.userLinks, link)
@@ -3942,6 +5749,30 @@ func (q *fakeQuerier) InsertUserLink(_ context.Context, args database.InsertUser
 	return link, nil
 }
 
+func (q *fakeQuerier) InsertUserLoginFailure(_ context.Context, arg database.InsertUserLoginFailureParams) (database.UserLoginFailure, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserLoginFailure{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, failure := range q.userLoginFailures {
+		if failure.UserID == arg.UserID {
+			q.userLoginFailures[i].FailedAttempts++
+			q.userLoginFailures[i].LastFailedAt = arg.LastFailedAt
+			return q.userLoginFailures[i], nil
+		}
+	}
+	failure := database.UserLoginFailure{
+		UserID:         arg.UserID,
+		FailedAttempts: 1,
+		LastFailedAt:   arg.LastFailedAt,
+	}
+	q.userLoginFailures = append(q.userLoginFailures, failure)
+	return failure, nil
+}
+
 func (q *fakeQuerier) InsertWorkspace(_ context.Context, arg database.InsertWorkspaceParams) (database.Workspace, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Workspace{}, err
@@ -3988,6 +5819,7 @@ func (q *fakeQuerier) InsertWorkspaceAgent(_ context.Context, arg database.Inser
 		OperatingSystem:          arg.OperatingSystem,
 		Directory:                arg.Directory,
 		StartupScriptBehavior:    arg.StartupScriptBehavior,
+		PeerConnections:          json.RawMessage("[]"),
 		StartupScript:            arg.StartupScript,
 		InstanceMetadata:         arg.InstanceMetadata,
 		ResourceMetadata:         arg.ResourceMetadata,
@@ -4125,11 +5957,35 @@ func (q *fakeQuerier) InsertWorkspaceApp(_ context.Context, arg database.InsertW
 		HealthcheckInterval:  arg.HealthcheckInterval,
 		HealthcheckThreshold: arg.HealthcheckThreshold,
 		Health:               arg.Health,
+		SharingGroups:        arg.SharingGroups,
 	}
 	q.workspaceApps = append(q.workspaceApps, workspaceApp)
 	return workspaceApp, nil
 }
 
+func (q *fakeQuerier) InsertWorkspaceAppSecurityKey(_ context.Context, arg database.InsertWorkspaceAppSecurityKeyParams) (database.WorkspaceAppSecurityKey, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceAppSecurityKey{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, key := range q.workspaceAppSecurityKeys {
+		if key.ID == arg.ID {
+			return database.WorkspaceAppSecurityKey{}, errDuplicateKey
+		}
+	}
+	key := database.WorkspaceAppSecurityKey{
+		ID:        arg.ID,
+		Secret:    arg.Secret,
+		CreatedAt: arg.CreatedAt,
+		StartsAt:  arg.StartsAt,
+	}
+	q.workspaceAppSecurityKeys = append(q.workspaceAppSecurityKeys, key)
+	return key, nil
+}
+
 func (q *fakeQuerier) InsertWorkspaceBuild(_ context.Context, arg database.InsertWorkspaceBuildParams) (database.WorkspaceBuild, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.WorkspaceBuild{}, err
@@ -4291,7 +6147,76 @@ func (q *fakeQuerier) UpdateAPIKeyByID(_ context.Context, arg database.UpdateAPI
 		q.apiKeys[index] = apiKey
 		return nil
 	}
-	return sql.ErrNoRows
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateDERPRegionByID(_ context.Context, arg database.UpdateDERPRegionByIDParams) (database.DERPRegion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DERPRegion{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, region := range q.derpRegions {
+		if region.RegionID != arg.RegionID && strings.EqualFold(region.RegionCode, arg.RegionCode) {
+			return database.DERPRegion{}, errDuplicateKey
+		}
+	}
+	for i, region := range q.derpRegions {
+		if region.RegionID != arg.RegionID {
+			continue
+		}
+		region.RegionCode = arg.RegionCode
+		region.RegionName = arg.RegionName
+		region.Nodes = arg.Nodes
+		region.Avoid = arg.Avoid
+		region.UpdatedAt = arg.UpdatedAt
+		q.derpRegions[i] = region
+		return region, nil
+	}
+	return database.DERPRegion{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateDERPRegionHealthByID(_ context.Context, arg database.UpdateDERPRegionHealthByIDParams) (database.DERPRegion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DERPRegion{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, region := range q.derpRegions {
+		if region.RegionID != arg.RegionID {
+			continue
+		}
+		region.Healthy = arg.Healthy
+		region.HealthError = arg.HealthError
+		region.LastHealthCheckAt = arg.LastHealthCheckAt
+		q.derpRegions[i] = region
+		return region, nil
+	}
+	return database.DERPRegion{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateDERPRegionPolicyByID(_ context.Context, arg database.UpdateDERPRegionPolicyByIDParams) (database.DERPRegionPolicy, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.DERPRegionPolicy{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, policy := range q.derpRegionPolicies {
+		if policy.ID != arg.ID {
+			continue
+		}
+		policy.RegionIDs = arg.RegionIDs
+		policy.UpdatedAt = arg.UpdatedAt
+		q.derpRegionPolicies[i] = policy
+		return policy, nil
+	}
+	return database.DERPRegionPolicy{}, sql.ErrNoRows
 }
 
 func (q *fakeQuerier) UpdateGitAuthLink(_ context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
@@ -4390,6 +6315,102 @@ func (q *fakeQuerier) UpdateMemberRoles(_ context.Context, arg database.UpdateMe
 	return database.OrganizationMember{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateOAuth2DeviceCodePolledByID(_ context.Context, arg database.UpdateOAuth2DeviceCodePolledByIDParams) (database.OAuth2DeviceCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, code := range q.oauth2DeviceCodes {
+		if code.ID != arg.ID {
+			continue
+		}
+		code.LastPolledAt = arg.LastPolledAt
+		code.PollingInterval = arg.PollingInterval
+		q.oauth2DeviceCodes[i] = code
+		return code, nil
+	}
+	return database.OAuth2DeviceCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOAuth2DeviceCodeStatusByID(_ context.Context, arg database.UpdateOAuth2DeviceCodeStatusByIDParams) (database.OAuth2DeviceCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2DeviceCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, code := range q.oauth2DeviceCodes {
+		if code.ID != arg.ID || code.Status != database.OAuth2DeviceCodeStatusPending {
+			continue
+		}
+		code.Status = arg.Status
+		code.UserID = arg.UserID
+		q.oauth2DeviceCodes[i] = code
+		return code, nil
+	}
+	return database.OAuth2DeviceCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOAuth2ProviderAppByID(_ context.Context, arg database.UpdateOAuth2ProviderAppByIDParams) (database.OAuth2ProviderApp, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderApp{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.Name == arg.Name && app.ID != arg.ID {
+			return database.OAuth2ProviderApp{}, &pq.Error{
+				Code:       errDuplicateKey.Code,
+				Message:    errDuplicateKey.Message,
+				Constraint: string(database.UniqueOauth2ProviderAppsNameKey),
+				Table:      "oauth2_provider_apps",
+			}
+		}
+	}
+
+	for i, app := range q.oauth2ProviderApps {
+		if app.ID != arg.ID {
+			continue
+		}
+		app.UpdatedAt = arg.UpdatedAt
+		app.Name = arg.Name
+		app.Icon = arg.Icon
+		app.CallbackURL = arg.CallbackURL
+		q.oauth2ProviderApps[i] = app
+		return app, nil
+	}
+	return database.OAuth2ProviderApp{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOAuth2ProviderAppSecretByID(_ context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppSecret{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, secret := range q.oauth2ProviderAppSecrets {
+		if secret.ID != arg.ID {
+			continue
+		}
+		secret.LastUsedAt = arg.LastUsedAt
+		q.oauth2ProviderAppSecrets[i] = secret
+		return secret, nil
+	}
+	return database.OAuth2ProviderAppSecret{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4478,6 +6499,22 @@ func (q *fakeQuerier) UpdateReplica(_ context.Context, arg database.UpdateReplic
 	return database.Replica{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateTailnetPeerStatusByCoordinator(_ context.Context, arg database.UpdateTailnetPeerStatusByCoordinatorParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, peer := range q.tailnetPeers {
+		if peer.CoordinatorID == arg.CoordinatorID {
+			q.tailnetPeers[i].Status = arg.Status
+		}
+	}
+	return nil
+}
+
 func (q *fakeQuerier) UpdateTemplateACLByID(_ context.Context, arg database.UpdateTemplateACLByIDParams) (database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Template{}, err
@@ -4539,6 +6576,27 @@ func (q *fakeQuerier) UpdateTemplateDeletedByID(_ context.Context, arg database.
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateTemplateGitSourceSyncStatus(_ context.Context, arg database.UpdateTemplateGitSourceSyncStatusParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		source.LastCommitSHA = arg.LastCommitSHA
+		source.LastSyncedAt = arg.LastSyncedAt
+		source.LastError = arg.LastError
+		q.templateGitSources[i] = source
+		return nil
+	}
+	return nil
+}
+
 func (q *fakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Template{}, err
@@ -4556,6 +6614,8 @@ func (q *fakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 		tpl.DisplayName = arg.DisplayName
 		tpl.Description = arg.Description
 		tpl.Icon = arg.Icon
+		tpl.RequireActiveVersion = arg.RequireActiveVersion
+		tpl.MaxPortShareLevel = arg.MaxPortShareLevel
 		q.templates[idx] = tpl
 		return tpl.DeepCopy(), nil
 	}
@@ -4650,6 +6710,69 @@ func (q *fakeQuerier) UpdateTemplateVersionGitAuthProvidersByJobID(_ context.Con
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateTemplateVersionRolloutStatusByID(_ context.Context, arg database.UpdateTemplateVersionRolloutStatusByIDParams) (database.TemplateVersionRollout, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionRollout{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, rollout := range q.templateVersionRollouts {
+		if rollout.ID != arg.ID {
+			continue
+		}
+		rollout.Status = arg.Status
+		rollout.PausedReason = arg.PausedReason
+		rollout.UpdatedAt = arg.UpdatedAt
+		q.templateVersionRollouts[i] = rollout
+		return rollout, nil
+	}
+	return database.TemplateVersionRollout{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateTemplateVersionRolloutWorkspace(_ context.Context, arg database.UpdateTemplateVersionRolloutWorkspaceParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, workspace := range q.templateVersionRolloutWorkspaces {
+		if workspace.RolloutID != arg.RolloutID || workspace.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		workspace.BuildID = arg.BuildID
+		workspace.Status = arg.Status
+		workspace.Error = arg.Error
+		workspace.UpdatedAt = arg.UpdatedAt
+		q.templateVersionRolloutWorkspaces[i] = workspace
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) UpdateTemplateVersionStateByID(_ context.Context, arg database.UpdateTemplateVersionStateByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, templateVersion := range q.templateVersions {
+		if templateVersion.ID != arg.ID {
+			continue
+		}
+		templateVersion.State = arg.State
+		templateVersion.UpdatedAt = arg.UpdatedAt
+		q.templateVersions[index] = templateVersion
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateUserDeletedByID(_ context.Context, params database.UpdateUserDeletedByIDParams) error {
 	if err := validateDatabaseType(params); err != nil {
 		return err
@@ -4764,6 +6887,24 @@ func (q *fakeQuerier) UpdateUserLinkedID(_ context.Context, params database.Upda
 	return database.UserLink{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateUserLoginLockout(_ context.Context, arg database.UpdateUserLoginLockoutParams) (database.UserLoginFailure, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserLoginFailure{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, failure := range q.userLoginFailures {
+		if failure.UserID == arg.UserID {
+			q.userLoginFailures[i].FailedAttempts = 0
+			q.userLoginFailures[i].LockedUntil = arg.LockedUntil
+			return q.userLoginFailures[i], nil
+		}
+	}
+	return database.UserLoginFailure{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateUserProfile(_ context.Context, arg database.UpdateUserProfileParams) (database.User, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.User{}, err
@@ -4839,6 +6980,28 @@ func (q *fakeQuerier) UpdateUserStatus(_ context.Context, arg database.UpdateUse
 	return database.User{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateUserTOTP(_ context.Context, arg database.UpdateUserTOTPParams) (database.UserTOTP, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserTOTP{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, totp := range q.userTOTPs {
+		if totp.UserID != arg.UserID {
+			continue
+		}
+		totp.Enabled = arg.Enabled
+		totp.HashedRecoveryCodes = arg.HashedRecoveryCodes
+		totp.LastCounter = arg.LastCounter
+		totp.UpdatedAt = arg.UpdatedAt
+		q.userTOTPs[i] = totp
+		return totp, nil
+	}
+	return database.UserTOTP{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateWorkspace(_ context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Workspace{}, err
@@ -4931,6 +7094,23 @@ func (q *fakeQuerier) UpdateWorkspaceAgentMetadata(_ context.Context, arg databa
 	return nil
 }
 
+func (q *fakeQuerier) UpdateWorkspaceAgentPeerConnectionsByID(_ context.Context, arg database.UpdateWorkspaceAgentPeerConnectionsByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for i, agent := range q.workspaceAgents {
+		if agent.ID == arg.ID {
+			agent.PeerConnections = arg.PeerConnections
+			q.workspaceAgents[i] = agent
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateWorkspaceAgentStartupByID(_ context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -5169,14 +7349,6 @@ func (q *fakeQuerier) UpdateWorkspaceTTLToBeWithinTemplateMax(_ context.Context,
 	return nil
 }
 
-func (q *fakeQuerier) UpsertAppSecurityKey(_ context.Context, data string) error {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
-
-	q.appSecurityKey = data
-	return nil
-}
-
 func (q *fakeQuerier) UpsertDefaultProxy(_ context.Context, arg database.UpsertDefaultProxyParams) error {
 	q.defaultProxyDisplayName = arg.DisplayName
 	q.defaultProxyIconURL = arg.IconUrl
@@ -5206,3 +7378,214 @@ func (q *fakeQuerier) UpsertServiceBanner(_ context.Context, data string) error
 	q.serviceBanner = []byte(data)
 	return nil
 }
+
+func (q *fakeQuerier) UpsertTailnetCoordinator(_ context.Context, id uuid.UUID) (database.TailnetCoordinator, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, coordinator := range q.tailnetCoordinators {
+		if coordinator.ID != id {
+			continue
+		}
+		q.tailnetCoordinators[i].HeartbeatAt = database.Now()
+		return q.tailnetCoordinators[i], nil
+	}
+	coordinator := database.TailnetCoordinator{
+		ID:          id,
+		HeartbeatAt: database.Now(),
+	}
+	q.tailnetCoordinators = append(q.tailnetCoordinators, coordinator)
+	return coordinator, nil
+}
+
+func (q *fakeQuerier) UpsertTailnetPeer(_ context.Context, arg database.UpsertTailnetPeerParams) (database.TailnetPeer, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TailnetPeer{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.tailnetCoordinatorExistsNoLock(arg.CoordinatorID) {
+		return database.TailnetPeer{}, errForeignKeyConstraint
+	}
+	peer := database.TailnetPeer{
+		ID:            arg.ID,
+		CoordinatorID: arg.CoordinatorID,
+		UpdatedAt:     database.Now(),
+		Node:          arg.Node,
+		Status:        arg.Status,
+	}
+	for i, existing := range q.tailnetPeers {
+		if existing.ID == arg.ID && existing.CoordinatorID == arg.CoordinatorID {
+			q.tailnetPeers[i] = peer
+			return peer, nil
+		}
+	}
+	q.tailnetPeers = append(q.tailnetPeers, peer)
+	return peer, nil
+}
+
+func (q *fakeQuerier) UpsertTailnetTunnel(_ context.Context, arg database.UpsertTailnetTunnelParams) (database.TailnetTunnel, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TailnetTunnel{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.tailnetCoordinatorExistsNoLock(arg.CoordinatorID) {
+		return database.TailnetTunnel{}, errForeignKeyConstraint
+	}
+	tunnel := database.TailnetTunnel{
+		CoordinatorID: arg.CoordinatorID,
+		SrcID:         arg.SrcID,
+		DstID:         arg.DstID,
+		UpdatedAt:     database.Now(),
+	}
+	for i, existing := range q.tailnetTunnels {
+		if existing.CoordinatorID == arg.CoordinatorID && existing.SrcID == arg.SrcID && existing.DstID == arg.DstID {
+			q.tailnetTunnels[i] = tunnel
+			return tunnel, nil
+		}
+	}
+	q.tailnetTunnels = append(q.tailnetTunnels, tunnel)
+	return tunnel, nil
+}
+
+func (q *fakeQuerier) UpsertTemplateGitSource(_ context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateGitSource{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, source := range q.templateGitSources {
+		if source.TemplateID != arg.TemplateID {
+			continue
+		}
+		source.UpdatedAt = arg.UpdatedAt
+		source.CreatedBy = arg.CreatedBy
+		source.RepositoryURL = arg.RepositoryURL
+		source.Ref = arg.Ref
+		source.Subdirectory = arg.Subdirectory
+		source.GitAuthProviderID = arg.GitAuthProviderID
+		source.PollInterval = arg.PollInterval
+		source.WebhookSecret = arg.WebhookSecret
+		source.LastCommitSHA = ""
+		q.templateGitSources[i] = source
+		return source, nil
+	}
+
+	//nolint:gosimple
+	source := database.TemplateGitSource{
+		TemplateID:        arg.TemplateID,
+		CreatedAt:         arg.CreatedAt,
+		UpdatedAt:         arg.UpdatedAt,
+		CreatedBy:         arg.CreatedBy,
+		RepositoryURL:     arg.RepositoryURL,
+		Ref:               arg.Ref,
+		Subdirectory:      arg.Subdirectory,
+		GitAuthProviderID: arg.GitAuthProviderID,
+		PollInterval:      arg.PollInterval,
+		WebhookSecret:     arg.WebhookSecret,
+	}
+	q.templateGitSources = append(q.templateGitSources, source)
+	return source, nil
+}
+
+func (q *fakeQuerier) UpsertUserTOTP(_ context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserTOTP{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	totp := database.UserTOTP{
+		UserID:              arg.UserID,
+		Secret:              arg.Secret,
+		HashedRecoveryCodes: []string{},
+		CreatedAt:           arg.CreatedAt,
+		UpdatedAt:           arg.CreatedAt,
+	}
+	for i, existing := range q.userTOTPs {
+		if existing.UserID == arg.UserID {
+			q.userTOTPs[i] = totp
+			return totp, nil
+		}
+	}
+	q.userTOTPs = append(q.userTOTPs, totp)
+	return totp, nil
+}
+
+func (q *fakeQuerier) UpsertWorkspaceAppUsageRollup(_ context.Context, arg database.UpsertWorkspaceAppUsageRollupParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, rollup := range q.workspaceAppUsageRollups {
+		if !rollup.Bucket.Equal(arg.Bucket) || rollup.WorkspaceID != arg.WorkspaceID ||
+			rollup.UserID != arg.UserID || rollup.AppSlug != arg.AppSlug {
+			continue
+		}
+		rollup.Requests += arg.Requests
+		rollup.ErrorRequests += arg.ErrorRequests
+		rollup.TotalLatencyMs += arg.TotalLatencyMs
+		rollup.BytesSent += arg.BytesSent
+		q.workspaceAppUsageRollups[i] = rollup
+		return nil
+	}
+
+	//nolint:gosimple
+	q.workspaceAppUsageRollups = append(q.workspaceAppUsageRollups, database.WorkspaceAppUsageRollup{
+		Bucket:         arg.Bucket,
+		WorkspaceID:    arg.WorkspaceID,
+		UserID:         arg.UserID,
+		AppSlug:        arg.AppSlug,
+		Requests:       arg.Requests,
+		ErrorRequests:  arg.ErrorRequests,
+		TotalLatencyMs: arg.TotalLatencyMs,
+		BytesSent:      arg.BytesSent,
+	})
+	return nil
+}
+
+func (q *fakeQuerier) UpsertWorkspacePortShare(_ context.Context, arg database.UpsertWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspacePortShare{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, share := range q.workspacePortShares {
+		if share.WorkspaceID != arg.WorkspaceID || share.AgentName != arg.AgentName || share.Port != arg.Port {
+			continue
+		}
+		share.ShareLevel = arg.ShareLevel
+		share.GroupID = arg.GroupID
+		share.ExpiresAt = arg.ExpiresAt
+		share.UpdatedAt = arg.UpdatedAt
+		q.workspacePortShares[i] = share
+		return share, nil
+	}
+
+	//nolint:gosimple
+	share := database.WorkspacePortShare{
+		WorkspaceID: arg.WorkspaceID,
+		AgentName:   arg.AgentName,
+		Port:        arg.Port,
+		ShareLevel:  arg.ShareLevel,
+		GroupID:     arg.GroupID,
+		ExpiresAt:   arg.ExpiresAt,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+	}
+	q.workspacePortShares = append(q.workspacePortShares, share)
+	return share, nil
+}
@@ -112,11 +112,22 @@ func APIKey(t testing.TB, db database.Store, seed database.APIKey) (key database
 		LoginType:       takeFirst(seed.LoginType, database.LoginTypePassword),
 		Scope:           takeFirst(seed.Scope, database.APIKeyScopeAll),
 		TokenName:       takeFirst(seed.TokenName),
+		UserAgent:       takeFirst(seed.UserAgent),
 	})
 	require.NoError(t, err, "insert api key")
 	return key, fmt.Sprintf("%s-%s", key.ID, secret)
 }
 
+func APIKeyImpersonation(t testing.TB, db database.Store, orig database.APIKeyImpersonation) database.APIKeyImpersonation {
+	impersonation, err := db.InsertAPIKeyImpersonation(genCtx, database.InsertAPIKeyImpersonationParams{
+		APIKeyID:       orig.APIKeyID,
+		ImpersonatorID: takeFirst(orig.ImpersonatorID, uuid.New()),
+		CreatedAt:      takeFirst(orig.CreatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert api key impersonation")
+	return impersonation
+}
+
 func WorkspaceAgent(t testing.TB, db database.Store, orig database.WorkspaceAgent) database.WorkspaceAgent {
 	workspace, err := db.InsertWorkspaceAgent(genCtx, database.InsertWorkspaceAgentParams{
 		ID:         takeFirst(orig.ID, uuid.New()),
@@ -354,6 +365,7 @@ func WorkspaceApp(t testing.TB, db database.Store, orig database.WorkspaceApp) d
 		HealthcheckInterval:  takeFirst(orig.HealthcheckInterval, 60),
 		HealthcheckThreshold: takeFirst(orig.HealthcheckThreshold, 60),
 		Health:               takeFirst(orig.Health, database.WorkspaceAppHealthHealthy),
+		SharingGroups:        takeFirstSlice(orig.SharingGroups, []string{}),
 	})
 	require.NoError(t, err, "insert app")
 	return resource
@@ -390,6 +402,21 @@ func WorkspaceResourceMetadatums(t testing.TB, db database.Store, seed database.
 	return meta
 }
 
+func WorkspacePortShare(t testing.TB, db database.Store, orig database.WorkspacePortShare) database.WorkspacePortShare {
+	share, err := db.UpsertWorkspacePortShare(genCtx, database.UpsertWorkspacePortShareParams{
+		WorkspaceID: takeFirst(orig.WorkspaceID, uuid.New()),
+		AgentName:   takeFirst(orig.AgentName, "main"),
+		Port:        takeFirst(orig.Port, 8080),
+		ShareLevel:  takeFirst(orig.ShareLevel, database.PortShareLevelAuthenticated),
+		GroupID:     orig.GroupID,
+		ExpiresAt:   orig.ExpiresAt,
+		CreatedAt:   takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:   takeFirst(orig.UpdatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert workspace port share")
+	return share
+}
+
 func WorkspaceProxy(t testing.TB, db database.Store, orig database.WorkspaceProxy) (database.WorkspaceProxy, string) {
 	secret, err := cryptorand.HexString(64)
 	require.NoError(t, err, "generate secret")
@@ -418,6 +445,78 @@ func WorkspaceProxy(t testing.TB, db database.Store, orig database.WorkspaceProx
 	return proxy, secret
 }
 
+func OAuth2ProviderApp(t testing.TB, db database.Store, seed database.OAuth2ProviderApp) database.OAuth2ProviderApp {
+	app, err := db.InsertOAuth2ProviderApp(genCtx, database.InsertOAuth2ProviderAppParams{
+		ID:          takeFirst(seed.ID, uuid.New()),
+		Name:        takeFirst(seed.Name, namesgenerator.GetRandomName(1)),
+		CreatedAt:   takeFirst(seed.CreatedAt, database.Now()),
+		UpdatedAt:   takeFirst(seed.UpdatedAt, database.Now()),
+		Icon:        takeFirst(seed.Icon, ""),
+		CallbackURL: takeFirst(seed.CallbackURL, "http://localhost"),
+	})
+	require.NoError(t, err, "insert oauth2 app")
+	return app
+}
+
+func OAuth2ProviderAppSecret(t testing.TB, db database.Store, seed database.OAuth2ProviderAppSecret) database.OAuth2ProviderAppSecret {
+	app, err := db.InsertOAuth2ProviderAppSecret(genCtx, database.InsertOAuth2ProviderAppSecretParams{
+		ID:            takeFirst(seed.ID, uuid.New()),
+		CreatedAt:     takeFirst(seed.CreatedAt, database.Now()),
+		SecretPrefix:  takeFirstSlice(seed.SecretPrefix, []byte(uuid.NewString())),
+		HashedSecret:  takeFirstSlice(seed.HashedSecret, []byte("hashed-secret")),
+		DisplaySecret: takeFirst(seed.DisplaySecret, "secret"),
+		AppID:         takeFirst(seed.AppID, uuid.New()),
+	})
+	require.NoError(t, err, "insert oauth2 app secret")
+	return app
+}
+
+func OAuth2ProviderAppCode(t testing.TB, db database.Store, seed database.OAuth2ProviderAppCode) database.OAuth2ProviderAppCode {
+	code, err := db.InsertOAuth2ProviderAppCode(genCtx, database.InsertOAuth2ProviderAppCodeParams{
+		ID:            takeFirst(seed.ID, uuid.New()),
+		CreatedAt:     takeFirst(seed.CreatedAt, database.Now()),
+		ExpiresAt:     takeFirst(seed.ExpiresAt, database.Now().Add(10*time.Minute)),
+		SecretPrefix:  takeFirstSlice(seed.SecretPrefix, []byte(uuid.NewString())),
+		HashedSecret:  takeFirstSlice(seed.HashedSecret, []byte("hashed-secret")),
+		AppID:         takeFirst(seed.AppID, uuid.New()),
+		UserID:        takeFirst(seed.UserID, uuid.New()),
+		Scope:         takeFirst(seed.Scope, database.APIKeyScopeAll),
+		RedirectURI:   takeFirst(seed.RedirectURI, "http://localhost"),
+		CodeChallenge: takeFirst(seed.CodeChallenge),
+	})
+	require.NoError(t, err, "insert oauth2 app code")
+	return code
+}
+
+func OAuth2ProviderAppToken(t testing.TB, db database.Store, seed database.OAuth2ProviderAppToken) database.OAuth2ProviderAppToken {
+	token, err := db.InsertOAuth2ProviderAppToken(genCtx, database.InsertOAuth2ProviderAppTokenParams{
+		ID:          takeFirst(seed.ID, uuid.New()),
+		CreatedAt:   takeFirst(seed.CreatedAt, database.Now()),
+		ExpiresAt:   takeFirst(seed.ExpiresAt, database.Now().Add(30*24*time.Hour)),
+		HashPrefix:  takeFirstSlice(seed.HashPrefix, []byte(uuid.NewString())),
+		RefreshHash: takeFirstSlice(seed.RefreshHash, []byte("hashed-secret")),
+		AppSecretID: takeFirst(seed.AppSecretID, uuid.New()),
+		APIKeyID:    takeFirst(seed.APIKeyID),
+	})
+	require.NoError(t, err, "insert oauth2 app token")
+	return token
+}
+
+func OAuth2DeviceCode(t testing.TB, db database.Store, seed database.OAuth2DeviceCode) database.OAuth2DeviceCode {
+	code, err := db.InsertOAuth2DeviceCode(genCtx, database.InsertOAuth2DeviceCodeParams{
+		ID:              takeFirst(seed.ID, uuid.New()),
+		CreatedAt:       takeFirst(seed.CreatedAt, database.Now()),
+		ExpiresAt:       takeFirst(seed.ExpiresAt, database.Now().Add(10*time.Minute)),
+		SecretPrefix:    takeFirstSlice(seed.SecretPrefix, []byte(uuid.NewString())),
+		HashedSecret:    takeFirstSlice(seed.HashedSecret, []byte("hashed-secret")),
+		UserCode:        takeFirst(seed.UserCode, uuid.NewString()[:9]),
+		Scope:           takeFirst(seed.Scope, database.APIKeyScopeAll),
+		PollingInterval: takeFirst(seed.PollingInterval, 5),
+	})
+	require.NoError(t, err, "insert oauth2 device code")
+	return code
+}
+
 func File(t testing.TB, db database.Store, orig database.File) database.File {
 	file, err := db.InsertFile(genCtx, database.InsertFileParams{
 		ID:        takeFirst(orig.ID, uuid.New()),
@@ -439,12 +538,43 @@ func UserLink(t testing.TB, db database.Store, orig database.UserLink) database.
 		OAuthAccessToken:  takeFirst(orig.OAuthAccessToken, uuid.NewString()),
 		OAuthRefreshToken: takeFirst(orig.OAuthAccessToken, uuid.NewString()),
 		OAuthExpiry:       takeFirst(orig.OAuthExpiry, database.Now().Add(time.Hour*24)),
+		OIDCProviderID:    takeFirst(orig.OIDCProviderID),
 	})
 
 	require.NoError(t, err, "insert link")
 	return link
 }
 
+func UserTOTP(t testing.TB, db database.Store, orig database.UserTOTP) database.UserTOTP {
+	totp, err := db.UpsertUserTOTP(genCtx, database.UpsertUserTOTPParams{
+		UserID:    takeFirst(orig.UserID, uuid.New()),
+		Secret:    takeFirst(orig.Secret, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"),
+		CreatedAt: takeFirst(orig.CreatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert user totp")
+	return totp
+}
+
+func UserLoginFailure(t testing.TB, db database.Store, orig database.UserLoginFailure) database.UserLoginFailure {
+	failure, err := db.InsertUserLoginFailure(genCtx, database.InsertUserLoginFailureParams{
+		UserID:       takeFirst(orig.UserID, uuid.New()),
+		LastFailedAt: takeFirst(orig.LastFailedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert user login failure")
+	return failure
+}
+
+func ServiceAccount(t testing.TB, db database.Store, orig database.ServiceAccount) database.ServiceAccount {
+	account, err := db.InsertServiceAccount(genCtx, database.InsertServiceAccountParams{
+		UserID:         takeFirst(orig.UserID, uuid.New()),
+		OrganizationID: takeFirst(orig.OrganizationID, uuid.New()),
+		CreatedBy:      takeFirst(orig.CreatedBy, uuid.New()),
+		CreatedAt:      takeFirst(orig.CreatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert service account")
+	return account
+}
+
 func GitAuthLink(t testing.TB, db database.Store, orig database.GitAuthLink) database.GitAuthLink {
 	link, err := db.InsertGitAuthLink(genCtx, database.InsertGitAuthLinkParams{
 		ProviderID:        takeFirst(orig.ProviderID, uuid.New().String()),
@@ -476,6 +606,52 @@ func TemplateVersion(t testing.TB, db database.Store, orig database.TemplateVers
 	return version
 }
 
+func TemplateGitSource(t testing.TB, db database.Store, orig database.TemplateGitSource) database.TemplateGitSource {
+	source, err := db.UpsertTemplateGitSource(genCtx, database.UpsertTemplateGitSourceParams{
+		TemplateID:        takeFirst(orig.TemplateID, uuid.New()),
+		CreatedAt:         takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:         takeFirst(orig.UpdatedAt, database.Now()),
+		CreatedBy:         takeFirst(orig.CreatedBy, uuid.New()),
+		RepositoryURL:     takeFirst(orig.RepositoryURL, "https://github.com/coder/coder.git"),
+		Ref:               takeFirst(orig.Ref, "main"),
+		Subdirectory:      takeFirst(orig.Subdirectory, ""),
+		GitAuthProviderID: takeFirst(orig.GitAuthProviderID, ""),
+		PollInterval:      takeFirst(orig.PollInterval, 0),
+		WebhookSecret:     takeFirst(orig.WebhookSecret, ""),
+	})
+	require.NoError(t, err, "insert template git source")
+	return source
+}
+
+func TemplateVersionPreset(t testing.TB, db database.Store, orig database.TemplateVersionPreset) database.TemplateVersionPreset {
+	preset, err := db.InsertTemplateVersionPreset(genCtx, database.InsertTemplateVersionPresetParams{
+		ID:                takeFirst(orig.ID, uuid.New()),
+		TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
+		Name:              takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+		Description:       takeFirst(orig.Description, namesgenerator.GetRandomName(1)),
+		CreatedAt:         takeFirst(orig.CreatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert template version preset")
+	return preset
+}
+
+func TemplateVersionRollout(t testing.TB, db database.Store, orig database.TemplateVersionRollout) database.TemplateVersionRollout {
+	rollout, err := db.InsertTemplateVersionRollout(genCtx, database.InsertTemplateVersionRolloutParams{
+		ID:                      takeFirst(orig.ID, uuid.New()),
+		TemplateID:              takeFirst(orig.TemplateID, uuid.New()),
+		TemplateVersionID:       takeFirst(orig.TemplateVersionID, uuid.New()),
+		CreatedBy:               takeFirst(orig.CreatedBy, uuid.New()),
+		CreatedAt:               takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:               takeFirst(orig.UpdatedAt, database.Now()),
+		Percentage:              takeFirst(orig.Percentage, 100),
+		GroupIDs:                takeFirstSlice(orig.GroupIDs, []uuid.UUID{}),
+		BatchSize:               takeFirst(orig.BatchSize, 10),
+		FailureThresholdPercent: takeFirst(orig.FailureThresholdPercent, 20),
+	})
+	require.NoError(t, err, "insert template version rollout")
+	return rollout
+}
+
 func TemplateVersionVariable(t testing.TB, db database.Store, orig database.TemplateVersionVariable) database.TemplateVersionVariable {
 	version, err := db.InsertTemplateVersionVariable(genCtx, database.InsertTemplateVersionVariableParams{
 		TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
@@ -75,6 +75,17 @@ func TestGenerator(t *testing.T) {
 		require.Equal(t, exp, must(db.GetWorkspaceResourceMetadataByResourceIDs(context.Background(), []uuid.UUID{exp[0].WorkspaceResourceID})))
 	})
 
+	t.Run("WorkspacePortShare", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.WorkspacePortShare(t, db, database.WorkspacePortShare{})
+		require.Equal(t, exp, must(db.GetWorkspacePortShare(context.Background(), database.GetWorkspacePortShareParams{
+			WorkspaceID: exp.WorkspaceID,
+			AgentName:   exp.AgentName,
+			Port:        exp.Port,
+		})))
+	})
+
 	t.Run("WorkspaceProxy", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
@@ -153,6 +164,27 @@ func TestGenerator(t *testing.T) {
 		require.Equal(t, exp, must(db.GetTemplateVersionByID(context.Background(), exp.ID)))
 	})
 
+	t.Run("TemplateGitSource", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.TemplateGitSource(t, db, database.TemplateGitSource{})
+		require.Equal(t, exp, must(db.GetTemplateGitSourceByTemplateID(context.Background(), exp.TemplateID)))
+	})
+
+	t.Run("TemplateVersionPreset", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.TemplateVersionPreset(t, db, database.TemplateVersionPreset{})
+		require.Equal(t, exp, must(db.GetTemplateVersionPresetByID(context.Background(), exp.ID)))
+	})
+
+	t.Run("TemplateVersionRollout", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.TemplateVersionRollout(t, db, database.TemplateVersionRollout{})
+		require.Equal(t, exp, must(db.GetTemplateVersionRolloutByID(context.Background(), exp.ID)))
+	})
+
 	t.Run("WorkspaceBuild", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
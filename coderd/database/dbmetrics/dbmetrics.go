@@ -122,6 +122,27 @@ func (m metricsStore) AcquireProvisionerJob(ctx context.Context, arg database.Ac
 	return provisionerJob, err
 }
 
+func (m metricsStore) ArchiveUnusedTemplateVersions(ctx context.Context, arg database.ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	start := time.Now()
+	ids, err := m.s.ArchiveUnusedTemplateVersions(ctx, arg)
+	m.queryLatencies.WithLabelValues("ArchiveUnusedTemplateVersions").Observe(time.Since(start).Seconds())
+	return ids, err
+}
+
+func (m metricsStore) CleanTailnetLostPeers(ctx context.Context, updatedAt time.Time) error {
+	start := time.Now()
+	err := m.s.CleanTailnetLostPeers(ctx, updatedAt)
+	m.queryLatencies.WithLabelValues("CleanTailnetLostPeers").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) CleanTailnetLostTunnels(ctx context.Context, updatedAt time.Time) error {
+	start := time.Now()
+	err := m.s.CleanTailnetLostTunnels(ctx, updatedAt)
+	m.queryLatencies.WithLabelValues("CleanTailnetLostTunnels").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteAPIKeyByID(ctx context.Context, id string) error {
 	start := time.Now()
 	err := m.s.DeleteAPIKeyByID(ctx, id)
@@ -136,6 +157,13 @@ func (m metricsStore) DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUI
 	return err
 }
 
+func (m metricsStore) DeleteAllTailnetTunnels(ctx context.Context, arg database.DeleteAllTailnetTunnelsParams) error {
+	start := time.Now()
+	err := m.s.DeleteAllTailnetTunnels(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteAllTailnetTunnels").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error {
 	start := time.Now()
 	err := m.s.DeleteApplicationConnectAPIKeysByUserID(ctx, userID)
@@ -143,6 +171,48 @@ func (m metricsStore) DeleteApplicationConnectAPIKeysByUserID(ctx context.Contex
 	return err
 }
 
+func (m metricsStore) DeleteDERPRegionByID(ctx context.Context, regionID int32) error {
+	start := time.Now()
+	err := m.s.DeleteDERPRegionByID(ctx, regionID)
+	m.queryLatencies.WithLabelValues("DeleteDERPRegionByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteDERPRegionPolicyByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteDERPRegionPolicyByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteExpiredOAuth2DeviceCodes(ctx context.Context) error {
+	start := time.Now()
+	err := m.s.DeleteExpiredOAuth2DeviceCodes(ctx)
+	m.queryLatencies.WithLabelValues("DeleteExpiredOAuth2DeviceCodes").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteExpiredOAuth2ProviderAppCodes(ctx context.Context) error {
+	start := time.Now()
+	err := m.s.DeleteExpiredOAuth2ProviderAppCodes(ctx)
+	m.queryLatencies.WithLabelValues("DeleteExpiredOAuth2ProviderAppCodes").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteExpiredTailnetCoordinators(ctx context.Context, heartbeatAt time.Time) error {
+	start := time.Now()
+	err := m.s.DeleteExpiredTailnetCoordinators(ctx, heartbeatAt)
+	m.queryLatencies.WithLabelValues("DeleteExpiredTailnetCoordinators").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteExpiredWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) error {
+	start := time.Now()
+	err := m.s.DeleteExpiredWorkspaceAppSecurityKeys(ctx, now)
+	m.queryLatencies.WithLabelValues("DeleteExpiredWorkspaceAppSecurityKeys").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
 	start := time.Now()
 	err := m.s.DeleteGitSSHKey(ctx, userID)
@@ -178,6 +248,55 @@ func (m metricsStore) DeleteLicense(ctx context.Context, id int32) (int32, error
 	return licenseID, err
 }
 
+func (m metricsStore) DeleteOAuth2DeviceCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	start := time.Now()
+	codeID, err := m.s.DeleteOAuth2DeviceCodeByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2DeviceCodeByID").Observe(time.Since(start).Seconds())
+	return codeID, err
+}
+
+func (m metricsStore) DeleteOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteOAuth2ProviderAppByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	start := time.Now()
+	codeID, err := m.s.DeleteOAuth2ProviderAppCodeByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppCodeByID").Observe(time.Since(start).Seconds())
+	return codeID, err
+}
+
+func (m metricsStore) DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Context, arg database.DeleteOAuth2ProviderAppCodesByAppAndUserIDParams) error {
+	start := time.Now()
+	err := m.s.DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppCodesByAppAndUserID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteOAuth2ProviderAppSecretByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppSecretByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteOAuth2ProviderAppTokenByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	start := time.Now()
+	tokenID, err := m.s.DeleteOAuth2ProviderAppTokenByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppTokenByID").Observe(time.Since(start).Seconds())
+	return tokenID, err
+}
+
+func (m metricsStore) DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Context, arg database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams) error {
+	start := time.Now()
+	err := m.s.DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppTokensByAppAndUserID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteOldWorkspaceAgentStartupLogs(ctx context.Context) error {
 	start := time.Now()
 	err := m.s.DeleteOldWorkspaceAgentStartupLogs(ctx)
@@ -192,6 +311,13 @@ func (m metricsStore) DeleteOldWorkspaceAgentStats(ctx context.Context) error {
 	return err
 }
 
+func (m metricsStore) DeleteOldWorkspaceAppUsageRollups(ctx context.Context) error {
+	start := time.Now()
+	err := m.s.DeleteOldWorkspaceAppUsageRollups(ctx)
+	m.queryLatencies.WithLabelValues("DeleteOldWorkspaceAppUsageRollups").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
 	start := time.Now()
 	err := m.s.DeleteReplicasUpdatedBefore(ctx, updatedAt)
@@ -199,6 +325,76 @@ func (m metricsStore) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt
 	return err
 }
 
+func (m metricsStore) DeleteSessionAPIKeysByUserID(ctx context.Context, arg database.DeleteSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	start := time.Now()
+	keys, err := m.s.DeleteSessionAPIKeysByUserID(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteSessionAPIKeysByUserID").Observe(time.Since(start).Seconds())
+	return keys, err
+}
+
+func (m metricsStore) DeleteTailnetCoordinator(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteTailnetCoordinator(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteTailnetCoordinator").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteTailnetPeer(ctx context.Context, arg database.DeleteTailnetPeerParams) (database.DeleteTailnetPeerRow, error) {
+	start := time.Now()
+	deleteTailnetPeerRow, err := m.s.DeleteTailnetPeer(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteTailnetPeer").Observe(time.Since(start).Seconds())
+	return deleteTailnetPeerRow, err
+}
+
+func (m metricsStore) DeleteTailnetTunnel(ctx context.Context, arg database.DeleteTailnetTunnelParams) (database.DeleteTailnetTunnelRow, error) {
+	start := time.Now()
+	deleteTailnetTunnelRow, err := m.s.DeleteTailnetTunnel(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteTailnetTunnel").Observe(time.Since(start).Seconds())
+	return deleteTailnetTunnelRow, err
+}
+
+func (m metricsStore) DeleteTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteTemplateGitSourceByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("DeleteTemplateGitSourceByTemplateID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteTemplateVersionPresetsByTemplateVersionID(ctx, templateVersionID)
+	m.queryLatencies.WithLabelValues("DeleteTemplateVersionPresetsByTemplateVersionID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteUserLoginFailures(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteUserLoginFailures(ctx, userID)
+	m.queryLatencies.WithLabelValues("DeleteUserLoginFailures").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteUserTOTP(ctx, userID)
+	m.queryLatencies.WithLabelValues("DeleteUserTOTP").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteWorkspacePortShare(ctx context.Context, arg database.DeleteWorkspacePortShareParams) error {
+	start := time.Now()
+	err := m.s.DeleteWorkspacePortShare(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteWorkspacePortShare").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) ExpireWorkspaceAppSecurityKeys(ctx context.Context, arg database.ExpireWorkspaceAppSecurityKeysParams) error {
+	start := time.Now()
+	err := m.s.ExpireWorkspaceAppSecurityKeys(ctx, arg)
+	m.queryLatencies.WithLabelValues("ExpireWorkspaceAppSecurityKeys").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) GetAPIKeyByID(ctx context.Context, id string) (database.APIKey, error) {
 	start := time.Now()
 	apiKey, err := m.s.GetAPIKeyByID(ctx, id)
@@ -213,6 +409,13 @@ func (m metricsStore) GetAPIKeyByName(ctx context.Context, arg database.GetAPIKe
 	return apiKey, err
 }
 
+func (m metricsStore) GetAPIKeyImpersonationByAPIKeyID(ctx context.Context, apiKeyID string) (database.GetAPIKeyImpersonationByAPIKeyIDRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAPIKeyImpersonationByAPIKeyID(ctx, apiKeyID)
+	m.queryLatencies.WithLabelValues("GetAPIKeyImpersonationByAPIKeyID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetAPIKeysByLoginType(ctx context.Context, loginType database.LoginType) ([]database.APIKey, error) {
 	start := time.Now()
 	apiKeys, err := m.s.GetAPIKeysByLoginType(ctx, loginType)
@@ -241,13 +444,6 @@ func (m metricsStore) GetActiveUserCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
-func (m metricsStore) GetAppSecurityKey(ctx context.Context) (string, error) {
-	start := time.Now()
-	key, err := m.s.GetAppSecurityKey(ctx)
-	m.queryLatencies.WithLabelValues("GetAppSecurityKey").Observe(time.Since(start).Seconds())
-	return key, err
-}
-
 func (m metricsStore) GetAuditLogsOffset(ctx context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	start := time.Now()
 	rows, err := m.s.GetAuditLogsOffset(ctx, arg)
@@ -269,6 +465,48 @@ func (m metricsStore) GetDERPMeshKey(ctx context.Context) (string, error) {
 	return key, err
 }
 
+func (m metricsStore) GetDERPRegionByID(ctx context.Context, regionID int32) (database.DERPRegion, error) {
+	start := time.Now()
+	region, err := m.s.GetDERPRegionByID(ctx, regionID)
+	m.queryLatencies.WithLabelValues("GetDERPRegionByID").Observe(time.Since(start).Seconds())
+	return region, err
+}
+
+func (m metricsStore) GetDERPRegionPolicies(ctx context.Context) ([]database.DERPRegionPolicy, error) {
+	start := time.Now()
+	policies, err := m.s.GetDERPRegionPolicies(ctx)
+	m.queryLatencies.WithLabelValues("GetDERPRegionPolicies").Observe(time.Since(start).Seconds())
+	return policies, err
+}
+
+func (m metricsStore) GetDERPRegionPoliciesByUserID(ctx context.Context, userID uuid.UUID) ([]database.DERPRegionPolicy, error) {
+	start := time.Now()
+	policies, err := m.s.GetDERPRegionPoliciesByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetDERPRegionPoliciesByUserID").Observe(time.Since(start).Seconds())
+	return policies, err
+}
+
+func (m metricsStore) GetDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) (database.DERPRegionPolicy, error) {
+	start := time.Now()
+	policy, err := m.s.GetDERPRegionPolicyByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetDERPRegionPolicyByID").Observe(time.Since(start).Seconds())
+	return policy, err
+}
+
+func (m metricsStore) GetDERPRegionPolicyByTemplateID(ctx context.Context, templateID uuid.NullUUID) (database.DERPRegionPolicy, error) {
+	start := time.Now()
+	policy, err := m.s.GetDERPRegionPolicyByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetDERPRegionPolicyByTemplateID").Observe(time.Since(start).Seconds())
+	return policy, err
+}
+
+func (m metricsStore) GetDERPRegions(ctx context.Context) ([]database.DERPRegion, error) {
+	start := time.Now()
+	regions, err := m.s.GetDERPRegions(ctx)
+	m.queryLatencies.WithLabelValues("GetDERPRegions").Observe(time.Since(start).Seconds())
+	return regions, err
+}
+
 func (m metricsStore) GetDefaultProxyConfig(ctx context.Context) (database.GetDefaultProxyConfigRow, error) {
 	start := time.Now()
 	resp, err := m.s.GetDefaultProxyConfig(ctx)
@@ -423,6 +661,83 @@ func (m metricsStore) GetLogoURL(ctx context.Context) (string, error) {
 	return url, err
 }
 
+func (m metricsStore) GetOAuth2DeviceCodeByPrefix(ctx context.Context, secretPrefix []byte) (database.OAuth2DeviceCode, error) {
+	start := time.Now()
+	oAuth2DeviceCode, err := m.s.GetOAuth2DeviceCodeByPrefix(ctx, secretPrefix)
+	m.queryLatencies.WithLabelValues("GetOAuth2DeviceCodeByPrefix").Observe(time.Since(start).Seconds())
+	return oAuth2DeviceCode, err
+}
+
+func (m metricsStore) GetOAuth2DeviceCodeByUserCode(ctx context.Context, userCode string) (database.OAuth2DeviceCode, error) {
+	start := time.Now()
+	oAuth2DeviceCode, err := m.s.GetOAuth2DeviceCodeByUserCode(ctx, userCode)
+	m.queryLatencies.WithLabelValues("GetOAuth2DeviceCodeByUserCode").Observe(time.Since(start).Seconds())
+	return oAuth2DeviceCode, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderApp, error) {
+	start := time.Now()
+	oAuth2ProviderApp, err := m.s.GetOAuth2ProviderAppByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppByID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderApp, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppCode, error) {
+	start := time.Now()
+	oAuth2ProviderAppCode, err := m.s.GetOAuth2ProviderAppCodeByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppCodeByID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppCode, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secretPrefix []byte) (database.OAuth2ProviderAppCode, error) {
+	start := time.Now()
+	oAuth2ProviderAppCode, err := m.s.GetOAuth2ProviderAppCodeByPrefix(ctx, secretPrefix)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppCodeByPrefix").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppCode, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
+	start := time.Now()
+	oAuth2ProviderAppSecret, err := m.s.GetOAuth2ProviderAppSecretByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppSecretByID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppSecret, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppSecretByPrefix(ctx context.Context, secretPrefix []byte) (database.OAuth2ProviderAppSecret, error) {
+	start := time.Now()
+	oAuth2ProviderAppSecret, err := m.s.GetOAuth2ProviderAppSecretByPrefix(ctx, secretPrefix)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppSecretByPrefix").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppSecret, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppSecretsByAppID(ctx context.Context, appID uuid.UUID) ([]database.OAuth2ProviderAppSecret, error) {
+	start := time.Now()
+	oAuth2ProviderAppSecrets, err := m.s.GetOAuth2ProviderAppSecretsByAppID(ctx, appID)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppSecretsByAppID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppSecrets, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppTokenByPrefix(ctx context.Context, hashPrefix []byte) (database.OAuth2ProviderAppToken, error) {
+	start := time.Now()
+	oAuth2ProviderAppToken, err := m.s.GetOAuth2ProviderAppTokenByPrefix(ctx, hashPrefix)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppTokenByPrefix").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppToken, err
+}
+
+func (m metricsStore) GetOAuth2ProviderApps(ctx context.Context) ([]database.OAuth2ProviderApp, error) {
+	start := time.Now()
+	oAuth2ProviderApps, err := m.s.GetOAuth2ProviderApps(ctx)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderApps").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderApps, err
+}
+
+func (m metricsStore) GetOAuth2ProviderAppsByUserID(ctx context.Context, userID uuid.UUID) ([]database.OAuth2ProviderApp, error) {
+	start := time.Now()
+	oAuth2ProviderApps, err := m.s.GetOAuth2ProviderAppsByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppsByUserID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderApps, err
+}
+
 func (m metricsStore) GetOrganizationByID(ctx context.Context, id uuid.UUID) (database.Organization, error) {
 	start := time.Now()
 	organization, err := m.s.GetOrganizationByID(ctx, id)
@@ -542,6 +857,13 @@ func (m metricsStore) GetReplicasUpdatedAfter(ctx context.Context, updatedAt tim
 	return replicas, err
 }
 
+func (m metricsStore) GetRunningTemplateVersionRollouts(ctx context.Context) ([]database.TemplateVersionRollout, error) {
+	start := time.Now()
+	rollouts, err := m.s.GetRunningTemplateVersionRollouts(ctx)
+	m.queryLatencies.WithLabelValues("GetRunningTemplateVersionRollouts").Observe(time.Since(start).Seconds())
+	return rollouts, err
+}
+
 func (m metricsStore) GetServiceBanner(ctx context.Context) (string, error) {
 	start := time.Now()
 	banner, err := m.s.GetServiceBanner(ctx)
@@ -549,6 +871,48 @@ func (m metricsStore) GetServiceBanner(ctx context.Context) (string, error) {
 	return banner, err
 }
 
+func (m metricsStore) GetSessionAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]database.APIKey, error) {
+	start := time.Now()
+	aPIKeys, err := m.s.GetSessionAPIKeysByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetSessionAPIKeysByUserID").Observe(time.Since(start).Seconds())
+	return aPIKeys, err
+}
+
+func (m metricsStore) GetTailnetCoordinators(ctx context.Context) ([]database.TailnetCoordinator, error) {
+	start := time.Now()
+	coordinators, err := m.s.GetTailnetCoordinators(ctx)
+	m.queryLatencies.WithLabelValues("GetTailnetCoordinators").Observe(time.Since(start).Seconds())
+	return coordinators, err
+}
+
+func (m metricsStore) GetTailnetPeers(ctx context.Context, id uuid.UUID) ([]database.TailnetPeer, error) {
+	start := time.Now()
+	peers, err := m.s.GetTailnetPeers(ctx, id)
+	m.queryLatencies.WithLabelValues("GetTailnetPeers").Observe(time.Since(start).Seconds())
+	return peers, err
+}
+
+func (m metricsStore) GetTailnetTunnelPeerBindings(ctx context.Context, srcID uuid.UUID) ([]database.GetTailnetTunnelPeerBindingsRow, error) {
+	start := time.Now()
+	bindings, err := m.s.GetTailnetTunnelPeerBindings(ctx, srcID)
+	m.queryLatencies.WithLabelValues("GetTailnetTunnelPeerBindings").Observe(time.Since(start).Seconds())
+	return bindings, err
+}
+
+func (m metricsStore) GetTailnetTunnelPeerIDs(ctx context.Context, srcID uuid.UUID) ([]database.GetTailnetTunnelPeerIDsRow, error) {
+	start := time.Now()
+	ids, err := m.s.GetTailnetTunnelPeerIDs(ctx, srcID)
+	m.queryLatencies.WithLabelValues("GetTailnetTunnelPeerIDs").Observe(time.Since(start).Seconds())
+	return ids, err
+}
+
+func (m metricsStore) GetTemplateActiveVersionApps(ctx context.Context, templateIDs []uuid.UUID) ([]database.GetTemplateActiveVersionAppsRow, error) {
+	start := time.Now()
+	apps, err := m.s.GetTemplateActiveVersionApps(ctx, templateIDs)
+	m.queryLatencies.WithLabelValues("GetTemplateActiveVersionApps").Observe(time.Since(start).Seconds())
+	return apps, err
+}
+
 func (m metricsStore) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
 	start := time.Now()
 	buildTime, err := m.s.GetTemplateAverageBuildTime(ctx, arg)
@@ -577,6 +941,20 @@ func (m metricsStore) GetTemplateDAUs(ctx context.Context, arg database.GetTempl
 	return daus, err
 }
 
+func (m metricsStore) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSource, error) {
+	start := time.Now()
+	source, err := m.s.GetTemplateGitSourceByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetTemplateGitSourceByTemplateID").Observe(time.Since(start).Seconds())
+	return source, err
+}
+
+func (m metricsStore) GetTemplateGitSources(ctx context.Context) ([]database.TemplateGitSource, error) {
+	start := time.Now()
+	sources, err := m.s.GetTemplateGitSources(ctx)
+	m.queryLatencies.WithLabelValues("GetTemplateGitSources").Observe(time.Since(start).Seconds())
+	return sources, err
+}
+
 func (m metricsStore) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (database.TemplateVersion, error) {
 	start := time.Now()
 	version, err := m.s.GetTemplateVersionByID(ctx, id)
@@ -605,6 +983,55 @@ func (m metricsStore) GetTemplateVersionParameters(ctx context.Context, template
 	return parameters, err
 }
 
+func (m metricsStore) GetTemplateVersionPresetByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionPreset, error) {
+	start := time.Now()
+	preset, err := m.s.GetTemplateVersionPresetByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionPresetByID").Observe(time.Since(start).Seconds())
+	return preset, err
+}
+
+func (m metricsStore) GetTemplateVersionPresetParametersByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPresetParameter, error) {
+	start := time.Now()
+	parameters, err := m.s.GetTemplateVersionPresetParametersByTemplateVersionID(ctx, templateVersionID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionPresetParametersByTemplateVersionID").Observe(time.Since(start).Seconds())
+	return parameters, err
+}
+
+func (m metricsStore) GetTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	start := time.Now()
+	presets, err := m.s.GetTemplateVersionPresetsByTemplateVersionID(ctx, templateVersionID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionPresetsByTemplateVersionID").Observe(time.Since(start).Seconds())
+	return presets, err
+}
+
+func (m metricsStore) GetTemplateVersionRolloutByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionRollout, error) {
+	start := time.Now()
+	rollout, err := m.s.GetTemplateVersionRolloutByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionRolloutByID").Observe(time.Since(start).Seconds())
+	return rollout, err
+}
+
+func (m metricsStore) GetTemplateVersionRolloutCandidates(ctx context.Context, arg database.GetTemplateVersionRolloutCandidatesParams) ([]uuid.UUID, error) {
+	start := time.Now()
+	ids, err := m.s.GetTemplateVersionRolloutCandidates(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionRolloutCandidates").Observe(time.Since(start).Seconds())
+	return ids, err
+}
+
+func (m metricsStore) GetTemplateVersionRolloutWorkspacesByRolloutID(ctx context.Context, rolloutID uuid.UUID) ([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error) {
+	start := time.Now()
+	workspaces, err := m.s.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rolloutID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionRolloutWorkspacesByRolloutID").Observe(time.Since(start).Seconds())
+	return workspaces, err
+}
+
+func (m metricsStore) GetTemplateVersionRolloutsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.TemplateVersionRollout, error) {
+	start := time.Now()
+	rollouts, err := m.s.GetTemplateVersionRolloutsByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionRolloutsByTemplateID").Observe(time.Since(start).Seconds())
+	return rollouts, err
+}
+
 func (m metricsStore) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	start := time.Now()
 	variables, err := m.s.GetTemplateVersionVariables(ctx, templateVersionID)
@@ -689,6 +1116,20 @@ func (m metricsStore) GetUserLinkByUserIDLoginType(ctx context.Context, arg data
 	return link, err
 }
 
+func (m metricsStore) GetUserLoginFailuresByUserID(ctx context.Context, userID uuid.UUID) (database.UserLoginFailure, error) {
+	start := time.Now()
+	userLoginFailure, err := m.s.GetUserLoginFailuresByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetUserLoginFailuresByUserID").Observe(time.Since(start).Seconds())
+	return userLoginFailure, err
+}
+
+func (m metricsStore) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	start := time.Now()
+	userTOTP, err := m.s.GetUserTOTPByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetUserTOTPByUserID").Observe(time.Since(start).Seconds())
+	return userTOTP, err
+}
+
 func (m metricsStore) GetUsers(ctx context.Context, arg database.GetUsersParams) ([]database.GetUsersRow, error) {
 	start := time.Now()
 	users, err := m.s.GetUsers(ctx, arg)
@@ -787,6 +1228,20 @@ func (m metricsStore) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg d
 	return app, err
 }
 
+func (m metricsStore) GetWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) ([]database.WorkspaceAppSecurityKey, error) {
+	start := time.Now()
+	keys, err := m.s.GetWorkspaceAppSecurityKeys(ctx, now)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAppSecurityKeys").Observe(time.Since(start).Seconds())
+	return keys, err
+}
+
+func (m metricsStore) GetWorkspaceAppUsageInsights(ctx context.Context, arg database.GetWorkspaceAppUsageInsightsParams) ([]database.GetWorkspaceAppUsageInsightsRow, error) {
+	start := time.Now()
+	insights, err := m.s.GetWorkspaceAppUsageInsights(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAppUsageInsights").Observe(time.Since(start).Seconds())
+	return insights, err
+}
+
 func (m metricsStore) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]database.WorkspaceApp, error) {
 	start := time.Now()
 	apps, err := m.s.GetWorkspaceAppsByAgentID(ctx, agentID)
@@ -878,6 +1333,20 @@ func (m metricsStore) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspac
 	return workspace, err
 }
 
+func (m metricsStore) GetWorkspacePortShare(ctx context.Context, arg database.GetWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	start := time.Now()
+	share, err := m.s.GetWorkspacePortShare(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspacePortShare").Observe(time.Since(start).Seconds())
+	return share, err
+}
+
+func (m metricsStore) GetWorkspacePortSharesByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]database.WorkspacePortShare, error) {
+	start := time.Now()
+	shares, err := m.s.GetWorkspacePortSharesByWorkspaceID(ctx, workspaceID)
+	m.queryLatencies.WithLabelValues("GetWorkspacePortSharesByWorkspaceID").Observe(time.Since(start).Seconds())
+	return shares, err
+}
+
 func (m metricsStore) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
 	start := time.Now()
 	proxies, err := m.s.GetWorkspaceProxies(ctx)
@@ -969,6 +1438,13 @@ func (m metricsStore) InsertAPIKey(ctx context.Context, arg database.InsertAPIKe
 	return key, err
 }
 
+func (m metricsStore) InsertAPIKeyImpersonation(ctx context.Context, arg database.InsertAPIKeyImpersonationParams) (database.APIKeyImpersonation, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertAPIKeyImpersonation(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertAPIKeyImpersonation").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (database.Group, error) {
 	start := time.Now()
 	group, err := m.s.InsertAllUsersGroup(ctx, organizationID)
@@ -990,6 +1466,20 @@ func (m metricsStore) InsertDERPMeshKey(ctx context.Context, value string) error
 	return err
 }
 
+func (m metricsStore) InsertDERPRegion(ctx context.Context, arg database.InsertDERPRegionParams) (database.DERPRegion, error) {
+	start := time.Now()
+	region, err := m.s.InsertDERPRegion(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertDERPRegion").Observe(time.Since(start).Seconds())
+	return region, err
+}
+
+func (m metricsStore) InsertDERPRegionPolicy(ctx context.Context, arg database.InsertDERPRegionPolicyParams) (database.DERPRegionPolicy, error) {
+	start := time.Now()
+	policy, err := m.s.InsertDERPRegionPolicy(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertDERPRegionPolicy").Observe(time.Since(start).Seconds())
+	return policy, err
+}
+
 func (m metricsStore) InsertDeploymentID(ctx context.Context, value string) error {
 	start := time.Now()
 	err := m.s.InsertDeploymentID(ctx, value)
@@ -1039,6 +1529,41 @@ func (m metricsStore) InsertLicense(ctx context.Context, arg database.InsertLice
 	return license, err
 }
 
+func (m metricsStore) InsertOAuth2DeviceCode(ctx context.Context, arg database.InsertOAuth2DeviceCodeParams) (database.OAuth2DeviceCode, error) {
+	start := time.Now()
+	oAuth2DeviceCode, err := m.s.InsertOAuth2DeviceCode(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertOAuth2DeviceCode").Observe(time.Since(start).Seconds())
+	return oAuth2DeviceCode, err
+}
+
+func (m metricsStore) InsertOAuth2ProviderApp(ctx context.Context, arg database.InsertOAuth2ProviderAppParams) (database.OAuth2ProviderApp, error) {
+	start := time.Now()
+	oAuth2ProviderApp, err := m.s.InsertOAuth2ProviderApp(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertOAuth2ProviderApp").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderApp, err
+}
+
+func (m metricsStore) InsertOAuth2ProviderAppCode(ctx context.Context, arg database.InsertOAuth2ProviderAppCodeParams) (database.OAuth2ProviderAppCode, error) {
+	start := time.Now()
+	oAuth2ProviderAppCode, err := m.s.InsertOAuth2ProviderAppCode(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertOAuth2ProviderAppCode").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppCode, err
+}
+
+func (m metricsStore) InsertOAuth2ProviderAppSecret(ctx context.Context, arg database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
+	start := time.Now()
+	oAuth2ProviderAppSecret, err := m.s.InsertOAuth2ProviderAppSecret(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertOAuth2ProviderAppSecret").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppSecret, err
+}
+
+func (m metricsStore) InsertOAuth2ProviderAppToken(ctx context.Context, arg database.InsertOAuth2ProviderAppTokenParams) (database.OAuth2ProviderAppToken, error) {
+	start := time.Now()
+	oAuth2ProviderAppToken, err := m.s.InsertOAuth2ProviderAppToken(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertOAuth2ProviderAppToken").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppToken, err
+}
+
 func (m metricsStore) InsertOrganization(ctx context.Context, arg database.InsertOrganizationParams) (database.Organization, error) {
 	start := time.Now()
 	organization, err := m.s.InsertOrganization(ctx, arg)
@@ -1081,6 +1606,13 @@ func (m metricsStore) InsertReplica(ctx context.Context, arg database.InsertRepl
 	return replica, err
 }
 
+func (m metricsStore) InsertServiceAccount(ctx context.Context, arg database.InsertServiceAccountParams) (database.ServiceAccount, error) {
+	start := time.Now()
+	account, err := m.s.InsertServiceAccount(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertServiceAccount").Observe(time.Since(start).Seconds())
+	return account, err
+}
+
 func (m metricsStore) InsertTemplate(ctx context.Context, arg database.InsertTemplateParams) (database.Template, error) {
 	start := time.Now()
 	template, err := m.s.InsertTemplate(ctx, arg)
@@ -1102,6 +1634,34 @@ func (m metricsStore) InsertTemplateVersionParameter(ctx context.Context, arg da
 	return parameter, err
 }
 
+func (m metricsStore) InsertTemplateVersionPreset(ctx context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	start := time.Now()
+	preset, err := m.s.InsertTemplateVersionPreset(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionPreset").Observe(time.Since(start).Seconds())
+	return preset, err
+}
+
+func (m metricsStore) InsertTemplateVersionPresetParameters(ctx context.Context, arg database.InsertTemplateVersionPresetParametersParams) error {
+	start := time.Now()
+	err := m.s.InsertTemplateVersionPresetParameters(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionPresetParameters").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) InsertTemplateVersionRollout(ctx context.Context, arg database.InsertTemplateVersionRolloutParams) (database.TemplateVersionRollout, error) {
+	start := time.Now()
+	rollout, err := m.s.InsertTemplateVersionRollout(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionRollout").Observe(time.Since(start).Seconds())
+	return rollout, err
+}
+
+func (m metricsStore) InsertTemplateVersionRolloutWorkspace(ctx context.Context, arg database.InsertTemplateVersionRolloutWorkspaceParams) error {
+	start := time.Now()
+	err := m.s.InsertTemplateVersionRolloutWorkspace(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionRolloutWorkspace").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) InsertTemplateVersionVariable(ctx context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	start := time.Now()
 	variable, err := m.s.InsertTemplateVersionVariable(ctx, arg)
@@ -1130,6 +1690,13 @@ func (m metricsStore) InsertUserLink(ctx context.Context, arg database.InsertUse
 	return link, err
 }
 
+func (m metricsStore) InsertUserLoginFailure(ctx context.Context, arg database.InsertUserLoginFailureParams) (database.UserLoginFailure, error) {
+	start := time.Now()
+	userLoginFailure, err := m.s.InsertUserLoginFailure(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertUserLoginFailure").Observe(time.Since(start).Seconds())
+	return userLoginFailure, err
+}
+
 func (m metricsStore) InsertWorkspace(ctx context.Context, arg database.InsertWorkspaceParams) (database.Workspace, error) {
 	start := time.Now()
 	workspace, err := m.s.InsertWorkspace(ctx, arg)
@@ -1172,6 +1739,13 @@ func (m metricsStore) InsertWorkspaceApp(ctx context.Context, arg database.Inser
 	return app, err
 }
 
+func (m metricsStore) InsertWorkspaceAppSecurityKey(ctx context.Context, arg database.InsertWorkspaceAppSecurityKeyParams) (database.WorkspaceAppSecurityKey, error) {
+	start := time.Now()
+	key, err := m.s.InsertWorkspaceAppSecurityKey(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAppSecurityKey").Observe(time.Since(start).Seconds())
+	return key, err
+}
+
 func (m metricsStore) InsertWorkspaceBuild(ctx context.Context, arg database.InsertWorkspaceBuildParams) (database.WorkspaceBuild, error) {
 	start := time.Now()
 	build, err := m.s.InsertWorkspaceBuild(ctx, arg)
@@ -1228,6 +1802,27 @@ func (m metricsStore) UpdateAPIKeyByID(ctx context.Context, arg database.UpdateA
 	return err
 }
 
+func (m metricsStore) UpdateDERPRegionByID(ctx context.Context, arg database.UpdateDERPRegionByIDParams) (database.DERPRegion, error) {
+	start := time.Now()
+	region, err := m.s.UpdateDERPRegionByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateDERPRegionByID").Observe(time.Since(start).Seconds())
+	return region, err
+}
+
+func (m metricsStore) UpdateDERPRegionHealthByID(ctx context.Context, arg database.UpdateDERPRegionHealthByIDParams) (database.DERPRegion, error) {
+	start := time.Now()
+	region, err := m.s.UpdateDERPRegionHealthByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateDERPRegionHealthByID").Observe(time.Since(start).Seconds())
+	return region, err
+}
+
+func (m metricsStore) UpdateDERPRegionPolicyByID(ctx context.Context, arg database.UpdateDERPRegionPolicyByIDParams) (database.DERPRegionPolicy, error) {
+	start := time.Now()
+	policy, err := m.s.UpdateDERPRegionPolicyByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateDERPRegionPolicyByID").Observe(time.Since(start).Seconds())
+	return policy, err
+}
+
 func (m metricsStore) UpdateGitAuthLink(ctx context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	start := time.Now()
 	link, err := m.s.UpdateGitAuthLink(ctx, arg)
@@ -1256,6 +1851,34 @@ func (m metricsStore) UpdateMemberRoles(ctx context.Context, arg database.Update
 	return member, err
 }
 
+func (m metricsStore) UpdateOAuth2DeviceCodePolledByID(ctx context.Context, arg database.UpdateOAuth2DeviceCodePolledByIDParams) (database.OAuth2DeviceCode, error) {
+	start := time.Now()
+	oAuth2DeviceCode, err := m.s.UpdateOAuth2DeviceCodePolledByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOAuth2DeviceCodePolledByID").Observe(time.Since(start).Seconds())
+	return oAuth2DeviceCode, err
+}
+
+func (m metricsStore) UpdateOAuth2DeviceCodeStatusByID(ctx context.Context, arg database.UpdateOAuth2DeviceCodeStatusByIDParams) (database.OAuth2DeviceCode, error) {
+	start := time.Now()
+	oAuth2DeviceCode, err := m.s.UpdateOAuth2DeviceCodeStatusByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOAuth2DeviceCodeStatusByID").Observe(time.Since(start).Seconds())
+	return oAuth2DeviceCode, err
+}
+
+func (m metricsStore) UpdateOAuth2ProviderAppByID(ctx context.Context, arg database.UpdateOAuth2ProviderAppByIDParams) (database.OAuth2ProviderApp, error) {
+	start := time.Now()
+	oAuth2ProviderApp, err := m.s.UpdateOAuth2ProviderAppByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOAuth2ProviderAppByID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderApp, err
+}
+
+func (m metricsStore) UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
+	start := time.Now()
+	oAuth2ProviderAppSecret, err := m.s.UpdateOAuth2ProviderAppSecretByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOAuth2ProviderAppSecretByID").Observe(time.Since(start).Seconds())
+	return oAuth2ProviderAppSecret, err
+}
+
 func (m metricsStore) UpdateProvisionerJobByID(ctx context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateProvisionerJobByID(ctx, arg)
@@ -1284,6 +1907,13 @@ func (m metricsStore) UpdateReplica(ctx context.Context, arg database.UpdateRepl
 	return replica, err
 }
 
+func (m metricsStore) UpdateTailnetPeerStatusByCoordinator(ctx context.Context, arg database.UpdateTailnetPeerStatusByCoordinatorParams) error {
+	start := time.Now()
+	err := m.s.UpdateTailnetPeerStatusByCoordinator(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTailnetPeerStatusByCoordinator").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateTemplateACLByID(ctx context.Context, arg database.UpdateTemplateACLByIDParams) (database.Template, error) {
 	start := time.Now()
 	template, err := m.s.UpdateTemplateACLByID(ctx, arg)
@@ -1305,6 +1935,13 @@ func (m metricsStore) UpdateTemplateDeletedByID(ctx context.Context, arg databas
 	return err
 }
 
+func (m metricsStore) UpdateTemplateGitSourceSyncStatus(ctx context.Context, arg database.UpdateTemplateGitSourceSyncStatusParams) error {
+	start := time.Now()
+	err := m.s.UpdateTemplateGitSourceSyncStatus(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateGitSourceSyncStatus").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateTemplateMetaByID(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 	start := time.Now()
 	template, err := m.s.UpdateTemplateMetaByID(ctx, arg)
@@ -1340,6 +1977,27 @@ func (m metricsStore) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.C
 	return err
 }
 
+func (m metricsStore) UpdateTemplateVersionRolloutStatusByID(ctx context.Context, arg database.UpdateTemplateVersionRolloutStatusByIDParams) (database.TemplateVersionRollout, error) {
+	start := time.Now()
+	rollout, err := m.s.UpdateTemplateVersionRolloutStatusByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateVersionRolloutStatusByID").Observe(time.Since(start).Seconds())
+	return rollout, err
+}
+
+func (m metricsStore) UpdateTemplateVersionRolloutWorkspace(ctx context.Context, arg database.UpdateTemplateVersionRolloutWorkspaceParams) error {
+	start := time.Now()
+	err := m.s.UpdateTemplateVersionRolloutWorkspace(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateVersionRolloutWorkspace").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) UpdateTemplateVersionStateByID(ctx context.Context, arg database.UpdateTemplateVersionStateByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateTemplateVersionStateByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateVersionStateByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateUserDeletedByID(ctx context.Context, arg database.UpdateUserDeletedByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateUserDeletedByID(ctx, arg)
@@ -1375,6 +2033,13 @@ func (m metricsStore) UpdateUserLinkedID(ctx context.Context, arg database.Updat
 	return link, err
 }
 
+func (m metricsStore) UpdateUserLoginLockout(ctx context.Context, arg database.UpdateUserLoginLockoutParams) (database.UserLoginFailure, error) {
+	start := time.Now()
+	userLoginFailure, err := m.s.UpdateUserLoginLockout(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateUserLoginLockout").Observe(time.Since(start).Seconds())
+	return userLoginFailure, err
+}
+
 func (m metricsStore) UpdateUserProfile(ctx context.Context, arg database.UpdateUserProfileParams) (database.User, error) {
 	start := time.Now()
 	user, err := m.s.UpdateUserProfile(ctx, arg)
@@ -1396,6 +2061,13 @@ func (m metricsStore) UpdateUserStatus(ctx context.Context, arg database.UpdateU
 	return user, err
 }
 
+func (m metricsStore) UpdateUserTOTP(ctx context.Context, arg database.UpdateUserTOTPParams) (database.UserTOTP, error) {
+	start := time.Now()
+	userTOTP, err := m.s.UpdateUserTOTP(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateUserTOTP").Observe(time.Since(start).Seconds())
+	return userTOTP, err
+}
+
 func (m metricsStore) UpdateWorkspace(ctx context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 	start := time.Now()
 	workspace, err := m.s.UpdateWorkspace(ctx, arg)
@@ -1424,6 +2096,13 @@ func (m metricsStore) UpdateWorkspaceAgentMetadata(ctx context.Context, arg data
 	return err
 }
 
+func (m metricsStore) UpdateWorkspaceAgentPeerConnectionsByID(ctx context.Context, arg database.UpdateWorkspaceAgentPeerConnectionsByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateWorkspaceAgentPeerConnectionsByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceAgentPeerConnectionsByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceAgentStartupByID(ctx, arg)
@@ -1508,13 +2187,6 @@ func (m metricsStore) UpdateWorkspaceTTLToBeWithinTemplateMax(ctx context.Contex
 	return r0
 }
 
-func (m metricsStore) UpsertAppSecurityKey(ctx context.Context, value string) error {
-	start := time.Now()
-	r0 := m.s.UpsertAppSecurityKey(ctx, value)
-	m.queryLatencies.WithLabelValues("UpsertAppSecurityKey").Observe(time.Since(start).Seconds())
-	return r0
-}
-
 func (m metricsStore) UpsertDefaultProxy(ctx context.Context, arg database.UpsertDefaultProxyParams) error {
 	start := time.Now()
 	r0 := m.s.UpsertDefaultProxy(ctx, arg)
@@ -1542,3 +2214,52 @@ func (m metricsStore) UpsertServiceBanner(ctx context.Context, value string) err
 	m.queryLatencies.WithLabelValues("UpsertServiceBanner").Observe(time.Since(start).Seconds())
 	return r0
 }
+
+func (m metricsStore) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (database.TailnetCoordinator, error) {
+	start := time.Now()
+	coordinator, err := m.s.UpsertTailnetCoordinator(ctx, id)
+	m.queryLatencies.WithLabelValues("UpsertTailnetCoordinator").Observe(time.Since(start).Seconds())
+	return coordinator, err
+}
+
+func (m metricsStore) UpsertTailnetPeer(ctx context.Context, arg database.UpsertTailnetPeerParams) (database.TailnetPeer, error) {
+	start := time.Now()
+	peer, err := m.s.UpsertTailnetPeer(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertTailnetPeer").Observe(time.Since(start).Seconds())
+	return peer, err
+}
+
+func (m metricsStore) UpsertTailnetTunnel(ctx context.Context, arg database.UpsertTailnetTunnelParams) (database.TailnetTunnel, error) {
+	start := time.Now()
+	tunnel, err := m.s.UpsertTailnetTunnel(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertTailnetTunnel").Observe(time.Since(start).Seconds())
+	return tunnel, err
+}
+
+func (m metricsStore) UpsertTemplateGitSource(ctx context.Context, arg database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	start := time.Now()
+	source, err := m.s.UpsertTemplateGitSource(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertTemplateGitSource").Observe(time.Since(start).Seconds())
+	return source, err
+}
+
+func (m metricsStore) UpsertUserTOTP(ctx context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	start := time.Now()
+	userTOTP, err := m.s.UpsertUserTOTP(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertUserTOTP").Observe(time.Since(start).Seconds())
+	return userTOTP, err
+}
+
+func (m metricsStore) UpsertWorkspaceAppUsageRollup(ctx context.Context, arg database.UpsertWorkspaceAppUsageRollupParams) error {
+	start := time.Now()
+	err := m.s.UpsertWorkspaceAppUsageRollup(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceAppUsageRollup").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) UpsertWorkspacePortShare(ctx context.Context, arg database.UpsertWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	start := time.Now()
+	share, err := m.s.UpsertWorkspacePortShare(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspacePortShare").Observe(time.Since(start).Seconds())
+	return share, err
+}
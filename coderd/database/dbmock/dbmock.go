@@ -68,6 +68,49 @@ func (mr *MockStoreMockRecorder) AcquireProvisionerJob(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireProvisionerJob", reflect.TypeOf((*MockStore)(nil).AcquireProvisionerJob), arg0, arg1)
 }
 
+// ArchiveUnusedTemplateVersions mocks base method.
+func (m *MockStore) ArchiveUnusedTemplateVersions(arg0 context.Context, arg1 database.ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveUnusedTemplateVersions", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveUnusedTemplateVersions indicates an expected call of ArchiveUnusedTemplateVersions.
+func (mr *MockStoreMockRecorder) ArchiveUnusedTemplateVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveUnusedTemplateVersions", reflect.TypeOf((*MockStore)(nil).ArchiveUnusedTemplateVersions), arg0, arg1)
+}
+
+// CleanTailnetLostPeers mocks base method.
+func (m *MockStore) CleanTailnetLostPeers(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanTailnetLostPeers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanTailnetLostPeers indicates an expected call of CleanTailnetLostPeers.
+func (mr *MockStoreMockRecorder) CleanTailnetLostPeers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanTailnetLostPeers", reflect.TypeOf((*MockStore)(nil).CleanTailnetLostPeers), arg0, arg1)
+}
+
+// CleanTailnetLostTunnels mocks base method.
+func (m *MockStore) CleanTailnetLostTunnels(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanTailnetLostTunnels", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanTailnetLostTunnels indicates an expected call of CleanTailnetLostTunnels.
+func (mr *MockStoreMockRecorder) CleanTailnetLostTunnels(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanTailnetLostTunnels", reflect.TypeOf((*MockStore)(nil).CleanTailnetLostTunnels), arg0, arg1)
+}
+
 // DeleteAPIKeyByID mocks base method.
 func (m *MockStore) DeleteAPIKeyByID(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -96,6 +139,20 @@ func (mr *MockStoreMockRecorder) DeleteAPIKeysByUserID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).DeleteAPIKeysByUserID), arg0, arg1)
 }
 
+// DeleteAllTailnetTunnels mocks base method.
+func (m *MockStore) DeleteAllTailnetTunnels(arg0 context.Context, arg1 database.DeleteAllTailnetTunnelsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllTailnetTunnels", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllTailnetTunnels indicates an expected call of DeleteAllTailnetTunnels.
+func (mr *MockStoreMockRecorder) DeleteAllTailnetTunnels(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllTailnetTunnels", reflect.TypeOf((*MockStore)(nil).DeleteAllTailnetTunnels), arg0, arg1)
+}
+
 // DeleteApplicationConnectAPIKeysByUserID mocks base method.
 func (m *MockStore) DeleteApplicationConnectAPIKeysByUserID(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -110,6 +167,90 @@ func (mr *MockStoreMockRecorder) DeleteApplicationConnectAPIKeysByUserID(arg0, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplicationConnectAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).DeleteApplicationConnectAPIKeysByUserID), arg0, arg1)
 }
 
+// DeleteDERPRegionByID mocks base method.
+func (m *MockStore) DeleteDERPRegionByID(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDERPRegionByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDERPRegionByID indicates an expected call of DeleteDERPRegionByID.
+func (mr *MockStoreMockRecorder) DeleteDERPRegionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDERPRegionByID", reflect.TypeOf((*MockStore)(nil).DeleteDERPRegionByID), arg0, arg1)
+}
+
+// DeleteDERPRegionPolicyByID mocks base method.
+func (m *MockStore) DeleteDERPRegionPolicyByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDERPRegionPolicyByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDERPRegionPolicyByID indicates an expected call of DeleteDERPRegionPolicyByID.
+func (mr *MockStoreMockRecorder) DeleteDERPRegionPolicyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDERPRegionPolicyByID", reflect.TypeOf((*MockStore)(nil).DeleteDERPRegionPolicyByID), arg0, arg1)
+}
+
+// DeleteExpiredOAuth2DeviceCodes mocks base method.
+func (m *MockStore) DeleteExpiredOAuth2DeviceCodes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredOAuth2DeviceCodes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredOAuth2DeviceCodes indicates an expected call of DeleteExpiredOAuth2DeviceCodes.
+func (mr *MockStoreMockRecorder) DeleteExpiredOAuth2DeviceCodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredOAuth2DeviceCodes", reflect.TypeOf((*MockStore)(nil).DeleteExpiredOAuth2DeviceCodes), arg0)
+}
+
+// DeleteExpiredOAuth2ProviderAppCodes mocks base method.
+func (m *MockStore) DeleteExpiredOAuth2ProviderAppCodes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredOAuth2ProviderAppCodes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredOAuth2ProviderAppCodes indicates an expected call of DeleteExpiredOAuth2ProviderAppCodes.
+func (mr *MockStoreMockRecorder) DeleteExpiredOAuth2ProviderAppCodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredOAuth2ProviderAppCodes", reflect.TypeOf((*MockStore)(nil).DeleteExpiredOAuth2ProviderAppCodes), arg0)
+}
+
+// DeleteExpiredTailnetCoordinators mocks base method.
+func (m *MockStore) DeleteExpiredTailnetCoordinators(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTailnetCoordinators", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredTailnetCoordinators indicates an expected call of DeleteExpiredTailnetCoordinators.
+func (mr *MockStoreMockRecorder) DeleteExpiredTailnetCoordinators(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTailnetCoordinators", reflect.TypeOf((*MockStore)(nil).DeleteExpiredTailnetCoordinators), arg0, arg1)
+}
+
+// DeleteExpiredWorkspaceAppSecurityKeys mocks base method.
+func (m *MockStore) DeleteExpiredWorkspaceAppSecurityKeys(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredWorkspaceAppSecurityKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredWorkspaceAppSecurityKeys indicates an expected call of DeleteExpiredWorkspaceAppSecurityKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredWorkspaceAppSecurityKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredWorkspaceAppSecurityKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredWorkspaceAppSecurityKeys), arg0, arg1)
+}
+
 // DeleteGitSSHKey mocks base method.
 func (m *MockStore) DeleteGitSSHKey(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -181,6 +322,107 @@ func (mr *MockStoreMockRecorder) DeleteLicense(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLicense", reflect.TypeOf((*MockStore)(nil).DeleteLicense), arg0, arg1)
 }
 
+// DeleteOAuth2DeviceCodeByID mocks base method.
+func (m *MockStore) DeleteOAuth2DeviceCodeByID(arg0 context.Context, arg1 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2DeviceCodeByID", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOAuth2DeviceCodeByID indicates an expected call of DeleteOAuth2DeviceCodeByID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2DeviceCodeByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2DeviceCodeByID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2DeviceCodeByID), arg0, arg1)
+}
+
+// DeleteOAuth2ProviderAppByID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2ProviderAppByID indicates an expected call of DeleteOAuth2ProviderAppByID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppByID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppByID), arg0, arg1)
+}
+
+// DeleteOAuth2ProviderAppCodeByID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppCodeByID(arg0 context.Context, arg1 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppCodeByID", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOAuth2ProviderAppCodeByID indicates an expected call of DeleteOAuth2ProviderAppCodeByID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppCodeByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppCodeByID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppCodeByID), arg0, arg1)
+}
+
+// DeleteOAuth2ProviderAppCodesByAppAndUserID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppCodesByAppAndUserID(arg0 context.Context, arg1 database.DeleteOAuth2ProviderAppCodesByAppAndUserIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppCodesByAppAndUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2ProviderAppCodesByAppAndUserID indicates an expected call of DeleteOAuth2ProviderAppCodesByAppAndUserID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppCodesByAppAndUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppCodesByAppAndUserID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppCodesByAppAndUserID), arg0, arg1)
+}
+
+// DeleteOAuth2ProviderAppSecretByID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppSecretByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppSecretByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2ProviderAppSecretByID indicates an expected call of DeleteOAuth2ProviderAppSecretByID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppSecretByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppSecretByID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppSecretByID), arg0, arg1)
+}
+
+// DeleteOAuth2ProviderAppTokenByID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppTokenByID(arg0 context.Context, arg1 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppTokenByID", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOAuth2ProviderAppTokenByID indicates an expected call of DeleteOAuth2ProviderAppTokenByID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppTokenByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppTokenByID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppTokenByID), arg0, arg1)
+}
+
+// DeleteOAuth2ProviderAppTokensByAppAndUserID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppTokensByAppAndUserID(arg0 context.Context, arg1 database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppTokensByAppAndUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2ProviderAppTokensByAppAndUserID indicates an expected call of DeleteOAuth2ProviderAppTokensByAppAndUserID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppTokensByAppAndUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppTokensByAppAndUserID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppTokensByAppAndUserID), arg0, arg1)
+}
+
 // DeleteOldWorkspaceAgentStartupLogs mocks base method.
 func (m *MockStore) DeleteOldWorkspaceAgentStartupLogs(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -209,6 +451,20 @@ func (mr *MockStoreMockRecorder) DeleteOldWorkspaceAgentStats(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceAgentStats", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceAgentStats), arg0)
 }
 
+// DeleteOldWorkspaceAppUsageRollups mocks base method.
+func (m *MockStore) DeleteOldWorkspaceAppUsageRollups(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldWorkspaceAppUsageRollups", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOldWorkspaceAppUsageRollups indicates an expected call of DeleteOldWorkspaceAppUsageRollups.
+func (mr *MockStoreMockRecorder) DeleteOldWorkspaceAppUsageRollups(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceAppUsageRollups", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceAppUsageRollups), arg0)
+}
+
 // DeleteReplicasUpdatedBefore mocks base method.
 func (m *MockStore) DeleteReplicasUpdatedBefore(arg0 context.Context, arg1 time.Time) error {
 	m.ctrl.T.Helper()
@@ -223,6 +479,149 @@ func (mr *MockStoreMockRecorder) DeleteReplicasUpdatedBefore(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReplicasUpdatedBefore", reflect.TypeOf((*MockStore)(nil).DeleteReplicasUpdatedBefore), arg0, arg1)
 }
 
+// DeleteSessionAPIKeysByUserID mocks base method.
+func (m *MockStore) DeleteSessionAPIKeysByUserID(arg0 context.Context, arg1 database.DeleteSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionAPIKeysByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSessionAPIKeysByUserID indicates an expected call of DeleteSessionAPIKeysByUserID.
+func (mr *MockStoreMockRecorder) DeleteSessionAPIKeysByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).DeleteSessionAPIKeysByUserID), arg0, arg1)
+}
+
+// DeleteTailnetCoordinator mocks base method.
+func (m *MockStore) DeleteTailnetCoordinator(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTailnetCoordinator", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTailnetCoordinator indicates an expected call of DeleteTailnetCoordinator.
+func (mr *MockStoreMockRecorder) DeleteTailnetCoordinator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTailnetCoordinator", reflect.TypeOf((*MockStore)(nil).DeleteTailnetCoordinator), arg0, arg1)
+}
+
+// DeleteTailnetPeer mocks base method.
+func (m *MockStore) DeleteTailnetPeer(arg0 context.Context, arg1 database.DeleteTailnetPeerParams) (database.DeleteTailnetPeerRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTailnetPeer", arg0, arg1)
+	ret0, _ := ret[0].(database.DeleteTailnetPeerRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTailnetPeer indicates an expected call of DeleteTailnetPeer.
+func (mr *MockStoreMockRecorder) DeleteTailnetPeer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTailnetPeer", reflect.TypeOf((*MockStore)(nil).DeleteTailnetPeer), arg0, arg1)
+}
+
+// DeleteTailnetTunnel mocks base method.
+func (m *MockStore) DeleteTailnetTunnel(arg0 context.Context, arg1 database.DeleteTailnetTunnelParams) (database.DeleteTailnetTunnelRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTailnetTunnel", arg0, arg1)
+	ret0, _ := ret[0].(database.DeleteTailnetTunnelRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTailnetTunnel indicates an expected call of DeleteTailnetTunnel.
+func (mr *MockStoreMockRecorder) DeleteTailnetTunnel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTailnetTunnel", reflect.TypeOf((*MockStore)(nil).DeleteTailnetTunnel), arg0, arg1)
+}
+
+// DeleteTemplateGitSourceByTemplateID mocks base method.
+func (m *MockStore) DeleteTemplateGitSourceByTemplateID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplateGitSourceByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplateGitSourceByTemplateID indicates an expected call of DeleteTemplateGitSourceByTemplateID.
+func (mr *MockStoreMockRecorder) DeleteTemplateGitSourceByTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplateGitSourceByTemplateID", reflect.TypeOf((*MockStore)(nil).DeleteTemplateGitSourceByTemplateID), arg0, arg1)
+}
+
+// DeleteTemplateVersionPresetsByTemplateVersionID mocks base method.
+func (m *MockStore) DeleteTemplateVersionPresetsByTemplateVersionID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplateVersionPresetsByTemplateVersionID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplateVersionPresetsByTemplateVersionID indicates an expected call of DeleteTemplateVersionPresetsByTemplateVersionID.
+func (mr *MockStoreMockRecorder) DeleteTemplateVersionPresetsByTemplateVersionID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplateVersionPresetsByTemplateVersionID", reflect.TypeOf((*MockStore)(nil).DeleteTemplateVersionPresetsByTemplateVersionID), arg0, arg1)
+}
+
+// DeleteUserLoginFailures mocks base method.
+func (m *MockStore) DeleteUserLoginFailures(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserLoginFailures indicates an expected call of DeleteUserLoginFailures.
+func (mr *MockStoreMockRecorder) DeleteUserLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserLoginFailures", reflect.TypeOf((*MockStore)(nil).DeleteUserLoginFailures), arg0, arg1)
+}
+
+// DeleteUserTOTP mocks base method.
+func (m *MockStore) DeleteUserTOTP(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTOTP indicates an expected call of DeleteUserTOTP.
+func (mr *MockStoreMockRecorder) DeleteUserTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTOTP", reflect.TypeOf((*MockStore)(nil).DeleteUserTOTP), arg0, arg1)
+}
+
+// DeleteWorkspacePortShare mocks base method.
+func (m *MockStore) DeleteWorkspacePortShare(arg0 context.Context, arg1 database.DeleteWorkspacePortShareParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspacePortShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspacePortShare indicates an expected call of DeleteWorkspacePortShare.
+func (mr *MockStoreMockRecorder) DeleteWorkspacePortShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspacePortShare", reflect.TypeOf((*MockStore)(nil).DeleteWorkspacePortShare), arg0, arg1)
+}
+
+// ExpireWorkspaceAppSecurityKeys mocks base method.
+func (m *MockStore) ExpireWorkspaceAppSecurityKeys(arg0 context.Context, arg1 database.ExpireWorkspaceAppSecurityKeysParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireWorkspaceAppSecurityKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireWorkspaceAppSecurityKeys indicates an expected call of ExpireWorkspaceAppSecurityKeys.
+func (mr *MockStoreMockRecorder) ExpireWorkspaceAppSecurityKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireWorkspaceAppSecurityKeys", reflect.TypeOf((*MockStore)(nil).ExpireWorkspaceAppSecurityKeys), arg0, arg1)
+}
+
 // GetAPIKeyByID mocks base method.
 func (m *MockStore) GetAPIKeyByID(arg0 context.Context, arg1 string) (database.APIKey, error) {
 	m.ctrl.T.Helper()
@@ -253,6 +652,21 @@ func (mr *MockStoreMockRecorder) GetAPIKeyByName(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByName", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByName), arg0, arg1)
 }
 
+// GetAPIKeyImpersonationByAPIKeyID mocks base method.
+func (m *MockStore) GetAPIKeyImpersonationByAPIKeyID(arg0 context.Context, arg1 string) (database.GetAPIKeyImpersonationByAPIKeyIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyImpersonationByAPIKeyID", arg0, arg1)
+	ret0, _ := ret[0].(database.GetAPIKeyImpersonationByAPIKeyIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyImpersonationByAPIKeyID indicates an expected call of GetAPIKeyImpersonationByAPIKeyID.
+func (mr *MockStoreMockRecorder) GetAPIKeyImpersonationByAPIKeyID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyImpersonationByAPIKeyID", reflect.TypeOf((*MockStore)(nil).GetAPIKeyImpersonationByAPIKeyID), arg0, arg1)
+}
+
 // GetAPIKeysByLoginType mocks base method.
 func (m *MockStore) GetAPIKeysByLoginType(arg0 context.Context, arg1 database.LoginType) ([]database.APIKey, error) {
 	m.ctrl.T.Helper()
@@ -313,21 +727,6 @@ func (mr *MockStoreMockRecorder) GetActiveUserCount(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserCount", reflect.TypeOf((*MockStore)(nil).GetActiveUserCount), arg0)
 }
 
-// GetAppSecurityKey mocks base method.
-func (m *MockStore) GetAppSecurityKey(arg0 context.Context) (string, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAppSecurityKey", arg0)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetAppSecurityKey indicates an expected call of GetAppSecurityKey.
-func (mr *MockStoreMockRecorder) GetAppSecurityKey(arg0 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppSecurityKey", reflect.TypeOf((*MockStore)(nil).GetAppSecurityKey), arg0)
-}
-
 // GetAuditLogsOffset mocks base method.
 func (m *MockStore) GetAuditLogsOffset(arg0 context.Context, arg1 database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	m.ctrl.T.Helper()
@@ -418,6 +817,96 @@ func (mr *MockStoreMockRecorder) GetDERPMeshKey(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPMeshKey", reflect.TypeOf((*MockStore)(nil).GetDERPMeshKey), arg0)
 }
 
+// GetDERPRegionByID mocks base method.
+func (m *MockStore) GetDERPRegionByID(arg0 context.Context, arg1 int32) (database.DERPRegion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPRegionByID", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPRegionByID indicates an expected call of GetDERPRegionByID.
+func (mr *MockStoreMockRecorder) GetDERPRegionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPRegionByID", reflect.TypeOf((*MockStore)(nil).GetDERPRegionByID), arg0, arg1)
+}
+
+// GetDERPRegionPolicies mocks base method.
+func (m *MockStore) GetDERPRegionPolicies(arg0 context.Context) ([]database.DERPRegionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPRegionPolicies", arg0)
+	ret0, _ := ret[0].([]database.DERPRegionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPRegionPolicies indicates an expected call of GetDERPRegionPolicies.
+func (mr *MockStoreMockRecorder) GetDERPRegionPolicies(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPRegionPolicies", reflect.TypeOf((*MockStore)(nil).GetDERPRegionPolicies), arg0)
+}
+
+// GetDERPRegionPoliciesByUserID mocks base method.
+func (m *MockStore) GetDERPRegionPoliciesByUserID(arg0 context.Context, arg1 uuid.UUID) ([]database.DERPRegionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPRegionPoliciesByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.DERPRegionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPRegionPoliciesByUserID indicates an expected call of GetDERPRegionPoliciesByUserID.
+func (mr *MockStoreMockRecorder) GetDERPRegionPoliciesByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPRegionPoliciesByUserID", reflect.TypeOf((*MockStore)(nil).GetDERPRegionPoliciesByUserID), arg0, arg1)
+}
+
+// GetDERPRegionPolicyByID mocks base method.
+func (m *MockStore) GetDERPRegionPolicyByID(arg0 context.Context, arg1 uuid.UUID) (database.DERPRegionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPRegionPolicyByID", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPRegionPolicyByID indicates an expected call of GetDERPRegionPolicyByID.
+func (mr *MockStoreMockRecorder) GetDERPRegionPolicyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPRegionPolicyByID", reflect.TypeOf((*MockStore)(nil).GetDERPRegionPolicyByID), arg0, arg1)
+}
+
+// GetDERPRegionPolicyByTemplateID mocks base method.
+func (m *MockStore) GetDERPRegionPolicyByTemplateID(arg0 context.Context, arg1 uuid.NullUUID) (database.DERPRegionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPRegionPolicyByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPRegionPolicyByTemplateID indicates an expected call of GetDERPRegionPolicyByTemplateID.
+func (mr *MockStoreMockRecorder) GetDERPRegionPolicyByTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPRegionPolicyByTemplateID", reflect.TypeOf((*MockStore)(nil).GetDERPRegionPolicyByTemplateID), arg0, arg1)
+}
+
+// GetDERPRegions mocks base method.
+func (m *MockStore) GetDERPRegions(arg0 context.Context) ([]database.DERPRegion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPRegions", arg0)
+	ret0, _ := ret[0].([]database.DERPRegion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPRegions indicates an expected call of GetDERPRegions.
+func (mr *MockStoreMockRecorder) GetDERPRegions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPRegions", reflect.TypeOf((*MockStore)(nil).GetDERPRegions), arg0)
+}
+
 // GetDefaultProxyConfig mocks base method.
 func (m *MockStore) GetDefaultProxyConfig(arg0 context.Context) (database.GetDefaultProxyConfigRow, error) {
 	m.ctrl.T.Helper()
@@ -736,16 +1225,181 @@ func (mr *MockStoreMockRecorder) GetLicenses(arg0 interface{}) *gomock.Call {
 // GetLogoURL mocks base method.
 func (m *MockStore) GetLogoURL(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLogoURL", arg0)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetLogoURL", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogoURL indicates an expected call of GetLogoURL.
+func (mr *MockStoreMockRecorder) GetLogoURL(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogoURL", reflect.TypeOf((*MockStore)(nil).GetLogoURL), arg0)
+}
+
+// GetOAuth2DeviceCodeByPrefix mocks base method.
+func (m *MockStore) GetOAuth2DeviceCodeByPrefix(arg0 context.Context, arg1 []byte) (database.OAuth2DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2DeviceCodeByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2DeviceCodeByPrefix indicates an expected call of GetOAuth2DeviceCodeByPrefix.
+func (mr *MockStoreMockRecorder) GetOAuth2DeviceCodeByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2DeviceCodeByPrefix", reflect.TypeOf((*MockStore)(nil).GetOAuth2DeviceCodeByPrefix), arg0, arg1)
+}
+
+// GetOAuth2DeviceCodeByUserCode mocks base method.
+func (m *MockStore) GetOAuth2DeviceCodeByUserCode(arg0 context.Context, arg1 string) (database.OAuth2DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2DeviceCodeByUserCode", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2DeviceCodeByUserCode indicates an expected call of GetOAuth2DeviceCodeByUserCode.
+func (mr *MockStoreMockRecorder) GetOAuth2DeviceCodeByUserCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2DeviceCodeByUserCode", reflect.TypeOf((*MockStore)(nil).GetOAuth2DeviceCodeByUserCode), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppByID mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppByID(arg0 context.Context, arg1 uuid.UUID) (database.OAuth2ProviderApp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderApp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppByID indicates an expected call of GetOAuth2ProviderAppByID.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppByID", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppByID), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppCodeByID mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppCodeByID(arg0 context.Context, arg1 uuid.UUID) (database.OAuth2ProviderAppCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppCodeByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppCodeByID indicates an expected call of GetOAuth2ProviderAppCodeByID.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppCodeByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppCodeByID", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppCodeByID), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppCodeByPrefix mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppCodeByPrefix(arg0 context.Context, arg1 []byte) (database.OAuth2ProviderAppCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppCodeByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppCodeByPrefix indicates an expected call of GetOAuth2ProviderAppCodeByPrefix.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppCodeByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppCodeByPrefix", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppCodeByPrefix), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppSecretByID mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppSecretByID(arg0 context.Context, arg1 uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppSecretByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppSecretByID indicates an expected call of GetOAuth2ProviderAppSecretByID.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppSecretByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppSecretByID", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppSecretByID), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppSecretByPrefix mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppSecretByPrefix(arg0 context.Context, arg1 []byte) (database.OAuth2ProviderAppSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppSecretByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppSecretByPrefix indicates an expected call of GetOAuth2ProviderAppSecretByPrefix.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppSecretByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppSecretByPrefix", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppSecretByPrefix), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppSecretsByAppID mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppSecretsByAppID(arg0 context.Context, arg1 uuid.UUID) ([]database.OAuth2ProviderAppSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppSecretsByAppID", arg0, arg1)
+	ret0, _ := ret[0].([]database.OAuth2ProviderAppSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppSecretsByAppID indicates an expected call of GetOAuth2ProviderAppSecretsByAppID.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppSecretsByAppID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppSecretsByAppID", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppSecretsByAppID), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppTokenByPrefix mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppTokenByPrefix(arg0 context.Context, arg1 []byte) (database.OAuth2ProviderAppToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppTokenByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppTokenByPrefix indicates an expected call of GetOAuth2ProviderAppTokenByPrefix.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppTokenByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppTokenByPrefix", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppTokenByPrefix), arg0, arg1)
+}
+
+// GetOAuth2ProviderApps mocks base method.
+func (m *MockStore) GetOAuth2ProviderApps(arg0 context.Context) ([]database.OAuth2ProviderApp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderApps", arg0)
+	ret0, _ := ret[0].([]database.OAuth2ProviderApp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderApps indicates an expected call of GetOAuth2ProviderApps.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderApps(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderApps", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderApps), arg0)
+}
+
+// GetOAuth2ProviderAppsByUserID mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]database.OAuth2ProviderApp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.OAuth2ProviderApp)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLogoURL indicates an expected call of GetLogoURL.
-func (mr *MockStoreMockRecorder) GetLogoURL(arg0 interface{}) *gomock.Call {
+// GetOAuth2ProviderAppsByUserID indicates an expected call of GetOAuth2ProviderAppsByUserID.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppsByUserID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogoURL", reflect.TypeOf((*MockStore)(nil).GetLogoURL), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppsByUserID", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppsByUserID), arg0, arg1)
 }
 
 // GetOrganizationByID mocks base method.
@@ -1003,6 +1657,21 @@ func (mr *MockStoreMockRecorder) GetReplicasUpdatedAfter(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicasUpdatedAfter", reflect.TypeOf((*MockStore)(nil).GetReplicasUpdatedAfter), arg0, arg1)
 }
 
+// GetRunningTemplateVersionRollouts mocks base method.
+func (m *MockStore) GetRunningTemplateVersionRollouts(arg0 context.Context) ([]database.TemplateVersionRollout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunningTemplateVersionRollouts", arg0)
+	ret0, _ := ret[0].([]database.TemplateVersionRollout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunningTemplateVersionRollouts indicates an expected call of GetRunningTemplateVersionRollouts.
+func (mr *MockStoreMockRecorder) GetRunningTemplateVersionRollouts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningTemplateVersionRollouts", reflect.TypeOf((*MockStore)(nil).GetRunningTemplateVersionRollouts), arg0)
+}
+
 // GetServiceBanner mocks base method.
 func (m *MockStore) GetServiceBanner(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -1018,6 +1687,96 @@ func (mr *MockStoreMockRecorder) GetServiceBanner(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceBanner", reflect.TypeOf((*MockStore)(nil).GetServiceBanner), arg0)
 }
 
+// GetSessionAPIKeysByUserID mocks base method.
+func (m *MockStore) GetSessionAPIKeysByUserID(arg0 context.Context, arg1 uuid.UUID) ([]database.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionAPIKeysByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionAPIKeysByUserID indicates an expected call of GetSessionAPIKeysByUserID.
+func (mr *MockStoreMockRecorder) GetSessionAPIKeysByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).GetSessionAPIKeysByUserID), arg0, arg1)
+}
+
+// GetTailnetCoordinators mocks base method.
+func (m *MockStore) GetTailnetCoordinators(arg0 context.Context) ([]database.TailnetCoordinator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTailnetCoordinators", arg0)
+	ret0, _ := ret[0].([]database.TailnetCoordinator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTailnetCoordinators indicates an expected call of GetTailnetCoordinators.
+func (mr *MockStoreMockRecorder) GetTailnetCoordinators(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTailnetCoordinators", reflect.TypeOf((*MockStore)(nil).GetTailnetCoordinators), arg0)
+}
+
+// GetTailnetPeers mocks base method.
+func (m *MockStore) GetTailnetPeers(arg0 context.Context, arg1 uuid.UUID) ([]database.TailnetPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTailnetPeers", arg0, arg1)
+	ret0, _ := ret[0].([]database.TailnetPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTailnetPeers indicates an expected call of GetTailnetPeers.
+func (mr *MockStoreMockRecorder) GetTailnetPeers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTailnetPeers", reflect.TypeOf((*MockStore)(nil).GetTailnetPeers), arg0, arg1)
+}
+
+// GetTailnetTunnelPeerBindings mocks base method.
+func (m *MockStore) GetTailnetTunnelPeerBindings(arg0 context.Context, arg1 uuid.UUID) ([]database.GetTailnetTunnelPeerBindingsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTailnetTunnelPeerBindings", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTailnetTunnelPeerBindingsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTailnetTunnelPeerBindings indicates an expected call of GetTailnetTunnelPeerBindings.
+func (mr *MockStoreMockRecorder) GetTailnetTunnelPeerBindings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTailnetTunnelPeerBindings", reflect.TypeOf((*MockStore)(nil).GetTailnetTunnelPeerBindings), arg0, arg1)
+}
+
+// GetTailnetTunnelPeerIDs mocks base method.
+func (m *MockStore) GetTailnetTunnelPeerIDs(arg0 context.Context, arg1 uuid.UUID) ([]database.GetTailnetTunnelPeerIDsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTailnetTunnelPeerIDs", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTailnetTunnelPeerIDsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTailnetTunnelPeerIDs indicates an expected call of GetTailnetTunnelPeerIDs.
+func (mr *MockStoreMockRecorder) GetTailnetTunnelPeerIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTailnetTunnelPeerIDs", reflect.TypeOf((*MockStore)(nil).GetTailnetTunnelPeerIDs), arg0, arg1)
+}
+
+// GetTemplateActiveVersionApps mocks base method.
+func (m *MockStore) GetTemplateActiveVersionApps(arg0 context.Context, arg1 []uuid.UUID) ([]database.GetTemplateActiveVersionAppsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateActiveVersionApps", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTemplateActiveVersionAppsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateActiveVersionApps indicates an expected call of GetTemplateActiveVersionApps.
+func (mr *MockStoreMockRecorder) GetTemplateActiveVersionApps(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateActiveVersionApps", reflect.TypeOf((*MockStore)(nil).GetTemplateActiveVersionApps), arg0, arg1)
+}
+
 // GetTemplateAverageBuildTime mocks base method.
 func (m *MockStore) GetTemplateAverageBuildTime(arg0 context.Context, arg1 database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
 	m.ctrl.T.Helper()
@@ -1078,6 +1837,36 @@ func (mr *MockStoreMockRecorder) GetTemplateDAUs(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateDAUs", reflect.TypeOf((*MockStore)(nil).GetTemplateDAUs), arg0, arg1)
 }
 
+// GetTemplateGitSourceByTemplateID mocks base method.
+func (m *MockStore) GetTemplateGitSourceByTemplateID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateGitSourceByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateGitSourceByTemplateID indicates an expected call of GetTemplateGitSourceByTemplateID.
+func (mr *MockStoreMockRecorder) GetTemplateGitSourceByTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateGitSourceByTemplateID", reflect.TypeOf((*MockStore)(nil).GetTemplateGitSourceByTemplateID), arg0, arg1)
+}
+
+// GetTemplateGitSources mocks base method.
+func (m *MockStore) GetTemplateGitSources(arg0 context.Context) ([]database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateGitSources", arg0)
+	ret0, _ := ret[0].([]database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateGitSources indicates an expected call of GetTemplateGitSources.
+func (mr *MockStoreMockRecorder) GetTemplateGitSources(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateGitSources", reflect.TypeOf((*MockStore)(nil).GetTemplateGitSources), arg0)
+}
+
 // GetTemplateGroupRoles mocks base method.
 func (m *MockStore) GetTemplateGroupRoles(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateGroup, error) {
 	m.ctrl.T.Helper()
@@ -1168,6 +1957,111 @@ func (mr *MockStoreMockRecorder) GetTemplateVersionParameters(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionParameters", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionParameters), arg0, arg1)
 }
 
+// GetTemplateVersionPresetByID mocks base method.
+func (m *MockStore) GetTemplateVersionPresetByID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionPresetByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionPresetByID indicates an expected call of GetTemplateVersionPresetByID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionPresetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionPresetByID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionPresetByID), arg0, arg1)
+}
+
+// GetTemplateVersionPresetParametersByTemplateVersionID mocks base method.
+func (m *MockStore) GetTemplateVersionPresetParametersByTemplateVersionID(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionPresetParameter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionPresetParametersByTemplateVersionID", arg0, arg1)
+	ret0, _ := ret[0].([]database.TemplateVersionPresetParameter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionPresetParametersByTemplateVersionID indicates an expected call of GetTemplateVersionPresetParametersByTemplateVersionID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionPresetParametersByTemplateVersionID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionPresetParametersByTemplateVersionID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionPresetParametersByTemplateVersionID), arg0, arg1)
+}
+
+// GetTemplateVersionPresetsByTemplateVersionID mocks base method.
+func (m *MockStore) GetTemplateVersionPresetsByTemplateVersionID(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionPresetsByTemplateVersionID", arg0, arg1)
+	ret0, _ := ret[0].([]database.TemplateVersionPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionPresetsByTemplateVersionID indicates an expected call of GetTemplateVersionPresetsByTemplateVersionID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionPresetsByTemplateVersionID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionPresetsByTemplateVersionID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionPresetsByTemplateVersionID), arg0, arg1)
+}
+
+// GetTemplateVersionRolloutByID mocks base method.
+func (m *MockStore) GetTemplateVersionRolloutByID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionRollout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionRolloutByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionRollout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionRolloutByID indicates an expected call of GetTemplateVersionRolloutByID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionRolloutByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionRolloutByID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionRolloutByID), arg0, arg1)
+}
+
+// GetTemplateVersionRolloutCandidates mocks base method.
+func (m *MockStore) GetTemplateVersionRolloutCandidates(arg0 context.Context, arg1 database.GetTemplateVersionRolloutCandidatesParams) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionRolloutCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionRolloutCandidates indicates an expected call of GetTemplateVersionRolloutCandidates.
+func (mr *MockStoreMockRecorder) GetTemplateVersionRolloutCandidates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionRolloutCandidates", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionRolloutCandidates), arg0, arg1)
+}
+
+// GetTemplateVersionRolloutWorkspacesByRolloutID mocks base method.
+func (m *MockStore) GetTemplateVersionRolloutWorkspacesByRolloutID(arg0 context.Context, arg1 uuid.UUID) ([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionRolloutWorkspacesByRolloutID", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionRolloutWorkspacesByRolloutID indicates an expected call of GetTemplateVersionRolloutWorkspacesByRolloutID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionRolloutWorkspacesByRolloutID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionRolloutWorkspacesByRolloutID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionRolloutWorkspacesByRolloutID), arg0, arg1)
+}
+
+// GetTemplateVersionRolloutsByTemplateID mocks base method.
+func (m *MockStore) GetTemplateVersionRolloutsByTemplateID(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionRollout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionRolloutsByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].([]database.TemplateVersionRollout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionRolloutsByTemplateID indicates an expected call of GetTemplateVersionRolloutsByTemplateID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionRolloutsByTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionRolloutsByTemplateID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionRolloutsByTemplateID), arg0, arg1)
+}
+
 // GetTemplateVersionVariables mocks base method.
 func (m *MockStore) GetTemplateVersionVariables(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	m.ctrl.T.Helper()
@@ -1348,6 +2242,36 @@ func (mr *MockStoreMockRecorder) GetUserLinkByUserIDLoginType(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLinkByUserIDLoginType", reflect.TypeOf((*MockStore)(nil).GetUserLinkByUserIDLoginType), arg0, arg1)
 }
 
+// GetUserLoginFailuresByUserID mocks base method.
+func (m *MockStore) GetUserLoginFailuresByUserID(arg0 context.Context, arg1 uuid.UUID) (database.UserLoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLoginFailuresByUserID", arg0, arg1)
+	ret0, _ := ret[0].(database.UserLoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLoginFailuresByUserID indicates an expected call of GetUserLoginFailuresByUserID.
+func (mr *MockStoreMockRecorder) GetUserLoginFailuresByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLoginFailuresByUserID", reflect.TypeOf((*MockStore)(nil).GetUserLoginFailuresByUserID), arg0, arg1)
+}
+
+// GetUserTOTPByUserID mocks base method.
+func (m *MockStore) GetUserTOTPByUserID(arg0 context.Context, arg1 uuid.UUID) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTPByUserID", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTPByUserID indicates an expected call of GetUserTOTPByUserID.
+func (mr *MockStoreMockRecorder) GetUserTOTPByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTPByUserID", reflect.TypeOf((*MockStore)(nil).GetUserTOTPByUserID), arg0, arg1)
+}
+
 // GetUsers mocks base method.
 func (m *MockStore) GetUsers(arg0 context.Context, arg1 database.GetUsersParams) ([]database.GetUsersRow, error) {
 	m.ctrl.T.Helper()
@@ -1558,6 +2482,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAppByAgentIDAndSlug(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAppByAgentIDAndSlug", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAppByAgentIDAndSlug), arg0, arg1)
 }
 
+// GetWorkspaceAppSecurityKeys mocks base method.
+func (m *MockStore) GetWorkspaceAppSecurityKeys(arg0 context.Context, arg1 time.Time) ([]database.WorkspaceAppSecurityKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAppSecurityKeys", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspaceAppSecurityKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAppSecurityKeys indicates an expected call of GetWorkspaceAppSecurityKeys.
+func (mr *MockStoreMockRecorder) GetWorkspaceAppSecurityKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAppSecurityKeys", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAppSecurityKeys), arg0, arg1)
+}
+
+// GetWorkspaceAppUsageInsights mocks base method.
+func (m *MockStore) GetWorkspaceAppUsageInsights(arg0 context.Context, arg1 database.GetWorkspaceAppUsageInsightsParams) ([]database.GetWorkspaceAppUsageInsightsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAppUsageInsights", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetWorkspaceAppUsageInsightsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAppUsageInsights indicates an expected call of GetWorkspaceAppUsageInsights.
+func (mr *MockStoreMockRecorder) GetWorkspaceAppUsageInsights(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAppUsageInsights", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAppUsageInsights), arg0, arg1)
+}
+
 // GetWorkspaceAppsByAgentID mocks base method.
 func (m *MockStore) GetWorkspaceAppsByAgentID(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceApp, error) {
 	m.ctrl.T.Helper()
@@ -1753,6 +2707,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceByWorkspaceAppID(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceByWorkspaceAppID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceByWorkspaceAppID), arg0, arg1)
 }
 
+// GetWorkspacePortShare mocks base method.
+func (m *MockStore) GetWorkspacePortShare(arg0 context.Context, arg1 database.GetWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspacePortShare", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspacePortShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspacePortShare indicates an expected call of GetWorkspacePortShare.
+func (mr *MockStoreMockRecorder) GetWorkspacePortShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspacePortShare", reflect.TypeOf((*MockStore)(nil).GetWorkspacePortShare), arg0, arg1)
+}
+
+// GetWorkspacePortSharesByWorkspaceID mocks base method.
+func (m *MockStore) GetWorkspacePortSharesByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspacePortShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspacePortSharesByWorkspaceID", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspacePortShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspacePortSharesByWorkspaceID indicates an expected call of GetWorkspacePortSharesByWorkspaceID.
+func (mr *MockStoreMockRecorder) GetWorkspacePortSharesByWorkspaceID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspacePortSharesByWorkspaceID", reflect.TypeOf((*MockStore)(nil).GetWorkspacePortSharesByWorkspaceID), arg0, arg1)
+}
+
 // GetWorkspaceProxies mocks base method.
 func (m *MockStore) GetWorkspaceProxies(arg0 context.Context) ([]database.WorkspaceProxy, error) {
 	m.ctrl.T.Helper()
@@ -1962,6 +2946,21 @@ func (mr *MockStoreMockRecorder) InsertAPIKey(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAPIKey", reflect.TypeOf((*MockStore)(nil).InsertAPIKey), arg0, arg1)
 }
 
+// InsertAPIKeyImpersonation mocks base method.
+func (m *MockStore) InsertAPIKeyImpersonation(arg0 context.Context, arg1 database.InsertAPIKeyImpersonationParams) (database.APIKeyImpersonation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAPIKeyImpersonation", arg0, arg1)
+	ret0, _ := ret[0].(database.APIKeyImpersonation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertAPIKeyImpersonation indicates an expected call of InsertAPIKeyImpersonation.
+func (mr *MockStoreMockRecorder) InsertAPIKeyImpersonation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAPIKeyImpersonation", reflect.TypeOf((*MockStore)(nil).InsertAPIKeyImpersonation), arg0, arg1)
+}
+
 // InsertAllUsersGroup mocks base method.
 func (m *MockStore) InsertAllUsersGroup(arg0 context.Context, arg1 uuid.UUID) (database.Group, error) {
 	m.ctrl.T.Helper()
@@ -2006,6 +3005,36 @@ func (mr *MockStoreMockRecorder) InsertDERPMeshKey(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDERPMeshKey", reflect.TypeOf((*MockStore)(nil).InsertDERPMeshKey), arg0, arg1)
 }
 
+// InsertDERPRegion mocks base method.
+func (m *MockStore) InsertDERPRegion(arg0 context.Context, arg1 database.InsertDERPRegionParams) (database.DERPRegion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDERPRegion", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertDERPRegion indicates an expected call of InsertDERPRegion.
+func (mr *MockStoreMockRecorder) InsertDERPRegion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDERPRegion", reflect.TypeOf((*MockStore)(nil).InsertDERPRegion), arg0, arg1)
+}
+
+// InsertDERPRegionPolicy mocks base method.
+func (m *MockStore) InsertDERPRegionPolicy(arg0 context.Context, arg1 database.InsertDERPRegionPolicyParams) (database.DERPRegionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDERPRegionPolicy", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertDERPRegionPolicy indicates an expected call of InsertDERPRegionPolicy.
+func (mr *MockStoreMockRecorder) InsertDERPRegionPolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDERPRegionPolicy", reflect.TypeOf((*MockStore)(nil).InsertDERPRegionPolicy), arg0, arg1)
+}
+
 // InsertDeploymentID mocks base method.
 func (m *MockStore) InsertDeploymentID(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -2083,30 +3112,105 @@ func (mr *MockStoreMockRecorder) InsertGroup(arg0, arg1 interface{}) *gomock.Cal
 // InsertGroupMember mocks base method.
 func (m *MockStore) InsertGroupMember(arg0 context.Context, arg1 database.InsertGroupMemberParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertGroupMember", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "InsertGroupMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertGroupMember indicates an expected call of InsertGroupMember.
+func (mr *MockStoreMockRecorder) InsertGroupMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertGroupMember", reflect.TypeOf((*MockStore)(nil).InsertGroupMember), arg0, arg1)
+}
+
+// InsertLicense mocks base method.
+func (m *MockStore) InsertLicense(arg0 context.Context, arg1 database.InsertLicenseParams) (database.License, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLicense", arg0, arg1)
+	ret0, _ := ret[0].(database.License)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLicense indicates an expected call of InsertLicense.
+func (mr *MockStoreMockRecorder) InsertLicense(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLicense", reflect.TypeOf((*MockStore)(nil).InsertLicense), arg0, arg1)
+}
+
+// InsertOAuth2DeviceCode mocks base method.
+func (m *MockStore) InsertOAuth2DeviceCode(arg0 context.Context, arg1 database.InsertOAuth2DeviceCodeParams) (database.OAuth2DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOAuth2DeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOAuth2DeviceCode indicates an expected call of InsertOAuth2DeviceCode.
+func (mr *MockStoreMockRecorder) InsertOAuth2DeviceCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2DeviceCode", reflect.TypeOf((*MockStore)(nil).InsertOAuth2DeviceCode), arg0, arg1)
+}
+
+// InsertOAuth2ProviderApp mocks base method.
+func (m *MockStore) InsertOAuth2ProviderApp(arg0 context.Context, arg1 database.InsertOAuth2ProviderAppParams) (database.OAuth2ProviderApp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOAuth2ProviderApp", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderApp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOAuth2ProviderApp indicates an expected call of InsertOAuth2ProviderApp.
+func (mr *MockStoreMockRecorder) InsertOAuth2ProviderApp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2ProviderApp", reflect.TypeOf((*MockStore)(nil).InsertOAuth2ProviderApp), arg0, arg1)
+}
+
+// InsertOAuth2ProviderAppCode mocks base method.
+func (m *MockStore) InsertOAuth2ProviderAppCode(arg0 context.Context, arg1 database.InsertOAuth2ProviderAppCodeParams) (database.OAuth2ProviderAppCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOAuth2ProviderAppCode", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOAuth2ProviderAppCode indicates an expected call of InsertOAuth2ProviderAppCode.
+func (mr *MockStoreMockRecorder) InsertOAuth2ProviderAppCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2ProviderAppCode", reflect.TypeOf((*MockStore)(nil).InsertOAuth2ProviderAppCode), arg0, arg1)
+}
+
+// InsertOAuth2ProviderAppSecret mocks base method.
+func (m *MockStore) InsertOAuth2ProviderAppSecret(arg0 context.Context, arg1 database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOAuth2ProviderAppSecret", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// InsertGroupMember indicates an expected call of InsertGroupMember.
-func (mr *MockStoreMockRecorder) InsertGroupMember(arg0, arg1 interface{}) *gomock.Call {
+// InsertOAuth2ProviderAppSecret indicates an expected call of InsertOAuth2ProviderAppSecret.
+func (mr *MockStoreMockRecorder) InsertOAuth2ProviderAppSecret(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertGroupMember", reflect.TypeOf((*MockStore)(nil).InsertGroupMember), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2ProviderAppSecret", reflect.TypeOf((*MockStore)(nil).InsertOAuth2ProviderAppSecret), arg0, arg1)
 }
 
-// InsertLicense mocks base method.
-func (m *MockStore) InsertLicense(arg0 context.Context, arg1 database.InsertLicenseParams) (database.License, error) {
+// InsertOAuth2ProviderAppToken mocks base method.
+func (m *MockStore) InsertOAuth2ProviderAppToken(arg0 context.Context, arg1 database.InsertOAuth2ProviderAppTokenParams) (database.OAuth2ProviderAppToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertLicense", arg0, arg1)
-	ret0, _ := ret[0].(database.License)
+	ret := m.ctrl.Call(m, "InsertOAuth2ProviderAppToken", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertLicense indicates an expected call of InsertLicense.
-func (mr *MockStoreMockRecorder) InsertLicense(arg0, arg1 interface{}) *gomock.Call {
+// InsertOAuth2ProviderAppToken indicates an expected call of InsertOAuth2ProviderAppToken.
+func (mr *MockStoreMockRecorder) InsertOAuth2ProviderAppToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLicense", reflect.TypeOf((*MockStore)(nil).InsertLicense), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2ProviderAppToken", reflect.TypeOf((*MockStore)(nil).InsertOAuth2ProviderAppToken), arg0, arg1)
 }
 
 // InsertOrganization mocks base method.
@@ -2199,6 +3303,21 @@ func (mr *MockStoreMockRecorder) InsertReplica(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReplica", reflect.TypeOf((*MockStore)(nil).InsertReplica), arg0, arg1)
 }
 
+// InsertServiceAccount mocks base method.
+func (m *MockStore) InsertServiceAccount(arg0 context.Context, arg1 database.InsertServiceAccountParams) (database.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertServiceAccount", arg0, arg1)
+	ret0, _ := ret[0].(database.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertServiceAccount indicates an expected call of InsertServiceAccount.
+func (mr *MockStoreMockRecorder) InsertServiceAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertServiceAccount", reflect.TypeOf((*MockStore)(nil).InsertServiceAccount), arg0, arg1)
+}
+
 // InsertTemplate mocks base method.
 func (m *MockStore) InsertTemplate(arg0 context.Context, arg1 database.InsertTemplateParams) (database.Template, error) {
 	m.ctrl.T.Helper()
@@ -2244,6 +3363,64 @@ func (mr *MockStoreMockRecorder) InsertTemplateVersionParameter(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionParameter", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionParameter), arg0, arg1)
 }
 
+// InsertTemplateVersionPreset mocks base method.
+func (m *MockStore) InsertTemplateVersionPreset(arg0 context.Context, arg1 database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionPreset", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTemplateVersionPreset indicates an expected call of InsertTemplateVersionPreset.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionPreset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionPreset", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionPreset), arg0, arg1)
+}
+
+// InsertTemplateVersionPresetParameters mocks base method.
+func (m *MockStore) InsertTemplateVersionPresetParameters(arg0 context.Context, arg1 database.InsertTemplateVersionPresetParametersParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionPresetParameters", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertTemplateVersionPresetParameters indicates an expected call of InsertTemplateVersionPresetParameters.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionPresetParameters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionPresetParameters", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionPresetParameters), arg0, arg1)
+}
+
+// InsertTemplateVersionRollout mocks base method.
+func (m *MockStore) InsertTemplateVersionRollout(arg0 context.Context, arg1 database.InsertTemplateVersionRolloutParams) (database.TemplateVersionRollout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionRollout", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionRollout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTemplateVersionRollout indicates an expected call of InsertTemplateVersionRollout.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionRollout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionRollout", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionRollout), arg0, arg1)
+}
+
+// InsertTemplateVersionRolloutWorkspace mocks base method.
+func (m *MockStore) InsertTemplateVersionRolloutWorkspace(arg0 context.Context, arg1 database.InsertTemplateVersionRolloutWorkspaceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionRolloutWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertTemplateVersionRolloutWorkspace indicates an expected call of InsertTemplateVersionRolloutWorkspace.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionRolloutWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionRolloutWorkspace", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionRolloutWorkspace), arg0, arg1)
+}
+
 // InsertTemplateVersionVariable mocks base method.
 func (m *MockStore) InsertTemplateVersionVariable(arg0 context.Context, arg1 database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	m.ctrl.T.Helper()
@@ -2303,6 +3480,21 @@ func (mr *MockStoreMockRecorder) InsertUserLink(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserLink", reflect.TypeOf((*MockStore)(nil).InsertUserLink), arg0, arg1)
 }
 
+// InsertUserLoginFailure mocks base method.
+func (m *MockStore) InsertUserLoginFailure(arg0 context.Context, arg1 database.InsertUserLoginFailureParams) (database.UserLoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUserLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(database.UserLoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertUserLoginFailure indicates an expected call of InsertUserLoginFailure.
+func (mr *MockStoreMockRecorder) InsertUserLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserLoginFailure", reflect.TypeOf((*MockStore)(nil).InsertUserLoginFailure), arg0, arg1)
+}
+
 // InsertWorkspace mocks base method.
 func (m *MockStore) InsertWorkspace(arg0 context.Context, arg1 database.InsertWorkspaceParams) (database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -2392,6 +3584,21 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceApp(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceApp", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceApp), arg0, arg1)
 }
 
+// InsertWorkspaceAppSecurityKey mocks base method.
+func (m *MockStore) InsertWorkspaceAppSecurityKey(arg0 context.Context, arg1 database.InsertWorkspaceAppSecurityKeyParams) (database.WorkspaceAppSecurityKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAppSecurityKey", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceAppSecurityKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWorkspaceAppSecurityKey indicates an expected call of InsertWorkspaceAppSecurityKey.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAppSecurityKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAppSecurityKey", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAppSecurityKey), arg0, arg1)
+}
+
 // InsertWorkspaceBuild mocks base method.
 func (m *MockStore) InsertWorkspaceBuild(arg0 context.Context, arg1 database.InsertWorkspaceBuildParams) (database.WorkspaceBuild, error) {
 	m.ctrl.T.Helper()
@@ -2525,6 +3732,51 @@ func (mr *MockStoreMockRecorder) UpdateAPIKeyByID(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyByID", reflect.TypeOf((*MockStore)(nil).UpdateAPIKeyByID), arg0, arg1)
 }
 
+// UpdateDERPRegionByID mocks base method.
+func (m *MockStore) UpdateDERPRegionByID(arg0 context.Context, arg1 database.UpdateDERPRegionByIDParams) (database.DERPRegion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDERPRegionByID", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDERPRegionByID indicates an expected call of UpdateDERPRegionByID.
+func (mr *MockStoreMockRecorder) UpdateDERPRegionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDERPRegionByID", reflect.TypeOf((*MockStore)(nil).UpdateDERPRegionByID), arg0, arg1)
+}
+
+// UpdateDERPRegionHealthByID mocks base method.
+func (m *MockStore) UpdateDERPRegionHealthByID(arg0 context.Context, arg1 database.UpdateDERPRegionHealthByIDParams) (database.DERPRegion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDERPRegionHealthByID", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDERPRegionHealthByID indicates an expected call of UpdateDERPRegionHealthByID.
+func (mr *MockStoreMockRecorder) UpdateDERPRegionHealthByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDERPRegionHealthByID", reflect.TypeOf((*MockStore)(nil).UpdateDERPRegionHealthByID), arg0, arg1)
+}
+
+// UpdateDERPRegionPolicyByID mocks base method.
+func (m *MockStore) UpdateDERPRegionPolicyByID(arg0 context.Context, arg1 database.UpdateDERPRegionPolicyByIDParams) (database.DERPRegionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDERPRegionPolicyByID", arg0, arg1)
+	ret0, _ := ret[0].(database.DERPRegionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDERPRegionPolicyByID indicates an expected call of UpdateDERPRegionPolicyByID.
+func (mr *MockStoreMockRecorder) UpdateDERPRegionPolicyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDERPRegionPolicyByID", reflect.TypeOf((*MockStore)(nil).UpdateDERPRegionPolicyByID), arg0, arg1)
+}
+
 // UpdateGitAuthLink mocks base method.
 func (m *MockStore) UpdateGitAuthLink(arg0 context.Context, arg1 database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	m.ctrl.T.Helper()
@@ -2585,6 +3837,66 @@ func (mr *MockStoreMockRecorder) UpdateMemberRoles(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRoles", reflect.TypeOf((*MockStore)(nil).UpdateMemberRoles), arg0, arg1)
 }
 
+// UpdateOAuth2DeviceCodePolledByID mocks base method.
+func (m *MockStore) UpdateOAuth2DeviceCodePolledByID(arg0 context.Context, arg1 database.UpdateOAuth2DeviceCodePolledByIDParams) (database.OAuth2DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2DeviceCodePolledByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOAuth2DeviceCodePolledByID indicates an expected call of UpdateOAuth2DeviceCodePolledByID.
+func (mr *MockStoreMockRecorder) UpdateOAuth2DeviceCodePolledByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2DeviceCodePolledByID", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2DeviceCodePolledByID), arg0, arg1)
+}
+
+// UpdateOAuth2DeviceCodeStatusByID mocks base method.
+func (m *MockStore) UpdateOAuth2DeviceCodeStatusByID(arg0 context.Context, arg1 database.UpdateOAuth2DeviceCodeStatusByIDParams) (database.OAuth2DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2DeviceCodeStatusByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOAuth2DeviceCodeStatusByID indicates an expected call of UpdateOAuth2DeviceCodeStatusByID.
+func (mr *MockStoreMockRecorder) UpdateOAuth2DeviceCodeStatusByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2DeviceCodeStatusByID", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2DeviceCodeStatusByID), arg0, arg1)
+}
+
+// UpdateOAuth2ProviderAppByID mocks base method.
+func (m *MockStore) UpdateOAuth2ProviderAppByID(arg0 context.Context, arg1 database.UpdateOAuth2ProviderAppByIDParams) (database.OAuth2ProviderApp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2ProviderAppByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderApp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOAuth2ProviderAppByID indicates an expected call of UpdateOAuth2ProviderAppByID.
+func (mr *MockStoreMockRecorder) UpdateOAuth2ProviderAppByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2ProviderAppByID", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2ProviderAppByID), arg0, arg1)
+}
+
+// UpdateOAuth2ProviderAppSecretByID mocks base method.
+func (m *MockStore) UpdateOAuth2ProviderAppSecretByID(arg0 context.Context, arg1 database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2ProviderAppSecretByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOAuth2ProviderAppSecretByID indicates an expected call of UpdateOAuth2ProviderAppSecretByID.
+func (mr *MockStoreMockRecorder) UpdateOAuth2ProviderAppSecretByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2ProviderAppSecretByID", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2ProviderAppSecretByID), arg0, arg1)
+}
+
 // UpdateProvisionerJobByID mocks base method.
 func (m *MockStore) UpdateProvisionerJobByID(arg0 context.Context, arg1 database.UpdateProvisionerJobByIDParams) error {
 	m.ctrl.T.Helper()
@@ -2642,6 +3954,20 @@ func (mr *MockStoreMockRecorder) UpdateReplica(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReplica", reflect.TypeOf((*MockStore)(nil).UpdateReplica), arg0, arg1)
 }
 
+// UpdateTailnetPeerStatusByCoordinator mocks base method.
+func (m *MockStore) UpdateTailnetPeerStatusByCoordinator(arg0 context.Context, arg1 database.UpdateTailnetPeerStatusByCoordinatorParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTailnetPeerStatusByCoordinator", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTailnetPeerStatusByCoordinator indicates an expected call of UpdateTailnetPeerStatusByCoordinator.
+func (mr *MockStoreMockRecorder) UpdateTailnetPeerStatusByCoordinator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTailnetPeerStatusByCoordinator", reflect.TypeOf((*MockStore)(nil).UpdateTailnetPeerStatusByCoordinator), arg0, arg1)
+}
+
 // UpdateTemplateACLByID mocks base method.
 func (m *MockStore) UpdateTemplateACLByID(arg0 context.Context, arg1 database.UpdateTemplateACLByIDParams) (database.Template, error) {
 	m.ctrl.T.Helper()
@@ -2685,6 +4011,20 @@ func (mr *MockStoreMockRecorder) UpdateTemplateDeletedByID(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateDeletedByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateDeletedByID), arg0, arg1)
 }
 
+// UpdateTemplateGitSourceSyncStatus mocks base method.
+func (m *MockStore) UpdateTemplateGitSourceSyncStatus(arg0 context.Context, arg1 database.UpdateTemplateGitSourceSyncStatusParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateGitSourceSyncStatus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateGitSourceSyncStatus indicates an expected call of UpdateTemplateGitSourceSyncStatus.
+func (mr *MockStoreMockRecorder) UpdateTemplateGitSourceSyncStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateGitSourceSyncStatus", reflect.TypeOf((*MockStore)(nil).UpdateTemplateGitSourceSyncStatus), arg0, arg1)
+}
+
 // UpdateTemplateMetaByID mocks base method.
 func (m *MockStore) UpdateTemplateMetaByID(arg0 context.Context, arg1 database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 	m.ctrl.T.Helper()
@@ -2758,6 +4098,49 @@ func (mr *MockStoreMockRecorder) UpdateTemplateVersionGitAuthProvidersByJobID(ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionGitAuthProvidersByJobID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionGitAuthProvidersByJobID), arg0, arg1)
 }
 
+// UpdateTemplateVersionRolloutStatusByID mocks base method.
+func (m *MockStore) UpdateTemplateVersionRolloutStatusByID(arg0 context.Context, arg1 database.UpdateTemplateVersionRolloutStatusByIDParams) (database.TemplateVersionRollout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateVersionRolloutStatusByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionRollout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplateVersionRolloutStatusByID indicates an expected call of UpdateTemplateVersionRolloutStatusByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateVersionRolloutStatusByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionRolloutStatusByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionRolloutStatusByID), arg0, arg1)
+}
+
+// UpdateTemplateVersionRolloutWorkspace mocks base method.
+func (m *MockStore) UpdateTemplateVersionRolloutWorkspace(arg0 context.Context, arg1 database.UpdateTemplateVersionRolloutWorkspaceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateVersionRolloutWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateVersionRolloutWorkspace indicates an expected call of UpdateTemplateVersionRolloutWorkspace.
+func (mr *MockStoreMockRecorder) UpdateTemplateVersionRolloutWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionRolloutWorkspace", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionRolloutWorkspace), arg0, arg1)
+}
+
+// UpdateTemplateVersionStateByID mocks base method.
+func (m *MockStore) UpdateTemplateVersionStateByID(arg0 context.Context, arg1 database.UpdateTemplateVersionStateByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateVersionStateByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateVersionStateByID indicates an expected call of UpdateTemplateVersionStateByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateVersionStateByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionStateByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionStateByID), arg0, arg1)
+}
+
 // UpdateUserDeletedByID mocks base method.
 func (m *MockStore) UpdateUserDeletedByID(arg0 context.Context, arg1 database.UpdateUserDeletedByIDParams) error {
 	m.ctrl.T.Helper()
@@ -2831,6 +4214,21 @@ func (mr *MockStoreMockRecorder) UpdateUserLinkedID(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserLinkedID", reflect.TypeOf((*MockStore)(nil).UpdateUserLinkedID), arg0, arg1)
 }
 
+// UpdateUserLoginLockout mocks base method.
+func (m *MockStore) UpdateUserLoginLockout(arg0 context.Context, arg1 database.UpdateUserLoginLockoutParams) (database.UserLoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserLoginLockout", arg0, arg1)
+	ret0, _ := ret[0].(database.UserLoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserLoginLockout indicates an expected call of UpdateUserLoginLockout.
+func (mr *MockStoreMockRecorder) UpdateUserLoginLockout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserLoginLockout", reflect.TypeOf((*MockStore)(nil).UpdateUserLoginLockout), arg0, arg1)
+}
+
 // UpdateUserProfile mocks base method.
 func (m *MockStore) UpdateUserProfile(arg0 context.Context, arg1 database.UpdateUserProfileParams) (database.User, error) {
 	m.ctrl.T.Helper()
@@ -2876,6 +4274,21 @@ func (mr *MockStoreMockRecorder) UpdateUserStatus(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserStatus", reflect.TypeOf((*MockStore)(nil).UpdateUserStatus), arg0, arg1)
 }
 
+// UpdateUserTOTP mocks base method.
+func (m *MockStore) UpdateUserTOTP(arg0 context.Context, arg1 database.UpdateUserTOTPParams) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTP indicates an expected call of UpdateUserTOTP.
+func (mr *MockStoreMockRecorder) UpdateUserTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTP", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTP), arg0, arg1)
+}
+
 // UpdateWorkspace mocks base method.
 func (m *MockStore) UpdateWorkspace(arg0 context.Context, arg1 database.UpdateWorkspaceParams) (database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -2933,6 +4346,20 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentMetadata(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentMetadata", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentMetadata), arg0, arg1)
 }
 
+// UpdateWorkspaceAgentPeerConnectionsByID mocks base method.
+func (m *MockStore) UpdateWorkspaceAgentPeerConnectionsByID(arg0 context.Context, arg1 database.UpdateWorkspaceAgentPeerConnectionsByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceAgentPeerConnectionsByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceAgentPeerConnectionsByID indicates an expected call of UpdateWorkspaceAgentPeerConnectionsByID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentPeerConnectionsByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentPeerConnectionsByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentPeerConnectionsByID), arg0, arg1)
+}
+
 // UpdateWorkspaceAgentStartupByID mocks base method.
 func (m *MockStore) UpdateWorkspaceAgentStartupByID(arg0 context.Context, arg1 database.UpdateWorkspaceAgentStartupByIDParams) error {
 	m.ctrl.T.Helper()
@@ -3104,20 +4531,6 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceTTLToBeWithinTemplateMax(arg0, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceTTLToBeWithinTemplateMax", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceTTLToBeWithinTemplateMax), arg0, arg1)
 }
 
-// UpsertAppSecurityKey mocks base method.
-func (m *MockStore) UpsertAppSecurityKey(arg0 context.Context, arg1 string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpsertAppSecurityKey", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// UpsertAppSecurityKey indicates an expected call of UpsertAppSecurityKey.
-func (mr *MockStoreMockRecorder) UpsertAppSecurityKey(arg0, arg1 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAppSecurityKey", reflect.TypeOf((*MockStore)(nil).UpsertAppSecurityKey), arg0, arg1)
-}
-
 // UpsertDefaultProxy mocks base method.
 func (m *MockStore) UpsertDefaultProxy(arg0 context.Context, arg1 database.UpsertDefaultProxyParams) error {
 	m.ctrl.T.Helper()
@@ -3174,6 +4587,110 @@ func (mr *MockStoreMockRecorder) UpsertServiceBanner(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertServiceBanner", reflect.TypeOf((*MockStore)(nil).UpsertServiceBanner), arg0, arg1)
 }
 
+// UpsertTailnetCoordinator mocks base method.
+func (m *MockStore) UpsertTailnetCoordinator(arg0 context.Context, arg1 uuid.UUID) (database.TailnetCoordinator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTailnetCoordinator", arg0, arg1)
+	ret0, _ := ret[0].(database.TailnetCoordinator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTailnetCoordinator indicates an expected call of UpsertTailnetCoordinator.
+func (mr *MockStoreMockRecorder) UpsertTailnetCoordinator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTailnetCoordinator", reflect.TypeOf((*MockStore)(nil).UpsertTailnetCoordinator), arg0, arg1)
+}
+
+// UpsertTailnetPeer mocks base method.
+func (m *MockStore) UpsertTailnetPeer(arg0 context.Context, arg1 database.UpsertTailnetPeerParams) (database.TailnetPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTailnetPeer", arg0, arg1)
+	ret0, _ := ret[0].(database.TailnetPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTailnetPeer indicates an expected call of UpsertTailnetPeer.
+func (mr *MockStoreMockRecorder) UpsertTailnetPeer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTailnetPeer", reflect.TypeOf((*MockStore)(nil).UpsertTailnetPeer), arg0, arg1)
+}
+
+// UpsertTailnetTunnel mocks base method.
+func (m *MockStore) UpsertTailnetTunnel(arg0 context.Context, arg1 database.UpsertTailnetTunnelParams) (database.TailnetTunnel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTailnetTunnel", arg0, arg1)
+	ret0, _ := ret[0].(database.TailnetTunnel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTailnetTunnel indicates an expected call of UpsertTailnetTunnel.
+func (mr *MockStoreMockRecorder) UpsertTailnetTunnel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTailnetTunnel", reflect.TypeOf((*MockStore)(nil).UpsertTailnetTunnel), arg0, arg1)
+}
+
+// UpsertTemplateGitSource mocks base method.
+func (m *MockStore) UpsertTemplateGitSource(arg0 context.Context, arg1 database.UpsertTemplateGitSourceParams) (database.TemplateGitSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTemplateGitSource", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateGitSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTemplateGitSource indicates an expected call of UpsertTemplateGitSource.
+func (mr *MockStoreMockRecorder) UpsertTemplateGitSource(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTemplateGitSource", reflect.TypeOf((*MockStore)(nil).UpsertTemplateGitSource), arg0, arg1)
+}
+
+// UpsertUserTOTP mocks base method.
+func (m *MockStore) UpsertUserTOTP(arg0 context.Context, arg1 database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserTOTP indicates an expected call of UpsertUserTOTP.
+func (mr *MockStoreMockRecorder) UpsertUserTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserTOTP", reflect.TypeOf((*MockStore)(nil).UpsertUserTOTP), arg0, arg1)
+}
+
+// UpsertWorkspaceAppUsageRollup mocks base method.
+func (m *MockStore) UpsertWorkspaceAppUsageRollup(arg0 context.Context, arg1 database.UpsertWorkspaceAppUsageRollupParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceAppUsageRollup", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceAppUsageRollup indicates an expected call of UpsertWorkspaceAppUsageRollup.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceAppUsageRollup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceAppUsageRollup", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceAppUsageRollup), arg0, arg1)
+}
+
+// UpsertWorkspacePortShare mocks base method.
+func (m *MockStore) UpsertWorkspacePortShare(arg0 context.Context, arg1 database.UpsertWorkspacePortShareParams) (database.WorkspacePortShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspacePortShare", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspacePortShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertWorkspacePortShare indicates an expected call of UpsertWorkspacePortShare.
+func (mr *MockStoreMockRecorder) UpsertWorkspacePortShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspacePortShare", reflect.TypeOf((*MockStore)(nil).UpsertWorkspacePortShare), arg0, arg1)
+}
+
 // Wrappers mocks base method.
 func (m *MockStore) Wrappers() []string {
 	m.ctrl.T.Helper()
@@ -45,6 +45,15 @@ func New(ctx context.Context, logger slog.Logger, db database.Store) io.Closer {
 			eg.Go(func() error {
 				return db.DeleteOldWorkspaceAgentStats(ctx)
 			})
+			eg.Go(func() error {
+				return db.DeleteOldWorkspaceAppUsageRollups(ctx)
+			})
+			eg.Go(func() error {
+				return db.DeleteExpiredOAuth2ProviderAppCodes(ctx)
+			})
+			eg.Go(func() error {
+				return db.DeleteExpiredOAuth2DeviceCodes(ctx)
+			})
 			err := eg.Wait()
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
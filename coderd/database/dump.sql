@@ -8,7 +8,8 @@ CREATE TYPE api_key_scope AS ENUM (
 CREATE TYPE app_sharing_level AS ENUM (
     'owner',
     'authenticated',
-    'public'
+    'public',
+    'group'
 );
 
 CREATE TYPE audit_action AS ENUM (
@@ -19,7 +20,9 @@ CREATE TYPE audit_action AS ENUM (
     'stop',
     'login',
     'logout',
-    'register'
+    'register',
+    'connect',
+    'lockout'
 );
 
 CREATE TYPE build_reason AS ENUM (
@@ -46,11 +49,19 @@ CREATE TYPE login_type AS ENUM (
     'github',
     'oidc',
     'token',
-    'none'
+    'none',
+    'oauth2_provider_app',
+    'impersonation'
 );
 
 COMMENT ON TYPE login_type IS 'Specifies the method of authentication. "none" is a special case in which no authentication method is allowed.';
 
+CREATE TYPE oauth2_device_code_status AS ENUM (
+    'pending',
+    'approved',
+    'denied'
+);
+
 CREATE TYPE parameter_destination_scheme AS ENUM (
     'none',
     'environment_variable',
@@ -73,6 +84,15 @@ CREATE TYPE parameter_type_system AS ENUM (
     'hcl'
 );
 
+CREATE TYPE port_share_level AS ENUM (
+    'owner',
+    'group',
+    'authenticated',
+    'public'
+);
+
+COMMENT ON TYPE port_share_level IS 'Who may reach a workspace port through the application proxy, from most to least restrictive.';
+
 CREATE TYPE provisioner_job_type AS ENUM (
     'template_version_import',
     'workspace_build',
@@ -99,7 +119,13 @@ CREATE TYPE resource_type AS ENUM (
     'group',
     'workspace_build',
     'license',
-    'workspace_proxy'
+    'workspace_proxy',
+    'oauth2_provider_app',
+    'oauth2_provider_app_secret',
+    'template_version_rollout',
+    'workspace_port_share',
+    'derp_region',
+    'derp_region_policy'
 );
 
 CREATE TYPE startup_script_behavior AS ENUM (
@@ -107,6 +133,35 @@ CREATE TYPE startup_script_behavior AS ENUM (
     'non-blocking'
 );
 
+CREATE TYPE tailnet_status AS ENUM (
+    'ok',
+    'lost'
+);
+
+CREATE TYPE template_version_rollout_status AS ENUM (
+    'running',
+    'paused',
+    'completed',
+    'aborted'
+);
+
+CREATE TYPE template_version_rollout_workspace_status AS ENUM (
+    'pending',
+    'building',
+    'succeeded',
+    'failed',
+    'skipped'
+);
+
+CREATE TYPE template_version_state AS ENUM (
+    'draft',
+    'active',
+    'deprecated',
+    'archived'
+);
+
+COMMENT ON TYPE template_version_state IS 'Lifecycle state of a template version. "draft" versions have never been promoted, "deprecated" versions warn users on build and "archived" versions can no longer be built.';
+
 CREATE TYPE user_status AS ENUM (
     'active',
     'suspended'
@@ -143,6 +198,16 @@ CREATE TYPE workspace_transition AS ENUM (
     'delete'
 );
 
+CREATE FUNCTION delete_deleted_oauth2_provider_app_token_api_key() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    DELETE FROM api_keys
+    WHERE id = OLD.api_key_id;
+    RETURN OLD;
+END;
+$$;
+
 CREATE FUNCTION delete_deleted_user_api_keys() RETURNS trigger
     LANGUAGE plpgsql
     AS $$
@@ -171,6 +236,16 @@ BEGIN
 END;
 $$;
 
+CREATE TABLE api_key_impersonations (
+    api_key_id text NOT NULL,
+    impersonator_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE api_key_impersonations IS 'The owners that created impersonation API keys, which authenticate as another user.';
+
+COMMENT ON COLUMN api_key_impersonations.impersonator_id IS 'The owner that is impersonating the user of the API key.';
+
 CREATE TABLE api_keys (
     id text NOT NULL,
     hashed_secret bytea NOT NULL,
@@ -183,11 +258,14 @@ CREATE TABLE api_keys (
     lifetime_seconds bigint DEFAULT 86400 NOT NULL,
     ip_address inet DEFAULT '0.0.0.0'::inet NOT NULL,
     scope api_key_scope DEFAULT 'all'::api_key_scope NOT NULL,
-    token_name text DEFAULT ''::text NOT NULL
+    token_name text DEFAULT ''::text NOT NULL,
+    user_agent text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON COLUMN api_keys.hashed_secret IS 'hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.';
 
+COMMENT ON COLUMN api_keys.user_agent IS 'The user agent of the client that created the key, used to tell sessions apart.';
+
 CREATE TABLE audit_logs (
     id uuid NOT NULL,
     "time" timestamp with time zone NOT NULL,
@@ -206,6 +284,42 @@ CREATE TABLE audit_logs (
     resource_icon text NOT NULL
 );
 
+CREATE TABLE derp_region_policies (
+    id uuid NOT NULL,
+    group_id uuid,
+    template_id uuid,
+    region_ids integer[] NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    CONSTRAINT derp_region_policies_target_check CHECK (((group_id IS NULL) <> (template_id IS NULL)))
+);
+
+COMMENT ON TABLE derp_region_policies IS 'Restricts the DERP regions used by the clients of a group''s members, or by the agents of a template''s workspaces.';
+
+COMMENT ON COLUMN derp_region_policies.region_ids IS 'The DERP regions that may be used. Regions of the static DERP map must be listed as well to remain usable.';
+
+CREATE TABLE derp_regions (
+    region_id integer NOT NULL,
+    region_code text NOT NULL,
+    region_name text NOT NULL,
+    nodes jsonb DEFAULT '[]'::jsonb NOT NULL,
+    avoid boolean DEFAULT false NOT NULL,
+    healthy boolean DEFAULT true NOT NULL,
+    health_error text DEFAULT ''::text NOT NULL,
+    last_health_check_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    id uuid DEFAULT gen_random_uuid() NOT NULL
+);
+
+COMMENT ON TABLE derp_regions IS 'DERP regions managed by administrators, merged into the DERP map served to clients and agents.';
+
+COMMENT ON COLUMN derp_regions.nodes IS 'The JSON encoded tailcfg.DERPNode list of the region.';
+
+COMMENT ON COLUMN derp_regions.avoid IS 'Clients do not pick avoided regions as their home region, but still use them to reach peers homed there.';
+
+COMMENT ON COLUMN derp_regions.healthy IS 'Unhealthy regions are left out of the DERP map until they pass a health check again.';
+
 CREATE TABLE files (
     hash character varying(64) NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -266,6 +380,82 @@ CREATE SEQUENCE licenses_id_seq
 
 ALTER SEQUENCE licenses_id_seq OWNED BY licenses.id;
 
+CREATE TABLE oauth2_device_codes (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    secret_prefix bytea NOT NULL,
+    hashed_secret bytea NOT NULL,
+    user_code text NOT NULL,
+    scope api_key_scope NOT NULL,
+    status oauth2_device_code_status DEFAULT 'pending'::oauth2_device_code_status NOT NULL,
+    user_id uuid,
+    polling_interval integer NOT NULL,
+    last_polled_at timestamp with time zone
+);
+
+COMMENT ON TABLE oauth2_device_codes IS 'Pending device authorization requests, see RFC 8628. Used by the CLI to log in on machines without a browser.';
+
+COMMENT ON COLUMN oauth2_device_codes.user_code IS 'The short code the user enters on the verification page.';
+
+COMMENT ON COLUMN oauth2_device_codes.user_id IS 'The user that approved or denied the request.';
+
+COMMENT ON COLUMN oauth2_device_codes.polling_interval IS 'The minimum number of seconds the device must wait between token requests. It is increased when the device polls too quickly.';
+
+CREATE TABLE oauth2_provider_app_codes (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    secret_prefix bytea NOT NULL,
+    hashed_secret bytea NOT NULL,
+    user_id uuid NOT NULL,
+    app_id uuid NOT NULL,
+    scope api_key_scope NOT NULL,
+    redirect_uri text NOT NULL,
+    code_challenge text NOT NULL
+);
+
+COMMENT ON TABLE oauth2_provider_app_codes IS 'Single-use authorization codes handed out to applications after the user gives consent.';
+
+COMMENT ON COLUMN oauth2_provider_app_codes.code_challenge IS 'The S256 PKCE challenge sent with the authorization request, empty if the application did not use PKCE.';
+
+CREATE TABLE oauth2_provider_app_secrets (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    last_used_at timestamp with time zone,
+    hashed_secret bytea NOT NULL,
+    secret_prefix bytea NOT NULL,
+    display_secret text NOT NULL,
+    app_id uuid NOT NULL
+);
+
+COMMENT ON COLUMN oauth2_provider_app_secrets.display_secret IS 'The tail end of the original secret so secrets can be told apart.';
+
+CREATE TABLE oauth2_provider_app_tokens (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    hash_prefix bytea NOT NULL,
+    refresh_hash bytea NOT NULL,
+    app_secret_id uuid NOT NULL,
+    api_key_id text NOT NULL
+);
+
+COMMENT ON TABLE oauth2_provider_app_tokens IS 'Refresh tokens issued to applications. The access token is the referenced API key.';
+
+COMMENT ON COLUMN oauth2_provider_app_tokens.refresh_hash IS 'Refresh tokens provide a way to refresh an access token (API key). An expired API key can be refreshed if this token is not yet expired, meaning this expiry can outlive an API key.';
+
+CREATE TABLE oauth2_provider_apps (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    name character varying(64) NOT NULL,
+    icon character varying(256) NOT NULL,
+    callback_url text NOT NULL
+);
+
+COMMENT ON TABLE oauth2_provider_apps IS 'Third-party applications that can sign in users with Coder acting as the OAuth2 authorization server.';
+
 CREATE TABLE organization_members (
     user_id uuid NOT NULL,
     organization_id uuid NOT NULL,
@@ -378,11 +568,84 @@ CREATE TABLE replicas (
     error text DEFAULT ''::text NOT NULL
 );
 
+CREATE TABLE service_accounts (
+    user_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE service_accounts IS 'Users that are not humans. They cannot log in and only authenticate with tokens.';
+
+COMMENT ON COLUMN service_accounts.organization_id IS 'The organization that owns the service account.';
+
+COMMENT ON COLUMN service_accounts.created_by IS 'The admin that created the service account. It is recorded on the audit logs of the service account.';
+
 CREATE TABLE site_configs (
     key character varying(256) NOT NULL,
     value character varying(8192) NOT NULL
 );
 
+CREATE TABLE tailnet_coordinators (
+    id uuid NOT NULL,
+    heartbeat_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE tailnet_coordinators IS 'Coder replicas that coordinate tailnet peers, with the time of their last heartbeat.';
+
+CREATE TABLE tailnet_peers (
+    id uuid NOT NULL,
+    coordinator_id uuid NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    node bytea NOT NULL,
+    status tailnet_status DEFAULT 'ok'::tailnet_status NOT NULL
+);
+
+COMMENT ON TABLE tailnet_peers IS 'Tailnet peers connected to a coordinator. A peer that reconnects to another replica may briefly be connected to several coordinators.';
+
+COMMENT ON COLUMN tailnet_peers.node IS 'The protobuf encoded tailnet node of the peer.';
+
+COMMENT ON COLUMN tailnet_peers.status IS 'Peers are lost when they disconnect without saying goodbye, for example because their coordinator shut down.';
+
+CREATE TABLE tailnet_tunnels (
+    coordinator_id uuid NOT NULL,
+    src_id uuid NOT NULL,
+    dst_id uuid NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE tailnet_tunnels IS 'Tunnels requested by tailnet peers. The source peer is connected to the coordinator.';
+
+CREATE TABLE template_git_sources (
+    template_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    created_by uuid NOT NULL,
+    repository_url text NOT NULL,
+    ref text NOT NULL,
+    subdirectory text DEFAULT ''::text NOT NULL,
+    git_auth_provider_id text DEFAULT ''::text NOT NULL,
+    poll_interval bigint DEFAULT 0 NOT NULL,
+    webhook_secret text DEFAULT ''::text NOT NULL,
+    last_commit_sha text DEFAULT ''::text NOT NULL,
+    last_synced_at timestamp with time zone,
+    last_error text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON COLUMN template_git_sources.created_by IS 'The user whose git auth link is used to clone the repository. New template versions are attributed to this user.';
+
+COMMENT ON COLUMN template_git_sources.ref IS 'Branch or tag to track.';
+
+COMMENT ON COLUMN template_git_sources.subdirectory IS 'Directory within the repository that contains the template.';
+
+COMMENT ON COLUMN template_git_sources.git_auth_provider_id IS 'ID of the git auth provider used to authenticate clones. Empty for public repositories.';
+
+COMMENT ON COLUMN template_git_sources.poll_interval IS 'Interval in nanoseconds between polls of the repository. Zero disables polling.';
+
+COMMENT ON COLUMN template_git_sources.webhook_secret IS 'Shared secret used to verify push webhooks. Empty disables the webhook.';
+
+COMMENT ON COLUMN template_git_sources.last_commit_sha IS 'The commit that the most recent template version was created from.';
+
 CREATE TABLE template_version_parameters (
     template_version_id uuid NOT NULL,
     name text NOT NULL,
@@ -433,6 +696,60 @@ COMMENT ON COLUMN template_version_parameters.legacy_variable_name IS 'Name of t
 
 COMMENT ON COLUMN template_version_parameters.display_name IS 'Display name of the rich parameter';
 
+CREATE TABLE template_version_preset_parameters (
+    preset_id uuid NOT NULL,
+    name text NOT NULL,
+    value text NOT NULL
+);
+
+CREATE TABLE template_version_presets (
+    id uuid NOT NULL,
+    template_version_id uuid NOT NULL,
+    name text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE template_version_presets IS 'Named sets of rich parameter values that template authors offer when creating a workspace.';
+
+CREATE TABLE template_version_rollout_workspaces (
+    rollout_id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    build_id uuid,
+    status template_version_rollout_workspace_status DEFAULT 'pending'::template_version_rollout_workspace_status NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON COLUMN template_version_rollout_workspaces.build_id IS 'The workspace build that moved the workspace to the template version.';
+
+COMMENT ON COLUMN template_version_rollout_workspaces.error IS 'Reason the workspace build could not be created.';
+
+CREATE TABLE template_version_rollouts (
+    id uuid NOT NULL,
+    template_id uuid NOT NULL,
+    template_version_id uuid NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    status template_version_rollout_status DEFAULT 'running'::template_version_rollout_status NOT NULL,
+    percentage integer NOT NULL,
+    group_ids uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    batch_size integer NOT NULL,
+    failure_threshold_percent integer NOT NULL,
+    paused_reason text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON COLUMN template_version_rollouts.created_by IS 'The user that started the rollout. Workspace builds are initiated by this user.';
+
+COMMENT ON COLUMN template_version_rollouts.percentage IS 'Percentage of the cohort that is moved to the template version.';
+
+COMMENT ON COLUMN template_version_rollouts.group_ids IS 'Groups whose members'' workspaces form the cohort. Empty selects every workspace of the template.';
+
+COMMENT ON COLUMN template_version_rollouts.batch_size IS 'Maximum number of workspace builds in progress at once.';
+
+COMMENT ON COLUMN template_version_rollouts.failure_threshold_percent IS 'The rollout pauses when the percentage of failed builds exceeds this value.';
+
 CREATE TABLE template_version_variables (
     template_version_id uuid NOT NULL,
     name text NOT NULL,
@@ -468,7 +785,8 @@ CREATE TABLE template_versions (
     readme character varying(1048576) NOT NULL,
     job_id uuid NOT NULL,
     created_by uuid NOT NULL,
-    git_auth_providers text[]
+    git_auth_providers text[],
+    state template_version_state DEFAULT 'draft'::template_version_state NOT NULL
 );
 
 COMMENT ON COLUMN template_versions.git_auth_providers IS 'IDs of Git auth providers for a specific template version';
@@ -494,7 +812,9 @@ CREATE TABLE templates (
     allow_user_autostart boolean DEFAULT true NOT NULL,
     allow_user_autostop boolean DEFAULT true NOT NULL,
     failure_ttl bigint DEFAULT 0 NOT NULL,
-    inactivity_ttl bigint DEFAULT 0 NOT NULL
+    inactivity_ttl bigint DEFAULT 0 NOT NULL,
+    require_active_version boolean DEFAULT false NOT NULL,
+    max_port_share_level port_share_level DEFAULT 'authenticated'::port_share_level NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -507,15 +827,53 @@ COMMENT ON COLUMN templates.allow_user_autostart IS 'Allow users to specify an a
 
 COMMENT ON COLUMN templates.allow_user_autostop IS 'Allow users to specify custom autostop values for workspaces (enterprise).';
 
+COMMENT ON COLUMN templates.require_active_version IS 'Require workspaces to be started on the active template version.';
+
+COMMENT ON COLUMN templates.max_port_share_level IS 'The least restrictive level workspace owners may share ports with.';
+
 CREATE TABLE user_links (
     user_id uuid NOT NULL,
     login_type login_type NOT NULL,
     linked_id text DEFAULT ''::text NOT NULL,
     oauth_access_token text DEFAULT ''::text NOT NULL,
     oauth_refresh_token text DEFAULT ''::text NOT NULL,
-    oauth_expiry timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL
+    oauth_expiry timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL,
+    oidc_provider_id text DEFAULT ''::text NOT NULL
 );
 
+COMMENT ON COLUMN user_links.oidc_provider_id IS 'The ID of the additional OIDC provider the user is linked to. Empty for the primary provider and other login types.';
+
+CREATE TABLE user_login_failures (
+    user_id uuid NOT NULL,
+    failed_attempts bigint DEFAULT 0 NOT NULL,
+    last_failed_at timestamp with time zone NOT NULL,
+    locked_until timestamp with time zone
+);
+
+COMMENT ON TABLE user_login_failures IS 'Consecutive failed password logins of users. Rows are removed upon a successful login.';
+
+COMMENT ON COLUMN user_login_failures.failed_attempts IS 'Failed attempts since the last successful login or lockout.';
+
+COMMENT ON COLUMN user_login_failures.locked_until IS 'Password logins of the user are rejected until this time.';
+
+CREATE TABLE user_totp (
+    user_id uuid NOT NULL,
+    secret text NOT NULL,
+    enabled boolean DEFAULT false NOT NULL,
+    hashed_recovery_codes text[] DEFAULT '{}'::text[] NOT NULL,
+    last_counter bigint DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE user_totp IS 'Time-based one-time password enrollments of password users.';
+
+COMMENT ON COLUMN user_totp.enabled IS 'Whether the enrollment was verified with a code. Unverified enrollments are not required at login.';
+
+COMMENT ON COLUMN user_totp.hashed_recovery_codes IS 'SHA-256 hashes of the unused recovery codes.';
+
+COMMENT ON COLUMN user_totp.last_counter IS 'The time step of the last accepted code, so codes cannot be reused.';
+
 CREATE TABLE users (
     id uuid NOT NULL,
     email text NOT NULL,
@@ -615,6 +973,7 @@ CREATE TABLE workspace_agents (
     startup_logs_overflowed boolean DEFAULT false NOT NULL,
     subsystem workspace_agent_subsystem DEFAULT 'none'::workspace_agent_subsystem NOT NULL,
     startup_script_behavior startup_script_behavior DEFAULT 'non-blocking'::startup_script_behavior NOT NULL,
+    peer_connections jsonb DEFAULT '[]'::jsonb NOT NULL,
     CONSTRAINT max_startup_logs_length CHECK ((startup_logs_length <= 1048576))
 );
 
@@ -642,6 +1001,47 @@ COMMENT ON COLUMN workspace_agents.startup_logs_overflowed IS 'Whether the start
 
 COMMENT ON COLUMN workspace_agents.startup_script_behavior IS 'When startup script behavior is non-blocking, the workspace will be ready and accessible upon agent connection, when it is blocking, workspace will wait for the startup script to complete before becoming ready and accessible.';
 
+COMMENT ON COLUMN workspace_agents.peer_connections IS 'The latest snapshot of the tailnet connections to the agent, reported with agent stats.';
+
+CREATE TABLE workspace_app_security_keys (
+    id uuid NOT NULL,
+    secret text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone
+);
+
+COMMENT ON TABLE workspace_app_security_keys IS 'Keys used to sign workspace app tokens and encrypt API keys for subdomain apps. The ID of the key is stored in the header of signed and encrypted values.';
+
+COMMENT ON COLUMN workspace_app_security_keys.secret IS 'The hex encoded 96 byte key.';
+
+COMMENT ON COLUMN workspace_app_security_keys.starts_at IS 'New values are signed with the most recent key that has started. Keys are distributed to replicas and workspace proxies before they start.';
+
+COMMENT ON COLUMN workspace_app_security_keys.expires_at IS 'When the key is no longer accepted. Null until the key is replaced by a newer key.';
+
+CREATE TABLE workspace_app_usage_rollups (
+    bucket timestamp with time zone NOT NULL,
+    workspace_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    app_slug text NOT NULL,
+    requests bigint NOT NULL,
+    error_requests bigint NOT NULL,
+    total_latency_ms bigint NOT NULL,
+    bytes_sent bigint NOT NULL
+);
+
+COMMENT ON TABLE workspace_app_usage_rollups IS 'Requests proxied to workspace apps, aggregated per hour, workspace, user and app.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.bucket IS 'The start of the hour the requests were made in.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.user_id IS 'The user that made the requests, or the nil UUID for unauthenticated requests to public apps.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.error_requests IS 'Requests that failed with a 5xx status.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.total_latency_ms IS 'The sum of the time to the response headers of all requests.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.bytes_sent IS 'The sum of the response body bytes sent to the users.';
+
 CREATE TABLE workspace_apps (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -657,9 +1057,12 @@ CREATE TABLE workspace_apps (
     subdomain boolean DEFAULT false NOT NULL,
     sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL,
     slug text NOT NULL,
-    external boolean DEFAULT false NOT NULL
+    external boolean DEFAULT false NOT NULL,
+    sharing_groups text[] DEFAULT '{}'::text[] NOT NULL
 );
 
+COMMENT ON COLUMN workspace_apps.sharing_groups IS 'Names of the groups that may access the app if the sharing level is "group". Names are resolved in the organization of the workspace when the app is accessed.';
+
 CREATE TABLE workspace_build_parameters (
     workspace_build_id uuid NOT NULL,
     name text NOT NULL,
@@ -687,6 +1090,21 @@ CREATE TABLE workspace_builds (
     max_deadline timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL
 );
 
+CREATE TABLE workspace_port_shares (
+    workspace_id uuid NOT NULL,
+    agent_name text NOT NULL,
+    port integer NOT NULL,
+    share_level port_share_level NOT NULL,
+    group_id uuid,
+    expires_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_port_shares IS 'Ports of a workspace agent that the workspace owner shared with other users.';
+
+COMMENT ON COLUMN workspace_port_shares.group_id IS 'The group the port is shared with when share_level is "group".';
+
 CREATE TABLE workspace_proxies (
     id uuid NOT NULL,
     name text NOT NULL,
@@ -765,12 +1183,24 @@ ALTER TABLE ONLY workspace_resource_metadata ALTER COLUMN id SET DEFAULT nextval
 ALTER TABLE ONLY workspace_agent_stats
     ADD CONSTRAINT agent_stats_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY api_key_impersonations
+    ADD CONSTRAINT api_key_impersonations_pkey PRIMARY KEY (api_key_id);
+
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY audit_logs
     ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY derp_region_policies
+    ADD CONSTRAINT derp_region_policies_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY derp_regions
+    ADD CONSTRAINT derp_regions_id_key UNIQUE (id);
+
+ALTER TABLE ONLY derp_regions
+    ADD CONSTRAINT derp_regions_pkey PRIMARY KEY (region_id);
+
 ALTER TABLE ONLY files
     ADD CONSTRAINT files_hash_created_by_key UNIQUE (hash, created_by);
 
@@ -798,6 +1228,39 @@ ALTER TABLE ONLY licenses
 ALTER TABLE ONLY licenses
     ADD CONSTRAINT licenses_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY oauth2_device_codes
+    ADD CONSTRAINT oauth2_device_codes_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY oauth2_device_codes
+    ADD CONSTRAINT oauth2_device_codes_secret_prefix_key UNIQUE (secret_prefix);
+
+ALTER TABLE ONLY oauth2_device_codes
+    ADD CONSTRAINT oauth2_device_codes_user_code_key UNIQUE (user_code);
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_secret_prefix_key UNIQUE (secret_prefix);
+
+ALTER TABLE ONLY oauth2_provider_app_secrets
+    ADD CONSTRAINT oauth2_provider_app_secrets_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY oauth2_provider_app_secrets
+    ADD CONSTRAINT oauth2_provider_app_secrets_secret_prefix_key UNIQUE (secret_prefix);
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_hash_prefix_key UNIQUE (hash_prefix);
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY oauth2_provider_apps
+    ADD CONSTRAINT oauth2_provider_apps_name_key UNIQUE (name);
+
+ALTER TABLE ONLY oauth2_provider_apps
+    ADD CONSTRAINT oauth2_provider_apps_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_pkey PRIMARY KEY (organization_id, user_id);
 
@@ -828,12 +1291,42 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY service_accounts
+    ADD CONSTRAINT service_accounts_pkey PRIMARY KEY (user_id);
+
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
+ALTER TABLE ONLY tailnet_coordinators
+    ADD CONSTRAINT tailnet_coordinators_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY tailnet_peers
+    ADD CONSTRAINT tailnet_peers_pkey PRIMARY KEY (id, coordinator_id);
+
+ALTER TABLE ONLY tailnet_tunnels
+    ADD CONSTRAINT tailnet_tunnels_pkey PRIMARY KEY (coordinator_id, src_id, dst_id);
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_pkey PRIMARY KEY (template_id);
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_name_key UNIQUE (template_version_id, name);
 
+ALTER TABLE ONLY template_version_preset_parameters
+    ADD CONSTRAINT template_version_preset_parameters_pkey PRIMARY KEY (preset_id, name);
+
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_template_version_id_name_key UNIQUE (template_version_id, name);
+
+ALTER TABLE ONLY template_version_rollout_workspaces
+    ADD CONSTRAINT template_version_rollout_workspaces_pkey PRIMARY KEY (rollout_id, workspace_id);
+
+ALTER TABLE ONLY template_version_rollouts
+    ADD CONSTRAINT template_version_rollouts_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY template_version_variables
     ADD CONSTRAINT template_version_variables_template_version_id_name_key UNIQUE (template_version_id, name);
 
@@ -849,6 +1342,12 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_pkey PRIMARY KEY (user_id, login_type);
 
+ALTER TABLE ONLY user_login_failures
+    ADD CONSTRAINT user_login_failures_pkey PRIMARY KEY (user_id);
+
+ALTER TABLE ONLY user_totp
+    ADD CONSTRAINT user_totp_pkey PRIMARY KEY (user_id);
+
 ALTER TABLE ONLY users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
@@ -861,6 +1360,12 @@ ALTER TABLE ONLY workspace_agent_startup_logs
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_app_security_keys
+    ADD CONSTRAINT workspace_app_security_keys_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY workspace_app_usage_rollups
+    ADD CONSTRAINT workspace_app_usage_rollups_pkey PRIMARY KEY (bucket, workspace_id, user_id, app_slug);
+
 ALTER TABLE ONLY workspace_apps
     ADD CONSTRAINT workspace_apps_agent_id_slug_idx UNIQUE (agent_id, slug);
 
@@ -879,6 +1384,9 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
 
+ALTER TABLE ONLY workspace_port_shares
+    ADD CONSTRAINT workspace_port_shares_pkey PRIMARY KEY (workspace_id, agent_name, port);
+
 ALTER TABLE ONLY workspace_proxies
     ADD CONSTRAINT workspace_proxies_pkey PRIMARY KEY (id);
 
@@ -894,6 +1402,12 @@ ALTER TABLE ONLY workspace_resources
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_pkey PRIMARY KEY (id);
 
+CREATE UNIQUE INDEX derp_region_policies_group_id_idx ON derp_region_policies USING btree (group_id) WHERE (group_id IS NOT NULL);
+
+CREATE UNIQUE INDEX derp_region_policies_template_id_idx ON derp_region_policies USING btree (template_id) WHERE (template_id IS NOT NULL);
+
+CREATE UNIQUE INDEX derp_regions_region_code_idx ON derp_regions USING btree (lower(region_code));
+
 CREATE INDEX idx_agent_stats_created_at ON workspace_agent_stats USING btree (created_at);
 
 CREATE INDEX idx_agent_stats_user_id ON workspace_agent_stats USING btree (user_id);
@@ -926,6 +1440,14 @@ CREATE INDEX provisioner_job_logs_id_job_id_idx ON provisioner_job_logs USING bt
 
 CREATE INDEX provisioner_jobs_started_at_idx ON provisioner_jobs USING btree (started_at) WHERE (started_at IS NULL);
 
+CREATE INDEX tailnet_peers_coordinator_id_idx ON tailnet_peers USING btree (coordinator_id);
+
+CREATE INDEX tailnet_tunnels_dst_id_idx ON tailnet_tunnels USING btree (dst_id);
+
+CREATE INDEX tailnet_tunnels_src_id_idx ON tailnet_tunnels USING btree (src_id);
+
+CREATE UNIQUE INDEX template_version_rollouts_template_id_idx ON template_version_rollouts USING btree (template_id) WHERE (status = ANY (ARRAY['running'::template_version_rollout_status, 'paused'::template_version_rollout_status]));
+
 CREATE UNIQUE INDEX templates_organization_id_name_idx ON templates USING btree (organization_id, lower((name)::text)) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX users_email_lower_idx ON users USING btree (lower(email)) WHERE (deleted = false);
@@ -938,19 +1460,35 @@ CREATE INDEX workspace_agents_auth_token_idx ON workspace_agents USING btree (au
 
 CREATE INDEX workspace_agents_resource_id_idx ON workspace_agents USING btree (resource_id);
 
+CREATE INDEX workspace_app_usage_rollups_workspace_id_idx ON workspace_app_usage_rollups USING btree (workspace_id);
+
 CREATE UNIQUE INDEX workspace_proxies_lower_name_idx ON workspace_proxies USING btree (lower(name)) WHERE (deleted = false);
 
 CREATE INDEX workspace_resources_job_id_idx ON workspace_resources USING btree (job_id);
 
 CREATE UNIQUE INDEX workspaces_owner_id_lower_idx ON workspaces USING btree (owner_id, lower((name)::text)) WHERE (deleted = false);
 
+CREATE TRIGGER trigger_delete_oauth2_provider_app_token AFTER DELETE ON oauth2_provider_app_tokens FOR EACH ROW EXECUTE FUNCTION delete_deleted_oauth2_provider_app_token_api_key();
+
 CREATE TRIGGER trigger_insert_apikeys BEFORE INSERT ON api_keys FOR EACH ROW EXECUTE FUNCTION insert_apikey_fail_if_user_deleted();
 
 CREATE TRIGGER trigger_update_users AFTER INSERT OR UPDATE ON users FOR EACH ROW WHEN ((new.deleted = true)) EXECUTE FUNCTION delete_deleted_user_api_keys();
 
+ALTER TABLE ONLY api_key_impersonations
+    ADD CONSTRAINT api_key_impersonations_api_key_id_fkey FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY api_key_impersonations
+    ADD CONSTRAINT api_key_impersonations_impersonator_id_fkey FOREIGN KEY (impersonator_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_user_id_uuid_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY derp_region_policies
+    ADD CONSTRAINT derp_region_policies_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY derp_region_policies
+    ADD CONSTRAINT derp_region_policies_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY gitsshkeys
     ADD CONSTRAINT gitsshkeys_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
 
@@ -963,6 +1501,24 @@ ALTER TABLE ONLY group_members
 ALTER TABLE ONLY groups
     ADD CONSTRAINT groups_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY oauth2_device_codes
+    ADD CONSTRAINT oauth2_device_codes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_secrets
+    ADD CONSTRAINT oauth2_provider_app_secrets_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_api_key_id_fkey FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_app_secret_id_fkey FOREIGN KEY (app_secret_id) REFERENCES oauth2_provider_app_secrets(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_organization_id_uuid_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
@@ -978,9 +1534,51 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY service_accounts
+    ADD CONSTRAINT service_accounts_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id);
+
+ALTER TABLE ONLY service_accounts
+    ADD CONSTRAINT service_accounts_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY service_accounts
+    ADD CONSTRAINT service_accounts_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY tailnet_peers
+    ADD CONSTRAINT tailnet_peers_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY tailnet_tunnels
+    ADD CONSTRAINT tailnet_tunnels_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE ONLY template_git_sources
+    ADD CONSTRAINT template_git_sources_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_version_preset_parameters
+    ADD CONSTRAINT template_version_preset_parameters_preset_id_fkey FOREIGN KEY (preset_id) REFERENCES template_version_presets(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_rollout_workspaces
+    ADD CONSTRAINT template_version_rollout_workspaces_rollout_id_fkey FOREIGN KEY (rollout_id) REFERENCES template_version_rollouts(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_rollout_workspaces
+    ADD CONSTRAINT template_version_rollout_workspaces_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_rollouts
+    ADD CONSTRAINT template_version_rollouts_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE ONLY template_version_rollouts
+    ADD CONSTRAINT template_version_rollouts_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_rollouts
+    ADD CONSTRAINT template_version_rollouts_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_variables
     ADD CONSTRAINT template_version_variables_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
@@ -1002,6 +1600,12 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY user_login_failures
+    ADD CONSTRAINT user_login_failures_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY user_totp
+    ADD CONSTRAINT user_totp_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agent_metadata
     ADD CONSTRAINT workspace_agent_metadata_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
@@ -1011,6 +1615,9 @@ ALTER TABLE ONLY workspace_agent_startup_logs
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_resource_id_fkey FOREIGN KEY (resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_app_usage_rollups
+    ADD CONSTRAINT workspace_app_usage_rollups_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_apps
     ADD CONSTRAINT workspace_apps_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
@@ -1026,6 +1633,12 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_port_shares
+    ADD CONSTRAINT workspace_port_shares_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_port_shares
+    ADD CONSTRAINT workspace_port_shares_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_resource_metadata
     ADD CONSTRAINT workspace_resource_metadata_workspace_resource_id_fkey FOREIGN KEY (workspace_resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
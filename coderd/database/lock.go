@@ -5,4 +5,7 @@ package database
 // same ID.
 const (
 	LockIDDeploymentSetup = iota + 1
+	LockIDTemplateGitSync
+	LockIDTemplateVersionRollout
+	LockIDAppSecurityKeys
 )
@@ -0,0 +1,23 @@
+CREATE TABLE template_git_sources (
+    template_id uuid NOT NULL PRIMARY KEY REFERENCES templates(id) ON DELETE CASCADE,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    created_by uuid NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    repository_url text NOT NULL,
+    ref text NOT NULL,
+    subdirectory text NOT NULL DEFAULT '',
+    git_auth_provider_id text NOT NULL DEFAULT '',
+    poll_interval bigint NOT NULL DEFAULT 0,
+    webhook_secret text NOT NULL DEFAULT '',
+    last_commit_sha text NOT NULL DEFAULT '',
+    last_synced_at timestamp with time zone,
+    last_error text NOT NULL DEFAULT ''
+);
+
+COMMENT ON COLUMN template_git_sources.created_by IS 'The user whose git auth link is used to clone the repository. New template versions are attributed to this user.';
+COMMENT ON COLUMN template_git_sources.ref IS 'Branch or tag to track.';
+COMMENT ON COLUMN template_git_sources.subdirectory IS 'Directory within the repository that contains the template.';
+COMMENT ON COLUMN template_git_sources.git_auth_provider_id IS 'ID of the git auth provider used to authenticate clones. Empty for public repositories.';
+COMMENT ON COLUMN template_git_sources.poll_interval IS 'Interval in nanoseconds between polls of the repository. Zero disables polling.';
+COMMENT ON COLUMN template_git_sources.webhook_secret IS 'Shared secret used to verify push webhooks. Empty disables the webhook.';
+COMMENT ON COLUMN template_git_sources.last_commit_sha IS 'The commit that the most recent template version was created from.';
@@ -0,0 +1,5 @@
+ALTER TABLE templates DROP COLUMN require_active_version;
+
+ALTER TABLE template_versions DROP COLUMN state;
+
+DROP TYPE template_version_state;
@@ -0,0 +1,18 @@
+CREATE TYPE template_version_state AS ENUM (
+    'draft',
+    'active',
+    'deprecated',
+    'archived'
+);
+
+COMMENT ON TYPE template_version_state IS 'Lifecycle state of a template version. "draft" versions have never been promoted, "deprecated" versions warn users on build and "archived" versions can no longer be built.';
+
+ALTER TABLE template_versions ADD COLUMN state template_version_state NOT NULL DEFAULT 'draft';
+
+-- Every version that belongs to a template could have been promoted until now,
+-- so treat them as active.
+UPDATE template_versions SET state = 'active' WHERE template_id IS NOT NULL;
+
+ALTER TABLE templates ADD COLUMN require_active_version boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN templates.require_active_version IS 'Require workspaces to be started on the active template version.';
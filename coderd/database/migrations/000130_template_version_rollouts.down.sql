@@ -0,0 +1,7 @@
+DROP TABLE template_version_rollout_workspaces;
+
+DROP TABLE template_version_rollouts;
+
+DROP TYPE template_version_rollout_workspace_status;
+
+DROP TYPE template_version_rollout_status;
@@ -0,0 +1,51 @@
+CREATE TYPE template_version_rollout_status AS ENUM (
+    'running',
+    'paused',
+    'completed',
+    'aborted'
+);
+
+CREATE TYPE template_version_rollout_workspace_status AS ENUM (
+    'pending',
+    'building',
+    'succeeded',
+    'failed',
+    'skipped'
+);
+
+CREATE TABLE template_version_rollouts (
+    id uuid NOT NULL PRIMARY KEY,
+    template_id uuid NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+    template_version_id uuid NOT NULL REFERENCES template_versions(id) ON DELETE CASCADE,
+    created_by uuid NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    status template_version_rollout_status NOT NULL DEFAULT 'running',
+    percentage integer NOT NULL,
+    group_ids uuid[] NOT NULL DEFAULT '{}',
+    batch_size integer NOT NULL,
+    failure_threshold_percent integer NOT NULL,
+    paused_reason text NOT NULL DEFAULT ''
+);
+
+COMMENT ON COLUMN template_version_rollouts.created_by IS 'The user that started the rollout. Workspace builds are initiated by this user.';
+COMMENT ON COLUMN template_version_rollouts.percentage IS 'Percentage of the cohort that is moved to the template version.';
+COMMENT ON COLUMN template_version_rollouts.group_ids IS 'Groups whose members'' workspaces form the cohort. Empty selects every workspace of the template.';
+COMMENT ON COLUMN template_version_rollouts.batch_size IS 'Maximum number of workspace builds in progress at once.';
+COMMENT ON COLUMN template_version_rollouts.failure_threshold_percent IS 'The rollout pauses when the percentage of failed builds exceeds this value.';
+
+-- Only one rollout per template may be in progress at a time.
+CREATE UNIQUE INDEX template_version_rollouts_template_id_idx ON template_version_rollouts USING btree (template_id) WHERE (status = ANY (ARRAY['running'::template_version_rollout_status, 'paused'::template_version_rollout_status]));
+
+CREATE TABLE template_version_rollout_workspaces (
+    rollout_id uuid NOT NULL REFERENCES template_version_rollouts(id) ON DELETE CASCADE,
+    workspace_id uuid NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    build_id uuid,
+    status template_version_rollout_workspace_status NOT NULL DEFAULT 'pending',
+    error text NOT NULL DEFAULT '',
+    updated_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (rollout_id, workspace_id)
+);
+
+COMMENT ON COLUMN template_version_rollout_workspaces.build_id IS 'The workspace build that moved the workspace to the template version.';
+COMMENT ON COLUMN template_version_rollout_workspaces.error IS 'Reason the workspace build could not be created.';
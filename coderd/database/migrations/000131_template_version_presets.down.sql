@@ -0,0 +1,3 @@
+DROP TABLE template_version_preset_parameters;
+
+DROP TABLE template_version_presets;
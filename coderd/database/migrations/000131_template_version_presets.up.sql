@@ -0,0 +1,17 @@
+CREATE TABLE template_version_presets (
+    id uuid NOT NULL PRIMARY KEY,
+    template_version_id uuid NOT NULL REFERENCES template_versions(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL,
+    UNIQUE (template_version_id, name)
+);
+
+COMMENT ON TABLE template_version_presets IS 'Named sets of rich parameter values that template authors offer when creating a workspace.';
+
+CREATE TABLE template_version_preset_parameters (
+    preset_id uuid NOT NULL REFERENCES template_version_presets(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    value text NOT NULL,
+    PRIMARY KEY (preset_id, name)
+);
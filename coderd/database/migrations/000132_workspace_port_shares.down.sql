@@ -0,0 +1,5 @@
+ALTER TABLE templates DROP COLUMN max_port_share_level;
+
+DROP TABLE workspace_port_shares;
+
+DROP TYPE port_share_level;
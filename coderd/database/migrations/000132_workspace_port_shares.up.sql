@@ -0,0 +1,28 @@
+CREATE TYPE port_share_level AS ENUM (
+    'owner',
+    'group',
+    'authenticated',
+    'public'
+);
+
+COMMENT ON TYPE port_share_level IS 'Who may reach a workspace port through the application proxy, from most to least restrictive.';
+
+CREATE TABLE workspace_port_shares (
+    workspace_id uuid NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    agent_name text NOT NULL,
+    port integer NOT NULL,
+    share_level port_share_level NOT NULL,
+    group_id uuid REFERENCES groups(id) ON DELETE CASCADE,
+    expires_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (workspace_id, agent_name, port)
+);
+
+COMMENT ON TABLE workspace_port_shares IS 'Ports of a workspace agent that the workspace owner shared with other users.';
+
+COMMENT ON COLUMN workspace_port_shares.group_id IS 'The group the port is shared with when share_level is "group".';
+
+ALTER TABLE templates ADD COLUMN max_port_share_level port_share_level NOT NULL DEFAULT 'authenticated';
+
+COMMENT ON COLUMN templates.max_port_share_level IS 'The least restrictive level workspace owners may share ports with.';
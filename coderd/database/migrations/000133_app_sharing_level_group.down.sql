@@ -0,0 +1,5 @@
+-- It's not possible to drop enum values from enum types, so apps shared with
+-- groups fall back to the level "owner".
+UPDATE workspace_apps SET sharing_level = 'owner' WHERE sharing_level = 'group';
+
+ALTER TABLE workspace_apps DROP COLUMN sharing_groups;
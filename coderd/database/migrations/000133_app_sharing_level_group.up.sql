@@ -0,0 +1,9 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE app_sharing_level ADD VALUE IF NOT EXISTS 'group';
+
+ALTER TYPE audit_action ADD VALUE IF NOT EXISTS 'connect';
+
+ALTER TABLE workspace_apps ADD COLUMN sharing_groups text[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN workspace_apps.sharing_groups IS 'Names of the groups that may access the app if the sharing level is "group". Names are resolved in the organization of the workspace when the app is accessed.';
@@ -0,0 +1,9 @@
+INSERT INTO site_configs (key, value)
+SELECT 'app_signing_key', secret
+FROM workspace_app_security_keys
+WHERE starts_at <= NOW()
+ORDER BY starts_at DESC
+LIMIT 1
+ON CONFLICT (key) DO NOTHING;
+
+DROP TABLE workspace_app_security_keys;
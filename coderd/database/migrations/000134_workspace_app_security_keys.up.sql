@@ -0,0 +1,25 @@
+CREATE TABLE workspace_app_security_keys (
+    id uuid NOT NULL PRIMARY KEY,
+    secret text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone
+);
+
+COMMENT ON TABLE workspace_app_security_keys IS 'Keys used to sign workspace app tokens and encrypt API keys for subdomain apps. The ID of the key is stored in the header of signed and encrypted values.';
+
+COMMENT ON COLUMN workspace_app_security_keys.secret IS 'The hex encoded 96 byte key.';
+
+COMMENT ON COLUMN workspace_app_security_keys.starts_at IS 'New values are signed with the most recent key that has started. Keys are distributed to replicas and workspace proxies before they start.';
+
+COMMENT ON COLUMN workspace_app_security_keys.expires_at IS 'When the key is no longer accepted. Null until the key is replaced by a newer key.';
+
+-- Keep the existing key so tokens signed before the upgrade remain valid
+-- until they expire. Those tokens don't have a key ID in their header, so the
+-- key gets the nil UUID that they are verified with.
+INSERT INTO workspace_app_security_keys (id, secret, created_at, starts_at)
+SELECT '00000000-0000-0000-0000-000000000000'::uuid, value, NOW(), NOW()
+FROM site_configs
+WHERE key = 'app_signing_key' AND length(value) = 192;
+
+DELETE FROM site_configs WHERE key = 'app_signing_key';
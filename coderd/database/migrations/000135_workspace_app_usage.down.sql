@@ -0,0 +1 @@
+DROP TABLE workspace_app_usage_rollups;
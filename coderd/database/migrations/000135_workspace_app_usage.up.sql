@@ -0,0 +1,25 @@
+CREATE TABLE workspace_app_usage_rollups (
+    bucket timestamp with time zone NOT NULL,
+    workspace_id uuid NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL,
+    app_slug text NOT NULL,
+    requests bigint NOT NULL,
+    error_requests bigint NOT NULL,
+    total_latency_ms bigint NOT NULL,
+    bytes_sent bigint NOT NULL,
+    PRIMARY KEY (bucket, workspace_id, user_id, app_slug)
+);
+
+COMMENT ON TABLE workspace_app_usage_rollups IS 'Requests proxied to workspace apps, aggregated per hour, workspace, user and app.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.bucket IS 'The start of the hour the requests were made in.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.user_id IS 'The user that made the requests, or the nil UUID for unauthenticated requests to public apps.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.error_requests IS 'Requests that failed with a 5xx status.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.total_latency_ms IS 'The sum of the time to the response headers of all requests.';
+
+COMMENT ON COLUMN workspace_app_usage_rollups.bytes_sent IS 'The sum of the response body bytes sent to the users.';
+
+CREATE INDEX workspace_app_usage_rollups_workspace_id_idx ON workspace_app_usage_rollups USING btree (workspace_id);
@@ -0,0 +1,4 @@
+DROP TABLE tailnet_tunnels;
+DROP TABLE tailnet_peers;
+DROP TABLE tailnet_coordinators;
+DROP TYPE tailnet_status;
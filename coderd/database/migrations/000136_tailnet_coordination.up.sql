@@ -0,0 +1,42 @@
+CREATE TYPE tailnet_status AS ENUM (
+    'ok',
+    'lost'
+);
+
+CREATE TABLE tailnet_coordinators (
+    id uuid NOT NULL PRIMARY KEY,
+    heartbeat_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE tailnet_coordinators IS 'Coder replicas that coordinate tailnet peers, with the time of their last heartbeat.';
+
+CREATE TABLE tailnet_peers (
+    id uuid NOT NULL,
+    coordinator_id uuid NOT NULL REFERENCES tailnet_coordinators(id) ON DELETE CASCADE,
+    updated_at timestamp with time zone NOT NULL,
+    node bytea NOT NULL,
+    status tailnet_status DEFAULT 'ok'::tailnet_status NOT NULL,
+    PRIMARY KEY (id, coordinator_id)
+);
+
+COMMENT ON TABLE tailnet_peers IS 'Tailnet peers connected to a coordinator. A peer that reconnects to another replica may briefly be connected to several coordinators.';
+
+COMMENT ON COLUMN tailnet_peers.node IS 'The protobuf encoded tailnet node of the peer.';
+
+COMMENT ON COLUMN tailnet_peers.status IS 'Peers are lost when they disconnect without saying goodbye, for example because their coordinator shut down.';
+
+CREATE INDEX tailnet_peers_coordinator_id_idx ON tailnet_peers USING btree (coordinator_id);
+
+CREATE TABLE tailnet_tunnels (
+    coordinator_id uuid NOT NULL REFERENCES tailnet_coordinators(id) ON DELETE CASCADE,
+    src_id uuid NOT NULL,
+    dst_id uuid NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (coordinator_id, src_id, dst_id)
+);
+
+COMMENT ON TABLE tailnet_tunnels IS 'Tunnels requested by tailnet peers. The source peer is connected to the coordinator.';
+
+CREATE INDEX tailnet_tunnels_src_id_idx ON tailnet_tunnels USING btree (src_id);
+
+CREATE INDEX tailnet_tunnels_dst_id_idx ON tailnet_tunnels USING btree (dst_id);
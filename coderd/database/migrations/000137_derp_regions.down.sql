@@ -0,0 +1,2 @@
+DROP TABLE derp_region_policies;
+DROP TABLE derp_regions;
@@ -0,0 +1,43 @@
+CREATE TABLE derp_regions (
+    region_id integer NOT NULL PRIMARY KEY,
+    region_code text NOT NULL,
+    region_name text NOT NULL,
+    nodes jsonb DEFAULT '[]'::jsonb NOT NULL,
+    weight integer DEFAULT 1 NOT NULL CHECK (weight >= 0),
+    avoid boolean DEFAULT false NOT NULL,
+    healthy boolean DEFAULT true NOT NULL,
+    health_error text DEFAULT ''::text NOT NULL,
+    last_health_check_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE derp_regions IS 'DERP regions managed by administrators, merged into the DERP map served to clients and agents.';
+
+COMMENT ON COLUMN derp_regions.nodes IS 'The JSON encoded tailcfg.DERPNode list of the region.';
+
+COMMENT ON COLUMN derp_regions.weight IS 'Clients only pick a home region among the usable regions with the highest weight. A weight of zero means the region is never picked as a home region.';
+
+COMMENT ON COLUMN derp_regions.avoid IS 'Clients do not pick avoided regions as their home region, but still use them to reach peers homed there.';
+
+COMMENT ON COLUMN derp_regions.healthy IS 'Unhealthy regions are left out of the DERP map until they pass a health check again.';
+
+CREATE UNIQUE INDEX derp_regions_region_code_idx ON derp_regions USING btree (lower(region_code));
+
+CREATE TABLE derp_region_policies (
+    id uuid NOT NULL PRIMARY KEY,
+    group_id uuid REFERENCES groups(id) ON DELETE CASCADE,
+    template_id uuid REFERENCES templates(id) ON DELETE CASCADE,
+    region_ids integer[] NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    CONSTRAINT derp_region_policies_target_check CHECK (((group_id IS NULL) <> (template_id IS NULL)))
+);
+
+COMMENT ON TABLE derp_region_policies IS 'Restricts the DERP regions used by the clients of a group''s members, or by the agents of a template''s workspaces.';
+
+COMMENT ON COLUMN derp_region_policies.region_ids IS 'The DERP regions that may be used. Regions of the static DERP map must be listed as well to remain usable.';
+
+CREATE UNIQUE INDEX derp_region_policies_group_id_idx ON derp_region_policies USING btree (group_id) WHERE (group_id IS NOT NULL);
+
+CREATE UNIQUE INDEX derp_region_policies_template_id_idx ON derp_region_policies USING btree (template_id) WHERE (template_id IS NOT NULL);
@@ -0,0 +1,3 @@
+ALTER TABLE workspace_agents ADD COLUMN peer_connections jsonb DEFAULT '[]'::jsonb NOT NULL;
+
+COMMENT ON COLUMN workspace_agents.peer_connections IS 'The latest snapshot of the tailnet connections to the agent, reported with agent stats.';
@@ -0,0 +1 @@
+ALTER TABLE user_links DROP COLUMN oidc_provider_id;
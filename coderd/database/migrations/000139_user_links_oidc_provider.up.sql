@@ -0,0 +1,3 @@
+ALTER TABLE user_links ADD COLUMN oidc_provider_id text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN user_links.oidc_provider_id IS 'The ID of the additional OIDC provider the user is linked to. Empty for the primary provider and other login types.';
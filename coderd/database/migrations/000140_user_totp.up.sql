@@ -0,0 +1,14 @@
+CREATE TABLE user_totp (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret text NOT NULL,
+    enabled boolean DEFAULT false NOT NULL,
+    hashed_recovery_codes text[] DEFAULT '{}'::text[] NOT NULL,
+    last_counter bigint DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE user_totp IS 'Time-based one-time password enrollments of password users.';
+COMMENT ON COLUMN user_totp.enabled IS 'Whether the enrollment was verified with a code. Unverified enrollments are not required at login.';
+COMMENT ON COLUMN user_totp.hashed_recovery_codes IS 'SHA-256 hashes of the unused recovery codes.';
+COMMENT ON COLUMN user_totp.last_counter IS 'The time step of the last accepted code, so codes cannot be reused.';
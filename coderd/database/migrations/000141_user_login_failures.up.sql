@@ -0,0 +1,14 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE audit_action ADD VALUE IF NOT EXISTS 'lockout';
+
+CREATE TABLE user_login_failures (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    failed_attempts bigint DEFAULT 0 NOT NULL,
+    last_failed_at timestamp with time zone NOT NULL,
+    locked_until timestamp with time zone
+);
+
+COMMENT ON TABLE user_login_failures IS 'Consecutive failed password logins of users. Rows are removed upon a successful login.';
+COMMENT ON COLUMN user_login_failures.failed_attempts IS 'Failed attempts since the last successful login or lockout.';
+COMMENT ON COLUMN user_login_failures.locked_until IS 'Password logins of the user are rejected until this time.';
@@ -0,0 +1,3 @@
+ALTER TABLE api_keys ADD COLUMN user_agent text DEFAULT '' NOT NULL;
+
+COMMENT ON COLUMN api_keys.user_agent IS 'The user agent of the client that created the key, used to tell sessions apart.';
@@ -0,0 +1,74 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE login_type ADD VALUE IF NOT EXISTS 'oauth2_provider_app';
+ALTER TYPE resource_type ADD VALUE IF NOT EXISTS 'oauth2_provider_app';
+ALTER TYPE resource_type ADD VALUE IF NOT EXISTS 'oauth2_provider_app_secret';
+
+CREATE TABLE oauth2_provider_apps (
+    id uuid NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    name varchar(64) NOT NULL UNIQUE,
+    icon varchar(256) NOT NULL,
+    callback_url text NOT NULL
+);
+
+COMMENT ON TABLE oauth2_provider_apps IS 'Third-party applications that can sign in users with Coder acting as the OAuth2 authorization server.';
+
+CREATE TABLE oauth2_provider_app_secrets (
+    id uuid NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL,
+    last_used_at timestamp with time zone NULL,
+    hashed_secret bytea NOT NULL,
+    secret_prefix bytea NOT NULL UNIQUE,
+    display_secret text NOT NULL,
+    app_id uuid NOT NULL REFERENCES oauth2_provider_apps (id) ON DELETE CASCADE
+);
+
+COMMENT ON COLUMN oauth2_provider_app_secrets.display_secret IS 'The tail end of the original secret so secrets can be told apart.';
+
+CREATE TABLE oauth2_provider_app_codes (
+    id uuid NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    secret_prefix bytea NOT NULL UNIQUE,
+    hashed_secret bytea NOT NULL,
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    app_id uuid NOT NULL REFERENCES oauth2_provider_apps (id) ON DELETE CASCADE,
+    scope api_key_scope NOT NULL,
+    redirect_uri text NOT NULL,
+    code_challenge text NOT NULL
+);
+
+COMMENT ON TABLE oauth2_provider_app_codes IS 'Single-use authorization codes handed out to applications after the user gives consent.';
+COMMENT ON COLUMN oauth2_provider_app_codes.code_challenge IS 'The S256 PKCE challenge sent with the authorization request, empty if the application did not use PKCE.';
+
+CREATE TABLE oauth2_provider_app_tokens (
+    id uuid NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    hash_prefix bytea NOT NULL UNIQUE,
+    refresh_hash bytea NOT NULL,
+    app_secret_id uuid NOT NULL REFERENCES oauth2_provider_app_secrets (id) ON DELETE CASCADE,
+    api_key_id text NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE oauth2_provider_app_tokens IS 'Refresh tokens issued to applications. The access token is the referenced API key.';
+COMMENT ON COLUMN oauth2_provider_app_tokens.refresh_hash IS 'Refresh tokens provide a way to refresh an access token (API key). An expired API key can be refreshed if this token is not yet expired, meaning this expiry can outlive an API key.';
+
+-- Revoking the refresh token, either directly or by deleting the secret or
+-- application it was issued to, must revoke the access token as well.
+CREATE FUNCTION delete_deleted_oauth2_provider_app_token_api_key() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    DELETE FROM api_keys
+    WHERE id = OLD.api_key_id;
+    RETURN OLD;
+END;
+$$;
+
+CREATE TRIGGER trigger_delete_oauth2_provider_app_token
+AFTER DELETE ON oauth2_provider_app_tokens
+FOR EACH ROW
+EXECUTE PROCEDURE delete_deleted_oauth2_provider_app_token_api_key();
@@ -0,0 +1,2 @@
+DROP TABLE oauth2_device_codes;
+DROP TYPE oauth2_device_code_status;
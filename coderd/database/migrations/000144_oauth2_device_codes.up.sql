@@ -0,0 +1,24 @@
+CREATE TYPE oauth2_device_code_status AS ENUM (
+    'pending',
+    'approved',
+    'denied'
+);
+
+CREATE TABLE oauth2_device_codes (
+    id uuid NOT NULL PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    secret_prefix bytea NOT NULL UNIQUE,
+    hashed_secret bytea NOT NULL,
+    user_code text NOT NULL UNIQUE,
+    scope api_key_scope NOT NULL,
+    status oauth2_device_code_status NOT NULL DEFAULT 'pending',
+    user_id uuid NULL REFERENCES users (id) ON DELETE CASCADE,
+    polling_interval integer NOT NULL,
+    last_polled_at timestamp with time zone NULL
+);
+
+COMMENT ON TABLE oauth2_device_codes IS 'Pending device authorization requests, see RFC 8628. Used by the CLI to log in on machines without a browser.';
+COMMENT ON COLUMN oauth2_device_codes.user_code IS 'The short code the user enters on the verification page.';
+COMMENT ON COLUMN oauth2_device_codes.user_id IS 'The user that approved or denied the request.';
+COMMENT ON COLUMN oauth2_device_codes.polling_interval IS 'The minimum number of seconds the device must wait between token requests. It is increased when the device polls too quickly.';
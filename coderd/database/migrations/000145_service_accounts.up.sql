@@ -0,0 +1,10 @@
+CREATE TABLE service_accounts (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    created_by uuid NOT NULL REFERENCES users (id),
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE service_accounts IS 'Users that are not humans. They cannot log in and only authenticate with tokens.';
+COMMENT ON COLUMN service_accounts.organization_id IS 'The organization that owns the service account.';
+COMMENT ON COLUMN service_accounts.created_by IS 'The admin that created the service account. It is recorded on the audit logs of the service account.';
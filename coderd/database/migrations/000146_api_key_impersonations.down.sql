@@ -0,0 +1,4 @@
+DROP TABLE api_key_impersonations;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
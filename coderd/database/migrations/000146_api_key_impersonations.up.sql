@@ -0,0 +1,10 @@
+ALTER TYPE login_type ADD VALUE IF NOT EXISTS 'impersonation';
+
+CREATE TABLE api_key_impersonations (
+    api_key_id text NOT NULL PRIMARY KEY REFERENCES api_keys (id) ON DELETE CASCADE,
+    impersonator_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE api_key_impersonations IS 'The owners that created impersonation API keys, which authenticate as another user.';
+COMMENT ON COLUMN api_key_impersonations.impersonator_id IS 'The owner that is impersonating the user of the API key.';
@@ -0,0 +1,8 @@
+ALTER TABLE derp_regions ADD COLUMN weight integer DEFAULT 1 NOT NULL CHECK (weight >= 0);
+COMMENT ON COLUMN derp_regions.weight IS 'Clients only pick a home region among the usable regions with the highest weight. A weight of zero means the region is never picked as a home region.';
+
+ALTER TABLE derp_regions DROP CONSTRAINT derp_regions_id_key;
+ALTER TABLE derp_regions DROP COLUMN id;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
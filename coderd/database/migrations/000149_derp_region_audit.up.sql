@@ -0,0 +1,10 @@
+ALTER TYPE resource_type ADD VALUE IF NOT EXISTS 'derp_region';
+ALTER TYPE resource_type ADD VALUE IF NOT EXISTS 'derp_region_policy';
+
+-- Audit logs reference resources by UUID.
+ALTER TABLE derp_regions ADD COLUMN id uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE derp_regions ADD CONSTRAINT derp_regions_id_key UNIQUE (id);
+
+-- Clients pick their home region by latency alone, so weights could only be
+-- approximated by avoiding regions behind the admin's back.
+ALTER TABLE derp_regions DROP COLUMN weight;
@@ -0,0 +1,26 @@
+INSERT INTO
+	template_git_sources (
+		template_id,
+		created_at,
+		updated_at,
+		created_by,
+		repository_url,
+		ref,
+		subdirectory,
+		git_auth_provider_id,
+		poll_interval,
+		webhook_secret
+	)
+VALUES
+	(
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'https://github.com/coder/templates.git',
+		'main',
+		'docker',
+		'github',
+		300000000000,
+		'secret'
+	);
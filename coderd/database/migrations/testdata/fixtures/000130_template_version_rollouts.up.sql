@@ -0,0 +1,43 @@
+INSERT INTO
+	template_version_rollouts (
+		id,
+		template_id,
+		template_version_id,
+		created_by,
+		created_at,
+		updated_at,
+		status,
+		percentage,
+		group_ids,
+		batch_size,
+		failure_threshold_percent
+	)
+VALUES
+	(
+		'1b1f5c3a-69a6-4f0e-9b6a-0f2d29f6f0c4',
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		'4e681a60-83da-42c2-902e-6535376ebb77',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'running',
+		50,
+		'{}',
+		10,
+		20
+	);
+
+INSERT INTO
+	template_version_rollout_workspaces (
+		rollout_id,
+		workspace_id,
+		status,
+		updated_at
+	)
+VALUES
+	(
+		'1b1f5c3a-69a6-4f0e-9b6a-0f2d29f6f0c4',
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'pending',
+		'2023-05-01 00:00:00+00'
+	);
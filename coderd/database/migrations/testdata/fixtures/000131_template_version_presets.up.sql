@@ -0,0 +1,29 @@
+INSERT INTO
+	template_version_presets (
+		id,
+		template_version_id,
+		name,
+		description,
+		created_at
+	)
+VALUES
+	(
+		'7f3a2d0c-5b1e-4c8a-9e2f-3d6b1a4c8e90',
+		'4e681a60-83da-42c2-902e-6535376ebb77',
+		'small',
+		'Two cores and four gigabytes of memory',
+		'2023-05-01 00:00:00+00'
+	);
+
+INSERT INTO
+	template_version_preset_parameters (
+		preset_id,
+		name,
+		value
+	)
+VALUES
+	(
+		'7f3a2d0c-5b1e-4c8a-9e2f-3d6b1a4c8e90',
+		'cpu',
+		'2'
+	);
@@ -0,0 +1,18 @@
+INSERT INTO
+	workspace_port_shares (
+		workspace_id,
+		agent_name,
+		port,
+		share_level,
+		created_at,
+		updated_at
+	)
+VALUES
+	(
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'main',
+		8080,
+		'authenticated',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00'
+	);
@@ -0,0 +1,16 @@
+INSERT INTO
+	workspace_app_security_keys (
+		id,
+		secret,
+		created_at,
+		starts_at,
+		expires_at
+	)
+VALUES
+	(
+		'f8c2a5a3-6f0e-4b8a-9d7c-0d6f2b1f9e4a',
+		'6465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e207761732068657265206465616e2077617320686572',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'2023-05-02 00:00:00+00'
+	);
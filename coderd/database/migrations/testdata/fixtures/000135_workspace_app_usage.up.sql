@@ -0,0 +1,22 @@
+INSERT INTO
+	workspace_app_usage_rollups (
+		bucket,
+		workspace_id,
+		user_id,
+		app_slug,
+		requests,
+		error_requests,
+		total_latency_ms,
+		bytes_sent
+	)
+VALUES
+	(
+		'2023-05-01 00:00:00+00',
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'code-server',
+		42,
+		1,
+		1260,
+		52480
+	);
@@ -0,0 +1,25 @@
+INSERT INTO tailnet_coordinators
+	(id, heartbeat_at)
+VALUES
+	('a1b2c3d4-0000-4000-8000-000000000136', '2023-05-01 00:00:00+00');
+
+INSERT INTO tailnet_peers
+	(id, coordinator_id, updated_at, node, status)
+VALUES
+	(
+		'b1b2c3d4-0000-4000-8000-000000000136',
+		'a1b2c3d4-0000-4000-8000-000000000136',
+		'2023-05-01 00:00:00+00',
+		'\x0801'::bytea,
+		'ok'
+	);
+
+INSERT INTO tailnet_tunnels
+	(coordinator_id, src_id, dst_id, updated_at)
+VALUES
+	(
+		'a1b2c3d4-0000-4000-8000-000000000136',
+		'b1b2c3d4-0000-4000-8000-000000000136',
+		'c1b2c3d4-0000-4000-8000-000000000136',
+		'2023-05-01 00:00:00+00'
+	);
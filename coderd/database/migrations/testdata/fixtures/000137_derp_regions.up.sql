@@ -0,0 +1,16 @@
+INSERT INTO derp_regions
+	(region_id, region_code, region_name, nodes, weight, avoid, healthy, health_error, last_health_check_at, created_at, updated_at)
+VALUES
+	(
+		10137,
+		'eu-west',
+		'EU West',
+		'[{"Name":"10137a","RegionID":10137,"HostName":"derp.eu-west.example.com"}]'::jsonb,
+		1,
+		false,
+		true,
+		'',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00'
+	);
@@ -0,0 +1,72 @@
+INSERT INTO
+	oauth2_provider_apps (id, created_at, updated_at, name, icon, callback_url)
+VALUES
+	(
+		'b0a2c6e4-3b5d-4f6a-8c9e-1d2f3a4b5c6d',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'dashboard',
+		'/icon/coder.svg',
+		'https://dashboard.example.com/oauth2/callback'
+	);
+
+INSERT INTO
+	oauth2_provider_app_secrets (id, created_at, last_used_at, hashed_secret, secret_prefix, display_secret, app_id)
+VALUES
+	(
+		'c1b3d7f5-4c6e-4a7b-9daf-2e3a4b5c6d7e',
+		'2023-05-01 00:00:00+00',
+		NULL,
+		'\x6465616e207761732068657265',
+		'\x7365637265745f707265666978',
+		'dean',
+		'b0a2c6e4-3b5d-4f6a-8c9e-1d2f3a4b5c6d'
+	);
+
+INSERT INTO
+	oauth2_provider_app_codes (id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, scope, redirect_uri, code_challenge)
+VALUES
+	(
+		'd2c4e8a6-5d7f-4b8c-aeb0-3f4b5c6d7e8f',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:10:00+00',
+		'\x636f64655f707265666978',
+		'\x6465616e207761732068657265',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'b0a2c6e4-3b5d-4f6a-8c9e-1d2f3a4b5c6d',
+		'all',
+		'https://dashboard.example.com/oauth2/callback',
+		''
+	);
+
+INSERT INTO
+	api_keys (id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent)
+VALUES
+	(
+		'OaWt7rUgC3',
+		'\x6465616e207761732068657265',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-05-01 00:00:00+00',
+		'2023-05-02 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:00:00+00',
+		'oauth2_provider_app',
+		86400,
+		'0.0.0.0',
+		'all',
+		'',
+		''
+	);
+
+INSERT INTO
+	oauth2_provider_app_tokens (id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id)
+VALUES
+	(
+		'e3d5f9b7-6e8a-4c9d-bfc1-4a5c6d7e8f90',
+		'2023-05-01 00:00:00+00',
+		'2023-05-31 00:00:00+00',
+		'\x726566726573685f707265666978',
+		'\x6465616e207761732068657265',
+		'c1b3d7f5-4c6e-4a7b-9daf-2e3a4b5c6d7e',
+		'OaWt7rUgC3'
+	);
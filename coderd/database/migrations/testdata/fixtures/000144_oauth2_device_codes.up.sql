@@ -0,0 +1,16 @@
+INSERT INTO
+	oauth2_device_codes (id, created_at, expires_at, secret_prefix, hashed_secret, user_code, scope, status, user_id, polling_interval, last_polled_at)
+VALUES
+	(
+		'f4e6a0c8-7f9b-4dae-8c02-5b6d7e8f9a01',
+		'2023-05-01 00:00:00+00',
+		'2023-05-01 00:10:00+00',
+		'\x6465766963655f707265666978',
+		'\x6465616e207761732068657265',
+		'BCDF-GHJK',
+		'all',
+		'approved',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		5,
+		'2023-05-01 00:00:05+00'
+	);
@@ -0,0 +1,9 @@
+INSERT INTO
+	service_accounts (user_id, organization_id, created_by, created_at)
+VALUES
+	(
+		'0ed9befc-4911-4ccf-a8e2-559bf72daa94',
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-05-01 00:00:00+00'
+	);
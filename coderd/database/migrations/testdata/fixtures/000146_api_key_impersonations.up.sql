@@ -0,0 +1,8 @@
+INSERT INTO
+	api_key_impersonations (api_key_id, impersonator_id, created_at)
+VALUES
+	(
+		'peuLZhMXt4',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-05-01 00:00:00+00'
+	);
@@ -82,6 +82,8 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 			&i.AllowUserAutostop,
 			&i.FailureTTL,
 			&i.InactivityTTL,
+			&i.RequireActiveVersion,
+			&i.MaxPortShareLevel,
 		); err != nil {
 			return nil, err
 		}
@@ -80,6 +80,7 @@ const (
 	AppSharingLevelOwner         AppSharingLevel = "owner"
 	AppSharingLevelAuthenticated AppSharingLevel = "authenticated"
 	AppSharingLevelPublic        AppSharingLevel = "public"
+	AppSharingLevelGroup         AppSharingLevel = "group"
 )
 
 func (e *AppSharingLevel) Scan(src interface{}) error {
@@ -121,7 +122,8 @@ func (e AppSharingLevel) Valid() bool {
 	switch e {
 	case AppSharingLevelOwner,
 		AppSharingLevelAuthenticated,
-		AppSharingLevelPublic:
+		AppSharingLevelPublic,
+		AppSharingLevelGroup:
 		return true
 	}
 	return false
@@ -132,6 +134,7 @@ func AllAppSharingLevelValues() []AppSharingLevel {
 		AppSharingLevelOwner,
 		AppSharingLevelAuthenticated,
 		AppSharingLevelPublic,
+		AppSharingLevelGroup,
 	}
 }
 
@@ -146,6 +149,8 @@ const (
 	AuditActionLogin    AuditAction = "login"
 	AuditActionLogout   AuditAction = "logout"
 	AuditActionRegister AuditAction = "register"
+	AuditActionConnect  AuditAction = "connect"
+	AuditActionLockout  AuditAction = "lockout"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -192,7 +197,9 @@ func (e AuditAction) Valid() bool {
 		AuditActionStop,
 		AuditActionLogin,
 		AuditActionLogout,
-		AuditActionRegister:
+		AuditActionRegister,
+		AuditActionConnect,
+		AuditActionLockout:
 		return true
 	}
 	return false
@@ -208,6 +215,8 @@ func AllAuditActionValues() []AuditAction {
 		AuditActionLogin,
 		AuditActionLogout,
 		AuditActionRegister,
+		AuditActionConnect,
+		AuditActionLockout,
 	}
 }
 
@@ -401,11 +410,13 @@ func AllLogSourceValues() []LogSource {
 type LoginType string
 
 const (
-	LoginTypePassword LoginType = "password"
-	LoginTypeGithub   LoginType = "github"
-	LoginTypeOIDC     LoginType = "oidc"
-	LoginTypeToken    LoginType = "token"
-	LoginTypeNone     LoginType = "none"
+	LoginTypePassword          LoginType = "password"
+	LoginTypeGithub            LoginType = "github"
+	LoginTypeOIDC              LoginType = "oidc"
+	LoginTypeToken             LoginType = "token"
+	LoginTypeNone              LoginType = "none"
+	LoginTypeOAuth2ProviderApp LoginType = "oauth2_provider_app"
+	LoginTypeImpersonation     LoginType = "impersonation"
 )
 
 func (e *LoginType) Scan(src interface{}) error {
@@ -449,7 +460,9 @@ func (e LoginType) Valid() bool {
 		LoginTypeGithub,
 		LoginTypeOIDC,
 		LoginTypeToken,
-		LoginTypeNone:
+		LoginTypeNone,
+		LoginTypeOAuth2ProviderApp,
+		LoginTypeImpersonation:
 		return true
 	}
 	return false
@@ -462,6 +475,69 @@ func AllLoginTypeValues() []LoginType {
 		LoginTypeOIDC,
 		LoginTypeToken,
 		LoginTypeNone,
+		LoginTypeOAuth2ProviderApp,
+		LoginTypeImpersonation,
+	}
+}
+
+type OAuth2DeviceCodeStatus string
+
+const (
+	OAuth2DeviceCodeStatusPending  OAuth2DeviceCodeStatus = "pending"
+	OAuth2DeviceCodeStatusApproved OAuth2DeviceCodeStatus = "approved"
+	OAuth2DeviceCodeStatusDenied   OAuth2DeviceCodeStatus = "denied"
+)
+
+func (e *OAuth2DeviceCodeStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OAuth2DeviceCodeStatus(s)
+	case string:
+		*e = OAuth2DeviceCodeStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OAuth2DeviceCodeStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOAuth2DeviceCodeStatus struct {
+	OAuth2DeviceCodeStatus OAuth2DeviceCodeStatus
+	Valid                  bool // Valid is true if OAuth2DeviceCodeStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOAuth2DeviceCodeStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OAuth2DeviceCodeStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OAuth2DeviceCodeStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOAuth2DeviceCodeStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OAuth2DeviceCodeStatus), nil
+}
+
+func (e OAuth2DeviceCodeStatus) Valid() bool {
+	switch e {
+	case OAuth2DeviceCodeStatusPending,
+		OAuth2DeviceCodeStatusApproved,
+		OAuth2DeviceCodeStatusDenied:
+		return true
+	}
+	return false
+}
+
+func AllOAuth2DeviceCodeStatusValues() []OAuth2DeviceCodeStatus {
+	return []OAuth2DeviceCodeStatus{
+		OAuth2DeviceCodeStatusPending,
+		OAuth2DeviceCodeStatusApproved,
+		OAuth2DeviceCodeStatusDenied,
 	}
 }
 
@@ -703,6 +779,71 @@ func AllParameterTypeSystemValues() []ParameterTypeSystem {
 	}
 }
 
+// Who may reach a workspace port through the application proxy, from most to least restrictive.
+type PortShareLevel string
+
+const (
+	PortShareLevelOwner         PortShareLevel = "owner"
+	PortShareLevelGroup         PortShareLevel = "group"
+	PortShareLevelAuthenticated PortShareLevel = "authenticated"
+	PortShareLevelPublic        PortShareLevel = "public"
+)
+
+func (e *PortShareLevel) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PortShareLevel(s)
+	case string:
+		*e = PortShareLevel(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PortShareLevel: %T", src)
+	}
+	return nil
+}
+
+type NullPortShareLevel struct {
+	PortShareLevel PortShareLevel
+	Valid          bool // Valid is true if PortShareLevel is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPortShareLevel) Scan(value interface{}) error {
+	if value == nil {
+		ns.PortShareLevel, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PortShareLevel.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPortShareLevel) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PortShareLevel), nil
+}
+
+func (e PortShareLevel) Valid() bool {
+	switch e {
+	case PortShareLevelOwner,
+		PortShareLevelGroup,
+		PortShareLevelAuthenticated,
+		PortShareLevelPublic:
+		return true
+	}
+	return false
+}
+
+func AllPortShareLevelValues() []PortShareLevel {
+	return []PortShareLevel{
+		PortShareLevelOwner,
+		PortShareLevelGroup,
+		PortShareLevelAuthenticated,
+		PortShareLevelPublic,
+	}
+}
+
 type ProvisionerJobType string
 
 const (
@@ -880,17 +1021,23 @@ func AllProvisionerTypeValues() []ProvisionerType {
 type ResourceType string
 
 const (
-	ResourceTypeOrganization    ResourceType = "organization"
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeGitSshKey       ResourceType = "git_ssh_key"
-	ResourceTypeApiKey          ResourceType = "api_key"
-	ResourceTypeGroup           ResourceType = "group"
-	ResourceTypeWorkspaceBuild  ResourceType = "workspace_build"
-	ResourceTypeLicense         ResourceType = "license"
-	ResourceTypeWorkspaceProxy  ResourceType = "workspace_proxy"
+	ResourceTypeOrganization            ResourceType = "organization"
+	ResourceTypeTemplate                ResourceType = "template"
+	ResourceTypeTemplateVersion         ResourceType = "template_version"
+	ResourceTypeUser                    ResourceType = "user"
+	ResourceTypeWorkspace               ResourceType = "workspace"
+	ResourceTypeGitSshKey               ResourceType = "git_ssh_key"
+	ResourceTypeApiKey                  ResourceType = "api_key"
+	ResourceTypeGroup                   ResourceType = "group"
+	ResourceTypeWorkspaceBuild          ResourceType = "workspace_build"
+	ResourceTypeLicense                 ResourceType = "license"
+	ResourceTypeWorkspaceProxy          ResourceType = "workspace_proxy"
+	ResourceTypeOauth2ProviderApp       ResourceType = "oauth2_provider_app"
+	ResourceTypeOauth2ProviderAppSecret ResourceType = "oauth2_provider_app_secret"
+	ResourceTypeTemplateVersionRollout  ResourceType = "template_version_rollout"
+	ResourceTypeWorkspacePortShare      ResourceType = "workspace_port_share"
+	ResourceTypeDerpRegion              ResourceType = "derp_region"
+	ResourceTypeDerpRegionPolicy        ResourceType = "derp_region_policy"
 )
 
 func (e *ResourceType) Scan(src interface{}) error {
@@ -940,7 +1087,13 @@ func (e ResourceType) Valid() bool {
 		ResourceTypeGroup,
 		ResourceTypeWorkspaceBuild,
 		ResourceTypeLicense,
-		ResourceTypeWorkspaceProxy:
+		ResourceTypeWorkspaceProxy,
+		ResourceTypeOauth2ProviderApp,
+		ResourceTypeOauth2ProviderAppSecret,
+		ResourceTypeTemplateVersionRollout,
+		ResourceTypeWorkspacePortShare,
+		ResourceTypeDerpRegion,
+		ResourceTypeDerpRegionPolicy:
 		return true
 	}
 	return false
@@ -959,6 +1112,12 @@ func AllResourceTypeValues() []ResourceType {
 		ResourceTypeWorkspaceBuild,
 		ResourceTypeLicense,
 		ResourceTypeWorkspaceProxy,
+		ResourceTypeOauth2ProviderApp,
+		ResourceTypeOauth2ProviderAppSecret,
+		ResourceTypeTemplateVersionRollout,
+		ResourceTypeWorkspacePortShare,
+		ResourceTypeDerpRegion,
+		ResourceTypeDerpRegionPolicy,
 	}
 }
 
@@ -1020,6 +1179,260 @@ func AllStartupScriptBehaviorValues() []StartupScriptBehavior {
 	}
 }
 
+type TailnetStatus string
+
+const (
+	TailnetStatusOk   TailnetStatus = "ok"
+	TailnetStatusLost TailnetStatus = "lost"
+)
+
+func (e *TailnetStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TailnetStatus(s)
+	case string:
+		*e = TailnetStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TailnetStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTailnetStatus struct {
+	TailnetStatus TailnetStatus
+	Valid         bool // Valid is true if TailnetStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTailnetStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TailnetStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TailnetStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTailnetStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TailnetStatus), nil
+}
+
+func (e TailnetStatus) Valid() bool {
+	switch e {
+	case TailnetStatusOk,
+		TailnetStatusLost:
+		return true
+	}
+	return false
+}
+
+func AllTailnetStatusValues() []TailnetStatus {
+	return []TailnetStatus{
+		TailnetStatusOk,
+		TailnetStatusLost,
+	}
+}
+
+type TemplateVersionRolloutStatus string
+
+const (
+	TemplateVersionRolloutStatusRunning   TemplateVersionRolloutStatus = "running"
+	TemplateVersionRolloutStatusPaused    TemplateVersionRolloutStatus = "paused"
+	TemplateVersionRolloutStatusCompleted TemplateVersionRolloutStatus = "completed"
+	TemplateVersionRolloutStatusAborted   TemplateVersionRolloutStatus = "aborted"
+)
+
+func (e *TemplateVersionRolloutStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateVersionRolloutStatus(s)
+	case string:
+		*e = TemplateVersionRolloutStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateVersionRolloutStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateVersionRolloutStatus struct {
+	TemplateVersionRolloutStatus TemplateVersionRolloutStatus
+	Valid                        bool // Valid is true if TemplateVersionRolloutStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateVersionRolloutStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateVersionRolloutStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateVersionRolloutStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateVersionRolloutStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateVersionRolloutStatus), nil
+}
+
+func (e TemplateVersionRolloutStatus) Valid() bool {
+	switch e {
+	case TemplateVersionRolloutStatusRunning,
+		TemplateVersionRolloutStatusPaused,
+		TemplateVersionRolloutStatusCompleted,
+		TemplateVersionRolloutStatusAborted:
+		return true
+	}
+	return false
+}
+
+func AllTemplateVersionRolloutStatusValues() []TemplateVersionRolloutStatus {
+	return []TemplateVersionRolloutStatus{
+		TemplateVersionRolloutStatusRunning,
+		TemplateVersionRolloutStatusPaused,
+		TemplateVersionRolloutStatusCompleted,
+		TemplateVersionRolloutStatusAborted,
+	}
+}
+
+type TemplateVersionRolloutWorkspaceStatus string
+
+const (
+	TemplateVersionRolloutWorkspaceStatusPending   TemplateVersionRolloutWorkspaceStatus = "pending"
+	TemplateVersionRolloutWorkspaceStatusBuilding  TemplateVersionRolloutWorkspaceStatus = "building"
+	TemplateVersionRolloutWorkspaceStatusSucceeded TemplateVersionRolloutWorkspaceStatus = "succeeded"
+	TemplateVersionRolloutWorkspaceStatusFailed    TemplateVersionRolloutWorkspaceStatus = "failed"
+	TemplateVersionRolloutWorkspaceStatusSkipped   TemplateVersionRolloutWorkspaceStatus = "skipped"
+)
+
+func (e *TemplateVersionRolloutWorkspaceStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateVersionRolloutWorkspaceStatus(s)
+	case string:
+		*e = TemplateVersionRolloutWorkspaceStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateVersionRolloutWorkspaceStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateVersionRolloutWorkspaceStatus struct {
+	TemplateVersionRolloutWorkspaceStatus TemplateVersionRolloutWorkspaceStatus
+	Valid                                 bool // Valid is true if TemplateVersionRolloutWorkspaceStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateVersionRolloutWorkspaceStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateVersionRolloutWorkspaceStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateVersionRolloutWorkspaceStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateVersionRolloutWorkspaceStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateVersionRolloutWorkspaceStatus), nil
+}
+
+func (e TemplateVersionRolloutWorkspaceStatus) Valid() bool {
+	switch e {
+	case TemplateVersionRolloutWorkspaceStatusPending,
+		TemplateVersionRolloutWorkspaceStatusBuilding,
+		TemplateVersionRolloutWorkspaceStatusSucceeded,
+		TemplateVersionRolloutWorkspaceStatusFailed,
+		TemplateVersionRolloutWorkspaceStatusSkipped:
+		return true
+	}
+	return false
+}
+
+func AllTemplateVersionRolloutWorkspaceStatusValues() []TemplateVersionRolloutWorkspaceStatus {
+	return []TemplateVersionRolloutWorkspaceStatus{
+		TemplateVersionRolloutWorkspaceStatusPending,
+		TemplateVersionRolloutWorkspaceStatusBuilding,
+		TemplateVersionRolloutWorkspaceStatusSucceeded,
+		TemplateVersionRolloutWorkspaceStatusFailed,
+		TemplateVersionRolloutWorkspaceStatusSkipped,
+	}
+}
+
+// Lifecycle state of a template version. "draft" versions have never been promoted, "deprecated" versions warn users on build and "archived" versions can no longer be built.
+type TemplateVersionState string
+
+const (
+	TemplateVersionStateDraft      TemplateVersionState = "draft"
+	TemplateVersionStateActive     TemplateVersionState = "active"
+	TemplateVersionStateDeprecated TemplateVersionState = "deprecated"
+	TemplateVersionStateArchived   TemplateVersionState = "archived"
+)
+
+func (e *TemplateVersionState) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateVersionState(s)
+	case string:
+		*e = TemplateVersionState(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateVersionState: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateVersionState struct {
+	TemplateVersionState TemplateVersionState
+	Valid                bool // Valid is true if TemplateVersionState is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateVersionState) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateVersionState, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateVersionState.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateVersionState) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateVersionState), nil
+}
+
+func (e TemplateVersionState) Valid() bool {
+	switch e {
+	case TemplateVersionStateDraft,
+		TemplateVersionStateActive,
+		TemplateVersionStateDeprecated,
+		TemplateVersionStateArchived:
+		return true
+	}
+	return false
+}
+
+func AllTemplateVersionStateValues() []TemplateVersionState {
+	return []TemplateVersionState{
+		TemplateVersionStateDraft,
+		TemplateVersionStateActive,
+		TemplateVersionStateDeprecated,
+		TemplateVersionStateArchived,
+	}
+}
+
 type UserStatus string
 
 const (
@@ -1357,6 +1770,15 @@ type APIKey struct {
 	IPAddress       pqtype.Inet `db:"ip_address" json:"ip_address"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 	TokenName       string      `db:"token_name" json:"token_name"`
+	// The user agent of the client that created the key, used to tell sessions apart.
+	UserAgent string `db:"user_agent" json:"user_agent"`
+}
+
+type APIKeyImpersonation struct {
+	APIKeyID string `db:"api_key_id" json:"api_key_id"`
+	// The owner that is impersonating the user of the API key.
+	ImpersonatorID uuid.UUID `db:"impersonator_id" json:"impersonator_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
 }
 
 type AuditLog struct {
@@ -1377,6 +1799,33 @@ type AuditLog struct {
 	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
 }
 
+type DERPRegionPolicy struct {
+	ID         uuid.UUID     `db:"id" json:"id"`
+	GroupID    uuid.NullUUID `db:"group_id" json:"group_id"`
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	// The DERP regions that may be used. Regions of the static DERP map must be listed as well to remain usable.
+	RegionIDs []int32   `db:"region_ids" json:"region_ids"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type DERPRegion struct {
+	RegionID   int32  `db:"region_id" json:"region_id"`
+	RegionCode string `db:"region_code" json:"region_code"`
+	RegionName string `db:"region_name" json:"region_name"`
+	// The JSON encoded tailcfg.DERPNode list of the region.
+	Nodes json.RawMessage `db:"nodes" json:"nodes"`
+	// Clients do not pick avoided regions as their home region, but still use them to reach peers homed there.
+	Avoid bool `db:"avoid" json:"avoid"`
+	// Unhealthy regions are left out of the DERP map until they pass a health check again.
+	Healthy           bool         `db:"healthy" json:"healthy"`
+	HealthError       string       `db:"health_error" json:"health_error"`
+	LastHealthCheckAt sql.NullTime `db:"last_health_check_at" json:"last_health_check_at"`
+	CreatedAt         time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`
+	ID                uuid.UUID    `db:"id" json:"id"`
+}
+
 type File struct {
 	Hash      string    `db:"hash" json:"hash"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -1426,6 +1875,68 @@ type License struct {
 	UUID uuid.UUID `db:"uuid" json:"uuid"`
 }
 
+type OAuth2DeviceCode struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
+	SecretPrefix []byte    `db:"secret_prefix" json:"secret_prefix"`
+	HashedSecret []byte    `db:"hashed_secret" json:"hashed_secret"`
+	// The short code the user enters on the verification page.
+	UserCode string                 `db:"user_code" json:"user_code"`
+	Scope    APIKeyScope            `db:"scope" json:"scope"`
+	Status   OAuth2DeviceCodeStatus `db:"status" json:"status"`
+	// The user that approved or denied the request.
+	UserID uuid.NullUUID `db:"user_id" json:"user_id"`
+	// The minimum number of seconds the device must wait between token requests. It is increased when the device polls too quickly.
+	PollingInterval int32        `db:"polling_interval" json:"polling_interval"`
+	LastPolledAt    sql.NullTime `db:"last_polled_at" json:"last_polled_at"`
+}
+
+type OAuth2ProviderApp struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Name        string    `db:"name" json:"name"`
+	Icon        string    `db:"icon" json:"icon"`
+	CallbackURL string    `db:"callback_url" json:"callback_url"`
+}
+
+type OAuth2ProviderAppCode struct {
+	ID           uuid.UUID   `db:"id" json:"id"`
+	CreatedAt    time.Time   `db:"created_at" json:"created_at"`
+	ExpiresAt    time.Time   `db:"expires_at" json:"expires_at"`
+	SecretPrefix []byte      `db:"secret_prefix" json:"secret_prefix"`
+	HashedSecret []byte      `db:"hashed_secret" json:"hashed_secret"`
+	UserID       uuid.UUID   `db:"user_id" json:"user_id"`
+	AppID        uuid.UUID   `db:"app_id" json:"app_id"`
+	Scope        APIKeyScope `db:"scope" json:"scope"`
+	RedirectURI  string      `db:"redirect_uri" json:"redirect_uri"`
+	// The S256 PKCE challenge sent with the authorization request, empty if the application did not use PKCE.
+	CodeChallenge string `db:"code_challenge" json:"code_challenge"`
+}
+
+type OAuth2ProviderAppSecret struct {
+	ID           uuid.UUID    `db:"id" json:"id"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+	LastUsedAt   sql.NullTime `db:"last_used_at" json:"last_used_at"`
+	HashedSecret []byte       `db:"hashed_secret" json:"hashed_secret"`
+	SecretPrefix []byte       `db:"secret_prefix" json:"secret_prefix"`
+	// The tail end of the original secret so secrets can be told apart.
+	DisplaySecret string    `db:"display_secret" json:"display_secret"`
+	AppID         uuid.UUID `db:"app_id" json:"app_id"`
+}
+
+type OAuth2ProviderAppToken struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+	HashPrefix []byte    `db:"hash_prefix" json:"hash_prefix"`
+	// Refresh tokens provide a way to refresh an access token (API key). An expired API key can be refreshed if this token is not yet expired, meaning this expiry can outlive an API key.
+	RefreshHash []byte    `db:"refresh_hash" json:"refresh_hash"`
+	AppSecretID uuid.UUID `db:"app_secret_id" json:"app_secret_id"`
+	APIKeyID    string    `db:"api_key_id" json:"api_key_id"`
+}
+
 type Organization struct {
 	ID          uuid.UUID `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
@@ -1529,11 +2040,42 @@ type Replica struct {
 	Error           string       `db:"error" json:"error"`
 }
 
+type ServiceAccount struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	// The organization that owns the service account.
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	// The admin that created the service account. It is recorded on the audit logs of the service account.
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
 type SiteConfig struct {
 	Key   string `db:"key" json:"key"`
 	Value string `db:"value" json:"value"`
 }
 
+type TailnetCoordinator struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	HeartbeatAt time.Time `db:"heartbeat_at" json:"heartbeat_at"`
+}
+
+type TailnetPeer struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	// The protobuf encoded tailnet node of the peer.
+	Node []byte `db:"node" json:"node"`
+	// Peers are lost when they disconnect without saying goodbye, for example because their coordinator shut down.
+	Status TailnetStatus `db:"status" json:"status"`
+}
+
+type TailnetTunnel struct {
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	SrcID         uuid.UUID `db:"src_id" json:"src_id"`
+	DstID         uuid.UUID `db:"dst_id" json:"dst_id"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type Template struct {
 	ID              uuid.UUID       `db:"id" json:"id"`
 	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
@@ -1561,6 +2103,33 @@ type Template struct {
 	AllowUserAutostop bool  `db:"allow_user_autostop" json:"allow_user_autostop"`
 	FailureTTL        int64 `db:"failure_ttl" json:"failure_ttl"`
 	InactivityTTL     int64 `db:"inactivity_ttl" json:"inactivity_ttl"`
+	// Require workspaces to be started on the active template version.
+	RequireActiveVersion bool `db:"require_active_version" json:"require_active_version"`
+	// The least restrictive level workspace owners may share ports with.
+	MaxPortShareLevel PortShareLevel `db:"max_port_share_level" json:"max_port_share_level"`
+}
+
+type TemplateGitSource struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	// The user whose git auth link is used to clone the repository. New template versions are attributed to this user.
+	CreatedBy     uuid.UUID `db:"created_by" json:"created_by"`
+	RepositoryURL string    `db:"repository_url" json:"repository_url"`
+	// Branch or tag to track.
+	Ref string `db:"ref" json:"ref"`
+	// Directory within the repository that contains the template.
+	Subdirectory string `db:"subdirectory" json:"subdirectory"`
+	// ID of the git auth provider used to authenticate clones. Empty for public repositories.
+	GitAuthProviderID string `db:"git_auth_provider_id" json:"git_auth_provider_id"`
+	// Interval in nanoseconds between polls of the repository. Zero disables polling.
+	PollInterval int64 `db:"poll_interval" json:"poll_interval"`
+	// Shared secret used to verify push webhooks. Empty disables the webhook.
+	WebhookSecret string `db:"webhook_secret" json:"webhook_secret"`
+	// The commit that the most recent template version was created from.
+	LastCommitSHA string       `db:"last_commit_sha" json:"last_commit_sha"`
+	LastSyncedAt  sql.NullTime `db:"last_synced_at" json:"last_synced_at"`
+	LastError     string       `db:"last_error" json:"last_error"`
 }
 
 type TemplateVersion struct {
@@ -1574,7 +2143,8 @@ type TemplateVersion struct {
 	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
 	CreatedBy      uuid.UUID     `db:"created_by" json:"created_by"`
 	// IDs of Git auth providers for a specific template version
-	GitAuthProviders []string `db:"git_auth_providers" json:"git_auth_providers"`
+	GitAuthProviders []string             `db:"git_auth_providers" json:"git_auth_providers"`
+	State            TemplateVersionState `db:"state" json:"state"`
 }
 
 type TemplateVersionParameter struct {
@@ -1611,6 +2181,52 @@ type TemplateVersionParameter struct {
 	DisplayName string `db:"display_name" json:"display_name"`
 }
 
+// Named sets of rich parameter values that template authors offer when creating a workspace.
+type TemplateVersionPreset struct {
+	ID                uuid.UUID `db:"id" json:"id"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Description       string    `db:"description" json:"description"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+}
+
+type TemplateVersionPresetParameter struct {
+	PresetID uuid.UUID `db:"preset_id" json:"preset_id"`
+	Name     string    `db:"name" json:"name"`
+	Value    string    `db:"value" json:"value"`
+}
+
+type TemplateVersionRollout struct {
+	ID                uuid.UUID `db:"id" json:"id"`
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	// The user that started the rollout. Workspace builds are initiated by this user.
+	CreatedBy uuid.UUID                    `db:"created_by" json:"created_by"`
+	CreatedAt time.Time                    `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time                    `db:"updated_at" json:"updated_at"`
+	Status    TemplateVersionRolloutStatus `db:"status" json:"status"`
+	// Percentage of the cohort that is moved to the template version.
+	Percentage int32 `db:"percentage" json:"percentage"`
+	// Groups whose members' workspaces form the cohort. Empty selects every workspace of the template.
+	GroupIDs []uuid.UUID `db:"group_ids" json:"group_ids"`
+	// Maximum number of workspace builds in progress at once.
+	BatchSize int32 `db:"batch_size" json:"batch_size"`
+	// The rollout pauses when the percentage of failed builds exceeds this value.
+	FailureThresholdPercent int32  `db:"failure_threshold_percent" json:"failure_threshold_percent"`
+	PausedReason            string `db:"paused_reason" json:"paused_reason"`
+}
+
+type TemplateVersionRolloutWorkspace struct {
+	RolloutID   uuid.UUID `db:"rollout_id" json:"rollout_id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	// The workspace build that moved the workspace to the template version.
+	BuildID uuid.NullUUID                         `db:"build_id" json:"build_id"`
+	Status  TemplateVersionRolloutWorkspaceStatus `db:"status" json:"status"`
+	// Reason the workspace build could not be created.
+	Error     string    `db:"error" json:"error"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type TemplateVersionVariable struct {
 	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
 	// Variable name
@@ -1651,6 +2267,30 @@ type UserLink struct {
 	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
 	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
 	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+	// The ID of the additional OIDC provider the user is linked to. Empty for the primary provider and other login types.
+	OIDCProviderID string `db:"oidc_provider_id" json:"oidc_provider_id"`
+}
+
+type UserLoginFailure struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	// Failed attempts since the last successful login or lockout.
+	FailedAttempts int64     `db:"failed_attempts" json:"failed_attempts"`
+	LastFailedAt   time.Time `db:"last_failed_at" json:"last_failed_at"`
+	// Password logins of the user are rejected until this time.
+	LockedUntil sql.NullTime `db:"locked_until" json:"locked_until"`
+}
+
+type UserTOTP struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Secret string    `db:"secret" json:"secret"`
+	// Whether the enrollment was verified with a code. Unverified enrollments are not required at login.
+	Enabled bool `db:"enabled" json:"enabled"`
+	// SHA-256 hashes of the unused recovery codes.
+	HashedRecoveryCodes []string `db:"hashed_recovery_codes" json:"hashed_recovery_codes"`
+	// The time step of the last accepted code, so codes cannot be reused.
+	LastCounter int64     `db:"last_counter" json:"last_counter"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type Workspace struct {
@@ -1711,6 +2351,8 @@ type WorkspaceAgent struct {
 	Subsystem             WorkspaceAgentSubsystem `db:"subsystem" json:"subsystem"`
 	// When startup script behavior is non-blocking, the workspace will be ready and accessible upon agent connection, when it is blocking, workspace will wait for the startup script to complete before becoming ready and accessible.
 	StartupScriptBehavior StartupScriptBehavior `db:"startup_script_behavior" json:"startup_script_behavior"`
+	// The latest snapshot of the tailnet connections to the agent, reported with agent stats.
+	PeerConnections json.RawMessage `db:"peer_connections" json:"peer_connections"`
 }
 
 type WorkspaceAgentMetadatum struct {
@@ -1771,6 +2413,37 @@ type WorkspaceApp struct {
 	SharingLevel         AppSharingLevel    `db:"sharing_level" json:"sharing_level"`
 	Slug                 string             `db:"slug" json:"slug"`
 	External             bool               `db:"external" json:"external"`
+	// Names of the groups that may access the app if the sharing level is "group". Names are resolved in the organization of the workspace when the app is accessed.
+	SharingGroups []string `db:"sharing_groups" json:"sharing_groups"`
+}
+
+// Keys used to sign workspace app tokens and encrypt API keys for subdomain apps. The ID of the key is stored in the header of signed and encrypted values.
+type WorkspaceAppSecurityKey struct {
+	ID uuid.UUID `db:"id" json:"id"`
+	// The hex encoded 96 byte key.
+	Secret    string    `db:"secret" json:"secret"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// New values are signed with the most recent key that has started. Keys are distributed to replicas and workspace proxies before they start.
+	StartsAt time.Time `db:"starts_at" json:"starts_at"`
+	// When the key is no longer accepted. Null until the key is replaced by a newer key.
+	ExpiresAt sql.NullTime `db:"expires_at" json:"expires_at"`
+}
+
+// Requests proxied to workspace apps, aggregated per hour, workspace, user and app.
+type WorkspaceAppUsageRollup struct {
+	// The start of the hour the requests were made in.
+	Bucket      time.Time `db:"bucket" json:"bucket"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	// The user that made the requests, or the nil UUID for unauthenticated requests to public apps.
+	UserID   uuid.UUID `db:"user_id" json:"user_id"`
+	AppSlug  string    `db:"app_slug" json:"app_slug"`
+	Requests int64     `db:"requests" json:"requests"`
+	// Requests that failed with a 5xx status.
+	ErrorRequests int64 `db:"error_requests" json:"error_requests"`
+	// The sum of the time to the response headers of all requests.
+	TotalLatencyMs int64 `db:"total_latency_ms" json:"total_latency_ms"`
+	// The sum of the response body bytes sent to the users.
+	BytesSent int64 `db:"bytes_sent" json:"bytes_sent"`
 }
 
 type WorkspaceBuild struct {
@@ -1798,6 +2471,19 @@ type WorkspaceBuildParameter struct {
 	Value string `db:"value" json:"value"`
 }
 
+// Ports of a workspace agent that the workspace owner shared with other users.
+type WorkspacePortShare struct {
+	WorkspaceID uuid.UUID      `db:"workspace_id" json:"workspace_id"`
+	AgentName   string         `db:"agent_name" json:"agent_name"`
+	Port        int32          `db:"port" json:"port"`
+	ShareLevel  PortShareLevel `db:"share_level" json:"share_level"`
+	// The group the port is shared with when share_level is "group".
+	GroupID   uuid.NullUUID `db:"group_id" json:"group_id"`
+	ExpiresAt sql.NullTime  `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
+}
+
 type WorkspaceProxy struct {
 	ID          uuid.UUID `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
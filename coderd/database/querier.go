@@ -26,27 +26,78 @@ type sqlcQuerier interface {
 	// multiple provisioners from acquiring the same jobs. See:
 	// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
 	AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error)
+	// Archives every version of a template that is not the active version and is
+	// not used by the latest build of any workspace. If template_version_ids is
+	// not empty, only those versions are considered. Files that are no longer
+	// referenced by a provisioner job, other than the imports of archived
+	// versions, are deleted to reclaim storage.
+	ArchiveUnusedTemplateVersions(ctx context.Context, arg ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error)
+	CleanTailnetLostPeers(ctx context.Context, updatedAt time.Time) error
+	// Deletes the tunnels of peers that have been removed, for example because
+	// they were lost.
+	CleanTailnetLostTunnels(ctx context.Context, updatedAt time.Time) error
 	DeleteAPIKeyByID(ctx context.Context, id string) error
 	DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteAllTailnetTunnels(ctx context.Context, arg DeleteAllTailnetTunnelsParams) error
 	DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteDERPRegionByID(ctx context.Context, regionID int32) error
+	DeleteDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredOAuth2DeviceCodes(ctx context.Context) error
+	// Codes are deleted once they are exchanged, this cleans up the ones that
+	// never were.
+	DeleteExpiredOAuth2ProviderAppCodes(ctx context.Context) error
+	// Deleting a coordinator also deletes its peers and tunnels.
+	DeleteExpiredTailnetCoordinators(ctx context.Context, heartbeatAt time.Time) error
+	DeleteExpiredWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) error
 	DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error
 	DeleteGroupByID(ctx context.Context, id uuid.UUID) error
 	DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error
 	DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error
 	DeleteLicense(ctx context.Context, id int32) (int32, error)
+	// Returns no rows if the code was already deleted, so a device code that is
+	// redeemed concurrently is only exchanged once.
+	DeleteOAuth2DeviceCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	DeleteOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) error
+	// Returns no rows if the code was already deleted, so a code that is redeemed
+	// concurrently is only exchanged once.
+	DeleteOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Context, arg DeleteOAuth2ProviderAppCodesByAppAndUserIDParams) error
+	DeleteOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) error
+	// Returns no rows if the token was already deleted, so a refresh token that is
+	// used concurrently is only exchanged once.
+	DeleteOAuth2ProviderAppTokenByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	// Deleting the API keys deletes the tokens as well through the foreign key.
+	DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Context, arg DeleteOAuth2ProviderAppTokensByAppAndUserIDParams) error
 	// If an agent hasn't connected in the last 7 days, we purge it's logs.
 	// Logs can take up a lot of space, so it's important we clean up frequently.
 	DeleteOldWorkspaceAgentStartupLogs(ctx context.Context) error
 	DeleteOldWorkspaceAgentStats(ctx context.Context) error
+	DeleteOldWorkspaceAppUsageRollups(ctx context.Context) error
 	DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error
+	// Deletes all sessions of the user except the one with the given ID, so users
+	// can sign out everywhere else. The deleted sessions are returned to audit
+	// them.
+	DeleteSessionAPIKeysByUserID(ctx context.Context, arg DeleteSessionAPIKeysByUserIDParams) ([]APIKey, error)
+	DeleteTailnetCoordinator(ctx context.Context, id uuid.UUID) error
+	DeleteTailnetPeer(ctx context.Context, arg DeleteTailnetPeerParams) (DeleteTailnetPeerRow, error)
+	DeleteTailnetTunnel(ctx context.Context, arg DeleteTailnetTunnelParams) (DeleteTailnetTunnelRow, error)
+	DeleteTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	DeleteTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) error
+	DeleteUserLoginFailures(ctx context.Context, userID uuid.UUID) error
+	DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error
+	DeleteWorkspacePortShare(ctx context.Context, arg DeleteWorkspacePortShareParams) error
+	// Sets the expiry of the keys that were replaced by a key starting at
+	// starts_at.
+	ExpireWorkspaceAppSecurityKeys(ctx context.Context, arg ExpireWorkspaceAppSecurityKeysParams) error
 	GetAPIKeyByID(ctx context.Context, id string) (APIKey, error)
 	// there is no unique constraint on empty token names
 	GetAPIKeyByName(ctx context.Context, arg GetAPIKeyByNameParams) (APIKey, error)
+	GetAPIKeyImpersonationByAPIKeyID(ctx context.Context, apiKeyID string) (GetAPIKeyImpersonationByAPIKeyIDRow, error)
 	GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error)
 	GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUserIDParams) ([]APIKey, error)
 	GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error)
+	// Service accounts are not counted, as they are not people.
 	GetActiveUserCount(ctx context.Context) (int64, error)
-	GetAppSecurityKey(ctx context.Context) (string, error)
 	// GetAuditLogsBefore retrieves `row_limit` number of audit logs before the provided
 	// ID.
 	GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOffsetParams) ([]GetAuditLogsOffsetRow, error)
@@ -54,6 +105,14 @@ type sqlcQuerier interface {
 	// are included.
 	GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error)
 	GetDERPMeshKey(ctx context.Context) (string, error)
+	GetDERPRegionByID(ctx context.Context, regionID int32) (DERPRegion, error)
+	GetDERPRegionPolicies(ctx context.Context) ([]DERPRegionPolicy, error)
+	// Returns the policies of all groups the user is a member of, including
+	// the "Everyone" group of each of their organizations.
+	GetDERPRegionPoliciesByUserID(ctx context.Context, userID uuid.UUID) ([]DERPRegionPolicy, error)
+	GetDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) (DERPRegionPolicy, error)
+	GetDERPRegionPolicyByTemplateID(ctx context.Context, templateID uuid.NullUUID) (DERPRegionPolicy, error)
+	GetDERPRegions(ctx context.Context) ([]DERPRegion, error)
 	GetDefaultProxyConfig(ctx context.Context) (GetDefaultProxyConfigRow, error)
 	GetDeploymentDAUs(ctx context.Context, tzOffset int32) ([]GetDeploymentDAUsRow, error)
 	GetDeploymentID(ctx context.Context) (string, error)
@@ -78,6 +137,19 @@ type sqlcQuerier interface {
 	GetLicenseByID(ctx context.Context, id int32) (License, error)
 	GetLicenses(ctx context.Context) ([]License, error)
 	GetLogoURL(ctx context.Context) (string, error)
+	GetOAuth2DeviceCodeByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2DeviceCode, error)
+	GetOAuth2DeviceCodeByUserCode(ctx context.Context, userCode string) (OAuth2DeviceCode, error)
+	GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error)
+	GetOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderAppCode, error)
+	GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2ProviderAppCode, error)
+	GetOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderAppSecret, error)
+	GetOAuth2ProviderAppSecretByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2ProviderAppSecret, error)
+	GetOAuth2ProviderAppSecretsByAppID(ctx context.Context, appID uuid.UUID) ([]OAuth2ProviderAppSecret, error)
+	GetOAuth2ProviderAppTokenByPrefix(ctx context.Context, hashPrefix []byte) (OAuth2ProviderAppToken, error)
+	GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2ProviderApp, error)
+	// Returns the applications the user has authorized, which are the ones holding
+	// tokens on behalf of the user.
+	GetOAuth2ProviderAppsByUserID(ctx context.Context, userID uuid.UUID) ([]OAuth2ProviderApp, error)
 	GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error)
 	GetOrganizationByName(ctx context.Context, name string) (Organization, error)
 	GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error)
@@ -95,15 +167,47 @@ type sqlcQuerier interface {
 	GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error)
 	GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error)
+	// Returns the running rollouts of all templates that have not been deleted.
+	GetRunningTemplateVersionRollouts(ctx context.Context) ([]TemplateVersionRollout, error)
 	GetServiceBanner(ctx context.Context) (string, error)
+	// Sessions are the unexpired keys created by logging in, as opposed to tokens,
+	// the named keys that are handed to workspaces and the keys issued to OAuth2
+	// applications.
+	GetSessionAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
+	GetTailnetCoordinators(ctx context.Context) ([]TailnetCoordinator, error)
+	GetTailnetPeers(ctx context.Context, id uuid.UUID) ([]TailnetPeer, error)
+	// Returns the mappings of all peers that have a tunnel to or from the given
+	// peer.
+	GetTailnetTunnelPeerBindings(ctx context.Context, srcID uuid.UUID) ([]GetTailnetTunnelPeerBindingsRow, error)
+	// Returns the peers that have a tunnel to or from the given peer, and the
+	// coordinators that hold the tunnels.
+	GetTailnetTunnelPeerIDs(ctx context.Context, srcID uuid.UUID) ([]GetTailnetTunnelPeerIDsRow, error)
+	// Returns the apps declared by the active version of each template, so apps
+	// that are never used are included in insights. All templates are included if
+	// template_ids is empty.
+	GetTemplateActiveVersionApps(ctx context.Context, templateIDs []uuid.UUID) ([]GetTemplateActiveVersionAppsRow, error)
 	GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error)
 	GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error)
 	GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error)
 	GetTemplateDAUs(ctx context.Context, arg GetTemplateDAUsParams) ([]GetTemplateDAUsRow, error)
+	GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSource, error)
+	// Returns the git sources of all templates that have not been deleted.
+	GetTemplateGitSources(ctx context.Context) ([]TemplateGitSource, error)
 	GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error)
 	GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionParameter, error)
+	GetTemplateVersionPresetByID(ctx context.Context, id uuid.UUID) (TemplateVersionPreset, error)
+	GetTemplateVersionPresetParametersByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPresetParameter, error)
+	GetTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPreset, error)
+	GetTemplateVersionRolloutByID(ctx context.Context, id uuid.UUID) (TemplateVersionRollout, error)
+	// Returns the IDs of the template's workspaces whose latest build is not on
+	// the template version, ordered by ID. When group IDs are given, only
+	// workspaces owned by members of those groups are returned. The "Everyone"
+	// group shares its ID with the organization and matches every workspace.
+	GetTemplateVersionRolloutCandidates(ctx context.Context, arg GetTemplateVersionRolloutCandidatesParams) ([]uuid.UUID, error)
+	GetTemplateVersionRolloutWorkspacesByRolloutID(ctx context.Context, rolloutID uuid.UUID) ([]GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error)
+	GetTemplateVersionRolloutsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]TemplateVersionRollout, error)
 	GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionVariable, error)
 	GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UUID) ([]TemplateVersion, error)
 	GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error)
@@ -116,6 +220,8 @@ type sqlcQuerier interface {
 	GetUserCount(ctx context.Context) (int64, error)
 	GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error)
 	GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error)
+	GetUserLoginFailuresByUserID(ctx context.Context, userID uuid.UUID) (UserLoginFailure, error)
+	GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (UserTOTP, error)
 	// This will never return deleted users.
 	GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error)
 	// This shouldn't check for deleted, because it's frequently used
@@ -134,6 +240,11 @@ type sqlcQuerier interface {
 	GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceAgent, error)
 	GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]WorkspaceAgent, error)
 	GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg GetWorkspaceAppByAgentIDAndSlugParams) (WorkspaceApp, error)
+	// Returns the keys that have not expired, most recently started first.
+	GetWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) ([]WorkspaceAppSecurityKey, error)
+	// Sums the usage of each app by template between start_time (inclusive) and
+	// end_time (exclusive). All templates are included if template_ids is empty.
+	GetWorkspaceAppUsageInsights(ctx context.Context, arg GetWorkspaceAppUsageInsightsParams) ([]GetWorkspaceAppUsageInsightsRow, error)
 	GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error)
 	GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error)
 	GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error)
@@ -147,6 +258,8 @@ type sqlcQuerier interface {
 	GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Workspace, error)
 	GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWorkspaceByOwnerIDAndNameParams) (Workspace, error)
 	GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceAppID uuid.UUID) (Workspace, error)
+	GetWorkspacePortShare(ctx context.Context, arg GetWorkspacePortShareParams) (WorkspacePortShare, error)
+	GetWorkspacePortSharesByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]WorkspacePortShare, error)
 	GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy, error)
 	// Finds a workspace proxy that has an access URL or app hostname that matches
 	// the provided hostname. This is to check if a hostname matches any workspace
@@ -167,12 +280,15 @@ type sqlcQuerier interface {
 	GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]GetWorkspacesRow, error)
 	GetWorkspacesEligibleForAutoStartStop(ctx context.Context, now time.Time) ([]Workspace, error)
 	InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error)
+	InsertAPIKeyImpersonation(ctx context.Context, arg InsertAPIKeyImpersonationParams) (APIKeyImpersonation, error)
 	// We use the organization_id as the id
 	// for simplicity since all users is
 	// every member of the org.
 	InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error)
 	InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error)
 	InsertDERPMeshKey(ctx context.Context, value string) error
+	InsertDERPRegion(ctx context.Context, arg InsertDERPRegionParams) (DERPRegion, error)
+	InsertDERPRegionPolicy(ctx context.Context, arg InsertDERPRegionPolicyParams) (DERPRegionPolicy, error)
 	InsertDeploymentID(ctx context.Context, value string) error
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
 	InsertGitAuthLink(ctx context.Context, arg InsertGitAuthLinkParams) (GitAuthLink, error)
@@ -180,26 +296,39 @@ type sqlcQuerier interface {
 	InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error)
 	InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error
 	InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error)
+	InsertOAuth2DeviceCode(ctx context.Context, arg InsertOAuth2DeviceCodeParams) (OAuth2DeviceCode, error)
+	InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAuth2ProviderAppParams) (OAuth2ProviderApp, error)
+	InsertOAuth2ProviderAppCode(ctx context.Context, arg InsertOAuth2ProviderAppCodeParams) (OAuth2ProviderAppCode, error)
+	InsertOAuth2ProviderAppSecret(ctx context.Context, arg InsertOAuth2ProviderAppSecretParams) (OAuth2ProviderAppSecret, error)
+	InsertOAuth2ProviderAppToken(ctx context.Context, arg InsertOAuth2ProviderAppTokenParams) (OAuth2ProviderAppToken, error)
 	InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error)
 	InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error)
 	InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error)
 	InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error)
 	InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error)
 	InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error)
+	InsertServiceAccount(ctx context.Context, arg InsertServiceAccountParams) (ServiceAccount, error)
 	InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error)
 	InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error)
 	InsertTemplateVersionParameter(ctx context.Context, arg InsertTemplateVersionParameterParams) (TemplateVersionParameter, error)
+	InsertTemplateVersionPreset(ctx context.Context, arg InsertTemplateVersionPresetParams) (TemplateVersionPreset, error)
+	InsertTemplateVersionPresetParameters(ctx context.Context, arg InsertTemplateVersionPresetParametersParams) error
+	InsertTemplateVersionRollout(ctx context.Context, arg InsertTemplateVersionRolloutParams) (TemplateVersionRollout, error)
+	InsertTemplateVersionRolloutWorkspace(ctx context.Context, arg InsertTemplateVersionRolloutWorkspaceParams) error
 	InsertTemplateVersionVariable(ctx context.Context, arg InsertTemplateVersionVariableParams) (TemplateVersionVariable, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
 	// InsertUserGroupsByName adds a user to all provided groups, if they exist.
 	InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error
 	InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error)
+	// Records a failed login, counting up from any previous failures.
+	InsertUserLoginFailure(ctx context.Context, arg InsertUserLoginFailureParams) (UserLoginFailure, error)
 	InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error)
 	InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspaceAgentParams) (WorkspaceAgent, error)
 	InsertWorkspaceAgentMetadata(ctx context.Context, arg InsertWorkspaceAgentMetadataParams) error
 	InsertWorkspaceAgentStartupLogs(ctx context.Context, arg InsertWorkspaceAgentStartupLogsParams) ([]WorkspaceAgentStartupLog, error)
 	InsertWorkspaceAgentStat(ctx context.Context, arg InsertWorkspaceAgentStatParams) (WorkspaceAgentStat, error)
 	InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error)
+	InsertWorkspaceAppSecurityKey(ctx context.Context, arg InsertWorkspaceAppSecurityKeyParams) (WorkspaceAppSecurityKey, error)
 	InsertWorkspaceBuild(ctx context.Context, arg InsertWorkspaceBuildParams) (WorkspaceBuild, error)
 	InsertWorkspaceBuildParameters(ctx context.Context, arg InsertWorkspaceBuildParametersParams) error
 	InsertWorkspaceProxy(ctx context.Context, arg InsertWorkspaceProxyParams) (WorkspaceProxy, error)
@@ -214,34 +343,52 @@ type sqlcQuerier interface {
 	// Use database.LockID() to generate a unique lock ID from a string.
 	TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error)
 	UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDParams) error
+	UpdateDERPRegionByID(ctx context.Context, arg UpdateDERPRegionByIDParams) (DERPRegion, error)
+	UpdateDERPRegionHealthByID(ctx context.Context, arg UpdateDERPRegionHealthByIDParams) (DERPRegion, error)
+	UpdateDERPRegionPolicyByID(ctx context.Context, arg UpdateDERPRegionPolicyByIDParams) (DERPRegionPolicy, error)
 	UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error)
 	UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error)
 	UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error)
 	UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error)
+	UpdateOAuth2DeviceCodePolledByID(ctx context.Context, arg UpdateOAuth2DeviceCodePolledByIDParams) (OAuth2DeviceCode, error)
+	// Only pending requests can be approved or denied.
+	UpdateOAuth2DeviceCodeStatusByID(ctx context.Context, arg UpdateOAuth2DeviceCodeStatusByIDParams) (OAuth2DeviceCode, error)
+	UpdateOAuth2ProviderAppByID(ctx context.Context, arg UpdateOAuth2ProviderAppByIDParams) (OAuth2ProviderApp, error)
+	UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg UpdateOAuth2ProviderAppSecretByIDParams) (OAuth2ProviderAppSecret, error)
 	UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error
 	UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error
 	UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error
 	UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error)
+	UpdateTailnetPeerStatusByCoordinator(ctx context.Context, arg UpdateTailnetPeerStatusByCoordinatorParams) error
 	UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) (Template, error)
 	UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error
 	UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error
+	UpdateTemplateGitSourceSyncStatus(ctx context.Context, arg UpdateTemplateGitSourceSyncStatusParams) error
 	UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error)
 	UpdateTemplateScheduleByID(ctx context.Context, arg UpdateTemplateScheduleByIDParams) (Template, error)
 	UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) (TemplateVersion, error)
 	UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error
 	UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Context, arg UpdateTemplateVersionGitAuthProvidersByJobIDParams) error
+	UpdateTemplateVersionRolloutStatusByID(ctx context.Context, arg UpdateTemplateVersionRolloutStatusByIDParams) (TemplateVersionRollout, error)
+	UpdateTemplateVersionRolloutWorkspace(ctx context.Context, arg UpdateTemplateVersionRolloutWorkspaceParams) error
+	UpdateTemplateVersionStateByID(ctx context.Context, arg UpdateTemplateVersionStateByIDParams) error
 	UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error
 	UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error
 	UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLastSeenAtParams) (User, error)
 	UpdateUserLink(ctx context.Context, arg UpdateUserLinkParams) (UserLink, error)
 	UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error)
+	// Locks the user out. Failures are counted from zero again once the lockout
+	// ends.
+	UpdateUserLoginLockout(ctx context.Context, arg UpdateUserLoginLockoutParams) (UserLoginFailure, error)
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 	UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
+	UpdateUserTOTP(ctx context.Context, arg UpdateUserTOTPParams) (UserTOTP, error)
 	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
 	UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error
 	UpdateWorkspaceAgentLifecycleStateByID(ctx context.Context, arg UpdateWorkspaceAgentLifecycleStateByIDParams) error
 	UpdateWorkspaceAgentMetadata(ctx context.Context, arg UpdateWorkspaceAgentMetadataParams) error
+	UpdateWorkspaceAgentPeerConnectionsByID(ctx context.Context, arg UpdateWorkspaceAgentPeerConnectionsByIDParams) error
 	UpdateWorkspaceAgentStartupByID(ctx context.Context, arg UpdateWorkspaceAgentStartupByIDParams) error
 	UpdateWorkspaceAgentStartupLogOverflowByID(ctx context.Context, arg UpdateWorkspaceAgentStartupLogOverflowByIDParams) error
 	UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error
@@ -255,7 +402,6 @@ type sqlcQuerier interface {
 	UpdateWorkspaceProxyDeleted(ctx context.Context, arg UpdateWorkspaceProxyDeletedParams) error
 	UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspaceTTLParams) error
 	UpdateWorkspaceTTLToBeWithinTemplateMax(ctx context.Context, arg UpdateWorkspaceTTLToBeWithinTemplateMaxParams) error
-	UpsertAppSecurityKey(ctx context.Context, value string) error
 	// The default proxy is implied and not actually stored in the database.
 	// So we need to store it's configuration here for display purposes.
 	// The functional values are immutable and controlled implicitly.
@@ -263,6 +409,17 @@ type sqlcQuerier interface {
 	UpsertLastUpdateCheck(ctx context.Context, value string) error
 	UpsertLogoURL(ctx context.Context, value string) error
 	UpsertServiceBanner(ctx context.Context, value string) error
+	UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (TailnetCoordinator, error)
+	UpsertTailnetPeer(ctx context.Context, arg UpsertTailnetPeerParams) (TailnetPeer, error)
+	UpsertTailnetTunnel(ctx context.Context, arg UpsertTailnetTunnelParams) (TailnetTunnel, error)
+	UpsertTemplateGitSource(ctx context.Context, arg UpsertTemplateGitSourceParams) (TemplateGitSource, error)
+	// Starts a new enrollment. Any previous enrollment of the user is replaced
+	// and stays disabled until it is verified.
+	UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTOTP, error)
+	// Adds the usage to the rollup of the bucket, creating it if it does not
+	// exist.
+	UpsertWorkspaceAppUsageRollup(ctx context.Context, arg UpsertWorkspaceAppUsageRollupParams) error
+	UpsertWorkspacePortShare(ctx context.Context, arg UpsertWorkspacePortShareParams) (WorkspacePortShare, error)
 }
 
 var _ sqlcQuerier = (*sqlQuerier)(nil)
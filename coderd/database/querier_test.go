@@ -314,3 +314,87 @@ func TestDefaultProxy(t *testing.T) {
 	require.NoError(t, err, "get deployment id")
 	require.Equal(t, depID, found)
 }
+
+func TestOAuth2RedeemOnce(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.SkipNow()
+	}
+	sqlDB := testSQLDB(t)
+	err := migrations.Up(sqlDB)
+	require.NoError(t, err)
+	db := database.New(sqlDB)
+
+	user := dbgen.User(t, db, database.User{})
+	app := dbgen.OAuth2ProviderApp(t, db, database.OAuth2ProviderApp{})
+	secret := dbgen.OAuth2ProviderAppSecret(t, db, database.OAuth2ProviderAppSecret{AppID: app.ID})
+	code := dbgen.OAuth2ProviderAppCode(t, db, database.OAuth2ProviderAppCode{
+		AppID:  app.ID,
+		UserID: user.ID,
+	})
+	key, _ := dbgen.APIKey(t, db, database.APIKey{
+		UserID:    user.ID,
+		LoginType: database.LoginTypeOAuth2ProviderApp,
+	})
+	token := dbgen.OAuth2ProviderAppToken(t, db, database.OAuth2ProviderAppToken{
+		AppSecretID: secret.ID,
+		APIKeyID:    key.ID,
+	})
+	deviceCode := dbgen.OAuth2DeviceCode(t, db, database.OAuth2DeviceCode{})
+
+	for _, tc := range []struct {
+		name   string
+		delete func(ctx context.Context, tx database.Store) (uuid.UUID, error)
+	}{{
+		name: "Code",
+		delete: func(ctx context.Context, tx database.Store) (uuid.UUID, error) {
+			return tx.DeleteOAuth2ProviderAppCodeByID(ctx, code.ID)
+		},
+	}, {
+		name: "Token",
+		delete: func(ctx context.Context, tx database.Store) (uuid.UUID, error) {
+			return tx.DeleteOAuth2ProviderAppTokenByID(ctx, token.ID)
+		},
+	}, {
+		name: "DeviceCode",
+		delete: func(ctx context.Context, tx database.Store) (uuid.UUID, error) {
+			return tx.DeleteOAuth2DeviceCodeByID(ctx, deviceCode.ID)
+		},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testutil.Context(t, testutil.WaitLong)
+
+			// The first transaction deletes the row and holds on to it while
+			// the second one tries to delete it as well.
+			deleted := make(chan struct{})
+			release := make(chan struct{})
+			first := make(chan error, 1)
+			go func() {
+				first <- db.InTx(func(tx database.Store) error {
+					_, err := tc.delete(ctx, tx)
+					close(deleted)
+					if err != nil {
+						return err
+					}
+					<-release
+					return nil
+				}, nil)
+			}()
+			<-deleted
+
+			second := make(chan error, 1)
+			go func() {
+				second <- db.InTx(func(tx database.Store) error {
+					_, err := tc.delete(ctx, tx)
+					return err
+				}, nil)
+			}()
+			close(release)
+
+			require.NoError(t, <-first)
+			require.ErrorIs(t, <-second, sql.ErrNoRows)
+		})
+	}
+}
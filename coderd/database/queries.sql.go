@@ -15,6 +15,62 @@ import (
 	"github.com/tabbed/pqtype"
 )
 
+const getAPIKeyImpersonationByAPIKeyID = `-- name: GetAPIKeyImpersonationByAPIKeyID :one
+SELECT
+	api_key_impersonations.api_key_id, api_key_impersonations.impersonator_id, api_key_impersonations.created_at,
+	users.username AS impersonator_username
+FROM
+	api_key_impersonations
+INNER JOIN
+	users ON users.id = api_key_impersonations.impersonator_id
+WHERE
+	api_key_id = $1
+`
+
+type GetAPIKeyImpersonationByAPIKeyIDRow struct {
+	APIKeyID             string    `db:"api_key_id" json:"api_key_id"`
+	ImpersonatorID       uuid.UUID `db:"impersonator_id" json:"impersonator_id"`
+	CreatedAt            time.Time `db:"created_at" json:"created_at"`
+	ImpersonatorUsername string    `db:"impersonator_username" json:"impersonator_username"`
+}
+
+func (q *sqlQuerier) GetAPIKeyImpersonationByAPIKeyID(ctx context.Context, apiKeyID string) (GetAPIKeyImpersonationByAPIKeyIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyImpersonationByAPIKeyID, apiKeyID)
+	var i GetAPIKeyImpersonationByAPIKeyIDRow
+	err := row.Scan(
+		&i.APIKeyID,
+		&i.ImpersonatorID,
+		&i.CreatedAt,
+		&i.ImpersonatorUsername,
+	)
+	return i, err
+}
+
+const insertAPIKeyImpersonation = `-- name: InsertAPIKeyImpersonation :one
+INSERT INTO
+	api_key_impersonations (
+		api_key_id,
+		impersonator_id,
+		created_at
+	)
+VALUES
+	($1, $2, $3)
+RETURNING api_key_id, impersonator_id, created_at
+`
+
+type InsertAPIKeyImpersonationParams struct {
+	APIKeyID       string    `db:"api_key_id" json:"api_key_id"`
+	ImpersonatorID uuid.UUID `db:"impersonator_id" json:"impersonator_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertAPIKeyImpersonation(ctx context.Context, arg InsertAPIKeyImpersonationParams) (APIKeyImpersonation, error) {
+	row := q.db.QueryRowContext(ctx, insertAPIKeyImpersonation, arg.APIKeyID, arg.ImpersonatorID, arg.CreatedAt)
+	var i APIKeyImpersonation
+	err := row.Scan(&i.APIKeyID, &i.ImpersonatorID, &i.CreatedAt)
+	return i, err
+}
+
 const deleteAPIKeyByID = `-- name: DeleteAPIKeyByID :exec
 DELETE FROM
 	api_keys
@@ -52,9 +108,65 @@ func (q *sqlQuerier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context
 	return err
 }
 
+const deleteSessionAPIKeysByUserID = `-- name: DeleteSessionAPIKeysByUserID :many
+DELETE FROM
+	api_keys
+WHERE
+	user_id = $1 AND
+	login_type NOT IN ('token'::login_type, 'oauth2_provider_app'::login_type) AND
+	token_name = '' AND
+	id != $2
+RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent
+`
+
+type DeleteSessionAPIKeysByUserIDParams struct {
+	UserID   uuid.UUID `db:"user_id" json:"user_id"`
+	ExceptID string    `db:"except_id" json:"except_id"`
+}
+
+// Deletes all sessions of the user except the one with the given ID, so users
+// can sign out everywhere else. The deleted sessions are returned to audit
+// them.
+func (q *sqlQuerier) DeleteSessionAPIKeysByUserID(ctx context.Context, arg DeleteSessionAPIKeysByUserIDParams) ([]APIKey, error) {
+	rows, err := q.db.QueryContext(ctx, deleteSessionAPIKeysByUserID, arg.UserID, arg.ExceptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []APIKey
+	for rows.Next() {
+		var i APIKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.HashedSecret,
+			&i.UserID,
+			&i.LastUsed,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LoginType,
+			&i.LifetimeSeconds,
+			&i.IPAddress,
+			&i.Scope,
+			&i.TokenName,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAPIKeyByID = `-- name: GetAPIKeyByID :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent
 FROM
 	api_keys
 WHERE
@@ -79,13 +191,14 @@ func (q *sqlQuerier) GetAPIKeyByID(ctx context.Context, id string) (APIKey, erro
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		&i.UserAgent,
 	)
 	return i, err
 }
 
 const getAPIKeyByName = `-- name: GetAPIKeyByName :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent
 FROM
 	api_keys
 WHERE
@@ -118,12 +231,13 @@ func (q *sqlQuerier) GetAPIKeyByName(ctx context.Context, arg GetAPIKeyByNamePar
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		&i.UserAgent,
 	)
 	return i, err
 }
 
 const getAPIKeysByLoginType = `-- name: GetAPIKeysByLoginType :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE login_type = $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent FROM api_keys WHERE login_type = $1
 `
 
 func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error) {
@@ -148,6 +262,7 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			&i.UserAgent,
 		); err != nil {
 			return nil, err
 		}
@@ -163,7 +278,7 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 }
 
 const getAPIKeysByUserID = `-- name: GetAPIKeysByUserID :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE login_type = $1 AND user_id = $2
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent FROM api_keys WHERE login_type = $1 AND user_id = $2
 `
 
 type GetAPIKeysByUserIDParams struct {
@@ -193,6 +308,7 @@ func (q *sqlQuerier) GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUse
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			&i.UserAgent,
 		); err != nil {
 			return nil, err
 		}
@@ -208,7 +324,7 @@ func (q *sqlQuerier) GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUse
 }
 
 const getAPIKeysLastUsedAfter = `-- name: GetAPIKeysLastUsedAfter :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE last_used > $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent FROM api_keys WHERE last_used > $1
 `
 
 func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error) {
@@ -233,6 +349,61 @@ func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionAPIKeysByUserID = `-- name: GetSessionAPIKeysByUserID :many
+SELECT
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent
+FROM
+	api_keys
+WHERE
+	user_id = $1 AND
+	login_type NOT IN ('token'::login_type, 'oauth2_provider_app'::login_type) AND
+	token_name = '' AND
+	expires_at > now()
+ORDER BY
+	last_used DESC
+`
+
+// Sessions are the unexpired keys created by logging in, as opposed to tokens,
+// the named keys that are handed to workspaces and the keys issued to OAuth2
+// applications.
+func (q *sqlQuerier) GetSessionAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]APIKey, error) {
+	rows, err := q.db.QueryContext(ctx, getSessionAPIKeysByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []APIKey
+	for rows.Next() {
+		var i APIKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.HashedSecret,
+			&i.UserID,
+			&i.LastUsed,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LoginType,
+			&i.LifetimeSeconds,
+			&i.IPAddress,
+			&i.Scope,
+			&i.TokenName,
+			&i.UserAgent,
 		); err != nil {
 			return nil, err
 		}
@@ -261,7 +432,8 @@ INSERT INTO
 		updated_at,
 		login_type,
 		scope,
-		token_name
+		token_name,
+		user_agent
 	)
 VALUES
 	($1,
@@ -270,7 +442,7 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE $2::bigint
 	 END
-	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, user_agent
 `
 
 type InsertAPIKeyParams struct {
@@ -286,6 +458,7 @@ type InsertAPIKeyParams struct {
 	LoginType       LoginType   `db:"login_type" json:"login_type"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 	TokenName       string      `db:"token_name" json:"token_name"`
+	UserAgent       string      `db:"user_agent" json:"user_agent"`
 }
 
 func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error) {
@@ -302,6 +475,7 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		arg.LoginType,
 		arg.Scope,
 		arg.TokenName,
+		arg.UserAgent,
 	)
 	var i APIKey
 	err := row.Scan(
@@ -317,6 +491,7 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		&i.UserAgent,
 	)
 	return i, err
 }
@@ -636,118 +811,85 @@ func (q *sqlQuerier) InsertAuditLog(ctx context.Context, arg InsertAuditLogParam
 	return i, err
 }
 
-const getFileByHashAndCreator = `-- name: GetFileByHashAndCreator :one
-SELECT
-	hash, created_at, created_by, mimetype, data, id
-FROM
-	files
+const deleteDERPRegionByID = `-- name: DeleteDERPRegionByID :exec
+DELETE FROM
+	derp_regions
 WHERE
-	hash = $1
-AND
-	created_by = $2
-LIMIT
-	1
+	region_id = $1
 `
 
-type GetFileByHashAndCreatorParams struct {
-	Hash      string    `db:"hash" json:"hash"`
-	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+func (q *sqlQuerier) DeleteDERPRegionByID(ctx context.Context, regionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteDERPRegionByID, regionID)
+	return err
 }
 
-func (q *sqlQuerier) GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFileByHashAndCreator, arg.Hash, arg.CreatedBy)
-	var i File
-	err := row.Scan(
-		&i.Hash,
-		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
-	)
-	return i, err
+const deleteDERPRegionPolicyByID = `-- name: DeleteDERPRegionPolicyByID :exec
+DELETE FROM
+	derp_region_policies
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteDERPRegionPolicyByID, id)
+	return err
 }
 
-const getFileByID = `-- name: GetFileByID :one
+const getDERPRegionByID = `-- name: GetDERPRegionByID :one
 SELECT
-	hash, created_at, created_by, mimetype, data, id
+	region_id, region_code, region_name, nodes, avoid, healthy, health_error, last_health_check_at, created_at, updated_at, id
 FROM
-	files
+	derp_regions
 WHERE
-	id = $1
+	region_id = $1
 LIMIT
 	1
 `
 
-func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFileByID, id)
-	var i File
+func (q *sqlQuerier) GetDERPRegionByID(ctx context.Context, regionID int32) (DERPRegion, error) {
+	row := q.db.QueryRowContext(ctx, getDERPRegionByID, regionID)
+	var i DERPRegion
 	err := row.Scan(
-		&i.Hash,
+		&i.RegionID,
+		&i.RegionCode,
+		&i.RegionName,
+		&i.Nodes,
+		&i.Avoid,
+		&i.Healthy,
+		&i.HealthError,
+		&i.LastHealthCheckAt,
 		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
+		&i.UpdatedAt,
 		&i.ID,
 	)
 	return i, err
 }
 
-const getFileTemplates = `-- name: GetFileTemplates :many
+const getDERPRegionPolicies = `-- name: GetDERPRegionPolicies :many
 SELECT
-	files.id AS file_id,
-	files.created_by AS file_created_by,
-	templates.id AS template_id,
-	templates.organization_id AS template_organization_id,
-	templates.created_by AS template_created_by,
-	templates.user_acl,
-	templates.group_acl
+	id, group_id, template_id, region_ids, created_at, updated_at
 FROM
-	templates
-INNER JOIN
-	template_versions
-	ON templates.id = template_versions.template_id
-INNER JOIN
-	provisioner_jobs
-	ON job_id = provisioner_jobs.id
-INNER JOIN
-	files
-	ON files.id = provisioner_jobs.file_id
-WHERE
-    -- Only fetch template version associated files.
-	storage_method = 'file'
-	AND provisioner_jobs.type = 'template_version_import'
-	AND file_id = $1
+	derp_region_policies
+ORDER BY
+	created_at ASC
 `
 
-type GetFileTemplatesRow struct {
-	FileID                 uuid.UUID   `db:"file_id" json:"file_id"`
-	FileCreatedBy          uuid.UUID   `db:"file_created_by" json:"file_created_by"`
-	TemplateID             uuid.UUID   `db:"template_id" json:"template_id"`
-	TemplateOrganizationID uuid.UUID   `db:"template_organization_id" json:"template_organization_id"`
-	TemplateCreatedBy      uuid.UUID   `db:"template_created_by" json:"template_created_by"`
-	UserACL                TemplateACL `db:"user_acl" json:"user_acl"`
-	GroupACL               TemplateACL `db:"group_acl" json:"group_acl"`
-}
-
-// Get all templates that use a file.
-func (q *sqlQuerier) GetFileTemplates(ctx context.Context, fileID uuid.UUID) ([]GetFileTemplatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getFileTemplates, fileID)
+func (q *sqlQuerier) GetDERPRegionPolicies(ctx context.Context) ([]DERPRegionPolicy, error) {
+	rows, err := q.db.QueryContext(ctx, getDERPRegionPolicies)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetFileTemplatesRow
+	var items []DERPRegionPolicy
 	for rows.Next() {
-		var i GetFileTemplatesRow
+		var i DERPRegionPolicy
 		if err := rows.Scan(
-			&i.FileID,
-			&i.FileCreatedBy,
+			&i.ID,
+			&i.GroupID,
 			&i.TemplateID,
-			&i.TemplateOrganizationID,
-			&i.TemplateCreatedBy,
-			&i.UserACL,
-			&i.GroupACL,
+			pq.Array(&i.RegionIDs),
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -762,347 +904,490 @@ func (q *sqlQuerier) GetFileTemplates(ctx context.Context, fileID uuid.UUID) ([]
 	return items, nil
 }
 
-const insertFile = `-- name: InsertFile :one
-INSERT INTO
-	files (id, hash, created_at, created_by, mimetype, "data")
-VALUES
-	($1, $2, $3, $4, $5, $6) RETURNING hash, created_at, created_by, mimetype, data, id
+const getDERPRegionPoliciesByUserID = `-- name: GetDERPRegionPoliciesByUserID :many
+SELECT
+	id, group_id, template_id, region_ids, created_at, updated_at
+FROM
+	derp_region_policies
+WHERE
+	group_id IN (
+		SELECT
+			group_id
+		FROM
+			group_members
+		WHERE
+			group_members.user_id = $1
+		UNION
+		-- The "Everyone" group shares its ID with the organization.
+		SELECT
+			organization_id
+		FROM
+			organization_members
+		WHERE
+			organization_members.user_id = $1
+	)
 `
 
-type InsertFileParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Hash      string    `db:"hash" json:"hash"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
-	Mimetype  string    `db:"mimetype" json:"mimetype"`
-	Data      []byte    `db:"data" json:"data"`
-}
-
-func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, insertFile,
-		arg.ID,
-		arg.Hash,
-		arg.CreatedAt,
-		arg.CreatedBy,
-		arg.Mimetype,
-		arg.Data,
-	)
-	var i File
-	err := row.Scan(
-		&i.Hash,
-		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
-	)
-	return i, err
+// Returns the policies of all groups the user is a member of, including
+// the "Everyone" group of each of their organizations.
+func (q *sqlQuerier) GetDERPRegionPoliciesByUserID(ctx context.Context, userID uuid.UUID) ([]DERPRegionPolicy, error) {
+	rows, err := q.db.QueryContext(ctx, getDERPRegionPoliciesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DERPRegionPolicy
+	for rows.Next() {
+		var i DERPRegionPolicy
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.TemplateID,
+			pq.Array(&i.RegionIDs),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getGitAuthLink = `-- name: GetGitAuthLink :one
-SELECT provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry FROM git_auth_links WHERE provider_id = $1 AND user_id = $2
+const getDERPRegionPolicyByID = `-- name: GetDERPRegionPolicyByID :one
+SELECT
+	id, group_id, template_id, region_ids, created_at, updated_at
+FROM
+	derp_region_policies
+WHERE
+	id = $1
+LIMIT
+	1
 `
 
-type GetGitAuthLinkParams struct {
-	ProviderID string    `db:"provider_id" json:"provider_id"`
-	UserID     uuid.UUID `db:"user_id" json:"user_id"`
-}
-
-func (q *sqlQuerier) GetGitAuthLink(ctx context.Context, arg GetGitAuthLinkParams) (GitAuthLink, error) {
-	row := q.db.QueryRowContext(ctx, getGitAuthLink, arg.ProviderID, arg.UserID)
-	var i GitAuthLink
+func (q *sqlQuerier) GetDERPRegionPolicyByID(ctx context.Context, id uuid.UUID) (DERPRegionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getDERPRegionPolicyByID, id)
+	var i DERPRegionPolicy
 	err := row.Scan(
-		&i.ProviderID,
-		&i.UserID,
+		&i.ID,
+		&i.GroupID,
+		&i.TemplateID,
+		pq.Array(&i.RegionIDs),
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const insertGitAuthLink = `-- name: InsertGitAuthLink :one
-INSERT INTO git_auth_links (
-    provider_id,
-    user_id,
-    created_at,
-    updated_at,
-    oauth_access_token,
-    oauth_refresh_token,
-    oauth_expiry
-) VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7
-) RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
+const getDERPRegionPolicyByTemplateID = `-- name: GetDERPRegionPolicyByTemplateID :one
+SELECT
+	id, group_id, template_id, region_ids, created_at, updated_at
+FROM
+	derp_region_policies
+WHERE
+	template_id = $1
+LIMIT
+	1
 `
 
-type InsertGitAuthLinkParams struct {
-	ProviderID        string    `db:"provider_id" json:"provider_id"`
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt         time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
-}
-
-func (q *sqlQuerier) InsertGitAuthLink(ctx context.Context, arg InsertGitAuthLinkParams) (GitAuthLink, error) {
-	row := q.db.QueryRowContext(ctx, insertGitAuthLink,
-		arg.ProviderID,
-		arg.UserID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
-	)
-	var i GitAuthLink
+func (q *sqlQuerier) GetDERPRegionPolicyByTemplateID(ctx context.Context, templateID uuid.NullUUID) (DERPRegionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getDERPRegionPolicyByTemplateID, templateID)
+	var i DERPRegionPolicy
 	err := row.Scan(
-		&i.ProviderID,
-		&i.UserID,
+		&i.ID,
+		&i.GroupID,
+		&i.TemplateID,
+		pq.Array(&i.RegionIDs),
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const updateGitAuthLink = `-- name: UpdateGitAuthLink :one
-UPDATE git_auth_links SET
-    updated_at = $3,
-    oauth_access_token = $4,
-    oauth_refresh_token = $5,
-    oauth_expiry = $6
-WHERE provider_id = $1 AND user_id = $2 RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
+const getDERPRegions = `-- name: GetDERPRegions :many
+SELECT
+	region_id, region_code, region_name, nodes, avoid, healthy, health_error, last_health_check_at, created_at, updated_at, id
+FROM
+	derp_regions
+ORDER BY
+	region_id ASC
 `
 
-type UpdateGitAuthLinkParams struct {
-	ProviderID        string    `db:"provider_id" json:"provider_id"`
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+func (q *sqlQuerier) GetDERPRegions(ctx context.Context) ([]DERPRegion, error) {
+	rows, err := q.db.QueryContext(ctx, getDERPRegions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DERPRegion
+	for rows.Next() {
+		var i DERPRegion
+		if err := rows.Scan(
+			&i.RegionID,
+			&i.RegionCode,
+			&i.RegionName,
+			&i.Nodes,
+			&i.Avoid,
+			&i.Healthy,
+			&i.HealthError,
+			&i.LastHealthCheckAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *sqlQuerier) UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error) {
-	row := q.db.QueryRowContext(ctx, updateGitAuthLink,
-		arg.ProviderID,
-		arg.UserID,
-		arg.UpdatedAt,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
-	)
-	var i GitAuthLink
-	err := row.Scan(
-		&i.ProviderID,
-		&i.UserID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+const insertDERPRegion = `-- name: InsertDERPRegion :one
+INSERT INTO
+	derp_regions (
+		id,
+		region_id,
+		region_code,
+		region_name,
+		nodes,
+		avoid,
+		created_at,
+		updated_at
 	)
-	return i, err
-}
-
-const deleteGitSSHKey = `-- name: DeleteGitSSHKey :exec
-DELETE FROM
-	gitsshkeys
-WHERE
-	user_id = $1
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING region_id, region_code, region_name, nodes, avoid, healthy, health_error, last_health_check_at, created_at, updated_at, id
 `
 
-func (q *sqlQuerier) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteGitSSHKey, userID)
-	return err
+type InsertDERPRegionParams struct {
+	ID         uuid.UUID       `db:"id" json:"id"`
+	RegionID   int32           `db:"region_id" json:"region_id"`
+	RegionCode string          `db:"region_code" json:"region_code"`
+	RegionName string          `db:"region_name" json:"region_name"`
+	Nodes      json.RawMessage `db:"nodes" json:"nodes"`
+	Avoid      bool            `db:"avoid" json:"avoid"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time       `db:"updated_at" json:"updated_at"`
 }
 
-const getGitSSHKey = `-- name: GetGitSSHKey :one
-SELECT
-	user_id, created_at, updated_at, private_key, public_key
-FROM
-	gitsshkeys
-WHERE
-	user_id = $1
-`
-
-func (q *sqlQuerier) GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error) {
-	row := q.db.QueryRowContext(ctx, getGitSSHKey, userID)
-	var i GitSSHKey
+func (q *sqlQuerier) InsertDERPRegion(ctx context.Context, arg InsertDERPRegionParams) (DERPRegion, error) {
+	row := q.db.QueryRowContext(ctx, insertDERPRegion,
+		arg.ID,
+		arg.RegionID,
+		arg.RegionCode,
+		arg.RegionName,
+		arg.Nodes,
+		arg.Avoid,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i DERPRegion
 	err := row.Scan(
-		&i.UserID,
+		&i.RegionID,
+		&i.RegionCode,
+		&i.RegionName,
+		&i.Nodes,
+		&i.Avoid,
+		&i.Healthy,
+		&i.HealthError,
+		&i.LastHealthCheckAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PrivateKey,
-		&i.PublicKey,
+		&i.ID,
 	)
 	return i, err
 }
 
-const insertGitSSHKey = `-- name: InsertGitSSHKey :one
+const insertDERPRegionPolicy = `-- name: InsertDERPRegionPolicy :one
 INSERT INTO
-	gitsshkeys (
-		user_id,
+	derp_region_policies (
+		id,
+		group_id,
+		template_id,
+		region_ids,
 		created_at,
-		updated_at,
-		private_key,
-		public_key
+		updated_at
 	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING user_id, created_at, updated_at, private_key, public_key
+	($1, $2, $3, $4, $5, $6) RETURNING id, group_id, template_id, region_ids, created_at, updated_at
 `
 
-type InsertGitSSHKeyParams struct {
-	UserID     uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
-	PrivateKey string    `db:"private_key" json:"private_key"`
-	PublicKey  string    `db:"public_key" json:"public_key"`
+type InsertDERPRegionPolicyParams struct {
+	ID         uuid.UUID     `db:"id" json:"id"`
+	GroupID    uuid.NullUUID `db:"group_id" json:"group_id"`
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	RegionIDs  []int32       `db:"region_ids" json:"region_ids"`
+	CreatedAt  time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) InsertGitSSHKey(ctx context.Context, arg InsertGitSSHKeyParams) (GitSSHKey, error) {
-	row := q.db.QueryRowContext(ctx, insertGitSSHKey,
-		arg.UserID,
+func (q *sqlQuerier) InsertDERPRegionPolicy(ctx context.Context, arg InsertDERPRegionPolicyParams) (DERPRegionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, insertDERPRegionPolicy,
+		arg.ID,
+		arg.GroupID,
+		arg.TemplateID,
+		pq.Array(arg.RegionIDs),
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.PrivateKey,
-		arg.PublicKey,
 	)
-	var i GitSSHKey
+	var i DERPRegionPolicy
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.GroupID,
+		&i.TemplateID,
+		pq.Array(&i.RegionIDs),
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PrivateKey,
-		&i.PublicKey,
 	)
 	return i, err
 }
 
-const updateGitSSHKey = `-- name: UpdateGitSSHKey :one
+const updateDERPRegionByID = `-- name: UpdateDERPRegionByID :one
 UPDATE
-	gitsshkeys
+	derp_regions
 SET
-	updated_at = $2,
-	private_key = $3,
-	public_key = $4
+	region_code = $2,
+	region_name = $3,
+	nodes = $4,
+	avoid = $5,
+	updated_at = $6
 WHERE
-	user_id = $1
-RETURNING
-	user_id, created_at, updated_at, private_key, public_key
+	region_id = $1
+RETURNING region_id, region_code, region_name, nodes, avoid, healthy, health_error, last_health_check_at, created_at, updated_at, id
 `
 
-type UpdateGitSSHKeyParams struct {
-	UserID     uuid.UUID `db:"user_id" json:"user_id"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
-	PrivateKey string    `db:"private_key" json:"private_key"`
-	PublicKey  string    `db:"public_key" json:"public_key"`
+type UpdateDERPRegionByIDParams struct {
+	RegionID   int32           `db:"region_id" json:"region_id"`
+	RegionCode string          `db:"region_code" json:"region_code"`
+	RegionName string          `db:"region_name" json:"region_name"`
+	Nodes      json.RawMessage `db:"nodes" json:"nodes"`
+	Avoid      bool            `db:"avoid" json:"avoid"`
+	UpdatedAt  time.Time       `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error) {
-	row := q.db.QueryRowContext(ctx, updateGitSSHKey,
-		arg.UserID,
+func (q *sqlQuerier) UpdateDERPRegionByID(ctx context.Context, arg UpdateDERPRegionByIDParams) (DERPRegion, error) {
+	row := q.db.QueryRowContext(ctx, updateDERPRegionByID,
+		arg.RegionID,
+		arg.RegionCode,
+		arg.RegionName,
+		arg.Nodes,
+		arg.Avoid,
 		arg.UpdatedAt,
-		arg.PrivateKey,
-		arg.PublicKey,
 	)
-	var i GitSSHKey
+	var i DERPRegion
 	err := row.Scan(
-		&i.UserID,
+		&i.RegionID,
+		&i.RegionCode,
+		&i.RegionName,
+		&i.Nodes,
+		&i.Avoid,
+		&i.Healthy,
+		&i.HealthError,
+		&i.LastHealthCheckAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PrivateKey,
-		&i.PublicKey,
+		&i.ID,
 	)
 	return i, err
 }
 
-const deleteGroupMemberFromGroup = `-- name: DeleteGroupMemberFromGroup :exec
-DELETE FROM
-	group_members
+const updateDERPRegionHealthByID = `-- name: UpdateDERPRegionHealthByID :one
+UPDATE
+	derp_regions
+SET
+	healthy = $2,
+	health_error = $3,
+	last_health_check_at = $4
 WHERE
-	user_id = $1 AND
-	group_id = $2
+	region_id = $1
+RETURNING region_id, region_code, region_name, nodes, avoid, healthy, health_error, last_health_check_at, created_at, updated_at, id
 `
 
-type DeleteGroupMemberFromGroupParams struct {
-	UserID  uuid.UUID `db:"user_id" json:"user_id"`
-	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+type UpdateDERPRegionHealthByIDParams struct {
+	RegionID          int32        `db:"region_id" json:"region_id"`
+	Healthy           bool         `db:"healthy" json:"healthy"`
+	HealthError       string       `db:"health_error" json:"health_error"`
+	LastHealthCheckAt sql.NullTime `db:"last_health_check_at" json:"last_health_check_at"`
 }
 
-func (q *sqlQuerier) DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupMemberFromGroup, arg.UserID, arg.GroupID)
-	return err
+func (q *sqlQuerier) UpdateDERPRegionHealthByID(ctx context.Context, arg UpdateDERPRegionHealthByIDParams) (DERPRegion, error) {
+	row := q.db.QueryRowContext(ctx, updateDERPRegionHealthByID,
+		arg.RegionID,
+		arg.Healthy,
+		arg.HealthError,
+		arg.LastHealthCheckAt,
+	)
+	var i DERPRegion
+	err := row.Scan(
+		&i.RegionID,
+		&i.RegionCode,
+		&i.RegionName,
+		&i.Nodes,
+		&i.Avoid,
+		&i.Healthy,
+		&i.HealthError,
+		&i.LastHealthCheckAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ID,
+	)
+	return i, err
 }
 
-const deleteGroupMembersByOrgAndUser = `-- name: DeleteGroupMembersByOrgAndUser :exec
-DELETE FROM
-	group_members
+const updateDERPRegionPolicyByID = `-- name: UpdateDERPRegionPolicyByID :one
+UPDATE
+	derp_region_policies
+SET
+	region_ids = $2,
+	updated_at = $3
 WHERE
-	group_members.user_id = $1
-	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = $2)
+	id = $1
+RETURNING id, group_id, template_id, region_ids, created_at, updated_at
 `
 
-type DeleteGroupMembersByOrgAndUserParams struct {
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+type UpdateDERPRegionPolicyByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	RegionIDs []int32   `db:"region_ids" json:"region_ids"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupMembersByOrgAndUser, arg.UserID, arg.OrganizationID)
-	return err
+func (q *sqlQuerier) UpdateDERPRegionPolicyByID(ctx context.Context, arg UpdateDERPRegionPolicyByIDParams) (DERPRegionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, updateDERPRegionPolicyByID, arg.ID, pq.Array(arg.RegionIDs), arg.UpdatedAt)
+	var i DERPRegionPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.TemplateID,
+		pq.Array(&i.RegionIDs),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-const getGroupMembers = `-- name: GetGroupMembers :many
+const getFileByHashAndCreator = `-- name: GetFileByHashAndCreator :one
 SELECT
-	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
+	hash, created_at, created_by, mimetype, data, id
 FROM
-	users
-JOIN
-	group_members
-ON
-	users.id = group_members.user_id
+	files
 WHERE
-	group_members.group_id = $1
-AND
-	users.status = 'active'
+	hash = $1
 AND
-	users.deleted = 'false'
+	created_by = $2
+LIMIT
+	1
 `
 
-func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupMembers, groupID)
+type GetFileByHashAndCreatorParams struct {
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *sqlQuerier) GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByHashAndCreator, arg.Hash, arg.CreatedBy)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+	)
+	return i, err
+}
+
+const getFileByID = `-- name: GetFileByID :one
+SELECT
+	hash, created_at, created_by, mimetype, data, id
+FROM
+	files
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByID, id)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+	)
+	return i, err
+}
+
+const getFileTemplates = `-- name: GetFileTemplates :many
+SELECT
+	files.id AS file_id,
+	files.created_by AS file_created_by,
+	templates.id AS template_id,
+	templates.organization_id AS template_organization_id,
+	templates.created_by AS template_created_by,
+	templates.user_acl,
+	templates.group_acl
+FROM
+	templates
+INNER JOIN
+	template_versions
+	ON templates.id = template_versions.template_id
+INNER JOIN
+	provisioner_jobs
+	ON job_id = provisioner_jobs.id
+INNER JOIN
+	files
+	ON files.id = provisioner_jobs.file_id
+WHERE
+    -- Only fetch template version associated files.
+	storage_method = 'file'
+	AND provisioner_jobs.type = 'template_version_import'
+	AND file_id = $1
+`
+
+type GetFileTemplatesRow struct {
+	FileID                 uuid.UUID   `db:"file_id" json:"file_id"`
+	FileCreatedBy          uuid.UUID   `db:"file_created_by" json:"file_created_by"`
+	TemplateID             uuid.UUID   `db:"template_id" json:"template_id"`
+	TemplateOrganizationID uuid.UUID   `db:"template_organization_id" json:"template_organization_id"`
+	TemplateCreatedBy      uuid.UUID   `db:"template_created_by" json:"template_created_by"`
+	UserACL                TemplateACL `db:"user_acl" json:"user_acl"`
+	GroupACL               TemplateACL `db:"group_acl" json:"group_acl"`
+}
+
+// Get all templates that use a file.
+func (q *sqlQuerier) GetFileTemplates(ctx context.Context, fileID uuid.UUID) ([]GetFileTemplatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFileTemplates, fileID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []GetFileTemplatesRow
 	for rows.Next() {
-		var i User
+		var i GetFileTemplatesRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
+			&i.FileID,
+			&i.FileCreatedBy,
+			&i.TemplateID,
+			&i.TemplateOrganizationID,
+			&i.TemplateCreatedBy,
+			&i.UserACL,
+			&i.GroupACL,
 		); err != nil {
 			return nil, err
 		}
@@ -1117,357 +1402,347 @@ func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]
 	return items, nil
 }
 
-const insertGroupMember = `-- name: InsertGroupMember :exec
+const insertFile = `-- name: InsertFile :one
 INSERT INTO
-    group_members (user_id, group_id)
+	files (id, hash, created_at, created_by, mimetype, "data")
 VALUES
-    ($1, $2)
+	($1, $2, $3, $4, $5, $6) RETURNING hash, created_at, created_by, mimetype, data, id
 `
 
-type InsertGroupMemberParams struct {
-	UserID  uuid.UUID `db:"user_id" json:"user_id"`
-	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+type InsertFileParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+	Mimetype  string    `db:"mimetype" json:"mimetype"`
+	Data      []byte    `db:"data" json:"data"`
 }
 
-func (q *sqlQuerier) InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error {
-	_, err := q.db.ExecContext(ctx, insertGroupMember, arg.UserID, arg.GroupID)
-	return err
+func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, insertFile,
+		arg.ID,
+		arg.Hash,
+		arg.CreatedAt,
+		arg.CreatedBy,
+		arg.Mimetype,
+		arg.Data,
+	)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+	)
+	return i, err
 }
 
-const insertUserGroupsByName = `-- name: InsertUserGroupsByName :exec
-WITH groups AS (
-    SELECT
-        id
-    FROM
-        groups
-    WHERE
-        groups.organization_id = $2 AND
-        groups.name = ANY($3 :: text [])
-)
-INSERT INTO
-    group_members (user_id, group_id)
-SELECT
-    $1,
-    groups.id
-FROM
-    groups
+const getGitAuthLink = `-- name: GetGitAuthLink :one
+SELECT provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry FROM git_auth_links WHERE provider_id = $1 AND user_id = $2
 `
 
-type InsertUserGroupsByNameParams struct {
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	GroupNames     []string  `db:"group_names" json:"group_names"`
+type GetGitAuthLinkParams struct {
+	ProviderID string    `db:"provider_id" json:"provider_id"`
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
 }
 
-// InsertUserGroupsByName adds a user to all provided groups, if they exist.
-func (q *sqlQuerier) InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error {
-	_, err := q.db.ExecContext(ctx, insertUserGroupsByName, arg.UserID, arg.OrganizationID, pq.Array(arg.GroupNames))
-	return err
+func (q *sqlQuerier) GetGitAuthLink(ctx context.Context, arg GetGitAuthLinkParams) (GitAuthLink, error) {
+	row := q.db.QueryRowContext(ctx, getGitAuthLink, arg.ProviderID, arg.UserID)
+	var i GitAuthLink
+	err := row.Scan(
+		&i.ProviderID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
 }
 
-const deleteGroupByID = `-- name: DeleteGroupByID :exec
-DELETE FROM
-	groups
-WHERE
-	id = $1
-`
-
-func (q *sqlQuerier) DeleteGroupByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupByID, id)
-	return err
-}
-
-const getGroupByID = `-- name: GetGroupByID :one
-SELECT
-	id, name, organization_id, avatar_url, quota_allowance
-FROM
-	groups
-WHERE
-	id = $1
-LIMIT
-	1
+const insertGitAuthLink = `-- name: InsertGitAuthLink :one
+INSERT INTO git_auth_links (
+    provider_id,
+    user_id,
+    created_at,
+    updated_at,
+    oauth_access_token,
+    oauth_refresh_token,
+    oauth_expiry
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+) RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
 `
 
-func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByID, id)
-	var i Group
+type InsertGitAuthLinkParams struct {
+	ProviderID        string    `db:"provider_id" json:"provider_id"`
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+}
+
+func (q *sqlQuerier) InsertGitAuthLink(ctx context.Context, arg InsertGitAuthLinkParams) (GitAuthLink, error) {
+	row := q.db.QueryRowContext(ctx, insertGitAuthLink,
+		arg.ProviderID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+	)
+	var i GitAuthLink
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.ProviderID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
-SELECT
-	id, name, organization_id, avatar_url, quota_allowance
-FROM
-	groups
-WHERE
-	organization_id = $1
-AND
-	name = $2
-LIMIT
-	1
+const updateGitAuthLink = `-- name: UpdateGitAuthLink :one
+UPDATE git_auth_links SET
+    updated_at = $3,
+    oauth_access_token = $4,
+    oauth_refresh_token = $5,
+    oauth_expiry = $6
+WHERE provider_id = $1 AND user_id = $2 RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
 `
 
-type GetGroupByOrgAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Name           string    `db:"name" json:"name"`
+type UpdateGitAuthLinkParams struct {
+	ProviderID        string    `db:"provider_id" json:"provider_id"`
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
 }
 
-func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
-	var i Group
+func (q *sqlQuerier) UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error) {
+	row := q.db.QueryRowContext(ctx, updateGitAuthLink,
+		arg.ProviderID,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+	)
+	var i GitAuthLink
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.ProviderID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const getGroupsByOrganizationID = `-- name: GetGroupsByOrganizationID :many
-SELECT
-	id, name, organization_id, avatar_url, quota_allowance
-FROM
-	groups
+const deleteGitSSHKey = `-- name: DeleteGitSSHKey :exec
+DELETE FROM
+	gitsshkeys
 WHERE
-	organization_id = $1
-AND
-	id != $1
+	user_id = $1
 `
 
-func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Group
-	for rows.Next() {
-		var i Group
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.OrganizationID,
-			&i.AvatarURL,
-			&i.QuotaAllowance,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *sqlQuerier) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGitSSHKey, userID)
+	return err
 }
 
-const insertAllUsersGroup = `-- name: InsertAllUsersGroup :one
-INSERT INTO groups (
-	id,
-	name,
-	organization_id
-)
-VALUES
-	($1, 'Everyone', $1) RETURNING id, name, organization_id, avatar_url, quota_allowance
+const getGitSSHKey = `-- name: GetGitSSHKey :one
+SELECT
+	user_id, created_at, updated_at, private_key, public_key
+FROM
+	gitsshkeys
+WHERE
+	user_id = $1
 `
 
-// We use the organization_id as the id
-// for simplicity since all users is
-// every member of the org.
-func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertAllUsersGroup, organizationID)
-	var i Group
+func (q *sqlQuerier) GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error) {
+	row := q.db.QueryRowContext(ctx, getGitSSHKey, userID)
+	var i GitSSHKey
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PrivateKey,
+		&i.PublicKey,
 	)
 	return i, err
 }
 
-const insertGroup = `-- name: InsertGroup :one
-INSERT INTO groups (
-	id,
-	name,
-	organization_id,
-	avatar_url,
-	quota_allowance
-)
+const insertGitSSHKey = `-- name: InsertGitSSHKey :one
+INSERT INTO
+	gitsshkeys (
+		user_id,
+		created_at,
+		updated_at,
+		private_key,
+		public_key
+	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING id, name, organization_id, avatar_url, quota_allowance
+	($1, $2, $3, $4, $5) RETURNING user_id, created_at, updated_at, private_key, public_key
 `
 
-type InsertGroupParams struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	Name           string    `db:"name" json:"name"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
-	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
+type InsertGitSSHKeyParams struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	PrivateKey string    `db:"private_key" json:"private_key"`
+	PublicKey  string    `db:"public_key" json:"public_key"`
 }
 
-func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertGroup,
-		arg.ID,
-		arg.Name,
-		arg.OrganizationID,
-		arg.AvatarURL,
-		arg.QuotaAllowance,
+func (q *sqlQuerier) InsertGitSSHKey(ctx context.Context, arg InsertGitSSHKeyParams) (GitSSHKey, error) {
+	row := q.db.QueryRowContext(ctx, insertGitSSHKey,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.PrivateKey,
+		arg.PublicKey,
 	)
-	var i Group
+	var i GitSSHKey
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PrivateKey,
+		&i.PublicKey,
 	)
 	return i, err
 }
 
-const updateGroupByID = `-- name: UpdateGroupByID :one
+const updateGitSSHKey = `-- name: UpdateGitSSHKey :one
 UPDATE
-	groups
+	gitsshkeys
 SET
-	name = $1,
-	avatar_url = $2,
-	quota_allowance = $3
+	updated_at = $2,
+	private_key = $3,
+	public_key = $4
 WHERE
-	id = $4
-RETURNING id, name, organization_id, avatar_url, quota_allowance
+	user_id = $1
+RETURNING
+	user_id, created_at, updated_at, private_key, public_key
 `
 
-type UpdateGroupByIDParams struct {
-	Name           string    `db:"name" json:"name"`
-	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
-	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
-	ID             uuid.UUID `db:"id" json:"id"`
+type UpdateGitSSHKeyParams struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	PrivateKey string    `db:"private_key" json:"private_key"`
+	PublicKey  string    `db:"public_key" json:"public_key"`
 }
 
-func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, updateGroupByID,
-		arg.Name,
-		arg.AvatarURL,
-		arg.QuotaAllowance,
-		arg.ID,
+func (q *sqlQuerier) UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error) {
+	row := q.db.QueryRowContext(ctx, updateGitSSHKey,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.PrivateKey,
+		arg.PublicKey,
 	)
-	var i Group
+	var i GitSSHKey
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PrivateKey,
+		&i.PublicKey,
 	)
 	return i, err
 }
 
-const deleteLicense = `-- name: DeleteLicense :one
-DELETE
-FROM licenses
-WHERE id = $1
-RETURNING id
+const deleteGroupMemberFromGroup = `-- name: DeleteGroupMemberFromGroup :exec
+DELETE FROM
+	group_members
+WHERE
+	user_id = $1 AND
+	group_id = $2
 `
 
-func (q *sqlQuerier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
-	row := q.db.QueryRowContext(ctx, deleteLicense, id)
-	err := row.Scan(&id)
-	return id, err
+type DeleteGroupMemberFromGroupParams struct {
+	UserID  uuid.UUID `db:"user_id" json:"user_id"`
+	GroupID uuid.UUID `db:"group_id" json:"group_id"`
 }
 
-const getLicenseByID = `-- name: GetLicenseByID :one
-SELECT
-	id, uploaded_at, jwt, exp, uuid
-FROM
-	licenses
+func (q *sqlQuerier) DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupMemberFromGroup, arg.UserID, arg.GroupID)
+	return err
+}
+
+const deleteGroupMembersByOrgAndUser = `-- name: DeleteGroupMembersByOrgAndUser :exec
+DELETE FROM
+	group_members
 WHERE
-	id = $1
-LIMIT
-	1
+	group_members.user_id = $1
+	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = $2)
 `
 
-func (q *sqlQuerier) GetLicenseByID(ctx context.Context, id int32) (License, error) {
-	row := q.db.QueryRowContext(ctx, getLicenseByID, id)
-	var i License
-	err := row.Scan(
-		&i.ID,
-		&i.UploadedAt,
-		&i.JWT,
-		&i.Exp,
-		&i.UUID,
-	)
-	return i, err
+type DeleteGroupMembersByOrgAndUserParams struct {
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
 }
 
-const getLicenses = `-- name: GetLicenses :many
-SELECT id, uploaded_at, jwt, exp, uuid
-FROM licenses
-ORDER BY (id)
-`
-
-func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
-	rows, err := q.db.QueryContext(ctx, getLicenses)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []License
-	for rows.Next() {
-		var i License
-		if err := rows.Scan(
-			&i.ID,
-			&i.UploadedAt,
-			&i.JWT,
-			&i.Exp,
-			&i.UUID,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *sqlQuerier) DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupMembersByOrgAndUser, arg.UserID, arg.OrganizationID)
+	return err
 }
 
-const getUnexpiredLicenses = `-- name: GetUnexpiredLicenses :many
-SELECT id, uploaded_at, jwt, exp, uuid
-FROM licenses
-WHERE exp > NOW()
-ORDER BY (id)
+const getGroupMembers = `-- name: GetGroupMembers :many
+SELECT
+	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
+FROM
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = $1
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
 `
 
-func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error) {
-	rows, err := q.db.QueryContext(ctx, getUnexpiredLicenses)
+func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMembers, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []License
+	var items []User
 	for rows.Next() {
-		var i License
+		var i User
 		if err := rows.Scan(
 			&i.ID,
-			&i.UploadedAt,
-			&i.JWT,
-			&i.Exp,
-			&i.UUID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1482,168 +1757,147 @@ func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error
 	return items, nil
 }
 
-const insertLicense = `-- name: InsertLicense :one
+const insertGroupMember = `-- name: InsertGroupMember :exec
 INSERT INTO
-	licenses (
-	uploaded_at,
-	jwt,
-	exp,
-	uuid
-)
+    group_members (user_id, group_id)
 VALUES
-	($1, $2, $3, $4) RETURNING id, uploaded_at, jwt, exp, uuid
+    ($1, $2)
 `
 
-type InsertLicenseParams struct {
-	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
-	JWT        string    `db:"jwt" json:"jwt"`
-	Exp        time.Time `db:"exp" json:"exp"`
-	UUID       uuid.UUID `db:"uuid" json:"uuid"`
+type InsertGroupMemberParams struct {
+	UserID  uuid.UUID `db:"user_id" json:"user_id"`
+	GroupID uuid.UUID `db:"group_id" json:"group_id"`
 }
 
-func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error) {
-	row := q.db.QueryRowContext(ctx, insertLicense,
-		arg.UploadedAt,
-		arg.JWT,
-		arg.Exp,
-		arg.UUID,
-	)
-	var i License
-	err := row.Scan(
-		&i.ID,
-		&i.UploadedAt,
-		&i.JWT,
-		&i.Exp,
-		&i.UUID,
-	)
-	return i, err
+func (q *sqlQuerier) InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error {
+	_, err := q.db.ExecContext(ctx, insertGroupMember, arg.UserID, arg.GroupID)
+	return err
 }
 
-const acquireLock = `-- name: AcquireLock :exec
-SELECT pg_advisory_xact_lock($1)
+const insertUserGroupsByName = `-- name: InsertUserGroupsByName :exec
+WITH groups AS (
+    SELECT
+        id
+    FROM
+        groups
+    WHERE
+        groups.organization_id = $2 AND
+        groups.name = ANY($3 :: text [])
+)
+INSERT INTO
+    group_members (user_id, group_id)
+SELECT
+    $1,
+    groups.id
+FROM
+    groups
 `
 
-// Blocks until the lock is acquired.
-//
-// This must be called from within a transaction. The lock will be automatically
-// released when the transaction ends.
-//
-// Use database.LockID() to generate a unique lock ID from a string.
-func (q *sqlQuerier) AcquireLock(ctx context.Context, pgAdvisoryXactLock int64) error {
-	_, err := q.db.ExecContext(ctx, acquireLock, pgAdvisoryXactLock)
+type InsertUserGroupsByNameParams struct {
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	GroupNames     []string  `db:"group_names" json:"group_names"`
+}
+
+// InsertUserGroupsByName adds a user to all provided groups, if they exist.
+func (q *sqlQuerier) InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error {
+	_, err := q.db.ExecContext(ctx, insertUserGroupsByName, arg.UserID, arg.OrganizationID, pq.Array(arg.GroupNames))
 	return err
 }
 
-const tryAcquireLock = `-- name: TryAcquireLock :one
-SELECT pg_try_advisory_xact_lock($1)
+const deleteGroupByID = `-- name: DeleteGroupByID :exec
+DELETE FROM
+	groups
+WHERE
+	id = $1
 `
 
-// Non blocking lock. Returns true if the lock was acquired, false otherwise.
-//
-// This must be called from within a transaction. The lock will be automatically
-// released when the transaction ends.
-//
-// Use database.LockID() to generate a unique lock ID from a string.
-func (q *sqlQuerier) TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error) {
-	row := q.db.QueryRowContext(ctx, tryAcquireLock, pgTryAdvisoryXactLock)
-	var pg_try_advisory_xact_lock bool
-	err := row.Scan(&pg_try_advisory_xact_lock)
-	return pg_try_advisory_xact_lock, err
+func (q *sqlQuerier) DeleteGroupByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupByID, id)
+	return err
 }
 
-const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
+const getGroupByID = `-- name: GetGroupByID :one
 SELECT
-    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+	id, name, organization_id, avatar_url, quota_allowance
 FROM
-    organization_members
+	groups
 WHERE
-    user_id = ANY($1 :: uuid [ ])
-GROUP BY
-    user_id
+	id = $1
+LIMIT
+	1
 `
 
-type GetOrganizationIDsByMemberIDsRow struct {
-	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
-	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
-}
-
-func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetOrganizationIDsByMemberIDsRow
-	for rows.Next() {
-		var i GetOrganizationIDsByMemberIDsRow
-		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroupByID, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
+	)
+	return i, err
 }
 
-const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
+const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
 SELECT
-	user_id, organization_id, created_at, updated_at, roles
+	id, name, organization_id, avatar_url, quota_allowance
 FROM
-	organization_members
+	groups
 WHERE
 	organization_id = $1
-	AND user_id = $2
+AND
+	name = $2
 LIMIT
 	1
 `
 
-type GetOrganizationMemberByUserIDParams struct {
+type GetGroupByOrgAndNameParams struct {
 	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	Name           string    `db:"name" json:"name"`
 }
 
-func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationMemberByUserID, arg.OrganizationID, arg.UserID)
-	var i OrganizationMember
+func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
+	var i Group
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
 		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		pq.Array(&i.Roles),
+		&i.AvatarURL,
+		&i.QuotaAllowance,
 	)
 	return i, err
 }
 
-const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
+const getGroupsByOrganizationID = `-- name: GetGroupsByOrganizationID :many
 SELECT
-	user_id, organization_id, created_at, updated_at, roles
+	id, name, organization_id, avatar_url, quota_allowance
 FROM
-	organization_members
+	groups
 WHERE
-  user_id = $1
+	organization_id = $1
+AND
+	id != $1
 `
 
-func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationMembershipsByUserID, userID)
+func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []OrganizationMember
+	var items []Group
 	for rows.Next() {
-		var i OrganizationMember
+		var i Group
 		if err := rows.Scan(
-			&i.UserID,
+			&i.ID,
+			&i.Name,
 			&i.OrganizationID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			pq.Array(&i.Roles),
+			&i.AvatarURL,
+			&i.QuotaAllowance,
 		); err != nil {
 			return nil, err
 		}
@@ -1658,145 +1912,166 @@ func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, use
 	return items, nil
 }
 
-const insertOrganizationMember = `-- name: InsertOrganizationMember :one
-INSERT INTO
-	organization_members (
-		organization_id,
-		user_id,
-		created_at,
-		updated_at,
-		roles
+const insertAllUsersGroup = `-- name: InsertAllUsersGroup :one
+INSERT INTO groups (
+	id,
+	name,
+	organization_id
+)
+VALUES
+	($1, 'Everyone', $1) RETURNING id, name, organization_id, avatar_url, quota_allowance
+`
+
+// We use the organization_id as the id
+// for simplicity since all users is
+// every member of the org.
+func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertAllUsersGroup, organizationID)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
 	)
+	return i, err
+}
+
+const insertGroup = `-- name: InsertGroup :one
+INSERT INTO groups (
+	id,
+	name,
+	organization_id,
+	avatar_url,
+	quota_allowance
+)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING user_id, organization_id, created_at, updated_at, roles
+	($1, $2, $3, $4, $5) RETURNING id, name, organization_id, avatar_url, quota_allowance
 `
 
-type InsertOrganizationMemberParams struct {
+type InsertGroupParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
 	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt      time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
-	Roles          []string  `db:"roles" json:"roles"`
+	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
+	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
 }
 
-func (q *sqlQuerier) InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, insertOrganizationMember,
+func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertGroup,
+		arg.ID,
+		arg.Name,
 		arg.OrganizationID,
-		arg.UserID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		pq.Array(arg.Roles),
+		arg.AvatarURL,
+		arg.QuotaAllowance,
 	)
-	var i OrganizationMember
+	var i Group
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
 		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		pq.Array(&i.Roles),
+		&i.AvatarURL,
+		&i.QuotaAllowance,
 	)
 	return i, err
 }
 
-const updateMemberRoles = `-- name: UpdateMemberRoles :one
+const updateGroupByID = `-- name: UpdateGroupByID :one
 UPDATE
-	organization_members
+	groups
 SET
-	-- Remove all duplicates from the roles.
-	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+	name = $1,
+	avatar_url = $2,
+	quota_allowance = $3
 WHERE
-	user_id = $2
-	AND organization_id = $3
-RETURNING user_id, organization_id, created_at, updated_at, roles
+	id = $4
+RETURNING id, name, organization_id, avatar_url, quota_allowance
 `
 
-type UpdateMemberRolesParams struct {
-	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
-	UserID       uuid.UUID `db:"user_id" json:"user_id"`
-	OrgID        uuid.UUID `db:"org_id" json:"org_id"`
+type UpdateGroupByIDParams struct {
+	Name           string    `db:"name" json:"name"`
+	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
+	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
+	ID             uuid.UUID `db:"id" json:"id"`
 }
 
-func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, updateMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.OrgID)
-	var i OrganizationMember
+func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, updateGroupByID,
+		arg.Name,
+		arg.AvatarURL,
+		arg.QuotaAllowance,
+		arg.ID,
+	)
+	var i Group
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
 		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		pq.Array(&i.Roles),
+		&i.AvatarURL,
+		&i.QuotaAllowance,
 	)
 	return i, err
 }
 
-const getOrganizationByID = `-- name: GetOrganizationByID :one
+const deleteLicense = `-- name: DeleteLicense :one
+DELETE
+FROM licenses
+WHERE id = $1
+RETURNING id
+`
+
+func (q *sqlQuerier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, deleteLicense, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getLicenseByID = `-- name: GetLicenseByID :one
 SELECT
-	id, name, description, created_at, updated_at
+	id, uploaded_at, jwt, exp, uuid
 FROM
-	organizations
+	licenses
 WHERE
 	id = $1
+LIMIT
+	1
 `
 
-func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
-	var i Organization
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
-
-const getOrganizationByName = `-- name: GetOrganizationByName :one
-SELECT
-	id, name, description, created_at, updated_at
-FROM
-	organizations
-WHERE
-	LOWER("name") = LOWER($1)
-LIMIT
-	1
-`
-
-func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
-	var i Organization
+func (q *sqlQuerier) GetLicenseByID(ctx context.Context, id int32) (License, error) {
+	row := q.db.QueryRowContext(ctx, getLicenseByID, id)
+	var i License
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.UploadedAt,
+		&i.JWT,
+		&i.Exp,
+		&i.UUID,
 	)
 	return i, err
 }
 
-const getOrganizations = `-- name: GetOrganizations :many
-SELECT
-	id, name, description, created_at, updated_at
-FROM
-	organizations
+const getLicenses = `-- name: GetLicenses :many
+SELECT id, uploaded_at, jwt, exp, uuid
+FROM licenses
+ORDER BY (id)
 `
 
-func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizations)
+func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
+	rows, err := q.db.QueryContext(ctx, getLicenses)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Organization
+	var items []License
 	for rows.Next() {
-		var i Organization
+		var i License
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.UploadedAt,
+			&i.JWT,
+			&i.Exp,
+			&i.UUID,
 		); err != nil {
 			return nil, err
 		}
@@ -1811,37 +2086,28 @@ func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, erro
 	return items, nil
 }
 
-const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
-SELECT
-	id, name, description, created_at, updated_at
-FROM
-	organizations
-WHERE
-	id = (
-		SELECT
-			organization_id
-		FROM
-			organization_members
-		WHERE
-			user_id = $1
-	)
+const getUnexpiredLicenses = `-- name: GetUnexpiredLicenses :many
+SELECT id, uploaded_at, jwt, exp, uuid
+FROM licenses
+WHERE exp > NOW()
+ORDER BY (id)
 `
 
-func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationsByUserID, userID)
+func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error) {
+	rows, err := q.db.QueryContext(ctx, getUnexpiredLicenses)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Organization
+	var items []License
 	for rows.Next() {
-		var i Organization
+		var i License
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.UploadedAt,
+			&i.JWT,
+			&i.Exp,
+			&i.UUID,
 		); err != nil {
 			return nil, err
 		}
@@ -1856,206 +2122,353 @@ func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.U
 	return items, nil
 }
 
-const insertOrganization = `-- name: InsertOrganization :one
+const insertLicense = `-- name: InsertLicense :one
 INSERT INTO
-	organizations (id, "name", description, created_at, updated_at)
+	licenses (
+	uploaded_at,
+	jwt,
+	exp,
+	uuid
+)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING id, name, description, created_at, updated_at
+	($1, $2, $3, $4) RETURNING id, uploaded_at, jwt, exp, uuid
 `
 
-type InsertOrganizationParams struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+type InsertLicenseParams struct {
+	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
+	JWT        string    `db:"jwt" json:"jwt"`
+	Exp        time.Time `db:"exp" json:"exp"`
+	UUID       uuid.UUID `db:"uuid" json:"uuid"`
 }
 
-func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, insertOrganization,
-		arg.ID,
-		arg.Name,
-		arg.Description,
-		arg.CreatedAt,
-		arg.UpdatedAt,
+func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error) {
+	row := q.db.QueryRowContext(ctx, insertLicense,
+		arg.UploadedAt,
+		arg.JWT,
+		arg.Exp,
+		arg.UUID,
 	)
-	var i Organization
+	var i License
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.UploadedAt,
+		&i.JWT,
+		&i.Exp,
+		&i.UUID,
 	)
 	return i, err
 }
 
-const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
-SELECT
-	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
-FROM
-	parameter_schemas
-WHERE
-	job_id = $1
-ORDER BY
-	index
+const acquireLock = `-- name: AcquireLock :exec
+SELECT pg_advisory_xact_lock($1)
 `
 
-func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
-	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ParameterSchema
-	for rows.Next() {
-		var i ParameterSchema
-		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.JobID,
-			&i.Name,
-			&i.Description,
-			&i.DefaultSourceScheme,
-			&i.DefaultSourceValue,
-			&i.AllowOverrideSource,
-			&i.DefaultDestinationScheme,
-			&i.AllowOverrideDestination,
-			&i.DefaultRefresh,
-			&i.RedisplayValue,
-			&i.ValidationError,
-			&i.ValidationCondition,
-			&i.ValidationTypeSystem,
-			&i.ValidationValueType,
-			&i.Index,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+// Blocks until the lock is acquired.
+//
+// This must be called from within a transaction. The lock will be automatically
+// released when the transaction ends.
+//
+// Use database.LockID() to generate a unique lock ID from a string.
+func (q *sqlQuerier) AcquireLock(ctx context.Context, pgAdvisoryXactLock int64) error {
+	_, err := q.db.ExecContext(ctx, acquireLock, pgAdvisoryXactLock)
+	return err
 }
 
-const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
-SELECT
-	id, created_at, updated_at, name, provisioners, replica_id, tags
-FROM
-	provisioner_daemons
+const tryAcquireLock = `-- name: TryAcquireLock :one
+SELECT pg_try_advisory_xact_lock($1)
 `
 
-func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerDaemons)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ProvisionerDaemon
-	for rows.Next() {
-		var i ProvisionerDaemon
-		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Name,
-			pq.Array(&i.Provisioners),
-			&i.ReplicaID,
-			&i.Tags,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+// Non blocking lock. Returns true if the lock was acquired, false otherwise.
+//
+// This must be called from within a transaction. The lock will be automatically
+// released when the transaction ends.
+//
+// Use database.LockID() to generate a unique lock ID from a string.
+func (q *sqlQuerier) TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryAcquireLock, pgTryAdvisoryXactLock)
+	var pg_try_advisory_xact_lock bool
+	err := row.Scan(&pg_try_advisory_xact_lock)
+	return pg_try_advisory_xact_lock, err
 }
 
-const insertProvisionerDaemon = `-- name: InsertProvisionerDaemon :one
-INSERT INTO
-	provisioner_daemons (
-		id,
-		created_at,
-		"name",
-		provisioners,
-		tags
-	)
-VALUES
-	($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags
+const deleteExpiredOAuth2DeviceCodes = `-- name: DeleteExpiredOAuth2DeviceCodes :exec
+DELETE FROM oauth2_device_codes WHERE expires_at < NOW()
 `
 
-type InsertProvisionerDaemonParams struct {
-	ID           uuid.UUID         `db:"id" json:"id"`
-	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
-	Name         string            `db:"name" json:"name"`
-	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
-	Tags         StringMap         `db:"tags" json:"tags"`
+func (q *sqlQuerier) DeleteExpiredOAuth2DeviceCodes(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredOAuth2DeviceCodes)
+	return err
 }
 
-func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerDaemon,
-		arg.ID,
-		arg.CreatedAt,
-		arg.Name,
-		pq.Array(arg.Provisioners),
-		arg.Tags,
+const deleteExpiredOAuth2ProviderAppCodes = `-- name: DeleteExpiredOAuth2ProviderAppCodes :exec
+DELETE FROM oauth2_provider_app_codes WHERE expires_at < NOW()
+`
+
+// Codes are deleted once they are exchanged, this cleans up the ones that
+// never were.
+func (q *sqlQuerier) DeleteExpiredOAuth2ProviderAppCodes(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredOAuth2ProviderAppCodes)
+	return err
+}
+
+const deleteOAuth2DeviceCodeByID = `-- name: DeleteOAuth2DeviceCodeByID :one
+DELETE FROM oauth2_device_codes WHERE id = $1 RETURNING id
+`
+
+// Returns no rows if the code was already deleted, so a device code that is
+// redeemed concurrently is only exchanged once.
+func (q *sqlQuerier) DeleteOAuth2DeviceCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, deleteOAuth2DeviceCodeByID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteOAuth2ProviderAppByID = `-- name: DeleteOAuth2ProviderAppByID :exec
+DELETE FROM oauth2_provider_apps WHERE id = $1
+`
+
+func (q *sqlQuerier) DeleteOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppByID, id)
+	return err
+}
+
+const deleteOAuth2ProviderAppCodeByID = `-- name: DeleteOAuth2ProviderAppCodeByID :one
+DELETE FROM oauth2_provider_app_codes WHERE id = $1 RETURNING id
+`
+
+// Returns no rows if the code was already deleted, so a code that is redeemed
+// concurrently is only exchanged once.
+func (q *sqlQuerier) DeleteOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, deleteOAuth2ProviderAppCodeByID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteOAuth2ProviderAppCodesByAppAndUserID = `-- name: DeleteOAuth2ProviderAppCodesByAppAndUserID :exec
+DELETE FROM oauth2_provider_app_codes WHERE app_id = $1 AND user_id = $2
+`
+
+type DeleteOAuth2ProviderAppCodesByAppAndUserIDParams struct {
+	AppID  uuid.UUID `db:"app_id" json:"app_id"`
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Context, arg DeleteOAuth2ProviderAppCodesByAppAndUserIDParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppCodesByAppAndUserID, arg.AppID, arg.UserID)
+	return err
+}
+
+const deleteOAuth2ProviderAppSecretByID = `-- name: DeleteOAuth2ProviderAppSecretByID :exec
+DELETE FROM oauth2_provider_app_secrets WHERE id = $1
+`
+
+func (q *sqlQuerier) DeleteOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppSecretByID, id)
+	return err
+}
+
+const deleteOAuth2ProviderAppTokenByID = `-- name: DeleteOAuth2ProviderAppTokenByID :one
+DELETE FROM oauth2_provider_app_tokens WHERE id = $1 RETURNING id
+`
+
+// Returns no rows if the token was already deleted, so a refresh token that is
+// used concurrently is only exchanged once.
+func (q *sqlQuerier) DeleteOAuth2ProviderAppTokenByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, deleteOAuth2ProviderAppTokenByID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteOAuth2ProviderAppTokensByAppAndUserID = `-- name: DeleteOAuth2ProviderAppTokensByAppAndUserID :exec
+DELETE FROM
+	api_keys
+USING
+	oauth2_provider_app_tokens, oauth2_provider_app_secrets
+WHERE
+	oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
+	AND oauth2_provider_app_tokens.api_key_id = api_keys.id
+	AND oauth2_provider_app_secrets.app_id = $1
+	AND api_keys.user_id = $2
+`
+
+type DeleteOAuth2ProviderAppTokensByAppAndUserIDParams struct {
+	AppID  uuid.UUID `db:"app_id" json:"app_id"`
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+// Deleting the API keys deletes the tokens as well through the foreign key.
+func (q *sqlQuerier) DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Context, arg DeleteOAuth2ProviderAppTokensByAppAndUserIDParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppTokensByAppAndUserID, arg.AppID, arg.UserID)
+	return err
+}
+
+const getOAuth2DeviceCodeByPrefix = `-- name: GetOAuth2DeviceCodeByPrefix :one
+SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_code, scope, status, user_id, polling_interval, last_polled_at FROM oauth2_device_codes WHERE secret_prefix = $1
+`
+
+func (q *sqlQuerier) GetOAuth2DeviceCodeByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2DeviceCodeByPrefix, secretPrefix)
+	var i OAuth2DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserCode,
+		&i.Scope,
+		&i.Status,
+		&i.UserID,
+		&i.PollingInterval,
+		&i.LastPolledAt,
 	)
-	var i ProvisionerDaemon
+	return i, err
+}
+
+const getOAuth2DeviceCodeByUserCode = `-- name: GetOAuth2DeviceCodeByUserCode :one
+SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_code, scope, status, user_id, polling_interval, last_polled_at FROM oauth2_device_codes WHERE user_code = $1
+`
+
+func (q *sqlQuerier) GetOAuth2DeviceCodeByUserCode(ctx context.Context, userCode string) (OAuth2DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2DeviceCodeByUserCode, userCode)
+	var i OAuth2DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserCode,
+		&i.Scope,
+		&i.Status,
+		&i.UserID,
+		&i.PollingInterval,
+		&i.LastPolledAt,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppByID = `-- name: GetOAuth2ProviderAppByID :one
+SELECT id, created_at, updated_at, name, icon, callback_url FROM oauth2_provider_apps WHERE id = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppByID, id)
+	var i OAuth2ProviderApp
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
-		pq.Array(&i.Provisioners),
-		&i.ReplicaID,
-		&i.Tags,
+		&i.Icon,
+		&i.CallbackURL,
 	)
 	return i, err
 }
 
-const getProvisionerLogsAfterID = `-- name: GetProvisionerLogsAfterID :many
-SELECT
-	job_id, created_at, source, level, stage, output, id
-FROM
-	provisioner_job_logs
-WHERE
-	job_id = $1
-	AND (
-		id > $2
-	) ORDER BY id ASC
+const getOAuth2ProviderAppCodeByID = `-- name: GetOAuth2ProviderAppCodeByID :one
+SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, scope, redirect_uri, code_challenge FROM oauth2_provider_app_codes WHERE id = $1
 `
 
-type GetProvisionerLogsAfterIDParams struct {
-	JobID        uuid.UUID `db:"job_id" json:"job_id"`
-	CreatedAfter int64     `db:"created_after" json:"created_after"`
+func (q *sqlQuerier) GetOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderAppCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppCodeByID, id)
+	var i OAuth2ProviderAppCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserID,
+		&i.AppID,
+		&i.Scope,
+		&i.RedirectURI,
+		&i.CodeChallenge,
+	)
+	return i, err
 }
 
-func (q *sqlQuerier) GetProvisionerLogsAfterID(ctx context.Context, arg GetProvisionerLogsAfterIDParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerLogsAfterID, arg.JobID, arg.CreatedAfter)
+const getOAuth2ProviderAppCodeByPrefix = `-- name: GetOAuth2ProviderAppCodeByPrefix :one
+SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, scope, redirect_uri, code_challenge FROM oauth2_provider_app_codes WHERE secret_prefix = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2ProviderAppCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppCodeByPrefix, secretPrefix)
+	var i OAuth2ProviderAppCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserID,
+		&i.AppID,
+		&i.Scope,
+		&i.RedirectURI,
+		&i.CodeChallenge,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppSecretByID = `-- name: GetOAuth2ProviderAppSecretByID :one
+SELECT id, created_at, last_used_at, hashed_secret, secret_prefix, display_secret, app_id FROM oauth2_provider_app_secrets WHERE id = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderAppSecret, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppSecretByID, id)
+	var i OAuth2ProviderAppSecret
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.HashedSecret,
+		&i.SecretPrefix,
+		&i.DisplaySecret,
+		&i.AppID,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppSecretByPrefix = `-- name: GetOAuth2ProviderAppSecretByPrefix :one
+SELECT id, created_at, last_used_at, hashed_secret, secret_prefix, display_secret, app_id FROM oauth2_provider_app_secrets WHERE secret_prefix = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppSecretByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2ProviderAppSecret, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppSecretByPrefix, secretPrefix)
+	var i OAuth2ProviderAppSecret
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.HashedSecret,
+		&i.SecretPrefix,
+		&i.DisplaySecret,
+		&i.AppID,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppSecretsByAppID = `-- name: GetOAuth2ProviderAppSecretsByAppID :many
+SELECT id, created_at, last_used_at, hashed_secret, secret_prefix, display_secret, app_id FROM oauth2_provider_app_secrets WHERE app_id = $1 ORDER BY (created_at, id) ASC
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppSecretsByAppID(ctx context.Context, appID uuid.UUID) ([]OAuth2ProviderAppSecret, error) {
+	rows, err := q.db.QueryContext(ctx, getOAuth2ProviderAppSecretsByAppID, appID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJobLog
+	var items []OAuth2ProviderAppSecret
 	for rows.Next() {
-		var i ProvisionerJobLog
+		var i OAuth2ProviderAppSecret
 		if err := rows.Scan(
-			&i.JobID,
-			&i.CreatedAt,
-			&i.Source,
-			&i.Level,
-			&i.Stage,
-			&i.Output,
 			&i.ID,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.HashedSecret,
+			&i.SecretPrefix,
+			&i.DisplaySecret,
+			&i.AppID,
 		); err != nil {
 			return nil, err
 		}
@@ -2070,51 +2483,45 @@ func (q *sqlQuerier) GetProvisionerLogsAfterID(ctx context.Context, arg GetProvi
 	return items, nil
 }
 
-const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
-INSERT INTO
-	provisioner_job_logs
-SELECT
-	$1 :: uuid AS job_id,
-	unnest($2 :: timestamptz [ ]) AS created_at,
-	unnest($3 :: log_source [ ]) AS source,
-	unnest($4 :: log_level [ ]) AS LEVEL,
-	unnest($5 :: VARCHAR(128) [ ]) AS stage,
-	unnest($6 :: VARCHAR(1024) [ ]) AS output RETURNING job_id, created_at, source, level, stage, output, id
+const getOAuth2ProviderAppTokenByPrefix = `-- name: GetOAuth2ProviderAppTokenByPrefix :one
+SELECT id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id FROM oauth2_provider_app_tokens WHERE hash_prefix = $1
 `
 
-type InsertProvisionerJobLogsParams struct {
-	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
-	CreatedAt []time.Time `db:"created_at" json:"created_at"`
-	Source    []LogSource `db:"source" json:"source"`
-	Level     []LogLevel  `db:"level" json:"level"`
-	Stage     []string    `db:"stage" json:"stage"`
-	Output    []string    `db:"output" json:"output"`
+func (q *sqlQuerier) GetOAuth2ProviderAppTokenByPrefix(ctx context.Context, hashPrefix []byte) (OAuth2ProviderAppToken, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppTokenByPrefix, hashPrefix)
+	var i OAuth2ProviderAppToken
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.HashPrefix,
+		&i.RefreshHash,
+		&i.AppSecretID,
+		&i.APIKeyID,
+	)
+	return i, err
 }
 
-func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, insertProvisionerJobLogs,
-		arg.JobID,
-		pq.Array(arg.CreatedAt),
-		pq.Array(arg.Source),
-		pq.Array(arg.Level),
-		pq.Array(arg.Stage),
-		pq.Array(arg.Output),
-	)
+const getOAuth2ProviderApps = `-- name: GetOAuth2ProviderApps :many
+SELECT id, created_at, updated_at, name, icon, callback_url FROM oauth2_provider_apps ORDER BY (name, id) ASC
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2ProviderApp, error) {
+	rows, err := q.db.QueryContext(ctx, getOAuth2ProviderApps)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJobLog
+	var items []OAuth2ProviderApp
 	for rows.Next() {
-		var i ProvisionerJobLog
+		var i OAuth2ProviderApp
 		if err := rows.Scan(
-			&i.JobID,
-			&i.CreatedAt,
-			&i.Source,
-			&i.Level,
-			&i.Stage,
-			&i.Output,
 			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Icon,
+			&i.CallbackURL,
 		); err != nil {
 			return nil, err
 		}
@@ -2129,150 +2536,46 @@ func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertPro
 	return items, nil
 }
 
-const acquireProvisionerJob = `-- name: AcquireProvisionerJob :one
-UPDATE
-	provisioner_jobs
-SET
-	started_at = $1,
-	updated_at = $1,
-	worker_id = $2
+const getOAuth2ProviderAppsByUserID = `-- name: GetOAuth2ProviderAppsByUserID :many
+SELECT
+	id, created_at, updated_at, name, icon, callback_url
+FROM
+	oauth2_provider_apps
 WHERE
-	id = (
+	id IN (
 		SELECT
-			id
+			oauth2_provider_app_secrets.app_id
 		FROM
-			provisioner_jobs AS nested
+			oauth2_provider_app_tokens
+		INNER JOIN oauth2_provider_app_secrets
+			ON oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
+		INNER JOIN api_keys
+			ON api_keys.id = oauth2_provider_app_tokens.api_key_id
 		WHERE
-			nested.started_at IS NULL
-			-- Ensure the caller has the correct provisioner.
-			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
-			-- Ensure the caller satisfies all job tags.
-			AND nested.tags <@ $4 :: jsonb
-		ORDER BY
-			nested.created_at
-		FOR UPDATE
-		SKIP LOCKED
-		LIMIT
-			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+			api_keys.user_id = $1
+	)
+ORDER BY
+	(name, id) ASC
 `
 
-type AcquireProvisionerJobParams struct {
-	StartedAt sql.NullTime      `db:"started_at" json:"started_at"`
-	WorkerID  uuid.NullUUID     `db:"worker_id" json:"worker_id"`
-	Types     []ProvisionerType `db:"types" json:"types"`
-	Tags      json.RawMessage   `db:"tags" json:"tags"`
-}
-
-// Acquires the lock for a single job that isn't started, completed,
-// canceled, and that matches an array of provisioner types.
-//
-// SKIP LOCKED is used to jump over locked rows. This prevents
-// multiple provisioners from acquiring the same jobs. See:
-// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
-func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, acquireProvisionerJob,
-		arg.StartedAt,
-		arg.WorkerID,
-		pq.Array(arg.Types),
-		arg.Tags,
-	)
-	var i ProvisionerJob
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
-		&i.OrganizationID,
-		&i.InitiatorID,
-		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
-		&i.ErrorCode,
-		&i.TraceMetadata,
-	)
-	return i, err
-}
-
-const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
-SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
-FROM
-	provisioner_jobs
-WHERE
-	id = $1
-`
-
-func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
-	var i ProvisionerJob
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
-		&i.OrganizationID,
-		&i.InitiatorID,
-		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
-		&i.ErrorCode,
-		&i.TraceMetadata,
-	)
-	return i, err
-}
-
-const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
-SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
-FROM
-	provisioner_jobs
-WHERE
-	id = ANY($1 :: uuid [ ])
-`
-
-func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsByIDs, pq.Array(ids))
+// Returns the applications the user has authorized, which are the ones holding
+// tokens on behalf of the user.
+func (q *sqlQuerier) GetOAuth2ProviderAppsByUserID(ctx context.Context, userID uuid.UUID) ([]OAuth2ProviderApp, error) {
+	rows, err := q.db.QueryContext(ctx, getOAuth2ProviderAppsByUserID, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJob
+	var items []OAuth2ProviderApp
 	for rows.Next() {
-		var i ProvisionerJob
+		var i OAuth2ProviderApp
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.StartedAt,
-			&i.CanceledAt,
-			&i.CompletedAt,
-			&i.Error,
-			&i.OrganizationID,
-			&i.InitiatorID,
-			&i.Provisioner,
-			&i.StorageMethod,
-			&i.Type,
-			&i.Input,
-			&i.WorkerID,
-			&i.FileID,
-			&i.Tags,
-			&i.ErrorCode,
-			&i.TraceMetadata,
+			&i.Name,
+			&i.Icon,
+			&i.CallbackURL,
 		); err != nil {
 			return nil, err
 		}
@@ -2287,227 +2590,439 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 	return items, nil
 }
 
-const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
-SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata FROM provisioner_jobs WHERE created_at > $1
-`
-
-func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ProvisionerJob
-	for rows.Next() {
-		var i ProvisionerJob
-		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.StartedAt,
-			&i.CanceledAt,
-			&i.CompletedAt,
-			&i.Error,
-			&i.OrganizationID,
-			&i.InitiatorID,
-			&i.Provisioner,
-			&i.StorageMethod,
-			&i.Type,
-			&i.Input,
-			&i.WorkerID,
-			&i.FileID,
-			&i.Tags,
-			&i.ErrorCode,
-			&i.TraceMetadata,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+const insertOAuth2DeviceCode = `-- name: InsertOAuth2DeviceCode :one
+INSERT INTO oauth2_device_codes (
+	id,
+	created_at,
+	expires_at,
+	secret_prefix,
+	hashed_secret,
+	user_code,
+	scope,
+	polling_interval
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8
+) RETURNING id, created_at, expires_at, secret_prefix, hashed_secret, user_code, scope, status, user_id, polling_interval, last_polled_at
+`
+
+type InsertOAuth2DeviceCodeParams struct {
+	ID              uuid.UUID   `db:"id" json:"id"`
+	CreatedAt       time.Time   `db:"created_at" json:"created_at"`
+	ExpiresAt       time.Time   `db:"expires_at" json:"expires_at"`
+	SecretPrefix    []byte      `db:"secret_prefix" json:"secret_prefix"`
+	HashedSecret    []byte      `db:"hashed_secret" json:"hashed_secret"`
+	UserCode        string      `db:"user_code" json:"user_code"`
+	Scope           APIKeyScope `db:"scope" json:"scope"`
+	PollingInterval int32       `db:"polling_interval" json:"polling_interval"`
 }
 
-const insertProvisionerJob = `-- name: InsertProvisionerJob :one
-INSERT INTO
-	provisioner_jobs (
-		id,
-		created_at,
-		updated_at,
-		organization_id,
-		initiator_id,
-		provisioner,
-		storage_method,
-		file_id,
-		"type",
-		"input",
-		tags,
-		trace_metadata
+func (q *sqlQuerier) InsertOAuth2DeviceCode(ctx context.Context, arg InsertOAuth2DeviceCodeParams) (OAuth2DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2DeviceCode,
+		arg.ID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.SecretPrefix,
+		arg.HashedSecret,
+		arg.UserCode,
+		arg.Scope,
+		arg.PollingInterval,
 	)
-VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
-`
+	var i OAuth2DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserCode,
+		&i.Scope,
+		&i.Status,
+		&i.UserID,
+		&i.PollingInterval,
+		&i.LastPolledAt,
+	)
+	return i, err
+}
 
-type InsertProvisionerJobParams struct {
-	ID             uuid.UUID                `db:"id" json:"id"`
-	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
-	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
-	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
-	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
-	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
-	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
-	Type           ProvisionerJobType       `db:"type" json:"type"`
-	Input          json.RawMessage          `db:"input" json:"input"`
-	Tags           StringMap                `db:"tags" json:"tags"`
-	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+const insertOAuth2ProviderApp = `-- name: InsertOAuth2ProviderApp :one
+INSERT INTO oauth2_provider_apps (
+	id,
+	created_at,
+	updated_at,
+	name,
+	icon,
+	callback_url
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6
+) RETURNING id, created_at, updated_at, name, icon, callback_url
+`
+
+type InsertOAuth2ProviderAppParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Name        string    `db:"name" json:"name"`
+	Icon        string    `db:"icon" json:"icon"`
+	CallbackURL string    `db:"callback_url" json:"callback_url"`
 }
 
-func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+func (q *sqlQuerier) InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAuth2ProviderAppParams) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderApp,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.OrganizationID,
-		arg.InitiatorID,
-		arg.Provisioner,
-		arg.StorageMethod,
-		arg.FileID,
-		arg.Type,
-		arg.Input,
-		arg.Tags,
-		arg.TraceMetadata,
+		arg.Name,
+		arg.Icon,
+		arg.CallbackURL,
 	)
-	var i ProvisionerJob
+	var i OAuth2ProviderApp
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
-		&i.OrganizationID,
-		&i.InitiatorID,
-		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
-		&i.ErrorCode,
-		&i.TraceMetadata,
+		&i.Name,
+		&i.Icon,
+		&i.CallbackURL,
 	)
 	return i, err
 }
 
-const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
-UPDATE
-	provisioner_jobs
-SET
-	updated_at = $2
-WHERE
-	id = $1
+const insertOAuth2ProviderAppCode = `-- name: InsertOAuth2ProviderAppCode :one
+INSERT INTO oauth2_provider_app_codes (
+	id,
+	created_at,
+	expires_at,
+	secret_prefix,
+	hashed_secret,
+	app_id,
+	user_id,
+	scope,
+	redirect_uri,
+	code_challenge
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9,
+	$10
+) RETURNING id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, scope, redirect_uri, code_challenge
 `
 
-type UpdateProvisionerJobByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
-	return err
+type InsertOAuth2ProviderAppCodeParams struct {
+	ID            uuid.UUID   `db:"id" json:"id"`
+	CreatedAt     time.Time   `db:"created_at" json:"created_at"`
+	ExpiresAt     time.Time   `db:"expires_at" json:"expires_at"`
+	SecretPrefix  []byte      `db:"secret_prefix" json:"secret_prefix"`
+	HashedSecret  []byte      `db:"hashed_secret" json:"hashed_secret"`
+	AppID         uuid.UUID   `db:"app_id" json:"app_id"`
+	UserID        uuid.UUID   `db:"user_id" json:"user_id"`
+	Scope         APIKeyScope `db:"scope" json:"scope"`
+	RedirectURI   string      `db:"redirect_uri" json:"redirect_uri"`
+	CodeChallenge string      `db:"code_challenge" json:"code_challenge"`
 }
 
-const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
-UPDATE
-	provisioner_jobs
-SET
-	canceled_at = $2,
-	completed_at = $3
-WHERE
-	id = $1
+func (q *sqlQuerier) InsertOAuth2ProviderAppCode(ctx context.Context, arg InsertOAuth2ProviderAppCodeParams) (OAuth2ProviderAppCode, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderAppCode,
+		arg.ID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.SecretPrefix,
+		arg.HashedSecret,
+		arg.AppID,
+		arg.UserID,
+		arg.Scope,
+		arg.RedirectURI,
+		arg.CodeChallenge,
+	)
+	var i OAuth2ProviderAppCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserID,
+		&i.AppID,
+		&i.Scope,
+		&i.RedirectURI,
+		&i.CodeChallenge,
+	)
+	return i, err
+}
+
+const insertOAuth2ProviderAppSecret = `-- name: InsertOAuth2ProviderAppSecret :one
+INSERT INTO oauth2_provider_app_secrets (
+	id,
+	created_at,
+	secret_prefix,
+	hashed_secret,
+	display_secret,
+	app_id
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6
+) RETURNING id, created_at, last_used_at, hashed_secret, secret_prefix, display_secret, app_id
+`
+
+type InsertOAuth2ProviderAppSecretParams struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	SecretPrefix  []byte    `db:"secret_prefix" json:"secret_prefix"`
+	HashedSecret  []byte    `db:"hashed_secret" json:"hashed_secret"`
+	DisplaySecret string    `db:"display_secret" json:"display_secret"`
+	AppID         uuid.UUID `db:"app_id" json:"app_id"`
+}
+
+func (q *sqlQuerier) InsertOAuth2ProviderAppSecret(ctx context.Context, arg InsertOAuth2ProviderAppSecretParams) (OAuth2ProviderAppSecret, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderAppSecret,
+		arg.ID,
+		arg.CreatedAt,
+		arg.SecretPrefix,
+		arg.HashedSecret,
+		arg.DisplaySecret,
+		arg.AppID,
+	)
+	var i OAuth2ProviderAppSecret
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.HashedSecret,
+		&i.SecretPrefix,
+		&i.DisplaySecret,
+		&i.AppID,
+	)
+	return i, err
+}
+
+const insertOAuth2ProviderAppToken = `-- name: InsertOAuth2ProviderAppToken :one
+INSERT INTO oauth2_provider_app_tokens (
+	id,
+	created_at,
+	expires_at,
+	hash_prefix,
+	refresh_hash,
+	app_secret_id,
+	api_key_id
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7
+) RETURNING id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id
+`
+
+type InsertOAuth2ProviderAppTokenParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
+	HashPrefix  []byte    `db:"hash_prefix" json:"hash_prefix"`
+	RefreshHash []byte    `db:"refresh_hash" json:"refresh_hash"`
+	AppSecretID uuid.UUID `db:"app_secret_id" json:"app_secret_id"`
+	APIKeyID    string    `db:"api_key_id" json:"api_key_id"`
+}
+
+func (q *sqlQuerier) InsertOAuth2ProviderAppToken(ctx context.Context, arg InsertOAuth2ProviderAppTokenParams) (OAuth2ProviderAppToken, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderAppToken,
+		arg.ID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.HashPrefix,
+		arg.RefreshHash,
+		arg.AppSecretID,
+		arg.APIKeyID,
+	)
+	var i OAuth2ProviderAppToken
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.HashPrefix,
+		&i.RefreshHash,
+		&i.AppSecretID,
+		&i.APIKeyID,
+	)
+	return i, err
+}
+
+const updateOAuth2DeviceCodePolledByID = `-- name: UpdateOAuth2DeviceCodePolledByID :one
+UPDATE oauth2_device_codes SET
+	last_polled_at = $2,
+	polling_interval = $3
+WHERE id = $1 RETURNING id, created_at, expires_at, secret_prefix, hashed_secret, user_code, scope, status, user_id, polling_interval, last_polled_at
 `
 
-type UpdateProvisionerJobWithCancelByIDParams struct {
-	ID          uuid.UUID    `db:"id" json:"id"`
-	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
-	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+type UpdateOAuth2DeviceCodePolledByIDParams struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	LastPolledAt    sql.NullTime `db:"last_polled_at" json:"last_polled_at"`
+	PollingInterval int32        `db:"polling_interval" json:"polling_interval"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
-	return err
+func (q *sqlQuerier) UpdateOAuth2DeviceCodePolledByID(ctx context.Context, arg UpdateOAuth2DeviceCodePolledByIDParams) (OAuth2DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2DeviceCodePolledByID, arg.ID, arg.LastPolledAt, arg.PollingInterval)
+	var i OAuth2DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserCode,
+		&i.Scope,
+		&i.Status,
+		&i.UserID,
+		&i.PollingInterval,
+		&i.LastPolledAt,
+	)
+	return i, err
 }
 
-const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
-UPDATE
-	provisioner_jobs
-SET
+const updateOAuth2DeviceCodeStatusByID = `-- name: UpdateOAuth2DeviceCodeStatusByID :one
+UPDATE oauth2_device_codes SET
+	status = $2,
+	user_id = $3
+WHERE id = $1 AND status = 'pending' RETURNING id, created_at, expires_at, secret_prefix, hashed_secret, user_code, scope, status, user_id, polling_interval, last_polled_at
+`
+
+type UpdateOAuth2DeviceCodeStatusByIDParams struct {
+	ID     uuid.UUID              `db:"id" json:"id"`
+	Status OAuth2DeviceCodeStatus `db:"status" json:"status"`
+	UserID uuid.NullUUID          `db:"user_id" json:"user_id"`
+}
+
+// Only pending requests can be approved or denied.
+func (q *sqlQuerier) UpdateOAuth2DeviceCodeStatusByID(ctx context.Context, arg UpdateOAuth2DeviceCodeStatusByIDParams) (OAuth2DeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2DeviceCodeStatusByID, arg.ID, arg.Status, arg.UserID)
+	var i OAuth2DeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.SecretPrefix,
+		&i.HashedSecret,
+		&i.UserCode,
+		&i.Scope,
+		&i.Status,
+		&i.UserID,
+		&i.PollingInterval,
+		&i.LastPolledAt,
+	)
+	return i, err
+}
+
+const updateOAuth2ProviderAppByID = `-- name: UpdateOAuth2ProviderAppByID :one
+UPDATE oauth2_provider_apps SET
 	updated_at = $2,
-	completed_at = $3,
-	error = $4,
-	error_code = $5
-WHERE
-	id = $1
+	name = $3,
+	icon = $4,
+	callback_url = $5
+WHERE id = $1 RETURNING id, created_at, updated_at, name, icon, callback_url
 `
 
-type UpdateProvisionerJobWithCompleteByIDParams struct {
-	ID          uuid.UUID      `db:"id" json:"id"`
-	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
-	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
-	Error       sql.NullString `db:"error" json:"error"`
-	ErrorCode   sql.NullString `db:"error_code" json:"error_code"`
+type UpdateOAuth2ProviderAppByIDParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Name        string    `db:"name" json:"name"`
+	Icon        string    `db:"icon" json:"icon"`
+	CallbackURL string    `db:"callback_url" json:"callback_url"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
+func (q *sqlQuerier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg UpdateOAuth2ProviderAppByIDParams) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2ProviderAppByID,
 		arg.ID,
 		arg.UpdatedAt,
-		arg.CompletedAt,
-		arg.Error,
-		arg.ErrorCode,
+		arg.Name,
+		arg.Icon,
+		arg.CallbackURL,
 	)
-	return err
+	var i OAuth2ProviderApp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Icon,
+		&i.CallbackURL,
+	)
+	return i, err
 }
 
-const getWorkspaceProxies = `-- name: GetWorkspaceProxies :many
+const updateOAuth2ProviderAppSecretByID = `-- name: UpdateOAuth2ProviderAppSecretByID :one
+UPDATE oauth2_provider_app_secrets SET
+	last_used_at = $2
+WHERE id = $1 RETURNING id, created_at, last_used_at, hashed_secret, secret_prefix, display_secret, app_id
+`
+
+type UpdateOAuth2ProviderAppSecretByIDParams struct {
+	ID         uuid.UUID    `db:"id" json:"id"`
+	LastUsedAt sql.NullTime `db:"last_used_at" json:"last_used_at"`
+}
+
+func (q *sqlQuerier) UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg UpdateOAuth2ProviderAppSecretByIDParams) (OAuth2ProviderAppSecret, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2ProviderAppSecretByID, arg.ID, arg.LastUsedAt)
+	var i OAuth2ProviderAppSecret
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.HashedSecret,
+		&i.SecretPrefix,
+		&i.DisplaySecret,
+		&i.AppID,
+	)
+	return i, err
+}
+
+const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
 FROM
-	workspace_proxies
+    organization_members
 WHERE
-	deleted = false
+    user_id = ANY($1 :: uuid [ ])
+GROUP BY
+    user_id
 `
 
-func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceProxies)
+type GetOrganizationIDsByMemberIDsRow struct {
+	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
+	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
+}
+
+func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WorkspaceProxy
+	var items []GetOrganizationIDsByMemberIDsRow
 	for rows.Next() {
-		var i WorkspaceProxy
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.DisplayName,
-			&i.Icon,
-			&i.Url,
-			&i.WildcardHostname,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Deleted,
-			&i.TokenHashedSecret,
-		); err != nil {
+		var i GetOrganizationIDsByMemberIDsRow
+		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -2521,372 +3036,258 @@ func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy,
 	return items, nil
 }
 
-const getWorkspaceProxyByHostname = `-- name: GetWorkspaceProxyByHostname :one
+const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+	user_id, organization_id, created_at, updated_at, roles
 FROM
-	workspace_proxies
+	organization_members
 WHERE
-	-- Validate that the @hostname has been sanitized and is not empty. This
-	-- doesn't prevent SQL injection (already prevented by using prepared
-	-- queries), but it does prevent carefully crafted hostnames from matching
-	-- when they shouldn't.
-	--
-	-- Periods don't need to be escaped because they're not special characters
-	-- in SQL matches unlike regular expressions.
-	$1 :: text SIMILAR TO '[a-zA-Z0-9._-]+' AND
-	deleted = false AND
-
-	-- Validate that the hostname matches either the wildcard hostname or the
-	-- access URL (ignoring scheme, port and path).
-	(
-		(
-			$2 :: bool = true AND
-			url SIMILAR TO '[^:]*://' || $1 :: text || '([:/]?%)*'
-		) OR
-		(
-			$3 :: bool = true AND
-			$1 :: text LIKE replace(wildcard_hostname, '*', '%')
-		)
-	)
+	organization_id = $1
+	AND user_id = $2
 LIMIT
 	1
 `
 
-type GetWorkspaceProxyByHostnameParams struct {
-	Hostname              string `db:"hostname" json:"hostname"`
-	AllowAccessUrl        bool   `db:"allow_access_url" json:"allow_access_url"`
-	AllowWildcardHostname bool   `db:"allow_wildcard_hostname" json:"allow_wildcard_hostname"`
+type GetOrganizationMemberByUserIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
 }
 
-// Finds a workspace proxy that has an access URL or app hostname that matches
-// the provided hostname. This is to check if a hostname matches any workspace
-// proxy.
-//
-// The hostname must be sanitized to only contain [a-zA-Z0-9.-] before calling
-// this query. The scheme, port and path should be stripped.
-func (q *sqlQuerier) GetWorkspaceProxyByHostname(ctx context.Context, arg GetWorkspaceProxyByHostnameParams) (WorkspaceProxy, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByHostname, arg.Hostname, arg.AllowAccessUrl, arg.AllowWildcardHostname)
-	var i WorkspaceProxy
+func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationMemberByUserID, arg.OrganizationID, arg.UserID)
+	var i OrganizationMember
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Url,
-		&i.WildcardHostname,
+		&i.UserID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Deleted,
-		&i.TokenHashedSecret,
+		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const getWorkspaceProxyByID = `-- name: GetWorkspaceProxyByID :one
+const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+	user_id, organization_id, created_at, updated_at, roles
 FROM
-	workspace_proxies
+	organization_members
 WHERE
-	id = $1
-LIMIT
-	1
+  user_id = $1
 `
 
-func (q *sqlQuerier) GetWorkspaceProxyByID(ctx context.Context, id uuid.UUID) (WorkspaceProxy, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByID, id)
-	var i WorkspaceProxy
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Url,
-		&i.WildcardHostname,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Deleted,
-		&i.TokenHashedSecret,
-	)
-	return i, err
-}
-
-const getWorkspaceProxyByName = `-- name: GetWorkspaceProxyByName :one
-SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
-FROM
-	workspace_proxies
-WHERE
-	name = $1
-	AND deleted = false
-LIMIT
-	1
-`
-
-func (q *sqlQuerier) GetWorkspaceProxyByName(ctx context.Context, name string) (WorkspaceProxy, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByName, name)
-	var i WorkspaceProxy
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Url,
-		&i.WildcardHostname,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Deleted,
-		&i.TokenHashedSecret,
-	)
-	return i, err
+func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationMembershipsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationMember
+	for rows.Next() {
+		var i OrganizationMember
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const insertWorkspaceProxy = `-- name: InsertWorkspaceProxy :one
+const insertOrganizationMember = `-- name: InsertOrganizationMember :one
 INSERT INTO
-	workspace_proxies (
-		id,
-		url,
-		wildcard_hostname,
-		name,
-		display_name,
-		icon,
-		token_hashed_secret,
+	organization_members (
+		organization_id,
+		user_id,
 		created_at,
 		updated_at,
-		deleted
+		roles
 	)
 VALUES
-	($1, '', '', $2, $3, $4, $5, $6, $7, false) RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+	($1, $2, $3, $4, $5) RETURNING user_id, organization_id, created_at, updated_at, roles
 `
 
-type InsertWorkspaceProxyParams struct {
-	ID                uuid.UUID `db:"id" json:"id"`
-	Name              string    `db:"name" json:"name"`
-	DisplayName       string    `db:"display_name" json:"display_name"`
-	Icon              string    `db:"icon" json:"icon"`
-	TokenHashedSecret []byte    `db:"token_hashed_secret" json:"token_hashed_secret"`
-	CreatedAt         time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+type InsertOrganizationMemberParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	Roles          []string  `db:"roles" json:"roles"`
 }
 
-func (q *sqlQuerier) InsertWorkspaceProxy(ctx context.Context, arg InsertWorkspaceProxyParams) (WorkspaceProxy, error) {
-	row := q.db.QueryRowContext(ctx, insertWorkspaceProxy,
-		arg.ID,
-		arg.Name,
-		arg.DisplayName,
-		arg.Icon,
-		arg.TokenHashedSecret,
+func (q *sqlQuerier) InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationMember,
+		arg.OrganizationID,
+		arg.UserID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		pq.Array(arg.Roles),
 	)
-	var i WorkspaceProxy
+	var i OrganizationMember
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Url,
-		&i.WildcardHostname,
+		&i.UserID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Deleted,
-		&i.TokenHashedSecret,
+		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const registerWorkspaceProxy = `-- name: RegisterWorkspaceProxy :one
+const updateMemberRoles = `-- name: UpdateMemberRoles :one
 UPDATE
-	workspace_proxies
+	organization_members
 SET
-	url = $1,
-	wildcard_hostname = $2,
-	updated_at = Now()
+	-- Remove all duplicates from the roles.
+	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
 WHERE
-	id = $3
-RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+	user_id = $2
+	AND organization_id = $3
+RETURNING user_id, organization_id, created_at, updated_at, roles
 `
 
-type RegisterWorkspaceProxyParams struct {
-	Url              string    `db:"url" json:"url"`
-	WildcardHostname string    `db:"wildcard_hostname" json:"wildcard_hostname"`
-	ID               uuid.UUID `db:"id" json:"id"`
+type UpdateMemberRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	OrgID        uuid.UUID `db:"org_id" json:"org_id"`
 }
 
-func (q *sqlQuerier) RegisterWorkspaceProxy(ctx context.Context, arg RegisterWorkspaceProxyParams) (WorkspaceProxy, error) {
-	row := q.db.QueryRowContext(ctx, registerWorkspaceProxy, arg.Url, arg.WildcardHostname, arg.ID)
-	var i WorkspaceProxy
+func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, updateMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.OrgID)
+	var i OrganizationMember
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Url,
-		&i.WildcardHostname,
+		&i.UserID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Deleted,
-		&i.TokenHashedSecret,
+		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const updateWorkspaceProxy = `-- name: UpdateWorkspaceProxy :one
-UPDATE
-	workspace_proxies
-SET
-	-- These values should always be provided.
-	name = $1,
-	display_name = $2,
-	icon = $3,
-	-- Only update the token if a new one is provided.
-	-- So this is an optional field.
-	token_hashed_secret = CASE
-		WHEN length($4 :: bytea) > 0  THEN $4 :: bytea
-		ELSE  workspace_proxies.token_hashed_secret
-	END,
-	-- Always update this timestamp.
-	updated_at = Now()
+const getOrganizationByID = `-- name: GetOrganizationByID :one
+SELECT
+	id, name, description, created_at, updated_at
+FROM
+	organizations
 WHERE
-	id = $5
-RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+	id = $1
 `
 
-type UpdateWorkspaceProxyParams struct {
-	Name              string    `db:"name" json:"name"`
-	DisplayName       string    `db:"display_name" json:"display_name"`
-	Icon              string    `db:"icon" json:"icon"`
-	TokenHashedSecret []byte    `db:"token_hashed_secret" json:"token_hashed_secret"`
-	ID                uuid.UUID `db:"id" json:"id"`
-}
-
-// This allows editing the properties of a workspace proxy.
-func (q *sqlQuerier) UpdateWorkspaceProxy(ctx context.Context, arg UpdateWorkspaceProxyParams) (WorkspaceProxy, error) {
-	row := q.db.QueryRowContext(ctx, updateWorkspaceProxy,
-		arg.Name,
-		arg.DisplayName,
-		arg.Icon,
-		arg.TokenHashedSecret,
-		arg.ID,
-	)
-	var i WorkspaceProxy
+func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
+	var i Organization
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Url,
-		&i.WildcardHostname,
+		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Deleted,
-		&i.TokenHashedSecret,
 	)
 	return i, err
 }
 
-const updateWorkspaceProxyDeleted = `-- name: UpdateWorkspaceProxyDeleted :exec
-UPDATE
-	workspace_proxies
-SET
-	updated_at = Now(),
-	deleted = $1
+const getOrganizationByName = `-- name: GetOrganizationByName :one
+SELECT
+	id, name, description, created_at, updated_at
+FROM
+	organizations
 WHERE
-	id = $2
+	LOWER("name") = LOWER($1)
+LIMIT
+	1
 `
 
-type UpdateWorkspaceProxyDeletedParams struct {
-	Deleted bool      `db:"deleted" json:"deleted"`
-	ID      uuid.UUID `db:"id" json:"id"`
-}
-
-func (q *sqlQuerier) UpdateWorkspaceProxyDeleted(ctx context.Context, arg UpdateWorkspaceProxyDeletedParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceProxyDeleted, arg.Deleted, arg.ID)
-	return err
+func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-const getQuotaAllowanceForUser = `-- name: GetQuotaAllowanceForUser :one
+const getOrganizations = `-- name: GetOrganizations :many
 SELECT
-	coalesce(SUM(quota_allowance), 0)::BIGINT
+	id, name, description, created_at, updated_at
 FROM
-	group_members gm
-JOIN groups g ON
-	g.id = gm.group_id
-WHERE
-	user_id = $1
+	organizations
 `
 
-func (q *sqlQuerier) GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getQuotaAllowanceForUser, userID)
-	var column_1 int64
-	err := row.Scan(&column_1)
-	return column_1, err
-}
-
-const getQuotaConsumedForUser = `-- name: GetQuotaConsumedForUser :one
-WITH latest_builds AS (
-SELECT
-	DISTINCT ON
-	(workspace_id) id,
-	workspace_id,
-	daily_cost
-FROM
-	workspace_builds wb
-ORDER BY
-	workspace_id,
-	created_at DESC
-)
-SELECT
-	coalesce(SUM(daily_cost), 0)::BIGINT
-FROM
-	workspaces
-JOIN latest_builds ON
-	latest_builds.workspace_id = workspaces.id
-WHERE NOT deleted AND workspaces.owner_id = $1
-`
-
-func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUser, ownerID)
-	var column_1 int64
-	err := row.Scan(&column_1)
-	return column_1, err
-}
-
-const deleteReplicasUpdatedBefore = `-- name: DeleteReplicasUpdatedBefore :exec
-DELETE FROM replicas WHERE updated_at < $1
-`
-
-func (q *sqlQuerier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
-	_, err := q.db.ExecContext(ctx, deleteReplicasUpdatedBefore, updatedAt)
-	return err
+func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getReplicasUpdatedAfter = `-- name: GetReplicasUpdatedAfter :many
-SELECT id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error FROM replicas WHERE updated_at > $1 AND stopped_at IS NULL
+const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
+SELECT
+	id, name, description, created_at, updated_at
+FROM
+	organizations
+WHERE
+	id = (
+		SELECT
+			organization_id
+		FROM
+			organization_members
+		WHERE
+			user_id = $1
+	)
 `
 
-func (q *sqlQuerier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error) {
-	rows, err := q.db.QueryContext(ctx, getReplicasUpdatedAfter, updatedAt)
+func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationsByUserID, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Replica
+	var items []Organization
 	for rows.Next() {
-		var i Replica
+		var i Organization
 		if err := rows.Scan(
 			&i.ID,
+			&i.Name,
+			&i.Description,
 			&i.CreatedAt,
-			&i.StartedAt,
-			&i.StoppedAt,
 			&i.UpdatedAt,
-			&i.Hostname,
-			&i.RegionID,
-			&i.RelayAddress,
-			&i.DatabaseLatency,
-			&i.Version,
-			&i.Error,
 		); err != nil {
 			return nil, err
 		}
@@ -2901,467 +3302,3038 @@ func (q *sqlQuerier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time
 	return items, nil
 }
 
-const insertReplica = `-- name: InsertReplica :one
-INSERT INTO replicas (
-    id,
-    created_at,
-    started_at,
-    updated_at,
-    hostname,
-    region_id,
-    relay_address,
-    version,
-    database_latency
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
+const insertOrganization = `-- name: InsertOrganization :one
+INSERT INTO
+	organizations (id, "name", description, created_at, updated_at)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING id, name, description, created_at, updated_at
 `
 
-type InsertReplicaParams struct {
-	ID              uuid.UUID `db:"id" json:"id"`
-	CreatedAt       time.Time `db:"created_at" json:"created_at"`
-	StartedAt       time.Time `db:"started_at" json:"started_at"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
-	Hostname        string    `db:"hostname" json:"hostname"`
-	RegionID        int32     `db:"region_id" json:"region_id"`
-	RelayAddress    string    `db:"relay_address" json:"relay_address"`
-	Version         string    `db:"version" json:"version"`
-	DatabaseLatency int32     `db:"database_latency" json:"database_latency"`
+type InsertOrganizationParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error) {
-	row := q.db.QueryRowContext(ctx, insertReplica,
+func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganization,
 		arg.ID,
+		arg.Name,
+		arg.Description,
 		arg.CreatedAt,
-		arg.StartedAt,
 		arg.UpdatedAt,
-		arg.Hostname,
-		arg.RegionID,
-		arg.RelayAddress,
-		arg.Version,
-		arg.DatabaseLatency,
 	)
-	var i Replica
+	var i Organization
 	err := row.Scan(
 		&i.ID,
+		&i.Name,
+		&i.Description,
 		&i.CreatedAt,
-		&i.StartedAt,
-		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Hostname,
-		&i.RegionID,
-		&i.RelayAddress,
-		&i.DatabaseLatency,
-		&i.Version,
-		&i.Error,
 	)
 	return i, err
 }
 
-const updateReplica = `-- name: UpdateReplica :one
-UPDATE replicas SET
-    updated_at = $2,
-    started_at = $3,
-    stopped_at = $4,
-    relay_address = $5,
-    region_id = $6,
-    hostname = $7,
-    version = $8,
-    error = $9,
-    database_latency = $10
-WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
+const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+SELECT
+	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+FROM
+	parameter_schemas
+WHERE
+	job_id = $1
+ORDER BY
+	index
 `
 
-type UpdateReplicaParams struct {
-	ID              uuid.UUID    `db:"id" json:"id"`
-	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
-	StartedAt       time.Time    `db:"started_at" json:"started_at"`
-	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
-	RelayAddress    string       `db:"relay_address" json:"relay_address"`
-	RegionID        int32        `db:"region_id" json:"region_id"`
-	Hostname        string       `db:"hostname" json:"hostname"`
-	Version         string       `db:"version" json:"version"`
-	Error           string       `db:"error" json:"error"`
-	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
+func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
+	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParameterSchema
+	for rows.Next() {
+		var i ParameterSchema
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.JobID,
+			&i.Name,
+			&i.Description,
+			&i.DefaultSourceScheme,
+			&i.DefaultSourceValue,
+			&i.AllowOverrideSource,
+			&i.DefaultDestinationScheme,
+			&i.AllowOverrideDestination,
+			&i.DefaultRefresh,
+			&i.RedisplayValue,
+			&i.ValidationError,
+			&i.ValidationCondition,
+			&i.ValidationTypeSystem,
+			&i.ValidationValueType,
+			&i.Index,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
-	row := q.db.QueryRowContext(ctx, updateReplica,
-		arg.ID,
-		arg.UpdatedAt,
-		arg.StartedAt,
-		arg.StoppedAt,
-		arg.RelayAddress,
-		arg.RegionID,
-		arg.Hostname,
-		arg.Version,
-		arg.Error,
-		arg.DatabaseLatency,
-	)
-	var i Replica
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.StartedAt,
-		&i.StoppedAt,
-		&i.UpdatedAt,
-		&i.Hostname,
-		&i.RegionID,
-		&i.RelayAddress,
-		&i.DatabaseLatency,
-		&i.Version,
-		&i.Error,
+const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
+SELECT
+	id, created_at, updated_at, name, provisioners, replica_id, tags
+FROM
+	provisioner_daemons
+`
+
+func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerDaemons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerDaemon
+	for rows.Next() {
+		var i ProvisionerDaemon
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			pq.Array(&i.Provisioners),
+			&i.ReplicaID,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerDaemon = `-- name: InsertProvisionerDaemon :one
+INSERT INTO
+	provisioner_daemons (
+		id,
+		created_at,
+		"name",
+		provisioners,
+		tags
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags
+`
+
+type InsertProvisionerDaemonParams struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+	Name         string            `db:"name" json:"name"`
+	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
+	Tags         StringMap         `db:"tags" json:"tags"`
+}
+
+func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerDaemon,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Name,
+		pq.Array(arg.Provisioners),
+		arg.Tags,
+	)
+	var i ProvisionerDaemon
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		pq.Array(&i.Provisioners),
+		&i.ReplicaID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getProvisionerLogsAfterID = `-- name: GetProvisionerLogsAfterID :many
+SELECT
+	job_id, created_at, source, level, stage, output, id
+FROM
+	provisioner_job_logs
+WHERE
+	job_id = $1
+	AND (
+		id > $2
+	) ORDER BY id ASC
+`
+
+type GetProvisionerLogsAfterIDParams struct {
+	JobID        uuid.UUID `db:"job_id" json:"job_id"`
+	CreatedAfter int64     `db:"created_after" json:"created_after"`
+}
+
+func (q *sqlQuerier) GetProvisionerLogsAfterID(ctx context.Context, arg GetProvisionerLogsAfterIDParams) ([]ProvisionerJobLog, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerLogsAfterID, arg.JobID, arg.CreatedAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobLog
+	for rows.Next() {
+		var i ProvisionerJobLog
+		if err := rows.Scan(
+			&i.JobID,
+			&i.CreatedAt,
+			&i.Source,
+			&i.Level,
+			&i.Stage,
+			&i.Output,
+			&i.ID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
+INSERT INTO
+	provisioner_job_logs
+SELECT
+	$1 :: uuid AS job_id,
+	unnest($2 :: timestamptz [ ]) AS created_at,
+	unnest($3 :: log_source [ ]) AS source,
+	unnest($4 :: log_level [ ]) AS LEVEL,
+	unnest($5 :: VARCHAR(128) [ ]) AS stage,
+	unnest($6 :: VARCHAR(1024) [ ]) AS output RETURNING job_id, created_at, source, level, stage, output, id
+`
+
+type InsertProvisionerJobLogsParams struct {
+	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
+	CreatedAt []time.Time `db:"created_at" json:"created_at"`
+	Source    []LogSource `db:"source" json:"source"`
+	Level     []LogLevel  `db:"level" json:"level"`
+	Stage     []string    `db:"stage" json:"stage"`
+	Output    []string    `db:"output" json:"output"`
+}
+
+func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
+	rows, err := q.db.QueryContext(ctx, insertProvisionerJobLogs,
+		arg.JobID,
+		pq.Array(arg.CreatedAt),
+		pq.Array(arg.Source),
+		pq.Array(arg.Level),
+		pq.Array(arg.Stage),
+		pq.Array(arg.Output),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobLog
+	for rows.Next() {
+		var i ProvisionerJobLog
+		if err := rows.Scan(
+			&i.JobID,
+			&i.CreatedAt,
+			&i.Source,
+			&i.Level,
+			&i.Stage,
+			&i.Output,
+			&i.ID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const acquireProvisionerJob = `-- name: AcquireProvisionerJob :one
+UPDATE
+	provisioner_jobs
+SET
+	started_at = $1,
+	updated_at = $1,
+	worker_id = $2
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			provisioner_jobs AS nested
+		WHERE
+			nested.started_at IS NULL
+			-- Ensure the caller has the correct provisioner.
+			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
+			-- Ensure the caller satisfies all job tags.
+			AND nested.tags <@ $4 :: jsonb
+		ORDER BY
+			nested.created_at
+		FOR UPDATE
+		SKIP LOCKED
+		LIMIT
+			1
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+`
+
+type AcquireProvisionerJobParams struct {
+	StartedAt sql.NullTime      `db:"started_at" json:"started_at"`
+	WorkerID  uuid.NullUUID     `db:"worker_id" json:"worker_id"`
+	Types     []ProvisionerType `db:"types" json:"types"`
+	Tags      json.RawMessage   `db:"tags" json:"tags"`
+}
+
+// Acquires the lock for a single job that isn't started, completed,
+// canceled, and that matches an array of provisioner types.
+//
+// SKIP LOCKED is used to jump over locked rows. This prevents
+// multiple provisioners from acquiring the same jobs. See:
+// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
+func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, acquireProvisionerJob,
+		arg.StartedAt,
+		arg.WorkerID,
+		pq.Array(arg.Types),
+		arg.Tags,
+	)
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.ErrorCode,
+		&i.TraceMetadata,
+	)
+	return i, err
+}
+
+const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
+SELECT
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+FROM
+	provisioner_jobs
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.ErrorCode,
+		&i.TraceMetadata,
+	)
+	return i, err
+}
+
+const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
+SELECT
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+FROM
+	provisioner_jobs
+WHERE
+	id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJob
+	for rows.Next() {
+		var i ProvisionerJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+			&i.FileID,
+			&i.Tags,
+			&i.ErrorCode,
+			&i.TraceMetadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
+SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata FROM provisioner_jobs WHERE created_at > $1
+`
+
+func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJob
+	for rows.Next() {
+		var i ProvisionerJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+			&i.FileID,
+			&i.Tags,
+			&i.ErrorCode,
+			&i.TraceMetadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerJob = `-- name: InsertProvisionerJob :one
+INSERT INTO
+	provisioner_jobs (
+		id,
+		created_at,
+		updated_at,
+		organization_id,
+		initiator_id,
+		provisioner,
+		storage_method,
+		file_id,
+		"type",
+		"input",
+		tags,
+		trace_metadata
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+`
+
+type InsertProvisionerJobParams struct {
+	ID             uuid.UUID                `db:"id" json:"id"`
+	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
+	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
+	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
+	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
+	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
+	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
+	Type           ProvisionerJobType       `db:"type" json:"type"`
+	Input          json.RawMessage          `db:"input" json:"input"`
+	Tags           StringMap                `db:"tags" json:"tags"`
+	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+}
+
+func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.OrganizationID,
+		arg.InitiatorID,
+		arg.Provisioner,
+		arg.StorageMethod,
+		arg.FileID,
+		arg.Type,
+		arg.Input,
+		arg.Tags,
+		arg.TraceMetadata,
+	)
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.ErrorCode,
+		&i.TraceMetadata,
+	)
+	return i, err
+}
+
+const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	canceled_at = $2,
+	completed_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCancelByIDParams struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
+	return err
+}
+
+const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	completed_at = $3,
+	error = $4,
+	error_code = $5
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCompleteByIDParams struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
+	Error       sql.NullString `db:"error" json:"error"`
+	ErrorCode   sql.NullString `db:"error_code" json:"error_code"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+		arg.Error,
+		arg.ErrorCode,
+	)
+	return err
+}
+
+const getWorkspaceProxies = `-- name: GetWorkspaceProxies :many
+SELECT
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+FROM
+	workspace_proxies
+WHERE
+	deleted = false
+`
+
+func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceProxies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceProxy
+	for rows.Next() {
+		var i WorkspaceProxy
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.DisplayName,
+			&i.Icon,
+			&i.Url,
+			&i.WildcardHostname,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Deleted,
+			&i.TokenHashedSecret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceProxyByHostname = `-- name: GetWorkspaceProxyByHostname :one
+SELECT
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+FROM
+	workspace_proxies
+WHERE
+	-- Validate that the @hostname has been sanitized and is not empty. This
+	-- doesn't prevent SQL injection (already prevented by using prepared
+	-- queries), but it does prevent carefully crafted hostnames from matching
+	-- when they shouldn't.
+	--
+	-- Periods don't need to be escaped because they're not special characters
+	-- in SQL matches unlike regular expressions.
+	$1 :: text SIMILAR TO '[a-zA-Z0-9._-]+' AND
+	deleted = false AND
+
+	-- Validate that the hostname matches either the wildcard hostname or the
+	-- access URL (ignoring scheme, port and path).
+	(
+		(
+			$2 :: bool = true AND
+			url SIMILAR TO '[^:]*://' || $1 :: text || '([:/]?%)*'
+		) OR
+		(
+			$3 :: bool = true AND
+			$1 :: text LIKE replace(wildcard_hostname, '*', '%')
+		)
+	)
+LIMIT
+	1
+`
+
+type GetWorkspaceProxyByHostnameParams struct {
+	Hostname              string `db:"hostname" json:"hostname"`
+	AllowAccessUrl        bool   `db:"allow_access_url" json:"allow_access_url"`
+	AllowWildcardHostname bool   `db:"allow_wildcard_hostname" json:"allow_wildcard_hostname"`
+}
+
+// Finds a workspace proxy that has an access URL or app hostname that matches
+// the provided hostname. This is to check if a hostname matches any workspace
+// proxy.
+//
+// The hostname must be sanitized to only contain [a-zA-Z0-9.-] before calling
+// this query. The scheme, port and path should be stripped.
+func (q *sqlQuerier) GetWorkspaceProxyByHostname(ctx context.Context, arg GetWorkspaceProxyByHostnameParams) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByHostname, arg.Hostname, arg.AllowAccessUrl, arg.AllowWildcardHostname)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+	)
+	return i, err
+}
+
+const getWorkspaceProxyByID = `-- name: GetWorkspaceProxyByID :one
+SELECT
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+FROM
+	workspace_proxies
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetWorkspaceProxyByID(ctx context.Context, id uuid.UUID) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByID, id)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+	)
+	return i, err
+}
+
+const getWorkspaceProxyByName = `-- name: GetWorkspaceProxyByName :one
+SELECT
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+FROM
+	workspace_proxies
+WHERE
+	name = $1
+	AND deleted = false
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetWorkspaceProxyByName(ctx context.Context, name string) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByName, name)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+	)
+	return i, err
+}
+
+const insertWorkspaceProxy = `-- name: InsertWorkspaceProxy :one
+INSERT INTO
+	workspace_proxies (
+		id,
+		url,
+		wildcard_hostname,
+		name,
+		display_name,
+		icon,
+		token_hashed_secret,
+		created_at,
+		updated_at,
+		deleted
+	)
+VALUES
+	($1, '', '', $2, $3, $4, $5, $6, $7, false) RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+`
+
+type InsertWorkspaceProxyParams struct {
+	ID                uuid.UUID `db:"id" json:"id"`
+	Name              string    `db:"name" json:"name"`
+	DisplayName       string    `db:"display_name" json:"display_name"`
+	Icon              string    `db:"icon" json:"icon"`
+	TokenHashedSecret []byte    `db:"token_hashed_secret" json:"token_hashed_secret"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceProxy(ctx context.Context, arg InsertWorkspaceProxyParams) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceProxy,
+		arg.ID,
+		arg.Name,
+		arg.DisplayName,
+		arg.Icon,
+		arg.TokenHashedSecret,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+	)
+	return i, err
+}
+
+const registerWorkspaceProxy = `-- name: RegisterWorkspaceProxy :one
+UPDATE
+	workspace_proxies
+SET
+	url = $1,
+	wildcard_hostname = $2,
+	updated_at = Now()
+WHERE
+	id = $3
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+`
+
+type RegisterWorkspaceProxyParams struct {
+	Url              string    `db:"url" json:"url"`
+	WildcardHostname string    `db:"wildcard_hostname" json:"wildcard_hostname"`
+	ID               uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) RegisterWorkspaceProxy(ctx context.Context, arg RegisterWorkspaceProxyParams) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, registerWorkspaceProxy, arg.Url, arg.WildcardHostname, arg.ID)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+	)
+	return i, err
+}
+
+const updateWorkspaceProxy = `-- name: UpdateWorkspaceProxy :one
+UPDATE
+	workspace_proxies
+SET
+	-- These values should always be provided.
+	name = $1,
+	display_name = $2,
+	icon = $3,
+	-- Only update the token if a new one is provided.
+	-- So this is an optional field.
+	token_hashed_secret = CASE
+		WHEN length($4 :: bytea) > 0  THEN $4 :: bytea
+		ELSE  workspace_proxies.token_hashed_secret
+	END,
+	-- Always update this timestamp.
+	updated_at = Now()
+WHERE
+	id = $5
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret
+`
+
+type UpdateWorkspaceProxyParams struct {
+	Name              string    `db:"name" json:"name"`
+	DisplayName       string    `db:"display_name" json:"display_name"`
+	Icon              string    `db:"icon" json:"icon"`
+	TokenHashedSecret []byte    `db:"token_hashed_secret" json:"token_hashed_secret"`
+	ID                uuid.UUID `db:"id" json:"id"`
+}
+
+// This allows editing the properties of a workspace proxy.
+func (q *sqlQuerier) UpdateWorkspaceProxy(ctx context.Context, arg UpdateWorkspaceProxyParams) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceProxy,
+		arg.Name,
+		arg.DisplayName,
+		arg.Icon,
+		arg.TokenHashedSecret,
+		arg.ID,
+	)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+	)
+	return i, err
+}
+
+const updateWorkspaceProxyDeleted = `-- name: UpdateWorkspaceProxyDeleted :exec
+UPDATE
+	workspace_proxies
+SET
+	updated_at = Now(),
+	deleted = $1
+WHERE
+	id = $2
+`
+
+type UpdateWorkspaceProxyDeletedParams struct {
+	Deleted bool      `db:"deleted" json:"deleted"`
+	ID      uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceProxyDeleted(ctx context.Context, arg UpdateWorkspaceProxyDeletedParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceProxyDeleted, arg.Deleted, arg.ID)
+	return err
+}
+
+const getQuotaAllowanceForUser = `-- name: GetQuotaAllowanceForUser :one
+SELECT
+	coalesce(SUM(quota_allowance), 0)::BIGINT
+FROM
+	group_members gm
+JOIN groups g ON
+	g.id = gm.group_id
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getQuotaAllowanceForUser, userID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getQuotaConsumedForUser = `-- name: GetQuotaConsumedForUser :one
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+)
+SELECT
+	coalesce(SUM(daily_cost), 0)::BIGINT
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+WHERE NOT deleted AND workspaces.owner_id = $1
+`
+
+func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUser, ownerID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const deleteReplicasUpdatedBefore = `-- name: DeleteReplicasUpdatedBefore :exec
+DELETE FROM replicas WHERE updated_at < $1
+`
+
+func (q *sqlQuerier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteReplicasUpdatedBefore, updatedAt)
+	return err
+}
+
+const getReplicasUpdatedAfter = `-- name: GetReplicasUpdatedAfter :many
+SELECT id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error FROM replicas WHERE updated_at > $1 AND stopped_at IS NULL
+`
+
+func (q *sqlQuerier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error) {
+	rows, err := q.db.QueryContext(ctx, getReplicasUpdatedAfter, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Replica
+	for rows.Next() {
+		var i Replica
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.StoppedAt,
+			&i.UpdatedAt,
+			&i.Hostname,
+			&i.RegionID,
+			&i.RelayAddress,
+			&i.DatabaseLatency,
+			&i.Version,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertReplica = `-- name: InsertReplica :one
+INSERT INTO replicas (
+    id,
+    created_at,
+    started_at,
+    updated_at,
+    hostname,
+    region_id,
+    relay_address,
+    version,
+    database_latency
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
+`
+
+type InsertReplicaParams struct {
+	ID              uuid.UUID `db:"id" json:"id"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	StartedAt       time.Time `db:"started_at" json:"started_at"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	Hostname        string    `db:"hostname" json:"hostname"`
+	RegionID        int32     `db:"region_id" json:"region_id"`
+	RelayAddress    string    `db:"relay_address" json:"relay_address"`
+	Version         string    `db:"version" json:"version"`
+	DatabaseLatency int32     `db:"database_latency" json:"database_latency"`
+}
+
+func (q *sqlQuerier) InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error) {
+	row := q.db.QueryRowContext(ctx, insertReplica,
+		arg.ID,
+		arg.CreatedAt,
+		arg.StartedAt,
+		arg.UpdatedAt,
+		arg.Hostname,
+		arg.RegionID,
+		arg.RelayAddress,
+		arg.Version,
+		arg.DatabaseLatency,
+	)
+	var i Replica
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.StoppedAt,
+		&i.UpdatedAt,
+		&i.Hostname,
+		&i.RegionID,
+		&i.RelayAddress,
+		&i.DatabaseLatency,
+		&i.Version,
+		&i.Error,
+	)
+	return i, err
+}
+
+const updateReplica = `-- name: UpdateReplica :one
+UPDATE replicas SET
+    updated_at = $2,
+    started_at = $3,
+    stopped_at = $4,
+    relay_address = $5,
+    region_id = $6,
+    hostname = $7,
+    version = $8,
+    error = $9,
+    database_latency = $10
+WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
+`
+
+type UpdateReplicaParams struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
+	StartedAt       time.Time    `db:"started_at" json:"started_at"`
+	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
+	RelayAddress    string       `db:"relay_address" json:"relay_address"`
+	RegionID        int32        `db:"region_id" json:"region_id"`
+	Hostname        string       `db:"hostname" json:"hostname"`
+	Version         string       `db:"version" json:"version"`
+	Error           string       `db:"error" json:"error"`
+	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
+}
+
+func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
+	row := q.db.QueryRowContext(ctx, updateReplica,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.StartedAt,
+		arg.StoppedAt,
+		arg.RelayAddress,
+		arg.RegionID,
+		arg.Hostname,
+		arg.Version,
+		arg.Error,
+		arg.DatabaseLatency,
+	)
+	var i Replica
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.StoppedAt,
+		&i.UpdatedAt,
+		&i.Hostname,
+		&i.RegionID,
+		&i.RelayAddress,
+		&i.DatabaseLatency,
+		&i.Version,
+		&i.Error,
+	)
+	return i, err
+}
+
+const insertServiceAccount = `-- name: InsertServiceAccount :one
+INSERT INTO
+	service_accounts (
+		user_id,
+		organization_id,
+		created_by,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4)
+RETURNING user_id, organization_id, created_by, created_at
+`
+
+type InsertServiceAccountParams struct {
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedBy      uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertServiceAccount(ctx context.Context, arg InsertServiceAccountParams) (ServiceAccount, error) {
+	row := q.db.QueryRowContext(ctx, insertServiceAccount,
+		arg.UserID,
+		arg.OrganizationID,
+		arg.CreatedBy,
+		arg.CreatedAt,
+	)
+	var i ServiceAccount
+	err := row.Scan(
+		&i.UserID,
+		&i.OrganizationID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDERPMeshKey = `-- name: GetDERPMeshKey :one
+SELECT value FROM site_configs WHERE key = 'derp_mesh_key'
+`
+
+func (q *sqlQuerier) GetDERPMeshKey(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getDERPMeshKey)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getDefaultProxyConfig = `-- name: GetDefaultProxyConfig :one
+SELECT
+	COALESCE((SELECT value FROM site_configs WHERE key = 'default_proxy_display_name'), 'Default') :: text AS display_name,
+	COALESCE((SELECT value FROM site_configs WHERE key = 'default_proxy_icon_url'), '/emojis/1f3e1.png') :: text AS icon_url
+`
+
+type GetDefaultProxyConfigRow struct {
+	DisplayName string `db:"display_name" json:"display_name"`
+	IconUrl     string `db:"icon_url" json:"icon_url"`
+}
+
+func (q *sqlQuerier) GetDefaultProxyConfig(ctx context.Context) (GetDefaultProxyConfigRow, error) {
+	row := q.db.QueryRowContext(ctx, getDefaultProxyConfig)
+	var i GetDefaultProxyConfigRow
+	err := row.Scan(&i.DisplayName, &i.IconUrl)
+	return i, err
+}
+
+const getDeploymentID = `-- name: GetDeploymentID :one
+SELECT value FROM site_configs WHERE key = 'deployment_id'
+`
+
+func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getDeploymentID)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getLastUpdateCheck = `-- name: GetLastUpdateCheck :one
+SELECT value FROM site_configs WHERE key = 'last_update_check'
+`
+
+func (q *sqlQuerier) GetLastUpdateCheck(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getLastUpdateCheck)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getLogoURL = `-- name: GetLogoURL :one
+SELECT value FROM site_configs WHERE key = 'logo_url'
+`
+
+func (q *sqlQuerier) GetLogoURL(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getLogoURL)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getServiceBanner = `-- name: GetServiceBanner :one
+SELECT value FROM site_configs WHERE key = 'service_banner'
+`
+
+func (q *sqlQuerier) GetServiceBanner(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getServiceBanner)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const insertDERPMeshKey = `-- name: InsertDERPMeshKey :exec
+INSERT INTO site_configs (key, value) VALUES ('derp_mesh_key', $1)
+`
+
+func (q *sqlQuerier) InsertDERPMeshKey(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertDERPMeshKey, value)
+	return err
+}
+
+const insertDeploymentID = `-- name: InsertDeploymentID :exec
+INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+`
+
+func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
+	return err
+}
+
+const upsertDefaultProxy = `-- name: UpsertDefaultProxy :exec
+INSERT INTO site_configs (key, value)
+VALUES
+    ('default_proxy_display_name', $1 :: text),
+    ('default_proxy_icon_url', $2 :: text)
+ON CONFLICT
+    (key)
+DO UPDATE SET value = EXCLUDED.value WHERE site_configs.key = EXCLUDED.key
+`
+
+type UpsertDefaultProxyParams struct {
+	DisplayName string `db:"display_name" json:"display_name"`
+	IconUrl     string `db:"icon_url" json:"icon_url"`
+}
+
+// The default proxy is implied and not actually stored in the database.
+// So we need to store it's configuration here for display purposes.
+// The functional values are immutable and controlled implicitly.
+func (q *sqlQuerier) UpsertDefaultProxy(ctx context.Context, arg UpsertDefaultProxyParams) error {
+	_, err := q.db.ExecContext(ctx, upsertDefaultProxy, arg.DisplayName, arg.IconUrl)
+	return err
+}
+
+const upsertLastUpdateCheck = `-- name: UpsertLastUpdateCheck :exec
+INSERT INTO site_configs (key, value) VALUES ('last_update_check', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'last_update_check'
+`
+
+func (q *sqlQuerier) UpsertLastUpdateCheck(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, upsertLastUpdateCheck, value)
+	return err
+}
+
+const upsertLogoURL = `-- name: UpsertLogoURL :exec
+INSERT INTO site_configs (key, value) VALUES ('logo_url', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'logo_url'
+`
+
+func (q *sqlQuerier) UpsertLogoURL(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, upsertLogoURL, value)
+	return err
+}
+
+const upsertServiceBanner = `-- name: UpsertServiceBanner :exec
+INSERT INTO site_configs (key, value) VALUES ('service_banner', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'service_banner'
+`
+
+func (q *sqlQuerier) UpsertServiceBanner(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, upsertServiceBanner, value)
+	return err
+}
+
+const deleteTemplateGitSourceByTemplateID = `-- name: DeleteTemplateGitSourceByTemplateID :exec
+DELETE FROM
+	template_git_sources
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) DeleteTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateGitSourceByTemplateID, templateID)
+	return err
+}
+
+const cleanTailnetLostPeers = `-- name: CleanTailnetLostPeers :exec
+DELETE FROM tailnet_peers WHERE updated_at < $1 AND status = 'lost'::tailnet_status
+`
+
+func (q *sqlQuerier) CleanTailnetLostPeers(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanTailnetLostPeers, updatedAt)
+	return err
+}
+
+const cleanTailnetLostTunnels = `-- name: CleanTailnetLostTunnels :exec
+DELETE FROM tailnet_tunnels
+WHERE updated_at < $1 AND NOT EXISTS (
+	SELECT 1 FROM tailnet_peers
+	WHERE tailnet_peers.id = tailnet_tunnels.src_id AND tailnet_peers.coordinator_id = tailnet_tunnels.coordinator_id
+)
+`
+
+// Deletes the tunnels of peers that have been removed, for example because
+// they were lost.
+func (q *sqlQuerier) CleanTailnetLostTunnels(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanTailnetLostTunnels, updatedAt)
+	return err
+}
+
+const deleteAllTailnetTunnels = `-- name: DeleteAllTailnetTunnels :exec
+DELETE
+FROM tailnet_tunnels
+WHERE coordinator_id = $1 AND src_id = $2
+`
+
+type DeleteAllTailnetTunnelsParams struct {
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	SrcID         uuid.UUID `db:"src_id" json:"src_id"`
+}
+
+func (q *sqlQuerier) DeleteAllTailnetTunnels(ctx context.Context, arg DeleteAllTailnetTunnelsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAllTailnetTunnels, arg.CoordinatorID, arg.SrcID)
+	return err
+}
+
+const deleteExpiredTailnetCoordinators = `-- name: DeleteExpiredTailnetCoordinators :exec
+DELETE FROM tailnet_coordinators WHERE heartbeat_at < $1
+`
+
+// Deleting a coordinator also deletes its peers and tunnels.
+func (q *sqlQuerier) DeleteExpiredTailnetCoordinators(ctx context.Context, heartbeatAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredTailnetCoordinators, heartbeatAt)
+	return err
+}
+
+const deleteTailnetCoordinator = `-- name: DeleteTailnetCoordinator :exec
+DELETE FROM tailnet_coordinators WHERE id = $1
+`
+
+func (q *sqlQuerier) DeleteTailnetCoordinator(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTailnetCoordinator, id)
+	return err
+}
+
+const deleteTailnetPeer = `-- name: DeleteTailnetPeer :one
+DELETE FROM tailnet_peers WHERE id = $1 AND coordinator_id = $2 RETURNING id, coordinator_id
+`
+
+type DeleteTailnetPeerParams struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+}
+
+type DeleteTailnetPeerRow struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+}
+
+func (q *sqlQuerier) DeleteTailnetPeer(ctx context.Context, arg DeleteTailnetPeerParams) (DeleteTailnetPeerRow, error) {
+	row := q.db.QueryRowContext(ctx, deleteTailnetPeer, arg.ID, arg.CoordinatorID)
+	var i DeleteTailnetPeerRow
+	err := row.Scan(&i.ID, &i.CoordinatorID)
+	return i, err
+}
+
+const deleteTailnetTunnel = `-- name: DeleteTailnetTunnel :one
+DELETE
+FROM tailnet_tunnels
+WHERE coordinator_id = $1 AND src_id = $2 AND dst_id = $3
+RETURNING coordinator_id, src_id, dst_id
+`
+
+type DeleteTailnetTunnelParams struct {
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	SrcID         uuid.UUID `db:"src_id" json:"src_id"`
+	DstID         uuid.UUID `db:"dst_id" json:"dst_id"`
+}
+
+type DeleteTailnetTunnelRow struct {
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	SrcID         uuid.UUID `db:"src_id" json:"src_id"`
+	DstID         uuid.UUID `db:"dst_id" json:"dst_id"`
+}
+
+func (q *sqlQuerier) DeleteTailnetTunnel(ctx context.Context, arg DeleteTailnetTunnelParams) (DeleteTailnetTunnelRow, error) {
+	row := q.db.QueryRowContext(ctx, deleteTailnetTunnel, arg.CoordinatorID, arg.SrcID, arg.DstID)
+	var i DeleteTailnetTunnelRow
+	err := row.Scan(&i.CoordinatorID, &i.SrcID, &i.DstID)
+	return i, err
+}
+
+const getTailnetCoordinators = `-- name: GetTailnetCoordinators :many
+SELECT id, heartbeat_at FROM tailnet_coordinators
+`
+
+func (q *sqlQuerier) GetTailnetCoordinators(ctx context.Context) ([]TailnetCoordinator, error) {
+	rows, err := q.db.QueryContext(ctx, getTailnetCoordinators)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TailnetCoordinator
+	for rows.Next() {
+		var i TailnetCoordinator
+		if err := rows.Scan(&i.ID, &i.HeartbeatAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTailnetPeers = `-- name: GetTailnetPeers :many
+SELECT id, coordinator_id, updated_at, node, status FROM tailnet_peers WHERE id = $1
+`
+
+func (q *sqlQuerier) GetTailnetPeers(ctx context.Context, id uuid.UUID) ([]TailnetPeer, error) {
+	rows, err := q.db.QueryContext(ctx, getTailnetPeers, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TailnetPeer
+	for rows.Next() {
+		var i TailnetPeer
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoordinatorID,
+			&i.UpdatedAt,
+			&i.Node,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTailnetTunnelPeerBindings = `-- name: GetTailnetTunnelPeerBindings :many
+SELECT tailnet_peers.id AS peer_id, coordinator_id, updated_at, node, status
+FROM tailnet_peers
+WHERE id IN (
+	SELECT dst_id FROM tailnet_tunnels WHERE tailnet_tunnels.src_id = $1
+	UNION
+	SELECT src_id FROM tailnet_tunnels WHERE tailnet_tunnels.dst_id = $1
+)
+`
+
+type GetTailnetTunnelPeerBindingsRow struct {
+	PeerID        uuid.UUID     `db:"peer_id" json:"peer_id"`
+	CoordinatorID uuid.UUID     `db:"coordinator_id" json:"coordinator_id"`
+	UpdatedAt     time.Time     `db:"updated_at" json:"updated_at"`
+	Node          []byte        `db:"node" json:"node"`
+	Status        TailnetStatus `db:"status" json:"status"`
+}
+
+// Returns the mappings of all peers that have a tunnel to or from the given
+// peer.
+func (q *sqlQuerier) GetTailnetTunnelPeerBindings(ctx context.Context, srcID uuid.UUID) ([]GetTailnetTunnelPeerBindingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTailnetTunnelPeerBindings, srcID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTailnetTunnelPeerBindingsRow
+	for rows.Next() {
+		var i GetTailnetTunnelPeerBindingsRow
+		if err := rows.Scan(
+			&i.PeerID,
+			&i.CoordinatorID,
+			&i.UpdatedAt,
+			&i.Node,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTailnetTunnelPeerIDs = `-- name: GetTailnetTunnelPeerIDs :many
+SELECT dst_id as peer_id, coordinator_id, updated_at
+FROM tailnet_tunnels
+WHERE tailnet_tunnels.src_id = $1
+UNION
+SELECT src_id as peer_id, coordinator_id, updated_at
+FROM tailnet_tunnels
+WHERE tailnet_tunnels.dst_id = $1
+`
+
+type GetTailnetTunnelPeerIDsRow struct {
+	PeerID        uuid.UUID `db:"peer_id" json:"peer_id"`
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Returns the peers that have a tunnel to or from the given peer, and the
+// coordinators that hold the tunnels.
+func (q *sqlQuerier) GetTailnetTunnelPeerIDs(ctx context.Context, srcID uuid.UUID) ([]GetTailnetTunnelPeerIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTailnetTunnelPeerIDs, srcID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTailnetTunnelPeerIDsRow
+	for rows.Next() {
+		var i GetTailnetTunnelPeerIDsRow
+		if err := rows.Scan(&i.PeerID, &i.CoordinatorID, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTailnetPeerStatusByCoordinator = `-- name: UpdateTailnetPeerStatusByCoordinator :exec
+UPDATE
+	tailnet_peers
+SET
+	status = $2
+WHERE
+	coordinator_id = $1
+`
+
+type UpdateTailnetPeerStatusByCoordinatorParams struct {
+	CoordinatorID uuid.UUID     `db:"coordinator_id" json:"coordinator_id"`
+	Status        TailnetStatus `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) UpdateTailnetPeerStatusByCoordinator(ctx context.Context, arg UpdateTailnetPeerStatusByCoordinatorParams) error {
+	_, err := q.db.ExecContext(ctx, updateTailnetPeerStatusByCoordinator, arg.CoordinatorID, arg.Status)
+	return err
+}
+
+const upsertTailnetCoordinator = `-- name: UpsertTailnetCoordinator :one
+INSERT INTO
+	tailnet_coordinators (
+		id,
+		heartbeat_at
+	)
+VALUES
+	($1, now() at time zone 'utc')
+ON CONFLICT (id)
+DO UPDATE SET
+	id = $1,
+	heartbeat_at = now() at time zone 'utc'
+RETURNING id, heartbeat_at
+`
+
+func (q *sqlQuerier) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (TailnetCoordinator, error) {
+	row := q.db.QueryRowContext(ctx, upsertTailnetCoordinator, id)
+	var i TailnetCoordinator
+	err := row.Scan(&i.ID, &i.HeartbeatAt)
+	return i, err
+}
+
+const upsertTailnetPeer = `-- name: UpsertTailnetPeer :one
+INSERT INTO
+	tailnet_peers (
+		id,
+		coordinator_id,
+		node,
+		status,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, now() at time zone 'utc')
+ON CONFLICT (id, coordinator_id)
+DO UPDATE SET
+	id = $1,
+	coordinator_id = $2,
+	node = $3,
+	status = $4,
+	updated_at = now() at time zone 'utc'
+RETURNING id, coordinator_id, updated_at, node, status
+`
+
+type UpsertTailnetPeerParams struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	CoordinatorID uuid.UUID     `db:"coordinator_id" json:"coordinator_id"`
+	Node          []byte        `db:"node" json:"node"`
+	Status        TailnetStatus `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) UpsertTailnetPeer(ctx context.Context, arg UpsertTailnetPeerParams) (TailnetPeer, error) {
+	row := q.db.QueryRowContext(ctx, upsertTailnetPeer,
+		arg.ID,
+		arg.CoordinatorID,
+		arg.Node,
+		arg.Status,
+	)
+	var i TailnetPeer
+	err := row.Scan(
+		&i.ID,
+		&i.CoordinatorID,
+		&i.UpdatedAt,
+		&i.Node,
+		&i.Status,
+	)
+	return i, err
+}
+
+const upsertTailnetTunnel = `-- name: UpsertTailnetTunnel :one
+INSERT INTO
+	tailnet_tunnels (
+		coordinator_id,
+		src_id,
+		dst_id,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, now() at time zone 'utc')
+ON CONFLICT (coordinator_id, src_id, dst_id)
+DO UPDATE SET
+	coordinator_id = $1,
+	src_id = $2,
+	dst_id = $3,
+	updated_at = now() at time zone 'utc'
+RETURNING coordinator_id, src_id, dst_id, updated_at
+`
+
+type UpsertTailnetTunnelParams struct {
+	CoordinatorID uuid.UUID `db:"coordinator_id" json:"coordinator_id"`
+	SrcID         uuid.UUID `db:"src_id" json:"src_id"`
+	DstID         uuid.UUID `db:"dst_id" json:"dst_id"`
+}
+
+func (q *sqlQuerier) UpsertTailnetTunnel(ctx context.Context, arg UpsertTailnetTunnelParams) (TailnetTunnel, error) {
+	row := q.db.QueryRowContext(ctx, upsertTailnetTunnel, arg.CoordinatorID, arg.SrcID, arg.DstID)
+	var i TailnetTunnel
+	err := row.Scan(
+		&i.CoordinatorID,
+		&i.SrcID,
+		&i.DstID,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTemplateGitSourceByTemplateID = `-- name: GetTemplateGitSourceByTemplateID :one
+SELECT
+	template_id, created_at, updated_at, created_by, repository_url, ref, subdirectory, git_auth_provider_id, poll_interval, webhook_secret, last_commit_sha, last_synced_at, last_error
+FROM
+	template_git_sources
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateGitSourceByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateGitSourceByTemplateID, templateID)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.RepositoryURL,
+		&i.Ref,
+		&i.Subdirectory,
+		&i.GitAuthProviderID,
+		&i.PollInterval,
+		&i.WebhookSecret,
+		&i.LastCommitSHA,
+		&i.LastSyncedAt,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const getTemplateGitSources = `-- name: GetTemplateGitSources :many
+SELECT
+	template_git_sources.template_id, template_git_sources.created_at, template_git_sources.updated_at, template_git_sources.created_by, template_git_sources.repository_url, template_git_sources.ref, template_git_sources.subdirectory, template_git_sources.git_auth_provider_id, template_git_sources.poll_interval, template_git_sources.webhook_secret, template_git_sources.last_commit_sha, template_git_sources.last_synced_at, template_git_sources.last_error
+FROM
+	template_git_sources
+JOIN
+	templates ON templates.id = template_git_sources.template_id
+WHERE
+	templates.deleted = false
+`
+
+// Returns the git sources of all templates that have not been deleted.
+func (q *sqlQuerier) GetTemplateGitSources(ctx context.Context) ([]TemplateGitSource, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateGitSources)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateGitSource
+	for rows.Next() {
+		var i TemplateGitSource
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.RepositoryURL,
+			&i.Ref,
+			&i.Subdirectory,
+			&i.GitAuthProviderID,
+			&i.PollInterval,
+			&i.WebhookSecret,
+			&i.LastCommitSHA,
+			&i.LastSyncedAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTemplateGitSourceSyncStatus = `-- name: UpdateTemplateGitSourceSyncStatus :exec
+UPDATE
+	template_git_sources
+SET
+	last_commit_sha = $2,
+	last_synced_at = $3,
+	last_error = $4
+WHERE
+	template_id = $1
+`
+
+type UpdateTemplateGitSourceSyncStatusParams struct {
+	TemplateID    uuid.UUID    `db:"template_id" json:"template_id"`
+	LastCommitSHA string       `db:"last_commit_sha" json:"last_commit_sha"`
+	LastSyncedAt  sql.NullTime `db:"last_synced_at" json:"last_synced_at"`
+	LastError     string       `db:"last_error" json:"last_error"`
+}
+
+func (q *sqlQuerier) UpdateTemplateGitSourceSyncStatus(ctx context.Context, arg UpdateTemplateGitSourceSyncStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateGitSourceSyncStatus,
+		arg.TemplateID,
+		arg.LastCommitSHA,
+		arg.LastSyncedAt,
+		arg.LastError,
+	)
+	return err
+}
+
+const upsertTemplateGitSource = `-- name: UpsertTemplateGitSource :one
+INSERT INTO
+	template_git_sources (
+		template_id,
+		created_at,
+		updated_at,
+		created_by,
+		repository_url,
+		ref,
+		subdirectory,
+		git_auth_provider_id,
+		poll_interval,
+		webhook_secret
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (template_id) DO UPDATE SET
+	updated_at = $3,
+	created_by = $4,
+	repository_url = $5,
+	ref = $6,
+	subdirectory = $7,
+	git_auth_provider_id = $8,
+	poll_interval = $9,
+	webhook_secret = $10,
+	-- Force the next sync to create a version when the source changes.
+	last_commit_sha = ''
+RETURNING template_id, created_at, updated_at, created_by, repository_url, ref, subdirectory, git_auth_provider_id, poll_interval, webhook_secret, last_commit_sha, last_synced_at, last_error
+`
+
+type UpsertTemplateGitSourceParams struct {
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	CreatedBy         uuid.UUID `db:"created_by" json:"created_by"`
+	RepositoryURL     string    `db:"repository_url" json:"repository_url"`
+	Ref               string    `db:"ref" json:"ref"`
+	Subdirectory      string    `db:"subdirectory" json:"subdirectory"`
+	GitAuthProviderID string    `db:"git_auth_provider_id" json:"git_auth_provider_id"`
+	PollInterval      int64     `db:"poll_interval" json:"poll_interval"`
+	WebhookSecret     string    `db:"webhook_secret" json:"webhook_secret"`
+}
+
+func (q *sqlQuerier) UpsertTemplateGitSource(ctx context.Context, arg UpsertTemplateGitSourceParams) (TemplateGitSource, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateGitSource,
+		arg.TemplateID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.CreatedBy,
+		arg.RepositoryURL,
+		arg.Ref,
+		arg.Subdirectory,
+		arg.GitAuthProviderID,
+		arg.PollInterval,
+		arg.WebhookSecret,
+	)
+	var i TemplateGitSource
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.RepositoryURL,
+		&i.Ref,
+		&i.Subdirectory,
+		&i.GitAuthProviderID,
+		&i.PollInterval,
+		&i.WebhookSecret,
+		&i.LastCommitSHA,
+		&i.LastSyncedAt,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const getTemplateAverageBuildTime = `-- name: GetTemplateAverageBuildTime :one
+WITH build_times AS (
+SELECT
+	EXTRACT(EPOCH FROM (pj.completed_at - pj.started_at))::FLOAT AS exec_time_sec,
+	workspace_builds.transition
+FROM
+	workspace_builds
+JOIN template_versions ON
+	workspace_builds.template_version_id = template_versions.id
+JOIN provisioner_jobs pj ON
+	workspace_builds.job_id = pj.id
+WHERE
+	template_versions.template_id = $1 AND
+		(pj.completed_at IS NOT NULL) AND (pj.started_at IS NOT NULL) AND
+		(pj.started_at > $2) AND
+		(pj.canceled_at IS NULL) AND
+		((pj.error IS NULL) OR (pj.error = ''))
+ORDER BY
+	workspace_builds.created_at DESC
+)
+SELECT
+	-- Postgres offers no clear way to DRY this short of a function or other
+	-- complexities.
+	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_50,
+	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_50,
+	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_50,
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_95,
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_95,
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95
+FROM build_times
+`
+
+type GetTemplateAverageBuildTimeParams struct {
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	StartTime  sql.NullTime  `db:"start_time" json:"start_time"`
+}
+
+type GetTemplateAverageBuildTimeRow struct {
+	Start50  float64 `db:"start_50" json:"start_50"`
+	Stop50   float64 `db:"stop_50" json:"stop_50"`
+	Delete50 float64 `db:"delete_50" json:"delete_50"`
+	Start95  float64 `db:"start_95" json:"start_95"`
+	Stop95   float64 `db:"stop_95" json:"stop_95"`
+	Delete95 float64 `db:"delete_95" json:"delete_95"`
+}
+
+func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateAverageBuildTime, arg.TemplateID, arg.StartTime)
+	var i GetTemplateAverageBuildTimeRow
+	err := row.Scan(
+		&i.Start50,
+		&i.Stop50,
+		&i.Delete50,
+		&i.Start95,
+		&i.Stop95,
+		&i.Delete95,
+	)
+	return i, err
+}
+
+const getTemplateByID = `-- name: GetTemplateByID :one
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+FROM
+	templates
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.RequireActiveVersion,
+		&i.MaxPortShareLevel,
+	)
+	return i, err
+}
+
+const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+FROM
+	templates
+WHERE
+	organization_id = $1
+	AND deleted = $2
+	AND LOWER("name") = LOWER($3)
+LIMIT
+	1
+`
+
+type GetTemplateByOrganizationAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Deleted        bool      `db:"deleted" json:"deleted"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.RequireActiveVersion,
+		&i.MaxPortShareLevel,
+	)
+	return i, err
+}
+
+const getTemplates = `-- name: GetTemplates :many
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level FROM templates
+ORDER BY (name, id) ASC
+`
+
+func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Template
+	for rows.Next() {
+		var i Template
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.Deleted,
+			&i.Name,
+			&i.Provisioner,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.DefaultTTL,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.DisplayName,
+			&i.AllowUserCancelWorkspaceJobs,
+			&i.MaxTTL,
+			&i.AllowUserAutostart,
+			&i.AllowUserAutostop,
+			&i.FailureTTL,
+			&i.InactivityTTL,
+			&i.RequireActiveVersion,
+			&i.MaxPortShareLevel,
+			&i.MaxPortShareLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+FROM
+	templates
+WHERE
+	-- Optionally include deleted templates
+	templates.deleted = $1
+	-- Filter by organization_id
+	AND CASE
+		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			organization_id = $2
+		ELSE true
+	END
+	-- Filter by exact name
+	AND CASE
+		WHEN $3 :: text != '' THEN
+			LOWER("name") = LOWER($3)
+		ELSE true
+	END
+	-- Filter by ids
+	AND CASE
+		WHEN array_length($4 :: uuid[], 1) > 0 THEN
+			id = ANY($4)
+		ELSE true
+	END
+  -- Authorize Filter clause will be injected below in GetAuthorizedTemplates
+  -- @authorize_filter
+ORDER BY (name, id) ASC
+`
+
+type GetTemplatesWithFilterParams struct {
+	Deleted        bool        `db:"deleted" json:"deleted"`
+	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
+	ExactName      string      `db:"exact_name" json:"exact_name"`
+	IDs            []uuid.UUID `db:"ids" json:"ids"`
+}
+
+func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplatesWithFilter,
+		arg.Deleted,
+		arg.OrganizationID,
+		arg.ExactName,
+		pq.Array(arg.IDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Template
+	for rows.Next() {
+		var i Template
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.Deleted,
+			&i.Name,
+			&i.Provisioner,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.DefaultTTL,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.DisplayName,
+			&i.AllowUserCancelWorkspaceJobs,
+			&i.MaxTTL,
+			&i.AllowUserAutostart,
+			&i.AllowUserAutostop,
+			&i.FailureTTL,
+			&i.InactivityTTL,
+			&i.RequireActiveVersion,
+			&i.MaxPortShareLevel,
+			&i.MaxPortShareLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplate = `-- name: InsertTemplate :one
+INSERT INTO
+	templates (
+		id,
+		created_at,
+		updated_at,
+		organization_id,
+		"name",
+		provisioner,
+		active_version_id,
+		description,
+		created_by,
+		icon,
+		user_acl,
+		group_acl,
+		display_name,
+		allow_user_cancel_workspace_jobs
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+`
+
+type InsertTemplateParams struct {
+	ID                           uuid.UUID       `db:"id" json:"id"`
+	CreatedAt                    time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time       `db:"updated_at" json:"updated_at"`
+	OrganizationID               uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Name                         string          `db:"name" json:"name"`
+	Provisioner                  ProvisionerType `db:"provisioner" json:"provisioner"`
+	ActiveVersionID              uuid.UUID       `db:"active_version_id" json:"active_version_id"`
+	Description                  string          `db:"description" json:"description"`
+	CreatedBy                    uuid.UUID       `db:"created_by" json:"created_by"`
+	Icon                         string          `db:"icon" json:"icon"`
+	UserACL                      TemplateACL     `db:"user_acl" json:"user_acl"`
+	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
+	DisplayName                  string          `db:"display_name" json:"display_name"`
+	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+}
+
+func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplate,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.OrganizationID,
+		arg.Name,
+		arg.Provisioner,
+		arg.ActiveVersionID,
+		arg.Description,
+		arg.CreatedBy,
+		arg.Icon,
+		arg.UserACL,
+		arg.GroupACL,
+		arg.DisplayName,
+		arg.AllowUserCancelWorkspaceJobs,
+	)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.RequireActiveVersion,
+		&i.MaxPortShareLevel,
+	)
+	return i, err
+}
+
+const updateTemplateACLByID = `-- name: UpdateTemplateACLByID :one
+UPDATE
+	templates
+SET
+	group_acl = $1,
+	user_acl = $2
+WHERE
+	id = $3
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+`
+
+type UpdateTemplateACLByIDParams struct {
+	GroupACL TemplateACL `db:"group_acl" json:"group_acl"`
+	UserACL  TemplateACL `db:"user_acl" json:"user_acl"`
+	ID       uuid.UUID   `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateACLByID, arg.GroupACL, arg.UserACL, arg.ID)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.RequireActiveVersion,
+		&i.MaxPortShareLevel,
+	)
+	return i, err
+}
+
+const updateTemplateActiveVersionByID = `-- name: UpdateTemplateActiveVersionByID :exec
+UPDATE
+	templates
+SET
+	active_version_id = $2,
+	updated_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateTemplateActiveVersionByIDParams struct {
+	ID              uuid.UUID `db:"id" json:"id"`
+	ActiveVersionID uuid.UUID `db:"active_version_id" json:"active_version_id"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateActiveVersionByID, arg.ID, arg.ActiveVersionID, arg.UpdatedAt)
+	return err
+}
+
+const updateTemplateDeletedByID = `-- name: UpdateTemplateDeletedByID :exec
+UPDATE
+	templates
+SET
+	deleted = $2,
+	updated_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateTemplateDeletedByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Deleted   bool      `db:"deleted" json:"deleted"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateDeletedByID, arg.ID, arg.Deleted, arg.UpdatedAt)
+	return err
+}
+
+const updateTemplateMetaByID = `-- name: UpdateTemplateMetaByID :one
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	description = $3,
+	name = $4,
+	icon = $5,
+	display_name = $6,
+	allow_user_cancel_workspace_jobs = $7,
+	require_active_version = $8,
+	max_port_share_level = $9
+WHERE
+	id = $1
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+`
+
+type UpdateTemplateMetaByIDParams struct {
+	ID                           uuid.UUID      `db:"id" json:"id"`
+	UpdatedAt                    time.Time      `db:"updated_at" json:"updated_at"`
+	Description                  string         `db:"description" json:"description"`
+	Name                         string         `db:"name" json:"name"`
+	Icon                         string         `db:"icon" json:"icon"`
+	DisplayName                  string         `db:"display_name" json:"display_name"`
+	AllowUserCancelWorkspaceJobs bool           `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+	RequireActiveVersion         bool           `db:"require_active_version" json:"require_active_version"`
+	MaxPortShareLevel            PortShareLevel `db:"max_port_share_level" json:"max_port_share_level"`
+}
+
+func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateMetaByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Description,
+		arg.Name,
+		arg.Icon,
+		arg.DisplayName,
+		arg.AllowUserCancelWorkspaceJobs,
+		arg.RequireActiveVersion,
+		arg.MaxPortShareLevel,
+	)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.RequireActiveVersion,
+		&i.MaxPortShareLevel,
+	)
+	return i, err
+}
+
+const updateTemplateScheduleByID = `-- name: UpdateTemplateScheduleByID :one
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	allow_user_autostart = $3,
+	allow_user_autostop = $4,
+	default_ttl = $5,
+	max_ttl = $6,
+	failure_ttl = $7,
+	inactivity_ttl = $8
+WHERE
+	id = $1
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, require_active_version, max_port_share_level
+`
+
+type UpdateTemplateScheduleByIDParams struct {
+	ID                 uuid.UUID `db:"id" json:"id"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+	AllowUserAutostart bool      `db:"allow_user_autostart" json:"allow_user_autostart"`
+	AllowUserAutostop  bool      `db:"allow_user_autostop" json:"allow_user_autostop"`
+	DefaultTTL         int64     `db:"default_ttl" json:"default_ttl"`
+	MaxTTL             int64     `db:"max_ttl" json:"max_ttl"`
+	FailureTTL         int64     `db:"failure_ttl" json:"failure_ttl"`
+	InactivityTTL      int64     `db:"inactivity_ttl" json:"inactivity_ttl"`
+}
+
+func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateTemplateScheduleByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateScheduleByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.AllowUserAutostart,
+		arg.AllowUserAutostop,
+		arg.DefaultTTL,
+		arg.MaxTTL,
+		arg.FailureTTL,
+		arg.InactivityTTL,
+	)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.RequireActiveVersion,
+		&i.MaxPortShareLevel,
+	)
+	return i, err
+}
+
+const getTemplateVersionParameters = `-- name: GetTemplateVersionParameters :many
+SELECT template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic, required, legacy_variable_name, display_name FROM template_version_parameters WHERE template_version_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionParameter, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionParameters, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionParameter
+	for rows.Next() {
+		var i TemplateVersionParameter
+		if err := rows.Scan(
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Description,
+			&i.Type,
+			&i.Mutable,
+			&i.DefaultValue,
+			&i.Icon,
+			&i.Options,
+			&i.ValidationRegex,
+			&i.ValidationMin,
+			&i.ValidationMax,
+			&i.ValidationError,
+			&i.ValidationMonotonic,
+			&i.Required,
+			&i.LegacyVariableName,
+			&i.DisplayName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersionParameter = `-- name: InsertTemplateVersionParameter :one
+INSERT INTO
+    template_version_parameters (
+        template_version_id,
+        name,
+        description,
+        type,
+        mutable,
+        default_value,
+        icon,
+        options,
+        validation_regex,
+        validation_min,
+        validation_max,
+        validation_error,
+        validation_monotonic,
+        required,
+        legacy_variable_name,
+        display_name
+    )
+VALUES
+    (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11,
+        $12,
+        $13,
+        $14,
+        $15,
+		$16
+    ) RETURNING template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic, required, legacy_variable_name, display_name
+`
+
+type InsertTemplateVersionParameterParams struct {
+	TemplateVersionID   uuid.UUID       `db:"template_version_id" json:"template_version_id"`
+	Name                string          `db:"name" json:"name"`
+	Description         string          `db:"description" json:"description"`
+	Type                string          `db:"type" json:"type"`
+	Mutable             bool            `db:"mutable" json:"mutable"`
+	DefaultValue        string          `db:"default_value" json:"default_value"`
+	Icon                string          `db:"icon" json:"icon"`
+	Options             json.RawMessage `db:"options" json:"options"`
+	ValidationRegex     string          `db:"validation_regex" json:"validation_regex"`
+	ValidationMin       sql.NullInt32   `db:"validation_min" json:"validation_min"`
+	ValidationMax       sql.NullInt32   `db:"validation_max" json:"validation_max"`
+	ValidationError     string          `db:"validation_error" json:"validation_error"`
+	ValidationMonotonic string          `db:"validation_monotonic" json:"validation_monotonic"`
+	Required            bool            `db:"required" json:"required"`
+	LegacyVariableName  string          `db:"legacy_variable_name" json:"legacy_variable_name"`
+	DisplayName         string          `db:"display_name" json:"display_name"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersionParameter(ctx context.Context, arg InsertTemplateVersionParameterParams) (TemplateVersionParameter, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionParameter,
+		arg.TemplateVersionID,
+		arg.Name,
+		arg.Description,
+		arg.Type,
+		arg.Mutable,
+		arg.DefaultValue,
+		arg.Icon,
+		arg.Options,
+		arg.ValidationRegex,
+		arg.ValidationMin,
+		arg.ValidationMax,
+		arg.ValidationError,
+		arg.ValidationMonotonic,
+		arg.Required,
+		arg.LegacyVariableName,
+		arg.DisplayName,
+	)
+	var i TemplateVersionParameter
+	err := row.Scan(
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Description,
+		&i.Type,
+		&i.Mutable,
+		&i.DefaultValue,
+		&i.Icon,
+		&i.Options,
+		&i.ValidationRegex,
+		&i.ValidationMin,
+		&i.ValidationMax,
+		&i.ValidationError,
+		&i.ValidationMonotonic,
+		&i.Required,
+		&i.LegacyVariableName,
+		&i.DisplayName,
 	)
 	return i, err
 }
 
-const getAppSecurityKey = `-- name: GetAppSecurityKey :one
-SELECT value FROM site_configs WHERE key = 'app_signing_key'
-`
-
-func (q *sqlQuerier) GetAppSecurityKey(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getAppSecurityKey)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getDERPMeshKey = `-- name: GetDERPMeshKey :one
-SELECT value FROM site_configs WHERE key = 'derp_mesh_key'
+const deleteTemplateVersionPresetsByTemplateVersionID = `-- name: DeleteTemplateVersionPresetsByTemplateVersionID :exec
+DELETE FROM
+	template_version_presets
+WHERE
+	template_version_id = $1
 `
 
-func (q *sqlQuerier) GetDERPMeshKey(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getDERPMeshKey)
-	var value string
-	err := row.Scan(&value)
-	return value, err
+func (q *sqlQuerier) DeleteTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateVersionPresetsByTemplateVersionID, templateVersionID)
+	return err
 }
 
-const getDefaultProxyConfig = `-- name: GetDefaultProxyConfig :one
+const getTemplateVersionPresetByID = `-- name: GetTemplateVersionPresetByID :one
 SELECT
-	COALESCE((SELECT value FROM site_configs WHERE key = 'default_proxy_display_name'), 'Default') :: text AS display_name,
-	COALESCE((SELECT value FROM site_configs WHERE key = 'default_proxy_icon_url'), '/emojis/1f3e1.png') :: text AS icon_url
+	id, template_version_id, name, description, created_at
+FROM
+	template_version_presets
+WHERE
+	id = $1
 `
 
-type GetDefaultProxyConfigRow struct {
-	DisplayName string `db:"display_name" json:"display_name"`
-	IconUrl     string `db:"icon_url" json:"icon_url"`
-}
-
-func (q *sqlQuerier) GetDefaultProxyConfig(ctx context.Context) (GetDefaultProxyConfigRow, error) {
-	row := q.db.QueryRowContext(ctx, getDefaultProxyConfig)
-	var i GetDefaultProxyConfigRow
-	err := row.Scan(&i.DisplayName, &i.IconUrl)
+func (q *sqlQuerier) GetTemplateVersionPresetByID(ctx context.Context, id uuid.UUID) (TemplateVersionPreset, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionPresetByID, id)
+	var i TemplateVersionPreset
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
-const getDeploymentID = `-- name: GetDeploymentID :one
-SELECT value FROM site_configs WHERE key = 'deployment_id'
-`
-
-func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getDeploymentID)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getLastUpdateCheck = `-- name: GetLastUpdateCheck :one
-SELECT value FROM site_configs WHERE key = 'last_update_check'
-`
-
-func (q *sqlQuerier) GetLastUpdateCheck(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getLastUpdateCheck)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getLogoURL = `-- name: GetLogoURL :one
-SELECT value FROM site_configs WHERE key = 'logo_url'
-`
-
-func (q *sqlQuerier) GetLogoURL(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getLogoURL)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getServiceBanner = `-- name: GetServiceBanner :one
-SELECT value FROM site_configs WHERE key = 'service_banner'
-`
-
-func (q *sqlQuerier) GetServiceBanner(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getServiceBanner)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const insertDERPMeshKey = `-- name: InsertDERPMeshKey :exec
-INSERT INTO site_configs (key, value) VALUES ('derp_mesh_key', $1)
-`
-
-func (q *sqlQuerier) InsertDERPMeshKey(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertDERPMeshKey, value)
-	return err
-}
-
-const insertDeploymentID = `-- name: InsertDeploymentID :exec
-INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+const getTemplateVersionPresetParametersByTemplateVersionID = `-- name: GetTemplateVersionPresetParametersByTemplateVersionID :many
+SELECT
+	template_version_preset_parameters.preset_id, template_version_preset_parameters.name, template_version_preset_parameters.value
+FROM
+	template_version_preset_parameters
+JOIN
+	template_version_presets ON template_version_presets.id = template_version_preset_parameters.preset_id
+WHERE
+	template_version_presets.template_version_id = $1
+ORDER BY
+	template_version_preset_parameters.name ASC
 `
 
-func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
-	return err
+func (q *sqlQuerier) GetTemplateVersionPresetParametersByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPresetParameter, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionPresetParametersByTemplateVersionID, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionPresetParameter
+	for rows.Next() {
+		var i TemplateVersionPresetParameter
+		if err := rows.Scan(&i.PresetID, &i.Name, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const upsertAppSecurityKey = `-- name: UpsertAppSecurityKey :exec
-INSERT INTO site_configs (key, value) VALUES ('app_signing_key', $1)
-ON CONFLICT (key) DO UPDATE set value = $1 WHERE site_configs.key = 'app_signing_key'
+const getTemplateVersionPresetsByTemplateVersionID = `-- name: GetTemplateVersionPresetsByTemplateVersionID :many
+SELECT
+	id, template_version_id, name, description, created_at
+FROM
+	template_version_presets
+WHERE
+	template_version_id = $1
+ORDER BY
+	name ASC
 `
 
-func (q *sqlQuerier) UpsertAppSecurityKey(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, upsertAppSecurityKey, value)
-	return err
+func (q *sqlQuerier) GetTemplateVersionPresetsByTemplateVersionID(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPreset, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionPresetsByTemplateVersionID, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionPreset
+	for rows.Next() {
+		var i TemplateVersionPreset
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const upsertDefaultProxy = `-- name: UpsertDefaultProxy :exec
-INSERT INTO site_configs (key, value)
+const insertTemplateVersionPreset = `-- name: InsertTemplateVersionPreset :one
+INSERT INTO
+	template_version_presets (
+		id,
+		template_version_id,
+		name,
+		description,
+		created_at
+	)
 VALUES
-    ('default_proxy_display_name', $1 :: text),
-    ('default_proxy_icon_url', $2 :: text)
-ON CONFLICT
-    (key)
-DO UPDATE SET value = EXCLUDED.value WHERE site_configs.key = EXCLUDED.key
+	($1, $2, $3, $4, $5) RETURNING id, template_version_id, name, description, created_at
 `
 
-type UpsertDefaultProxyParams struct {
-	DisplayName string `db:"display_name" json:"display_name"`
-	IconUrl     string `db:"icon_url" json:"icon_url"`
-}
-
-// The default proxy is implied and not actually stored in the database.
-// So we need to store it's configuration here for display purposes.
-// The functional values are immutable and controlled implicitly.
-func (q *sqlQuerier) UpsertDefaultProxy(ctx context.Context, arg UpsertDefaultProxyParams) error {
-	_, err := q.db.ExecContext(ctx, upsertDefaultProxy, arg.DisplayName, arg.IconUrl)
-	return err
+type InsertTemplateVersionPresetParams struct {
+	ID                uuid.UUID `db:"id" json:"id"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Description       string    `db:"description" json:"description"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
 }
 
-const upsertLastUpdateCheck = `-- name: UpsertLastUpdateCheck :exec
-INSERT INTO site_configs (key, value) VALUES ('last_update_check', $1)
-ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'last_update_check'
-`
-
-func (q *sqlQuerier) UpsertLastUpdateCheck(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, upsertLastUpdateCheck, value)
-	return err
+func (q *sqlQuerier) InsertTemplateVersionPreset(ctx context.Context, arg InsertTemplateVersionPresetParams) (TemplateVersionPreset, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionPreset,
+		arg.ID,
+		arg.TemplateVersionID,
+		arg.Name,
+		arg.Description,
+		arg.CreatedAt,
+	)
+	var i TemplateVersionPreset
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
-const upsertLogoURL = `-- name: UpsertLogoURL :exec
-INSERT INTO site_configs (key, value) VALUES ('logo_url', $1)
-ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'logo_url'
+const insertTemplateVersionPresetParameters = `-- name: InsertTemplateVersionPresetParameters :exec
+INSERT INTO
+	template_version_preset_parameters (preset_id, name, value)
+SELECT
+	$1 :: uuid AS preset_id,
+	unnest($2 :: text[]) AS name,
+	unnest($3 :: text[]) AS value
 `
 
-func (q *sqlQuerier) UpsertLogoURL(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, upsertLogoURL, value)
-	return err
+type InsertTemplateVersionPresetParametersParams struct {
+	PresetID uuid.UUID `db:"preset_id" json:"preset_id"`
+	Name     []string  `db:"name" json:"name"`
+	Value    []string  `db:"value" json:"value"`
 }
 
-const upsertServiceBanner = `-- name: UpsertServiceBanner :exec
-INSERT INTO site_configs (key, value) VALUES ('service_banner', $1)
-ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'service_banner'
-`
-
-func (q *sqlQuerier) UpsertServiceBanner(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, upsertServiceBanner, value)
+func (q *sqlQuerier) InsertTemplateVersionPresetParameters(ctx context.Context, arg InsertTemplateVersionPresetParametersParams) error {
+	_, err := q.db.ExecContext(ctx, insertTemplateVersionPresetParameters, arg.PresetID, pq.Array(arg.Name), pq.Array(arg.Value))
 	return err
 }
 
-const getTemplateAverageBuildTime = `-- name: GetTemplateAverageBuildTime :one
-WITH build_times AS (
+const getRunningTemplateVersionRollouts = `-- name: GetRunningTemplateVersionRollouts :many
 SELECT
-	EXTRACT(EPOCH FROM (pj.completed_at - pj.started_at))::FLOAT AS exec_time_sec,
-	workspace_builds.transition
+	template_version_rollouts.id, template_version_rollouts.template_id, template_version_rollouts.template_version_id, template_version_rollouts.created_by, template_version_rollouts.created_at, template_version_rollouts.updated_at, template_version_rollouts.status, template_version_rollouts.percentage, template_version_rollouts.group_ids, template_version_rollouts.batch_size, template_version_rollouts.failure_threshold_percent, template_version_rollouts.paused_reason
 FROM
-	workspace_builds
-JOIN template_versions ON
-	workspace_builds.template_version_id = template_versions.id
-JOIN provisioner_jobs pj ON
-	workspace_builds.job_id = pj.id
+	template_version_rollouts
+JOIN
+	templates ON templates.id = template_version_rollouts.template_id
 WHERE
-	template_versions.template_id = $1 AND
-		(pj.completed_at IS NOT NULL) AND (pj.started_at IS NOT NULL) AND
-		(pj.started_at > $2) AND
-		(pj.canceled_at IS NULL) AND
-		((pj.error IS NULL) OR (pj.error = ''))
+	template_version_rollouts.status = 'running'
+	AND templates.deleted = false
 ORDER BY
-	workspace_builds.created_at DESC
-)
-SELECT
-	-- Postgres offers no clear way to DRY this short of a function or other
-	-- complexities.
-	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_50,
-	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_50,
-	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_50,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_95,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_95,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95
-FROM build_times
-`
-
-type GetTemplateAverageBuildTimeParams struct {
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	StartTime  sql.NullTime  `db:"start_time" json:"start_time"`
-}
-
-type GetTemplateAverageBuildTimeRow struct {
-	Start50  float64 `db:"start_50" json:"start_50"`
-	Stop50   float64 `db:"stop_50" json:"stop_50"`
-	Delete50 float64 `db:"delete_50" json:"delete_50"`
-	Start95  float64 `db:"start_95" json:"start_95"`
-	Stop95   float64 `db:"stop_95" json:"stop_95"`
-	Delete95 float64 `db:"delete_95" json:"delete_95"`
-}
+	template_version_rollouts.created_at ASC
+`
 
-func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateAverageBuildTime, arg.TemplateID, arg.StartTime)
-	var i GetTemplateAverageBuildTimeRow
-	err := row.Scan(
-		&i.Start50,
-		&i.Stop50,
-		&i.Delete50,
-		&i.Start95,
-		&i.Stop95,
-		&i.Delete95,
-	)
-	return i, err
+// Returns the running rollouts of all templates that have not been deleted.
+func (q *sqlQuerier) GetRunningTemplateVersionRollouts(ctx context.Context) ([]TemplateVersionRollout, error) {
+	rows, err := q.db.QueryContext(ctx, getRunningTemplateVersionRollouts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionRollout
+	for rows.Next() {
+		var i TemplateVersionRollout
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.Percentage,
+			pq.Array(&i.GroupIDs),
+			&i.BatchSize,
+			&i.FailureThresholdPercent,
+			&i.PausedReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getTemplateByID = `-- name: GetTemplateByID :one
+const getTemplateVersionRolloutByID = `-- name: GetTemplateVersionRolloutByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
+	id, template_id, template_version_id, created_by, created_at, updated_at, status, percentage, group_ids, batch_size, failure_threshold_percent, paused_reason
 FROM
-	templates
+	template_version_rollouts
 WHERE
 	id = $1
-LIMIT
-	1
 `
 
-func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
-	var i Template
+func (q *sqlQuerier) GetTemplateVersionRolloutByID(ctx context.Context, id uuid.UUID) (TemplateVersionRollout, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionRolloutByID, id)
+	var i TemplateVersionRollout
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
+		&i.Status,
+		&i.Percentage,
+		pq.Array(&i.GroupIDs),
+		&i.BatchSize,
+		&i.FailureThresholdPercent,
+		&i.PausedReason,
 	)
 	return i, err
 }
 
-const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
+const getTemplateVersionRolloutCandidates = `-- name: GetTemplateVersionRolloutCandidates :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
+	workspaces.id
 FROM
-	templates
+	workspaces
+JOIN
+	workspace_builds ON workspace_builds.workspace_id = workspaces.id
 WHERE
-	organization_id = $1
-	AND deleted = $2
-	AND LOWER("name") = LOWER($3)
-LIMIT
-	1
+	workspaces.template_id = $1
+	AND workspaces.deleted = false
+	AND workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds
+		WHERE
+			workspace_builds.workspace_id = workspaces.id
+	)
+	AND workspace_builds.template_version_id != $2
+	AND (
+		cardinality($3 :: uuid[]) = 0
+		OR workspaces.organization_id = ANY($3 :: uuid[])
+		OR workspaces.owner_id IN (
+			SELECT
+				user_id
+			FROM
+				group_members
+			WHERE
+				group_id = ANY($3 :: uuid[])
+		)
+	)
+ORDER BY
+	workspaces.id ASC
 `
 
-type GetTemplateByOrganizationAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Deleted        bool      `db:"deleted" json:"deleted"`
-	Name           string    `db:"name" json:"name"`
+type GetTemplateVersionRolloutCandidatesParams struct {
+	TemplateID        uuid.UUID   `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID   `db:"template_version_id" json:"template_version_id"`
+	GroupIDs          []uuid.UUID `db:"group_ids" json:"group_ids"`
 }
 
-func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
-	)
-	return i, err
+// Returns the IDs of the template's workspaces whose latest build is not on
+// the template version, ordered by ID. When group IDs are given, only
+// workspaces owned by members of those groups are returned. The "Everyone"
+// group shares its ID with the organization and matches every workspace.
+func (q *sqlQuerier) GetTemplateVersionRolloutCandidates(ctx context.Context, arg GetTemplateVersionRolloutCandidatesParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionRolloutCandidates, arg.TemplateID, arg.TemplateVersionID, pq.Array(arg.GroupIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl FROM templates
-ORDER BY (name, id) ASC
+const getTemplateVersionRolloutWorkspacesByRolloutID = `-- name: GetTemplateVersionRolloutWorkspacesByRolloutID :many
+SELECT
+	template_version_rollout_workspaces.rollout_id, template_version_rollout_workspaces.workspace_id, template_version_rollout_workspaces.build_id, template_version_rollout_workspaces.status, template_version_rollout_workspaces.error, template_version_rollout_workspaces.updated_at,
+	workspaces.name AS workspace_name,
+	users.username AS workspace_owner_name
+FROM
+	template_version_rollout_workspaces
+JOIN
+	workspaces ON workspaces.id = template_version_rollout_workspaces.workspace_id
+JOIN
+	users ON users.id = workspaces.owner_id
+WHERE
+	template_version_rollout_workspaces.rollout_id = $1
+ORDER BY
+	template_version_rollout_workspaces.workspace_id ASC
 `
 
-func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplates)
+type GetTemplateVersionRolloutWorkspacesByRolloutIDRow struct {
+	RolloutID          uuid.UUID                             `db:"rollout_id" json:"rollout_id"`
+	WorkspaceID        uuid.UUID                             `db:"workspace_id" json:"workspace_id"`
+	BuildID            uuid.NullUUID                         `db:"build_id" json:"build_id"`
+	Status             TemplateVersionRolloutWorkspaceStatus `db:"status" json:"status"`
+	Error              string                                `db:"error" json:"error"`
+	UpdatedAt          time.Time                             `db:"updated_at" json:"updated_at"`
+	WorkspaceName      string                                `db:"workspace_name" json:"workspace_name"`
+	WorkspaceOwnerName string                                `db:"workspace_owner_name" json:"workspace_owner_name"`
+}
+
+func (q *sqlQuerier) GetTemplateVersionRolloutWorkspacesByRolloutID(ctx context.Context, rolloutID uuid.UUID) ([]GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionRolloutWorkspacesByRolloutID, rolloutID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Template
+	var items []GetTemplateVersionRolloutWorkspacesByRolloutIDRow
 	for rows.Next() {
-		var i Template
+		var i GetTemplateVersionRolloutWorkspacesByRolloutIDRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
+			&i.RolloutID,
+			&i.WorkspaceID,
+			&i.BuildID,
+			&i.Status,
+			&i.Error,
 			&i.UpdatedAt,
-			&i.OrganizationID,
-			&i.Deleted,
-			&i.Name,
-			&i.Provisioner,
-			&i.ActiveVersionID,
-			&i.Description,
-			&i.DefaultTTL,
-			&i.CreatedBy,
-			&i.Icon,
-			&i.UserACL,
-			&i.GroupACL,
-			&i.DisplayName,
-			&i.AllowUserCancelWorkspaceJobs,
-			&i.MaxTTL,
-			&i.AllowUserAutostart,
-			&i.AllowUserAutostop,
-			&i.FailureTTL,
-			&i.InactivityTTL,
+			&i.WorkspaceName,
+			&i.WorkspaceOwnerName,
 		); err != nil {
 			return nil, err
 		}
@@ -3376,80 +6348,39 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 	return items, nil
 }
 
-const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
+const getTemplateVersionRolloutsByTemplateID = `-- name: GetTemplateVersionRolloutsByTemplateID :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
+	id, template_id, template_version_id, created_by, created_at, updated_at, status, percentage, group_ids, batch_size, failure_threshold_percent, paused_reason
 FROM
-	templates
+	template_version_rollouts
 WHERE
-	-- Optionally include deleted templates
-	templates.deleted = $1
-	-- Filter by organization_id
-	AND CASE
-		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
-			organization_id = $2
-		ELSE true
-	END
-	-- Filter by exact name
-	AND CASE
-		WHEN $3 :: text != '' THEN
-			LOWER("name") = LOWER($3)
-		ELSE true
-	END
-	-- Filter by ids
-	AND CASE
-		WHEN array_length($4 :: uuid[], 1) > 0 THEN
-			id = ANY($4)
-		ELSE true
-	END
-  -- Authorize Filter clause will be injected below in GetAuthorizedTemplates
-  -- @authorize_filter
-ORDER BY (name, id) ASC
+	template_id = $1
+ORDER BY
+	created_at DESC
 `
 
-type GetTemplatesWithFilterParams struct {
-	Deleted        bool        `db:"deleted" json:"deleted"`
-	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
-	ExactName      string      `db:"exact_name" json:"exact_name"`
-	IDs            []uuid.UUID `db:"ids" json:"ids"`
-}
-
-func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplatesWithFilter,
-		arg.Deleted,
-		arg.OrganizationID,
-		arg.ExactName,
-		pq.Array(arg.IDs),
-	)
+func (q *sqlQuerier) GetTemplateVersionRolloutsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]TemplateVersionRollout, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionRolloutsByTemplateID, templateID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Template
+	var items []TemplateVersionRollout
 	for rows.Next() {
-		var i Template
+		var i TemplateVersionRollout
 		if err := rows.Scan(
 			&i.ID,
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.OrganizationID,
-			&i.Deleted,
-			&i.Name,
-			&i.Provisioner,
-			&i.ActiveVersionID,
-			&i.Description,
-			&i.DefaultTTL,
-			&i.CreatedBy,
-			&i.Icon,
-			&i.UserACL,
-			&i.GroupACL,
-			&i.DisplayName,
-			&i.AllowUserCancelWorkspaceJobs,
-			&i.MaxTTL,
-			&i.AllowUserAutostart,
-			&i.AllowUserAutostop,
-			&i.FailureTTL,
-			&i.InactivityTTL,
+			&i.Status,
+			&i.Percentage,
+			pq.Array(&i.GroupIDs),
+			&i.BatchSize,
+			&i.FailureThresholdPercent,
+			&i.PausedReason,
 		); err != nil {
 			return nil, err
 		}
@@ -3464,341 +6395,263 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 	return items, nil
 }
 
-const insertTemplate = `-- name: InsertTemplate :one
+const insertTemplateVersionRollout = `-- name: InsertTemplateVersionRollout :one
 INSERT INTO
-	templates (
+	template_version_rollouts (
 		id,
-		created_at,
-		updated_at,
-		organization_id,
-		"name",
-		provisioner,
-		active_version_id,
-		description,
-		created_by,
-		icon,
-		user_acl,
-		group_acl,
-		display_name,
-		allow_user_cancel_workspace_jobs
+		template_id,
+		template_version_id,
+		created_by,
+		created_at,
+		updated_at,
+		percentage,
+		group_ids,
+		batch_size,
+		failure_threshold_percent
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, template_id, template_version_id, created_by, created_at, updated_at, status, percentage, group_ids, batch_size, failure_threshold_percent, paused_reason
 `
 
-type InsertTemplateParams struct {
-	ID                           uuid.UUID       `db:"id" json:"id"`
-	CreatedAt                    time.Time       `db:"created_at" json:"created_at"`
-	UpdatedAt                    time.Time       `db:"updated_at" json:"updated_at"`
-	OrganizationID               uuid.UUID       `db:"organization_id" json:"organization_id"`
-	Name                         string          `db:"name" json:"name"`
-	Provisioner                  ProvisionerType `db:"provisioner" json:"provisioner"`
-	ActiveVersionID              uuid.UUID       `db:"active_version_id" json:"active_version_id"`
-	Description                  string          `db:"description" json:"description"`
-	CreatedBy                    uuid.UUID       `db:"created_by" json:"created_by"`
-	Icon                         string          `db:"icon" json:"icon"`
-	UserACL                      TemplateACL     `db:"user_acl" json:"user_acl"`
-	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
-	DisplayName                  string          `db:"display_name" json:"display_name"`
-	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+type InsertTemplateVersionRolloutParams struct {
+	ID                      uuid.UUID   `db:"id" json:"id"`
+	TemplateID              uuid.UUID   `db:"template_id" json:"template_id"`
+	TemplateVersionID       uuid.UUID   `db:"template_version_id" json:"template_version_id"`
+	CreatedBy               uuid.UUID   `db:"created_by" json:"created_by"`
+	CreatedAt               time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt               time.Time   `db:"updated_at" json:"updated_at"`
+	Percentage              int32       `db:"percentage" json:"percentage"`
+	GroupIDs                []uuid.UUID `db:"group_ids" json:"group_ids"`
+	BatchSize               int32       `db:"batch_size" json:"batch_size"`
+	FailureThresholdPercent int32       `db:"failure_threshold_percent" json:"failure_threshold_percent"`
 }
 
-func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplate,
+func (q *sqlQuerier) InsertTemplateVersionRollout(ctx context.Context, arg InsertTemplateVersionRolloutParams) (TemplateVersionRollout, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionRollout,
 		arg.ID,
+		arg.TemplateID,
+		arg.TemplateVersionID,
+		arg.CreatedBy,
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.OrganizationID,
-		arg.Name,
-		arg.Provisioner,
-		arg.ActiveVersionID,
-		arg.Description,
-		arg.CreatedBy,
-		arg.Icon,
-		arg.UserACL,
-		arg.GroupACL,
-		arg.DisplayName,
-		arg.AllowUserCancelWorkspaceJobs,
+		arg.Percentage,
+		pq.Array(arg.GroupIDs),
+		arg.BatchSize,
+		arg.FailureThresholdPercent,
 	)
-	var i Template
+	var i TemplateVersionRollout
 	err := row.Scan(
 		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
+		&i.TemplateID,
+		&i.TemplateVersionID,
 		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
-	)
-	return i, err
-}
-
-const updateTemplateACLByID = `-- name: UpdateTemplateACLByID :one
-UPDATE
-	templates
-SET
-	group_acl = $1,
-	user_acl = $2
-WHERE
-	id = $3
-RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
-`
-
-type UpdateTemplateACLByIDParams struct {
-	GroupACL TemplateACL `db:"group_acl" json:"group_acl"`
-	UserACL  TemplateACL `db:"user_acl" json:"user_acl"`
-	ID       uuid.UUID   `db:"id" json:"id"`
-}
-
-func (q *sqlQuerier) UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, updateTemplateACLByID, arg.GroupACL, arg.UserACL, arg.ID)
-	var i Template
-	err := row.Scan(
-		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
+		&i.Status,
+		&i.Percentage,
+		pq.Array(&i.GroupIDs),
+		&i.BatchSize,
+		&i.FailureThresholdPercent,
+		&i.PausedReason,
 	)
 	return i, err
 }
 
-const updateTemplateActiveVersionByID = `-- name: UpdateTemplateActiveVersionByID :exec
-UPDATE
-	templates
-SET
-	active_version_id = $2,
-	updated_at = $3
-WHERE
-	id = $1
-`
-
-type UpdateTemplateActiveVersionByIDParams struct {
-	ID              uuid.UUID `db:"id" json:"id"`
-	ActiveVersionID uuid.UUID `db:"active_version_id" json:"active_version_id"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateActiveVersionByID, arg.ID, arg.ActiveVersionID, arg.UpdatedAt)
-	return err
-}
-
-const updateTemplateDeletedByID = `-- name: UpdateTemplateDeletedByID :exec
-UPDATE
-	templates
-SET
-	deleted = $2,
-	updated_at = $3
-WHERE
-	id = $1
+const insertTemplateVersionRolloutWorkspace = `-- name: InsertTemplateVersionRolloutWorkspace :exec
+INSERT INTO
+	template_version_rollout_workspaces (
+		rollout_id,
+		workspace_id,
+		updated_at
+	)
+VALUES
+	($1, $2, $3)
 `
 
-type UpdateTemplateDeletedByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Deleted   bool      `db:"deleted" json:"deleted"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+type InsertTemplateVersionRolloutWorkspaceParams struct {
+	RolloutID   uuid.UUID `db:"rollout_id" json:"rollout_id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateDeletedByID, arg.ID, arg.Deleted, arg.UpdatedAt)
+func (q *sqlQuerier) InsertTemplateVersionRolloutWorkspace(ctx context.Context, arg InsertTemplateVersionRolloutWorkspaceParams) error {
+	_, err := q.db.ExecContext(ctx, insertTemplateVersionRolloutWorkspace, arg.RolloutID, arg.WorkspaceID, arg.UpdatedAt)
 	return err
 }
 
-const updateTemplateMetaByID = `-- name: UpdateTemplateMetaByID :one
+const updateTemplateVersionRolloutStatusByID = `-- name: UpdateTemplateVersionRolloutStatusByID :one
 UPDATE
-	templates
+	template_version_rollouts
 SET
-	updated_at = $2,
-	description = $3,
-	name = $4,
-	icon = $5,
-	display_name = $6,
-	allow_user_cancel_workspace_jobs = $7
+	status = $2,
+	paused_reason = $3,
+	updated_at = $4
 WHERE
 	id = $1
-RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
+RETURNING id, template_id, template_version_id, created_by, created_at, updated_at, status, percentage, group_ids, batch_size, failure_threshold_percent, paused_reason
 `
 
-type UpdateTemplateMetaByIDParams struct {
-	ID                           uuid.UUID `db:"id" json:"id"`
-	UpdatedAt                    time.Time `db:"updated_at" json:"updated_at"`
-	Description                  string    `db:"description" json:"description"`
-	Name                         string    `db:"name" json:"name"`
-	Icon                         string    `db:"icon" json:"icon"`
-	DisplayName                  string    `db:"display_name" json:"display_name"`
-	AllowUserCancelWorkspaceJobs bool      `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+type UpdateTemplateVersionRolloutStatusByIDParams struct {
+	ID           uuid.UUID                    `db:"id" json:"id"`
+	Status       TemplateVersionRolloutStatus `db:"status" json:"status"`
+	PausedReason string                       `db:"paused_reason" json:"paused_reason"`
+	UpdatedAt    time.Time                    `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, updateTemplateMetaByID,
+func (q *sqlQuerier) UpdateTemplateVersionRolloutStatusByID(ctx context.Context, arg UpdateTemplateVersionRolloutStatusByIDParams) (TemplateVersionRollout, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateVersionRolloutStatusByID,
 		arg.ID,
+		arg.Status,
+		arg.PausedReason,
 		arg.UpdatedAt,
-		arg.Description,
-		arg.Name,
-		arg.Icon,
-		arg.DisplayName,
-		arg.AllowUserCancelWorkspaceJobs,
 	)
-	var i Template
+	var i TemplateVersionRollout
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
+		&i.Status,
+		&i.Percentage,
+		pq.Array(&i.GroupIDs),
+		&i.BatchSize,
+		&i.FailureThresholdPercent,
+		&i.PausedReason,
 	)
 	return i, err
 }
 
-const updateTemplateScheduleByID = `-- name: UpdateTemplateScheduleByID :one
+const updateTemplateVersionRolloutWorkspace = `-- name: UpdateTemplateVersionRolloutWorkspace :exec
 UPDATE
-	templates
+	template_version_rollout_workspaces
 SET
-	updated_at = $2,
-	allow_user_autostart = $3,
-	allow_user_autostop = $4,
-	default_ttl = $5,
-	max_ttl = $6,
-	failure_ttl = $7,
-	inactivity_ttl = $8
+	build_id = $3,
+	status = $4,
+	error = $5,
+	updated_at = $6
 WHERE
-	id = $1
-RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl
+	rollout_id = $1
+	AND workspace_id = $2
 `
 
-type UpdateTemplateScheduleByIDParams struct {
-	ID                 uuid.UUID `db:"id" json:"id"`
-	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
-	AllowUserAutostart bool      `db:"allow_user_autostart" json:"allow_user_autostart"`
-	AllowUserAutostop  bool      `db:"allow_user_autostop" json:"allow_user_autostop"`
-	DefaultTTL         int64     `db:"default_ttl" json:"default_ttl"`
-	MaxTTL             int64     `db:"max_ttl" json:"max_ttl"`
-	FailureTTL         int64     `db:"failure_ttl" json:"failure_ttl"`
-	InactivityTTL      int64     `db:"inactivity_ttl" json:"inactivity_ttl"`
+type UpdateTemplateVersionRolloutWorkspaceParams struct {
+	RolloutID   uuid.UUID                             `db:"rollout_id" json:"rollout_id"`
+	WorkspaceID uuid.UUID                             `db:"workspace_id" json:"workspace_id"`
+	BuildID     uuid.NullUUID                         `db:"build_id" json:"build_id"`
+	Status      TemplateVersionRolloutWorkspaceStatus `db:"status" json:"status"`
+	Error       string                                `db:"error" json:"error"`
+	UpdatedAt   time.Time                             `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateTemplateScheduleByIDParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, updateTemplateScheduleByID,
-		arg.ID,
+func (q *sqlQuerier) UpdateTemplateVersionRolloutWorkspace(ctx context.Context, arg UpdateTemplateVersionRolloutWorkspaceParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionRolloutWorkspace,
+		arg.RolloutID,
+		arg.WorkspaceID,
+		arg.BuildID,
+		arg.Status,
+		arg.Error,
 		arg.UpdatedAt,
-		arg.AllowUserAutostart,
-		arg.AllowUserAutostop,
-		arg.DefaultTTL,
-		arg.MaxTTL,
-		arg.FailureTTL,
-		arg.InactivityTTL,
-	)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
 	)
-	return i, err
+	return err
 }
 
-const getTemplateVersionParameters = `-- name: GetTemplateVersionParameters :many
-SELECT template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic, required, legacy_variable_name, display_name FROM template_version_parameters WHERE template_version_id = $1
+const archiveUnusedTemplateVersions = `-- name: ArchiveUnusedTemplateVersions :many
+WITH archived_versions AS (
+	UPDATE
+		template_versions
+	SET
+		state = 'archived'::template_version_state,
+		updated_at = $1
+	FROM
+		templates
+	WHERE
+		template_versions.template_id = $2 :: uuid
+		AND templates.id = template_versions.template_id
+		AND template_versions.id != templates.active_version_id
+		AND template_versions.state != 'archived'::template_version_state
+		AND (
+			cardinality($3 :: uuid[]) = 0
+			OR template_versions.id = ANY($3 :: uuid[])
+		)
+		AND template_versions.id NOT IN (
+			SELECT DISTINCT ON (workspace_builds.workspace_id)
+				workspace_builds.template_version_id
+			FROM
+				workspace_builds
+			JOIN
+				workspaces ON workspaces.id = workspace_builds.workspace_id
+			WHERE
+				workspaces.template_id = $2 :: uuid
+				AND workspaces.deleted = false
+			ORDER BY
+				workspace_builds.workspace_id, workspace_builds.build_number DESC
+		)
+	RETURNING
+		template_versions.id, template_versions.job_id
+), deleted_files AS (
+	DELETE FROM
+		files
+	WHERE
+		files.id IN (
+			SELECT
+				provisioner_jobs.file_id
+			FROM
+				provisioner_jobs
+			JOIN
+				archived_versions ON archived_versions.job_id = provisioner_jobs.id
+		)
+		-- Workspace builds and dry runs reference the file as well, only
+		-- the imports of archived versions may still use it.
+		AND files.id NOT IN (
+			SELECT
+				provisioner_jobs.file_id
+			FROM
+				provisioner_jobs
+			WHERE
+				provisioner_jobs.id NOT IN (SELECT job_id FROM archived_versions)
+				AND provisioner_jobs.id NOT IN (
+					SELECT
+						template_versions.job_id
+					FROM
+						template_versions
+					WHERE
+						template_versions.state = 'archived'::template_version_state
+				)
+		)
+)
+SELECT
+	id
+FROM
+	archived_versions
 `
 
-func (q *sqlQuerier) GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionParameter, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionParameters, templateVersionID)
+type ArchiveUnusedTemplateVersionsParams struct {
+	UpdatedAt          time.Time   `db:"updated_at" json:"updated_at"`
+	TemplateID         uuid.UUID   `db:"template_id" json:"template_id"`
+	TemplateVersionIds []uuid.UUID `db:"template_version_ids" json:"template_version_ids"`
+}
+
+// Archives every version of a template that is not the active version and is
+// not used by the latest build of any workspace. If template_version_ids is
+// not empty, only those versions are considered. Files that are no longer
+// referenced by a provisioner job, other than the imports of archived
+// versions, are deleted to reclaim storage.
+func (q *sqlQuerier) ArchiveUnusedTemplateVersions(ctx context.Context, arg ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, archiveUnusedTemplateVersions, arg.UpdatedAt, arg.TemplateID, pq.Array(arg.TemplateVersionIds))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TemplateVersionParameter
+	var items []uuid.UUID
 	for rows.Next() {
-		var i TemplateVersionParameter
-		if err := rows.Scan(
-			&i.TemplateVersionID,
-			&i.Name,
-			&i.Description,
-			&i.Type,
-			&i.Mutable,
-			&i.DefaultValue,
-			&i.Icon,
-			&i.Options,
-			&i.ValidationRegex,
-			&i.ValidationMin,
-			&i.ValidationMax,
-			&i.ValidationError,
-			&i.ValidationMonotonic,
-			&i.Required,
-			&i.LegacyVariableName,
-			&i.DisplayName,
-		); err != nil {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -3809,110 +6662,9 @@ func (q *sqlQuerier) GetTemplateVersionParameters(ctx context.Context, templateV
 	return items, nil
 }
 
-const insertTemplateVersionParameter = `-- name: InsertTemplateVersionParameter :one
-INSERT INTO
-    template_version_parameters (
-        template_version_id,
-        name,
-        description,
-        type,
-        mutable,
-        default_value,
-        icon,
-        options,
-        validation_regex,
-        validation_min,
-        validation_max,
-        validation_error,
-        validation_monotonic,
-        required,
-        legacy_variable_name,
-        display_name
-    )
-VALUES
-    (
-        $1,
-        $2,
-        $3,
-        $4,
-        $5,
-        $6,
-        $7,
-        $8,
-        $9,
-        $10,
-        $11,
-        $12,
-        $13,
-        $14,
-        $15,
-		$16
-    ) RETURNING template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic, required, legacy_variable_name, display_name
-`
-
-type InsertTemplateVersionParameterParams struct {
-	TemplateVersionID   uuid.UUID       `db:"template_version_id" json:"template_version_id"`
-	Name                string          `db:"name" json:"name"`
-	Description         string          `db:"description" json:"description"`
-	Type                string          `db:"type" json:"type"`
-	Mutable             bool            `db:"mutable" json:"mutable"`
-	DefaultValue        string          `db:"default_value" json:"default_value"`
-	Icon                string          `db:"icon" json:"icon"`
-	Options             json.RawMessage `db:"options" json:"options"`
-	ValidationRegex     string          `db:"validation_regex" json:"validation_regex"`
-	ValidationMin       sql.NullInt32   `db:"validation_min" json:"validation_min"`
-	ValidationMax       sql.NullInt32   `db:"validation_max" json:"validation_max"`
-	ValidationError     string          `db:"validation_error" json:"validation_error"`
-	ValidationMonotonic string          `db:"validation_monotonic" json:"validation_monotonic"`
-	Required            bool            `db:"required" json:"required"`
-	LegacyVariableName  string          `db:"legacy_variable_name" json:"legacy_variable_name"`
-	DisplayName         string          `db:"display_name" json:"display_name"`
-}
-
-func (q *sqlQuerier) InsertTemplateVersionParameter(ctx context.Context, arg InsertTemplateVersionParameterParams) (TemplateVersionParameter, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplateVersionParameter,
-		arg.TemplateVersionID,
-		arg.Name,
-		arg.Description,
-		arg.Type,
-		arg.Mutable,
-		arg.DefaultValue,
-		arg.Icon,
-		arg.Options,
-		arg.ValidationRegex,
-		arg.ValidationMin,
-		arg.ValidationMax,
-		arg.ValidationError,
-		arg.ValidationMonotonic,
-		arg.Required,
-		arg.LegacyVariableName,
-		arg.DisplayName,
-	)
-	var i TemplateVersionParameter
-	err := row.Scan(
-		&i.TemplateVersionID,
-		&i.Name,
-		&i.Description,
-		&i.Type,
-		&i.Mutable,
-		&i.DefaultValue,
-		&i.Icon,
-		&i.Options,
-		&i.ValidationRegex,
-		&i.ValidationMin,
-		&i.ValidationMax,
-		&i.ValidationError,
-		&i.ValidationMonotonic,
-		&i.Required,
-		&i.LegacyVariableName,
-		&i.DisplayName,
-	)
-	return i, err
-}
-
 const getPreviousTemplateVersion = `-- name: GetPreviousTemplateVersion :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 FROM
 	template_versions
 WHERE
@@ -3947,13 +6699,14 @@ func (q *sqlQuerier) GetPreviousTemplateVersion(ctx context.Context, arg GetPrev
 		&i.JobID,
 		&i.CreatedBy,
 		pq.Array(&i.GitAuthProviders),
+		&i.State,
 	)
 	return i, err
 }
 
 const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 FROM
 	template_versions
 WHERE
@@ -3974,13 +6727,14 @@ func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (
 		&i.JobID,
 		&i.CreatedBy,
 		pq.Array(&i.GitAuthProviders),
+		&i.State,
 	)
 	return i, err
 }
 
 const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 FROM
 	template_versions
 WHERE
@@ -4001,13 +6755,14 @@ func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.U
 		&i.JobID,
 		&i.CreatedBy,
 		pq.Array(&i.GitAuthProviders),
+		&i.State,
 	)
 	return i, err
 }
 
 const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 FROM
 	template_versions
 WHERE
@@ -4034,13 +6789,14 @@ func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context,
 		&i.JobID,
 		&i.CreatedBy,
 		pq.Array(&i.GitAuthProviders),
+		&i.State,
 	)
 	return i, err
 }
 
 const getTemplateVersionsByIDs = `-- name: GetTemplateVersionsByIDs :many
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 FROM
 	template_versions
 WHERE
@@ -4067,6 +6823,7 @@ func (q *sqlQuerier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UU
 			&i.JobID,
 			&i.CreatedBy,
 			pq.Array(&i.GitAuthProviders),
+			&i.State,
 		); err != nil {
 			return nil, err
 		}
@@ -4083,7 +6840,7 @@ func (q *sqlQuerier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UU
 
 const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 FROM
 	template_versions
 WHERE
@@ -4148,6 +6905,7 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 			&i.JobID,
 			&i.CreatedBy,
 			pq.Array(&i.GitAuthProviders),
+			&i.State,
 		); err != nil {
 			return nil, err
 		}
@@ -4163,7 +6921,7 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 }
 
 const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
-SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers FROM template_versions WHERE created_at > $1
+SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state FROM template_versions WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
@@ -4186,6 +6944,7 @@ func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, create
 			&i.JobID,
 			&i.CreatedBy,
 			pq.Array(&i.GitAuthProviders),
+			&i.State,
 		); err != nil {
 			return nil, err
 		}
@@ -4214,7 +6973,7 @@ INSERT INTO
 		created_by
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 `
 
 type InsertTemplateVersionParams struct {
@@ -4253,6 +7012,7 @@ func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTempla
 		&i.JobID,
 		&i.CreatedBy,
 		pq.Array(&i.GitAuthProviders),
+		&i.State,
 	)
 	return i, err
 }
@@ -4265,7 +7025,7 @@ SET
 	updated_at = $3,
 	name = $4
 WHERE
-	id = $1 RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	id = $1 RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, state
 `
 
 type UpdateTemplateVersionByIDParams struct {
@@ -4294,6 +7054,7 @@ func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTe
 		&i.JobID,
 		&i.CreatedBy,
 		pq.Array(&i.GitAuthProviders),
+		&i.State,
 	)
 	return i, err
 }
@@ -4340,6 +7101,27 @@ func (q *sqlQuerier) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Co
 	return err
 }
 
+const updateTemplateVersionStateByID = `-- name: UpdateTemplateVersionStateByID :exec
+UPDATE
+	template_versions
+SET
+	state = $2,
+	updated_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateTemplateVersionStateByIDParams struct {
+	ID        uuid.UUID            `db:"id" json:"id"`
+	State     TemplateVersionState `db:"state" json:"state"`
+	UpdatedAt time.Time            `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateVersionStateByID(ctx context.Context, arg UpdateTemplateVersionStateByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionStateByID, arg.ID, arg.State, arg.UpdatedAt)
+	return err
+}
+
 const getTemplateVersionVariables = `-- name: GetTemplateVersionVariables :many
 SELECT template_version_id, name, description, type, value, default_value, required, sensitive FROM template_version_variables WHERE template_version_id = $1
 `
@@ -4439,7 +7221,7 @@ func (q *sqlQuerier) InsertTemplateVersionVariable(ctx context.Context, arg Inse
 
 const getUserLinkByLinkedID = `-- name: GetUserLinkByLinkedID :one
 SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider_id
 FROM
 	user_links
 WHERE
@@ -4456,13 +7238,14 @@ func (q *sqlQuerier) GetUserLinkByLinkedID(ctx context.Context, linkedID string)
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProviderID,
 	)
 	return i, err
 }
 
 const getUserLinkByUserIDLoginType = `-- name: GetUserLinkByUserIDLoginType :one
 SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider_id
 FROM
 	user_links
 WHERE
@@ -4484,6 +7267,7 @@ func (q *sqlQuerier) GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUs
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProviderID,
 	)
 	return i, err
 }
@@ -4496,10 +7280,11 @@ INSERT INTO
 		linked_id,
 		oauth_access_token,
 		oauth_refresh_token,
-		oauth_expiry
+		oauth_expiry,
+		oidc_provider_id
 	)
 VALUES
-	( $1, $2, $3, $4, $5, $6 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	( $1, $2, $3, $4, $5, $6, $7 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider_id
 `
 
 type InsertUserLinkParams struct {
@@ -4509,6 +7294,7 @@ type InsertUserLinkParams struct {
 	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
 	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
 	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+	OIDCProviderID    string    `db:"oidc_provider_id" json:"oidc_provider_id"`
 }
 
 func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error) {
@@ -4519,6 +7305,7 @@ func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParam
 		arg.OAuthAccessToken,
 		arg.OAuthRefreshToken,
 		arg.OAuthExpiry,
+		arg.OIDCProviderID,
 	)
 	var i UserLink
 	err := row.Scan(
@@ -4528,6 +7315,7 @@ func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParam
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProviderID,
 	)
 	return i, err
 }
@@ -4540,7 +7328,7 @@ SET
 	oauth_refresh_token = $2,
 	oauth_expiry = $3
 WHERE
-	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider_id
 `
 
 type UpdateUserLinkParams struct {
@@ -4567,6 +7355,7 @@ func (q *sqlQuerier) UpdateUserLink(ctx context.Context, arg UpdateUserLinkParam
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProviderID,
 	)
 	return i, err
 }
@@ -4577,7 +7366,7 @@ UPDATE
 SET
 	linked_id = $1
 WHERE
-	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry, oidc_provider_id
 `
 
 type UpdateUserLinkedIDParams struct {
@@ -4596,6 +7385,224 @@ func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinke
 		&i.OAuthAccessToken,
 		&i.OAuthRefreshToken,
 		&i.OAuthExpiry,
+		&i.OIDCProviderID,
+	)
+	return i, err
+}
+
+const deleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM
+	user_totp
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteUserTOTP(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTOTP, userID)
+	return err
+}
+
+const deleteUserLoginFailures = `-- name: DeleteUserLoginFailures :exec
+DELETE FROM
+	user_login_failures
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteUserLoginFailures(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserLoginFailures, userID)
+	return err
+}
+
+const getUserLoginFailuresByUserID = `-- name: GetUserLoginFailuresByUserID :one
+SELECT
+	user_id, failed_attempts, last_failed_at, locked_until
+FROM
+	user_login_failures
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) GetUserLoginFailuresByUserID(ctx context.Context, userID uuid.UUID) (UserLoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, getUserLoginFailuresByUserID, userID)
+	var i UserLoginFailure
+	err := row.Scan(
+		&i.UserID,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const insertUserLoginFailure = `-- name: InsertUserLoginFailure :one
+INSERT INTO
+	user_login_failures (
+		user_id,
+		failed_attempts,
+		last_failed_at
+	)
+VALUES
+	($1, 1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+	failed_attempts = user_login_failures.failed_attempts + 1,
+	last_failed_at = $2
+RETURNING user_id, failed_attempts, last_failed_at, locked_until
+`
+
+type InsertUserLoginFailureParams struct {
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	LastFailedAt time.Time `db:"last_failed_at" json:"last_failed_at"`
+}
+
+// Records a failed login, counting up from any previous failures.
+func (q *sqlQuerier) InsertUserLoginFailure(ctx context.Context, arg InsertUserLoginFailureParams) (UserLoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, insertUserLoginFailure, arg.UserID, arg.LastFailedAt)
+	var i UserLoginFailure
+	err := row.Scan(
+		&i.UserID,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const updateUserLoginLockout = `-- name: UpdateUserLoginLockout :one
+UPDATE
+	user_login_failures
+SET
+	failed_attempts = 0,
+	locked_until = $2
+WHERE
+	user_id = $1
+RETURNING user_id, failed_attempts, last_failed_at, locked_until
+`
+
+type UpdateUserLoginLockoutParams struct {
+	UserID      uuid.UUID    `db:"user_id" json:"user_id"`
+	LockedUntil sql.NullTime `db:"locked_until" json:"locked_until"`
+}
+
+// Locks the user out. Failures are counted from zero again once the lockout
+// ends.
+func (q *sqlQuerier) UpdateUserLoginLockout(ctx context.Context, arg UpdateUserLoginLockoutParams) (UserLoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLoginLockout, arg.UserID, arg.LockedUntil)
+	var i UserLoginFailure
+	err := row.Scan(
+		&i.UserID,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const getUserTOTPByUserID = `-- name: GetUserTOTPByUserID :one
+SELECT
+	user_id, secret, enabled, hashed_recovery_codes, last_counter, created_at, updated_at
+FROM
+	user_totp
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, getUserTOTPByUserID, userID)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Enabled,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.LastCounter,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateUserTOTP = `-- name: UpdateUserTOTP :one
+UPDATE
+	user_totp
+SET
+	enabled = $2,
+	hashed_recovery_codes = $3,
+	last_counter = $4,
+	updated_at = $5
+WHERE
+	user_id = $1
+RETURNING user_id, secret, enabled, hashed_recovery_codes, last_counter, created_at, updated_at
+`
+
+type UpdateUserTOTPParams struct {
+	UserID              uuid.UUID `db:"user_id" json:"user_id"`
+	Enabled             bool      `db:"enabled" json:"enabled"`
+	HashedRecoveryCodes []string  `db:"hashed_recovery_codes" json:"hashed_recovery_codes"`
+	LastCounter         int64     `db:"last_counter" json:"last_counter"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateUserTOTP(ctx context.Context, arg UpdateUserTOTPParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, updateUserTOTP,
+		arg.UserID,
+		arg.Enabled,
+		pq.Array(arg.HashedRecoveryCodes),
+		arg.LastCounter,
+		arg.UpdatedAt,
+	)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Enabled,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.LastCounter,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertUserTOTP = `-- name: UpsertUserTOTP :one
+INSERT INTO
+	user_totp (
+		user_id,
+		secret,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $3)
+ON CONFLICT (user_id) DO UPDATE SET
+	secret = $2,
+	enabled = false,
+	hashed_recovery_codes = '{}',
+	last_counter = 0,
+	created_at = $3,
+	updated_at = $3
+RETURNING user_id, secret, enabled, hashed_recovery_codes, last_counter, created_at, updated_at
+`
+
+type UpsertUserTOTPParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Secret    string    `db:"secret" json:"secret"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Starts a new enrollment. Any previous enrollment of the user is replaced
+// and stays disabled until it is verified.
+func (q *sqlQuerier) UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserTOTP, arg.UserID, arg.Secret, arg.CreatedAt)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Enabled,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.LastCounter,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -4607,8 +7614,10 @@ FROM
 	users
 WHERE
     status = 'active'::user_status AND deleted = false
+	AND NOT EXISTS (SELECT 1 FROM service_accounts WHERE service_accounts.user_id = users.id)
 `
 
+// Service accounts are not counted, as they are not people.
 func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
 	row := q.db.QueryRowContext(ctx, getActiveUserCount)
 	var count int64
@@ -4622,6 +7631,9 @@ SELECT
 	-- status is used to enforce 'suspended' users, as all roles are ignored
 	--	when suspended.
 	id, username, status,
+	-- service_account_created_by is only set for service accounts, which
+	-- can only authenticate with tokens.
+	service_accounts.created_by AS service_account_created_by,
 	-- All user roles, including their org roles.
 	array_cat(
 		-- All users are members
@@ -4652,16 +7664,19 @@ SELECT
 	) :: text[] AS groups
 FROM
 	users
+LEFT JOIN
+	service_accounts ON service_accounts.user_id = users.id
 WHERE
 	id = $1
 `
 
 type GetAuthorizationUserRolesRow struct {
-	ID       uuid.UUID  `db:"id" json:"id"`
-	Username string     `db:"username" json:"username"`
-	Status   UserStatus `db:"status" json:"status"`
-	Roles    []string   `db:"roles" json:"roles"`
-	Groups   []string   `db:"groups" json:"groups"`
+	ID                      uuid.UUID     `db:"id" json:"id"`
+	Username                string        `db:"username" json:"username"`
+	Status                  UserStatus    `db:"status" json:"status"`
+	ServiceAccountCreatedBy uuid.NullUUID `db:"service_account_created_by" json:"service_account_created_by"`
+	Roles                   []string      `db:"roles" json:"roles"`
+	Groups                  []string      `db:"groups" json:"groups"`
 }
 
 // This function returns roles for authorization purposes. Implied member roles
@@ -4673,6 +7688,7 @@ func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.
 		&i.ID,
 		&i.Username,
 		&i.Status,
+		&i.ServiceAccountCreatedBy,
 		pq.Array(&i.Roles),
 		pq.Array(&i.Groups),
 	)
@@ -5248,7 +8264,7 @@ func (q *sqlQuerier) DeleteOldWorkspaceAgentStartupLogs(ctx context.Context) err
 
 const getWorkspaceAgentByAuthToken = `-- name: GetWorkspaceAgentByAuthToken :one
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior, peer_connections
 FROM
 	workspace_agents
 WHERE
@@ -5292,13 +8308,14 @@ func (q *sqlQuerier) GetWorkspaceAgentByAuthToken(ctx context.Context, authToken
 		&i.StartupLogsOverflowed,
 		&i.Subsystem,
 		&i.StartupScriptBehavior,
+		&i.PeerConnections,
 	)
 	return i, err
 }
 
 const getWorkspaceAgentByID = `-- name: GetWorkspaceAgentByID :one
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior, peer_connections
 FROM
 	workspace_agents
 WHERE
@@ -5340,13 +8357,14 @@ func (q *sqlQuerier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (W
 		&i.StartupLogsOverflowed,
 		&i.Subsystem,
 		&i.StartupScriptBehavior,
+		&i.PeerConnections,
 	)
 	return i, err
 }
 
 const getWorkspaceAgentByInstanceID = `-- name: GetWorkspaceAgentByInstanceID :one
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior, peer_connections
 FROM
 	workspace_agents
 WHERE
@@ -5390,6 +8408,7 @@ func (q *sqlQuerier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInst
 		&i.StartupLogsOverflowed,
 		&i.Subsystem,
 		&i.StartupScriptBehavior,
+		&i.PeerConnections,
 	)
 	return i, err
 }
@@ -5505,7 +8524,7 @@ func (q *sqlQuerier) GetWorkspaceAgentStartupLogsEOF(ctx context.Context, agentI
 
 const getWorkspaceAgentsByResourceIDs = `-- name: GetWorkspaceAgentsByResourceIDs :many
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior, peer_connections
 FROM
 	workspace_agents
 WHERE
@@ -5553,6 +8572,7 @@ func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []
 			&i.StartupLogsOverflowed,
 			&i.Subsystem,
 			&i.StartupScriptBehavior,
+			&i.PeerConnections,
 		); err != nil {
 			return nil, err
 		}
@@ -5568,7 +8588,7 @@ func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []
 }
 
 const getWorkspaceAgentsCreatedAfter = `-- name: GetWorkspaceAgentsCreatedAfter :many
-SELECT id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior FROM workspace_agents WHERE created_at > $1
+SELECT id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior, peer_connections FROM workspace_agents WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceAgent, error) {
@@ -5612,6 +8632,7 @@ func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, created
 			&i.StartupLogsOverflowed,
 			&i.Subsystem,
 			&i.StartupScriptBehavior,
+			&i.PeerConnections,
 		); err != nil {
 			return nil, err
 		}
@@ -5628,7 +8649,7 @@ func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, created
 
 const getWorkspaceAgentsInLatestBuildByWorkspaceID = `-- name: GetWorkspaceAgentsInLatestBuildByWorkspaceID :many
 SELECT
-	workspace_agents.id, workspace_agents.created_at, workspace_agents.updated_at, workspace_agents.name, workspace_agents.first_connected_at, workspace_agents.last_connected_at, workspace_agents.disconnected_at, workspace_agents.resource_id, workspace_agents.auth_token, workspace_agents.auth_instance_id, workspace_agents.architecture, workspace_agents.environment_variables, workspace_agents.operating_system, workspace_agents.startup_script, workspace_agents.instance_metadata, workspace_agents.resource_metadata, workspace_agents.directory, workspace_agents.version, workspace_agents.last_connected_replica_id, workspace_agents.connection_timeout_seconds, workspace_agents.troubleshooting_url, workspace_agents.motd_file, workspace_agents.lifecycle_state, workspace_agents.startup_script_timeout_seconds, workspace_agents.expanded_directory, workspace_agents.shutdown_script, workspace_agents.shutdown_script_timeout_seconds, workspace_agents.startup_logs_length, workspace_agents.startup_logs_overflowed, workspace_agents.subsystem, workspace_agents.startup_script_behavior
+	workspace_agents.id, workspace_agents.created_at, workspace_agents.updated_at, workspace_agents.name, workspace_agents.first_connected_at, workspace_agents.last_connected_at, workspace_agents.disconnected_at, workspace_agents.resource_id, workspace_agents.auth_token, workspace_agents.auth_instance_id, workspace_agents.architecture, workspace_agents.environment_variables, workspace_agents.operating_system, workspace_agents.startup_script, workspace_agents.instance_metadata, workspace_agents.resource_metadata, workspace_agents.directory, workspace_agents.version, workspace_agents.last_connected_replica_id, workspace_agents.connection_timeout_seconds, workspace_agents.troubleshooting_url, workspace_agents.motd_file, workspace_agents.lifecycle_state, workspace_agents.startup_script_timeout_seconds, workspace_agents.expanded_directory, workspace_agents.shutdown_script, workspace_agents.shutdown_script_timeout_seconds, workspace_agents.startup_logs_length, workspace_agents.startup_logs_overflowed, workspace_agents.subsystem, workspace_agents.startup_script_behavior, workspace_agents.peer_connections
 FROM
 	workspace_agents
 JOIN
@@ -5688,6 +8709,7 @@ func (q *sqlQuerier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Co
 			&i.StartupLogsOverflowed,
 			&i.Subsystem,
 			&i.StartupScriptBehavior,
+			&i.PeerConnections,
 		); err != nil {
 			return nil, err
 		}
@@ -5728,7 +8750,7 @@ INSERT INTO
 		shutdown_script_timeout_seconds
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) RETURNING id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) RETURNING id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, startup_logs_length, startup_logs_overflowed, subsystem, startup_script_behavior, peer_connections
 `
 
 type InsertWorkspaceAgentParams struct {
@@ -5812,6 +8834,7 @@ func (q *sqlQuerier) InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspa
 		&i.StartupLogsOverflowed,
 		&i.Subsystem,
 		&i.StartupScriptBehavior,
+		&i.PeerConnections,
 	)
 	return i, err
 }
@@ -5997,6 +9020,25 @@ func (q *sqlQuerier) UpdateWorkspaceAgentMetadata(ctx context.Context, arg Updat
 	return err
 }
 
+const updateWorkspaceAgentPeerConnectionsByID = `-- name: UpdateWorkspaceAgentPeerConnectionsByID :exec
+UPDATE
+	workspace_agents
+SET
+	peer_connections = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceAgentPeerConnectionsByIDParams struct {
+	ID              uuid.UUID       `db:"id" json:"id"`
+	PeerConnections json.RawMessage `db:"peer_connections" json:"peer_connections"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAgentPeerConnectionsByID(ctx context.Context, arg UpdateWorkspaceAgentPeerConnectionsByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentPeerConnectionsByID, arg.ID, arg.PeerConnections)
+	return err
+}
+
 const updateWorkspaceAgentStartupByID = `-- name: UpdateWorkspaceAgentStartupByID :exec
 UPDATE
 	workspace_agents
@@ -6470,7 +9512,7 @@ func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWor
 }
 
 const getWorkspaceAppByAgentIDAndSlug = `-- name: GetWorkspaceAppByAgentIDAndSlug :one
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = $1 AND slug = $2
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, sharing_groups FROM workspace_apps WHERE agent_id = $1 AND slug = $2
 `
 
 type GetWorkspaceAppByAgentIDAndSlugParams struct {
@@ -6497,12 +9539,13 @@ func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg Ge
 		&i.SharingLevel,
 		&i.Slug,
 		&i.External,
+		pq.Array(&i.SharingGroups),
 	)
 	return i, err
 }
 
 const getWorkspaceAppsByAgentID = `-- name: GetWorkspaceAppsByAgentID :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, sharing_groups FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error) {
@@ -6530,6 +9573,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 			&i.SharingLevel,
 			&i.Slug,
 			&i.External,
+			pq.Array(&i.SharingGroups),
 		); err != nil {
 			return nil, err
 		}
@@ -6545,7 +9589,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 }
 
 const getWorkspaceAppsByAgentIDs = `-- name: GetWorkspaceAppsByAgentIDs :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, sharing_groups FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error) {
@@ -6573,6 +9617,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 			&i.SharingLevel,
 			&i.Slug,
 			&i.External,
+			pq.Array(&i.SharingGroups),
 		); err != nil {
 			return nil, err
 		}
@@ -6588,7 +9633,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 }
 
 const getWorkspaceAppsCreatedAfter = `-- name: GetWorkspaceAppsCreatedAfter :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, sharing_groups FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error) {
@@ -6616,6 +9661,7 @@ func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt
 			&i.SharingLevel,
 			&i.Slug,
 			&i.External,
+			pq.Array(&i.SharingGroups),
 		); err != nil {
 			return nil, err
 		}
@@ -6647,10 +9693,11 @@ INSERT INTO
         healthcheck_url,
         healthcheck_interval,
         healthcheck_threshold,
-        health
+        health,
+        sharing_groups
     )
 VALUES
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, sharing_groups
 `
 
 type InsertWorkspaceAppParams struct {
@@ -6669,6 +9716,7 @@ type InsertWorkspaceAppParams struct {
 	HealthcheckInterval  int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
 	HealthcheckThreshold int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
 	Health               WorkspaceAppHealth `db:"health" json:"health"`
+	SharingGroups        []string           `db:"sharing_groups" json:"sharing_groups"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error) {
@@ -6688,6 +9736,7 @@ func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspace
 		arg.HealthcheckInterval,
 		arg.HealthcheckThreshold,
 		arg.Health,
+		pq.Array(arg.SharingGroups),
 	)
 	var i WorkspaceApp
 	err := row.Scan(
@@ -6706,6 +9755,7 @@ func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspace
 		&i.SharingLevel,
 		&i.Slug,
 		&i.External,
+		pq.Array(&i.SharingGroups),
 	)
 	return i, err
 }
@@ -6729,6 +9779,310 @@ func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg Updat
 	return err
 }
 
+const deleteExpiredWorkspaceAppSecurityKeys = `-- name: DeleteExpiredWorkspaceAppSecurityKeys :exec
+DELETE FROM
+	workspace_app_security_keys
+WHERE
+	expires_at <= $1 :: timestamptz
+`
+
+func (q *sqlQuerier) DeleteExpiredWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredWorkspaceAppSecurityKeys, now)
+	return err
+}
+
+const expireWorkspaceAppSecurityKeys = `-- name: ExpireWorkspaceAppSecurityKeys :exec
+UPDATE
+	workspace_app_security_keys
+SET
+	expires_at = $1 :: timestamptz
+WHERE
+	expires_at IS NULL AND starts_at < $2 :: timestamptz
+`
+
+type ExpireWorkspaceAppSecurityKeysParams struct {
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+	StartsAt  time.Time `db:"starts_at" json:"starts_at"`
+}
+
+// Sets the expiry of the keys that were replaced by a key starting at
+// starts_at.
+func (q *sqlQuerier) ExpireWorkspaceAppSecurityKeys(ctx context.Context, arg ExpireWorkspaceAppSecurityKeysParams) error {
+	_, err := q.db.ExecContext(ctx, expireWorkspaceAppSecurityKeys, arg.ExpiresAt, arg.StartsAt)
+	return err
+}
+
+const getWorkspaceAppSecurityKeys = `-- name: GetWorkspaceAppSecurityKeys :many
+SELECT
+	id, secret, created_at, starts_at, expires_at
+FROM
+	workspace_app_security_keys
+WHERE
+	expires_at IS NULL OR expires_at > $1 :: timestamptz
+ORDER BY
+	starts_at DESC
+`
+
+// Returns the keys that have not expired, most recently started first.
+func (q *sqlQuerier) GetWorkspaceAppSecurityKeys(ctx context.Context, now time.Time) ([]WorkspaceAppSecurityKey, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAppSecurityKeys, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAppSecurityKey
+	for rows.Next() {
+		var i WorkspaceAppSecurityKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Secret,
+			&i.CreatedAt,
+			&i.StartsAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceAppSecurityKey = `-- name: InsertWorkspaceAppSecurityKey :one
+INSERT INTO
+	workspace_app_security_keys (id, secret, created_at, starts_at)
+VALUES
+	($1, $2, $3, $4) RETURNING id, secret, created_at, starts_at, expires_at
+`
+
+type InsertWorkspaceAppSecurityKeyParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Secret    string    `db:"secret" json:"secret"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	StartsAt  time.Time `db:"starts_at" json:"starts_at"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAppSecurityKey(ctx context.Context, arg InsertWorkspaceAppSecurityKeyParams) (WorkspaceAppSecurityKey, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceAppSecurityKey,
+		arg.ID,
+		arg.Secret,
+		arg.CreatedAt,
+		arg.StartsAt,
+	)
+	var i WorkspaceAppSecurityKey
+	err := row.Scan(
+		&i.ID,
+		&i.Secret,
+		&i.CreatedAt,
+		&i.StartsAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const deleteOldWorkspaceAppUsageRollups = `-- name: DeleteOldWorkspaceAppUsageRollups :exec
+DELETE FROM workspace_app_usage_rollups WHERE bucket < NOW() - INTERVAL '90 days'
+`
+
+func (q *sqlQuerier) DeleteOldWorkspaceAppUsageRollups(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOldWorkspaceAppUsageRollups)
+	return err
+}
+
+const getTemplateActiveVersionApps = `-- name: GetTemplateActiveVersionApps :many
+SELECT DISTINCT ON (templates.id, workspace_apps.slug)
+	templates.id AS template_id,
+	workspace_apps.slug,
+	workspace_apps.display_name,
+	workspace_apps.icon
+FROM
+	templates
+JOIN
+	template_versions ON template_versions.id = templates.active_version_id
+JOIN
+	workspace_resources ON workspace_resources.job_id = template_versions.job_id
+JOIN
+	workspace_agents ON workspace_agents.resource_id = workspace_resources.id
+JOIN
+	workspace_apps ON workspace_apps.agent_id = workspace_agents.id
+WHERE
+	templates.deleted = false
+	AND (
+		cardinality($1 :: uuid[]) = 0
+		OR templates.id = ANY($1 :: uuid[])
+	)
+ORDER BY
+	templates.id, workspace_apps.slug
+`
+
+type GetTemplateActiveVersionAppsRow struct {
+	TemplateID  uuid.UUID `db:"template_id" json:"template_id"`
+	Slug        string    `db:"slug" json:"slug"`
+	DisplayName string    `db:"display_name" json:"display_name"`
+	Icon        string    `db:"icon" json:"icon"`
+}
+
+// Returns the apps declared by the active version of each template, so apps
+// that are never used are included in insights. All templates are included if
+// template_ids is empty.
+func (q *sqlQuerier) GetTemplateActiveVersionApps(ctx context.Context, templateIDs []uuid.UUID) ([]GetTemplateActiveVersionAppsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateActiveVersionApps, pq.Array(templateIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateActiveVersionAppsRow
+	for rows.Next() {
+		var i GetTemplateActiveVersionAppsRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.Slug,
+			&i.DisplayName,
+			&i.Icon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceAppUsageInsights = `-- name: GetWorkspaceAppUsageInsights :many
+SELECT
+	workspaces.template_id,
+	workspace_app_usage_rollups.app_slug,
+	COUNT(DISTINCT workspace_app_usage_rollups.user_id) AS unique_users,
+	SUM(workspace_app_usage_rollups.requests) :: bigint AS requests,
+	SUM(workspace_app_usage_rollups.error_requests) :: bigint AS error_requests,
+	SUM(workspace_app_usage_rollups.total_latency_ms) :: bigint AS total_latency_ms,
+	SUM(workspace_app_usage_rollups.bytes_sent) :: bigint AS bytes_sent
+FROM
+	workspace_app_usage_rollups
+JOIN
+	workspaces ON workspaces.id = workspace_app_usage_rollups.workspace_id
+WHERE
+	workspace_app_usage_rollups.bucket >= $1 :: timestamptz
+	AND workspace_app_usage_rollups.bucket < $2 :: timestamptz
+	AND (
+		cardinality($3 :: uuid[]) = 0
+		OR workspaces.template_id = ANY($3 :: uuid[])
+	)
+GROUP BY
+	workspaces.template_id, workspace_app_usage_rollups.app_slug
+ORDER BY
+	workspaces.template_id, workspace_app_usage_rollups.app_slug
+`
+
+type GetWorkspaceAppUsageInsightsParams struct {
+	StartTime   time.Time   `db:"start_time" json:"start_time"`
+	EndTime     time.Time   `db:"end_time" json:"end_time"`
+	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+}
+
+type GetWorkspaceAppUsageInsightsRow struct {
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	AppSlug        string    `db:"app_slug" json:"app_slug"`
+	UniqueUsers    int64     `db:"unique_users" json:"unique_users"`
+	Requests       int64     `db:"requests" json:"requests"`
+	ErrorRequests  int64     `db:"error_requests" json:"error_requests"`
+	TotalLatencyMs int64     `db:"total_latency_ms" json:"total_latency_ms"`
+	BytesSent      int64     `db:"bytes_sent" json:"bytes_sent"`
+}
+
+// Sums the usage of each app by template between start_time (inclusive) and
+// end_time (exclusive). All templates are included if template_ids is empty.
+func (q *sqlQuerier) GetWorkspaceAppUsageInsights(ctx context.Context, arg GetWorkspaceAppUsageInsightsParams) ([]GetWorkspaceAppUsageInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAppUsageInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceAppUsageInsightsRow
+	for rows.Next() {
+		var i GetWorkspaceAppUsageInsightsRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.AppSlug,
+			&i.UniqueUsers,
+			&i.Requests,
+			&i.ErrorRequests,
+			&i.TotalLatencyMs,
+			&i.BytesSent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertWorkspaceAppUsageRollup = `-- name: UpsertWorkspaceAppUsageRollup :exec
+INSERT INTO
+	workspace_app_usage_rollups (
+		bucket,
+		workspace_id,
+		user_id,
+		app_slug,
+		requests,
+		error_requests,
+		total_latency_ms,
+		bytes_sent
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT
+	(bucket, workspace_id, user_id, app_slug)
+DO UPDATE SET
+	requests = workspace_app_usage_rollups.requests + EXCLUDED.requests,
+	error_requests = workspace_app_usage_rollups.error_requests + EXCLUDED.error_requests,
+	total_latency_ms = workspace_app_usage_rollups.total_latency_ms + EXCLUDED.total_latency_ms,
+	bytes_sent = workspace_app_usage_rollups.bytes_sent + EXCLUDED.bytes_sent
+`
+
+type UpsertWorkspaceAppUsageRollupParams struct {
+	Bucket         time.Time `db:"bucket" json:"bucket"`
+	WorkspaceID    uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	AppSlug        string    `db:"app_slug" json:"app_slug"`
+	Requests       int64     `db:"requests" json:"requests"`
+	ErrorRequests  int64     `db:"error_requests" json:"error_requests"`
+	TotalLatencyMs int64     `db:"total_latency_ms" json:"total_latency_ms"`
+	BytesSent      int64     `db:"bytes_sent" json:"bytes_sent"`
+}
+
+// Adds the usage to the rollup of the bucket, creating it if it does not
+// exist.
+func (q *sqlQuerier) UpsertWorkspaceAppUsageRollup(ctx context.Context, arg UpsertWorkspaceAppUsageRollupParams) error {
+	_, err := q.db.ExecContext(ctx, upsertWorkspaceAppUsageRollup,
+		arg.Bucket,
+		arg.WorkspaceID,
+		arg.UserID,
+		arg.AppSlug,
+		arg.Requests,
+		arg.ErrorRequests,
+		arg.TotalLatencyMs,
+		arg.BytesSent,
+	)
+	return err
+}
+
 const getWorkspaceBuildParameters = `-- name: GetWorkspaceBuildParameters :many
 SELECT
     workspace_build_id, name, value
@@ -7313,6 +10667,161 @@ func (q *sqlQuerier) UpdateWorkspaceBuildCostByID(ctx context.Context, arg Updat
 	return i, err
 }
 
+const deleteWorkspacePortShare = `-- name: DeleteWorkspacePortShare :exec
+DELETE FROM
+	workspace_port_shares
+WHERE
+	workspace_id = $1
+	AND agent_name = $2
+	AND port = $3
+`
+
+type DeleteWorkspacePortShareParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	AgentName   string    `db:"agent_name" json:"agent_name"`
+	Port        int32     `db:"port" json:"port"`
+}
+
+func (q *sqlQuerier) DeleteWorkspacePortShare(ctx context.Context, arg DeleteWorkspacePortShareParams) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspacePortShare, arg.WorkspaceID, arg.AgentName, arg.Port)
+	return err
+}
+
+const getWorkspacePortShare = `-- name: GetWorkspacePortShare :one
+SELECT
+	workspace_id, agent_name, port, share_level, group_id, expires_at, created_at, updated_at
+FROM
+	workspace_port_shares
+WHERE
+	workspace_id = $1
+	AND agent_name = $2
+	AND port = $3
+`
+
+type GetWorkspacePortShareParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	AgentName   string    `db:"agent_name" json:"agent_name"`
+	Port        int32     `db:"port" json:"port"`
+}
+
+func (q *sqlQuerier) GetWorkspacePortShare(ctx context.Context, arg GetWorkspacePortShareParams) (WorkspacePortShare, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspacePortShare, arg.WorkspaceID, arg.AgentName, arg.Port)
+	var i WorkspacePortShare
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.AgentName,
+		&i.Port,
+		&i.ShareLevel,
+		&i.GroupID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWorkspacePortSharesByWorkspaceID = `-- name: GetWorkspacePortSharesByWorkspaceID :many
+SELECT
+	workspace_id, agent_name, port, share_level, group_id, expires_at, created_at, updated_at
+FROM
+	workspace_port_shares
+WHERE
+	workspace_id = $1
+ORDER BY
+	agent_name ASC,
+	port ASC
+`
+
+func (q *sqlQuerier) GetWorkspacePortSharesByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]WorkspacePortShare, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacePortSharesByWorkspaceID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspacePortShare
+	for rows.Next() {
+		var i WorkspacePortShare
+		if err := rows.Scan(
+			&i.WorkspaceID,
+			&i.AgentName,
+			&i.Port,
+			&i.ShareLevel,
+			&i.GroupID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertWorkspacePortShare = `-- name: UpsertWorkspacePortShare :one
+INSERT INTO
+	workspace_port_shares (
+		workspace_id,
+		agent_name,
+		port,
+		share_level,
+		group_id,
+		expires_at,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (workspace_id, agent_name, port) DO UPDATE SET
+	share_level = $4,
+	group_id = $5,
+	expires_at = $6,
+	updated_at = $8
+RETURNING workspace_id, agent_name, port, share_level, group_id, expires_at, created_at, updated_at
+`
+
+type UpsertWorkspacePortShareParams struct {
+	WorkspaceID uuid.UUID      `db:"workspace_id" json:"workspace_id"`
+	AgentName   string         `db:"agent_name" json:"agent_name"`
+	Port        int32          `db:"port" json:"port"`
+	ShareLevel  PortShareLevel `db:"share_level" json:"share_level"`
+	GroupID     uuid.NullUUID  `db:"group_id" json:"group_id"`
+	ExpiresAt   sql.NullTime   `db:"expires_at" json:"expires_at"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertWorkspacePortShare(ctx context.Context, arg UpsertWorkspacePortShareParams) (WorkspacePortShare, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspacePortShare,
+		arg.WorkspaceID,
+		arg.AgentName,
+		arg.Port,
+		arg.ShareLevel,
+		arg.GroupID,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i WorkspacePortShare
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.AgentName,
+		&i.Port,
+		&i.ShareLevel,
+		&i.GroupID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getWorkspaceResourceByID = `-- name: GetWorkspaceResourceByID :one
 SELECT
 	id, created_at, job_id, transition, type, name, hide, icon, instance_type, daily_cost
@@ -0,0 +1,21 @@
+-- name: InsertAPIKeyImpersonation :one
+INSERT INTO
+	api_key_impersonations (
+		api_key_id,
+		impersonator_id,
+		created_at
+	)
+VALUES
+	($1, $2, $3)
+RETURNING *;
+
+-- name: GetAPIKeyImpersonationByAPIKeyID :one
+SELECT
+	api_key_impersonations.*,
+	users.username AS impersonator_username
+FROM
+	api_key_impersonations
+INNER JOIN
+	users ON users.id = api_key_impersonations.impersonator_id
+WHERE
+	api_key_id = $1;
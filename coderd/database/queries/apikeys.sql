@@ -30,6 +30,22 @@ SELECT * FROM api_keys WHERE login_type = $1;
 -- name: GetAPIKeysByUserID :many
 SELECT * FROM api_keys WHERE login_type = $1 AND user_id = $2;
 
+-- name: GetSessionAPIKeysByUserID :many
+-- Sessions are the unexpired keys created by logging in, as opposed to tokens,
+-- the named keys that are handed to workspaces and the keys issued to OAuth2
+-- applications.
+SELECT
+	*
+FROM
+	api_keys
+WHERE
+	user_id = $1 AND
+	login_type NOT IN ('token'::login_type, 'oauth2_provider_app'::login_type) AND
+	token_name = '' AND
+	expires_at > now()
+ORDER BY
+	last_used DESC;
+
 -- name: InsertAPIKey :one
 INSERT INTO
 	api_keys (
@@ -44,7 +60,8 @@ INSERT INTO
 		updated_at,
 		login_type,
 		scope,
-		token_name
+		token_name,
+		user_agent
 	)
 VALUES
 	(@id,
@@ -53,7 +70,7 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE @lifetime_seconds::bigint
 	 END
-	 , @hashed_secret, @ip_address, @user_id, @last_used, @expires_at, @created_at, @updated_at, @login_type, @scope, @token_name) RETURNING *;
+	 , @hashed_secret, @ip_address, @user_id, @last_used, @expires_at, @created_at, @updated_at, @login_type, @scope, @token_name, @user_agent) RETURNING *;
 
 -- name: UpdateAPIKeyByID :exec
 UPDATE
@@ -83,3 +100,16 @@ DELETE FROM
 	api_keys
 WHERE
 	user_id = $1;
+
+-- name: DeleteSessionAPIKeysByUserID :many
+-- Deletes all sessions of the user except the one with the given ID, so users
+-- can sign out everywhere else. The deleted sessions are returned to audit
+-- them.
+DELETE FROM
+	api_keys
+WHERE
+	user_id = @user_id AND
+	login_type NOT IN ('token'::login_type, 'oauth2_provider_app'::login_type) AND
+	token_name = '' AND
+	id != @except_id
+RETURNING *;
@@ -0,0 +1,144 @@
+-- name: GetDERPRegions :many
+SELECT
+	*
+FROM
+	derp_regions
+ORDER BY
+	region_id ASC;
+
+-- name: GetDERPRegionByID :one
+SELECT
+	*
+FROM
+	derp_regions
+WHERE
+	region_id = $1
+LIMIT
+	1;
+
+-- name: InsertDERPRegion :one
+INSERT INTO
+	derp_regions (
+		id,
+		region_id,
+		region_code,
+		region_name,
+		nodes,
+		avoid,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- name: UpdateDERPRegionByID :one
+UPDATE
+	derp_regions
+SET
+	region_code = $2,
+	region_name = $3,
+	nodes = $4,
+	avoid = $5,
+	updated_at = $6
+WHERE
+	region_id = $1
+RETURNING *;
+
+-- name: UpdateDERPRegionHealthByID :one
+UPDATE
+	derp_regions
+SET
+	healthy = $2,
+	health_error = $3,
+	last_health_check_at = $4
+WHERE
+	region_id = $1
+RETURNING *;
+
+-- name: DeleteDERPRegionByID :exec
+DELETE FROM
+	derp_regions
+WHERE
+	region_id = $1;
+
+-- name: GetDERPRegionPolicies :many
+SELECT
+	*
+FROM
+	derp_region_policies
+ORDER BY
+	created_at ASC;
+
+-- name: GetDERPRegionPolicyByID :one
+SELECT
+	*
+FROM
+	derp_region_policies
+WHERE
+	id = $1
+LIMIT
+	1;
+
+-- name: GetDERPRegionPolicyByTemplateID :one
+SELECT
+	*
+FROM
+	derp_region_policies
+WHERE
+	template_id = $1
+LIMIT
+	1;
+
+-- name: GetDERPRegionPoliciesByUserID :many
+-- Returns the policies of all groups the user is a member of, including
+-- the "Everyone" group of each of their organizations.
+SELECT
+	*
+FROM
+	derp_region_policies
+WHERE
+	group_id IN (
+		SELECT
+			group_id
+		FROM
+			group_members
+		WHERE
+			group_members.user_id = @user_id
+		UNION
+		-- The "Everyone" group shares its ID with the organization.
+		SELECT
+			organization_id
+		FROM
+			organization_members
+		WHERE
+			organization_members.user_id = @user_id
+	);
+
+-- name: InsertDERPRegionPolicy :one
+INSERT INTO
+	derp_region_policies (
+		id,
+		group_id,
+		template_id,
+		region_ids,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: UpdateDERPRegionPolicyByID :one
+UPDATE
+	derp_region_policies
+SET
+	region_ids = $2,
+	updated_at = $3
+WHERE
+	id = $1
+RETURNING *;
+
+-- name: DeleteDERPRegionPolicyByID :exec
+DELETE FROM
+	derp_region_policies
+WHERE
+	id = $1;
@@ -0,0 +1,221 @@
+-- name: GetOAuth2ProviderApps :many
+SELECT * FROM oauth2_provider_apps ORDER BY (name, id) ASC;
+
+-- name: GetOAuth2ProviderAppByID :one
+SELECT * FROM oauth2_provider_apps WHERE id = $1;
+
+-- name: InsertOAuth2ProviderApp :one
+INSERT INTO oauth2_provider_apps (
+	id,
+	created_at,
+	updated_at,
+	name,
+	icon,
+	callback_url
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6
+) RETURNING *;
+
+-- name: UpdateOAuth2ProviderAppByID :one
+UPDATE oauth2_provider_apps SET
+	updated_at = $2,
+	name = $3,
+	icon = $4,
+	callback_url = $5
+WHERE id = $1 RETURNING *;
+
+-- name: DeleteOAuth2ProviderAppByID :exec
+DELETE FROM oauth2_provider_apps WHERE id = $1;
+
+-- name: GetOAuth2ProviderAppSecretByID :one
+SELECT * FROM oauth2_provider_app_secrets WHERE id = $1;
+
+-- name: GetOAuth2ProviderAppSecretsByAppID :many
+SELECT * FROM oauth2_provider_app_secrets WHERE app_id = $1 ORDER BY (created_at, id) ASC;
+
+-- name: GetOAuth2ProviderAppSecretByPrefix :one
+SELECT * FROM oauth2_provider_app_secrets WHERE secret_prefix = $1;
+
+-- name: InsertOAuth2ProviderAppSecret :one
+INSERT INTO oauth2_provider_app_secrets (
+	id,
+	created_at,
+	secret_prefix,
+	hashed_secret,
+	display_secret,
+	app_id
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6
+) RETURNING *;
+
+-- name: UpdateOAuth2ProviderAppSecretByID :one
+UPDATE oauth2_provider_app_secrets SET
+	last_used_at = $2
+WHERE id = $1 RETURNING *;
+
+-- name: DeleteOAuth2ProviderAppSecretByID :exec
+DELETE FROM oauth2_provider_app_secrets WHERE id = $1;
+
+-- name: GetOAuth2ProviderAppCodeByID :one
+SELECT * FROM oauth2_provider_app_codes WHERE id = $1;
+
+-- name: GetOAuth2ProviderAppCodeByPrefix :one
+SELECT * FROM oauth2_provider_app_codes WHERE secret_prefix = $1;
+
+-- name: InsertOAuth2ProviderAppCode :one
+INSERT INTO oauth2_provider_app_codes (
+	id,
+	created_at,
+	expires_at,
+	secret_prefix,
+	hashed_secret,
+	app_id,
+	user_id,
+	scope,
+	redirect_uri,
+	code_challenge
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9,
+	$10
+) RETURNING *;
+
+-- name: DeleteOAuth2ProviderAppCodeByID :one
+-- Returns no rows if the code was already deleted, so a code that is redeemed
+-- concurrently is only exchanged once.
+DELETE FROM oauth2_provider_app_codes WHERE id = $1 RETURNING id;
+
+-- name: DeleteOAuth2ProviderAppCodesByAppAndUserID :exec
+DELETE FROM oauth2_provider_app_codes WHERE app_id = $1 AND user_id = $2;
+
+-- name: DeleteExpiredOAuth2ProviderAppCodes :exec
+-- Codes are deleted once they are exchanged, this cleans up the ones that
+-- never were.
+DELETE FROM oauth2_provider_app_codes WHERE expires_at < NOW();
+
+-- name: GetOAuth2ProviderAppTokenByPrefix :one
+SELECT * FROM oauth2_provider_app_tokens WHERE hash_prefix = $1;
+
+-- name: InsertOAuth2ProviderAppToken :one
+INSERT INTO oauth2_provider_app_tokens (
+	id,
+	created_at,
+	expires_at,
+	hash_prefix,
+	refresh_hash,
+	app_secret_id,
+	api_key_id
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7
+) RETURNING *;
+
+-- name: DeleteOAuth2ProviderAppTokenByID :one
+-- Returns no rows if the token was already deleted, so a refresh token that is
+-- used concurrently is only exchanged once.
+DELETE FROM oauth2_provider_app_tokens WHERE id = $1 RETURNING id;
+
+-- name: DeleteOAuth2ProviderAppTokensByAppAndUserID :exec
+-- Deleting the API keys deletes the tokens as well through the foreign key.
+DELETE FROM
+	api_keys
+USING
+	oauth2_provider_app_tokens, oauth2_provider_app_secrets
+WHERE
+	oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
+	AND oauth2_provider_app_tokens.api_key_id = api_keys.id
+	AND oauth2_provider_app_secrets.app_id = $1
+	AND api_keys.user_id = $2;
+
+-- name: GetOAuth2ProviderAppsByUserID :many
+-- Returns the applications the user has authorized, which are the ones holding
+-- tokens on behalf of the user.
+SELECT
+	*
+FROM
+	oauth2_provider_apps
+WHERE
+	id IN (
+		SELECT
+			oauth2_provider_app_secrets.app_id
+		FROM
+			oauth2_provider_app_tokens
+		INNER JOIN oauth2_provider_app_secrets
+			ON oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
+		INNER JOIN api_keys
+			ON api_keys.id = oauth2_provider_app_tokens.api_key_id
+		WHERE
+			api_keys.user_id = @user_id
+	)
+ORDER BY
+	(name, id) ASC;
+
+-- name: GetOAuth2DeviceCodeByPrefix :one
+SELECT * FROM oauth2_device_codes WHERE secret_prefix = $1;
+
+-- name: GetOAuth2DeviceCodeByUserCode :one
+SELECT * FROM oauth2_device_codes WHERE user_code = $1;
+
+-- name: InsertOAuth2DeviceCode :one
+INSERT INTO oauth2_device_codes (
+	id,
+	created_at,
+	expires_at,
+	secret_prefix,
+	hashed_secret,
+	user_code,
+	scope,
+	polling_interval
+) VALUES(
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8
+) RETURNING *;
+
+-- name: UpdateOAuth2DeviceCodeStatusByID :one
+-- Only pending requests can be approved or denied.
+UPDATE oauth2_device_codes SET
+	status = $2,
+	user_id = $3
+WHERE id = $1 AND status = 'pending' RETURNING *;
+
+-- name: UpdateOAuth2DeviceCodePolledByID :one
+UPDATE oauth2_device_codes SET
+	last_polled_at = $2,
+	polling_interval = $3
+WHERE id = $1 RETURNING *;
+
+-- name: DeleteOAuth2DeviceCodeByID :one
+-- Returns no rows if the code was already deleted, so a device code that is
+-- redeemed concurrently is only exchanged once.
+DELETE FROM oauth2_device_codes WHERE id = $1 RETURNING id;
+
+-- name: DeleteExpiredOAuth2DeviceCodes :exec
+DELETE FROM oauth2_device_codes WHERE expires_at < NOW();
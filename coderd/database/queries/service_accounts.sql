@@ -0,0 +1,11 @@
+-- name: InsertServiceAccount :one
+INSERT INTO
+	service_accounts (
+		user_id,
+		organization_id,
+		created_by,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4)
+RETURNING *;
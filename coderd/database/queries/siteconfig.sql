@@ -50,10 +50,3 @@ ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'logo_url';
 
 -- name: GetLogoURL :one
 SELECT value FROM site_configs WHERE key = 'logo_url';
-
--- name: GetAppSecurityKey :one
-SELECT value FROM site_configs WHERE key = 'app_signing_key';
-
--- name: UpsertAppSecurityKey :exec
-INSERT INTO site_configs (key, value) VALUES ('app_signing_key', $1)
-ON CONFLICT (key) DO UPDATE set value = $1 WHERE site_configs.key = 'app_signing_key';
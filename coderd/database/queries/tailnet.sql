@@ -0,0 +1,120 @@
+-- name: UpsertTailnetCoordinator :one
+INSERT INTO
+	tailnet_coordinators (
+		id,
+		heartbeat_at
+	)
+VALUES
+	($1, now() at time zone 'utc')
+ON CONFLICT (id)
+DO UPDATE SET
+	id = $1,
+	heartbeat_at = now() at time zone 'utc'
+RETURNING *;
+
+-- name: GetTailnetCoordinators :many
+SELECT * FROM tailnet_coordinators;
+
+-- name: DeleteTailnetCoordinator :exec
+DELETE FROM tailnet_coordinators WHERE id = $1;
+
+-- name: DeleteExpiredTailnetCoordinators :exec
+-- Deleting a coordinator also deletes its peers and tunnels.
+DELETE FROM tailnet_coordinators WHERE heartbeat_at < $1;
+
+-- name: UpsertTailnetPeer :one
+INSERT INTO
+	tailnet_peers (
+		id,
+		coordinator_id,
+		node,
+		status,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, now() at time zone 'utc')
+ON CONFLICT (id, coordinator_id)
+DO UPDATE SET
+	id = $1,
+	coordinator_id = $2,
+	node = $3,
+	status = $4,
+	updated_at = now() at time zone 'utc'
+RETURNING *;
+
+-- name: UpdateTailnetPeerStatusByCoordinator :exec
+UPDATE
+	tailnet_peers
+SET
+	status = $2
+WHERE
+	coordinator_id = $1;
+
+-- name: DeleteTailnetPeer :one
+DELETE FROM tailnet_peers WHERE id = $1 AND coordinator_id = $2 RETURNING id, coordinator_id;
+
+-- name: CleanTailnetLostPeers :exec
+DELETE FROM tailnet_peers WHERE updated_at < $1 AND status = 'lost'::tailnet_status;
+
+-- name: CleanTailnetLostTunnels :exec
+-- Deletes the tunnels of peers that have been removed, for example because
+-- they were lost.
+DELETE FROM tailnet_tunnels
+WHERE updated_at < $1 AND NOT EXISTS (
+	SELECT 1 FROM tailnet_peers
+	WHERE tailnet_peers.id = tailnet_tunnels.src_id AND tailnet_peers.coordinator_id = tailnet_tunnels.coordinator_id
+);
+
+-- name: GetTailnetPeers :many
+SELECT * FROM tailnet_peers WHERE id = $1;
+
+-- name: UpsertTailnetTunnel :one
+INSERT INTO
+	tailnet_tunnels (
+		coordinator_id,
+		src_id,
+		dst_id,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, now() at time zone 'utc')
+ON CONFLICT (coordinator_id, src_id, dst_id)
+DO UPDATE SET
+	coordinator_id = $1,
+	src_id = $2,
+	dst_id = $3,
+	updated_at = now() at time zone 'utc'
+RETURNING *;
+
+-- name: DeleteTailnetTunnel :one
+DELETE
+FROM tailnet_tunnels
+WHERE coordinator_id = $1 AND src_id = $2 AND dst_id = $3
+RETURNING coordinator_id, src_id, dst_id;
+
+-- name: DeleteAllTailnetTunnels :exec
+DELETE
+FROM tailnet_tunnels
+WHERE coordinator_id = $1 AND src_id = $2;
+
+-- name: GetTailnetTunnelPeerIDs :many
+-- Returns the peers that have a tunnel to or from the given peer, and the
+-- coordinators that hold the tunnels.
+SELECT dst_id as peer_id, coordinator_id, updated_at
+FROM tailnet_tunnels
+WHERE tailnet_tunnels.src_id = $1
+UNION
+SELECT src_id as peer_id, coordinator_id, updated_at
+FROM tailnet_tunnels
+WHERE tailnet_tunnels.dst_id = $1;
+
+-- name: GetTailnetTunnelPeerBindings :many
+-- Returns the mappings of all peers that have a tunnel to or from the given
+-- peer.
+SELECT tailnet_peers.id AS peer_id, coordinator_id, updated_at, node, status
+FROM tailnet_peers
+WHERE id IN (
+	SELECT dst_id FROM tailnet_tunnels WHERE tailnet_tunnels.src_id = $1
+	UNION
+	SELECT src_id FROM tailnet_tunnels WHERE tailnet_tunnels.dst_id = $1
+);
@@ -0,0 +1,63 @@
+-- name: GetTemplateGitSourceByTemplateID :one
+SELECT
+	*
+FROM
+	template_git_sources
+WHERE
+	template_id = $1;
+
+-- name: GetTemplateGitSources :many
+-- Returns the git sources of all templates that have not been deleted.
+SELECT
+	template_git_sources.*
+FROM
+	template_git_sources
+JOIN
+	templates ON templates.id = template_git_sources.template_id
+WHERE
+	templates.deleted = false;
+
+-- name: UpsertTemplateGitSource :one
+INSERT INTO
+	template_git_sources (
+		template_id,
+		created_at,
+		updated_at,
+		created_by,
+		repository_url,
+		ref,
+		subdirectory,
+		git_auth_provider_id,
+		poll_interval,
+		webhook_secret
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (template_id) DO UPDATE SET
+	updated_at = $3,
+	created_by = $4,
+	repository_url = $5,
+	ref = $6,
+	subdirectory = $7,
+	git_auth_provider_id = $8,
+	poll_interval = $9,
+	webhook_secret = $10,
+	-- Force the next sync to create a version when the source changes.
+	last_commit_sha = ''
+RETURNING *;
+
+-- name: UpdateTemplateGitSourceSyncStatus :exec
+UPDATE
+	template_git_sources
+SET
+	last_commit_sha = $2,
+	last_synced_at = $3,
+	last_error = $4
+WHERE
+	template_id = $1;
+
+-- name: DeleteTemplateGitSourceByTemplateID :exec
+DELETE FROM
+	template_git_sources
+WHERE
+	template_id = $1;
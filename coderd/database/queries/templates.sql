@@ -104,7 +104,9 @@ SET
 	name = $4,
 	icon = $5,
 	display_name = $6,
-	allow_user_cancel_workspace_jobs = $7
+	allow_user_cancel_workspace_jobs = $7,
+	require_active_version = $8,
+	max_port_share_level = $9
 WHERE
 	id = $1
 RETURNING
@@ -0,0 +1,55 @@
+-- name: GetTemplateVersionPresetByID :one
+SELECT
+	*
+FROM
+	template_version_presets
+WHERE
+	id = $1;
+
+-- name: GetTemplateVersionPresetsByTemplateVersionID :many
+SELECT
+	*
+FROM
+	template_version_presets
+WHERE
+	template_version_id = $1
+ORDER BY
+	name ASC;
+
+-- name: GetTemplateVersionPresetParametersByTemplateVersionID :many
+SELECT
+	template_version_preset_parameters.*
+FROM
+	template_version_preset_parameters
+JOIN
+	template_version_presets ON template_version_presets.id = template_version_preset_parameters.preset_id
+WHERE
+	template_version_presets.template_version_id = $1
+ORDER BY
+	template_version_preset_parameters.name ASC;
+
+-- name: InsertTemplateVersionPreset :one
+INSERT INTO
+	template_version_presets (
+		id,
+		template_version_id,
+		name,
+		description,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: InsertTemplateVersionPresetParameters :exec
+INSERT INTO
+	template_version_preset_parameters (preset_id, name, value)
+SELECT
+	@preset_id :: uuid AS preset_id,
+	unnest(@name :: text[]) AS name,
+	unnest(@value :: text[]) AS value;
+
+-- name: DeleteTemplateVersionPresetsByTemplateVersionID :exec
+DELETE FROM
+	template_version_presets
+WHERE
+	template_version_id = $1;
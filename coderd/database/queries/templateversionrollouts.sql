@@ -0,0 +1,136 @@
+-- name: GetTemplateVersionRolloutByID :one
+SELECT
+	*
+FROM
+	template_version_rollouts
+WHERE
+	id = $1;
+
+-- name: GetTemplateVersionRolloutsByTemplateID :many
+SELECT
+	*
+FROM
+	template_version_rollouts
+WHERE
+	template_id = $1
+ORDER BY
+	created_at DESC;
+
+-- name: GetRunningTemplateVersionRollouts :many
+-- Returns the running rollouts of all templates that have not been deleted.
+SELECT
+	template_version_rollouts.*
+FROM
+	template_version_rollouts
+JOIN
+	templates ON templates.id = template_version_rollouts.template_id
+WHERE
+	template_version_rollouts.status = 'running'
+	AND templates.deleted = false
+ORDER BY
+	template_version_rollouts.created_at ASC;
+
+-- name: InsertTemplateVersionRollout :one
+INSERT INTO
+	template_version_rollouts (
+		id,
+		template_id,
+		template_version_id,
+		created_by,
+		created_at,
+		updated_at,
+		percentage,
+		group_ids,
+		batch_size,
+		failure_threshold_percent
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: UpdateTemplateVersionRolloutStatusByID :one
+UPDATE
+	template_version_rollouts
+SET
+	status = $2,
+	paused_reason = $3,
+	updated_at = $4
+WHERE
+	id = $1
+RETURNING *;
+
+-- name: GetTemplateVersionRolloutCandidates :many
+-- Returns the IDs of the template's workspaces whose latest build is not on
+-- the template version, ordered by ID. When group IDs are given, only
+-- workspaces owned by members of those groups are returned. The "Everyone"
+-- group shares its ID with the organization and matches every workspace.
+SELECT
+	workspaces.id
+FROM
+	workspaces
+JOIN
+	workspace_builds ON workspace_builds.workspace_id = workspaces.id
+WHERE
+	workspaces.template_id = @template_id
+	AND workspaces.deleted = false
+	AND workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds
+		WHERE
+			workspace_builds.workspace_id = workspaces.id
+	)
+	AND workspace_builds.template_version_id != @template_version_id
+	AND (
+		cardinality(@group_ids :: uuid[]) = 0
+		OR workspaces.organization_id = ANY(@group_ids :: uuid[])
+		OR workspaces.owner_id IN (
+			SELECT
+				user_id
+			FROM
+				group_members
+			WHERE
+				group_id = ANY(@group_ids :: uuid[])
+		)
+	)
+ORDER BY
+	workspaces.id ASC;
+
+-- name: GetTemplateVersionRolloutWorkspacesByRolloutID :many
+SELECT
+	template_version_rollout_workspaces.*,
+	workspaces.name AS workspace_name,
+	users.username AS workspace_owner_name
+FROM
+	template_version_rollout_workspaces
+JOIN
+	workspaces ON workspaces.id = template_version_rollout_workspaces.workspace_id
+JOIN
+	users ON users.id = workspaces.owner_id
+WHERE
+	template_version_rollout_workspaces.rollout_id = $1
+ORDER BY
+	template_version_rollout_workspaces.workspace_id ASC;
+
+-- name: InsertTemplateVersionRolloutWorkspace :exec
+INSERT INTO
+	template_version_rollout_workspaces (
+		rollout_id,
+		workspace_id,
+		updated_at
+	)
+VALUES
+	($1, $2, $3);
+
+-- name: UpdateTemplateVersionRolloutWorkspace :exec
+UPDATE
+	template_version_rollout_workspaces
+SET
+	build_id = $3,
+	status = $4,
+	error = $5,
+	updated_at = $6
+WHERE
+	rollout_id = $1
+	AND workspace_id = $2;
@@ -112,6 +112,89 @@ SET
 WHERE
 	job_id = $1;
 
+-- name: UpdateTemplateVersionStateByID :exec
+UPDATE
+	template_versions
+SET
+	state = $2,
+	updated_at = $3
+WHERE
+	id = $1;
+
+-- name: ArchiveUnusedTemplateVersions :many
+-- Archives every version of a template that is not the active version and is
+-- not used by the latest build of any workspace. If template_version_ids is
+-- not empty, only those versions are considered. Files that are no longer
+-- referenced by a provisioner job, other than the imports of archived
+-- versions, are deleted to reclaim storage.
+WITH archived_versions AS (
+	UPDATE
+		template_versions
+	SET
+		state = 'archived'::template_version_state,
+		updated_at = @updated_at
+	FROM
+		templates
+	WHERE
+		template_versions.template_id = @template_id :: uuid
+		AND templates.id = template_versions.template_id
+		AND template_versions.id != templates.active_version_id
+		AND template_versions.state != 'archived'::template_version_state
+		AND (
+			cardinality(@template_version_ids :: uuid[]) = 0
+			OR template_versions.id = ANY(@template_version_ids :: uuid[])
+		)
+		AND template_versions.id NOT IN (
+			SELECT DISTINCT ON (workspace_builds.workspace_id)
+				workspace_builds.template_version_id
+			FROM
+				workspace_builds
+			JOIN
+				workspaces ON workspaces.id = workspace_builds.workspace_id
+			WHERE
+				workspaces.template_id = @template_id :: uuid
+				AND workspaces.deleted = false
+			ORDER BY
+				workspace_builds.workspace_id, workspace_builds.build_number DESC
+		)
+	RETURNING
+		template_versions.id, template_versions.job_id
+), deleted_files AS (
+	DELETE FROM
+		files
+	WHERE
+		files.id IN (
+			SELECT
+				provisioner_jobs.file_id
+			FROM
+				provisioner_jobs
+			JOIN
+				archived_versions ON archived_versions.job_id = provisioner_jobs.id
+		)
+		-- Workspace builds and dry runs reference the file as well, only
+		-- the imports of archived versions may still use it.
+		AND files.id NOT IN (
+			SELECT
+				provisioner_jobs.file_id
+			FROM
+				provisioner_jobs
+			WHERE
+				provisioner_jobs.id NOT IN (SELECT job_id FROM archived_versions)
+				AND provisioner_jobs.id NOT IN (
+					SELECT
+						template_versions.job_id
+					FROM
+						template_versions
+					WHERE
+						template_versions.state = 'archived'::template_version_state
+				)
+		)
+)
+SELECT
+	id
+FROM
+	archived_versions;
+
 -- name: GetPreviousTemplateVersion :one
 SELECT
 	*
@@ -22,10 +22,11 @@ INSERT INTO
 		linked_id,
 		oauth_access_token,
 		oauth_refresh_token,
-		oauth_expiry
+		oauth_expiry,
+		oidc_provider_id
 	)
 VALUES
-	( $1, $2, $3, $4, $5, $6 ) RETURNING *;
+	( $1, $2, $3, $4, $5, $6, $7 ) RETURNING *;
 
 -- name: UpdateUserLinkedID :one
 UPDATE
@@ -0,0 +1,40 @@
+-- name: GetUserLoginFailuresByUserID :one
+SELECT
+	*
+FROM
+	user_login_failures
+WHERE
+	user_id = $1;
+
+-- name: InsertUserLoginFailure :one
+-- Records a failed login, counting up from any previous failures.
+INSERT INTO
+	user_login_failures (
+		user_id,
+		failed_attempts,
+		last_failed_at
+	)
+VALUES
+	($1, 1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+	failed_attempts = user_login_failures.failed_attempts + 1,
+	last_failed_at = $2
+RETURNING *;
+
+-- name: UpdateUserLoginLockout :one
+-- Locks the user out. Failures are counted from zero again once the lockout
+-- ends.
+UPDATE
+	user_login_failures
+SET
+	failed_attempts = 0,
+	locked_until = $2
+WHERE
+	user_id = $1
+RETURNING *;
+
+-- name: DeleteUserLoginFailures :exec
+DELETE FROM
+	user_login_failures
+WHERE
+	user_id = $1;
@@ -0,0 +1,46 @@
+-- name: GetUserTOTPByUserID :one
+SELECT
+	*
+FROM
+	user_totp
+WHERE
+	user_id = $1;
+
+-- name: UpsertUserTOTP :one
+-- Starts a new enrollment. Any previous enrollment of the user is replaced
+-- and stays disabled until it is verified.
+INSERT INTO
+	user_totp (
+		user_id,
+		secret,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $3)
+ON CONFLICT (user_id) DO UPDATE SET
+	secret = $2,
+	enabled = false,
+	hashed_recovery_codes = '{}',
+	last_counter = 0,
+	created_at = $3,
+	updated_at = $3
+RETURNING *;
+
+-- name: UpdateUserTOTP :one
+UPDATE
+	user_totp
+SET
+	enabled = $2,
+	hashed_recovery_codes = $3,
+	last_counter = $4,
+	updated_at = $5
+WHERE
+	user_id = $1
+RETURNING *;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM
+	user_totp
+WHERE
+	user_id = $1;
@@ -34,12 +34,14 @@ WHERE
 	deleted = false;
 
 -- name: GetActiveUserCount :one
+-- Service accounts are not counted, as they are not people.
 SELECT
 	COUNT(*)
 FROM
 	users
 WHERE
-    status = 'active'::user_status AND deleted = false;
+    status = 'active'::user_status AND deleted = false
+	AND NOT EXISTS (SELECT 1 FROM service_accounts WHERE service_accounts.user_id = users.id);
 
 -- name: GetFilteredUserCount :one
 -- This will never count deleted users.
@@ -216,6 +218,9 @@ SELECT
 	-- status is used to enforce 'suspended' users, as all roles are ignored
 	--	when suspended.
 	id, username, status,
+	-- service_account_created_by is only set for service accounts, which
+	-- can only authenticate with tokens.
+	service_accounts.created_by AS service_account_created_by,
 	-- All user roles, including their org roles.
 	array_cat(
 		-- All users are members
@@ -246,5 +251,7 @@ SELECT
 	) :: text[] AS groups
 FROM
 	users
+LEFT JOIN
+	service_accounts ON service_accounts.user_id = users.id
 WHERE
 	id = @user_id;
@@ -95,6 +95,14 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateWorkspaceAgentPeerConnectionsByID :exec
+UPDATE
+	workspace_agents
+SET
+	peer_connections = $2
+WHERE
+	id = $1;
+
 -- name: InsertWorkspaceAgentMetadata :exec
 INSERT INTO
 	workspace_agent_metadata (
@@ -0,0 +1,32 @@
+-- name: GetWorkspaceAppSecurityKeys :many
+-- Returns the keys that have not expired, most recently started first.
+SELECT
+	*
+FROM
+	workspace_app_security_keys
+WHERE
+	expires_at IS NULL OR expires_at > @now :: timestamptz
+ORDER BY
+	starts_at DESC;
+
+-- name: InsertWorkspaceAppSecurityKey :one
+INSERT INTO
+	workspace_app_security_keys (id, secret, created_at, starts_at)
+VALUES
+	($1, $2, $3, $4) RETURNING *;
+
+-- name: ExpireWorkspaceAppSecurityKeys :exec
+-- Sets the expiry of the keys that were replaced by a key starting at
+-- starts_at.
+UPDATE
+	workspace_app_security_keys
+SET
+	expires_at = @expires_at :: timestamptz
+WHERE
+	expires_at IS NULL AND starts_at < @starts_at :: timestamptz;
+
+-- name: DeleteExpiredWorkspaceAppSecurityKeys :exec
+DELETE FROM
+	workspace_app_security_keys
+WHERE
+	expires_at <= @now :: timestamptz;
@@ -0,0 +1,81 @@
+-- name: UpsertWorkspaceAppUsageRollup :exec
+-- Adds the usage to the rollup of the bucket, creating it if it does not
+-- exist.
+INSERT INTO
+	workspace_app_usage_rollups (
+		bucket,
+		workspace_id,
+		user_id,
+		app_slug,
+		requests,
+		error_requests,
+		total_latency_ms,
+		bytes_sent
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT
+	(bucket, workspace_id, user_id, app_slug)
+DO UPDATE SET
+	requests = workspace_app_usage_rollups.requests + EXCLUDED.requests,
+	error_requests = workspace_app_usage_rollups.error_requests + EXCLUDED.error_requests,
+	total_latency_ms = workspace_app_usage_rollups.total_latency_ms + EXCLUDED.total_latency_ms,
+	bytes_sent = workspace_app_usage_rollups.bytes_sent + EXCLUDED.bytes_sent;
+
+-- name: GetWorkspaceAppUsageInsights :many
+-- Sums the usage of each app by template between start_time (inclusive) and
+-- end_time (exclusive). All templates are included if template_ids is empty.
+SELECT
+	workspaces.template_id,
+	workspace_app_usage_rollups.app_slug,
+	COUNT(DISTINCT workspace_app_usage_rollups.user_id) AS unique_users,
+	SUM(workspace_app_usage_rollups.requests) :: bigint AS requests,
+	SUM(workspace_app_usage_rollups.error_requests) :: bigint AS error_requests,
+	SUM(workspace_app_usage_rollups.total_latency_ms) :: bigint AS total_latency_ms,
+	SUM(workspace_app_usage_rollups.bytes_sent) :: bigint AS bytes_sent
+FROM
+	workspace_app_usage_rollups
+JOIN
+	workspaces ON workspaces.id = workspace_app_usage_rollups.workspace_id
+WHERE
+	workspace_app_usage_rollups.bucket >= @start_time :: timestamptz
+	AND workspace_app_usage_rollups.bucket < @end_time :: timestamptz
+	AND (
+		cardinality(@template_ids :: uuid[]) = 0
+		OR workspaces.template_id = ANY(@template_ids :: uuid[])
+	)
+GROUP BY
+	workspaces.template_id, workspace_app_usage_rollups.app_slug
+ORDER BY
+	workspaces.template_id, workspace_app_usage_rollups.app_slug;
+
+-- name: GetTemplateActiveVersionApps :many
+-- Returns the apps declared by the active version of each template, so apps
+-- that are never used are included in insights. All templates are included if
+-- template_ids is empty.
+SELECT DISTINCT ON (templates.id, workspace_apps.slug)
+	templates.id AS template_id,
+	workspace_apps.slug,
+	workspace_apps.display_name,
+	workspace_apps.icon
+FROM
+	templates
+JOIN
+	template_versions ON template_versions.id = templates.active_version_id
+JOIN
+	workspace_resources ON workspace_resources.job_id = template_versions.job_id
+JOIN
+	workspace_agents ON workspace_agents.resource_id = workspace_resources.id
+JOIN
+	workspace_apps ON workspace_apps.agent_id = workspace_agents.id
+WHERE
+	templates.deleted = false
+	AND (
+		cardinality(@template_ids :: uuid[]) = 0
+		OR templates.id = ANY(@template_ids :: uuid[])
+	)
+ORDER BY
+	templates.id, workspace_apps.slug;
+
+-- name: DeleteOldWorkspaceAppUsageRollups :exec
+DELETE FROM workspace_app_usage_rollups WHERE bucket < NOW() - INTERVAL '90 days';
@@ -0,0 +1,49 @@
+-- name: GetWorkspacePortShare :one
+SELECT
+	*
+FROM
+	workspace_port_shares
+WHERE
+	workspace_id = $1
+	AND agent_name = $2
+	AND port = $3;
+
+-- name: GetWorkspacePortSharesByWorkspaceID :many
+SELECT
+	*
+FROM
+	workspace_port_shares
+WHERE
+	workspace_id = $1
+ORDER BY
+	agent_name ASC,
+	port ASC;
+
+-- name: UpsertWorkspacePortShare :one
+INSERT INTO
+	workspace_port_shares (
+		workspace_id,
+		agent_name,
+		port,
+		share_level,
+		group_id,
+		expires_at,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (workspace_id, agent_name, port) DO UPDATE SET
+	share_level = $4,
+	group_id = $5,
+	expires_at = $6,
+	updated_at = $8
+RETURNING *;
+
+-- name: DeleteWorkspacePortShare :exec
+DELETE FROM
+	workspace_port_shares
+WHERE
+	workspace_id = $1
+	AND agent_name = $2
+	AND port = $3;
@@ -0,0 +1,139 @@
+package coderd
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
+)
+
+// @Summary Watch DERP map of the authenticated user
+// @ID watch-derp-map-of-the-authenticated-user
+// @Security CoderSessionToken
+// @Produce text/event-stream
+// @Tags Agents
+// @Success 200 {object} tailcfg.DERPMap
+// @Router /derp-map [get]
+func (api *API) derpMapUpdates(rw http.ResponseWriter, r *http.Request) {
+	apiKey := httpmw.APIKey(r)
+
+	api.serveDERPMapUpdates(rw, r, func(ctx context.Context) (*tailcfg.DERPMap, error) {
+		return (*api.DERPMapProvider.Load()).ForUser(ctx, apiKey.UserID)
+	})
+}
+
+// @Summary Watch DERP map of the authorized workspace agent
+// @ID watch-derp-map-of-the-authorized-workspace-agent
+// @Security CoderSessionToken
+// @Produce text/event-stream
+// @Tags Agents
+// @Success 200 {object} tailcfg.DERPMap
+// @Router /workspaceagents/me/derp-map [get]
+func (api *API) workspaceAgentDERPMapUpdates(rw http.ResponseWriter, r *http.Request) {
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+
+	api.serveDERPMapUpdates(rw, r, func(ctx context.Context) (*tailcfg.DERPMap, error) {
+		return api.agentDERPMap(ctx, workspaceAgent.ID)
+	})
+}
+
+// serveDERPMapUpdates sends the DERP map returned by fetch as a server-sent
+// event, and sends it again whenever it changes.
+func (api *API) serveDERPMapUpdates(rw http.ResponseWriter, r *http.Request, fetch func(ctx context.Context) (*tailcfg.DERPMap, error)) {
+	ctx := r.Context()
+
+	sendEvent, senderClosed, err := httpapi.ServerSentEventSender(rw, r)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error setting up server-sent events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Prevent handler from returning until the sender is closed.
+	defer func() {
+		<-senderClosed
+	}()
+
+	updates, unsubscribe := api.DERPMapWatcher.Subscribe()
+	defer unsubscribe()
+
+	var lastDERPMap *tailcfg.DERPMap
+	for {
+		derpMap, err := fetch(ctx)
+		if err != nil {
+			_ = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeError,
+				Data: codersdk.Response{
+					Message: "Internal error fetching DERP map.",
+					Detail:  err.Error(),
+				},
+			})
+			return
+		}
+		if !reflect.DeepEqual(lastDERPMap, derpMap) {
+			err = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeData,
+				Data: derpMap,
+			})
+			if err != nil {
+				return
+			}
+			lastDERPMap = derpMap
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-senderClosed:
+			return
+		case <-updates:
+		}
+	}
+}
+
+// agentDERPMap returns the DERP map of a workspace agent, which depends on
+// the template of its workspace.
+func (api *API) agentDERPMap(ctx context.Context, agentID uuid.UUID) (*tailcfg.DERPMap, error) {
+	workspace, err := api.Database.GetWorkspaceByAgentID(ctx, agentID)
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace by agent id: %w", err)
+	}
+	derpMap, err := (*api.DERPMapProvider.Load()).ForTemplate(ctx, workspace.TemplateID)
+	if err != nil {
+		return nil, xerrors.Errorf("get derp map for template: %w", err)
+	}
+	return derpMap, nil
+}
+
+// updateAgentConnDERPMap keeps the DERP map of a connection coderd made to a
+// workspace agent up to date until ctx is canceled.
+func (api *API) updateAgentConnDERPMap(ctx context.Context, conn *tailnet.Conn, agentID uuid.UUID) {
+	updates, unsubscribe := api.DERPMapWatcher.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates:
+		}
+		derpMap, err := api.agentDERPMap(ctx, agentID)
+		if err != nil {
+			if ctx.Err() == nil {
+				api.Logger.Warn(ctx, "fetch agent derp map", slog.F("agent_id", agentID), slog.Error(err))
+			}
+			continue
+		}
+		conn.SetDERPMap(derpMap)
+	}
+}
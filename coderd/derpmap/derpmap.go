@@ -0,0 +1,51 @@
+package derpmap
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"tailscale.com/tailcfg"
+)
+
+// PubsubEvent is published whenever the DERP map served to clients or agents
+// may have changed. The payload is empty, subscribers must ask the Provider
+// for the new DERP map.
+const PubsubEvent = "derp_map_updated"
+
+// Provider returns the DERP map handed out to clients and agents. The
+// enterprise implementation restricts the regions using region policies.
+type Provider interface {
+	// ForUser returns the DERP map used by clients that connect to workspace
+	// agents on behalf of the user.
+	ForUser(ctx context.Context, userID uuid.UUID) (*tailcfg.DERPMap, error)
+	// ForTemplate returns the DERP map used by the agents of workspaces
+	// built from the template.
+	ForTemplate(ctx context.Context, templateID uuid.UUID) (*tailcfg.DERPMap, error)
+	// All returns the DERP map with every region, regardless of policies.
+	// It's used to name the regions reported by agents and clients.
+	All(ctx context.Context) (*tailcfg.DERPMap, error)
+}
+
+type agplProvider struct {
+	derpMap *tailcfg.DERPMap
+}
+
+var _ Provider = &agplProvider{}
+
+// NewAGPLProvider returns a Provider that hands out the static DERP map to
+// everyone.
+func NewAGPLProvider(derpMap *tailcfg.DERPMap) Provider {
+	return &agplProvider{derpMap: derpMap}
+}
+
+func (p *agplProvider) ForUser(_ context.Context, _ uuid.UUID) (*tailcfg.DERPMap, error) {
+	return p.derpMap.Clone(), nil
+}
+
+func (p *agplProvider) ForTemplate(_ context.Context, _ uuid.UUID) (*tailcfg.DERPMap, error) {
+	return p.derpMap.Clone(), nil
+}
+
+func (p *agplProvider) All(_ context.Context) (*tailcfg.DERPMap, error) {
+	return p.derpMap.Clone(), nil
+}
@@ -0,0 +1,105 @@
+package derpmap
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database/pubsub"
+	"github.com/coder/retry"
+)
+
+// Watcher shares a single pubsub subscription to DERP map updates between
+// everyone that hands out DERP maps, so an update doesn't cost a subscription
+// per connected client or agent.
+type Watcher struct {
+	logger     slog.Logger
+	generation atomic.Uint64
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+
+	cancel context.CancelFunc
+	closed chan struct{}
+}
+
+// NewWatcher subscribes to DERP map updates in the background until Close is
+// called.
+func NewWatcher(logger slog.Logger, ps pubsub.Pubsub) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{
+		logger:      logger,
+		subscribers: map[chan struct{}]struct{}{},
+		cancel:      cancel,
+		closed:      make(chan struct{}),
+	}
+	go w.run(ctx, ps)
+	return w
+}
+
+func (w *Watcher) run(ctx context.Context, ps pubsub.Pubsub) {
+	defer close(w.closed)
+
+	for retrier := retry.New(time.Second, time.Minute); retrier.Wait(ctx); {
+		cancelSubscribe, err := ps.SubscribeWithErr(PubsubEvent, func(ctx context.Context, _ []byte, err error) {
+			if err != nil {
+				// Dropped messages may have been updates.
+				w.logger.Warn(ctx, "derp map updates may have been dropped", slog.Error(err))
+			}
+			w.notify()
+		})
+		if err != nil {
+			w.logger.Warn(ctx, "subscribe to derp map updates", slog.Error(err))
+			continue
+		}
+		// Updates published before the subscription was set up are missed,
+		// so everyone has to fetch the DERP map again.
+		w.notify()
+		<-ctx.Done()
+		cancelSubscribe()
+		return
+	}
+}
+
+func (w *Watcher) notify() {
+	w.generation.Add(1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for updates := range w.subscribers {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Generation changes whenever the DERP map may have changed. Providers use it
+// to build the DERP map once per update, instead of once per subscriber.
+func (w *Watcher) Generation() uint64 {
+	return w.generation.Load()
+}
+
+// Subscribe returns a channel that receives a value whenever the DERP map may
+// have changed. Updates are coalesced, since subscribers fetch the latest DERP
+// map. The returned function must be called to unsubscribe.
+func (w *Watcher) Subscribe() (<-chan struct{}, func()) {
+	updates := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers[updates] = struct{}{}
+	return updates, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers, updates)
+	}
+}
+
+// Close unsubscribes from DERP map updates.
+func (w *Watcher) Close() {
+	w.cancel()
+	<-w.closed
+}
@@ -0,0 +1,63 @@
+package derpmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database/pubsub"
+	"github.com/coder/coder/coderd/derpmap"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	ps := pubsub.NewInMemory()
+	watcher := derpmap.NewWatcher(slogtest.Make(t, nil), ps)
+	t.Cleanup(watcher.Close)
+
+	// The watcher notifies everyone once it has subscribed.
+	require.Eventually(t, func() bool {
+		return watcher.Generation() > 0
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	first, unsubscribeFirst := watcher.Subscribe()
+	second, unsubscribeSecond := watcher.Subscribe()
+	defer unsubscribeSecond()
+
+	generation := watcher.Generation()
+	err := ps.Publish(derpmap.PubsubEvent, nil)
+	require.NoError(t, err)
+	require.Greater(t, watcher.Generation(), generation)
+	for _, updates := range []<-chan struct{}{first, second} {
+		select {
+		case <-updates:
+		default:
+			t.Fatal("expected an update")
+		}
+	}
+
+	// Updates are coalesced.
+	err = ps.Publish(derpmap.PubsubEvent, nil)
+	require.NoError(t, err)
+	err = ps.Publish(derpmap.PubsubEvent, nil)
+	require.NoError(t, err)
+	<-second
+	select {
+	case <-second:
+		t.Fatal("expected updates to be coalesced")
+	default:
+	}
+
+	unsubscribeFirst()
+	<-first
+	err = ps.Publish(derpmap.PubsubEvent, nil)
+	require.NoError(t, err)
+	select {
+	case <-first:
+		t.Fatal("expected no update after unsubscribing")
+	default:
+	}
+}
@@ -0,0 +1,165 @@
+package coderd_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/derpmap"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/tailnet/tailnettest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestDERPMapUpdates(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	updates, err := client.DERPMapUpdates(ctx)
+	require.NoError(t, err)
+
+	var derpMap *tailcfg.DERPMap
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the DERP map")
+	case derpMap = <-updates:
+	}
+	require.Equal(t, api.DERPMap.Regions[1].RegionName, derpMap.Regions[1].RegionName)
+
+	restricted := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: api.DERPMap.Regions[1],
+			10000: {
+				RegionID:   10000,
+				RegionCode: "eu-west",
+				RegionName: "EU West",
+			},
+		},
+	}
+	setDERPMapProvider(t, api, restricted)
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the DERP map update")
+	case derpMap = <-updates:
+	}
+	require.Contains(t, derpMap.Regions, 10000)
+	require.Equal(t, "EU West", derpMap.Regions[10000].RegionName)
+}
+
+func TestWorkspaceAgentDERPMapUpdates(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  echo.ProvisionComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+	agentCloser := agent.New(agent.Options{
+		Client: agentClient,
+		Logger: slogtest.Make(t, nil).Named("agent").Leveled(slog.LevelDebug),
+	})
+	defer agentCloser.Close()
+	resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+	agentID := resources[0].Agents[0].ID
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	conn, err := client.DialWorkspaceAgent(ctx, agentID, &codersdk.DialWorkspaceAgentOptions{
+		Logger: slogtest.Make(t, nil).Named("client").Leveled(slog.LevelDebug),
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Add a region while connected.
+	newDERPMap := tailnettest.RunDERPAndSTUN(t)
+	region := newDERPMap.Regions[1]
+	region.RegionID = 2
+	for _, node := range region.Nodes {
+		node.RegionID = 2
+	}
+	withRegion := api.DERPMap.Clone()
+	withRegion.Regions[2] = region
+	setDERPMapProvider(t, api, withRegion)
+	require.Eventually(t, func() bool {
+		_, ok := conn.DERPMap().Regions[2]
+		return ok
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	// Remove the original region, which moves the agent to the new one.
+	setDERPMapProvider(t, api, &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{2: region},
+	})
+	require.Eventually(t, func() bool {
+		_, ok := conn.DERPMap().Regions[1]
+		return !ok
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Eventually(t, func() bool {
+		node := (*api.TailnetCoordinator.Load()).Node(agentID)
+		return node != nil && node.PreferredDERP == 2
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+	session, err := sshClient.NewSession()
+	require.NoError(t, err)
+	defer session.Close()
+	output, err := session.CombinedOutput("echo test")
+	require.NoError(t, err)
+	require.Equal(t, "test", strings.TrimSpace(string(output)))
+}
+
+func setDERPMapProvider(t *testing.T, api *coderd.API, derpMap *tailcfg.DERPMap) {
+	t.Helper()
+
+	provider := derpmap.Provider(&staticDERPMapProvider{derpMap: derpMap})
+	api.DERPMapProvider.Store(&provider)
+	err := api.Pubsub.Publish(derpmap.PubsubEvent, nil)
+	require.NoError(t, err)
+}
+
+type staticDERPMapProvider struct {
+	derpMap *tailcfg.DERPMap
+}
+
+func (p *staticDERPMapProvider) ForUser(_ context.Context, _ uuid.UUID) (*tailcfg.DERPMap, error) {
+	return p.derpMap.Clone(), nil
+}
+
+func (p *staticDERPMapProvider) ForTemplate(_ context.Context, _ uuid.UUID) (*tailcfg.DERPMap, error) {
+	return p.derpMap.Clone(), nil
+}
+
+func (p *staticDERPMapProvider) All(_ context.Context) (*tailcfg.DERPMap, error) {
+	return p.derpMap.Clone(), nil
+}
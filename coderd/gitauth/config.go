@@ -109,6 +109,21 @@ func (c *Config) ValidateToken(ctx context.Context, token string) (bool, error)
 	return true, nil
 }
 
+// Credentials returns the username and password Git should use to
+// authenticate over HTTPS with an access token from this provider.
+func (c *Config) Credentials(token string) (username, password string) {
+	switch c.Type {
+	case codersdk.GitProviderGitLab:
+		// https://stackoverflow.com/questions/25409700/using-gitlab-token-to-clone-without-authentication
+		return "oauth2", token
+	case codersdk.GitProviderBitBucket:
+		// https://support.atlassian.com/bitbucket-cloud/docs/use-oauth-on-bitbucket-cloud/#Cloning-a-repository-with-an-access-token
+		return "x-token-auth", token
+	default:
+		return token, ""
+	}
+}
+
 // ConvertConfig converts the SDK configuration entry format
 // to the parsed and ready-to-consume in coderd provider type.
 func ConvertConfig(entries []codersdk.GitAuthConfig, accessURL *url.URL) ([]*Config, error) {
@@ -51,6 +51,12 @@ type Authorization struct {
 	// It is usually the "username" of the user, but it can be the name of the
 	// external workspace proxy or other service type actor.
 	ActorName string
+	// ServiceAccountCreatedBy is the admin that created the user if they are
+	// a service account.
+	ServiceAccountCreatedBy uuid.NullUUID
+	// ImpersonatorID is the owner that is acting as the user if the request
+	// was authenticated with an impersonation API key.
+	ImpersonatorID uuid.NullUUID
 }
 
 // UserAuthorizationOptional may return the roles and scope used for
@@ -75,6 +81,9 @@ func UserAuthorization(r *http.Request) Authorization {
 type OAuth2Configs struct {
 	Github OAuth2Config
 	OIDC   OAuth2Config
+	// OIDCProviders are the additional OpenID Connect providers keyed by
+	// provider ID.
+	OIDCProviders map[string]OAuth2Config
 }
 
 const (
@@ -87,6 +96,9 @@ type ExtractAPIKeyConfig struct {
 	OAuth2Configs               *OAuth2Configs
 	RedirectToLogin             bool
 	DisableSessionExpiryRefresh bool
+	// DisableImpersonation rejects the API keys that owners created to
+	// impersonate other users.
+	DisableImpersonation bool
 
 	// Optional governs whether the API key is optional. Use this if you want to
 	// allow unauthenticated requests.
@@ -243,12 +255,21 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 				oauthConfig = cfg.OAuth2Configs.Github
 			case database.LoginTypeOIDC:
 				oauthConfig = cfg.OAuth2Configs.OIDC
+				if link.OIDCProviderID != "" {
+					oauthConfig = cfg.OAuth2Configs.OIDCProviders[link.OIDCProviderID]
+				}
 			default:
 				return write(http.StatusInternalServerError, codersdk.Response{
 					Message: internalErrorMessage,
 					Detail:  fmt.Sprintf("Unexpected authentication type %q.", key.LoginType),
 				})
 			}
+			if oauthConfig == nil {
+				return write(http.StatusUnauthorized, codersdk.Response{
+					Message: "Could not refresh expired Oauth token.",
+					Detail:  fmt.Sprintf("The %q authentication provider the user is linked to is not configured.", key.LoginType),
+				})
+			}
 			// If it is, let's refresh it from the provided config
 			token, err := oauthConfig.TokenSource(r.Context(), &oauth2.Token{
 				AccessToken:  link.OAuthAccessToken,
@@ -277,6 +298,25 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 		})
 	}
 
+	var impersonation database.GetAPIKeyImpersonationByAPIKeyIDRow
+	if key.LoginType == database.LoginTypeImpersonation {
+		// Disabling impersonation also ends the impersonations in progress.
+		if cfg.DisableImpersonation {
+			return optionalWrite(http.StatusUnauthorized, codersdk.Response{
+				Message: SignedOutErrorMessage,
+				Detail:  "Impersonation is disabled on this deployment.",
+			})
+		}
+		//nolint:gocritic // System needs to fetch the impersonator of the API key.
+		impersonation, err = cfg.DB.GetAPIKeyImpersonationByAPIKeyID(dbauthz.AsSystemRestricted(ctx), key.ID)
+		if err != nil {
+			return write(http.StatusInternalServerError, codersdk.Response{
+				Message: internalErrorMessage,
+				Detail:  fmt.Sprintf("Internal error fetching API key impersonation. %s", err.Error()),
+			})
+		}
+	}
+
 	// Only update LastUsed once an hour to prevent database spam.
 	if now.Sub(key.LastUsed) > time.Hour {
 		key.LastUsed = now
@@ -295,8 +335,9 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 		changed = true
 	}
 	// Only update the ExpiresAt once an hour to prevent database spam.
-	// We extend the ExpiresAt to reduce re-authentication.
-	if !cfg.DisableSessionExpiryRefresh {
+	// We extend the ExpiresAt to reduce re-authentication. Impersonation
+	// keys are never extended.
+	if !cfg.DisableSessionExpiryRefresh && key.LoginType != database.LoginTypeImpersonation {
 		apiKeyLifetime := time.Duration(key.LifetimeSeconds) * time.Second
 		if key.ExpiresAt.Sub(now) <= apiKeyLifetime-time.Hour {
 			key.ExpiresAt = now.Add(apiKeyLifetime)
@@ -371,9 +412,32 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 		})
 	}
 
+	// Service accounts can only authenticate with tokens, any other key was
+	// not created for them by an admin. Owners can still impersonate them.
+	// Keys scoped to connecting to workspace apps are minted from a request
+	// that was already authenticated, see workspaceApplicationAuth.
+	if roles.ServiceAccountCreatedBy.Valid && key.LoginType != database.LoginTypeToken && key.LoginType != database.LoginTypeImpersonation &&
+		key.Scope != database.APIKeyScopeApplicationConnect {
+		return write(http.StatusUnauthorized, codersdk.Response{
+			Message: SignedOutErrorMessage,
+			Detail:  "Service accounts can only authenticate with tokens.",
+		})
+	}
+
+	if key.LoginType == database.LoginTypeImpersonation {
+		// Clients show a banner while this header is set, so the owner
+		// cannot forget that they are acting as someone else.
+		rw.Header().Set(codersdk.ImpersonatorHeader, impersonation.ImpersonatorUsername)
+	}
+
 	// Actor is the user's authorization context.
 	authz := Authorization{
-		ActorName: roles.Username,
+		ActorName:               roles.Username,
+		ServiceAccountCreatedBy: roles.ServiceAccountCreatedBy,
+		ImpersonatorID: uuid.NullUUID{
+			UUID:  impersonation.ImpersonatorID,
+			Valid: key.LoginType == database.LoginTypeImpersonation,
+		},
 		Actor: rbac.Subject{
 			ID:     key.UserID.String(),
 			Roles:  rbac.RoleNames(roles.Roles),
@@ -392,6 +456,7 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 // 3: The old cookie
 // 4. The coder_session_token query parameter
 // 5. The custom auth header
+// 6. The "Authorization: Bearer" header used by OAuth2 clients
 func APITokenFromRequest(r *http.Request) string {
 	cookie, err := r.Cookie(codersdk.SessionTokenCookie)
 	if err == nil && cookie.Value != "" {
@@ -413,6 +478,12 @@ func APITokenFromRequest(r *http.Request) string {
 		return cookie.Value
 	}
 
+	// Access tokens issued by the OAuth2 provider are sent as bearer tokens.
+	scheme, bearer, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && bearer != "" {
+		return bearer
+	}
+
 	return ""
 }
 
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
@@ -595,4 +596,126 @@ func TestAPIKey(t *testing.T) {
 		require.Equal(t, sentAPIKey.ExpiresAt, gotAPIKey.ExpiresAt)
 		require.Equal(t, sentAPIKey.LoginType, gotAPIKey.LoginType)
 	})
+
+	t.Run("Impersonation", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db                = dbfake.New()
+			owner             = dbgen.User(t, db, database.User{})
+			user              = dbgen.User(t, db, database.User{})
+			sentAPIKey, token = dbgen.APIKey(t, db, database.APIKey{
+				UserID:          user.ID,
+				ExpiresAt:       database.Now().Add(time.Hour),
+				LifetimeSeconds: int64(time.Hour.Seconds()),
+				LoginType:       database.LoginTypeImpersonation,
+			})
+			_ = dbgen.APIKeyImpersonation(t, db, database.APIKeyImpersonation{
+				APIKeyID:       sentAPIKey.ID,
+				ImpersonatorID: owner.ID,
+			})
+
+			r  = httptest.NewRequest("GET", "/", nil)
+			rw = httptest.NewRecorder()
+		)
+		r.Header.Set(codersdk.SessionTokenHeader, token)
+
+		httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
+			DB:              db,
+			RedirectToLogin: false,
+		})(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			authz := httpmw.UserAuthorization(r)
+			assert.Equal(t, user.ID.String(), authz.Actor.ID)
+			assert.Equal(t, uuid.NullUUID{UUID: owner.ID, Valid: true}, authz.ImpersonatorID)
+			httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.Response{
+				Message: "It worked!",
+			})
+		})).ServeHTTP(rw, r)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, owner.Username, res.Header.Get(codersdk.ImpersonatorHeader))
+
+		gotAPIKey, err := db.GetAPIKeyByID(r.Context(), sentAPIKey.ID)
+		require.NoError(t, err)
+		require.Equal(t, sentAPIKey.ExpiresAt, gotAPIKey.ExpiresAt)
+	})
+
+	t.Run("ImpersonationDisabled", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db                = dbfake.New()
+			owner             = dbgen.User(t, db, database.User{})
+			user              = dbgen.User(t, db, database.User{})
+			sentAPIKey, token = dbgen.APIKey(t, db, database.APIKey{
+				UserID:    user.ID,
+				ExpiresAt: database.Now().Add(time.Hour),
+				LoginType: database.LoginTypeImpersonation,
+			})
+			_ = dbgen.APIKeyImpersonation(t, db, database.APIKeyImpersonation{
+				APIKeyID:       sentAPIKey.ID,
+				ImpersonatorID: owner.ID,
+			})
+
+			r  = httptest.NewRequest("GET", "/", nil)
+			rw = httptest.NewRecorder()
+		)
+		r.Header.Set(codersdk.SessionTokenHeader, token)
+
+		httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
+			DB:                   db,
+			RedirectToLogin:      false,
+			DisableImpersonation: true,
+		})(successHandler).ServeHTTP(rw, r)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		require.Empty(t, res.Header.Get(codersdk.ImpersonatorHeader))
+	})
+
+	t.Run("ServiceAccount", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db      = dbfake.New()
+			admin   = dbgen.User(t, db, database.User{})
+			user    = dbgen.User(t, db, database.User{})
+			_       = dbgen.ServiceAccount(t, db, database.ServiceAccount{UserID: user.ID, CreatedBy: admin.ID})
+			expires = database.Now().Add(time.Hour)
+		)
+
+		for _, tc := range []struct {
+			name      string
+			loginType database.LoginType
+			scope     database.APIKeyScope
+			status    int
+		}{
+			{name: "Token", loginType: database.LoginTypeToken, scope: database.APIKeyScopeAll, status: http.StatusOK},
+			{name: "Password", loginType: database.LoginTypePassword, scope: database.APIKeyScopeAll, status: http.StatusUnauthorized},
+			// Keys minted for workspace apps by workspaceApplicationAuth.
+			{name: "ApplicationConnect", loginType: database.LoginTypePassword, scope: database.APIKeyScopeApplicationConnect, status: http.StatusOK},
+		} {
+			_, token := dbgen.APIKey(t, db, database.APIKey{
+				UserID:    user.ID,
+				ExpiresAt: expires,
+				LoginType: tc.loginType,
+				Scope:     tc.scope,
+			})
+			r := httptest.NewRequest("GET", "/", nil)
+			rw := httptest.NewRecorder()
+			r.Header.Set(codersdk.SessionTokenHeader, token)
+
+			httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
+				DB:              db,
+				RedirectToLogin: false,
+			})(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				authz := httpmw.UserAuthorization(r)
+				assert.Equal(t, uuid.NullUUID{UUID: admin.ID, Valid: true}, authz.ServiceAccountCreatedBy)
+				httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.Response{
+					Message: "It worked!",
+				})
+			})).ServeHTTP(rw, r)
+			res := rw.Result()
+			_ = res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode, tc.name)
+		}
+	})
 }
@@ -11,9 +11,16 @@ import (
 
 type requestIDContextKey struct{}
 
+// RequestIDOptional may return the ID of the request if the request ID
+// middleware was provided.
+func RequestIDOptional(r *http.Request) (uuid.UUID, bool) {
+	rid, ok := r.Context().Value(requestIDContextKey{}).(uuid.UUID)
+	return rid, ok
+}
+
 // RequestID returns the ID of the request.
 func RequestID(r *http.Request) uuid.UUID {
-	rid, ok := r.Context().Value(requestIDContextKey{}).(uuid.UUID)
+	rid, ok := RequestIDOptional(r)
 	if !ok {
 		panic("developer error: request id middleware not provided")
 	}
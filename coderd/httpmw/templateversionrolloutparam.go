@@ -0,0 +1,54 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+type templateVersionRolloutParamContextKey struct{}
+
+// TemplateVersionRolloutParam returns the rollout from the ExtractTemplateVersionRolloutParam handler.
+func TemplateVersionRolloutParam(r *http.Request) database.TemplateVersionRollout {
+	rollout, ok := r.Context().Value(templateVersionRolloutParamContextKey{}).(database.TemplateVersionRollout)
+	if !ok {
+		panic("developer error: template version rollout param middleware not provided")
+	}
+	return rollout
+}
+
+// ExtractTemplateVersionRolloutParam grabs a rollout of the template from the
+// "rollout" URL parameter. It must be used after ExtractTemplateParam.
+func ExtractTemplateVersionRolloutParam(db database.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			rolloutID, parsed := parseUUID(rw, r, "rollout")
+			if !parsed {
+				return
+			}
+			rollout, err := db.GetTemplateVersionRolloutByID(ctx, rolloutID)
+			if httpapi.Is404Error(err) {
+				httpapi.ResourceNotFound(rw)
+				return
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching template version rollout.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			if rollout.TemplateID != TemplateParam(r).ID {
+				httpapi.ResourceNotFound(rw)
+				return
+			}
+
+			ctx = context.WithValue(ctx, templateVersionRolloutParamContextKey{}, rollout)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
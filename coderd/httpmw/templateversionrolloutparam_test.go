@@ -0,0 +1,84 @@
+package httpmw_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/httpmw"
+)
+
+func TestTemplateVersionRolloutParam(t *testing.T) {
+	t.Parallel()
+
+	serve := func(t *testing.T, db database.Store, templateID uuid.UUID, rolloutID string) *http.Response {
+		router := chi.NewRouter()
+		router.Use(
+			httpmw.ExtractTemplateParam(db),
+			httpmw.ExtractTemplateVersionRolloutParam(db),
+		)
+		router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			rollout := httpmw.TemplateVersionRolloutParam(r)
+			require.Equal(t, rolloutID, rollout.ID.String())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		r := httptest.NewRequest("GET", "/", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("template", templateID.String())
+		rctx.URLParams.Add("rollout", rolloutID)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		res := w.Result()
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		template := dbgen.Template(t, db, database.Template{})
+		rollout := dbgen.TemplateVersionRollout(t, db, database.TemplateVersionRollout{TemplateID: template.ID})
+
+		res := serve(t, db, template.ID, rollout.ID.String())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		template := dbgen.Template(t, db, database.Template{})
+
+		res := serve(t, db, template.ID, uuid.NewString())
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("OtherTemplate", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		template := dbgen.Template(t, db, database.Template{})
+		rollout := dbgen.TemplateVersionRollout(t, db, database.TemplateVersionRollout{})
+
+		res := serve(t, db, template.ID, rollout.ID.String())
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		template := dbgen.Template(t, db, database.Template{})
+
+		res := serve(t, db, template.ID, "not-a-uuid")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
@@ -0,0 +1,150 @@
+package coderd
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/apikey"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/codersdk"
+)
+
+// impersonationLifetime is how long an impersonation API key is valid for.
+// The key is never refreshed, so the owner has to impersonate the user again
+// once it expires.
+const impersonationLifetime = time.Hour
+
+// Creates a short-lived API key that authenticates as the user. Only owners
+// can impersonate users, and the actions taken with the key are audited with
+// the owner as the impersonator.
+//
+// @Summary Impersonate user
+// @ID impersonate-user
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 201 {object} codersdk.ImpersonateUserResponse
+// @Router /users/{user}/impersonate [post]
+func (api *API) postUserImpersonation(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	aReq.Old = database.APIKey{}
+	defer commitAudit()
+
+	if api.DeploymentValues.DisableImpersonation.Value() {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Impersonation is disabled on this deployment.",
+		})
+		return
+	}
+	if rejectImpersonation(rw, r) {
+		return
+	}
+	// We avoid rbac.Authorizer since owners are the only role that can
+	// impersonate, even if another role can create API keys for users.
+	if !slices.Contains(httpmw.UserAuthorization(r).Actor.SafeRoleNames(), rbac.RoleOwner()) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Only owners can impersonate users.",
+		})
+		return
+	}
+	if user.ID == apiKey.UserID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You cannot impersonate yourself.",
+		})
+		return
+	}
+	if user.Status != database.UserStatusActive {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You cannot impersonate a user that is not active.",
+		})
+		return
+	}
+
+	keyParams, sessionToken, err := apikey.Generate(apikey.CreateParams{
+		UserID:           user.ID,
+		LoginType:        database.LoginTypeImpersonation,
+		DeploymentValues: api.DeploymentValues,
+		Scope:            database.APIKeyScopeAll,
+		LifetimeSeconds:  int64(impersonationLifetime.Seconds()),
+		RemoteAddr:       r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate API key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var key database.APIKey
+	err = api.Database.InTx(func(tx database.Store) error {
+		key, err = tx.InsertAPIKey(ctx, keyParams)
+		if err != nil {
+			return xerrors.Errorf("insert api key: %w", err)
+		}
+		_, err = tx.InsertAPIKeyImpersonation(ctx, database.InsertAPIKeyImpersonationParams{
+			APIKeyID:       key.ID,
+			ImpersonatorID: apiKey.UserID,
+			CreatedAt:      database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("insert api key impersonation: %w", err)
+		}
+		return nil
+	}, nil)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to create API key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = key
+
+	api.Telemetry.Report(&telemetry.Snapshot{
+		APIKeys: []telemetry.APIKey{telemetry.ConvertAPIKey(key)},
+	})
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.ImpersonateUserResponse{
+		Key:       sessionToken,
+		ExpiresAt: key.ExpiresAt,
+	})
+}
+
+// rejectImpersonation writes an error if the request was authenticated with
+// an impersonation API key. It is used by the endpoints that hand out
+// credentials, which would outlive the impersonation.
+func rejectImpersonation(rw http.ResponseWriter, r *http.Request) bool {
+	if httpmw.APIKey(r).LoginType != database.LoginTypeImpersonation {
+		return false
+	}
+	httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+		Message: "This action is not allowed while impersonating a user.",
+	})
+	return true
+}
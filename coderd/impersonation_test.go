@@ -0,0 +1,154 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestImpersonateUser(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		owner := coderdtest.CreateFirstUser(t, client)
+		_, member := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		impersonation, err := client.ImpersonateUser(ctx, member.Username)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(time.Hour), impersonation.ExpiresAt, time.Minute)
+
+		impersonated := codersdk.New(client.URL)
+		impersonated.SetSessionToken(impersonation.Key)
+		me, err := impersonated.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, member.ID, me.ID)
+
+		// Every response reminds the client who is impersonating.
+		res, err := impersonated.Request(ctx, http.MethodGet, "/api/v2/users/me", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, "testuser", res.Header.Get(codersdk.ImpersonatorHeader))
+
+		// Audit logs record both the user and the owner.
+		_, err = impersonated.UpdateUserProfile(ctx, codersdk.Me, codersdk.UpdateUserProfileRequest{
+			Username: "impersonated",
+		})
+		require.NoError(t, err)
+		logs := auditor.AuditLogs()
+		last := logs[len(logs)-1]
+		require.Equal(t, member.ID, last.UserID)
+		var fields map[string]string
+		require.NoError(t, json.Unmarshal(last.AdditionalFields, &fields))
+		require.Equal(t, owner.UserID.String(), fields["impersonator_id"])
+
+		// The key cannot be used to create credentials that outlive it.
+		var apiErr *codersdk.Error
+		_, err = impersonated.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		_, err = impersonated.CreateAPIKey(ctx, codersdk.Me)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		res, err = impersonated.Request(ctx, http.MethodGet, "/api/v2/applications/auth-redirect", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		// Nor can it change how the user signs in or see their sessions.
+		for name, fn := range map[string]func() error{
+			"EnrollTOTP": func() error {
+				_, err := impersonated.EnrollTOTP(ctx, codersdk.Me)
+				return err
+			},
+			"VerifyTOTP": func() error {
+				_, err := impersonated.VerifyTOTP(ctx, codersdk.Me, codersdk.VerifyTOTPRequest{Code: "123456"})
+				return err
+			},
+			"RegenerateTOTPRecoveryCodes": func() error {
+				_, err := impersonated.RegenerateTOTPRecoveryCodes(ctx, codersdk.Me, codersdk.VerifyTOTPRequest{Code: "123456"})
+				return err
+			},
+			"ResetTOTP": func() error {
+				return impersonated.ResetTOTP(ctx, codersdk.Me, codersdk.ResetTOTPRequest{Password: "SomeSecurePassword!"})
+			},
+			"UpdateUserPassword": func() error {
+				return impersonated.UpdateUserPassword(ctx, codersdk.Me, codersdk.UpdateUserPasswordRequest{
+					OldPassword: "SomeSecurePassword!",
+					Password:    "MySecurePassword!",
+				})
+			},
+			"Sessions": func() error {
+				_, err := impersonated.Sessions(ctx, codersdk.Me)
+				return err
+			},
+			"RevokeSession": func() error {
+				return impersonated.RevokeSession(ctx, codersdk.Me, "session")
+			},
+			"RevokeOtherSessions": func() error {
+				return impersonated.RevokeOtherSessions(ctx, codersdk.Me)
+			},
+		} {
+			err := fn()
+			require.ErrorAs(t, err, &apiErr, name)
+			require.Equal(t, http.StatusForbidden, apiErr.StatusCode(), name)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		dv := coderdtest.DeploymentValues(t)
+		dv.DisableImpersonation = true
+		client := coderdtest.New(t, &coderdtest.Options{DeploymentValues: dv})
+		owner := coderdtest.CreateFirstUser(t, client)
+		_, member := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.ImpersonateUser(ctx, member.Username)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("NotOwner", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		owner := coderdtest.CreateFirstUser(t, client)
+		userAdmin, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleUserAdmin())
+		_, member := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := userAdmin.ImpersonateUser(ctx, member.Username)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.ImpersonateUser(ctx, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
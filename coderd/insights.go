@@ -1,9 +1,18 @@
 package coderd
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"sort"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
@@ -43,3 +52,164 @@ func (api *API) deploymentDAUs(rw http.ResponseWriter, r *http.Request) {
 	}
 	httpapi.Write(ctx, rw, http.StatusOK, resp)
 }
+
+// @Summary Get app insights
+// @ID get-app-insights
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Param start_time query string false "Start time, defaults to 30 days before the end time" format(date-time)
+// @Param end_time query string false "End time, defaults to now" format(date-time)
+// @Param template_ids query []string false "Template IDs, defaults to all templates" collectionFormat(csv)
+// @Success 200 {object} codersdk.AppInsightsResponse
+// @Router /insights/apps [get]
+func (api *API) deploymentAppInsights(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	vals := r.URL.Query()
+	p := httpapi.NewQueryParamParser()
+	templateIDs := p.UUIDs(vals, []uuid.UUID{}, "template_ids")
+	startTime, endTime := parseInsightsTimeRange(p, vals)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	resp, err := api.appInsights(ctx, templateIDs, startTime, endTime)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Get template app insights
+// @ID get-template-app-insights
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param start_time query string false "Start time, defaults to 30 days before the end time" format(date-time)
+// @Param end_time query string false "End time, defaults to now" format(date-time)
+// @Success 200 {object} codersdk.AppInsightsResponse
+// @Router /templates/{template}/insights/apps [get]
+func (api *API) templateAppInsights(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	vals := r.URL.Query()
+	p := httpapi.NewQueryParamParser()
+	startTime, endTime := parseInsightsTimeRange(p, vals)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	resp, err := api.appInsights(ctx, []uuid.UUID{template.ID}, startTime, endTime)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// parseInsightsTimeRange parses the start and end time query parameters. The
+// usage is collected per hour, so the start time is rounded down and the end
+// time up to the hour.
+func parseInsightsTimeRange(p *httpapi.QueryParamParser, vals url.Values) (startTime, endTime time.Time) {
+	endTime = p.Time(vals, database.Now(), "end_time", time.RFC3339)
+	startTime = p.Time(vals, endTime.Add(-30*24*time.Hour), "start_time", time.RFC3339)
+	if !startTime.Before(endTime) && len(p.Errors) == 0 {
+		p.Errors = append(p.Errors, codersdk.ValidationError{
+			Field:  "start_time",
+			Detail: "Query param \"start_time\" must be before \"end_time\"",
+		})
+	}
+
+	startTime = startTime.UTC().Truncate(time.Hour)
+	if truncated := endTime.UTC().Truncate(time.Hour); !truncated.Equal(endTime) {
+		endTime = truncated.Add(time.Hour)
+	}
+	return startTime, endTime.UTC()
+}
+
+// appInsights returns the usage of the apps of the templates, including apps
+// declared by the active versions that were not used. The caller must
+// authorize the request.
+func (api *API) appInsights(ctx context.Context, templateIDs []uuid.UUID, startTime, endTime time.Time) (codersdk.AppInsightsResponse, error) {
+	//nolint:gocritic // Usage is only readable by the system, the handlers authorize the request.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	apps, err := api.Database.GetTemplateActiveVersionApps(ctx, templateIDs)
+	if err != nil {
+		return codersdk.AppInsightsResponse{}, err
+	}
+	usage, err := api.Database.GetWorkspaceAppUsageInsights(ctx, database.GetWorkspaceAppUsageInsightsParams{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		TemplateIDs: templateIDs,
+	})
+	if err != nil {
+		return codersdk.AppInsightsResponse{}, err
+	}
+
+	type key struct {
+		templateID uuid.UUID
+		slug       string
+	}
+	insights := make(map[key]*codersdk.AppInsight)
+	for _, app := range apps {
+		insights[key{app.TemplateID, app.Slug}] = &codersdk.AppInsight{
+			TemplateID:  app.TemplateID,
+			Slug:        app.Slug,
+			DisplayName: app.DisplayName,
+			Icon:        app.Icon,
+		}
+	}
+	for _, row := range usage {
+		insight, ok := insights[key{row.TemplateID, row.AppSlug}]
+		if !ok {
+			// The app was removed from the template, or only declared by
+			// an older version.
+			insight = &codersdk.AppInsight{
+				TemplateID: row.TemplateID,
+				Slug:       row.AppSlug,
+			}
+			insights[key{row.TemplateID, row.AppSlug}] = insight
+		}
+		insight.UniqueUsers = row.UniqueUsers
+		insight.Requests = row.Requests
+		insight.ErrorRequests = row.ErrorRequests
+		insight.BytesSent = row.BytesSent
+		if row.Requests > 0 {
+			insight.AverageLatencyMS = float64(row.TotalLatencyMs) / float64(row.Requests)
+		}
+	}
+
+	resp := codersdk.AppInsightsResponse{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Apps:      make([]codersdk.AppInsight, 0, len(insights)),
+	}
+	for _, insight := range insights {
+		resp.Apps = append(resp.Apps, *insight)
+	}
+	sort.Slice(resp.Apps, func(i, j int) bool {
+		if resp.Apps[i].TemplateID != resp.Apps[j].TemplateID {
+			return resp.Apps[i].TemplateID.String() < resp.Apps[j].TemplateID.String()
+		}
+		return resp.Apps[i].Slug < resp.Apps[j].Slug
+	})
+	return resp, nil
+}
@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -12,9 +13,12 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
 	"github.com/coder/coder/testutil"
 )
 
@@ -100,3 +104,121 @@ func TestDeploymentInsights(t *testing.T) {
 	res, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{})
 	require.NoError(t, err)
 }
+
+func TestAppInsights(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	// Apps are declared by the plan of the template version.
+	provision := []*proto.Provision_Response{{
+		Type: &proto.Provision_Response_Complete{
+			Complete: &proto.Provision_Complete{
+				Resources: []*proto.Resource{{
+					Name: "example",
+					Type: "aws_instance",
+					Agents: []*proto.Agent{{
+						Id:   uuid.NewString(),
+						Name: "dev",
+						Auth: &proto.Agent_Token{
+							Token: uuid.NewString(),
+						},
+						Apps: []*proto.App{{
+							Slug:        "code-server",
+							DisplayName: "code-server",
+							Icon:        "/icon/code.svg",
+							Url:         "http://localhost:13337",
+						}, {
+							Slug:        "unused",
+							DisplayName: "Unused",
+							Url:         "http://localhost:8080",
+						}},
+					}},
+				}},
+			},
+		},
+	}}
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  provision,
+		ProvisionApply: provision,
+	})
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	now := time.Now().UTC()
+	//nolint:gocritic // Usage is only writable by the system.
+	err := workspaceapps.UpsertAppUsage(dbauthz.AsSystemRestricted(ctx), api.Database, []workspaceapps.AppUsage{{
+		Bucket:         now.Truncate(time.Hour),
+		WorkspaceID:    workspace.ID,
+		UserID:         user.UserID,
+		AppSlug:        "code-server",
+		Requests:       4,
+		ErrorRequests:  1,
+		TotalLatencyMS: 100,
+		BytesSent:      1024,
+	}, {
+		// Anonymous usage of a public app.
+		Bucket:      now.Truncate(time.Hour),
+		WorkspaceID: workspace.ID,
+		AppSlug:     "code-server",
+		Requests:    1,
+	}, {
+		// Usage of an app that was removed from the template.
+		Bucket:      now.Truncate(time.Hour),
+		WorkspaceID: workspace.ID,
+		UserID:      user.UserID,
+		AppSlug:     "removed",
+		Requests:    1,
+	}, {
+		// Outside of the time range.
+		Bucket:      now.Add(-60 * 24 * time.Hour).Truncate(time.Hour),
+		WorkspaceID: workspace.ID,
+		UserID:      user.UserID,
+		AppSlug:     "unused",
+		Requests:    1,
+	}})
+	require.NoError(t, err)
+
+	res, err := client.TemplateAppInsights(ctx, template.ID, codersdk.AppInsightsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []codersdk.AppInsight{{
+		TemplateID:       template.ID,
+		Slug:             "code-server",
+		DisplayName:      "code-server",
+		Icon:             "/icon/code.svg",
+		UniqueUsers:      2,
+		Requests:         5,
+		ErrorRequests:    1,
+		AverageLatencyMS: 20,
+		BytesSent:        1024,
+	}, {
+		TemplateID:  template.ID,
+		Slug:        "removed",
+		UniqueUsers: 1,
+		Requests:    1,
+	}, {
+		TemplateID:  template.ID,
+		Slug:        "unused",
+		DisplayName: "Unused",
+	}}, res.Apps)
+
+	res, err = client.AppInsights(ctx, codersdk.AppInsightsRequest{
+		TemplateIDs: []uuid.UUID{uuid.New()},
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.Apps)
+
+	_, err = client.AppInsights(ctx, codersdk.AppInsightsRequest{
+		StartTime: now,
+		EndTime:   now.Add(-time.Hour),
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
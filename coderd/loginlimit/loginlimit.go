@@ -0,0 +1,133 @@
+// Package loginlimit slows down and locks out repeated failed password logins.
+package loginlimit
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// FreeAttempts is the number of consecutive failures that are not
+	// delayed, so users mistyping their password are not slowed down.
+	FreeAttempts = 3
+	// BaseDelay is the delay after the first failure beyond FreeAttempts. It
+	// doubles with every further failure.
+	BaseDelay = time.Second
+	// MaxDelay caps the delay between attempts.
+	MaxDelay = 30 * time.Second
+
+	pruneInterval = time.Minute
+)
+
+// Delay returns how long to wait after the last of the given number of
+// consecutive failures before another attempt is accepted.
+func Delay(failures int64) time.Duration {
+	if failures <= FreeAttempts {
+		return 0
+	}
+	delay := BaseDelay
+	for i := int64(FreeAttempts + 1); i < failures; i++ {
+		delay *= 2
+		if delay >= MaxDelay {
+			return MaxDelay
+		}
+	}
+	return delay
+}
+
+// Wait returns how long to wait before another attempt is accepted given the
+// consecutive failures so far. Zero means an attempt is accepted now.
+func Wait(failures int64, lastFailure, lockedUntil, now time.Time) time.Duration {
+	wait := lastFailure.Add(Delay(failures)).Sub(now)
+	if locked := lockedUntil.Sub(now); locked > wait {
+		wait = locked
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// IPTracker counts failed attempts per source IP address in memory. Each
+// replica tracks the addresses it has seen.
+type IPTracker struct {
+	threshold int64
+	duration  time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*ipEntry
+	lastPrune time.Time
+}
+
+type ipEntry struct {
+	failures    int64
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewIPTracker returns a tracker that locks addresses out for the duration
+// once they reach the threshold of consecutive failures. A threshold of zero
+// disables lockouts, though failures are still delayed.
+func NewIPTracker(threshold int64, duration time.Duration) *IPTracker {
+	return &IPTracker{
+		threshold: threshold,
+		duration:  duration,
+		entries:   map[string]*ipEntry{},
+	}
+}
+
+// Wait returns how long the address must wait before its next attempt.
+func (t *IPTracker) Wait(ip string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[ip]
+	if !ok {
+		return 0
+	}
+	return Wait(entry.failures, entry.lastFailure, entry.lockedUntil, now)
+}
+
+// Fail records a failed attempt from the address. It returns true if the
+// address was locked out by this attempt.
+func (t *IPTracker) Fail(ip string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(now)
+
+	entry, ok := t.entries[ip]
+	if !ok || t.expired(entry, now) {
+		entry = &ipEntry{}
+		t.entries[ip] = entry
+	}
+	entry.failures++
+	entry.lastFailure = now
+	if t.threshold > 0 && entry.failures >= t.threshold && !entry.lockedUntil.After(now) {
+		entry.lockedUntil = now.Add(t.duration)
+		// Start over once the lockout ends.
+		entry.failures = 0
+		return true
+	}
+	return false
+}
+
+// expired returns whether the failures of an address have decayed. Successful
+// logins don't reset the failures of an address, since an attacker guessing
+// the passwords of many accounts could reset them by logging in to their own
+// account in between. The caller must hold the lock.
+func (t *IPTracker) expired(entry *ipEntry, now time.Time) bool {
+	return now.Sub(entry.lastFailure) > MaxDelay+t.duration && !entry.lockedUntil.After(now)
+}
+
+// prune drops addresses that no longer have to wait, so the tracker does not
+// grow without bound. The caller must hold the lock.
+func (t *IPTracker) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < pruneInterval {
+		return
+	}
+	t.lastPrune = now
+	for ip, entry := range t.entries {
+		if t.expired(entry, now) {
+			delete(t.entries, ip)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package loginlimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/loginlimit"
+)
+
+func TestDelay(t *testing.T) {
+	t.Parallel()
+
+	for failures, expected := range map[int64]time.Duration{
+		0:                           0,
+		loginlimit.FreeAttempts:     0,
+		loginlimit.FreeAttempts + 1: time.Second,
+		loginlimit.FreeAttempts + 2: 2 * time.Second,
+		loginlimit.FreeAttempts + 3: 4 * time.Second,
+		100:                         loginlimit.MaxDelay,
+	} {
+		require.Equal(t, expected, loginlimit.Delay(failures), "failures %d", failures)
+	}
+}
+
+func TestWait(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	require.Zero(t, loginlimit.Wait(1, now, time.Time{}, now))
+	require.Equal(t, time.Second, loginlimit.Wait(loginlimit.FreeAttempts+1, now, time.Time{}, now))
+	require.Zero(t, loginlimit.Wait(loginlimit.FreeAttempts+1, now.Add(-time.Minute), time.Time{}, now))
+	require.Equal(t, time.Hour, loginlimit.Wait(0, time.Time{}, now.Add(time.Hour), now))
+}
+
+func TestIPTracker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Lockout", func(t *testing.T) {
+		t.Parallel()
+		tracker := loginlimit.NewIPTracker(5, time.Hour)
+		now := time.Now()
+		for i := 0; i < 4; i++ {
+			require.False(t, tracker.Fail("127.0.0.1", now))
+		}
+		require.Equal(t, time.Second, tracker.Wait("127.0.0.1", now))
+		require.Zero(t, tracker.Wait("127.0.0.2", now))
+
+		require.True(t, tracker.Fail("127.0.0.1", now))
+		require.Equal(t, time.Hour, tracker.Wait("127.0.0.1", now))
+		require.Zero(t, tracker.Wait("127.0.0.1", now.Add(time.Hour)))
+	})
+
+	t.Run("Decay", func(t *testing.T) {
+		t.Parallel()
+		tracker := loginlimit.NewIPTracker(5, time.Hour)
+		now := time.Now()
+		for i := 0; i < 4; i++ {
+			require.False(t, tracker.Fail("127.0.0.1", now))
+		}
+
+		// The failures are forgotten once the address stopped failing for
+		// longer than it could be locked out.
+		now = now.Add(loginlimit.MaxDelay + time.Hour + time.Second)
+		require.Zero(t, tracker.Wait("127.0.0.1", now))
+		for i := 0; i < 4; i++ {
+			require.False(t, tracker.Fail("127.0.0.1", now))
+		}
+		require.True(t, tracker.Fail("127.0.0.1", now))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		tracker := loginlimit.NewIPTracker(0, time.Hour)
+		now := time.Now()
+		for i := 0; i < 10; i++ {
+			require.False(t, tracker.Fail("127.0.0.1", now))
+		}
+		require.Equal(t, loginlimit.MaxDelay, tracker.Wait("127.0.0.1", now))
+	})
+}
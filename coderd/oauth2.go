@@ -0,0 +1,1015 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/apikey"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/oauth2provider"
+	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/site"
+)
+
+// @Summary Get OAuth2 applications
+// @ID get-oauth2-applications
+// @Security CoderSessionToken
+// @Produce json
+// @Tags OAuth2
+// @Param user_id query string false "Filter by applications authorized for a user"
+// @Success 200 {array} codersdk.OAuth2ProviderApp
+// @Router /oauth2-provider/apps [get]
+func (api *API) oAuth2ProviderApps(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vals := r.URL.Query()
+	p := httpapi.NewQueryParamParser()
+	userID := p.UUID(vals, uuid.Nil, "user_id")
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	var (
+		apps []database.OAuth2ProviderApp
+		err  error
+	)
+	if userID == uuid.Nil {
+		apps, err = api.Database.GetOAuth2ProviderApps(ctx)
+	} else {
+		apps, err = api.Database.GetOAuth2ProviderAppsByUserID(ctx, userID)
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching OAuth2 apps.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOAuth2ProviderApps(api.AccessURL, apps))
+}
+
+// @Summary Get OAuth2 application
+// @ID get-oauth2-application
+// @Security CoderSessionToken
+// @Produce json
+// @Tags OAuth2
+// @Param app path string true "App ID"
+// @Success 200 {object} codersdk.OAuth2ProviderApp
+// @Router /oauth2-provider/apps/{app} [get]
+func (api *API) oAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app := httpmw.OAuth2ProviderApp(r)
+	httpapi.Write(ctx, rw, http.StatusOK, convertOAuth2ProviderApp(api.AccessURL, app))
+}
+
+// @Summary Create OAuth2 application
+// @ID create-oauth2-application
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags OAuth2
+// @Param request body codersdk.PostOAuth2ProviderAppRequest true "The OAuth2 application to create."
+// @Success 201 {object} codersdk.OAuth2ProviderApp
+// @Router /oauth2-provider/apps [post]
+func (api *API) postOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OAuth2ProviderApp](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.PostOAuth2ProviderAppRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	app, err := api.Database.InsertOAuth2ProviderApp(ctx, database.InsertOAuth2ProviderAppParams{
+		ID:          uuid.New(),
+		CreatedAt:   database.Now(),
+		UpdatedAt:   database.Now(),
+		Name:        req.Name,
+		Icon:        req.Icon,
+		CallbackURL: req.CallbackURL,
+	})
+	if database.IsUniqueViolation(err, database.UniqueOauth2ProviderAppsNameKey) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("An OAuth2 app named %q already exists.", req.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "name",
+				Detail: "This value is already in use and should be unique.",
+			}},
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating OAuth2 app.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = app
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertOAuth2ProviderApp(api.AccessURL, app))
+}
+
+// @Summary Update OAuth2 application
+// @ID update-oauth2-application
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags OAuth2
+// @Param app path string true "App ID"
+// @Param request body codersdk.PutOAuth2ProviderAppRequest true "Update an OAuth2 application."
+// @Success 200 {object} codersdk.OAuth2ProviderApp
+// @Router /oauth2-provider/apps/{app} [put]
+func (api *API) putOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		app               = httpmw.OAuth2ProviderApp(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OAuth2ProviderApp](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	aReq.Old = app
+	defer commitAudit()
+
+	var req codersdk.PutOAuth2ProviderAppRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	app, err := api.Database.UpdateOAuth2ProviderAppByID(ctx, database.UpdateOAuth2ProviderAppByIDParams{
+		ID:          app.ID,
+		UpdatedAt:   database.Now(),
+		Name:        req.Name,
+		Icon:        req.Icon,
+		CallbackURL: req.CallbackURL,
+	})
+	if database.IsUniqueViolation(err, database.UniqueOauth2ProviderAppsNameKey) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("An OAuth2 app named %q already exists.", req.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "name",
+				Detail: "This value is already in use and should be unique.",
+			}},
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating OAuth2 app.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = app
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOAuth2ProviderApp(api.AccessURL, app))
+}
+
+// Deleting an application revokes every token issued to it.
+//
+// @Summary Delete OAuth2 application
+// @ID delete-oauth2-application
+// @Security CoderSessionToken
+// @Tags OAuth2
+// @Param app path string true "App ID"
+// @Success 204
+// @Router /oauth2-provider/apps/{app} [delete]
+func (api *API) deleteOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		app               = httpmw.OAuth2ProviderApp(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OAuth2ProviderApp](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	aReq.Old = app
+	defer commitAudit()
+
+	err := api.Database.DeleteOAuth2ProviderAppByID(ctx, app.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting OAuth2 app.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+// @Summary Get OAuth2 application secrets
+// @ID get-oauth2-application-secrets
+// @Security CoderSessionToken
+// @Produce json
+// @Tags OAuth2
+// @Param app path string true "App ID"
+// @Success 200 {array} codersdk.OAuth2ProviderAppSecret
+// @Router /oauth2-provider/apps/{app}/secrets [get]
+func (api *API) oAuth2ProviderAppSecrets(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app := httpmw.OAuth2ProviderApp(r)
+
+	dbSecrets, err := api.Database.GetOAuth2ProviderAppSecretsByAppID(ctx, app.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching OAuth2 app secrets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	secrets := make([]codersdk.OAuth2ProviderAppSecret, 0, len(dbSecrets))
+	for _, secret := range dbSecrets {
+		secrets = append(secrets, convertOAuth2ProviderAppSecret(secret))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, secrets)
+}
+
+// The full secret is only returned by this endpoint, it cannot be retrieved
+// afterwards.
+//
+// @Summary Create OAuth2 application secret
+// @ID create-oauth2-application-secret
+// @Security CoderSessionToken
+// @Produce json
+// @Tags OAuth2
+// @Param app path string true "App ID"
+// @Success 201 {object} codersdk.OAuth2ProviderAppSecretFull
+// @Router /oauth2-provider/apps/{app}/secrets [post]
+func (api *API) postOAuth2ProviderAppSecret(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		app               = httpmw.OAuth2ProviderApp(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OAuth2ProviderAppSecret](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	secret, err := oauth2provider.GenerateSecret()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate OAuth2 client secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	dbSecret, err := api.Database.InsertOAuth2ProviderAppSecret(ctx, database.InsertOAuth2ProviderAppSecretParams{
+		ID:           uuid.New(),
+		CreatedAt:    database.Now(),
+		SecretPrefix: []byte(secret.Prefix),
+		HashedSecret: secret.Hashed,
+		// Keep the last few characters so secrets can be told apart.
+		DisplaySecret: secret.Secret[len(secret.Secret)-6:],
+		AppID:         app.ID,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating OAuth2 client secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = dbSecret
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.OAuth2ProviderAppSecretFull{
+		ID:               dbSecret.ID,
+		ClientSecretFull: secret.Formatted,
+	})
+}
+
+// Deleting a secret revokes every token issued with it.
+//
+// @Summary Delete OAuth2 application secret
+// @ID delete-oauth2-application-secret
+// @Security CoderSessionToken
+// @Tags OAuth2
+// @Param app path string true "App ID"
+// @Param secretID path string true "Secret ID"
+// @Success 204
+// @Router /oauth2-provider/apps/{app}/secrets/{secretID} [delete]
+func (api *API) deleteOAuth2ProviderAppSecret(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		secret            = httpmw.OAuth2ProviderAppSecret(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OAuth2ProviderAppSecret](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	aReq.Old = secret
+	defer commitAudit()
+
+	err := api.Database.DeleteOAuth2ProviderAppSecretByID(ctx, secret.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting OAuth2 client secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+// oauth2AuthorizeParams are the validated query parameters of an
+// authorization request.
+type oauth2AuthorizeParams struct {
+	app database.OAuth2ProviderApp
+	// redirectURI is the redirect_uri parameter as sent by the client. It is
+	// empty if the client relies on the registered callback URL.
+	redirectURI   string
+	redirectURL   *url.URL
+	state         string
+	scope         database.APIKeyScope
+	codeChallenge string
+}
+
+// parseOAuth2AuthorizeParams validates an authorization request. Errors that
+// could be used to redirect the user to an attacker controlled URL render an
+// error page, the rest are sent back to the client as described by RFC 6749
+// section 4.1.2.1.
+func (api *API) parseOAuth2AuthorizeParams(rw http.ResponseWriter, r *http.Request) (oauth2AuthorizeParams, bool) {
+	var (
+		ctx    = r.Context()
+		vals   = r.URL.Query()
+		params = oauth2AuthorizeParams{
+			redirectURI: vals.Get("redirect_uri"),
+			state:       vals.Get("state"),
+		}
+	)
+	renderError := func(description string) (oauth2AuthorizeParams, bool) {
+		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+			Status:       http.StatusBadRequest,
+			Title:        "Invalid OAuth2 request",
+			Description:  description,
+			DashboardURL: api.AccessURL.String(),
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+
+	appID, err := uuid.Parse(vals.Get("client_id"))
+	if err != nil {
+		return renderError("The client_id parameter is missing or invalid.")
+	}
+	params.app, err = api.Database.GetOAuth2ProviderAppByID(ctx, appID)
+	if httpapi.Is404Error(err) {
+		return renderError("The application does not exist.")
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching OAuth2 app.",
+			Detail:  err.Error(),
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+
+	redirect := params.app.CallbackURL
+	if params.redirectURI != "" {
+		if !oauth2provider.VerifyRedirectURI(params.app.CallbackURL, params.redirectURI) {
+			return renderError("The redirect_uri does not match the callback URL of the application.")
+		}
+		redirect = params.redirectURI
+	}
+	params.redirectURL, err = url.Parse(redirect)
+	if err != nil {
+		return renderError("The redirect_uri is invalid.")
+	}
+
+	redirectError := func(code, description string) (oauth2AuthorizeParams, bool) {
+		http.Redirect(rw, r, params.errorRedirect(code, description), http.StatusTemporaryRedirect)
+		return oauth2AuthorizeParams{}, false
+	}
+	if vals.Get("response_type") != "code" {
+		return redirectError("unsupported_response_type", "Only the authorization code flow is supported.")
+	}
+	params.scope, err = oauth2provider.ParseScope(vals.Get("scope"))
+	if err != nil {
+		return redirectError("invalid_scope", err.Error())
+	}
+	params.codeChallenge = vals.Get("code_challenge")
+	if params.codeChallenge != "" && vals.Get("code_challenge_method") != "S256" {
+		return redirectError("invalid_request", "Only the S256 code challenge method is supported.")
+	}
+
+	return params, true
+}
+
+// errorRedirect returns the URL that reports an error back to the client.
+func (p oauth2AuthorizeParams) errorRedirect(code, description string) string {
+	u := *p.redirectURL
+	q := u.Query()
+	q.Set("error", code)
+	if description != "" {
+		q.Set("error_description", description)
+	}
+	if p.state != "" {
+		q.Set("state", p.state)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// getOAuth2ProviderAppAuthorize asks the user to allow the application to
+// access their account.
+func (api *API) getOAuth2ProviderAppAuthorize(rw http.ResponseWriter, r *http.Request) {
+	params, ok := api.parseOAuth2AuthorizeParams(rw, r)
+	if !ok {
+		return
+	}
+
+	site.RenderOAuthAllowPage(rw, r, site.RenderOAuthAllowData{
+		AppIcon:   params.app.Icon,
+		AppName:   params.app.Name,
+		Username:  httpmw.UserAuthorization(r).ActorName,
+		Scopes:    oauth2ScopeDescriptions(params.scope),
+		CancelURI: params.errorRedirect("access_denied", ""),
+		CSRFToken: oauth2provider.CSRFToken(httpmw.APIKey(r)),
+	})
+}
+
+// oauth2ScopeDescriptions describes what a token with the scope can do on the
+// consent pages.
+func oauth2ScopeDescriptions(scope database.APIKeyScope) []string {
+	if scope == database.APIKeyScopeApplicationConnect {
+		return []string{"Connect to the applications of your workspaces."}
+	}
+	return []string{"Full access to your account."}
+}
+
+// postOAuth2ProviderAppAuthorize is submitted by the consent page. It issues
+// an authorization code and sends the user back to the application. The form
+// must carry the CSRF token rendered with the page so other sites cannot allow
+// the application on the user's behalf.
+func (api *API) postOAuth2ProviderAppAuthorize(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		apiKey = httpmw.APIKey(r)
+	)
+	if rejectImpersonation(rw, r) {
+		return
+	}
+	params, ok := api.parseOAuth2AuthorizeParams(rw, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid form.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !oauth2provider.VerifyCSRFToken(apiKey, r.PostForm.Get("csrf_token")) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Invalid or missing CSRF token. Reload the page and try again.",
+		})
+		return
+	}
+
+	code, err := oauth2provider.GenerateSecret()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate OAuth2 authorization code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	_, err = api.Database.InsertOAuth2ProviderAppCode(ctx, database.InsertOAuth2ProviderAppCodeParams{
+		ID:            uuid.New(),
+		CreatedAt:     database.Now(),
+		ExpiresAt:     database.Now().Add(oauth2provider.CodeLifetime),
+		SecretPrefix:  []byte(code.Prefix),
+		HashedSecret:  code.Hashed,
+		AppID:         params.app.ID,
+		UserID:        apiKey.UserID,
+		Scope:         params.scope,
+		RedirectURI:   params.redirectURI,
+		CodeChallenge: params.codeChallenge,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating OAuth2 authorization code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	u := *params.redirectURL
+	q := u.Query()
+	q.Set("code", code.Formatted)
+	if params.state != "" {
+		q.Set("state", params.state)
+	}
+	u.RawQuery = q.Encode()
+	// See other forces the browser to follow the redirect with a GET.
+	http.Redirect(rw, r, u.String(), http.StatusSeeOther)
+}
+
+// oauth2TokenResponse is the successful response of the token endpoint as
+// described by RFC 6749 section 5.1.
+type oauth2TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// oauth2Error is an error response of the token and revocation endpoints as
+// described by RFC 6749 section 5.2.
+type oauth2Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func writeOAuth2Error(ctx context.Context, rw http.ResponseWriter, status int, code, description string) {
+	rw.Header().Set("Cache-Control", "no-store")
+	httpapi.Write(ctx, rw, status, oauth2Error{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+// authenticateOAuth2Client verifies the client credentials of a token or
+// revocation request. Both HTTP basic authentication and form parameters are
+// accepted.
+func (api *API) authenticateOAuth2Client(ctx context.Context, rw http.ResponseWriter, r *http.Request) (database.OAuth2ProviderApp, database.OAuth2ProviderAppSecret, bool) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	invalid := func() (database.OAuth2ProviderApp, database.OAuth2ProviderAppSecret, bool) {
+		writeOAuth2Error(ctx, rw, http.StatusUnauthorized, "invalid_client", "Client authentication failed.")
+		return database.OAuth2ProviderApp{}, database.OAuth2ProviderAppSecret{}, false
+	}
+
+	appID, err := uuid.Parse(clientID)
+	if err != nil {
+		return invalid()
+	}
+	prefix, secretValue, err := oauth2provider.ParseSecret(clientSecret)
+	if err != nil {
+		return invalid()
+	}
+	secret, err := api.Database.GetOAuth2ProviderAppSecretByPrefix(ctx, []byte(prefix))
+	if httpapi.Is404Error(err) {
+		return invalid()
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return database.OAuth2ProviderApp{}, database.OAuth2ProviderAppSecret{}, false
+	}
+	if secret.AppID != appID || !oauth2provider.VerifySecret(secret.HashedSecret, secretValue) {
+		return invalid()
+	}
+	app, err := api.Database.GetOAuth2ProviderAppByID(ctx, appID)
+	if httpapi.Is404Error(err) {
+		return invalid()
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return database.OAuth2ProviderApp{}, database.OAuth2ProviderAppSecret{}, false
+	}
+	return app, secret, true
+}
+
+// postOAuth2ProviderAppToken exchanges an authorization code, a refresh token
+// or a device code for an access token. Clients authenticate with their client
+// secret rather than a session, so the database is accessed as the system.
+func (api *API) postOAuth2ProviderAppToken(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // The client is authenticated by its secret below.
+	ctx := dbauthz.AsSystemRestricted(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_request", "The request body is invalid.")
+		return
+	}
+	// The CLI is a public client without a secret, the device code
+	// authenticates it instead.
+	if r.PostForm.Get("grant_type") == codersdk.OAuth2DeviceGrantType {
+		api.oauth2DeviceCodeGrant(ctx, rw, r)
+		return
+	}
+	app, secret, ok := api.authenticateOAuth2Client(ctx, rw, r)
+	if !ok {
+		return
+	}
+
+	var (
+		token     oauth2TokenResponse
+		errCode   string
+		errDetail string
+		err       error
+	)
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case "authorization_code":
+		token, errCode, errDetail, err = api.oauth2AuthorizationCodeGrant(ctx, r, app, secret)
+	case "refresh_token":
+		token, errCode, errDetail, err = api.oauth2RefreshTokenGrant(ctx, r, app, secret)
+	default:
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("Grant type %q is not supported.", grantType))
+		return
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if errCode != "" {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, errCode, errDetail)
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	httpapi.Write(ctx, rw, http.StatusOK, token)
+}
+
+// oauth2AuthorizationCodeGrant redeems an authorization code. An error code is
+// returned for requests that were rejected, err is reserved for internal
+// errors.
+func (api *API) oauth2AuthorizationCodeGrant(ctx context.Context, r *http.Request, app database.OAuth2ProviderApp, secret database.OAuth2ProviderAppSecret) (oauth2TokenResponse, string, string, error) {
+	const invalidGrant = "invalid_grant"
+
+	prefix, codeValue, err := oauth2provider.ParseSecret(r.PostForm.Get("code"))
+	if err != nil {
+		return oauth2TokenResponse{}, invalidGrant, "The authorization code is invalid.", nil
+	}
+	code, err := api.Database.GetOAuth2ProviderAppCodeByPrefix(ctx, []byte(prefix))
+	if httpapi.Is404Error(err) {
+		return oauth2TokenResponse{}, invalidGrant, "The authorization code is invalid.", nil
+	}
+	if err != nil {
+		return oauth2TokenResponse{}, "", "", xerrors.Errorf("get authorization code: %w", err)
+	}
+	if code.AppID != app.ID || !oauth2provider.VerifySecret(code.HashedSecret, codeValue) {
+		return oauth2TokenResponse{}, invalidGrant, "The authorization code is invalid.", nil
+	}
+	if code.ExpiresAt.Before(database.Now()) {
+		return oauth2TokenResponse{}, invalidGrant, "The authorization code has expired.", nil
+	}
+	// The redirect_uri must be repeated if it was sent with the authorization
+	// request, see RFC 6749 section 4.1.3.
+	if code.RedirectURI != r.PostForm.Get("redirect_uri") {
+		return oauth2TokenResponse{}, invalidGrant, "The redirect_uri does not match the authorization request.", nil
+	}
+	if !oauth2provider.VerifyPKCE(code.CodeChallenge, r.PostForm.Get("code_verifier")) {
+		return oauth2TokenResponse{}, invalidGrant, "The code_verifier does not match the code challenge.", nil
+	}
+	active, err := api.oauth2UserActive(ctx, code.UserID)
+	if err != nil {
+		return oauth2TokenResponse{}, "", "", err
+	}
+	if !active {
+		return oauth2TokenResponse{}, invalidGrant, "The user is not active.", nil
+	}
+
+	var token oauth2TokenResponse
+	err = api.Database.InTx(func(tx database.Store) error {
+		// Codes can only be used once. No rows are returned if the code was
+		// redeemed concurrently.
+		_, err := tx.DeleteOAuth2ProviderAppCodeByID(ctx, code.ID)
+		if err != nil {
+			return xerrors.Errorf("delete authorization code: %w", err)
+		}
+		token, err = api.issueOAuth2ProviderAppToken(ctx, tx, r, secret, code.UserID, code.Scope)
+		return err
+	}, nil)
+	if err != nil {
+		// The code was redeemed concurrently.
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return oauth2TokenResponse{}, invalidGrant, "The authorization code is invalid.", nil
+		}
+		return oauth2TokenResponse{}, "", "", err
+	}
+	return token, "", "", nil
+}
+
+// oauth2RefreshTokenGrant exchanges a refresh token for a new access and
+// refresh token. The previous access token is revoked.
+func (api *API) oauth2RefreshTokenGrant(ctx context.Context, r *http.Request, app database.OAuth2ProviderApp, secret database.OAuth2ProviderAppSecret) (oauth2TokenResponse, string, string, error) {
+	const invalidGrant = "invalid_grant"
+
+	dbToken, key, ok, err := api.lookupOAuth2ProviderAppToken(ctx, app, r.PostForm.Get("refresh_token"))
+	if err != nil {
+		return oauth2TokenResponse{}, "", "", err
+	}
+	if !ok {
+		return oauth2TokenResponse{}, invalidGrant, "The refresh token is invalid.", nil
+	}
+	if dbToken.ExpiresAt.Before(database.Now()) {
+		return oauth2TokenResponse{}, invalidGrant, "The refresh token has expired.", nil
+	}
+	active, err := api.oauth2UserActive(ctx, key.UserID)
+	if err != nil {
+		return oauth2TokenResponse{}, "", "", err
+	}
+	if !active {
+		return oauth2TokenResponse{}, invalidGrant, "The user is not active.", nil
+	}
+
+	var token oauth2TokenResponse
+	err = api.Database.InTx(func(tx database.Store) error {
+		// Refresh tokens can only be used once. No rows are returned if the
+		// token was refreshed concurrently.
+		_, err := tx.DeleteOAuth2ProviderAppTokenByID(ctx, dbToken.ID)
+		if err != nil {
+			return xerrors.Errorf("delete token: %w", err)
+		}
+		err = tx.DeleteAPIKeyByID(ctx, key.ID)
+		if err != nil {
+			return xerrors.Errorf("delete api key: %w", err)
+		}
+		token, err = api.issueOAuth2ProviderAppToken(ctx, tx, r, secret, key.UserID, key.Scope)
+		return err
+	}, nil)
+	if err != nil {
+		// The token was refreshed concurrently.
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return oauth2TokenResponse{}, invalidGrant, "The refresh token is invalid.", nil
+		}
+		return oauth2TokenResponse{}, "", "", err
+	}
+	return token, "", "", nil
+}
+
+// lookupOAuth2ProviderAppToken returns the token matching a refresh token
+// issued to the app, along with its API key. False is returned if the refresh
+// token is invalid.
+func (api *API) lookupOAuth2ProviderAppToken(ctx context.Context, app database.OAuth2ProviderApp, refreshToken string) (database.OAuth2ProviderAppToken, database.APIKey, bool, error) {
+	prefix, tokenValue, err := oauth2provider.ParseSecret(refreshToken)
+	if err != nil {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, nil
+	}
+	dbToken, err := api.Database.GetOAuth2ProviderAppTokenByPrefix(ctx, []byte(prefix))
+	if httpapi.Is404Error(err) {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, nil
+	}
+	if err != nil {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, xerrors.Errorf("get token: %w", err)
+	}
+	if !oauth2provider.VerifySecret(dbToken.RefreshHash, tokenValue) {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, nil
+	}
+	// The token may have been issued with another secret of the same app.
+	tokenSecret, err := api.Database.GetOAuth2ProviderAppSecretByID(ctx, dbToken.AppSecretID)
+	if err != nil {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, xerrors.Errorf("get token secret: %w", err)
+	}
+	if tokenSecret.AppID != app.ID {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, nil
+	}
+	key, err := api.Database.GetAPIKeyByID(ctx, dbToken.APIKeyID)
+	if httpapi.Is404Error(err) {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, nil
+	}
+	if err != nil {
+		return database.OAuth2ProviderAppToken{}, database.APIKey{}, false, xerrors.Errorf("get api key: %w", err)
+	}
+	return dbToken, key, true, nil
+}
+
+func (api *API) oauth2UserActive(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := api.Database.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, xerrors.Errorf("get user: %w", err)
+	}
+	return user.Status == database.UserStatusActive && !user.Deleted, nil
+}
+
+// issueOAuth2ProviderAppToken creates an API key for the user and a refresh
+// token that can be used to replace it.
+func (api *API) issueOAuth2ProviderAppToken(ctx context.Context, tx database.Store, r *http.Request, secret database.OAuth2ProviderAppSecret, userID uuid.UUID, scope database.APIKeyScope) (oauth2TokenResponse, error) {
+	keyParams, sessionToken, err := apikey.Generate(apikey.CreateParams{
+		UserID:           userID,
+		LoginType:        database.LoginTypeOAuth2ProviderApp,
+		DeploymentValues: api.DeploymentValues,
+		Scope:            scope,
+		RemoteAddr:       r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
+	})
+	if err != nil {
+		return oauth2TokenResponse{}, xerrors.Errorf("generate api key: %w", err)
+	}
+	key, err := tx.InsertAPIKey(ctx, keyParams)
+	if err != nil {
+		return oauth2TokenResponse{}, xerrors.Errorf("insert api key: %w", err)
+	}
+
+	refresh, err := oauth2provider.GenerateSecret()
+	if err != nil {
+		return oauth2TokenResponse{}, xerrors.Errorf("generate refresh token: %w", err)
+	}
+	_, err = tx.InsertOAuth2ProviderAppToken(ctx, database.InsertOAuth2ProviderAppTokenParams{
+		ID:          uuid.New(),
+		CreatedAt:   database.Now(),
+		ExpiresAt:   database.Now().Add(oauth2provider.RefreshTokenLifetime),
+		HashPrefix:  []byte(refresh.Prefix),
+		RefreshHash: refresh.Hashed,
+		AppSecretID: secret.ID,
+		APIKeyID:    key.ID,
+	})
+	if err != nil {
+		return oauth2TokenResponse{}, xerrors.Errorf("insert token: %w", err)
+	}
+
+	_, err = tx.UpdateOAuth2ProviderAppSecretByID(ctx, database.UpdateOAuth2ProviderAppSecretByIDParams{
+		ID: secret.ID,
+		LastUsedAt: sql.NullTime{
+			Time:  database.Now(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return oauth2TokenResponse{}, xerrors.Errorf("update secret last used: %w", err)
+	}
+
+	api.Telemetry.Report(&telemetry.Snapshot{
+		APIKeys: []telemetry.APIKey{telemetry.ConvertAPIKey(key)},
+	})
+
+	return oauth2TokenResponse{
+		AccessToken:  sessionToken,
+		TokenType:    "Bearer",
+		RefreshToken: refresh.Formatted,
+		ExpiresIn:    int64(time.Until(key.ExpiresAt).Seconds()),
+	}, nil
+}
+
+// postOAuth2ProviderAppRevoke revokes a refresh token along with the access
+// token it belongs to, see RFC 7009. Access tokens can be revoked by the user
+// from the dashboard or by deleting the application's tokens.
+func (api *API) postOAuth2ProviderAppRevoke(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // The client is authenticated by its secret below.
+	ctx := dbauthz.AsSystemRestricted(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_request", "The request body is invalid.")
+		return
+	}
+	app, _, ok := api.authenticateOAuth2Client(ctx, rw, r)
+	if !ok {
+		return
+	}
+	if r.PostForm.Get("token_type_hint") == "access_token" {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "unsupported_token_type", "Only refresh tokens can be revoked.")
+		return
+	}
+
+	_, key, ok, err := api.lookupOAuth2ProviderAppToken(ctx, app, r.PostForm.Get("token"))
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	// Invalid tokens do not cause an error, the client cannot do anything
+	// about them.
+	if ok {
+		err = api.Database.DeleteAPIKeyByID(ctx, key.ID)
+		if err != nil && !httpapi.Is404Error(err) {
+			writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// deleteOAuth2ProviderAppTokens revokes every token and authorization code the
+// user has granted to an application.
+func (api *API) deleteOAuth2ProviderAppTokens(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		apiKey = httpmw.APIKey(r)
+	)
+
+	appID, err := uuid.Parse(r.URL.Query().Get("client_id"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid client_id.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.InTx(func(tx database.Store) error {
+		err := tx.DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx, database.DeleteOAuth2ProviderAppCodesByAppAndUserIDParams{
+			AppID:  appID,
+			UserID: apiKey.UserID,
+		})
+		if err != nil {
+			return xerrors.Errorf("delete codes: %w", err)
+		}
+		err = tx.DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx, database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams{
+			AppID:  appID,
+			UserID: apiKey.UserID,
+		})
+		if err != nil {
+			return xerrors.Errorf("delete tokens: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error revoking OAuth2 app tokens.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+func convertOAuth2ProviderApp(accessURL *url.URL, app database.OAuth2ProviderApp) codersdk.OAuth2ProviderApp {
+	return codersdk.OAuth2ProviderApp{
+		ID:          app.ID,
+		Name:        app.Name,
+		CallbackURL: app.CallbackURL,
+		Icon:        app.Icon,
+		Endpoints: codersdk.OAuth2AppEndpoints{
+			Authorization: accessURL.ResolveReference(&url.URL{Path: "/oauth2/authorize"}).String(),
+			Token:         accessURL.ResolveReference(&url.URL{Path: "/oauth2/tokens"}).String(),
+			Revocation:    accessURL.ResolveReference(&url.URL{Path: "/oauth2/revoke"}).String(),
+		},
+	}
+}
+
+func convertOAuth2ProviderApps(accessURL *url.URL, apps []database.OAuth2ProviderApp) []codersdk.OAuth2ProviderApp {
+	converted := make([]codersdk.OAuth2ProviderApp, 0, len(apps))
+	for _, app := range apps {
+		converted = append(converted, convertOAuth2ProviderApp(accessURL, app))
+	}
+	return converted
+}
+
+func convertOAuth2ProviderAppSecret(secret database.OAuth2ProviderAppSecret) codersdk.OAuth2ProviderAppSecret {
+	var lastUsedAt *time.Time
+	if secret.LastUsedAt.Valid {
+		lastUsedAt = &secret.LastUsedAt.Time
+	}
+	return codersdk.OAuth2ProviderAppSecret{
+		ID:                    secret.ID,
+		LastUsedAt:            lastUsedAt,
+		ClientSecretTruncated: secret.DisplaySecret,
+	}
+}
@@ -0,0 +1,389 @@
+package coderd_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOAuth2ProviderApps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		app, err := client.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:        "dashboards",
+			CallbackURL: "http://localhost:3000/callback",
+			Icon:        "/icon/code.svg",
+		})
+		require.NoError(t, err)
+		require.Equal(t, client.URL.String()+"/oauth2/authorize", app.Endpoints.Authorization)
+		require.Equal(t, client.URL.String()+"/oauth2/tokens", app.Endpoints.Token)
+
+		// Names are unique.
+		_, err = client.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:        "dashboards",
+			CallbackURL: "http://localhost:3000/callback",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		// Members can see apps so they know what they are authorizing, but
+		// cannot manage them.
+		apps, err := member.OAuth2ProviderApps(ctx, codersdk.OAuth2ProviderAppFilter{})
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		_, err = member.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:        "mine",
+			CallbackURL: "http://localhost:3000/callback",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		_, err = member.PostOAuth2ProviderAppSecret(ctx, app.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		updated, err := client.PutOAuth2ProviderApp(ctx, app.ID, codersdk.PutOAuth2ProviderAppRequest{
+			Name:        "dashboards-2",
+			CallbackURL: "https://dashboards.example.com/callback",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "dashboards-2", updated.Name)
+		require.Empty(t, updated.Icon)
+
+		secret, err := client.PostOAuth2ProviderAppSecret(ctx, app.ID)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(secret.ClientSecretFull, "coder_"))
+		secrets, err := client.OAuth2ProviderAppSecrets(ctx, app.ID)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		require.True(t, strings.HasSuffix(secret.ClientSecretFull, secrets[0].ClientSecretTruncated))
+		require.Nil(t, secrets[0].LastUsedAt)
+
+		err = client.DeleteOAuth2ProviderAppSecret(ctx, app.ID, secret.ID)
+		require.NoError(t, err)
+		secrets, err = client.OAuth2ProviderAppSecrets(ctx, app.ID)
+		require.NoError(t, err)
+		require.Empty(t, secrets)
+
+		err = client.DeleteOAuth2ProviderApp(ctx, app.ID)
+		require.NoError(t, err)
+		_, err = client.OAuth2ProviderApp(ctx, app.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestOAuth2ProviderAppFlow(t *testing.T) {
+	t.Parallel()
+
+	const callbackURL = "http://localhost:3000/callback"
+
+	setup := func(t *testing.T) (owner *codersdk.Client, member *codersdk.Client, app codersdk.OAuth2ProviderApp, config *oauth2.Config) {
+		owner = coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		member, _ = coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		app, err := owner.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:        "ide-plugin",
+			CallbackURL: callbackURL,
+		})
+		require.NoError(t, err)
+		secret, err := owner.PostOAuth2ProviderAppSecret(ctx, app.ID)
+		require.NoError(t, err)
+
+		return owner, member, app, &oauth2.Config{
+			ClientID:     app.ID.String(),
+			ClientSecret: secret.ClientSecretFull,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  app.Endpoints.Authorization,
+				TokenURL: app.Endpoints.Token,
+			},
+			RedirectURL: callbackURL,
+		}
+	}
+
+	t.Run("CodeWithPKCE", func(t *testing.T) {
+		t.Parallel()
+		owner, member, app, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		code := authorizeOAuth2ProviderApp(ctx, t, member, config, "state-1",
+			oauth2.SetAuthURLParam("code_challenge", pkceChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		)
+
+		// The verifier is required.
+		_, err := config.Exchange(ctx, code)
+		require.Error(t, err)
+
+		token, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+		require.NoError(t, err)
+		require.Equal(t, "Bearer", token.TokenType)
+		require.NotEmpty(t, token.RefreshToken)
+		require.True(t, token.Expiry.After(time.Now()))
+
+		// Codes can only be used once.
+		_, err = config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+		require.Error(t, err)
+
+		me := oauth2UserID(ctx, t, owner.URL, token.AccessToken)
+		memberUser, err := member.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, memberUser.ID, me)
+
+		// The app is listed as authorized by the user.
+		apps, err := member.OAuth2ProviderApps(ctx, codersdk.OAuth2ProviderAppFilter{UserID: memberUser.ID})
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		require.Equal(t, app.ID, apps[0].ID)
+
+		// OAuth2 tokens are not listed as sessions.
+		sessions, err := member.Sessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		for _, session := range sessions {
+			require.NotEqual(t, codersdk.LoginTypeOAuth2ProviderApp, session.LoginType)
+		}
+
+		secrets, err := owner.OAuth2ProviderAppSecrets(ctx, app.ID)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		require.NotNil(t, secrets[0].LastUsedAt)
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		t.Parallel()
+		owner, member, _, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		code := authorizeOAuth2ProviderApp(ctx, t, member, config, "")
+		token, err := config.Exchange(ctx, code)
+		require.NoError(t, err)
+
+		refreshed, err := config.TokenSource(ctx, &oauth2.Token{
+			RefreshToken: token.RefreshToken,
+			Expiry:       time.Now().Add(-time.Minute),
+		}).Token()
+		require.NoError(t, err)
+		require.NotEqual(t, token.AccessToken, refreshed.AccessToken)
+		require.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+
+		// The previous tokens are revoked.
+		requireOAuth2Unauthorized(ctx, t, owner.URL, token.AccessToken)
+		_, err = config.TokenSource(ctx, &oauth2.Token{
+			RefreshToken: token.RefreshToken,
+			Expiry:       time.Now().Add(-time.Minute),
+		}).Token()
+		require.Error(t, err)
+		_ = oauth2UserID(ctx, t, owner.URL, refreshed.AccessToken)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		t.Parallel()
+		owner, member, app, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		code := authorizeOAuth2ProviderApp(ctx, t, member, config, "")
+		token, err := config.Exchange(ctx, code)
+		require.NoError(t, err)
+		_ = oauth2UserID(ctx, t, owner.URL, token.AccessToken)
+
+		err = member.RevokeOAuth2ProviderApp(ctx, app.ID)
+		require.NoError(t, err)
+		requireOAuth2Unauthorized(ctx, t, owner.URL, token.AccessToken)
+
+		memberUser, err := member.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		apps, err := member.OAuth2ProviderApps(ctx, codersdk.OAuth2ProviderAppFilter{UserID: memberUser.ID})
+		require.NoError(t, err)
+		require.Empty(t, apps)
+	})
+
+	t.Run("RevokeEndpoint", func(t *testing.T) {
+		t.Parallel()
+		owner, member, app, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		code := authorizeOAuth2ProviderApp(ctx, t, member, config, "")
+		token, err := config.Exchange(ctx, code)
+		require.NoError(t, err)
+
+		res, err := http.PostForm(app.Endpoints.Revocation, url.Values{
+			"client_id":     {config.ClientID},
+			"client_secret": {config.ClientSecret},
+			"token":         {token.RefreshToken},
+		})
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		requireOAuth2Unauthorized(ctx, t, owner.URL, token.AccessToken)
+	})
+
+	t.Run("DeleteSecret", func(t *testing.T) {
+		t.Parallel()
+		owner, member, app, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		code := authorizeOAuth2ProviderApp(ctx, t, member, config, "")
+		token, err := config.Exchange(ctx, code)
+		require.NoError(t, err)
+
+		secrets, err := owner.OAuth2ProviderAppSecrets(ctx, app.ID)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		err = owner.DeleteOAuth2ProviderAppSecret(ctx, app.ID, secrets[0].ID)
+		require.NoError(t, err)
+		requireOAuth2Unauthorized(ctx, t, owner.URL, token.AccessToken)
+	})
+
+	t.Run("InvalidClient", func(t *testing.T) {
+		t.Parallel()
+		_, member, _, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		code := authorizeOAuth2ProviderApp(ctx, t, member, config, "")
+		invalid := *config
+		invalid.ClientSecret = "coder_invalid_secret"
+		_, err := invalid.Exchange(ctx, code)
+		var retrieveErr *oauth2.RetrieveError
+		require.ErrorAs(t, err, &retrieveErr)
+		require.Equal(t, http.StatusUnauthorized, retrieveErr.Response.StatusCode)
+	})
+
+	t.Run("InvalidRedirect", func(t *testing.T) {
+		t.Parallel()
+		_, member, _, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		invalid := *config
+		invalid.RedirectURL = "http://evil.example.com/callback"
+		res, err := member.Request(ctx, http.MethodPost, invalid.AuthCodeURL(""), nil)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("MissingCSRFToken", func(t *testing.T) {
+		t.Parallel()
+		_, member, _, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		res, err := member.Request(ctx, http.MethodPost, config.AuthCodeURL(""), nil)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("ConsentPage", func(t *testing.T) {
+		t.Parallel()
+		_, member, _, config := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		res, err := member.Request(ctx, http.MethodGet, config.AuthCodeURL("state-1"), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), "ide-plugin")
+		require.Contains(t, string(body), "error=access_denied")
+	})
+}
+
+// authorizeOAuth2ProviderApp allows the app on behalf of the client's user and
+// returns the authorization code.
+func authorizeOAuth2ProviderApp(ctx context.Context, t *testing.T, client *codersdk.Client, config *oauth2.Config, state string, opts ...oauth2.AuthCodeOption) string {
+	t.Helper()
+
+	noRedirect := codersdk.New(client.URL)
+	noRedirect.SetSessionToken(client.SessionToken())
+	noRedirect.HTTPClient = &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	authURL := config.AuthCodeURL(state, opts...)
+	res, err := noRedirect.Request(ctx, http.MethodGet, authURL, nil)
+	require.NoError(t, err)
+	page, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	form := url.Values{"csrf_token": {oauth2CSRFToken(t, string(page))}}
+	res, err = noRedirect.Request(ctx, http.MethodPost, authURL, strings.NewReader(form.Encode()), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	})
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusSeeOther, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(location.String(), config.RedirectURL))
+	require.Equal(t, state, location.Query().Get("state"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+	return code
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func oauth2Me(ctx context.Context, t *testing.T, serverURL *url.URL, accessToken string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL.String()+"/api/v2/users/me", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return res
+}
+
+// oauth2UserID returns the ID of the user the access token belongs to.
+func oauth2UserID(ctx context.Context, t *testing.T, serverURL *url.URL, accessToken string) uuid.UUID {
+	t.Helper()
+
+	res := oauth2Me(ctx, t, serverURL, accessToken)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var user codersdk.User
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&user))
+	return user.ID
+}
+
+func requireOAuth2Unauthorized(ctx context.Context, t *testing.T, serverURL *url.URL, accessToken string) {
+	t.Helper()
+
+	res := oauth2Me(ctx, t, serverURL, accessToken)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
@@ -0,0 +1,335 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/apikey"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/oauth2provider"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/site"
+)
+
+// postOAuth2DeviceAuthorization starts a device authorization request, see RFC
+// 8628 section 3.1. The CLI uses it to log in on machines without a browser:
+// the user approves the request from another device while the CLI polls the
+// token endpoint with the device code.
+func (api *API) postOAuth2DeviceAuthorization(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // The device is not authenticated yet.
+	ctx := dbauthz.AsSystemRestricted(r.Context())
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_request", "The request body is invalid.")
+		return
+	}
+	scope, err := oauth2provider.ParseScope(r.PostForm.Get("scope"))
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
+
+	deviceCode, err := oauth2provider.GenerateSecret()
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	userCode, err := oauth2provider.GenerateUserCode()
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	code, err := api.Database.InsertOAuth2DeviceCode(ctx, database.InsertOAuth2DeviceCodeParams{
+		ID:              uuid.New(),
+		CreatedAt:       database.Now(),
+		ExpiresAt:       database.Now().Add(oauth2provider.DeviceCodeLifetime),
+		SecretPrefix:    []byte(deviceCode.Prefix),
+		HashedSecret:    deviceCode.Hashed,
+		UserCode:        userCode,
+		Scope:           scope,
+		PollingInterval: int32(oauth2provider.DevicePollingInterval.Seconds()),
+	})
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	verification := api.AccessURL.ResolveReference(&url.URL{Path: "/oauth2/device"})
+	complete := *verification
+	complete.RawQuery = url.Values{"user_code": {code.UserCode}}.Encode()
+
+	rw.Header().Set("Cache-Control", "no-store")
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OAuth2DeviceAuthorization{
+		DeviceCode:              deviceCode.Formatted,
+		UserCode:                code.UserCode,
+		VerificationURI:         verification.String(),
+		VerificationURIComplete: complete.String(),
+		ExpiresIn:               int64(oauth2provider.DeviceCodeLifetime.Seconds()),
+		Interval:                int64(code.PollingInterval),
+	})
+}
+
+// oauth2DeviceCodeGrant exchanges the device code of an approved device
+// authorization request for a session token, see RFC 8628 section 3.4.
+func (api *API) oauth2DeviceCodeGrant(ctx context.Context, rw http.ResponseWriter, r *http.Request) {
+	const invalidGrant = "invalid_grant"
+
+	prefix, secret, err := oauth2provider.ParseSecret(r.PostForm.Get("device_code"))
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The device code is invalid.")
+		return
+	}
+	code, err := api.Database.GetOAuth2DeviceCodeByPrefix(ctx, []byte(prefix))
+	if httpapi.Is404Error(err) {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The device code is invalid.")
+		return
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if !oauth2provider.VerifySecret(code.HashedSecret, secret) {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The device code is invalid.")
+		return
+	}
+	now := database.Now()
+	if now.After(code.ExpiresAt) {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, codersdk.OAuth2ErrorExpiredToken, "The device code has expired.")
+		return
+	}
+
+	// Devices that poll faster than the interval are told to slow down and
+	// have to wait longer from then on.
+	interval := code.PollingInterval
+	slowDown := code.LastPolledAt.Valid && now.Sub(code.LastPolledAt.Time) < time.Duration(interval)*time.Second
+	if slowDown {
+		interval += int32(oauth2provider.DevicePollingInterval.Seconds())
+	}
+	code, err = api.Database.UpdateOAuth2DeviceCodePolledByID(ctx, database.UpdateOAuth2DeviceCodePolledByIDParams{
+		ID: code.ID,
+		LastPolledAt: sql.NullTime{
+			Time:  now,
+			Valid: true,
+		},
+		PollingInterval: interval,
+	})
+	// The code was redeemed or denied by a concurrent request.
+	if xerrors.Is(err, sql.ErrNoRows) {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The device code is invalid.")
+		return
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if slowDown {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, codersdk.OAuth2ErrorSlowDown, fmt.Sprintf("Wait %d seconds between requests.", interval))
+		return
+	}
+
+	switch code.Status {
+	case database.OAuth2DeviceCodeStatusPending:
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, codersdk.OAuth2ErrorAuthorizationPending, "The user has not approved the request yet.")
+		return
+	case database.OAuth2DeviceCodeStatusDenied:
+		_, err = api.Database.DeleteOAuth2DeviceCodeByID(ctx, code.ID)
+		if xerrors.Is(err, sql.ErrNoRows) {
+			writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The device code is invalid.")
+			return
+		}
+		if err != nil {
+			writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, codersdk.OAuth2ErrorAccessDenied, "The user denied the request.")
+		return
+	}
+
+	// Approved device codes can only be redeemed once. No rows are returned if
+	// the code was redeemed concurrently.
+	_, err = api.Database.DeleteOAuth2DeviceCodeByID(ctx, code.ID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The device code is invalid.")
+		return
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	active, err := api.oauth2UserActive(ctx, code.UserID.UUID)
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if !active {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, invalidGrant, "The user is not active.")
+		return
+	}
+
+	// The token is the same kind of key the CLI gets from /cli-auth.
+	lifeTime := time.Hour * 24 * 7
+	cookie, _, err := api.createAPIKey(ctx, apikey.CreateParams{
+		UserID:           code.UserID.UUID,
+		DeploymentValues: api.DeploymentValues,
+		LoginType:        database.LoginTypePassword,
+		Scope:            code.Scope,
+		RemoteAddr:       r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
+		ExpiresAt:        database.Now().Add(lifeTime),
+		LifetimeSeconds:  int64(lifeTime.Seconds()),
+	})
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OAuth2DeviceToken{
+		AccessToken: cookie.Value,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifeTime.Seconds()),
+	})
+}
+
+// getOAuth2DeviceVerification renders the page where the user enters the user
+// code shown by the device, and then asks them to approve the request.
+func (api *API) getOAuth2DeviceVerification(rw http.ResponseWriter, r *http.Request) {
+	data := site.RenderOAuthDeviceData{
+		Username:  httpmw.UserAuthorization(r).ActorName,
+		CSRFToken: oauth2provider.CSRFToken(httpmw.APIKey(r)),
+	}
+	userCode := r.URL.Query().Get("user_code")
+	if userCode == "" {
+		site.RenderOAuthDevicePage(rw, r, data)
+		return
+	}
+
+	code, message, err := api.pendingOAuth2DeviceCode(r.Context(), userCode)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching device code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if message != "" {
+		data.Error = message
+		site.RenderOAuthDevicePage(rw, r, data)
+		return
+	}
+	data.UserCode = code.UserCode
+	data.Scopes = oauth2ScopeDescriptions(code.Scope)
+	site.RenderOAuthDevicePage(rw, r, data)
+}
+
+// postOAuth2DeviceVerification is submitted by the verification page to
+// approve or deny a device authorization request. The form must carry the CSRF
+// token rendered with the page so other sites cannot submit it on the user's
+// behalf.
+func (api *API) postOAuth2DeviceVerification(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		apiKey = httpmw.APIKey(r)
+		data   = site.RenderOAuthDeviceData{
+			Username:  httpmw.UserAuthorization(r).ActorName,
+			CSRFToken: oauth2provider.CSRFToken(apiKey),
+		}
+	)
+	if rejectImpersonation(rw, r) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid form.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !oauth2provider.VerifyCSRFToken(apiKey, r.PostForm.Get("csrf_token")) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Invalid or missing CSRF token. Reload the page and try again.",
+		})
+		return
+	}
+
+	code, message, err := api.pendingOAuth2DeviceCode(ctx, r.PostForm.Get("user_code"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching device code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if message != "" {
+		data.Error = message
+		site.RenderOAuthDevicePage(rw, r, data)
+		return
+	}
+
+	status := database.OAuth2DeviceCodeStatusDenied
+	data.Done = "The request was denied. You can close this window."
+	if r.PostForm.Get("action") == "approve" {
+		status = database.OAuth2DeviceCodeStatusApproved
+		data.Done = fmt.Sprintf("The device is now signed in as %s. You can close this window and return to your device.", data.Username)
+	}
+	//nolint:gocritic // Device codes belong to no one until they are approved.
+	_, err = api.Database.UpdateOAuth2DeviceCodeStatusByID(dbauthz.AsSystemRestricted(ctx), database.UpdateOAuth2DeviceCodeStatusByIDParams{
+		ID:     code.ID,
+		Status: status,
+		UserID: uuid.NullUUID{
+			UUID:  apiKey.UserID,
+			Valid: true,
+		},
+	})
+	if httpapi.Is404Error(err) {
+		// Someone else responded to the request in the meantime.
+		site.RenderOAuthDevicePage(rw, r, site.RenderOAuthDeviceData{
+			Username:  data.Username,
+			Error:     "The code is invalid or has expired.",
+			CSRFToken: data.CSRFToken,
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating device code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	site.RenderOAuthDevicePage(rw, r, data)
+}
+
+// pendingOAuth2DeviceCode returns the device authorization request for a user
+// code entered by the user. If there is no pending request a message to show
+// the user is returned instead.
+func (api *API) pendingOAuth2DeviceCode(ctx context.Context, userCode string) (database.OAuth2DeviceCode, string, error) {
+	const invalid = "The code is invalid or has expired."
+
+	normalized, ok := oauth2provider.NormalizeUserCode(userCode)
+	if !ok {
+		return database.OAuth2DeviceCode{}, invalid, nil
+	}
+	//nolint:gocritic // Device codes belong to no one until they are approved.
+	code, err := api.Database.GetOAuth2DeviceCodeByUserCode(dbauthz.AsSystemRestricted(ctx), normalized)
+	if httpapi.Is404Error(err) {
+		return database.OAuth2DeviceCode{}, invalid, nil
+	}
+	if err != nil {
+		return database.OAuth2DeviceCode{}, "", err
+	}
+	if code.Status != database.OAuth2DeviceCodeStatusPending || database.Now().After(code.ExpiresAt) {
+		return database.OAuth2DeviceCode{}, invalid, nil
+	}
+	return code, "", nil
+}
@@ -0,0 +1,201 @@
+package coderd_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOAuth2DeviceFlow(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (anonymous *codersdk.Client, member *codersdk.Client, memberID codersdk.User) {
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		member, memberID = coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+		return codersdk.New(owner.URL), member, memberID
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		t.Parallel()
+		anonymous, member, user := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		auth, err := anonymous.OAuth2DeviceAuthorization(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, anonymous.URL.String()+"/oauth2/device", auth.VerificationURI)
+		require.Contains(t, auth.VerificationURIComplete, url.QueryEscape(auth.UserCode))
+		require.EqualValues(t, 5, auth.Interval)
+
+		// The user enters the code in lowercase and without the dash.
+		respondOAuth2Device(ctx, t, member, strings.ToLower(strings.ReplaceAll(auth.UserCode, "-", "")), "approve")
+
+		token, err := anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		require.NoError(t, err)
+		require.Equal(t, "Bearer", token.TokenType)
+		require.Equal(t, user.ID, oauth2UserID(ctx, t, anonymous.URL, token.AccessToken))
+
+		// Device codes can only be redeemed once.
+		_, err = anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		requireOAuth2ErrorCode(t, err, "invalid_grant")
+	})
+
+	t.Run("PendingAndSlowDown", func(t *testing.T) {
+		t.Parallel()
+		anonymous, _, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		auth, err := anonymous.OAuth2DeviceAuthorization(ctx, "")
+		require.NoError(t, err)
+
+		_, err = anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		requireOAuth2ErrorCode(t, err, codersdk.OAuth2ErrorAuthorizationPending)
+		_, err = anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		requireOAuth2ErrorCode(t, err, codersdk.OAuth2ErrorSlowDown)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+		anonymous, member, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		auth, err := anonymous.OAuth2DeviceAuthorization(ctx, "")
+		require.NoError(t, err)
+		respondOAuth2Device(ctx, t, member, auth.UserCode, "deny")
+
+		_, err = anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		requireOAuth2ErrorCode(t, err, codersdk.OAuth2ErrorAccessDenied)
+	})
+
+	t.Run("ApplicationConnectScope", func(t *testing.T) {
+		t.Parallel()
+		anonymous, member, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		auth, err := anonymous.OAuth2DeviceAuthorization(ctx, "application_connect")
+		require.NoError(t, err)
+		respondOAuth2Device(ctx, t, member, auth.UserCode, "approve")
+
+		token, err := anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		require.NoError(t, err)
+		key, err := member.APIKeyByID(ctx, codersdk.Me, strings.Split(token.AccessToken, "-")[0])
+		require.NoError(t, err)
+		require.Equal(t, codersdk.APIKeyScopeApplicationConnect, key.Scope)
+	})
+
+	t.Run("InvalidScope", func(t *testing.T) {
+		t.Parallel()
+		anonymous, _, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := anonymous.OAuth2DeviceAuthorization(ctx, "openid")
+		requireOAuth2ErrorCode(t, err, "invalid_scope")
+	})
+
+	t.Run("InvalidDeviceCode", func(t *testing.T) {
+		t.Parallel()
+		anonymous, _, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := anonymous.OAuth2DeviceToken(ctx, "coder_invalid_code")
+		requireOAuth2ErrorCode(t, err, "invalid_grant")
+	})
+
+	t.Run("VerificationPage", func(t *testing.T) {
+		t.Parallel()
+		anonymous, member, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		auth, err := anonymous.OAuth2DeviceAuthorization(ctx, "")
+		require.NoError(t, err)
+
+		body := getOAuth2DevicePage(ctx, t, member, auth.VerificationURIComplete)
+		require.Contains(t, body, auth.UserCode)
+		require.Contains(t, body, `value="approve"`)
+
+		// The form cannot be submitted without the page's CSRF token.
+		form := url.Values{
+			"user_code":  {auth.UserCode},
+			"action":     {"approve"},
+			"csrf_token": {"invalid"},
+		}
+		res, err := member.Request(ctx, http.MethodPost, "/oauth2/device", strings.NewReader(form.Encode()), func(r *http.Request) {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		})
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		_, err = anonymous.OAuth2DeviceToken(ctx, auth.DeviceCode)
+		requireOAuth2ErrorCode(t, err, codersdk.OAuth2ErrorAuthorizationPending)
+
+		body = getOAuth2DevicePage(ctx, t, member, auth.VerificationURI+"?user_code=BCDF-GHJK")
+		require.Contains(t, body, "The code is invalid or has expired.")
+
+		// Codes cannot be used once they were responded to.
+		respondOAuth2Device(ctx, t, member, auth.UserCode, "deny")
+		body = getOAuth2DevicePage(ctx, t, member, auth.VerificationURIComplete)
+		require.Contains(t, body, "The code is invalid or has expired.")
+	})
+}
+
+// respondOAuth2Device submits the verification page on behalf of the client's
+// user.
+func respondOAuth2Device(ctx context.Context, t *testing.T, client *codersdk.Client, userCode, action string) {
+	t.Helper()
+
+	page := getOAuth2DevicePage(ctx, t, client, "/oauth2/device?user_code="+url.QueryEscape(userCode))
+	form := url.Values{
+		"user_code":  {userCode},
+		"action":     {action},
+		"csrf_token": {oauth2CSRFToken(t, page)},
+	}
+	res, err := client.Request(ctx, http.MethodPost, "/oauth2/device", strings.NewReader(form.Encode()), func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	})
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), "The code is invalid or has expired.")
+}
+
+func getOAuth2DevicePage(ctx context.Context, t *testing.T, client *codersdk.Client, pageURL string) string {
+	t.Helper()
+
+	res, err := client.Request(ctx, http.MethodGet, pageURL, nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// oauth2CSRFToken returns the CSRF token rendered into an OAuth2 form.
+func oauth2CSRFToken(t *testing.T, page string) string {
+	t.Helper()
+
+	match := regexp.MustCompile(`name="csrf_token" value="([^"]+)"`).FindStringSubmatch(page)
+	require.Len(t, match, 2, "page has no CSRF token")
+	return match[1]
+}
+
+func requireOAuth2ErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var oauthErr *codersdk.OAuth2Error
+	require.True(t, errors.As(err, &oauthErr), "expected an OAuth2 error, got %v", err)
+	require.Equal(t, code, oauthErr.Code)
+}
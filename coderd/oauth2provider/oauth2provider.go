@@ -0,0 +1,210 @@
+// Package oauth2provider contains the primitives used by Coder when it acts as
+// an OAuth2 authorization server for third-party applications: client secrets,
+// authorization codes and refresh tokens, scope mapping and PKCE. It also
+// contains the user codes of the device authorization grant used by the CLI.
+package oauth2provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/cryptorand"
+)
+
+const (
+	// CodeLifetime is how long an authorization code can be exchanged for a
+	// token.
+	CodeLifetime = 10 * time.Minute
+	// RefreshTokenLifetime is how long a refresh token can be used to obtain a
+	// new access token.
+	RefreshTokenLifetime = 30 * 24 * time.Hour
+	// DeviceCodeLifetime is how long the user has to approve a device
+	// authorization request.
+	DeviceCodeLifetime = 10 * time.Minute
+	// DevicePollingInterval is the initial minimum interval between device
+	// access token requests. Devices that poll faster are told to slow down
+	// and the interval is increased by the same amount, see RFC 8628 section
+	// 3.5.
+	DevicePollingInterval = 5 * time.Second
+
+	// secretPrefix is prepended to every formatted secret so they are easy to
+	// recognize in logs and secret scanners.
+	secretPrefix = "coder"
+	prefixLength = 10
+	secretLength = 40
+
+	// userCodeCharset only contains consonants so user codes are easy to type
+	// and cannot spell words, see RFC 8628 section 6.1.
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+)
+
+// Secret is a client secret, authorization code or refresh token.
+type Secret struct {
+	// Formatted is the value handed to the client. It is only ever shown once.
+	Formatted string
+	// Prefix is stored in plain text and used to look the secret up.
+	Prefix string
+	// Secret is the random part of the secret.
+	Secret string
+	// Hashed is what is stored in the database.
+	Hashed []byte
+}
+
+// GenerateSecret returns a new random secret.
+func GenerateSecret() (Secret, error) {
+	prefix, err := cryptorand.String(prefixLength)
+	if err != nil {
+		return Secret{}, xerrors.Errorf("generate prefix: %w", err)
+	}
+	secret, err := cryptorand.String(secretLength)
+	if err != nil {
+		return Secret{}, xerrors.Errorf("generate secret: %w", err)
+	}
+	hashed := sha256.Sum256([]byte(secret))
+	return Secret{
+		Formatted: fmt.Sprintf("%s_%s_%s", secretPrefix, prefix, secret),
+		Prefix:    prefix,
+		Secret:    secret,
+		Hashed:    hashed[:],
+	}, nil
+}
+
+// ParseSecret splits a formatted secret into its prefix and secret parts.
+func ParseSecret(formatted string) (prefix string, secret string, err error) {
+	parts := strings.Split(formatted, "_")
+	if len(parts) != 3 || parts[0] != secretPrefix {
+		return "", "", xerrors.New("incorrectly formatted secret")
+	}
+	if parts[1] == "" || parts[2] == "" {
+		return "", "", xerrors.New("incorrectly formatted secret")
+	}
+	return parts[1], parts[2], nil
+}
+
+// VerifySecret reports whether secret matches the stored hash.
+func VerifySecret(hashed []byte, secret string) bool {
+	sum := sha256.Sum256([]byte(secret))
+	return subtle.ConstantTimeCompare(hashed, sum[:]) == 1
+}
+
+// ParseScope maps a space-separated OAuth2 scope onto an API key scope. An
+// empty scope grants full access to the user's account.
+func ParseScope(scope string) (database.APIKeyScope, error) {
+	result := database.APIKeyScopeAll
+	seen := false
+	for _, s := range strings.Fields(scope) {
+		var parsed database.APIKeyScope
+		switch s {
+		case "all":
+			parsed = database.APIKeyScopeAll
+		case "application_connect":
+			parsed = database.APIKeyScopeApplicationConnect
+		default:
+			return "", xerrors.Errorf("unsupported scope %q", s)
+		}
+		if seen && parsed != result {
+			return "", xerrors.New("only one of \"all\" and \"application_connect\" may be requested")
+		}
+		result = parsed
+		seen = true
+	}
+	return result, nil
+}
+
+// VerifyPKCE checks a code verifier against an S256 code challenge. An empty
+// challenge means the client did not use PKCE, in which case a verifier must
+// not be sent either.
+func VerifyPKCE(challenge, verifier string) bool {
+	if challenge == "" {
+		return verifier == ""
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(computed)) == 1
+}
+
+// VerifyRedirectURI reports whether redirect is allowed by the registered
+// callback URL. The scheme and host must match and the path must either equal
+// the callback's path or be below it. Dot segments are rejected, even when
+// percent-encoded, so a redirect cannot climb out of the callback's path once
+// the client resolves it.
+func VerifyRedirectURI(callback, redirect string) bool {
+	cb, err := url.Parse(callback)
+	if err != nil {
+		return false
+	}
+	ru, err := url.Parse(redirect)
+	if err != nil {
+		return false
+	}
+	if cb.Scheme != ru.Scheme || cb.Host != ru.Host || ru.User != nil {
+		return false
+	}
+	for _, segment := range strings.Split(ru.EscapedPath(), "/") {
+		segment, err = url.PathUnescape(segment)
+		if err != nil {
+			return false
+		}
+		if segment == "." || segment == ".." || strings.ContainsAny(segment, "/\\") {
+			return false
+		}
+	}
+
+	cbPath := path.Clean("/" + cb.Path)
+	ruPath := path.Clean("/" + ru.Path)
+	if cbPath == "/" {
+		return true
+	}
+	return ruPath == cbPath || strings.HasPrefix(ruPath, cbPath+"/")
+}
+
+// CSRFToken returns the token that must be submitted with the consent and
+// device verification forms. It is derived from the session's API key so it
+// cannot be guessed by another site and stops working once the session ends.
+func CSRFToken(key database.APIKey) string {
+	mac := hmac.New(sha256.New, key.HashedSecret)
+	_, _ = mac.Write([]byte("oauth2-form:" + key.ID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCSRFToken reports whether token was issued for the session's API key.
+func VerifyCSRFToken(key database.APIKey, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(CSRFToken(key)), []byte(token)) == 1
+}
+
+// GenerateUserCode returns a new user code for a device authorization request
+// in the XXXX-XXXX format.
+func GenerateUserCode() (string, error) {
+	code, err := cryptorand.StringCharset(userCodeCharset, userCodeLength)
+	if err != nil {
+		return "", xerrors.Errorf("generate user code: %w", err)
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:], nil
+}
+
+// NormalizeUserCode formats a user code entered by the user so it can be
+// compared to a generated one. Case, dashes and whitespace are ignored.
+func NormalizeUserCode(code string) (string, bool) {
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, code)
+	if len(code) != userCodeLength || strings.Trim(code, userCodeCharset) != "" {
+		return "", false
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:], true
+}
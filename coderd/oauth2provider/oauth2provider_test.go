@@ -0,0 +1,135 @@
+package oauth2provider_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/oauth2provider"
+)
+
+func TestSecret(t *testing.T) {
+	t.Parallel()
+
+	secret, err := oauth2provider.GenerateSecret()
+	require.NoError(t, err)
+
+	prefix, raw, err := oauth2provider.ParseSecret(secret.Formatted)
+	require.NoError(t, err)
+	require.Equal(t, secret.Prefix, prefix)
+	require.Equal(t, secret.Secret, raw)
+	require.True(t, oauth2provider.VerifySecret(secret.Hashed, raw))
+	require.False(t, oauth2provider.VerifySecret(secret.Hashed, raw+"x"))
+
+	for _, invalid := range []string{"", "coder", "coder__x", "other_a_b", "coder_a_b_c"} {
+		_, _, err := oauth2provider.ParseSecret(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	t.Parallel()
+
+	for scope, expected := range map[string]database.APIKeyScope{
+		"":                       database.APIKeyScopeAll,
+		"all":                    database.APIKeyScopeAll,
+		"application_connect":    database.APIKeyScopeApplicationConnect,
+		"all all":                database.APIKeyScopeAll,
+		" application_connect  ": database.APIKeyScopeApplicationConnect,
+	} {
+		parsed, err := oauth2provider.ParseScope(scope)
+		require.NoError(t, err, scope)
+		require.Equal(t, expected, parsed, scope)
+	}
+
+	for _, scope := range []string{"openid", "all application_connect"} {
+		_, err := oauth2provider.ParseScope(scope)
+		require.Error(t, err, scope)
+	}
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	t.Parallel()
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	require.True(t, oauth2provider.VerifyPKCE(challenge, verifier))
+	require.False(t, oauth2provider.VerifyPKCE(challenge, "wrong"))
+	require.False(t, oauth2provider.VerifyPKCE(challenge, ""))
+	require.True(t, oauth2provider.VerifyPKCE("", ""))
+	require.False(t, oauth2provider.VerifyPKCE("", verifier))
+}
+
+func TestVerifyRedirectURI(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		callback string
+		redirect string
+		allowed  bool
+	}{
+		{"https://app.example.com/callback", "https://app.example.com/callback", true},
+		{"https://app.example.com/callback", "https://app.example.com/callback/sub", true},
+		{"https://app.example.com/callback", "https://app.example.com/callbackx", false},
+		{"https://app.example.com/callback", "http://app.example.com/callback", false},
+		{"https://app.example.com/callback", "https://evil.example.com/callback", false},
+		{"https://app.example.com", "https://app.example.com/", true},
+		{"https://app.example.com/", "https://app.example.com/anything", true},
+		{"https://app.example.com/callback/", "https://app.example.com/callback", true},
+		{"https://app.example.com/callback", "https://app.example.com/callback/../admin", false},
+		{"https://app.example.com/callback", "https://app.example.com/callback/./sub", false},
+		{"https://app.example.com/callback", "https://app.example.com/callback/%2e%2e/admin", false},
+		{"https://app.example.com/callback", "https://app.example.com/callback/%2E%2E/admin", false},
+		{"https://app.example.com/callback", "https://app.example.com/callback/..%2fadmin", false},
+		{"https://app.example.com/callback", "https://app.example.com/callback/..%5cadmin", false},
+		{"https://app.example.com/callback", "https://user@app.example.com/callback", false},
+	} {
+		require.Equal(t, tc.allowed, oauth2provider.VerifyRedirectURI(tc.callback, tc.redirect), "%s -> %s", tc.callback, tc.redirect)
+	}
+}
+
+func TestUserCode(t *testing.T) {
+	t.Parallel()
+
+	code, err := oauth2provider.GenerateUserCode()
+	require.NoError(t, err)
+	require.Len(t, code, 9)
+	require.Equal(t, "-", code[4:5])
+
+	normalized, ok := oauth2provider.NormalizeUserCode(code)
+	require.True(t, ok)
+	require.Equal(t, code, normalized)
+
+	for input, expected := range map[string]string{
+		"bcdf-ghjk":   "BCDF-GHJK",
+		"BCDFGHJK":    "BCDF-GHJK",
+		" bcdf ghjk ": "BCDF-GHJK",
+	} {
+		normalized, ok := oauth2provider.NormalizeUserCode(input)
+		require.True(t, ok, input)
+		require.Equal(t, expected, normalized, input)
+	}
+
+	for _, input := range []string{"", "BCDF-GHJ", "BCDF-GHJKL", "ABCD-EFGH"} {
+		_, ok := oauth2provider.NormalizeUserCode(input)
+		require.False(t, ok, input)
+	}
+}
+
+func TestCSRFToken(t *testing.T) {
+	t.Parallel()
+
+	key := database.APIKey{ID: "abcdefghij", HashedSecret: []byte("secret")}
+	other := database.APIKey{ID: "abcdefghij", HashedSecret: []byte("other")}
+
+	token := oauth2provider.CSRFToken(key)
+	require.NotEmpty(t, token)
+	require.True(t, oauth2provider.VerifyCSRFToken(key, token))
+	require.False(t, oauth2provider.VerifyCSRFToken(other, token))
+	require.False(t, oauth2provider.VerifyCSRFToken(key, ""))
+}
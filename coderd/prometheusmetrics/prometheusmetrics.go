@@ -3,6 +3,7 @@ package prometheusmetrics
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -19,6 +20,8 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/db2sdk"
 	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/derpmap"
+	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/tailnet"
 )
 
@@ -143,7 +146,7 @@ func Workspaces(ctx context.Context, registerer prometheus.Registerer, db databa
 }
 
 // Agents tracks the total number of workspaces with labels on status.
-func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Registerer, db database.Store, coordinator *atomic.Pointer[tailnet.Coordinator], derpMap *tailcfg.DERPMap, agentInactiveDisconnectTimeout, duration time.Duration) (func(), error) {
+func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Registerer, db database.Store, coordinator *atomic.Pointer[tailnet.Coordinator], derpMapProvider *atomic.Pointer[derpmap.Provider], agentInactiveDisconnectTimeout, duration time.Duration) (func(), error) {
 	if duration == 0 {
 		duration = 1 * time.Minute
 	}
@@ -181,6 +184,17 @@ func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Regis
 		return nil, err
 	}
 
+	agentsPeerConnectionsGauge := NewCachedGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "coderd",
+		Subsystem: "agents",
+		Name:      "peer_connections",
+		Help:      "Tailnet connections to agents by type, as last reported by the agents.",
+	}, []string{agentNameLabel, usernameLabel, workspaceNameLabel, "type", "derp_region"}))
+	err = registerer.Register(agentsPeerConnectionsGauge)
+	if err != nil {
+		return nil, err
+	}
+
 	agentsAppsGauge := NewCachedGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "coderd",
 		Subsystem: "agents",
@@ -225,6 +239,7 @@ func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Regis
 			logger.Debug(ctx, "Agent metrics collection is starting")
 			timer := prometheus.NewTimer(metricsCollectorAgents)
 
+			var derpMap *tailcfg.DERPMap
 			workspaceRows, err := db.GetWorkspaces(ctx, database.GetWorkspacesParams{
 				AgentInactiveDisconnectTimeoutSeconds: int64(agentInactiveDisconnectTimeout.Seconds()),
 			})
@@ -233,6 +248,12 @@ func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Regis
 				goto done
 			}
 
+			derpMap, err = (*derpMapProvider.Load()).All(ctx)
+			if err != nil {
+				logger.Error(ctx, "can't get DERP map", slog.Error(err))
+				goto done
+			}
+
 			for _, workspace := range workspaceRows {
 				user, err := db.GetUserByID(ctx, workspace.OwnerID)
 				if err != nil {
@@ -295,6 +316,26 @@ func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Regis
 						}
 					}
 
+					// Collect information about direct and relayed connections
+					if connectionStatus.Status == database.WorkspaceAgentStatusConnected {
+						var peerConnections []agentsdk.PeerConnection
+						err = json.Unmarshal(agent.PeerConnections, &peerConnections)
+						if err != nil {
+							logger.Error(ctx, "can't unmarshal peer connections", slog.F("agent_id", agent.ID), slog.Error(err))
+						}
+						for _, peerConnection := range peerConnections {
+							if peerConnection.Direct {
+								agentsPeerConnectionsGauge.WithLabelValues(VectorOperationAdd, 1, agent.Name, user.Username, workspace.Name, "direct", "")
+								continue
+							}
+							regionName := fmt.Sprintf("Unnamed %d", peerConnection.DERPRegionID)
+							if region, found := derpMap.Regions[peerConnection.DERPRegionID]; found {
+								regionName = region.RegionName
+							}
+							agentsPeerConnectionsGauge.WithLabelValues(VectorOperationAdd, 1, agent.Name, user.Username, workspace.Name, "relayed", regionName)
+						}
+					}
+
 					// Collect information about registered applications
 					apps, err := db.GetWorkspaceAppsByAgentID(ctx, agent.ID)
 					if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -311,6 +352,7 @@ func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Regis
 			agentsGauge.Commit()
 			agentsConnectionsGauge.Commit()
 			agentsConnectionLatenciesGauge.Commit()
+			agentsPeerConnectionsGauge.Commit()
 			agentsAppsGauge.Commit()
 
 		done:
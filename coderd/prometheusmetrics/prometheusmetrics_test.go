@@ -23,6 +23,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbfake"
 	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/derpmap"
 	"github.com/coder/coder/coderd/prometheusmetrics"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
@@ -303,6 +304,9 @@ func TestAgents(t *testing.T) {
 	coordinatorPtr := atomic.Pointer[tailnet.Coordinator]{}
 	coordinatorPtr.Store(&coordinator)
 	derpMap := tailnettest.RunDERPAndSTUN(t)
+	derpMapProvider := derpmap.NewAGPLProvider(derpMap)
+	derpMapProviderPtr := atomic.Pointer[derpmap.Provider]{}
+	derpMapProviderPtr.Store(&derpMapProvider)
 	agentInactiveDisconnectTimeout := 1 * time.Hour // don't need to focus on this value in tests
 	registry := prometheus.NewRegistry()
 
@@ -312,7 +316,7 @@ func TestAgents(t *testing.T) {
 	// when
 	closeFunc, err := prometheusmetrics.Agents(ctx, slogtest.Make(t, &slogtest.Options{
 		IgnoreErrors: true,
-	}), registry, db, &coordinatorPtr, derpMap, agentInactiveDisconnectTimeout, time.Millisecond)
+	}), registry, db, &coordinatorPtr, &derpMapProviderPtr, agentInactiveDisconnectTimeout, time.Millisecond)
 	require.NoError(t, err)
 	t.Cleanup(closeFunc)
 
@@ -483,3 +487,92 @@ func prepareWorkspaceAndAgent(t *testing.T, client *codersdk.Client, user coders
 	agentClient.SetSessionToken(authToken)
 	return agentClient
 }
+
+func TestAgentsPeerConnections(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	user := dbgen.User(t, db, database.User{})
+	org := dbgen.Organization(t, db, database.Organization{})
+	template := dbgen.Template(t, db, database.Template{OrganizationID: org.ID})
+	workspace := dbgen.Workspace(t, db, database.Workspace{
+		OwnerID:        user.ID,
+		OrganizationID: org.ID,
+		TemplateID:     template.ID,
+	})
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{OrganizationID: org.ID})
+	build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID: workspace.ID,
+		JobID:       job.ID,
+	})
+	resource := dbgen.WorkspaceResource(t, db, database.WorkspaceResource{JobID: build.JobID})
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: resource.ID})
+
+	now := database.Now()
+	err := db.UpdateWorkspaceAgentConnectionByID(context.Background(), database.UpdateWorkspaceAgentConnectionByIDParams{
+		ID:               agent.ID,
+		FirstConnectedAt: sql.NullTime{Time: now, Valid: true},
+		LastConnectedAt:  sql.NullTime{Time: now, Valid: true},
+		UpdatedAt:        now,
+	})
+	require.NoError(t, err)
+
+	derpMap := tailnettest.RunDERPAndSTUN(t)
+	regionID := derpMap.RegionIDs()[0]
+	peerConnections, err := json.Marshal([]agentsdk.PeerConnection{
+		{Direct: true},
+		{Direct: true},
+		{DERPRegionID: regionID},
+	})
+	require.NoError(t, err)
+	err = db.UpdateWorkspaceAgentPeerConnectionsByID(context.Background(), database.UpdateWorkspaceAgentPeerConnectionsByIDParams{
+		ID:              agent.ID,
+		PeerConnections: peerConnections,
+	})
+	require.NoError(t, err)
+
+	coordinator := tailnet.NewCoordinator(slogtest.Make(t, nil).Leveled(slog.LevelDebug))
+	coordinatorPtr := atomic.Pointer[tailnet.Coordinator]{}
+	coordinatorPtr.Store(&coordinator)
+	derpMapProvider := derpmap.NewAGPLProvider(derpMap)
+	derpMapProviderPtr := atomic.Pointer[derpmap.Provider]{}
+	derpMapProviderPtr.Store(&derpMapProvider)
+	registry := prometheus.NewRegistry()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	closeFunc, err := prometheusmetrics.Agents(ctx, slogtest.Make(t, &slogtest.Options{
+		IgnoreErrors: true,
+	}), registry, db, &coordinatorPtr, &derpMapProviderPtr, time.Hour, time.Millisecond)
+	require.NoError(t, err)
+	t.Cleanup(closeFunc)
+
+	require.Eventually(t, func() bool {
+		metrics, err := registry.Gather()
+		assert.NoError(t, err)
+
+		connections := map[string]int{}
+		for _, metric := range metrics {
+			if metric.GetName() != "coderd_agents_peer_connections" {
+				continue
+			}
+			for _, m := range metric.Metric {
+				var connectionType, derpRegion string
+				for _, label := range m.Label {
+					switch label.GetName() {
+					case "type":
+						connectionType = label.GetValue()
+					case "derp_region":
+						derpRegion = label.GetValue()
+					}
+				}
+				connections[connectionType+"/"+derpRegion] = int(m.Gauge.GetValue())
+			}
+		}
+		return reflect.DeepEqual(map[string]int{
+			"direct/": 2,
+			"relayed/" + derpMap.Regions[regionID].RegionName: 1,
+		}, connections)
+	}, testutil.WaitShort, testutil.IntervalFast)
+}
@@ -67,6 +67,9 @@ type Server struct {
 
 	AcquireJobDebounce time.Duration
 	OIDCConfig         httpmw.OAuth2Config
+	// OIDCProviders are the additional OpenID Connect providers keyed by
+	// provider ID.
+	OIDCProviders map[string]httpmw.OAuth2Config
 }
 
 // AcquireJob queries the database to lock a job.
@@ -188,8 +191,8 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		}
 
 		var workspaceOwnerOIDCAccessToken string
-		if server.OIDCConfig != nil {
-			workspaceOwnerOIDCAccessToken, err = obtainOIDCAccessToken(ctx, server.Database, server.OIDCConfig, owner.ID)
+		if server.OIDCConfig != nil || len(server.OIDCProviders) > 0 {
+			workspaceOwnerOIDCAccessToken, err = obtainOIDCAccessToken(ctx, server.Database, server.OIDCConfig, server.OIDCProviders, owner.ID)
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("obtain OIDC access token: %s", err))
 			}
@@ -716,6 +719,8 @@ func (server *Server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*p
 					New:              build,
 					Status:           http.StatusInternalServerError,
 					AdditionalFields: wriBytes,
+
+					ServiceAccountCreatedBy: server.serviceAccountCreatedBy(ctx, job.InitiatorID),
 				})
 			}
 		}
@@ -876,6 +881,57 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 		if err != nil {
 			return nil, xerrors.Errorf("complete job: %w", err)
 		}
+
+		if input.ActivateOnSuccess && !completedError.Valid {
+			templateVersion, err := server.Database.GetTemplateVersionByID(ctx, input.TemplateVersionID)
+			if err != nil {
+				return nil, xerrors.Errorf("get template version: %w", err)
+			}
+			if templateVersion.TemplateID.Valid {
+				err = server.Database.InTx(func(tx database.Store) error {
+					template, err := tx.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
+					if err != nil {
+						return xerrors.Errorf("get template: %w", err)
+					}
+					err = tx.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
+						ID:              template.ID,
+						ActiveVersionID: templateVersion.ID,
+						UpdatedAt:       database.Now(),
+					})
+					if err != nil {
+						return xerrors.Errorf("update template active version: %w", err)
+					}
+					// The previously active version is deprecated, so only
+					// the active version of a template is in the active state.
+					if template.ActiveVersionID != templateVersion.ID {
+						err = tx.UpdateTemplateVersionStateByID(ctx, database.UpdateTemplateVersionStateByIDParams{
+							ID:        template.ActiveVersionID,
+							State:     database.TemplateVersionStateDeprecated,
+							UpdatedAt: database.Now(),
+						})
+						if err != nil {
+							return xerrors.Errorf("deprecate previous active version: %w", err)
+						}
+					}
+					err = tx.UpdateTemplateVersionStateByID(ctx, database.UpdateTemplateVersionStateByIDParams{
+						ID:        templateVersion.ID,
+						State:     database.TemplateVersionStateActive,
+						UpdatedAt: database.Now(),
+					})
+					if err != nil {
+						return xerrors.Errorf("update template version state: %w", err)
+					}
+					return nil
+				}, nil)
+				if err != nil {
+					return nil, err
+				}
+				server.Logger.Info(ctx, "promoted template version to active",
+					slog.F("template_id", templateVersion.TemplateID.UUID),
+					slog.F("template_version_id", templateVersion.ID),
+				)
+			}
+		}
 	case *proto.CompletedJob_WorkspaceBuild_:
 		var input WorkspaceProvisionJob
 		err = json.Unmarshal(job.Input, &input)
@@ -1067,6 +1123,8 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 				New:              workspaceBuild,
 				Status:           http.StatusOK,
 				AdditionalFields: wriBytes,
+
+				ServiceAccountCreatedBy: server.serviceAccountCreatedBy(ctx, job.InitiatorID),
 			})
 		}
 
@@ -1125,6 +1183,17 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 	return &proto.Empty{}, nil
 }
 
+// serviceAccountCreatedBy returns the admin that created the user if they are
+// a service account, so builds they start are audited with the admin.
+func (server *Server) serviceAccountCreatedBy(ctx context.Context, userID uuid.UUID) uuid.NullUUID {
+	roles, err := server.Database.GetAuthorizationUserRoles(ctx, userID)
+	if err != nil {
+		server.Logger.Warn(ctx, "get roles of build initiator", slog.F("user_id", userID), slog.Error(err))
+		return uuid.NullUUID{}
+	}
+	return roles.ServiceAccountCreatedBy
+}
+
 func (server *Server) startTrace(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	return server.Tracer.Start(ctx, name, append(opts, trace.WithAttributes(
 		semconv.ServiceNameKey.String("coderd.provisionerd"),
@@ -1263,11 +1332,18 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 			}
 
 			sharingLevel := database.AppSharingLevelOwner
+			sharingGroups := []string{}
 			switch app.SharingLevel {
 			case sdkproto.AppSharingLevel_AUTHENTICATED:
 				sharingLevel = database.AppSharingLevelAuthenticated
 			case sdkproto.AppSharingLevel_PUBLIC:
 				sharingLevel = database.AppSharingLevelPublic
+			case sdkproto.AppSharingLevel_GROUP:
+				if len(app.SharingGroups) == 0 {
+					return xerrors.Errorf("app %q is shared with groups, but no groups are specified", slug)
+				}
+				sharingLevel = database.AppSharingLevelGroup
+				sharingGroups = app.SharingGroups
 			}
 
 			dbApp, err := db.InsertWorkspaceApp(ctx, database.InsertWorkspaceAppParams{
@@ -1292,6 +1368,7 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 				HealthcheckInterval:  app.Healthcheck.Interval,
 				HealthcheckThreshold: app.Healthcheck.Threshold,
 				Health:               health,
+				SharingGroups:        sharingGroups,
 			})
 			if err != nil {
 				return xerrors.Errorf("insert app: %w", err)
@@ -1382,7 +1459,7 @@ func deleteSessionToken(ctx context.Context, db database.Store, workspace databa
 
 // obtainOIDCAccessToken returns a valid OpenID Connect access token
 // for the user if it's able to obtain one, otherwise it returns an empty string.
-func obtainOIDCAccessToken(ctx context.Context, db database.Store, oidcConfig httpmw.OAuth2Config, userID uuid.UUID) (string, error) {
+func obtainOIDCAccessToken(ctx context.Context, db database.Store, oidcConfig httpmw.OAuth2Config, oidcProviders map[string]httpmw.OAuth2Config, userID uuid.UUID) (string, error) {
 	link, err := db.GetUserLinkByUserIDLoginType(ctx, database.GetUserLinkByUserIDLoginTypeParams{
 		UserID:    userID,
 		LoginType: database.LoginTypeOIDC,
@@ -1393,8 +1470,11 @@ func obtainOIDCAccessToken(ctx context.Context, db database.Store, oidcConfig ht
 	if err != nil {
 		return "", xerrors.Errorf("get owner oidc link: %w", err)
 	}
+	if link.OIDCProviderID != "" {
+		oidcConfig = oidcProviders[link.OIDCProviderID]
+	}
 
-	if link.OAuthExpiry.Before(database.Now()) && !link.OAuthExpiry.IsZero() && link.OAuthRefreshToken != "" {
+	if oidcConfig != nil && link.OAuthExpiry.Before(database.Now()) && !link.OAuthExpiry.IsZero() && link.OAuthRefreshToken != "" {
 		token, err := oidcConfig.TokenSource(ctx, &oauth2.Token{
 			AccessToken:  link.OAuthAccessToken,
 			RefreshToken: link.OAuthRefreshToken,
@@ -1505,6 +1585,9 @@ func auditActionFromTransition(transition database.WorkspaceTransition) database
 type TemplateVersionImportJob struct {
 	TemplateVersionID  uuid.UUID                `json:"template_version_id"`
 	UserVariableValues []codersdk.VariableValue `json:"user_variable_values"`
+	// ActivateOnSuccess promotes the template version to the active version
+	// of its template once the import succeeds.
+	ActivateOnSuccess bool `json:"activate_on_success,omitempty"`
 }
 
 // WorkspaceProvisionJob is the payload for the "workspace_provision" job type.
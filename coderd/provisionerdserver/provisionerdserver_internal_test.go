@@ -12,6 +12,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbfake"
 	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/testutil"
 )
 
@@ -21,7 +22,7 @@ func TestObtainOIDCAccessToken(t *testing.T) {
 	t.Run("NoToken", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
-		_, err := obtainOIDCAccessToken(ctx, db, nil, uuid.Nil)
+		_, err := obtainOIDCAccessToken(ctx, db, nil, nil, uuid.Nil)
 		require.NoError(t, err)
 	})
 	t.Run("InvalidConfig", func(t *testing.T) {
@@ -34,7 +35,7 @@ func TestObtainOIDCAccessToken(t *testing.T) {
 			LoginType:   database.LoginTypeOIDC,
 			OAuthExpiry: database.Now().Add(-time.Hour),
 		})
-		_, err := obtainOIDCAccessToken(ctx, db, &oauth2.Config{}, user.ID)
+		_, err := obtainOIDCAccessToken(ctx, db, &oauth2.Config{}, nil, user.ID)
 		require.NoError(t, err)
 	})
 	t.Run("Exchange", func(t *testing.T) {
@@ -50,7 +51,7 @@ func TestObtainOIDCAccessToken(t *testing.T) {
 			Token: &oauth2.Token{
 				AccessToken: "token",
 			},
-		}, user.ID)
+		}, nil, user.ID)
 		require.NoError(t, err)
 		link, err := db.GetUserLinkByUserIDLoginType(ctx, database.GetUserLinkByUserIDLoginTypeParams{
 			UserID:    user.ID,
@@ -59,4 +60,29 @@ func TestObtainOIDCAccessToken(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "token", link.OAuthAccessToken)
 	})
+	t.Run("Provider", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		dbgen.UserLink(t, db, database.UserLink{
+			UserID:         user.ID,
+			LoginType:      database.LoginTypeOIDC,
+			OAuthExpiry:    database.Now().Add(-time.Hour),
+			OIDCProviderID: "okta",
+		})
+		_, err := obtainOIDCAccessToken(ctx, db, &oauth2.Config{}, map[string]httpmw.OAuth2Config{
+			"okta": &testutil.OAuth2Config{
+				Token: &oauth2.Token{
+					AccessToken: "okta-token",
+				},
+			},
+		}, user.ID)
+		require.NoError(t, err)
+		link, err := db.GetUserLinkByUserIDLoginType(ctx, database.GetUserLinkByUserIDLoginTypeParams{
+			UserID:    user.ID,
+			LoginType: database.LoginTypeOIDC,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "okta-token", link.OAuthAccessToken)
+	})
 }
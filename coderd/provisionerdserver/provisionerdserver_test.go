@@ -892,6 +892,56 @@ func TestCompleteJob(t *testing.T) {
 		require.False(t, job.Error.Valid)
 	})
 
+	t.Run("TemplateImportActivateOnSuccess", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		previousID := uuid.New()
+		template := dbgen.Template(t, srv.Database, database.Template{
+			ActiveVersionID: previousID,
+		})
+		previous := dbgen.TemplateVersion(t, srv.Database, database.TemplateVersion{
+			ID:         previousID,
+			TemplateID: uuid.NullUUID{UUID: template.ID, Valid: true},
+		})
+		jobID := uuid.New()
+		version := dbgen.TemplateVersion(t, srv.Database, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: template.ID, Valid: true},
+			JobID:      jobID,
+		})
+		job, err := srv.Database.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:            jobID,
+			Provisioner:   database.ProvisionerTypeEcho,
+			Input:         []byte(`{"template_version_id": "` + version.ID.String() + `", "activate_on_success": true}`),
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+		})
+		require.NoError(t, err)
+		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			WorkerID: uuid.NullUUID{
+				UUID:  srv.ID,
+				Valid: true,
+			},
+			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+		})
+		require.NoError(t, err)
+		_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
+			JobId: job.ID.String(),
+			Type: &proto.CompletedJob_TemplateImport_{
+				TemplateImport: &proto.CompletedJob_TemplateImport{},
+			},
+		})
+		require.NoError(t, err)
+		template, err = srv.Database.GetTemplateByID(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, version.ID, template.ActiveVersionID)
+		version, err = srv.Database.GetTemplateVersionByID(ctx, version.ID)
+		require.NoError(t, err)
+		require.Equal(t, database.TemplateVersionStateActive, version.State)
+		previous, err = srv.Database.GetTemplateVersionByID(ctx, previous.ID)
+		require.NoError(t, err)
+		require.Equal(t, database.TemplateVersionStateDeprecated, previous.State)
+	})
+
 	t.Run("WorkspaceBuild", func(t *testing.T) {
 		t.Parallel()
 
@@ -134,6 +134,15 @@ func (api *API) provisionerJobResources(rw http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	derpMap, err := (*api.DERPMapProvider.Load()).All(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	apiResources := make([]codersdk.WorkspaceResource, 0)
 	for _, resource := range resources {
 		agents := make([]codersdk.WorkspaceAgent, 0)
@@ -149,7 +158,7 @@ func (api *API) provisionerJobResources(rw http.ResponseWriter, r *http.Request,
 			}
 
 			apiAgent, err := convertWorkspaceAgent(
-				api.DERPMap, *api.TailnetCoordinator.Load(), agent, convertApps(dbApps), api.AgentInactiveDisconnectTimeout,
+				derpMap, *api.TailnetCoordinator.Load(), agent, convertApps(dbApps), api.AgentInactiveDisconnectTimeout,
 				api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
 			)
 			if err != nil {
@@ -151,6 +151,14 @@ var (
 		Type: "license",
 	}
 
+	// ResourceOAuth2ProviderApp is a third-party application that can sign in
+	// users with Coder as the OAuth2 authorization server. Site wide.
+	//	create/update/delete = register, edit or remove applications and their secrets
+	//	read = view applications, required to authorize them
+	ResourceOAuth2ProviderApp = Object{
+		Type: "oauth2_app",
+	}
+
 	// ResourceDeploymentValues
 	ResourceDeploymentValues = Object{
 		Type: "deployment_config",
@@ -11,6 +11,7 @@ func AllResources() []Object {
 		ResourceFile,
 		ResourceGroup,
 		ResourceLicense,
+		ResourceOAuth2ProviderApp,
 		ResourceOrgRoleAssignment,
 		ResourceOrganization,
 		ResourceOrganizationMember,
@@ -150,6 +150,8 @@ func ReloadBuiltinRoles(opts *RoleOptions) {
 			ResourceRoleAssignment.Type: {ActionRead},
 			// All users can see the provisioner daemons.
 			ResourceProvisionerDaemon.Type: {ActionRead},
+			// All users can see the OAuth2 applications they can sign in to.
+			ResourceOAuth2ProviderApp.Type: {ActionRead},
 		}),
 		Org:  map[string][]Permission{},
 		User: allPermsExcept(),
@@ -283,9 +285,17 @@ func ReloadBuiltinRoles(opts *RoleOptions) {
 //	map[actor_role][assign_role]<can_assign>
 var assignRoles = map[string]map[string]bool{
 	"system": {
-		owner:     true,
+		owner:         true,
+		auditor:       true,
+		member:        true,
+		orgAdmin:      true,
+		orgMember:     true,
+		templateAdmin: true,
+		userAdmin:     true,
+	},
+	// SCIM provisioning only creates members.
+	"scim": {
 		member:    true,
-		orgAdmin:  true,
 		orgMember: true,
 	},
 	owner: {
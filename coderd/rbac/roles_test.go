@@ -309,6 +309,24 @@ func TestRolePermissions(t *testing.T) {
 				false: {memberMe, otherOrgAdmin, otherOrgMember, userAdmin},
 			},
 		},
+		{
+			Name:     "OAuth2ProviderApp",
+			Actions:  []rbac.Action{rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
+			Resource: rbac.ResourceOAuth2ProviderApp,
+			AuthorizeMap: map[bool][]authSubject{
+				true:  {owner},
+				false: {memberMe, orgMemberMe, orgAdmin, otherOrgAdmin, otherOrgMember, templateAdmin, userAdmin},
+			},
+		},
+		{
+			Name:     "ReadOAuth2ProviderApp",
+			Actions:  []rbac.Action{rbac.ActionRead},
+			Resource: rbac.ResourceOAuth2ProviderApp,
+			AuthorizeMap: map[bool][]authSubject{
+				true:  {owner, memberMe, orgMemberMe, orgAdmin, otherOrgAdmin, otherOrgMember, templateAdmin, userAdmin},
+				false: {},
+			},
+		},
 		{
 			Name:     "Groups",
 			Actions:  []rbac.Action{rbac.ActionRead},
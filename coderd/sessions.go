@@ -0,0 +1,202 @@
+package coderd
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+// Sessions are the API keys created by logging in to the dashboard or the CLI.
+// Tokens are managed separately.
+//
+// @Summary Get user sessions
+// @ID get-user-sessions
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.Session
+// @Router /users/{user}/sessions [get]
+func (api *API) sessions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		user   = httpmw.UserParam(r)
+		apiKey = httpmw.APIKey(r)
+	)
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
+	keys, err := api.Database.GetSessionAPIKeysByUserID(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching sessions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sessions := make([]codersdk.Session, 0, len(keys))
+	for _, key := range keys {
+		sessions = append(sessions, convertSession(key, apiKey.ID))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sessions)
+}
+
+// @Summary Revoke user session
+// @ID revoke-user-session
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param session path string true "Session ID"
+// @Success 204
+// @Router /users/{user}/sessions/{session} [delete]
+func (api *API) deleteSession(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		sessionID         = chi.URLParam(r, "session")
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
+	key, err := api.Database.GetAPIKeyByID(ctx, sessionID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Only sessions can be revoked here. Tokens are deleted through the tokens
+	// API, and the keys of OAuth2 applications by revoking the application.
+	if key.UserID != user.ID || !isSessionAPIKey(key) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	aReq.Old = key
+
+	err = api.Database.DeleteAPIKeyByID(ctx, key.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error revoking session.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+// Revokes every session of the user except the one making the request. When
+// an admin revokes the sessions of another user, all of them are revoked.
+// Every revoked session is audited.
+//
+// @Summary Revoke other user sessions
+// @ID revoke-other-user-sessions
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 204
+// @Router /users/{user}/sessions [delete]
+func (api *API) deleteSessions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		user    = httpmw.UserParam(r)
+		apiKey  = httpmw.APIKey(r)
+		authz   = httpmw.UserAuthorization(r)
+		auditor = api.Auditor.Load()
+	)
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
+	var exceptID string
+	if apiKey.UserID == user.ID {
+		exceptID = apiKey.ID
+	}
+	keys, err := api.Database.DeleteSessionAPIKeysByUserID(ctx, database.DeleteSessionAPIKeysByUserIDParams{
+		UserID:   user.ID,
+		ExceptID: exceptID,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error revoking sessions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	for _, key := range keys {
+		audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.APIKey]{
+			Audit:     *auditor,
+			Log:       api.Logger,
+			UserID:    apiKey.UserID,
+			RequestID: httpmw.RequestID(r),
+			IP:        r.RemoteAddr,
+			UserAgent: r.UserAgent(),
+			Status:    http.StatusNoContent,
+			Action:    database.AuditActionDelete,
+			Resource:  key,
+
+			ServiceAccountCreatedBy: authz.ServiceAccountCreatedBy,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+// isSessionAPIKey matches the keys returned by GetSessionAPIKeysByUserID.
+func isSessionAPIKey(key database.APIKey) bool {
+	switch key.LoginType {
+	case database.LoginTypeToken, database.LoginTypeOAuth2ProviderApp:
+		return false
+	}
+	return key.TokenName == ""
+}
+
+func convertSession(key database.APIKey, currentID string) codersdk.Session {
+	var ip string
+	if key.IPAddress.Valid {
+		ip = key.IPAddress.IPNet.IP.String()
+	}
+	return codersdk.Session{
+		ID:        key.ID,
+		UserID:    key.UserID,
+		LoginType: codersdk.LoginType(key.LoginType),
+		Scope:     codersdk.APIKeyScope(key.Scope),
+		IPAddress: ip,
+		UserAgent: key.UserAgent,
+		CreatedAt: key.CreatedAt,
+		LastUsed:  key.LastUsed,
+		ExpiresAt: key.ExpiresAt,
+		Current:   key.ID == currentID,
+	}
+}
@@ -0,0 +1,168 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ListAndRevoke", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		other := codersdk.New(client.URL)
+		login, err := other.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+		})
+		require.NoError(t, err)
+		other.SetSessionToken(login.SessionToken)
+
+		// Tokens are not sessions.
+		_, err = client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+
+		sessions, err := client.Sessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		var otherID string
+		for _, session := range sessions {
+			require.Equal(t, codersdk.LoginTypePassword, session.LoginType)
+			require.NotEmpty(t, session.UserAgent)
+			if !session.Current {
+				otherID = session.ID
+			}
+		}
+		require.NotEmpty(t, otherID)
+
+		err = client.RevokeSession(ctx, codersdk.Me, otherID)
+		require.NoError(t, err)
+		_, err = other.User(ctx, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		sessions, err = client.Sessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.True(t, sessions[0].Current)
+	})
+
+	t.Run("OAuth2AppKeys", func(t *testing.T) {
+		t.Parallel()
+		db, pubsub := dbtestutil.NewDB(t)
+		client := coderdtest.New(t, &coderdtest.Options{
+			Database: db,
+			Pubsub:   pubsub,
+		})
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Keys issued to OAuth2 applications are revoked with the
+		// application, not as sessions.
+		key, _ := dbgen.APIKey(t, db, database.APIKey{
+			UserID:    first.UserID,
+			LoginType: database.LoginTypeOAuth2ProviderApp,
+		})
+
+		sessions, err := client.Sessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.True(t, sessions[0].Current)
+
+		err = client.RevokeSession(ctx, codersdk.Me, key.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		_, err = db.GetAPIKeyByID(ctx, key.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("RevokeOthers", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		other := codersdk.New(client.URL)
+		login, err := other.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+		})
+		require.NoError(t, err)
+		other.SetSessionToken(login.SessionToken)
+
+		token, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+		tokenClient := codersdk.New(client.URL)
+		tokenClient.SetSessionToken(token.Key)
+
+		err = client.RevokeOtherSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		_, err = other.User(ctx, codersdk.Me)
+		require.Error(t, err)
+		_, err = client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		_, err = tokenClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+	})
+
+	t.Run("OnBehalfOfUser", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		first := coderdtest.CreateFirstUser(t, client)
+		memberClient, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Members cannot revoke the sessions of others.
+		err := memberClient.RevokeOtherSessions(ctx, first.UserID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		sessions, err := client.Sessions(ctx, member.ID.String())
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.False(t, sessions[0].Current)
+
+		// Sessions of other users can't be revoked through your own.
+		err = client.RevokeSession(ctx, codersdk.Me, sessions[0].ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		// All sessions are revoked when acting on behalf of another user.
+		err = client.RevokeOtherSessions(ctx, member.ID.String())
+		require.NoError(t, err)
+		_, err = memberClient.User(ctx, codersdk.Me)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		// Every revoked session is audited.
+		logs := auditor.AuditLogs()
+		last := logs[len(logs)-1]
+		require.Equal(t, database.AuditActionDelete, last.Action)
+		require.Equal(t, database.ResourceTypeApiKey, last.ResourceType)
+		require.Equal(t, first.UserID, last.UserID)
+		require.Equal(t, member.ID, last.ResourceID)
+	})
+}
@@ -0,0 +1,249 @@
+package templategit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/provisionersdk"
+)
+
+// Repository identifies a template stored in a git repository.
+type Repository struct {
+	// URL is the HTTP(S) clone URL of the repository.
+	URL string
+	// Ref is the branch, tag or commit to check out.
+	Ref string
+	// Subdirectory is the path of the template within the repository.
+	Subdirectory string
+	// Username and Password are sent as basic auth to the git server.
+	// Both are empty for public repositories.
+	Username string
+	Password string
+}
+
+// ValidateURL ensures the repository URL can be cloned by coderd. Only
+// HTTP(S) is supported since git auth providers issue HTTP tokens.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return xerrors.Errorf("parse repository url: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return xerrors.Errorf("repository url must use http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return xerrors.New("repository url must contain a host")
+	}
+	if u.User != nil {
+		return xerrors.New("repository url must not contain credentials, use a git auth provider instead")
+	}
+	return nil
+}
+
+// ValidateSubdirectory ensures the subdirectory does not escape the
+// repository root.
+func ValidateSubdirectory(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if filepath.IsAbs(dir) {
+		return xerrors.New("subdirectory must be relative to the repository root")
+	}
+	clean := filepath.Clean(dir)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return xerrors.New("subdirectory must be within the repository")
+	}
+	return nil
+}
+
+// ValidateRef ensures the ref is a branch, tag or commit name that git would
+// accept, following the rules of git check-ref-format. Refs are passed to
+// git as arguments, so names that look like options are rejected too.
+func ValidateRef(ref string) error {
+	switch {
+	case ref == "":
+		return xerrors.New("ref must not be empty")
+	case strings.HasPrefix(ref, "-"):
+		return xerrors.New("ref must not start with a dash")
+	case ref == "@":
+		return xerrors.New("ref must not be a single @")
+	case strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.Contains(ref, "//"):
+		return xerrors.New("ref must not start or end with a slash or contain consecutive slashes")
+	case strings.HasSuffix(ref, "."):
+		return xerrors.New("ref must not end with a dot")
+	case strings.Contains(ref, ".."):
+		return xerrors.New("ref must not contain \"..\"")
+	case strings.Contains(ref, "@{"):
+		return xerrors.New("ref must not contain \"@{\"")
+	}
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return xerrors.Errorf("ref must not contain %q", r)
+		}
+	}
+	for _, component := range strings.Split(ref, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return xerrors.New("ref components must not start with a dot or end with \".lock\"")
+		}
+	}
+	return nil
+}
+
+// ResolveRef returns the commit that the repository ref points to without
+// cloning the repository. Refs that are already a full commit SHA are
+// returned as-is.
+func ResolveRef(ctx context.Context, repo Repository) (string, error) {
+	if err := ValidateRef(repo.Ref); err != nil {
+		return "", err
+	}
+	if isCommitSHA(repo.Ref) {
+		return repo.Ref, nil
+	}
+	out, err := runGit(ctx, "", repo, "ls-remote", "--", repo.URL, repo.Ref, "refs/heads/"+repo.Ref, "refs/tags/"+repo.Ref+"^{}", "refs/tags/"+repo.Ref)
+	if err != nil {
+		return "", err
+	}
+	// Prefer branches, then peeled tags, then annotated tag objects.
+	refs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+	for _, name := range []string{"refs/heads/" + repo.Ref, "refs/tags/" + repo.Ref + "^{}", "refs/tags/" + repo.Ref, repo.Ref} {
+		if sha, ok := refs[name]; ok {
+			return sha, nil
+		}
+	}
+	return "", xerrors.Errorf("ref %q not found in repository", repo.Ref)
+}
+
+// Archive clones the repository at ref and returns a tar archive of the
+// template subdirectory along with the commit it was created from.
+func Archive(ctx context.Context, repo Repository) ([]byte, string, error) {
+	if err := ValidateRef(repo.Ref); err != nil {
+		return nil, "", err
+	}
+	dir, err := os.MkdirTemp("", "coder-template-git-")
+	if err != nil {
+		return nil, "", xerrors.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", "--", repo.URL, repo.Ref},
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	} {
+		_, err = runGit(ctx, dir, repo, args...)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	commit, err := runGit(ctx, dir, repo, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, "", err
+	}
+
+	templateDir, err := resolveTemplateDir(dir, repo.Subdirectory)
+	if err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	err = provisionersdk.Tar(&buf, templateDir, provisionersdk.TemplateArchiveLimit)
+	if err != nil {
+		return nil, "", xerrors.Errorf("archive %q: %w", repo.Subdirectory, err)
+	}
+	return buf.Bytes(), strings.TrimSpace(commit), nil
+}
+
+// resolveTemplateDir returns the template subdirectory of the clone. The
+// repository controls the files in the clone, so symlinks are rejected to
+// avoid archiving files of the coderd host.
+func resolveTemplateDir(dir string, subdirectory string) (string, error) {
+	// The temp dir itself may be behind a symlink, e.g. on macOS.
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", xerrors.Errorf("resolve clone dir: %w", err)
+	}
+	templateDir, err := filepath.EvalSymlinks(filepath.Join(root, filepath.Clean(subdirectory)))
+	if err != nil {
+		return "", xerrors.Errorf("resolve %q: %w", subdirectory, err)
+	}
+	rel, err := filepath.Rel(root, templateDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", xerrors.Errorf("subdirectory %q must be within the repository", subdirectory)
+	}
+	err = filepath.WalkDir(templateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			rel, _ := filepath.Rel(templateDir, path)
+			return xerrors.Errorf("template must not contain symlinks, found %q", filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return templateDir, nil
+}
+
+func runGit(ctx context.Context, dir string, repo Repository, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		// Never block on an interactive prompt for credentials.
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		// Local and exotic transports would allow reading from the
+		// coderd host.
+		"GIT_ALLOW_PROTOCOL=http:https",
+	)
+	if repo.Username != "" || repo.Password != "" {
+		// Credentials are passed through the environment so they are not
+		// visible in the process list.
+		auth := base64.StdEncoding.EncodeToString([]byte(repo.Username + ":" + repo.Password))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			fmt.Sprintf("GIT_CONFIG_VALUE_0=Authorization: Basic %s", auth),
+		)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", xerrors.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func isCommitSHA(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	for _, r := range ref {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
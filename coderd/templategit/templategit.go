@@ -0,0 +1,434 @@
+// Package templategit creates template versions from git repositories.
+//
+// Templates with a git source are polled on an interval and can be synced
+// on demand, e.g. from a push webhook. A new template version is created
+// whenever the tracked ref points to a new commit, and it is promoted to
+// the active version once the import job succeeds.
+package templategit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/tabbed/pqtype"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/gitauth"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/codersdk"
+)
+
+// Options set optional parameters for the syncer.
+type Options struct {
+	// Interval is how often sources are checked for an elapsed poll
+	// interval, default 1m.
+	Interval time.Duration
+	// SyncTimeout bounds a single sync, default 5m.
+	SyncTimeout time.Duration
+}
+
+// Syncer creates template versions from git sources.
+type Syncer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	db     database.Store
+	log    slog.Logger
+	opts   Options
+
+	gitAuthConfigs []*gitauth.Config
+
+	// syncing deduplicates concurrent syncs of the same template on this
+	// replica.
+	syncing   map[uuid.UUID]struct{}
+	syncingMu sync.Mutex
+
+	// closeMu prevents triggers from adding to wg while Close waits on it.
+	closeMu sync.Mutex
+	wg      sync.WaitGroup
+	closed  chan struct{}
+}
+
+// New starts polling git sources. It is the caller's responsibility to call
+// Close on the returned instance.
+func New(db database.Store, log slog.Logger, gitAuthConfigs []*gitauth.Config, opts Options) *Syncer {
+	if opts.Interval == 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.SyncTimeout == 0 {
+		opts.SyncTimeout = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // The syncer creates template versions on behalf of the source owner.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	s := &Syncer{
+		ctx:            ctx,
+		cancel:         cancel,
+		db:             db,
+		log:            log,
+		opts:           opts,
+		gitAuthConfigs: gitAuthConfigs,
+		syncing:        map[uuid.UUID]struct{}{},
+		closed:         make(chan struct{}),
+	}
+	go s.start()
+	return s
+}
+
+func (s *Syncer) start() {
+	defer close(s.closed)
+
+	t := time.NewTicker(s.opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		sources, err := s.db.GetTemplateGitSources(s.ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			s.log.Error(s.ctx, "get template git sources", slog.Error(err))
+			continue
+		}
+		now := database.Now()
+		for _, source := range sources {
+			if source.PollInterval <= 0 {
+				continue
+			}
+			if source.LastSyncedAt.Valid && now.Sub(source.LastSyncedAt.Time) < time.Duration(source.PollInterval) {
+				continue
+			}
+			_ = s.Trigger(source.TemplateID, false)
+		}
+	}
+}
+
+// Trigger syncs the template's git source in the background. The outcome is
+// recorded on the source. ErrSyncInProgress is returned if the template is
+// already syncing, and ErrClosed once the syncer is closed.
+func (s *Syncer) Trigger(templateID uuid.UUID, force bool) error {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.ctx.Err() != nil {
+		return ErrClosed
+	}
+	if !s.acquire(templateID) {
+		return ErrSyncInProgress
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(templateID)
+		_, err := s.syncSource(s.ctx, templateID, force)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.log.Warn(s.ctx, "sync template git source", slog.F("template_id", templateID), slog.Error(err))
+		}
+	}()
+	return nil
+}
+
+var (
+	// ErrSyncInProgress is returned when the template is already being
+	// synced by this replica.
+	ErrSyncInProgress = xerrors.New("template git source sync already in progress")
+	// ErrClosed is returned when a sync is triggered after Close.
+	ErrClosed = xerrors.New("template git syncer is closed")
+)
+
+// Sync creates a new template version if the tracked ref has moved since the
+// last sync, or unconditionally if force is set. The returned version is
+// nil if the source was already up to date.
+func (s *Syncer) Sync(ctx context.Context, templateID uuid.UUID, force bool) (*database.TemplateVersion, error) {
+	if !s.acquire(templateID) {
+		return nil, ErrSyncInProgress
+	}
+	defer s.release(templateID)
+	return s.syncSource(ctx, templateID, force)
+}
+
+// acquire marks the template as syncing. It returns false if the template is
+// already syncing.
+func (s *Syncer) acquire(templateID uuid.UUID) bool {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+	if _, ok := s.syncing[templateID]; ok {
+		return false
+	}
+	s.syncing[templateID] = struct{}{}
+	return true
+}
+
+func (s *Syncer) release(templateID uuid.UUID) {
+	s.syncingMu.Lock()
+	delete(s.syncing, templateID)
+	s.syncingMu.Unlock()
+}
+
+func (s *Syncer) syncSource(ctx context.Context, templateID uuid.UUID, force bool) (*database.TemplateVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.SyncTimeout)
+	defer cancel()
+	//nolint:gocritic // Callers have already authorized the sync.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	source, err := s.db.GetTemplateGitSourceByTemplateID(ctx, templateID)
+	if err != nil {
+		return nil, xerrors.Errorf("get template git source: %w", err)
+	}
+	version, err := s.sync(ctx, source, force)
+	if err != nil {
+		statusErr := s.db.UpdateTemplateGitSourceSyncStatus(ctx, database.UpdateTemplateGitSourceSyncStatusParams{
+			TemplateID:    templateID,
+			LastCommitSHA: source.LastCommitSHA,
+			LastSyncedAt:  sql.NullTime{Time: database.Now(), Valid: true},
+			LastError:     err.Error(),
+		})
+		if statusErr != nil {
+			s.log.Warn(ctx, "update template git source sync status", slog.Error(statusErr))
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+func (s *Syncer) sync(ctx context.Context, source database.TemplateGitSource, force bool) (*database.TemplateVersion, error) {
+	repo, err := s.repository(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := ResolveRef(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var archive []byte
+	if commit != source.LastCommitSHA || force {
+		// Fetch the resolved commit so the version matches what was checked.
+		repo.Ref = commit
+		archive, commit, err = Archive(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var version *database.TemplateVersion
+	err = s.db.InTx(func(tx database.Store) error {
+		// Every replica polls, so serialize version creation and check
+		// again whether another replica already synced this commit.
+		err := tx.AcquireLock(ctx, database.LockIDTemplateGitSync)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		source, err = tx.GetTemplateGitSourceByTemplateID(ctx, source.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template git source: %w", err)
+		}
+		if archive != nil && (commit != source.LastCommitSHA || force) {
+			// Versions are created as the source owner so a sync can't do
+			// anything the owner couldn't do by pushing the template.
+			ownerCtx, err := asUser(ctx, tx, source.CreatedBy)
+			if err != nil {
+				return err
+			}
+			template, err := tx.GetTemplateByID(ownerCtx, source.TemplateID)
+			if err != nil {
+				return xerrors.Errorf("get template: %w", err)
+			}
+			v, err := createTemplateVersion(ownerCtx, tx, template, source, archive)
+			if err != nil {
+				return err
+			}
+			version = &v
+		}
+		return tx.UpdateTemplateGitSourceSyncStatus(ctx, database.UpdateTemplateGitSourceSyncStatusParams{
+			TemplateID:    source.TemplateID,
+			LastCommitSHA: commit,
+			LastSyncedAt:  sql.NullTime{Time: database.Now(), Valid: true},
+			LastError:     "",
+		})
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if version != nil {
+		s.log.Info(ctx, "created template version from git",
+			slog.F("template_id", source.TemplateID),
+			slog.F("template_version_id", version.ID),
+			slog.F("commit", commit),
+		)
+	}
+	return version, nil
+}
+
+// repository returns the repository for the source, authenticated as the
+// user that configured it.
+func (s *Syncer) repository(ctx context.Context, source database.TemplateGitSource) (Repository, error) {
+	repo := Repository{
+		URL:          source.RepositoryURL,
+		Ref:          source.Ref,
+		Subdirectory: source.Subdirectory,
+	}
+	if source.GitAuthProviderID == "" {
+		return repo, nil
+	}
+	var config *gitauth.Config
+	for _, c := range s.gitAuthConfigs {
+		if c.ID == source.GitAuthProviderID {
+			config = c
+			break
+		}
+	}
+	if config == nil {
+		return Repository{}, xerrors.Errorf("git auth provider %q is not configured", source.GitAuthProviderID)
+	}
+	link, err := s.db.GetGitAuthLink(ctx, database.GetGitAuthLinkParams{
+		ProviderID: config.ID,
+		UserID:     source.CreatedBy,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Repository{}, xerrors.Errorf("the source owner has not authenticated with git auth provider %q", config.ID)
+		}
+		return Repository{}, xerrors.Errorf("get git auth link: %w", err)
+	}
+	link, valid, err := config.RefreshToken(ctx, s.db, link)
+	if err != nil {
+		return Repository{}, xerrors.Errorf("refresh git auth token: %w", err)
+	}
+	if !valid {
+		return Repository{}, xerrors.Errorf("the git auth token for provider %q has expired, the source owner must authenticate again", config.ID)
+	}
+	repo.Username, repo.Password = config.Credentials(link.OAuthAccessToken)
+	return repo, nil
+}
+
+func asUser(ctx context.Context, db database.Store, userID uuid.UUID) (context.Context, error) {
+	roles, err := db.GetAuthorizationUserRoles(ctx, userID)
+	if err != nil {
+		return nil, xerrors.Errorf("get source owner roles: %w", err)
+	}
+	if roles.Status != database.UserStatusActive {
+		return nil, xerrors.Errorf("the source owner %q is %s", roles.Username, roles.Status)
+	}
+	return dbauthz.As(ctx, rbac.Subject{
+		ID:     userID.String(),
+		Roles:  rbac.RoleNames(roles.Roles),
+		Groups: roles.Groups,
+		Scope:  rbac.ScopeAll,
+	}.WithCachedASTValue()), nil
+}
+
+// createTemplateVersion queues an import job for the archive the same way
+// as uploading a template through the API.
+func createTemplateVersion(ctx context.Context, db database.Store, template database.Template, source database.TemplateGitSource, archive []byte) (database.TemplateVersion, error) {
+	// Carry over the variable values of the active version so required
+	// variables don't fail the import.
+	variables, err := db.GetTemplateVersionVariables(ctx, template.ActiveVersionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.TemplateVersion{}, xerrors.Errorf("get active version variables: %w", err)
+	}
+	variableValues := make([]codersdk.VariableValue, 0, len(variables))
+	for _, variable := range variables {
+		variableValues = append(variableValues, codersdk.VariableValue{
+			Name:  variable.Name,
+			Value: variable.Value,
+		})
+	}
+
+	hashBytes := sha256.Sum256(archive)
+	hash := hex.EncodeToString(hashBytes[:])
+	file, err := db.GetFileByHashAndCreator(ctx, database.GetFileByHashAndCreatorParams{
+		Hash:      hash,
+		CreatedBy: source.CreatedBy,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		file, err = db.InsertFile(ctx, database.InsertFileParams{
+			ID:        uuid.New(),
+			Hash:      hash,
+			CreatedBy: source.CreatedBy,
+			CreatedAt: database.Now(),
+			Mimetype:  "application/x-tar",
+			Data:      archive,
+		})
+	}
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("insert file: %w", err)
+	}
+
+	versionID := uuid.New()
+	jobInput, err := json.Marshal(provisionerdserver.TemplateVersionImportJob{
+		TemplateVersionID:  versionID,
+		UserVariableValues: variableValues,
+		ActivateOnSuccess:  true,
+	})
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("marshal job input: %w", err)
+	}
+	traceMetadataRaw, err := json.Marshal(tracing.MetadataFromContext(ctx))
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("marshal job metadata: %w", err)
+	}
+	job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:             uuid.New(),
+		CreatedAt:      database.Now(),
+		UpdatedAt:      database.Now(),
+		OrganizationID: template.OrganizationID,
+		InitiatorID:    source.CreatedBy,
+		Provisioner:    template.Provisioner,
+		StorageMethod:  database.ProvisionerStorageMethodFile,
+		FileID:         file.ID,
+		Type:           database.ProvisionerJobTypeTemplateVersionImport,
+		Input:          jobInput,
+		Tags:           provisionerdserver.MutateTags(source.CreatedBy, nil),
+		TraceMetadata: pqtype.NullRawMessage{
+			Valid:      true,
+			RawMessage: traceMetadataRaw,
+		},
+	})
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("insert provisioner job: %w", err)
+	}
+
+	version, err := db.InsertTemplateVersion(ctx, database.InsertTemplateVersionParams{
+		ID:             versionID,
+		TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+		OrganizationID: template.OrganizationID,
+		CreatedAt:      database.Now(),
+		UpdatedAt:      database.Now(),
+		Name:           namesgenerator.GetRandomName(1),
+		Readme:         "",
+		JobID:          job.ID,
+		CreatedBy:      source.CreatedBy,
+	})
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("insert template version: %w", err)
+	}
+	return version, nil
+}
+
+// Close stops polling and waits for in-flight syncs to finish.
+func (s *Syncer) Close() error {
+	s.closeMu.Lock()
+	s.cancel()
+	s.closeMu.Unlock()
+	<-s.closed
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,377 @@
+package coderd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/templategit"
+	"github.com/coder/coder/codersdk"
+)
+
+// minTemplateGitSourcePollInterval prevents admins from hammering git
+// servers.
+const minTemplateGitSourcePollInterval = time.Minute
+
+// @Summary Get template git source
+// @ID get-template-git-source
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateGitSource
+// @Router /templates/{template}/git-source [get]
+func (api *API) templateGitSource(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	source, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateGitSource(source))
+}
+
+// @Summary Update template git source
+// @ID update-template-git-source
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateGitSourceRequest true "Git source"
+// @Success 200 {object} codersdk.TemplateGitSource
+// @Router /templates/{template}/git-source [put]
+func (api *API) putTemplateGitSource(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		apiKey   = httpmw.APIKey(r)
+		template = httpmw.TemplateParam(r)
+	)
+
+	var req codersdk.UpdateTemplateGitSourceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if err := templategit.ValidateURL(req.RepositoryURL); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "repository_url", Detail: err.Error()})
+	}
+	if err := templategit.ValidateRef(req.Ref); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "ref", Detail: err.Error()})
+	}
+	if err := templategit.ValidateSubdirectory(req.Subdirectory); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "subdirectory", Detail: err.Error()})
+	}
+	if req.GitAuthProviderID != "" {
+		found := false
+		for _, config := range api.GitAuthConfigs {
+			if config.ID == req.GitAuthProviderID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "git_auth_provider_id",
+				Detail: fmt.Sprintf("Git auth provider %q is not configured.", req.GitAuthProviderID),
+			})
+		}
+	}
+	pollInterval := time.Duration(req.PollIntervalMillis) * time.Millisecond
+	if pollInterval < 0 || (pollInterval > 0 && pollInterval < minTemplateGitSourcePollInterval) {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "poll_interval_ms",
+			Detail: fmt.Sprintf("Must be zero to disable polling or at least %s.", minTemplateGitSourcePollInterval),
+		})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update template git source.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	var webhookSecret string
+	if req.WebhookSecret != nil {
+		webhookSecret = *req.WebhookSecret
+	} else {
+		existing, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template git source.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		webhookSecret = existing.WebhookSecret
+	}
+
+	source, err := api.Database.UpsertTemplateGitSource(ctx, database.UpsertTemplateGitSourceParams{
+		TemplateID:        template.ID,
+		CreatedAt:         database.Now(),
+		UpdatedAt:         database.Now(),
+		CreatedBy:         apiKey.UserID,
+		RepositoryURL:     req.RepositoryURL,
+		Ref:               req.Ref,
+		Subdirectory:      req.Subdirectory,
+		GitAuthProviderID: req.GitAuthProviderID,
+		PollInterval:      int64(pollInterval),
+		WebhookSecret:     webhookSecret,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateGitSource(source))
+}
+
+// @Summary Delete template git source
+// @ID delete-template-git-source
+// @Security CoderSessionToken
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 204
+// @Router /templates/{template}/git-source [delete]
+func (api *API) deleteTemplateGitSource(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	_, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.DeleteTemplateGitSourceByTemplateID(ctx, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+// postTemplateGitSourceSync queues a sync of the template's git source.
+// Cloning can take a while, so the sync runs in the background and its outcome
+// is recorded on the source.
+//
+// @Summary Sync template git source
+// @ID sync-template-git-source
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.SyncTemplateGitSourceRequest true "Sync request"
+// @Success 202 {object} codersdk.TemplateGitSource
+// @Router /templates/{template}/git-source/sync [post]
+func (api *API) postTemplateGitSourceSync(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	var req codersdk.SyncTemplateGitSourceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// Fetching the source as the user authorizes the sync.
+	source, err := api.Database.GetTemplateGitSourceByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.templateGitSyncer.Trigger(template.ID, req.Force)
+	if errors.Is(err, templategit.ErrSyncInProgress) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The template git source is already being synced.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusServiceUnavailable, codersdk.Response{
+			Message: "Failed to sync template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusAccepted, convertTemplateGitSource(source))
+}
+
+// postTemplateGitSourceWebhook accepts push webhooks from GitHub, Gitea and
+// GitLab. Requests are authenticated with the source's webhook secret.
+//
+// @Summary Receive template git source webhook
+// @ID receive-template-git-source-webhook
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 202 {object} codersdk.Response
+// @Router /templates/{template}/git-source/webhook [post]
+func (api *API) postTemplateGitSourceWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "template"))
+	if err != nil {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	//nolint:gocritic // Webhooks are authenticated by the source's secret.
+	source, err := api.Database.GetTemplateGitSourceByTemplateID(dbauthz.AsSystemRestricted(ctx), templateID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && source.WebhookSecret == "") {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, 10<<20))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to read request body.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !validTemplateGitSourceWebhook(r, body, source.WebhookSecret) {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "Invalid webhook signature.",
+		})
+		return
+	}
+
+	if r.Header.Get("X-GitHub-Event") == "ping" || r.Header.Get("X-Gitea-Event") == "ping" {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+			Message: "Pong!",
+		})
+		return
+	}
+	var event struct {
+		Ref string `json:"ref"`
+	}
+	// Events without a ref still trigger a sync, which is a no-op if the
+	// tracked ref hasn't moved.
+	_ = json.Unmarshal(body, &event)
+	if event.Ref != "" && event.Ref != source.Ref &&
+		event.Ref != "refs/heads/"+source.Ref && event.Ref != "refs/tags/"+source.Ref {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+			Message: fmt.Sprintf("Ignoring push to %q.", event.Ref),
+		})
+		return
+	}
+
+	// A sync that is already running picks up the push unless it resolved
+	// the ref just before, in which case the next poll or push will.
+	err = api.templateGitSyncer.Trigger(templateID, false)
+	if err != nil && !errors.Is(err, templategit.ErrSyncInProgress) {
+		httpapi.Write(ctx, rw, http.StatusServiceUnavailable, codersdk.Response{
+			Message: "Failed to trigger sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusAccepted, codersdk.Response{
+		Message: "Sync triggered.",
+	})
+}
+
+// validTemplateGitSourceWebhook checks the HMAC signature sent by GitHub and
+// Gitea, or the plain token sent by GitLab.
+func validTemplateGitSourceWebhook(r *http.Request, body []byte, secret string) bool {
+	if signature := r.Header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected))
+	}
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+func convertTemplateGitSource(source database.TemplateGitSource) codersdk.TemplateGitSource {
+	s := codersdk.TemplateGitSource{
+		TemplateID:         source.TemplateID,
+		CreatedAt:          source.CreatedAt,
+		UpdatedAt:          source.UpdatedAt,
+		CreatedBy:          source.CreatedBy,
+		RepositoryURL:      source.RepositoryURL,
+		Ref:                source.Ref,
+		Subdirectory:       source.Subdirectory,
+		GitAuthProviderID:  source.GitAuthProviderID,
+		PollIntervalMillis: time.Duration(source.PollInterval).Milliseconds(),
+		HasWebhookSecret:   source.WebhookSecret != "",
+		LastCommitSHA:      source.LastCommitSHA,
+		LastError:          source.LastError,
+	}
+	if source.LastSyncedAt.Valid {
+		s.LastSyncedAt = &source.LastSyncedAt.Time
+	}
+	return s
+}
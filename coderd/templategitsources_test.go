@@ -0,0 +1,186 @@
+package coderd_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateGitSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.TemplateGitSource(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		source, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL:      "https://github.com/coder/coder.git",
+			Ref:                "main",
+			Subdirectory:       "examples/templates/docker",
+			PollIntervalMillis: 300000,
+			WebhookSecret:      ptr.Ref("secret"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, source.CreatedBy)
+		require.Equal(t, "examples/templates/docker", source.Subdirectory)
+		require.EqualValues(t, 300000, source.PollIntervalMillis)
+		require.True(t, source.HasWebhookSecret)
+
+		// The webhook secret is kept when omitted.
+		source, err = client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: "https://github.com/coder/coder.git",
+			Ref:           "release",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "release", source.Ref)
+		require.True(t, source.HasWebhookSecret)
+
+		got, err := client.TemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, source, got)
+
+		err = client.DeleteTemplateGitSource(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = client.TemplateGitSource(ctx, template.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL:      "file:///etc",
+			Ref:                "-main",
+			Subdirectory:       "../",
+			GitAuthProviderID:  "github",
+			PollIntervalMillis: 1000,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		fields := map[string]bool{}
+		for _, v := range apiErr.Validations {
+			fields[v.Field] = true
+		}
+		require.Equal(t, map[string]bool{
+			"repository_url":       true,
+			"ref":                  true,
+			"subdirectory":         true,
+			"git_auth_provider_id": true,
+			"poll_interval_ms":     true,
+		}, fields)
+	})
+
+	t.Run("MemberCannotRead", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: "https://github.com/coder/coder.git",
+			Ref:           "main",
+		})
+		require.NoError(t, err)
+
+		_, err = member.TemplateGitSource(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Webhook", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		webhook := func(body string, headers map[string]string) int {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+				client.URL.JoinPath(fmt.Sprintf("/api/v2/templates/%s/git-source/webhook", template.ID)).String(),
+				bytes.NewBufferString(body))
+			require.NoError(t, err)
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res.StatusCode
+		}
+		sign := func(body, secret string) string {
+			mac := hmac.New(sha256.New, []byte(secret))
+			_, _ = mac.Write([]byte(body))
+			return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		}
+
+		// Webhooks are disabled without a secret.
+		_, err := client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			RepositoryURL: "https://github.com/coder/coder.git",
+			Ref:           "main",
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, webhook(`{}`, map[string]string{"X-Gitlab-Token": ""}))
+
+		_, err = client.UpdateTemplateGitSource(ctx, template.ID, codersdk.UpdateTemplateGitSourceRequest{
+			// Nothing listens here, so triggered syncs fail fast.
+			RepositoryURL: "http://127.0.0.1:1/repo.git",
+			Ref:           "main",
+			WebhookSecret: ptr.Ref("secret"),
+		})
+		require.NoError(t, err)
+
+		push := `{"ref":"refs/heads/main"}`
+		require.Equal(t, http.StatusUnauthorized, webhook(push, nil))
+		require.Equal(t, http.StatusUnauthorized, webhook(push, map[string]string{"X-Hub-Signature-256": sign(push, "wrong")}))
+		require.Equal(t, http.StatusUnauthorized, webhook(push, map[string]string{"X-Gitlab-Token": "wrong"}))
+		require.Equal(t, http.StatusOK, webhook(`{}`, map[string]string{
+			"X-GitHub-Event":      "ping",
+			"X-Hub-Signature-256": sign(`{}`, "secret"),
+		}))
+		other := `{"ref":"refs/heads/other"}`
+		require.Equal(t, http.StatusOK, webhook(other, map[string]string{"X-Hub-Signature-256": sign(other, "secret")}))
+		require.Equal(t, http.StatusAccepted, webhook(push, map[string]string{"X-Hub-Signature-256": sign(push, "secret")}))
+		require.Equal(t, http.StatusAccepted, webhook(push, map[string]string{"X-Gitlab-Token": "secret"}))
+	})
+}
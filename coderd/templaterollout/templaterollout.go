@@ -0,0 +1,340 @@
+// Package templaterollout moves a cohort of workspaces to a template version
+// in batches.
+//
+// The cohort of a rollout is selected when it is created. On every tick the
+// runner records the outcome of finished builds, pauses the rollout when the
+// share of failed builds exceeds its threshold, and starts builds for pending
+// workspaces until the batch size is reached.
+//
+// Cohorts are selected by percentage and group membership. Selecting
+// workspaces by label is out of scope, since workspaces don't have labels.
+package templaterollout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/wsbuilder"
+)
+
+// Options set optional parameters for the runner.
+type Options struct {
+	// Interval is how often running rollouts are advanced, default 30s.
+	Interval time.Duration
+}
+
+// Runner advances running rollouts in the background.
+type Runner struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	db     database.Store
+	log    slog.Logger
+	opts   Options
+
+	wg     sync.WaitGroup
+	closed chan struct{}
+}
+
+// New starts advancing running rollouts. It is the caller's responsibility to
+// call Close on the returned instance.
+func New(db database.Store, log slog.Logger, opts Options) *Runner {
+	if opts.Interval == 0 {
+		opts.Interval = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // The runner builds workspaces on behalf of the rollout creator.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	r := &Runner{
+		ctx:    ctx,
+		cancel: cancel,
+		db:     db,
+		log:    log,
+		opts:   opts,
+		closed: make(chan struct{}),
+	}
+	go r.start()
+	return r
+}
+
+func (r *Runner) start() {
+	defer close(r.closed)
+
+	t := time.NewTicker(r.opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		rollouts, err := r.db.GetRunningTemplateVersionRollouts(r.ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			r.log.Error(r.ctx, "get running template version rollouts", slog.Error(err))
+			continue
+		}
+		for _, rollout := range rollouts {
+			err := r.Advance(r.ctx, rollout.ID)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				r.log.Warn(r.ctx, "advance template version rollout",
+					slog.F("template_id", rollout.TemplateID),
+					slog.F("rollout_id", rollout.ID),
+					slog.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// Trigger advances the rollout in the background, e.g. right after it was
+// started or resumed rather than on the next tick.
+func (r *Runner) Trigger(rolloutID uuid.UUID) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		err := r.Advance(r.ctx, rolloutID)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			r.log.Warn(r.ctx, "advance template version rollout", slog.F("rollout_id", rolloutID), slog.Error(err))
+		}
+	}()
+}
+
+// Advance records the outcome of finished builds in the rollout, pauses or
+// completes it, and starts builds for the next pending workspaces. Rollouts
+// that are not running are left untouched.
+func (r *Runner) Advance(ctx context.Context, rolloutID uuid.UUID) error {
+	//nolint:gocritic // Callers have already authorized the rollout.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	return r.db.InTx(func(tx database.Store) error {
+		// Every replica advances rollouts, so serialize them to avoid
+		// building a workspace twice.
+		err := tx.AcquireLock(ctx, database.LockIDTemplateVersionRollout)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		rollout, err := tx.GetTemplateVersionRolloutByID(ctx, rolloutID)
+		if err != nil {
+			return xerrors.Errorf("get rollout: %w", err)
+		}
+		if rollout.Status != database.TemplateVersionRolloutStatusRunning {
+			return nil
+		}
+		entries, err := tx.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rollout.ID)
+		if err != nil {
+			return xerrors.Errorf("get rollout workspaces: %w", err)
+		}
+
+		counts := map[database.TemplateVersionRolloutWorkspaceStatus]int{}
+		for i, entry := range entries {
+			if entry.Status == database.TemplateVersionRolloutWorkspaceStatusBuilding {
+				status, err := buildStatus(ctx, tx, entry.BuildID.UUID)
+				if err != nil {
+					return err
+				}
+				if status != entry.Status {
+					entry.Status = status
+					err = updateEntry(ctx, tx, entry)
+					if err != nil {
+						return err
+					}
+					entries[i] = entry
+				}
+			}
+			counts[entry.Status]++
+		}
+
+		if reason := pauseReason(rollout, counts); reason != "" {
+			r.log.Info(ctx, "pausing template version rollout",
+				slog.F("rollout_id", rollout.ID),
+				slog.F("reason", reason),
+			)
+			return updateStatus(ctx, tx, rollout, database.TemplateVersionRolloutStatusPaused, reason)
+		}
+		if counts[database.TemplateVersionRolloutWorkspaceStatusPending] == 0 &&
+			counts[database.TemplateVersionRolloutWorkspaceStatusBuilding] == 0 {
+			r.log.Info(ctx, "completed template version rollout", slog.F("rollout_id", rollout.ID))
+			return updateStatus(ctx, tx, rollout, database.TemplateVersionRolloutStatusCompleted, "")
+		}
+
+		building := counts[database.TemplateVersionRolloutWorkspaceStatusBuilding]
+		for _, entry := range entries {
+			if building >= int(rollout.BatchSize) {
+				break
+			}
+			if entry.Status != database.TemplateVersionRolloutWorkspaceStatusPending {
+				continue
+			}
+			entry, err = r.startBuild(ctx, tx, rollout, entry)
+			if err != nil {
+				return err
+			}
+			if entry.Status == database.TemplateVersionRolloutWorkspaceStatusPending {
+				continue
+			}
+			err = updateEntry(ctx, tx, entry)
+			if err != nil {
+				return err
+			}
+			if entry.Status == database.TemplateVersionRolloutWorkspaceStatusBuilding {
+				building++
+			}
+		}
+		return nil
+	}, nil)
+}
+
+// startBuild moves the workspace to the rollout's template version. The
+// returned entry is still pending if the workspace is busy with another
+// build.
+func (r *Runner) startBuild(ctx context.Context, tx database.Store, rollout database.TemplateVersionRollout, entry database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow) (database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, error) {
+	workspace, err := tx.GetWorkspaceByID(ctx, entry.WorkspaceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return entry, xerrors.Errorf("get workspace: %w", err)
+	}
+	if errors.Is(err, sql.ErrNoRows) || workspace.Deleted {
+		entry.Status = database.TemplateVersionRolloutWorkspaceStatusSkipped
+		return entry, nil
+	}
+	latestBuild, err := tx.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		return entry, xerrors.Errorf("get latest workspace build: %w", err)
+	}
+	if latestBuild.TemplateVersionID == rollout.TemplateVersionID {
+		// The workspace was updated outside of the rollout.
+		entry.Status = database.TemplateVersionRolloutWorkspaceStatusSkipped
+		return entry, nil
+	}
+	latestJob, err := tx.GetProvisionerJobByID(ctx, latestBuild.JobID)
+	if err != nil {
+		return entry, xerrors.Errorf("get latest workspace build job: %w", err)
+	}
+	if !latestJob.CompletedAt.Valid {
+		return entry, nil
+	}
+
+	// Keep stopped workspaces stopped, they are moved to the new version
+	// without being started.
+	transition := latestBuild.Transition
+	if transition == database.WorkspaceTransitionDelete {
+		entry.Status = database.TemplateVersionRolloutWorkspaceStatusSkipped
+		return entry, nil
+	}
+	builder := wsbuilder.New(workspace, transition).
+		VersionID(rollout.TemplateVersionID).
+		Initiator(rollout.CreatedBy).
+		Reason(database.BuildReasonInitiator).
+		SetLastWorkspaceBuildInTx(&latestBuild).
+		SetLastWorkspaceBuildJobInTx(&latestJob)
+	build, _, err := builder.Build(ctx, tx, nil)
+	if err != nil {
+		var buildErr wsbuilder.BuildError
+		if !xerrors.As(err, &buildErr) {
+			return entry, xerrors.Errorf("build workspace %s: %w", workspace.ID, err)
+		}
+		r.log.Warn(ctx, "unable to build workspace for rollout",
+			slog.F("rollout_id", rollout.ID),
+			slog.F("workspace_id", workspace.ID),
+			slog.Error(err),
+		)
+		entry.Status = database.TemplateVersionRolloutWorkspaceStatusFailed
+		entry.Error = buildErr.Message
+		return entry, nil
+	}
+	entry.BuildID = uuid.NullUUID{UUID: build.ID, Valid: true}
+	entry.Status = database.TemplateVersionRolloutWorkspaceStatusBuilding
+	return entry, nil
+}
+
+// buildStatus returns the rollout status of a workspace from its build job.
+func buildStatus(ctx context.Context, db database.Store, buildID uuid.UUID) (database.TemplateVersionRolloutWorkspaceStatus, error) {
+	build, err := db.GetWorkspaceBuildByID(ctx, buildID)
+	if err != nil {
+		return "", xerrors.Errorf("get workspace build: %w", err)
+	}
+	job, err := db.GetProvisionerJobByID(ctx, build.JobID)
+	if err != nil {
+		return "", xerrors.Errorf("get workspace build job: %w", err)
+	}
+	switch {
+	case !job.CompletedAt.Valid:
+		return database.TemplateVersionRolloutWorkspaceStatusBuilding, nil
+	case job.CanceledAt.Valid, job.Error.String != "":
+		return database.TemplateVersionRolloutWorkspaceStatusFailed, nil
+	default:
+		return database.TemplateVersionRolloutWorkspaceStatusSucceeded, nil
+	}
+}
+
+// minFinishedBuilds is the number of builds that have to finish before the
+// failure rate of a rollout is evaluated, so a single failure early in the
+// rollout doesn't exceed the threshold. Smaller cohorts are evaluated once
+// every build finished.
+const minFinishedBuilds = 5
+
+// pauseReason returns why the rollout should be paused, or an empty string
+// if the share of failed builds is within the threshold.
+func pauseReason(rollout database.TemplateVersionRollout, counts map[database.TemplateVersionRolloutWorkspaceStatus]int) string {
+	failed := counts[database.TemplateVersionRolloutWorkspaceStatusFailed]
+	finished := failed + counts[database.TemplateVersionRolloutWorkspaceStatusSucceeded]
+	cohort := finished + counts[database.TemplateVersionRolloutWorkspaceStatusPending] +
+		counts[database.TemplateVersionRolloutWorkspaceStatusBuilding]
+	if finished < minFinishedBuilds && finished < cohort {
+		return ""
+	}
+	if failed == 0 || failed*100 <= int(rollout.FailureThresholdPercent)*finished {
+		return ""
+	}
+	return fmt.Sprintf("%d of %d builds failed, exceeding the failure threshold of %d%%", failed, finished, rollout.FailureThresholdPercent)
+}
+
+func updateEntry(ctx context.Context, db database.Store, entry database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow) error {
+	err := db.UpdateTemplateVersionRolloutWorkspace(ctx, database.UpdateTemplateVersionRolloutWorkspaceParams{
+		RolloutID:   entry.RolloutID,
+		WorkspaceID: entry.WorkspaceID,
+		BuildID:     entry.BuildID,
+		Status:      entry.Status,
+		Error:       entry.Error,
+		UpdatedAt:   database.Now(),
+	})
+	if err != nil {
+		return xerrors.Errorf("update rollout workspace: %w", err)
+	}
+	return nil
+}
+
+func updateStatus(ctx context.Context, db database.Store, rollout database.TemplateVersionRollout, status database.TemplateVersionRolloutStatus, reason string) error {
+	_, err := db.UpdateTemplateVersionRolloutStatusByID(ctx, database.UpdateTemplateVersionRolloutStatusByIDParams{
+		ID:           rollout.ID,
+		Status:       status,
+		PausedReason: reason,
+		UpdatedAt:    database.Now(),
+	})
+	if err != nil {
+		return xerrors.Errorf("update rollout status: %w", err)
+	}
+	return nil
+}
+
+// Close stops advancing rollouts and waits for in-flight advances to finish.
+func (r *Runner) Close() error {
+	r.cancel()
+	<-r.closed
+	r.wg.Wait()
+	return nil
+}
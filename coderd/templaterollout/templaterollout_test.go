@@ -0,0 +1,153 @@
+package templaterollout_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/templaterollout"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	rawDB := dbfake.New()
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+	db := dbauthz.New(rawDB, rbac.NewAuthorizer(prometheus.NewRegistry()), logger)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	org := dbgen.Organization(t, rawDB, database.Organization{})
+	user := dbgen.User(t, rawDB, database.User{RBACRoles: []string{rbac.RoleOwner()}})
+	template := dbgen.Template(t, rawDB, database.Template{OrganizationID: org.ID, CreatedBy: user.ID})
+	templateVersion := func() database.TemplateVersion {
+		job := dbgen.ProvisionerJob(t, rawDB, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    user.ID,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+			StartedAt:      sql.NullTime{Time: database.Now(), Valid: true},
+			CompletedAt:    sql.NullTime{Time: database.Now(), Valid: true},
+		})
+		return dbgen.TemplateVersion(t, rawDB, database.TemplateVersion{
+			TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+			OrganizationID: org.ID,
+			JobID:          job.ID,
+			CreatedBy:      user.ID,
+		})
+	}
+	oldVersion := templateVersion()
+	newVersion := templateVersion()
+
+	rollout := dbgen.TemplateVersionRollout(t, rawDB, database.TemplateVersionRollout{
+		TemplateID:              template.ID,
+		TemplateVersionID:       newVersion.ID,
+		CreatedBy:               user.ID,
+		BatchSize:               1,
+		FailureThresholdPercent: 20,
+	})
+	for i := 0; i < 2; i++ {
+		workspace := dbgen.Workspace(t, rawDB, database.Workspace{
+			OwnerID:        user.ID,
+			OrganizationID: org.ID,
+			TemplateID:     template.ID,
+		})
+		job := dbgen.ProvisionerJob(t, rawDB, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    user.ID,
+			StartedAt:      sql.NullTime{Time: database.Now(), Valid: true},
+			CompletedAt:    sql.NullTime{Time: database.Now(), Valid: true},
+		})
+		dbgen.WorkspaceBuild(t, rawDB, database.WorkspaceBuild{
+			WorkspaceID:       workspace.ID,
+			TemplateVersionID: oldVersion.ID,
+			InitiatorID:       user.ID,
+			JobID:             job.ID,
+		})
+		err := rawDB.InsertTemplateVersionRolloutWorkspace(ctx, database.InsertTemplateVersionRolloutWorkspaceParams{
+			RolloutID:   rollout.ID,
+			WorkspaceID: workspace.ID,
+			UpdatedAt:   database.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	runner := templaterollout.New(db, logger, templaterollout.Options{Interval: time.Hour})
+	defer runner.Close()
+
+	// completeBuild finishes the build of the workspace that is being moved
+	// to the new version.
+	completeBuild := func(errMessage string) {
+		t.Helper()
+		entries, err := rawDB.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rollout.ID)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			if entry.Status != database.TemplateVersionRolloutWorkspaceStatusBuilding {
+				continue
+			}
+			build, err := rawDB.GetWorkspaceBuildByID(ctx, entry.BuildID.UUID)
+			require.NoError(t, err)
+			require.Equal(t, newVersion.ID, build.TemplateVersionID)
+			require.Equal(t, user.ID, build.InitiatorID)
+			err = rawDB.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+				ID:          build.JobID,
+				UpdatedAt:   database.Now(),
+				CompletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+				Error:       sql.NullString{String: errMessage, Valid: errMessage != ""},
+			})
+			require.NoError(t, err)
+			return
+		}
+		t.Fatal("no workspace is building")
+	}
+	requireCounts := func(pending, building, succeeded, failed int) {
+		t.Helper()
+		entries, err := rawDB.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rollout.ID)
+		require.NoError(t, err)
+		counts := map[database.TemplateVersionRolloutWorkspaceStatus]int{}
+		for _, entry := range entries {
+			counts[entry.Status]++
+		}
+		require.Equal(t, []int{pending, building, succeeded, failed}, []int{
+			counts[database.TemplateVersionRolloutWorkspaceStatusPending],
+			counts[database.TemplateVersionRolloutWorkspaceStatusBuilding],
+			counts[database.TemplateVersionRolloutWorkspaceStatusSucceeded],
+			counts[database.TemplateVersionRolloutWorkspaceStatusFailed],
+		})
+	}
+
+	// The batch size limits the builds in progress.
+	require.NoError(t, runner.Advance(ctx, rollout.ID))
+	requireCounts(1, 1, 0, 0)
+	require.NoError(t, runner.Advance(ctx, rollout.ID))
+	requireCounts(1, 1, 0, 0)
+
+	// A single failure doesn't pause the rollout until enough builds
+	// finished.
+	completeBuild("failed to apply")
+	require.NoError(t, runner.Advance(ctx, rollout.ID))
+	requireCounts(0, 1, 0, 1)
+
+	// One of two builds failing exceeds the threshold.
+	completeBuild("")
+	require.NoError(t, runner.Advance(ctx, rollout.ID))
+	requireCounts(0, 0, 1, 1)
+	rollout, err := rawDB.GetTemplateVersionRolloutByID(ctx, rollout.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateVersionRolloutStatusPaused, rollout.Status)
+	require.Equal(t, "1 of 2 builds failed, exceeding the failure threshold of 20%", rollout.PausedReason)
+
+	// Paused rollouts are left untouched.
+	require.NoError(t, runner.Advance(ctx, rollout.ID))
+	requireCounts(0, 0, 1, 1)
+}
@@ -331,11 +331,20 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 		if err != nil {
 			return xerrors.Errorf("insert template version: %s", err)
 		}
+		err = tx.UpdateTemplateVersionStateByID(ctx, database.UpdateTemplateVersionStateByIDParams{
+			ID:        templateVersion.ID,
+			State:     database.TemplateVersionStateActive,
+			UpdatedAt: database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("update template version state: %s", err)
+		}
 		newTemplateVersion := templateVersion
 		newTemplateVersion.TemplateID = uuid.NullUUID{
 			UUID:  dbTemplate.ID,
 			Valid: true,
 		}
+		newTemplateVersion.State = database.TemplateVersionStateActive
 		templateVersionAudit.New = newTemplateVersion
 
 		createdByNameMap, err := getCreatedByNamesByTemplateIDs(ctx, tx, []database.Template{dbTemplate})
@@ -497,6 +506,13 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.InactivityTTLMillis < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "inactivity_ttl_ms", Detail: "Must be a positive integer."})
 	}
+	maxPortShareLevel := template.MaxPortShareLevel
+	if req.MaxPortShareLevel != nil {
+		if !req.MaxPortShareLevel.Valid() {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "max_port_share_level", Detail: fmt.Sprintf("Must be one of %v.", codersdk.WorkspacePortShareLevels)})
+		}
+		maxPortShareLevel = database.PortShareLevel(*req.MaxPortShareLevel)
+	}
 
 	if len(validErrs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -515,6 +531,8 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			req.AllowUserAutostart == template.AllowUserAutostart &&
 			req.AllowUserAutostop == template.AllowUserAutostop &&
 			req.AllowUserCancelWorkspaceJobs == template.AllowUserCancelWorkspaceJobs &&
+			req.RequireActiveVersion == template.RequireActiveVersion &&
+			maxPortShareLevel == template.MaxPortShareLevel &&
 			req.DefaultTTLMillis == time.Duration(template.DefaultTTL).Milliseconds() &&
 			req.MaxTTLMillis == time.Duration(template.MaxTTL).Milliseconds() &&
 			req.FailureTTLMillis == time.Duration(template.FailureTTL).Milliseconds() &&
@@ -537,6 +555,8 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			Description:                  req.Description,
 			Icon:                         req.Icon,
 			AllowUserCancelWorkspaceJobs: req.AllowUserCancelWorkspaceJobs,
+			RequireActiveVersion:         req.RequireActiveVersion,
+			MaxPortShareLevel:            maxPortShareLevel,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template metadata: %w", err)
@@ -716,5 +736,7 @@ func (api *API) convertTemplate(
 		AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
 		FailureTTLMillis:             time.Duration(template.FailureTTL).Milliseconds(),
 		InactivityTTLMillis:          time.Duration(template.InactivityTTL).Milliseconds(),
+		RequireActiveVersion:         template.RequireActiveVersion,
+		MaxPortShareLevel:            codersdk.WorkspacePortShareLevel(template.MaxPortShareLevel),
 	}
 }
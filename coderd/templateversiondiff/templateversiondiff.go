@@ -0,0 +1,374 @@
+// Package templateversiondiff compares two template versions.
+//
+// The comparison covers the files in the source archives, rich parameters,
+// template variables, and the resources, agents and apps reported by the
+// import job. Entries are matched by name, so renaming something shows up as
+// a removal and an addition.
+package templateversiondiff
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/diff"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+)
+
+// redacted replaces the values of sensitive template variables.
+const redacted = "*redacted*"
+
+// Version holds the parts of a template version that are compared.
+type Version struct {
+	// Archive is the tar archive uploaded for the version.
+	Archive    []byte
+	Parameters []database.TemplateVersionParameter
+	Variables  []database.TemplateVersionVariable
+	Resources  []database.WorkspaceResource
+	Agents     []database.WorkspaceAgent
+	Apps       []database.WorkspaceApp
+}
+
+// Compare returns the differences between base and version. The returned
+// slices are never nil.
+func Compare(base, version Version) (codersdk.TemplateVersionDiff, error) {
+	files, err := Files(base.Archive, version.Archive)
+	if err != nil {
+		return codersdk.TemplateVersionDiff{}, err
+	}
+	return codersdk.TemplateVersionDiff{
+		Files:      files,
+		Parameters: Parameters(base.Parameters, version.Parameters),
+		Variables:  Variables(base.Variables, version.Variables),
+		Resources:  Resources(base, version),
+	}, nil
+}
+
+// Files returns a unified diff for every file that differs between two tar
+// archives, sorted by path.
+func Files(base, version []byte) ([]codersdk.TemplateVersionFileDiff, error) {
+	baseFiles, err := readArchive(base)
+	if err != nil {
+		return nil, xerrors.Errorf("read base archive: %w", err)
+	}
+	versionFiles, err := readArchive(version)
+	if err != nil {
+		return nil, xerrors.Errorf("read archive: %w", err)
+	}
+
+	diffs := make([]codersdk.TemplateVersionFileDiff, 0)
+	for _, path := range sortedKeys(baseFiles, versionFiles) {
+		oldContent, inBase := baseFiles[path]
+		newContent, inVersion := versionFiles[path]
+
+		fileDiff := codersdk.TemplateVersionFileDiff{Path: path}
+		oldName, newName := "a/"+path, "b/"+path
+		switch {
+		case !inBase:
+			fileDiff.Change = codersdk.TemplateVersionDiffChangeAdded
+			oldName = "/dev/null"
+		case !inVersion:
+			fileDiff.Change = codersdk.TemplateVersionDiffChangeRemoved
+			newName = "/dev/null"
+		case bytes.Equal(oldContent, newContent):
+			continue
+		default:
+			fileDiff.Change = codersdk.TemplateVersionDiffChangeModified
+		}
+
+		if !utf8.Valid(oldContent) || !utf8.Valid(newContent) {
+			fileDiff.Binary = true
+			diffs = append(diffs, fileDiff)
+			continue
+		}
+		var buf bytes.Buffer
+		err = diff.Text(oldName, newName, oldContent, newContent, &buf)
+		if xerrors.Is(err, bufio.ErrTooLong) {
+			// Files with very long lines, e.g. minified assets, are
+			// reported like binary files.
+			fileDiff.Binary = true
+			diffs = append(diffs, fileDiff)
+			continue
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("diff %q: %w", path, err)
+		}
+		fileDiff.Diff = buf.String()
+		diffs = append(diffs, fileDiff)
+	}
+	return diffs, nil
+}
+
+// readArchive returns the contents of every regular file in a tar archive
+// keyed by its cleaned path.
+func readArchive(data []byte) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if xerrors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(header.Name, "./")] = content
+	}
+}
+
+// Parameters returns the rich parameters that were added, removed or
+// modified, sorted by name.
+func Parameters(base, version []database.TemplateVersionParameter) []codersdk.TemplateVersionParameterDiff {
+	baseParams := make(map[string]database.TemplateVersionParameter, len(base))
+	for _, param := range base {
+		baseParams[param.Name] = param
+	}
+	versionParams := make(map[string]database.TemplateVersionParameter, len(version))
+	for _, param := range version {
+		versionParams[param.Name] = param
+	}
+
+	diffs := make([]codersdk.TemplateVersionParameterDiff, 0)
+	for _, name := range sortedKeys(baseParams, versionParams) {
+		oldParam, inBase := baseParams[name]
+		newParam, inVersion := versionParams[name]
+		switch {
+		case !inBase:
+			diffs = append(diffs, codersdk.TemplateVersionParameterDiff{Name: name, Change: codersdk.TemplateVersionDiffChangeAdded})
+		case !inVersion:
+			diffs = append(diffs, codersdk.TemplateVersionParameterDiff{Name: name, Change: codersdk.TemplateVersionDiffChangeRemoved})
+		default:
+			var f fields
+			f.add("display_name", oldParam.DisplayName, newParam.DisplayName)
+			f.add("description", oldParam.Description, newParam.Description)
+			f.add("type", oldParam.Type, newParam.Type)
+			f.addBool("mutable", oldParam.Mutable, newParam.Mutable)
+			f.addBool("required", oldParam.Required, newParam.Required)
+			f.add("default_value", oldParam.DefaultValue, newParam.DefaultValue)
+			f.add("icon", oldParam.Icon, newParam.Icon)
+			f.add("options", string(oldParam.Options), string(newParam.Options))
+			f.add("validation_regex", oldParam.ValidationRegex, newParam.ValidationRegex)
+			f.add("validation_error", oldParam.ValidationError, newParam.ValidationError)
+			f.addInt32("validation_min", oldParam.ValidationMin.Int32, oldParam.ValidationMin.Valid, newParam.ValidationMin.Int32, newParam.ValidationMin.Valid)
+			f.addInt32("validation_max", oldParam.ValidationMax.Int32, oldParam.ValidationMax.Valid, newParam.ValidationMax.Int32, newParam.ValidationMax.Valid)
+			f.add("validation_monotonic", oldParam.ValidationMonotonic, newParam.ValidationMonotonic)
+			if len(f) == 0 {
+				continue
+			}
+			diffs = append(diffs, codersdk.TemplateVersionParameterDiff{Name: name, Change: codersdk.TemplateVersionDiffChangeModified, Fields: f})
+		}
+	}
+	return diffs
+}
+
+// Variables returns the template variables that were added, removed or
+// modified, sorted by name. Values are redacted if the variable is sensitive
+// in either version.
+func Variables(base, version []database.TemplateVersionVariable) []codersdk.TemplateVersionVariableDiff {
+	baseVars := make(map[string]database.TemplateVersionVariable, len(base))
+	for _, variable := range base {
+		baseVars[variable.Name] = variable
+	}
+	versionVars := make(map[string]database.TemplateVersionVariable, len(version))
+	for _, variable := range version {
+		versionVars[variable.Name] = variable
+	}
+
+	diffs := make([]codersdk.TemplateVersionVariableDiff, 0)
+	for _, name := range sortedKeys(baseVars, versionVars) {
+		oldVar, inBase := baseVars[name]
+		newVar, inVersion := versionVars[name]
+		switch {
+		case !inBase:
+			diffs = append(diffs, codersdk.TemplateVersionVariableDiff{Name: name, Change: codersdk.TemplateVersionDiffChangeAdded})
+		case !inVersion:
+			diffs = append(diffs, codersdk.TemplateVersionVariableDiff{Name: name, Change: codersdk.TemplateVersionDiffChangeRemoved})
+		default:
+			var f fields
+			f.add("description", oldVar.Description, newVar.Description)
+			f.add("type", oldVar.Type, newVar.Type)
+			f.addBool("required", oldVar.Required, newVar.Required)
+			f.addBool("sensitive", oldVar.Sensitive, newVar.Sensitive)
+			if oldVar.Sensitive || newVar.Sensitive {
+				f.addRedacted("default_value", oldVar.DefaultValue, newVar.DefaultValue)
+				f.addRedacted("value", oldVar.Value, newVar.Value)
+			} else {
+				f.add("default_value", oldVar.DefaultValue, newVar.DefaultValue)
+				f.add("value", oldVar.Value, newVar.Value)
+			}
+			if len(f) == 0 {
+				continue
+			}
+			diffs = append(diffs, codersdk.TemplateVersionVariableDiff{Name: name, Change: codersdk.TemplateVersionDiffChangeModified, Fields: f})
+		}
+	}
+	return diffs
+}
+
+// Resources returns the resources, agents and apps from the import jobs that
+// were added, removed or modified, sorted by path. Only resources created on
+// start are compared, since those are what users see in their workspaces.
+func Resources(base, version Version) []codersdk.TemplateVersionResourceDiff {
+	baseEntries := resourceEntries(base)
+	versionEntries := resourceEntries(version)
+
+	diffs := make([]codersdk.TemplateVersionResourceDiff, 0)
+	for _, path := range sortedKeys(baseEntries, versionEntries) {
+		oldEntry, inBase := baseEntries[path]
+		newEntry, inVersion := versionEntries[path]
+		switch {
+		case !inBase:
+			diffs = append(diffs, codersdk.TemplateVersionResourceDiff{Kind: newEntry.kind, Path: path, Change: codersdk.TemplateVersionDiffChangeAdded})
+		case !inVersion:
+			diffs = append(diffs, codersdk.TemplateVersionResourceDiff{Kind: oldEntry.kind, Path: path, Change: codersdk.TemplateVersionDiffChangeRemoved})
+		default:
+			var f fields
+			for _, name := range sortedKeys(oldEntry.attributes, newEntry.attributes) {
+				f.add(name, oldEntry.attributes[name], newEntry.attributes[name])
+			}
+			if len(f) == 0 {
+				continue
+			}
+			diffs = append(diffs, codersdk.TemplateVersionResourceDiff{Kind: newEntry.kind, Path: path, Change: codersdk.TemplateVersionDiffChangeModified, Fields: f})
+		}
+	}
+	return diffs
+}
+
+type resourceEntry struct {
+	kind       codersdk.TemplateVersionDiffKind
+	attributes map[string]string
+}
+
+// resourceEntries flattens the resources, agents and apps of a version into
+// entries keyed by path.
+func resourceEntries(version Version) map[string]resourceEntry {
+	entries := make(map[string]resourceEntry)
+	resourcePaths := make(map[string]string)
+	for _, resource := range version.Resources {
+		if resource.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
+		path := resource.Type + "." + resource.Name
+		resourcePaths[resource.ID.String()] = path
+		entries[path] = resourceEntry{
+			kind: codersdk.TemplateVersionDiffKindResource,
+			attributes: map[string]string{
+				"hide":          strconv.FormatBool(resource.Hide),
+				"icon":          resource.Icon,
+				"instance_type": resource.InstanceType.String,
+				"daily_cost":    strconv.FormatInt(int64(resource.DailyCost), 10),
+			},
+		}
+	}
+
+	agentPaths := make(map[string]string)
+	for _, agent := range version.Agents {
+		resourcePath, ok := resourcePaths[agent.ResourceID.String()]
+		if !ok {
+			continue
+		}
+		path := resourcePath + "/" + agent.Name
+		agentPaths[agent.ID.String()] = path
+		entries[path] = resourceEntry{
+			kind: codersdk.TemplateVersionDiffKindAgent,
+			attributes: map[string]string{
+				"operating_system":                agent.OperatingSystem,
+				"architecture":                    agent.Architecture,
+				"directory":                       agent.Directory,
+				"startup_script":                  agent.StartupScript.String,
+				"startup_script_behavior":         string(agent.StartupScriptBehavior),
+				"startup_script_timeout_seconds":  strconv.FormatInt(int64(agent.StartupScriptTimeoutSeconds), 10),
+				"shutdown_script":                 agent.ShutdownScript.String,
+				"shutdown_script_timeout_seconds": strconv.FormatInt(int64(agent.ShutdownScriptTimeoutSeconds), 10),
+				"connection_timeout_seconds":      strconv.FormatInt(int64(agent.ConnectionTimeoutSeconds), 10),
+				"troubleshooting_url":             agent.TroubleshootingURL,
+				"motd_file":                       agent.MOTDFile,
+			},
+		}
+	}
+
+	for _, app := range version.Apps {
+		agentPath, ok := agentPaths[app.AgentID.String()]
+		if !ok {
+			continue
+		}
+		entries[agentPath+"/"+app.Slug] = resourceEntry{
+			kind: codersdk.TemplateVersionDiffKindApp,
+			attributes: map[string]string{
+				"display_name":    app.DisplayName,
+				"icon":            app.Icon,
+				"command":         app.Command.String,
+				"url":             app.Url.String,
+				"external":        strconv.FormatBool(app.External),
+				"subdomain":       strconv.FormatBool(app.Subdomain),
+				"sharing_level":   string(app.SharingLevel),
+				"healthcheck_url": app.HealthcheckUrl,
+			},
+		}
+	}
+	return entries
+}
+
+// fields collects the attributes that differ between two versions.
+type fields []codersdk.TemplateVersionFieldDiff
+
+func (f *fields) add(name, before, after string) {
+	if before == after {
+		return
+	}
+	*f = append(*f, codersdk.TemplateVersionFieldDiff{Field: name, Old: before, New: after})
+}
+
+func (f *fields) addBool(name string, before, after bool) {
+	f.add(name, strconv.FormatBool(before), strconv.FormatBool(after))
+}
+
+func (f *fields) addInt32(name string, before int32, beforeValid bool, after int32, afterValid bool) {
+	var beforeStr, afterStr string
+	if beforeValid {
+		beforeStr = strconv.FormatInt(int64(before), 10)
+	}
+	if afterValid {
+		afterStr = strconv.FormatInt(int64(after), 10)
+	}
+	f.add(name, beforeStr, afterStr)
+}
+
+// addRedacted records that a sensitive value changed without revealing it.
+func (f *fields) addRedacted(name, before, after string) {
+	if before == after {
+		return
+	}
+	*f = append(*f, codersdk.TemplateVersionFieldDiff{Field: name, Old: redacted, New: redacted})
+}
+
+// sortedKeys returns the union of the keys of a and b in sorted order.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,184 @@
+package templateversiondiff_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/templateversiondiff"
+	"github.com/coder/coder/codersdk"
+)
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Changes", func(t *testing.T) {
+		t.Parallel()
+		base := archive(t, map[string]string{
+			"main.tf":    "line 1\nline 2\n",
+			"removed.tf": "gone\n",
+			"same.tf":    "same\n",
+		})
+		version := archive(t, map[string]string{
+			"main.tf":  "line 1\nline 3\n",
+			"added.tf": "new\n",
+			"same.tf":  "same\n",
+			"logo.png": "\xff\xfe",
+		})
+
+		diffs, err := templateversiondiff.Files(base, version)
+		require.NoError(t, err)
+		require.Len(t, diffs, 4)
+
+		require.Equal(t, "added.tf", diffs[0].Path)
+		require.Equal(t, codersdk.TemplateVersionDiffChangeAdded, diffs[0].Change)
+		require.Contains(t, diffs[0].Diff, "--- /dev/null")
+		require.Contains(t, diffs[0].Diff, "+new")
+
+		require.Equal(t, "logo.png", diffs[1].Path)
+		require.True(t, diffs[1].Binary)
+		require.Empty(t, diffs[1].Diff)
+
+		require.Equal(t, "main.tf", diffs[2].Path)
+		require.Equal(t, codersdk.TemplateVersionDiffChangeModified, diffs[2].Change)
+		require.Contains(t, diffs[2].Diff, "--- a/main.tf")
+		require.Contains(t, diffs[2].Diff, "+++ b/main.tf")
+		require.Contains(t, diffs[2].Diff, "-line 2")
+		require.Contains(t, diffs[2].Diff, "+line 3")
+
+		require.Equal(t, "removed.tf", diffs[3].Path)
+		require.Equal(t, codersdk.TemplateVersionDiffChangeRemoved, diffs[3].Change)
+		require.Contains(t, diffs[3].Diff, "+++ /dev/null")
+	})
+
+	t.Run("LongLines", func(t *testing.T) {
+		t.Parallel()
+		base := archive(t, map[string]string{"bundle.js": "a"})
+		version := archive(t, map[string]string{"bundle.js": strings.Repeat("b", 1<<17)})
+
+		diffs, err := templateversiondiff.Files(base, version)
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		require.True(t, diffs[0].Binary)
+	})
+
+	t.Run("InvalidArchive", func(t *testing.T) {
+		t.Parallel()
+		_, err := templateversiondiff.Files([]byte("not a tar"), archive(t, nil))
+		require.Error(t, err)
+	})
+}
+
+func TestParameters(t *testing.T) {
+	t.Parallel()
+
+	base := []database.TemplateVersionParameter{
+		{Name: "region", Type: "string", DefaultValue: "us"},
+		{Name: "size", Type: "number", ValidationMin: sql.NullInt32{Int32: 1, Valid: true}},
+		{Name: "old", Type: "string"},
+	}
+	version := []database.TemplateVersionParameter{
+		{Name: "region", Type: "string", DefaultValue: "us"},
+		{Name: "size", Type: "number", ValidationMin: sql.NullInt32{Int32: 2, Valid: true}, ValidationMax: sql.NullInt32{Int32: 8, Valid: true}},
+		{Name: "new", Type: "bool"},
+	}
+
+	diffs := templateversiondiff.Parameters(base, version)
+	require.Equal(t, []codersdk.TemplateVersionParameterDiff{
+		{Name: "new", Change: codersdk.TemplateVersionDiffChangeAdded},
+		{Name: "old", Change: codersdk.TemplateVersionDiffChangeRemoved},
+		{Name: "size", Change: codersdk.TemplateVersionDiffChangeModified, Fields: []codersdk.TemplateVersionFieldDiff{
+			{Field: "validation_min", Old: "1", New: "2"},
+			{Field: "validation_max", Old: "", New: "8"},
+		}},
+	}, diffs)
+}
+
+func TestVariables(t *testing.T) {
+	t.Parallel()
+
+	base := []database.TemplateVersionVariable{
+		{Name: "image", Type: "string", DefaultValue: "ubuntu"},
+		{Name: "token", Type: "string", Value: "secret-1", Sensitive: true},
+	}
+	version := []database.TemplateVersionVariable{
+		{Name: "image", Type: "string", DefaultValue: "debian"},
+		{Name: "token", Type: "string", Value: "secret-2", Sensitive: true},
+	}
+
+	diffs := templateversiondiff.Variables(base, version)
+	require.Equal(t, []codersdk.TemplateVersionVariableDiff{
+		{Name: "image", Change: codersdk.TemplateVersionDiffChangeModified, Fields: []codersdk.TemplateVersionFieldDiff{
+			{Field: "default_value", Old: "ubuntu", New: "debian"},
+		}},
+		{Name: "token", Change: codersdk.TemplateVersionDiffChangeModified, Fields: []codersdk.TemplateVersionFieldDiff{
+			{Field: "value", Old: "*redacted*", New: "*redacted*"},
+		}},
+	}, diffs)
+}
+
+func TestResources(t *testing.T) {
+	t.Parallel()
+
+	version := func(os string, apps ...string) templateversiondiff.Version {
+		resource := database.WorkspaceResource{
+			ID:         uuid.New(),
+			Transition: database.WorkspaceTransitionStart,
+			Type:       "docker_container",
+			Name:       "main",
+		}
+		agent := database.WorkspaceAgent{
+			ID:              uuid.New(),
+			ResourceID:      resource.ID,
+			Name:            "dev",
+			OperatingSystem: os,
+		}
+		v := templateversiondiff.Version{
+			Resources: []database.WorkspaceResource{resource, {
+				ID:         uuid.New(),
+				Transition: database.WorkspaceTransitionStop,
+				Type:       "docker_volume",
+				Name:       uuid.NewString(),
+			}},
+			Agents: []database.WorkspaceAgent{agent},
+		}
+		for _, slug := range apps {
+			v.Apps = append(v.Apps, database.WorkspaceApp{ID: uuid.New(), AgentID: agent.ID, Slug: slug})
+		}
+		return v
+	}
+
+	diffs := templateversiondiff.Resources(version("linux", "code-server"), version("windows", "jupyter"))
+	require.Equal(t, []codersdk.TemplateVersionResourceDiff{
+		{Kind: codersdk.TemplateVersionDiffKindAgent, Path: "docker_container.main/dev", Change: codersdk.TemplateVersionDiffChangeModified, Fields: []codersdk.TemplateVersionFieldDiff{
+			{Field: "operating_system", Old: "linux", New: "windows"},
+		}},
+		{Kind: codersdk.TemplateVersionDiffKindApp, Path: "docker_container.main/dev/code-server", Change: codersdk.TemplateVersionDiffChangeRemoved},
+		{Kind: codersdk.TemplateVersionDiffKindApp, Path: "docker_container.main/dev/jupyter", Change: codersdk.TemplateVersionDiffChangeAdded},
+	}, diffs)
+}
+
+func archive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for name, content := range files {
+		err := writer.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
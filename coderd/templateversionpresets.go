@@ -0,0 +1,261 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get presets by template version
+// @ID get-presets-by-template-version
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Success 200 {array} codersdk.TemplateVersionPreset
+// @Router /templateversions/{templateversion}/presets [get]
+func (api *API) templateVersionPresets(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	templateVersion := httpmw.TemplateVersionParam(r)
+
+	presets, err := api.fetchTemplateVersionPresets(ctx, api.Database, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version presets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, presets)
+}
+
+// @Summary Update presets by template version
+// @Description Replaces every preset of the template version.
+// @ID update-presets-by-template-version
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateVersionPresetsRequest true "Update template version presets request"
+// @Success 200 {array} codersdk.TemplateVersionPreset
+// @Router /templateversions/{templateversion}/presets [put]
+func (api *API) putTemplateVersionPresets(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	templateVersion := httpmw.TemplateVersionParam(r)
+
+	var req codersdk.UpdateTemplateVersionPresetsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	job, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Presets are validated against the rich parameters, which are only known
+	// once the template version has been imported.
+	if !job.CompletedAt.Valid || job.Error.Valid {
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "The template version must be imported successfully before presets can be set.",
+		})
+		return
+	}
+
+	dbTemplateVersionParameters, err := api.Database.GetTemplateVersionParameters(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersionParameters, err := convertTemplateVersionParameters(dbTemplateVersionParameters)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template version parameter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var validations []codersdk.ValidationError
+	presetNames := map[string]struct{}{}
+	for i, preset := range req.Presets {
+		field := fmt.Sprintf("presets[%d]", i)
+		if _, ok := presetNames[preset.Name]; ok {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".name",
+				Detail: fmt.Sprintf("Preset %q is defined more than once.", preset.Name),
+			})
+			continue
+		}
+		presetNames[preset.Name] = struct{}{}
+
+		err := validateTemplateVersionPreset(templateVersionParameters, preset)
+		if err != nil {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".parameters",
+				Detail: err.Error(),
+			})
+		}
+	}
+	if len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid template version presets.",
+			Validations: validations,
+		})
+		return
+	}
+
+	var presets []codersdk.TemplateVersionPreset
+	err = api.Database.InTx(func(tx database.Store) error {
+		err := tx.DeleteTemplateVersionPresetsByTemplateVersionID(ctx, templateVersion.ID)
+		if err != nil {
+			return xerrors.Errorf("delete template version presets: %w", err)
+		}
+		for _, preset := range req.Presets {
+			dbPreset, err := tx.InsertTemplateVersionPreset(ctx, database.InsertTemplateVersionPresetParams{
+				ID:                uuid.New(),
+				TemplateVersionID: templateVersion.ID,
+				Name:              preset.Name,
+				Description:       preset.Description,
+				CreatedAt:         database.Now(),
+			})
+			if err != nil {
+				return xerrors.Errorf("insert template version preset %q: %w", preset.Name, err)
+			}
+			names := make([]string, 0, len(preset.Parameters))
+			values := make([]string, 0, len(preset.Parameters))
+			for _, parameter := range preset.Parameters {
+				names = append(names, parameter.Name)
+				values = append(values, parameter.Value)
+			}
+			err = tx.InsertTemplateVersionPresetParameters(ctx, database.InsertTemplateVersionPresetParametersParams{
+				PresetID: dbPreset.ID,
+				Name:     names,
+				Value:    values,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert template version preset parameters: %w", err)
+			}
+		}
+		presets, err = api.fetchTemplateVersionPresets(ctx, tx, templateVersion.ID)
+		return err
+	}, nil)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template version presets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, presets)
+}
+
+// validateTemplateVersionPreset checks the values of a preset against the rich
+// parameters of the template version. Presets may leave out parameters, so
+// only the parameters they set are validated.
+func validateTemplateVersionPreset(templateVersionParameters []codersdk.TemplateVersionParameter, preset codersdk.TemplateVersionPresetRequest) error {
+	richParameters := make([]codersdk.TemplateVersionParameter, 0, len(preset.Parameters))
+	seen := map[string]struct{}{}
+	for _, parameter := range preset.Parameters {
+		if _, ok := seen[parameter.Name]; ok {
+			return xerrors.Errorf("parameter %q is set more than once", parameter.Name)
+		}
+		seen[parameter.Name] = struct{}{}
+
+		found := false
+		for _, templateVersionParameter := range templateVersionParameters {
+			if templateVersionParameter.Name == parameter.Name {
+				richParameters = append(richParameters, templateVersionParameter)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return xerrors.Errorf("parameter %q is not defined by the template version", parameter.Name)
+		}
+	}
+	return codersdk.ValidateWorkspaceBuildParameters(richParameters, preset.Parameters, nil)
+}
+
+// presetRichParameterValues merges the values of a preset of the template's
+// active version with the values of the request. Values of the request take
+// precedence over the preset.
+func (api *API) presetRichParameterValues(ctx context.Context, template database.Template, presetID uuid.UUID, values []codersdk.WorkspaceBuildParameter) ([]codersdk.WorkspaceBuildParameter, error) {
+	presets, err := api.fetchTemplateVersionPresets(ctx, api.Database, template.ActiveVersionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, preset := range presets {
+		if preset.ID != presetID {
+			continue
+		}
+		merged := make([]codersdk.WorkspaceBuildParameter, 0, len(values)+len(preset.Parameters))
+		merged = append(merged, values...)
+	PresetParameters:
+		for _, parameter := range preset.Parameters {
+			for _, value := range values {
+				if value.Name == parameter.Name {
+					continue PresetParameters
+				}
+			}
+			merged = append(merged, parameter)
+		}
+		return merged, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (*API) fetchTemplateVersionPresets(ctx context.Context, db database.Store, templateVersionID uuid.UUID) ([]codersdk.TemplateVersionPreset, error) {
+	dbPresets, err := db.GetTemplateVersionPresetsByTemplateVersionID(ctx, templateVersionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get template version presets: %w", err)
+	}
+	dbParameters, err := db.GetTemplateVersionPresetParametersByTemplateVersionID(ctx, templateVersionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get template version preset parameters: %w", err)
+	}
+
+	parameters := map[uuid.UUID][]codersdk.WorkspaceBuildParameter{}
+	for _, parameter := range dbParameters {
+		parameters[parameter.PresetID] = append(parameters[parameter.PresetID], codersdk.WorkspaceBuildParameter{
+			Name:  parameter.Name,
+			Value: parameter.Value,
+		})
+	}
+	presets := make([]codersdk.TemplateVersionPreset, 0, len(dbPresets))
+	for _, preset := range dbPresets {
+		presetParameters := parameters[preset.ID]
+		if presetParameters == nil {
+			presetParameters = []codersdk.WorkspaceBuildParameter{}
+		}
+		presets = append(presets, codersdk.TemplateVersionPreset{
+			ID:          preset.ID,
+			Name:        preset.Name,
+			Description: preset.Description,
+			Parameters:  presetParameters,
+		})
+	}
+	return presets, nil
+}
@@ -0,0 +1,226 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateVersionPresets(t *testing.T) {
+	t.Parallel()
+
+	// setup creates a template whose version has a required "project"
+	// parameter and two optional ones.
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.CreateFirstUserResponse, codersdk.Template, codersdk.TemplateVersion) {
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Parameters: []*proto.RichParameter{
+							{
+								Name:     "project",
+								Type:     "string",
+								Required: true,
+							},
+							{
+								Name:          "cpu",
+								Type:          "number",
+								DefaultValue:  "2",
+								ValidationMin: ptr.Ref(int32(1)),
+								ValidationMax: ptr.Ref(int32(8)),
+							},
+							{
+								Name:         "region",
+								Type:         "string",
+								DefaultValue: "us",
+								Options: []*proto.RichParameterOption{
+									{Name: "US", Value: "us"},
+									{Name: "EU", Value: "eu"},
+								},
+							},
+						},
+					},
+				},
+			}},
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		return client, user, template, version
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client, _, _, version := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		presets, err := client.TemplateVersionPresets(ctx, version.ID)
+		require.NoError(t, err)
+		require.Empty(t, presets)
+
+		presets, err = client.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+			Presets: []codersdk.TemplateVersionPresetRequest{
+				{
+					Name:        "large",
+					Description: "Eight cores in Europe",
+					Parameters: []codersdk.WorkspaceBuildParameter{
+						{Name: "cpu", Value: "8"},
+						{Name: "region", Value: "eu"},
+					},
+				},
+				{
+					Name:       "small",
+					Parameters: []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "1"}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, presets, 2)
+		require.Equal(t, "large", presets[0].Name)
+		require.Equal(t, "Eight cores in Europe", presets[0].Description)
+		require.ElementsMatch(t, []codersdk.WorkspaceBuildParameter{
+			{Name: "cpu", Value: "8"},
+			{Name: "region", Value: "eu"},
+		}, presets[0].Parameters)
+
+		// Presets are replaced as a whole.
+		_, err = client.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+			Presets: []codersdk.TemplateVersionPresetRequest{{
+				Name:       "small",
+				Parameters: []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "2"}},
+			}},
+		})
+		require.NoError(t, err)
+		presets, err = client.TemplateVersionPresets(ctx, version.ID)
+		require.NoError(t, err)
+		require.Len(t, presets, 1)
+		require.Equal(t, []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "2"}}, presets[0].Parameters)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+		client, _, _, version := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		for _, parameters := range [][]codersdk.WorkspaceBuildParameter{
+			{{Name: "cpu", Value: "16"}},
+			{{Name: "region", Value: "asia"}},
+			{{Name: "unknown", Value: "1"}},
+			{{Name: "cpu", Value: "1"}, {Name: "cpu", Value: "2"}},
+		} {
+			_, err := client.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+				Presets: []codersdk.TemplateVersionPresetRequest{{
+					Name:       "invalid",
+					Parameters: parameters,
+				}},
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+			require.Equal(t, "presets[0].parameters", apiErr.Validations[0].Field)
+		}
+
+		_, err := client.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+			Presets: []codersdk.TemplateVersionPresetRequest{
+				{Name: "small", Parameters: []codersdk.WorkspaceBuildParameter{}},
+				{Name: "small", Parameters: []codersdk.WorkspaceBuildParameter{}},
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "presets[1].name", apiErr.Validations[0].Field)
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+		client, user, _, version := setup(t)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.TemplateVersionPresets(ctx, version.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("CreateWorkspace", func(t *testing.T) {
+		t.Parallel()
+		client, user, template, version := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		presets, err := client.UpdateTemplateVersionPresets(ctx, version.ID, codersdk.UpdateTemplateVersionPresetsRequest{
+			Presets: []codersdk.TemplateVersionPresetRequest{{
+				Name: "large",
+				Parameters: []codersdk.WorkspaceBuildParameter{
+					{Name: "cpu", Value: "8"},
+					{Name: "region", Value: "eu"},
+				},
+			}},
+		})
+		require.NoError(t, err)
+
+		// Explicit values take precedence over the preset.
+		workspace, err := client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "preset",
+			RichParameterValues: []codersdk.WorkspaceBuildParameter{
+				{Name: "project", Value: "coder"},
+				{Name: "region", Value: "us"},
+			},
+			TemplateVersionPresetID: presets[0].ID,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		parameters, err := client.WorkspaceBuildParameters(ctx, workspace.LatestBuild.ID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []codersdk.WorkspaceBuildParameter{
+			{Name: "project", Value: "coder"},
+			{Name: "cpu", Value: "8"},
+			{Name: "region", Value: "us"},
+		}, parameters)
+
+		// The preset doesn't set the required parameter.
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID:              template.ID,
+			Name:                    "missing",
+			TemplateVersionPresetID: presets[0].ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "rich_parameter_values", apiErr.Validations[0].Field)
+
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID:              template.ID,
+			Name:                    "unknown",
+			TemplateVersionPresetID: uuid.New(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "template_version_preset_id", apiErr.Validations[0].Field)
+	})
+}
@@ -0,0 +1,499 @@
+package coderd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/db2sdk"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get template version rollouts by template
+// @ID get-template-version-rollouts-by-template
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {array} codersdk.TemplateVersionRollout
+// @Router /templates/{template}/rollouts [get]
+func (api *API) templateVersionRollouts(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	rollouts, err := api.Database.GetTemplateVersionRolloutsByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version rollouts.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	versionIDs := make([]uuid.UUID, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		versionIDs = append(versionIDs, rollout.TemplateVersionID)
+	}
+	versions, err := api.Database.GetTemplateVersionsByIDs(ctx, versionIDs)
+	if err != nil && !httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template versions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	versionNames := make(map[uuid.UUID]string, len(versions))
+	for _, version := range versions {
+		versionNames[version.ID] = version.Name
+	}
+
+	resp := make([]codersdk.TemplateVersionRollout, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		entries, err := api.Database.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rollout.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template version rollout workspaces.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		resp = append(resp, convertTemplateVersionRollout(rollout, versionNames[rollout.TemplateVersionID], entries, false))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Get template version rollout
+// @ID get-template-version-rollout
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param rollout path string true "Rollout ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateVersionRollout
+// @Router /templates/{template}/rollouts/{rollout} [get]
+func (api *API) templateVersionRollout(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		rollout = httpmw.TemplateVersionRolloutParam(r)
+	)
+
+	resp, ok := api.fetchTemplateVersionRollout(rw, r, rollout)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Create template version rollout
+// @Description Moves a percentage of the template's workspaces, optionally
+// @Description restricted to the members of groups, to a template version in
+// @Description batches. The rollout is paused when the share of failed builds
+// @Description exceeds the failure threshold.
+// @ID create-template-version-rollout
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.CreateTemplateVersionRolloutRequest true "Rollout request"
+// @Success 201 {object} codersdk.TemplateVersionRollout
+// @Router /templates/{template}/rollouts [post]
+func (api *API) postTemplateVersionRollout(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		apiKey            = httpmw.APIKey(r)
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersionRollout](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.CreateTemplateVersionRolloutRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	version, err := api.Database.GetTemplateVersionByID(ctx, req.TemplateVersionID)
+	if httpapi.Is404Error(err) || (err == nil && version.TemplateID.UUID != template.ID) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template version doesn't belong to the template.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_version_id",
+				Detail: fmt.Sprintf("Template version %q not found in the template.", req.TemplateVersionID),
+			}},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if version.State == database.TemplateVersionStateArchived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Archived template versions can't be rolled out.",
+		})
+		return
+	}
+	job, err := api.Database.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if db2sdk.ProvisionerJobStatus(job) != codersdk.ProvisionerJobSucceeded {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Only template versions that were imported successfully can be rolled out.",
+		})
+		return
+	}
+	if template.RequireActiveVersion && version.ID != template.ActiveVersionID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template requires workspaces to be started on the active version. Promote the template version before rolling it out.",
+		})
+		return
+	}
+
+	for _, groupID := range req.GroupIDs {
+		group, err := api.Database.GetGroupByID(ctx, groupID)
+		if httpapi.Is404Error(err) || (err == nil && group.OrganizationID != template.OrganizationID) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Group not found.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "group_ids",
+					Detail: fmt.Sprintf("Group %q not found in the template's organization.", groupID),
+				}},
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching group.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	groupIDs := req.GroupIDs
+	if groupIDs == nil {
+		groupIDs = []uuid.UUID{}
+	}
+
+	var (
+		rollout     database.TemplateVersionRollout
+		conflict    bool
+		noCandidate bool
+	)
+	err = api.Database.InTx(func(tx database.Store) error {
+		existing, err := tx.GetTemplateVersionRolloutsByTemplateID(ctx, template.ID)
+		if err != nil {
+			return xerrors.Errorf("get rollouts: %w", err)
+		}
+		for _, r := range existing {
+			if r.Status == database.TemplateVersionRolloutStatusRunning || r.Status == database.TemplateVersionRolloutStatusPaused {
+				conflict = true
+				return nil
+			}
+		}
+
+		candidates, err := tx.GetTemplateVersionRolloutCandidates(ctx, database.GetTemplateVersionRolloutCandidatesParams{
+			TemplateID:        template.ID,
+			TemplateVersionID: version.ID,
+			GroupIDs:          groupIDs,
+		})
+		if err != nil {
+			return xerrors.Errorf("get rollout candidates: %w", err)
+		}
+		// Round up so that small cohorts always include a workspace.
+		selected := (len(candidates)*req.Percentage + 99) / 100
+		if selected == 0 {
+			noCandidate = true
+			return nil
+		}
+
+		now := database.Now()
+		rollout, err = tx.InsertTemplateVersionRollout(ctx, database.InsertTemplateVersionRolloutParams{
+			ID:                      uuid.New(),
+			TemplateID:              template.ID,
+			TemplateVersionID:       version.ID,
+			CreatedBy:               apiKey.UserID,
+			CreatedAt:               now,
+			UpdatedAt:               now,
+			Percentage:              int32(req.Percentage),
+			GroupIDs:                groupIDs,
+			BatchSize:               int32(req.BatchSize),
+			FailureThresholdPercent: int32(req.FailureThresholdPercent),
+		})
+		if database.IsUniqueViolation(err) {
+			conflict = true
+			return nil
+		}
+		if err != nil {
+			return xerrors.Errorf("insert rollout: %w", err)
+		}
+		for _, workspaceID := range candidates[:selected] {
+			err = tx.InsertTemplateVersionRolloutWorkspace(ctx, database.InsertTemplateVersionRolloutWorkspaceParams{
+				RolloutID:   rollout.ID,
+				WorkspaceID: workspaceID,
+				UpdatedAt:   now,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert rollout workspace: %w", err)
+			}
+		}
+		return nil
+	}, nil)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating template version rollout.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if conflict {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The template already has a rollout in progress. Abort it before starting a new one.",
+		})
+		return
+	}
+	if noCandidate {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "No workspaces need to be moved to the template version.",
+		})
+		return
+	}
+	aReq.New = rollout
+
+	api.templateRolloutRunner.Trigger(rollout.ID)
+
+	resp, ok := api.fetchTemplateVersionRollout(rw, r, rollout)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+// @Summary Update template version rollout
+// @Description Pauses, resumes or aborts a rollout. Resuming a rollout
+// @Description retries the workspaces that failed to build.
+// @ID update-template-version-rollout
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param rollout path string true "Rollout ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateVersionRolloutRequest true "Update request"
+// @Success 200 {object} codersdk.TemplateVersionRollout
+// @Router /templates/{template}/rollouts/{rollout} [patch]
+func (api *API) patchTemplateVersionRollout(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		rollout           = httpmw.TemplateVersionRolloutParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersionRollout](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = rollout
+
+	var req codersdk.UpdateTemplateVersionRolloutRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	status := database.TemplateVersionRolloutStatus(req.Status)
+	switch status {
+	case database.TemplateVersionRolloutStatusRunning, database.TemplateVersionRolloutStatusPaused, database.TemplateVersionRolloutStatusAborted:
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid rollout status %q.", req.Status),
+			Validations: []codersdk.ValidationError{{
+				Field:  "status",
+				Detail: "Must be one of running, paused or aborted.",
+			}},
+		})
+		return
+	}
+	if rollout.Status == status {
+		aReq.New = rollout
+		resp, ok := api.fetchTemplateVersionRollout(rw, r, rollout)
+		if !ok {
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusOK, resp)
+		return
+	}
+	if rollout.Status == database.TemplateVersionRolloutStatusCompleted || rollout.Status == database.TemplateVersionRolloutStatusAborted {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("The rollout is %s and can't be changed.", rollout.Status),
+		})
+		return
+	}
+
+	err := api.Database.InTx(func(tx database.Store) error {
+		if status == database.TemplateVersionRolloutStatusRunning {
+			// Retry the workspaces that failed, otherwise the rollout would
+			// be paused again right away.
+			entries, err := tx.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rollout.ID)
+			if err != nil {
+				return xerrors.Errorf("get rollout workspaces: %w", err)
+			}
+			for _, entry := range entries {
+				if entry.Status != database.TemplateVersionRolloutWorkspaceStatusFailed {
+					continue
+				}
+				err = tx.UpdateTemplateVersionRolloutWorkspace(ctx, database.UpdateTemplateVersionRolloutWorkspaceParams{
+					RolloutID:   entry.RolloutID,
+					WorkspaceID: entry.WorkspaceID,
+					Status:      database.TemplateVersionRolloutWorkspaceStatusPending,
+					UpdatedAt:   database.Now(),
+				})
+				if err != nil {
+					return xerrors.Errorf("reset rollout workspace: %w", err)
+				}
+			}
+		}
+		var err error
+		rollout, err = tx.UpdateTemplateVersionRolloutStatusByID(ctx, database.UpdateTemplateVersionRolloutStatusByIDParams{
+			ID:        rollout.ID,
+			Status:    status,
+			UpdatedAt: database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("update rollout status: %w", err)
+		}
+		return nil
+	}, nil)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template version rollout.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = rollout
+
+	if status == database.TemplateVersionRolloutStatusRunning {
+		api.templateRolloutRunner.Trigger(rollout.ID)
+	}
+
+	resp, ok := api.fetchTemplateVersionRollout(rw, r, rollout)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// fetchTemplateVersionRollout returns the rollout along with its workspaces.
+// It writes an error response and returns false if they can't be fetched.
+func (api *API) fetchTemplateVersionRollout(rw http.ResponseWriter, r *http.Request, rollout database.TemplateVersionRollout) (codersdk.TemplateVersionRollout, bool) {
+	ctx := r.Context()
+
+	version, err := api.Database.GetTemplateVersionByID(ctx, rollout.TemplateVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return codersdk.TemplateVersionRollout{}, false
+	}
+	entries, err := api.Database.GetTemplateVersionRolloutWorkspacesByRolloutID(ctx, rollout.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return codersdk.TemplateVersionRollout{}, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version rollout workspaces.",
+			Detail:  err.Error(),
+		})
+		return codersdk.TemplateVersionRollout{}, false
+	}
+	return convertTemplateVersionRollout(rollout, version.Name, entries, true), true
+}
+
+func convertTemplateVersionRollout(rollout database.TemplateVersionRollout, versionName string, entries []database.GetTemplateVersionRolloutWorkspacesByRolloutIDRow, includeWorkspaces bool) codersdk.TemplateVersionRollout {
+	resp := codersdk.TemplateVersionRollout{
+		ID:                      rollout.ID,
+		TemplateID:              rollout.TemplateID,
+		TemplateVersionID:       rollout.TemplateVersionID,
+		TemplateVersionName:     versionName,
+		CreatedBy:               rollout.CreatedBy,
+		CreatedAt:               rollout.CreatedAt,
+		UpdatedAt:               rollout.UpdatedAt,
+		Status:                  codersdk.TemplateVersionRolloutStatus(rollout.Status),
+		PausedReason:            rollout.PausedReason,
+		Percentage:              int(rollout.Percentage),
+		GroupIDs:                rollout.GroupIDs,
+		BatchSize:               int(rollout.BatchSize),
+		FailureThresholdPercent: int(rollout.FailureThresholdPercent),
+	}
+	if resp.GroupIDs == nil {
+		resp.GroupIDs = []uuid.UUID{}
+	}
+	for _, entry := range entries {
+		resp.Counts.Total++
+		switch entry.Status {
+		case database.TemplateVersionRolloutWorkspaceStatusPending:
+			resp.Counts.Pending++
+		case database.TemplateVersionRolloutWorkspaceStatusBuilding:
+			resp.Counts.Building++
+		case database.TemplateVersionRolloutWorkspaceStatusSucceeded:
+			resp.Counts.Succeeded++
+		case database.TemplateVersionRolloutWorkspaceStatusFailed:
+			resp.Counts.Failed++
+		case database.TemplateVersionRolloutWorkspaceStatusSkipped:
+			resp.Counts.Skipped++
+		}
+		if !includeWorkspaces {
+			continue
+		}
+		workspace := codersdk.TemplateVersionRolloutWorkspace{
+			WorkspaceID:        entry.WorkspaceID,
+			WorkspaceName:      entry.WorkspaceName,
+			WorkspaceOwnerName: entry.WorkspaceOwnerName,
+			Status:             codersdk.TemplateVersionRolloutWorkspaceStatus(entry.Status),
+			Error:              entry.Error,
+		}
+		if entry.BuildID.Valid {
+			buildID := entry.BuildID.UUID
+			workspace.BuildID = &buildID
+		}
+		resp.Workspaces = append(resp.Workspaces, workspace)
+	}
+	return resp
+}
@@ -0,0 +1,279 @@
+package coderd_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateVersionRollout(t *testing.T) {
+	t.Parallel()
+
+	// setup creates a template with the given number of workspaces and a new
+	// template version that hasn't been promoted.
+	setup := func(t *testing.T, workspaces int) (*codersdk.Client, io.Closer, codersdk.Template, codersdk.TemplateVersion) {
+		client, closer := coderdtest.NewWithProvisionerCloser(t, &coderdtest.Options{
+			TemplateRolloutInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		for i := 0; i < workspaces; i++ {
+			workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+			coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		}
+		next := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, next.ID)
+		return client, closer, template, next
+	}
+
+	awaitStatus := func(ctx context.Context, t *testing.T, client *codersdk.Client, template codersdk.Template, rolloutID uuid.UUID, status codersdk.TemplateVersionRolloutStatus) codersdk.TemplateVersionRollout {
+		var rollout codersdk.TemplateVersionRollout
+		require.Eventually(t, func() bool {
+			var err error
+			rollout, err = client.TemplateVersionRollout(ctx, template.ID, rolloutID)
+			return assert.NoError(t, err) && rollout.Status == status
+		}, testutil.WaitLong, testutil.IntervalFast)
+		return rollout
+	}
+
+	t.Run("Completed", func(t *testing.T) {
+		t.Parallel()
+		client, _, template, version := setup(t, 2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		rollout, err := client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID:       version.ID,
+			Percentage:              100,
+			BatchSize:               1,
+			FailureThresholdPercent: 0,
+		})
+		require.NoError(t, err)
+		require.Equal(t, version.Name, rollout.TemplateVersionName)
+		require.Equal(t, 2, rollout.Counts.Total)
+		require.Len(t, rollout.Workspaces, 2)
+
+		rollout = awaitStatus(ctx, t, client, template, rollout.ID, codersdk.TemplateVersionRolloutStatusCompleted)
+		require.Equal(t, 2, rollout.Counts.Succeeded)
+		for _, entry := range rollout.Workspaces {
+			require.NotNil(t, entry.BuildID)
+			workspace, err := client.Workspace(ctx, entry.WorkspaceID)
+			require.NoError(t, err)
+			require.Equal(t, version.ID, workspace.LatestBuild.TemplateVersionID)
+			require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+		}
+
+		rollouts, err := client.TemplateVersionRollouts(ctx, template.ID)
+		require.NoError(t, err)
+		require.Len(t, rollouts, 1)
+		require.Equal(t, 2, rollouts[0].Counts.Succeeded)
+		require.Empty(t, rollouts[0].Workspaces)
+	})
+
+	t.Run("Percentage", func(t *testing.T) {
+		t.Parallel()
+		client, _, template, version := setup(t, 3)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		rollout, err := client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: version.ID,
+			Percentage:        50,
+			BatchSize:         10,
+		})
+		require.NoError(t, err)
+		// Two of three workspaces, rounded up.
+		require.Equal(t, 2, rollout.Counts.Total)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		client, closer, template, version := setup(t, 2)
+		// Keep the builds of the rollout pending.
+		require.NoError(t, closer.Close())
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		rollout, err := client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: version.ID,
+			Percentage:        100,
+			BatchSize:         1,
+		})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			rollout, err = client.TemplateVersionRollout(ctx, template.ID, rollout.ID)
+			return assert.NoError(t, err) && rollout.Counts.Building == 1
+		}, testutil.WaitLong, testutil.IntervalFast)
+		// The batch size limits the builds in progress.
+		require.Equal(t, 1, rollout.Counts.Pending)
+
+		// Only one rollout may be in progress at a time.
+		_, err = client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: version.ID,
+			Percentage:        100,
+			BatchSize:         1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		rollout, err = client.UpdateTemplateVersionRollout(ctx, template.ID, rollout.ID, codersdk.UpdateTemplateVersionRolloutRequest{
+			Status: codersdk.TemplateVersionRolloutStatusAborted,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionRolloutStatusAborted, rollout.Status)
+
+		// Aborted rollouts can't be resumed.
+		_, err = client.UpdateTemplateVersionRollout(ctx, template.ID, rollout.ID, codersdk.UpdateTemplateVersionRolloutRequest{
+			Status: codersdk.TemplateVersionRolloutStatusRunning,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("PauseResume", func(t *testing.T) {
+		t.Parallel()
+		client, closer, template, version := setup(t, 1)
+		require.NoError(t, closer.Close())
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		rollout, err := client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: version.ID,
+			Percentage:        100,
+			BatchSize:         1,
+		})
+		require.NoError(t, err)
+
+		rollout, err = client.UpdateTemplateVersionRollout(ctx, template.ID, rollout.ID, codersdk.UpdateTemplateVersionRolloutRequest{
+			Status: codersdk.TemplateVersionRolloutStatusPaused,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionRolloutStatusPaused, rollout.Status)
+		require.Empty(t, rollout.PausedReason)
+
+		rollout, err = client.UpdateTemplateVersionRollout(ctx, template.ID, rollout.ID, codersdk.UpdateTemplateVersionRolloutRequest{
+			Status: codersdk.TemplateVersionRolloutStatusRunning,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionRolloutStatusRunning, rollout.Status)
+		require.Eventually(t, func() bool {
+			rollout, err = client.TemplateVersionRollout(ctx, template.ID, rollout.ID)
+			return assert.NoError(t, err) && rollout.Counts.Building == 1
+		}, testutil.WaitLong, testutil.IntervalFast)
+	})
+
+	t.Run("Audit", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		next := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, next.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		rollout, err := client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: next.ID,
+			Percentage:        100,
+			BatchSize:         1,
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateTemplateVersionRollout(ctx, template.ID, rollout.ID, codersdk.UpdateTemplateVersionRolloutRequest{
+			Status: codersdk.TemplateVersionRolloutStatusAborted,
+		})
+		require.NoError(t, err)
+
+		var actions []database.AuditAction
+		for _, alog := range auditor.AuditLogs() {
+			if alog.ResourceType != database.ResourceTypeTemplateVersionRollout {
+				continue
+			}
+			require.Equal(t, rollout.ID, alog.ResourceID)
+			actions = append(actions, alog.Action)
+		}
+		require.Equal(t, []database.AuditAction{database.AuditActionCreate, database.AuditActionWrite}, actions)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		otherVersion := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, otherVersion.ID)
+		coderdtest.CreateTemplate(t, client, user.OrganizationID, otherVersion.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		var apiErr *codersdk.Error
+		_, err := client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: otherVersion.ID,
+			Percentage:        100,
+			BatchSize:         1,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "template_version_id", apiErr.Validations[0].Field)
+
+		// Every workspace is already on the version.
+		_, err = client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: version.ID,
+			Percentage:        100,
+			BatchSize:         1,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Contains(t, apiErr.Message, "No workspaces")
+
+		_, err = client.CreateTemplateVersionRollout(ctx, template.ID, codersdk.CreateTemplateVersionRolloutRequest{
+			TemplateVersionID: version.ID,
+			Percentage:        150,
+			BatchSize:         1,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberNotFound", func(t *testing.T) {
+		t.Parallel()
+		client, _, template, _ := setup(t, 0)
+		member, _ := coderdtest.CreateAnotherUser(t, client, template.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.TemplateVersionRollouts(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
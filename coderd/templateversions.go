@@ -20,12 +20,14 @@ import (
 
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/gitauth"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/parameter"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/templateversiondiff"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/examples"
@@ -176,6 +178,156 @@ func (api *API) patchTemplateVersion(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersion(updatedTemplateVersion, convertProvisionerJob(job), user, nil))
 }
 
+// @Summary Update template version state
+// @ID update-template-version-state
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateVersionStateRequest true "Update template version state request"
+// @Success 200 {object} codersdk.TemplateVersion
+// @Router /templateversions/{templateversion}/state [patch]
+func (api *API) patchTemplateVersionState(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		templateVersion   = httpmw.TemplateVersionParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersion](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = templateVersion
+
+	var req codersdk.UpdateTemplateVersionStateRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	state := database.TemplateVersionState(req.State)
+	switch state {
+	case database.TemplateVersionStateActive:
+		// Only the active version of a template is in the active state, which
+		// promoting the version takes care of.
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Versions can't be made active by changing their state.",
+			Detail:  "Promote the version with PATCH /api/v2/templates/{template}/versions instead.",
+			Validations: []codersdk.ValidationError{
+				{Field: "state", Detail: "Must be one of deprecated or archived."},
+			},
+		})
+		return
+	case database.TemplateVersionStateDeprecated, database.TemplateVersionStateArchived:
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid template version state.",
+			Validations: []codersdk.ValidationError{
+				{Field: "state", Detail: "Must be one of active, deprecated or archived."},
+			},
+		})
+		return
+	}
+	if !templateVersion.TemplateID.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Only versions of a template have a state.",
+		})
+		return
+	}
+	if templateVersion.State == database.TemplateVersionStateArchived && state != templateVersion.State {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Archived template versions can't be restored.",
+		})
+		return
+	}
+	template, err := api.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	switch {
+	case state == templateVersion.State:
+		// Nothing to do.
+	case state == database.TemplateVersionStateArchived:
+		archived, err := api.Database.ArchiveUnusedTemplateVersions(ctx, database.ArchiveUnusedTemplateVersionsParams{
+			UpdatedAt:          database.Now(),
+			TemplateID:         template.ID,
+			TemplateVersionIds: []uuid.UUID{templateVersion.ID},
+		})
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error archiving template version.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if len(archived) == 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "The template version is active or used by a workspace and can't be archived.",
+			})
+			return
+		}
+	default:
+		if state == database.TemplateVersionStateDeprecated && template.ActiveVersionID == templateVersion.ID {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "The active version of a template can't be deprecated. Promote another version first.",
+			})
+			return
+		}
+		err = api.Database.UpdateTemplateVersionStateByID(ctx, database.UpdateTemplateVersionStateByIDParams{
+			ID:        templateVersion.ID,
+			State:     state,
+			UpdatedAt: database.Now(),
+		})
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error updating template version state.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	updated, err := api.Database.GetTemplateVersionByID(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = updated
+
+	job, err := api.Database.GetProvisionerJobByID(ctx, updated.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	user, err := api.Database.GetUserByID(ctx, updated.CreatedBy)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error on fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersion(updated, convertProvisionerJob(job), user, nil))
+}
+
 // @Summary Cancel template version by ID
 // @ID cancel-template-version-by-id
 // @Security CoderSessionToken
@@ -411,6 +563,146 @@ func (api *API) templateVersionVariables(rw http.ResponseWriter, r *http.Request
 	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionVariables(dbTemplateVersionVariables))
 }
 
+// @Summary Compare template versions
+// @ID compare-template-versions
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Param base query string true "Base template version ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateVersionDiff
+// @Router /templateversions/{templateversion}/diff [get]
+func (api *API) templateVersionDiff(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	templateVersion := httpmw.TemplateVersionParam(r)
+
+	vals := r.URL.Query()
+	p := httpapi.NewQueryParamParser()
+	baseID := p.Required("base").UUID(vals, uuid.Nil, "base")
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	baseVersion, err := api.Database.GetTemplateVersionByID(ctx, baseID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: fmt.Sprintf("Base template version %q not found.", baseID),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching base template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if baseVersion.TemplateID != templateVersion.TemplateID || baseVersion.OrganizationID != templateVersion.OrganizationID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The base template version doesn't belong to the same template.",
+		})
+		return
+	}
+
+	var versions [2]templateversiondiff.Version
+	for i, version := range []database.TemplateVersion{baseVersion, templateVersion} {
+		job, err := api.Database.GetProvisionerJobByID(ctx, version.JobID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching provisioner job.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if !job.CompletedAt.Valid {
+			httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+				Message: fmt.Sprintf("Job for template version %q hasn't completed!", version.Name),
+			})
+			return
+		}
+		versions[i], err = api.templateVersionDiffInput(ctx, version, job)
+		if httpapi.Is404Error(err) {
+			// Reading the source archive requires permission to update
+			// the template.
+			httpapi.Forbidden(rw)
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template version.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	diff, err := templateversiondiff.Compare(versions[0], versions[1])
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error comparing template versions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	diff.BaseVersionID = baseVersion.ID
+	diff.VersionID = templateVersion.ID
+	httpapi.Write(ctx, rw, http.StatusOK, diff)
+}
+
+// templateVersionDiffInput loads everything about a template version that
+// is compared by templateVersionDiff.
+func (api *API) templateVersionDiffInput(ctx context.Context, version database.TemplateVersion, job database.ProvisionerJob) (templateversiondiff.Version, error) {
+	file, err := api.Database.GetFileByID(ctx, job.FileID)
+	if err != nil {
+		return templateversiondiff.Version{}, xerrors.Errorf("get file: %w", err)
+	}
+	parameters, err := api.Database.GetTemplateVersionParameters(ctx, version.ID)
+	if err != nil {
+		return templateversiondiff.Version{}, xerrors.Errorf("get parameters: %w", err)
+	}
+	variables, err := api.Database.GetTemplateVersionVariables(ctx, version.ID)
+	if err != nil {
+		return templateversiondiff.Version{}, xerrors.Errorf("get variables: %w", err)
+	}
+
+	// nolint:gocritic // Import job resources are read as the system, like in provisionerJobResources.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(sysCtx, job.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return templateversiondiff.Version{}, xerrors.Errorf("get resources: %w", err)
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(sysCtx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return templateversiondiff.Version{}, xerrors.Errorf("get agents: %w", err)
+	}
+	agentIDs := make([]uuid.UUID, 0, len(agents))
+	for _, agent := range agents {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	apps, err := api.Database.GetWorkspaceAppsByAgentIDs(sysCtx, agentIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return templateversiondiff.Version{}, xerrors.Errorf("get apps: %w", err)
+	}
+
+	return templateversiondiff.Version{
+		Archive:    file.Data,
+		Parameters: parameters,
+		Variables:  variables,
+		Resources:  resources,
+		Agents:     agents,
+		Apps:       apps,
+	}, nil
+}
+
 // @Summary Create template version dry-run
 // @ID create-template-version-dry-run
 // @Security CoderSessionToken
@@ -1076,6 +1368,12 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 		})
 		return
 	}
+	if version.State == database.TemplateVersionStateArchived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Archived template versions can't be promoted.",
+		})
+		return
+	}
 
 	err = api.Database.InTx(func(store database.Store) error {
 		err = store.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
@@ -1086,6 +1384,26 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 		if err != nil {
 			return xerrors.Errorf("update active version: %w", err)
 		}
+		// Only the active version of a template is in the active state, so
+		// the previous one is deprecated to warn users to update.
+		if template.ActiveVersionID != req.ID {
+			err = store.UpdateTemplateVersionStateByID(ctx, database.UpdateTemplateVersionStateByIDParams{
+				ID:        template.ActiveVersionID,
+				State:     database.TemplateVersionStateDeprecated,
+				UpdatedAt: database.Now(),
+			})
+			if err != nil {
+				return xerrors.Errorf("deprecate previous active version: %w", err)
+			}
+		}
+		err = store.UpdateTemplateVersionStateByID(ctx, database.UpdateTemplateVersionStateByIDParams{
+			ID:        req.ID,
+			State:     database.TemplateVersionStateActive,
+			UpdatedAt: database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("update template version state: %w", err)
+		}
 		return nil
 	}, nil)
 	if err != nil {
@@ -1106,6 +1424,67 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	})
 }
 
+// @Summary Archive unused template versions
+// @ID archive-unused-template-versions
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.ArchiveTemplateVersionsRequest true "Archive template versions request"
+// @Success 200 {object} codersdk.ArchiveTemplateVersionsResponse
+// @Router /templates/{template}/versions/archive [post]
+func (api *API) postArchiveTemplateVersions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Template](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = template
+
+	var req codersdk.ArchiveTemplateVersionsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	archived, err := api.Database.ArchiveUnusedTemplateVersions(ctx, database.ArchiveUnusedTemplateVersionsParams{
+		UpdatedAt:          database.Now(),
+		TemplateID:         template.ID,
+		TemplateVersionIds: req.TemplateVersionIDs,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error archiving template versions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if archived == nil {
+		archived = []uuid.UUID{}
+	}
+	aReq.New = template
+	aReq.AdditionalFields, err = json.Marshal(map[string][]uuid.UUID{"archived_template_version_ids": archived})
+	if err != nil {
+		api.Logger.Warn(ctx, "marshal archived template version ids", slog.Error(err))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.ArchiveTemplateVersionsResponse{
+		TemplateID:  template.ID,
+		ArchivedIDs: archived,
+	})
+}
+
 // postTemplateVersionsByOrganization creates a new version of a template. An import job is queued to parse the storage method provided.
 //
 // @Summary Create template version by organization
@@ -1424,6 +1803,7 @@ func convertTemplateVersion(version database.TemplateVersion, job codersdk.Provi
 		Job:            job,
 		Readme:         version.Readme,
 		CreatedBy:      createdBy,
+		State:          codersdk.TemplateVersionState(version.State),
 		Warnings:       warnings,
 	}
 }
@@ -3,6 +3,7 @@ package coderd_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"net/http"
 	"regexp"
 	"testing"
@@ -1291,3 +1292,325 @@ func TestTemplateVersionPatch(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestPatchTemplateVersionState(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version1 := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version1.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version1.ID)
+		version2 := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionApply: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{Name: "version2", Type: "example"}},
+					},
+				},
+			}},
+		}, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version2.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		version1, err := client.TemplateVersion(ctx, version1.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionStateActive, version1.State)
+		require.Equal(t, codersdk.TemplateVersionStateDraft, version2.State)
+
+		// The active version can't be deprecated or archived.
+		var apiErr *codersdk.Error
+		_, err = client.UpdateTemplateVersionState(ctx, version1.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateDeprecated,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		_, err = client.UpdateTemplateVersionState(ctx, version1.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateArchived,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// Versions can't be returned to draft.
+		_, err = client.UpdateTemplateVersionState(ctx, version2.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateDraft,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// Versions are only made active by promoting them.
+		_, err = client.UpdateTemplateVersionState(ctx, version2.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateActive,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		version2, err = client.UpdateTemplateVersionState(ctx, version2.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateDeprecated,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionStateDeprecated, version2.State)
+
+		version2, err = client.UpdateTemplateVersionState(ctx, version2.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateArchived,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionStateArchived, version2.State)
+
+		// The source of the archived version is deleted.
+		_, _, err = client.Download(ctx, version2.Job.FileID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		_, _, err = client.Download(ctx, version1.Job.FileID)
+		require.NoError(t, err)
+
+		// Archived versions can't be restored or promoted.
+		_, err = client.UpdateTemplateVersionState(ctx, version2.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateActive,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		err = client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: version2.ID,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("UsedByWorkspace", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version1 := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version1.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version1.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		version2 := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version2.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: version2.ID,
+		})
+		require.NoError(t, err)
+		version2, err = client.TemplateVersion(ctx, version2.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionStateActive, version2.State)
+
+		// Promoting a version deprecates the previous active version.
+		version1, err = client.TemplateVersion(ctx, version1.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionStateDeprecated, version1.State)
+
+		_, err = client.UpdateTemplateVersionState(ctx, version1.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateArchived,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// Deprecated versions can still be built.
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		require.Equal(t, version1.ID, build.TemplateVersionID)
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		version = coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.UpdateTemplateVersionState(ctx, version.ID, codersdk.UpdateTemplateVersionStateRequest{
+			State: codersdk.TemplateVersionStateDeprecated,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
+
+func TestArchiveTemplateVersions(t *testing.T) {
+	t.Parallel()
+	auditor := audit.NewMock()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor})
+	user := coderdtest.CreateFirstUser(t, client)
+	version1 := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version1.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version1.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	version2 := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version2.ID)
+	version3 := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version3.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+		ID: version3.ID,
+	})
+	require.NoError(t, err)
+
+	// The first version is used by the workspace and the third is active.
+	resp, err := client.ArchiveTemplateVersions(ctx, template.ID, codersdk.ArchiveTemplateVersionsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{version2.ID}, resp.ArchivedIDs)
+	alog := auditor.AuditLogs()[len(auditor.AuditLogs())-1]
+	require.Equal(t, database.ResourceTypeTemplate, alog.ResourceType)
+	require.Equal(t, template.ID, alog.ResourceID)
+	require.JSONEq(t, fmt.Sprintf(`{"archived_template_version_ids":[%q]}`, version2.ID), string(alog.AdditionalFields))
+
+	version2, err = client.TemplateVersion(ctx, version2.ID)
+	require.NoError(t, err)
+	require.Equal(t, codersdk.TemplateVersionStateArchived, version2.State)
+
+	// Archiving again is a no-op.
+	resp, err = client.ArchiveTemplateVersions(ctx, template.ID, codersdk.ArchiveTemplateVersionsRequest{
+		TemplateVersionIDs: []uuid.UUID{version1.ID, version2.ID},
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.ArchivedIDs)
+
+	// Once the workspace is updated the first version is archived, but its
+	// source is kept since the previous workspace builds reference it.
+	build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		TemplateVersionID: version3.ID,
+		Transition:        codersdk.WorkspaceTransitionStart,
+	})
+	require.NoError(t, err)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+	resp, err = client.ArchiveTemplateVersions(ctx, template.ID, codersdk.ArchiveTemplateVersionsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{version1.ID}, resp.ArchivedIDs)
+	_, _, err = client.Download(ctx, version1.Job.FileID)
+	require.NoError(t, err)
+}
+
+func TestTemplateVersionDiff(t *testing.T) {
+	t.Parallel()
+
+	echoResponses := func(operatingSystem string, parameters ...string) *echo.Responses {
+		richParameters := make([]*proto.RichParameter, 0, len(parameters))
+		for _, name := range parameters {
+			richParameters = append(richParameters, &proto.RichParameter{Name: name, Type: "string"})
+		}
+		return &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionApply: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Parameters: richParameters,
+						Resources: []*proto.Resource{{
+							Name: "main",
+							Type: "example",
+							Agents: []*proto.Agent{{
+								Name:            "dev",
+								OperatingSystem: operatingSystem,
+								Auth:            &proto.Agent_Token{},
+							}},
+						}},
+					},
+				},
+			}},
+		}
+	}
+
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version1 := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, echoResponses("linux", "region"))
+	coderdtest.AwaitTemplateVersionJob(t, client, version1.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version1.ID)
+	version2 := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, echoResponses("windows", "region", "size"), template.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version2.ID)
+
+	t.Run("Changes", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		diff, err := client.TemplateVersionDiff(ctx, version1.ID, version2.ID)
+		require.NoError(t, err)
+		require.Equal(t, version1.ID, diff.BaseVersionID)
+		require.Equal(t, version2.ID, diff.VersionID)
+		require.NotEmpty(t, diff.Files)
+		require.Empty(t, diff.Variables)
+		require.Equal(t, []codersdk.TemplateVersionParameterDiff{{
+			Name:   "size",
+			Change: codersdk.TemplateVersionDiffChangeAdded,
+		}}, diff.Parameters)
+		require.Equal(t, []codersdk.TemplateVersionResourceDiff{{
+			Kind:   codersdk.TemplateVersionDiffKindAgent,
+			Path:   "example.main/dev",
+			Change: codersdk.TemplateVersionDiffChangeModified,
+			Fields: []codersdk.TemplateVersionFieldDiff{{Field: "operating_system", Old: "linux", New: "windows"}},
+		}}, diff.Resources)
+	})
+
+	t.Run("Same", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		diff, err := client.TemplateVersionDiff(ctx, version1.ID, version1.ID)
+		require.NoError(t, err)
+		require.Empty(t, diff.Files)
+		require.Empty(t, diff.Parameters)
+		require.Empty(t, diff.Resources)
+	})
+
+	t.Run("BaseNotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.TemplateVersionDiff(ctx, uuid.New(), version2.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("DifferentTemplate", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		otherVersion := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, otherVersion.ID)
+		_ = coderdtest.CreateTemplate(t, client, user.OrganizationID, otherVersion.ID)
+
+		_, err := client.TemplateVersionDiff(ctx, otherVersion.ID, version2.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		_, err := member.TemplateVersionDiff(ctx, version1.ID, version2.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
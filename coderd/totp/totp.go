@@ -0,0 +1,155 @@
+// Package totp implements time-based one-time passwords (RFC 6238) as
+// generated by authenticator apps, and the recovery codes that can be used in
+// their place.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //#nosec // RFC 6238 authenticator apps default to SHA-1.
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cryptorand"
+)
+
+const (
+	// Digits is the length of a code.
+	Digits = 6
+	// Period is how long a code is valid for.
+	Period = 30 * time.Second
+	// Skew is the number of periods before and after the current one whose
+	// codes are accepted to account for clock drift.
+	Skew = 1
+
+	// RecoveryCodeCount is the number of recovery codes generated at once.
+	RecoveryCodeCount = 10
+
+	secretSize         = 20
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", xerrors.Errorf("read random bytes: %w", err)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URL returns the otpauth:// URL authenticator apps import the secret from,
+// usually by scanning it as a QR code.
+func URL(secret, issuer, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+// Code returns the code of the secret at the given time.
+func Code(secret string, now time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, counter(now)), nil
+}
+
+// Validate checks the code against the secret at the given time. Codes of
+// periods up to and including lastCounter are rejected so a code can only be
+// used once. The counter of the matching period is returned so it can be
+// stored as the next lastCounter.
+func Validate(secret, input string, now time.Time, lastCounter int64) (int64, bool, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+	input = strings.TrimSpace(input)
+	if len(input) != Digits {
+		return 0, false, nil
+	}
+	current := counter(now)
+	for c := current - Skew; c <= current+Skew; c++ {
+		if c <= lastCounter {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(code(key, c)), []byte(input)) == 1 {
+			return c, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// GenerateRecoveryCodes returns new single-use recovery codes along with the
+// hashes to store for them.
+func GenerateRecoveryCodes() (codes []string, hashed []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		raw, err := cryptorand.StringCharset(recoveryCodeChars, recoveryCodeLength)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("generate recovery code: %w", err)
+		}
+		code := raw[:recoveryCodeLength/2] + "-" + raw[recoveryCodeLength/2:]
+		codes = append(codes, code)
+		hashed = append(hashed, HashRecoveryCode(code))
+	}
+	return codes, hashed, nil
+}
+
+// HashRecoveryCode returns the hash stored for a recovery code. Recovery codes
+// are random enough that a fast hash is sufficient.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return nil, xerrors.Errorf("decode secret: %w", err)
+	}
+	return key, nil
+}
+
+func counter(now time.Time) int64 {
+	return now.Unix() / int64(Period.Seconds())
+}
+
+// code implements HOTP (RFC 4226) for the given counter.
+func code(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
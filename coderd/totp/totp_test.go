@@ -0,0 +1,115 @@
+package totp_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/totp"
+)
+
+// rfcSecret is the SHA-1 secret of the RFC 6238 test vectors.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	// The RFC uses 8 digit codes, these are their last 6 digits.
+	for unix, expected := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := totp.Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+
+	t.Run("Current", func(t *testing.T) {
+		t.Parallel()
+		code, err := totp.Code(secret, now)
+		require.NoError(t, err)
+		counter, ok, err := totp.Validate(secret, code, now, 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, now.Unix()/30, counter)
+	})
+
+	t.Run("Skew", func(t *testing.T) {
+		t.Parallel()
+		code, err := totp.Code(secret, now.Add(-totp.Period))
+		require.NoError(t, err)
+		_, ok, err := totp.Validate(secret, code, now, 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		code, err = totp.Code(secret, now.Add(-3*totp.Period))
+		require.NoError(t, err)
+		_, ok, err = totp.Validate(secret, code, now, 0)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		t.Parallel()
+		code, err := totp.Code(secret, now)
+		require.NoError(t, err)
+		counter, ok, err := totp.Validate(secret, code, now, 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+		_, ok, err = totp.Validate(secret, code, now, counter)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		t.Parallel()
+		_, ok, err := totp.Validate(secret, "12345", now, 0)
+		require.NoError(t, err)
+		require.False(t, ok)
+		_, _, err = totp.Validate("not base32!", "123456", now, 0)
+		require.Error(t, err)
+	})
+}
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+
+	raw := totp.URL(rfcSecret, "Coder", "kyle@coder.com")
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Coder:kyle@coder.com", u.Path)
+	require.Equal(t, rfcSecret, u.Query().Get("secret"))
+	require.Equal(t, "Coder", u.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	codes, hashed, err := totp.GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, totp.RecoveryCodeCount)
+	require.Len(t, hashed, totp.RecoveryCodeCount)
+	for i, code := range codes {
+		require.Len(t, code, 11)
+		require.Equal(t, hashed[i], totp.HashRecoveryCode(code))
+		// Codes are accepted regardless of case and dashes.
+		require.Equal(t, hashed[i], totp.HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))))
+	}
+}
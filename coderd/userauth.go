@@ -5,16 +5,21 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"net/mail"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/go-github/v43/github"
 	"github.com/google/uuid"
 	"github.com/moby/moby/pkg/namesgenerator"
+	"golang.org/x/exp/slices"
 	"golang.org/x/oauth2"
 	"golang.org/x/xerrors"
 
@@ -25,7 +30,9 @@ import (
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/loginlimit"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/totp"
 	"github.com/coder/coder/coderd/userpassword"
 	"github.com/coder/coder/codersdk"
 )
@@ -59,34 +66,119 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, roles, ok := api.loginRequest(rw, r, loginWithPassword)
+	// 'user.ID' will be empty, or will be an actual value. Either is correct
+	// here.
+	aReq.UserID = user.ID
+	if !ok {
+		// user failed to login
+		return
+	}
+
+	recoveryCodes, ok := api.verifyLoginMFA(rw, r, user, roles, loginWithPassword)
+	if !ok {
+		return
+	}
+
+	// The failures of the address decay on their own, a successful login to
+	// one account must not clear the failures against other accounts.
+	//nolint:gocritic // The user is not logged in yet.
+	err := api.Database.DeleteUserLoginFailures(dbauthz.AsSystemRestricted(ctx), user.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	userSubj := rbac.Subject{
+		ID:     user.ID.String(),
+		Roles:  rbac.RoleNames(roles.Roles),
+		Groups: roles.Groups,
+		Scope:  rbac.ScopeAll,
+	}
+
+	//nolint:gocritic // Creating the API key as the user instead of as system.
+	cookie, key, err := api.createAPIKey(dbauthz.As(ctx, userSubj), apikey.CreateParams{
+		UserID:           user.ID,
+		LoginType:        database.LoginTypePassword,
+		RemoteAddr:       r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
+		DeploymentValues: api.DeploymentValues,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to create API key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	aReq.New = *key
+
+	http.SetCookie(rw, cookie)
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.LoginWithPasswordResponse{
+		SessionToken:  cookie.Value,
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// loginRequest checks the email and password of a password login. If the
+// credentials are rejected, the error response is written and false is
+// returned. Repeated failures from the same account or address are delayed
+// and eventually locked out.
+func (api *API) loginRequest(rw http.ResponseWriter, r *http.Request, req codersdk.LoginWithPasswordRequest) (database.User, database.GetAuthorizationUserRolesRow, bool) {
+	ctx := r.Context()
+	now := database.Now()
+	ip := loginIP(r)
+	if wait := api.loginIPTracker.Wait(ip, now); wait > 0 {
+		writeLoginThrottled(ctx, rw, wait, fmt.Sprintf("Too many failed login attempts. Try again in %s.", formatLoginWait(wait)))
+		return database.User{}, database.GetAuthorizationUserRolesRow{}, false
+	}
+
 	//nolint:gocritic // In order to login, we need to get the user first!
 	user, err := api.Database.GetUserByEmailOrUsername(dbauthz.AsSystemRestricted(ctx), database.GetUserByEmailOrUsernameParams{
-		Email: loginWithPassword.Email,
+		Email: req.Email,
 	})
 	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error.",
 		})
-		return
+		return user, database.GetAuthorizationUserRolesRow{}, false
 	}
 
-	aReq.UserID = user.ID
+	if user.ID != uuid.Nil {
+		//nolint:gocritic // The user is not logged in yet.
+		failures, err := api.Database.GetUserLoginFailuresByUserID(dbauthz.AsSystemRestricted(ctx), user.ID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			httpapi.InternalServerError(rw, err)
+			return user, database.GetAuthorizationUserRolesRow{}, false
+		}
+		if wait := loginlimit.Wait(failures.FailedAttempts, failures.LastFailedAt, failures.LockedUntil.Time, now); wait > 0 {
+			message := fmt.Sprintf("Too many failed login attempts. Try again in %s.", formatLoginWait(wait))
+			if failures.LockedUntil.Time.After(now) {
+				message = fmt.Sprintf("Your account is locked because of too many failed login attempts. Try again in %s or ask an admin to unlock it.", formatLoginWait(wait))
+			}
+			writeLoginThrottled(ctx, rw, wait, message)
+			return user, database.GetAuthorizationUserRolesRow{}, false
+		}
+	}
 
 	// If the user doesn't exist, it will be a default struct.
-	equal, err := userpassword.Compare(string(user.HashedPassword), loginWithPassword.Password)
+	equal, err := userpassword.Compare(string(user.HashedPassword), req.Password)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error.",
 		})
-		return
+		return user, database.GetAuthorizationUserRolesRow{}, false
 	}
 	if !equal {
+		api.recordLoginFailure(r, user)
 		// This message is the same as above to remove ease in detecting whether
 		// users are registered or not. Attackers still could with a timing attack.
 		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
 			Message: "Incorrect email or password.",
 		})
-		return
+		return user, database.GetAuthorizationUserRolesRow{}, false
 	}
 
 	// If password authentication is disabled and the user does not have the
@@ -95,14 +187,14 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
 			Message: "Password authentication is disabled.",
 		})
-		return
+		return user, database.GetAuthorizationUserRolesRow{}, false
 	}
 
 	if user.LoginType != database.LoginTypePassword {
 		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
 			Message: fmt.Sprintf("Incorrect login type, attempting to use %q but user is of login type %q", database.LoginTypePassword, user.LoginType),
 		})
-		return
+		return user, database.GetAuthorizationUserRolesRow{}, false
 	}
 
 	//nolint:gocritic // System needs to fetch user roles in order to login user.
@@ -111,7 +203,7 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error.",
 		})
-		return
+		return user, database.GetAuthorizationUserRolesRow{}, false
 	}
 
 	// If the user logged into a suspended account, reject the login request.
@@ -119,40 +211,165 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
 			Message: "Your account is suspended. Contact an admin to reactivate your account.",
 		})
+		return user, database.GetAuthorizationUserRolesRow{}, false
+	}
+
+	return user, roles, true
+}
+
+// verifyLoginMFA checks the second factor of a password login. Users without
+// an enabled TOTP enrollment pass unless the deployment requires them to
+// enroll. A pending enrollment is enabled by logging in with a valid code, in
+// which case its new recovery codes are returned.
+//
+// If no code was provided, a response telling the client which factor is
+// missing is written and false is returned.
+func (api *API) verifyLoginMFA(rw http.ResponseWriter, r *http.Request, user database.User, roles database.GetAuthorizationUserRolesRow, req codersdk.LoginWithPasswordRequest) ([]string, bool) {
+	ctx := r.Context()
+	//nolint:gocritic // System needs to read the enrollment before the user is logged in.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	enrollment, err := api.Database.GetUserTOTPByUserID(sysCtx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return nil, false
+	}
+	if !enrollment.Enabled && !api.mfaRequired(roles.Roles) {
+		return nil, true
+	}
+
+	if req.TOTPCode == "" && req.RecoveryCode == "" {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.LoginWithPasswordResponse{
+			MFARequired:           enrollment.Enabled,
+			MFAEnrollmentRequired: !enrollment.Enabled,
+		})
+		return nil, false
+	}
+	if enrollment.Secret == "" {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "You must enroll in multi-factor authentication before logging in.",
+		})
+		return nil, false
+	}
+
+	update, valid, err := checkTOTPCode(enrollment, req.TOTPCode, req.RecoveryCode, database.Now())
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return nil, false
+	}
+	if !valid {
+		api.recordLoginFailure(r, user)
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "Invalid authentication code.",
+		})
+		return nil, false
+	}
+
+	var recoveryCodes []string
+	if !enrollment.Enabled {
+		recoveryCodes, update.HashedRecoveryCodes, err = totp.GenerateRecoveryCodes()
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return nil, false
+		}
+		update.Enabled = true
+	}
+	_, err = api.Database.UpdateUserTOTP(sysCtx, update)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return nil, false
+	}
+	return recoveryCodes, true
+}
+
+// recordLoginFailure counts a failed login against the source address and,
+// if the account exists, against the user. Accounts that reach the lockout
+// threshold are locked and the lockout is audited.
+func (api *API) recordLoginFailure(r *http.Request, user database.User) {
+	var (
+		ctx    = r.Context()
+		now    = database.Now()
+		ip     = loginIP(r)
+		logger = api.Logger.With(slog.F("ip", ip))
+	)
+	if api.loginIPTracker.Fail(ip, now) {
+		logger.Warn(ctx, "locked out address after too many failed login attempts")
+	}
+	if user.ID == uuid.Nil {
 		return
 	}
 
-	userSubj := rbac.Subject{
-		ID:     user.ID.String(),
-		Roles:  rbac.RoleNames(roles.Roles),
-		Groups: roles.Groups,
-		Scope:  rbac.ScopeAll,
+	//nolint:gocritic // The user is not logged in yet.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	failures, err := api.Database.InsertUserLoginFailure(sysCtx, database.InsertUserLoginFailureParams{
+		UserID:       user.ID,
+		LastFailedAt: now,
+	})
+	if err != nil {
+		logger.Error(ctx, "record failed login", slog.F("user_id", user.ID), slog.Error(err))
+		return
+	}
+	threshold := api.DeploymentValues.LoginLockout.Threshold.Value()
+	if threshold <= 0 || failures.FailedAttempts < threshold {
+		return
 	}
 
-	//nolint:gocritic // Creating the API key as the user instead of as system.
-	cookie, key, err := api.createAPIKey(dbauthz.As(ctx, userSubj), apikey.CreateParams{
-		UserID:           user.ID,
-		LoginType:        database.LoginTypePassword,
-		RemoteAddr:       r.RemoteAddr,
-		DeploymentValues: api.DeploymentValues,
+	_, err = api.Database.UpdateUserLoginLockout(sysCtx, database.UpdateUserLoginLockoutParams{
+		UserID: user.ID,
+		LockedUntil: sql.NullTime{
+			Time:  now.Add(api.DeploymentValues.LoginLockout.Duration.Value()),
+			Valid: true,
+		},
 	})
 	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Failed to create API key.",
-			Detail:  err.Error(),
-		})
+		logger.Error(ctx, "lock out user", slog.F("user_id", user.ID), slog.Error(err))
 		return
 	}
+	logger.Warn(ctx, "locked out user after too many failed login attempts",
+		slog.F("user_id", user.ID),
+		slog.F("username", user.Username),
+	)
 
-	aReq.New = *key
+	auditor := api.Auditor.Load()
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.User]{
+		Audit:     *auditor,
+		Log:       api.Logger,
+		UserID:    user.ID,
+		RequestID: httpmw.RequestID(r),
+		IP:        r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		Status:    http.StatusUnauthorized,
+		Action:    database.AuditActionLockout,
+		Resource:  user,
+	})
+}
 
-	http.SetCookie(rw, cookie)
+// loginIP returns the address failed logins are counted against.
+func loginIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
 
-	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.LoginWithPasswordResponse{
-		SessionToken: cookie.Value,
+// writeLoginThrottled rejects a login attempt that was made too soon after
+// previous failures.
+func writeLoginThrottled(ctx context.Context, rw http.ResponseWriter, wait time.Duration, message string) {
+	rw.Header().Set("Retry-After", strconv.Itoa(int(loginWaitSeconds(wait))))
+	httpapi.Write(ctx, rw, http.StatusTooManyRequests, codersdk.Response{
+		Message: message,
 	})
 }
 
+// formatLoginWait rounds up so that clients never retry too early.
+func formatLoginWait(wait time.Duration) string {
+	return (time.Duration(loginWaitSeconds(wait)) * time.Second).String()
+}
+
+func loginWaitSeconds(wait time.Duration) int64 {
+	return int64(math.Ceil(wait.Seconds()))
+}
+
 // Clear the user's session cookie.
 //
 // @Summary Log out user
@@ -254,6 +471,14 @@ func (api *API) userAuthMethods(rw http.ResponseWriter, r *http.Request) {
 	if api.OIDCConfig != nil {
 		iconURL = api.OIDCConfig.IconURL
 	}
+	oidcProviders := make([]codersdk.OIDCProviderAuthMethod, 0, len(api.OIDCProviders))
+	for _, oidcConfig := range api.OIDCProviders {
+		oidcProviders = append(oidcProviders, codersdk.OIDCProviderAuthMethod{
+			ID:         oidcConfig.ID,
+			SignInText: oidcConfig.SignInText,
+			IconURL:    oidcConfig.IconURL,
+		})
+	}
 
 	httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.AuthMethods{
 		Password: codersdk.AuthMethod{
@@ -265,6 +490,7 @@ func (api *API) userAuthMethods(rw http.ResponseWriter, r *http.Request) {
 			SignInText: signInText,
 			IconURL:    iconURL,
 		},
+		OIDCProviders: oidcProviders,
 	})
 }
 
@@ -444,6 +670,10 @@ func (api *API) userOAuth2Github(rw http.ResponseWriter, r *http.Request) {
 type OIDCConfig struct {
 	httpmw.OAuth2Config
 
+	// ID identifies an additional provider in its callback URL and in the
+	// links of its users. It is empty for the primary provider.
+	ID string
+
 	Provider *oidc.Provider
 	Verifier *oidc.IDTokenVerifier
 	// EmailDomains are the domains to enforce when a user authenticates.
@@ -474,12 +704,33 @@ type OIDCConfig struct {
 	// to groups within Coder.
 	// map[oidcGroupName]coderGroupName
 	GroupMapping map[string]string
+	// UserRoleField selects the claim field to be used as the created user's
+	// site roles. If the field is the empty string, then no role updates
+	// will ever come from the OIDC provider.
+	UserRoleField string
+	// UserRoleMapping controls how roles returned by the OIDC provider get
+	// mapped to site roles within Coder.
+	// map[oidcRoleName][]coderRoleName
+	UserRoleMapping map[string][]string
+	// UserRolesDefault are the site roles assigned to users when none of the
+	// roles returned by the OIDC provider map to a site role.
+	UserRolesDefault []string
 	// SignInText is the text to display on the OIDC login button
 	SignInText string
 	// IconURL points to the URL of an icon to display on the OIDC login button
 	IconURL string
 }
 
+// OIDCProviderOAuth2Configs returns the OAuth2 configs of the additional
+// OpenID Connect providers keyed by provider ID.
+func OIDCProviderOAuth2Configs(providers []*OIDCConfig) map[string]httpmw.OAuth2Config {
+	configs := make(map[string]httpmw.OAuth2Config, len(providers))
+	for _, provider := range providers {
+		configs[provider.ID] = provider
+	}
+	return configs
+}
+
 // @Summary OpenID Connect Callback
 // @ID openid-connect-callback
 // @Security CoderSessionToken
@@ -487,8 +738,36 @@ type OIDCConfig struct {
 // @Success 307
 // @Router /users/oidc/callback [get]
 func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
+	api.oidcLogin(rw, r, api.OIDCConfig)
+}
+
+// @Summary OpenID Connect Callback for an additional provider
+// @ID openid-connect-callback-for-an-additional-provider
+// @Security CoderSessionToken
+// @Tags Users
+// @Param provider path string true "OIDC provider ID"
+// @Success 307
+// @Router /users/oidc/{provider}/callback [get]
+func (api *API) userOIDCProvider(rw http.ResponseWriter, r *http.Request) {
+	providerID := chi.URLParam(r, "provider")
+	for _, oidcConfig := range api.OIDCProviders {
+		if oidcConfig.ID != providerID {
+			continue
+		}
+		oidcConfig := oidcConfig
+		httpmw.ExtractOAuth2(oidcConfig, api.HTTPClient, oidcConfig.AuthURLParams)(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			api.oidcLogin(rw, r, oidcConfig)
+		})).ServeHTTP(rw, r)
+		return
+	}
+	httpapi.ResourceNotFound(rw)
+}
+
+// oidcLogin completes the OAuth2 flow of the given OpenID Connect provider
+// and signs the user in.
+func (api *API) oidcLogin(rw http.ResponseWriter, r *http.Request, oidcConfig *OIDCConfig) {
 	var (
-		// userOIDC is a system function.
+		// oidcLogin is a system function.
 		//nolint:gocritic
 		ctx               = dbauthz.AsSystemRestricted(r.Context())
 		state             = httpmw.OAuth2(r)
@@ -499,9 +778,18 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 			Request: r,
 			Action:  database.AuditActionLogin,
 		})
+		// rolesAReq is only committed if the roles of the user are changed
+		// by the OIDC provider.
+		rolesAReq, commitRolesAudit = audit.InitRequest[database.User](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
 	)
 	aReq.Old = database.APIKey{}
 	defer commitAudit()
+	defer commitRolesAudit()
 
 	// See the example here: https://github.com/coreos/go-oidc
 	rawIDToken, ok := state.Token.Extra("id_token").(string)
@@ -512,7 +800,7 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idToken, err := api.OIDCConfig.Verifier.Verify(ctx, rawIDToken)
+	idToken, err := oidcConfig.Verifier.Verify(ctx, rawIDToken)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Failed to verify OIDC token.",
@@ -550,8 +838,8 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 	// Some providers (e.g. ADFS) do not support custom OIDC claims in the
 	// UserInfo endpoint, so we allow users to disable it and only rely on the
 	// ID token.
-	if !api.OIDCConfig.IgnoreUserInfo {
-		userInfo, err := api.OIDCConfig.Provider.UserInfo(ctx, oauth2.StaticTokenSource(state.Token))
+	if !oidcConfig.IgnoreUserInfo {
+		userInfo, err := oidcConfig.Provider.UserInfo(ctx, oauth2.StaticTokenSource(state.Token))
 		if err == nil {
 			userInfoClaims := map[string]interface{}{}
 			err = userInfo.Claims(&userInfoClaims)
@@ -592,13 +880,13 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	usernameRaw, ok := claims[api.OIDCConfig.UsernameField]
+	usernameRaw, ok := claims[oidcConfig.UsernameField]
 	var username string
 	if ok {
 		username, _ = usernameRaw.(string)
 	}
 
-	emailRaw, ok := claims[api.OIDCConfig.EmailField]
+	emailRaw, ok := claims[oidcConfig.EmailField]
 	if !ok {
 		// Email is an optional claim in OIDC and
 		// instead the email is frequently sent in
@@ -626,7 +914,7 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 	if ok {
 		verified, ok := verifiedRaw.(bool)
 		if ok && !verified {
-			if !api.OIDCConfig.IgnoreEmailVerified {
+			if !oidcConfig.IgnoreEmailVerified {
 				httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
 					Message: fmt.Sprintf("Verify the %q email address on your OIDC provider to authenticate!", email),
 				})
@@ -640,10 +928,10 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 	var groups []string
 	// If the GroupField is the empty string, then groups from OIDC are not used.
 	// This is so we can support manual group assignment.
-	if api.OIDCConfig.GroupField != "" {
+	if oidcConfig.GroupField != "" {
 		usingGroups = true
-		groupsRaw, ok := claims[api.OIDCConfig.GroupField]
-		if ok && api.OIDCConfig.GroupField != "" {
+		groupsRaw, ok := claims[oidcConfig.GroupField]
+		if ok && oidcConfig.GroupField != "" {
 			// Convert the []interface{} we get to a []string.
 			groupsInterface, ok := groupsRaw.([]interface{})
 			if ok {
@@ -661,7 +949,7 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 						return
 					}
 
-					if mappedGroup, ok := api.OIDCConfig.GroupMapping[group]; ok {
+					if mappedGroup, ok := oidcConfig.GroupMapping[group]; ok {
 						group = mappedGroup
 					}
 
@@ -675,6 +963,46 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var usingRoles bool
+	var roles []string
+	// If the UserRoleField is the empty string, then roles from OIDC are not
+	// used. This is so we can support manual role assignment.
+	if oidcConfig.UserRoleField != "" {
+		usingRoles = true
+		var claimRoles []string
+		// IdPs omit empty claims, so a missing claim means the user has no
+		// roles.
+		switch rolesRaw := claims[oidcConfig.UserRoleField].(type) {
+		case nil:
+		case string:
+			claimRoles = []string{rolesRaw}
+		case []interface{}:
+			for _, roleInterface := range rolesRaw {
+				role, ok := roleInterface.(string)
+				if !ok {
+					httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+						Message: fmt.Sprintf("Invalid role type. Expected string, got: %T", roleInterface),
+					})
+					return
+				}
+				claimRoles = append(claimRoles, role)
+			}
+		default:
+			api.Logger.Debug(ctx, "roles field was an unknown type",
+				slog.F("type", fmt.Sprintf("%T", rolesRaw)),
+			)
+		}
+		api.Logger.Debug(ctx, "roles returned in oidc claims",
+			slog.F("len", len(claimRoles)),
+			slog.F("roles", claimRoles),
+		)
+
+		roles = oidcSiteRoles(claimRoles, oidcConfig.UserRoleMapping)
+		if len(roles) == 0 {
+			roles = oidcSiteRoles(oidcConfig.UserRolesDefault, nil)
+		}
+	}
+
 	// This conditional is purely to warn the user they might have misconfigured their OIDC
 	// configuration.
 	if _, groupClaimExists := claims["groups"]; !usingGroups && groupClaimExists {
@@ -695,9 +1023,9 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		username = httpapi.UsernameFrom(username)
 	}
 
-	if len(api.OIDCConfig.EmailDomain) > 0 {
+	if len(oidcConfig.EmailDomain) > 0 {
 		ok = false
-		for _, domain := range api.OIDCConfig.EmailDomain {
+		for _, domain := range oidcConfig.EmailDomain {
 			if strings.HasSuffix(strings.ToLower(email), strings.ToLower(domain)) {
 				ok = true
 				break
@@ -705,7 +1033,7 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		}
 		if !ok {
 			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
-				Message: fmt.Sprintf("Your email %q is not in domains %q !", email, api.OIDCConfig.EmailDomain),
+				Message: fmt.Sprintf("Your email %q is not in domains %q !", email, oidcConfig.EmailDomain),
 			})
 			return
 		}
@@ -738,12 +1066,16 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		State:        state,
 		LinkedID:     oidcLinkedID(idToken),
 		LoginType:    database.LoginTypeOIDC,
-		AllowSignups: api.OIDCConfig.AllowSignups,
+		OIDCProvider: oidcConfig.ID,
+		AllowSignups: oidcConfig.AllowSignups,
 		Email:        email,
 		Username:     username,
 		AvatarURL:    picture,
 		UsingGroups:  usingGroups,
 		Groups:       groups,
+		UsingRoles:   usingRoles,
+		Roles:        roles,
+		RolesAudit:   rolesAReq,
 	})
 	var httpErr httpError
 	if xerrors.As(err, &httpErr) {
@@ -772,6 +1104,32 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 	http.Redirect(rw, r, redirect, http.StatusTemporaryRedirect)
 }
 
+// oidcSiteRoles maps the roles returned by an OIDC provider to site roles.
+// Roles without a mapping are used as-is, and anything that isn't an
+// assignable site role is dropped. The member role is always implied.
+func oidcSiteRoles(claimRoles []string, mapping map[string][]string) []string {
+	roles := make([]string, 0, len(claimRoles))
+	for _, claimRole := range claimRoles {
+		mapped, ok := mapping[claimRole]
+		if !ok {
+			mapped = []string{claimRole}
+		}
+		for _, role := range mapped {
+			if role == rbac.RoleMember() || slices.Contains(roles, role) {
+				continue
+			}
+			if _, isOrgRole := rbac.IsOrgRole(role); isOrgRole {
+				continue
+			}
+			if _, err := rbac.RoleByName(role); err != nil {
+				continue
+			}
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
 // claimFields returns the sorted list of fields in the claims map.
 func claimFields(claims map[string]interface{}) []string {
 	fields := []string{}
@@ -814,6 +1172,9 @@ type oauthLoginParams struct {
 	State     httpmw.OAuth2State
 	LinkedID  string
 	LoginType database.LoginType
+	// OIDCProvider is the ID of the additional OpenID Connect provider the
+	// user signed in with. It is empty for the primary provider.
+	OIDCProvider string
 
 	// The following are necessary in order to
 	// create new users.
@@ -825,6 +1186,11 @@ type oauthLoginParams struct {
 	// to the Groups provided.
 	UsingGroups bool
 	Groups      []string
+	// If UsingRoles is true, then the site roles of the user will be set to
+	// the Roles provided. Changes to the roles are recorded on RolesAudit.
+	UsingRoles bool
+	Roles      []string
+	RolesAudit *audit.Request[database.User]
 }
 
 type httpError struct {
@@ -845,6 +1211,10 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 	var (
 		ctx  = r.Context()
 		user database.User
+		// rolesOld and rolesNew are the user before and after their roles
+		// were changed by the provider. They stay empty if the roles are
+		// unchanged.
+		rolesOld, rolesNew database.User
 	)
 
 	err := api.Database.InTx(func(tx database.Store) error {
@@ -873,6 +1243,13 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 			}
 		}
 
+		if link.UserID != uuid.Nil && link.OIDCProviderID != params.OIDCProvider {
+			return httpError{
+				code: http.StatusForbidden,
+				msg:  "Your account is linked to a different OpenID Connect provider.",
+			}
+		}
+
 		// This can happen if a user is a built-in user but is signing in
 		// with OIDC for the first time.
 		if user.ID == uuid.Nil {
@@ -947,6 +1324,7 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 				OAuthAccessToken:  params.State.Token.AccessToken,
 				OAuthRefreshToken: params.State.Token.RefreshToken,
 				OAuthExpiry:       params.State.Token.Expiry,
+				OIDCProviderID:    params.OIDCProvider,
 			})
 			if err != nil {
 				return xerrors.Errorf("insert user link: %w", err)
@@ -976,6 +1354,26 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 			}
 		}
 
+		// Ensure roles are correct.
+		if params.UsingRoles {
+			//nolint:gocritic
+			err := api.Options.SetUserSiteRoles(dbauthz.AsSystemRestricted(ctx), tx, user.ID, params.Roles)
+			if err != nil {
+				return xerrors.Errorf("set user site roles: %w", err)
+			}
+			//nolint:gocritic
+			updated, err := tx.GetUserByID(dbauthz.AsSystemRestricted(ctx), user.ID)
+			if err != nil {
+				return xerrors.Errorf("get user: %w", err)
+			}
+			added, removed := rbac.ChangeRoleSet(user.RBACRoles, updated.RBACRoles)
+			if len(added) > 0 || len(removed) > 0 {
+				rolesOld = user
+				user.RBACRoles = updated.RBACRoles
+				rolesNew = user
+			}
+		}
+
 		needsUpdate := false
 		if user.AvatarURL.String != params.AvatarURL {
 			user.AvatarURL = sql.NullString{
@@ -1021,6 +1419,11 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 	if err != nil {
 		return nil, database.APIKey{}, xerrors.Errorf("in tx: %w", err)
 	}
+	if rolesNew.ID != uuid.Nil && params.RolesAudit != nil {
+		params.RolesAudit.Old = rolesOld
+		params.RolesAudit.New = rolesNew
+		params.RolesAudit.UserID = user.ID
+	}
 
 	//nolint:gocritic
 	cookie, key, err := api.createAPIKey(dbauthz.AsSystemRestricted(ctx), apikey.CreateParams{
@@ -1028,6 +1431,7 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 		LoginType:        params.LoginType,
 		DeploymentValues: api.DeploymentValues,
 		RemoteAddr:       r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
 	})
 	if err != nil {
 		return nil, database.APIKey{}, xerrors.Errorf("create API key: %w", err)
@@ -877,6 +877,97 @@ func TestUserOIDC(t *testing.T) {
 	})
 }
 
+func TestUserOIDCProviders(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*codersdk.Client, *coderdtest.OIDCConfig, *coderdtest.OIDCConfig) {
+		t.Helper()
+		primary := coderdtest.NewOIDCConfig(t, "")
+		okta := coderdtest.NewOIDCConfig(t, "https://okta.example.com")
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCConfig: primary.OIDCConfig(t, nil, func(cfg *coderd.OIDCConfig) {
+				cfg.AllowSignups = true
+			}),
+			OIDCProviders: []*coderd.OIDCConfig{
+				okta.OIDCConfig(t, nil, func(cfg *coderd.OIDCConfig) {
+					cfg.ID = "okta"
+					cfg.AllowSignups = true
+					cfg.EmailDomain = []string{"okta.example.com"}
+					cfg.SignInText = "Okta"
+				}),
+			},
+		})
+		return client, primary, okta
+	}
+
+	t.Run("AuthMethods", func(t *testing.T) {
+		t.Parallel()
+		client, _, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		methods, err := client.AuthMethods(ctx)
+		require.NoError(t, err)
+		require.True(t, methods.OIDC.Enabled)
+		require.Equal(t, []codersdk.OIDCProviderAuthMethod{{
+			ID:         "okta",
+			SignInText: "Okta",
+		}}, methods.OIDCProviders)
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		t.Parallel()
+		client, _, okta := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		resp := oidcCallbackPath(t, client, "/api/v2/users/oidc/okta/callback", okta.EncodeClaims(t, jwt.MapClaims{
+			"email": "kyle@okta.example.com",
+		}))
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		client.SetSessionToken(authCookieValue(resp.Cookies()))
+		user, err := client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, "kyle", user.Username)
+	})
+
+	t.Run("EmailDomain", func(t *testing.T) {
+		t.Parallel()
+		client, _, okta := setup(t)
+
+		resp := oidcCallbackPath(t, client, "/api/v2/users/oidc/okta/callback", okta.EncodeClaims(t, jwt.MapClaims{
+			"email": "kyle@coder.com",
+		}))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("LinkedToOtherProvider", func(t *testing.T) {
+		t.Parallel()
+		client, primary, okta := setup(t)
+
+		resp := oidcCallbackPath(t, client, "/api/v2/users/oidc/okta/callback", okta.EncodeClaims(t, jwt.MapClaims{
+			"email": "kyle@okta.example.com",
+		}))
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		// The same email from the primary provider must not take over the
+		// account linked to the okta provider.
+		resp = oidcCallback(t, client, primary.EncodeClaims(t, jwt.MapClaims{
+			"email": "kyle@okta.example.com",
+		}))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("UnknownProvider", func(t *testing.T) {
+		t.Parallel()
+		client, _, okta := setup(t)
+
+		resp := oidcCallbackPath(t, client, "/api/v2/users/oidc/azure/callback", okta.EncodeClaims(t, jwt.MapClaims{
+			"email": "kyle@okta.example.com",
+		}))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
 func TestUserLogout(t *testing.T) {
 	t.Parallel()
 
@@ -1004,11 +1095,16 @@ func oauth2Callback(t *testing.T, client *codersdk.Client) *http.Response {
 }
 
 func oidcCallback(t *testing.T, client *codersdk.Client, code string) *http.Response {
+	t.Helper()
+	return oidcCallbackPath(t, client, "/api/v2/users/oidc/callback", code)
+}
+
+func oidcCallbackPath(t *testing.T, client *codersdk.Client, path string, code string) *http.Response {
 	t.Helper()
 	client.HTTPClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		return http.ErrUseLastResponse
 	}
-	oauthURL, err := client.URL.Parse(fmt.Sprintf("/api/v2/users/oidc/callback?code=%s&state=somestate", code))
+	oauthURL, err := client.URL.Parse(fmt.Sprintf("%s?code=%s&state=somestate", path, code))
 	require.NoError(t, err)
 	req, err := http.NewRequestWithContext(context.Background(), "GET", oauthURL.String(), nil)
 	require.NoError(t, err)
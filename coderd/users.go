@@ -287,7 +287,7 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 
 	// If password auth is disabled, don't allow new users to be
 	// created with a password!
-	if api.DeploymentValues.DisablePasswordAuth {
+	if api.DeploymentValues.DisablePasswordAuth.Value() && !req.ServiceAccount {
 		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
 			Message: "You cannot manually provision new users with password authentication disabled!",
 		})
@@ -357,9 +357,15 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if req.ServiceAccount && req.Password != "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Cannot set password for a service account.",
+		})
+		return
+	}
 
 	var loginType database.LoginType
-	if req.DisableLogin {
+	if req.DisableLogin || req.ServiceAccount {
 		loginType = database.LoginTypeNone
 	} else {
 		err = userpassword.Validate(req.Password)
@@ -379,6 +385,7 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 	user, _, err := api.CreateUser(ctx, api.Database, CreateUserRequest{
 		CreateUserRequest: req,
 		LoginType:         loginType,
+		CreatedBy:         httpmw.APIKey(r).UserID,
 	})
 	if dbauthz.IsNotAuthorizedError(err) {
 		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
@@ -671,6 +678,48 @@ func (api *API) putUserStatus(status database.UserStatus) func(rw http.ResponseW
 	}
 }
 
+// Unlocking an account clears its failed login attempts, so the user can log
+// in again before their lockout expires.
+//
+// @Summary Unlock user account
+// @ID unlock-user-account
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 204
+// @Router /users/{user}/unlock [put]
+func (api *API) putUnlockUserAccount(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.User](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = user
+
+	err := api.Database.DeleteUserLoginFailures(ctx, user.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error unlocking user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = user
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
 // @Summary Update user password
 // @ID update-user-password
 // @Security CoderSessionToken
@@ -696,6 +745,10 @@ func (api *API) putUserPassword(rw http.ResponseWriter, r *http.Request) {
 	defer commitAudit()
 	aReq.Old = user
 
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
 	if !httpapi.Read(ctx, rw, r, &params) {
 		return
 	}
@@ -993,6 +1046,9 @@ type CreateUserRequest struct {
 	codersdk.CreateUserRequest
 	CreateOrganization bool
 	LoginType          database.LoginType
+	// CreatedBy is the admin creating the user. It is required for service
+	// accounts.
+	CreatedBy uuid.UUID
 }
 
 func (api *API) CreateUser(ctx context.Context, store database.Store, req CreateUserRequest) (database.User, uuid.UUID, error) {
@@ -1083,6 +1139,17 @@ func (api *API) CreateUser(ctx context.Context, store database.Store, req Create
 		if err != nil {
 			return xerrors.Errorf("create organization member: %w", err)
 		}
+		if req.ServiceAccount {
+			_, err = tx.InsertServiceAccount(ctx, database.InsertServiceAccountParams{
+				UserID:         user.ID,
+				OrganizationID: req.OrganizationID,
+				CreatedBy:      req.CreatedBy,
+				CreatedAt:      database.Now(),
+			})
+			if err != nil {
+				return xerrors.Errorf("create service account: %w", err)
+			}
+		}
 		return nil
 	}, nil)
 }
@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sort"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/coder/coder/cli/clibase"
@@ -178,6 +180,51 @@ func TestPostLogin(t *testing.T) {
 		require.Equal(t, database.AuditActionLogin, auditor.AuditLogs()[numLogs-1].Action)
 	})
 
+	t.Run("Lockout", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		dc := coderdtest.DeploymentValues(t)
+		dc.LoginLockout.Threshold = 2
+		client := coderdtest.New(t, &coderdtest.Options{
+			Auditor:          auditor,
+			DeploymentValues: dc,
+		})
+		first := coderdtest.CreateFirstUser(t, client)
+		_, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		userClient := codersdk.New(client.URL)
+		var apiErr *codersdk.Error
+		for i := 0; i < 2; i++ {
+			_, err := userClient.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+				Email:    member.Email,
+				Password: "badpass",
+			})
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+		}
+		require.True(t, slices.ContainsFunc(auditor.AuditLogs(), func(log database.AuditLog) bool {
+			return log.Action == database.AuditActionLockout && log.ResourceID == member.ID
+		}))
+
+		// The correct password is rejected while locked out.
+		req := codersdk.LoginWithPasswordRequest{
+			Email:    member.Email,
+			Password: "SomeSecurePassword!",
+		}
+		_, err := userClient.LoginWithPassword(ctx, req)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode())
+		require.Contains(t, apiErr.Message, "locked")
+
+		err = client.UnlockUser(ctx, member.Username)
+		require.NoError(t, err)
+		_, err = userClient.LoginWithPassword(ctx, req)
+		require.NoError(t, err)
+	})
+
 	t.Run("DisabledPasswordAuth", func(t *testing.T) {
 		t.Parallel()
 
@@ -536,6 +583,75 @@ func TestPostUsers(t *testing.T) {
 		assert.Equal(t, firstUser.OrganizationID, user.OrganizationIDs[0])
 	})
 
+	t.Run("ServiceAccount", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		firstUser := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "ci@coder.com",
+			Username:       "ci",
+			Password:       "SomeSecurePassword!",
+			ServiceAccount: true,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		account, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+			OrganizationID: firstUser.OrganizationID,
+			Email:          "ci@coder.com",
+			Username:       "ci",
+			ServiceAccount: true,
+		})
+		require.NoError(t, err)
+		accountClient := codersdk.New(client.URL)
+
+		// Session keys are rejected, service accounts only use tokens.
+		key, err := client.CreateAPIKey(ctx, account.ID.String())
+		require.NoError(t, err)
+		accountClient.SetSessionToken(key.Key)
+		_, err = accountClient.User(ctx, codersdk.Me)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		token, err := client.CreateToken(ctx, account.ID.String(), codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeAll,
+		})
+		require.NoError(t, err)
+		accountClient.SetSessionToken(token.Key)
+		me, err := accountClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, account.ID, me.ID)
+
+		// Audit logs of the service account record the admin that created it.
+		_, err = accountClient.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeAll,
+		})
+		require.NoError(t, err)
+		logs := auditor.AuditLogs()
+		last := logs[len(logs)-1]
+		require.Equal(t, account.ID, last.UserID)
+		var fields map[string]string
+		require.NoError(t, json.Unmarshal(last.AdditionalFields, &fields))
+		require.Equal(t, firstUser.UserID.String(), fields["service_account_created_by"])
+
+		// So do audit logs written outside of the request, such as for
+		// every session that is revoked.
+		err = accountClient.RevokeOtherSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		logs = auditor.AuditLogs()
+		last = logs[len(logs)-1]
+		require.Equal(t, database.ResourceTypeApiKey, last.ResourceType)
+		require.Equal(t, database.AuditActionDelete, last.Action)
+		require.NoError(t, json.Unmarshal(last.AdditionalFields, &fields))
+		require.Equal(t, firstUser.UserID.String(), fields["service_account_created_by"])
+	})
+
 	t.Run("LastSeenAt", func(t *testing.T) {
 		t.Parallel()
 		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
@@ -0,0 +1,499 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/totp"
+	"github.com/coder/coder/coderd/userpassword"
+	"github.com/coder/coder/codersdk"
+)
+
+// totpIssuer is the account issuer shown by authenticator apps.
+const totpIssuer = "Coder"
+
+// @Summary Get user TOTP enrollment
+// @ID get-user-totp-enrollment
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {object} codersdk.UserTOTP
+// @Router /users/{user}/totp [get]
+func (api *API) userTOTP(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	enrollment, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		if dbauthz.IsNotAuthorizedError(err) {
+			httpapi.ResourceNotFound(rw)
+			return
+		}
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	res := codersdk.UserTOTP{
+		Enabled:  enrollment.Enabled,
+		Required: user.LoginType == database.LoginTypePassword && api.mfaRequired(user.RBACRoles),
+	}
+	if enrollment.Enabled {
+		res.RecoveryCodesRemaining = len(enrollment.HashedRecoveryCodes)
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
+}
+
+// Starts a new enrollment that must be verified with a code before it is
+// enabled. Users can only enroll themselves, since the secret must never be
+// known to anyone else.
+//
+// @Summary Enroll user in TOTP
+// @ID enroll-user-in-totp
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 201 {object} codersdk.TOTPEnrollment
+// @Router /users/{user}/totp [post]
+func (api *API) postUserTOTP(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+	if rejectOtherUserTOTP(rw, r, user) {
+		return
+	}
+
+	if user.LoginType != database.LoginTypePassword {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Multi-factor authentication is only supported for password users.",
+		})
+		return
+	}
+
+	existing, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		if dbauthz.IsNotAuthorizedError(err) {
+			httpapi.Forbidden(rw)
+			return
+		}
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if existing.Enabled {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "Multi-factor authentication is already enabled. Reset it before enrolling again.",
+		})
+		return
+	}
+
+	enrollment, ok := api.enrollTOTP(ctx, rw, user)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusCreated, enrollment)
+}
+
+// @Summary Verify user TOTP enrollment
+// @ID verify-user-totp-enrollment
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.VerifyTOTPRequest true "Verify request"
+// @Success 200 {object} codersdk.TOTPRecoveryCodes
+// @Router /users/{user}/totp/verify [post]
+func (api *API) postUserTOTPVerify(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+	if rejectOtherUserTOTP(rw, r, user) {
+		return
+	}
+
+	var req codersdk.VerifyTOTPRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	enrollment, ok := api.userTOTPEnrollment(rw, r, user)
+	if !ok {
+		return
+	}
+	if enrollment.Enabled {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "Multi-factor authentication is already enabled.",
+		})
+		return
+	}
+
+	api.replaceTOTPRecoveryCodes(rw, r, enrollment, req.Code)
+}
+
+// @Summary Regenerate user TOTP recovery codes
+// @ID regenerate-user-totp-recovery-codes
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.VerifyTOTPRequest true "Verify request"
+// @Success 200 {object} codersdk.TOTPRecoveryCodes
+// @Router /users/{user}/totp/recovery-codes [post]
+func (api *API) postUserTOTPRecoveryCodes(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+	if rejectOtherUserTOTP(rw, r, user) {
+		return
+	}
+
+	var req codersdk.VerifyTOTPRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	enrollment, ok := api.userTOTPEnrollment(rw, r, user)
+	if !ok {
+		return
+	}
+	if !enrollment.Enabled {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Multi-factor authentication is not enabled.",
+		})
+		return
+	}
+
+	api.replaceTOTPRecoveryCodes(rw, r, enrollment, req.Code)
+}
+
+// Removes the enrollment of the user. Users confirm the reset of their own
+// enrollment with a code or their password. Admins use this when a user lost
+// their authenticator app and recovery codes, but can only reset the
+// enrollment of users whose roles they could assign.
+//
+// @Summary Reset user TOTP enrollment
+// @ID reset-user-totp-enrollment
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.ResetTOTPRequest true "Reset request"
+// @Success 204
+// @Router /users/{user}/totp [delete]
+func (api *API) deleteUserTOTP(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.User](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = user
+
+	if rejectImpersonation(rw, r) {
+		return
+	}
+
+	if user.ID == apiKey.UserID {
+		var req codersdk.ResetTOTPRequest
+		if !httpapi.Read(ctx, rw, r, &req) {
+			return
+		}
+		if !api.confirmTOTPReset(rw, r, user, req) {
+			return
+		}
+	}
+
+	err := api.Database.DeleteUserTOTP(ctx, user.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.New = user
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// rejectOtherUserTOTP writes an error response when the request manages the
+// enrollment of another user. Admins can only reset enrollments.
+func rejectOtherUserTOTP(rw http.ResponseWriter, r *http.Request, user database.User) bool {
+	if httpmw.APIKey(r).UserID == user.ID {
+		return false
+	}
+	httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+		Message: "Users can only manage their own multi-factor authentication.",
+		Detail:  "Admins can reset the enrollment of other users instead.",
+	})
+	return true
+}
+
+// confirmTOTPReset checks the code or password a user confirms the reset of
+// their own enrollment with, writing an error response if neither is valid.
+func (api *API) confirmTOTPReset(rw http.ResponseWriter, r *http.Request, user database.User, req codersdk.ResetTOTPRequest) bool {
+	ctx := r.Context()
+
+	if req.Code != "" {
+		enrollment, ok := api.userTOTPEnrollment(rw, r, user)
+		if !ok {
+			return false
+		}
+		_, valid, err := checkTOTPCode(enrollment, req.Code, "", database.Now())
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return false
+		}
+		if !valid {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid authentication code.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "code",
+					Detail: "The code is incorrect or was already used.",
+				}},
+			})
+			return false
+		}
+		return true
+	}
+
+	if req.Password != "" {
+		valid, err := userpassword.Compare(string(user.HashedPassword), req.Password)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return false
+		}
+		if !valid {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Incorrect password.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "password",
+					Detail: "The password is incorrect.",
+				}},
+			})
+			return false
+		}
+		return true
+	}
+
+	httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+		Message: "A code or your password is required to reset multi-factor authentication.",
+	})
+	return false
+}
+
+// Starts an enrollment for a user that is required to enroll before they can
+// log in. The enrollment is verified by logging in with a code.
+//
+// @Summary Enroll in TOTP at login
+// @ID enroll-in-totp-at-login
+// @Accept json
+// @Produce json
+// @Tags Authorization
+// @Param request body codersdk.LoginWithPasswordRequest true "Login request"
+// @Success 201 {object} codersdk.TOTPEnrollment
+// @Router /users/login/totp [post]
+func (api *API) postLoginTOTPEnrollment(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var loginWithPassword codersdk.LoginWithPasswordRequest
+	if !httpapi.Read(ctx, rw, r, &loginWithPassword) {
+		return
+	}
+
+	user, roles, ok := api.loginRequest(rw, r, loginWithPassword)
+	if !ok {
+		return
+	}
+	if !api.mfaRequired(roles.Roles) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Multi-factor authentication is not required for your account. Log in to enroll.",
+		})
+		return
+	}
+
+	//nolint:gocritic // The user is not logged in yet.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	existing, err := api.Database.GetUserTOTPByUserID(sysCtx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if existing.Enabled {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "Multi-factor authentication is already enabled.",
+		})
+		return
+	}
+
+	enrollment, ok := api.enrollTOTP(sysCtx, rw, user)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusCreated, enrollment)
+}
+
+// enrollTOTP replaces any pending enrollment of the user with a new secret.
+func (api *API) enrollTOTP(ctx context.Context, rw http.ResponseWriter, user database.User) (codersdk.TOTPEnrollment, bool) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return codersdk.TOTPEnrollment{}, false
+	}
+	_, err = api.Database.UpsertUserTOTP(ctx, database.UpsertUserTOTPParams{
+		UserID:    user.ID,
+		Secret:    secret,
+		CreatedAt: database.Now(),
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return codersdk.TOTPEnrollment{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return codersdk.TOTPEnrollment{}, false
+	}
+	return codersdk.TOTPEnrollment{
+		Secret: secret,
+		URL:    totp.URL(secret, totpIssuer, user.Email),
+	}, true
+}
+
+// userTOTPEnrollment fetches the enrollment of the user, writing an error
+// response if there is none.
+func (api *API) userTOTPEnrollment(rw http.ResponseWriter, r *http.Request, user database.User) (database.UserTOTP, bool) {
+	ctx := r.Context()
+	enrollment, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You must enroll in multi-factor authentication first.",
+		})
+		return database.UserTOTP{}, false
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return database.UserTOTP{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return database.UserTOTP{}, false
+	}
+	return enrollment, true
+}
+
+// replaceTOTPRecoveryCodes checks the code against the enrollment, then
+// enables it with new recovery codes.
+func (api *API) replaceTOTPRecoveryCodes(rw http.ResponseWriter, r *http.Request, enrollment database.UserTOTP, code string) {
+	ctx := r.Context()
+
+	update, valid, err := checkTOTPCode(enrollment, code, "", database.Now())
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid authentication code.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "code",
+				Detail: "The code is incorrect or was already used.",
+			}},
+		})
+		return
+	}
+
+	codes, hashed, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	update.Enabled = true
+	update.HashedRecoveryCodes = hashed
+	_, err = api.Database.UpdateUserTOTP(ctx, update)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.TOTPRecoveryCodes{
+		RecoveryCodes: codes,
+	})
+}
+
+// mfaRequired returns whether a password user with the given roles must
+// enroll in multi-factor authentication.
+func (api *API) mfaRequired(roles []string) bool {
+	return api.DeploymentValues.RequireOwnerMFA.Value() && slices.Contains(roles, rbac.RoleOwner())
+}
+
+// checkTOTPCode validates a code or, for enabled enrollments, a recovery code.
+// The returned params record the code as used.
+func checkTOTPCode(enrollment database.UserTOTP, code, recoveryCode string, now time.Time) (database.UpdateUserTOTPParams, bool, error) {
+	update := database.UpdateUserTOTPParams{
+		UserID:              enrollment.UserID,
+		Enabled:             enrollment.Enabled,
+		HashedRecoveryCodes: enrollment.HashedRecoveryCodes,
+		LastCounter:         enrollment.LastCounter,
+		UpdatedAt:           now,
+	}
+	if code != "" {
+		counter, ok, err := totp.Validate(enrollment.Secret, code, now, enrollment.LastCounter)
+		if err != nil || !ok {
+			return update, false, err
+		}
+		update.LastCounter = counter
+		return update, true, nil
+	}
+	// Recovery codes only exist once an enrollment was verified.
+	if !enrollment.Enabled || recoveryCode == "" {
+		return update, false, nil
+	}
+	index := slices.Index(enrollment.HashedRecoveryCodes, totp.HashRecoveryCode(recoveryCode))
+	if index < 0 {
+		return update, false, nil
+	}
+	update.HashedRecoveryCodes = slices.Delete(slices.Clone(enrollment.HashedRecoveryCodes), index, index+1)
+	return update, true, nil
+}
@@ -0,0 +1,265 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/totp"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestUserTOTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("EnrollAndLogin", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		enrollment, err := client.EnrollTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.NotEmpty(t, enrollment.Secret)
+		require.Contains(t, enrollment.URL, "otpauth://totp/")
+
+		// A pending enrollment is not required at login.
+		status, err := client.UserTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+		login, err := client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, login.SessionToken)
+
+		_, err = client.VerifyTOTP(ctx, codersdk.Me, codersdk.VerifyTOTPRequest{Code: "000000"})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		code, err := totp.Code(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+		codes, err := client.VerifyTOTP(ctx, codersdk.Me, codersdk.VerifyTOTPRequest{Code: code})
+		require.NoError(t, err)
+		require.Len(t, codes.RecoveryCodes, totp.RecoveryCodeCount)
+
+		status, err = client.UserTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.True(t, status.Enabled)
+		require.False(t, status.Required)
+		require.Equal(t, totp.RecoveryCodeCount, status.RecoveryCodesRemaining)
+
+		// The password alone is no longer enough.
+		login, err = client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+		})
+		require.NoError(t, err)
+		require.True(t, login.MFARequired)
+		require.Empty(t, login.SessionToken)
+
+		// The code used to verify cannot be used again.
+		_, err = client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+			TOTPCode: code,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		code, err = totp.Code(enrollment.Secret, time.Now().Add(totp.Period))
+		require.NoError(t, err)
+		login, err = client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+			TOTPCode: code,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, login.SessionToken)
+	})
+
+	t.Run("EnrollOthers", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		_, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Not even owners may learn the secret of another user.
+		_, err := client.EnrollTOTP(ctx, member.ID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		_, err = client.VerifyTOTP(ctx, member.ID.String(), codersdk.VerifyTOTPRequest{Code: "000000"})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		codes := enableTOTP(t, client)
+
+		login, err := client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:        coderdtest.FirstUserParams.Email,
+			Password:     coderdtest.FirstUserParams.Password,
+			RecoveryCode: codes[0],
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, login.SessionToken)
+
+		// Recovery codes can only be used once.
+		_, err = client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:        coderdtest.FirstUserParams.Email,
+			Password:     coderdtest.FirstUserParams.Password,
+			RecoveryCode: codes[0],
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		status, err := client.UserTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, totp.RecoveryCodeCount-1, status.RecoveryCodesRemaining)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		first := coderdtest.CreateFirstUser(t, client)
+		memberClient, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+		userAdminClient, _ := coderdtest.CreateAnotherUser(t, client, first.OrganizationID, rbac.RoleUserAdmin())
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_ = enableTOTP(t, client)
+		_ = enableTOTP(t, memberClient)
+
+		// Members cannot reset the enrollment of others.
+		err := memberClient.ResetTOTP(ctx, first.UserID.String(), codersdk.ResetTOTPRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		// User admins cannot reset the enrollment of owners, since they can't
+		// assign the owner role.
+		err = userAdminClient.ResetTOTP(ctx, first.UserID.String(), codersdk.ResetTOTPRequest{})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		err = userAdminClient.ResetTOTP(ctx, member.ID.String(), codersdk.ResetTOTPRequest{})
+		require.NoError(t, err)
+		status, err := memberClient.UserTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+		logs := auditor.AuditLogs()
+		last := logs[len(logs)-1]
+		require.Equal(t, member.ID, last.ResourceID)
+		require.Equal(t, database.AuditActionWrite, last.Action)
+	})
+
+	t.Run("ResetSelf", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_ = enableTOTP(t, client)
+
+		// A session alone is not enough to reset your own enrollment.
+		err := client.ResetTOTP(ctx, codersdk.Me, codersdk.ResetTOTPRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		err = client.ResetTOTP(ctx, codersdk.Me, codersdk.ResetTOTPRequest{Password: "wrong"})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		err = client.ResetTOTP(ctx, codersdk.Me, codersdk.ResetTOTPRequest{Code: "000000"})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		err = client.ResetTOTP(ctx, codersdk.Me, codersdk.ResetTOTPRequest{
+			Password: coderdtest.FirstUserParams.Password,
+		})
+		require.NoError(t, err)
+		status, err := client.UserTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+	})
+
+	t.Run("RequiredForOwners", func(t *testing.T) {
+		t.Parallel()
+		dv := coderdtest.DeploymentValues(t)
+		dv.RequireOwnerMFA = true
+		client := coderdtest.New(t, &coderdtest.Options{DeploymentValues: dv})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		first, err := client.CreateFirstUser(ctx, coderdtest.FirstUserParams)
+		require.NoError(t, err)
+		req := codersdk.LoginWithPasswordRequest{
+			Email:    coderdtest.FirstUserParams.Email,
+			Password: coderdtest.FirstUserParams.Password,
+		}
+		login, err := client.LoginWithPassword(ctx, req)
+		require.NoError(t, err)
+		require.True(t, login.MFAEnrollmentRequired)
+		require.Empty(t, login.SessionToken)
+
+		enrollment, err := client.EnrollTOTPWithPassword(ctx, req)
+		require.NoError(t, err)
+		req.TOTPCode, err = totp.Code(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+		login, err = client.LoginWithPassword(ctx, req)
+		require.NoError(t, err)
+		require.NotEmpty(t, login.SessionToken)
+		require.Len(t, login.RecoveryCodes, totp.RecoveryCodeCount)
+		client.SetSessionToken(login.SessionToken)
+
+		status, err := client.UserTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.True(t, status.Enabled)
+		require.True(t, status.Required)
+
+		// Members are not required to enroll.
+		_, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+		_, err = client.EnrollTOTPWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    member.Email,
+			Password: "SomeSecurePassword!",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+// enableTOTP enrolls the user of the client and returns their recovery codes.
+func enableTOTP(t *testing.T, client *codersdk.Client) []string {
+	t.Helper()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	enrollment, err := client.EnrollTOTP(ctx, codersdk.Me)
+	require.NoError(t, err)
+	code, err := totp.Code(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	codes, err := client.VerifyTOTP(ctx, codersdk.Me, codersdk.VerifyTOTPRequest{Code: code})
+	require.NoError(t, err)
+	return codes.RecoveryCodes
+}
@@ -62,8 +62,16 @@ func (api *API) workspaceAgent(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	derpMap, err := (*api.DERPMapProvider.Load()).All(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 	apiAgent, err := convertWorkspaceAgent(
-		api.DERPMap, *api.TailnetCoordinator.Load(), workspaceAgent, convertApps(dbApps), api.AgentInactiveDisconnectTimeout,
+		derpMap, *api.TailnetCoordinator.Load(), workspaceAgent, convertApps(dbApps), api.AgentInactiveDisconnectTimeout,
 		api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
 	)
 	if err != nil {
@@ -87,8 +95,16 @@ func (api *API) workspaceAgent(rw http.ResponseWriter, r *http.Request) {
 func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	workspaceAgent := httpmw.WorkspaceAgent(r)
+	fullDERPMap, err := (*api.DERPMapProvider.Load()).All(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 	apiAgent, err := convertWorkspaceAgent(
-		api.DERPMap, *api.TailnetCoordinator.Load(), workspaceAgent, nil, api.AgentInactiveDisconnectTimeout,
+		fullDERPMap, *api.TailnetCoordinator.Load(), workspaceAgent, nil, api.AgentInactiveDisconnectTimeout,
 		api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
 	)
 	if err != nil {
@@ -149,6 +165,15 @@ func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	derpMap, err := (*api.DERPMapProvider.Load()).ForTemplate(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	vscodeProxyURI := strings.ReplaceAll(api.AppHostname, "*",
 		fmt.Sprintf("%s://{{port}}--%s--%s--%s",
 			api.AccessURL.Scheme,
@@ -162,7 +187,7 @@ func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request)
 
 	httpapi.Write(ctx, rw, http.StatusOK, agentsdk.Manifest{
 		Apps:                  convertApps(dbApps),
-		DERPMap:               api.DERPMap,
+		DERPMap:               derpMap,
 		GitAuthConfigs:        len(api.GitAuthConfigs),
 		EnvironmentVariables:  apiAgent.EnvironmentVariables,
 		StartupScript:         apiAgent.StartupScript,
@@ -189,8 +214,16 @@ func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request)
 func (api *API) postWorkspaceAgentStartup(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	workspaceAgent := httpmw.WorkspaceAgent(r)
+	derpMap, err := (*api.DERPMapProvider.Load()).All(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 	apiAgent, err := convertWorkspaceAgent(
-		api.DERPMap, *api.TailnetCoordinator.Load(), workspaceAgent, nil, api.AgentInactiveDisconnectTimeout,
+		derpMap, *api.TailnetCoordinator.Load(), workspaceAgent, nil, api.AgentInactiveDisconnectTimeout,
 		api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
 	)
 	if err != nil {
@@ -617,8 +650,16 @@ func (api *API) workspaceAgentListeningPorts(rw http.ResponseWriter, r *http.Req
 	ctx := r.Context()
 	workspaceAgent := httpmw.WorkspaceAgentParam(r)
 
+	derpMap, err := (*api.DERPMapProvider.Load()).All(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 	apiAgent, err := convertWorkspaceAgent(
-		api.DERPMap, *api.TailnetCoordinator.Load(), workspaceAgent, nil, api.AgentInactiveDisconnectTimeout,
+		derpMap, *api.TailnetCoordinator.Load(), workspaceAgent, nil, api.AgentInactiveDisconnectTimeout,
 		api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
 	)
 	if err != nil {
@@ -711,18 +752,26 @@ func (api *API) workspaceAgentListeningPorts(rw http.ResponseWriter, r *http.Req
 }
 
 func (api *API) dialWorkspaceAgentTailnet(agentID uuid.UUID) (*codersdk.WorkspaceAgentConn, error) {
-	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(api.ctx)
+	// coderd uses the DERP map of the agent, so it is always able to reach
+	// the home region of the agent.
+	//nolint:gocritic // coderd dials agents on behalf of many users.
+	systemCtx := dbauthz.AsSystemRestricted(ctx)
+	derpMap, err := api.agentDERPMap(systemCtx, agentID)
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("get agent derp map: %w", err)
+	}
 	conn, err := tailnet.NewConn(&tailnet.Options{
 		Addresses: []netip.Prefix{netip.PrefixFrom(tailnet.IP(), 128)},
-		DERPMap:   api.DERPMap,
+		DERPMap:   derpMap,
 		Logger:    api.Logger.Named("tailnet"),
 	})
 	if err != nil {
-		_ = clientConn.Close()
-		_ = serverConn.Close()
+		cancel()
 		return nil, xerrors.Errorf("create tailnet conn: %w", err)
 	}
-	ctx, cancel := context.WithCancel(api.ctx)
+	go api.updateAgentConnDERPMap(systemCtx, conn, agentID)
 	conn.SetDERPRegionDialer(func(_ context.Context, region *tailcfg.DERPRegion) net.Conn {
 		if !region.EmbeddedRelay {
 			return nil
@@ -737,31 +786,28 @@ func (api *API) dialWorkspaceAgentTailnet(agentID uuid.UUID) (*codersdk.Workspac
 		return left
 	})
 
-	sendNodes, _ := tailnet.ServeCoordinator(clientConn, func(node []*tailnet.Node) error {
-		err = conn.UpdateNodes(node, true)
-		if err != nil {
-			return xerrors.Errorf("update nodes: %w", err)
-		}
-		return nil
-	})
-	conn.SetNodeCallback(sendNodes)
+	coordination := tailnet.NewInMemoryCoordination(
+		api.ctx, api.Logger.Named("coordination"), *api.TailnetCoordinator.Load(),
+		uuid.New(), "coderd", tailnet.ClientTunnelAuth{AgentID: agentID},
+		conn, agentID,
+	)
 	agentConn := &codersdk.WorkspaceAgentConn{
 		Conn: conn,
 		CloseFunc: func() {
 			cancel()
-			_ = clientConn.Close()
-			_ = serverConn.Close()
+			_ = coordination.Close()
 		},
 	}
 	go func() {
-		err := (*api.TailnetCoordinator.Load()).ServeClient(serverConn, uuid.New(), agentID)
-		if err != nil {
-			// Sometimes, we get benign closed pipe errors when the server is
+		select {
+		case <-ctx.Done():
+		case err := <-coordination.Error():
+			// Sometimes, we get benign errors when the server is
 			// shutting down.
-			if api.ctx.Err() == nil {
-				api.Logger.Warn(ctx, "tailnet coordinator client error", slog.Error(err))
+			if ctx.Err() == nil {
+				api.Logger.Warn(ctx, "tailnet coordination error", slog.Error(err))
+				_ = agentConn.Close()
 			}
-			_ = agentConn.Close()
 		}
 	}()
 	if !agentConn.AwaitReachable(ctx) {
@@ -781,9 +827,42 @@ func (api *API) dialWorkspaceAgentTailnet(agentID uuid.UUID) (*codersdk.Workspac
 // @Router /workspaceagents/{workspaceagent}/connection [get]
 func (api *API) workspaceAgentConnection(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	var (
+		derpMap *tailcfg.DERPMap
+		err     error
+	)
+	if apiKey, ok := httpmw.APIKeyOptional(r); ok {
+		derpMap, err = (*api.DERPMapProvider.Load()).ForUser(ctx, apiKey.UserID)
+	} else {
+		// Workspace proxies connect on behalf of many users, so they use
+		// the DERP map of the agent.
+		derpMap, err = (*api.DERPMapProvider.Load()).ForTemplate(ctx, workspace.TemplateID)
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching DERP map.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Connections through DERP only work if the client can reach the home
+	// region of the agent, which its template's policy may not allow.
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+	node := (*api.TailnetCoordinator.Load()).Node(workspaceAgent.ID)
+	if node != nil && node.PreferredDERP != 0 {
+		if _, ok := derpMap.Regions[node.PreferredDERP]; !ok {
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: fmt.Sprintf("The workspace agent uses DERP region %d, which you are not allowed to use.", node.PreferredDERP),
+				Detail:  "Ask an administrator to allow the region in the DERP region policies of your groups.",
+			})
+			return
+		}
+	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceAgentConnectionInfo{
-		DERPMap: api.DERPMap,
+		DERPMap: derpMap,
 	})
 }
 
@@ -793,11 +872,21 @@ func (api *API) workspaceAgentConnection(rw http.ResponseWriter, r *http.Request
 // @ID coordinate-workspace-agent-via-tailnet
 // @Security CoderSessionToken
 // @Tags Agents
+// @Param version query int false "Coordination protocol version"
 // @Success 101
 // @Router /workspaceagents/me/coordinate [get]
 func (api *API) workspaceAgentCoordinate(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	version, err := tailnet.ParseCoordinateVersion(r.URL.Query().Get(tailnet.CoordinateVersionQueryParam))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Unsupported coordination protocol version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	api.WebsocketWaitMutex.Lock()
 	api.WebsocketWaitGroup.Add(1)
 	api.WebsocketWaitMutex.Unlock()
@@ -865,6 +954,7 @@ func (api *API) workspaceAgentCoordinate(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
+	rw.Header().Set(tailnet.CoordinateVersionHeader, strconv.Itoa(version))
 	conn, err := websocket.Accept(rw, r, nil)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -993,9 +1083,16 @@ func (api *API) workspaceAgentCoordinate(rw http.ResponseWriter, r *http.Request
 	closeChan := make(chan struct{})
 	go func() {
 		defer close(closeChan)
-		err := (*api.TailnetCoordinator.Load()).ServeAgent(wsNetConn, workspaceAgent.ID,
-			fmt.Sprintf("%s-%s-%s", owner.Username, workspace.Name, workspaceAgent.Name),
-		)
+		coordinator := *api.TailnetCoordinator.Load()
+		name := fmt.Sprintf("%s-%s-%s", owner.Username, workspace.Name, workspaceAgent.Name)
+		var err error
+		if version >= tailnet.CoordinateVersion2 {
+			err = tailnet.ServeCoordinatePeer(ctx, api.Logger, coordinator, wsNetConn,
+				workspaceAgent.ID, name, tailnet.AgentTunnelAuth{},
+			)
+		} else {
+			err = coordinator.ServeAgent(wsNetConn, workspaceAgent.ID, name)
+		}
 		if err != nil {
 			api.Logger.Warn(ctx, "tailnet coordinator agent error", slog.Error(err))
 			_ = conn.Close(websocket.StatusInternalError, err.Error())
@@ -1057,11 +1154,21 @@ func (api *API) workspaceAgentCoordinate(rw http.ResponseWriter, r *http.Request
 // @Security CoderSessionToken
 // @Tags Agents
 // @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Param version query int false "Coordination protocol version"
 // @Success 101
 // @Router /workspaceagents/{workspaceagent}/coordinate [get]
 func (api *API) workspaceAgentClientCoordinate(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	version, err := tailnet.ParseCoordinateVersion(r.URL.Query().Get(tailnet.CoordinateVersionQueryParam))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Unsupported coordination protocol version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	// This route accepts user API key auth and workspace proxy auth. The moon actor has
 	// full permissions so should be able to pass this authz check.
 	workspace := httpmw.WorkspaceParam(r)
@@ -1085,6 +1192,7 @@ func (api *API) workspaceAgentClientCoordinate(rw http.ResponseWriter, r *http.R
 	defer api.WebsocketWaitGroup.Done()
 	workspaceAgent := httpmw.WorkspaceAgentParam(r)
 
+	rw.Header().Set(tailnet.CoordinateVersionHeader, strconv.Itoa(version))
 	conn, err := websocket.Accept(rw, r, nil)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -1099,7 +1207,14 @@ func (api *API) workspaceAgentClientCoordinate(rw http.ResponseWriter, r *http.R
 	go httpapi.Heartbeat(ctx, conn)
 
 	defer conn.Close(websocket.StatusNormalClosure, "")
-	err = (*api.TailnetCoordinator.Load()).ServeClient(wsNetConn, uuid.New(), workspaceAgent.ID)
+	coordinator := *api.TailnetCoordinator.Load()
+	if version >= tailnet.CoordinateVersion2 {
+		err = tailnet.ServeCoordinatePeer(ctx, api.Logger, coordinator, wsNetConn,
+			uuid.New(), "client", tailnet.ClientTunnelAuth{AgentID: workspaceAgent.ID},
+		)
+	} else {
+		err = coordinator.ServeClient(wsNetConn, uuid.New(), workspaceAgent.ID)
+	}
 	if err != nil {
 		_ = conn.Close(websocket.StatusInternalError, err.Error())
 		return
@@ -1110,15 +1225,16 @@ func convertApps(dbApps []database.WorkspaceApp) []codersdk.WorkspaceApp {
 	apps := make([]codersdk.WorkspaceApp, 0)
 	for _, dbApp := range dbApps {
 		apps = append(apps, codersdk.WorkspaceApp{
-			ID:           dbApp.ID,
-			URL:          dbApp.Url.String,
-			External:     dbApp.External,
-			Slug:         dbApp.Slug,
-			DisplayName:  dbApp.DisplayName,
-			Command:      dbApp.Command.String,
-			Icon:         dbApp.Icon,
-			Subdomain:    dbApp.Subdomain,
-			SharingLevel: codersdk.WorkspaceAppSharingLevel(dbApp.SharingLevel),
+			ID:            dbApp.ID,
+			URL:           dbApp.Url.String,
+			External:      dbApp.External,
+			Slug:          dbApp.Slug,
+			DisplayName:   dbApp.DisplayName,
+			Command:       dbApp.Command.String,
+			Icon:          dbApp.Icon,
+			Subdomain:     dbApp.Subdomain,
+			SharingLevel:  codersdk.WorkspaceAppSharingLevel(dbApp.SharingLevel),
+			SharingGroups: dbApp.SharingGroups,
 			Healthcheck: codersdk.Healthcheck{
 				URL:       dbApp.HealthcheckUrl,
 				Interval:  dbApp.HealthcheckInterval,
@@ -1215,6 +1331,31 @@ func convertWorkspaceAgent(derpMap *tailcfg.DERPMap, coordinator tailnet.Coordin
 	workspaceAgent.LastConnectedAt = status.LastConnectedAt
 	workspaceAgent.DisconnectedAt = status.DisconnectedAt
 
+	workspaceAgent.PeerConnections = []codersdk.WorkspaceAgentPeerConnection{}
+	if workspaceAgent.Status == codersdk.WorkspaceAgentConnected {
+		var peerConnections []agentsdk.PeerConnection
+		if len(dbAgent.PeerConnections) > 0 {
+			err := json.Unmarshal(dbAgent.PeerConnections, &peerConnections)
+			if err != nil {
+				return codersdk.WorkspaceAgent{}, xerrors.Errorf("unmarshal peer connections: %w", err)
+			}
+		}
+		for _, peerConnection := range peerConnections {
+			converted := codersdk.WorkspaceAgentPeerConnection{
+				Direct:              peerConnection.Direct,
+				DERPRegionID:        peerConnection.DERPRegionID,
+				LatencyMilliseconds: peerConnection.LatencyMilliseconds,
+			}
+			if !peerConnection.Direct {
+				converted.DERPRegionName = fmt.Sprintf("Unnamed %d", peerConnection.DERPRegionID)
+				if region, found := derpMap.Regions[peerConnection.DERPRegionID]; found {
+					converted.DERPRegionName = region.RegionName
+				}
+			}
+			workspaceAgent.PeerConnections = append(workspaceAgent.PeerConnections, converted)
+		}
+	}
+
 	return workspaceAgent, nil
 }
 
@@ -1309,6 +1450,24 @@ func (api *API) workspaceAgentReportStats(rw http.ResponseWriter, r *http.Reques
 		}
 		return nil
 	})
+	// Older agents don't report peer connections, so the last snapshot is
+	// kept.
+	if req.PeerConnections != nil {
+		errGroup.Go(func() error {
+			peerConnections, err := json.Marshal(req.PeerConnections)
+			if err != nil {
+				return xerrors.Errorf("marshal peer connections: %w", err)
+			}
+			err = api.Database.UpdateWorkspaceAgentPeerConnectionsByID(ctx, database.UpdateWorkspaceAgentPeerConnectionsByIDParams{
+				ID:              workspaceAgent.ID,
+				PeerConnections: peerConnections,
+			})
+			if err != nil {
+				return xerrors.Errorf("can't update workspace agent peer connections: %w", err)
+			}
+			return nil
+		})
+	}
 	if api.Options.UpdateAgentMetrics != nil {
 		errGroup.Go(func() error {
 			user, err := api.Database.GetUserByID(ctx, workspace.OwnerID)
@@ -1886,7 +2045,7 @@ func (api *API) workspaceAgentsGitAuth(rw http.ResponseWriter, r *http.Request)
 					continue
 				}
 			}
-			httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken))
+			httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig, gitAuthLink.OAuthAccessToken))
 			return
 		}
 	}
@@ -1934,31 +2093,16 @@ func (api *API) workspaceAgentsGitAuth(rw http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
-	httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken))
+	httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig, gitAuthLink.OAuthAccessToken))
 }
 
 // Provider types have different username/password formats.
-func formatGitAuthAccessToken(typ codersdk.GitProvider, token string) agentsdk.GitAuthResponse {
-	var resp agentsdk.GitAuthResponse
-	switch typ {
-	case codersdk.GitProviderGitLab:
-		// https://stackoverflow.com/questions/25409700/using-gitlab-token-to-clone-without-authentication
-		resp = agentsdk.GitAuthResponse{
-			Username: "oauth2",
-			Password: token,
-		}
-	case codersdk.GitProviderBitBucket:
-		// https://support.atlassian.com/bitbucket-cloud/docs/use-oauth-on-bitbucket-cloud/#Cloning-a-repository-with-an-access-token
-		resp = agentsdk.GitAuthResponse{
-			Username: "x-token-auth",
-			Password: token,
-		}
-	default:
-		resp = agentsdk.GitAuthResponse{
-			Username: token,
-		}
+func formatGitAuthAccessToken(gitAuthConfig *gitauth.Config, token string) agentsdk.GitAuthResponse {
+	username, password := gitAuthConfig.Credentials(token)
+	return agentsdk.GitAuthResponse{
+		Username: username,
+		Password: password,
 	}
-	return resp
 }
 
 func (api *API) gitAuthCallback(gitAuthConfig *gitauth.Config) http.HandlerFunc {
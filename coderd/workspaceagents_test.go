@@ -1355,6 +1355,65 @@ func TestWorkspaceAgentReportStats(t *testing.T) {
 			"%s is not after %s", newWorkspace.LastUsedAt, workspace.LastUsedAt,
 		)
 	})
+
+	t.Run("PeerConnections", func(t *testing.T) {
+		t.Parallel()
+
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(authToken)
+		regionID := api.DERPMap.RegionIDs()[0]
+		_, err := agentClient.PostStats(ctx, &agentsdk.Stats{
+			ConnectionsByProto: map[string]int64{"TCP": 2},
+			ConnectionCount:    2,
+			PeerConnections: []agentsdk.PeerConnection{{
+				Direct:              true,
+				LatencyMilliseconds: 5,
+			}, {
+				DERPRegionID:        regionID,
+				LatencyMilliseconds: 20,
+			}},
+		})
+		require.NoError(t, err)
+
+		// The snapshot is only shown while the agent is connected.
+		workspaceAgent, err := client.WorkspaceAgent(ctx, build.Resources[0].Agents[0].ID)
+		require.NoError(t, err)
+		require.Empty(t, workspaceAgent.PeerConnections)
+
+		conn, err := agentClient.Listen(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.Eventually(t, func() bool {
+			workspaceAgent, err = client.WorkspaceAgent(ctx, workspaceAgent.ID)
+			return err == nil && workspaceAgent.Status == codersdk.WorkspaceAgentConnected
+		}, testutil.WaitShort, testutil.IntervalFast)
+		require.Equal(t, []codersdk.WorkspaceAgentPeerConnection{{
+			Direct:              true,
+			LatencyMilliseconds: 5,
+		}, {
+			DERPRegionID:        regionID,
+			DERPRegionName:      api.DERPMap.Regions[regionID].RegionName,
+			LatencyMilliseconds: 20,
+		}}, workspaceAgent.PeerConnections)
+	})
 }
 
 func TestWorkspaceAgent_LifecycleState(t *testing.T) {
@@ -61,6 +61,10 @@ func (api *API) workspaceApplicationAuth(rw http.ResponseWriter, r *http.Request
 		httpapi.ResourceNotFound(rw)
 		return
 	}
+	// The key would outlive the impersonation and not be marked as one.
+	if rejectImpersonation(rw, r) {
+		return
+	}
 
 	// Get the redirect URI from the query parameters and parse it.
 	redirectURI := r.URL.Query().Get(workspaceapps.RedirectURIQueryParam)
@@ -117,6 +121,7 @@ func (api *API) workspaceApplicationAuth(rw http.ResponseWriter, r *http.Request
 		ExpiresAt:        exp,
 		LifetimeSeconds:  lifetimeSeconds,
 		Scope:            database.APIKeyScopeApplicationConnect,
+		UserAgent:        r.UserAgent(),
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -127,7 +132,7 @@ func (api *API) workspaceApplicationAuth(rw http.ResponseWriter, r *http.Request
 	}
 
 	// Encrypt the API key.
-	encryptedAPIKey, err := api.AppSecurityKey.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
+	encryptedAPIKey, err := api.AppSecurityKeys.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
 		APIKey: cookie.Value,
 	})
 	if err != nil {
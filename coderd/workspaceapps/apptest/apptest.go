@@ -1260,4 +1260,51 @@ func Run(t *testing.T, appHostIsPrimary bool, factory DeploymentFactory) {
 			})
 		}
 	})
+
+	t.Run("AppUsage", func(t *testing.T) {
+		t.Parallel()
+
+		appDetails := setupProxyTest(t, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		u := appDetails.SubdomainAppURL(appDetails.Apps.Owner)
+		resp, err := requestWithRetries(ctx, t, appDetails.AppClient(t), http.MethodGet, u.String(), nil)
+		require.NoError(t, err)
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// The fake app is not listening, so the request fails.
+		resp, err = appDetails.AppClient(t).Request(ctx, http.MethodGet, appDetails.SubdomainAppURL(appDetails.Apps.Fake).String(), nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+		var owner, fake codersdk.AppInsight
+		require.Eventually(t, func() bool {
+			res, err := appDetails.SDKClient.TemplateAppInsights(ctx, appDetails.Workspace.TemplateID, codersdk.AppInsightsRequest{})
+			if !assert.NoError(t, err) {
+				return false
+			}
+			for _, app := range res.Apps {
+				switch app.Slug {
+				case proxyTestAppNameOwner:
+					owner = app
+				case proxyTestAppNameFake:
+					fake = app
+				}
+			}
+			return owner.Requests > 0 && fake.Requests > 0
+		}, testutil.WaitLong, testutil.IntervalFast)
+
+		require.EqualValues(t, 1, owner.UniqueUsers)
+		// Requests are retried until the app is reachable, so earlier
+		// requests may have failed.
+		require.Less(t, owner.ErrorRequests, owner.Requests)
+		require.GreaterOrEqual(t, owner.BytesSent, int64(len(proxyTestAppBody)))
+		require.Equal(t, fake.Requests, fake.ErrorRequests)
+	})
 }
@@ -3,16 +3,21 @@ package workspaceapps
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
@@ -29,16 +34,17 @@ type DBTokenProvider struct {
 	// DashboardURL is the main dashboard access URL for error pages.
 	DashboardURL                  *url.URL
 	Authorizer                    rbac.Authorizer
+	Auditor                       *atomic.Pointer[audit.Auditor]
 	Database                      database.Store
 	DeploymentValues              *codersdk.DeploymentValues
 	OAuth2Configs                 *httpmw.OAuth2Configs
 	WorkspaceAgentInactiveTimeout time.Duration
-	SigningKey                    SecurityKey
+	SigningKeys                   *SecurityKeySet
 }
 
 var _ SignedTokenProvider = &DBTokenProvider{}
 
-func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authorizer, db database.Store, cfg *codersdk.DeploymentValues, oauth2Cfgs *httpmw.OAuth2Configs, workspaceAgentInactiveTimeout time.Duration, signingKey SecurityKey) SignedTokenProvider {
+func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authorizer, auditor *atomic.Pointer[audit.Auditor], db database.Store, cfg *codersdk.DeploymentValues, oauth2Cfgs *httpmw.OAuth2Configs, workspaceAgentInactiveTimeout time.Duration, signingKeys *SecurityKeySet) SignedTokenProvider {
 	if workspaceAgentInactiveTimeout == 0 {
 		workspaceAgentInactiveTimeout = 1 * time.Minute
 	}
@@ -47,16 +53,17 @@ func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authoriz
 		Logger:                        log,
 		DashboardURL:                  accessURL,
 		Authorizer:                    authz,
+		Auditor:                       auditor,
 		Database:                      db,
 		DeploymentValues:              cfg,
 		OAuth2Configs:                 oauth2Cfgs,
 		WorkspaceAgentInactiveTimeout: workspaceAgentInactiveTimeout,
-		SigningKey:                    signingKey,
+		SigningKeys:                   signingKeys,
 	}
 }
 
 func (p *DBTokenProvider) FromRequest(r *http.Request) (*SignedToken, bool) {
-	return FromRequest(r, p.SigningKey)
+	return FromRequest(r, p.SigningKeys)
 }
 
 func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *http.Request, issueReq IssueTokenRequest) (*SignedToken, string, bool) {
@@ -85,6 +92,7 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		OAuth2Configs:               p.OAuth2Configs,
 		RedirectToLogin:             false,
 		DisableSessionExpiryRefresh: p.DeploymentValues.DisableSessionExpiryRefresh.Value(),
+		DisableImpersonation:        p.DeploymentValues.DisableImpersonation.Value(),
 		// Optional is true to allow for public apps. If the authorization check
 		// (later on) fails and the user is not authenticated, they will be
 		// redirected to the login page or app auth endpoint using code below.
@@ -209,12 +217,18 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 
 	// Sign the token.
 	token.Expiry = time.Now().Add(DefaultTokenExpiry)
-	tokenStr, err := p.SigningKey.SignToken(token)
+	tokenStr, err := p.SigningKeys.SignToken(token)
 	if err != nil {
 		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "generate token")
 		return nil, "", false
 	}
 
+	// Access to apps shared with groups is audited, since the groups an app is
+	// shared with may change without the owner rebuilding the workspace.
+	if apiKey != nil && dbReq.AppSharingLevel == database.AppSharingLevelGroup && apiKey.UserID != dbReq.Workspace.OwnerID {
+		p.auditAppAccess(r, apiKey.UserID, authz.ServiceAccountCreatedBy, dbReq)
+	}
+
 	return &token, tokenStr, true
 }
 
@@ -288,6 +302,17 @@ func (p *DBTokenProvider) authorizeRequest(ctx context.Context, roles *httpmw.Au
 		// We essentially already did this above with the regular RBAC check.
 		// Owners can always access their own apps according to RBAC rules, so
 		// they have already been returned from this function.
+	case database.AppSharingLevelGroup:
+		// Members of the groups are treated as if the app was shared with
+		// authenticated users, so scopes are enforced with the owned
+		// resource.
+		if !actorInAnyGroup(roles.Actor, dbReq.Workspace.OrganizationID, dbReq.AppSharingGroupIDs) {
+			break
+		}
+		err := p.Authorizer.Authorize(ctx, roles.Actor, rbacAction, rbacResourceOwned)
+		if err == nil {
+			return true, nil
+		}
 	case database.AppSharingLevelAuthenticated:
 		// Check with the owned resource to ensure the API key has permissions
 		// to connect to the actor's own workspace. This enforces scopes.
@@ -305,3 +330,60 @@ func (p *DBTokenProvider) authorizeRequest(ctx context.Context, roles *httpmw.Au
 	// No checks were successful.
 	return false, nil
 }
+
+// actorInAnyGroup returns whether the actor is a member of any of the groups.
+// The "Everyone" group of an organization has the ID of the organization and
+// includes every member of the organization.
+func actorInAnyGroup(actor rbac.Subject, organizationID uuid.UUID, groupIDs []uuid.UUID) bool {
+	for _, groupID := range groupIDs {
+		if groupID == organizationID {
+			if slices.Contains(actor.Roles.Names(), rbac.RoleOrgMember(organizationID)) {
+				return true
+			}
+			continue
+		}
+		if slices.Contains(actor.Groups, groupID.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditAppAccess records a user connecting to an app of a workspace they
+// don't own.
+func (p *DBTokenProvider) auditAppAccess(r *http.Request, userID uuid.UUID, serviceAccountCreatedBy uuid.NullUUID, dbReq *databaseRequest) {
+	if p.Auditor == nil {
+		return
+	}
+	auditor := p.Auditor.Load()
+	if auditor == nil {
+		return
+	}
+
+	additionalFields, err := json.Marshal(audit.AdditionalFields{
+		WorkspaceName:  dbReq.Workspace.Name,
+		WorkspaceOwner: dbReq.User.Username,
+		App:            dbReq.AppSlugOrPort,
+	})
+	if err != nil {
+		p.Logger.Warn(r.Context(), "marshal audit additional fields", slog.Error(err))
+		additionalFields = nil
+	}
+	// The request ID is unset if the request was made by a workspace proxy.
+	requestID, _ := httpmw.RequestIDOptional(r)
+
+	audit.BackgroundAudit(r.Context(), &audit.BackgroundAuditParams[database.Workspace]{
+		Audit:            *auditor,
+		Log:              p.Logger,
+		UserID:           userID,
+		RequestID:        requestID,
+		IP:               r.RemoteAddr,
+		UserAgent:        r.UserAgent(),
+		Status:           http.StatusOK,
+		Action:           database.AuditActionConnect,
+		AdditionalFields: additionalFields,
+		Resource:         dbReq.Workspace,
+
+		ServiceAccountCreatedBy: serviceAccountCreatedBy,
+	})
+}
@@ -2,6 +2,8 @@ package workspaceapps_test
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -19,7 +21,10 @@ import (
 
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
@@ -39,6 +44,12 @@ func Test_ResolveRequest(t *testing.T) {
 		appNamePublic     = "app-public"
 		appNameInvalidURL = "app-invalid-url"
 		appNameUnhealthy  = "app-unhealthy"
+		appNameGroup      = "app-group"
+		appNameEveryone   = "app-everyone"
+
+		// The group is created after the workspace is built, since groups are
+		// resolved by name when the app is accessed.
+		groupName = "app-group-members"
 
 		// This agent will never connect, so it will never become "connected".
 		agentNameUnhealthy    = "agent-unhealthy"
@@ -62,7 +73,9 @@ func Test_ResolveRequest(t *testing.T) {
 	deploymentValues.Dangerous.AllowPathAppSharing = true
 	deploymentValues.Dangerous.AllowPathAppSiteOwnerAccess = true
 
+	auditor := audit.NewMock()
 	client, closer, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+		Auditor:                     auditor,
 		AppHostname:                 "*.test.coder.com",
 		DeploymentValues:            deploymentValues,
 		IncludeProvisionerDaemon:    true,
@@ -89,7 +102,7 @@ func Test_ResolveRequest(t *testing.T) {
 	me, err := client.User(ctx, codersdk.Me)
 	require.NoError(t, err)
 
-	secondUserClient, _ := coderdtest.CreateAnotherUser(t, client, firstUser.OrganizationID)
+	secondUserClient, secondUser := coderdtest.CreateAnotherUser(t, client, firstUser.OrganizationID)
 
 	agentAuthToken := uuid.NewString()
 	version := coderdtest.CreateTemplateVersion(t, client, firstUser.OrganizationID, &echo.Responses{
@@ -127,6 +140,20 @@ func Test_ResolveRequest(t *testing.T) {
 										SharingLevel: proto.AppSharingLevel_PUBLIC,
 										Url:          appURL,
 									},
+									{
+										Slug:          appNameGroup,
+										DisplayName:   appNameGroup,
+										SharingLevel:  proto.AppSharingLevel_GROUP,
+										SharingGroups: []string{groupName, "does-not-exist"},
+										Url:           appURL,
+									},
+									{
+										Slug:          appNameEveryone,
+										DisplayName:   appNameEveryone,
+										SharingLevel:  proto.AppSharingLevel_GROUP,
+										SharingGroups: []string{"Everyone"},
+										Url:           appURL,
+									},
 									{
 										Slug:         appNameInvalidURL,
 										DisplayName:  appNameInvalidURL,
@@ -271,7 +298,7 @@ func Test_ResolveRequest(t *testing.T) {
 					require.Equal(t, codersdk.DevURLSignedAppTokenCookie, cookie.Name)
 					require.Equal(t, req.BasePath, cookie.Path)
 
-					parsedToken, err := api.AppSecurityKey.VerifySignedToken(cookie.Value)
+					parsedToken, err := api.AppSecurityKeys.VerifySignedToken(cookie.Value)
 					require.NoError(t, err)
 					// normalize expiry
 					require.WithinDuration(t, token.Expiry, parsedToken.Expiry, 2*time.Second)
@@ -525,7 +552,7 @@ func Test_ResolveRequest(t *testing.T) {
 			AgentID:     agentID,
 			AppURL:      appURL,
 		}
-		badTokenStr, err := api.AppSecurityKey.SignToken(badToken)
+		badTokenStr, err := api.AppSecurityKeys.SignToken(badToken)
 		require.NoError(t, err)
 
 		req := workspaceapps.Request{
@@ -568,7 +595,7 @@ func Test_ResolveRequest(t *testing.T) {
 		require.Len(t, cookies, 1)
 		require.Equal(t, cookies[0].Name, codersdk.DevURLSignedAppTokenCookie)
 		require.NotEqual(t, cookies[0].Value, badTokenStr)
-		parsedToken, err := api.AppSecurityKey.VerifySignedToken(cookies[0].Value)
+		parsedToken, err := api.AppSecurityKeys.VerifySignedToken(cookies[0].Value)
 		require.NoError(t, err)
 		require.Equal(t, appNameOwner, parsedToken.AppSlugOrPort)
 	})
@@ -630,6 +657,151 @@ func Test_ResolveRequest(t *testing.T) {
 		require.Equal(t, "http://127.0.0.1:9090", token.AppURL)
 	})
 
+	t.Run("PortShared", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitMedium)
+		defer cancel()
+
+		// The template allows sharing with authenticated users by default.
+		_, err := client.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			AgentName:  agentName,
+			Port:       7070,
+			ShareLevel: codersdk.WorkspacePortShareLevelPublic,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		_, err = client.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			AgentName:  agentName,
+			Port:       7070,
+			ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated,
+		})
+		require.NoError(t, err)
+
+		// Shares that exceed the maximum level of the template are capped.
+		dbgen.WorkspacePortShare(t, api.Database, database.WorkspacePortShare{
+			WorkspaceID: workspace.ID,
+			AgentName:   agentName,
+			Port:        7071,
+			ShareLevel:  database.PortShareLevelPublic,
+		})
+		dbgen.WorkspacePortShare(t, api.Database, database.WorkspacePortShare{
+			WorkspaceID: workspace.ID,
+			AgentName:   agentName,
+			Port:        7072,
+			ShareLevel:  database.PortShareLevelAuthenticated,
+			ExpiresAt:   sql.NullTime{Time: database.Now().Add(-time.Minute), Valid: true},
+		})
+		group := dbgen.Group(t, api.Database, database.Group{OrganizationID: firstUser.OrganizationID})
+		dbgen.GroupMember(t, api.Database, database.GroupMember{GroupID: group.ID, UserID: secondUser.ID})
+		_, err = client.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			AgentName:  agentName,
+			Port:       7073,
+			ShareLevel: codersdk.WorkspacePortShareLevelGroup,
+			GroupID:    &group.ID,
+		})
+		require.NoError(t, err)
+		thirdUserClient, _ := coderdtest.CreateAnotherUser(t, client, firstUser.OrganizationID)
+
+		resolve := func(t *testing.T, port string, sessionToken string) bool {
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/", nil)
+			if sessionToken != "" {
+				r.Header.Set(codersdk.SessionTokenHeader, sessionToken)
+			}
+			_, ok := workspaceapps.ResolveRequest(rw, r, workspaceapps.ResolveRequestOptions{
+				Logger:              api.Logger,
+				SignedTokenProvider: api.WorkspaceAppsProvider,
+				DashboardURL:        api.AccessURL,
+				PathAppBaseURL:      api.AccessURL,
+				AppHostname:         api.AppHostname,
+				AppRequest: workspaceapps.Request{
+					AccessMethod:      workspaceapps.AccessMethodSubdomain,
+					BasePath:          "/",
+					UsernameOrID:      me.Username,
+					WorkspaceNameOrID: workspace.Name,
+					AgentNameOrID:     agentName,
+					AppSlugOrPort:     port,
+				},
+			})
+			_ = rw.Result().Body.Close()
+			return ok
+		}
+
+		require.True(t, resolve(t, "7070", secondUserClient.SessionToken()))
+		require.False(t, resolve(t, "7070", ""))
+		require.True(t, resolve(t, "7071", secondUserClient.SessionToken()))
+		require.False(t, resolve(t, "7071", ""))
+		require.False(t, resolve(t, "7072", secondUserClient.SessionToken()))
+		require.True(t, resolve(t, "7073", secondUserClient.SessionToken()))
+		require.False(t, resolve(t, "7073", thirdUserClient.SessionToken()))
+		require.False(t, resolve(t, "7074", secondUserClient.SessionToken()))
+	})
+
+	t.Run("GroupShared", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitMedium)
+		defer cancel()
+
+		group := dbgen.Group(t, api.Database, database.Group{
+			OrganizationID: firstUser.OrganizationID,
+			Name:           groupName,
+		})
+		memberClient, member := coderdtest.CreateAnotherUser(t, client, firstUser.OrganizationID)
+		dbgen.GroupMember(t, api.Database, database.GroupMember{GroupID: group.ID, UserID: member.ID})
+		otherOrg, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "other-org"})
+		require.NoError(t, err)
+		otherOrgClient, _ := coderdtest.CreateAnotherUser(t, client, otherOrg.ID)
+
+		resolve := func(t *testing.T, app string, sessionToken string) bool {
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/app", nil)
+			if sessionToken != "" {
+				r.Header.Set(codersdk.SessionTokenHeader, sessionToken)
+			}
+			_, ok := workspaceapps.ResolveRequest(rw, r, workspaceapps.ResolveRequestOptions{
+				Logger:              api.Logger,
+				SignedTokenProvider: api.WorkspaceAppsProvider,
+				DashboardURL:        api.AccessURL,
+				PathAppBaseURL:      api.AccessURL,
+				AppHostname:         api.AppHostname,
+				AppRequest: workspaceapps.Request{
+					AccessMethod:      workspaceapps.AccessMethodSubdomain,
+					BasePath:          "/",
+					UsernameOrID:      me.Username,
+					WorkspaceNameOrID: workspace.Name,
+					AgentNameOrID:     agentName,
+					AppSlugOrPort:     app,
+				},
+			})
+			_ = rw.Result().Body.Close()
+			return ok
+		}
+
+		require.True(t, resolve(t, appNameGroup, client.SessionToken()))
+		require.True(t, resolve(t, appNameGroup, memberClient.SessionToken()))
+		require.False(t, resolve(t, appNameGroup, secondUserClient.SessionToken()))
+		require.False(t, resolve(t, appNameGroup, ""))
+		require.True(t, resolve(t, appNameEveryone, secondUserClient.SessionToken()))
+		require.False(t, resolve(t, appNameEveryone, otherOrgClient.SessionToken()))
+
+		// Only access by users other than the owner is audited.
+		var connected []uuid.UUID
+		for _, alog := range auditor.AuditLogs() {
+			if alog.Action != database.AuditActionConnect || alog.ResourceID != workspace.ID {
+				continue
+			}
+			var fields audit.AdditionalFields
+			require.NoError(t, json.Unmarshal(alog.AdditionalFields, &fields))
+			if fields.App == appNameGroup || fields.App == appNameEveryone {
+				connected = append(connected, alog.UserID)
+			}
+		}
+		require.ElementsMatch(t, []uuid.UUID{member.ID, secondUser.ID}, connected)
+	})
+
 	t.Run("Terminal", func(t *testing.T) {
 		t.Parallel()
 
@@ -3,6 +3,7 @@ package workspaceapps
 import (
 	"context"
 	"fmt"
+	insecurerand "math/rand"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -83,7 +85,7 @@ type Server struct {
 
 	SignedTokenProvider SignedTokenProvider
 	WorkspaceConnCache  *wsconncache.Cache
-	AppSecurityKey      SecurityKey
+	AppSecurityKeys     *SecurityKeySet
 
 	// DisablePathApps disables path-based apps. This is a security feature as path
 	// based apps share the same cookie as the dashboard, and are susceptible to XSS
@@ -94,6 +96,13 @@ type Server struct {
 	DisablePathApps  bool
 	SecureAuthCookie bool
 
+	// AppUsage collects the usage of proxied apps. Usage is not collected if
+	// it is nil.
+	AppUsage *AppUsageCollector
+	// AccessLogSampleRate is the fraction of proxied requests that are
+	// logged, between 0 and 1.
+	AccessLogSampleRate float64
+
 	websocketWaitMutex sync.Mutex
 	websocketWaitGroup sync.WaitGroup
 }
@@ -154,7 +163,7 @@ func (s *Server) handleAPIKeySmuggling(rw http.ResponseWriter, r *http.Request,
 	}
 
 	// Exchange the encoded API key for a real one.
-	token, err := s.AppSecurityKey.DecryptAPIKey(encryptedAPIKey)
+	token, err := s.AppSecurityKeys.DecryptAPIKey(encryptedAPIKey)
 	if err != nil {
 		s.Logger.Debug(ctx, "could not decrypt smuggled workspace app API key", slog.Error(err))
 		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
@@ -518,7 +527,7 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 
 	proxy := httputil.NewSingleHostReverseProxy(appURL)
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+		site.RenderStaticErrorPage(w, r, site.ErrorPageData{
 			Status:       http.StatusBadGateway,
 			Title:        "Bad Gateway",
 			Description:  "Failed to proxy request to application: " + err.Error(),
@@ -561,7 +570,50 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 	// end span so we don't get long lived trace data
 	tracing.EndHTTPSpan(r, http.StatusOK, trace.SpanFromContext(ctx))
 
-	proxy.ServeHTTP(rw, r)
+	uw := &usageResponseWriter{ResponseWriter: rw, start: time.Now()}
+	proxy.ServeHTTP(uw, r)
+	s.recordAppRequest(ctx, r, appToken, uw)
+}
+
+// recordAppRequest collects the usage of a request proxied to an app and logs
+// a sample of the requests.
+func (s *Server) recordAppRequest(ctx context.Context, r *http.Request, appToken SignedToken, w *usageResponseWriter) {
+	// The response is written implicitly if the proxy wrote nothing.
+	w.recordHeader(http.StatusOK)
+
+	//nolint:gosec // Sampling does not need a secure source of randomness.
+	if s.AccessLogSampleRate > 0 && insecurerand.Float64() < s.AccessLogSampleRate {
+		s.Logger.Info(ctx, "workspace app request",
+			slog.F("user_id", appToken.UserID),
+			slog.F("workspace_id", appToken.WorkspaceID),
+			slog.F("agent_id", appToken.AgentID),
+			slog.F("app_slug_or_port", appToken.AppSlugOrPort),
+			slog.F("method", r.Method),
+			slog.F("path", r.URL.Path),
+			slog.F("status_code", w.status),
+			slog.F("latency_ms", w.latency.Milliseconds()),
+			slog.F("bytes_sent", w.bytesSent),
+		)
+	}
+
+	// Ports are not declared by templates, so only the usage of apps is
+	// collected.
+	if _, err := strconv.ParseUint(appToken.AppSlugOrPort, 10, 16); err == nil || s.AppUsage == nil {
+		return
+	}
+	usage := AppUsage{
+		Bucket:         w.start,
+		WorkspaceID:    appToken.WorkspaceID,
+		UserID:         appToken.UserID,
+		AppSlug:        appToken.AppSlugOrPort,
+		Requests:       1,
+		TotalLatencyMS: w.latency.Milliseconds(),
+		BytesSent:      w.bytesSent,
+	}
+	if w.status >= http.StatusInternalServerError {
+		usage.ErrorRequests = 1
+	}
+	s.AppUsage.Add(usage)
 }
 
 // workspaceAgentPTY spawns a PTY and pipes it over a WebSocket.
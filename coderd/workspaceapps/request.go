@@ -3,6 +3,7 @@ package workspaceapps
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -180,6 +181,11 @@ type databaseRequest struct {
 	// AppSharingLevel is the sharing level of the app. This is forced to be set
 	// to AppSharingLevelOwner if the access method is terminal.
 	AppSharingLevel database.AppSharingLevel
+	// AppSharingGroupIDs are the IDs of the groups that may access the app if
+	// the sharing level is AppSharingLevelGroup. Groups of apps are resolved
+	// by name, groups that don't exist in the organization of the workspace
+	// are left out.
+	AppSharingGroupIDs []uuid.UUID
 }
 
 // getDatabase does queries to get the owner user, workspace and agent
@@ -261,6 +267,7 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 		agentNameOrID         = r.AgentNameOrID
 		appURL                string
 		appSharingLevel       database.AppSharingLevel
+		appSharingGroups      []string
 		appHealth             = database.WorkspaceAppHealthDisabled
 		portUint, portUintErr = strconv.ParseUint(r.AppSlugOrPort, 10, 16)
 	)
@@ -299,6 +306,7 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 				} else {
 					appSharingLevel = database.AppSharingLevelOwner
 				}
+				appSharingGroups = app.SharingGroups
 				appURL = app.Url.String
 				appHealth = app.Health
 				break
@@ -340,17 +348,95 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 		return nil, xerrors.Errorf("parse app URL %q: %w", appURL, err)
 	}
 
+	var appSharingGroupIDs []uuid.UUID
+	if portUintErr == nil {
+		appSharingLevel, appSharingGroupIDs, err = portSharingLevel(ctx, db, workspace, agent.Name, int32(portUint))
+		if err != nil {
+			return nil, xerrors.Errorf("get port sharing level: %w", err)
+		}
+	} else if appSharingLevel == database.AppSharingLevelGroup {
+		appSharingGroupIDs, err = appSharingGroupIDsByName(ctx, db, workspace.OrganizationID, appSharingGroups)
+		if err != nil {
+			return nil, xerrors.Errorf("get app sharing groups: %w", err)
+		}
+	}
+
 	return &databaseRequest{
-		Request:         r,
-		User:            user,
-		Workspace:       workspace,
-		Agent:           agent,
-		AppURL:          appURLParsed,
-		AppHealth:       appHealth,
-		AppSharingLevel: appSharingLevel,
+		Request:            r,
+		User:               user,
+		Workspace:          workspace,
+		Agent:              agent,
+		AppURL:             appURLParsed,
+		AppHealth:          appHealth,
+		AppSharingLevel:    appSharingLevel,
+		AppSharingGroupIDs: appSharingGroupIDs,
 	}, nil
 }
 
+// appSharingGroupIDsByName resolves the groups an app is shared with. Groups
+// are referenced by name in templates, so groups that were renamed or deleted
+// since the workspace was built no longer grant access.
+func appSharingGroupIDsByName(ctx context.Context, db database.Store, organizationID uuid.UUID, names []string) ([]uuid.UUID, error) {
+	groupIDs := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		group, err := db.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+			OrganizationID: organizationID,
+			Name:           name,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("get group %q: %w", name, err)
+		}
+		groupIDs = append(groupIDs, group.ID)
+	}
+	return groupIDs, nil
+}
+
+// portSharingLevel returns the sharing level of a port that the owner shared,
+// capped by the maximum level of the template. Ports that aren't shared or
+// whose share expired are only accessible by the owner.
+func portSharingLevel(ctx context.Context, db database.Store, workspace database.Workspace, agentName string, port int32) (database.AppSharingLevel, []uuid.UUID, error) {
+	share, err := db.GetWorkspacePortShare(ctx, database.GetWorkspacePortShareParams{
+		WorkspaceID: workspace.ID,
+		AgentName:   agentName,
+		Port:        port,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.AppSharingLevelOwner, nil, nil
+	}
+	if err != nil {
+		return "", nil, xerrors.Errorf("get workspace port share: %w", err)
+	}
+	if share.ExpiresAt.Valid && !share.ExpiresAt.Time.After(database.Now()) {
+		return database.AppSharingLevelOwner, nil, nil
+	}
+
+	template, err := db.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return "", nil, xerrors.Errorf("get template: %w", err)
+	}
+	level := codersdk.WorkspacePortShareLevel(share.ShareLevel)
+	if maxLevel := codersdk.WorkspacePortShareLevel(template.MaxPortShareLevel); level.Exceeds(maxLevel) {
+		level = maxLevel
+	}
+
+	switch level {
+	case codersdk.WorkspacePortShareLevelPublic:
+		return database.AppSharingLevelPublic, nil, nil
+	case codersdk.WorkspacePortShareLevelAuthenticated:
+		return database.AppSharingLevelAuthenticated, nil, nil
+	case codersdk.WorkspacePortShareLevelGroup:
+		if !share.GroupID.Valid {
+			return database.AppSharingLevelOwner, nil, nil
+		}
+		return database.AppSharingLevelGroup, []uuid.UUID{share.GroupID.UUID}, nil
+	default:
+		return database.AppSharingLevelOwner, nil, nil
+	}
+}
+
 // getDatabaseTerminal is called by getDatabase for AccessMethodTerminal
 // requests.
 func (r Request) getDatabaseTerminal(ctx context.Context, db database.Store) (*databaseRequest, error) {
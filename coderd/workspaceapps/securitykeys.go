@@ -0,0 +1,227 @@
+package workspaceapps
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+)
+
+// SecurityKeyRotationOptions configure how often security keys are rotated.
+type SecurityKeyRotationOptions struct {
+	// Interval is how long a key is used for new tokens before it is
+	// replaced, default 30 days.
+	Interval time.Duration
+	// PropagationDelay is how long a new key is distributed to replicas and
+	// workspace proxies before it is used, default 10 minutes. It must be
+	// longer than RefreshInterval and the interval workspace proxies register
+	// at.
+	PropagationDelay time.Duration
+	// GracePeriod is how long a replaced key is still accepted, default 1
+	// hour. It must be longer than the expiry of tokens and encrypted API
+	// keys.
+	GracePeriod time.Duration
+	// RefreshInterval is how often the keys are read from the database,
+	// default 1 minute.
+	RefreshInterval time.Duration
+}
+
+func (o *SecurityKeyRotationOptions) setDefaults() {
+	if o.Interval == 0 {
+		o.Interval = 30 * 24 * time.Hour
+	}
+	if o.PropagationDelay == 0 {
+		o.PropagationDelay = 10 * time.Minute
+	}
+	if o.GracePeriod == 0 {
+		o.GracePeriod = time.Hour
+	}
+	if o.RefreshInterval == 0 {
+		o.RefreshInterval = time.Minute
+	}
+}
+
+// RefreshSecurityKeys updates the set with the security keys in the database
+// that are still accepted. A new key is generated if there is none, or if the
+// active key is due to be replaced. Replacement keys start after the
+// propagation delay, and the keys they replace expire after the grace period.
+func RefreshSecurityKeys(ctx context.Context, db database.Store, keys *SecurityKeySet, opts SecurityKeyRotationOptions) error {
+	opts.setDefaults()
+
+	var rows []database.WorkspaceAppSecurityKey
+	err := db.InTx(func(tx database.Store) error {
+		// Every replica refreshes the keys, so serialize them to avoid
+		// generating more than one replacement.
+		err := tx.AcquireLock(ctx, database.LockIDAppSecurityKeys)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+
+		now := database.Now()
+		err = tx.DeleteExpiredWorkspaceAppSecurityKeys(ctx, now)
+		if err != nil {
+			return xerrors.Errorf("delete expired keys: %w", err)
+		}
+		rows, err = tx.GetWorkspaceAppSecurityKeys(ctx, now)
+		if err != nil {
+			return xerrors.Errorf("get keys: %w", err)
+		}
+
+		active, pending := activeSecurityKey(rows, now)
+		switch {
+		case active == nil:
+			// Nothing can be verified yet, so the key is used right away.
+			_, err = insertSecurityKey(ctx, tx, now, now)
+		case pending == nil && !now.Before(active.StartsAt.Add(opts.Interval)):
+			var key database.WorkspaceAppSecurityKey
+			key, err = insertSecurityKey(ctx, tx, now, now.Add(opts.PropagationDelay))
+			if err != nil {
+				break
+			}
+			err = tx.ExpireWorkspaceAppSecurityKeys(ctx, database.ExpireWorkspaceAppSecurityKeysParams{
+				ExpiresAt: key.StartsAt.Add(opts.GracePeriod),
+				StartsAt:  key.StartsAt,
+			})
+			if err != nil {
+				err = xerrors.Errorf("expire replaced keys: %w", err)
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rows, err = tx.GetWorkspaceAppSecurityKeys(ctx, now)
+		if err != nil {
+			return xerrors.Errorf("get keys: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	active, _ := activeSecurityKey(rows, database.Now())
+	if active == nil {
+		return xerrors.New("no active security key in the database")
+	}
+	decoded := make(map[string]SecurityKey, len(rows))
+	for _, row := range rows {
+		key, err := KeyFromString(row.Secret)
+		if err != nil {
+			return xerrors.Errorf("decode key %s: %w", row.ID, err)
+		}
+		decoded[row.ID.String()] = key
+	}
+	return keys.Update(active.ID.String(), decoded)
+}
+
+// activeSecurityKey returns the most recent key that has started and the most
+// recent key that has not started yet. Keys must be sorted by start time,
+// most recent first.
+func activeSecurityKey(keys []database.WorkspaceAppSecurityKey, now time.Time) (active, pending *database.WorkspaceAppSecurityKey) {
+	for i := range keys {
+		key := &keys[i]
+		if key.StartsAt.After(now) {
+			if pending == nil {
+				pending = key
+			}
+			continue
+		}
+		return key, pending
+	}
+	return nil, pending
+}
+
+func insertSecurityKey(ctx context.Context, db database.Store, now, startsAt time.Time) (database.WorkspaceAppSecurityKey, error) {
+	var secret SecurityKey
+	_, err := rand.Read(secret[:])
+	if err != nil {
+		return database.WorkspaceAppSecurityKey{}, xerrors.Errorf("generate key: %w", err)
+	}
+	key, err := db.InsertWorkspaceAppSecurityKey(ctx, database.InsertWorkspaceAppSecurityKeyParams{
+		ID:        uuid.New(),
+		Secret:    hex.EncodeToString(secret[:]),
+		CreatedAt: now,
+		StartsAt:  startsAt,
+	})
+	if err != nil {
+		return database.WorkspaceAppSecurityKey{}, xerrors.Errorf("insert key: %w", err)
+	}
+	return key, nil
+}
+
+// SecurityKeyRefresher refreshes a set of security keys from the database in
+// the background, rotating the keys when they are due.
+type SecurityKeyRefresher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	db     database.Store
+	log    slog.Logger
+	keys   *SecurityKeySet
+	opts   SecurityKeyRotationOptions
+
+	closed chan struct{}
+}
+
+// NewSecurityKeyRefresher starts refreshing the keys in the set. It is the
+// caller's responsibility to call Close on the returned instance.
+func NewSecurityKeyRefresher(db database.Store, log slog.Logger, keys *SecurityKeySet, opts SecurityKeyRotationOptions) *SecurityKeyRefresher {
+	opts.setDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // Security keys are only readable by the system.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	r := &SecurityKeyRefresher{
+		ctx:    ctx,
+		cancel: cancel,
+		db:     db,
+		log:    log,
+		keys:   keys,
+		opts:   opts,
+		closed: make(chan struct{}),
+	}
+	go r.start()
+	return r
+}
+
+func (r *SecurityKeyRefresher) start() {
+	defer close(r.closed)
+
+	t := time.NewTicker(r.opts.RefreshInterval)
+	defer t.Stop()
+	for {
+		activeID := r.keys.ActiveKeyID()
+		err := RefreshSecurityKeys(r.ctx, r.db, r.keys, r.opts)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			r.log.Error(r.ctx, "refresh workspace app security keys", slog.Error(err))
+		} else if newID := r.keys.ActiveKeyID(); newID != activeID {
+			r.log.Info(r.ctx, "workspace app security key rotated", slog.F("key_id", newID))
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Close stops refreshing the keys.
+func (r *SecurityKeyRefresher) Close() error {
+	r.cancel()
+	<-r.closed
+	return nil
+}
@@ -0,0 +1,89 @@
+package workspaceapps_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/workspaceapps"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRefreshSecurityKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Generate", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := dbfake.New()
+		keys := &workspaceapps.SecurityKeySet{}
+
+		err := workspaceapps.RefreshSecurityKeys(ctx, db, keys, workspaceapps.SecurityKeyRotationOptions{})
+		require.NoError(t, err)
+		activeID, set := keys.Keys()
+		require.NotEmpty(t, activeID)
+		require.Len(t, set, 1)
+
+		// The key is not replaced until it is due.
+		err = workspaceapps.RefreshSecurityKeys(ctx, db, keys, workspaceapps.SecurityKeyRotationOptions{})
+		require.NoError(t, err)
+		newActiveID, set := keys.Keys()
+		require.Equal(t, activeID, newActiveID)
+		require.Len(t, set, 1)
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := dbfake.New()
+		keys := &workspaceapps.SecurityKeySet{}
+
+		err := workspaceapps.RefreshSecurityKeys(ctx, db, keys, workspaceapps.SecurityKeyRotationOptions{})
+		require.NoError(t, err)
+		oldID := keys.ActiveKeyID()
+		token, err := keys.SignToken(workspaceapps.SignedToken{})
+		require.NoError(t, err)
+
+		// The replacement is distributed before it is used.
+		opts := workspaceapps.SecurityKeyRotationOptions{
+			Interval:         time.Nanosecond,
+			PropagationDelay: 100 * time.Millisecond,
+			GracePeriod:      time.Hour,
+		}
+		err = workspaceapps.RefreshSecurityKeys(ctx, db, keys, opts)
+		require.NoError(t, err)
+		activeID, set := keys.Keys()
+		require.Equal(t, oldID, activeID)
+		require.Len(t, set, 2)
+
+		// Only one replacement is generated at a time.
+		err = workspaceapps.RefreshSecurityKeys(ctx, db, keys, opts)
+		require.NoError(t, err)
+		_, set = keys.Keys()
+		require.Len(t, set, 2)
+
+		rows, err := db.GetWorkspaceAppSecurityKeys(ctx, database.Now())
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		newID := rows[0].ID.String()
+		require.False(t, rows[0].ExpiresAt.Valid)
+		require.Equal(t, oldID, rows[1].ID.String())
+		require.True(t, rows[1].ExpiresAt.Valid)
+		require.Equal(t, rows[0].StartsAt.Add(time.Hour), rows[1].ExpiresAt.Time)
+
+		// The replacement becomes active once it starts, and the old key is
+		// accepted until it expires.
+		require.Eventually(t, func() bool {
+			err := workspaceapps.RefreshSecurityKeys(ctx, db, keys, workspaceapps.SecurityKeyRotationOptions{})
+			return err == nil && keys.ActiveKeyID() == newID
+		}, testutil.WaitShort, testutil.IntervalFast)
+		_, err = keys.VerifySignedToken(token)
+		require.NoError(t, err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-jose/go-jose/v3"
@@ -80,10 +81,98 @@ func KeyFromString(str string) (SecurityKey, error) {
 	return key, nil
 }
 
-// SignToken generates a signed workspace app token with the given payload. If
-// the payload doesn't have an expiry, it will be set to the current time plus
-// the default expiry.
-func (k SecurityKey) SignToken(payload SignedToken) (string, error) {
+// SecurityKeySet is the set of security keys that are currently accepted.
+// Tokens and API keys are signed and encrypted with the active key, and the ID
+// of the key is stored in their header so they can be verified and decrypted
+// with any key in the set. This allows rotating the active key without
+// invalidating everything that was signed with the previous key.
+//
+// The zero value is an empty set. It is safe for concurrent use.
+type SecurityKeySet struct {
+	mu       sync.RWMutex
+	activeID string
+	keys     map[string]SecurityKey
+}
+
+// NewSecurityKeySet returns a set that only contains the given key.
+func NewSecurityKeySet(id string, key SecurityKey) *SecurityKeySet {
+	return &SecurityKeySet{
+		activeID: id,
+		keys:     map[string]SecurityKey{id: key},
+	}
+}
+
+// Update replaces the keys in the set. The active key must be one of the keys.
+func (s *SecurityKeySet) Update(activeID string, keys map[string]SecurityKey) error {
+	if _, ok := keys[activeID]; !ok {
+		return xerrors.Errorf("active key %q is not in the set", activeID)
+	}
+	copied := make(map[string]SecurityKey, len(keys))
+	for id, key := range keys {
+		copied[id] = key
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeID = activeID
+	s.keys = copied
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key used for new tokens and API keys.
+func (s *SecurityKeySet) ActiveKeyID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.activeID
+}
+
+// Keys returns the ID of the active key and a copy of the keys in the set by
+// ID.
+func (s *SecurityKeySet) Keys() (string, map[string]SecurityKey) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make(map[string]SecurityKey, len(s.keys))
+	for id, key := range s.keys {
+		keys[id] = key
+	}
+	return s.activeID, keys
+}
+
+func (s *SecurityKeySet) active() (string, SecurityKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[s.activeID]
+	if !ok {
+		return "", SecurityKey{}, xerrors.New("no active security key")
+	}
+	return s.activeID, key, nil
+}
+
+// LegacySecurityKeyID is the ID of the key that signed tokens before keys
+// could be rotated. Those tokens don't have a key ID in their header.
+const LegacySecurityKeyID = "00000000-0000-0000-0000-000000000000"
+
+func (s *SecurityKeySet) key(id string) (SecurityKey, error) {
+	if id == "" {
+		id = LegacySecurityKeyID
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return SecurityKey{}, xerrors.Errorf("unknown security key %q", id)
+	}
+	return key, nil
+}
+
+// SignToken generates a signed workspace app token with the given payload
+// using the active key. If the payload doesn't have an expiry, it will be set
+// to the current time plus the default expiry.
+func (s *SecurityKeySet) SignToken(payload SignedToken) (string, error) {
+	keyID, key, err := s.active()
+	if err != nil {
+		return "", err
+	}
 	if payload.Expiry.IsZero() {
 		payload.Expiry = time.Now().Add(DefaultTokenExpiry)
 	}
@@ -94,8 +183,8 @@ func (k SecurityKey) SignToken(payload SignedToken) (string, error) {
 
 	signer, err := jose.NewSigner(jose.SigningKey{
 		Algorithm: tokenSigningAlgorithm,
-		Key:       k.signingKey(),
-	}, nil)
+		Key:       key.signingKey(),
+	}, (&jose.SignerOptions{}).WithHeader("kid", keyID))
 	if err != nil {
 		return "", xerrors.Errorf("create signer: %w", err)
 	}
@@ -113,10 +202,10 @@ func (k SecurityKey) SignToken(payload SignedToken) (string, error) {
 	return serialized, nil
 }
 
-// VerifySignedToken parses a signed workspace app token with the given key and
-// returns the payload. If the token is invalid or expired, an error is
-// returned.
-func (k SecurityKey) VerifySignedToken(str string) (SignedToken, error) {
+// VerifySignedToken parses a signed workspace app token with the key it was
+// signed with and returns the payload. If the token is invalid, expired or
+// signed with a key that is not in the set, an error is returned.
+func (s *SecurityKeySet) VerifySignedToken(str string) (SignedToken, error) {
 	object, err := jose.ParseSigned(str)
 	if err != nil {
 		return SignedToken{}, xerrors.Errorf("parse JWS: %w", err)
@@ -128,7 +217,12 @@ func (k SecurityKey) VerifySignedToken(str string) (SignedToken, error) {
 		return SignedToken{}, xerrors.Errorf("expected token signing algorithm to be %q, got %q", tokenSigningAlgorithm, object.Signatures[0].Header.Algorithm)
 	}
 
-	output, err := object.Verify(k.signingKey())
+	key, err := s.key(object.Signatures[0].Header.KeyID)
+	if err != nil {
+		return SignedToken{}, err
+	}
+
+	output, err := object.Verify(key.signingKey())
 	if err != nil {
 		return SignedToken{}, xerrors.Errorf("verify JWS: %w", err)
 	}
@@ -150,8 +244,13 @@ type EncryptedAPIKeyPayload struct {
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
-// EncryptAPIKey encrypts an API key for subdomain token smuggling.
-func (k SecurityKey) EncryptAPIKey(payload EncryptedAPIKeyPayload) (string, error) {
+// EncryptAPIKey encrypts an API key for subdomain token smuggling using the
+// active key.
+func (s *SecurityKeySet) EncryptAPIKey(payload EncryptedAPIKeyPayload) (string, error) {
+	keyID, key, err := s.active()
+	if err != nil {
+		return "", err
+	}
 	if payload.APIKey == "" {
 		return "", xerrors.New("API key is empty")
 	}
@@ -171,7 +270,8 @@ func (k SecurityKey) EncryptAPIKey(payload EncryptedAPIKeyPayload) (string, erro
 		jose.A256GCM,
 		jose.Recipient{
 			Algorithm: apiKeyEncryptionAlgorithm,
-			Key:       k.encryptionKey(),
+			Key:       key.encryptionKey(),
+			KeyID:     keyID,
 		},
 		&jose.EncrypterOptions{
 			Compression: jose.DEFLATE,
@@ -190,7 +290,7 @@ func (k SecurityKey) EncryptAPIKey(payload EncryptedAPIKeyPayload) (string, erro
 }
 
 // DecryptAPIKey undoes EncryptAPIKey and is used in the subdomain app handler.
-func (k SecurityKey) DecryptAPIKey(encryptedAPIKey string) (string, error) {
+func (s *SecurityKeySet) DecryptAPIKey(encryptedAPIKey string) (string, error) {
 	encrypted, err := base64.RawURLEncoding.DecodeString(encryptedAPIKey)
 	if err != nil {
 		return "", xerrors.Errorf("base64 decode encrypted API key: %w", err)
@@ -204,8 +304,13 @@ func (k SecurityKey) DecryptAPIKey(encryptedAPIKey string) (string, error) {
 		return "", xerrors.Errorf("expected API key encryption algorithm to be %q, got %q", apiKeyEncryptionAlgorithm, object.Header.Algorithm)
 	}
 
+	key, err := s.key(object.Header.KeyID)
+	if err != nil {
+		return "", err
+	}
+
 	// Decrypt using the hashed secret.
-	decrypted, err := object.Decrypt(k.encryptionKey())
+	decrypted, err := object.Decrypt(key.encryptionKey())
 	if err != nil {
 		return "", xerrors.Errorf("decrypt API key: %w", err)
 	}
@@ -226,7 +331,7 @@ func (k SecurityKey) DecryptAPIKey(encryptedAPIKey string) (string, error) {
 
 // FromRequest returns the signed token from the request, if it exists and is
 // valid. The caller must check that the token matches the request.
-func FromRequest(r *http.Request, key SecurityKey) (*SignedToken, bool) {
+func FromRequest(r *http.Request, keys *SecurityKeySet) (*SignedToken, bool) {
 	// Get the token string from the request. We usually use a cookie for this,
 	// but for web terminal we also support a query parameter to support
 	// cross-domain terminal access.
@@ -239,7 +344,7 @@ func FromRequest(r *http.Request, key SecurityKey) (*SignedToken, bool) {
 	}
 
 	if tokenStr != "" {
-		token, err := key.VerifySignedToken(tokenStr)
+		token, err := keys.VerifySignedToken(tokenStr)
 		if err == nil {
 			req := token.Request.Normalize()
 			if cookieErr != nil && req.AccessMethod != AccessMethodTerminal {
@@ -1,6 +1,7 @@
 package workspaceapps_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -160,13 +161,15 @@ func Test_TokenMatchesRequest(t *testing.T) {
 	}
 }
 
+var appSecurityKeys = workspaceapps.NewSecurityKeySet(coderdtest.AppSecurityKeyID, coderdtest.AppSecurityKey)
+
 func Test_GenerateToken(t *testing.T) {
 	t.Parallel()
 
 	t.Run("SetExpiry", func(t *testing.T) {
 		t.Parallel()
 
-		tokenStr, err := coderdtest.AppSecurityKey.SignToken(workspaceapps.SignedToken{
+		tokenStr, err := appSecurityKeys.SignToken(workspaceapps.SignedToken{
 			Request: workspaceapps.Request{
 				AccessMethod:      workspaceapps.AccessMethodPath,
 				BasePath:          "/app",
@@ -184,7 +187,7 @@ func Test_GenerateToken(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		token, err := coderdtest.AppSecurityKey.VerifySignedToken(tokenStr)
+		token, err := appSecurityKeys.VerifySignedToken(tokenStr)
 		require.NoError(t, err)
 
 		require.WithinDuration(t, time.Now().Add(time.Minute), token.Expiry, 15*time.Second)
@@ -262,13 +265,13 @@ func Test_GenerateToken(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			t.Parallel()
 
-			str, err := coderdtest.AppSecurityKey.SignToken(c.token)
+			str, err := appSecurityKeys.SignToken(c.token)
 			require.NoError(t, err)
 
 			// Tokens aren't deterministic as they have a random nonce, so we
 			// can't compare them directly.
 
-			token, err := coderdtest.AppSecurityKey.VerifySignedToken(str)
+			token, err := appSecurityKeys.VerifySignedToken(str)
 			if c.parseErrContains != "" {
 				require.Error(t, err)
 				require.ErrorContains(t, err, c.parseErrContains)
@@ -291,7 +294,7 @@ func Test_ParseToken(t *testing.T) {
 	t.Run("InvalidJWS", func(t *testing.T) {
 		t.Parallel()
 
-		token, err := coderdtest.AppSecurityKey.VerifySignedToken("invalid")
+		token, err := appSecurityKeys.VerifySignedToken("invalid")
 		require.Error(t, err)
 		require.ErrorContains(t, err, "parse JWS")
 		require.Equal(t, workspaceapps.SignedToken{}, token)
@@ -308,7 +311,8 @@ func Test_ParseToken(t *testing.T) {
 		}
 		require.NotEqual(t, coderdtest.AppSecurityKey, otherKey)
 
-		tokenStr, err := otherKey.SignToken(workspaceapps.SignedToken{
+		otherKeys := workspaceapps.NewSecurityKeySet(coderdtest.AppSecurityKeyID, otherKey)
+		tokenStr, err := otherKeys.SignToken(workspaceapps.SignedToken{
 			Request: workspaceapps.Request{
 				AccessMethod:      workspaceapps.AccessMethodPath,
 				BasePath:          "/app",
@@ -327,7 +331,7 @@ func Test_ParseToken(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verify the token is invalid.
-		token, err := coderdtest.AppSecurityKey.VerifySignedToken(tokenStr)
+		token, err := appSecurityKeys.VerifySignedToken(tokenStr)
 		require.Error(t, err)
 		require.ErrorContains(t, err, "verify JWS")
 		require.Equal(t, workspaceapps.SignedToken{}, token)
@@ -337,14 +341,17 @@ func Test_ParseToken(t *testing.T) {
 		t.Parallel()
 
 		// Create a signature for an invalid body.
-		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS512, Key: coderdtest.AppSecurityKey[:64]}, nil)
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.HS512, Key: coderdtest.AppSecurityKey[:64]},
+			(&jose.SignerOptions{}).WithHeader("kid", coderdtest.AppSecurityKeyID),
+		)
 		require.NoError(t, err)
 		signedObject, err := signer.Sign([]byte("hi"))
 		require.NoError(t, err)
 		serialized, err := signedObject.CompactSerialize()
 		require.NoError(t, err)
 
-		token, err := coderdtest.AppSecurityKey.VerifySignedToken(serialized)
+		token, err := appSecurityKeys.VerifySignedToken(serialized)
 		require.Error(t, err)
 		require.ErrorContains(t, err, "unmarshal payload")
 		require.Equal(t, workspaceapps.SignedToken{}, token)
@@ -365,12 +372,12 @@ func TestAPIKeyEncryption(t *testing.T) {
 		t.Parallel()
 
 		key := genAPIKey(t)
-		encrypted, err := coderdtest.AppSecurityKey.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
+		encrypted, err := appSecurityKeys.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
 			APIKey: key,
 		})
 		require.NoError(t, err)
 
-		decryptedKey, err := coderdtest.AppSecurityKey.DecryptAPIKey(encrypted)
+		decryptedKey, err := appSecurityKeys.DecryptAPIKey(encrypted)
 		require.NoError(t, err)
 		require.Equal(t, key, decryptedKey)
 	})
@@ -382,13 +389,13 @@ func TestAPIKeyEncryption(t *testing.T) {
 			t.Parallel()
 
 			key := genAPIKey(t)
-			encrypted, err := coderdtest.AppSecurityKey.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
+			encrypted, err := appSecurityKeys.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
 				APIKey:    key,
 				ExpiresAt: database.Now().Add(-1 * time.Hour),
 			})
 			require.NoError(t, err)
 
-			decryptedKey, err := coderdtest.AppSecurityKey.DecryptAPIKey(encrypted)
+			decryptedKey, err := appSecurityKeys.DecryptAPIKey(encrypted)
 			require.Error(t, err)
 			require.ErrorContains(t, err, "expired")
 			require.Empty(t, decryptedKey)
@@ -407,16 +414,106 @@ func TestAPIKeyEncryption(t *testing.T) {
 
 			// Encrypt with the other key.
 			key := genAPIKey(t)
-			encrypted, err := otherKey.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
+			otherKeys := workspaceapps.NewSecurityKeySet(coderdtest.AppSecurityKeyID, otherKey)
+			encrypted, err := otherKeys.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{
 				APIKey: key,
 			})
 			require.NoError(t, err)
 
 			// Decrypt with the original key.
-			decryptedKey, err := coderdtest.AppSecurityKey.DecryptAPIKey(encrypted)
+			decryptedKey, err := appSecurityKeys.DecryptAPIKey(encrypted)
 			require.Error(t, err)
 			require.ErrorContains(t, err, "decrypt API key")
 			require.Empty(t, decryptedKey)
 		})
 	})
 }
+
+func TestSecurityKeySet(t *testing.T) {
+	t.Parallel()
+
+	oldID := uuid.NewString()
+	var oldKey workspaceapps.SecurityKey
+	copy(oldKey[:], coderdtest.AppSecurityKey[:])
+	for i := range oldKey {
+		oldKey[i] ^= 0xff
+	}
+	keys := workspaceapps.NewSecurityKeySet(oldID, oldKey)
+
+	token := workspaceapps.SignedToken{
+		Request: workspaceapps.Request{
+			AccessMethod:      workspaceapps.AccessMethodPath,
+			BasePath:          "/app",
+			UsernameOrID:      "foo",
+			WorkspaceNameOrID: "bar",
+			AgentNameOrID:     "baz",
+			AppSlugOrPort:     "qux",
+		},
+		Expiry: time.Now().Add(time.Hour),
+	}
+	oldToken, err := keys.SignToken(token)
+	require.NoError(t, err)
+	oldAPIKey, err := keys.EncryptAPIKey(workspaceapps.EncryptedAPIKeyPayload{APIKey: "key"})
+	require.NoError(t, err)
+
+	// The replaced key is still accepted during the grace period.
+	err = keys.Update(coderdtest.AppSecurityKeyID, map[string]workspaceapps.SecurityKey{
+		oldID:                       oldKey,
+		coderdtest.AppSecurityKeyID: coderdtest.AppSecurityKey,
+	})
+	require.NoError(t, err)
+	require.Equal(t, coderdtest.AppSecurityKeyID, keys.ActiveKeyID())
+	_, err = keys.VerifySignedToken(oldToken)
+	require.NoError(t, err)
+	decrypted, err := keys.DecryptAPIKey(oldAPIKey)
+	require.NoError(t, err)
+	require.Equal(t, "key", decrypted)
+
+	// New tokens are signed with the active key.
+	newToken, err := keys.SignToken(token)
+	require.NoError(t, err)
+	_, err = appSecurityKeys.VerifySignedToken(newToken)
+	require.NoError(t, err)
+
+	// Once the key expires, tokens signed with it are rejected.
+	err = keys.Update(coderdtest.AppSecurityKeyID, map[string]workspaceapps.SecurityKey{
+		coderdtest.AppSecurityKeyID: coderdtest.AppSecurityKey,
+	})
+	require.NoError(t, err)
+	_, err = keys.VerifySignedToken(oldToken)
+	require.ErrorContains(t, err, "unknown security key")
+	_, err = keys.DecryptAPIKey(oldAPIKey)
+	require.ErrorContains(t, err, "unknown security key")
+
+	err = keys.Update(oldID, map[string]workspaceapps.SecurityKey{
+		coderdtest.AppSecurityKeyID: coderdtest.AppSecurityKey,
+	})
+	require.ErrorContains(t, err, "is not in the set")
+}
+
+func TestSecurityKeySetLegacyKey(t *testing.T) {
+	t.Parallel()
+
+	// Tokens signed before keys could be rotated don't have a key ID, and
+	// are verified with the key that was migrated from the previous version.
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS512, Key: coderdtest.AppSecurityKey[:64]},
+		nil,
+	)
+	require.NoError(t, err)
+	payload, err := json.Marshal(workspaceapps.SignedToken{
+		Expiry: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	signedObject, err := signer.Sign(payload)
+	require.NoError(t, err)
+	serialized, err := signedObject.CompactSerialize()
+	require.NoError(t, err)
+
+	_, err = appSecurityKeys.VerifySignedToken(serialized)
+	require.ErrorContains(t, err, "unknown security key")
+
+	keys := workspaceapps.NewSecurityKeySet(workspaceapps.LegacySecurityKeyID, coderdtest.AppSecurityKey)
+	_, err = keys.VerifySignedToken(serialized)
+	require.NoError(t, err)
+}
@@ -0,0 +1,248 @@
+package workspaceapps
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// AppUsage is the usage of a workspace app by a user within an hour.
+type AppUsage struct {
+	// Bucket is the start of the hour the requests were made in.
+	Bucket      time.Time `json:"bucket" format:"date-time"`
+	WorkspaceID uuid.UUID `json:"workspace_id" format:"uuid"`
+	// UserID is the nil UUID for unauthenticated requests to public apps.
+	UserID   uuid.UUID `json:"user_id" format:"uuid"`
+	AppSlug  string    `json:"app_slug"`
+	Requests int64     `json:"requests"`
+	// ErrorRequests is the number of requests that failed with a 5xx status.
+	ErrorRequests int64 `json:"error_requests"`
+	// TotalLatencyMS is the sum of the time to the response headers of all
+	// requests.
+	TotalLatencyMS int64 `json:"total_latency_ms"`
+	// BytesSent is the sum of the response body bytes sent to the user. Data
+	// sent over upgraded connections, such as WebSockets, is not included.
+	BytesSent int64 `json:"bytes_sent"`
+}
+
+type appUsageKey struct {
+	bucket      time.Time
+	workspaceID uuid.UUID
+	userID      uuid.UUID
+	appSlug     string
+}
+
+func (u AppUsage) key() appUsageKey {
+	return appUsageKey{
+		bucket:      u.Bucket,
+		workspaceID: u.WorkspaceID,
+		userID:      u.UserID,
+		appSlug:     u.AppSlug,
+	}
+}
+
+// UpsertAppUsage adds the usage to the rollups in the database.
+func UpsertAppUsage(ctx context.Context, db database.Store, usage []AppUsage) error {
+	return db.InTx(func(tx database.Store) error {
+		for _, u := range usage {
+			err := tx.UpsertWorkspaceAppUsageRollup(ctx, database.UpsertWorkspaceAppUsageRollupParams{
+				Bucket:         u.Bucket,
+				WorkspaceID:    u.WorkspaceID,
+				UserID:         u.UserID,
+				AppSlug:        u.AppSlug,
+				Requests:       u.Requests,
+				ErrorRequests:  u.ErrorRequests,
+				TotalLatencyMs: u.TotalLatencyMS,
+				BytesSent:      u.BytesSent,
+			})
+			if err != nil {
+				return xerrors.Errorf("upsert usage of app %q in workspace %s: %w", u.AppSlug, u.WorkspaceID, err)
+			}
+		}
+		return nil
+	}, nil)
+}
+
+// AppUsageFlushFunc stores usage collected by an AppUsageCollector.
+type AppUsageFlushFunc func(ctx context.Context, usage []AppUsage) error
+
+// AppUsageCollector aggregates the usage of workspace apps in memory and
+// flushes it periodically, so the usage of many requests is stored at once.
+type AppUsageCollector struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	log      slog.Logger
+	flush    AppUsageFlushFunc
+	interval time.Duration
+
+	mu    sync.Mutex
+	usage map[appUsageKey]AppUsage
+
+	closed chan struct{}
+}
+
+// NewAppUsageCollector starts flushing collected usage every interval. It is
+// the caller's responsibility to call Close on the returned instance.
+func NewAppUsageCollector(log slog.Logger, flush AppUsageFlushFunc, interval time.Duration) *AppUsageCollector {
+	if interval == 0 {
+		interval = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &AppUsageCollector{
+		ctx:      ctx,
+		cancel:   cancel,
+		log:      log,
+		flush:    flush,
+		interval: interval,
+		usage:    make(map[appUsageKey]AppUsage),
+		closed:   make(chan struct{}),
+	}
+	go c.start()
+	return c
+}
+
+// Add adds the usage to the usage collected in the same hour for the same
+// workspace, user and app.
+func (c *AppUsageCollector) Add(usage AppUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usage.Bucket = usage.Bucket.UTC().Truncate(time.Hour)
+	k := usage.key()
+	existing, ok := c.usage[k]
+	if ok {
+		usage.Requests += existing.Requests
+		usage.ErrorRequests += existing.ErrorRequests
+		usage.TotalLatencyMS += existing.TotalLatencyMS
+		usage.BytesSent += existing.BytesSent
+	}
+	c.usage[k] = usage
+}
+
+// Flush flushes the usage collected so far. Usage that fails to flush is kept
+// and flushed again with the next flush.
+func (c *AppUsageCollector) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	usage := make([]AppUsage, 0, len(c.usage))
+	for _, u := range c.usage {
+		usage = append(usage, u)
+	}
+	c.usage = make(map[appUsageKey]AppUsage)
+	c.mu.Unlock()
+
+	if len(usage) == 0 {
+		return nil
+	}
+	err := c.flush(ctx, usage)
+	if err != nil {
+		for _, u := range usage {
+			c.Add(u)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *AppUsageCollector) start() {
+	defer close(c.closed)
+
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		err := c.Flush(c.ctx)
+		if err != nil && c.ctx.Err() == nil {
+			c.log.Error(c.ctx, "flush workspace app usage", slog.Error(err))
+		}
+	}
+}
+
+// Close stops flushing periodically and flushes the remaining usage.
+func (c *AppUsageCollector) Close() error {
+	c.cancel()
+	<-c.closed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return c.Flush(ctx)
+}
+
+// usageResponseWriter records the status and the body size of a response
+// proxied to a workspace app.
+type usageResponseWriter struct {
+	http.ResponseWriter
+
+	start time.Time
+	// latency is the time until the response headers were written.
+	latency     time.Duration
+	status      int
+	bytesSent   int64
+	wroteHeader bool
+}
+
+var (
+	_ http.ResponseWriter = (*usageResponseWriter)(nil)
+	_ http.Hijacker       = (*usageResponseWriter)(nil)
+	_ http.Flusher        = (*usageResponseWriter)(nil)
+)
+
+func (w *usageResponseWriter) recordHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+	w.latency = time.Since(w.start)
+}
+
+func (w *usageResponseWriter) WriteHeader(status int) {
+	// Informational responses are followed by the final response.
+	if status >= http.StatusOK || status == http.StatusSwitchingProtocols {
+		w.recordHeader(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *usageResponseWriter) Write(b []byte) (int, error) {
+	w.recordHeader(http.StatusOK)
+	n, err := w.ResponseWriter.Write(b)
+	w.bytesSent += int64(n)
+	return n, err
+}
+
+func (w *usageResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *usageResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, xerrors.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+	// Upgraded connections are proxied without writing the headers through
+	// the response writer.
+	w.recordHeader(http.StatusSwitchingProtocols)
+	return hijacker.Hijack()
+}
+
+func (w *usageResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,150 @@
+package workspaceapps_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/workspaceapps"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAppUsageCollector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Merge", func(t *testing.T) {
+		t.Parallel()
+
+		var flushed []workspaceapps.AppUsage
+		collector := workspaceapps.NewAppUsageCollector(slogtest.Make(t, nil), func(_ context.Context, usage []workspaceapps.AppUsage) error {
+			flushed = append(flushed, usage...)
+			return nil
+		}, time.Hour)
+		defer collector.Close()
+
+		hour := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+		usage := workspaceapps.AppUsage{
+			Bucket:         hour.Add(5 * time.Minute),
+			WorkspaceID:    uuid.New(),
+			UserID:         uuid.New(),
+			AppSlug:        "code-server",
+			Requests:       1,
+			TotalLatencyMS: 10,
+			BytesSent:      100,
+		}
+		collector.Add(usage)
+		usage.Bucket = hour.Add(55 * time.Minute)
+		usage.ErrorRequests = 1
+		collector.Add(usage)
+		// Usage in another hour is kept separately.
+		usage.Bucket = hour.Add(time.Hour)
+		collector.Add(usage)
+
+		err := collector.Flush(context.Background())
+		require.NoError(t, err)
+		require.Len(t, flushed, 2)
+		if flushed[0].Bucket.After(flushed[1].Bucket) {
+			flushed[0], flushed[1] = flushed[1], flushed[0]
+		}
+		require.Equal(t, hour, flushed[0].Bucket)
+		require.EqualValues(t, 2, flushed[0].Requests)
+		require.EqualValues(t, 1, flushed[0].ErrorRequests)
+		require.EqualValues(t, 20, flushed[0].TotalLatencyMS)
+		require.EqualValues(t, 200, flushed[0].BytesSent)
+		require.Equal(t, hour.Add(time.Hour), flushed[1].Bucket)
+		require.EqualValues(t, 1, flushed[1].Requests)
+
+		// Flushed usage is not flushed again.
+		flushed = nil
+		err = collector.Flush(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, flushed)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+
+		fail := true
+		var flushed []workspaceapps.AppUsage
+		collector := workspaceapps.NewAppUsageCollector(slogtest.Make(t, nil), func(_ context.Context, usage []workspaceapps.AppUsage) error {
+			if fail {
+				return xerrors.New("database unavailable")
+			}
+			flushed = append(flushed, usage...)
+			return nil
+		}, time.Hour)
+		defer collector.Close()
+
+		usage := workspaceapps.AppUsage{
+			Bucket:      time.Now(),
+			WorkspaceID: uuid.New(),
+			AppSlug:     "code-server",
+			Requests:    1,
+		}
+		collector.Add(usage)
+		err := collector.Flush(context.Background())
+		require.Error(t, err)
+
+		collector.Add(usage)
+		fail = false
+		err = collector.Flush(context.Background())
+		require.NoError(t, err)
+		require.Len(t, flushed, 1)
+		require.EqualValues(t, 2, flushed[0].Requests)
+	})
+
+	t.Run("Periodic", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu      sync.Mutex
+			flushed []workspaceapps.AppUsage
+		)
+		collector := workspaceapps.NewAppUsageCollector(slogtest.Make(t, nil), func(_ context.Context, usage []workspaceapps.AppUsage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			flushed = append(flushed, usage...)
+			return nil
+		}, testutil.IntervalFast)
+		defer collector.Close()
+
+		collector.Add(workspaceapps.AppUsage{
+			Bucket:      time.Now(),
+			WorkspaceID: uuid.New(),
+			AppSlug:     "code-server",
+			Requests:    1,
+		})
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(flushed) == 1
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+
+	t.Run("FlushOnClose", func(t *testing.T) {
+		t.Parallel()
+
+		var flushed []workspaceapps.AppUsage
+		collector := workspaceapps.NewAppUsageCollector(slogtest.Make(t, nil), func(_ context.Context, usage []workspaceapps.AppUsage) error {
+			flushed = append(flushed, usage...)
+			return nil
+		}, time.Hour)
+
+		collector.Add(workspaceapps.AppUsage{
+			Bucket:      time.Now(),
+			WorkspaceID: uuid.New(),
+			AppSlug:     "code-server",
+			Requests:    1,
+		})
+		err := collector.Close()
+		require.NoError(t, err)
+		require.Len(t, flushed, 1)
+	})
+}
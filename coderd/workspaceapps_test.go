@@ -266,6 +266,7 @@ func TestWorkspaceApps(t *testing.T) {
 			DeploymentValues:         deploymentValues,
 			AppHostname:              opts.AppHost,
 			IncludeProvisionerDaemon: true,
+			AppUsageFlushInterval:    testutil.IntervalFast,
 			RealIPConfig: &httpmw.RealIPConfig{
 				TrustedOrigins: []*net.IPNet{{
 					IP:   net.ParseIP("127.0.0.1"),
@@ -287,3 +288,56 @@ func TestWorkspaceApps(t *testing.T) {
 		}
 	})
 }
+
+func TestWorkspaceApplicationAuthServiceAccount(t *testing.T) {
+	t.Parallel()
+
+	accessURL, err := url.Parse("https://test.coder.com")
+	require.NoError(t, err)
+	client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+		AccessURL:   accessURL,
+		AppHostname: "*.test.coder.com",
+	})
+	firstUser := coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	account, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+		OrganizationID: firstUser.OrganizationID,
+		Email:          "ci@coder.com",
+		Username:       "ci",
+		ServiceAccount: true,
+	})
+	require.NoError(t, err)
+	token, err := client.CreateToken(ctx, account.ID.String(), codersdk.CreateTokenRequest{
+		Scope: codersdk.APIKeyScopeAll,
+	})
+	require.NoError(t, err)
+	accountClient := codersdk.New(client.URL)
+	accountClient.SetSessionToken(token.Key)
+	accountClient.HTTPClient.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := accountClient.Request(ctx, http.MethodGet, "/api/v2/applications/auth-redirect", nil, func(req *http.Request) {
+		q := req.URL.Query()
+		q.Set("redirect_uri", "https://something.test.coder.com")
+		req.URL.RawQuery = q.Encode()
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	loc, err := resp.Location()
+	require.NoError(t, err)
+	key, err := api.AppSecurityKeys.DecryptAPIKey(loc.Query().Get(workspaceapps.SubdomainProxyAPIKeyParam))
+	require.NoError(t, err)
+
+	// Service accounts only authenticate with tokens, except for the keys
+	// minted to connect to workspace apps.
+	appClient := codersdk.New(client.URL)
+	appClient.SetSessionToken(key)
+	me, err := appClient.User(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, me.ID)
+}
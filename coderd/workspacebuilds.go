@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/db2sdk"
@@ -74,6 +75,7 @@ func (api *API) workspaceBuild(rw http.ResponseWriter, r *http.Request) {
 		data.agents,
 		data.apps,
 		data.templateVersions[0],
+		data.derpMap,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -188,6 +190,7 @@ func (api *API) workspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		data.agents,
 		data.apps,
 		data.templateVersions,
+		data.derpMap,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -276,6 +279,7 @@ func (api *API) workspaceBuildByBuildNumber(rw http.ResponseWriter, r *http.Requ
 		data.agents,
 		data.apps,
 		data.templateVersions[0],
+		data.derpMap,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -384,6 +388,8 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		[]database.WorkspaceAgent{},
 		[]database.WorkspaceApp{},
 		database.TemplateVersion{},
+		// The build has no agents yet, so no regions need names.
+		nil,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -616,6 +622,7 @@ type workspaceBuildsData struct {
 	metadata         []database.WorkspaceResourceMetadatum
 	agents           []database.WorkspaceAgent
 	apps             []database.WorkspaceApp
+	derpMap          *tailcfg.DERPMap
 }
 
 func (api *API) workspaceBuildsData(ctx context.Context, workspaces []database.Workspace, workspaceBuilds []database.WorkspaceBuild) (workspaceBuildsData, error) {
@@ -703,6 +710,11 @@ func (api *API) workspaceBuildsData(ctx context.Context, workspaces []database.W
 		return workspaceBuildsData{}, xerrors.Errorf("fetching workspace apps: %w", err)
 	}
 
+	derpMap, err := (*api.DERPMapProvider.Load()).All(ctx)
+	if err != nil {
+		return workspaceBuildsData{}, xerrors.Errorf("get DERP map: %w", err)
+	}
+
 	return workspaceBuildsData{
 		users:            users,
 		jobs:             jobs,
@@ -711,6 +723,7 @@ func (api *API) workspaceBuildsData(ctx context.Context, workspaces []database.W
 		metadata:         metadata,
 		agents:           agents,
 		apps:             apps,
+		derpMap:          derpMap,
 	}, nil
 }
 
@@ -724,6 +737,7 @@ func (api *API) convertWorkspaceBuilds(
 	resourceAgents []database.WorkspaceAgent,
 	agentApps []database.WorkspaceApp,
 	templateVersions []database.TemplateVersion,
+	derpMap *tailcfg.DERPMap,
 ) ([]codersdk.WorkspaceBuild, error) {
 	workspaceByID := map[uuid.UUID]database.Workspace{}
 	for _, workspace := range workspaces {
@@ -764,6 +778,7 @@ func (api *API) convertWorkspaceBuilds(
 			resourceAgents,
 			agentApps,
 			templateVersion,
+			derpMap,
 		)
 		if err != nil {
 			return nil, xerrors.Errorf("converting workspace build: %w", err)
@@ -785,6 +800,7 @@ func (api *API) convertWorkspaceBuild(
 	resourceAgents []database.WorkspaceAgent,
 	agentApps []database.WorkspaceApp,
 	templateVersion database.TemplateVersion,
+	derpMap *tailcfg.DERPMap,
 ) (codersdk.WorkspaceBuild, error) {
 	userByID := map[uuid.UUID]database.User{}
 	for _, user := range users {
@@ -824,7 +840,7 @@ func (api *API) convertWorkspaceBuild(
 		for _, agent := range agents {
 			apps := appsByAgentID[agent.ID]
 			apiAgent, err := convertWorkspaceAgent(
-				api.DERPMap, *api.TailnetCoordinator.Load(), agent, convertApps(apps), api.AgentInactiveDisconnectTimeout,
+				derpMap, *api.TailnetCoordinator.Load(), agent, convertApps(apps), api.AgentInactiveDisconnectTimeout,
 				api.DeploymentValues.AgentFallbackTroubleshootingURL.String(),
 			)
 			if err != nil {
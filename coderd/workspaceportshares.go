@@ -0,0 +1,318 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get workspace port shares
+// @ID get-workspace-port-shares
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {array} codersdk.WorkspacePortShare
+// @Router /workspaces/{workspace}/port-shares [get]
+func (api *API) workspacePortShares(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	shares, err := api.Database.GetWorkspacePortSharesByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace port shares.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspacePortShares(shares))
+}
+
+// @Summary Upsert workspace port share
+// @ID upsert-workspace-port-share
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.UpsertWorkspacePortShareRequest true "Upsert port share request"
+// @Success 200 {object} codersdk.WorkspacePortShare
+// @Router /workspaces/{workspace}/port-shares [put]
+func (api *API) putWorkspacePortShare(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.WorkspacePortShare](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.UpsertWorkspacePortShareRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	agentName, ok := api.workspacePortShareAgentName(rw, r, workspace, req.AgentName)
+	if !ok {
+		return
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	maxLevel := codersdk.WorkspacePortShareLevel(template.MaxPortShareLevel)
+
+	var validErrs []codersdk.ValidationError
+	if req.Port < 1 || req.Port > 65535 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "port", Detail: "Must be between 1 and 65535."})
+	}
+	switch {
+	case !req.ShareLevel.Valid() || req.ShareLevel == codersdk.WorkspacePortShareLevelOwner:
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "share_level", Detail: "Must be one of group, authenticated or public."})
+	case req.ShareLevel.Exceeds(maxLevel):
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "share_level", Detail: fmt.Sprintf("The template only allows sharing ports up to the level %q.", maxLevel)})
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(database.Now()) {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "expires_at", Detail: "Must be in the future."})
+	}
+
+	var groupID uuid.NullUUID
+	if req.ShareLevel == codersdk.WorkspacePortShareLevelGroup {
+		if req.GroupID == nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "group_id", Detail: "Must be set when sharing with a group."})
+		} else {
+			// Users may not be allowed to read groups, but they may share
+			// ports with any group of the organization.
+			//nolint:gocritic // Only used to check the group exists.
+			group, err := api.Database.GetGroupByID(dbauthz.AsSystemRestricted(ctx), *req.GroupID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching group.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			if err != nil || group.OrganizationID != workspace.OrganizationID {
+				validErrs = append(validErrs, codersdk.ValidationError{Field: "group_id", Detail: "Group does not exist in the organization of the workspace."})
+			}
+			groupID = uuid.NullUUID{UUID: *req.GroupID, Valid: true}
+		}
+	} else if req.GroupID != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "group_id", Detail: "Must only be set when sharing with a group."})
+	}
+
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to share workspace port.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	existing, err := api.Database.GetWorkspacePortShare(ctx, database.GetWorkspacePortShareParams{
+		WorkspaceID: workspace.ID,
+		AgentName:   agentName,
+		Port:        req.Port,
+	})
+	switch {
+	case err == nil:
+		aReq.Old = existing
+	case errors.Is(err, sql.ErrNoRows):
+		aReq.Action = database.AuditActionCreate
+	default:
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace port share.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+	share, err := api.Database.UpsertWorkspacePortShare(ctx, database.UpsertWorkspacePortShareParams{
+		WorkspaceID: workspace.ID,
+		AgentName:   agentName,
+		Port:        req.Port,
+		ShareLevel:  database.PortShareLevel(req.ShareLevel),
+		GroupID:     groupID,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   database.Now(),
+		UpdatedAt:   database.Now(),
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error sharing workspace port.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = share
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspacePortShare(share))
+}
+
+// @Summary Delete workspace port share
+// @ID delete-workspace-port-share
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.DeleteWorkspacePortShareRequest true "Delete port share request"
+// @Success 204
+// @Router /workspaces/{workspace}/port-shares [delete]
+func (api *API) deleteWorkspacePortShare(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.WorkspacePortShare](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.DeleteWorkspacePortShareRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	agentName, ok := api.workspacePortShareAgentName(rw, r, workspace, req.AgentName)
+	if !ok {
+		return
+	}
+
+	share, err := api.Database.GetWorkspacePortShare(ctx, database.GetWorkspacePortShareParams{
+		WorkspaceID: workspace.ID,
+		AgentName:   agentName,
+		Port:        req.Port,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: fmt.Sprintf("Port %d of agent %q is not shared.", req.Port, agentName),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace port share.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.Old = share
+
+	err = api.Database.DeleteWorkspacePortShare(ctx, database.DeleteWorkspacePortShareParams{
+		WorkspaceID: workspace.ID,
+		AgentName:   agentName,
+		Port:        req.Port,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting workspace port share.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// workspacePortShareAgentName resolves the agent of a port share. The agent
+// may be left out if the latest build of the workspace has a single agent.
+func (api *API) workspacePortShareAgentName(rw http.ResponseWriter, r *http.Request, workspace database.Workspace, agentName string) (string, bool) {
+	ctx := r.Context()
+
+	agents, err := api.Database.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agents.",
+			Detail:  err.Error(),
+		})
+		return "", false
+	}
+	if agentName == "" {
+		if len(agents) != 1 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "The agent must be specified unless the workspace has exactly one agent.",
+				Validations: []codersdk.ValidationError{
+					{Field: "agent_name", Detail: fmt.Sprintf("The workspace has %d agents.", len(agents))},
+				},
+			})
+			return "", false
+		}
+		return agents[0].Name, true
+	}
+	for _, agent := range agents {
+		if agent.Name == agentName {
+			return agentName, true
+		}
+	}
+	httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+		Message: fmt.Sprintf("The workspace has no agent named %q.", agentName),
+		Validations: []codersdk.ValidationError{
+			{Field: "agent_name", Detail: "Must be the name of an agent of the workspace."},
+		},
+	})
+	return "", false
+}
+
+func convertWorkspacePortShares(shares []database.WorkspacePortShare) []codersdk.WorkspacePortShare {
+	converted := make([]codersdk.WorkspacePortShare, 0, len(shares))
+	for _, share := range shares {
+		converted = append(converted, convertWorkspacePortShare(share))
+	}
+	return converted
+}
+
+func convertWorkspacePortShare(share database.WorkspacePortShare) codersdk.WorkspacePortShare {
+	converted := codersdk.WorkspacePortShare{
+		WorkspaceID: share.WorkspaceID,
+		AgentName:   share.AgentName,
+		Port:        share.Port,
+		ShareLevel:  codersdk.WorkspacePortShareLevel(share.ShareLevel),
+		CreatedAt:   share.CreatedAt,
+		UpdatedAt:   share.UpdatedAt,
+	}
+	if share.GroupID.Valid {
+		groupID := share.GroupID.UUID
+		converted.GroupID = &groupID
+	}
+	if share.ExpiresAt.Valid {
+		expiresAt := share.ExpiresAt.Time
+		converted.ExpiresAt = &expiresAt
+	}
+	return converted
+}
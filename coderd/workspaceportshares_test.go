@@ -0,0 +1,199 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspacePortShares(t *testing.T) {
+	t.Parallel()
+
+	// setup creates a workspace owned by a member with a single agent named
+	// "example".
+	setup := func(t *testing.T) (*codersdk.Client, *codersdk.Client, codersdk.Template, codersdk.Workspace, *audit.MockAuditor) {
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionApplyWithAgent(uuid.NewString()),
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, member, workspace.LatestBuild.ID)
+		return client, member, template, workspace, auditor
+	}
+
+	t.Run("Share", func(t *testing.T) {
+		t.Parallel()
+		_, member, _, workspace, auditor := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		shares, err := member.WorkspacePortShares(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Empty(t, shares)
+
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		share, err := member.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			Port:       8080,
+			ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated,
+			ExpiresAt:  &expiresAt,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "example", share.AgentName)
+		require.EqualValues(t, 8080, share.Port)
+		require.Equal(t, codersdk.WorkspacePortShareLevelAuthenticated, share.ShareLevel)
+		require.NotNil(t, share.ExpiresAt)
+		require.True(t, expiresAt.Equal(*share.ExpiresAt))
+
+		// Sharing again replaces the share.
+		_, err = member.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			AgentName:  "example",
+			Port:       8080,
+			ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated,
+		})
+		require.NoError(t, err)
+		shares, err = member.WorkspacePortShares(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Len(t, shares, 1)
+		require.Nil(t, shares[0].ExpiresAt)
+
+		err = member.DeleteWorkspacePortShare(ctx, workspace.ID, codersdk.DeleteWorkspacePortShareRequest{
+			Port: 8080,
+		})
+		require.NoError(t, err)
+		shares, err = member.WorkspacePortShares(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Empty(t, shares)
+
+		var actions []database.AuditAction
+		for _, alog := range auditor.AuditLogs() {
+			if alog.ResourceType != database.ResourceTypeWorkspacePortShare {
+				continue
+			}
+			require.Equal(t, workspace.ID, alog.ResourceID)
+			require.Equal(t, "example:8080", alog.ResourceTarget)
+			actions = append(actions, alog.Action)
+		}
+		require.Equal(t, []database.AuditAction{database.AuditActionCreate, database.AuditActionWrite, database.AuditActionDelete}, actions)
+
+		err = member.DeleteWorkspacePortShare(ctx, workspace.ID, codersdk.DeleteWorkspacePortShareRequest{
+			Port: 8080,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+		_, member, _, workspace, _ := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		groupID := uuid.New()
+		for _, c := range []struct {
+			req   codersdk.UpsertWorkspacePortShareRequest
+			field string
+		}{
+			{codersdk.UpsertWorkspacePortShareRequest{Port: 70000, ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated}, "port"},
+			{codersdk.UpsertWorkspacePortShareRequest{Port: 8080, ShareLevel: codersdk.WorkspacePortShareLevelOwner}, "share_level"},
+			{codersdk.UpsertWorkspacePortShareRequest{Port: 8080, ShareLevel: codersdk.WorkspacePortShareLevelPublic}, "share_level"},
+			{codersdk.UpsertWorkspacePortShareRequest{Port: 8080, ShareLevel: codersdk.WorkspacePortShareLevelGroup}, "group_id"},
+			{codersdk.UpsertWorkspacePortShareRequest{Port: 8080, ShareLevel: codersdk.WorkspacePortShareLevelGroup, GroupID: &groupID}, "group_id"},
+			{codersdk.UpsertWorkspacePortShareRequest{Port: 8080, ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated, ExpiresAt: ptr.Ref(time.Now().Add(-time.Hour))}, "expires_at"},
+			{codersdk.UpsertWorkspacePortShareRequest{AgentName: "unknown", Port: 8080, ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated}, "agent_name"},
+		} {
+			_, err := member.UpsertWorkspacePortShare(ctx, workspace.ID, c.req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+			require.Equal(t, c.field, apiErr.Validations[0].Field)
+		}
+	})
+
+	t.Run("TemplateMaxLevel", func(t *testing.T) {
+		t.Parallel()
+		client, member, template, workspace, _ := setup(t)
+		require.Equal(t, codersdk.WorkspacePortShareLevelAuthenticated, template.MaxPortShareLevel)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		public := codersdk.WorkspacePortShareLevelPublic
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                         template.Name,
+			DisplayName:                  template.DisplayName,
+			Description:                  template.Description,
+			Icon:                         template.Icon,
+			DefaultTTLMillis:             template.DefaultTTLMillis,
+			AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+			MaxPortShareLevel:            &public,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspacePortShareLevelPublic, updated.MaxPortShareLevel)
+
+		_, err = member.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			Port:       8080,
+			ShareLevel: codersdk.WorkspacePortShareLevelPublic,
+		})
+		require.NoError(t, err)
+
+		// Leaving the level out keeps it unchanged.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:             template.Name,
+			Description:      "Changed",
+			DefaultTTLMillis: template.DefaultTTLMillis,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspacePortShareLevelPublic, updated.MaxPortShareLevel)
+
+		invalid := codersdk.WorkspacePortShareLevel("everyone")
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:              template.Name,
+			MaxPortShareLevel: &invalid,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "max_port_share_level", apiErr.Validations[0].Field)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+		client, _, _, workspace, _ := setup(t)
+		user, err := client.User(context.Background(), codersdk.Me)
+		require.NoError(t, err)
+		other, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationIDs[0])
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err = other.UpsertWorkspacePortShare(ctx, workspace.ID, codersdk.UpsertWorkspacePortShareRequest{
+			Port:       8080,
+			ShareLevel: codersdk.WorkspacePortShareLevelAuthenticated,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -390,6 +390,58 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	richParameterValues := createWorkspace.RichParameterValues
+	if createWorkspace.TemplateVersionPresetID != uuid.Nil {
+		richParameterValues, err = api.presetRichParameterValues(ctx, template, createWorkspace.TemplateVersionPresetID, createWorkspace.RichParameterValues)
+		if errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Preset %q doesn't exist for the active version of the template.", createWorkspace.TemplateVersionPresetID.String()),
+				Validations: []codersdk.ValidationError{{
+					Field:  "template_version_preset_id",
+					Detail: "preset not found",
+				}},
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template version preset.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		// Presets may leave out required parameters, so the merged values are
+		// validated as a whole before the build is created.
+		dbTemplateVersionParameters, err := api.Database.GetTemplateVersionParameters(ctx, template.ActiveVersionID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template version parameters.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		templateVersionParameters, err := convertTemplateVersionParameters(dbTemplateVersionParameters)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error converting template version parameter.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		err = codersdk.ValidateWorkspaceBuildParameters(templateVersionParameters, richParameterValues, nil)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid workspace parameters.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "rich_parameter_values",
+					Detail: err.Error(),
+				}},
+			})
+			return
+		}
+	}
+
 	// TODO: This should be a system call as the actor might not be able to
 	// read other workspaces. Ideally we check the error on create and look for
 	// a postgres conflict error.
@@ -445,7 +497,7 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			Reason(database.BuildReasonInitiator).
 			Initiator(apiKey.UserID).
 			ActiveVersion().
-			RichParameterValues(createWorkspace.RichParameterValues)
+			RichParameterValues(richParameterValues)
 		workspaceBuild, provisionerJob, err = builder.Build(
 			ctx, db, func(action rbac.Action, object rbac.Objecter) bool {
 				return api.Authorize(r, action, object)
@@ -494,6 +546,8 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		[]database.WorkspaceAgent{},
 		[]database.WorkspaceApp{},
 		database.TemplateVersion{},
+		// The build has no agents yet, so no regions need names.
+		nil,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -985,6 +1039,7 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		data.agents,
 		data.apps,
 		data.templateVersions,
+		data.derpMap,
 	)
 	if err != nil {
 		return workspaceData{}, xerrors.Errorf("convert workspace builds: %w", err)
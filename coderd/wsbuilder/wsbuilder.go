@@ -244,6 +244,10 @@ func (b *Builder) buildTx(authFunc func(action rbac.Action, object rbac.Objecter
 	if err != nil {
 		return nil, nil, err
 	}
+	err = b.checkTemplateVersionState()
+	if err != nil {
+		return nil, nil, err
+	}
 	err = b.checkTemplateJobStatus()
 	if err != nil {
 		return nil, nil, err
@@ -415,6 +419,16 @@ func (b *Builder) getTemplateVersionID() (uuid.UUID, error) {
 		}
 		return t.ActiveVersionID, nil
 	}
+	if b.trans == database.WorkspaceTransitionStart {
+		// Templates that require the active version always start on it.
+		t, err := b.getTemplate()
+		if err != nil {
+			return uuid.Nil, xerrors.Errorf("get template so we can check if the active version is required: %w", err)
+		}
+		if t.RequireActiveVersion {
+			return t.ActiveVersionID, nil
+		}
+	}
 	// default is prior version
 	bld, err := b.getLastBuild()
 	if err != nil {
@@ -637,6 +651,16 @@ func (b *Builder) authorize(authFunc func(action rbac.Action, object rbac.Object
 		}
 	}
 
+	if b.trans == database.WorkspaceTransitionStart && template.RequireActiveVersion &&
+		b.version.specific != nil && *b.version.specific != template.ActiveVersionID &&
+		!authFunc(rbac.ActionUpdate, template) {
+		return BuildError{
+			http.StatusForbidden,
+			"The template requires workspaces to be started on the active version. Only template managers may start other versions.",
+			xerrors.New(""),
+		}
+	}
+
 	if b.logLevel != "" && !authFunc(rbac.ActionUpdate, template) {
 		return BuildError{
 			http.StatusBadRequest,
@@ -670,6 +694,25 @@ func (b *Builder) checkTemplateVersionMatchesTemplate() error {
 	return nil
 }
 
+func (b *Builder) checkTemplateVersionState() error {
+	if b.trans == database.WorkspaceTransitionDelete {
+		// Workspaces can always be deleted, regardless of their version.
+		return nil
+	}
+	templateVersion, err := b.getTemplateVersion()
+	if err != nil {
+		return BuildError{http.StatusInternalServerError, "failed to fetch template version", err}
+	}
+	if templateVersion.State == database.TemplateVersionStateArchived {
+		return BuildError{
+			http.StatusBadRequest,
+			fmt.Sprintf("The template version %q is archived. You cannot build workspaces with it!", templateVersion.Name),
+			xerrors.New(""),
+		}
+	}
+	return nil
+}
+
 func (b *Builder) checkTemplateJobStatus() error {
 	templateVersion, err := b.getTemplateVersion()
 	if err != nil {
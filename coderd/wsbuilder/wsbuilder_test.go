@@ -16,6 +16,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbmock"
 	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/wsbuilder"
 	"github.com/coder/coder/codersdk"
 )
@@ -189,6 +190,100 @@ func TestBuilder_ActiveVersion(t *testing.T) {
 	req.NoError(err)
 }
 
+func TestBuilder_RequireActiveVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DefaultsToActive", func(t *testing.T) {
+		t.Parallel()
+		req := require.New(t)
+		asrt := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mDB := expectDB(t,
+			// Inputs
+			withTemplateRequireActiveVersion,
+			withActiveVersion(nil),
+			withLastBuildFound,
+			withRichParameters(nil),
+			withParameterSchemas(activeJobID, nil),
+
+			// Outputs
+			expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+				asrt.Equal(activeFileID, job.FileID)
+			}),
+			expectBuild(func(bld database.InsertWorkspaceBuildParams) {
+				asrt.Equal(activeVersionID, bld.TemplateVersionID)
+			}),
+			expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+			}),
+		)
+
+		ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+		uut := wsbuilder.New(ws, database.WorkspaceTransitionStart)
+		_, _, err := uut.Build(ctx, mDB, nil)
+		req.NoError(err)
+	})
+
+	t.Run("OtherVersionForbidden", func(t *testing.T) {
+		t.Parallel()
+		req := require.New(t)
+		asrt := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mDB := expectDB(t,
+			// Inputs
+			withTemplateRequireActiveVersion,
+		)
+
+		ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+		uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).VersionID(inactiveVersionID)
+		// The user may update the workspace, but not the template.
+		_, _, err := uut.Build(ctx, mDB, func(_ rbac.Action, object rbac.Objecter) bool {
+			return object.RBACObject().Type == rbac.ResourceWorkspace.Type
+		})
+		bldErr := wsbuilder.BuildError{}
+		req.ErrorAs(err, &bldErr)
+		asrt.Equal(http.StatusForbidden, bldErr.Status)
+	})
+}
+
+func TestBuilder_ArchivedVersion(t *testing.T) {
+	t.Parallel()
+	req := require.New(t)
+	asrt := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mDB := expectDB(t,
+		// Inputs
+		withTemplate,
+		func(mTx *dbmock.MockStore) {
+			mTx.EXPECT().GetTemplateVersionByID(gomock.Any(), inactiveVersionID).
+				Times(1).
+				Return(database.TemplateVersion{
+					ID:             inactiveVersionID,
+					TemplateID:     uuid.NullUUID{UUID: templateID, Valid: true},
+					OrganizationID: orgID,
+					Name:           "inactive",
+					JobID:          inactiveJobID,
+					State:          database.TemplateVersionStateArchived,
+				}, nil)
+		},
+	)
+
+	ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+	uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).VersionID(inactiveVersionID)
+	_, _, err := uut.Build(ctx, mDB, nil)
+	bldErr := wsbuilder.BuildError{}
+	req.ErrorAs(err, &bldErr)
+	asrt.Equal(http.StatusBadRequest, bldErr.Status)
+}
+
 func TestWorkspaceBuildWithRichParameters(t *testing.T) {
 	t.Parallel()
 
@@ -592,6 +687,18 @@ func withTemplate(mTx *dbmock.MockStore) {
 		}, nil)
 }
 
+func withTemplateRequireActiveVersion(mTx *dbmock.MockStore) {
+	mTx.EXPECT().GetTemplateByID(gomock.Any(), templateID).
+		Times(1).
+		Return(database.Template{
+			ID:                   templateID,
+			OrganizationID:       orgID,
+			Provisioner:          database.ProvisionerTypeTerraform,
+			ActiveVersionID:      activeVersionID,
+			RequireActiveVersion: true,
+		}, nil)
+}
+
 func withActiveVersion(params []database.TemplateVersionParameter) func(mTx *dbmock.MockStore) {
 	return func(mTx *dbmock.MockStore) {
 		mTx.EXPECT().GetTemplateVersionByID(gomock.Any(), activeVersionID).
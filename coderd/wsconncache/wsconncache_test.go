@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 	"go.uber.org/goleak"
+	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
@@ -228,7 +229,8 @@ func (c *client) Listen(_ context.Context) (net.Conn, error) {
 		<-closed
 	})
 	go func() {
-		_ = c.coordinator.ServeAgent(serverConn, c.agentID, "")
+		_ = tailnet.ServeCoordinatePeer(context.Background(), slogtest.Make(c.t, nil), c.coordinator, serverConn,
+			c.agentID, "", tailnet.AgentTunnelAuth{})
 		close(closed)
 	}()
 	return clientConn, nil
@@ -257,3 +259,7 @@ func (*client) PostStartup(_ context.Context, _ agentsdk.PostStartupRequest) err
 func (*client) PatchStartupLogs(_ context.Context, _ agentsdk.PatchStartupLogs) error {
 	return nil
 }
+
+func (*client) DERPMapUpdates(_ context.Context) (<-chan *tailcfg.DERPMap, error) {
+	return nil, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
 )
 
 // New returns a client that is used to interact with the
@@ -116,6 +117,23 @@ func (c *Client) Manifest(ctx context.Context) (Manifest, error) {
 	if err != nil {
 		return Manifest{}, err
 	}
+	err = c.rewriteDERPMap(agentMeta.DERPMap)
+	if err != nil {
+		return Manifest{}, err
+	}
+	return agentMeta, nil
+}
+
+// rewriteDERPMap rewrites the embedded relay regions of the DERP map to use
+// the access URL of the client.
+//
+// Agents can provide an arbitrary access URL that may be different
+// that the globally configured one. This breaks the built-in DERP,
+// which would continue to reference the global access URL.
+func (c *Client) rewriteDERPMap(derpMap *tailcfg.DERPMap) error {
+	if derpMap == nil {
+		return nil
+	}
 	accessingPort := c.SDK.URL.Port()
 	if accessingPort == "" {
 		accessingPort = "80"
@@ -125,15 +143,9 @@ func (c *Client) Manifest(ctx context.Context) (Manifest, error) {
 	}
 	accessPort, err := strconv.Atoi(accessingPort)
 	if err != nil {
-		return Manifest{}, xerrors.Errorf("convert accessing port %q: %w", accessingPort, err)
+		return xerrors.Errorf("convert accessing port %q: %w", accessingPort, err)
 	}
-	// Agents can provide an arbitrary access URL that may be different
-	// that the globally configured one. This breaks the built-in DERP,
-	// which would continue to reference the global access URL.
-	//
-	// This converts all built-in DERPs to use the access URL that the
-	// manifest request was performed with.
-	for _, region := range agentMeta.DERPMap.Regions {
+	for _, region := range derpMap.Regions {
 		if !region.EmbeddedRelay {
 			continue
 		}
@@ -147,16 +159,75 @@ func (c *Client) Manifest(ctx context.Context) (Manifest, error) {
 			node.ForceHTTP = c.SDK.URL.Scheme == "http"
 		}
 	}
-	return agentMeta, nil
+	return nil
+}
+
+// DERPMapUpdates streams the DERP map of the agent. The current DERP map is
+// sent first, followed by every change. The channel is closed when ctx is
+// canceled or the stream ends.
+func (c *Client) DERPMapUpdates(ctx context.Context) (<-chan *tailcfg.DERPMap, error) {
+	//nolint:bodyclose
+	res, err := c.SDK.Request(ctx, http.MethodGet, "/api/v2/workspaceagents/me/derp-map", nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, codersdk.ReadBodyAsError(res)
+	}
+	nextEvent := codersdk.ServerSentEventReader(ctx, res.Body)
+
+	updates := make(chan *tailcfg.DERPMap)
+	go func() {
+		defer close(updates)
+		defer res.Body.Close()
+
+		for {
+			sse, err := nextEvent()
+			if err != nil {
+				return
+			}
+			if sse.Type == codersdk.ServerSentEventTypeError {
+				// The server stops sending updates after an error.
+				return
+			}
+			if sse.Type != codersdk.ServerSentEventTypeData {
+				continue
+			}
+			b, ok := sse.Data.([]byte)
+			if !ok {
+				return
+			}
+			var derpMap tailcfg.DERPMap
+			err = json.Unmarshal(b, &derpMap)
+			if err != nil {
+				return
+			}
+			err = c.rewriteDERPMap(&derpMap)
+			if err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case updates <- &derpMap:
+			}
+		}
+	}()
+	return updates, nil
 }
 
 // Listen connects to the workspace agent coordinate WebSocket
-// that handles connection negotiation.
+// that handles connection negotiation. The connection speaks the
+// current version of the coordination protocol.
 func (c *Client) Listen(ctx context.Context) (net.Conn, error) {
 	coordinateURL, err := c.SDK.URL.Parse("/api/v2/workspaceagents/me/coordinate")
 	if err != nil {
 		return nil, xerrors.Errorf("parse url: %w", err)
 	}
+	q := coordinateURL.Query()
+	q.Set(tailnet.CoordinateVersionQueryParam, strconv.Itoa(tailnet.CurrentCoordinateVersion))
+	coordinateURL.RawQuery = q.Encode()
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, xerrors.Errorf("create cookie jar: %w", err)
@@ -486,6 +557,20 @@ type Stats struct {
 
 	// Metrics collected by the agent
 	Metrics []AgentMetric `json:"metrics"`
+
+	// PeerConnections is a snapshot of the active tailnet connections to the
+	// agent. Nil means the agent doesn't report connections.
+	PeerConnections []PeerConnection `json:"peer_connections"`
+}
+
+// PeerConnection is an active tailnet connection to the agent, and whether
+// its traffic flows directly or is relayed through DERP.
+type PeerConnection struct {
+	Direct bool `json:"direct"`
+	// DERPRegionID is the region relaying traffic when the connection isn't
+	// direct.
+	DERPRegionID        int     `json:"derp_region_id,omitempty"`
+	LatencyMilliseconds float64 `json:"latency_ms"`
 }
 
 type AgentMetricType string
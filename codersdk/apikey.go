@@ -37,6 +37,12 @@ const (
 	// API keys can still be created by an owner and used by the user.
 	// These keys would use the `LoginTypeToken` type.
 	LoginTypeNone LoginType = "none"
+	// LoginTypeOAuth2ProviderApp is used for the access tokens issued to
+	// OAuth2 applications that users have authorized.
+	LoginTypeOAuth2ProviderApp LoginType = "oauth2_provider_app"
+	// LoginTypeImpersonation is used for the short-lived API keys that
+	// owners create to act as another user.
+	LoginTypeImpersonation LoginType = "impersonation"
 )
 
 type APIKeyScope string
@@ -171,6 +177,62 @@ func (c *Client) DeleteAPIKey(ctx context.Context, userID string, id string) err
 	return nil
 }
 
+// Session is an API key created by logging in to the dashboard or the CLI.
+type Session struct {
+	ID        string      `json:"id" validate:"required"`
+	UserID    uuid.UUID   `json:"user_id" validate:"required" format:"uuid"`
+	LoginType LoginType   `json:"login_type" validate:"required" enums:"password,github,oidc"`
+	Scope     APIKeyScope `json:"scope" validate:"required" enums:"all,application_connect"`
+	IPAddress string      `json:"ip_address"`
+	UserAgent string      `json:"user_agent"`
+	CreatedAt time.Time   `json:"created_at" validate:"required" format:"date-time"`
+	LastUsed  time.Time   `json:"last_used" validate:"required" format:"date-time"`
+	ExpiresAt time.Time   `json:"expires_at" validate:"required" format:"date-time"`
+	// Current is true for the session that made the request.
+	Current bool `json:"current"`
+}
+
+// Sessions lists the active sessions of the user, most recently used first.
+func (c *Client) Sessions(ctx context.Context, user string) ([]Session, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/sessions", user), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var sessions []Session
+	return sessions, json.NewDecoder(res.Body).Decode(&sessions)
+}
+
+// RevokeSession signs out a single session of the user.
+func (c *Client) RevokeSession(ctx context.Context, user string, id string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/sessions/%s", user, id), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs out every session of the user except the one
+// making the request.
+func (c *Client) RevokeOtherSessions(ctx context.Context, user string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/sessions", user), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
 // GetTokenConfig returns deployment options related to token management
 func (c *Client) GetTokenConfig(ctx context.Context, userID string) (TokenConfig, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/keys/tokens/tokenconfig", userID), nil)
@@ -23,6 +23,13 @@ const (
 	ResourceTypeAPIKey          ResourceType = "api_key"
 	ResourceTypeGroup           ResourceType = "group"
 	ResourceTypeLicense         ResourceType = "license"
+	// nolint:gosec // These are not secrets.
+	ResourceTypeOAuth2ProviderApp       ResourceType = "oauth2_provider_app"
+	ResourceTypeOAuth2ProviderAppSecret ResourceType = "oauth2_provider_app_secret"
+	ResourceTypeTemplateVersionRollout  ResourceType = "template_version_rollout"
+	ResourceTypeWorkspacePortShare      ResourceType = "workspace_port_share"
+	ResourceTypeDERPRegion              ResourceType = "derp_region"
+	ResourceTypeDERPRegionPolicy        ResourceType = "derp_region_policy"
 )
 
 func (r ResourceType) FriendlyString() string {
@@ -47,6 +54,18 @@ func (r ResourceType) FriendlyString() string {
 		return "group"
 	case ResourceTypeLicense:
 		return "license"
+	case ResourceTypeOAuth2ProviderApp:
+		return "oauth2 app"
+	case ResourceTypeOAuth2ProviderAppSecret:
+		return "oauth2 app secret"
+	case ResourceTypeTemplateVersionRollout:
+		return "template version rollout"
+	case ResourceTypeWorkspacePortShare:
+		return "workspace port share"
+	case ResourceTypeDERPRegion:
+		return "derp region"
+	case ResourceTypeDERPRegionPolicy:
+		return "derp region policy"
 	default:
 		return "unknown"
 	}
@@ -63,6 +82,8 @@ const (
 	AuditActionLogin    AuditAction = "login"
 	AuditActionLogout   AuditAction = "logout"
 	AuditActionRegister AuditAction = "register"
+	AuditActionConnect  AuditAction = "connect"
+	AuditActionLockout  AuditAction = "lockout"
 )
 
 func (a AuditAction) Friendly() string {
@@ -83,6 +104,10 @@ func (a AuditAction) Friendly() string {
 		return "logged out"
 	case AuditActionRegister:
 		return "registered"
+	case AuditActionConnect:
+		return "connected to"
+	case AuditActionLockout:
+		return "was locked out"
 	default:
 		return "unknown"
 	}
@@ -71,6 +71,11 @@ const (
 	// command that was invoked to produce the request. It is for internal use
 	// only.
 	CLITelemetryHeader = "Coder-CLI-Telemetry"
+
+	// ImpersonatorHeader is set on every response to a request authenticated
+	// with an impersonation API key. It contains the username of the owner
+	// that is impersonating the user.
+	ImpersonatorHeader = "Coder-Impersonator"
 )
 
 // loggableMimeTypes is a list of MIME types that are safe to log
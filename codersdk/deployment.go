@@ -46,6 +46,7 @@ const (
 	FeatureAppearance                 FeatureName = "appearance"
 	FeatureAdvancedTemplateScheduling FeatureName = "advanced_template_scheduling"
 	FeatureWorkspaceProxy             FeatureName = "workspace_proxy"
+	FeatureUserRoleManagement         FeatureName = "user_role_management"
 )
 
 // FeatureNames must be kept in-sync with the Feature enum above.
@@ -61,6 +62,7 @@ var FeatureNames = []FeatureName{
 	FeatureAppearance,
 	FeatureAdvancedTemplateScheduling,
 	FeatureWorkspaceProxy,
+	FeatureUserRoleManagement,
 }
 
 // Humanize returns the feature name in a human-readable format.
@@ -158,11 +160,14 @@ type DeploymentValues struct {
 	SessionDuration                 clibase.Duration                `json:"max_session_expiry,omitempty" typescript:",notnull"`
 	DisableSessionExpiryRefresh     clibase.Bool                    `json:"disable_session_expiry_refresh,omitempty" typescript:",notnull"`
 	DisablePasswordAuth             clibase.Bool                    `json:"disable_password_auth,omitempty" typescript:",notnull"`
+	RequireOwnerMFA                 clibase.Bool                    `json:"require_owner_mfa,omitempty" typescript:",notnull"`
+	LoginLockout                    LoginLockoutConfig              `json:"login_lockout,omitempty" typescript:",notnull"`
 	Support                         SupportConfig                   `json:"support,omitempty" typescript:",notnull"`
 	GitAuthProviders                clibase.Struct[[]GitAuthConfig] `json:"git_auth,omitempty" typescript:",notnull"`
 	SSHConfig                       SSHConfig                       `json:"config_ssh,omitempty" typescript:",notnull"`
 	WgtunnelHost                    clibase.String                  `json:"wgtunnel_host,omitempty" typescript:",notnull"`
 	DisableOwnerWorkspaceExec       clibase.Bool                    `json:"disable_owner_workspace_exec,omitempty" typescript:",notnull"`
+	DisableImpersonation            clibase.Bool                    `json:"disable_impersonation,omitempty" typescript:",notnull"`
 	ProxyHealthStatusInterval       clibase.Duration                `json:"proxy_health_status_interval,omitempty" typescript:",notnull"`
 
 	Config      clibase.YAMLConfigPath `json:"config,omitempty" typescript:",notnull"`
@@ -252,21 +257,51 @@ type OAuth2GithubConfig struct {
 }
 
 type OIDCConfig struct {
-	AllowSignups        clibase.Bool                      `json:"allow_signups" typescript:",notnull"`
-	ClientID            clibase.String                    `json:"client_id" typescript:",notnull"`
-	ClientSecret        clibase.String                    `json:"client_secret" typescript:",notnull"`
-	EmailDomain         clibase.StringArray               `json:"email_domain" typescript:",notnull"`
-	IssuerURL           clibase.String                    `json:"issuer_url" typescript:",notnull"`
-	Scopes              clibase.StringArray               `json:"scopes" typescript:",notnull"`
-	IgnoreEmailVerified clibase.Bool                      `json:"ignore_email_verified" typescript:",notnull"`
-	UsernameField       clibase.String                    `json:"username_field" typescript:",notnull"`
-	EmailField          clibase.String                    `json:"email_field" typescript:",notnull"`
-	AuthURLParams       clibase.Struct[map[string]string] `json:"auth_url_params" typescript:",notnull"`
-	IgnoreUserInfo      clibase.Bool                      `json:"ignore_user_info" typescript:",notnull"`
-	GroupField          clibase.String                    `json:"groups_field" typescript:",notnull"`
-	GroupMapping        clibase.Struct[map[string]string] `json:"group_mapping" typescript:",notnull"`
-	SignInText          clibase.String                    `json:"sign_in_text" typescript:",notnull"`
-	IconURL             clibase.URL                       `json:"icon_url" typescript:",notnull"`
+	AllowSignups        clibase.Bool                        `json:"allow_signups" typescript:",notnull"`
+	ClientID            clibase.String                      `json:"client_id" typescript:",notnull"`
+	ClientSecret        clibase.String                      `json:"client_secret" typescript:",notnull"`
+	EmailDomain         clibase.StringArray                 `json:"email_domain" typescript:",notnull"`
+	IssuerURL           clibase.String                      `json:"issuer_url" typescript:",notnull"`
+	Scopes              clibase.StringArray                 `json:"scopes" typescript:",notnull"`
+	IgnoreEmailVerified clibase.Bool                        `json:"ignore_email_verified" typescript:",notnull"`
+	UsernameField       clibase.String                      `json:"username_field" typescript:",notnull"`
+	EmailField          clibase.String                      `json:"email_field" typescript:",notnull"`
+	AuthURLParams       clibase.Struct[map[string]string]   `json:"auth_url_params" typescript:",notnull"`
+	IgnoreUserInfo      clibase.Bool                        `json:"ignore_user_info" typescript:",notnull"`
+	GroupField          clibase.String                      `json:"groups_field" typescript:",notnull"`
+	GroupMapping        clibase.Struct[map[string]string]   `json:"group_mapping" typescript:",notnull"`
+	UserRoleField       clibase.String                      `json:"user_role_field" typescript:",notnull"`
+	UserRoleMapping     clibase.Struct[map[string][]string] `json:"user_role_mapping" typescript:",notnull"`
+	UserRolesDefault    clibase.StringArray                 `json:"user_roles_default" typescript:",notnull"`
+	SignInText          clibase.String                      `json:"sign_in_text" typescript:",notnull"`
+	IconURL             clibase.URL                         `json:"icon_url" typescript:",notnull"`
+	// Providers are OpenID Connect identity providers trusted in addition
+	// to the one configured above.
+	Providers clibase.Struct[[]OIDCProviderConfig] `json:"providers" typescript:",notnull"`
+}
+
+// OIDCProviderConfig is an additional OpenID Connect identity provider.
+// Users sign in with it through /api/v2/users/oidc/{id}/callback.
+type OIDCProviderConfig struct {
+	ID                  string              `json:"id" yaml:"id"`
+	IssuerURL           string              `json:"issuer_url" yaml:"issuer_url"`
+	ClientID            string              `json:"client_id" yaml:"client_id"`
+	ClientSecret        string              `json:"-" yaml:"client_secret"`
+	Scopes              []string            `json:"scopes" yaml:"scopes"`
+	AllowSignups        bool                `json:"allow_signups" yaml:"allow_signups"`
+	EmailDomain         []string            `json:"email_domain" yaml:"email_domain"`
+	IgnoreEmailVerified bool                `json:"ignore_email_verified" yaml:"ignore_email_verified"`
+	UsernameField       string              `json:"username_field" yaml:"username_field"`
+	EmailField          string              `json:"email_field" yaml:"email_field"`
+	AuthURLParams       map[string]string   `json:"auth_url_params" yaml:"auth_url_params"`
+	IgnoreUserInfo      bool                `json:"ignore_user_info" yaml:"ignore_user_info"`
+	GroupField          string              `json:"groups_field" yaml:"groups_field"`
+	GroupMapping        map[string]string   `json:"group_mapping" yaml:"group_mapping"`
+	UserRoleField       string              `json:"user_role_field" yaml:"user_role_field"`
+	UserRoleMapping     map[string][]string `json:"user_role_mapping" yaml:"user_role_mapping"`
+	UserRolesDefault    []string            `json:"user_roles_default" yaml:"user_roles_default"`
+	SignInText          string              `json:"sign_in_text" yaml:"sign_in_text"`
+	IconURL             string              `json:"icon_url" yaml:"icon_url"`
 }
 
 type TelemetryConfig struct {
@@ -319,14 +354,23 @@ type RateLimitConfig struct {
 	API        clibase.Int64 `json:"api" typescript:",notnull"`
 }
 
+// LoginLockoutConfig configures when repeated failed password logins lock out
+// an account or source IP address.
+type LoginLockoutConfig struct {
+	Threshold   clibase.Int64    `json:"threshold" typescript:",notnull"`
+	IPThreshold clibase.Int64    `json:"ip_threshold" typescript:",notnull"`
+	Duration    clibase.Duration `json:"duration" typescript:",notnull"`
+}
+
 type SwaggerConfig struct {
 	Enable clibase.Bool `json:"enable" typescript:",notnull"`
 }
 
 type LoggingConfig struct {
-	Human       clibase.String `json:"human" typescript:",notnull"`
-	JSON        clibase.String `json:"json" typescript:",notnull"`
-	Stackdriver clibase.String `json:"stackdriver" typescript:",notnull"`
+	Human                  clibase.String `json:"human" typescript:",notnull"`
+	JSON                   clibase.String `json:"json" typescript:",notnull"`
+	Stackdriver            clibase.String `json:"stackdriver" typescript:",notnull"`
+	AppAccessSamplePercent clibase.Int64  `json:"app_access_sample_percent" typescript:",notnull"`
 }
 
 type DangerousConfig struct {
@@ -988,6 +1032,38 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupOIDC,
 			YAML:        "groupMapping",
 		},
+		{
+			Name:        "OIDC User Role Field",
+			Description: "This field must be set if using the user roles sync feature. Set this to the name of the claim used to store the user's role. The roles should be sent as an array of strings.",
+			Flag:        "oidc-user-role-field",
+			Env:         "CODER_OIDC_USER_ROLE_FIELD",
+			// This value is intentionally blank. If this is empty, then OIDC user role
+			// sync behavior is disabled.
+			Default: "",
+			Value:   &c.OIDC.UserRoleField,
+			Group:   &deploymentGroupOIDC,
+			YAML:    "userRoleField",
+		},
+		{
+			Name:        "OIDC User Role Mapping",
+			Description: "A map of the OIDC passed in user roles and the site roles in Coder they should map to. This is useful if the role names do not match. Values are lists of Coder site roles, e.g. {\"admins\": [\"owner\"]}.",
+			Flag:        "oidc-user-role-mapping",
+			Env:         "CODER_OIDC_USER_ROLE_MAPPING",
+			Default:     "{}",
+			Value:       &c.OIDC.UserRoleMapping,
+			Group:       &deploymentGroupOIDC,
+			YAML:        "userRoleMapping",
+		},
+		{
+			Name:        "OIDC User Role Default",
+			Description: "If user role sync is enabled, these roles are assigned to users whose role claim doesn't map to any Coder roles.",
+			Flag:        "oidc-user-role-default",
+			Env:         "CODER_OIDC_USER_ROLE_DEFAULT",
+			Default:     "",
+			Value:       &c.OIDC.UserRolesDefault,
+			Group:       &deploymentGroupOIDC,
+			YAML:        "userRoleDefault",
+		},
 		{
 			Name:        "OpenID Connect sign in text",
 			Description: "The text to show on the OpenID Connect sign in button.",
@@ -1007,6 +1083,14 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupOIDC,
 			YAML:        "iconURL",
 		},
+		{
+			Name:        "OIDC Providers",
+			Description: "Additional OpenID Connect identity providers as a JSON list. Each provider needs a unique id, issuer_url, client_id and client_secret, and accepts the same claim settings as the primary provider. The primary provider is configured with the other OIDC options.",
+			Flag:        "oidc-providers",
+			Env:         "CODER_OIDC_PROVIDERS",
+			Value:       &c.OIDC.Providers,
+			Group:       &deploymentGroupOIDC,
+		},
 		// Telemetry settings
 		{
 			Name:        "Telemetry Enable",
@@ -1179,6 +1263,17 @@ when required by your organization's security policy.`,
 			YAML:        "stackdriverPath",
 			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "App Access Log Sample Percent",
+			Description: "Percentage of requests proxied to workspace apps that are logged with the user, app, status, latency and bytes sent.",
+			Flag:        "log-app-access-sample-percent",
+			Env:         "CODER_LOGGING_APP_ACCESS_SAMPLE_PERCENT",
+			Default:     "0",
+			Value:       &c.Logging.AppAccessSamplePercent,
+			Group:       &deploymentGroupIntrospectionLogging,
+			YAML:        "appAccessSamplePercent",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
 		// ☢️ Dangerous settings
 		{
 			Name:        "DANGEROUS: Allow all CORs requests",
@@ -1407,6 +1502,15 @@ when required by your organization's security policy.`,
 			YAML:        "disableOwnerWorkspaceAccess",
 			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "Disable Impersonation",
+			Description: "Prevent the 'owner' role from impersonating other users with short-lived API keys. Existing impersonation keys stop working while this is set.",
+			Flag:        "disable-impersonation",
+			Env:         "CODER_DISABLE_IMPERSONATION",
+
+			Value: &c.DisableImpersonation,
+			YAML:  "disableImpersonation",
+		},
 		{
 			Name:        "Session Duration",
 			Description: "The token expiry duration for browser sessions. Sessions may last longer if they are actively making requests, but this functionality can be disabled via --disable-session-expiry-refresh.",
@@ -1437,6 +1541,45 @@ when required by your organization's security policy.`,
 			Group: &deploymentGroupNetworkingHTTP,
 			YAML:  "disablePasswordAuth",
 		},
+		{
+			Name:        "Require Owner MFA",
+			Description: "Require users with the owner role to enter a code from an authenticator app when they sign in with their password. Owners that have not enrolled an authenticator app must enroll at their next password login.",
+			Flag:        "require-owner-mfa",
+			Env:         "CODER_REQUIRE_OWNER_MFA",
+			Value:       &c.RequireOwnerMFA,
+			Group:       &deploymentGroupNetworkingHTTP,
+			YAML:        "requireOwnerMFA",
+		},
+		{
+			Name:        "Login Lockout Threshold",
+			Description: "The number of consecutive failed password logins after which an account is locked out. Set to 0 to disable account lockouts. Failed logins are delayed regardless of this value.",
+			Flag:        "login-lockout-threshold",
+			Env:         "CODER_LOGIN_LOCKOUT_THRESHOLD",
+			Default:     "10",
+			Value:       &c.LoginLockout.Threshold,
+			Group:       &deploymentGroupNetworkingHTTP,
+			YAML:        "loginLockoutThreshold",
+		},
+		{
+			Name:        "Login IP Lockout Threshold",
+			Description: "The number of consecutive failed password logins from a single IP address after which the address is locked out. Set to 0 to disable IP address lockouts.",
+			Flag:        "login-ip-lockout-threshold",
+			Env:         "CODER_LOGIN_IP_LOCKOUT_THRESHOLD",
+			Default:     "50",
+			Value:       &c.LoginLockout.IPThreshold,
+			Group:       &deploymentGroupNetworkingHTTP,
+			YAML:        "loginIPLockoutThreshold",
+		},
+		{
+			Name:        "Login Lockout Duration",
+			Description: "How long accounts and IP addresses stay locked out after reaching their lockout threshold. Admins can unlock accounts early with `coder users unlock`.",
+			Flag:        "login-lockout-duration",
+			Env:         "CODER_LOGIN_LOCKOUT_DURATION",
+			Default:     (15 * time.Minute).String(),
+			Value:       &c.LoginLockout.Duration,
+			Group:       &deploymentGroupNetworkingHTTP,
+			YAML:        "loginLockoutDuration",
+		},
 		{
 			Name:          "Config Path",
 			Description:   `Specify a YAML file to load configuration from.`,
@@ -1707,6 +1850,10 @@ const (
 	// New workspace filter
 	ExperimentWorkspaceFilter Experiment = "workspace_filter"
 
+	// Coordinates tailnet connections of highly available deployments
+	// through Postgres instead of pubsub.
+	ExperimentTailnetPGCoordinator Experiment = "tailnet_pg_coordinator"
+
 	// Add new experiments here!
 	// ExperimentExample Experiment = "example"
 )
@@ -0,0 +1,208 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// ManagedDERPRegion is a DERP region configured by an administrator. Healthy
+// regions are merged into the DERP map served to clients and agents.
+type ManagedDERPRegion struct {
+	RegionID   int               `json:"region_id"`
+	RegionCode string            `json:"region_code"`
+	RegionName string            `json:"region_name"`
+	Nodes      []ManagedDERPNode `json:"nodes"`
+	// Avoid prevents clients from picking the region as their home region.
+	// The region is still used to reach peers that picked it.
+	Avoid             bool       `json:"avoid"`
+	Healthy           bool       `json:"healthy"`
+	HealthError       string     `json:"health_error,omitempty"`
+	LastHealthCheckAt *time.Time `json:"last_health_check_at,omitempty" format:"date-time"`
+	CreatedAt         time.Time  `json:"created_at" format:"date-time"`
+	UpdatedAt         time.Time  `json:"updated_at" format:"date-time"`
+}
+
+// ManagedDERPNode is a DERP server of a managed DERP region.
+type ManagedDERPNode struct {
+	Name     string `json:"name" validate:"required"`
+	HostName string `json:"host_name"`
+	IPv4     string `json:"ipv4,omitempty"`
+	IPv6     string `json:"ipv6,omitempty"`
+	// STUNPort defaults to 3478, -1 disables STUN.
+	STUNPort int  `json:"stun_port,omitempty"`
+	STUNOnly bool `json:"stun_only,omitempty"`
+	// DERPPort defaults to 443.
+	DERPPort  int  `json:"derp_port,omitempty"`
+	ForceHTTP bool `json:"force_http,omitempty"`
+}
+
+type CreateManagedDERPRegionRequest struct {
+	RegionID   int               `json:"region_id" validate:"required,min=1"`
+	RegionCode string            `json:"region_code" validate:"required"`
+	RegionName string            `json:"region_name" validate:"required"`
+	Nodes      []ManagedDERPNode `json:"nodes" validate:"required,min=1,dive"`
+	Avoid      bool              `json:"avoid"`
+}
+
+type UpdateManagedDERPRegionRequest struct {
+	RegionCode string            `json:"region_code" validate:"required"`
+	RegionName string            `json:"region_name" validate:"required"`
+	Nodes      []ManagedDERPNode `json:"nodes" validate:"required,min=1,dive"`
+	Avoid      bool              `json:"avoid"`
+}
+
+// DERPRegionPolicy restricts the DERP regions used by the clients of a
+// group's members, or by the agents of a template's workspaces. Users in
+// several restricted groups may only use the regions allowed by all of them.
+type DERPRegionPolicy struct {
+	ID         uuid.UUID  `json:"id" format:"uuid"`
+	GroupID    *uuid.UUID `json:"group_id,omitempty" format:"uuid"`
+	TemplateID *uuid.UUID `json:"template_id,omitempty" format:"uuid"`
+	// RegionIDs are the DERP regions that may be used, including regions
+	// of the static DERP map.
+	RegionIDs []int     `json:"region_ids"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+}
+
+// CreateDERPRegionPolicyRequest creates a policy for exactly one of a group
+// or a template.
+type CreateDERPRegionPolicyRequest struct {
+	GroupID    *uuid.UUID `json:"group_id,omitempty" format:"uuid"`
+	TemplateID *uuid.UUID `json:"template_id,omitempty" format:"uuid"`
+	RegionIDs  []int      `json:"region_ids"`
+}
+
+type UpdateDERPRegionPolicyRequest struct {
+	RegionIDs []int `json:"region_ids"`
+}
+
+func (c *Client) ManagedDERPRegions(ctx context.Context) ([]ManagedDERPRegion, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/derp-regions", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var regions []ManagedDERPRegion
+	return regions, json.NewDecoder(res.Body).Decode(&regions)
+}
+
+func (c *Client) ManagedDERPRegion(ctx context.Context, regionID int) (ManagedDERPRegion, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/derp-regions/%d", regionID), nil)
+	if err != nil {
+		return ManagedDERPRegion{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ManagedDERPRegion{}, ReadBodyAsError(res)
+	}
+	var region ManagedDERPRegion
+	return region, json.NewDecoder(res.Body).Decode(&region)
+}
+
+func (c *Client) CreateManagedDERPRegion(ctx context.Context, req CreateManagedDERPRegionRequest) (ManagedDERPRegion, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/derp-regions", req)
+	if err != nil {
+		return ManagedDERPRegion{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return ManagedDERPRegion{}, ReadBodyAsError(res)
+	}
+	var region ManagedDERPRegion
+	return region, json.NewDecoder(res.Body).Decode(&region)
+}
+
+func (c *Client) UpdateManagedDERPRegion(ctx context.Context, regionID int, req UpdateManagedDERPRegionRequest) (ManagedDERPRegion, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/derp-regions/%d", regionID), req)
+	if err != nil {
+		return ManagedDERPRegion{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ManagedDERPRegion{}, ReadBodyAsError(res)
+	}
+	var region ManagedDERPRegion
+	return region, json.NewDecoder(res.Body).Decode(&region)
+}
+
+func (c *Client) DeleteManagedDERPRegion(ctx context.Context, regionID int) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/derp-regions/%d", regionID), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+func (c *Client) DERPRegionPolicies(ctx context.Context) ([]DERPRegionPolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/derp-region-policies", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var policies []DERPRegionPolicy
+	return policies, json.NewDecoder(res.Body).Decode(&policies)
+}
+
+func (c *Client) CreateDERPRegionPolicy(ctx context.Context, req CreateDERPRegionPolicyRequest) (DERPRegionPolicy, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/derp-region-policies", req)
+	if err != nil {
+		return DERPRegionPolicy{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return DERPRegionPolicy{}, ReadBodyAsError(res)
+	}
+	var policy DERPRegionPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+func (c *Client) UpdateDERPRegionPolicy(ctx context.Context, id uuid.UUID, req UpdateDERPRegionPolicyRequest) (DERPRegionPolicy, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/derp-region-policies/%s", id), req)
+	if err != nil {
+		return DERPRegionPolicy{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return DERPRegionPolicy{}, ReadBodyAsError(res)
+	}
+	var policy DERPRegionPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+func (c *Client) DeleteDERPRegionPolicy(ctx context.Context, id uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/derp-region-policies/%s", id), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// AppInsightsRequest selects the usage included in app insights. The usage is
+// collected per hour, so the start time is rounded down and the end time up to
+// the hour.
+type AppInsightsRequest struct {
+	// StartTime defaults to 30 days before the end time.
+	StartTime time.Time `json:"start_time" format:"date-time"`
+	// EndTime defaults to now.
+	EndTime time.Time `json:"end_time" format:"date-time"`
+	// TemplateIDs limits the insights to apps of the templates. Apps of all
+	// templates are included if empty.
+	TemplateIDs []uuid.UUID `json:"template_ids" format:"uuid"`
+}
+
+func (r AppInsightsRequest) asRequestOption() RequestOption {
+	return func(req *http.Request) {
+		q := req.URL.Query()
+		if !r.StartTime.IsZero() {
+			q.Set("start_time", r.StartTime.Format(time.RFC3339))
+		}
+		if !r.EndTime.IsZero() {
+			q.Set("end_time", r.EndTime.Format(time.RFC3339))
+		}
+		if len(r.TemplateIDs) > 0 {
+			ids := make([]string, 0, len(r.TemplateIDs))
+			for _, id := range r.TemplateIDs {
+				ids = append(ids, id.String())
+			}
+			q.Set("template_ids", strings.Join(ids, ","))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+type AppInsightsResponse struct {
+	StartTime time.Time    `json:"start_time" format:"date-time"`
+	EndTime   time.Time    `json:"end_time" format:"date-time"`
+	Apps      []AppInsight `json:"apps"`
+}
+
+// AppInsight is the usage of an app of a template. Apps declared by the
+// active version of a template are included even if they were not used.
+type AppInsight struct {
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	Slug       string    `json:"slug"`
+	// DisplayName and Icon are empty if the active version of the template
+	// does not declare the app.
+	DisplayName string `json:"display_name"`
+	Icon        string `json:"icon"`
+	// UniqueUsers includes unauthenticated users of public apps as a single
+	// user.
+	UniqueUsers int64 `json:"unique_users"`
+	Requests    int64 `json:"requests"`
+	// ErrorRequests is the number of requests that failed with a 5xx status.
+	ErrorRequests int64 `json:"error_requests"`
+	// AverageLatencyMS is the average time until the app sent the response
+	// headers.
+	AverageLatencyMS float64 `json:"average_latency_ms"`
+	BytesSent        int64   `json:"bytes_sent"`
+}
+
+// AppInsights returns the usage of the apps of all templates, or of the
+// templates in the request.
+func (c *Client) AppInsights(ctx context.Context, req AppInsightsRequest) (AppInsightsResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/insights/apps", nil, req.asRequestOption())
+	if err != nil {
+		return AppInsightsResponse{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return AppInsightsResponse{}, ReadBodyAsError(res)
+	}
+
+	var resp AppInsightsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// TemplateAppInsights returns the usage of the apps of a template. The
+// template IDs of the request are ignored.
+func (c *Client) TemplateAppInsights(ctx context.Context, templateID uuid.UUID, req AppInsightsRequest) (AppInsightsResponse, error) {
+	req.TemplateIDs = nil
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/insights/apps", templateID), nil, req.asRequestOption())
+	if err != nil {
+		return AppInsightsResponse{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return AppInsightsResponse{}, ReadBodyAsError(res)
+	}
+
+	var resp AppInsightsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -0,0 +1,311 @@
+package codersdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OAuth2ProviderApp is a third-party application that can sign users in with
+// Coder.
+type OAuth2ProviderApp struct {
+	ID          uuid.UUID `json:"id" format:"uuid"`
+	Name        string    `json:"name"`
+	CallbackURL string    `json:"callback_url"`
+	Icon        string    `json:"icon"`
+
+	// Endpoints are included in the app response for easier discovery. The
+	// OAuth2 spec does not have a defined place to find these (for comparison,
+	// OIDC has a '/.well-known/openid-configuration' endpoint).
+	Endpoints OAuth2AppEndpoints `json:"endpoints"`
+}
+
+// OAuth2AppEndpoints are the URLs a client uses during the authorization flow.
+type OAuth2AppEndpoints struct {
+	Authorization string `json:"authorization"`
+	Token         string `json:"token"`
+	Revocation    string `json:"revocation"`
+}
+
+type OAuth2ProviderAppFilter struct {
+	// UserID limits the list to apps the user has authorized.
+	UserID uuid.UUID `json:"user_id,omitempty" format:"uuid"`
+}
+
+// OAuth2ProviderApps returns the registered applications.
+func (c *Client) OAuth2ProviderApps(ctx context.Context, filter OAuth2ProviderAppFilter) ([]OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/oauth2-provider/apps", nil,
+		func(r *http.Request) {
+			if filter.UserID != uuid.Nil {
+				q := r.URL.Query()
+				q.Set("user_id", filter.UserID.String())
+				r.URL.RawQuery = q.Encode()
+			}
+		})
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var apps []OAuth2ProviderApp
+	return apps, json.NewDecoder(res.Body).Decode(&apps)
+}
+
+// OAuth2ProviderApp returns an application registered with this Coder
+// deployment.
+func (c *Client) OAuth2ProviderApp(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s", id), nil)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OAuth2ProviderApp{}, ReadBodyAsError(res)
+	}
+	var app OAuth2ProviderApp
+	return app, json.NewDecoder(res.Body).Decode(&app)
+}
+
+type PostOAuth2ProviderAppRequest struct {
+	Name        string `json:"name" validate:"required,max=64"`
+	CallbackURL string `json:"callback_url" validate:"required,http_url"`
+	Icon        string `json:"icon" validate:"omitempty,max=256"`
+}
+
+// PostOAuth2ProviderApp registers a new application.
+func (c *Client) PostOAuth2ProviderApp(ctx context.Context, app PostOAuth2ProviderAppRequest) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/oauth2-provider/apps", app)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return OAuth2ProviderApp{}, ReadBodyAsError(res)
+	}
+	var resp OAuth2ProviderApp
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+type PutOAuth2ProviderAppRequest struct {
+	Name        string `json:"name" validate:"required,max=64"`
+	CallbackURL string `json:"callback_url" validate:"required,http_url"`
+	Icon        string `json:"icon" validate:"omitempty,max=256"`
+}
+
+// PutOAuth2ProviderApp updates an application.
+func (c *Client) PutOAuth2ProviderApp(ctx context.Context, id uuid.UUID, app PutOAuth2ProviderAppRequest) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s", id), app)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OAuth2ProviderApp{}, ReadBodyAsError(res)
+	}
+	var resp OAuth2ProviderApp
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// DeleteOAuth2ProviderApp deletes an application, revoking every token issued
+// to it.
+func (c *Client) DeleteOAuth2ProviderApp(ctx context.Context, id uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s", id), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// OAuth2ProviderAppSecret is a client secret of an application. Only the
+// truncated secret is returned after it has been created.
+type OAuth2ProviderAppSecret struct {
+	ID                    uuid.UUID  `json:"id" format:"uuid"`
+	LastUsedAt            *time.Time `json:"last_used_at,omitempty" format:"date-time"`
+	ClientSecretTruncated string     `json:"client_secret_truncated"`
+}
+
+// OAuth2ProviderAppSecretFull is only returned when a secret is created.
+type OAuth2ProviderAppSecretFull struct {
+	ID               uuid.UUID `json:"id" format:"uuid"`
+	ClientSecretFull string    `json:"client_secret_full"`
+}
+
+// OAuth2ProviderAppSecrets returns the truncated secrets of an application.
+func (c *Client) OAuth2ProviderAppSecrets(ctx context.Context, appID uuid.UUID) ([]OAuth2ProviderAppSecret, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s/secrets", appID), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var secrets []OAuth2ProviderAppSecret
+	return secrets, json.NewDecoder(res.Body).Decode(&secrets)
+}
+
+// PostOAuth2ProviderAppSecret creates a new client secret for an application.
+// The full secret is only returned once.
+func (c *Client) PostOAuth2ProviderAppSecret(ctx context.Context, appID uuid.UUID) (OAuth2ProviderAppSecretFull, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s/secrets", appID), nil)
+	if err != nil {
+		return OAuth2ProviderAppSecretFull{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return OAuth2ProviderAppSecretFull{}, ReadBodyAsError(res)
+	}
+	var secret OAuth2ProviderAppSecretFull
+	return secret, json.NewDecoder(res.Body).Decode(&secret)
+}
+
+// DeleteOAuth2ProviderAppSecret deletes a client secret. Tokens issued with
+// the secret are revoked.
+func (c *Client) DeleteOAuth2ProviderAppSecret(ctx context.Context, appID uuid.UUID, secretID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s/secrets/%s", appID, secretID), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// RevokeOAuth2ProviderApp revokes every token and authorization code the
+// authenticated user has granted to an application.
+func (c *Client) RevokeOAuth2ProviderApp(ctx context.Context, appID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, "/oauth2/tokens", nil, WithQueryParam("client_id", appID.String()))
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// OAuth2DeviceGrantType is the grant_type of device access token requests, see
+// RFC 8628 section 3.4.
+const OAuth2DeviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Error codes returned while polling for a device access token, see RFC 8628
+// section 3.5.
+const (
+	OAuth2ErrorAuthorizationPending = "authorization_pending"
+	OAuth2ErrorSlowDown             = "slow_down"
+	OAuth2ErrorAccessDenied         = "access_denied"
+	OAuth2ErrorExpiredToken         = "expired_token"
+)
+
+// OAuth2DeviceAuthorization is returned when a device authorization request
+// is started, see RFC 8628 section 3.2.
+type OAuth2DeviceAuthorization struct {
+	DeviceCode string `json:"device_code"`
+	// UserCode is entered by the user on the verification page.
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// ExpiresIn is the number of seconds the device code is valid for.
+	ExpiresIn int64 `json:"expires_in"`
+	// Interval is the number of seconds to wait between token requests.
+	Interval int64 `json:"interval"`
+}
+
+// OAuth2DeviceToken is returned once the user approved a device
+// authorization request. The access token is a session token.
+type OAuth2DeviceToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// OAuth2Error is an error response of the OAuth2 token endpoint, see RFC 6749
+// section 5.2.
+type OAuth2Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuth2Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// OAuth2DeviceAuthorization starts a device authorization request for the
+// CLI. The user approves it by entering the user code at the verification
+// URI, while the device polls OAuth2DeviceToken.
+func (c *Client) OAuth2DeviceAuthorization(ctx context.Context, scope string) (OAuth2DeviceAuthorization, error) {
+	form := url.Values{}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+	res, err := c.Request(ctx, http.MethodPost, "/oauth2/device/code", strings.NewReader(form.Encode()), withFormContentType)
+	if err != nil {
+		return OAuth2DeviceAuthorization{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OAuth2DeviceAuthorization{}, readOAuth2Error(res)
+	}
+	var auth OAuth2DeviceAuthorization
+	return auth, json.NewDecoder(res.Body).Decode(&auth)
+}
+
+// OAuth2DeviceToken exchanges the device code of an approved device
+// authorization request for a session token. Until the user has responded an
+// *OAuth2Error with the authorization_pending or slow_down code is returned.
+func (c *Client) OAuth2DeviceToken(ctx context.Context, deviceCode string) (OAuth2DeviceToken, error) {
+	form := url.Values{
+		"grant_type":  {OAuth2DeviceGrantType},
+		"device_code": {deviceCode},
+	}
+	res, err := c.Request(ctx, http.MethodPost, "/oauth2/tokens", strings.NewReader(form.Encode()), withFormContentType)
+	if err != nil {
+		return OAuth2DeviceToken{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OAuth2DeviceToken{}, readOAuth2Error(res)
+	}
+	var token OAuth2DeviceToken
+	return token, json.NewDecoder(res.Body).Decode(&token)
+}
+
+func withFormContentType(r *http.Request) {
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+}
+
+// readOAuth2Error returns an *OAuth2Error if the response is an OAuth2 error
+// response, or the regular API error otherwise.
+func readOAuth2Error(res *http.Response) error {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return xerrors.Errorf("read body: %w", err)
+	}
+	var oauthErr OAuth2Error
+	if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Code != "" {
+		return &oauthErr
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return ReadBodyAsError(res)
+}
@@ -20,6 +20,7 @@ const (
 	ResourceUserData                    RBACResource = "user_data"
 	ResourceOrganizationMember          RBACResource = "organization_member"
 	ResourceLicense                     RBACResource = "license"
+	ResourceOAuth2ProviderApp           RBACResource = "oauth2_app"
 	ResourceDeploymentValues            RBACResource = "deployment_config"
 	ResourceDeploymentStats             RBACResource = "deployment_stats"
 	ResourceReplicas                    RBACResource = "replicas"
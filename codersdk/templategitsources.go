@@ -0,0 +1,111 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TemplateGitSource is a git repository that new versions of a template are
+// created from.
+type TemplateGitSource struct {
+	TemplateID    uuid.UUID `json:"template_id" format:"uuid"`
+	CreatedAt     time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt     time.Time `json:"updated_at" format:"date-time"`
+	CreatedBy     uuid.UUID `json:"created_by" format:"uuid"`
+	RepositoryURL string    `json:"repository_url"`
+	Ref           string    `json:"ref"`
+	Subdirectory  string    `json:"subdirectory"`
+	// GitAuthProviderID is the git auth provider used to authenticate as
+	// CreatedBy when cloning the repository.
+	GitAuthProviderID string `json:"git_auth_provider_id"`
+	// PollIntervalMillis is how often the ref is checked for new commits. Zero
+	// disables polling.
+	PollIntervalMillis int64 `json:"poll_interval_ms"`
+	// HasWebhookSecret is true if push webhooks are accepted for the source.
+	HasWebhookSecret bool       `json:"has_webhook_secret"`
+	LastCommitSHA    string     `json:"last_commit_sha"`
+	LastSyncedAt     *time.Time `json:"last_synced_at,omitempty" format:"date-time"`
+	LastError        string     `json:"last_error"`
+}
+
+// UpdateTemplateGitSourceRequest configures the git source of a template.
+type UpdateTemplateGitSourceRequest struct {
+	RepositoryURL      string `json:"repository_url" validate:"required"`
+	Ref                string `json:"ref" validate:"required"`
+	Subdirectory       string `json:"subdirectory,omitempty"`
+	GitAuthProviderID  string `json:"git_auth_provider_id,omitempty"`
+	PollIntervalMillis int64  `json:"poll_interval_ms,omitempty"`
+	// WebhookSecret is used to verify push webhooks. The existing secret is
+	// kept if omitted, and webhooks are disabled if empty.
+	WebhookSecret *string `json:"webhook_secret,omitempty"`
+}
+
+// SyncTemplateGitSourceRequest triggers a sync of a template git source.
+type SyncTemplateGitSourceRequest struct {
+	// Force creates a new version even if the ref has not changed.
+	Force bool `json:"force,omitempty"`
+}
+
+// TemplateGitSource returns the git source of a template.
+func (c *Client) TemplateGitSource(ctx context.Context, template uuid.UUID) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/git-source", template), nil)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, ReadBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
+
+// UpdateTemplateGitSource sets the git source of a template. New versions are
+// created from the repository once it has been set.
+func (c *Client) UpdateTemplateGitSource(ctx context.Context, template uuid.UUID, req UpdateTemplateGitSourceRequest) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/git-source", template), req)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSource{}, ReadBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
+
+// DeleteTemplateGitSource stops creating versions of a template from git.
+func (c *Client) DeleteTemplateGitSource(ctx context.Context, template uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/templates/%s/git-source", template), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// SyncTemplateGitSource queues a check of the git source of a template for
+// new commits, which creates a template version if the ref has moved. The
+// source is returned as it was before the sync. The sync has finished once
+// LastSyncedAt changes.
+func (c *Client) SyncTemplateGitSource(ctx context.Context, template uuid.UUID, req SyncTemplateGitSourceRequest) (TemplateGitSource, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/git-source/sync", template), req)
+	if err != nil {
+		return TemplateGitSource{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return TemplateGitSource{}, ReadBodyAsError(res)
+	}
+	var source TemplateGitSource
+	return source, json.NewDecoder(res.Body).Decode(&source)
+}
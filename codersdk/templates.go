@@ -46,6 +46,14 @@ type Template struct {
 	// template scheduling feature.
 	FailureTTLMillis    int64 `json:"failure_ttl_ms"`
 	InactivityTTLMillis int64 `json:"inactivity_ttl_ms"`
+
+	// RequireActiveVersion forces workspaces to be started on the active
+	// version. Only template managers may start other versions.
+	RequireActiveVersion bool `json:"require_active_version"`
+
+	// MaxPortShareLevel is the least restrictive level workspace owners may
+	// share ports with.
+	MaxPortShareLevel WorkspacePortShareLevel `json:"max_port_share_level" enums:"owner,group,authenticated,public"`
 }
 
 type TransitionStats struct {
@@ -103,6 +111,9 @@ type UpdateTemplateMeta struct {
 	AllowUserCancelWorkspaceJobs bool  `json:"allow_user_cancel_workspace_jobs,omitempty"`
 	FailureTTLMillis             int64 `json:"failure_ttl_ms,omitempty"`
 	InactivityTTLMillis          int64 `json:"inactivity_ttl_ms,omitempty"`
+	RequireActiveVersion         bool  `json:"require_active_version,omitempty"`
+	// MaxPortShareLevel is left unchanged if unset.
+	MaxPortShareLevel *WorkspacePortShareLevel `json:"max_port_share_level,omitempty" enums:"owner,group,authenticated,public"`
 }
 
 type TemplateExample struct {
@@ -196,6 +207,33 @@ func (c *Client) UpdateActiveTemplateVersion(ctx context.Context, template uuid.
 	return nil
 }
 
+// ArchiveTemplateVersionsRequest selects the versions of a template to
+// archive. If TemplateVersionIDs is empty, every unused version is archived.
+type ArchiveTemplateVersionsRequest struct {
+	TemplateVersionIDs []uuid.UUID `json:"template_version_ids,omitempty" format:"uuid"`
+}
+
+type ArchiveTemplateVersionsResponse struct {
+	TemplateID  uuid.UUID   `json:"template_id" format:"uuid"`
+	ArchivedIDs []uuid.UUID `json:"archived_ids" format:"uuid"`
+}
+
+// ArchiveTemplateVersions archives versions of a template that are neither
+// active nor used by the latest build of a workspace. Their source files
+// are deleted.
+func (c *Client) ArchiveTemplateVersions(ctx context.Context, template uuid.UUID, req ArchiveTemplateVersionsRequest) (ArchiveTemplateVersionsResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/versions/archive", template), req)
+	if err != nil {
+		return ArchiveTemplateVersionsResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ArchiveTemplateVersionsResponse{}, ReadBodyAsError(res)
+	}
+	var resp ArchiveTemplateVersionsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // TemplateVersionsByTemplateRequest defines the request parameters for
 // TemplateVersionsByTemplate.
 type TemplateVersionsByTemplateRequest struct {
@@ -0,0 +1,62 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// TemplateVersionPreset is a named set of rich parameter values that users
+// can pick when creating a workspace from the template version.
+type TemplateVersionPreset struct {
+	ID          uuid.UUID                 `json:"id" format:"uuid"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Parameters  []WorkspaceBuildParameter `json:"parameters"`
+}
+
+// TemplateVersionPresetRequest defines a preset of a template version.
+type TemplateVersionPresetRequest struct {
+	Name        string `json:"name" validate:"required,template_version_name"`
+	Description string `json:"description,omitempty" validate:"lt=256"`
+	// Parameters must reference rich parameters of the template version.
+	// Parameters that are left out are prompted for as usual.
+	Parameters []WorkspaceBuildParameter `json:"parameters" validate:"required"`
+}
+
+// UpdateTemplateVersionPresetsRequest replaces the presets of a template
+// version.
+type UpdateTemplateVersionPresetsRequest struct {
+	Presets []TemplateVersionPresetRequest `json:"presets" validate:"dive"`
+}
+
+// TemplateVersionPresets returns the presets of a template version.
+func (c *Client) TemplateVersionPresets(ctx context.Context, version uuid.UUID) ([]TemplateVersionPreset, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/presets", version), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var presets []TemplateVersionPreset
+	return presets, json.NewDecoder(res.Body).Decode(&presets)
+}
+
+// UpdateTemplateVersionPresets replaces the presets of a template version.
+func (c *Client) UpdateTemplateVersionPresets(ctx context.Context, version uuid.UUID, req UpdateTemplateVersionPresetsRequest) ([]TemplateVersionPreset, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templateversions/%s/presets", version), req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var presets []TemplateVersionPreset
+	return presets, json.NewDecoder(res.Body).Decode(&presets)
+}
@@ -0,0 +1,155 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TemplateVersionRolloutStatus string
+
+const (
+	TemplateVersionRolloutStatusRunning   TemplateVersionRolloutStatus = "running"
+	TemplateVersionRolloutStatusPaused    TemplateVersionRolloutStatus = "paused"
+	TemplateVersionRolloutStatusCompleted TemplateVersionRolloutStatus = "completed"
+	TemplateVersionRolloutStatusAborted   TemplateVersionRolloutStatus = "aborted"
+)
+
+type TemplateVersionRolloutWorkspaceStatus string
+
+const (
+	TemplateVersionRolloutWorkspaceStatusPending   TemplateVersionRolloutWorkspaceStatus = "pending"
+	TemplateVersionRolloutWorkspaceStatusBuilding  TemplateVersionRolloutWorkspaceStatus = "building"
+	TemplateVersionRolloutWorkspaceStatusSucceeded TemplateVersionRolloutWorkspaceStatus = "succeeded"
+	TemplateVersionRolloutWorkspaceStatusFailed    TemplateVersionRolloutWorkspaceStatus = "failed"
+	TemplateVersionRolloutWorkspaceStatusSkipped   TemplateVersionRolloutWorkspaceStatus = "skipped"
+)
+
+// TemplateVersionRollout moves a cohort of a template's workspaces to a
+// template version in batches.
+type TemplateVersionRollout struct {
+	ID                  uuid.UUID                    `json:"id" format:"uuid"`
+	TemplateID          uuid.UUID                    `json:"template_id" format:"uuid"`
+	TemplateVersionID   uuid.UUID                    `json:"template_version_id" format:"uuid"`
+	TemplateVersionName string                       `json:"template_version_name"`
+	CreatedBy           uuid.UUID                    `json:"created_by" format:"uuid"`
+	CreatedAt           time.Time                    `json:"created_at" format:"date-time"`
+	UpdatedAt           time.Time                    `json:"updated_at" format:"date-time"`
+	Status              TemplateVersionRolloutStatus `json:"status" enums:"running,paused,completed,aborted"`
+	// PausedReason is set when the rollout was paused automatically.
+	PausedReason string `json:"paused_reason,omitempty"`
+	// Percentage of the cohort that was selected for the rollout.
+	Percentage int `json:"percentage"`
+	// GroupIDs restrict the cohort to workspaces owned by members of the
+	// groups. Empty selects every workspace of the template.
+	GroupIDs                []uuid.UUID                  `json:"group_ids" format:"uuid"`
+	BatchSize               int                          `json:"batch_size"`
+	FailureThresholdPercent int                          `json:"failure_threshold_percent"`
+	Counts                  TemplateVersionRolloutCounts `json:"counts"`
+	// Workspaces are only included when fetching a single rollout.
+	Workspaces []TemplateVersionRolloutWorkspace `json:"workspaces,omitempty"`
+}
+
+// TemplateVersionRolloutCounts is the number of workspaces in a rollout by
+// status.
+type TemplateVersionRolloutCounts struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Building  int `json:"building"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+type TemplateVersionRolloutWorkspace struct {
+	WorkspaceID        uuid.UUID                             `json:"workspace_id" format:"uuid"`
+	WorkspaceName      string                                `json:"workspace_name"`
+	WorkspaceOwnerName string                                `json:"workspace_owner_name"`
+	BuildID            *uuid.UUID                            `json:"build_id,omitempty" format:"uuid"`
+	Status             TemplateVersionRolloutWorkspaceStatus `json:"status" enums:"pending,building,succeeded,failed,skipped"`
+	// Error is set when a build could not be created for the workspace.
+	Error string `json:"error,omitempty"`
+}
+
+// CreateTemplateVersionRolloutRequest starts a rollout of a template version.
+type CreateTemplateVersionRolloutRequest struct {
+	TemplateVersionID uuid.UUID `json:"template_version_id" validate:"required" format:"uuid"`
+	// Percentage of the cohort to move to the template version.
+	Percentage int `json:"percentage" validate:"required,min=1,max=100"`
+	// GroupIDs restrict the cohort to workspaces owned by members of the
+	// groups.
+	GroupIDs []uuid.UUID `json:"group_ids,omitempty" format:"uuid"`
+	// BatchSize is the maximum number of builds in progress at once.
+	BatchSize int `json:"batch_size" validate:"required,min=1"`
+	// FailureThresholdPercent pauses the rollout when the percentage of
+	// failed builds exceeds it.
+	FailureThresholdPercent int `json:"failure_threshold_percent" validate:"min=0,max=100"`
+}
+
+// UpdateTemplateVersionRolloutRequest pauses, resumes or aborts a rollout.
+type UpdateTemplateVersionRolloutRequest struct {
+	Status TemplateVersionRolloutStatus `json:"status" validate:"required" enums:"running,paused,aborted"`
+}
+
+// CreateTemplateVersionRollout starts moving workspaces of a template to a
+// template version.
+func (c *Client) CreateTemplateVersionRollout(ctx context.Context, template uuid.UUID, req CreateTemplateVersionRolloutRequest) (TemplateVersionRollout, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/rollouts", template), req)
+	if err != nil {
+		return TemplateVersionRollout{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TemplateVersionRollout{}, ReadBodyAsError(res)
+	}
+	var rollout TemplateVersionRollout
+	return rollout, json.NewDecoder(res.Body).Decode(&rollout)
+}
+
+// TemplateVersionRollouts lists the rollouts of a template, most recent
+// first.
+func (c *Client) TemplateVersionRollouts(ctx context.Context, template uuid.UUID) ([]TemplateVersionRollout, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/rollouts", template), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var rollouts []TemplateVersionRollout
+	return rollouts, json.NewDecoder(res.Body).Decode(&rollouts)
+}
+
+// TemplateVersionRollout returns a rollout along with the status of each
+// workspace in it.
+func (c *Client) TemplateVersionRollout(ctx context.Context, template, rollout uuid.UUID) (TemplateVersionRollout, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/rollouts/%s", template, rollout), nil)
+	if err != nil {
+		return TemplateVersionRollout{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionRollout{}, ReadBodyAsError(res)
+	}
+	var resp TemplateVersionRollout
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// UpdateTemplateVersionRollout pauses, resumes or aborts a rollout.
+func (c *Client) UpdateTemplateVersionRollout(ctx context.Context, template, rollout uuid.UUID, req UpdateTemplateVersionRolloutRequest) (TemplateVersionRollout, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templates/%s/rollouts/%s", template, rollout), req)
+	if err != nil {
+		return TemplateVersionRollout{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionRollout{}, ReadBodyAsError(res)
+	}
+	var resp TemplateVersionRollout
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
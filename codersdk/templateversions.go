@@ -17,17 +17,34 @@ const (
 	TemplateVersionWarningUnsupportedWorkspaces TemplateVersionWarning = "UNSUPPORTED_WORKSPACES"
 )
 
+type TemplateVersionState string
+
+const (
+	// TemplateVersionStateDraft is a version that has never been promoted.
+	TemplateVersionStateDraft TemplateVersionState = "draft"
+	// TemplateVersionStateActive is a version that has been promoted and may
+	// be used to build workspaces.
+	TemplateVersionStateActive TemplateVersionState = "active"
+	// TemplateVersionStateDeprecated is a version that may still be used to
+	// build workspaces, but users are warned to update.
+	TemplateVersionStateDeprecated TemplateVersionState = "deprecated"
+	// TemplateVersionStateArchived is a version that can no longer be used to
+	// build workspaces. Its source files have been deleted.
+	TemplateVersionStateArchived TemplateVersionState = "archived"
+)
+
 // TemplateVersion represents a single version of a template.
 type TemplateVersion struct {
-	ID             uuid.UUID      `json:"id" format:"uuid"`
-	TemplateID     *uuid.UUID     `json:"template_id,omitempty" format:"uuid"`
-	OrganizationID uuid.UUID      `json:"organization_id,omitempty" format:"uuid"`
-	CreatedAt      time.Time      `json:"created_at" format:"date-time"`
-	UpdatedAt      time.Time      `json:"updated_at" format:"date-time"`
-	Name           string         `json:"name"`
-	Job            ProvisionerJob `json:"job"`
-	Readme         string         `json:"readme"`
-	CreatedBy      User           `json:"created_by"`
+	ID             uuid.UUID            `json:"id" format:"uuid"`
+	TemplateID     *uuid.UUID           `json:"template_id,omitempty" format:"uuid"`
+	OrganizationID uuid.UUID            `json:"organization_id,omitempty" format:"uuid"`
+	CreatedAt      time.Time            `json:"created_at" format:"date-time"`
+	UpdatedAt      time.Time            `json:"updated_at" format:"date-time"`
+	Name           string               `json:"name"`
+	Job            ProvisionerJob       `json:"job"`
+	Readme         string               `json:"readme"`
+	CreatedBy      User                 `json:"created_by"`
+	State          TemplateVersionState `json:"state" enums:"draft,active,deprecated,archived"`
 
 	Warnings []TemplateVersionWarning `json:"warnings,omitempty" enums:"DEPRECATED_PARAMETERS"`
 }
@@ -85,10 +102,95 @@ type TemplateVersionVariable struct {
 	Sensitive    bool   `json:"sensitive"`
 }
 
+// TemplateVersionDiffChange describes how an entry differs between two
+// template versions.
+type TemplateVersionDiffChange string
+
+const (
+	TemplateVersionDiffChangeAdded    TemplateVersionDiffChange = "added"
+	TemplateVersionDiffChangeRemoved  TemplateVersionDiffChange = "removed"
+	TemplateVersionDiffChangeModified TemplateVersionDiffChange = "modified"
+)
+
+// TemplateVersionDiffKind identifies the type of an entry in the resource
+// section of a template version diff.
+type TemplateVersionDiffKind string
+
+const (
+	TemplateVersionDiffKindResource TemplateVersionDiffKind = "resource"
+	TemplateVersionDiffKindAgent    TemplateVersionDiffKind = "agent"
+	TemplateVersionDiffKindApp      TemplateVersionDiffKind = "app"
+)
+
+// TemplateVersionDiff compares a template version against a base version.
+// Entries that are identical in both versions are omitted.
+type TemplateVersionDiff struct {
+	BaseVersionID uuid.UUID                      `json:"base_version_id" format:"uuid"`
+	VersionID     uuid.UUID                      `json:"version_id" format:"uuid"`
+	Files         []TemplateVersionFileDiff      `json:"files"`
+	Parameters    []TemplateVersionParameterDiff `json:"parameters"`
+	Variables     []TemplateVersionVariableDiff  `json:"variables"`
+	Resources     []TemplateVersionResourceDiff  `json:"resources"`
+}
+
+// TemplateVersionFileDiff is a file that differs between the archives of two
+// template versions.
+type TemplateVersionFileDiff struct {
+	Path   string                    `json:"path"`
+	Change TemplateVersionDiffChange `json:"change" enums:"added,removed,modified"`
+	// Binary is true if either side of the file isn't valid UTF-8 text or
+	// has lines too long to compare. No diff is produced for binary files.
+	Binary bool `json:"binary"`
+	// Diff is the unified diff of the file contents.
+	Diff string `json:"diff,omitempty"`
+}
+
+// TemplateVersionFieldDiff is a single attribute that changed between two
+// template versions.
+type TemplateVersionFieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// TemplateVersionParameterDiff is a rich parameter that was added, removed
+// or modified.
+type TemplateVersionParameterDiff struct {
+	Name   string                     `json:"name"`
+	Change TemplateVersionDiffChange  `json:"change" enums:"added,removed,modified"`
+	Fields []TemplateVersionFieldDiff `json:"fields,omitempty"`
+}
+
+// TemplateVersionVariableDiff is a template variable that was added, removed
+// or modified. Values of sensitive variables are redacted.
+type TemplateVersionVariableDiff struct {
+	Name   string                     `json:"name"`
+	Change TemplateVersionDiffChange  `json:"change" enums:"added,removed,modified"`
+	Fields []TemplateVersionFieldDiff `json:"fields,omitempty"`
+}
+
+// TemplateVersionResourceDiff is a resource, agent or app from the import
+// job that was added, removed or modified. Path identifies the entry, e.g.
+// "docker_container.main/dev/code-server" for the app "code-server" on the
+// agent "dev".
+type TemplateVersionResourceDiff struct {
+	Kind   TemplateVersionDiffKind    `json:"kind" enums:"resource,agent,app"`
+	Path   string                     `json:"path"`
+	Change TemplateVersionDiffChange  `json:"change" enums:"added,removed,modified"`
+	Fields []TemplateVersionFieldDiff `json:"fields,omitempty"`
+}
+
 type PatchTemplateVersionRequest struct {
 	Name string `json:"name" validate:"omitempty,template_version_name"`
 }
 
+// UpdateTemplateVersionStateRequest changes the lifecycle state of a
+// template version. Versions can't be returned to the draft state, and are
+// only made active by promoting them with UpdateActiveTemplateVersion.
+type UpdateTemplateVersionStateRequest struct {
+	State TemplateVersionState `json:"state" validate:"required" enums:"deprecated,archived"`
+}
+
 // TemplateVersion returns a template version by ID.
 func (c *Client) TemplateVersion(ctx context.Context, id uuid.UUID) (TemplateVersion, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s", id), nil)
@@ -172,6 +274,21 @@ func (c *Client) TemplateVersionVariables(ctx context.Context, version uuid.UUID
 	return variables, json.NewDecoder(res.Body).Decode(&variables)
 }
 
+// TemplateVersionDiff compares a template version against a base version of
+// the same template.
+func (c *Client) TemplateVersionDiff(ctx context.Context, base, version uuid.UUID) (TemplateVersionDiff, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/diff?base=%s", version, base), nil)
+	if err != nil {
+		return TemplateVersionDiff{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionDiff{}, ReadBodyAsError(res)
+	}
+	var diff TemplateVersionDiff
+	return diff, json.NewDecoder(res.Body).Decode(&diff)
+}
+
 // TemplateVersionLogsAfter streams logs for a template version that occurred after a specific log ID.
 func (c *Client) TemplateVersionLogsAfter(ctx context.Context, version uuid.UUID, after int64) (<-chan ProvisionerJobLog, io.Closer, error) {
 	return c.provisionerJobLogsAfter(ctx, fmt.Sprintf("/api/v2/templateversions/%s/logs", version), after)
@@ -277,3 +394,17 @@ func (c *Client) UpdateTemplateVersion(ctx context.Context, versionID uuid.UUID,
 	var version TemplateVersion
 	return version, json.NewDecoder(res.Body).Decode(&version)
 }
+
+// UpdateTemplateVersionState deprecates or archives a template version.
+func (c *Client) UpdateTemplateVersionState(ctx context.Context, versionID uuid.UUID, req UpdateTemplateVersionStateRequest) (TemplateVersion, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templateversions/%s/state", versionID), req)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersion{}, ReadBodyAsError(res)
+	}
+	var version TemplateVersion
+	return version, json.NewDecoder(res.Body).Decode(&version)
+}
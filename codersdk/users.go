@@ -68,11 +68,15 @@ type CreateFirstUserResponse struct {
 type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email" format:"email"`
 	Username string `json:"username" validate:"required,username"`
-	Password string `json:"password" validate:"required_if=DisableLogin false"`
+	Password string `json:"password" validate:"required_if=DisableLogin false ServiceAccount false"`
 	// DisableLogin sets the user's login type to 'none'. This prevents the user
 	// from being able to use a password or any other authentication method to login.
 	DisableLogin   bool      `json:"disable_login"`
 	OrganizationID uuid.UUID `json:"organization_id" validate:"" format:"uuid"`
+	// ServiceAccount creates a non-human account owned by the organization.
+	// Service accounts cannot log in, only authenticate with tokens, and are
+	// not counted as licensed users.
+	ServiceAccount bool `json:"service_account,omitempty"`
 }
 
 type UpdateUserProfileRequest struct {
@@ -93,15 +97,37 @@ type UserRoles struct {
 	OrganizationRoles map[uuid.UUID][]string `json:"organization_roles"`
 }
 
+// ImpersonateUserResponse contains a short-lived API key that authenticates
+// as the impersonated user.
+type ImpersonateUserResponse struct {
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expires_at" format:"date-time"`
+}
+
 // LoginWithPasswordRequest enables callers to authenticate with email and password.
 type LoginWithPasswordRequest struct {
 	Email    string `json:"email" validate:"required,email" format:"email"`
 	Password string `json:"password" validate:"required"`
+	// TOTPCode is the code of the user's authenticator app. It is required
+	// when the response of a login without it sets MFARequired or
+	// MFAEnrollmentRequired.
+	TOTPCode string `json:"totp_code,omitempty"`
+	// RecoveryCode can be used once instead of TOTPCode.
+	RecoveryCode string `json:"recovery_code,omitempty"`
 }
 
 // LoginWithPasswordResponse contains a session token for the newly authenticated user.
 type LoginWithPasswordResponse struct {
-	SessionToken string `json:"session_token" validate:"required"`
+	// SessionToken is empty if another factor is required.
+	SessionToken string `json:"session_token"`
+	// MFARequired is set if the login must be repeated with a TOTP or
+	// recovery code.
+	MFARequired bool `json:"mfa_required,omitempty"`
+	// MFAEnrollmentRequired is set if the user must enroll an authenticator
+	// app before logging in. See Client.EnrollTOTPWithPassword.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+	// RecoveryCodes are set when the login verified an enrollment.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 type CreateOrganizationRequest struct {
@@ -113,6 +139,9 @@ type AuthMethods struct {
 	Password AuthMethod     `json:"password"`
 	Github   AuthMethod     `json:"github"`
 	OIDC     OIDCAuthMethod `json:"oidc"`
+	// OIDCProviders are the additional OpenID Connect providers users can
+	// sign in with.
+	OIDCProviders []OIDCProviderAuthMethod `json:"oidc_providers"`
 }
 
 type AuthMethod struct {
@@ -125,6 +154,12 @@ type OIDCAuthMethod struct {
 	IconURL    string `json:"iconUrl"`
 }
 
+type OIDCProviderAuthMethod struct {
+	ID         string `json:"id"`
+	SignInText string `json:"signInText"`
+	IconURL    string `json:"iconUrl"`
+}
+
 // HasFirstUser returns whether the first user has been created.
 func (c *Client) HasFirstUser(ctx context.Context) (bool, error) {
 	res, err := c.Request(ctx, http.MethodGet, "/api/v2/users/first", nil)
@@ -236,6 +271,36 @@ func (c *Client) UpdateUserPassword(ctx context.Context, user string, req Update
 	return nil
 }
 
+// UnlockUser clears the failed login attempts of the user, lifting any
+// lockout.
+func (c *Client) UnlockUser(ctx context.Context, user string) error {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/users/%s/unlock", user), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// ImpersonateUser creates a short-lived API key for the user. Only owners
+// can impersonate other users, and the audit logs of the actions taken with
+// the key record the owner as the impersonator.
+func (c *Client) ImpersonateUser(ctx context.Context, user string) (ImpersonateUserResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/impersonate", user), nil)
+	if err != nil {
+		return ImpersonateUserResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return ImpersonateUserResponse{}, ReadBodyAsError(res)
+	}
+	var resp ImpersonateUserResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // UpdateUserRoles grants the userID the specified roles.
 // Include ALL roles the user has.
 func (c *Client) UpdateUserRoles(ctx context.Context, user string, req UpdateRoles) (User, error) {
@@ -288,7 +353,8 @@ func (c *Client) LoginWithPassword(ctx context.Context, req LoginWithPasswordReq
 		return LoginWithPasswordResponse{}, err
 	}
 	defer res.Body.Close()
-	if res.StatusCode != http.StatusCreated {
+	// A login that requires another factor succeeds without a session.
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
 		return LoginWithPasswordResponse{}, ReadBodyAsError(res)
 	}
 	var resp LoginWithPasswordResponse
@@ -0,0 +1,135 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// UserTOTP is the time-based one-time password (TOTP) enrollment of a
+// password user.
+type UserTOTP struct {
+	// Enabled is true once an enrollment was verified. Password logins of the
+	// user then require a code from their authenticator app.
+	Enabled bool `json:"enabled"`
+	// Required is true if the deployment requires the user to enroll.
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment is a new enrollment that must be verified with a code before
+// it is enabled.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URL is the otpauth:// URL authenticator apps import the secret from.
+	URL string `json:"url"`
+}
+
+type VerifyTOTPRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// ResetTOTPRequest confirms the reset of your own enrollment with a current
+// code or your password. It is ignored when resetting the enrollment of
+// another user.
+type ResetTOTPRequest struct {
+	Code     string `json:"code,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// TOTPRecoveryCodes can each be used once to log in instead of a code.
+type TOTPRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// UserTOTP returns the TOTP enrollment status of the user.
+func (c *Client) UserTOTP(ctx context.Context, user string) (UserTOTP, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/totp", user), nil)
+	if err != nil {
+		return UserTOTP{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserTOTP{}, ReadBodyAsError(res)
+	}
+	var totp UserTOTP
+	return totp, json.NewDecoder(res.Body).Decode(&totp)
+}
+
+// EnrollTOTP starts a new enrollment for the user. It is enabled once
+// verified with VerifyTOTP.
+func (c *Client) EnrollTOTP(ctx context.Context, user string) (TOTPEnrollment, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/totp", user), nil)
+	if err != nil {
+		return TOTPEnrollment{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TOTPEnrollment{}, ReadBodyAsError(res)
+	}
+	var enrollment TOTPEnrollment
+	return enrollment, json.NewDecoder(res.Body).Decode(&enrollment)
+}
+
+// VerifyTOTP enables the pending enrollment of the user and returns their
+// recovery codes.
+func (c *Client) VerifyTOTP(ctx context.Context, user string, req VerifyTOTPRequest) (TOTPRecoveryCodes, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/totp/verify", user), req)
+	if err != nil {
+		return TOTPRecoveryCodes{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TOTPRecoveryCodes{}, ReadBodyAsError(res)
+	}
+	var codes TOTPRecoveryCodes
+	return codes, json.NewDecoder(res.Body).Decode(&codes)
+}
+
+// RegenerateTOTPRecoveryCodes replaces the recovery codes of the user. A
+// current code is required.
+func (c *Client) RegenerateTOTPRecoveryCodes(ctx context.Context, user string, req VerifyTOTPRequest) (TOTPRecoveryCodes, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/totp/recovery-codes", user), req)
+	if err != nil {
+		return TOTPRecoveryCodes{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TOTPRecoveryCodes{}, ReadBodyAsError(res)
+	}
+	var codes TOTPRecoveryCodes
+	return codes, json.NewDecoder(res.Body).Decode(&codes)
+}
+
+// ResetTOTP removes the enrollment of the user. Admins use this when a user
+// lost their authenticator app and recovery codes.
+func (c *Client) ResetTOTP(ctx context.Context, user string, req ResetTOTPRequest) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/totp", user), req)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// EnrollTOTPWithPassword starts an enrollment for a user that must enroll
+// before they can log in. The enrollment is verified by logging in with a
+// code.
+func (c *Client) EnrollTOTPWithPassword(ctx context.Context, req LoginWithPasswordRequest) (TOTPEnrollment, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/users/login/totp", req)
+	if err != nil {
+		return TOTPEnrollment{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TOTPEnrollment{}, ReadBodyAsError(res)
+	}
+	var enrollment TOTPEnrollment
+	return enrollment, json.NewDecoder(res.Body).Decode(&enrollment)
+}
@@ -159,6 +159,9 @@ type WorkspaceAgent struct {
 	ShutdownScript               string         `json:"shutdown_script,omitempty"`
 	ShutdownScriptTimeoutSeconds int32          `json:"shutdown_script_timeout_seconds"`
 	Subsystem                    AgentSubsystem `json:"subsystem"`
+	// PeerConnections are the tailnet connections to the agent the last time
+	// it reported stats. They're empty when the agent isn't connected.
+	PeerConnections []WorkspaceAgentPeerConnection `json:"peer_connections"`
 }
 
 type DERPRegion struct {
@@ -166,6 +169,15 @@ type DERPRegion struct {
 	LatencyMilliseconds float64 `json:"latency_ms"`
 }
 
+// WorkspaceAgentPeerConnection is a tailnet connection to a workspace agent.
+// Traffic of connections that aren't direct is relayed through DERP.
+type WorkspaceAgentPeerConnection struct {
+	Direct              bool    `json:"direct"`
+	DERPRegionID        int     `json:"derp_region_id,omitempty"`
+	DERPRegionName      string  `json:"derp_region_name,omitempty"`
+	LatencyMilliseconds float64 `json:"latency_ms"`
+}
+
 // WorkspaceAgentConnectionInfo returns required information for establishing
 // a connection with a workspace.
 // @typescript-ignore WorkspaceAgentConnectionInfo
@@ -226,6 +238,9 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 	if err != nil {
 		return nil, xerrors.Errorf("parse url: %w", err)
 	}
+	q := coordinateURL.Query()
+	q.Set(tailnet.CoordinateVersionQueryParam, strconv.Itoa(tailnet.CurrentCoordinateVersion))
+	coordinateURL.RawQuery = q.Encode()
 	coordinateHeaders := make(http.Header)
 	tokenHeader := SessionTokenHeader
 	if c.SessionTokenHeader != "" {
@@ -267,12 +282,26 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 				options.Logger.Debug(ctx, "failed to dial", slog.Error(err))
 				continue
 			}
-			sendNode, errChan := tailnet.ServeCoordinator(websocket.NetConn(ctx, ws, websocket.MessageBinary), func(node []*tailnet.Node) error {
-				return conn.UpdateNodes(node, false)
-			})
-			conn.SetNodeCallback(sendNode)
-			options.Logger.Debug(ctx, "serving coordinator")
-			err = <-errChan
+			wsConn := websocket.NetConn(ctx, ws, websocket.MessageBinary)
+			// Older servers ignore the version query parameter and only
+			// speak version 1 of the coordination protocol.
+			if res.Header.Get(tailnet.CoordinateVersionHeader) == strconv.Itoa(tailnet.CoordinateVersion2) {
+				coordination := tailnet.NewRemoteCoordination(options.Logger, wsConn, conn, agentID)
+				options.Logger.Debug(ctx, "serving coordinator")
+				select {
+				case <-ctx.Done():
+					err = ctx.Err()
+				case err = <-coordination.Error():
+				}
+				_ = coordination.Close()
+			} else {
+				sendNode, errChan := tailnet.ServeCoordinator(wsConn, func(node []*tailnet.Node) error {
+					return conn.UpdateNodes(node, false)
+				})
+				conn.SetNodeCallback(sendNode)
+				options.Logger.Debug(ctx, "serving coordinator")
+				err = <-errChan
+			}
 			if errors.Is(err, context.Canceled) {
 				_ = ws.Close(websocket.StatusGoingAway, "")
 				return
@@ -290,11 +319,37 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 		return nil, err
 	}
 
+	derpMapDone := make(chan struct{})
+	go func() {
+		defer close(derpMapDone)
+		for retrier := retry.New(50*time.Millisecond, 10*time.Second); retrier.Wait(ctx); {
+			derpMapUpdates, err := c.DERPMapUpdates(ctx)
+			if err != nil {
+				var sdkErr *Error
+				if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+					// Older versions of coderd don't stream DERP map updates,
+					// in which case the connection keeps using the DERP map
+					// of the connection info.
+					options.Logger.Debug(ctx, "derp map updates are unsupported")
+					return
+				}
+				options.Logger.Debug(ctx, "failed to stream derp map updates", slog.Error(err))
+				continue
+			}
+			for derpMap := range derpMapUpdates {
+				options.Logger.Debug(ctx, "updating derp map")
+				conn.SetDERPMap(derpMap)
+				retrier.Reset()
+			}
+		}
+	}()
+
 	agentConn = &WorkspaceAgentConn{
 		Conn: conn,
 		CloseFunc: func() {
 			cancel()
 			<-closed
+			<-derpMapDone
 		},
 	}
 	if !agentConn.AwaitReachable(ctx) {
@@ -305,6 +360,58 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 	return agentConn, nil
 }
 
+// DERPMapUpdates streams the DERP map used by the authenticated user to
+// connect to workspace agents. The current DERP map is sent first, followed
+// by every change. The channel is closed when ctx is canceled or the stream
+// ends.
+func (c *Client) DERPMapUpdates(ctx context.Context) (<-chan *tailcfg.DERPMap, error) {
+	//nolint:bodyclose
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/derp-map", nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	nextEvent := ServerSentEventReader(ctx, res.Body)
+
+	updates := make(chan *tailcfg.DERPMap)
+	go func() {
+		defer close(updates)
+		defer res.Body.Close()
+
+		for {
+			sse, err := nextEvent()
+			if err != nil {
+				return
+			}
+			if sse.Type == ServerSentEventTypeError {
+				// The server stops sending updates after an error.
+				return
+			}
+			if sse.Type != ServerSentEventTypeData {
+				continue
+			}
+			b, ok := sse.Data.([]byte)
+			if !ok {
+				return
+			}
+			var derpMap tailcfg.DERPMap
+			err = json.Unmarshal(b, &derpMap)
+			if err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case updates <- &derpMap:
+			}
+		}
+	}()
+	return updates, nil
+}
+
 // WatchWorkspaceAgentMetadata watches the metadata of a workspace agent.
 // The returned channel will be closed when the context is canceled. Exactly
 // one error will be sent on the error channel. The metadata channel is never closed.
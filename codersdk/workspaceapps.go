@@ -19,6 +19,7 @@ const (
 	WorkspaceAppSharingLevelOwner         WorkspaceAppSharingLevel = "owner"
 	WorkspaceAppSharingLevelAuthenticated WorkspaceAppSharingLevel = "authenticated"
 	WorkspaceAppSharingLevelPublic        WorkspaceAppSharingLevel = "public"
+	WorkspaceAppSharingLevelGroup         WorkspaceAppSharingLevel = "group"
 )
 
 type WorkspaceApp struct {
@@ -42,7 +43,10 @@ type WorkspaceApp struct {
 	// and there is no app wildcard configured on the server, the app will not
 	// be accessible in the UI.
 	Subdomain    bool                     `json:"subdomain"`
-	SharingLevel WorkspaceAppSharingLevel `json:"sharing_level" enums:"owner,authenticated,public"`
+	SharingLevel WorkspaceAppSharingLevel `json:"sharing_level" enums:"owner,authenticated,public,group"`
+	// SharingGroups are the names of the groups whose members may access the
+	// app if the sharing level is "group".
+	SharingGroups []string `json:"sharing_groups,omitempty"`
 	// Healthcheck specifies the configuration for checking app health.
 	Healthcheck Healthcheck        `json:"healthcheck"`
 	Health      WorkspaceAppHealth `json:"health"`
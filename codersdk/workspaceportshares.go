@@ -0,0 +1,128 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspacePortShareLevel controls who may reach a workspace port through
+// the application proxy. Levels are ordered from most to least restrictive.
+type WorkspacePortShareLevel string
+
+const (
+	WorkspacePortShareLevelOwner         WorkspacePortShareLevel = "owner"
+	WorkspacePortShareLevelGroup         WorkspacePortShareLevel = "group"
+	WorkspacePortShareLevelAuthenticated WorkspacePortShareLevel = "authenticated"
+	WorkspacePortShareLevelPublic        WorkspacePortShareLevel = "public"
+)
+
+// WorkspacePortShareLevels lists every level from most to least restrictive.
+var WorkspacePortShareLevels = []WorkspacePortShareLevel{
+	WorkspacePortShareLevelOwner,
+	WorkspacePortShareLevelGroup,
+	WorkspacePortShareLevelAuthenticated,
+	WorkspacePortShareLevelPublic,
+}
+
+// Valid returns whether the level is known.
+func (l WorkspacePortShareLevel) Valid() bool {
+	return l.rank() >= 0
+}
+
+// Exceeds returns whether the level is less restrictive than maxLevel.
+func (l WorkspacePortShareLevel) Exceeds(maxLevel WorkspacePortShareLevel) bool {
+	return l.rank() > maxLevel.rank()
+}
+
+func (l WorkspacePortShareLevel) rank() int {
+	for i, level := range WorkspacePortShareLevels {
+		if level == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// WorkspacePortShare is a port of a workspace agent that the workspace owner
+// shared with other users.
+type WorkspacePortShare struct {
+	WorkspaceID uuid.UUID `json:"workspace_id" format:"uuid"`
+	AgentName   string    `json:"agent_name"`
+	Port        int32     `json:"port"`
+	// ShareLevel is the level the port was shared with. Shares that exceed
+	// the maximum level of the template are capped when enforced.
+	ShareLevel WorkspacePortShareLevel `json:"share_level" enums:"owner,group,authenticated,public"`
+	// GroupID is set when the port is shared with a group.
+	GroupID *uuid.UUID `json:"group_id,omitempty" format:"uuid"`
+	// ExpiresAt is unset when the share does not expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" format:"date-time"`
+	CreatedAt time.Time  `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time  `json:"updated_at" format:"date-time"`
+}
+
+// UpsertWorkspacePortShareRequest shares a port of a workspace agent, or
+// changes how an already shared port is shared.
+type UpsertWorkspacePortShareRequest struct {
+	// AgentName may be left empty if the workspace has a single agent.
+	AgentName string `json:"agent_name,omitempty"`
+	Port      int32  `json:"port" validate:"required"`
+	// ShareLevel may not exceed the maximum level of the template.
+	ShareLevel WorkspacePortShareLevel `json:"share_level" validate:"required" enums:"group,authenticated,public"`
+	// GroupID is required when ShareLevel is "group".
+	GroupID   *uuid.UUID `json:"group_id,omitempty" format:"uuid"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" format:"date-time"`
+}
+
+// DeleteWorkspacePortShareRequest stops sharing a port of a workspace agent.
+type DeleteWorkspacePortShareRequest struct {
+	// AgentName may be left empty if the workspace has a single agent.
+	AgentName string `json:"agent_name,omitempty"`
+	Port      int32  `json:"port" validate:"required"`
+}
+
+// WorkspacePortShares returns the shared ports of a workspace, including
+// expired shares.
+func (c *Client) WorkspacePortShares(ctx context.Context, workspace uuid.UUID) ([]WorkspacePortShare, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/port-shares", workspace), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var shares []WorkspacePortShare
+	return shares, json.NewDecoder(res.Body).Decode(&shares)
+}
+
+// UpsertWorkspacePortShare shares a port of a workspace agent.
+func (c *Client) UpsertWorkspacePortShare(ctx context.Context, workspace uuid.UUID, req UpsertWorkspacePortShareRequest) (WorkspacePortShare, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/workspaces/%s/port-shares", workspace), req)
+	if err != nil {
+		return WorkspacePortShare{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspacePortShare{}, ReadBodyAsError(res)
+	}
+	var share WorkspacePortShare
+	return share, json.NewDecoder(res.Body).Decode(&share)
+}
+
+// DeleteWorkspacePortShare stops sharing a port of a workspace agent.
+func (c *Client) DeleteWorkspacePortShare(ctx context.Context, workspace uuid.UUID, req DeleteWorkspacePortShareRequest) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/workspaces/%s/port-shares", workspace), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
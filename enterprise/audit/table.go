@@ -14,15 +14,21 @@ import (
 // AuditableResources map (below) as our documentation - generated in scripts/auditdocgen/main.go -
 // depends upon it.
 var AuditActionMap = map[string][]codersdk.AuditAction{
-	"GitSSHKey":       {codersdk.AuditActionCreate},
-	"Template":        {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"TemplateVersion": {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
-	"User":            {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"Workspace":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"WorkspaceBuild":  {codersdk.AuditActionStart, codersdk.AuditActionStop},
-	"Group":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"APIKey":          {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
-	"License":         {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"GitSSHKey":               {codersdk.AuditActionCreate},
+	"Template":                {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"TemplateVersion":         {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
+	"User":                    {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete, codersdk.AuditActionLockout},
+	"Workspace":               {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete, codersdk.AuditActionConnect},
+	"WorkspaceBuild":          {codersdk.AuditActionStart, codersdk.AuditActionStop},
+	"Group":                   {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"APIKey":                  {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"License":                 {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"OAuth2ProviderApp":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"OAuth2ProviderAppSecret": {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"TemplateVersionRollout":  {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
+	"WorkspacePortShare":      {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"DERPRegion":              {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"DERPRegionPolicy":        {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
 }
 
 type Action string
@@ -77,6 +83,8 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"max_ttl":                          ActionTrack,
 		"failure_ttl":                      ActionTrack,
 		"inactivity_ttl":                   ActionTrack,
+		"require_active_version":           ActionTrack,
+		"max_port_share_level":             ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                 ActionTrack,
@@ -89,6 +97,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"job_id":             ActionIgnore, // Not helpful in a diff because jobs aren't tracked in audit logs.
 		"created_by":         ActionTrack,
 		"git_auth_providers": ActionIgnore, // Not helpful because this can only change when new versions are added.
+		"state":              ActionTrack,
 	},
 	&database.User{}: {
 		"id":              ActionTrack,
@@ -154,6 +163,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"ip_address":       ActionIgnore,
 		"scope":            ActionIgnore,
 		"token_name":       ActionIgnore,
+		"user_agent":       ActionIgnore,
 	},
 	// TODO: track an ID here when the below ticket is completed:
 	// https://github.com/coder/coder/pull/6012
@@ -176,6 +186,68 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"deleted":             ActionIgnore,
 		"token_hashed_secret": ActionSecret,
 	},
+	&database.OAuth2ProviderApp{}: {
+		"id":           ActionIgnore,
+		"created_at":   ActionIgnore,
+		"updated_at":   ActionIgnore,
+		"name":         ActionTrack,
+		"icon":         ActionTrack,
+		"callback_url": ActionTrack,
+	},
+	&database.OAuth2ProviderAppSecret{}: {
+		"id":             ActionIgnore,
+		"created_at":     ActionIgnore,
+		"last_used_at":   ActionIgnore,
+		"hashed_secret":  ActionIgnore,
+		"secret_prefix":  ActionIgnore,
+		"display_secret": ActionIgnore,
+		"app_id":         ActionIgnore,
+	},
+	&database.TemplateVersionRollout{}: {
+		"id":                        ActionTrack,
+		"template_id":               ActionTrack,
+		"template_version_id":       ActionTrack,
+		"created_by":                ActionTrack,
+		"created_at":                ActionIgnore,
+		"updated_at":                ActionIgnore,
+		"status":                    ActionTrack,
+		"percentage":                ActionTrack,
+		"group_ids":                 ActionTrack,
+		"batch_size":                ActionTrack,
+		"failure_threshold_percent": ActionTrack,
+		"paused_reason":             ActionTrack,
+	},
+	&database.WorkspacePortShare{}: {
+		"workspace_id": ActionTrack,
+		"agent_name":   ActionTrack,
+		"port":         ActionTrack,
+		"share_level":  ActionTrack,
+		"group_id":     ActionTrack,
+		"expires_at":   ActionTrack,
+		"created_at":   ActionIgnore,
+		"updated_at":   ActionIgnore,
+	},
+	&database.DERPRegion{}: {
+		"id":                   ActionTrack,
+		"region_id":            ActionTrack,
+		"region_code":          ActionTrack,
+		"region_name":          ActionTrack,
+		"nodes":                ActionTrack,
+		"avoid":                ActionTrack,
+		"healthy":              ActionIgnore, // Changed by health checks, not by users.
+		"health_error":         ActionIgnore,
+		"last_health_check_at": ActionIgnore,
+		"created_at":           ActionIgnore,
+		"updated_at":           ActionIgnore,
+	},
+	&database.DERPRegionPolicy{}: {
+		"id":          ActionTrack,
+		"group_id":    ActionTrack,
+		"template_id": ActionTrack,
+		"region_ids":  ActionTrack,
+		"created_at":  ActionIgnore,
+		"updated_at":  ActionIgnore,
+	},
 }
 
 // auditMap converts a map of struct pointers to a map of struct names as
@@ -14,14 +14,17 @@ import (
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
+	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd"
 	agplaudit "github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/derpmap"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/license"
 	"github.com/coder/coder/enterprise/coderd/proxyhealth"
@@ -48,6 +51,12 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 	if options.PrometheusRegistry == nil {
 		options.PrometheusRegistry = prometheus.NewRegistry()
 	}
+	if options.DERPRegionHealthInterval == 0 {
+		options.DERPRegionHealthInterval = time.Minute
+	}
+	if options.DERPRegionHealthCheck == nil {
+		options.DERPRegionHealthCheck = checkDERPRegion
+	}
 	if options.Options.Authorizer == nil {
 		options.Options.Authorizer = rbac.NewCachingAuthorizer(options.PrometheusRegistry)
 	}
@@ -66,15 +75,18 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 	}()
 
 	api.AGPL.Options.SetUserGroups = api.setUserGroups
+	api.AGPL.Options.SetUserSiteRoles = api.setUserSiteRoles
 
 	oauthConfigs := &httpmw.OAuth2Configs{
-		Github: options.GithubOAuth2Config,
-		OIDC:   options.OIDCConfig,
+		Github:        options.GithubOAuth2Config,
+		OIDC:          options.OIDCConfig,
+		OIDCProviders: coderd.OIDCProviderOAuth2Configs(options.OIDCProviders),
 	}
 	apiKeyMiddleware := httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
-		DB:              options.Database,
-		OAuth2Configs:   oauthConfigs,
-		RedirectToLogin: false,
+		DB:                   options.Database,
+		OAuth2Configs:        oauthConfigs,
+		RedirectToLogin:      false,
+		DisableImpersonation: options.DeploymentValues.DisableImpersonation.Value(),
 	})
 
 	deploymentID, err := options.Database.GetDeploymentID(ctx)
@@ -124,6 +136,7 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Post("/issue-signed-app-token", api.workspaceProxyIssueSignedAppToken)
 				r.Post("/register", api.workspaceProxyRegister)
 				r.Post("/goingaway", api.workspaceProxyGoingAway)
+				r.Post("/app-usage", api.workspaceProxyReportAppUsage)
 			})
 			r.Route("/{workspaceproxy}", func(r chi.Router) {
 				r.Use(
@@ -180,6 +193,31 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 			r.Patch("/", api.patchGroup)
 			r.Delete("/", api.deleteGroup)
 		})
+		r.Route("/derp-regions", func(r chi.Router) {
+			r.Use(
+				api.templateRBACEnabledMW,
+				apiKeyMiddleware,
+			)
+			r.Get("/", api.managedDERPRegions)
+			r.Post("/", api.postManagedDERPRegion)
+			r.Route("/{region}", func(r chi.Router) {
+				r.Get("/", api.managedDERPRegion)
+				r.Patch("/", api.patchManagedDERPRegion)
+				r.Delete("/", api.deleteManagedDERPRegion)
+			})
+		})
+		r.Route("/derp-region-policies", func(r chi.Router) {
+			r.Use(
+				api.templateRBACEnabledMW,
+				apiKeyMiddleware,
+			)
+			r.Get("/", api.derpRegionPolicies)
+			r.Post("/", api.postDERPRegionPolicy)
+			r.Route("/{policy}", func(r chi.Router) {
+				r.Patch("/", api.patchDERPRegionPolicy)
+				r.Delete("/", api.deleteDERPRegionPolicy)
+			})
+		})
 		r.Route("/workspace-quota", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -201,6 +239,10 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 	if len(options.SCIMAPIKey) != 0 {
 		api.AGPL.RootHandler.Route("/scim/v2", func(r chi.Router) {
 			r.Use(
+				// The root handler is mounted before the API middleware,
+				// but SCIM requests are audited.
+				tracing.StatusWriterMiddleware,
+				httpmw.AttachRequestID,
 				api.scimEnabledMW,
 			)
 			r.Post("/Users", api.scimPostUser)
@@ -208,7 +250,17 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Get("/", api.scimGetUsers)
 				r.Post("/", api.scimPostUser)
 				r.Get("/{id}", api.scimGetUser)
+				r.Put("/{id}", api.scimPutUser)
 				r.Patch("/{id}", api.scimPatchUser)
+				r.Delete("/{id}", api.scimDeleteUser)
+			})
+			r.Route("/Groups", func(r chi.Router) {
+				r.Get("/", api.scimGetGroups)
+				r.Post("/", api.scimPostGroup)
+				r.Get("/{id}", api.scimGetGroup)
+				r.Put("/{id}", api.scimPutGroup)
+				r.Patch("/{id}", api.scimPatchGroup)
+				r.Delete("/{id}", api.scimDeleteGroup)
 			})
 		})
 	}
@@ -273,6 +325,7 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 		return nil, xerrors.Errorf("update entitlements: %w", err)
 	}
 	go api.runEntitlementsLoop(ctx)
+	go api.runDERPRegionHealthLoop(ctx)
 
 	return api, nil
 }
@@ -292,7 +345,12 @@ type Options struct {
 
 	EntitlementsUpdateInterval time.Duration
 	ProxyHealthInterval        time.Duration
+	DERPRegionHealthInterval   time.Duration
 	Keys                       map[string]ed25519.PublicKey
+
+	// DERPRegionHealthCheck returns an error if the managed DERP region is
+	// unusable. Defaults to exchanging messages through every node.
+	DERPRegionHealthCheck func(ctx context.Context, region *tailcfg.DERPRegion) error
 }
 
 type API struct {
@@ -342,6 +400,7 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 			codersdk.FeatureExternalProvisionerDaemons: true,
 			codersdk.FeatureAdvancedTemplateScheduling: true,
 			codersdk.FeatureWorkspaceProxy:             true,
+			codersdk.FeatureUserRoleManagement:         api.AGPL.OIDCConfig != nil && api.AGPL.OIDCConfig.UserRoleField != "",
 		})
 	if err != nil {
 		return err
@@ -393,9 +452,16 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 			committer := committer{Database: api.Database}
 			ptr := proto.QuotaCommitter(&committer)
 			api.AGPL.QuotaCommitter.Store(&ptr)
+
+			provider := derpmap.Provider(&derpMapProvider{db: api.Database, derpMap: api.DERPMap, watcher: api.AGPL.DERPMapWatcher})
+			api.AGPL.DERPMapProvider.Store(&provider)
 		} else {
 			api.AGPL.QuotaCommitter.Store(nil)
+
+			provider := derpmap.NewAGPLProvider(api.DERPMap)
+			api.AGPL.DERPMapProvider.Store(&provider)
 		}
+		api.publishDERPMapUpdate(ctx)
 	}
 
 	if changed, enabled := featureChanged(codersdk.FeatureAdvancedTemplateScheduling); changed {
@@ -412,7 +478,15 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 	if changed, enabled := featureChanged(codersdk.FeatureHighAvailability); changed {
 		coordinator := agpltailnet.NewCoordinator(api.Logger)
 		if enabled {
-			haCoordinator, err := tailnet.NewCoordinator(api.Logger, api.Pubsub)
+			var haCoordinator agpltailnet.Coordinator
+			var err error
+			if api.AGPL.Experiments.Enabled(codersdk.ExperimentTailnetPGCoordinator) {
+				// The coordinator outlives api.ctx, since it marks its peers
+				// as lost when it is closed on shutdown.
+				haCoordinator, err = tailnet.NewPGCoord(context.Background(), api.Logger, api.Pubsub, api.Database)
+			} else {
+				haCoordinator, err = tailnet.NewCoordinator(api.Logger, api.Pubsub)
+			}
 			if err != nil {
 				api.Logger.Error(ctx, "unable to set up high availability coordinator", slog.Error(err))
 				// If we try to setup the HA coordinator and it fails, nothing
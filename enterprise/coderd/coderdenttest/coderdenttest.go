@@ -13,6 +13,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
 
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/codersdk"
@@ -48,6 +49,8 @@ type Options struct {
 	SCIMAPIKey                 []byte
 	UserWorkspaceQuota         int
 	ProxyHealthInterval        time.Duration
+	DERPRegionHealthInterval   time.Duration
+	DERPRegionHealthCheck      func(ctx context.Context, region *tailcfg.DERPRegion) error
 }
 
 // New constructs a codersdk client connected to an in-memory Enterprise API instance.
@@ -77,6 +80,8 @@ func NewWithAPI(t *testing.T, options *Options) (*codersdk.Client, io.Closer, *c
 		EntitlementsUpdateInterval: options.EntitlementsUpdateInterval,
 		Keys:                       Keys,
 		ProxyHealthInterval:        options.ProxyHealthInterval,
+		DERPRegionHealthInterval:   options.DERPRegionHealthInterval,
+		DERPRegionHealthCheck:      options.DERPRegionHealthCheck,
 	})
 	require.NoError(t, err)
 	setHandler(coderAPI.AGPL.RootHandler)
@@ -22,6 +22,7 @@ import (
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd"
 	"github.com/coder/coder/enterprise/wsproxy"
+	"github.com/coder/coder/testutil"
 )
 
 type ProxyOptions struct {
@@ -117,22 +118,26 @@ func NewWorkspaceProxy(t *testing.T, coderdAPI *coderd.API, owner *codersdk.Clie
 	require.NoError(t, err, "failed to create workspace proxy")
 
 	wssrv, err := wsproxy.New(ctx, &wsproxy.Options{
-		Logger:            slogtest.Make(t, nil).Leveled(slog.LevelDebug),
-		DashboardURL:      coderdAPI.AccessURL,
-		AccessURL:         accessURL,
-		AppHostname:       options.AppHostname,
-		AppHostnameRegex:  appHostnameRegex,
-		RealIPConfig:      coderdAPI.RealIPConfig,
-		Tracing:           coderdAPI.TracerProvider,
-		APIRateLimit:      coderdAPI.APIRateLimit,
-		SecureAuthCookie:  coderdAPI.SecureAuthCookie,
-		ProxySessionToken: proxyRes.ProxyToken,
-		DisablePathApps:   options.DisablePathApps,
+		Logger:                slogtest.Make(t, nil).Leveled(slog.LevelDebug),
+		DashboardURL:          coderdAPI.AccessURL,
+		AccessURL:             accessURL,
+		AppHostname:           options.AppHostname,
+		AppHostnameRegex:      appHostnameRegex,
+		RealIPConfig:          coderdAPI.RealIPConfig,
+		Tracing:               coderdAPI.TracerProvider,
+		APIRateLimit:          coderdAPI.APIRateLimit,
+		SecureAuthCookie:      coderdAPI.SecureAuthCookie,
+		ProxySessionToken:     proxyRes.ProxyToken,
+		DisablePathApps:       options.DisablePathApps,
+		AppUsageFlushInterval: testutil.IntervalFast,
 		// We need a new registry to not conflict with the coderd internal
 		// proxy metrics.
 		PrometheusRegistry: prometheus.NewRegistry(),
 	})
 	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = wssrv.Close()
+	})
 
 	mutex.Lock()
 	handler = wssrv.Handler
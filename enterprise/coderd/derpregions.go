@@ -0,0 +1,970 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/derpmap"
+	"github.com/coder/coder/coderd/healthcheck"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get managed DERP regions
+// @ID get-managed-derp-regions
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Success 200 {array} codersdk.ManagedDERPRegion
+// @Router /derp-regions [get]
+func (api *API) managedDERPRegions(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	regions, err := api.Database.GetDERPRegions(ctx)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	resp := make([]codersdk.ManagedDERPRegion, 0, len(regions))
+	for _, region := range regions {
+		converted, err := convertManagedDERPRegion(region)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		resp = append(resp, converted)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Get managed DERP region
+// @ID get-managed-derp-region
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param region path int true "Region ID"
+// @Success 200 {object} codersdk.ManagedDERPRegion
+// @Router /derp-regions/{region} [get]
+func (api *API) managedDERPRegion(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	regionID, ok := parseDERPRegionID(rw, r)
+	if !ok {
+		return
+	}
+
+	region, err := api.Database.GetDERPRegionByID(ctx, regionID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	resp, err := convertManagedDERPRegion(region)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Create managed DERP region
+// @ID create-managed-derp-region
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param request body codersdk.CreateManagedDERPRegionRequest true "Create DERP region request"
+// @Success 201 {object} codersdk.ManagedDERPRegion
+// @Router /derp-regions [post]
+func (api *API) postManagedDERPRegion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.DERPRegion](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.CreateManagedDERPRegionRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if _, ok := api.AGPL.DERPMap.Regions[req.RegionID]; ok {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Region ID %d is used by the static DERP map.", req.RegionID),
+		})
+		return
+	}
+	nodes, err := convertManagedDERPNodes(req.RegionID, req.Nodes)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid DERP nodes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	region, err := api.Database.InsertDERPRegion(ctx, database.InsertDERPRegionParams{
+		ID:         uuid.New(),
+		RegionID:   int32(req.RegionID),
+		RegionCode: req.RegionCode,
+		RegionName: req.RegionName,
+		Nodes:      nodes,
+		Avoid:      req.Avoid,
+		CreatedAt:  database.Now(),
+		UpdatedAt:  database.Now(),
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("A DERP region with ID %d or code %q already exists.", req.RegionID, req.RegionCode),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.New = region
+	resp, err := convertManagedDERPRegion(region)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishDERPMapUpdate(ctx)
+
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+// @Summary Update managed DERP region
+// @ID update-managed-derp-region
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param region path int true "Region ID"
+// @Param request body codersdk.UpdateManagedDERPRegionRequest true "Update DERP region request"
+// @Success 200 {object} codersdk.ManagedDERPRegion
+// @Router /derp-regions/{region} [patch]
+func (api *API) patchManagedDERPRegion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.DERPRegion](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	regionID, ok := parseDERPRegionID(rw, r)
+	if !ok {
+		return
+	}
+	oldRegion, err := api.Database.GetDERPRegionByID(ctx, regionID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.Old = oldRegion
+
+	var req codersdk.UpdateManagedDERPRegionRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	nodes, err := convertManagedDERPNodes(int(regionID), req.Nodes)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid DERP nodes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	region, err := api.Database.UpdateDERPRegionByID(ctx, database.UpdateDERPRegionByIDParams{
+		RegionID:   regionID,
+		RegionCode: req.RegionCode,
+		RegionName: req.RegionName,
+		Nodes:      nodes,
+		Avoid:      req.Avoid,
+		UpdatedAt:  database.Now(),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("A DERP region with code %q already exists.", req.RegionCode),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.New = region
+	resp, err := convertManagedDERPRegion(region)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishDERPMapUpdate(ctx)
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Delete managed DERP region
+// @ID delete-managed-derp-region
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param region path int true "Region ID"
+// @Success 204
+// @Router /derp-regions/{region} [delete]
+func (api *API) deleteManagedDERPRegion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.DERPRegion](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionDelete, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	regionID, ok := parseDERPRegionID(rw, r)
+	if !ok {
+		return
+	}
+
+	region, err := api.Database.GetDERPRegionByID(ctx, regionID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.Old = region
+	err = api.Database.DeleteDERPRegionByID(ctx, regionID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishDERPMapUpdate(ctx)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get DERP region policies
+// @ID get-derp-region-policies
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Success 200 {array} codersdk.DERPRegionPolicy
+// @Router /derp-region-policies [get]
+func (api *API) derpRegionPolicies(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	policies, err := api.Database.GetDERPRegionPolicies(ctx)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	resp := make([]codersdk.DERPRegionPolicy, 0, len(policies))
+	for _, policy := range policies {
+		resp = append(resp, convertDERPRegionPolicy(policy))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Create DERP region policy
+// @ID create-derp-region-policy
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param request body codersdk.CreateDERPRegionPolicyRequest true "Create DERP region policy request"
+// @Success 201 {object} codersdk.DERPRegionPolicy
+// @Router /derp-region-policies [post]
+func (api *API) postDERPRegionPolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.DERPRegionPolicy](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.CreateDERPRegionPolicyRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if (req.GroupID == nil) == (req.TemplateID == nil) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Exactly one of group_id or template_id must be set.",
+		})
+		return
+	}
+	var (
+		groupID    uuid.NullUUID
+		templateID uuid.NullUUID
+	)
+	if req.GroupID != nil {
+		groupID = uuid.NullUUID{UUID: *req.GroupID, Valid: true}
+		_, err := api.Database.GetGroupByID(ctx, groupID.UUID)
+		if httpapi.Is404Error(err) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Group %q does not exist.", groupID.UUID),
+			})
+			return
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+	if req.TemplateID != nil {
+		templateID = uuid.NullUUID{UUID: *req.TemplateID, Valid: true}
+		_, err := api.Database.GetTemplateByID(ctx, templateID.UUID)
+		if httpapi.Is404Error(err) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Template %q does not exist.", templateID.UUID),
+			})
+			return
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+	regionIDs, ok := api.validateDERPRegionIDs(rw, r, req.RegionIDs)
+	if !ok {
+		return
+	}
+
+	policy, err := api.Database.InsertDERPRegionPolicy(ctx, database.InsertDERPRegionPolicyParams{
+		ID:         uuid.New(),
+		GroupID:    groupID,
+		TemplateID: templateID,
+		RegionIDs:  regionIDs,
+		CreatedAt:  database.Now(),
+		UpdatedAt:  database.Now(),
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "A DERP region policy already exists for this group or template.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.New = policy
+	api.publishDERPMapUpdate(ctx)
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertDERPRegionPolicy(policy))
+}
+
+// @Summary Update DERP region policy
+// @ID update-derp-region-policy
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param policy path string true "Policy ID" format(uuid)
+// @Param request body codersdk.UpdateDERPRegionPolicyRequest true "Update DERP region policy request"
+// @Success 200 {object} codersdk.DERPRegionPolicy
+// @Router /derp-region-policies/{policy} [patch]
+func (api *API) patchDERPRegionPolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.DERPRegionPolicy](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	policyID, ok := parseDERPRegionPolicyID(rw, r)
+	if !ok {
+		return
+	}
+	oldPolicy, err := api.Database.GetDERPRegionPolicyByID(ctx, policyID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.Old = oldPolicy
+
+	var req codersdk.UpdateDERPRegionPolicyRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	regionIDs, ok := api.validateDERPRegionIDs(rw, r, req.RegionIDs)
+	if !ok {
+		return
+	}
+
+	policy, err := api.Database.UpdateDERPRegionPolicyByID(ctx, database.UpdateDERPRegionPolicyByIDParams{
+		ID:        policyID,
+		RegionIDs: regionIDs,
+		UpdatedAt: database.Now(),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.New = policy
+	api.publishDERPMapUpdate(ctx)
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertDERPRegionPolicy(policy))
+}
+
+// @Summary Delete DERP region policy
+// @ID delete-derp-region-policy
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param policy path string true "Policy ID" format(uuid)
+// @Success 204
+// @Router /derp-region-policies/{policy} [delete]
+func (api *API) deleteDERPRegionPolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.DERPRegionPolicy](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionDelete, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	policyID, ok := parseDERPRegionPolicyID(rw, r)
+	if !ok {
+		return
+	}
+
+	policy, err := api.Database.GetDERPRegionPolicyByID(ctx, policyID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.Old = policy
+	err = api.Database.DeleteDERPRegionPolicyByID(ctx, policyID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishDERPMapUpdate(ctx)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func parseDERPRegionID(rw http.ResponseWriter, r *http.Request) (int32, bool) {
+	regionID, err := strconv.ParseInt(chi.URLParam(r, "region"), 10, 32)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Region ID must be an integer.",
+			Detail:  err.Error(),
+		})
+		return 0, false
+	}
+	return int32(regionID), true
+}
+
+func parseDERPRegionPolicyID(rw http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	policyID, err := uuid.Parse(chi.URLParam(r, "policy"))
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Policy ID must be a valid UUID.",
+			Detail:  err.Error(),
+		})
+		return uuid.Nil, false
+	}
+	return policyID, true
+}
+
+// validateDERPRegionIDs ensures every region of a policy is either part of
+// the static DERP map or a managed DERP region.
+func (api *API) validateDERPRegionIDs(rw http.ResponseWriter, r *http.Request, regionIDs []int) ([]int32, bool) {
+	ctx := r.Context()
+	regions, err := api.Database.GetDERPRegions(ctx)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return nil, false
+	}
+	known := make(map[int]struct{}, len(api.AGPL.DERPMap.Regions)+len(regions))
+	for regionID := range api.AGPL.DERPMap.Regions {
+		known[regionID] = struct{}{}
+	}
+	for _, region := range regions {
+		known[int(region.RegionID)] = struct{}{}
+	}
+
+	converted := make([]int32, 0, len(regionIDs))
+	for _, regionID := range regionIDs {
+		if _, ok := known[regionID]; !ok {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("DERP region %d does not exist.", regionID),
+			})
+			return nil, false
+		}
+		converted = append(converted, int32(regionID))
+	}
+	return converted, true
+}
+
+// publishDERPMapUpdate notifies everyone streaming DERP maps that their DERP
+// map may have changed.
+func (api *API) publishDERPMapUpdate(ctx context.Context) {
+	err := api.Pubsub.Publish(derpmap.PubsubEvent, nil)
+	if err != nil {
+		api.Logger.Warn(ctx, "publish derp map update", slog.Error(err))
+	}
+}
+
+func convertManagedDERPNodes(regionID int, nodes []codersdk.ManagedDERPNode) (json.RawMessage, error) {
+	names := make(map[string]struct{}, len(nodes))
+	converted := make([]*tailcfg.DERPNode, 0, len(nodes))
+	for _, node := range nodes {
+		if _, ok := names[node.Name]; ok {
+			return nil, xerrors.Errorf("node name %q is used more than once", node.Name)
+		}
+		names[node.Name] = struct{}{}
+		if node.HostName == "" && node.IPv4 == "" && node.IPv6 == "" {
+			return nil, xerrors.Errorf("node %q must have a host name or an IP address", node.Name)
+		}
+		if node.DERPPort < 0 || node.DERPPort > 65535 {
+			return nil, xerrors.Errorf("node %q has an invalid DERP port %d", node.Name, node.DERPPort)
+		}
+		if node.STUNPort < -1 || node.STUNPort > 65535 {
+			return nil, xerrors.Errorf("node %q has an invalid STUN port %d", node.Name, node.STUNPort)
+		}
+		converted = append(converted, &tailcfg.DERPNode{
+			Name:      node.Name,
+			RegionID:  regionID,
+			HostName:  node.HostName,
+			IPv4:      node.IPv4,
+			IPv6:      node.IPv6,
+			STUNPort:  node.STUNPort,
+			STUNOnly:  node.STUNOnly,
+			DERPPort:  node.DERPPort,
+			ForceHTTP: node.ForceHTTP,
+		})
+	}
+	return json.Marshal(converted)
+}
+
+func convertManagedDERPRegion(region database.DERPRegion) (codersdk.ManagedDERPRegion, error) {
+	var nodes []*tailcfg.DERPNode
+	err := json.Unmarshal(region.Nodes, &nodes)
+	if err != nil {
+		return codersdk.ManagedDERPRegion{}, xerrors.Errorf("unmarshal nodes of region %d: %w", region.RegionID, err)
+	}
+	converted := codersdk.ManagedDERPRegion{
+		RegionID:    int(region.RegionID),
+		RegionCode:  region.RegionCode,
+		RegionName:  region.RegionName,
+		Nodes:       make([]codersdk.ManagedDERPNode, 0, len(nodes)),
+		Avoid:       region.Avoid,
+		Healthy:     region.Healthy,
+		HealthError: region.HealthError,
+		CreatedAt:   region.CreatedAt,
+		UpdatedAt:   region.UpdatedAt,
+	}
+	if region.LastHealthCheckAt.Valid {
+		converted.LastHealthCheckAt = &region.LastHealthCheckAt.Time
+	}
+	for _, node := range nodes {
+		converted.Nodes = append(converted.Nodes, codersdk.ManagedDERPNode{
+			Name:      node.Name,
+			HostName:  node.HostName,
+			IPv4:      node.IPv4,
+			IPv6:      node.IPv6,
+			STUNPort:  node.STUNPort,
+			STUNOnly:  node.STUNOnly,
+			DERPPort:  node.DERPPort,
+			ForceHTTP: node.ForceHTTP,
+		})
+	}
+	return converted, nil
+}
+
+func convertDERPRegionPolicy(policy database.DERPRegionPolicy) codersdk.DERPRegionPolicy {
+	converted := codersdk.DERPRegionPolicy{
+		ID:        policy.ID,
+		RegionIDs: make([]int, 0, len(policy.RegionIDs)),
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+	if policy.GroupID.Valid {
+		converted.GroupID = &policy.GroupID.UUID
+	}
+	if policy.TemplateID.Valid {
+		converted.TemplateID = &policy.TemplateID.UUID
+	}
+	for _, regionID := range policy.RegionIDs {
+		converted.RegionIDs = append(converted.RegionIDs, int(regionID))
+	}
+	return converted
+}
+
+// tailcfgDERPRegion converts a managed DERP region into a region of the DERP
+// map.
+func tailcfgDERPRegion(region database.DERPRegion) (*tailcfg.DERPRegion, error) {
+	var nodes []*tailcfg.DERPNode
+	err := json.Unmarshal(region.Nodes, &nodes)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal nodes of region %d: %w", region.RegionID, err)
+	}
+	return &tailcfg.DERPRegion{
+		RegionID:   int(region.RegionID),
+		RegionCode: region.RegionCode,
+		RegionName: region.RegionName,
+		Avoid:      region.Avoid,
+		Nodes:      nodes,
+	}, nil
+}
+
+// derpMapProvider merges the healthy managed DERP regions into the static
+// DERP map, and restricts the regions using DERP region policies.
+type derpMapProvider struct {
+	db      database.Store
+	derpMap *tailcfg.DERPMap
+	watcher *derpmap.Watcher
+
+	// The snapshot is built once per DERP map update, instead of once per
+	// client or agent that fetches its DERP map.
+	mu       sync.Mutex
+	snapshot *derpMapSnapshot
+}
+
+type derpMapSnapshot struct {
+	generation uint64
+	// derpMap contains every healthy region.
+	derpMap *tailcfg.DERPMap
+	// templateRegions contains the allowed regions of templates with a
+	// policy.
+	templateRegions map[uuid.UUID]map[int]struct{}
+}
+
+var _ derpmap.Provider = &derpMapProvider{}
+
+func (p *derpMapProvider) ForUser(ctx context.Context, userID uuid.UUID) (*tailcfg.DERPMap, error) {
+	//nolint:gocritic // Users don't have access to the policies that apply to them.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	snapshot, err := p.currentSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Group memberships change without a DERP map update, so the policies
+	// of the user aren't part of the snapshot.
+	policies, err := p.db.GetDERPRegionPoliciesByUserID(ctx, userID)
+	if err != nil {
+		return nil, xerrors.Errorf("get derp region policies by user id: %w", err)
+	}
+
+	// Users in several restricted groups may only use the regions allowed
+	// by all of them.
+	var allowed map[int]struct{}
+	for _, policy := range policies {
+		regionIDs := make(map[int]struct{}, len(policy.RegionIDs))
+		for _, regionID := range policy.RegionIDs {
+			if _, ok := allowed[int(regionID)]; allowed != nil && !ok {
+				continue
+			}
+			regionIDs[int(regionID)] = struct{}{}
+		}
+		allowed = regionIDs
+	}
+	return snapshot.build(allowed)
+}
+
+func (p *derpMapProvider) ForTemplate(ctx context.Context, templateID uuid.UUID) (*tailcfg.DERPMap, error) {
+	//nolint:gocritic // Agents don't have access to the policies that apply to them.
+	snapshot, err := p.currentSnapshot(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.build(snapshot.templateRegions[templateID])
+}
+
+func (p *derpMapProvider) All(ctx context.Context) (*tailcfg.DERPMap, error) {
+	//nolint:gocritic // Region names are shown to everyone that can see an agent.
+	snapshot, err := p.currentSnapshot(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.build(nil)
+}
+
+// currentSnapshot returns the snapshot of the latest DERP map update,
+// fetching it if it hasn't been fetched yet.
+func (p *derpMapProvider) currentSnapshot(ctx context.Context) (*derpMapSnapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The generation is read before fetching, so an update that arrives
+	// while fetching causes the next call to fetch again.
+	generation := p.watcher.Generation()
+	if p.snapshot != nil && p.snapshot.generation == generation {
+		return p.snapshot, nil
+	}
+
+	regions, err := p.db.GetDERPRegions(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get derp regions: %w", err)
+	}
+	policies, err := p.db.GetDERPRegionPolicies(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get derp region policies: %w", err)
+	}
+
+	derpMap := p.derpMap.Clone()
+	if derpMap.Regions == nil {
+		derpMap.Regions = map[int]*tailcfg.DERPRegion{}
+	}
+	for _, region := range regions {
+		if !region.Healthy {
+			continue
+		}
+		if _, ok := derpMap.Regions[int(region.RegionID)]; ok {
+			continue
+		}
+		derpRegion, err := tailcfgDERPRegion(region)
+		if err != nil {
+			return nil, err
+		}
+		derpMap.Regions[derpRegion.RegionID] = derpRegion
+	}
+	templateRegions := map[uuid.UUID]map[int]struct{}{}
+	for _, policy := range policies {
+		if !policy.TemplateID.Valid {
+			continue
+		}
+		allowed := make(map[int]struct{}, len(policy.RegionIDs))
+		for _, regionID := range policy.RegionIDs {
+			allowed[int(regionID)] = struct{}{}
+		}
+		templateRegions[policy.TemplateID.UUID] = allowed
+	}
+
+	p.snapshot = &derpMapSnapshot{
+		generation:      generation,
+		derpMap:         derpMap,
+		templateRegions: templateRegions,
+	}
+	return p.snapshot, nil
+}
+
+// build returns a copy of the DERP map of the snapshot. If allowed is not
+// nil, all other regions are removed.
+func (s *derpMapSnapshot) build(allowed map[int]struct{}) (*tailcfg.DERPMap, error) {
+	derpMap := s.derpMap.Clone()
+	if allowed != nil {
+		for regionID := range derpMap.Regions {
+			if _, ok := allowed[regionID]; !ok {
+				delete(derpMap.Regions, regionID)
+			}
+		}
+	}
+	// An empty DERP map would leave clients unable to reach anyone, so
+	// refuse to hand it out.
+	if allowed != nil && len(derpMap.Regions) == 0 {
+		return nil, xerrors.New("DERP region policies leave no healthy region to connect through")
+	}
+	return derpMap, nil
+}
+
+// runDERPRegionHealthLoop periodically health checks the managed DERP
+// regions. Unhealthy regions are left out of DERP maps until they recover.
+func (api *API) runDERPRegionHealthLoop(ctx context.Context) {
+	ticker := time.NewTicker(api.DERPRegionHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		api.checkDERPRegionHealth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (api *API) checkDERPRegionHealth(ctx context.Context) {
+	//nolint:gocritic // The health checker is a system function.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	regions, err := api.Database.GetDERPRegions(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			api.Logger.Warn(ctx, "get derp regions for health check", slog.Error(err))
+		}
+		return
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		changed bool
+	)
+	for _, region := range regions {
+		region := region
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			derpRegion, err := tailcfgDERPRegion(region)
+			if err == nil {
+				checkCtx, cancel := context.WithTimeout(ctx, api.DERPRegionHealthInterval)
+				err = api.DERPRegionHealthCheck(checkCtx, derpRegion)
+				cancel()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			healthError := ""
+			if err != nil {
+				healthError = err.Error()
+			}
+			_, err = api.Database.UpdateDERPRegionHealthByID(ctx, database.UpdateDERPRegionHealthByIDParams{
+				RegionID:          region.RegionID,
+				Healthy:           healthError == "",
+				HealthError:       healthError,
+				LastHealthCheckAt: sql.NullTime{Time: database.Now(), Valid: true},
+			})
+			if xerrors.Is(err, sql.ErrNoRows) {
+				// The region was deleted during the health check.
+				return
+			}
+			if err != nil {
+				api.Logger.Warn(ctx, "update derp region health", slog.F("region_id", region.RegionID), slog.Error(err))
+				return
+			}
+			if region.Healthy != (healthError == "") {
+				api.Logger.Info(ctx, "derp region health changed",
+					slog.F("region_id", region.RegionID),
+					slog.F("healthy", healthError == ""),
+					slog.F("error", healthError),
+				)
+				mu.Lock()
+				changed = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if changed {
+		api.publishDERPMapUpdate(ctx)
+	}
+}
+
+// checkDERPRegion connects to every node of the DERP region and exchanges
+// messages through it.
+func checkDERPRegion(ctx context.Context, region *tailcfg.DERPRegion) error {
+	report := healthcheck.DERPRegionReport{Region: region}
+	report.Run(ctx)
+	if report.Healthy {
+		return nil
+	}
+	if report.Error != nil {
+		return report.Error
+	}
+	for _, node := range report.NodeReports {
+		if node.Error != nil {
+			return xerrors.Errorf("node %q: %w", node.Node.Name, node.Error)
+		}
+	}
+	return xerrors.New("region is unhealthy")
+}
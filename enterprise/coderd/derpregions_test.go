@@ -0,0 +1,417 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestManagedDERPRegions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			DERPRegionHealthCheck: func(context.Context, *tailcfg.DERPRegion) error { return nil },
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		region, err := client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(10000))
+		require.NoError(t, err)
+		require.Equal(t, 10000, region.RegionID)
+		require.Len(t, region.Nodes, 1)
+
+		_, err = client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(10000))
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		region, err = client.UpdateManagedDERPRegion(ctx, region.RegionID, codersdk.UpdateManagedDERPRegionRequest{
+			RegionCode: "eu-central",
+			RegionName: "EU Central",
+			Nodes:      region.Nodes,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "eu-central", region.RegionCode)
+
+		regions, err := client.ManagedDERPRegions(ctx)
+		require.NoError(t, err)
+		require.Len(t, regions, 1)
+
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		_, err = member.ManagedDERPRegions(ctx)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		err = client.DeleteManagedDERPRegion(ctx, region.RegionID)
+		require.NoError(t, err)
+		_, err = client.ManagedDERPRegion(ctx, region.RegionID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Audit", func(t *testing.T) {
+		t.Parallel()
+
+		auditor := audit.NewMock()
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			AuditLogging:          true,
+			DERPRegionHealthCheck: func(context.Context, *tailcfg.DERPRegion) error { return nil },
+			Options: &coderdtest.Options{
+				Auditor: auditor,
+			},
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+				codersdk.FeatureAuditLog:     1,
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		numLogs := len(auditor.AuditLogs())
+		region, err := client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(10000))
+		require.NoError(t, err)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		require.Equal(t, database.AuditActionCreate, auditor.AuditLogs()[numLogs-1].Action)
+		require.Equal(t, database.ResourceTypeDerpRegion, auditor.AuditLogs()[numLogs-1].ResourceType)
+		require.Equal(t, "eu-west", auditor.AuditLogs()[numLogs-1].ResourceTarget)
+
+		_, err = client.UpdateManagedDERPRegion(ctx, region.RegionID, codersdk.UpdateManagedDERPRegionRequest{
+			RegionCode: "eu-central",
+			RegionName: "EU Central",
+			Nodes:      region.Nodes,
+			Avoid:      true,
+		})
+		require.NoError(t, err)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		require.Equal(t, database.AuditActionWrite, auditor.AuditLogs()[numLogs-1].Action)
+		require.Equal(t, "eu-central", auditor.AuditLogs()[numLogs-1].ResourceTarget)
+
+		err = client.DeleteManagedDERPRegion(ctx, region.RegionID)
+		require.NoError(t, err)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		require.Equal(t, database.AuditActionDelete, auditor.AuditLogs()[numLogs-1].Action)
+	})
+
+	t.Run("StaticRegionConflict", func(t *testing.T) {
+		t.Parallel()
+
+		client, _, api := coderdenttest.NewWithAPI(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(api.AGPL.DERPMap.RegionIDs()[0]))
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		t.Parallel()
+
+		var healthy atomic.Bool
+		healthy.Store(true)
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			DERPRegionHealthInterval: testutil.IntervalFast,
+			DERPRegionHealthCheck: func(context.Context, *tailcfg.DERPRegion) error {
+				if healthy.Load() {
+					return nil
+				}
+				return xerrors.New("unreachable")
+			},
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(10000))
+		require.NoError(t, err)
+
+		updates, err := client.DERPMapUpdates(ctx)
+		require.NoError(t, err)
+		waitForDERPMap(ctx, t, updates, func(derpMap *tailcfg.DERPMap) bool {
+			_, ok := derpMap.Regions[10000]
+			return ok
+		})
+
+		healthy.Store(false)
+		waitForDERPMap(ctx, t, updates, func(derpMap *tailcfg.DERPMap) bool {
+			_, ok := derpMap.Regions[10000]
+			return !ok
+		})
+		region, err := client.ManagedDERPRegion(ctx, 10000)
+		require.NoError(t, err)
+		require.False(t, region.Healthy)
+		require.Equal(t, "unreachable", region.HealthError)
+		require.NotNil(t, region.LastHealthCheckAt)
+	})
+}
+
+func TestDERPRegionPolicies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Group", func(t *testing.T) {
+		t.Parallel()
+
+		client, _, api := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+			DERPRegionHealthCheck: func(context.Context, *tailcfg.DERPRegion) error { return nil },
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		})
+		staticRegionID := api.AGPL.DERPMap.RegionIDs()[0]
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(10000))
+		require.NoError(t, err)
+
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "eu",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{memberUser.ID.String()},
+		})
+		require.NoError(t, err)
+
+		updates, err := member.DERPMapUpdates(ctx)
+		require.NoError(t, err)
+		waitForDERPMap(ctx, t, updates, func(derpMap *tailcfg.DERPMap) bool {
+			_, ok := derpMap.Regions[10000]
+			return ok && derpMap.Regions[staticRegionID] != nil
+		})
+
+		policy, err := client.CreateDERPRegionPolicy(ctx, codersdk.CreateDERPRegionPolicyRequest{
+			GroupID:   &group.ID,
+			RegionIDs: []int{10000},
+		})
+		require.NoError(t, err)
+		waitForDERPMap(ctx, t, updates, func(derpMap *tailcfg.DERPMap) bool {
+			_, ok := derpMap.Regions[10000]
+			return ok && len(derpMap.Regions) == 1
+		})
+
+		// The admin isn't a member of the group.
+		derpMap := firstDERPMap(ctx, t, client)
+		require.Contains(t, derpMap.Regions, staticRegionID)
+		require.Contains(t, derpMap.Regions, 10000)
+
+		err = client.DeleteDERPRegionPolicy(ctx, policy.ID)
+		require.NoError(t, err)
+		waitForDERPMap(ctx, t, updates, func(derpMap *tailcfg.DERPMap) bool {
+			return derpMap.Regions[staticRegionID] != nil
+		})
+	})
+
+	t.Run("NoUsableRegion", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			DERPRegionHealthCheck: func(context.Context, *tailcfg.DERPRegion) error { return nil },
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateManagedDERPRegion(ctx, managedDERPRegionRequest(10000))
+		require.NoError(t, err)
+		_, err = client.CreateDERPRegionPolicy(ctx, codersdk.CreateDERPRegionPolicyRequest{
+			GroupID:   &user.OrganizationID,
+			RegionIDs: []int{10000},
+		})
+		require.NoError(t, err)
+
+		updates, err := client.DERPMapUpdates(ctx)
+		require.NoError(t, err)
+		waitForDERPMap(ctx, t, updates, func(derpMap *tailcfg.DERPMap) bool {
+			return len(derpMap.Regions) == 1
+		})
+
+		// The policy still allows the deleted region, but no DERP map is
+		// better than one without regions.
+		err = client.DeleteManagedDERPRegion(ctx, 10000)
+		require.NoError(t, err)
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the DERP map updates to end")
+		case derpMap, ok := <-updates:
+			require.False(t, ok, "unexpected DERP map %+v", derpMap)
+		}
+	})
+
+	t.Run("Audit", func(t *testing.T) {
+		t.Parallel()
+
+		auditor := audit.NewMock()
+		client, _, api := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+			AuditLogging: true,
+			Options: &coderdtest.Options{
+				Auditor: auditor,
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+				codersdk.FeatureAuditLog:     1,
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		numLogs := len(auditor.AuditLogs())
+		policy, err := client.CreateDERPRegionPolicy(ctx, codersdk.CreateDERPRegionPolicyRequest{
+			GroupID:   &user.OrganizationID,
+			RegionIDs: api.AGPL.DERPMap.RegionIDs(),
+		})
+		require.NoError(t, err)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		require.Equal(t, database.AuditActionCreate, auditor.AuditLogs()[numLogs-1].Action)
+		require.Equal(t, database.ResourceTypeDerpRegionPolicy, auditor.AuditLogs()[numLogs-1].ResourceType)
+		require.Equal(t, policy.ID, auditor.AuditLogs()[numLogs-1].ResourceID)
+
+		_, err = client.UpdateDERPRegionPolicy(ctx, policy.ID, codersdk.UpdateDERPRegionPolicyRequest{
+			RegionIDs: api.AGPL.DERPMap.RegionIDs()[:1],
+		})
+		require.NoError(t, err)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		require.Equal(t, database.AuditActionWrite, auditor.AuditLogs()[numLogs-1].Action)
+
+		err = client.DeleteDERPRegionPolicy(ctx, policy.ID)
+		require.NoError(t, err)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		require.Equal(t, database.AuditActionDelete, auditor.AuditLogs()[numLogs-1].Action)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		var apiErr *codersdk.Error
+		_, err := client.CreateDERPRegionPolicy(ctx, codersdk.CreateDERPRegionPolicyRequest{
+			RegionIDs: []int{1},
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.CreateDERPRegionPolicy(ctx, codersdk.CreateDERPRegionPolicyRequest{
+			GroupID:   &user.OrganizationID,
+			RegionIDs: []int{10000},
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+func managedDERPRegionRequest(regionID int) codersdk.CreateManagedDERPRegionRequest {
+	return codersdk.CreateManagedDERPRegionRequest{
+		RegionID:   regionID,
+		RegionCode: "eu-west",
+		RegionName: "EU West",
+		Nodes: []codersdk.ManagedDERPNode{{
+			Name:     "eu-west-1",
+			HostName: "derp.example.com",
+		}},
+	}
+}
+
+func firstDERPMap(ctx context.Context, t *testing.T, client *codersdk.Client) *tailcfg.DERPMap {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates, err := client.DERPMapUpdates(ctx)
+	require.NoError(t, err)
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the DERP map")
+		return nil
+	case derpMap := <-updates:
+		return derpMap
+	}
+}
+
+func waitForDERPMap(ctx context.Context, t *testing.T, updates <-chan *tailcfg.DERPMap, condition func(derpMap *tailcfg.DERPMap) bool) {
+	t.Helper()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the DERP map")
+		case derpMap, ok := <-updates:
+			require.True(t, ok, "DERP map updates closed")
+			if condition(derpMap) {
+				return
+			}
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for a DERP map update")
+		}
+	}
+}
@@ -250,6 +250,8 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	aReq.New = group.Auditable(patchedMembers)
+	// Membership changes may change the DERP regions of the members.
+	api.publishDERPMapUpdate(ctx)
 
 	httpapi.Write(ctx, rw, http.StatusOK, convertGroup(group, patchedMembers))
 }
@@ -296,6 +298,7 @@ func (api *API) deleteGroup(rw http.ResponseWriter, r *http.Request) {
 		httpapi.InternalServerError(rw, err)
 		return
 	}
+	api.publishDERPMapUpdate(ctx)
 
 	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
 		Message: "Successfully deleted group!",
@@ -11,6 +11,7 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/enterprise/coderd/license"
@@ -280,6 +281,29 @@ func TestEntitlements(t *testing.T) {
 		require.True(t, entitlements.HasLicense)
 		require.Contains(t, entitlements.Warnings, "Your deployment has 2 active users but is only licensed for 1.")
 	})
+	t.Run("ServiceAccountsNotCounted", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		account := dbgen.User(t, db, database.User{LoginType: database.LoginTypeNone})
+		dbgen.ServiceAccount(t, db, database.ServiceAccount{
+			UserID:    account.ID,
+			CreatedBy: user.ID,
+		})
+		db.InsertLicense(context.Background(), database.InsertLicenseParams{
+			JWT: coderdenttest.GenerateLicense(t, coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureUserLimit: 1,
+				},
+			}),
+			Exp: time.Now().Add(time.Hour),
+		})
+		entitlements, err := license.Entitlements(context.Background(), db, slog.Logger{}, 1, 1, coderdenttest.Keys, empty)
+		require.NoError(t, err)
+		require.True(t, entitlements.HasLicense)
+		require.NotContains(t, entitlements.Warnings, "Your deployment has 2 active users but is only licensed for 1.")
+		require.Equal(t, int64(1), *entitlements.Features[codersdk.FeatureUserLimit].Actual)
+	})
 	t.Run("MaximizeUserLimit", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
@@ -222,6 +222,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		AccessURL:             api.AccessURL,
 		GitAuthConfigs:        api.GitAuthConfigs,
 		OIDCConfig:            api.OIDCConfig,
+		OIDCProviders:         coderd.OIDCProviderOAuth2Configs(api.OIDCProviders),
 		ID:                    daemon.ID,
 		Database:              api.Database,
 		Pubsub:                api.Pubsub,
@@ -1,24 +1,36 @@
 package coderd
 
 import (
+	"context"
 	"crypto/subtle"
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/imulab/go-scim/pkg/v2/handlerutil"
-	scimjson "github.com/imulab/go-scim/pkg/v2/json"
-	"github.com/imulab/go-scim/pkg/v2/service"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
 
 	agpl "github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
 
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+)
+
 func (api *API) scimEnabledMW(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 		api.entitlementsMu.RLock()
@@ -40,82 +52,481 @@ func (api *API) scimVerifyAuthHeader(r *http.Request) bool {
 	return len(api.SCIMAPIKey) != 0 && subtle.ConstantTimeCompare(hdr, api.SCIMAPIKey) == 1
 }
 
-// scimGetUsers intentionally always returns no users. This is done to always force
-// Okta to try and create each user individually, this way we don't need to
-// implement fetching users twice.
+// scimAuditParams returns the parameters to audit a SCIM request. SCIM
+// requests are made by the identity provider, so they are audited without a
+// user.
+func (api *API) scimAuditParams(r *http.Request, action database.AuditAction) *audit.RequestParams {
+	return &audit.RequestParams{
+		Audit:            *api.AGPL.Auditor.Load(),
+		Log:              api.Logger,
+		Request:          r,
+		Action:           action,
+		AdditionalFields: json.RawMessage(`{"source":"scim"}`),
+		System:           true,
+	}
+}
+
+// scimError writes a SCIM error response. handlerutil.WriteError only uses
+// the status of wrapped SCIM errors, anything else is reported as a 500.
+func scimError(rw http.ResponseWriter, scimErr *spec.Error, detail string) {
+	_ = handlerutil.WriteError(rw, xerrors.Errorf("%s: %w", detail, scimErr))
+}
+
+// scimListResponse is a page of resources matching a query.
+type scimListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+// scimPaginate returns the page of resources requested by the startIndex and
+// count query parameters. startIndex is 1-based.
+func scimPaginate[T any](r *http.Request, resources []T) (scimListResponse[T], error) {
+	startIndex := 1
+	if raw := r.URL.Query().Get("startIndex"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return scimListResponse[T]{}, xerrors.Errorf("startIndex must be an integer: %w", spec.ErrInvalidValue)
+		}
+		if v > 1 {
+			startIndex = v
+		}
+	}
+	count := len(resources)
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return scimListResponse[T]{}, xerrors.Errorf("count must be an integer: %w", spec.ErrInvalidValue)
+		}
+		if v >= 0 && v < count {
+			count = v
+		}
+	}
+
+	page := []T{}
+	if startIndex <= len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		page = resources[startIndex-1 : end]
+	}
+	return scimListResponse[T]{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}, nil
+}
+
+// scimFilterClause is an equality comparison of a filter, e.g.
+// `userName eq "kyle"`.
+type scimFilterClause struct {
+	// Attribute is lowercased, since attribute names are case-insensitive.
+	Attribute string
+	Value     string
+}
+
+// parseSCIMFilter parses filters made of equality comparisons joined by
+// "and", which is what identity providers send to look up resources. Other
+// operators are rejected.
+func parseSCIMFilter(filter string, attributes ...string) ([]scimFilterClause, error) {
+	var clauses []scimFilterClause
+	rest := strings.TrimSpace(filter)
+	for rest != "" {
+		fields := strings.SplitN(rest, " ", 3)
+		if len(fields) != 3 || !strings.EqualFold(fields[1], "eq") {
+			return nil, xerrors.Errorf("only eq comparisons are supported: %w", spec.ErrInvalidFilter)
+		}
+		clause := scimFilterClause{Attribute: strings.ToLower(fields[0])}
+		supported := false
+		for _, attribute := range attributes {
+			if strings.EqualFold(attribute, clause.Attribute) {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, xerrors.Errorf("filtering by %q isn't supported: %w", fields[0], spec.ErrInvalidFilter)
+		}
+
+		rest = strings.TrimSpace(fields[2])
+		if strings.HasPrefix(rest, `"`) {
+			end := 1
+			for end < len(rest) && (rest[end] != '"' || rest[end-1] == '\\') {
+				end++
+			}
+			if end == len(rest) {
+				return nil, xerrors.Errorf("unterminated string: %w", spec.ErrInvalidFilter)
+			}
+			value, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil, xerrors.Errorf("invalid string %s: %w", rest[:end+1], spec.ErrInvalidFilter)
+			}
+			clause.Value = value
+			rest = rest[end+1:]
+		} else {
+			value, remaining, _ := strings.Cut(rest, " ")
+			clause.Value = value
+			rest = remaining
+		}
+		clauses = append(clauses, clause)
+
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			break
+		}
+		conjunction, remaining, _ := strings.Cut(rest, " ")
+		if !strings.EqualFold(conjunction, "and") {
+			return nil, xerrors.Errorf("only and is supported to join comparisons: %w", spec.ErrInvalidFilter)
+		}
+		rest = strings.TrimSpace(remaining)
+		if rest == "" {
+			return nil, xerrors.Errorf("filter ends with and: %w", spec.ErrInvalidFilter)
+		}
+	}
+	return clauses, nil
+}
+
+// scimBool parses a boolean SCIM value. Azure AD sends booleans as the
+// strings "True" and "False".
+func scimBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	err := json.Unmarshal(raw, &b)
+	if err == nil {
+		return b, nil
+	}
+	var s string
+	err = json.Unmarshal(raw, &s)
+	if err != nil {
+		return false, xerrors.Errorf("%s is not a boolean: %w", raw, spec.ErrInvalidValue)
+	}
+	b, err = strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, xerrors.Errorf("%q is not a boolean: %w", s, spec.ErrInvalidValue)
+	}
+	return b, nil
+}
+
+// scimOrganizationID returns the organization users and groups are
+// provisioned in. Once multi-organization support is added, we should enable
+// a configuration map of user email to organization.
+func (api *API) scimOrganizationID(ctx context.Context) (uuid.UUID, error) {
+	organizations, err := api.Database.GetOrganizations(ctx)
+	if err != nil {
+		return uuid.Nil, xerrors.Errorf("get organizations: %w", err)
+	}
+	if len(organizations) == 0 {
+		return uuid.Nil, nil
+	}
+	return organizations[0].ID, nil
+}
+
+// SCIMMember references a user or group resource, e.g. a member of a group
+// or a group of a user.
+type SCIMMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// We currently use our own struct instead of using the SCIM package. This was
+// done mostly because the SCIM package was almost impossible to use. We only
+// need these fields, so it was much simpler to use our own struct. This was
+// tested with Okta and Azure AD.
+type SCIMUser struct {
+	Schemas  []string `json:"schemas"`
+	ID       string   `json:"id"`
+	UserName string   `json:"userName"`
+	Name     struct {
+		GivenName  string `json:"givenName"`
+		FamilyName string `json:"familyName"`
+	} `json:"name"`
+	Emails []struct {
+		Primary bool   `json:"primary"`
+		Value   string `json:"value" format:"email"`
+		Type    string `json:"type"`
+		Display string `json:"display"`
+	} `json:"emails"`
+	Active bool `json:"active"`
+	// Groups is read-only, membership is managed with the Groups resource.
+	Groups []SCIMMember `json:"groups"`
+	Meta   struct {
+		ResourceType string `json:"resourceType"`
+	} `json:"meta"`
+}
+
+// primaryEmail returns the primary email, or the first one if none is
+// marked primary.
+func (u SCIMUser) primaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+func (u *SCIMUser) setEmail(email string) {
+	u.Emails = []struct {
+		Primary bool   `json:"primary"`
+		Value   string `json:"value" format:"email"`
+		Type    string `json:"type"`
+		Display string `json:"display"`
+	}{{Primary: true, Value: email, Type: "work"}}
+}
+
+func convertSCIMUser(user database.User, groups []SCIMMember) SCIMUser {
+	sUser := SCIMUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       user.ID.String(),
+		UserName: user.Username,
+		Active:   user.Status == database.UserStatusActive,
+		Groups:   groups,
+	}
+	if sUser.Groups == nil {
+		sUser.Groups = []SCIMMember{}
+	}
+	sUser.setEmail(user.Email)
+	sUser.Meta.ResourceType = "User"
+	return sUser
+}
+
+func scimUserMatches(user SCIMUser, clauses []scimFilterClause) bool {
+	for _, clause := range clauses {
+		switch clause.Attribute {
+		case "id":
+			if user.ID != clause.Value {
+				return false
+			}
+		case "username":
+			if !strings.EqualFold(user.UserName, clause.Value) {
+				return false
+			}
+		case "emails", "emails.value":
+			if !strings.EqualFold(user.primaryEmail(), clause.Value) {
+				return false
+			}
+		case "active":
+			if strconv.FormatBool(user.Active) != strings.ToLower(clause.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// scimUserGroups returns the groups of the organization each user is a
+// member of, keyed by user ID. The group of all users isn't included.
+func (api *API) scimUserGroups(ctx context.Context, organizationID uuid.UUID) (map[uuid.UUID][]SCIMMember, error) {
+	groups, err := api.Database.GetGroupsByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, xerrors.Errorf("get groups: %w", err)
+	}
+	userGroups := map[uuid.UUID][]SCIMMember{}
+	for _, group := range groups {
+		if group.ID == organizationID {
+			continue
+		}
+		members, err := api.Database.GetGroupMembers(ctx, group.ID)
+		if err != nil {
+			return nil, xerrors.Errorf("get members of group %q: %w", group.Name, err)
+		}
+		for _, member := range members {
+			userGroups[member.ID] = append(userGroups[member.ID], SCIMMember{
+				Value:   group.ID.String(),
+				Display: group.Name,
+			})
+		}
+	}
+	return userGroups, nil
+}
+
+// scimUser returns the user of the id URL parameter with its groups.
+func (api *API) scimUser(ctx context.Context, r *http.Request) (database.User, SCIMUser, error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return database.User{}, SCIMUser{}, xerrors.Errorf("user %q: %w", chi.URLParam(r, "id"), spec.ErrNotFound)
+	}
+	user, err := api.Database.GetUserByID(ctx, id)
+	if xerrors.Is(err, sql.ErrNoRows) || (err == nil && user.Deleted) {
+		return database.User{}, SCIMUser{}, xerrors.Errorf("user %q: %w", id, spec.ErrNotFound)
+	}
+	if err != nil {
+		return database.User{}, SCIMUser{}, xerrors.Errorf("get user: %w", err)
+	}
+	organizationID, err := api.scimOrganizationID(ctx)
+	if err != nil {
+		return database.User{}, SCIMUser{}, err
+	}
+	userGroups, err := api.scimUserGroups(ctx, organizationID)
+	if err != nil {
+		return database.User{}, SCIMUser{}, err
+	}
+	return user, convertSCIMUser(user, userGroups[user.ID]), nil
+}
+
+// scimRejectOwner returns an error if the user is an owner. Owners can't be
+// suspended or deleted through SCIM, so a misconfigured identity provider
+// can't lock the administrators out of the deployment.
+func (api *API) scimRejectOwner(ctx context.Context, user database.User) error {
+	roles, err := api.Database.GetAuthorizationUserRoles(ctx, user.ID)
+	if err != nil {
+		return xerrors.Errorf("get user roles: %w", err)
+	}
+	if slices.Contains(roles.Roles, rbac.RoleOwner()) {
+		return xerrors.Errorf("owners can't be suspended or deleted through SCIM: %w", spec.ErrMutability)
+	}
+	return nil
+}
+
+// scimUpdateUser saves the username, email and active state of sUser.
+func (api *API) scimUpdateUser(ctx context.Context, user database.User, sUser SCIMUser) (database.User, error) {
+	username := user.Username
+	if sUser.UserName != "" && !strings.EqualFold(sUser.UserName, user.Username) {
+		username = sUser.UserName
+		if httpapi.NameValid(username) != nil {
+			username = httpapi.UsernameFrom(username)
+		}
+	}
+	email := user.Email
+	if e := sUser.primaryEmail(); e != "" {
+		email = e
+	}
+
+	var err error
+	if username != user.Username || email != user.Email {
+		user, err = api.Database.UpdateUserProfile(ctx, database.UpdateUserProfileParams{
+			ID:        user.ID,
+			Email:     email,
+			Username:  username,
+			AvatarURL: user.AvatarURL,
+			UpdatedAt: database.Now(),
+		})
+		if database.IsUniqueViolation(err) {
+			return database.User{}, xerrors.Errorf("username or email is taken: %w", spec.ErrUniqueness)
+		}
+		if err != nil {
+			return database.User{}, xerrors.Errorf("update user profile: %w", err)
+		}
+	}
+
+	status := database.UserStatusSuspended
+	if sUser.Active {
+		status = database.UserStatusActive
+	}
+	if status != user.Status {
+		if status == database.UserStatusSuspended {
+			err = api.scimRejectOwner(ctx, user)
+			if err != nil {
+				return database.User{}, err
+			}
+		}
+		user, err = api.Database.UpdateUserStatus(ctx, database.UpdateUserStatusParams{
+			ID:        user.ID,
+			Status:    status,
+			UpdatedAt: database.Now(),
+		})
+		if err != nil {
+			return database.User{}, xerrors.Errorf("update user status: %w", err)
+		}
+	}
+	return user, nil
+}
+
+// scimGetUsers returns the users matching the filter query parameter. Only
+// eq comparisons of id, userName, emails.value and active are supported.
 //
 // @Summary SCIM 2.0: Get users
 // @ID scim-get-users
 // @Security CoderSessionToken
 // @Produce application/scim+json
 // @Tags Enterprise
+// @Param filter query string false "Filter of eq comparisons joined by and"
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Maximum number of results"
 // @Success 200
 // @Router /scim/v2/Users [get]
 //
 //nolint:revive
 func (api *API) scimGetUsers(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
 	if !api.scimVerifyAuthHeader(r) {
 		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
 		return
 	}
 
-	_ = handlerutil.WriteSearchResultToResponse(rw, &service.QueryResponse{
-		TotalResults: 0,
-		StartIndex:   1,
-		ItemsPerPage: 0,
-		Resources:    []scimjson.Serializable{},
-	})
+	clauses, err := parseSCIMFilter(r.URL.Query().Get("filter"), "id", "userName", "emails", "emails.value", "active")
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	organizationID, err := api.scimOrganizationID(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	userGroups, err := api.scimUserGroups(ctx, organizationID)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	users, err := api.Database.GetUsers(ctx, database.GetUsersParams{})
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	sUsers := []SCIMUser{}
+	for _, user := range database.ConvertUserRows(users) {
+		sUser := convertSCIMUser(user, userGroups[user.ID])
+		if scimUserMatches(sUser, clauses) {
+			sUsers = append(sUsers, sUser)
+		}
+	}
+	res, err := scimPaginate(r, sUsers)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
 }
 
-// scimGetUser intentionally always returns an error saying the user wasn't found.
-// This is done to always force Okta to try and create the user, this way we
-// don't need to implement fetching users twice.
-//
 // @Summary SCIM 2.0: Get user by ID
 // @ID scim-get-user-by-id
 // @Security CoderSessionToken
 // @Produce application/scim+json
 // @Tags Enterprise
 // @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} coderd.SCIMUser
 // @Failure 404
 // @Router /scim/v2/Users/{id} [get]
 //
 //nolint:revive
 func (api *API) scimGetUser(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
 	if !api.scimVerifyAuthHeader(r) {
 		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
 		return
 	}
 
-	_ = handlerutil.WriteError(rw, spec.ErrNotFound)
-}
-
-// We currently use our own struct instead of using the SCIM package. This was
-// done mostly because the SCIM package was almost impossible to use. We only
-// need these fields, so it was much simpler to use our own struct. This was
-// tested only with Okta.
-type SCIMUser struct {
-	Schemas  []string `json:"schemas"`
-	ID       string   `json:"id"`
-	UserName string   `json:"userName"`
-	Name     struct {
-		GivenName  string `json:"givenName"`
-		FamilyName string `json:"familyName"`
-	} `json:"name"`
-	Emails []struct {
-		Primary bool   `json:"primary"`
-		Value   string `json:"value" format:"email"`
-		Type    string `json:"type"`
-		Display string `json:"display"`
-	} `json:"emails"`
-	Active bool          `json:"active"`
-	Groups []interface{} `json:"groups"`
-	Meta   struct {
-		ResourceType string `json:"resourceType"`
-	} `json:"meta"`
+	_, sUser, err := api.scimUser(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sUser)
 }
 
-// scimPostUser creates a new user, or returns the existing user if it exists.
+// scimPostUser creates a new user, or returns the existing user if one with
+// the same email exists.
 //
 // @Summary SCIM 2.0: Create new user
 // @ID scim-create-new-user
@@ -126,12 +537,16 @@ type SCIMUser struct {
 // @Success 200 {object} coderd.SCIMUser
 // @Router /scim/v2/Users [post]
 func (api *API) scimPostUser(rw http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
 	if !api.scimVerifyAuthHeader(r) {
 		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
 		return
 	}
 
+	aReq, commitAudit := audit.InitRequest[database.User](rw, api.scimAuditParams(r, database.AuditActionCreate))
+	defer commitAudit()
+
 	var sUser SCIMUser
 	err := json.NewDecoder(r.Body).Decode(&sUser)
 	if err != nil {
@@ -152,6 +567,18 @@ func (api *API) scimPostUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	existing, err := api.Database.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+		Email: email,
+	})
+	if err == nil && !existing.Deleted {
+		httpapi.Write(ctx, rw, http.StatusOK, convertSCIMUser(existing, nil))
+		return
+	}
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
 	// The username is a required property in Coder. We make a best-effort
 	// attempt at using what the claims provide, but if that fails we will
 	// generate a random username.
@@ -166,23 +593,13 @@ func (api *API) scimPostUser(rw http.ResponseWriter, r *http.Request) {
 		sUser.UserName = httpapi.UsernameFrom(sUser.UserName)
 	}
 
-	var organizationID uuid.UUID
-	//nolint:gocritic
-	organizations, err := api.Database.GetOrganizations(dbauthz.AsSystemRestricted(ctx))
+	organizationID, err := api.scimOrganizationID(ctx)
 	if err != nil {
 		_ = handlerutil.WriteError(rw, err)
 		return
 	}
 
-	if len(organizations) > 0 {
-		// Add the user to the first organization. Once multi-organization
-		// support is added, we should enable a configuration map of user
-		// email to organization.
-		organizationID = organizations[0].ID
-	}
-
-	//nolint:gocritic // needed for SCIM
-	user, _, err := api.AGPL.CreateUser(dbauthz.AsSystemRestricted(ctx), api.Database, agpl.CreateUserRequest{
+	user, _, err := api.AGPL.CreateUser(ctx, api.Database, agpl.CreateUserRequest{
 		CreateUserRequest: codersdk.CreateUserRequest{
 			Username:       sUser.UserName,
 			Email:          email,
@@ -194,14 +611,131 @@ func (api *API) scimPostUser(rw http.ResponseWriter, r *http.Request) {
 		_ = handlerutil.WriteError(rw, err)
 		return
 	}
+	aReq.New = user
 
-	sUser.ID = user.ID.String()
-	sUser.UserName = user.Username
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMUser(user, nil))
+}
 
-	httpapi.Write(ctx, rw, http.StatusOK, sUser)
+// scimPutUser replaces the username, email and active state of a user.
+// Groups are read-only and ignored.
+//
+// @Summary SCIM 2.0: Replace user account
+// @ID scim-replace-user
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "User ID" format(uuid)
+// @Param request body coderd.SCIMUser true "Replace user request"
+// @Success 200 {object} coderd.SCIMUser
+// @Router /scim/v2/Users/{id} [put]
+func (api *API) scimPutUser(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.User](rw, api.scimAuditParams(r, database.AuditActionWrite))
+	defer commitAudit()
+
+	var sUser SCIMUser
+	err := json.NewDecoder(r.Body).Decode(&sUser)
+	if err != nil {
+		scimError(rw, spec.ErrInvalidSyntax, err.Error())
+		return
+	}
+
+	user, current, err := api.scimUser(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.Old = user
+	user, err = api.scimUpdateUser(ctx, user, sUser)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.New = user
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMUser(user, current.Groups))
+}
+
+// SCIMPatchOperation is an operation of a SCIM PATCH request.
+type SCIMPatchOperation struct {
+	// Op is add, remove or replace, case-insensitive.
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
 }
 
-// scimPatchUser supports suspending and activating users only.
+// SCIMPatchRequest is the body of SCIM PATCH requests.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// applySCIMUserPatch applies an operation to the attributes of sUser that
+// Coder stores. Other attributes are accepted and ignored.
+func applySCIMUserPatch(sUser *SCIMUser, op SCIMPatchOperation) error {
+	if op.Path == "" {
+		// Without a path, the value holds the attributes to change.
+		var attributes map[string]json.RawMessage
+		err := json.Unmarshal(op.Value, &attributes)
+		if err != nil {
+			return xerrors.Errorf("value must be an object without a path: %w", spec.ErrInvalidValue)
+		}
+		for path, value := range attributes {
+			err = applySCIMUserPatch(sUser, SCIMPatchOperation{Op: op.Op, Path: path, Value: value})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if strings.EqualFold(op.Op, "remove") {
+		return nil
+	}
+
+	path := strings.ToLower(op.Path)
+	switch {
+	case path == "active":
+		active, err := scimBool(op.Value)
+		if err != nil {
+			return err
+		}
+		sUser.Active = active
+	case path == "username":
+		var username string
+		err := json.Unmarshal(op.Value, &username)
+		if err != nil {
+			return xerrors.Errorf("userName must be a string: %w", spec.ErrInvalidValue)
+		}
+		sUser.UserName = username
+	case path == "emails":
+		var emails SCIMUser
+		err := json.Unmarshal([]byte(`{"emails":`+string(op.Value)+`}`), &emails)
+		if err != nil {
+			return xerrors.Errorf("emails must be a list of emails: %w", spec.ErrInvalidValue)
+		}
+		if email := emails.primaryEmail(); email != "" {
+			sUser.setEmail(email)
+		}
+	case strings.HasPrefix(path, "emails[") && strings.HasSuffix(path, "].value"):
+		var email string
+		err := json.Unmarshal(op.Value, &email)
+		if err != nil {
+			return xerrors.Errorf("email must be a string: %w", spec.ErrInvalidValue)
+		}
+		sUser.setEmail(email)
+	}
+	return nil
+}
+
+// scimPatchUser updates the username, email and active state of a user.
+// Identity providers deprovision users by setting active to false, which
+// suspends them. Bodies without operations are treated as a whole user, of
+// which only the active state is applied.
 //
 // @Summary SCIM 2.0: Update user account
 // @ID scim-update-user-status
@@ -209,56 +743,122 @@ func (api *API) scimPostUser(rw http.ResponseWriter, r *http.Request) {
 // @Produce application/scim+json
 // @Tags Enterprise
 // @Param id path string true "User ID" format(uuid)
-// @Param request body coderd.SCIMUser true "Update user request"
-// @Success 200 {object} codersdk.User
+// @Param request body coderd.SCIMPatchRequest true "Update user request"
+// @Success 200 {object} coderd.SCIMUser
 // @Router /scim/v2/Users/{id} [patch]
 func (api *API) scimPatchUser(rw http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
 	if !api.scimVerifyAuthHeader(r) {
 		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
 		return
 	}
 
-	id := chi.URLParam(r, "id")
+	aReq, commitAudit := audit.InitRequest[database.User](rw, api.scimAuditParams(r, database.AuditActionWrite))
+	defer commitAudit()
 
-	var sUser SCIMUser
-	err := json.NewDecoder(r.Body).Decode(&sUser)
+	var body json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		scimError(rw, spec.ErrInvalidSyntax, err.Error())
+		return
+	}
+
+	user, sUser, err := api.scimUser(ctx, r)
 	if err != nil {
 		_ = handlerutil.WriteError(rw, err)
 		return
 	}
-	sUser.ID = id
+	aReq.Old = user
 
-	uid, err := uuid.Parse(id)
+	var patch SCIMPatchRequest
+	err = json.Unmarshal(body, &patch)
 	if err != nil {
-		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusBadRequest, Type: "invalidId"})
+		scimError(rw, spec.ErrInvalidSyntax, err.Error())
 		return
 	}
+	if patch.Operations == nil {
+		var legacy SCIMUser
+		err = json.Unmarshal(body, &legacy)
+		if err != nil {
+			scimError(rw, spec.ErrInvalidSyntax, err.Error())
+			return
+		}
+		sUser.Active = legacy.Active
+	}
+	for _, op := range patch.Operations {
+		err = applySCIMUserPatch(&sUser, op)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+	}
 
-	//nolint:gocritic // needed for SCIM
-	dbUser, err := api.Database.GetUserByID(dbauthz.AsSystemRestricted(ctx), uid)
+	user, err = api.scimUpdateUser(ctx, user, sUser)
 	if err != nil {
 		_ = handlerutil.WriteError(rw, err)
 		return
 	}
+	aReq.New = user
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMUser(user, sUser.Groups))
+}
 
-	var status database.UserStatus
-	if sUser.Active {
-		status = database.UserStatusActive
-	} else {
-		status = database.UserStatusSuspended
+// scimDeleteUser deprovisions a user. Users without workspaces are deleted.
+// Users that own workspaces are suspended instead, since deleting them would
+// orphan their workspaces. Owners can't be deprovisioned.
+//
+// @Summary SCIM 2.0: Delete user
+// @ID scim-delete-user
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param id path string true "User ID" format(uuid)
+// @Success 204
+// @Router /scim/v2/Users/{id} [delete]
+func (api *API) scimDeleteUser(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
 	}
 
-	//nolint:gocritic // needed for SCIM
-	_, err = api.Database.UpdateUserStatus(dbauthz.AsSystemRestricted(r.Context()), database.UpdateUserStatusParams{
-		ID:        dbUser.ID,
-		Status:    status,
-		UpdatedAt: database.Now(),
+	aReq, commitAudit := audit.InitRequest[database.User](rw, api.scimAuditParams(r, database.AuditActionDelete))
+	defer commitAudit()
+
+	user, _, err := api.scimUser(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.Old = user
+	err = api.scimRejectOwner(ctx, user)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	workspaces, err := api.Database.GetWorkspaces(ctx, database.GetWorkspacesParams{
+		OwnerID: user.ID,
 	})
 	if err != nil {
 		_ = handlerutil.WriteError(rw, err)
 		return
 	}
-
-	httpapi.Write(ctx, rw, http.StatusOK, sUser)
+	if len(workspaces) > 0 {
+		aReq.Action = database.AuditActionWrite
+		aReq.New, err = api.Database.UpdateUserStatus(ctx, database.UpdateUserStatusParams{
+			ID:        user.ID,
+			Status:    database.UserStatusSuspended,
+			UpdatedAt: database.Now(),
+		})
+	} else {
+		err = api.Database.UpdateUserDeletedByID(ctx, database.UpdateUserDeletedByIDParams{
+			ID:      user.ID,
+			Deleted: true,
+		})
+	}
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
 }
@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/cryptorand"
 	"github.com/coder/coder/enterprise/coderd"
@@ -246,4 +249,339 @@ func TestScim(t *testing.T) {
 			assert.Equal(t, codersdk.UserStatusSuspended, userRes.Users[0].Status)
 		})
 	})
+
+	t.Run("getUsers", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client, scimAPIKey := setupScim(t)
+		sUser := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+		_ = postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+
+		var list struct {
+			TotalResults int               `json:"totalResults"`
+			Resources    []coderd.SCIMUser `json:"Resources"`
+		}
+		res, err := client.Request(ctx, "GET", fmt.Sprintf("/scim/v2/Users?filter=userName+eq+%q", sUser.UserName), nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+		require.Equal(t, 1, list.TotalResults)
+		require.Len(t, list.Resources, 1)
+		assert.Equal(t, sUser.ID, list.Resources[0].ID)
+		assert.True(t, list.Resources[0].Active)
+
+		// The first user and both SCIM users.
+		res, err = client.Request(ctx, "GET", "/scim/v2/Users?count=1", nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+		assert.Equal(t, 3, list.TotalResults)
+		assert.Len(t, list.Resources, 1)
+
+		res, err = client.Request(ctx, "GET", "/scim/v2/Users?filter=userName+sw+%22a%22", nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("getUser", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client, scimAPIKey := setupScim(t)
+		sUser := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+
+		var got coderd.SCIMUser
+		res, err := client.Request(ctx, "GET", "/scim/v2/Users/"+sUser.ID, nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, sUser.UserName, got.UserName)
+		assert.Equal(t, sUser.Emails[0].Value, got.Emails[0].Value)
+
+		res, err = client.Request(ctx, "GET", "/scim/v2/Users/"+uuid.NewString(), nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("putUser", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client, scimAPIKey := setupScim(t)
+		sUser := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+
+		replace := makeScimUser(t)
+		replace.Active = false
+		res, err := client.Request(ctx, "PUT", "/scim/v2/Users/"+sUser.ID, replace, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		user, err := client.User(ctx, sUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, replace.UserName, user.Username)
+		assert.Equal(t, replace.Emails[0].Value, user.Email)
+		assert.Equal(t, codersdk.UserStatusSuspended, user.Status)
+	})
+
+	t.Run("patchUserOperations", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client, scimAPIKey := setupScim(t)
+		sUser := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+
+		// Azure AD sends booleans as strings and capitalizes operations.
+		res, err := client.Request(ctx, "PATCH", "/scim/v2/Users/"+sUser.ID, json.RawMessage(`{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "Replace", "path": "active", "value": "False"},
+				{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "patched@coder.com"}
+			]
+		}`), setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		user, err := client.User(ctx, sUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "patched@coder.com", user.Email)
+		assert.Equal(t, codersdk.UserStatusSuspended, user.Status)
+
+		res, err = client.Request(ctx, "PATCH", "/scim/v2/Users/"+sUser.ID, json.RawMessage(`{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [{"op": "replace", "value": {"active": true}}]
+		}`), setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		user, err = client.User(ctx, sUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, codersdk.UserStatusActive, user.Status)
+	})
+
+	t.Run("deleteUser", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client, scimAPIKey := setupScim(t)
+		sUser := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+
+		res, err := client.Request(ctx, "DELETE", "/scim/v2/Users/"+sUser.ID, nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		res, err = client.Request(ctx, "GET", "/scim/v2/Users/"+sUser.ID, nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		client, scimAPIKey := setupScim(t)
+		owner, err := client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		// Owners can neither be deleted nor suspended.
+		res, err := client.Request(ctx, "DELETE", "/scim/v2/Users/"+owner.ID.String(), nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res, err = client.Request(ctx, "PATCH", "/scim/v2/Users/"+owner.ID.String(), json.RawMessage(`{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [{"op": "replace", "path": "active", "value": false}]
+		}`), setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		owner, err = client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.UserStatusActive, owner.Status)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		auditor := audit.NewMock()
+		scimAPIKey := []byte("hi")
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			AuditLogging: true,
+			SCIMAPIKey:   scimAPIKey,
+			Options: &coderdtest.Options{
+				Auditor: auditor,
+			},
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+		coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			AccountID: "coolin",
+			Features: license.Features{
+				codersdk.FeatureSCIM:     1,
+				codersdk.FeatureAuditLog: 1,
+			},
+		})
+
+		numLogs := len(auditor.AuditLogs())
+		sUser := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		created := auditor.AuditLogs()[numLogs-1]
+		assert.Equal(t, database.AuditActionCreate, created.Action)
+		assert.Equal(t, database.ResourceTypeUser, created.ResourceType)
+		assert.Equal(t, sUser.ID, created.ResourceID.String())
+		assert.Equal(t, uuid.Nil, created.UserID)
+		assert.JSONEq(t, `{"source":"scim"}`, string(created.AdditionalFields))
+
+		res, err := client.Request(ctx, "DELETE", "/scim/v2/Users/"+sUser.ID, nil, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		assert.Equal(t, database.AuditActionDelete, auditor.AuditLogs()[numLogs-1].Action)
+
+		res, err = client.Request(ctx, "POST", "/scim/v2/Groups", coderd.SCIMGroup{
+			DisplayName: "auditors",
+		}, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		numLogs++
+		require.Len(t, auditor.AuditLogs(), numLogs)
+		assert.Equal(t, database.AuditActionCreate, auditor.AuditLogs()[numLogs-1].Action)
+		assert.Equal(t, database.ResourceTypeGroup, auditor.AuditLogs()[numLogs-1].ResourceType)
+	})
+}
+
+func TestScimGroups(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	client, scimAPIKey := setupScim(t)
+	alice := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+	bob := postScimUser(ctx, t, client, scimAPIKey, makeScimUser(t))
+
+	var group coderd.SCIMGroup
+	res, err := client.Request(ctx, "POST", "/scim/v2/Groups", coderd.SCIMGroup{
+		DisplayName: "engineers",
+		Members:     []coderd.SCIMMember{{Value: alice.ID}},
+	}, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&group))
+	require.Equal(t, "engineers", group.DisplayName)
+	require.Len(t, group.Members, 1)
+	require.Equal(t, alice.ID, group.Members[0].Value)
+
+	// The group is listed on the user.
+	var user coderd.SCIMUser
+	res, err = client.Request(ctx, "GET", "/scim/v2/Users/"+alice.ID, nil, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&user))
+	require.Equal(t, []coderd.SCIMMember{{Value: group.ID, Display: "engineers"}}, user.Groups)
+
+	res, err = client.Request(ctx, "PATCH", "/scim/v2/Groups/"+group.ID, json.RawMessage(fmt.Sprintf(`{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": %q}]},
+			{"op": "remove", "path": "members[value eq \"%s\"]"},
+			{"op": "replace", "path": "displayName", "value": "developers"}
+		]
+	}`, bob.ID, alice.ID)), setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&group))
+	assert.Equal(t, "developers", group.DisplayName)
+	require.Len(t, group.Members, 1)
+	assert.Equal(t, bob.ID, group.Members[0].Value)
+
+	var list struct {
+		TotalResults int                `json:"totalResults"`
+		Resources    []coderd.SCIMGroup `json:"Resources"`
+	}
+	res, err = client.Request(ctx, "GET", "/scim/v2/Groups?filter=displayName+eq+%22developers%22", nil, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+	require.Equal(t, 1, list.TotalResults)
+	assert.Equal(t, group.ID, list.Resources[0].ID)
+
+	res, err = client.Request(ctx, "PUT", "/scim/v2/Groups/"+group.ID, coderd.SCIMGroup{
+		DisplayName: "developers",
+		Members:     []coderd.SCIMMember{{Value: alice.ID}, {Value: bob.ID}},
+	}, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&group))
+	assert.Len(t, group.Members, 2)
+
+	res, err = client.Request(ctx, "DELETE", "/scim/v2/Groups/"+group.ID, nil, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	res, err = client.Request(ctx, "GET", "/scim/v2/Groups/"+group.ID, nil, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func setupScim(t *testing.T) (*codersdk.Client, []byte) {
+	t.Helper()
+
+	scimAPIKey := []byte("hi")
+	client := coderdenttest.New(t, &coderdenttest.Options{SCIMAPIKey: scimAPIKey})
+	_ = coderdtest.CreateFirstUser(t, client)
+	coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		AccountID: "coolin",
+		Features: license.Features{
+			codersdk.FeatureSCIM: 1,
+		},
+	})
+	return client, scimAPIKey
+}
+
+func postScimUser(ctx context.Context, t *testing.T, client *codersdk.Client, scimAPIKey []byte, sUser coderd.SCIMUser) coderd.SCIMUser {
+	t.Helper()
+
+	res, err := client.Request(ctx, "POST", "/scim/v2/Users", sUser, setScimAuth(scimAPIKey))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&sUser))
+	return sUser
 }
@@ -0,0 +1,574 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+)
+
+// SCIMGroup is a group provisioned by an identity provider. Groups map onto
+// the groups of the default organization.
+type SCIMGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id"`
+	DisplayName string       `json:"displayName"`
+	Members     []SCIMMember `json:"members"`
+	Meta        struct {
+		ResourceType string `json:"resourceType"`
+	} `json:"meta"`
+}
+
+func convertSCIMGroup(group database.Group, members []database.User) SCIMGroup {
+	sGroup := SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          group.ID.String(),
+		DisplayName: group.Name,
+		Members:     make([]SCIMMember, 0, len(members)),
+	}
+	for _, member := range members {
+		sGroup.Members = append(sGroup.Members, SCIMMember{
+			Value:   member.ID.String(),
+			Display: member.Username,
+		})
+	}
+	sGroup.Meta.ResourceType = "Group"
+	return sGroup
+}
+
+func scimGroupMatches(group SCIMGroup, clauses []scimFilterClause) bool {
+	for _, clause := range clauses {
+		switch clause.Attribute {
+		case "id":
+			if group.ID != clause.Value {
+				return false
+			}
+		case "displayname":
+			if !strings.EqualFold(group.DisplayName, clause.Value) {
+				return false
+			}
+		case "members", "members.value":
+			found := false
+			for _, member := range group.Members {
+				if member.Value == clause.Value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// scimGroup returns the group of the id URL parameter. The group of all users
+// is managed by Coder and can't be provisioned.
+func (api *API) scimGroup(ctx context.Context, r *http.Request) (database.Group, []database.User, error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("group %q: %w", chi.URLParam(r, "id"), spec.ErrNotFound)
+	}
+	organizationID, err := api.scimOrganizationID(ctx)
+	if err != nil {
+		return database.Group{}, nil, err
+	}
+	group, err := api.Database.GetGroupByID(ctx, id)
+	if xerrors.Is(err, sql.ErrNoRows) || (err == nil && (group.OrganizationID != organizationID || group.ID == organizationID)) {
+		return database.Group{}, nil, xerrors.Errorf("group %q: %w", id, spec.ErrNotFound)
+	}
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("get group: %w", err)
+	}
+	members, err := api.Database.GetGroupMembers(ctx, group.ID)
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("get group members: %w", err)
+	}
+	return group, members, nil
+}
+
+// scimGroupMemberIDs parses the IDs of members, which must be members of
+// the organization.
+func (api *API) scimGroupMemberIDs(ctx context.Context, organizationID uuid.UUID, members []SCIMMember) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member.Value)
+		if err != nil {
+			return nil, xerrors.Errorf("member %q must be a user ID: %w", member.Value, spec.ErrInvalidValue)
+		}
+		_, err = api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+			OrganizationID: organizationID,
+			UserID:         id,
+		})
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("member %q must be a member of the organization: %w", member.Value, spec.ErrInvalidValue)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("get organization member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// scimSaveGroup renames the group and sets its members to exactly the
+// members given. A group without an ID is created in the same transaction as
+// its members.
+func (api *API) scimSaveGroup(ctx context.Context, group database.Group, name string, memberIDs []uuid.UUID) (database.Group, []database.User, error) {
+	if name == database.AllUsersGroup {
+		return database.Group{}, nil, xerrors.Errorf("%q is a reserved group name: %w", database.AllUsersGroup, spec.ErrInvalidValue)
+	}
+	err := api.Database.InTx(func(tx database.Store) error {
+		var err error
+		switch {
+		case group.ID == uuid.Nil:
+			group, err = tx.InsertGroup(ctx, database.InsertGroupParams{
+				ID:             uuid.New(),
+				Name:           name,
+				OrganizationID: group.OrganizationID,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert group: %w", err)
+			}
+		case name != group.Name:
+			group, err = tx.UpdateGroupByID(ctx, database.UpdateGroupByIDParams{
+				ID:             group.ID,
+				Name:           name,
+				AvatarURL:      group.AvatarURL,
+				QuotaAllowance: group.QuotaAllowance,
+			})
+			if err != nil {
+				return xerrors.Errorf("update group by ID: %w", err)
+			}
+		}
+
+		current, err := tx.GetGroupMembers(ctx, group.ID)
+		if err != nil {
+			return xerrors.Errorf("get group members: %w", err)
+		}
+		want := make(map[uuid.UUID]bool, len(memberIDs))
+		for _, id := range memberIDs {
+			want[id] = true
+		}
+		for _, member := range current {
+			if want[member.ID] {
+				delete(want, member.ID)
+				continue
+			}
+			err = tx.DeleteGroupMemberFromGroup(ctx, database.DeleteGroupMemberFromGroupParams{
+				UserID:  member.ID,
+				GroupID: group.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("delete group member %q: %w", member.ID, err)
+			}
+		}
+		for _, id := range memberIDs {
+			if !want[id] {
+				continue
+			}
+			delete(want, id)
+			err = tx.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+				UserID:  id,
+				GroupID: group.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert group member %q: %w", id, err)
+			}
+		}
+		return nil
+	}, nil)
+	if database.IsUniqueViolation(err) {
+		return database.Group{}, nil, xerrors.Errorf("a group named %q already exists: %w", name, spec.ErrUniqueness)
+	}
+	if err != nil {
+		return database.Group{}, nil, err
+	}
+	// Membership changes may change the DERP regions of the members.
+	api.publishDERPMapUpdate(ctx)
+
+	members, err := api.Database.GetGroupMembers(ctx, group.ID)
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("get group members: %w", err)
+	}
+	return group, members, nil
+}
+
+// scimGetGroups returns the groups matching the filter query parameter. Only
+// eq comparisons of id, displayName and members.value are supported.
+//
+// @Summary SCIM 2.0: Get groups
+// @ID scim-get-groups
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param filter query string false "Filter of eq comparisons joined by and"
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Maximum number of results"
+// @Success 200
+// @Router /scim/v2/Groups [get]
+func (api *API) scimGetGroups(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	clauses, err := parseSCIMFilter(r.URL.Query().Get("filter"), "id", "displayName", "members", "members.value")
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	organizationID, err := api.scimOrganizationID(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	groups, err := api.Database.GetGroupsByOrganizationID(ctx, organizationID)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	sGroups := []SCIMGroup{}
+	for _, group := range groups {
+		if group.ID == organizationID {
+			continue
+		}
+		members, err := api.Database.GetGroupMembers(ctx, group.ID)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		sGroup := convertSCIMGroup(group, members)
+		if scimGroupMatches(sGroup, clauses) {
+			sGroups = append(sGroups, sGroup)
+		}
+	}
+	res, err := scimPaginate(r, sGroups)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
+}
+
+// @Summary SCIM 2.0: Get group by ID
+// @ID scim-get-group-by-id
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} coderd.SCIMGroup
+// @Failure 404
+// @Router /scim/v2/Groups/{id} [get]
+func (api *API) scimGetGroup(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	group, members, err := api.scimGroup(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMGroup(group, members))
+}
+
+// @Summary SCIM 2.0: Create new group
+// @ID scim-create-new-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param request body coderd.SCIMGroup true "New group"
+// @Success 201 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups [post]
+func (api *API) scimPostGroup(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.AuditableGroup](rw, api.scimAuditParams(r, database.AuditActionCreate))
+	defer commitAudit()
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		scimError(rw, spec.ErrInvalidSyntax, err.Error())
+		return
+	}
+	if sGroup.DisplayName == "" {
+		scimError(rw, spec.ErrInvalidValue, "displayName is required")
+		return
+	}
+	if sGroup.DisplayName == database.AllUsersGroup {
+		scimError(rw, spec.ErrInvalidValue, database.AllUsersGroup+" is a reserved group name")
+		return
+	}
+
+	organizationID, err := api.scimOrganizationID(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	memberIDs, err := api.scimGroupMemberIDs(ctx, organizationID, sGroup.Members)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	group, members, err := api.scimSaveGroup(ctx, database.Group{OrganizationID: organizationID}, sGroup.DisplayName, memberIDs)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.New = group.Auditable(members)
+	httpapi.Write(ctx, rw, http.StatusCreated, convertSCIMGroup(group, members))
+}
+
+// scimPutGroup replaces the name and members of a group.
+//
+// @Summary SCIM 2.0: Replace group
+// @ID scim-replace-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param request body coderd.SCIMGroup true "Replace group request"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [put]
+func (api *API) scimPutGroup(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.AuditableGroup](rw, api.scimAuditParams(r, database.AuditActionWrite))
+	defer commitAudit()
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		scimError(rw, spec.ErrInvalidSyntax, err.Error())
+		return
+	}
+
+	group, currentMembers, err := api.scimGroup(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.Old = group.Auditable(currentMembers)
+	if sGroup.DisplayName == "" {
+		sGroup.DisplayName = group.Name
+	}
+	memberIDs, err := api.scimGroupMemberIDs(ctx, group.OrganizationID, sGroup.Members)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	group, members, err := api.scimSaveGroup(ctx, group, sGroup.DisplayName, memberIDs)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.New = group.Auditable(members)
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMGroup(group, members))
+}
+
+// applySCIMGroupPatch applies an operation to the name and members of
+// sGroup.
+func applySCIMGroupPatch(sGroup *SCIMGroup, op SCIMPatchOperation) error {
+	if op.Path == "" {
+		// Without a path, the value holds the attributes to change.
+		var attributes map[string]json.RawMessage
+		err := json.Unmarshal(op.Value, &attributes)
+		if err != nil {
+			return xerrors.Errorf("value must be an object without a path: %w", spec.ErrInvalidValue)
+		}
+		for path, value := range attributes {
+			err = applySCIMGroupPatch(sGroup, SCIMPatchOperation{Op: op.Op, Path: path, Value: value})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	operation := strings.ToLower(op.Op)
+	path := strings.ToLower(op.Path)
+	switch {
+	case path == "displayname":
+		if operation == "remove" {
+			return xerrors.Errorf("displayName is required: %w", spec.ErrMutability)
+		}
+		var name string
+		err := json.Unmarshal(op.Value, &name)
+		if err != nil {
+			return xerrors.Errorf("displayName must be a string: %w", spec.ErrInvalidValue)
+		}
+		sGroup.DisplayName = name
+	case path == "members":
+		var members []SCIMMember
+		if len(op.Value) > 0 {
+			err := json.Unmarshal(op.Value, &members)
+			if err != nil {
+				return xerrors.Errorf("members must be a list of members: %w", spec.ErrInvalidValue)
+			}
+		}
+		switch operation {
+		case "add":
+			sGroup.Members = append(sGroup.Members, members...)
+		case "replace":
+			sGroup.Members = members
+		case "remove":
+			if len(op.Value) == 0 {
+				sGroup.Members = nil
+				return nil
+			}
+			for _, member := range members {
+				sGroup.removeMember(member.Value)
+			}
+		default:
+			return xerrors.Errorf("unsupported operation %q: %w", op.Op, spec.ErrInvalidSyntax)
+		}
+	case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]"):
+		// e.g. members[value eq "2819c223-7f76-453a-919d-413861904646"]
+		clauses, err := parseSCIMFilter(op.Path[len("members["):len(op.Path)-1], "value")
+		if err != nil {
+			return xerrors.Errorf("invalid path %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+		if operation != "remove" {
+			return xerrors.Errorf("members can only be removed by filter: %w", spec.ErrInvalidPath)
+		}
+		for _, clause := range clauses {
+			sGroup.removeMember(clause.Value)
+		}
+	default:
+		return xerrors.Errorf("unsupported path %q: %w", op.Path, spec.ErrInvalidPath)
+	}
+	return nil
+}
+
+func (g *SCIMGroup) removeMember(id string) {
+	members := make([]SCIMMember, 0, len(g.Members))
+	for _, member := range g.Members {
+		if !strings.EqualFold(member.Value, id) {
+			members = append(members, member)
+		}
+	}
+	g.Members = members
+}
+
+// scimPatchGroup renames a group, or adds and removes members.
+//
+// @Summary SCIM 2.0: Update group
+// @ID scim-update-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param request body coderd.SCIMPatchRequest true "Update group request"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [patch]
+func (api *API) scimPatchGroup(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.AuditableGroup](rw, api.scimAuditParams(r, database.AuditActionWrite))
+	defer commitAudit()
+
+	var patch SCIMPatchRequest
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil {
+		scimError(rw, spec.ErrInvalidSyntax, err.Error())
+		return
+	}
+
+	group, members, err := api.scimGroup(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.Old = group.Auditable(members)
+	sGroup := convertSCIMGroup(group, members)
+	for _, op := range patch.Operations {
+		err = applySCIMGroupPatch(&sGroup, op)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+	}
+
+	memberIDs, err := api.scimGroupMemberIDs(ctx, group.OrganizationID, sGroup.Members)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	group, members, err = api.scimSaveGroup(ctx, group, sGroup.DisplayName, memberIDs)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.New = group.Auditable(members)
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMGroup(group, members))
+}
+
+// @Summary SCIM 2.0: Delete group
+// @ID scim-delete-group
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Success 204
+// @Router /scim/v2/Groups/{id} [delete]
+func (api *API) scimDeleteGroup(rw http.ResponseWriter, r *http.Request) {
+	//nolint:gocritic // SCIM requests are authorized by the SCIM API key.
+	ctx := dbauthz.AsSCIM(r.Context())
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.AuditableGroup](rw, api.scimAuditParams(r, database.AuditActionDelete))
+	defer commitAudit()
+
+	group, members, err := api.scimGroup(ctx, r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	aReq.Old = group.Auditable(members)
+	err = api.Database.DeleteGroupByID(ctx, group.ID)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	api.publishDERPMapUpdate(ctx)
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 )
@@ -50,3 +51,26 @@ func (api *API) setUserGroups(ctx context.Context, db database.Store, userID uui
 		return nil
 	}, nil)
 }
+
+func (api *API) setUserSiteRoles(ctx context.Context, db database.Store, userID uuid.UUID, roles []string) error {
+	api.entitlementsMu.RLock()
+	enabled := api.entitlements.Features[codersdk.FeatureUserRoleManagement].Enabled
+	api.entitlementsMu.RUnlock()
+
+	if !enabled {
+		api.Logger.Warn(ctx, "attempted to assign OIDC user roles without enterprise entitlement, roles left unchanged",
+			slog.F("user_id", userID), slog.F("roles", roles),
+		)
+		return nil
+	}
+
+	_, err := db.UpdateUserRoles(ctx, database.UpdateUserRolesParams{
+		GrantedRoles: roles,
+		ID:           userID,
+	})
+	if err != nil {
+		return xerrors.Errorf("update user roles: %w", err)
+	}
+
+	return nil
+}
@@ -13,7 +13,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/testutil"
@@ -219,6 +222,188 @@ func TestUserOIDC(t *testing.T) {
 			require.Len(t, group.Members, 0)
 		})
 	})
+
+	t.Run("Roles", func(t *testing.T) {
+		t.Parallel()
+		t.Run("Assigns", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			conf := coderdtest.NewOIDCConfig(t, "")
+
+			const roleClaim = "roles"
+			config := conf.OIDCConfig(t, jwt.MapClaims{}, func(cfg *coderd.OIDCConfig) {
+				cfg.UserRoleField = roleClaim
+			})
+			config.AllowSignups = true
+
+			auditor := audit.NewMock()
+			client := coderdenttest.New(t, &coderdenttest.Options{
+				AuditLogging: true,
+				Options: &coderdtest.Options{
+					OIDCConfig: config,
+					Auditor:    auditor,
+				},
+			})
+			_ = coderdtest.CreateFirstUser(t, client)
+			coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				AllFeatures: true,
+			})
+
+			resp := oidcCallback(t, client, conf.EncodeClaims(t, jwt.MapClaims{
+				"email":   "colin@coder.com",
+				roleClaim: []string{rbac.RoleTemplateAdmin(), "not-a-role"},
+			}))
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+			user, err := client.User(ctx, "colin")
+			require.NoError(t, err)
+			requireSiteRoles(t, user, rbac.RoleTemplateAdmin())
+
+			var rolesAudited bool
+			for _, log := range auditor.AuditLogs() {
+				if log.ResourceID == user.ID && log.Action == database.AuditActionWrite {
+					rolesAudited = true
+				}
+			}
+			require.True(t, rolesAudited, "role change audited")
+
+			// Logging in again with the same roles changes nothing.
+			auditor.ResetLogs()
+			resp = oidcCallback(t, client, conf.EncodeClaims(t, jwt.MapClaims{
+				"email":   "colin@coder.com",
+				roleClaim: []string{rbac.RoleTemplateAdmin()},
+			}))
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+			for _, log := range auditor.AuditLogs() {
+				require.NotEqual(t, database.AuditActionWrite, log.Action, "unchanged roles audited")
+			}
+		})
+
+		t.Run("AssignsMapped", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			conf := coderdtest.NewOIDCConfig(t, "")
+
+			config := conf.OIDCConfig(t, jwt.MapClaims{}, func(cfg *coderd.OIDCConfig) {
+				cfg.UserRoleField = "roles"
+				cfg.UserRoleMapping = map[string][]string{
+					"coder-admins": {rbac.RoleOwner()},
+					"security":     {"auditor", rbac.RoleUserAdmin()},
+				}
+			})
+			config.AllowSignups = true
+
+			client := coderdenttest.New(t, &coderdenttest.Options{
+				Options: &coderdtest.Options{
+					OIDCConfig: config,
+				},
+			})
+			_ = coderdtest.CreateFirstUser(t, client)
+			coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				AllFeatures: true,
+			})
+
+			resp := oidcCallback(t, client, conf.EncodeClaims(t, jwt.MapClaims{
+				"email": "colin@coder.com",
+				"roles": []string{"coder-admins", "security"},
+			}))
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+			user, err := client.User(ctx, "colin")
+			require.NoError(t, err)
+			requireSiteRoles(t, user, rbac.RoleOwner(), "auditor", rbac.RoleUserAdmin())
+		})
+
+		t.Run("DefaultThenRemove", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			conf := coderdtest.NewOIDCConfig(t, "")
+
+			config := conf.OIDCConfig(t, jwt.MapClaims{}, func(cfg *coderd.OIDCConfig) {
+				cfg.UserRoleField = "roles"
+				cfg.UserRolesDefault = []string{rbac.RoleTemplateAdmin()}
+			})
+			config.AllowSignups = true
+
+			client := coderdenttest.New(t, &coderdenttest.Options{
+				Options: &coderdtest.Options{
+					OIDCConfig: config,
+				},
+			})
+			_ = coderdtest.CreateFirstUser(t, client)
+			coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				AllFeatures: true,
+			})
+
+			resp := oidcCallback(t, client, conf.EncodeClaims(t, jwt.MapClaims{
+				"email": "colin@coder.com",
+				"roles": []string{rbac.RoleOwner()},
+			}))
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+			user, err := client.User(ctx, "colin")
+			require.NoError(t, err)
+			requireSiteRoles(t, user, rbac.RoleOwner())
+
+			// Without a role claim the owner role is revoked and the default
+			// role is assigned.
+			resp = oidcCallback(t, client, conf.EncodeClaims(t, jwt.MapClaims{
+				"email": "colin@coder.com",
+			}))
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+			user, err = client.User(ctx, "colin")
+			require.NoError(t, err)
+			requireSiteRoles(t, user, rbac.RoleTemplateAdmin())
+		})
+
+		t.Run("NotEntitled", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			conf := coderdtest.NewOIDCConfig(t, "")
+
+			config := conf.OIDCConfig(t, jwt.MapClaims{}, func(cfg *coderd.OIDCConfig) {
+				cfg.UserRoleField = "roles"
+			})
+			config.AllowSignups = true
+
+			client := coderdenttest.New(t, &coderdenttest.Options{
+				Options: &coderdtest.Options{
+					OIDCConfig: config,
+				},
+			})
+			_ = coderdtest.CreateFirstUser(t, client)
+
+			resp := oidcCallback(t, client, conf.EncodeClaims(t, jwt.MapClaims{
+				"email": "colin@coder.com",
+				"roles": []string{rbac.RoleOwner()},
+			}))
+			assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+			user, err := client.User(ctx, "colin")
+			require.NoError(t, err)
+			requireSiteRoles(t, user)
+		})
+	})
+}
+
+// requireSiteRoles asserts the user has exactly the given site roles, on top
+// of the implied member role.
+func requireSiteRoles(t *testing.T, user codersdk.User, roles ...string) {
+	t.Helper()
+
+	names := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		if role.Name == rbac.RoleMember() {
+			continue
+		}
+		names = append(names, role.Name)
+	}
+	require.ElementsMatch(t, roles, names)
 }
 
 func oidcCallback(t *testing.T, client *codersdk.Client, code string) *http.Response {
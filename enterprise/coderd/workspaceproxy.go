@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -554,14 +555,55 @@ func (api *API) workspaceProxyRegister(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	activeKeyID, keys := api.AppSecurityKeys.Keys()
+	appSecurityKeys := make([]wsproxysdk.AppSecurityKey, 0, len(keys))
+	for id, key := range keys {
+		appSecurityKeys = append(appSecurityKeys, wsproxysdk.AppSecurityKey{
+			ID:  id,
+			Key: key.String(),
+		})
+	}
+	sort.Slice(appSecurityKeys, func(i, j int) bool {
+		return appSecurityKeys[i].ID < appSecurityKeys[j].ID
+	})
+
 	// aReq.New = updatedProxy
 	httpapi.Write(ctx, rw, http.StatusCreated, wsproxysdk.RegisterWorkspaceProxyResponse{
-		AppSecurityKey: api.AppSecurityKey.String(),
+		AppSecurityKeys:        appSecurityKeys,
+		ActiveAppSecurityKeyID: activeKeyID,
 	})
 
 	go api.forceWorkspaceProxyHealthUpdate(api.ctx)
 }
 
+// workspaceProxyReportAppUsage stores the usage of workspace apps proxied by
+// the workspace proxy.
+// @Summary Report workspace app usage
+// @ID report-workspace-app-usage
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Enterprise
+// @Param request body wsproxysdk.ReportAppUsageRequest true "Report app usage request"
+// @Success 204
+// @Router /workspaceproxies/me/app-usage [post]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyReportAppUsage(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req wsproxysdk.ReportAppUsageRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	err := workspaceapps.UpsertAppUsage(ctx, api.Database, req.Usage)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
 // workspaceProxyGoingAway is used to tell coderd that the workspace proxy is
 // shutting down and going away. The main purpose of this function is for the
 // health status of the workspace proxy to be more quickly updated when we know
@@ -327,6 +327,47 @@ func TestWorkspaceProxyCRUD(t *testing.T) {
 	})
 }
 
+func TestWorkspaceProxyRegister(t *testing.T) {
+	t.Parallel()
+
+	dv := coderdtest.DeploymentValues(t)
+	dv.Experiments = []string{
+		string(codersdk.ExperimentMoons),
+		"*",
+	}
+	client := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			DeploymentValues: dv,
+		},
+	})
+	_ = coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		Features: license.Features{
+			codersdk.FeatureWorkspaceProxy: 1,
+		},
+	})
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	proxyRes, err := client.CreateWorkspaceProxy(ctx, codersdk.CreateWorkspaceProxyRequest{
+		Name: namesgenerator.GetRandomName(1),
+		Icon: "/emojis/flag.png",
+	})
+	require.NoError(t, err)
+
+	proxyClient := wsproxysdk.New(client.URL)
+	proxyClient.SetSessionToken(proxyRes.ProxyToken)
+	res, err := proxyClient.RegisterWorkspaceProxy(ctx, wsproxysdk.RegisterWorkspaceProxyRequest{
+		AccessURL:        "https://proxy.coder.test",
+		WildcardHostname: "*.proxy.coder.test",
+	})
+	require.NoError(t, err)
+	require.Equal(t, coderdtest.AppSecurityKeyID, res.ActiveAppSecurityKeyID)
+	require.Equal(t, []wsproxysdk.AppSecurityKey{{
+		ID:  coderdtest.AppSecurityKeyID,
+		Key: coderdtest.AppSecurityKey.String(),
+	}}, res.AppSecurityKeys)
+}
+
 func TestIssueSignedAppToken(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,269 @@
+package tailnet
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/google/uuid"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	agpl "github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/proto"
+)
+
+// Coordinate connects a peer using version 2 of the coordination protocol.
+// The high availability coordinator exchanges version 1 messages between
+// replicas, so peers are served by version 1 connections: an agent by a
+// single connection, and other peers by one connection per tunnel.
+func (c *haCoordinator) Coordinate(
+	ctx context.Context, id uuid.UUID, name string, a agpl.TunnelAuth,
+) (
+	chan<- *proto.CoordinateRequest, <-chan *proto.CoordinateResponse,
+) {
+	reqs := make(chan *proto.CoordinateRequest, agpl.RequestBufferSize)
+	resps := make(chan *proto.CoordinateResponse, agpl.ResponseBufferSize)
+	p := &v1Peer{
+		logger:   c.log.With(slog.F("peer_id", id), slog.F("peer_name", name)),
+		coord:    c,
+		id:       id,
+		name:     name,
+		auth:     a,
+		resps:    resps,
+		sessions: make(map[uuid.UUID]net.Conn),
+	}
+	go p.run(ctx, reqs)
+	return reqs, resps
+}
+
+// v1Peer translates between a version 2 peer and version 1 connections to
+// the coordinator.
+type v1Peer struct {
+	logger slog.Logger
+	coord  agpl.Coordinator
+	id     uuid.UUID
+	name   string
+	auth   agpl.TunnelAuth
+
+	mu    sync.Mutex
+	resps chan<- *proto.CoordinateResponse
+	node  *agpl.Node
+	// sessions maps tunnel destinations to version 1 connections. Agents
+	// have a single session keyed by their own ID.
+	sessions map[uuid.UUID]net.Conn
+	closed   bool
+}
+
+func (p *v1Peer) isAgent() bool {
+	_, ok := p.auth.(agpl.AgentTunnelAuth)
+	return ok
+}
+
+func (p *v1Peer) run(ctx context.Context, reqs <-chan *proto.CoordinateRequest) {
+	defer p.close()
+	if p.isAgent() {
+		p.openSession(p.id, func(conn net.Conn) error {
+			return p.coord.ServeAgent(conn, p.id, p.name)
+		})
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req, ok := <-reqs:
+			if !ok {
+				return
+			}
+			if !p.handleRequest(req) {
+				return
+			}
+		}
+	}
+}
+
+// handleRequest returns false once the peer is done.
+func (p *v1Peer) handleRequest(req *proto.CoordinateRequest) bool {
+	if req.UpdateSelf != nil {
+		node, err := agpl.ProtoToNode(req.UpdateSelf.GetNode())
+		if err != nil {
+			p.logger.Warn(context.Background(), "invalid node", slog.Error(err))
+			p.reject("invalid node")
+			return false
+		}
+		p.updateSelf(node)
+	}
+	if req.AddTunnel != nil {
+		dst, err := uuid.FromBytes(req.AddTunnel.GetId())
+		if err != nil {
+			p.reject("invalid tunnel ID")
+			return false
+		}
+		if !p.auth.Authorize(dst) {
+			p.reject("unauthorized tunnel to " + dst.String())
+			return false
+		}
+		p.openSession(dst, func(conn net.Conn) error {
+			return p.coord.ServeClient(conn, uuid.New(), dst)
+		})
+	}
+	if req.RemoveTunnel != nil {
+		dst, err := uuid.FromBytes(req.RemoveTunnel.GetId())
+		if err != nil {
+			p.reject("invalid tunnel ID")
+			return false
+		}
+		p.closeSession(dst)
+	}
+	return req.Disconnect == nil
+}
+
+func (p *v1Peer) updateSelf(node *agpl.Node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.node = node
+	data, err := json.Marshal(node)
+	if err != nil {
+		p.logger.Error(context.Background(), "marshal node", slog.Error(err))
+		return
+	}
+	for _, conn := range p.sessions {
+		_, err := conn.Write(data)
+		if err != nil {
+			p.logger.Debug(context.Background(), "write node to session", slog.Error(err))
+		}
+	}
+}
+
+// openSession serves a version 1 connection for the tunnel to dst and
+// translates the nodes it receives into peer updates.
+func (p *v1Peer) openSession(dst uuid.UUID, serve func(conn net.Conn) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	if _, ok := p.sessions[dst]; ok {
+		return
+	}
+	client, server := net.Pipe()
+	p.sessions[dst] = client
+	go func() {
+		err := serve(server)
+		if err != nil {
+			p.logger.Debug(context.Background(), "serve version 1 session", slog.Error(err))
+		}
+		_ = server.Close()
+	}()
+	go p.readSession(dst, client)
+	if p.node != nil {
+		data, err := json.Marshal(p.node)
+		if err != nil {
+			p.logger.Error(context.Background(), "marshal node", slog.Error(err))
+			return
+		}
+		_, err = client.Write(data)
+		if err != nil {
+			p.logger.Debug(context.Background(), "write node to session", slog.Error(err))
+		}
+	}
+}
+
+func (p *v1Peer) readSession(dst uuid.UUID, conn net.Conn) {
+	decoder := json.NewDecoder(conn)
+	for {
+		var nodes []*agpl.Node
+		err := decoder.Decode(&nodes)
+		if err != nil {
+			p.logger.Debug(context.Background(), "read version 1 session", slog.Error(err))
+			p.mu.Lock()
+			current := p.sessions[dst] == conn
+			p.mu.Unlock()
+			// The coordinator closed the session, for example because the
+			// agent reconnected, so we are done with the peer.
+			if current {
+				p.close()
+			}
+			return
+		}
+		updates := make([]*proto.CoordinateResponse_PeerUpdate, 0, len(nodes))
+		for _, node := range nodes {
+			pn, err := agpl.NodeToProto(node)
+			if err != nil {
+				p.logger.Error(context.Background(), "convert node", slog.Error(err))
+				continue
+			}
+			id := dst
+			if p.isAgent() {
+				// Version 1 messages don't include the IDs of clients, so
+				// derive a stable one from the node.
+				id = nodeIDToUUID(node.ID)
+			}
+			updates = append(updates, &proto.CoordinateResponse_PeerUpdate{
+				Id:   agpl.UUIDToByteSlice(id),
+				Node: pn,
+				Kind: proto.CoordinateResponse_PeerUpdate_NODE,
+			})
+		}
+		p.send(&proto.CoordinateResponse{PeerUpdates: updates})
+	}
+}
+
+func (p *v1Peer) closeSession(dst uuid.UUID) {
+	p.mu.Lock()
+	conn, ok := p.sessions[dst]
+	delete(p.sessions, dst)
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+	_ = conn.Close()
+	p.send(&proto.CoordinateResponse{
+		PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+			Id:     agpl.UUIDToByteSlice(dst),
+			Kind:   proto.CoordinateResponse_PeerUpdate_DISCONNECTED,
+			Reason: "tunnel removed",
+		}},
+	})
+}
+
+func (p *v1Peer) send(resp *proto.CoordinateResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.resps <- resp:
+	default:
+		p.logger.Error(context.Background(), "response buffer full, dropping update")
+	}
+}
+
+func (p *v1Peer) reject(reason string) {
+	p.logger.Warn(context.Background(), "rejected peer request", slog.F("reason", reason))
+	p.send(&proto.CoordinateResponse{Error: reason})
+}
+
+func (p *v1Peer) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for dst, conn := range p.sessions {
+		_ = conn.Close()
+		delete(p.sessions, dst)
+	}
+	close(p.resps)
+}
+
+// nodeIDToUUID returns a UUID that stays the same for a tailnet node ID.
+func nodeIDToUUID(id tailcfg.NodeID) uuid.UUID {
+	var u uuid.UUID
+	binary.BigEndian.PutUint64(u[8:], uint64(id))
+	return u
+}
@@ -1,6 +1,7 @@
 package tailnet_test
 
 import (
+	"context"
 	"net"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/coder/coder/coderd/database/pubsub"
 	"github.com/coder/coder/enterprise/tailnet"
 	agpl "github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/proto"
 	"github.com/coder/coder/testutil"
 )
 
@@ -160,6 +162,69 @@ func TestCoordinatorSingle(t *testing.T) {
 		<-clientErrChan
 		<-closeClientChan
 	})
+	t.Run("Tunnels", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		coordinator, err := tailnet.NewCoordinator(slogtest.Make(t, nil), pubsub.NewInMemory())
+		require.NoError(t, err)
+		defer coordinator.Close()
+
+		agentID := uuid.New()
+		agentReqs, agentResps := coordinator.Coordinate(ctx, agentID, "agent", agpl.AgentTunnelAuth{})
+		clientReqs, clientResps := coordinator.Coordinate(ctx, uuid.New(), "client", agpl.ClientTunnelAuth{AgentID: agentID})
+
+		agentNode, err := agpl.NodeToProto(&agpl.Node{ID: 1, PreferredDERP: 1})
+		require.NoError(t, err)
+		require.NoError(t, agpl.SendCtx(ctx, agentReqs, &proto.CoordinateRequest{
+			UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: agentNode},
+		}))
+		require.Eventually(t, func() bool {
+			return coordinator.Node(agentID) != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		clientNode, err := agpl.NodeToProto(&agpl.Node{ID: 2, PreferredDERP: 1})
+		require.NoError(t, err)
+		require.NoError(t, agpl.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: clientNode},
+			AddTunnel:  &proto.CoordinateRequest_Tunnel{Id: agpl.UUIDToByteSlice(agentID)},
+		}))
+
+		resp := recvCtx(ctx, t, clientResps)
+		require.Len(t, resp.PeerUpdates, 1)
+		require.Equal(t, agentID[:], resp.PeerUpdates[0].Id)
+		require.EqualValues(t, 1, resp.PeerUpdates[0].Node.Id)
+
+		resp = recvCtx(ctx, t, agentResps)
+		require.Len(t, resp.PeerUpdates, 1)
+		require.EqualValues(t, 2, resp.PeerUpdates[0].Node.Id)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		coordinator, err := tailnet.NewCoordinator(slogtest.Make(t, nil), pubsub.NewInMemory())
+		require.NoError(t, err)
+		defer coordinator.Close()
+
+		clientReqs, clientResps := coordinator.Coordinate(ctx, uuid.New(), "client", agpl.ClientTunnelAuth{AgentID: uuid.New()})
+		require.NoError(t, agpl.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			AddTunnel: &proto.CoordinateRequest_Tunnel{Id: agpl.UUIDToByteSlice(uuid.New())},
+		}))
+		resp := recvCtx(ctx, t, clientResps)
+		require.NotEmpty(t, resp.Error)
+	})
+}
+
+func recvCtx[A any](ctx context.Context, t *testing.T, c <-chan A) A {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+		t.Fatal("timeout")
+		var a A
+		return a
+	case a := <-c:
+		return a
+	}
 }
 
 func TestCoordinatorHA(t *testing.T) {
@@ -0,0 +1,936 @@
+package tailnet
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	gProto "google.golang.org/protobuf/proto"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/database/pubsub"
+	"github.com/coder/coder/coderd/rbac"
+	agpl "github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/proto"
+)
+
+const (
+	// HeartbeatPeriod is how often coordinators update their heartbeat.
+	HeartbeatPeriod = 2 * time.Second
+	// MissedHeartbeats is how many heartbeats a coordinator can miss before
+	// the peers connected to it are considered lost.
+	MissedHeartbeats = 3
+	// cleanupPeriod is how often expired coordinators, lost peers and their
+	// tunnels are removed from the database.
+	cleanupPeriod = time.Hour
+	// lostTimeout is how long lost peers, and coordinators that stopped
+	// sending heartbeats, are kept before they are removed.
+	lostTimeout = 24 * time.Hour
+	// numWorkers is the number of goroutines that send peer updates to the
+	// local peers.
+	numWorkers = 4
+)
+
+// pgCoord is a high availability coordinator that stores the mappings of
+// peers to coordinators, and the tunnels between peers, in Postgres. When a
+// peer changes, only the coordinators of the peers that have tunnels with it
+// are notified, rather than broadcasting every update to all replicas.
+type pgCoord struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	logger      slog.Logger
+	id          uuid.UUID
+	pubsub      pubsub.Pubsub
+	store       database.Store
+	unsubscribe func()
+
+	workQ    *workQ
+	resyncCh chan struct{}
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	peers map[uuid.UUID]*pgPeer
+	// coordinators maps the IDs of the coordinators in the database to
+	// whether they missed too many heartbeats.
+	coordinators map[uuid.UUID]bool
+	closed       bool
+}
+
+// pgPeer is a peer connected to this coordinator.
+type pgPeer struct {
+	id     uuid.UUID
+	name   string
+	auth   agpl.TunnelAuth
+	logger slog.Logger
+	start  time.Time
+	resps  chan *proto.CoordinateResponse
+	// stop is closed when the peer is overwritten by a peer with the same ID,
+	// or the coordinator closes.
+	stop chan struct{}
+	// done is closed when the peer no longer writes to the database.
+	done chan struct{}
+
+	// The following fields are protected by the coordinator mutex.
+	// node is the latest protobuf encoded node of the peer.
+	node []byte
+	// tunnels contains the destinations of the tunnels from the peer.
+	tunnels map[uuid.UUID]struct{}
+	// known maps the IDs of the peers that the peer has received updates
+	// about to the node it last received. The node is nil if the peer was
+	// lost.
+	known  map[uuid.UUID][]byte
+	closed bool
+}
+
+// NewPGCoord creates a high availability coordinator that stores the peers
+// and tunnels in the database, and uses pubsub to notify the coordinators
+// of the peers that are interested in an update.
+func NewPGCoord(ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store database.Store) (agpl.Coordinator, error) {
+	//nolint:gocritic // The coordinator only accesses the tailnet tables.
+	ctx, cancel := context.WithCancel(dbauthz.As(ctx, pgCoordSubject))
+	id := uuid.New()
+	c := &pgCoord{
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       logger.Named("pgcoord").With(slog.F("coordinator_id", id)),
+		id:           id,
+		pubsub:       ps,
+		store:        store,
+		workQ:        newWorkQ(),
+		resyncCh:     make(chan struct{}, 1),
+		peers:        make(map[uuid.UUID]*pgPeer),
+		coordinators: make(map[uuid.UUID]bool),
+	}
+	// The coordinator must exist before peers and tunnels can reference it.
+	_, err := store.UpsertTailnetCoordinator(ctx, id)
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("insert coordinator: %w", err)
+	}
+	c.unsubscribe, err = ps.SubscribeWithErr(eventPeerUpdate(id), c.listenPeerUpdates)
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("subscribe to peer updates: %w", err)
+	}
+	c.wg.Add(1 + numWorkers)
+	go c.heartbeat()
+	for i := 0; i < numWorkers; i++ {
+		go c.worker()
+	}
+	return c, nil
+}
+
+// pgCoordSubject is the subject used by the coordinator to access the
+// database. It may only access the system resources, which contain the
+// tailnet coordination tables.
+var pgCoordSubject = rbac.Subject{
+	ID: uuid.Nil.String(),
+	Roles: rbac.Roles([]rbac.Role{
+		{
+			Name:        "tailnetcoordinator",
+			DisplayName: "Tailnet Coordinator",
+			Site: rbac.Permissions(map[string][]rbac.Action{
+				rbac.ResourceSystem.Type: {rbac.WildcardSymbol},
+			}),
+			Org:  map[string][]rbac.Permission{},
+			User: []rbac.Permission{},
+		},
+	}),
+	Scope: rbac.ScopeAll,
+}.WithCachedASTValue()
+
+func eventPeerUpdate(coordinatorID uuid.UUID) string {
+	return fmt.Sprintf("tailnet_peer_update:%s", coordinatorID)
+}
+
+// Coordinate connects a peer to the coordinator.
+func (c *pgCoord) Coordinate(
+	ctx context.Context, id uuid.UUID, name string, a agpl.TunnelAuth,
+) (
+	chan<- *proto.CoordinateRequest, <-chan *proto.CoordinateResponse,
+) {
+	logger := c.logger.With(slog.F("peer_id", id), slog.F("peer_name", name))
+	reqs := make(chan *proto.CoordinateRequest, agpl.RequestBufferSize)
+	resps := make(chan *proto.CoordinateResponse, agpl.ResponseBufferSize)
+	p := &pgPeer{
+		id:      id,
+		name:    name,
+		auth:    a,
+		logger:  logger,
+		start:   time.Now(),
+		resps:   resps,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		tunnels: make(map[uuid.UUID]struct{}),
+		known:   make(map[uuid.UUID][]byte),
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		close(resps)
+		return reqs, resps
+	}
+	old, ok := c.peers[id]
+	if ok {
+		logger.Debug(ctx, "overwriting peer")
+		close(old.stop)
+		old.closeLocked()
+	}
+	c.peers[id] = p
+	c.mu.Unlock()
+
+	go c.handlePeer(ctx, p, old, reqs)
+	return reqs, resps
+}
+
+func (c *pgCoord) handlePeer(ctx context.Context, p, old *pgPeer, reqs <-chan *proto.CoordinateRequest) {
+	defer close(p.done)
+	if old != nil {
+		// Wait for the overwritten peer to finish its last request, and drop
+		// its tunnels since the new peer requests its own.
+		<-old.done
+		err := c.store.DeleteAllTailnetTunnels(c.ctx, database.DeleteAllTailnetTunnelsParams{
+			CoordinatorID: c.id,
+			SrcID:         p.id,
+		})
+		if err != nil {
+			p.logger.Error(c.ctx, "delete tunnels of overwritten peer", slog.Error(err))
+		}
+	}
+
+	// Send the nodes of the peers that already have tunnels to the peer, for
+	// example the clients of an agent that reconnected.
+	rows, err := c.store.GetTailnetTunnelPeerIDs(c.ctx, p.id)
+	if err != nil {
+		p.logger.Error(c.ctx, "get tunnel peers", slog.Error(err))
+	}
+	for _, row := range rows {
+		c.workQ.enqueue(row.PeerID)
+	}
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ctx.Done():
+			c.lostPeer(p)
+			return
+		case req, ok := <-reqs:
+			if !ok {
+				c.lostPeer(p)
+				return
+			}
+			err := c.handleRequest(p, req)
+			if err != nil {
+				p.logger.Warn(c.ctx, "rejected peer request", slog.Error(err))
+				c.mu.Lock()
+				c.sendLocked(p, &proto.CoordinateResponse{Error: err.Error()})
+				c.mu.Unlock()
+				c.disconnectPeer(p)
+				return
+			}
+			if req.Disconnect != nil {
+				c.disconnectPeer(p)
+				return
+			}
+		}
+	}
+}
+
+// handleRequest handles a request of the peer. An error rejects the peer.
+func (c *pgCoord) handleRequest(p *pgPeer, req *proto.CoordinateRequest) error {
+	if req.UpdateSelf != nil {
+		node, err := gProto.Marshal(req.UpdateSelf.GetNode())
+		if err != nil {
+			return xerrors.Errorf("marshal node: %w", err)
+		}
+		_, err = c.store.UpsertTailnetPeer(c.ctx, database.UpsertTailnetPeerParams{
+			ID:            p.id,
+			CoordinatorID: c.id,
+			Node:          node,
+			Status:        database.TailnetStatusOk,
+		})
+		if err != nil {
+			return xerrors.Errorf("upsert peer: %w", err)
+		}
+		c.mu.Lock()
+		p.node = node
+		c.mu.Unlock()
+		c.notifyTunnelPeers(p.id, nil)
+	}
+	if req.AddTunnel != nil {
+		dst, err := uuid.FromBytes(req.AddTunnel.GetId())
+		if err != nil {
+			return xerrors.Errorf("invalid tunnel ID: %w", err)
+		}
+		if !p.auth.Authorize(dst) {
+			return xerrors.Errorf("unauthorized tunnel to %s", dst)
+		}
+		_, err = c.store.UpsertTailnetTunnel(c.ctx, database.UpsertTailnetTunnelParams{
+			CoordinatorID: c.id,
+			SrcID:         p.id,
+			DstID:         dst,
+		})
+		if err != nil {
+			return xerrors.Errorf("upsert tunnel: %w", err)
+		}
+		c.mu.Lock()
+		p.tunnels[dst] = struct{}{}
+		c.mu.Unlock()
+		c.tunnelChanged(p.id, dst)
+	}
+	if req.RemoveTunnel != nil {
+		dst, err := uuid.FromBytes(req.RemoveTunnel.GetId())
+		if err != nil {
+			return xerrors.Errorf("invalid tunnel ID: %w", err)
+		}
+		_, err = c.store.DeleteTailnetTunnel(c.ctx, database.DeleteTailnetTunnelParams{
+			CoordinatorID: c.id,
+			SrcID:         p.id,
+			DstID:         dst,
+		})
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return xerrors.Errorf("delete tunnel: %w", err)
+		}
+		c.mu.Lock()
+		delete(p.tunnels, dst)
+		c.mu.Unlock()
+		c.tunnelChanged(p.id, dst)
+	}
+	return nil
+}
+
+// tunnelChanged sends the updated node of dst to src, and notifies the
+// coordinators of dst about src.
+func (c *pgCoord) tunnelChanged(src, dst uuid.UUID) {
+	c.workQ.enqueue(dst)
+	mappings, err := c.store.GetTailnetPeers(c.ctx, dst)
+	if err != nil {
+		c.logger.Error(c.ctx, "get tunnel destination", slog.F("dst_id", dst), slog.Error(err))
+		return
+	}
+	coordinators := make(map[uuid.UUID]struct{})
+	for _, m := range mappings {
+		coordinators[m.CoordinatorID] = struct{}{}
+	}
+	for coordinator := range coordinators {
+		c.notify(coordinator, src)
+	}
+}
+
+// tunnelCoordinators returns the coordinators of the peers that have tunnels
+// with the given peer.
+func (c *pgCoord) tunnelCoordinators(id uuid.UUID) (map[uuid.UUID]struct{}, error) {
+	coordinators := make(map[uuid.UUID]struct{})
+	// The coordinators of tunnels to the peer are the coordinators of their
+	// sources.
+	rows, err := c.store.GetTailnetTunnelPeerIDs(c.ctx, id)
+	if err != nil {
+		return nil, xerrors.Errorf("get tunnel peers: %w", err)
+	}
+	for _, row := range rows {
+		coordinators[row.CoordinatorID] = struct{}{}
+	}
+	bindings, err := c.store.GetTailnetTunnelPeerBindings(c.ctx, id)
+	if err != nil {
+		return nil, xerrors.Errorf("get tunnel peer bindings: %w", err)
+	}
+	for _, binding := range bindings {
+		coordinators[binding.CoordinatorID] = struct{}{}
+	}
+	return coordinators, nil
+}
+
+// notifyTunnelPeers notifies the coordinators of the peers that have tunnels
+// with the given peer that it changed. If coordinators is nil, they are
+// looked up.
+func (c *pgCoord) notifyTunnelPeers(id uuid.UUID, coordinators map[uuid.UUID]struct{}) {
+	if coordinators == nil {
+		var err error
+		coordinators, err = c.tunnelCoordinators(id)
+		if err != nil {
+			c.logger.Error(c.ctx, "get tunnel coordinators", slog.F("peer_id", id), slog.Error(err))
+			return
+		}
+	}
+	for coordinator := range coordinators {
+		c.notify(coordinator, id)
+	}
+}
+
+// notify tells a coordinator that the given peer changed.
+func (c *pgCoord) notify(coordinator, id uuid.UUID) {
+	if coordinator == c.id {
+		c.workQ.enqueue(id)
+		return
+	}
+	err := c.pubsub.Publish(eventPeerUpdate(coordinator), []byte(id.String()))
+	if err != nil {
+		c.logger.Error(c.ctx, "publish peer update",
+			slog.F("peer_id", id), slog.F("to_coordinator_id", coordinator), slog.Error(err))
+	}
+}
+
+// lostPeer marks a peer that went away without disconnecting as lost, so its
+// tunnel peers keep its node in case it comes back.
+func (c *pgCoord) lostPeer(p *pgPeer) {
+	c.mu.Lock()
+	node := p.node
+	c.mu.Unlock()
+	if node == nil {
+		// There is no node to keep.
+		c.disconnectPeer(p)
+		return
+	}
+	p.logger.Debug(c.ctx, "peer lost")
+	_, err := c.store.UpsertTailnetPeer(c.ctx, database.UpsertTailnetPeerParams{
+		ID:            p.id,
+		CoordinatorID: c.id,
+		Node:          node,
+		Status:        database.TailnetStatusLost,
+	})
+	if err != nil {
+		p.logger.Error(c.ctx, "mark peer lost", slog.Error(err))
+	}
+	c.notifyTunnelPeers(p.id, nil)
+	c.removePeer(p)
+}
+
+// disconnectPeer removes a peer and its tunnels.
+func (c *pgCoord) disconnectPeer(p *pgPeer) {
+	p.logger.Debug(c.ctx, "peer disconnected")
+	// Look up the coordinators to notify before the tunnels are gone.
+	coordinators, err := c.tunnelCoordinators(p.id)
+	if err != nil {
+		p.logger.Error(c.ctx, "get tunnel coordinators", slog.Error(err))
+	}
+	err = c.store.DeleteAllTailnetTunnels(c.ctx, database.DeleteAllTailnetTunnelsParams{
+		CoordinatorID: c.id,
+		SrcID:         p.id,
+	})
+	if err != nil {
+		p.logger.Error(c.ctx, "delete tunnels", slog.Error(err))
+	}
+	_, err = c.store.DeleteTailnetPeer(c.ctx, database.DeleteTailnetPeerParams{
+		ID:            p.id,
+		CoordinatorID: c.id,
+	})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		p.logger.Error(c.ctx, "delete peer", slog.Error(err))
+	}
+	if coordinators != nil {
+		c.notifyTunnelPeers(p.id, coordinators)
+	}
+	c.removePeer(p)
+}
+
+func (c *pgCoord) removePeer(p *pgPeer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.peers[p.id] == p {
+		delete(c.peers, p.id)
+	}
+	p.closeLocked()
+}
+
+func (p *pgPeer) closeLocked() {
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.resps)
+}
+
+func (c *pgCoord) sendLocked(p *pgPeer, resp *proto.CoordinateResponse) {
+	if p.closed {
+		return
+	}
+	select {
+	case p.resps <- resp:
+	default:
+		p.logger.Error(c.ctx, "response buffer full, dropping update")
+	}
+}
+
+func (c *pgCoord) listenPeerUpdates(_ context.Context, message []byte, err error) {
+	if err != nil {
+		// Messages may have been dropped, so check all the peers the local
+		// peers are interested in.
+		c.logger.Warn(c.ctx, "peer update subscription error", slog.Error(err))
+		c.triggerResync()
+		return
+	}
+	id, err := uuid.ParseBytes(message)
+	if err != nil {
+		c.logger.Error(c.ctx, "invalid peer update", slog.F("message", string(message)), slog.Error(err))
+		return
+	}
+	c.workQ.enqueue(id)
+}
+
+func (c *pgCoord) worker() {
+	defer c.wg.Done()
+	for {
+		id, ok := c.workQ.dequeue()
+		if !ok {
+			return
+		}
+		err := c.process(id)
+		if err != nil && c.ctx.Err() == nil {
+			c.logger.Error(c.ctx, "process peer update", slog.F("peer_id", id), slog.Error(err))
+		}
+		c.workQ.done(id)
+	}
+}
+
+// process sends the current state of the given peer to the local peers that
+// have tunnels with it, and disconnects it from the local peers that no
+// longer do.
+func (c *pgCoord) process(id uuid.UUID) error {
+	mappings, err := c.store.GetTailnetPeers(c.ctx, id)
+	if err != nil {
+		return xerrors.Errorf("get peers: %w", err)
+	}
+	rows, err := c.store.GetTailnetTunnelPeerIDs(c.ctx, id)
+	if err != nil {
+		return xerrors.Errorf("get tunnel peers: %w", err)
+	}
+	tunnelPeers := make(map[uuid.UUID]struct{}, len(rows))
+	for _, row := range rows {
+		tunnelPeers[row.PeerID] = struct{}{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	best, ok := c.bestMappingLocked(mappings)
+	for _, p := range c.peers {
+		if p.id == id {
+			continue
+		}
+		if _, tunnel := tunnelPeers[p.id]; !tunnel {
+			c.updatePeerLocked(p, id, database.TailnetPeer{}, false)
+			continue
+		}
+		c.updatePeerLocked(p, id, best, ok)
+	}
+	return nil
+}
+
+// bestMappingLocked returns the mapping of a peer with the newest node,
+// preferring peers that are connected to a coordinator that is alive. A
+// mapping to a coordinator that missed too many heartbeats is lost.
+func (c *pgCoord) bestMappingLocked(mappings []database.TailnetPeer) (database.TailnetPeer, bool) {
+	var best database.TailnetPeer
+	found := false
+	for _, m := range mappings {
+		if c.coordinators[m.CoordinatorID] {
+			m.Status = database.TailnetStatusLost
+		}
+		switch {
+		case !found:
+		case m.Status == best.Status && m.UpdatedAt.After(best.UpdatedAt):
+		case m.Status == database.TailnetStatusOk && best.Status == database.TailnetStatusLost:
+		default:
+			continue
+		}
+		best = m
+		found = true
+	}
+	return best, found
+}
+
+// updatePeerLocked sends the mapping of the peer with the given ID to p if it
+// changed since the last update.
+func (c *pgCoord) updatePeerLocked(p *pgPeer, id uuid.UUID, mapping database.TailnetPeer, ok bool) {
+	known, wasKnown := p.known[id]
+	switch {
+	case !ok:
+		if !wasKnown {
+			return
+		}
+		delete(p.known, id)
+		c.sendLocked(p, &proto.CoordinateResponse{
+			PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+				Id:     agpl.UUIDToByteSlice(id),
+				Kind:   proto.CoordinateResponse_PeerUpdate_DISCONNECTED,
+				Reason: "disconnected",
+			}},
+		})
+	case mapping.Status == database.TailnetStatusLost:
+		if !wasKnown || known == nil {
+			return
+		}
+		p.known[id] = nil
+		c.sendLocked(p, &proto.CoordinateResponse{
+			PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+				Id:     agpl.UUIDToByteSlice(id),
+				Kind:   proto.CoordinateResponse_PeerUpdate_LOST,
+				Reason: "lost",
+			}},
+		})
+	default:
+		if wasKnown && bytes.Equal(known, mapping.Node) {
+			return
+		}
+		node := new(proto.Node)
+		err := gProto.Unmarshal(mapping.Node, node)
+		if err != nil {
+			p.logger.Critical(c.ctx, "failed to unmarshal node", slog.F("node_peer_id", id), slog.Error(err))
+			return
+		}
+		p.known[id] = mapping.Node
+		c.sendLocked(p, &proto.CoordinateResponse{
+			PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+				Id:   agpl.UUIDToByteSlice(id),
+				Node: node,
+				Kind: proto.CoordinateResponse_PeerUpdate_NODE,
+			}},
+		})
+	}
+}
+
+func (c *pgCoord) triggerResync() {
+	select {
+	case c.resyncCh <- struct{}{}:
+	default:
+	}
+}
+
+// resync processes all the peers that the local peers have tunnels with or
+// received updates about.
+func (c *pgCoord) resync() {
+	c.mu.Lock()
+	ids := make(map[uuid.UUID]struct{})
+	locals := make([]uuid.UUID, 0, len(c.peers))
+	for _, p := range c.peers {
+		locals = append(locals, p.id)
+		for id := range p.known {
+			ids[id] = struct{}{}
+		}
+	}
+	c.mu.Unlock()
+	for _, local := range locals {
+		rows, err := c.store.GetTailnetTunnelPeerIDs(c.ctx, local)
+		if err != nil {
+			c.logger.Error(c.ctx, "get tunnel peers", slog.F("peer_id", local), slog.Error(err))
+			continue
+		}
+		for _, row := range rows {
+			ids[row.PeerID] = struct{}{}
+		}
+	}
+	for id := range ids {
+		c.workQ.enqueue(id)
+	}
+}
+
+func (c *pgCoord) heartbeat() {
+	defer c.wg.Done()
+	heartbeat := time.NewTicker(HeartbeatPeriod)
+	defer heartbeat.Stop()
+	cleanup := time.NewTicker(cleanupPeriod)
+	defer cleanup.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-heartbeat.C:
+			_, err := c.store.UpsertTailnetCoordinator(c.ctx, c.id)
+			if err != nil && c.ctx.Err() == nil {
+				c.logger.Error(c.ctx, "send heartbeat", slog.Error(err))
+			}
+			c.checkCoordinators()
+		case <-cleanup.C:
+			c.cleanup()
+		case <-c.resyncCh:
+			c.resync()
+		}
+	}
+}
+
+// checkCoordinators updates which coordinators missed too many heartbeats,
+// and resyncs the local peers if that changed.
+func (c *pgCoord) checkCoordinators() {
+	coordinators, err := c.store.GetTailnetCoordinators(c.ctx)
+	if err != nil {
+		if c.ctx.Err() == nil {
+			c.logger.Error(c.ctx, "get coordinators", slog.Error(err))
+		}
+		return
+	}
+	expiry := time.Now().Add(-MissedHeartbeats * HeartbeatPeriod)
+	current := make(map[uuid.UUID]bool, len(coordinators))
+	for _, coordinator := range coordinators {
+		current[coordinator.ID] = coordinator.ID != c.id && coordinator.HeartbeatAt.Before(expiry)
+	}
+	c.mu.Lock()
+	changed := len(current) != len(c.coordinators)
+	for id, expired := range current {
+		if was, ok := c.coordinators[id]; !ok || was != expired {
+			changed = true
+		}
+	}
+	c.coordinators = current
+	c.mu.Unlock()
+	if changed {
+		c.logger.Debug(c.ctx, "coordinators changed, resyncing peers")
+		c.resync()
+	}
+}
+
+func (c *pgCoord) cleanup() {
+	before := time.Now().Add(-lostTimeout)
+	err := c.store.DeleteExpiredTailnetCoordinators(c.ctx, before)
+	if err != nil {
+		c.logger.Error(c.ctx, "delete expired coordinators", slog.Error(err))
+	}
+	err = c.store.CleanTailnetLostPeers(c.ctx, before)
+	if err != nil {
+		c.logger.Error(c.ctx, "clean lost peers", slog.Error(err))
+	}
+	err = c.store.CleanTailnetLostTunnels(c.ctx, before)
+	if err != nil {
+		c.logger.Error(c.ctx, "clean lost tunnels", slog.Error(err))
+	}
+}
+
+// Node returns the latest node of a peer, or nil if it doesn't have one.
+func (c *pgCoord) Node(id uuid.UUID) *agpl.Node {
+	c.mu.Lock()
+	var data []byte
+	if p, ok := c.peers[id]; ok {
+		data = p.node
+	}
+	c.mu.Unlock()
+	if data == nil {
+		mappings, err := c.store.GetTailnetPeers(c.ctx, id)
+		if err != nil {
+			c.logger.Error(c.ctx, "get peers", slog.F("peer_id", id), slog.Error(err))
+			return nil
+		}
+		c.mu.Lock()
+		best, ok := c.bestMappingLocked(mappings)
+		c.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		data = best.Node
+	}
+	pn := new(proto.Node)
+	err := gProto.Unmarshal(data, pn)
+	if err != nil {
+		c.logger.Critical(c.ctx, "failed to unmarshal node", slog.F("peer_id", id), slog.Error(err))
+		return nil
+	}
+	node, err := agpl.ProtoToNode(pn)
+	if err != nil {
+		c.logger.Critical(c.ctx, "failed to convert node", slog.F("peer_id", id), slog.Error(err))
+		return nil
+	}
+	return node
+}
+
+// ServeClient serves a client speaking version 1 of the coordination
+// protocol.
+func (c *pgCoord) ServeClient(conn net.Conn, id uuid.UUID, agent uuid.UUID) error {
+	logger := c.logger.With(slog.F("client_id", id), slog.F("agent_id", agent))
+	return agpl.ServeClientV1(c.ctx, logger, c, conn, id, agent)
+}
+
+// ServeAgent serves an agent speaking version 1 of the coordination
+// protocol.
+func (c *pgCoord) ServeAgent(conn net.Conn, id uuid.UUID, name string) error {
+	logger := c.logger.With(slog.F("agent_id", id), slog.F("name", name))
+	return agpl.ServeAgentV1(c.ctx, logger, c, conn, id, name)
+}
+
+// Close marks the peers of the coordinator as lost, so peers on other
+// coordinators keep their nodes while they reconnect, and stops the
+// coordinator.
+func (c *pgCoord) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	peers := make([]*pgPeer, 0, len(c.peers))
+	for _, p := range c.peers {
+		close(p.stop)
+		peers = append(peers, p)
+	}
+	c.mu.Unlock()
+	c.unsubscribe()
+
+	for _, p := range peers {
+		<-p.done
+	}
+	err := c.store.UpdateTailnetPeerStatusByCoordinator(c.ctx, database.UpdateTailnetPeerStatusByCoordinatorParams{
+		CoordinatorID: c.id,
+		Status:        database.TailnetStatusLost,
+	})
+	if err != nil {
+		c.logger.Error(c.ctx, "mark peers lost", slog.Error(err))
+	}
+	for _, p := range peers {
+		coordinators, err := c.tunnelCoordinators(p.id)
+		if err != nil {
+			c.logger.Error(c.ctx, "get tunnel coordinators", slog.F("peer_id", p.id), slog.Error(err))
+			continue
+		}
+		delete(coordinators, c.id)
+		c.notifyTunnelPeers(p.id, coordinators)
+	}
+
+	c.cancel()
+	c.workQ.close()
+	c.wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, p := range c.peers {
+		p.closeLocked()
+		delete(c.peers, id)
+	}
+	return nil
+}
+
+func (c *pgCoord) ServeHTTPDebug(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = fmt.Fprintln(w, "<h1>postgres wireguard coordinator debug</h1>")
+	_, _ = fmt.Fprintf(w, "<p>coordinator <code>%s</code></p>\n", c.id)
+
+	now := time.Now()
+	peers := make([]*pgPeer, 0, len(c.peers))
+	tunnels := make([]agpl.HTMLTunnel, 0)
+	for _, p := range c.peers {
+		peers = append(peers, p)
+		for dst := range p.tunnels {
+			tunnels = append(tunnels, agpl.HTMLTunnel{Src: p.id, Dst: dst})
+		}
+	}
+	slices.SortFunc(peers, func(a, b *pgPeer) bool {
+		return a.name < b.name
+	})
+	_, _ = fmt.Fprintf(w, "<h2 id=peers><a href=#peers>#</a> local peers: total %d</h2>\n", len(peers))
+	_, _ = fmt.Fprintln(w, "<ul>")
+	for _, p := range peers {
+		_, _ = fmt.Fprintf(w, "<li style=\"margin-top:4px\"><b>%s</b> (<code>%s</code>): created %v ago, node %t, known peers %d</li>\n",
+			p.name,
+			p.id.String(),
+			now.Sub(p.start).Round(time.Second),
+			p.node != nil,
+			len(p.known),
+		)
+	}
+	_, _ = fmt.Fprintln(w, "</ul>")
+
+	slices.SortFunc(tunnels, func(a, b agpl.HTMLTunnel) bool {
+		if a.Src == b.Src {
+			return a.Dst.String() < b.Dst.String()
+		}
+		return a.Src.String() < b.Src.String()
+	})
+	_, _ = fmt.Fprintf(w, "<h2 id=tunnels><a href=#tunnels>#</a> local tunnels: total %d</h2>\n", len(tunnels))
+	_, _ = fmt.Fprintln(w, "<ul>")
+	for _, t := range tunnels {
+		_, _ = fmt.Fprintf(w, "<li><code>%s</code> → <code>%s</code></li>\n", t.Src.String(), t.Dst.String())
+	}
+	_, _ = fmt.Fprintln(w, "</ul>")
+
+	expired := make([]string, 0)
+	for id, isExpired := range c.coordinators {
+		if isExpired {
+			expired = append(expired, id.String())
+		}
+	}
+	slices.Sort(expired)
+	_, _ = fmt.Fprintf(w, "<h2 id=expired><a href=#expired>#</a> expired coordinators: total %d</h2>\n", len(expired))
+	_, _ = fmt.Fprintln(w, "<ul>")
+	for _, id := range expired {
+		_, _ = fmt.Fprintf(w, "<li><code>%s</code></li>\n", id)
+	}
+	_, _ = fmt.Fprintln(w, "</ul>")
+}
+
+// workQ is a queue of the IDs of peers to process. An ID that is already
+// queued is not queued again, and an ID is never processed by two workers at
+// once, so bursts of updates to the same peer are coalesced.
+type workQ struct {
+	cond       *sync.Cond
+	pending    []uuid.UUID
+	queued     map[uuid.UUID]struct{}
+	inProgress map[uuid.UUID]struct{}
+	closed     bool
+}
+
+func newWorkQ() *workQ {
+	return &workQ{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		queued:     make(map[uuid.UUID]struct{}),
+		inProgress: make(map[uuid.UUID]struct{}),
+	}
+}
+
+func (q *workQ) enqueue(id uuid.UUID) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if _, ok := q.queued[id]; ok {
+		return
+	}
+	q.queued[id] = struct{}{}
+	q.pending = append(q.pending, id)
+	q.cond.Broadcast()
+}
+
+// dequeue blocks until there is an ID that isn't being processed, and marks
+// it in progress. It returns false once the queue is closed.
+func (q *workQ) dequeue() (uuid.UUID, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for {
+		if q.closed {
+			return uuid.Nil, false
+		}
+		for i, id := range q.pending {
+			if _, ok := q.inProgress[id]; ok {
+				continue
+			}
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			delete(q.queued, id)
+			q.inProgress[id] = struct{}{}
+			return id, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// done marks the ID as processed.
+func (q *workQ) done(id uuid.UUID) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	delete(q.inProgress, id)
+	q.cond.Broadcast()
+}
+
+func (q *workQ) close() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,309 @@
+package tailnet_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/enterprise/tailnet"
+	agpl "github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestPGCoordinator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AgentWithClient", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		store, ps := dbtestutil.NewDB(t)
+		coordinator, err := tailnet.NewPGCoord(ctx, logger, ps, store)
+		require.NoError(t, err)
+		defer coordinator.Close()
+
+		agentID := uuid.New()
+		agentReqs, agentResps := coordinator.Coordinate(ctx, agentID, "agent", agpl.AgentTunnelAuth{})
+		sendNode(ctx, t, agentReqs, 1)
+		require.Eventually(t, func() bool {
+			return coordinator.Node(agentID) != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		clientID := uuid.New()
+		clientReqs, clientResps := coordinator.Coordinate(ctx, clientID, "client", agpl.ClientTunnelAuth{AgentID: agentID})
+		addTunnel(ctx, t, clientReqs, agentID)
+		update := recvPeerUpdate(ctx, t, clientResps)
+		require.Equal(t, agentID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_NODE, update.Kind)
+		require.EqualValues(t, 1, update.Node.Id)
+
+		sendNode(ctx, t, clientReqs, 2)
+		update = recvPeerUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.EqualValues(t, 2, update.Node.Id)
+
+		// Updates of the agent reach the client.
+		sendNode(ctx, t, agentReqs, 3)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.EqualValues(t, 3, update.Node.Id)
+
+		// The agent is told when the client disconnects.
+		err = agpl.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{Disconnect: &proto.CoordinateRequest_Disconnect{}})
+		require.NoError(t, err)
+		update = recvPeerUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, update.Kind)
+		requireRespsClosed(ctx, t, clientResps)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		store, ps := dbtestutil.NewDB(t)
+		coordinator, err := tailnet.NewPGCoord(ctx, logger, ps, store)
+		require.NoError(t, err)
+		defer coordinator.Close()
+
+		clientReqs, clientResps := coordinator.Coordinate(ctx, uuid.New(), "client", agpl.ClientTunnelAuth{AgentID: uuid.New()})
+		addTunnel(ctx, t, clientReqs, uuid.New())
+		resp := recvCtx(ctx, t, clientResps)
+		require.NotEmpty(t, resp.Error)
+		requireRespsClosed(ctx, t, clientResps)
+	})
+
+	t.Run("V1", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		store, ps := dbtestutil.NewDB(t)
+		coordinator, err := tailnet.NewPGCoord(ctx, logger, ps, store)
+		require.NoError(t, err)
+		defer coordinator.Close()
+
+		agentWS, agentServerWS := net.Pipe()
+		defer agentWS.Close()
+		agentNodeChan := make(chan []*agpl.Node)
+		sendAgentNode, agentErrChan := agpl.ServeCoordinator(agentWS, func(nodes []*agpl.Node) error {
+			agentNodeChan <- nodes
+			return nil
+		})
+		agentID := uuid.New()
+		closeAgentChan := make(chan struct{})
+		go func() {
+			err := coordinator.ServeAgent(agentServerWS, agentID, "")
+			assert.NoError(t, err)
+			close(closeAgentChan)
+		}()
+		sendAgentNode(&agpl.Node{PreferredDERP: 1})
+		require.Eventually(t, func() bool {
+			return coordinator.Node(agentID) != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		clientWS, clientServerWS := net.Pipe()
+		defer clientWS.Close()
+		clientNodeChan := make(chan []*agpl.Node)
+		sendClientNode, clientErrChan := agpl.ServeCoordinator(clientWS, func(nodes []*agpl.Node) error {
+			clientNodeChan <- nodes
+			return nil
+		})
+		closeClientChan := make(chan struct{})
+		go func() {
+			err := coordinator.ServeClient(clientServerWS, uuid.New(), agentID)
+			assert.NoError(t, err)
+			close(closeClientChan)
+		}()
+		agentNodes := recvCtx(ctx, t, clientNodeChan)
+		require.Len(t, agentNodes, 1)
+		require.Equal(t, 1, agentNodes[0].PreferredDERP)
+		sendClientNode(&agpl.Node{PreferredDERP: 2})
+		clientNodes := recvCtx(ctx, t, agentNodeChan)
+		require.Len(t, clientNodes, 1)
+		require.Equal(t, 2, clientNodes[0].PreferredDERP)
+
+		require.NoError(t, clientWS.Close())
+		require.NoError(t, clientServerWS.Close())
+		<-clientErrChan
+		<-closeClientChan
+		require.NoError(t, agentWS.Close())
+		require.NoError(t, agentServerWS.Close())
+		<-agentErrChan
+		<-closeAgentChan
+	})
+}
+
+func TestPGCoordinatorDual(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AgentWithClient", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		store, ps := dbtestutil.NewDB(t)
+		coordinator1, err := tailnet.NewPGCoord(ctx, logger.Named("coord1"), ps, store)
+		require.NoError(t, err)
+		defer coordinator1.Close()
+		coordinator2, err := tailnet.NewPGCoord(ctx, logger.Named("coord2"), ps, store)
+		require.NoError(t, err)
+		defer coordinator2.Close()
+
+		agentID := uuid.New()
+		agentReqs, agentResps := coordinator1.Coordinate(ctx, agentID, "agent", agpl.AgentTunnelAuth{})
+		sendNode(ctx, t, agentReqs, 1)
+		require.Eventually(t, func() bool {
+			return coordinator2.Node(agentID) != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		clientID := uuid.New()
+		clientReqs, clientResps := coordinator2.Coordinate(ctx, clientID, "client", agpl.ClientTunnelAuth{AgentID: agentID})
+		addTunnel(ctx, t, clientReqs, agentID)
+		update := recvPeerUpdate(ctx, t, clientResps)
+		require.Equal(t, agentID[:], update.Id)
+		require.EqualValues(t, 1, update.Node.Id)
+
+		sendNode(ctx, t, clientReqs, 2)
+		update = recvPeerUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.EqualValues(t, 2, update.Node.Id)
+
+		sendNode(ctx, t, agentReqs, 3)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.EqualValues(t, 3, update.Node.Id)
+
+		// The agent is told when the client removes its tunnel.
+		err = agpl.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			RemoveTunnel: &proto.CoordinateRequest_Tunnel{Id: agpl.UUIDToByteSlice(agentID)},
+		})
+		require.NoError(t, err)
+		update = recvPeerUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, update.Kind)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.Equal(t, agentID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, update.Kind)
+	})
+
+	t.Run("AgentReconnects", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		store, ps := dbtestutil.NewDB(t)
+		coordinator1, err := tailnet.NewPGCoord(ctx, logger.Named("coord1"), ps, store)
+		require.NoError(t, err)
+		defer coordinator1.Close()
+		coordinator2, err := tailnet.NewPGCoord(ctx, logger.Named("coord2"), ps, store)
+		require.NoError(t, err)
+		defer coordinator2.Close()
+
+		agentID := uuid.New()
+		clientID := uuid.New()
+		clientReqs, clientResps := coordinator2.Coordinate(ctx, clientID, "client", agpl.ClientTunnelAuth{AgentID: agentID})
+		addTunnel(ctx, t, clientReqs, agentID)
+		sendNode(ctx, t, clientReqs, 2)
+
+		// The agent connects after the client, and still gets its node.
+		agentCtx, agentCancel := context.WithCancel(ctx)
+		agentReqs, agentResps := coordinator1.Coordinate(agentCtx, agentID, "agent", agpl.AgentTunnelAuth{})
+		update := recvPeerUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.EqualValues(t, 2, update.Node.Id)
+		sendNode(ctx, t, agentReqs, 1)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.EqualValues(t, 1, update.Node.Id)
+
+		// The agent going away without disconnecting makes it lost.
+		agentCancel()
+		requireRespsClosed(ctx, t, agentResps)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.Equal(t, agentID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_LOST, update.Kind)
+
+		// It comes back on the other coordinator.
+		agentReqs, agentResps = coordinator2.Coordinate(ctx, agentID, "agent", agpl.AgentTunnelAuth{})
+		update = recvPeerUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		sendNode(ctx, t, agentReqs, 4)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_NODE, update.Kind)
+		require.EqualValues(t, 4, update.Node.Id)
+	})
+
+	t.Run("CoordinatorClosed", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		store, ps := dbtestutil.NewDB(t)
+		coordinator1, err := tailnet.NewPGCoord(ctx, logger.Named("coord1"), ps, store)
+		require.NoError(t, err)
+		defer coordinator1.Close()
+		coordinator2, err := tailnet.NewPGCoord(ctx, logger.Named("coord2"), ps, store)
+		require.NoError(t, err)
+		defer coordinator2.Close()
+
+		agentID := uuid.New()
+		agentReqs, agentResps := coordinator1.Coordinate(ctx, agentID, "agent", agpl.AgentTunnelAuth{})
+		sendNode(ctx, t, agentReqs, 1)
+		require.Eventually(t, func() bool {
+			return coordinator2.Node(agentID) != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		clientReqs, clientResps := coordinator2.Coordinate(ctx, uuid.New(), "client", agpl.ClientTunnelAuth{AgentID: agentID})
+		addTunnel(ctx, t, clientReqs, agentID)
+		update := recvPeerUpdate(ctx, t, clientResps)
+		require.EqualValues(t, 1, update.Node.Id)
+
+		// Closing the coordinator of the agent marks it lost, rather than
+		// disconnected, so the client keeps its node while it reconnects.
+		require.NoError(t, coordinator1.Close())
+		requireRespsClosed(ctx, t, agentResps)
+		update = recvPeerUpdate(ctx, t, clientResps)
+		require.Equal(t, agentID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_LOST, update.Kind)
+	})
+}
+
+func sendNode(ctx context.Context, t *testing.T, reqs chan<- *proto.CoordinateRequest, id int64) {
+	t.Helper()
+	node, err := agpl.NodeToProto(&agpl.Node{ID: tailcfg.NodeID(id), PreferredDERP: 1})
+	require.NoError(t, err)
+	err = agpl.SendCtx(ctx, reqs, &proto.CoordinateRequest{
+		UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: node},
+	})
+	require.NoError(t, err)
+}
+
+func addTunnel(ctx context.Context, t *testing.T, reqs chan<- *proto.CoordinateRequest, dst uuid.UUID) {
+	t.Helper()
+	err := agpl.SendCtx(ctx, reqs, &proto.CoordinateRequest{
+		AddTunnel: &proto.CoordinateRequest_Tunnel{Id: agpl.UUIDToByteSlice(dst)},
+	})
+	require.NoError(t, err)
+}
+
+func recvPeerUpdate(ctx context.Context, t *testing.T, resps <-chan *proto.CoordinateResponse) *proto.CoordinateResponse_PeerUpdate {
+	t.Helper()
+	resp := recvCtx(ctx, t, resps)
+	require.Empty(t, resp.Error)
+	require.Len(t, resp.PeerUpdates, 1)
+	return resp.PeerUpdates[0]
+}
+
+func requireRespsClosed(ctx context.Context, t *testing.T, resps <-chan *proto.CoordinateResponse) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for responses to close")
+	case _, ok := <-resps:
+		require.False(t, ok, "responses should be closed")
+	}
+}
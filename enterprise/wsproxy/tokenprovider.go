@@ -18,13 +18,13 @@ type TokenProvider struct {
 	AccessURL    *url.URL
 	AppHostname  string
 
-	Client      *wsproxysdk.Client
-	SecurityKey workspaceapps.SecurityKey
-	Logger      slog.Logger
+	Client       *wsproxysdk.Client
+	SecurityKeys *workspaceapps.SecurityKeySet
+	Logger       slog.Logger
 }
 
 func (p *TokenProvider) FromRequest(r *http.Request) (*workspaceapps.SignedToken, bool) {
-	return workspaceapps.FromRequest(r, p.SecurityKey)
+	return workspaceapps.FromRequest(r, p.SecurityKeys)
 }
 
 func (p *TokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *http.Request, issueReq workspaceapps.IssueTokenRequest) (*workspaceapps.SignedToken, string, bool) {
@@ -42,7 +42,7 @@ func (p *TokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *ht
 	}
 
 	// Check that it verifies properly and matches the string.
-	token, err := p.SecurityKey.VerifySignedToken(resp.SignedTokenStr)
+	token, err := p.SecurityKeys.VerifySignedToken(resp.SignedTokenStr)
 	if err != nil {
 		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "failed to verify newly generated signed token")
 		return nil, "", false
@@ -58,12 +58,21 @@ type Options struct {
 	APIRateLimit     int
 	SecureAuthCookie bool
 	DisablePathApps  bool
+	// AppAccessLogSampleRate is the fraction of requests proxied to workspace
+	// apps that are logged, between 0 and 1.
+	AppAccessLogSampleRate float64
 
 	ProxySessionToken string
 	// AllowAllCors will set all CORs headers to '*'.
 	// By default, CORs is set to accept external requests
 	// from the dashboardURL. This should only be used in development.
 	AllowAllCors bool
+	// RegisterInterval is how often the proxy registers with the primary to
+	// pick up rotated app security keys, default 1 minute.
+	RegisterInterval time.Duration
+	// AppUsageFlushInterval is how often the usage of workspace apps is
+	// reported to the primary, default 1 minute.
+	AppUsageFlushInterval time.Duration
 }
 
 func (o *Options) Validate() error {
@@ -104,6 +113,11 @@ type Server struct {
 	// TODO: Missing:
 	//		- derpserver
 
+	// appSecurityKeys are updated on every registration with the primary.
+	appSecurityKeys  *workspaceapps.SecurityKeySet
+	registerLoopDone chan struct{}
+	appUsage         *workspaceapps.AppUsageCollector
+
 	// Used for graceful shutdown. Required for the dialer.
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -116,6 +130,9 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 	if opts.PrometheusRegistry == nil {
 		opts.PrometheusRegistry = prometheus.NewRegistry()
 	}
+	if opts.RegisterInterval == 0 {
+		opts.RegisterInterval = time.Minute
+	}
 
 	if err := opts.Validate(); err != nil {
 		return nil, err
@@ -141,19 +158,29 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		return nil, xerrors.Errorf("%q is a workspace proxy, not a primary coderd instance", opts.DashboardURL)
 	}
 
-	regResp, err := client.RegisterWorkspaceProxy(ctx, wsproxysdk.RegisterWorkspaceProxyRequest{
+	regReq := wsproxysdk.RegisterWorkspaceProxyRequest{
 		AccessURL:        opts.AccessURL.String(),
 		WildcardHostname: opts.AppHostname,
-	})
+	}
+	regResp, err := client.RegisterWorkspaceProxy(ctx, regReq)
 	if err != nil {
 		return nil, xerrors.Errorf("register proxy: %w", err)
 	}
 
-	secKey, err := workspaceapps.KeyFromString(regResp.AppSecurityKey)
+	secKeys := &workspaceapps.SecurityKeySet{}
+	err = updateAppSecurityKeys(secKeys, regResp)
 	if err != nil {
-		return nil, xerrors.Errorf("parse app security key: %w", err)
+		return nil, err
 	}
 
+	appUsage := workspaceapps.NewAppUsageCollector(
+		opts.Logger.Named("app_usage_collector"),
+		func(ctx context.Context, usage []workspaceapps.AppUsage) error {
+			return client.ReportAppUsage(ctx, wsproxysdk.ReportAppUsageRequest{Usage: usage})
+		},
+		opts.AppUsageFlushInterval,
+	)
+
 	r := chi.NewRouter()
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &Server{
@@ -164,8 +191,11 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		TracerProvider:     opts.Tracing,
 		PrometheusRegistry: opts.PrometheusRegistry,
 		SDKClient:          client,
+		appSecurityKeys:    secKeys,
+		appUsage:           appUsage,
 		ctx:                ctx,
 		cancel:             cancel,
+		registerLoopDone:   make(chan struct{}),
 	}
 
 	s.AppServer = &workspaceapps.Server{
@@ -180,14 +210,17 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 			AccessURL:    opts.AccessURL,
 			AppHostname:  opts.AppHostname,
 			Client:       client,
-			SecurityKey:  secKey,
+			SecurityKeys: secKeys,
 			Logger:       s.Logger.Named("proxy_token_provider"),
 		},
 		WorkspaceConnCache: wsconncache.New(s.DialWorkspaceAgent, 0),
-		AppSecurityKey:     secKey,
+		AppSecurityKeys:    secKeys,
 
 		DisablePathApps:  opts.DisablePathApps,
 		SecureAuthCookie: opts.SecureAuthCookie,
+
+		AppUsage:            s.appUsage,
+		AccessLogSampleRate: opts.AppAccessLogSampleRate,
 	}
 
 	// The primary coderd dashboard needs to make some GET requests to
@@ -263,11 +296,62 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 	rootRouter.Mount("/", r)
 	s.Handler = rootRouter
 
+	go s.registerLoop(regReq)
+
 	return s, nil
 }
 
+// registerLoop registers the proxy with the primary periodically to pick up
+// rotated app security keys.
+func (s *Server) registerLoop(req wsproxysdk.RegisterWorkspaceProxyRequest) {
+	defer close(s.registerLoopDone)
+
+	t := time.NewTicker(s.Options.RegisterInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		resp, err := s.SDKClient.RegisterWorkspaceProxy(s.ctx, req)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.Logger.Warn(s.ctx, "register workspace proxy", slog.Error(err))
+			continue
+		}
+		err = updateAppSecurityKeys(s.appSecurityKeys, resp)
+		if err != nil {
+			s.Logger.Error(s.ctx, "update app security keys", slog.Error(err))
+		}
+	}
+}
+
+// updateAppSecurityKeys replaces the keys in the set with the app security
+// keys returned by the primary on registration.
+func updateAppSecurityKeys(keys *workspaceapps.SecurityKeySet, resp wsproxysdk.RegisterWorkspaceProxyResponse) error {
+	decoded := make(map[string]workspaceapps.SecurityKey, len(resp.AppSecurityKeys))
+	for _, key := range resp.AppSecurityKeys {
+		secKey, err := workspaceapps.KeyFromString(key.Key)
+		if err != nil {
+			return xerrors.Errorf("parse app security key %q: %w", key.ID, err)
+		}
+		decoded[key.ID] = secKey
+	}
+	err := keys.Update(resp.ActiveAppSecurityKeyID, decoded)
+	if err != nil {
+		return xerrors.Errorf("update app security keys: %w", err)
+	}
+	return nil
+}
+
 func (s *Server) Close() error {
 	s.cancel()
+	<-s.registerLoopDone
+	_ = s.appUsage.Close()
 
 	// A timeout to prevent the SDK from blocking the server shutdown.
 	tmp, cancel := context.WithTimeout(context.Background(), 3*time.Second)
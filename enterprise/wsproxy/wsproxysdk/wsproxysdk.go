@@ -151,7 +151,18 @@ type RegisterWorkspaceProxyRequest struct {
 }
 
 type RegisterWorkspaceProxyResponse struct {
-	AppSecurityKey string `json:"app_security_key"`
+	// AppSecurityKeys are the keys the workspace proxy must accept for signed
+	// app tokens and encrypted API keys. Keys are rotated by the primary, so
+	// workspace proxies register periodically to stay up to date.
+	AppSecurityKeys []AppSecurityKey `json:"app_security_keys"`
+	// ActiveAppSecurityKeyID is the ID of the key new tokens are signed with.
+	ActiveAppSecurityKeyID string `json:"active_app_security_key_id"`
+}
+
+type AppSecurityKey struct {
+	ID string `json:"id"`
+	// Key is the hex encoded key.
+	Key string `json:"key"`
 }
 
 func (c *Client) RegisterWorkspaceProxy(ctx context.Context, req RegisterWorkspaceProxyRequest) (RegisterWorkspaceProxyResponse, error) {
@@ -171,6 +182,28 @@ func (c *Client) RegisterWorkspaceProxy(ctx context.Context, req RegisterWorkspa
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+type ReportAppUsageRequest struct {
+	Usage []workspaceapps.AppUsage `json:"usage"`
+}
+
+// ReportAppUsage stores the usage of workspace apps proxied by the workspace
+// proxy.
+func (c *Client) ReportAppUsage(ctx context.Context, req ReportAppUsageRequest) error {
+	res, err := c.Request(ctx, http.MethodPost,
+		"/api/v2/workspaceproxies/me/app-usage",
+		req,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
 func (c *Client) WorkspaceProxyGoingAway(ctx context.Context) error {
 	res, err := c.Request(ctx, http.MethodPost,
 		"/api/v2/workspaceproxies/me/goingaway",
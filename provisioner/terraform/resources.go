@@ -62,6 +62,7 @@ type agentAppAttributes struct {
 	External    bool                       `mapstructure:"external"`
 	Command     string                     `mapstructure:"command"`
 	Share       string                     `mapstructure:"share"`
+	ShareGroups []string                   `mapstructure:"share_groups"`
 	Subdomain   bool                       `mapstructure:"subdomain"`
 	Healthcheck []appHealthcheckAttributes `mapstructure:"healthcheck"`
 }
@@ -355,6 +356,8 @@ func ConvertState(modules []*tfjson.StateModule, rawGraph string, rawParameterNa
 				sharingLevel = proto.AppSharingLevel_AUTHENTICATED
 			case "public":
 				sharingLevel = proto.AppSharingLevel_PUBLIC
+			case "group":
+				sharingLevel = proto.AppSharingLevel_GROUP
 			}
 
 			for _, agents := range resourceAgents {
@@ -364,15 +367,16 @@ func ConvertState(modules []*tfjson.StateModule, rawGraph string, rawParameterNa
 						continue
 					}
 					agent.Apps = append(agent.Apps, &proto.App{
-						Slug:         attrs.Slug,
-						DisplayName:  attrs.DisplayName,
-						Command:      attrs.Command,
-						External:     attrs.External,
-						Url:          attrs.URL,
-						Icon:         attrs.Icon,
-						Subdomain:    attrs.Subdomain,
-						SharingLevel: sharingLevel,
-						Healthcheck:  healthcheck,
+						Slug:          attrs.Slug,
+						DisplayName:   attrs.DisplayName,
+						Command:       attrs.Command,
+						External:      attrs.External,
+						Url:           attrs.URL,
+						Icon:          attrs.Icon,
+						Subdomain:     attrs.Subdomain,
+						SharingLevel:  sharingLevel,
+						SharingGroups: attrs.ShareGroups,
+						Healthcheck:   healthcheck,
 					})
 				}
 			}
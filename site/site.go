@@ -42,6 +42,16 @@ var (
 	errorHTML string
 
 	errorTemplate *htmltemplate.Template
+
+	//go:embed static/oauth2allow.html
+	oauthHTML string
+
+	oauthTemplate *htmltemplate.Template
+
+	//go:embed static/oauth2device.html
+	oauthDeviceHTML string
+
+	oauthDeviceTemplate *htmltemplate.Template
 )
 
 func init() {
@@ -50,6 +60,16 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	oauthTemplate, err = htmltemplate.New("oauth").Parse(oauthHTML)
+	if err != nil {
+		panic(err)
+	}
+
+	oauthDeviceTemplate, err = htmltemplate.New("oauthdevice").Parse(oauthDeviceHTML)
+	if err != nil {
+		panic(err)
+	}
 }
 
 // Handler returns an HTTP handler for serving the static site.
@@ -636,6 +656,66 @@ func RenderStaticErrorPage(rw http.ResponseWriter, r *http.Request, data ErrorPa
 	}
 }
 
+// RenderOAuthAllowData contains the variables that are found in
+// site/static/oauth2allow.html.
+type RenderOAuthAllowData struct {
+	AppIcon  string
+	AppName  string
+	Username string
+	// Scopes describe what the app will be able to do.
+	Scopes []string
+	// CancelURI is where the user is sent if they deny access.
+	CancelURI string
+	// CSRFToken is submitted with the form and tied to the user's session.
+	CSRFToken string
+}
+
+// RenderOAuthAllowPage renders the static page for a user to "Allow" a third
+// party application to access their account. It is rendered by the server so
+// it does not depend on the dashboard being built.
+func RenderOAuthAllowPage(rw http.ResponseWriter, r *http.Request, data RenderOAuthAllowData) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	err := oauthTemplate.Execute(rw, data)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to render oauth page: " + err.Error(),
+		})
+		return
+	}
+}
+
+// RenderOAuthDeviceData contains the variables that are found in
+// site/static/oauth2device.html.
+type RenderOAuthDeviceData struct {
+	Username string
+	// UserCode is set once the user entered a valid code. The page then asks
+	// the user to approve or deny the request.
+	UserCode string
+	// Scopes describe what the device will be able to do.
+	Scopes []string
+	// Error is shown below the code input.
+	Error string
+	// Done replaces the form once the request was approved or denied.
+	Done string
+	// CSRFToken is submitted with the form and tied to the user's session.
+	CSRFToken string
+}
+
+// RenderOAuthDevicePage renders the static page for a user to approve a
+// device authorization request.
+func RenderOAuthDevicePage(rw http.ResponseWriter, r *http.Request, data RenderOAuthDeviceData) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	err := oauthDeviceTemplate.Execute(rw, data)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to render oauth device page: " + err.Error(),
+		})
+		return
+	}
+}
+
 type binHashCache struct {
 	binFS http.FileSystem
 
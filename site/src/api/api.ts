@@ -90,10 +90,14 @@ export const provisioners: TypesGen.ProvisionerDaemon[] = [
 export const login = async (
   email: string,
   password: string,
+  totpCode?: string,
+  recoveryCode?: string,
 ): Promise<TypesGen.LoginWithPasswordResponse> => {
   const payload = JSON.stringify({
     email,
     password,
+    totp_code: totpCode,
+    recovery_code: recoveryCode,
   })
 
   const response = await axios.post<TypesGen.LoginWithPasswordResponse>(
@@ -813,6 +817,15 @@ export const getTemplateDAUs = async (
   return response.data
 }
 
+export const getTemplateAppInsights = async (
+  templateId: string,
+): Promise<TypesGen.AppInsightsResponse> => {
+  const response = await axios.get(
+    `/api/v2/templates/${templateId}/insights/apps`,
+  )
+  return response.data
+}
+
 export const getDeploymentDAUs = async (
   // Default to user's local timezone
   offset = new Date().getTimezoneOffset() / 60,
@@ -36,6 +36,33 @@ export interface AppHostResponse {
   readonly host: string
 }
 
+// From codersdk/insights.go
+export interface AppInsight {
+  readonly template_id: string
+  readonly slug: string
+  readonly display_name: string
+  readonly icon: string
+  readonly unique_users: number
+  readonly requests: number
+  readonly error_requests: number
+  readonly average_latency_ms: number
+  readonly bytes_sent: number
+}
+
+// From codersdk/insights.go
+export interface AppInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+}
+
+// From codersdk/insights.go
+export interface AppInsightsResponse {
+  readonly start_time: string
+  readonly end_time: string
+  readonly apps: AppInsight[]
+}
+
 // From codersdk/deployment.go
 export interface AppearanceConfig {
   readonly logo_url: string
@@ -43,6 +70,17 @@ export interface AppearanceConfig {
   readonly support_links?: LinkConfig[]
 }
 
+// From codersdk/templates.go
+export interface ArchiveTemplateVersionsRequest {
+  readonly template_version_ids?: string[]
+}
+
+// From codersdk/templates.go
+export interface ArchiveTemplateVersionsResponse {
+  readonly template_id: string
+  readonly archived_ids: string[]
+}
+
 // From codersdk/roles.go
 export interface AssignableRoles extends Role {
   readonly assignable: boolean
@@ -107,6 +145,7 @@ export interface AuthMethods {
   readonly password: AuthMethod
   readonly github: AuthMethod
   readonly oidc: OIDCAuthMethod
+  readonly oidc_providers: OIDCProviderAuthMethod[]
 }
 
 // From codersdk/authorization.go
@@ -139,6 +178,13 @@ export interface BuildInfoResponse {
   readonly workspace_proxy: boolean
 }
 
+// From codersdk/derpregions.go
+export interface CreateDERPRegionPolicyRequest {
+  readonly group_id?: string
+  readonly template_id?: string
+  readonly region_ids: number[]
+}
+
 // From codersdk/users.go
 export interface CreateFirstUserRequest {
   readonly email: string
@@ -160,6 +206,15 @@ export interface CreateGroupRequest {
   readonly quota_allowance: number
 }
 
+// From codersdk/derpregions.go
+export interface CreateManagedDERPRegionRequest {
+  readonly region_id: number
+  readonly region_code: string
+  readonly region_name: string
+  readonly nodes: ManagedDERPNode[]
+  readonly avoid: boolean
+}
+
 // From codersdk/users.go
 export interface CreateOrganizationRequest {
   readonly name: string
@@ -201,6 +256,15 @@ export interface CreateTemplateVersionRequest {
   readonly user_variable_values?: VariableValue[]
 }
 
+// From codersdk/templateversionrollouts.go
+export interface CreateTemplateVersionRolloutRequest {
+  readonly template_version_id: string
+  readonly percentage: number
+  readonly group_ids?: string[]
+  readonly batch_size: number
+  readonly failure_threshold_percent: number
+}
+
 // From codersdk/audit.go
 export interface CreateTestAuditLogRequest {
   readonly action?: AuditAction
@@ -226,6 +290,7 @@ export interface CreateUserRequest {
   readonly password: string
   readonly disable_login: boolean
   readonly organization_id: string
+  readonly service_account?: boolean
 }
 
 // From codersdk/workspaces.go
@@ -253,6 +318,7 @@ export interface CreateWorkspaceRequest {
   readonly autostart_schedule?: string
   readonly ttl_ms?: number
   readonly rich_parameter_values?: WorkspaceBuildParameter[]
+  readonly template_version_preset_id?: string
 }
 
 // From codersdk/deployment.go
@@ -290,6 +356,16 @@ export interface DERPRegion {
   readonly latency_ms: number
 }
 
+// From codersdk/derpregions.go
+export interface DERPRegionPolicy {
+  readonly id: string
+  readonly group_id?: string
+  readonly template_id?: string
+  readonly region_ids: number[]
+  readonly created_at: string
+  readonly updated_at: string
+}
+
 // From codersdk/deployment.go
 export interface DERPServerConfig {
   readonly enable: boolean
@@ -308,6 +384,12 @@ export interface DangerousConfig {
   readonly allow_all_cors: boolean
 }
 
+// From codersdk/workspaceportshares.go
+export interface DeleteWorkspacePortShareRequest {
+  readonly agent_name?: string
+  readonly port: number
+}
+
 // From codersdk/deployment.go
 export interface DeploymentStats {
   readonly aggregated_from: string
@@ -363,6 +445,8 @@ export interface DeploymentValues {
   readonly max_session_expiry?: number
   readonly disable_session_expiry_refresh?: boolean
   readonly disable_password_auth?: boolean
+  readonly require_owner_mfa?: boolean
+  readonly login_lockout?: LoginLockoutConfig
   readonly support?: SupportConfig
   // Named type "github.com/coder/coder/cli/clibase.Struct[[]github.com/coder/coder/codersdk.GitAuthConfig]" unknown, using "any"
   // eslint-disable-next-line @typescript-eslint/no-explicit-any -- External type
@@ -370,6 +454,7 @@ export interface DeploymentValues {
   readonly config_ssh?: SSHConfig
   readonly wgtunnel_host?: string
   readonly disable_owner_workspace_exec?: boolean
+  readonly disable_impersonation?: boolean
   readonly proxy_health_status_interval?: number
   // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.YAMLConfigPath")
   readonly config?: string
@@ -449,6 +534,12 @@ export interface Healthcheck {
   readonly threshold: number
 }
 
+// From codersdk/users.go
+export interface ImpersonateUserResponse {
+  readonly key: string
+  readonly expires_at: string
+}
+
 // From codersdk/workspaceagents.go
 export interface IssueReconnectingPTYSignedTokenRequest {
   readonly url: string
@@ -482,17 +573,63 @@ export interface LoggingConfig {
   readonly human: string
   readonly json: string
   readonly stackdriver: string
+  readonly app_access_sample_percent: number
+}
+
+// From codersdk/deployment.go
+export interface LoginLockoutConfig {
+  readonly threshold: number
+  readonly ip_threshold: number
+  readonly duration: number
 }
 
 // From codersdk/users.go
 export interface LoginWithPasswordRequest {
   readonly email: string
   readonly password: string
+  readonly totp_code?: string
+  readonly recovery_code?: string
 }
 
 // From codersdk/users.go
 export interface LoginWithPasswordResponse {
   readonly session_token: string
+  readonly mfa_required?: boolean
+  readonly mfa_enrollment_required?: boolean
+  readonly recovery_codes?: string[]
+}
+
+// From codersdk/derpregions.go
+export interface ManagedDERPNode {
+  readonly name: string
+  readonly host_name: string
+  readonly ipv4?: string
+  readonly ipv6?: string
+  readonly stun_port?: number
+  readonly stun_only?: boolean
+  readonly derp_port?: number
+  readonly force_http?: boolean
+}
+
+// From codersdk/derpregions.go
+export interface ManagedDERPRegion {
+  readonly region_id: number
+  readonly region_code: string
+  readonly region_name: string
+  readonly nodes: ManagedDERPNode[]
+  readonly avoid: boolean
+  readonly healthy: boolean
+  readonly health_error?: string
+  readonly last_health_check_at?: string
+  readonly created_at: string
+  readonly updated_at: string
+}
+
+// From codersdk/oauth2.go
+export interface OAuth2AppEndpoints {
+  readonly authorization: string
+  readonly token: string
+  readonly revocation: string
 }
 
 // From codersdk/deployment.go
@@ -500,6 +637,29 @@ export interface OAuth2Config {
   readonly github: OAuth2GithubConfig
 }
 
+// From codersdk/oauth2.go
+export interface OAuth2DeviceAuthorization {
+  readonly device_code: string
+  readonly user_code: string
+  readonly verification_uri: string
+  readonly verification_uri_complete: string
+  readonly expires_in: number
+  readonly interval: number
+}
+
+// From codersdk/oauth2.go
+export interface OAuth2DeviceToken {
+  readonly access_token: string
+  readonly token_type: string
+  readonly expires_in: number
+}
+
+// From codersdk/oauth2.go
+export interface OAuth2Error {
+  readonly error: string
+  readonly error_description?: string
+}
+
 // From codersdk/deployment.go
 export interface OAuth2GithubConfig {
   readonly client_id: string
@@ -513,6 +673,33 @@ export interface OAuth2GithubConfig {
   readonly enterprise_base_url: string
 }
 
+// From codersdk/oauth2.go
+export interface OAuth2ProviderApp {
+  readonly id: string
+  readonly name: string
+  readonly callback_url: string
+  readonly icon: string
+  readonly endpoints: OAuth2AppEndpoints
+}
+
+// From codersdk/oauth2.go
+export interface OAuth2ProviderAppFilter {
+  readonly user_id?: string
+}
+
+// From codersdk/oauth2.go
+export interface OAuth2ProviderAppSecret {
+  readonly id: string
+  readonly last_used_at?: string
+  readonly client_secret_truncated: string
+}
+
+// From codersdk/oauth2.go
+export interface OAuth2ProviderAppSecretFull {
+  readonly id: string
+  readonly client_secret_full: string
+}
+
 // From codersdk/users.go
 export interface OIDCAuthMethod extends AuthMethod {
   readonly signInText: string
@@ -540,6 +727,44 @@ export interface OIDCConfig {
   // Named type "github.com/coder/coder/cli/clibase.Struct[map[string]string]" unknown, using "any"
   // eslint-disable-next-line @typescript-eslint/no-explicit-any -- External type
   readonly group_mapping: any
+  readonly user_role_field: string
+  // Named type "github.com/coder/coder/cli/clibase.Struct[map[string][]string]" unknown, using "any"
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any -- External type
+  readonly user_role_mapping: any
+  // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.StringArray")
+  readonly user_roles_default: string[]
+  readonly sign_in_text: string
+  readonly icon_url: string
+  // Named type "github.com/coder/coder/cli/clibase.Struct[[]github.com/coder/coder/codersdk.OIDCProviderConfig]" unknown, using "any"
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any -- External type
+  readonly providers: any
+}
+
+// From codersdk/users.go
+export interface OIDCProviderAuthMethod {
+  readonly id: string
+  readonly signInText: string
+  readonly iconUrl: string
+}
+
+// From codersdk/deployment.go
+export interface OIDCProviderConfig {
+  readonly id: string
+  readonly issuer_url: string
+  readonly client_id: string
+  readonly scopes: string[]
+  readonly allow_signups: boolean
+  readonly email_domain: string[]
+  readonly ignore_email_verified: boolean
+  readonly username_field: string
+  readonly email_field: string
+  readonly auth_url_params: Record<string, string>
+  readonly ignore_user_info: boolean
+  readonly groups_field: string
+  readonly group_mapping: Record<string, string>
+  readonly user_role_field: string
+  readonly user_role_mapping: Record<string, string[]>
+  readonly user_roles_default: string[]
   readonly sign_in_text: string
   readonly icon_url: string
 }
@@ -591,6 +816,13 @@ export interface PatchWorkspaceProxy {
   readonly regenerate_token: boolean
 }
 
+// From codersdk/oauth2.go
+export interface PostOAuth2ProviderAppRequest {
+  readonly name: string
+  readonly callback_url: string
+  readonly icon: string
+}
+
 // From codersdk/deployment.go
 export interface PprofConfig {
   readonly enable: boolean
@@ -663,6 +895,13 @@ export interface PutExtendWorkspaceRequest {
   readonly deadline: string
 }
 
+// From codersdk/oauth2.go
+export interface PutOAuth2ProviderAppRequest {
+  readonly name: string
+  readonly callback_url: string
+  readonly icon: string
+}
+
 // From codersdk/deployment.go
 export interface RateLimitConfig {
   readonly disable_all: boolean
@@ -696,6 +935,12 @@ export interface Replica {
   readonly database_latency: number
 }
 
+// From codersdk/usertotp.go
+export interface ResetTOTPRequest {
+  readonly code?: string
+  readonly password?: string
+}
+
 // From codersdk/client.go
 export interface Response {
   readonly message: string
@@ -737,6 +982,20 @@ export interface ServiceBannerConfig {
   readonly background_color?: string
 }
 
+// From codersdk/apikey.go
+export interface Session {
+  readonly id: string
+  readonly user_id: string
+  readonly login_type: LoginType
+  readonly scope: APIKeyScope
+  readonly ip_address: string
+  readonly user_agent: string
+  readonly created_at: string
+  readonly last_used: string
+  readonly expires_at: string
+  readonly current: boolean
+}
+
 // From codersdk/deployment.go
 export interface SessionCountDeploymentStats {
   readonly vscode: number
@@ -757,6 +1016,11 @@ export interface SwaggerConfig {
   readonly enable: boolean
 }
 
+// From codersdk/templategitsources.go
+export interface SyncTemplateGitSourceRequest {
+  readonly force?: boolean
+}
+
 // From codersdk/deployment.go
 export interface TLSConfig {
   readonly enable: boolean
@@ -775,6 +1039,17 @@ export interface TLSConfig {
   readonly client_key_file: string
 }
 
+// From codersdk/usertotp.go
+export interface TOTPEnrollment {
+  readonly secret: string
+  readonly url: string
+}
+
+// From codersdk/usertotp.go
+export interface TOTPRecoveryCodes {
+  readonly recovery_codes: string[]
+}
+
 // From codersdk/deployment.go
 export interface TelemetryConfig {
   readonly enable: boolean
@@ -805,6 +1080,8 @@ export interface Template {
   readonly allow_user_cancel_workspace_jobs: boolean
   readonly failure_ttl_ms: number
   readonly inactivity_ttl_ms: number
+  readonly require_active_version: boolean
+  readonly max_port_share_level: WorkspacePortShareLevel
 }
 
 // From codersdk/templates.go
@@ -830,6 +1107,23 @@ export interface TemplateExample {
   readonly markdown: string
 }
 
+// From codersdk/templategitsources.go
+export interface TemplateGitSource {
+  readonly template_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly created_by: string
+  readonly repository_url: string
+  readonly ref: string
+  readonly subdirectory: string
+  readonly git_auth_provider_id: string
+  readonly poll_interval_ms: number
+  readonly has_webhook_secret: boolean
+  readonly last_commit_sha: string
+  readonly last_synced_at?: string
+  readonly last_error: string
+}
+
 // From codersdk/templates.go
 export interface TemplateGroup extends Group {
   readonly role: TemplateRole
@@ -851,9 +1145,35 @@ export interface TemplateVersion {
   readonly job: ProvisionerJob
   readonly readme: string
   readonly created_by: User
+  readonly state: TemplateVersionState
   readonly warnings?: TemplateVersionWarning[]
 }
 
+// From codersdk/templateversions.go
+export interface TemplateVersionDiff {
+  readonly base_version_id: string
+  readonly version_id: string
+  readonly files: TemplateVersionFileDiff[]
+  readonly parameters: TemplateVersionParameterDiff[]
+  readonly variables: TemplateVersionVariableDiff[]
+  readonly resources: TemplateVersionResourceDiff[]
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionFieldDiff {
+  readonly field: string
+  readonly old: string
+  readonly new: string
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionFileDiff {
+  readonly path: string
+  readonly change: TemplateVersionDiffChange
+  readonly binary: boolean
+  readonly diff?: string
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionGitAuth {
   readonly id: string
@@ -882,6 +1202,13 @@ export interface TemplateVersionParameter {
   readonly legacy_variable_name?: string
 }
 
+// From codersdk/templateversions.go
+export interface TemplateVersionParameterDiff {
+  readonly name: string
+  readonly change: TemplateVersionDiffChange
+  readonly fields?: TemplateVersionFieldDiff[]
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionParameterOption {
   readonly name: string
@@ -890,6 +1217,68 @@ export interface TemplateVersionParameterOption {
   readonly icon: string
 }
 
+// From codersdk/templateversionpresets.go
+export interface TemplateVersionPreset {
+  readonly id: string
+  readonly name: string
+  readonly description?: string
+  readonly parameters: WorkspaceBuildParameter[]
+}
+
+// From codersdk/templateversionpresets.go
+export interface TemplateVersionPresetRequest {
+  readonly name: string
+  readonly description?: string
+  readonly parameters: WorkspaceBuildParameter[]
+}
+
+// From codersdk/templateversions.go
+export interface TemplateVersionResourceDiff {
+  readonly kind: TemplateVersionDiffKind
+  readonly path: string
+  readonly change: TemplateVersionDiffChange
+  readonly fields?: TemplateVersionFieldDiff[]
+}
+
+// From codersdk/templateversionrollouts.go
+export interface TemplateVersionRollout {
+  readonly id: string
+  readonly template_id: string
+  readonly template_version_id: string
+  readonly template_version_name: string
+  readonly created_by: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly status: TemplateVersionRolloutStatus
+  readonly paused_reason?: string
+  readonly percentage: number
+  readonly group_ids: string[]
+  readonly batch_size: number
+  readonly failure_threshold_percent: number
+  readonly counts: TemplateVersionRolloutCounts
+  readonly workspaces?: TemplateVersionRolloutWorkspace[]
+}
+
+// From codersdk/templateversionrollouts.go
+export interface TemplateVersionRolloutCounts {
+  readonly total: number
+  readonly pending: number
+  readonly building: number
+  readonly succeeded: number
+  readonly failed: number
+  readonly skipped: number
+}
+
+// From codersdk/templateversionrollouts.go
+export interface TemplateVersionRolloutWorkspace {
+  readonly workspace_id: string
+  readonly workspace_name: string
+  readonly workspace_owner_name: string
+  readonly build_id?: string
+  readonly status: TemplateVersionRolloutWorkspaceStatus
+  readonly error?: string
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionVariable {
   readonly name: string
@@ -901,6 +1290,13 @@ export interface TemplateVersionVariable {
   readonly sensitive: boolean
 }
 
+// From codersdk/templateversions.go
+export interface TemplateVersionVariableDiff {
+  readonly name: string
+  readonly change: TemplateVersionDiffChange
+  readonly fields?: TemplateVersionFieldDiff[]
+}
+
 // From codersdk/templates.go
 export interface TemplateVersionsByTemplateRequest extends Pagination {
   readonly template_id: string
@@ -948,6 +1344,19 @@ export interface UpdateCheckResponse {
   readonly url: string
 }
 
+// From codersdk/derpregions.go
+export interface UpdateDERPRegionPolicyRequest {
+  readonly region_ids: number[]
+}
+
+// From codersdk/derpregions.go
+export interface UpdateManagedDERPRegionRequest {
+  readonly region_code: string
+  readonly region_name: string
+  readonly nodes: ManagedDERPNode[]
+  readonly avoid: boolean
+}
+
 // From codersdk/users.go
 export interface UpdateRoles {
   readonly roles: string[]
@@ -959,6 +1368,16 @@ export interface UpdateTemplateACL {
   readonly group_perms?: Record<string, TemplateRole>
 }
 
+// From codersdk/templategitsources.go
+export interface UpdateTemplateGitSourceRequest {
+  readonly repository_url: string
+  readonly ref: string
+  readonly subdirectory?: string
+  readonly git_auth_provider_id?: string
+  readonly poll_interval_ms?: number
+  readonly webhook_secret?: string
+}
+
 // From codersdk/templates.go
 export interface UpdateTemplateMeta {
   readonly name?: string
@@ -972,6 +1391,23 @@ export interface UpdateTemplateMeta {
   readonly allow_user_cancel_workspace_jobs?: boolean
   readonly failure_ttl_ms?: number
   readonly inactivity_ttl_ms?: number
+  readonly require_active_version?: boolean
+  readonly max_port_share_level?: WorkspacePortShareLevel
+}
+
+// From codersdk/templateversionpresets.go
+export interface UpdateTemplateVersionPresetsRequest {
+  readonly presets: TemplateVersionPresetRequest[]
+}
+
+// From codersdk/templateversionrollouts.go
+export interface UpdateTemplateVersionRolloutRequest {
+  readonly status: TemplateVersionRolloutStatus
+}
+
+// From codersdk/templateversions.go
+export interface UpdateTemplateVersionStateRequest {
+  readonly state: TemplateVersionState
 }
 
 // From codersdk/users.go
@@ -1011,6 +1447,15 @@ export interface UploadResponse {
   readonly hash: string
 }
 
+// From codersdk/workspaceportshares.go
+export interface UpsertWorkspacePortShareRequest {
+  readonly agent_name?: string
+  readonly port: number
+  readonly share_level: WorkspacePortShareLevel
+  readonly group_id?: string
+  readonly expires_at?: string
+}
+
 // From codersdk/users.go
 export interface User {
   readonly id: string
@@ -1030,6 +1475,13 @@ export interface UserRoles {
   readonly organization_roles: Record<string, string[]>
 }
 
+// From codersdk/usertotp.go
+export interface UserTOTP {
+  readonly enabled: boolean
+  readonly required: boolean
+  readonly recovery_codes_remaining: number
+}
+
 // From codersdk/users.go
 export interface UsersRequest extends Pagination {
   readonly q?: string
@@ -1047,6 +1499,11 @@ export interface VariableValue {
   readonly value: string
 }
 
+// From codersdk/usertotp.go
+export interface VerifyTOTPRequest {
+  readonly code: string
+}
+
 // From codersdk/workspaces.go
 export interface Workspace {
   readonly id: string
@@ -1101,6 +1558,7 @@ export interface WorkspaceAgent {
   readonly shutdown_script?: string
   readonly shutdown_script_timeout_seconds: number
   readonly subsystem: AgentSubsystem
+  readonly peer_connections: WorkspaceAgentPeerConnection[]
 }
 
 // From codersdk/workspaceagentconn.go
@@ -1138,6 +1596,14 @@ export interface WorkspaceAgentMetadataResult {
   readonly error: string
 }
 
+// From codersdk/workspaceagents.go
+export interface WorkspaceAgentPeerConnection {
+  readonly direct: boolean
+  readonly derp_region_id?: number
+  readonly derp_region_name?: string
+  readonly latency_ms: number
+}
+
 // From codersdk/workspaceagents.go
 export interface WorkspaceAgentStartupLog {
   readonly id: number
@@ -1158,6 +1624,7 @@ export interface WorkspaceApp {
   readonly icon?: string
   readonly subdomain: boolean
   readonly sharing_level: WorkspaceAppSharingLevel
+  readonly sharing_groups?: string[]
   readonly healthcheck: Healthcheck
   readonly health: WorkspaceAppHealth
 }
@@ -1226,6 +1693,18 @@ export interface WorkspaceOptions {
   readonly include_deleted?: boolean
 }
 
+// From codersdk/workspaceportshares.go
+export interface WorkspacePortShare {
+  readonly workspace_id: string
+  readonly agent_name: string
+  readonly port: number
+  readonly share_level: WorkspacePortShareLevel
+  readonly group_id?: string
+  readonly expires_at?: string
+  readonly created_at: string
+  readonly updated_at: string
+}
+
 // From codersdk/workspaceproxy.go
 export interface WorkspaceProxy {
   readonly id: string
@@ -1302,8 +1781,10 @@ export const AgentSubsystems: AgentSubsystem[] = ["envbox"]
 
 // From codersdk/audit.go
 export type AuditAction =
+  | "connect"
   | "create"
   | "delete"
+  | "lockout"
   | "login"
   | "logout"
   | "register"
@@ -1311,8 +1792,10 @@ export type AuditAction =
   | "stop"
   | "write"
 export const AuditActions: AuditAction[] = [
+  "connect",
   "create",
   "delete",
+  "lockout",
   "login",
   "logout",
   "register",
@@ -1338,9 +1821,14 @@ export const Entitlements: Entitlement[] = [
 ]
 
 // From codersdk/deployment.go
-export type Experiment = "moons" | "workspace_actions" | "workspace_filter"
+export type Experiment =
+  | "moons"
+  | "tailnet_pg_coordinator"
+  | "workspace_actions"
+  | "workspace_filter"
 export const Experiments: Experiment[] = [
   "moons",
+  "tailnet_pg_coordinator",
   "workspace_actions",
   "workspace_filter",
 ]
@@ -1357,6 +1845,7 @@ export type FeatureName =
   | "scim"
   | "template_rbac"
   | "user_limit"
+  | "user_role_management"
   | "workspace_proxy"
 export const FeatureNames: FeatureName[] = [
   "advanced_template_scheduling",
@@ -1369,6 +1858,7 @@ export const FeatureNames: FeatureName[] = [
   "scim",
   "template_rbac",
   "user_limit",
+  "user_role_management",
   "workspace_proxy",
 ]
 
@@ -1399,10 +1889,19 @@ export type LogSource = "provisioner" | "provisioner_daemon"
 export const LogSources: LogSource[] = ["provisioner", "provisioner_daemon"]
 
 // From codersdk/apikey.go
-export type LoginType = "github" | "none" | "oidc" | "password" | "token"
+export type LoginType =
+  | "github"
+  | "impersonation"
+  | "none"
+  | "oauth2_provider_app"
+  | "oidc"
+  | "password"
+  | "token"
 export const LoginTypes: LoginType[] = [
   "github",
+  "impersonation",
   "none",
+  "oauth2_provider_app",
   "oidc",
   "password",
   "token",
@@ -1463,6 +1962,7 @@ export type RBACResource =
   | "file"
   | "group"
   | "license"
+  | "oauth2_app"
   | "organization"
   | "organization_member"
   | "provisioner_daemon"
@@ -1486,6 +1986,7 @@ export const RBACResources: RBACResource[] = [
   "file",
   "group",
   "license",
+  "oauth2_app",
   "organization",
   "organization_member",
   "provisioner_daemon",
@@ -1502,24 +2003,36 @@ export const RBACResources: RBACResource[] = [
 // From codersdk/audit.go
 export type ResourceType =
   | "api_key"
+  | "derp_region"
+  | "derp_region_policy"
   | "git_ssh_key"
   | "group"
   | "license"
+  | "oauth2_provider_app"
+  | "oauth2_provider_app_secret"
   | "template"
   | "template_version"
+  | "template_version_rollout"
   | "user"
   | "workspace"
   | "workspace_build"
+  | "workspace_port_share"
 export const ResourceTypes: ResourceType[] = [
   "api_key",
+  "derp_region",
+  "derp_region_policy",
   "git_ssh_key",
   "group",
   "license",
+  "oauth2_provider_app",
+  "oauth2_provider_app_secret",
   "template",
   "template_version",
+  "template_version_rollout",
   "user",
   "workspace",
   "workspace_build",
+  "workspace_port_share",
 ]
 
 // From codersdk/serversentevents.go
@@ -1534,6 +2047,58 @@ export const ServerSentEventTypes: ServerSentEventType[] = [
 export type TemplateRole = "" | "admin" | "use"
 export const TemplateRoles: TemplateRole[] = ["", "admin", "use"]
 
+// From codersdk/templateversions.go
+export type TemplateVersionDiffChange = "added" | "modified" | "removed"
+export const TemplateVersionDiffChanges: TemplateVersionDiffChange[] = [
+  "added",
+  "modified",
+  "removed",
+]
+
+// From codersdk/templateversions.go
+export type TemplateVersionDiffKind = "agent" | "app" | "resource"
+export const TemplateVersionDiffKinds: TemplateVersionDiffKind[] = [
+  "agent",
+  "app",
+  "resource",
+]
+
+// From codersdk/templateversionrollouts.go
+export type TemplateVersionRolloutStatus =
+  | "aborted"
+  | "completed"
+  | "paused"
+  | "running"
+export const TemplateVersionRolloutStatuses: TemplateVersionRolloutStatus[] = [
+  "aborted",
+  "completed",
+  "paused",
+  "running",
+]
+
+// From codersdk/templateversionrollouts.go
+export type TemplateVersionRolloutWorkspaceStatus =
+  | "building"
+  | "failed"
+  | "pending"
+  | "skipped"
+  | "succeeded"
+export const TemplateVersionRolloutWorkspaceStatuses: TemplateVersionRolloutWorkspaceStatus[] =
+  ["building", "failed", "pending", "skipped", "succeeded"]
+
+// From codersdk/templateversions.go
+export type TemplateVersionState =
+  | "active"
+  | "archived"
+  | "deprecated"
+  | "draft"
+export const TemplateVersionStates: TemplateVersionState[] = [
+  "active",
+  "archived",
+  "deprecated",
+  "draft",
+]
+
 // From codersdk/templateversions.go
 export type TemplateVersionWarning = "UNSUPPORTED_WORKSPACES"
 export const TemplateVersionWarnings: TemplateVersionWarning[] = [
@@ -1606,9 +2171,27 @@ export const WorkspaceAppHealths: WorkspaceAppHealth[] = [
 ]
 
 // From codersdk/workspaceapps.go
-export type WorkspaceAppSharingLevel = "authenticated" | "owner" | "public"
+export type WorkspaceAppSharingLevel =
+  | "authenticated"
+  | "group"
+  | "owner"
+  | "public"
 export const WorkspaceAppSharingLevels: WorkspaceAppSharingLevel[] = [
   "authenticated",
+  "group",
+  "owner",
+  "public",
+]
+
+// From codersdk/workspaceportshares.go
+export type WorkspacePortShareLevel =
+  | "authenticated"
+  | "group"
+  | "owner"
+  | "public"
+export const WorkspacePortShareLevels: WorkspacePortShareLevel[] = [
+  "authenticated",
+  "group",
   "owner",
   "public",
 ]
@@ -80,6 +80,17 @@ SharingLevelAuthenticated.args = {
   agent: MockWorkspaceAgent,
 }
 
+export const SharingLevelGroup = Template.bind({})
+SharingLevelGroup.args = {
+  workspace: MockWorkspace,
+  app: {
+    ...MockWorkspaceApp,
+    sharing_level: "group",
+    sharing_groups: ["engineering", "product"],
+  },
+  agent: MockWorkspaceAgent,
+}
+
 export const SharingLevelPublic = Template.bind({})
 SharingLevelPublic.args = {
   workspace: MockWorkspace,
@@ -25,6 +25,17 @@ export const ShareIcon = ({ app }: ShareIconProps) => {
       </Tooltip>
     )
   }
+  if (app.sharing_level === "group") {
+    return (
+      <Tooltip
+        title={t("shareTooltip.group", {
+          groups: (app.sharing_groups ?? []).join(", "),
+        })}
+      >
+        <GroupOutlinedIcon />
+      </Tooltip>
+    )
+  }
   if (app.sharing_level === "public") {
     return (
       <Tooltip title={t("shareTooltip.public")}>
@@ -0,0 +1,32 @@
+import { ComponentMeta, Story } from "@storybook/react"
+import { MockTemplateAppInsightsResponse } from "testHelpers/entities"
+import { AppUsageTable, AppUsageTableProps } from "./AppUsageTable"
+
+export default {
+  title: "components/AppUsageTable",
+  component: AppUsageTable,
+} as ComponentMeta<typeof AppUsageTable>
+
+const Template: Story<AppUsageTableProps> = (args) => (
+  <AppUsageTable {...args} />
+)
+
+export const Example = Template.bind({})
+Example.args = {
+  insights: MockTemplateAppInsightsResponse,
+}
+
+export const Unused = Template.bind({})
+Unused.args = {
+  insights: {
+    ...MockTemplateAppInsightsResponse,
+    apps: MockTemplateAppInsightsResponse.apps.map((app) => ({
+      ...app,
+      unique_users: 0,
+      requests: 0,
+      error_requests: 0,
+      average_latency_ms: 0,
+      bytes_sent: 0,
+    })),
+  },
+}
@@ -0,0 +1,94 @@
+import Table from "@mui/material/Table"
+import TableBody from "@mui/material/TableBody"
+import TableCell from "@mui/material/TableCell"
+import TableContainer from "@mui/material/TableContainer"
+import TableHead from "@mui/material/TableHead"
+import TableRow from "@mui/material/TableRow"
+import * as TypesGen from "api/typesGenerated"
+import { AvatarData } from "components/AvatarData/AvatarData"
+import { Stack } from "components/Stack/Stack"
+import {
+  HelpTooltip,
+  HelpTooltipText,
+  HelpTooltipTitle,
+} from "components/Tooltips/HelpTooltip"
+import { WorkspaceSection } from "components/WorkspaceSection/WorkspaceSection"
+import prettyBytes from "pretty-bytes"
+import { FC } from "react"
+
+export const Language = {
+  title: "App usage",
+  appLabel: "App",
+  usersLabel: "Users",
+  requestsLabel: "Requests",
+  errorsLabel: "Errors",
+  latencyLabel: "Avg. latency",
+  bytesSentLabel: "Data sent",
+}
+
+export interface AppUsageTableProps {
+  insights: TypesGen.AppInsightsResponse
+}
+
+export const AppUsageTable: FC<AppUsageTableProps> = ({ insights }) => {
+  const apps = insights.apps
+    .slice()
+    .sort((a, b) => b.requests - a.requests || a.slug.localeCompare(b.slug))
+
+  return (
+    <WorkspaceSection
+      title={
+        <Stack direction="row" spacing={1} alignItems="center">
+          {Language.title}
+          <HelpTooltip size="small">
+            <HelpTooltipTitle>How is app usage collected?</HelpTooltipTitle>
+            <HelpTooltipText>
+              Requests to apps of workspaces of this template are counted
+              every hour. Apps removed from the template are listed until
+              their usage is older than 30 days.
+            </HelpTooltipText>
+          </HelpTooltip>
+        </Stack>
+      }
+    >
+      <TableContainer>
+        <Table data-testid="app-usage-table">
+          <TableHead>
+            <TableRow>
+              <TableCell width="40%">{Language.appLabel}</TableCell>
+              <TableCell>{Language.usersLabel}</TableCell>
+              <TableCell>{Language.requestsLabel}</TableCell>
+              <TableCell>{Language.errorsLabel}</TableCell>
+              <TableCell>{Language.latencyLabel}</TableCell>
+              <TableCell>{Language.bytesSentLabel}</TableCell>
+            </TableRow>
+          </TableHead>
+          <TableBody>
+            {apps.map((app) => (
+              <TableRow key={app.slug}>
+                <TableCell>
+                  <AvatarData
+                    title={app.display_name || app.slug}
+                    subtitle={app.display_name ? app.slug : undefined}
+                    src={app.icon}
+                  />
+                </TableCell>
+                <TableCell>{app.unique_users}</TableCell>
+                <TableCell>{app.requests}</TableCell>
+                <TableCell>{app.error_requests}</TableCell>
+                <TableCell>
+                  {app.requests > 0
+                    ? `${Math.round(app.average_latency_ms)}ms`
+                    : "-"}
+                </TableCell>
+                <TableCell>
+                  {app.requests > 0 ? prettyBytes(app.bytes_sent) : "-"}
+                </TableCell>
+              </TableRow>
+            ))}
+          </TableBody>
+        </Table>
+      </TableContainer>
+    </WorkspaceSection>
+  )
+}
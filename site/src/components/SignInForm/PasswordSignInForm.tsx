@@ -9,15 +9,17 @@ import { FC } from "react"
 import { BuiltInAuthFormValues } from "./SignInForm.types"
 
 type PasswordSignInFormProps = {
-  onSubmit: (credentials: { email: string; password: string }) => void
+  onSubmit: (credentials: BuiltInAuthFormValues) => void
   initialTouched?: FormikTouched<BuiltInAuthFormValues>
   isSigningIn: boolean
+  mfaRequired?: boolean
 }
 
 export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
   onSubmit,
   initialTouched,
   isSigningIn,
+  mfaRequired,
 }) => {
   const validationSchema = Yup.object({
     email: Yup.string()
@@ -25,6 +27,7 @@ export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
       .email(Language.emailInvalid)
       .required(Language.emailRequired),
     password: Yup.string(),
+    code: Yup.string(),
   })
 
   const form: FormikContextType<BuiltInAuthFormValues> =
@@ -32,6 +35,7 @@ export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
       initialValues: {
         email: "",
         password: "",
+        code: "",
       },
       validationSchema,
       onSubmit,
@@ -59,6 +63,16 @@ export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
           label={Language.passwordLabel}
           type="password"
         />
+        {mfaRequired && (
+          <TextField
+            {...getFieldHelpers("code", Language.codeHelperText)}
+            autoFocus
+            autoComplete="one-time-code"
+            fullWidth
+            id="code"
+            label={Language.codeLabel}
+          />
+        )}
         <div>
           <LoadingButton
             size="large"
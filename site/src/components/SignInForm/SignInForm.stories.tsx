@@ -31,6 +31,7 @@ SigningIn.args = {
     password: { enabled: true },
     github: { enabled: true },
     oidc: { enabled: false, signInText: "", iconUrl: "" },
+    oidc_providers: [],
   },
 }
 
@@ -51,6 +52,12 @@ WithError.args = {
   },
 }
 
+export const WithMFARequired = Template.bind({})
+WithMFARequired.args = {
+  ...SignedOut.args,
+  mfaRequired: true,
+}
+
 export const WithGithub = Template.bind({})
 WithGithub.args = {
   ...SignedOut.args,
@@ -58,6 +65,7 @@ WithGithub.args = {
     password: { enabled: true },
     github: { enabled: true },
     oidc: { enabled: false, signInText: "", iconUrl: "" },
+    oidc_providers: [],
   },
 }
 
@@ -68,6 +76,7 @@ WithOIDC.args = {
     password: { enabled: true },
     github: { enabled: false },
     oidc: { enabled: true, signInText: "", iconUrl: "" },
+    oidc_providers: [],
   },
 }
 
@@ -78,6 +87,7 @@ WithOIDCWithoutPassword.args = {
     password: { enabled: false },
     github: { enabled: false },
     oidc: { enabled: true, signInText: "", iconUrl: "" },
+    oidc_providers: [],
   },
 }
 
@@ -88,6 +98,7 @@ WithoutAny.args = {
     password: { enabled: false },
     github: { enabled: false },
     oidc: { enabled: false, signInText: "", iconUrl: "" },
+    oidc_providers: [],
   },
 }
 
@@ -98,5 +109,20 @@ WithGithubAndOIDC.args = {
     password: { enabled: true },
     github: { enabled: true },
     oidc: { enabled: true, signInText: "", iconUrl: "" },
+    oidc_providers: [],
+  },
+}
+
+export const WithOIDCProviders = Template.bind({})
+WithOIDCProviders.args = {
+  ...SignedOut.args,
+  authMethods: {
+    password: { enabled: true },
+    github: { enabled: false },
+    oidc: { enabled: true, signInText: "", iconUrl: "" },
+    oidc_providers: [
+      { id: "okta", signInText: "Okta", iconUrl: "" },
+      { id: "azure", signInText: "Azure AD", iconUrl: "" },
+    ],
   },
 }
@@ -15,6 +15,9 @@ import { ErrorAlert } from "components/Alert/ErrorAlert"
 export const Language = {
   emailLabel: "Email",
   passwordLabel: "Password",
+  codeLabel: "Authentication code",
+  codeHelperText:
+    "Enter the code from your authenticator app or a recovery code.",
   emailInvalid: "Please enter a valid email address.",
   emailRequired: "Please enter an email address.",
   passwordSignIn: "Sign In",
@@ -70,7 +73,10 @@ export interface SignInFormProps {
   redirectTo: string
   error?: unknown
   authMethods?: AuthMethods
-  onSubmit: (credentials: { email: string; password: string }) => void
+  // mfaRequired is true once the password was accepted and a code from an
+  // authenticator app is required.
+  mfaRequired?: boolean
+  onSubmit: (credentials: BuiltInAuthFormValues) => void
   // initialTouched is only used for testing the error state of the form.
   initialTouched?: FormikTouched<BuiltInAuthFormValues>
 }
@@ -80,11 +86,14 @@ export const SignInForm: FC<React.PropsWithChildren<SignInFormProps>> = ({
   redirectTo,
   isSigningIn,
   error,
+  mfaRequired,
   onSubmit,
   initialTouched,
 }) => {
   const oAuthEnabled = Boolean(
-    authMethods?.github.enabled || authMethods?.oidc.enabled,
+    authMethods?.github.enabled ||
+      authMethods?.oidc.enabled ||
+      authMethods?.oidc_providers.length,
   )
   const passwordEnabled = authMethods?.password.enabled ?? true
   // Hide password auth by default if any OAuth method is enabled
@@ -109,6 +118,7 @@ export const SignInForm: FC<React.PropsWithChildren<SignInFormProps>> = ({
           onSubmit={onSubmit}
           initialTouched={initialTouched}
           isSigningIn={isSigningIn}
+          mfaRequired={mfaRequired}
         />
       </Maybe>
       <Maybe condition={passwordEnabled && showPasswordAuth && oAuthEnabled}>
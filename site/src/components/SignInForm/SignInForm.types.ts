@@ -6,4 +6,8 @@
 export interface BuiltInAuthFormValues {
   email: string
   password: string
+  // code is the code from an authenticator app or a recovery code. It is
+  // only asked for once the user is known to have multi-factor authentication
+  // enabled.
+  code?: string
 }
@@ -14,6 +14,12 @@ export const LoginPage: FC = () => {
   const loginPageTranslation = useTranslation("loginPage")
 
   if (authState.matches("signedIn")) {
+    // Pages like the OAuth2 consent screen are rendered by the server rather
+    // than the dashboard, so they need a full page load.
+    if (redirectTo.startsWith("/oauth2/")) {
+      window.location.replace(redirectTo)
+      return null
+    }
     return <Navigate to={redirectTo} replace />
   } else if (authState.matches("configuringTheFirstUser")) {
     return <Navigate to="/setup" />
@@ -29,8 +35,8 @@ export const LoginPage: FC = () => {
           context={authState.context}
           isLoading={authState.matches("loadingInitialAuthData")}
           isSigningIn={authState.matches("signingIn")}
-          onSignIn={({ email, password }) => {
-            authSend({ type: "SIGN_IN", email, password })
+          onSignIn={({ email, password, code }) => {
+            authSend({ type: "SIGN_IN", email, password, code })
           }}
         />
       </>
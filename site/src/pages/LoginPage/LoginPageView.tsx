@@ -2,16 +2,21 @@ import { makeStyles } from "@mui/styles"
 import { FullScreenLoader } from "components/Loader/FullScreenLoader"
 import { FC } from "react"
 import { useLocation } from "react-router-dom"
-import { AuthContext, UnauthenticatedData } from "xServices/auth/authXService"
+import {
+  AuthContext,
+  UnauthenticatedData,
+  isMFARequiredError,
+} from "xServices/auth/authXService"
 import { SignInForm } from "components/SignInForm/SignInForm"
 import { retrieveRedirect } from "utils/redirect"
 import { CoderIcon } from "components/Icons/CoderIcon"
+import { BuiltInAuthFormValues } from "components/SignInForm/SignInForm.types"
 
 export interface LoginPageViewProps {
   context: AuthContext
   isLoading: boolean
   isSigningIn: boolean
-  onSignIn: (credentials: { email: string; password: string }) => void
+  onSignIn: (credentials: BuiltInAuthFormValues) => void
 }
 
 export const LoginPageView: FC<LoginPageViewProps> = ({
@@ -36,7 +41,8 @@ export const LoginPageView: FC<LoginPageViewProps> = ({
           authMethods={data.authMethods}
           redirectTo={redirectTo}
           isSigningIn={isSigningIn}
-          error={error}
+          error={isMFARequiredError(error) ? undefined : error}
+          mfaRequired={isMFARequiredError(error)}
           onSubmit={onSignIn}
         />
         <footer className={styles.footer}>
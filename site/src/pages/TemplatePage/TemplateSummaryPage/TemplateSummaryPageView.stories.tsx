@@ -1,6 +1,7 @@
 import { Story } from "@storybook/react"
 import {
   MockTemplate,
+  MockTemplateAppInsightsResponse,
   MockTemplateDAUResponse,
   MockTemplateVersion,
   MockTemplateVersion3,
@@ -28,6 +29,7 @@ Example.args = {
   data: {
     resources: [MockWorkspaceResource, MockWorkspaceResource2],
     daus: MockTemplateDAUResponse,
+    appInsights: MockTemplateAppInsightsResponse,
   },
 }
 
@@ -38,6 +40,7 @@ NoIcon.args = {
   data: {
     resources: [MockWorkspaceResource, MockWorkspaceResource2],
     daus: MockTemplateDAUResponse,
+    appInsights: MockTemplateAppInsightsResponse,
   },
 }
 
@@ -61,6 +64,7 @@ SmallViewport.args = {
   data: {
     resources: [MockWorkspaceResource, MockWorkspaceResource2],
     daus: MockTemplateDAUResponse,
+    appInsights: MockTemplateAppInsightsResponse,
   },
 }
 SmallViewport.parameters = {
@@ -74,5 +78,6 @@ WithDeprecatedParameters.args = {
   data: {
     resources: [MockWorkspaceResource, MockWorkspaceResource2],
     daus: MockTemplateDAUResponse,
+    appInsights: MockTemplateAppInsightsResponse,
   },
 }
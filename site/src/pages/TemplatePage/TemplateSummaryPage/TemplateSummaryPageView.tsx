@@ -12,6 +12,7 @@ import { DAUChart } from "../../../components/DAUChart/DAUChart"
 import { TemplateSummaryData } from "./data"
 import { useLocation, useNavigate } from "react-router-dom"
 import { TemplateVersionWarnings } from "components/TemplateVersionWarnings/TemplateVersionWarnings"
+import { AppUsageTable } from "components/AppUsageTable/AppUsageTable"
 
 export interface TemplateSummaryPageViewProps {
   data?: TemplateSummaryData
@@ -39,7 +40,7 @@ export const TemplateSummaryPageView: FC<TemplateSummaryPageViewProps> = ({
     return <Loader />
   }
 
-  const { daus, resources } = data
+  const { daus, resources, appInsights } = data
 
   const getStartedResources = (resources: WorkspaceResource[]) => {
     return resources.filter(
@@ -52,6 +53,9 @@ export const TemplateSummaryPageView: FC<TemplateSummaryPageViewProps> = ({
       <TemplateVersionWarnings warnings={activeVersion.warnings} />
       <TemplateStats template={template} activeVersion={activeVersion} />
       {daus && <DAUChart daus={daus} />}
+      {appInsights && appInsights.apps.length > 0 && (
+        <AppUsageTable insights={appInsights} />
+      )}
       <TemplateResourcesTable resources={getStartedResources(resources)} />
     </Stack>
   )
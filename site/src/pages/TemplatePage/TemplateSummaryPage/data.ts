@@ -1,18 +1,24 @@
 import { useQuery } from "@tanstack/react-query"
-import { getTemplateVersionResources, getTemplateDAUs } from "api/api"
+import {
+  getTemplateVersionResources,
+  getTemplateDAUs,
+  getTemplateAppInsights,
+} from "api/api"
 
 const fetchTemplateSummary = async (
   templateId: string,
   activeVersionId: string,
 ) => {
-  const [resources, daus] = await Promise.all([
+  const [resources, daus, appInsights] = await Promise.all([
     getTemplateVersionResources(activeVersionId),
     getTemplateDAUs(templateId),
+    getTemplateAppInsights(templateId),
   ])
 
   return {
     resources,
     daus,
+    appInsights,
   }
 }
 
@@ -24,6 +24,46 @@ export const MockTemplateDAUResponse: TypesGen.DAUsResponse = {
     { date: "2022-08-30T00:00:00Z", amount: 1 },
   ],
 }
+export const MockTemplateAppInsightsResponse: TypesGen.AppInsightsResponse =
+  {
+    start_time: "2022-08-01T00:00:00Z",
+    end_time: "2022-08-31T00:00:00Z",
+    apps: [
+      {
+        template_id: "test-template",
+        slug: "code-server",
+        display_name: "code-server",
+        icon: "/icon/code.svg",
+        unique_users: 3,
+        requests: 1204,
+        error_requests: 2,
+        average_latency_ms: 42.5,
+        bytes_sent: 52428800,
+      },
+      {
+        template_id: "test-template",
+        slug: "jupyter",
+        display_name: "Jupyter",
+        icon: "/icon/jupyter.svg",
+        unique_users: 1,
+        requests: 87,
+        error_requests: 0,
+        average_latency_ms: 120,
+        bytes_sent: 1048576,
+      },
+      {
+        template_id: "test-template",
+        slug: "filebrowser",
+        display_name: "File Browser",
+        icon: "",
+        unique_users: 0,
+        requests: 0,
+        error_requests: 0,
+        average_latency_ms: 0,
+        bytes_sent: 0,
+      },
+    ],
+  }
 export const MockDeploymentDAUResponse: TypesGen.DAUsResponse = {
   tz_hour_offset: 0,
   entries: [
@@ -324,6 +364,7 @@ You can add instructions here
 
 [Some link info](https://coder.com)`,
   created_by: MockUser,
+  state: "active",
 }
 
 export const MockTemplateVersion2: TypesGen.TemplateVersion = {
@@ -341,6 +382,7 @@ You can add instructions here
 
 [Some link info](https://coder.com)`,
   created_by: MockUser,
+  state: "active",
 }
 
 export const MockTemplateVersion3: TypesGen.TemplateVersion = {
@@ -352,6 +394,7 @@ export const MockTemplateVersion3: TypesGen.TemplateVersion = {
   name: "test-version-3",
   readme: "README",
   created_by: MockUser,
+  state: "draft",
   warnings: ["UNSUPPORTED_WORKSPACES"],
 }
 
@@ -390,6 +433,8 @@ export const MockTemplate: TypesGen.Template = {
   inactivity_ttl_ms: 0,
   allow_user_autostart: false,
   allow_user_autostop: false,
+  require_active_version: false,
+  max_port_share_level: "authenticated",
 }
 
 export const MockTemplateVersionFiles: TemplateVersionFiles = {
@@ -505,6 +550,7 @@ export const MockWorkspaceAgent: TypesGen.WorkspaceAgent = {
   startup_script_timeout_seconds: 120,
   shutdown_script_timeout_seconds: 120,
   subsystem: "envbox",
+  peer_connections: [],
 }
 
 export const MockWorkspaceAgentDisconnected: TypesGen.WorkspaceAgent = {
@@ -1018,6 +1064,7 @@ export const MockAuthMethods: TypesGen.AuthMethods = {
   password: { enabled: true },
   github: { enabled: false },
   oidc: { enabled: false, signInText: "", iconUrl: "" },
+  oidc_providers: [],
 }
 
 export const MockGitSSHKey: TypesGen.GitSSHKey = {
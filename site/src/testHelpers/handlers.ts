@@ -12,6 +12,13 @@ export const handlers = [
     return res(ctx.status(200), ctx.json(M.MockTemplateDAUResponse))
   }),
 
+  rest.get(
+    "/api/v2/templates/:templateId/insights/apps",
+    async (req, res, ctx) => {
+      return res(ctx.status(200), ctx.json(M.MockTemplateAppInsightsResponse))
+    },
+  ),
+
   rest.get("/api/v2/insights/daus", async (req, res, ctx) => {
     return res(ctx.status(200), ctx.json(M.MockDeploymentDAUResponse))
   }),
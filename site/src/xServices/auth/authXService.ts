@@ -122,11 +122,41 @@ const loadInitialAuthData = async (): Promise<AuthData> => {
   }
 }
 
+/**
+ * MFARequiredError is thrown when a password login must be repeated with a
+ * code from the user's authenticator app.
+ */
+export class MFARequiredError extends Error {
+  constructor() {
+    super("Enter the code from your authenticator app or a recovery code.")
+  }
+}
+
+export const isMFARequiredError = (error: unknown): error is MFARequiredError =>
+  error instanceof MFARequiredError
+
 const signIn = async (
   email: string,
   password: string,
+  code?: string,
 ): Promise<AuthenticatedData> => {
-  await API.login(email, password)
+  code = code?.trim()
+  // Codes from authenticator apps are all digits, recovery codes are not.
+  const isTOTPCode = code !== undefined && /^[0-9]+$/.test(code)
+  const response = await API.login(
+    email,
+    password,
+    code && isTOTPCode ? code : undefined,
+    code && !isTOTPCode ? code : undefined,
+  )
+  if (response.mfa_enrollment_required) {
+    throw new Error(
+      "Your account must enroll in multi-factor authentication. Run `coder login --email` to enroll.",
+    )
+  }
+  if (response.mfa_required) {
+    throw new MFARequiredError()
+  }
   const [user, permissions] = await Promise.all([
     API.getAuthenticatedUser(),
     API.checkAuthorization({
@@ -159,7 +189,7 @@ export interface AuthContext {
 
 export type AuthEvent =
   | { type: "SIGN_OUT" }
-  | { type: "SIGN_IN"; email: string; password: string }
+  | { type: "SIGN_IN"; email: string; password: string; code?: string }
   | { type: "UPDATE_PROFILE"; data: TypesGen.UpdateUserProfileRequest }
 
 export const authMachine =
@@ -319,7 +349,7 @@ export const authMachine =
     {
       services: {
         loadInitialAuthData,
-        signIn: (_, { email, password }) => signIn(email, password),
+        signIn: (_, { email, password, code }) => signIn(email, password, code),
         signOut,
         updateProfile: async ({ data }, event) => {
           if (!data) {
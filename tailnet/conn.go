@@ -364,24 +364,24 @@ func (c *Conn) RemoveAllPeers() error {
 
 	c.netMap.Peers = []*tailcfg.Node{}
 	c.peerMap = map[tailcfg.NodeID]*tailcfg.Node{}
-	netMapCopy := *c.netMap
-	c.logger.Debug(context.Background(), "updating network map")
-	c.wireguardEngine.SetNetworkMap(&netMapCopy)
-	cfg, err := nmcfg.WGCfg(c.netMap, Logger(c.logger.Named("wgconfig")), netmap.AllowSingleHosts, "")
-	if err != nil {
-		return xerrors.Errorf("update wireguard config: %w", err)
+	return c.reconfigLocked()
+}
+
+// RemovePeer removes the peer with the given node ID from the network map.
+// Removing an unknown peer is a no-op.
+func (c *Conn) RemovePeer(id tailcfg.NodeID) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.peerMap[id]; !ok {
+		return nil
 	}
-	err = c.wireguardEngine.Reconfig(cfg, c.wireguardRouter, &dns.Config{}, &tailcfg.Debug{})
-	if err != nil {
-		if c.isClosed() {
-			return nil
-		}
-		if errors.Is(err, wgengine.ErrNoChanges) {
-			return nil
-		}
-		return xerrors.Errorf("reconfig: %w", err)
+	delete(c.peerMap, id)
+	c.netMap.Peers = make([]*tailcfg.Node, 0, len(c.peerMap))
+	for _, peer := range c.peerMap {
+		c.netMap.Peers = append(c.netMap.Peers, peer.Clone())
 	}
-	return nil
+	return c.reconfigLocked()
 }
 
 // UpdateNodes connects with a set of peers. This can be constantly updated,
@@ -448,6 +448,12 @@ func (c *Conn) UpdateNodes(nodes []*Node, replacePeers bool) error {
 	for _, peer := range c.peerMap {
 		c.netMap.Peers = append(c.netMap.Peers, peer.Clone())
 	}
+	return c.reconfigLocked()
+}
+
+// reconfigLocked applies the network map to the wireguard engine. The caller
+// must hold c.mutex.
+func (c *Conn) reconfigLocked() error {
 	netMapCopy := *c.netMap
 	c.logger.Debug(context.Background(), "updating network map")
 	c.wireguardEngine.SetNetworkMap(&netMapCopy)
@@ -0,0 +1,120 @@
+package tailnet
+
+import (
+	"net/netip"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+
+	"github.com/coder/coder/tailnet/proto"
+)
+
+// NodeToProto converts a Node to its protobuf representation.
+func NodeToProto(n *Node) (*proto.Node, error) {
+	k, err := n.Key.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("marshal key: %w", err)
+	}
+	disco, err := n.DiscoKey.MarshalText()
+	if err != nil {
+		return nil, xerrors.Errorf("marshal disco key: %w", err)
+	}
+	var derpForcedWebsocket map[int32]string
+	if n.DERPForcedWebsocket != nil {
+		derpForcedWebsocket = make(map[int32]string, len(n.DERPForcedWebsocket))
+		for region, reason := range n.DERPForcedWebsocket {
+			derpForcedWebsocket[int32(region)] = reason
+		}
+	}
+	return &proto.Node{
+		Id:                  int64(n.ID),
+		AsOf:                timestamppb.New(n.AsOf),
+		Key:                 k,
+		Disco:               string(disco),
+		PreferredDerp:       int32(n.PreferredDERP),
+		DerpLatency:         n.DERPLatency,
+		DerpForcedWebsocket: derpForcedWebsocket,
+		Addresses:           prefixesToStrings(n.Addresses),
+		AllowedIps:          prefixesToStrings(n.AllowedIPs),
+		Endpoints:           n.Endpoints,
+	}, nil
+}
+
+// ProtoToNode converts the protobuf representation of a node back to a Node.
+// Empty collections are converted to nil so the JSON encoding of the node
+// matches the one of the node it was created from.
+func ProtoToNode(p *proto.Node) (*Node, error) {
+	k := key.NodePublic{}
+	err := k.UnmarshalBinary(p.GetKey())
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal key: %w", err)
+	}
+	disco := key.DiscoPublic{}
+	err = disco.UnmarshalText([]byte(p.GetDisco()))
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal disco key: %w", err)
+	}
+	var derpLatency map[string]float64
+	if len(p.GetDerpLatency()) > 0 {
+		derpLatency = p.GetDerpLatency()
+	}
+	var derpForcedWebsocket map[int]string
+	if len(p.GetDerpForcedWebsocket()) > 0 {
+		derpForcedWebsocket = make(map[int]string, len(p.GetDerpForcedWebsocket()))
+		for region, reason := range p.GetDerpForcedWebsocket() {
+			derpForcedWebsocket[int(region)] = reason
+		}
+	}
+	addresses, err := stringsToPrefixes(p.GetAddresses())
+	if err != nil {
+		return nil, xerrors.Errorf("parse addresses: %w", err)
+	}
+	allowedIPs, err := stringsToPrefixes(p.GetAllowedIps())
+	if err != nil {
+		return nil, xerrors.Errorf("parse allowed IPs: %w", err)
+	}
+	var endpoints []string
+	if len(p.GetEndpoints()) > 0 {
+		endpoints = p.GetEndpoints()
+	}
+	return &Node{
+		ID:                  tailcfg.NodeID(p.GetId()),
+		AsOf:                p.GetAsOf().AsTime(),
+		Key:                 k,
+		DiscoKey:            disco,
+		PreferredDERP:       int(p.GetPreferredDerp()),
+		DERPLatency:         derpLatency,
+		DERPForcedWebsocket: derpForcedWebsocket,
+		Addresses:           addresses,
+		AllowedIPs:          allowedIPs,
+		Endpoints:           endpoints,
+	}, nil
+}
+
+func prefixesToStrings(prefixes []netip.Prefix) []string {
+	if prefixes == nil {
+		return nil
+	}
+	out := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		out = append(out, prefix.String())
+	}
+	return out
+}
+
+func stringsToPrefixes(strs []string) ([]netip.Prefix, error) {
+	if len(strs) == 0 {
+		return nil, nil
+	}
+	out := make([]netip.Prefix, 0, len(strs))
+	for _, str := range strs {
+		prefix, err := netip.ParsePrefix(str)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, prefix)
+	}
+	return out, nil
+}
@@ -0,0 +1,251 @@
+package tailnet
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/tailnet/proto"
+)
+
+// Coordinatee is something that can be coordinated, such as a *Conn.
+type Coordinatee interface {
+	UpdateNodes(nodes []*Node, replacePeers bool) error
+	RemovePeer(id tailcfg.NodeID) error
+	SetNodeCallback(callback func(node *Node))
+}
+
+// Coordination exchanges the nodes of a Coordinatee with a coordinator using
+// version 2 of the coordination protocol.
+type Coordination struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	logger       slog.Logger
+	coordinatee  Coordinatee
+	reqs         chan<- *proto.CoordinateRequest
+	errChan      chan error
+	respLoopDone chan struct{}
+
+	mu sync.Mutex
+	// peers maps the IDs of tunnel peers to the ID of their last node, so
+	// the node can be removed when the peer disconnects.
+	peers  map[uuid.UUID]tailcfg.NodeID
+	closed bool
+}
+
+// NewRemoteCoordination coordinates the Coordinatee over conn, which must
+// speak version 2 of the coordination protocol. If tunnelTarget is not
+// uuid.Nil, a tunnel to it is requested.
+func NewRemoteCoordination(logger slog.Logger, conn net.Conn, coordinatee Coordinatee, tunnelTarget uuid.UUID) *Coordination {
+	ctx, cancel := context.WithCancel(context.Background())
+	reqs := make(chan *proto.CoordinateRequest, RequestBufferSize)
+	resps := make(chan *proto.CoordinateResponse, ResponseBufferSize)
+	c := newCoordination(ctx, cancel, logger, coordinatee, reqs, resps, tunnelTarget)
+
+	go func() {
+		defer close(resps)
+		reader := bufio.NewReader(conn)
+		for {
+			resp := new(proto.CoordinateResponse)
+			err := protodelim.UnmarshalFrom(reader, resp)
+			if err != nil {
+				c.sendErr(xerrors.Errorf("read: %w", err))
+				return
+			}
+			err = SendCtx(ctx, resps, resp)
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer conn.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case req, ok := <-reqs:
+				if !ok {
+					return
+				}
+				_, err := protodelim.MarshalTo(conn, req)
+				if err != nil {
+					c.sendErr(xerrors.Errorf("write: %w", err))
+					return
+				}
+				if req.Disconnect != nil {
+					return
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// NewInMemoryCoordination coordinates the Coordinatee with a coordinator in
+// the same process as the peer with the given ID. If tunnelTarget is not
+// uuid.Nil, a tunnel to it is requested.
+func NewInMemoryCoordination(
+	ctx context.Context, logger slog.Logger, coordinator Coordinator,
+	id uuid.UUID, name string, a TunnelAuth,
+	coordinatee Coordinatee, tunnelTarget uuid.UUID,
+) *Coordination {
+	ctx, cancel := context.WithCancel(ctx)
+	reqs, resps := coordinator.Coordinate(ctx, id, name, a)
+	return newCoordination(ctx, cancel, logger, coordinatee, reqs, resps, tunnelTarget)
+}
+
+func newCoordination(
+	ctx context.Context, cancel context.CancelFunc, logger slog.Logger, coordinatee Coordinatee,
+	reqs chan<- *proto.CoordinateRequest, resps <-chan *proto.CoordinateResponse,
+	tunnelTarget uuid.UUID,
+) *Coordination {
+	c := &Coordination{
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       logger,
+		coordinatee:  coordinatee,
+		reqs:         reqs,
+		errChan:      make(chan error, 1),
+		respLoopDone: make(chan struct{}),
+		peers:        make(map[uuid.UUID]tailcfg.NodeID),
+	}
+	if tunnelTarget != uuid.Nil {
+		// The request channel is buffered, so this only blocks if the
+		// coordination is closed right away.
+		_ = SendCtx(ctx, reqs, &proto.CoordinateRequest{
+			AddTunnel: &proto.CoordinateRequest_Tunnel{Id: UUIDToByteSlice(tunnelTarget)},
+		})
+	}
+	coordinatee.SetNodeCallback(func(node *Node) {
+		pn, err := NodeToProto(node)
+		if err != nil {
+			c.logger.Critical(ctx, "failed to convert node", slog.Error(err))
+			c.sendErr(err)
+			return
+		}
+		c.mu.Lock()
+		closed := c.closed
+		c.mu.Unlock()
+		if closed {
+			c.logger.Debug(ctx, "ignoring node update on closed coordination")
+			return
+		}
+		err = SendCtx(ctx, reqs, &proto.CoordinateRequest{
+			UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: pn},
+		})
+		if err != nil {
+			c.sendErr(xerrors.Errorf("update self: %w", err))
+		}
+	})
+	go c.respLoop(resps)
+	return c
+}
+
+func (c *Coordination) respLoop(resps <-chan *proto.CoordinateResponse) {
+	defer close(c.respLoopDone)
+	for resp := range resps {
+		if resp.Error != "" {
+			c.sendErr(xerrors.Errorf("coordinator: %s", resp.Error))
+			continue
+		}
+		err := c.handleResponse(resp)
+		if err != nil {
+			c.logger.Debug(c.ctx, "failed to handle coordinate response", slog.Error(err))
+			c.sendErr(err)
+			return
+		}
+	}
+	c.sendErr(xerrors.New("coordination closed"))
+}
+
+func (c *Coordination) handleResponse(resp *proto.CoordinateResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nodes := make([]*Node, 0, len(resp.PeerUpdates))
+	for _, update := range resp.PeerUpdates {
+		id, err := uuid.FromBytes(update.Id)
+		if err != nil {
+			return xerrors.Errorf("parse peer ID: %w", err)
+		}
+		switch update.Kind {
+		case proto.CoordinateResponse_PeerUpdate_NODE:
+			node, err := ProtoToNode(update.Node)
+			if err != nil {
+				return xerrors.Errorf("convert node: %w", err)
+			}
+			c.peers[id] = node.ID
+			nodes = append(nodes, node)
+		case proto.CoordinateResponse_PeerUpdate_DISCONNECTED:
+			nodeID, ok := c.peers[id]
+			if !ok {
+				continue
+			}
+			delete(c.peers, id)
+			err := c.coordinatee.RemovePeer(nodeID)
+			if err != nil {
+				return xerrors.Errorf("remove peer: %w", err)
+			}
+		case proto.CoordinateResponse_PeerUpdate_LOST:
+			// The peer may come back, so keep its node. Stale peers are
+			// cleaned up by the Coordinatee.
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	err := c.coordinatee.UpdateNodes(nodes, false)
+	if err != nil {
+		return xerrors.Errorf("update nodes: %w", err)
+	}
+	return nil
+}
+
+func (c *Coordination) sendErr(err error) {
+	select {
+	case c.errChan <- err:
+	default:
+	}
+}
+
+// Error returns a channel that receives the first error that ends the
+// coordination.
+func (c *Coordination) Error() <-chan error {
+	return c.errChan
+}
+
+// Close gracefully disconnects from the coordinator.
+func (c *Coordination) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(c.ctx, time.Second)
+	defer cancel()
+	err := SendCtx(ctx, c.reqs, &proto.CoordinateRequest{Disconnect: &proto.CoordinateRequest_Disconnect{}})
+	if err != nil {
+		c.cancel()
+		return xerrors.Errorf("send disconnect: %w", err)
+	}
+	// Wait for the coordinator to acknowledge the disconnect by closing the
+	// responses, so the peer isn't considered lost.
+	select {
+	case <-c.respLoopDone:
+	case <-ctx.Done():
+	}
+	c.cancel()
+	return nil
+}
@@ -21,6 +21,8 @@ import (
 	"golang.org/x/xerrors"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/key"
+
+	"github.com/coder/coder/tailnet/proto"
 )
 
 // Coordinator exchanges nodes with agents to establish connections.
@@ -41,6 +43,13 @@ type Coordinator interface {
 	// incoming connections and publishes node updates.
 	// Name is just used for debug information. It can be left blank.
 	ServeAgent(conn net.Conn, id uuid.UUID, name string) error
+	// Coordinate connects a peer with the specified ID to the coordinator.
+	// The peer sends requests on the returned request channel and receives
+	// updates of the peers it has tunnels with on the response channel,
+	// which is closed when the coordinator is done with the peer. Name is
+	// just used for debug information. The TunnelAuth decides which peers
+	// the peer may open tunnels to.
+	Coordinate(ctx context.Context, id uuid.UUID, name string, a TunnelAuth) (chan<- *proto.CoordinateRequest, <-chan *proto.CoordinateResponse)
 	// Close closes the coordinator.
 	Close() error
 }
@@ -115,6 +124,17 @@ func ServeCoordinator(conn net.Conn, updateNodes func(node []*Node) error) (func
 
 const LoggerName = "coord"
 
+const (
+	// RequestBufferSize is the number of requests a peer may queue before the
+	// coordinator processes them.
+	RequestBufferSize = 32
+	// ResponseBufferSize is the number of responses the coordinator queues
+	// for a peer. Responses are queued while holding the coordinator mutex,
+	// so they must not block. Node updates don't come quickly, so 512 should
+	// be plenty for all but the most pathological cases.
+	ResponseBufferSize = 512
+)
+
 // NewCoordinator constructs a new in-memory connection coordinator. This
 // coordinator is incompatible with multiple Coder replicas as all node data is
 // in-memory.
@@ -135,148 +155,331 @@ type coordinator struct {
 	core *core
 }
 
-// core is an in-memory structure of Node and TrackedConn mappings.  Its methods may be called from multiple goroutines;
-// it is protected by a mutex to ensure data stay consistent.
+// core is an in-memory structure of peers and the tunnels between them.  Its methods may be called from multiple
+// goroutines; it is protected by a mutex to ensure data stay consistent.
 type core struct {
 	logger slog.Logger
 	mutex  sync.RWMutex
 	closed bool
 
-	// nodes maps agent and connection IDs their respective node.
-	nodes map[uuid.UUID]*Node
-	// agentSockets maps agent IDs to their open websocket.
-	agentSockets map[uuid.UUID]*TrackedConn
-	// agentToConnectionSockets maps agent IDs to connection IDs of conns that
-	// are subscribed to updates for that agent.
-	agentToConnectionSockets map[uuid.UUID]map[uuid.UUID]*TrackedConn
-
-	// agentNameCache holds a cache of agent names. If one of them disappears,
-	// it's helpful to have a name cached for debugging.
-	agentNameCache *lru.Cache[uuid.UUID, string]
+	// peers maps peer IDs to the currently connected peer.
+	peers map[uuid.UUID]*peer
+	// tunnels holds the tunnels between peers. Peers only see each other's
+	// nodes if a tunnel exists between them.
+	tunnels *tunnelStore
 }
 
 func newCore(logger slog.Logger) *core {
-	nameCache, err := lru.New[uuid.UUID, string](512)
-	if err != nil {
-		panic("make lru cache: " + err.Error())
-	}
-
 	return &core{
-		logger:                   logger,
-		closed:                   false,
-		nodes:                    make(map[uuid.UUID]*Node),
-		agentSockets:             map[uuid.UUID]*TrackedConn{},
-		agentToConnectionSockets: map[uuid.UUID]map[uuid.UUID]*TrackedConn{},
-		agentNameCache:           nameCache,
+		logger:  logger,
+		closed:  false,
+		peers:   make(map[uuid.UUID]*peer),
+		tunnels: newTunnelStore(),
 	}
 }
 
-var ErrWouldBlock = xerrors.New("would block")
-
-type TrackedConn struct {
-	ctx     context.Context
-	cancel  func()
-	conn    net.Conn
-	updates chan []*Node
-	logger  slog.Logger
-
-	// ID is an ephemeral UUID used to uniquely identify the owner of the
-	// connection.
-	ID uuid.UUID
-
-	Name       string
-	Start      int64
-	LastWrite  int64
-	Overwrites int64
+// peer is a connected participant of the coordinator, such as an agent or a
+// client.
+type peer struct {
+	logger     slog.Logger
+	id         uuid.UUID
+	name       string
+	auth       TunnelAuth
+	node       *proto.Node
+	resps      chan<- *proto.CoordinateResponse
+	start      time.Time
+	lastWrite  time.Time
+	overwrites int
 }
 
-func (t *TrackedConn) Enqueue(n []*Node) (err error) {
-	atomic.StoreInt64(&t.LastWrite, time.Now().Unix())
+// send queues a response for the peer. The caller must hold the core mutex.
+func (p *peer) send(resp *proto.CoordinateResponse) {
 	select {
-	case t.updates <- n:
-		return nil
+	case p.resps <- resp:
+		p.lastWrite = time.Now()
 	default:
-		return ErrWouldBlock
+		// queue is backed up for some reason.  This is bad, but we don't want to drop
+		// updates to other peers over it.  Log and move on.
+		p.logger.Error(context.Background(), "response buffer full, dropping update")
 	}
 }
 
-// Close the connection and cancel the context for reading node updates from the queue
-func (t *TrackedConn) Close() error {
-	t.cancel()
-	return t.conn.Close()
+func (p *peer) nodeUpdate() *proto.CoordinateResponse_PeerUpdate {
+	return &proto.CoordinateResponse_PeerUpdate{
+		Id:   UUIDToByteSlice(p.id),
+		Node: p.node,
+		Kind: proto.CoordinateResponse_PeerUpdate_NODE,
+	}
 }
 
-// WriteTimeout is the amount of time we wait to write a node update to a connection before we declare it hung.
-// It is exported so that tests can use it.
-const WriteTimeout = time.Second * 5
+var (
+	ErrClosed          = xerrors.New("coordinator is closed")
+	errDisconnect      = xerrors.New("peer disconnected")
+	errUnauthorized    = xerrors.New("unauthorized tunnel")
+	errPeerOverwritten = xerrors.New("peer was overwritten")
+)
 
-// SendUpdates reads node updates and writes them to the connection.  Ends when writes hit an error or context is
-// canceled.
-func (t *TrackedConn) SendUpdates() {
+// Coordinate connects a peer to the coordinator. Requests are read from the
+// returned request channel until it is closed or ctx is canceled. The
+// response channel is closed when the coordinator is done with the peer.
+func (c *coordinator) Coordinate(
+	ctx context.Context, id uuid.UUID, name string, a TunnelAuth,
+) (
+	chan<- *proto.CoordinateRequest, <-chan *proto.CoordinateResponse,
+) {
+	logger := c.core.logger.With(slog.F("peer_id", id), slog.F("peer_name", name))
+	reqs := make(chan *proto.CoordinateRequest, RequestBufferSize)
+	resps := make(chan *proto.CoordinateResponse, ResponseBufferSize)
+
+	now := time.Now()
+	p := &peer{
+		logger:    logger,
+		id:        id,
+		name:      name,
+		auth:      a,
+		resps:     resps,
+		start:     now,
+		lastWrite: now,
+	}
+	err := c.core.initPeer(p)
+	if err != nil {
+		if xerrors.Is(err, ErrClosed) {
+			logger.Debug(ctx, "coordinate failed: coordinator is closed")
+		} else {
+			logger.Critical(ctx, "coordinate failed", slog.Error(err))
+		}
+		close(resps)
+		// Drain requests so the caller never blocks on a peer that will
+		// never be served.
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-reqs:
+					if !ok {
+						return
+					}
+				}
+			}
+		}()
+		return reqs, resps
+	}
+	go c.handleRequests(ctx, p, reqs)
+	return reqs, resps
+}
+
+func (c *coordinator) handleRequests(ctx context.Context, p *peer, reqs <-chan *proto.CoordinateRequest) {
 	for {
 		select {
-		case <-t.ctx.Done():
-			t.logger.Debug(t.ctx, "done sending updates")
+		case <-ctx.Done():
+			p.logger.Debug(ctx, "peer context canceled")
+			c.core.lostPeer(p)
 			return
-		case nodes := <-t.updates:
-			data, err := json.Marshal(nodes)
-			if err != nil {
-				t.logger.Error(t.ctx, "unable to marshal nodes update", slog.Error(err), slog.F("nodes", nodes))
-				return
-			}
-
-			// Set a deadline so that hung connections don't put back pressure on the system.
-			// Node updates are tiny, so even the dinkiest connection can handle them if it's not hung.
-			err = t.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
-			if err != nil {
-				// often, this is just because the connection is closed/broken, so only log at debug.
-				t.logger.Debug(t.ctx, "unable to set write deadline", slog.Error(err))
-				_ = t.Close()
-				return
-			}
-			_, err = t.conn.Write(data)
-			if err != nil {
-				// often, this is just because the connection is closed/broken, so only log at debug.
-				t.logger.Debug(t.ctx, "could not write nodes to connection", slog.Error(err), slog.F("nodes", nodes))
-				_ = t.Close()
+		case req, ok := <-reqs:
+			if !ok {
+				p.logger.Debug(ctx, "peer request channel closed")
+				c.core.lostPeer(p)
 				return
 			}
-			t.logger.Debug(t.ctx, "wrote nodes", slog.F("nodes", nodes))
-
-			// nhooyr.io/websocket has a bugged implementation of deadlines on a websocket net.Conn.  What they are
-			// *supposed* to do is set a deadline for any subsequent writes to complete, otherwise the call to Write()
-			// fails.  What nhooyr.io/websocket does is set a timer, after which it expires the websocket write context.
-			// If this timer fires, then the next write will fail *even if we set a new write deadline*.  So, after
-			// our successful write, it is important that we reset the deadline before it fires.
-			err = t.conn.SetWriteDeadline(time.Time{})
+			err := c.core.handleRequest(p, req)
 			if err != nil {
-				// often, this is just because the connection is closed/broken, so only log at debug.
-				t.logger.Debug(t.ctx, "unable to extend write deadline", slog.Error(err))
-				_ = t.Close()
+				if !xerrors.Is(err, errDisconnect) {
+					p.logger.Debug(ctx, "stopped handling peer requests", slog.Error(err))
+				}
 				return
 			}
 		}
 	}
 }
 
-func NewTrackedConn(ctx context.Context, cancel func(), conn net.Conn, id uuid.UUID, logger slog.Logger, overwrites int64) *TrackedConn {
-	// buffer updates so they don't block, since we hold the
-	// coordinator mutex while queuing.  Node updates don't
-	// come quickly, so 512 should be plenty for all but
-	// the most pathological cases.
-	updates := make(chan []*Node, 512)
-	now := time.Now().Unix()
-	return &TrackedConn{
-		ctx:        ctx,
-		conn:       conn,
-		cancel:     cancel,
-		updates:    updates,
-		logger:     logger,
-		ID:         id,
-		Start:      now,
-		LastWrite:  now,
-		Overwrites: overwrites,
+// initPeer adds the peer and sends it the nodes of the peers it has tunnels
+// with.  It is one function that does two things because it is critical that
+// we hold the mutex for both things, lest we miss some updates.
+func (c *core) initPeer(p *peer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	// If an old connection with the same ID exists, we close it to avoid any
+	// leaks. This shouldn't ever occur because we expect one agent to be
+	// running, but it's possible for a race condition to happen when an agent
+	// is disconnected and attempts to reconnect before the server realizes the
+	// old connection is dead.
+	if old, ok := c.peers[p.id]; ok {
+		p.overwrites = old.overwrites + 1
+		// Keep the last known node until the new connection sends its own,
+		// so peers that connect in the meantime can still reach it.
+		p.node = old.node
+		c.tunnels.removeAll(old.id)
+		close(old.resps)
+		p.logger.Debug(context.Background(), "overwrote existing peer", slog.F("overwrites", p.overwrites))
+	}
+
+	// Publish the nodes of all peers that have tunnels to this one, for
+	// example clients waiting for an agent to connect.
+	var updates []*proto.CoordinateResponse_PeerUpdate
+	for _, tp := range c.tunnels.findTunnelPeers(p.id) {
+		other, ok := c.peers[tp]
+		if !ok || other.node == nil {
+			continue
+		}
+		updates = append(updates, other.nodeUpdate())
+	}
+	c.peers[p.id] = p
+	if len(updates) > 0 {
+		p.send(&proto.CoordinateResponse{PeerUpdates: updates})
+		p.logger.Debug(context.Background(), "sent initial peer nodes", slog.F("count", len(updates)))
+	}
+	p.logger.Debug(context.Background(), "added peer")
+	return nil
+}
+
+func (c *core) handleRequest(p *peer, req *proto.CoordinateRequest) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	if c.peers[p.id] != p {
+		return errPeerOverwritten
+	}
+
+	if req.UpdateSelf != nil {
+		c.updateSelfLocked(p, req.UpdateSelf.GetNode())
+	}
+	if req.AddTunnel != nil {
+		dst, err := uuid.FromBytes(req.AddTunnel.GetId())
+		if err != nil {
+			c.rejectLocked(p, fmt.Sprintf("invalid tunnel ID: %s", err))
+			return xerrors.Errorf("parse tunnel ID: %w", err)
+		}
+		if !p.auth.Authorize(dst) {
+			c.rejectLocked(p, fmt.Sprintf("unauthorized tunnel to %s", dst))
+			return errUnauthorized
+		}
+		c.addTunnelLocked(p, dst)
+	}
+	if req.RemoveTunnel != nil {
+		dst, err := uuid.FromBytes(req.RemoveTunnel.GetId())
+		if err != nil {
+			c.rejectLocked(p, fmt.Sprintf("invalid tunnel ID: %s", err))
+			return xerrors.Errorf("parse tunnel ID: %w", err)
+		}
+		c.removeTunnelLocked(p, dst)
+	}
+	if req.Disconnect != nil {
+		c.removePeerLocked(p, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, "disconnect")
+		return errDisconnect
+	}
+	return nil
+}
+
+func (c *core) updateSelfLocked(p *peer, node *proto.Node) {
+	p.node = node
+	p.logger.Debug(context.Background(), "got peer node update")
+	// All tunnel peers receive the same response, so it is only encoded once
+	// per peer connection rather than once per node in a full list.
+	resp := &proto.CoordinateResponse{
+		PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{p.nodeUpdate()},
+	}
+	for _, tp := range c.tunnels.findTunnelPeers(p.id) {
+		other, ok := c.peers[tp]
+		if !ok {
+			continue
+		}
+		other.send(resp)
+	}
+}
+
+func (c *core) addTunnelLocked(p *peer, dst uuid.UUID) {
+	c.tunnels.add(p.id, dst)
+	p.logger.Debug(context.Background(), "added tunnel", slog.F("dst_id", dst))
+	other, ok := c.peers[dst]
+	if !ok {
+		return
+	}
+	if p.node != nil {
+		other.send(&proto.CoordinateResponse{
+			PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{p.nodeUpdate()},
+		})
+	}
+	if other.node != nil {
+		p.send(&proto.CoordinateResponse{
+			PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{other.nodeUpdate()},
+		})
+	}
+}
+
+func (c *core) removeTunnelLocked(p *peer, dst uuid.UUID) {
+	c.tunnels.remove(p.id, dst)
+	p.logger.Debug(context.Background(), "removed tunnel", slog.F("dst_id", dst))
+	// The peers may still see each other through a tunnel in the other
+	// direction.
+	if c.tunnels.tunnelExists(p.id, dst) {
+		return
+	}
+	other, ok := c.peers[dst]
+	if !ok {
+		return
+	}
+	other.send(&proto.CoordinateResponse{
+		PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+			Id:     UUIDToByteSlice(p.id),
+			Kind:   proto.CoordinateResponse_PeerUpdate_DISCONNECTED,
+			Reason: "tunnel removed",
+		}},
+	})
+	p.send(&proto.CoordinateResponse{
+		PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+			Id:     UUIDToByteSlice(dst),
+			Kind:   proto.CoordinateResponse_PeerUpdate_DISCONNECTED,
+			Reason: "tunnel removed",
+		}},
+	})
+}
+
+// rejectLocked sends an error to the peer and removes it.
+func (c *core) rejectLocked(p *peer, reason string) {
+	p.logger.Warn(context.Background(), "rejected peer request", slog.F("reason", reason))
+	p.send(&proto.CoordinateResponse{Error: reason})
+	c.removePeerLocked(p, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, "rejected request")
+}
+
+// lostPeer removes a peer whose connection went away without a disconnect.
+func (c *core) lostPeer(p *peer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed || c.peers[p.id] != p {
+		return
 	}
+	c.removePeerLocked(p, proto.CoordinateResponse_PeerUpdate_LOST, "lost")
+}
+
+// removePeerLocked notifies the tunnel peers of p and removes it along with
+// the tunnels it initiated. Tunnels to p are kept, so peers that reconnect,
+// such as agents, receive the nodes of the peers that want to reach them.
+func (c *core) removePeerLocked(p *peer, kind proto.CoordinateResponse_PeerUpdate_Kind, reason string) {
+	resp := &proto.CoordinateResponse{
+		PeerUpdates: []*proto.CoordinateResponse_PeerUpdate{{
+			Id:     UUIDToByteSlice(p.id),
+			Kind:   kind,
+			Reason: reason,
+		}},
+	}
+	for _, tp := range c.tunnels.findTunnelPeers(p.id) {
+		other, ok := c.peers[tp]
+		if !ok {
+			continue
+		}
+		other.send(resp)
+	}
+	c.tunnels.removeAll(p.id)
+	delete(c.peers, p.id)
+	close(p.resps)
+	p.logger.Debug(context.Background(), "removed peer", slog.F("kind", kind.String()))
 }
 
 // Node returns an in-memory node by ID.
@@ -288,7 +491,16 @@ func (c *coordinator) Node(id uuid.UUID) *Node {
 func (c *core) node(id uuid.UUID) *Node {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	return c.nodes[id]
+	p, ok := c.peers[id]
+	if !ok || p.node == nil {
+		return nil
+	}
+	node, err := ProtoToNode(p.node)
+	if err != nil {
+		p.logger.Critical(context.Background(), "failed to convert node", slog.Error(err))
+		return nil
+	}
+	return node
 }
 
 func (c *coordinator) NodeCount() int {
@@ -298,7 +510,13 @@ func (c *coordinator) NodeCount() int {
 func (c *core) nodeCount() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	return len(c.nodes)
+	count := 0
+	for _, p := range c.peers {
+		if p.node != nil {
+			count++
+		}
+	}
+	return count
 }
 
 func (c *coordinator) AgentCount() int {
@@ -308,336 +526,334 @@ func (c *coordinator) AgentCount() int {
 func (c *core) agentCount() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	return len(c.agentSockets)
+	count := 0
+	for _, p := range c.peers {
+		if _, ok := p.auth.(AgentTunnelAuth); ok {
+			count++
+		}
+	}
+	return count
 }
 
 // ServeClient accepts a WebSocket connection that wants to connect to an agent
-// with the specified ID.
+// with the specified ID. It speaks version 1 of the coordination protocol.
 func (c *coordinator) ServeClient(conn net.Conn, id uuid.UUID, agent uuid.UUID) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	logger := c.core.clientLogger(id, agent)
+	logger := c.core.logger.With(slog.F("client_id", id), slog.F("agent_id", agent))
+	return ServeClientV1(ctx, logger, c, conn, id, agent)
+}
+
+// ServeClientV1 serves a client speaking version 1 of the coordination
+// protocol on conn by connecting it to the coordinator as a peer with a
+// tunnel to the agent.
+func ServeClientV1(ctx context.Context, logger slog.Logger, c Coordinator, conn net.Conn, id uuid.UUID, agent uuid.UUID) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	logger.Debug(ctx, "coordinating client")
-	tc, err := c.core.initAndTrackClient(ctx, cancel, conn, id, agent)
+	reqs, resps := c.Coordinate(ctx, id, id.String(), ClientTunnelAuth{AgentID: agent})
+	err := SendCtx(ctx, reqs, &proto.CoordinateRequest{
+		AddTunnel: &proto.CoordinateRequest_Tunnel{Id: UUIDToByteSlice(agent)},
+	})
 	if err != nil {
+		// can only be a context error, no need to log here.
 		return err
 	}
-	defer c.core.clientDisconnected(id, agent)
+	return serveV1(ctx, cancel, logger, conn, reqs, resps)
+}
 
-	// On this goroutine, we read updates from the client and publish them.  We start a second goroutine
-	// to write updates back to the client.
-	go tc.SendUpdates()
+// ServeAgent accepts a WebSocket connection to an agent that
+// listens to incoming connections and publishes node updates. It speaks
+// version 1 of the coordination protocol.
+func (c *coordinator) ServeAgent(conn net.Conn, id uuid.UUID, name string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := c.core.logger.With(slog.F("agent_id", id), slog.F("name", name))
+	return ServeAgentV1(ctx, logger, c, conn, id, name)
+}
+
+// ServeAgentV1 serves an agent speaking version 1 of the coordination
+// protocol on conn by connecting it to the coordinator as a peer.
+func ServeAgentV1(ctx context.Context, logger slog.Logger, c Coordinator, conn net.Conn, id uuid.UUID, name string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	logger.Debug(ctx, "coordinating agent")
+	reqs, resps := c.Coordinate(ctx, id, name, AgentTunnelAuth{})
+	return serveV1(ctx, cancel, logger, conn, reqs, resps)
+}
+
+// serveV1 translates between version 1 of the coordination protocol on conn
+// and a peer of the coordinator. Nodes read from conn update the peer, and
+// node updates of its tunnel peers are written to conn as a JSON list.
+func serveV1(
+	ctx context.Context, cancel context.CancelFunc, logger slog.Logger, conn net.Conn,
+	reqs chan<- *proto.CoordinateRequest, resps <-chan *proto.CoordinateResponse,
+) error {
+	tc := NewTrackedConn(ctx, cancel, conn, uuid.Nil, logger, 0)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		// The coordinator closes resps when it is done with the peer, for
+		// example when the peer is overwritten or the coordinator closes.
+		defer tc.Close()
+		for resp := range resps {
+			nodes := make([]*Node, 0, len(resp.PeerUpdates))
+			for _, update := range resp.PeerUpdates {
+				if update.Kind != proto.CoordinateResponse_PeerUpdate_NODE {
+					// Version 1 only knows about nodes, peers that go away
+					// are cleaned up by the connection itself.
+					continue
+				}
+				node, err := ProtoToNode(update.Node)
+				if err != nil {
+					logger.Critical(ctx, "failed to convert node", slog.Error(err))
+					continue
+				}
+				nodes = append(nodes, node)
+			}
+			if len(nodes) == 0 {
+				continue
+			}
+			if !tc.writeNodes(nodes) {
+				return
+			}
+		}
+		logger.Debug(ctx, "coordinator closed responses")
+	}()
+	defer func() {
+		cancel()
+		<-writerDone
+	}()
 
 	decoder := json.NewDecoder(conn)
 	for {
-		err := c.handleNextClientMessage(id, agent, decoder)
+		var node Node
+		err := decoder.Decode(&node)
 		if err != nil {
-			logger.Debug(ctx, "unable to read client update; closed conn?", slog.Error(err))
+			logger.Debug(ctx, "unable to read node update; closed conn?", slog.Error(err))
+			_ = SendCtx(ctx, reqs, &proto.CoordinateRequest{Disconnect: &proto.CoordinateRequest_Disconnect{}})
 			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, context.Canceled) {
 				return nil
 			}
-			return xerrors.Errorf("handle next client message: %w", err)
+			return xerrors.Errorf("read json: %w", err)
 		}
-	}
-}
-
-func (c *core) clientLogger(id, agent uuid.UUID) slog.Logger {
-	return c.logger.With(slog.F("client_id", id), slog.F("agent_id", agent))
-}
-
-// initAndTrackClient creates a TrackedConn for the client, and sends any initial Node updates if we have any.  It is
-// one function that does two things because it is critical that we hold the mutex for both things, lest we miss some
-// updates.
-func (c *core) initAndTrackClient(
-	ctx context.Context, cancel func(), conn net.Conn, id, agent uuid.UUID,
-) (
-	*TrackedConn, error,
-) {
-	logger := c.clientLogger(id, agent)
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	if c.closed {
-		return nil, xerrors.New("coordinator is closed")
-	}
-	tc := NewTrackedConn(ctx, cancel, conn, id, logger, 0)
-
-	// When a new connection is requested, we update it with the latest
-	// node of the agent. This allows the connection to establish.
-	node, ok := c.nodes[agent]
-	if ok {
-		err := tc.Enqueue([]*Node{node})
-		// this should never error since we're still the only goroutine that
-		// knows about the TrackedConn.  If we hit an error something really
-		// wrong is happening
+		logger.Debug(ctx, "got node update", slog.F("node", node))
+		pn, err := NodeToProto(&node)
+		if err != nil {
+			return xerrors.Errorf("convert node: %w", err)
+		}
+		err = SendCtx(ctx, reqs, &proto.CoordinateRequest{
+			UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: pn},
+		})
 		if err != nil {
-			logger.Critical(ctx, "unable to queue initial node", slog.Error(err))
-			return nil, err
+			return nil
 		}
 	}
+}
 
-	// Insert this connection into a map so the agent
-	// can publish node updates.
-	connectionSockets, ok := c.agentToConnectionSockets[agent]
-	if !ok {
-		connectionSockets = map[uuid.UUID]*TrackedConn{}
-		c.agentToConnectionSockets[agent] = connectionSockets
+// SendCtx sends a value on the channel, or returns the context error if the
+// context is canceled first.
+func SendCtx[A any](ctx context.Context, c chan<- A, a A) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c <- a:
+		return nil
 	}
-	connectionSockets[id] = tc
-	logger.Debug(ctx, "added tracked connection")
-	return tc, nil
 }
 
-func (c *core) clientDisconnected(id, agent uuid.UUID) {
-	logger := c.clientLogger(id, agent)
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	// Clean all traces of this connection from the map.
-	delete(c.nodes, id)
-	logger.Debug(context.Background(), "deleted client node")
-	connectionSockets, ok := c.agentToConnectionSockets[agent]
-	if !ok {
-		return
-	}
-	delete(connectionSockets, id)
-	logger.Debug(context.Background(), "deleted client connectionSocket from map")
-	if len(connectionSockets) != 0 {
-		return
-	}
-	delete(c.agentToConnectionSockets, agent)
-	logger.Debug(context.Background(), "deleted last client connectionSocket from map")
+// UUIDToByteSlice returns the bytes of the UUID.
+func UUIDToByteSlice(u uuid.UUID) []byte {
+	b := [16]byte(u)
+	o := make([]byte, 16)
+	copy(o, b[:])
+	return o
 }
 
-func (c *coordinator) handleNextClientMessage(id, agent uuid.UUID, decoder *json.Decoder) error {
-	logger := c.core.clientLogger(id, agent)
-	var node Node
-	err := decoder.Decode(&node)
-	if err != nil {
-		return xerrors.Errorf("read json: %w", err)
-	}
-	logger.Debug(context.Background(), "got client node update", slog.F("node", node))
-	return c.core.clientNodeUpdate(id, agent, &node)
+// Close closes all of the open connections in the coordinator and stops the
+// coordinator from accepting new connections.
+func (c *coordinator) Close() error {
+	return c.core.close()
 }
 
-func (c *core) clientNodeUpdate(id, agent uuid.UUID, node *Node) error {
-	logger := c.clientLogger(id, agent)
+func (c *core) close() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	// Update the node of this client in our in-memory map. If an agent entirely
-	// shuts down and reconnects, it needs to be aware of all clients attempting
-	// to establish connections.
-	c.nodes[id] = node
-
-	agentSocket, ok := c.agentSockets[agent]
-	if !ok {
-		logger.Debug(context.Background(), "no agent socket, unable to send node")
+	if c.closed {
 		return nil
 	}
-
-	err := agentSocket.Enqueue([]*Node{node})
-	if err != nil {
-		return xerrors.Errorf("Enqueue node: %w", err)
+	c.closed = true
+	for id, p := range c.peers {
+		close(p.resps)
+		delete(c.peers, id)
 	}
-	logger.Debug(context.Background(), "enqueued node to agent")
 	return nil
 }
 
-func (c *core) agentLogger(id uuid.UUID) slog.Logger {
-	return c.logger.With(slog.F("agent_id", id))
+func (c *coordinator) ServeHTTPDebug(w http.ResponseWriter, r *http.Request) {
+	c.core.serveHTTPDebug(w, r)
 }
 
-// ServeAgent accepts a WebSocket connection to an agent that
-// listens to incoming connections and publishes node updates.
-func (c *coordinator) ServeAgent(conn net.Conn, id uuid.UUID, name string) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	logger := c.core.agentLogger(id)
-	logger.Debug(context.Background(), "coordinating agent")
-	// This uniquely identifies a connection that belongs to this goroutine.
-	unique := uuid.New()
-	tc, err := c.core.initAndTrackAgent(ctx, cancel, conn, id, unique, name)
-	if err != nil {
-		return err
-	}
+func (c *core) serveHTTPDebug(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	// On this goroutine, we read updates from the agent and publish them.  We start a second goroutine
-	// to write updates back to the agent.
-	go tc.SendUpdates()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 
-	defer c.core.agentDisconnected(id, unique)
+	_, _ = fmt.Fprintln(w, "<h1>in-memory wireguard coordinator debug</h1>")
 
-	decoder := json.NewDecoder(conn)
-	for {
-		err := c.handleNextAgentMessage(id, decoder)
-		if err != nil {
-			logger.Debug(ctx, "unable to read agent update; closed conn?", slog.Error(err))
-			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, context.Canceled) {
-				return nil
-			}
-			return xerrors.Errorf("handle next agent message: %w", err)
-		}
+	now := time.Now()
+	peers := make([]*peer, 0, len(c.peers))
+	for _, p := range c.peers {
+		peers = append(peers, p)
 	}
-}
-
-func (c *core) agentDisconnected(id, unique uuid.UUID) {
-	logger := c.agentLogger(id)
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// Only delete the connection if it's ours. It could have been
-	// overwritten.
-	if idConn, ok := c.agentSockets[id]; ok && idConn.ID == unique {
-		delete(c.agentSockets, id)
-		delete(c.nodes, id)
-		logger.Debug(context.Background(), "deleted agent socket and node")
+	slices.SortFunc(peers, func(a, b *peer) bool {
+		return a.name < b.name
+	})
+	_, _ = fmt.Fprintf(w, "<h2 id=peers><a href=#peers>#</a> peers: total %d</h2>\n", len(peers))
+	_, _ = fmt.Fprintln(w, "<ul>")
+	for _, p := range peers {
+		_, _ = fmt.Fprintf(w, "<li style=\"margin-top:4px\"><b>%s</b> (<code>%s</code>): created %v ago, write %v ago, overwrites %d, node %t </li>\n",
+			p.name,
+			p.id.String(),
+			now.Sub(p.start).Round(time.Second),
+			now.Sub(p.lastWrite).Round(time.Second),
+			p.overwrites,
+			p.node != nil,
+		)
 	}
-}
+	_, _ = fmt.Fprintln(w, "</ul>")
 
-// initAndTrackAgent creates a TrackedConn for the agent, and sends any initial nodes updates if we have any.  It is
-// one function that does two things because it is critical that we hold the mutex for both things, lest we miss some
-// updates.
-func (c *core) initAndTrackAgent(ctx context.Context, cancel func(), conn net.Conn, id, unique uuid.UUID, name string) (*TrackedConn, error) {
-	logger := c.logger.With(slog.F("agent_id", id))
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	if c.closed {
-		return nil, xerrors.New("coordinator is closed")
-	}
-
-	overwrites := int64(0)
-	// If an old agent socket is connected, we Close it to avoid any leaks. This
-	// shouldn't ever occur because we expect one agent to be running, but it's
-	// possible for a race condition to happen when an agent is disconnected and
-	// attempts to reconnect before the server realizes the old connection is
-	// dead.
-	oldAgentSocket, ok := c.agentSockets[id]
-	if ok {
-		overwrites = oldAgentSocket.Overwrites + 1
-		_ = oldAgentSocket.Close()
-	}
-	tc := NewTrackedConn(ctx, cancel, conn, unique, logger, overwrites)
-	c.agentNameCache.Add(id, name)
-
-	sockets, ok := c.agentToConnectionSockets[id]
-	if ok {
-		// Publish all nodes that want to connect to the
-		// desired agent ID.
-		nodes := make([]*Node, 0, len(sockets))
-		for targetID := range sockets {
-			node, ok := c.nodes[targetID]
-			if !ok {
-				continue
-			}
-			nodes = append(nodes, node)
-		}
-		err := tc.Enqueue(nodes)
-		// this should never error since we're still the only goroutine that
-		// knows about the TrackedConn.  If we hit an error something really
-		// wrong is happening
-		if err != nil {
-			logger.Critical(ctx, "unable to queue initial nodes", slog.Error(err))
-			return nil, err
+	tunnels := c.tunnels.htmlDebug()
+	slices.SortFunc(tunnels, func(a, b HTMLTunnel) bool {
+		if a.Src == b.Src {
+			return a.Dst.String() < b.Dst.String()
 		}
-		logger.Debug(ctx, "wrote initial client(s) to agent", slog.F("nodes", nodes))
+		return a.Src.String() < b.Src.String()
+	})
+	_, _ = fmt.Fprintf(w, "<h2 id=tunnels><a href=#tunnels>#</a> tunnels: total %d</h2>\n", len(tunnels))
+	_, _ = fmt.Fprintln(w, "<ul>")
+	for _, t := range tunnels {
+		_, _ = fmt.Fprintf(w, "<li><code>%s</code> → <code>%s</code></li>\n", t.Src.String(), t.Dst.String())
 	}
-
-	c.agentSockets[id] = tc
-	logger.Debug(ctx, "added agent socket")
-	return tc, nil
+	_, _ = fmt.Fprintln(w, "</ul>")
 }
 
-func (c *coordinator) handleNextAgentMessage(id uuid.UUID, decoder *json.Decoder) error {
-	logger := c.core.agentLogger(id)
-	var node Node
-	err := decoder.Decode(&node)
-	if err != nil {
-		return xerrors.Errorf("read json: %w", err)
-	}
-	logger.Debug(context.Background(), "decoded agent node", slog.F("node", node))
-	return c.core.agentNodeUpdate(id, &node)
-}
+var ErrWouldBlock = xerrors.New("would block")
 
-func (c *core) agentNodeUpdate(id uuid.UUID, node *Node) error {
-	logger := c.agentLogger(id)
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	c.nodes[id] = node
-	connectionSockets, ok := c.agentToConnectionSockets[id]
-	if !ok {
-		logger.Debug(context.Background(), "no client sockets; unable to send node")
-		return nil
-	}
+type TrackedConn struct {
+	ctx     context.Context
+	cancel  func()
+	conn    net.Conn
+	updates chan []*Node
+	logger  slog.Logger
 
-	// Publish the new node to every listening socket.
-	for clientID, connectionSocket := range connectionSockets {
-		err := connectionSocket.Enqueue([]*Node{node})
-		if err == nil {
-			logger.Debug(context.Background(), "enqueued agent node to client",
-				slog.F("client_id", clientID))
-		} else {
-			// queue is backed up for some reason.  This is bad, but we don't want to drop
-			// updates to other clients over it.  Log and move on.
-			logger.Error(context.Background(), "failed to Enqueue",
-				slog.F("client_id", clientID), slog.Error(err))
-		}
-	}
-	return nil
-}
+	// ID is an ephemeral UUID used to uniquely identify the owner of the
+	// connection.
+	ID uuid.UUID
 
-// Close closes all of the open connections in the coordinator and stops the
-// coordinator from accepting new connections.
-func (c *coordinator) Close() error {
-	return c.core.close()
+	Name       string
+	Start      int64
+	LastWrite  int64
+	Overwrites int64
 }
 
-func (c *core) close() error {
-	c.mutex.Lock()
-	if c.closed {
-		c.mutex.Unlock()
+func (t *TrackedConn) Enqueue(n []*Node) (err error) {
+	atomic.StoreInt64(&t.LastWrite, time.Now().Unix())
+	select {
+	case t.updates <- n:
 		return nil
+	default:
+		return ErrWouldBlock
 	}
-	c.closed = true
+}
 
-	wg := sync.WaitGroup{}
+// Close the connection and cancel the context for reading node updates from the queue
+func (t *TrackedConn) Close() error {
+	t.cancel()
+	return t.conn.Close()
+}
 
-	wg.Add(len(c.agentSockets))
-	for _, socket := range c.agentSockets {
-		socket := socket
-		go func() {
-			_ = socket.Close()
-			wg.Done()
-		}()
-	}
+// WriteTimeout is the amount of time we wait to write a node update to a connection before we declare it hung.
+// It is exported so that tests can use it.
+const WriteTimeout = time.Second * 5
 
-	for _, connMap := range c.agentToConnectionSockets {
-		wg.Add(len(connMap))
-		for _, socket := range connMap {
-			socket := socket
-			go func() {
-				_ = socket.Close()
-				wg.Done()
-			}()
+// SendUpdates reads node updates and writes them to the connection.  Ends when writes hit an error or context is
+// canceled.
+func (t *TrackedConn) SendUpdates() {
+	for {
+		select {
+		case <-t.ctx.Done():
+			t.logger.Debug(t.ctx, "done sending updates")
+			return
+		case nodes := <-t.updates:
+			if !t.writeNodes(nodes) {
+				return
+			}
 		}
 	}
-
-	c.mutex.Unlock()
-
-	wg.Wait()
-	return nil
-}
-
-func (c *coordinator) ServeHTTPDebug(w http.ResponseWriter, r *http.Request) {
-	c.core.serveHTTPDebug(w, r)
 }
 
-func (c *core) serveHTTPDebug(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// writeNodes writes the nodes to the connection as a JSON list. The
+// connection is closed if the write fails.
+func (t *TrackedConn) writeNodes(nodes []*Node) bool {
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		t.logger.Error(t.ctx, "unable to marshal nodes update", slog.Error(err), slog.F("nodes", nodes))
+		return false
+	}
 
-	_, _ = fmt.Fprintln(w, "<h1>in-memory wireguard coordinator debug</h1>")
+	// Set a deadline so that hung connections don't put back pressure on the system.
+	// Node updates are tiny, so even the dinkiest connection can handle them if it's not hung.
+	err = t.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	if err != nil {
+		// often, this is just because the connection is closed/broken, so only log at debug.
+		t.logger.Debug(t.ctx, "unable to set write deadline", slog.Error(err))
+		_ = t.Close()
+		return false
+	}
+	_, err = t.conn.Write(data)
+	if err != nil {
+		// often, this is just because the connection is closed/broken, so only log at debug.
+		t.logger.Debug(t.ctx, "could not write nodes to connection", slog.Error(err), slog.F("nodes", nodes))
+		_ = t.Close()
+		return false
+	}
+	t.logger.Debug(t.ctx, "wrote nodes", slog.F("nodes", nodes))
+
+	// nhooyr.io/websocket has a bugged implementation of deadlines on a websocket net.Conn.  What they are
+	// *supposed* to do is set a deadline for any subsequent writes to complete, otherwise the call to Write()
+	// fails.  What nhooyr.io/websocket does is set a timer, after which it expires the websocket write context.
+	// If this timer fires, then the next write will fail *even if we set a new write deadline*.  So, after
+	// our successful write, it is important that we reset the deadline before it fires.
+	err = t.conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		// often, this is just because the connection is closed/broken, so only log at debug.
+		t.logger.Debug(t.ctx, "unable to extend write deadline", slog.Error(err))
+		_ = t.Close()
+		return false
+	}
+	return true
+}
 
-	CoordinatorHTTPDebug(c.agentSockets, c.agentToConnectionSockets, c.agentNameCache)(w, r)
+func NewTrackedConn(ctx context.Context, cancel func(), conn net.Conn, id uuid.UUID, logger slog.Logger, overwrites int64) *TrackedConn {
+	// buffer updates so they don't block, since we hold the
+	// coordinator mutex while queuing.  Node updates don't
+	// come quickly, so 512 should be plenty for all but
+	// the most pathological cases.
+	updates := make(chan []*Node, 512)
+	now := time.Now().Unix()
+	return &TrackedConn{
+		ctx:        ctx,
+		conn:       conn,
+		cancel:     cancel,
+		updates:    updates,
+		logger:     logger,
+		ID:         id,
+		Start:      now,
+		LastWrite:  now,
+		Overwrites: overwrites,
+	}
 }
 
 func CoordinatorHTTPDebug(
@@ -6,10 +6,12 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"nhooyr.io/websocket"
+	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
@@ -19,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/proto"
 	"github.com/coder/coder/testutil"
 )
 
@@ -355,6 +358,272 @@ func TestCoordinator_AgentUpdateWhileClientConnects(t *testing.T) {
 	require.Equal(t, 1, cNodes[0].PreferredDERP)
 }
 
+func TestCoordinator_Tunnels(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AgentWithClient", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		coordinator := tailnet.NewCoordinator(logger)
+		defer coordinator.Close()
+
+		agentID := uuid.New()
+		agentReqs, agentResps := coordinator.Coordinate(ctx, agentID, "agent", tailnet.AgentTunnelAuth{})
+		sendUpdateSelf(ctx, t, agentReqs, 1)
+
+		clientID := uuid.New()
+		clientReqs, clientResps := coordinator.Coordinate(ctx, clientID, "client", tailnet.ClientTunnelAuth{AgentID: agentID})
+		sendUpdateSelf(ctx, t, clientReqs, 2)
+		require.NoError(t, tailnet.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			AddTunnel: &proto.CoordinateRequest_Tunnel{Id: tailnet.UUIDToByteSlice(agentID)},
+		}))
+
+		// Both peers receive each other's node once the tunnel exists.
+		update := recvUpdate(ctx, t, clientResps)
+		require.Equal(t, agentID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_NODE, update.Kind)
+		require.EqualValues(t, 1, update.Node.Id)
+		update = recvUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.EqualValues(t, 2, update.Node.Id)
+
+		// Only the changed node is sent.
+		sendUpdateSelf(ctx, t, agentReqs, 3)
+		update = recvUpdate(ctx, t, clientResps)
+		require.EqualValues(t, 3, update.Node.Id)
+
+		require.NoError(t, tailnet.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			Disconnect: &proto.CoordinateRequest_Disconnect{},
+		}))
+		update = recvUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, update.Kind)
+		requireClosed(ctx, t, clientResps)
+	})
+
+	t.Run("ClientLost", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		coordinator := tailnet.NewCoordinator(logger)
+		defer coordinator.Close()
+
+		agentID := uuid.New()
+		_, agentResps := coordinator.Coordinate(ctx, agentID, "agent", tailnet.AgentTunnelAuth{})
+
+		clientCtx, clientCancel := context.WithCancel(ctx)
+		clientID := uuid.New()
+		clientReqs, clientResps := coordinator.Coordinate(clientCtx, clientID, "client", tailnet.ClientTunnelAuth{AgentID: agentID})
+		require.NoError(t, tailnet.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			AddTunnel: &proto.CoordinateRequest_Tunnel{Id: tailnet.UUIDToByteSlice(agentID)},
+		}))
+		sendUpdateSelf(ctx, t, clientReqs, 2)
+		update := recvUpdate(ctx, t, agentResps)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_NODE, update.Kind)
+
+		clientCancel()
+		update = recvUpdate(ctx, t, agentResps)
+		require.Equal(t, clientID[:], update.Id)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_LOST, update.Kind)
+		requireClosed(ctx, t, clientResps)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		coordinator := tailnet.NewCoordinator(logger)
+		defer coordinator.Close()
+
+		// A peer with a node that the client is not allowed to see.
+		otherID := uuid.New()
+		otherReqs, _ := coordinator.Coordinate(ctx, otherID, "other", tailnet.AgentTunnelAuth{})
+		sendUpdateSelf(ctx, t, otherReqs, 1)
+
+		clientReqs, clientResps := coordinator.Coordinate(ctx, uuid.New(), "client", tailnet.ClientTunnelAuth{AgentID: uuid.New()})
+		require.NoError(t, tailnet.SendCtx(ctx, clientReqs, &proto.CoordinateRequest{
+			AddTunnel: &proto.CoordinateRequest_Tunnel{Id: tailnet.UUIDToByteSlice(otherID)},
+		}))
+		resp := requireRecvCtx(ctx, t, clientResps)
+		require.NotEmpty(t, resp.Error)
+		require.Empty(t, resp.PeerUpdates)
+		requireClosed(ctx, t, clientResps)
+	})
+
+	t.Run("AgentCannotTunnel", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		coordinator := tailnet.NewCoordinator(logger)
+		defer coordinator.Close()
+
+		agentReqs, agentResps := coordinator.Coordinate(ctx, uuid.New(), "agent", tailnet.AgentTunnelAuth{})
+		require.NoError(t, tailnet.SendCtx(ctx, agentReqs, &proto.CoordinateRequest{
+			AddTunnel: &proto.CoordinateRequest_Tunnel{Id: tailnet.UUIDToByteSlice(uuid.New())},
+		}))
+		resp := requireRecvCtx(ctx, t, agentResps)
+		require.NotEmpty(t, resp.Error)
+		requireClosed(ctx, t, agentResps)
+	})
+
+	t.Run("V1ClientV2Agent", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		coordinator := tailnet.NewCoordinator(logger)
+		defer coordinator.Close()
+
+		agentID := uuid.New()
+		agentReqs, agentResps := coordinator.Coordinate(ctx, agentID, "agent", tailnet.AgentTunnelAuth{})
+		sendUpdateSelf(ctx, t, agentReqs, 1)
+
+		clientWS, clientServerWS := net.Pipe()
+		defer clientWS.Close()
+		clientNodeChan := make(chan []*tailnet.Node)
+		sendClientNode, clientErrChan := tailnet.ServeCoordinator(clientWS, func(nodes []*tailnet.Node) error {
+			clientNodeChan <- nodes
+			return nil
+		})
+		closeClientChan := make(chan struct{})
+		go func() {
+			err := coordinator.ServeClient(clientServerWS, uuid.New(), agentID)
+			assert.NoError(t, err)
+			close(closeClientChan)
+		}()
+		agentNodes := requireRecvCtx(ctx, t, clientNodeChan)
+		require.Len(t, agentNodes, 1)
+		require.EqualValues(t, 1, agentNodes[0].ID)
+
+		sendClientNode(&tailnet.Node{ID: 2})
+		update := recvUpdate(ctx, t, agentResps)
+		require.EqualValues(t, 2, update.Node.Id)
+
+		require.NoError(t, clientWS.Close())
+		update = recvUpdate(ctx, t, agentResps)
+		require.Equal(t, proto.CoordinateResponse_PeerUpdate_DISCONNECTED, update.Kind)
+		<-clientErrChan
+		<-closeClientChan
+	})
+}
+
+func TestRemoteCoordination(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitShort)
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	coordinator := tailnet.NewCoordinator(logger)
+	defer coordinator.Close()
+
+	agentID := uuid.New()
+	agentReqs, agentResps := coordinator.Coordinate(ctx, agentID, "agent", tailnet.AgentTunnelAuth{})
+	sendUpdateSelf(ctx, t, agentReqs, 1)
+
+	client, server := net.Pipe()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- tailnet.ServeCoordinatePeer(ctx, logger, coordinator, server,
+			uuid.New(), "client", tailnet.ClientTunnelAuth{AgentID: agentID})
+	}()
+	fake := newFakeCoordinatee()
+	coordination := tailnet.NewRemoteCoordination(logger, client, fake, agentID)
+
+	nodes := requireRecvCtx(ctx, t, fake.nodes)
+	require.Len(t, nodes, 1)
+	require.EqualValues(t, 1, nodes[0].ID)
+
+	fake.sendNode(&tailnet.Node{ID: 2, PreferredDERP: 1})
+	update := recvUpdate(ctx, t, agentResps)
+	require.EqualValues(t, 2, update.Node.Id)
+
+	// The agent going away removes it from the coordinatee.
+	require.NoError(t, tailnet.SendCtx(ctx, agentReqs, &proto.CoordinateRequest{
+		Disconnect: &proto.CoordinateRequest_Disconnect{},
+	}))
+	removed := requireRecvCtx(ctx, t, fake.removed)
+	require.EqualValues(t, 1, removed)
+
+	require.NoError(t, coordination.Close())
+	require.NoError(t, requireRecvCtx(ctx, t, serveErr))
+}
+
+type fakeCoordinatee struct {
+	nodes   chan []*tailnet.Node
+	removed chan tailcfg.NodeID
+
+	mu       sync.Mutex
+	callback func(*tailnet.Node)
+}
+
+func newFakeCoordinatee() *fakeCoordinatee {
+	return &fakeCoordinatee{
+		nodes:   make(chan []*tailnet.Node, 16),
+		removed: make(chan tailcfg.NodeID, 16),
+	}
+}
+
+func (f *fakeCoordinatee) UpdateNodes(nodes []*tailnet.Node, _ bool) error {
+	f.nodes <- nodes
+	return nil
+}
+
+func (f *fakeCoordinatee) RemovePeer(id tailcfg.NodeID) error {
+	f.removed <- id
+	return nil
+}
+
+func (f *fakeCoordinatee) SetNodeCallback(callback func(*tailnet.Node)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.callback = callback
+}
+
+func (f *fakeCoordinatee) sendNode(node *tailnet.Node) {
+	f.mu.Lock()
+	callback := f.callback
+	f.mu.Unlock()
+	callback(node)
+}
+
+func sendUpdateSelf(ctx context.Context, t *testing.T, reqs chan<- *proto.CoordinateRequest, id int64) {
+	t.Helper()
+	node, err := tailnet.NodeToProto(&tailnet.Node{ID: tailcfg.NodeID(id), PreferredDERP: 1})
+	require.NoError(t, err)
+	err = tailnet.SendCtx(ctx, reqs, &proto.CoordinateRequest{
+		UpdateSelf: &proto.CoordinateRequest_UpdateSelf{Node: node},
+	})
+	require.NoError(t, err)
+}
+
+func recvUpdate(ctx context.Context, t *testing.T, resps <-chan *proto.CoordinateResponse) *proto.CoordinateResponse_PeerUpdate {
+	t.Helper()
+	resp := requireRecvCtx(ctx, t, resps)
+	require.Empty(t, resp.Error)
+	require.Len(t, resp.PeerUpdates, 1)
+	return resp.PeerUpdates[0]
+}
+
+func requireRecvCtx[A any](ctx context.Context, t *testing.T, c <-chan A) A {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+		t.Fatal("timeout")
+		var a A
+		return a
+	case a := <-c:
+		return a
+	}
+}
+
+func requireClosed(ctx context.Context, t *testing.T, resps <-chan *proto.CoordinateResponse) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for responses to close")
+	case _, ok := <-resps:
+		require.False(t, ok, "responses should be closed")
+	}
+}
+
 func websocketConn(ctx context.Context, t *testing.T) (client net.Conn, server net.Conn) {
 	t.Helper()
 	sc := make(chan net.Conn, 1)
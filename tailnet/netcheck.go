@@ -0,0 +1,213 @@
+package tailnet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"tailscale.com/net/netcheck"
+	"tailscale.com/net/portmapper"
+	"tailscale.com/net/tstun"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/opt"
+
+	"cdr.dev/slog"
+)
+
+// wireguardOverhead is the worst case size of the IPv6, UDP and WireGuard
+// headers added to every tailnet packet sent directly.
+const wireguardOverhead = 80
+
+// NetcheckMinimumMTU is the smallest interface MTU that carries tailnet
+// packets directly without fragmentation.
+const NetcheckMinimumMTU = tstun.DefaultMTU + wireguardOverhead
+
+// NetcheckReport describes the network conditions of a machine that decide
+// whether tailnet connections to and from it can be direct.
+type NetcheckReport struct {
+	// UDP is whether a UDP STUN round trip completed.
+	UDP         bool `json:"udp"`
+	IPv4        bool `json:"ipv4"`
+	IPv6        bool `json:"ipv6"`
+	IPv4CanSend bool `json:"ipv4_can_send"`
+	IPv6CanSend bool `json:"ipv6_can_send"`
+	OSHasIPv6   bool `json:"os_has_ipv6"`
+	// MappingVariesByDestIP is whether the NAT picks a different public port
+	// for every destination, which is known as a hard NAT. Nil means
+	// unknown.
+	MappingVariesByDestIP *bool `json:"mapping_varies_by_dest_ip"`
+	HairPinning           *bool `json:"hair_pinning"`
+	UPnP                  *bool `json:"upnp"`
+	PMP                   *bool `json:"pmp"`
+	PCP                   *bool `json:"pcp"`
+	// CaptivePortal is whether HTTP traffic to DERP servers is intercepted.
+	CaptivePortal *bool `json:"captive_portal"`
+
+	PreferredDERP int `json:"preferred_derp"`
+	// RegionLatencyMillis is keyed by DERP region ID.
+	RegionLatencyMillis map[int]int64 `json:"region_latency_ms"`
+	GlobalV4            string        `json:"global_v4"`
+	GlobalV6            string        `json:"global_v6"`
+
+	Interfaces []NetcheckInterface `json:"interfaces"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// NetcheckInterface is a network interface of the machine that is up.
+type NetcheckInterface struct {
+	Name      string   `json:"name"`
+	MTU       int      `json:"mtu"`
+	Addresses []string `json:"addresses"`
+}
+
+// Netcheck probes the network of the machine using the STUN servers and DERP
+// regions of derpMap.
+func Netcheck(ctx context.Context, logger slog.Logger, derpMap *tailcfg.DERPMap) NetcheckReport {
+	var report NetcheckReport
+	report.Interfaces, _ = netcheckInterfaces()
+
+	logf := Logger(logger.Named("netcheck"))
+	client := &netcheck.Client{
+		PortMapper: portmapper.NewClient(Logger(logger.Named("portmap")), nil),
+		Logf:       logf,
+	}
+	result, err := client.GetReport(ctx, derpMap)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	report.UDP = result.UDP
+	report.IPv4 = result.IPv4
+	report.IPv6 = result.IPv6
+	report.IPv4CanSend = result.IPv4CanSend
+	report.IPv6CanSend = result.IPv6CanSend
+	report.OSHasIPv6 = result.OSHasIPv6
+	report.MappingVariesByDestIP = optBool(result.MappingVariesByDestIP)
+	report.HairPinning = optBool(result.HairPinning)
+	report.UPnP = optBool(result.UPnP)
+	report.PMP = optBool(result.PMP)
+	report.PCP = optBool(result.PCP)
+	report.CaptivePortal = optBool(result.CaptivePortal)
+	report.PreferredDERP = result.PreferredDERP
+	report.RegionLatencyMillis = make(map[int]int64, len(result.RegionLatency))
+	for regionID, latency := range result.RegionLatency {
+		report.RegionLatencyMillis[regionID] = latency.Milliseconds()
+	}
+	report.GlobalV4 = result.GlobalV4
+	report.GlobalV6 = result.GlobalV6
+	return report
+}
+
+func netcheckInterfaces() ([]NetcheckInterface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	interfaces := make([]NetcheckInterface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		addresses := make([]string, 0, len(addrs))
+		for _, addr := range addrs {
+			addresses = append(addresses, addr.String())
+		}
+		interfaces = append(interfaces, NetcheckInterface{
+			Name:      iface.Name,
+			MTU:       iface.MTU,
+			Addresses: addresses,
+		})
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].Name < interfaces[j].Name
+	})
+	return interfaces, nil
+}
+
+func optBool(b opt.Bool) *bool {
+	v, ok := b.Get()
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// NetcheckDiagnosis explains the netcheck reports of both ends of a
+// connection.
+type NetcheckDiagnosis struct {
+	// Blockers are the reasons a direct connection is unlikely, so traffic
+	// is relayed through DERP.
+	Blockers []string `json:"blockers"`
+	// Warnings are problems that don't prevent direct connections, but may
+	// degrade them.
+	Warnings []string `json:"warnings"`
+}
+
+// DiagnoseNetcheck compares the netcheck reports of the client and the
+// workspace agent of a connection.
+func DiagnoseNetcheck(client, agent NetcheckReport) NetcheckDiagnosis {
+	diagnosis := NetcheckDiagnosis{
+		Blockers: []string{},
+		Warnings: []string{},
+	}
+	sides := []struct {
+		name   string
+		report NetcheckReport
+	}{
+		{name: "client", report: client},
+		{name: "workspace", report: agent},
+	}
+	for _, side := range sides {
+		if side.report.Error != "" {
+			diagnosis.Warnings = append(diagnosis.Warnings, fmt.Sprintf("The netcheck of the %s failed: %s", side.name, side.report.Error))
+			continue
+		}
+		if !side.report.UDP {
+			diagnosis.Blockers = append(diagnosis.Blockers, fmt.Sprintf("The %s can't reach STUN servers over UDP, so it can't discover a public endpoint. A firewall is likely blocking outbound UDP.", side.name))
+		}
+		if isTrue(side.report.CaptivePortal) {
+			diagnosis.Blockers = append(diagnosis.Blockers, fmt.Sprintf("The %s is behind a captive portal that intercepts HTTP traffic.", side.name))
+		}
+		if side.report.PreferredDERP == 0 {
+			diagnosis.Warnings = append(diagnosis.Warnings, fmt.Sprintf("The %s couldn't pick a preferred DERP region, so relayed connections may fail.", side.name))
+		}
+		for _, iface := range side.report.Interfaces {
+			if iface.MTU > 0 && iface.MTU < NetcheckMinimumMTU {
+				diagnosis.Warnings = append(diagnosis.Warnings, fmt.Sprintf("Interface %q of the %s has an MTU of %d, below the %d bytes needed to send tailnet packets directly without fragmentation.", iface.Name, side.name, iface.MTU, NetcheckMinimumMTU))
+			}
+		}
+	}
+	if client.Error != "" || agent.Error != "" {
+		return diagnosis
+	}
+
+	switch {
+	case isTrue(client.MappingVariesByDestIP) && isTrue(agent.MappingVariesByDestIP):
+		diagnosis.Blockers = append(diagnosis.Blockers, "Both the client and the workspace are behind NATs that use a different public port for every destination (hard NAT), so hole punching fails.")
+	case isTrue(client.MappingVariesByDestIP) && !hasPortMapping(agent):
+		diagnosis.Warnings = append(diagnosis.Warnings, "The client is behind a hard NAT, so a direct connection depends on the workspace accepting inbound UDP.")
+	case isTrue(agent.MappingVariesByDestIP) && !hasPortMapping(client):
+		diagnosis.Warnings = append(diagnosis.Warnings, "The workspace is behind a hard NAT, so a direct connection depends on the client accepting inbound UDP.")
+	}
+	if client.UDP && agent.UDP {
+		sharedFamily := (client.IPv4 && agent.IPv4) || (client.IPv6 && agent.IPv6)
+		if !sharedFamily {
+			diagnosis.Blockers = append(diagnosis.Blockers, "The client and the workspace have no IP version in common, one is IPv4 only and the other IPv6 only.")
+		}
+	}
+	return diagnosis
+}
+
+func hasPortMapping(report NetcheckReport) bool {
+	return isTrue(report.UPnP) || isTrue(report.PMP) || isTrue(report.PCP)
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
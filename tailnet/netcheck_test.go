@@ -0,0 +1,93 @@
+package tailnet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/tailnet"
+)
+
+func TestDiagnoseNetcheck(t *testing.T) {
+	t.Parallel()
+
+	yes := true
+	no := false
+	easy := tailnet.NetcheckReport{
+		UDP:                   true,
+		IPv4:                  true,
+		MappingVariesByDestIP: &no,
+		PreferredDERP:         1,
+		Interfaces: []tailnet.NetcheckInterface{{
+			Name: "eth0",
+			MTU:  1500,
+		}},
+	}
+	hard := easy
+	hard.MappingVariesByDestIP = &yes
+
+	for _, tc := range []struct {
+		name     string
+		client   func(r *tailnet.NetcheckReport)
+		agent    func(r *tailnet.NetcheckReport)
+		base     [2]tailnet.NetcheckReport
+		blockers int
+		warnings int
+	}{{
+		name: "Direct",
+		base: [2]tailnet.NetcheckReport{easy, easy},
+	}, {
+		name:     "OneHardNAT",
+		base:     [2]tailnet.NetcheckReport{hard, easy},
+		warnings: 1,
+	}, {
+		name:     "BothHardNAT",
+		base:     [2]tailnet.NetcheckReport{hard, hard},
+		blockers: 1,
+	}, {
+		name:     "UDPBlocked",
+		base:     [2]tailnet.NetcheckReport{easy, easy},
+		agent:    func(r *tailnet.NetcheckReport) { r.UDP = false },
+		blockers: 1,
+	}, {
+		name:     "CaptivePortal",
+		base:     [2]tailnet.NetcheckReport{easy, easy},
+		client:   func(r *tailnet.NetcheckReport) { r.CaptivePortal = &yes },
+		blockers: 1,
+	}, {
+		name: "NoCommonIPVersion",
+		base: [2]tailnet.NetcheckReport{easy, easy},
+		agent: func(r *tailnet.NetcheckReport) {
+			r.IPv4 = false
+			r.IPv6 = true
+		},
+		blockers: 1,
+	}, {
+		name: "SmallMTU",
+		base: [2]tailnet.NetcheckReport{easy, easy},
+		client: func(r *tailnet.NetcheckReport) {
+			r.Interfaces = []tailnet.NetcheckInterface{{Name: "tun0", MTU: 1300}}
+		},
+		warnings: 1,
+	}, {
+		name:     "Failed",
+		base:     [2]tailnet.NetcheckReport{easy, {Error: "timed out"}},
+		warnings: 1,
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, agent := tc.base[0], tc.base[1]
+			if tc.client != nil {
+				tc.client(&client)
+			}
+			if tc.agent != nil {
+				tc.agent(&agent)
+			}
+			diagnosis := tailnet.DiagnoseNetcheck(client, agent)
+			require.Len(t, diagnosis.Blockers, tc.blockers, diagnosis.Blockers)
+			require.Len(t, diagnosis.Warnings, tc.warnings, diagnosis.Warnings)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package tailnet
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/tailnet/proto"
+)
+
+const (
+	// CoordinateVersion1 exchanges JSON encoded nodes. Clients receive the
+	// nodes of the agent they connect to, and agents the nodes of all
+	// clients connecting to them.
+	CoordinateVersion1 = 1
+	// CoordinateVersion2 exchanges length-prefixed protobuf
+	// CoordinateRequest and CoordinateResponse messages. Peers request
+	// tunnels explicitly and only receive the changes to the peers they
+	// have tunnels with.
+	CoordinateVersion2 = 2
+	// CurrentCoordinateVersion is the latest version of the coordination
+	// protocol.
+	CurrentCoordinateVersion = CoordinateVersion2
+
+	// CoordinateVersionQueryParam is the query parameter used to request a
+	// version of the coordination protocol. Requests without it use
+	// version 1.
+	CoordinateVersionQueryParam = "version"
+	// CoordinateVersionHeader is set on the response by servers that accept
+	// the requested version of the coordination protocol. Older servers
+	// ignore the query parameter and always speak version 1.
+	CoordinateVersionHeader = "Coder-Coordinate-Version"
+)
+
+// ParseCoordinateVersion parses the requested version of the coordination
+// protocol. An empty string is version 1.
+func ParseCoordinateVersion(s string) (int, error) {
+	if s == "" {
+		return CoordinateVersion1, nil
+	}
+	version, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid coordinate version %q", s)
+	}
+	if version < CoordinateVersion1 || version > CurrentCoordinateVersion {
+		return 0, xerrors.Errorf("unsupported coordinate version %d, must be between %d and %d",
+			version, CoordinateVersion1, CurrentCoordinateVersion)
+	}
+	return version, nil
+}
+
+// ServeCoordinatePeer serves version 2 of the coordination protocol on conn
+// for the peer with the given ID. It returns when the connection closes or
+// the coordinator is done with the peer.
+func ServeCoordinatePeer(
+	ctx context.Context, logger slog.Logger, coordinator Coordinator, conn net.Conn,
+	id uuid.UUID, name string, a TunnelAuth,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	logger = logger.With(slog.F("peer_id", id), slog.F("peer_name", name))
+	reqs, resps := coordinator.Coordinate(ctx, id, name, a)
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		// The coordinator closes resps when it is done with the peer.
+		defer conn.Close()
+		defer cancel()
+		for resp := range resps {
+			err := writeResponse(conn, resp)
+			if err != nil {
+				// often, this is just because the connection is closed/broken, so only log at debug.
+				logger.Debug(ctx, "could not write response to connection", slog.Error(err))
+				return
+			}
+		}
+	}()
+	defer func() {
+		cancel()
+		<-writerDone
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		req := new(proto.CoordinateRequest)
+		err := protodelim.UnmarshalFrom(reader, req)
+		if err != nil {
+			logger.Debug(ctx, "unable to read request; closed conn?", slog.Error(err))
+			if isClosedErr(err) {
+				return nil
+			}
+			return xerrors.Errorf("read request: %w", err)
+		}
+		err = SendCtx(ctx, reqs, req)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// writeResponse writes the length-prefixed response to conn.
+func writeResponse(conn net.Conn, resp *proto.CoordinateResponse) error {
+	// Set a deadline so that hung connections don't put back pressure on the system.
+	err := conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	if err != nil {
+		return xerrors.Errorf("set write deadline: %w", err)
+	}
+	_, err = protodelim.MarshalTo(conn, resp)
+	if err != nil {
+		return xerrors.Errorf("write: %w", err)
+	}
+	// Reset the deadline after the write, see TrackedConn.writeNodes for why
+	// this matters for nhooyr.io/websocket.
+	err = conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return xerrors.Errorf("reset write deadline: %w", err)
+	}
+	return nil
+}
+
+func isClosedErr(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, context.Canceled)
+}
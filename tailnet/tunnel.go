@@ -0,0 +1,126 @@
+package tailnet
+
+import (
+	"github.com/google/uuid"
+)
+
+// TunnelAuth decides which peers a peer may open a tunnel to. Peers can only
+// see each other's nodes if a tunnel exists between them.
+type TunnelAuth interface {
+	Authorize(dst uuid.UUID) bool
+}
+
+// SingleTailnetTunnelAuth allows all tunnels, since Coderd and wsproxy are
+// allowed to initiate a tunnel to any agent.
+type SingleTailnetTunnelAuth struct{}
+
+func (SingleTailnetTunnelAuth) Authorize(uuid.UUID) bool {
+	return true
+}
+
+// ClientTunnelAuth allows connecting to a single, given agent.
+type ClientTunnelAuth struct {
+	AgentID uuid.UUID
+}
+
+func (c ClientTunnelAuth) Authorize(dst uuid.UUID) bool {
+	return c.AgentID == dst
+}
+
+// AgentTunnelAuth disallows all tunnels, since agents are not allowed to
+// initiate their own tunnels.
+type AgentTunnelAuth struct{}
+
+func (AgentTunnelAuth) Authorize(uuid.UUID) bool {
+	return false
+}
+
+// tunnelStore contains tunnel information and allows querying it. It is not
+// threadsafe and all methods must be called while holding a mutex to ensure
+// exclusion.
+type tunnelStore struct {
+	bySrc map[uuid.UUID]map[uuid.UUID]struct{}
+	byDst map[uuid.UUID]map[uuid.UUID]struct{}
+}
+
+func newTunnelStore() *tunnelStore {
+	return &tunnelStore{
+		bySrc: make(map[uuid.UUID]map[uuid.UUID]struct{}),
+		byDst: make(map[uuid.UUID]map[uuid.UUID]struct{}),
+	}
+}
+
+func (s *tunnelStore) add(src, dst uuid.UUID) {
+	srcM, ok := s.bySrc[src]
+	if !ok {
+		srcM = make(map[uuid.UUID]struct{})
+		s.bySrc[src] = srcM
+	}
+	srcM[dst] = struct{}{}
+	dstM, ok := s.byDst[dst]
+	if !ok {
+		dstM = make(map[uuid.UUID]struct{})
+		s.byDst[dst] = dstM
+	}
+	dstM[src] = struct{}{}
+}
+
+func (s *tunnelStore) remove(src, dst uuid.UUID) {
+	delete(s.bySrc[src], dst)
+	if len(s.bySrc[src]) == 0 {
+		delete(s.bySrc, src)
+	}
+	delete(s.byDst[dst], src)
+	if len(s.byDst[dst]) == 0 {
+		delete(s.byDst, dst)
+	}
+}
+
+// removeAll removes all tunnels where the given ID is the source.
+func (s *tunnelStore) removeAll(src uuid.UUID) {
+	for dst := range s.bySrc[src] {
+		s.remove(src, dst)
+	}
+}
+
+// findTunnelPeers returns the IDs of all peers that have a tunnel to or from
+// the given ID.
+func (s *tunnelStore) findTunnelPeers(id uuid.UUID) []uuid.UUID {
+	set := make(map[uuid.UUID]struct{})
+	for dst := range s.bySrc[id] {
+		set[dst] = struct{}{}
+	}
+	for src := range s.byDst[id] {
+		set[src] = struct{}{}
+	}
+	out := make([]uuid.UUID, 0, len(set))
+	for id := range set {
+		out = append(out, id)
+	}
+	return out
+}
+
+// tunnelExists returns whether a tunnel exists between the two IDs in either
+// direction.
+func (s *tunnelStore) tunnelExists(a, b uuid.UUID) bool {
+	if _, ok := s.bySrc[a][b]; ok {
+		return true
+	}
+	_, ok := s.bySrc[b][a]
+	return ok
+}
+
+func (s *tunnelStore) htmlDebug() []HTMLTunnel {
+	out := make([]HTMLTunnel, 0)
+	for src, dsts := range s.bySrc {
+		for dst := range dsts {
+			out = append(out, HTMLTunnel{Src: src, Dst: dst})
+		}
+	}
+	return out
+}
+
+// HTMLTunnel is a tunnel as shown on the coordinator debug page.
+type HTMLTunnel struct {
+	Src, Dst uuid.UUID
+}